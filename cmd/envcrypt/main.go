@@ -0,0 +1,60 @@
+// Command envcrypt generates config decryption keys and seals values for
+// committed .env files:
+//
+//	envcrypt keygen
+//	printf %s "$SECRET" | envcrypt seal <recipient>
+//
+// The server decrypts the sealed values at startup with the key in
+// CONFIG_DECRYPTION_KEY, CONFIG_DECRYPTION_KEY_FILE or
+// CONFIG_DECRYPTION_KEY_COMMAND.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/haidang666/go-app/pkg/envcrypt"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "envcrypt:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: envcrypt keygen | envcrypt seal <recipient>")
+	}
+	switch args[0] {
+	case "keygen":
+		id, err := envcrypt.GenerateIdentity()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("# recipient: %s\n%s\n", id.Recipient(), id)
+		return nil
+	case "seal":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: envcrypt seal <recipient>")
+		}
+		r, err := envcrypt.ParseRecipient(args[1])
+		if err != nil {
+			return err
+		}
+		plaintext, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		sealed, err := envcrypt.Seal(r, string(plaintext))
+		if err != nil {
+			return err
+		}
+		fmt.Println(sealed)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// compare benches the base and PGO binaries in turn, rounds times each so
+// that drift in the machine's load affects both, and prints the sign-in
+// throughput and latencies of each side. The binaries run with the
+// environment of this process, on a free port.
+func compare(ctx context.Context, argv []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	base := flags.String("base", "bin/go-app-nopgo", "binary built with -pgo=off")
+	pgo := flags.String("pgo", "bin/go-app-pgo", "binary built with the profile")
+	rounds := flags.Int("rounds", 3, "benches of each binary")
+	args := loadFlags(flags)
+	flags.Parse(argv)
+
+	binaries := []string{*base, *pgo}
+	results := make([][]*loadResult, len(binaries))
+	for round := 1; round <= *rounds; round++ {
+		for i, bin := range binaries {
+			fmt.Printf("round %d: %s\n", round, bin)
+			result, err := benchBinary(ctx, bin, *args)
+			if err != nil {
+				return fmt.Errorf("%s: %w", bin, err)
+			}
+			results[i] = append(results[i], result)
+		}
+	}
+
+	fmt.Printf("\n%-28s %12s %10s %10s\n", OP_SIGN_IN, "req/s", "p50", "p99")
+	var rates [2]float64
+	for i, bin := range binaries {
+		var p50, p99 time.Duration
+		for _, r := range results[i] {
+			rates[i] += r.rate(OP_SIGN_IN)
+			p50 += r.Ops[OP_SIGN_IN].percentile(0.50)
+			p99 += r.Ops[OP_SIGN_IN].percentile(0.99)
+		}
+		n := len(results[i])
+		rates[i] /= float64(n)
+		fmt.Printf("%-28s %12.1f %10s %10s\n", bin, rates[i],
+			(p50 / time.Duration(n)).Round(time.Microsecond), (p99 / time.Duration(n)).Round(time.Microsecond))
+	}
+	if rates[0] > 0 {
+		fmt.Printf("pgo throughput change: %+.1f%%\n", (rates[1]/rates[0]-1)*100)
+	}
+	return nil
+}
+
+// benchBinary starts bin, waits for it to be ready, runs the load and
+// stops it.
+func benchBinary(ctx context.Context, bin string, args loadArgs) (*loadResult, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(), "APP_PORT="+strconv.Itoa(port), "APP_LISTEN=")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		cmd.Process.Signal(syscall.SIGTERM)
+		cmd.Wait()
+	}()
+
+	url := "http://127.0.0.1:" + strconv.Itoa(port)
+	if err := waitReady(ctx, url+"/readyz", 30*time.Second); err != nil {
+		return nil, fmt.Errorf("%w; output:\n%s", err, output.String())
+	}
+	args.BaseURL = apiURL(url)
+	return runLoad(ctx, args)
+}
+
+func waitReady(ctx context.Context, url string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if res, err := http.DefaultClient.Do(req); err == nil {
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("not ready in time")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/client"
+)
+
+// loadPassword is the password of the accounts the load signs up.
+const loadPassword = "Pgo-load-Passw0rd!"
+
+// Operations of the load, weighted like a sign-in heavy API: every
+// iteration signs in, then uses and refreshes the tokens.
+const (
+	OP_SIGN_IN       = "sign_in"
+	OP_LIST_SESSIONS = "list_sessions"
+	OP_REFRESH       = "refresh"
+)
+
+var loadOps = []string{OP_SIGN_IN, OP_LIST_SESSIONS, OP_REFRESH}
+
+type loadArgs struct {
+	// BaseURL includes the version prefix, e.g. http://localhost:8080/api/v1.
+	BaseURL     string
+	Users       int
+	Concurrency int
+	Duration    time.Duration
+}
+
+// loadResult is what a load run measured, by operation.
+type loadResult struct {
+	Elapsed time.Duration
+	Ops     map[string]*opStats
+}
+
+type opStats struct {
+	Count     int
+	Errors    int
+	latencies []time.Duration
+	// FirstError is kept to tell why a run fails, e.g. rate limits.
+	FirstError error
+}
+
+func (s *opStats) percentile(p float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	return s.latencies[int(float64(len(s.latencies)-1)*p)]
+}
+
+// runLoad signs up args.Users accounts, then has args.Concurrency workers
+// sign in to them for args.Duration. Sign-up is not measured; the optional
+// started hooks run once it is done, e.g. to start profiling.
+func runLoad(ctx context.Context, args loadArgs, started ...func() error) (*loadResult, error) {
+	hc := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: args.Concurrency}}
+	anonymous := client.New(args.BaseURL, client.WithHTTPClient(hc), client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+
+	run := rand.Int64()
+	emails := make([]string, args.Users)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("pgo-%x-%d@load.test", run, i)
+		_, err := anonymous.SignUp(ctx, &client.SignUpRequest{Email: emails[i], Password: loadPassword})
+		if err != nil {
+			return nil, fmt.Errorf("sign up %s: %w", emails[i], err)
+		}
+	}
+
+	for _, f := range started {
+		if err := f(); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, args.Duration)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		result = &loadResult{Ops: map[string]*opStats{}}
+		wg     sync.WaitGroup
+	)
+	for _, op := range loadOps {
+		result.Ops[op] = &opStats{}
+	}
+	record := func(op string, start time.Time, err error) {
+		if ctx.Err() != nil && errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		s := result.Ops[op]
+		s.Count++
+		s.latencies = append(s.latencies, time.Since(start))
+		if err != nil {
+			s.Errors++
+			if s.FirstError == nil {
+				s.FirstError = err
+			}
+		}
+	}
+
+	start := time.Now()
+	for w := 0; w < args.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				email := emails[rand.IntN(len(emails))]
+				t0 := time.Now()
+				tokens, err := anonymous.SignIn(ctx, &client.SignInRequest{Email: email, Password: loadPassword})
+				record(OP_SIGN_IN, t0, err)
+				if err != nil {
+					continue
+				}
+
+				authed := client.New(args.BaseURL, client.WithHTTPClient(hc), client.WithToken(tokens.AccessToken),
+					client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+				t0 = time.Now()
+				_, err = authed.ListSessions(ctx)
+				record(OP_LIST_SESSIONS, t0, err)
+
+				t0 = time.Now()
+				_, err = anonymous.Refresh(ctx, &client.RefreshRequest{RefreshToken: tokens.RefreshToken})
+				record(OP_REFRESH, t0, err)
+			}
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+
+	for _, s := range result.Ops {
+		slices.Sort(s.latencies)
+	}
+	return result, nil
+}
+
+// print writes a line per operation: throughput, errors and latencies.
+func (r *loadResult) print(w io.Writer) {
+	fmt.Fprintf(w, "%-14s %10s %8s %10s %10s %10s\n", "op", "req/s", "errors", "p50", "p90", "p99")
+	for _, op := range loadOps {
+		s := r.Ops[op]
+		fmt.Fprintf(w, "%-14s %10.1f %8d %10s %10s %10s\n", op, r.rate(op), s.Errors,
+			s.percentile(0.50).Round(time.Microsecond), s.percentile(0.90).Round(time.Microsecond), s.percentile(0.99).Round(time.Microsecond))
+	}
+	for _, op := range loadOps {
+		if err := r.Ops[op].FirstError; err != nil {
+			fmt.Fprintf(w, "first %s error: %v\n", op, err)
+		}
+	}
+}
+
+// rate is the successful requests per second of op.
+func (r *loadResult) rate(op string) float64 {
+	s := r.Ops[op]
+	return float64(s.Count-s.Errors) / r.Elapsed.Seconds()
+}
@@ -0,0 +1,81 @@
+// Command pgo captures the profiles of a sign-in heavy load and measures
+// what profile-guided optimization gains on it:
+//
+//	pgo profile [-out cmd/server/default.pgo] [-allocs allocs.pprof]
+//	pgo bench -url http://localhost:8080
+//	pgo compare -base bin/go-app-nopgo -pgo bin/go-app-pgo
+//
+// profile runs the server container in process, with the configuration
+// of the environment, and writes the CPU profile of the load where go
+// build -pgo=auto picks it up. bench runs the load against a running
+// server; compare starts each binary in turn and benches it. See make
+// pgo-profile and make pgo-bench.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "profile":
+		err = profile(ctx, os.Args[2:])
+	case "bench":
+		err = bench(ctx, os.Args[2:])
+	case "compare":
+		err = compare(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pgo %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pgo profile|bench|compare [flags]")
+	os.Exit(2)
+}
+
+// loadFlags registers the flags shaping the load on flags.
+func loadFlags(flags *flag.FlagSet) *loadArgs {
+	args := &loadArgs{}
+	flags.IntVar(&args.Users, "users", 20, "accounts to sign in to")
+	flags.IntVar(&args.Concurrency, "concurrency", 16, "concurrent workers")
+	flags.DurationVar(&args.Duration, "duration", 30*time.Second, "length of the load")
+	return args
+}
+
+func bench(ctx context.Context, argv []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	url := flags.String("url", "http://localhost:8080", "base URL of the server")
+	args := loadFlags(flags)
+	flags.Parse(argv)
+
+	args.BaseURL = apiURL(*url)
+	result, err := runLoad(ctx, *args)
+	if err != nil {
+		return err
+	}
+	result.print(os.Stdout)
+	return nil
+}
+
+// apiURL is the versioned API under the server's base URL.
+func apiURL(base string) string {
+	return base + "/api/v1"
+}
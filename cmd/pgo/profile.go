@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/haidang666/go-app/internal/bootstrap"
+	"github.com/haidang666/go-app/internal/config"
+)
+
+// profile serves the container on a loopback port and profiles the process
+// while the load runs. Sign-up happens before the CPU profile starts, so
+// it only covers the measured operations.
+func profile(ctx context.Context, argv []string) error {
+	flags := flag.NewFlagSet("profile", flag.ExitOnError)
+	out := flags.String("out", "cmd/server/default.pgo", "CPU profile to write, used by go build -pgo=auto")
+	allocs := flags.String("allocs", "", "allocation profile to write, if set")
+	args := loadFlags(flags)
+	flags.Parse(argv)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	c, err := bootstrap.CreateServerContainer(cfg)
+	if err != nil {
+		return fmt.Errorf("create server container: %w", err)
+	}
+	defer c.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: c.Router}
+	go server.Serve(ln)
+	defer server.Close()
+	args.BaseURL = apiURL("http://" + ln.Addr().String())
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := runLoad(ctx, *args, func() error { return pprof.StartCPUProfile(f) })
+	pprof.StopCPUProfile()
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	result.print(os.Stdout)
+	fmt.Printf("cpu profile written to %s\n", *out)
+
+	if *allocs != "" {
+		if err := writeAllocs(*allocs); err != nil {
+			return err
+		}
+		fmt.Printf("allocation profile written to %s\n", *allocs)
+	}
+	if failed(result) {
+		return errors.New("requests failed during the load; the profile may not be representative")
+	}
+	return nil
+}
+
+func writeAllocs(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// The profile is as of the last GC; collect to include the load.
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// failed reports whether most requests of an operation failed, e.g.
+// because rate limits rejected the load.
+func failed(r *loadResult) bool {
+	for _, s := range r.Ops {
+		if s.Count > 0 && s.Errors*2 > s.Count {
+			return true
+		}
+	}
+	return false
+}
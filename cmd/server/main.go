@@ -2,31 +2,89 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/haidang666/go-app/internal/bootstrap"
 	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/pkg/crash"
 	"github.com/haidang666/go-app/pkg/logger"
+
+	// The Postgres-backed stores open the DB_* database with the database/sql
+	// driver registered as "pgx", their default SQL driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(doctor(os.Args[2:]))
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		logger.L().Fatalf("config error: %v", err)
 	}
 
+	reporter := crash.NewReporter(crash.ReporterArgs{Dir: cfg.Crash.ReportDir, Config: cfg.Snapshot()})
+	if err := reporter.Install(); err != nil {
+		logger.L().Fatalf("crash reporter: %v", err)
+	}
+	defer reporter.Recover()
+	logger.OnFatal(func(msg string) {
+		if path, err := reporter.Write("fatal: " + msg); err == nil && path != "" {
+			fmt.Fprintf(os.Stderr, "crash report written to %s\n", path)
+		}
+		bootstrap.WriteTerminationLog(cfg.App.TerminationLog, "fatal: "+msg)
+	})
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	c, err := bootstrap.CreateServerContainer()
+	c, err := bootstrap.CreateServerContainer(cfg)
 	if err != nil {
 		logger.L().Fatalf("fail to create server container: %v", err)
 	}
 	defer c.Close()
 
-	if err := bootstrap.StartRestAPI(ctx, cfg, c.Router); err != nil {
+	go func() {
+		defer reporter.Recover()
+		c.RunElector(ctx)
+	}()
+
+	if err := bootstrap.Serve(ctx, cfg, c); err != nil {
+		// The shutdown report has been logged; the exit code tells deploy
+		// tooling the instance did not stop cleanly.
+		var degraded *bootstrap.DegradedShutdownError
+		if errors.As(err, &degraded) {
+			c.Close()
+			os.Exit(bootstrap.EXIT_DEGRADED_SHUTDOWN)
+		}
 		logger.L().Fatalf("starting server: %v", err)
 	}
 }
+
+// doctor runs the self-test, e.g. as a deploy preflight:
+//
+//	go-app doctor [-email-to sink@example.com] [-timeout 10s]
+//
+// It exits 1 when a check fails.
+func doctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	emailTo := flags.String("email-to", "", "send a test email to this address")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit of each check")
+	flags.Parse(args)
+
+	ok := bootstrap.RunDoctor(context.Background(), os.Stdout, bootstrap.DoctorArgs{
+		EmailTo: *emailTo,
+		Timeout: *timeout,
+	})
+	if !ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+var ErrAdminTaskParamsNotObject = errors.New("params must be a JSON object")
+
+type TriggerAdminTaskRequest struct {
+	// Params are passed to the task as they are; what it takes is listed by
+	// GET /admin/tasks.
+	Params json.RawMessage `json:"params"`
+}
+
+func (req *TriggerAdminTaskRequest) Validate() error {
+	params := bytes.TrimSpace(req.Params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		req.Params = nil
+		return nil
+	}
+	if params[0] != '{' {
+		return ErrAdminTaskParamsNotObject
+	}
+	return nil
+}
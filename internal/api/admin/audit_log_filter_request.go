@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+// AuditLogFilterRequest is the query of the audit log list and export
+// endpoints. UserID limits them to events involving that user, as actor or
+// subject; action may be repeated or comma-separated; from and to are
+// RFC 3339 times, from inclusive and to exclusive.
+type AuditLogFilterRequest struct {
+	UserID    uuid.UUID  `query:"user_id"`
+	ActorID   uuid.UUID  `query:"actor_id"`
+	SubjectID uuid.UUID  `query:"subject_id"`
+	Actions   []string   `query:"action" validate:"max=20,dive,required,max=100"`
+	From      *time.Time `query:"from"`
+	To        *time.Time `query:"to" validate:"omitempty,gtfield=From"`
+}
+
+func (req *AuditLogFilterRequest) Validate() error {
+	return validate.Struct(req)
+}
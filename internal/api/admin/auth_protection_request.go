@@ -0,0 +1,11 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type SetAuthProtectionRequest struct {
+	Mode string `json:"mode" validate:"required,oneof=auto on off"`
+}
+
+func (req *SetAuthProtectionRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+const maxBulkItems = 1000
+
+var (
+	ErrBulkEmpty    = errors.New("at least one item is required")
+	ErrBulkTooLarge = errors.New("too many items in a single bulk request")
+)
+
+type BulkCreateUsersRequest struct {
+	Users []auth.SignUpRequest `json:"users"`
+}
+
+func (req *BulkCreateUsersRequest) Validate() error {
+	return validateSize(len(req.Users))
+}
+
+type UpdateUserItem struct {
+	ID       string `json:"id" validate:"required,uuid"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Password string `json:"password" validate:"omitempty,strong_password"`
+}
+
+// Validate checks a single item; Email and Password are optional but must be
+// well-formed when present.
+func (item *UpdateUserItem) Validate() error {
+	return validate.Struct(item)
+}
+
+type BulkUpdateUsersRequest struct {
+	Users []UpdateUserItem `json:"users"`
+}
+
+func (req *BulkUpdateUsersRequest) Validate() error {
+	return validateSize(len(req.Users))
+}
+
+func validateSize(n int) error {
+	if n == 0 {
+		return ErrBulkEmpty
+	}
+	if n > maxBulkItems {
+		return ErrBulkTooLarge
+	}
+	return nil
+}
+
+type ForcePasswordRotationRequest struct {
+	UserIDs []string `json:"user_ids"`
+	// RevokeSessions also signs the users out everywhere.
+	RevokeSessions bool `json:"revoke_sessions"`
+}
+
+func (req *ForcePasswordRotationRequest) Validate() error {
+	return validateSize(len(req.UserIDs))
+}
@@ -0,0 +1,12 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// DeadLetterFilterRequest is the query of the dead letter list.
+type DeadLetterFilterRequest struct {
+	Kind string `query:"kind" validate:"max=100"`
+}
+
+func (req *DeadLetterFilterRequest) Validate() error {
+	return validate.Struct(req)
+}
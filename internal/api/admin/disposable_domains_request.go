@@ -0,0 +1,11 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type ReplaceDisposableDomainsRequest struct {
+	Domains []string `json:"domains" validate:"required,dive,required,fqdn"`
+}
+
+func (req *ReplaceDisposableDomainsRequest) Validate() error {
+	return validate.Struct(req)
+}
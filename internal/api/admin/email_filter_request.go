@@ -0,0 +1,12 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// EmailFilterRequest is the query of the outbound email list.
+type EmailFilterRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=queued sent failed bounced complained suppressed"`
+}
+
+func (req *EmailFilterRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,23 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// EmailSuppressionFilterRequest is the query of the suppression list.
+type EmailSuppressionFilterRequest struct {
+	Reason string `query:"reason" validate:"omitempty,oneof=bounce complaint unsubscribe manual"`
+}
+
+func (req *EmailSuppressionFilterRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type AddEmailSuppressionRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	// Reason defaults to manual.
+	Reason string `json:"reason" validate:"omitempty,oneof=bounce complaint unsubscribe manual"`
+	Detail string `json:"detail" validate:"max=500"`
+}
+
+func (req *AddEmailSuppressionRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,12 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type ImpersonateUserRequest struct {
+	// Reason is recorded in the audit log and shown to the user.
+	Reason string `json:"reason" validate:"required,max=500,no_control_chars"`
+}
+
+func (req *ImpersonateUserRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,13 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type MintInvitationRequest struct {
+	Email          string `json:"email" validate:"omitempty,email"`
+	MaxUses        int    `json:"max_uses" validate:"omitempty,min=1,max=10000"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1,max=8760"`
+}
+
+func (req *MintInvitationRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,18 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+// JobStatsRequest is the query of the job queue stats.
+type JobStatsRequest struct {
+	// Window is how far back processing rates are computed; 15 minutes when
+	// empty.
+	Window time.Duration `query:"window" validate:"omitempty,min=1m,max=168h"`
+}
+
+func (req *JobStatsRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,13 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type SetMaintenanceRequest struct {
+	ReadOnly *bool `json:"read_only" validate:"required"`
+	// Reason defaults to maintenance and is ignored when ReadOnly is false.
+	Reason string `json:"reason" validate:"omitempty,oneof=maintenance failover migration"`
+}
+
+func (req *SetMaintenanceRequest) Validate() error {
+	return validate.Struct(req)
+}
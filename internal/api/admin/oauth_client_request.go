@@ -0,0 +1,19 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RegisterOAuthClientRequest struct {
+	Name string `json:"name" validate:"required,max=100,no_control_chars"`
+	// Scopes travel space-separated in tokens, so they cannot contain spaces.
+	Scopes []string `json:"scopes" validate:"max=50,dive,required,max=100,printascii,excludesall= "`
+	// RedirectURIs are required for OpenID Connect relying parties.
+	RedirectURIs []string `json:"redirect_uris" validate:"max=20,dive,required,url"`
+	// Public clients get no secret and must use PKCE.
+	Public bool `json:"public"`
+	// Plan selects the client's request quota; empty means the default plan.
+	Plan string `json:"plan" validate:"max=50"`
+}
+
+func (req *RegisterOAuthClientRequest) Validate() error {
+	return validate.Struct(req)
+}
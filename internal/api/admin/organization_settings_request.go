@@ -0,0 +1,18 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// UpdateOrganizationSettingsRequest replaces all of a tenant's settings;
+// omitted fields are reset.
+type UpdateOrganizationSettingsRequest struct {
+	AllowedCallbackDomains []string `json:"allowed_callback_domains" validate:"max=50,dive,min=1,max=253"`
+	DefaultMemberRole      string   `json:"default_member_role" validate:"max=63"`
+	RequireMFA             bool     `json:"require_mfa"`
+	// SessionMaxAgeSeconds is between 5 minutes and a year; 0 removes the
+	// limit.
+	SessionMaxAgeSeconds int64 `json:"session_max_age_seconds" validate:"omitempty,min=300,max=31536000"`
+}
+
+func (req *UpdateOrganizationSettingsRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,12 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RevokeUserAccessRequest struct {
+	// Reason is recorded in the audit log.
+	Reason string `json:"reason" validate:"max=500,no_control_chars"`
+}
+
+func (req *RevokeUserAccessRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,20 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RegisterSAMLConnectionRequest struct {
+	// Tenant appears in the connection's endpoint URLs.
+	Tenant      string `json:"tenant" validate:"required,max=63"`
+	IdPEntityID string `json:"idp_entity_id" validate:"required,max=1024"`
+	IdPSSOURL   string `json:"idp_sso_url" validate:"required,url,max=2048"`
+	// IdPCertificate is the PEM certificate the identity provider signs
+	// with.
+	IdPCertificate    string `json:"idp_certificate" validate:"required,max=16384"`
+	EmailAttribute    string `json:"email_attribute" validate:"max=256"`
+	UsernameAttribute string `json:"username_attribute" validate:"max=256"`
+	JITProvisioning   bool   `json:"jit_provisioning"`
+}
+
+func (req *RegisterSAMLConnectionRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,25 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type CreateServiceAccountRequest struct {
+	// Tenant is the organization the account belongs to.
+	Tenant      string   `json:"tenant" validate:"required,max=63"`
+	Name        string   `json:"name" validate:"required,max=100,no_control_chars"`
+	Description string   `json:"description" validate:"max=500,no_control_chars"`
+	Roles       []string `json:"roles" validate:"max=20,unique,dive,max=63"`
+}
+
+func (req *CreateServiceAccountRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type CreateServiceAccountKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100,no_control_chars"`
+	// ExpiresInDays is optional; keys without one do not expire.
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=730"`
+}
+
+func (req *CreateServiceAccountKeyRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,13 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type SetTenantQuotaRequest struct {
+	// Limits is in the format of QUOTA_PLANS, e.g. "600/1m;100000/24h";
+	// empty removes the tenant quota.
+	Limits string `json:"limits" validate:"max=200"`
+}
+
+func (req *SetTenantQuotaRequest) Validate() error {
+	return validate.Struct(req)
+}
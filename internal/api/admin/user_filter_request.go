@@ -0,0 +1,16 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// UserFilterRequest is the query of the user list and export endpoints.
+type UserFilterRequest struct {
+	// Query matches a substring of the email or username.
+	Query    string `query:"q" validate:"max=200"`
+	Status   string `query:"status" validate:"omitempty,oneof=active suspended banned"`
+	Plan     string `query:"plan"`
+	TenantID string `query:"tenant"`
+}
+
+func (req *UserFilterRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,13 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type SetUserPlanRequest struct {
+	// Plan is a configured quota plan; empty returns the user to the
+	// default plan.
+	Plan string `json:"plan" validate:"max=50"`
+}
+
+func (req *SetUserPlanRequest) Validate() error {
+	return validate.Struct(req)
+}
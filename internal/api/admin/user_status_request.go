@@ -0,0 +1,13 @@
+package admin
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type SetUserStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+	// Reason is recorded in the audit log.
+	Reason string `json:"reason" validate:"max=500,no_control_chars"`
+}
+
+func (req *SetUserStatusRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,20 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (req *ForgotPasswordRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,strong_password"`
+}
+
+func (req *ResetPasswordRequest) Validate() error {
+	return validate.Struct(req)
+}
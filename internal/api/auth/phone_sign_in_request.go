@@ -0,0 +1,20 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RequestSignInCodeRequest struct {
+	Phone string `json:"phone" validate:"required,e164_phone"`
+}
+
+func (req *RequestSignInCodeRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type PhoneSignInRequest struct {
+	Phone string `json:"phone" validate:"required,e164_phone"`
+	Code  string `json:"code" validate:"required,numeric"`
+}
+
+func (req *PhoneSignInRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (req *RefreshRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type RestoreAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (req *RestoreAccountRequest) Validate() error {
+	return validate.Struct(req)
+}
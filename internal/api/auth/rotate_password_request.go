@@ -0,0 +1,17 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// RotatePasswordRequest replaces an expired password; the account is
+// identified as in SignInRequest.
+type RotatePasswordRequest struct {
+	SignInRequest
+	NewPassword string `json:"new_password"`
+}
+
+func (req *RotatePasswordRequest) Validate() error {
+	if err := req.SignInRequest.Validate(); err != nil {
+		return err
+	}
+	return validate.Var("new_password", req.NewPassword, "required,strong_password")
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+func init() {
+	validate.RegisterStructValidation(validateSignInIdentifier, SignInRequest{})
+}
+
+// SignInRequest identifies the account by either email or username.
+type SignInRequest struct {
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (req *SignInRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+func validateSignInIdentifier(sl validate.StructLevel) {
+	req := sl.Current().Interface().(SignInRequest)
+	if (req.Email == "") == (req.Username == "") {
+		sl.ReportError(req.Email, "email", "Email", "exactly_one_of", "username")
+	}
+}
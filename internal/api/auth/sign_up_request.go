@@ -1,22 +1,25 @@
 package auth
 
-import "github.com/go-playground/validator/v10"
-
-var validate = validator.New(validator.WithRequiredStructEnabled())
+import "github.com/haidang666/go-app/pkg/validate"
 
 type SignUpRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,strong_password"`
+	// Username is optional; users without one sign in by email only.
+	Username string `json:"username,omitempty" validate:"omitempty,username"`
+	// InviteCode is required while registration is invite-only.
+	InviteCode string `json:"invite_code,omitempty"`
+	// TermsVersion and PrivacyVersion are the documents shown on the form.
+	TermsVersion   string `json:"terms_version,omitempty" map:"Terms.Terms"`
+	PrivacyVersion string `json:"privacy_version,omitempty" map:"Terms.Privacy"`
+	// Website is the honeypot: the form hides it from people, so it must be
+	// left empty.
+	Website string `json:"website,omitempty" map:"-"`
+	// FormToken is the token the form was shown with, from
+	// GET /auth/sign-up/form.
+	FormToken string `json:"form_token,omitempty" map:"-"`
 }
 
 func (req *SignUpRequest) Validate() error {
-	errs := validate.Var(req.Email, "required,email")
-	if errs != nil {
-		return errs
-	}
-	errs = validate.Var(req.Password, "required,min=5")
-	if errs != nil {
-		return errs
-	}
-	return nil
+	return validate.Struct(req)
 }
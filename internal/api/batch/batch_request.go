@@ -0,0 +1,55 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+var ErrNoSubRequests = errors.New("at least one sub-request is required")
+
+type SubRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type BatchRequest struct {
+	Requests []SubRequest `json:"requests"`
+}
+
+func (req *BatchRequest) Validate(maxRequests int) error {
+	if len(req.Requests) == 0 {
+		return ErrNoSubRequests
+	}
+	if len(req.Requests) > maxRequests {
+		return fmt.Errorf("at most %d sub-requests are allowed", maxRequests)
+	}
+
+	for i, sub := range req.Requests {
+		if err := validate.Var("method", sub.Method, "required,oneof=GET POST PUT PATCH DELETE"); err != nil {
+			return fmt.Errorf("requests[%d]: unsupported method %q", i, sub.Method)
+		}
+		// "//host/path" would be parsed as a host and a path of its own.
+		if !strings.HasPrefix(sub.Path, "/") || strings.HasPrefix(sub.Path, "//") {
+			return fmt.Errorf("requests[%d]: path must be absolute", i)
+		}
+	}
+	return nil
+}
+
+type SubResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type BatchResponse struct {
+	Responses []SubResponse `json:"responses"`
+}
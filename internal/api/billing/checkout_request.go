@@ -0,0 +1,11 @@
+package billing
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type CheckoutRequest struct {
+	Plan string `json:"plan" validate:"required,max=50"`
+}
+
+func (req *CheckoutRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,383 @@
+// Command genmapping writes mapping_gen.go from the //mapping: directives in
+// mapping.go, failing when a request field or an input field is left out.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	modulePath = "github.com/haidang666/go-app"
+	// moduleRoot is relative to the mapping package, where go generate runs.
+	moduleRoot = "../../.."
+)
+
+// packageDirs are searched, in order, for the packages named in directives.
+var packageDirs = []string{"internal/api", "internal/domain"}
+
+type mapping struct {
+	fn      string
+	src     typeRef
+	dst     typeRef
+	ignored []string
+}
+
+type typeRef struct {
+	pkg  string
+	name string
+}
+
+func (t typeRef) String() string {
+	return t.pkg + "." + t.name
+}
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "mapping.go", nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mappings, err := parseDirectives(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l := &loader{fset: fset, pkgs: make(map[string]*pkg)}
+	imports := make(map[string]string)
+	var body bytes.Buffer
+	for _, m := range mappings {
+		assigns, err := l.resolve(m)
+		if err != nil {
+			log.Fatalf("%s: %v", m.fn, err)
+		}
+		for _, ref := range []typeRef{m.src, m.dst} {
+			imports[ref.pkg] = l.pkgs[ref.pkg].importPath
+		}
+		writeFunc(&body, m, assigns)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by genmapping; DO NOT EDIT.\n\npackage mapping\n\nimport (\n")
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int { return strings.Compare(imports[a], imports[b]) })
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q\n", imports[name])
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("mapping_gen.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseDirectives(f *ast.File) ([]mapping, error) {
+	var out []mapping
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			spec, ok := strings.CutPrefix(c.Text, "//mapping:")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(spec)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("directive %q: want <Func> <pkg>.<Request> <pkg>.<Input>", c.Text)
+			}
+			m := mapping{fn: fields[0]}
+			var err error
+			if m.src, err = parseTypeRef(fields[1]); err != nil {
+				return nil, err
+			}
+			if m.dst, err = parseTypeRef(fields[2]); err != nil {
+				return nil, err
+			}
+			for _, ig := range fields[3:] {
+				path, ok := strings.CutPrefix(ig, "-")
+				if !ok {
+					return nil, fmt.Errorf("directive %q: %q must start with '-'", c.Text, ig)
+				}
+				m.ignored = append(m.ignored, path)
+			}
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func parseTypeRef(s string) (typeRef, error) {
+	p, name, ok := strings.Cut(s, ".")
+	if !ok {
+		return typeRef{}, fmt.Errorf("%q must be <pkg>.<Type>", s)
+	}
+	return typeRef{pkg: p, name: name}, nil
+}
+
+type pkg struct {
+	importPath string
+	dir        string
+	structs    map[string]*ast.StructType
+	// fileImports maps each struct to its file's imports, name to path.
+	fileImports map[string]map[string]string
+}
+
+type loader struct {
+	fset *token.FileSet
+	pkgs map[string]*pkg
+}
+
+// leaf is a field that is not itself flattened, addressed by its path.
+type leaf struct {
+	path []string
+	typ  string
+	tag  reflect.StructTag
+}
+
+func (lf leaf) key() string {
+	return strings.Join(lf.path, ".")
+}
+
+type assign struct {
+	dst, src string
+}
+
+func (l *loader) resolve(m mapping) ([]assign, error) {
+	srcLeaves, err := l.leaves(m.src, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	dstLeaves, err := l.leaves(m.dst, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	var out []assign
+	for _, s := range srcLeaves {
+		want := s.tag.Get("map")
+		if want == "-" {
+			continue
+		}
+		var match []leaf
+		for _, d := range dstLeaves {
+			if want != "" && d.key() == want || want == "" && d.path[len(d.path)-1] == s.path[len(s.path)-1] {
+				match = append(match, d)
+			}
+		}
+		switch {
+		case len(match) == 0:
+			return nil, fmt.Errorf("%s.%s has no field in %s; tag it map:\"<path>\" or map:\"-\"", m.src, s.key(), m.dst)
+		case len(match) > 1:
+			return nil, fmt.Errorf("%s.%s matches several fields in %s; tag it map:\"<path>\"", m.src, s.key(), m.dst)
+		case match[0].typ != s.typ:
+			return nil, fmt.Errorf("%s.%s is %s but %s.%s is %s", m.src, s.key(), s.typ, m.dst, match[0].key(), match[0].typ)
+		}
+		set[match[0].key()] = true
+		out = append(out, assign{dst: match[0].key(), src: s.key()})
+	}
+
+	for _, d := range dstLeaves {
+		if !set[d.key()] && !ignored(d.key(), m.ignored) {
+			return nil, fmt.Errorf("%s.%s is not set from %s; map it or list -%s", m.dst, d.key(), m.src, d.key())
+		}
+	}
+	return out, nil
+}
+
+func ignored(path string, ignores []string) bool {
+	for _, ig := range ignores {
+		if path == ig || strings.HasPrefix(path, ig+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// leaves lists the exported fields of ref, flattening embedded structs and,
+// when nested is set, struct-typed fields declared in the module.
+func (l *loader) leaves(ref typeRef, prefix []string, nested bool) ([]leaf, error) {
+	p, err := l.load(ref.pkg)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := p.structs[ref.name]
+	if !ok {
+		return nil, fmt.Errorf("no struct %s in %s", ref, p.dir)
+	}
+
+	var out []leaf
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		embedded := len(names) == 0
+		if embedded {
+			names = append(names, typeName(field.Type))
+		}
+
+		inner, isStruct := l.structRef(p, ref.name, field.Type)
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+			path := append(slices.Clone(prefix), name)
+			if isStruct && (embedded || nested) {
+				sub, err := l.leaves(inner, path, nested)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, sub...)
+				continue
+			}
+			out = append(out, leaf{path: path, typ: qualify(ref.pkg, field.Type), tag: tag})
+		}
+	}
+	return out, nil
+}
+
+// structRef resolves a field type to a struct declared in the module.
+func (l *loader) structRef(p *pkg, owner string, expr ast.Expr) (typeRef, bool) {
+	var ref typeRef
+	switch t := expr.(type) {
+	case *ast.Ident:
+		ref = typeRef{pkg: filepath.Base(p.dir), name: t.Name}
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return typeRef{}, false
+		}
+		path, ok := p.fileImports[owner][x.Name]
+		if !ok || !strings.HasPrefix(path, modulePath+"/") {
+			return typeRef{}, false
+		}
+		ref = typeRef{pkg: x.Name, name: t.Sel.Name}
+	default:
+		return typeRef{}, false
+	}
+	inner, err := l.load(ref.pkg)
+	if err != nil {
+		return typeRef{}, false
+	}
+	_, ok := inner.structs[ref.name]
+	return ref, ok
+}
+
+func (l *loader) load(name string) (*pkg, error) {
+	if p, ok := l.pkgs[name]; ok {
+		return p, nil
+	}
+	for _, base := range packageDirs {
+		dir := filepath.Join(moduleRoot, base, name)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		p := &pkg{
+			importPath:  modulePath + "/" + base + "/" + name,
+			dir:         dir,
+			structs:     make(map[string]*ast.StructType),
+			fileImports: make(map[string]map[string]string),
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(l.fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil, err
+			}
+			imports := make(map[string]string)
+			for _, imp := range f.Imports {
+				ip, _ := strconv.Unquote(imp.Path.Value)
+				local := filepath.Base(ip)
+				if imp.Name != nil {
+					local = imp.Name.Name
+				}
+				imports[local] = ip
+			}
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						p.structs[ts.Name.Name] = st
+						p.fileImports[ts.Name.Name] = imports
+					}
+				}
+			}
+		}
+		l.pkgs[name] = p
+		return p, nil
+	}
+	return nil, fmt.Errorf("package %s not found under %v", name, packageDirs)
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	}
+	return ""
+}
+
+// qualify renders a field type so types from different packages compare
+// correctly: local named types get their package's name.
+func qualify(pkgName string, expr ast.Expr) string {
+	s := exprString(expr)
+	if id, ok := expr.(*ast.Ident); ok && ast.IsExported(id.Name) {
+		return pkgName + "." + s
+	}
+	return s
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func writeFunc(buf *bytes.Buffer, m mapping, assigns []assign) {
+	fmt.Fprintf(buf, "\n// %s maps %s to %s.", m.fn, m.src, m.dst)
+	if len(m.ignored) > 0 {
+		fmt.Fprintf(buf, " The caller sets %s.", strings.Join(m.ignored, ", "))
+	}
+	fmt.Fprintf(buf, "\nfunc %s(req *%s) *%s {\n\tout := new(%s)\n", m.fn, m.src, m.dst, m.dst)
+	for _, a := range assigns {
+		fmt.Fprintf(buf, "\tout.%s = req.%s\n", a.dst, a.src)
+	}
+	buf.WriteString("\treturn out\n}\n")
+}
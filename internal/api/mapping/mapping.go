@@ -0,0 +1,23 @@
+// Package mapping converts API request DTOs to use case inputs. The
+// conversions are generated from the directives below, one per line:
+//
+//	//mapping:<Func> <pkg>.<Request> <pkg>.<Input> [-<Input field path>...]
+//
+// Request fields map to the input field of the same name, or to the path in
+// their `map:"Terms.Privacy"` tag; `map:"-"` skips one. Every request field
+// must land in the input and every input field must be set, unless listed
+// with '-' as the handler's job, so a field added on one side only fails
+// go generate instead of being silently dropped.
+package mapping
+
+//go:generate go run ./internal/genmapping
+
+//mapping:SignUpInput auth.SignUpRequest dto.SignUpInput -Client -SkipWelcomeEmail -Bot
+//mapping:UpgradeInput me.UpgradeRequest dto.SignUpInput -Client -SkipWelcomeEmail -Bot
+//mapping:SignInInput auth.SignInRequest dto.SignInInput -Phone -Client
+//mapping:RotatePasswordInput auth.RotatePasswordRequest dto.RotatePasswordInput -SignInInput.Phone -SignInInput.Client
+//mapping:RefreshTokensInput auth.RefreshRequest dto.RefreshTokensInput -Client
+//mapping:PhoneSignInInput auth.PhoneSignInRequest dto.PhoneSignInInput -Client
+//mapping:ResetPasswordInput auth.ResetPasswordRequest dto.ResetPasswordInput
+//mapping:UserFilter admin.UserFilterRequest dto.UserFilter
+//mapping:AuditLogFilter admin.AuditLogFilterRequest dto.AuditLogFilter
@@ -0,0 +1,163 @@
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/pkg/http/request"
+)
+
+// TestRoundTrip fills every field of each request with a value of its own,
+// sends it through the decoder its handler uses and maps it, then checks
+// that each value arrives, in the input field the request field maps to.
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		req  any
+		// query requests are read from the URL, the others from JSON.
+		query bool
+		mapTo func(req any) any
+	}{
+		{"SignUpInput", new(auth.SignUpRequest), false, func(r any) any { return SignUpInput(r.(*auth.SignUpRequest)) }},
+		{"UpgradeInput", new(me.UpgradeRequest), false, func(r any) any { return UpgradeInput(r.(*me.UpgradeRequest)) }},
+		{"SignInInput", new(auth.SignInRequest), false, func(r any) any { return SignInInput(r.(*auth.SignInRequest)) }},
+		{"RotatePasswordInput", new(auth.RotatePasswordRequest), false, func(r any) any { return RotatePasswordInput(r.(*auth.RotatePasswordRequest)) }},
+		{"RefreshTokensInput", new(auth.RefreshRequest), false, func(r any) any { return RefreshTokensInput(r.(*auth.RefreshRequest)) }},
+		{"PhoneSignInInput", new(auth.PhoneSignInRequest), false, func(r any) any { return PhoneSignInInput(r.(*auth.PhoneSignInRequest)) }},
+		{"ResetPasswordInput", new(auth.ResetPasswordRequest), false, func(r any) any { return ResetPasswordInput(r.(*auth.ResetPasswordRequest)) }},
+		{"UserFilter", new(admin.UserFilterRequest), true, func(r any) any { return UserFilter(r.(*admin.UserFilterRequest)) }},
+		{"AuditLogFilter", new(admin.AuditLogFilterRequest), true, func(r any) any { return AuditLogFilter(r.(*admin.AuditLogFilterRequest)) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fill(t, reflect.ValueOf(tc.req).Elem(), new(int))
+
+			var r *http.Request
+			if tc.query {
+				r = httptest.NewRequest(http.MethodGet, "/?"+queryValues(tc.req).Encode(), nil)
+			} else {
+				body, err := json.Marshal(tc.req)
+				if err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+				r = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			}
+			decoded := reflect.New(reflect.TypeOf(tc.req).Elem()).Interface()
+			decode := request.FromJSON
+			if tc.query {
+				decode = request.FromQuery
+			}
+			if err := decode(r, decoded); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			// want is the input field name each value belongs in.
+			want := make(map[string]string)
+			leaves(reflect.ValueOf(decoded).Elem(), func(f reflect.StructField, value string) {
+				target := f.Name
+				if path := f.Tag.Get("map"); path != "" {
+					target = path[strings.LastIndex(path, ".")+1:]
+				}
+				want[value] = target
+			})
+			sent := make(map[string]string)
+			leaves(reflect.ValueOf(tc.req).Elem(), func(f reflect.StructField, value string) { sent[value] = f.Name })
+			for value, field := range sent {
+				if _, ok := want[value]; !ok {
+					t.Errorf("%s was lost decoding the request", field)
+				}
+			}
+
+			got := make(map[string]string)
+			leaves(reflect.ValueOf(tc.mapTo(decoded)).Elem(), func(f reflect.StructField, value string) { got[value] = f.Name })
+			for value, target := range want {
+				if got[value] != target {
+					t.Errorf("%q belongs in %s, found in %q", value, target, got[value])
+				}
+			}
+		})
+	}
+}
+
+// fill sets every mapped field of v to a value no other field has, failing
+// on a kind it does not know, so a new one gets covered here too.
+func fill(t *testing.T, v reflect.Value, n *int) {
+	t.Helper()
+	next := func() int { *n++; return *n }
+	for i := range v.NumField() {
+		f, fv := v.Type().Field(i), v.Field(i)
+		if f.Tag.Get("map") == "-" {
+			continue
+		}
+		switch x := fv.Addr().Interface().(type) {
+		case *string:
+			*x = fmt.Sprintf("value-%d", next())
+		case *[]string:
+			*x = []string{fmt.Sprintf("value-%d", next()), fmt.Sprintf("value-%d", next())}
+		case *uuid.UUID:
+			*x = uuid.New()
+		case **time.Time:
+			at := time.Date(2026, 1, 1, 0, 0, next(), 0, time.UTC)
+			*x = &at
+		default:
+			if f.Anonymous && fv.Kind() == reflect.Struct {
+				fill(t, fv, n)
+				continue
+			}
+			t.Fatalf("fill: unsupported field %s %s", f.Name, f.Type)
+		}
+	}
+}
+
+// leaves calls fn with each value set in a mapped field of v, and the
+// field holding it, descending into nested structs.
+func leaves(v reflect.Value, fn func(f reflect.StructField, value string)) {
+	for i := range v.NumField() {
+		f, fv := v.Type().Field(i), v.Field(i)
+		if f.Tag.Get("map") == "-" {
+			continue
+		}
+		switch x := fv.Interface().(type) {
+		case string:
+			if x != "" {
+				fn(f, x)
+			}
+		case []string:
+			for _, e := range x {
+				fn(f, e)
+			}
+		case uuid.UUID:
+			if x != uuid.Nil {
+				fn(f, x.String())
+			}
+		case *time.Time:
+			if x != nil {
+				fn(f, x.Format(time.RFC3339Nano))
+			}
+		default:
+			if fv.Kind() == reflect.Struct {
+				leaves(fv, fn)
+			}
+		}
+	}
+}
+
+// queryValues encodes req by its query tags.
+func queryValues(req any) url.Values {
+	values := make(url.Values)
+	leaves(reflect.ValueOf(req).Elem(), func(f reflect.StructField, value string) {
+		values.Add(f.Tag.Get("query"), value)
+	})
+	return values
+}
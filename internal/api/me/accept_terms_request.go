@@ -0,0 +1,12 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type AcceptTermsRequest struct {
+	TermsVersion   string `json:"terms_version" validate:"required"`
+	PrivacyVersion string `json:"privacy_version" validate:"required"`
+}
+
+func (req *AcceptTermsRequest) Validate() error {
+	return validate.Struct(req)
+}
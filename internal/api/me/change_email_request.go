@@ -0,0 +1,12 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (req *ChangeEmailRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,13 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// DeleteAccountRequest re-authenticates the user; accounts without a usable
+// password, such as those provisioned through SAML, leave Password empty.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+func (req *DeleteAccountRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,28 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// LinkIdentityRequest links a phone number, which is then verified through
+// /me/phone/verify, or a password to the caller's account. CurrentPassword
+// re-authenticates the user; accounts without a usable password leave it
+// empty.
+type LinkIdentityRequest struct {
+	Type            string `json:"type" validate:"required,oneof=phone password"`
+	Phone           string `json:"phone,omitempty" validate:"required_if=Type phone,omitempty,e164_phone"`
+	Password        string `json:"password,omitempty" validate:"required_if=Type password,omitempty,strong_password"`
+	CurrentPassword string `json:"current_password,omitempty"`
+}
+
+func (req *LinkIdentityRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// UnlinkIdentityRequest re-authenticates the user; accounts without a
+// usable password leave Password empty.
+type UnlinkIdentityRequest struct {
+	Password string `json:"password"`
+}
+
+func (req *UnlinkIdentityRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,23 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type QuietHoursRequest struct {
+	Start string `json:"start" validate:"required,datetime=15:04"`
+	End   string `json:"end" validate:"required,datetime=15:04"`
+}
+
+// UpdateNotificationPreferencesRequest replaces the caller's preferences;
+// channels and categories left out keep their defaults.
+type UpdateNotificationPreferencesRequest struct {
+	Channels   map[string]bool `json:"channels" validate:"omitempty,dive,keys,oneof=email sms in_app,endkeys"`
+	Categories map[string]bool `json:"categories" validate:"omitempty,dive,keys,oneof=account security,endkeys"`
+	// QuietHours is optional; null turns quiet hours off.
+	QuietHours *QuietHoursRequest `json:"quiet_hours"`
+	TimeZone   string             `json:"time_zone" validate:"omitempty,timezone"`
+	Digest     string             `json:"digest" validate:"omitempty,oneof=off daily weekly"`
+}
+
+func (req *UpdateNotificationPreferencesRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,18 @@
+package me
+
+import (
+	"time"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+type CreatePersonalTokenRequest struct {
+	Name   string   `json:"name" validate:"required,max=100,no_control_chars"`
+	Scopes []string `json:"scopes" validate:"required,min=1,max=2,unique,dive,oneof=read write"`
+	// ExpiresAt is optional; tokens without one do not expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (req *CreatePersonalTokenRequest) Validate() error {
+	return validate.Struct(req)
+}
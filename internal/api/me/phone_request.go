@@ -0,0 +1,19 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+type ChangePhoneRequest struct {
+	Phone string `json:"phone" validate:"required,e164_phone"`
+}
+
+func (req *ChangePhoneRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric"`
+}
+
+func (req *VerifyPhoneRequest) Validate() error {
+	return validate.Struct(req)
+}
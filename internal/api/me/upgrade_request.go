@@ -0,0 +1,18 @@
+package me
+
+import "github.com/haidang666/go-app/pkg/validate"
+
+// UpgradeRequest carries the credentials that turn a guest into a full
+// account; the fields match sign-up.
+type UpgradeRequest struct {
+	Email          string `json:"email" validate:"required,email"`
+	Password       string `json:"password" validate:"required,strong_password"`
+	Username       string `json:"username,omitempty" validate:"omitempty,username"`
+	InviteCode     string `json:"invite_code,omitempty"`
+	TermsVersion   string `json:"terms_version,omitempty" map:"Terms.Terms"`
+	PrivacyVersion string `json:"privacy_version,omitempty" map:"Terms.Privacy"`
+}
+
+func (req *UpgradeRequest) Validate() error {
+	return validate.Struct(req)
+}
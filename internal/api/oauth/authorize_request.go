@@ -0,0 +1,29 @@
+package oauth
+
+import (
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+// AuthorizeRequest carries the parameters of an OpenID Connect
+// authentication request, forwarded by the front end once the user has
+// signed in and consented.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id" validate:"required"`
+	RedirectURI         string `json:"redirect_uri" validate:"required,url"`
+	Scope               string `json:"scope"`
+	State               string `json:"state" validate:"max=512"`
+	Nonce               string `json:"nonce" validate:"max=512"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+func (req *AuthorizeRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+func (req *AuthorizeRequest) Scopes() []string {
+	return strings.Fields(req.Scope)
+}
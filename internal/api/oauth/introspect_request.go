@@ -0,0 +1,22 @@
+package oauth
+
+import (
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+// IntrospectRequest is the form-encoded body of POST /oauth/introspect. The
+// client authenticates as at the token endpoint. TokenTypeHint is accepted
+// but not needed: every kind of token is recognized by itself.
+type IntrospectRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+func (req *IntrospectRequest) Validate() error {
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return ErrMissingClientCredentials
+	}
+	return validate.Var("token", req.Token, "required")
+}
@@ -0,0 +1,51 @@
+package oauth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+const (
+	GRANT_TYPE_CLIENT_CREDENTIALS = "client_credentials"
+	GRANT_TYPE_AUTHORIZATION_CODE = "authorization_code"
+)
+
+var (
+	ErrMissingClientCredentials = errors.New("client credentials are required")
+	ErrMissingCode              = errors.New("code, redirect_uri and code_verifier are required")
+)
+
+// TokenRequest is the form-encoded body of POST /oauth/token. The client may
+// authenticate with HTTP Basic instead of the client_id and client_secret
+// fields; public clients send client_id alone.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Scope        string `form:"scope"`
+	// Code, RedirectURI and CodeVerifier belong to the authorization_code
+	// grant.
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+func (req *TokenRequest) Validate() error {
+	if err := validate.Var("grant_type", req.GrantType, "required"); err != nil {
+		return err
+	}
+	if req.ClientID == "" {
+		return ErrMissingClientCredentials
+	}
+	if req.GrantType == GRANT_TYPE_AUTHORIZATION_CODE &&
+		(req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "") {
+		return ErrMissingCode
+	}
+	return nil
+}
+
+func (req *TokenRequest) Scopes() []string {
+	return strings.Fields(req.Scope)
+}
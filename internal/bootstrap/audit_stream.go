@@ -0,0 +1,85 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/pkg/retry"
+	"github.com/haidang666/go-app/pkg/siem"
+)
+
+// AuditStreamConfig streams the audit events to a SIEM as they are
+// stored, in addition to the audit log: "none", "syslog" (RFC 5424 to
+// SyslogAddress over SyslogNetwork udp, tcp or tls), "http" (JSON lines
+// posted to HTTPUrl) or "kafka" (through a Kafka REST Proxy). Events are
+// sent in batches of BatchSize, at least every FlushInterval; a failed
+// batch is tried MaxAttempts times in all, and once Buffer events wait, new
+// ones are dropped.
+type AuditStreamConfig struct {
+	Sink          string        `split_words:"true" default:"none"`
+	Buffer        int           `split_words:"true" default:"10000"`
+	BatchSize     int           `split_words:"true" default:"100"`
+	FlushInterval time.Duration `split_words:"true" default:"1s"`
+	Timeout       time.Duration `split_words:"true" default:"5s"`
+	MaxAttempts   int           `split_words:"true" default:"5"`
+	SyslogNetwork string        `split_words:"true" default:"tcp"`
+	SyslogAddress string        `split_words:"true"`
+	// HTTPUrl and KafkaRESTUrl are spelled for split_words to read
+	// AUDIT_STREAM_HTTP_URL and AUDIT_STREAM_KAFKA_REST_URL.
+	HTTPUrl      string            `split_words:"true"`
+	HTTPHeaders  map[string]string `split_words:"true" secret:"true"`
+	KafkaRESTUrl string            `split_words:"true"`
+	KafkaTopic   string            `split_words:"true" default:"audit-events"`
+}
+
+var auditStreamConfig = config.RegisterSection[AuditStreamConfig]("AUDIT_STREAM")
+
+// ProvideAuditStream streams the audit events to the SIEM selected by
+// AUDIT_STREAM_SINK, or returns nil when it is "none"
+func ProvideAuditStream(cfg *config.Config) (*siem.Streamer, error) {
+	stream := auditStreamConfig.From(cfg)
+	var sink siem.Sink
+	switch stream.Sink {
+	case "none":
+		return nil, nil
+	case "syslog":
+		s, err := siem.NewSyslogSink(siem.SyslogSinkArgs{
+			Network: stream.SyslogNetwork,
+			Address: stream.SyslogAddress,
+			AppName: cfg.Log.OTLPServiceName,
+			MsgID:   "audit",
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case "http":
+		if stream.HTTPUrl == "" {
+			return nil, fmt.Errorf("AUDIT_STREAM_HTTP_URL must be set for the http sink")
+		}
+		sink = siem.NewHTTPSink(siem.HTTPSinkArgs{URL: stream.HTTPUrl, Headers: stream.HTTPHeaders})
+	case "kafka":
+		s, err := siem.NewKafkaSink(siem.KafkaSinkArgs{
+			RESTURL: stream.KafkaRESTUrl,
+			Topic:   stream.KafkaTopic,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	default:
+		return nil, fmt.Errorf("AUDIT_STREAM_SINK must be none, syslog, http or kafka, got %q", stream.Sink)
+	}
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = stream.MaxAttempts
+	policy.MaxElapsed = 0
+	return siem.NewStreamer(siem.StreamerArgs{
+		Sink:          sink,
+		Buffer:        stream.Buffer,
+		BatchSize:     stream.BatchSize,
+		FlushInterval: stream.FlushInterval,
+		SendTimeout:   stream.Timeout,
+		Retry:         policy,
+	}), nil
+}
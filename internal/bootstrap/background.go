@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/safe"
+)
+
+type backgroundTask struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// background runs the modules' background tasks on every instance, for as
+// long as it serves, where the leader's tasks run on one instance only.
+type background struct {
+	tasks []backgroundTask
+}
+
+func (b *background) add(name string, run func(ctx context.Context)) {
+	b.tasks = append(b.tasks, backgroundTask{name: name, run: run})
+}
+
+// Run starts the tasks and waits for them to return once ctx is done. A task
+// that returns early or panics is logged and not restarted.
+func (b *background) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range b.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := safe.Call(ctx, "background."+t.name, func() error {
+				t.run(ctx)
+				return nil
+			}, "task", t.name)
+			if err == nil && ctx.Err() == nil {
+				logger.L().Errorw("background task stopped", "task", t.name)
+			}
+		}()
+	}
+	wg.Wait()
+}
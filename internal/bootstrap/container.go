@@ -1,22 +1,112 @@
 package bootstrap
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/pkg/apperr"
+	"github.com/haidang666/go-app/pkg/drain"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/fixtures"
+	"github.com/haidang666/go-app/pkg/leader"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/otlplog"
+	"github.com/haidang666/go-app/pkg/siem"
+	"github.com/haidang666/go-app/pkg/trace"
+)
+
+// The components reported to the lifecycle registry.
+const (
+	COMPONENT_DEPENDENCIES    = "dependencies"
+	COMPONENT_HTTP            = "http"
+	COMPONENT_OPS_HTTP        = "ops_http"
+	COMPONENT_EVENT_BUS       = "event_bus"
+	COMPONENT_LEADER_ELECTION = "leader_election"
 )
 
 type Container struct {
-	Status int
-	Router *chi.Mux
+	// Lifecycle holds the instance status and the health of its components.
+	Lifecycle *lifecycle.Registry
+	Router    *chi.Mux
+	// OpsRouter is served on APP_OPS_ADDR; nil when that is unset.
+	OpsRouter *chi.Mux
+	Elector   *leader.Elector
+	Drainer   *drain.Drainer
+	// PreStop fails readiness for APP_SHUTDOWN_DELAY, from SIGTERM or a
+	// preStop hook before it.
+	PreStop  *drain.PreStop
+	EventBus *eventbus.Bus
+	// Fixtures loads fixture files into the repositories.
+	Fixtures *fixtures.Loader
+	// Metrics is the push backend to flush on shutdown, if any.
+	Metrics metrics.Backend
+	// LogExporter is flushed last on shutdown; nil unless LOG_OTLP_ENDPOINT
+	// is set.
+	LogExporter *otlplog.Exporter
+	// Analytics is flushed on shutdown when it buffers events.
+	Analytics contract.AnalyticsTracker
+	// AuditStream is flushed on shutdown; nil unless AUDIT_STREAM_SINK is
+	// set.
+	AuditStream *siem.Streamer
+	warmer      *warmer
+	// background runs the modules' tasks that every instance runs.
+	background *background
+	doctor     *Doctor
 }
 
 // CreateServerContainer initializes the application container using Wire dependency injection
-func CreateServerContainer() (*Container, error) {
-	return InitializeContainer()
+func CreateServerContainer(cfg *config.Config) (*Container, error) {
+	logger.ConfigureSampling(cfg.Log.SampleFirst, cfg.Log.SampleInterval)
+	apperr.CaptureStacks(cfg.App.Env != "production")
+	if err := configureTracing(cfg.Trace); err != nil {
+		return nil, err
+	}
+	c, err := InitializeContainer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Seed.Fixtures) > 0 {
+		if cfg.App.Env == "production" {
+			return nil, errors.New("SEED_FIXTURES must not be used in production")
+		}
+		if err := seedFixtures(context.Background(), c.Fixtures, cfg.Seed.Fixtures); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func configureTracing(cfg config.TraceConfig) error {
+	switch cfg.Exporter {
+	case "none":
+		trace.Configure(nil, 0)
+	case "log":
+		trace.Configure(trace.NewLogExporter(logger.L()), cfg.SampleRatio)
+	default:
+		return fmt.Errorf("TRACE_EXPORTER must be none or log, got %q", cfg.Exporter)
+	}
+	return nil
+}
+
+func (c *Container) Status() lifecycle.Status {
+	return c.Lifecycle.Status()
+}
+
+// RunElector runs leader election until ctx is done, reporting it as a
+// component.
+func (c *Container) RunElector(ctx context.Context) {
+	c.Lifecycle.Set(COMPONENT_LEADER_ELECTION, lifecycle.COMPONENT_UP, "")
+	defer c.Lifecycle.Set(COMPONENT_LEADER_ELECTION, lifecycle.COMPONENT_STOPPED, "")
+	c.Elector.Run(ctx)
 }
 
 func (c *Container) Close() {
-	c.Status = 0
+	c.Lifecycle.Stopping()
 	fmt.Println("Container closed")
 }
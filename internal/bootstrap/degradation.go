@@ -0,0 +1,122 @@
+package bootstrap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/health"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/resilience"
+	"github.com/haidang666/go-app/pkg/resp"
+)
+
+// The features backed by Redis, as named in DEGRADATION_FAIL_MODES.
+const (
+	FEATURE_QUOTA      = "quota"
+	FEATURE_SESSIONS   = "sessions"
+	FEATURE_DEDUPE     = "dedupe"
+	FEATURE_USER_CACHE = "user_cache"
+	// FEATURE_PRESENCE has no fail mode: presence is best effort and
+	// connections carry on without it.
+	FEATURE_PRESENCE = "presence"
+)
+
+const (
+	FAIL_OPEN   = "open"
+	FAIL_CLOSED = "closed"
+)
+
+// DegradationConfig sets what each Redis-backed feature does while Redis is
+// unreachable or its circuit is open, "open" carrying on without it and
+// "closed" refusing what needs it, e.g.
+// DEGRADATION_FAIL_MODES=quota:open,sessions:closed. The features are quota
+// (requests pass unmetered, or get 503), sessions (the revocation check of
+// access tokens is skipped, or requests get 503), dedupe (webhooks are
+// processed without duplicate detection, or fail for the sender to retry)
+// and user_cache (cached users are served though invalidations may be
+// missed, or the cache is bypassed). The circuits use the RESILIENCE_*
+// settings.
+type DegradationConfig struct {
+	FailModes map[string]string `split_words:"true" default:"quota:open,sessions:closed,dedupe:closed,user_cache:open" secret:"false"`
+}
+
+var degradationConfig = config.RegisterSection[DegradationConfig]("DEGRADATION")
+
+// FailModes is the fail mode of every Redis-backed feature.
+type FailModes map[string]string
+
+// Open reports whether feature carries on without Redis.
+func (m FailModes) Open(feature string) bool {
+	return m[feature] == FAIL_OPEN
+}
+
+// ProvideFailModes provides the fail modes of DEGRADATION_FAIL_MODES; a
+// feature left out fails closed.
+func ProvideFailModes(cfg *config.Config) (FailModes, error) {
+	modes := FailModes{
+		FEATURE_QUOTA:      FAIL_CLOSED,
+		FEATURE_SESSIONS:   FAIL_CLOSED,
+		FEATURE_DEDUPE:     FAIL_CLOSED,
+		FEATURE_USER_CACHE: FAIL_CLOSED,
+	}
+	for feature, mode := range degradationConfig.From(cfg).FailModes {
+		if _, ok := modes[feature]; !ok {
+			return nil, fmt.Errorf("DEGRADATION_FAIL_MODES: unknown feature %q", feature)
+		}
+		if mode != FAIL_OPEN && mode != FAIL_CLOSED {
+			return nil, fmt.Errorf("DEGRADATION_FAIL_MODES: %s must be open or closed, got %q", feature, mode)
+		}
+		modes[feature] = mode
+	}
+	return modes, nil
+}
+
+// redisClient returns a client of the Redis at addr whose commands for
+// feature go through the "redis.<feature>" policy. Redis policies are not
+// critical: the fail modes decide what an outage costs, not readiness.
+func redisClient(cfg *config.Config, registry *resilience.Registry, feature string, args resp.ClientArgs) *infrastructure.ResilientRedisClient {
+	policy := registry.Policy("redis."+feature, resilience.PolicyArgs{
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenTimeout:      cfg.Resilience.OpenTimeout,
+		MaxConcurrent:    cfg.Resilience.MaxConcurrent,
+		MaxWait:          cfg.Resilience.MaxWait,
+		IsFailure:        infrastructure.IsRedisFailure,
+	})
+	return infrastructure.NewResilientRedisClient(resp.NewClient(args), policy)
+}
+
+// degradations reports the features running on their fail mode: those
+// whose Redis circuit is not closed, and the user cache while its
+// invalidation subscription is down.
+func degradations(registry *resilience.Registry, modes FailModes, relay *infrastructure.UserCacheRelay) []health.Degradation {
+	statuses, _ := registry.Statuses()
+	seen := make(map[string]bool)
+	var out []health.Degradation
+	for _, s := range statuses {
+		feature, ok := strings.CutPrefix(s.Name, "redis.")
+		if !ok || s.State == resilience.STATE_CLOSED.String() {
+			continue
+		}
+		seen[feature] = true
+		mode, ok := modes[feature]
+		if !ok {
+			mode = FAIL_OPEN
+		}
+		out = append(out, health.Degradation{
+			Feature:  feature,
+			FailMode: mode,
+			Reason:   "redis circuit " + s.State,
+		})
+	}
+	if relay != nil && !relay.Connected() && !seen[FEATURE_USER_CACHE] {
+		out = append(out, health.Degradation{
+			Feature:  FEATURE_USER_CACHE,
+			FailMode: modes[FEATURE_USER_CACHE],
+			Reason:   "invalidation subscription down",
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Feature < out[j].Feature })
+	return out
+}
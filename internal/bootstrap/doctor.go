@@ -0,0 +1,197 @@
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/doctor"
+	"github.com/haidang666/go-app/pkg/jwt"
+	"github.com/haidang666/go-app/pkg/resp"
+)
+
+type DoctorArgs struct {
+	// EmailTo receives a test email, e.g. a sink mailbox; empty skips it.
+	EmailTo string
+	// Timeout bounds each check.
+	Timeout time.Duration
+}
+
+// Doctor checks what the configuration alone cannot: that the dependencies
+// answer, the tables exist, tokens round-trip and email goes out.
+type Doctor struct {
+	cfg       *config.Config
+	jwtClient *jwt.Client
+	transport contract.MailTransport
+}
+
+// ProvideDoctor provides the self-test run by the doctor command
+func ProvideDoctor(cfg *config.Config, jwtClient *jwt.Client, transport contract.MailTransport) *Doctor {
+	return &Doctor{cfg: cfg, jwtClient: jwtClient, transport: transport}
+}
+
+// RunDoctor loads the configuration and builds the container, which
+// validates it, then runs the container's self-test, writing the report to
+// w. It reports whether every check passed.
+func RunDoctor(ctx context.Context, w io.Writer, args DoctorArgs) bool {
+	report := doctor.NewReport(w, args.Timeout)
+	var c *Container
+	configured := report.Run(ctx, doctor.Check{Name: "config", Run: func(context.Context) (string, error) {
+		cfg, err := config.Load()
+		if err != nil {
+			return "", err
+		}
+		if c, err = InitializeContainer(cfg); err != nil {
+			return "", err
+		}
+		return "APP_ENV=" + cfg.App.Env, nil
+	}})
+	if configured {
+		report.Run(ctx, c.doctor.checks(args.EmailTo)...)
+	}
+	return report.Summary()
+}
+
+func (d *Doctor) checks(emailTo string) []doctor.Check {
+	var checks []doctor.Check
+	for _, r := range d.redisDependencies() {
+		checks = append(checks, doctor.Check{Name: "redis." + r.feature, Run: r.ping})
+	}
+	for _, t := range d.tables() {
+		checks = append(checks, doctor.Check{Name: "postgres." + t.store, Run: t.check})
+	}
+	checks = append(checks,
+		doctor.Check{Name: "jwt", Run: d.checkJWT},
+		doctor.Check{Name: "email", Run: func(ctx context.Context) (string, error) {
+			return d.sendTestEmail(ctx, emailTo)
+		}},
+	)
+	return checks
+}
+
+type redisDependency struct {
+	feature string
+	args    resp.ClientArgs
+}
+
+// redisDependencies are the Redis servers of the features configured to
+// use one.
+func (d *Doctor) redisDependencies() []redisDependency {
+	cfg := d.cfg
+	var deps []redisDependency
+	if cfg.Quota.Backend == "redis" {
+		deps = append(deps, redisDependency{FEATURE_QUOTA, resp.ClientArgs{Addr: cfg.Quota.RedisAddr, Password: cfg.Quota.RedisPassword, DB: cfg.Quota.RedisDB}})
+	}
+	if cfg.Sessions.Backend == "redis" {
+		deps = append(deps, redisDependency{FEATURE_SESSIONS, resp.ClientArgs{Addr: cfg.Sessions.RedisAddr, Password: cfg.Sessions.RedisPassword, DB: cfg.Sessions.RedisDB}})
+	}
+	if cfg.Dedupe.Backend == "redis" {
+		deps = append(deps, redisDependency{FEATURE_DEDUPE, resp.ClientArgs{Addr: cfg.Dedupe.RedisAddr, Password: cfg.Dedupe.RedisPassword, DB: cfg.Dedupe.RedisDB}})
+	}
+	if cfg.UserCache.Enabled && cfg.UserCache.Invalidation == "redis" {
+		deps = append(deps, redisDependency{FEATURE_USER_CACHE, resp.ClientArgs{Addr: cfg.UserCache.RedisAddr, Password: cfg.UserCache.RedisPassword, DB: cfg.UserCache.RedisDB}})
+	}
+	if presence := presenceConfig.From(cfg); presence.Backend == "redis" {
+		deps = append(deps, redisDependency{FEATURE_PRESENCE, resp.ClientArgs{Addr: presence.RedisAddr, Password: presence.RedisPassword, DB: presence.RedisDB}})
+	}
+	return deps
+}
+
+func (r redisDependency) ping(ctx context.Context) (string, error) {
+	client := resp.NewClient(r.args)
+	defer client.Close()
+	reply, err := client.Do(ctx, "PING")
+	if err != nil {
+		return "", err
+	}
+	if reply != "PONG" {
+		return "", fmt.Errorf("unexpected reply to PING: %v", reply)
+	}
+	return r.args.Addr, nil
+}
+
+// storeTable is the table a Postgres-backed store needs in the DB_*
+// database. There is no migration tool: the tables are created from the
+// schemas documented on the stores, so checking they exist verifies the
+// database was migrated.
+type storeTable struct {
+	store  string
+	driver string
+	table  string
+	dsn    string
+}
+
+func (d *Doctor) tables() []storeTable {
+	cfg := d.cfg
+	dsn := postgresDSN(cfg)
+	var tables []storeTable
+	if cfg.Sessions.Backend == "postgres" {
+		tables = append(tables, storeTable{"sessions", cfg.Sessions.SQLDriver, "sessions", dsn})
+	}
+	if cfg.Jobs.Backend == "postgres" {
+		tables = append(tables, storeTable{"jobs", cfg.Jobs.SQLDriver, "scheduled_jobs", dsn})
+	}
+	if stats := statsConfig.From(cfg); stats.Backend == "postgres" {
+		tables = append(tables, storeTable{"stats", stats.SQLDriver, "stats_views", dsn})
+	}
+	if cfg.Analytics.Sink == "postgres" {
+		tables = append(tables, storeTable{"analytics", cfg.Analytics.SQLDriver, "analytics_events", dsn})
+	}
+	return tables
+}
+
+func (t storeTable) check(ctx context.Context) (string, error) {
+	if err := sqlDriverLinked(t.driver); err != nil {
+		return "", err
+	}
+	db, err := sql.Open(t.driver, t.dsn)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return "", err
+	}
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+t.table+" LIMIT 0")
+	if err != nil {
+		return "", fmt.Errorf("table %s: %w", t.table, err)
+	}
+	rows.Close()
+	return "table " + t.table + " present", nil
+}
+
+// checkJWT signs an access token and verifies it back, as the API does.
+func (d *Doctor) checkJWT(context.Context) (string, error) {
+	token, _, err := d.jwtClient.Issue(jwt.TOKEN_TYPE_ACCESS, jwt.Subject{UserID: uuid.NewString()})
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+	if _, err := d.jwtClient.VerifyType(token, jwt.TOKEN_TYPE_ACCESS); err != nil {
+		return "", fmt.Errorf("verify: %w", err)
+	}
+	return "issuer " + d.cfg.JWT.Issuer, nil
+}
+
+// sendTestEmail hands a message straight to the provider, bypassing the
+// mail queue, so a failure shows here rather than in a retry later.
+func (d *Doctor) sendTestEmail(ctx context.Context, to string) (string, error) {
+	if to == "" {
+		return "", doctor.Skip("no address given")
+	}
+	err := d.transport.Send(ctx, dto.EmailMessage{
+		ID:      uuid.New(),
+		To:      to,
+		Subject: "go-app doctor test email",
+		Body:    "This email was sent by the doctor command to check outgoing email. It needs no action.",
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sent to " + to, nil
+}
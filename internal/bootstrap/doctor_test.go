@@ -0,0 +1,15 @@
+package bootstrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStoreTableNamesAMissingDriver(t *testing.T) {
+	table := storeTable{store: "sessions", driver: "nodriver", table: "sessions", dsn: "postgres://db/app"}
+	_, err := table.check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `"nodriver"`) {
+		t.Errorf("check = %v, want an error naming the driver", err)
+	}
+}
@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+)
+
+// MaintenanceConfig puts the service in read-only mode at start-up, e.g.
+// during a primary failover; admins can also toggle it at runtime. Reason is
+// maintenance, failover or migration. AllowPaths lists the write endpoints
+// that stay open, e.g. MAINTENANCE_ALLOW_PATHS=/api/v1/auth/refresh.
+type MaintenanceConfig struct {
+	ReadOnly   bool          `split_words:"true" default:"false"`
+	Reason     string        `split_words:"true" default:"maintenance"`
+	RetryAfter time.Duration `split_words:"true" default:"30s"`
+	AllowPaths []string      `split_words:"true"`
+}
+
+var maintenanceConfig = config.RegisterSection[MaintenanceConfig]("MAINTENANCE")
+
+// ProvideMaintenanceRepository provides the maintenance mode, starting in
+// the configured one
+func ProvideMaintenanceRepository(cfg *config.Config) (contract.MaintenanceRepository, error) {
+	maintenance := maintenanceConfig.From(cfg)
+	mode := entity.MaintenanceMode{}
+	if maintenance.ReadOnly {
+		if !entity.IsMaintenanceReason(maintenance.Reason) {
+			return nil, fmt.Errorf("MAINTENANCE_REASON must be maintenance, failover or migration, got %q", maintenance.Reason)
+		}
+		now := time.Now().UTC()
+		mode = entity.MaintenanceMode{ReadOnly: true, Reason: maintenance.Reason, Since: &now}
+	}
+	return infrastructure.NewMaintenanceRepository(mode), nil
+}
@@ -0,0 +1,188 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/infrastructure/http/router"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/pkg/leader"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/safe"
+)
+
+var (
+	moduleTaskRunsTotal = metrics.NewCounter("module_task_runs_total",
+		"Runs of the modules' scheduled tasks by outcome (ok, error, panic).", "module", "task", "outcome")
+	moduleTaskDuration = metrics.NewHistogram("module_task_duration_seconds",
+		"Duration of the modules' scheduled task runs.", metrics.DefaultBuckets, "module", "task")
+)
+
+// Module is a feature area, such as auth, billing or mail, that contributes
+// its own health checks, leader and background tasks, scheduled job
+// handlers, warm-up hooks and routes, and keeps its metrics beside them, typically
+// refreshed by a task. ProvideModules lists the modules and the container
+// registers their contributions, so a module adds a check or a task in its
+// own file rather than in the central wiring.
+type Module interface {
+	Name() string
+	Register(r *ModuleRegistrar)
+}
+
+// ModuleRegistrar is what a module registers its contributions with. The
+// names it is given are prefixed with the module's, e.g. the "queue" check
+// of the mail module is the "mail_queue" component.
+type ModuleRegistrar struct {
+	module     string
+	lifecycle  *lifecycle.Registry
+	elector    *leader.Elector
+	policy     lifecycle.CheckPolicy
+	warmer     *warmer
+	background *background
+	scheduler  *jobs.Scheduler
+	tasks      *jobs.AdminTaskRegistry
+	routes     *router.RouteTable
+}
+
+// ModuleCheck probes a module dependency. Its ctx expires after one check
+// interval.
+type ModuleCheck func(ctx context.Context) (lifecycle.ComponentState, string)
+
+// Check adds a component probed in the background every interval, or every
+// READINESS_CHECK_INTERVAL when interval is 0, with the readiness
+// thresholds. Critical components take the instance out of rotation while
+// they are down.
+func (r *ModuleRegistrar) Check(name string, critical bool, interval time.Duration, check ModuleCheck) {
+	policy := r.policy
+	if interval > 0 {
+		policy.Interval = interval
+		policy.MaxAge = 0
+	}
+	timeout := policy.Interval
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	r.lifecycle.AddCachedCheck(r.name(name), critical, func() (lifecycle.ComponentState, string) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return check(ctx)
+	}, policy)
+}
+
+// Task adds a long-running task run on the leader only.
+func (r *ModuleRegistrar) Task(name string, task leader.Task) {
+	r.elector.Register(r.name(name), task)
+}
+
+// Every adds a task run on the leader every interval, starting right after
+// it takes the lead. Failed and panicking runs are logged and counted; the
+// next run is still made on time.
+func (r *ModuleRegistrar) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	module, task := r.module, r.name(name)
+	r.elector.Register(task, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			start := time.Now()
+			err := safe.Call(ctx, "module."+task, func() error { return fn(ctx) })
+			moduleTaskDuration.Observe(time.Since(start).Seconds(), module, name)
+			if safe.IsPanic(err) {
+				moduleTaskRunsTotal.Inc(module, name, "panic")
+			} else if err != nil && ctx.Err() == nil {
+				moduleTaskRunsTotal.Inc(module, name, "error")
+				logger.L().Errorw("module task failed", "task", task, "error", err)
+			} else if err == nil {
+				moduleTaskRunsTotal.Inc(module, name, "ok")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Background adds a long-running task run on every instance while it
+// serves, such as a subscription to another instance's updates. It must
+// return when ctx is done.
+func (r *ModuleRegistrar) Background(name string, task func(ctx context.Context)) {
+	r.background.add(r.name(name), task)
+}
+
+// WarmUp adds a hook run once after boot, before the instance reports
+// ready, to take a first-request cost such as opening connections off the
+// first requests. Hooks run on every instance, concurrently.
+func (r *ModuleRegistrar) WarmUp(name string, hook func(ctx context.Context) error) {
+	r.warmer.add(r.name(name), hook)
+}
+
+// Job handles the scheduled jobs of kind. Kinds are stored with the jobs, so
+// they are not prefixed: name them after the module, e.g.
+// "phone.clear_unverified".
+func (r *ModuleRegistrar) Job(kind string, handler contract.JobHandler) {
+	r.scheduler.Handle(kind, handler)
+}
+
+// AdminTask offers admins an operational task to trigger on demand with
+// POST /admin/tasks/{name}, such as a purge or a resend. It runs as a
+// scheduled job of kind "<module>.<name>", by run, on the leader.
+func (r *ModuleRegistrar) AdminTask(name string, task jobs.AdminTask, run contract.JobHandler) {
+	kind := r.module + "." + name
+	r.scheduler.Handle(kind, run)
+	r.tasks.Add(kind, task)
+}
+
+// Route serves handler on method and pattern, relative to /api/v1, behind
+// the middleware policy calls for, e.g.
+//
+//	r.Route(http.MethodPost, "/reports", h.Create, router.Policy{
+//		Auth:         router.AUTH_USER,
+//		Roles:        []string{entity.ROLE_ADMIN},
+//		Idempotent:   true,
+//		MaxBodyBytes: 64 << 10,
+//	})
+//
+// Route patterns are not prefixed with the module's name.
+func (r *ModuleRegistrar) Route(method, pattern string, handler http.HandlerFunc, policy router.Policy) {
+	r.routes.Add(router.Route{Method: method, Pattern: pattern, Handler: handler, Policy: policy})
+}
+
+func (r *ModuleRegistrar) name(name string) string {
+	if name == "" {
+		return r.module
+	}
+	return r.module + "_" + name
+}
+
+// registerModules registers the contributions of every module.
+func registerModules(
+	l *lifecycle.Registry,
+	elector *leader.Elector,
+	policy lifecycle.CheckPolicy,
+	w *warmer,
+	bg *background,
+	scheduler *jobs.Scheduler,
+	tasks *jobs.AdminTaskRegistry,
+	routes *router.RouteTable,
+	modules []Module,
+) {
+	for _, m := range modules {
+		m.Register(&ModuleRegistrar{
+			module:     m.Name(),
+			lifecycle:  l,
+			elector:    elector,
+			policy:     policy,
+			warmer:     w,
+			background: bg,
+			scheduler:  scheduler,
+			tasks:      tasks,
+			routes:     routes,
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	accountUseCase "github.com/haidang666/go-app/internal/domain/use_case/account"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/retry"
+)
+
+// AccountDeletionConfig sets the page the emailed restore links open and how
+// long a deleted account can be restored. The accounts whose recovery window
+// ended are purged every PurgeInterval.
+type AccountDeletionConfig struct {
+	LinkBaseURL    string        `split_words:"true" default:"http://localhost:3000/restore-account"`
+	RecoveryWindow time.Duration `split_words:"true" default:"720h"`
+	PurgeInterval  time.Duration `split_words:"true" default:"1h"`
+}
+
+var accountDeletionConfig = config.RegisterSection[AccountDeletionConfig]("ACCOUNT_DELETION")
+
+func (c *AccountDeletionConfig) Validate() error {
+	// The restore links are bearer tokens valid for the whole window.
+	if c.RecoveryWindow < time.Hour || c.RecoveryWindow > 90*24*time.Hour {
+		return fmt.Errorf("ACCOUNT_DELETION_RECOVERY_WINDOW must be between 1h0m0s and 2160h0m0s, got %s", c.RecoveryWindow)
+	}
+	return nil
+}
+
+// AccountModule lets support resend a user's email change confirmation with
+// the account.resend_email_change admin task. On the leader, it purges the
+// deleted accounts whose recovery window ended every purgeInterval.
+type AccountModule struct {
+	resend        *accountUseCase.ResendEmailChangeUseCase
+	purgeDeleted  *accountUseCase.PurgeDeletedAccountsUseCase
+	purgeInterval time.Duration
+}
+
+var _ Module = (*AccountModule)(nil)
+
+func NewAccountModule(
+	resend *accountUseCase.ResendEmailChangeUseCase,
+	purgeDeleted *accountUseCase.PurgeDeletedAccountsUseCase,
+	purgeInterval time.Duration,
+) *AccountModule {
+	return &AccountModule{resend: resend, purgeDeleted: purgeDeleted, purgeInterval: purgeInterval}
+}
+
+func (m *AccountModule) Name() string { return "account" }
+
+func (m *AccountModule) Register(r *ModuleRegistrar) {
+	r.AdminTask("resend_email_change", jobs.AdminTask{
+		Description: "Send the user's pending email change a new confirmation link.",
+		Params:      `{"user_id": "<uuid>"}`,
+		Validate: func(raw json.RawMessage) error {
+			_, err := parseUserIDParam(raw)
+			return err
+		},
+	}, m.resendEmailChange)
+	r.Every("purge_deleted", m.purgeInterval, m.purgeDeletedAccounts)
+}
+
+func (m *AccountModule) purgeDeletedAccounts(ctx context.Context) error {
+	n, err := m.purgeDeleted.Execute(ctx)
+	if n > 0 {
+		logger.L().Infow("purged deleted accounts", "accounts", n)
+	}
+	return err
+}
+
+func (m *AccountModule) resendEmailChange(ctx context.Context, raw json.RawMessage) error {
+	userID, err := parseUserIDParam(raw)
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	if _, err := m.resend.Execute(ctx, userID); err != nil {
+		if errors.Is(err, errs.ErrNoPendingEmailChange) {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+func parseUserIDParam(raw json.RawMessage) (uuid.UUID, error) {
+	var p struct {
+		UserID string `json:"user_id"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	if p.UserID == "" {
+		return uuid.Nil, fmt.Errorf("user_id is required")
+	}
+	id, err := uuid.Parse(p.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("user_id: %w", err)
+	}
+	return id, nil
+}
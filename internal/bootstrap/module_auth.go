@@ -0,0 +1,235 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	sessionUseCase "github.com/haidang666/go-app/internal/domain/use_case/session"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/retry"
+)
+
+// ExternalAuthConfig lets OAuth clients authenticate with access tokens
+// from other OpenID Connect issuers. Issuers is the allowlist; tokens of any
+// other issuer are refused, and none are accepted when it is empty. Each
+// issuer's signing keys are cached for their Cache-Control max-age, bounded
+// by MinRefreshInterval and RefreshInterval; MinRefreshInterval also bounds
+// how often a token signed with an unknown key triggers a refetch. Audience
+// is required with Issuers: without it, a token an issuer minted for any
+// other client would be accepted here.
+//
+// Users can call the API with those issuers' access tokens too. A subject
+// seen for the first time is linked to a local user by its verified email:
+// to a new account when ProvisionUsers is set, or to the existing one when
+// LinkByEmail is set, which trusts the issuers to verify emails. Both are
+// off by default; ProvisionUsers also requires Audience, so only tokens
+// minted for this service open accounts.
+type ExternalAuthConfig struct {
+	Issuers            []string      `split_words:"true"`
+	Audience           string        `split_words:"true"`
+	RefreshInterval    time.Duration `split_words:"true" default:"1h"`
+	MinRefreshInterval time.Duration `split_words:"true" default:"1m"`
+	Timeout            time.Duration `split_words:"true" default:"5s"`
+	ProvisionUsers     bool          `split_words:"true" default:"false"`
+	LinkByEmail        bool          `split_words:"true" default:"false"`
+}
+
+var externalAuthConfig = config.RegisterSection[ExternalAuthConfig]("EXTERNAL_AUTH")
+
+func (c *ExternalAuthConfig) Validate() error {
+	switch {
+	case len(c.Issuers) > 0 && c.Audience == "":
+		return fmt.Errorf("EXTERNAL_AUTH_AUDIENCE is required when EXTERNAL_AUTH_ISSUERS is set")
+	case c.ProvisionUsers && c.Audience == "":
+		return fmt.Errorf("EXTERNAL_AUTH_PROVISION_USERS requires EXTERNAL_AUTH_AUDIENCE")
+	}
+	return nil
+}
+
+// AuthConfig orders the authentication strategies of each route group, api
+// and admin: jwt (bearer access tokens, and external issuers' ones), api_key
+// (personal access tokens and service account keys), cookie (an access token
+// in the CookieName cookie, for a same-site web front end) and hmac
+// (internal services' requests signed with their secret). Strategies maps a
+// group to the ones it tries, first match wins, e.g.
+// AUTH_STRATEGIES=api:jwt|api_key|cookie,admin:jwt|hmac; groups without an
+// entry try jwt|api_key. InternalSecrets maps each internal service to its
+// secret, e.g. AUTH_INTERNAL_SECRETS=billing-worker:<hex>; their requests
+// carry InternalRoles and must be signed less than InternalMaxSkew apart
+// from the server's clock, see pkg/reqsign.
+type AuthConfig struct {
+	Strategies      map[string]string `split_words:"true" secret:"false"`
+	CookieName      string            `split_words:"true" default:"__Host-access_token"`
+	InternalSecrets map[string]string `split_words:"true" secret:"true"`
+	InternalRoles   []string          `split_words:"true"`
+	InternalMaxSkew time.Duration     `split_words:"true" default:"5m"`
+}
+
+var authConfig = config.RegisterSection[AuthConfig]("AUTH")
+
+// CredentialGateConfig caps the concurrent requests of sign-up and sign-in,
+// which hash a password each, apart from load shedding. Limits is keyed by
+// route, e.g. CREDENTIAL_GATE_LIMITS=sign_in:8,sign_up:4; a route without
+// an entry gets GOMAXPROCS and one with 0 is unlimited. Requests beyond a
+// limit wait up to QueueTimeout, MaxQueue of them per route, then get 503.
+type CredentialGateConfig struct {
+	Limits       map[string]int `split_words:"true" secret:"false"`
+	MaxQueue     int            `split_words:"true" default:"64"`
+	QueueTimeout time.Duration  `split_words:"true" default:"2s"`
+	RetryAfter   time.Duration  `split_words:"true" default:"1s"`
+}
+
+var credentialGateConfig = config.RegisterSection[CredentialGateConfig]("CREDENTIAL_GATE")
+
+// AuthProtectionConfig adapts the protection of sign-up and sign-in to
+// their failures: when, over Window, a route sees at least the minimum
+// failures of a scope making up at least FailureRatio of its attempts, the
+// requests of that scope are rate limited per address by RateLimit, e.g.
+// "5/1m;20/1h", and sign-ins must pass a CAPTCHA, as must sign-ups where
+// bot detection is on. The scopes are every request, each ASN, which needs
+// GEOIP_ASN_DB_PATH or an ISP database in GEOIP_DB_PATH, and each /24 or
+// /48 subnet; a minimum of 0 leaves a scope out. The protection lasts at
+// least Cooldown and ends once the failures fall below half a threshold.
+// Mode, auto, on or off, is the override admins can change at runtime.
+type AuthProtectionConfig struct {
+	Enabled            bool          `split_words:"true" default:"false"`
+	Mode               string        `split_words:"true" default:"auto"`
+	Window             time.Duration `split_words:"true" default:"5m"`
+	Cooldown           time.Duration `split_words:"true" default:"15m"`
+	FailureRatio       float64       `split_words:"true" default:"0.6"`
+	GlobalMinFailures  int           `split_words:"true" default:"500"`
+	ASNMinFailures     int           `split_words:"true" default:"100"`
+	SubnetMinFailures  int           `split_words:"true" default:"30"`
+	RateLimit          string        `split_words:"true" default:"5/1m;20/1h"`
+	MaxTrackedNetworks int           `split_words:"true" default:"10000"`
+}
+
+var authProtectionConfig = config.RegisterSection[AuthProtectionConfig]("AUTH_PROTECTION")
+
+// AuthModule reports the password hashing pool down while its queue stays
+// full, as sign-ups and sign-ins are then being rejected. At startup it
+// computes a few hashes and opens the session store's connections, so the
+// first sign-ins are not slower than the rest. Admins can purge the expired
+// sessions and password resets, and the deleted accounts past their recovery
+// window, with the auth.purge_expired_tokens task.
+type AuthModule struct {
+	hasher     contract.PasswordHasher
+	sessions   contract.SessionRepository
+	purge      *sessionUseCase.PurgeExpiredTokensUseCase
+	warmHashes int
+	warmConns  int
+}
+
+var _ Module = (*AuthModule)(nil)
+
+func NewAuthModule(
+	hasher contract.PasswordHasher,
+	sessions contract.SessionRepository,
+	purge *sessionUseCase.PurgeExpiredTokensUseCase,
+	warmHashes, warmConns int,
+) *AuthModule {
+	return &AuthModule{hasher: hasher, sessions: sessions, purge: purge, warmHashes: warmHashes, warmConns: warmConns}
+}
+
+// purgeParams are the params of the auth.purge_expired_tokens task.
+// OlderThan keeps the tokens expired for less than it, e.g. "24h"; all the
+// expired tokens are purged by default.
+type purgeParams struct {
+	OlderThan string `json:"older_than"`
+}
+
+func (p *purgeParams) parse(raw json.RawMessage) (time.Duration, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return 0, err
+	}
+	if p.OlderThan == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.OlderThan)
+	if err != nil {
+		return 0, fmt.Errorf("older_than: %w", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("older_than must not be negative")
+	}
+	return d, nil
+}
+
+func (m *AuthModule) Name() string { return "auth" }
+
+func (m *AuthModule) Register(r *ModuleRegistrar) {
+	r.AdminTask("purge_expired_tokens", jobs.AdminTask{
+		Description: "Delete the expired sessions, password resets and authorization codes, and the deleted accounts past their recovery window.",
+		Params:      `{"older_than": "24h"} (optional)`,
+		Validate: func(raw json.RawMessage) error {
+			_, err := new(purgeParams).parse(raw)
+			return err
+		},
+	}, m.purgeExpiredTokens)
+	if m.warmHashes > 0 {
+		r.WarmUp("password_hashing", m.warmHasher)
+	}
+	if store, ok := m.sessions.(interface {
+		WarmUp(ctx context.Context, n int) error
+	}); ok && m.warmConns > 0 {
+		r.WarmUp("sessions", func(ctx context.Context) error {
+			return store.WarmUp(ctx, m.warmConns)
+		})
+	}
+
+	pool, ok := m.hasher.(interface{ Backlog() (queued, capacity int) })
+	if !ok {
+		return
+	}
+	r.Check("password_hashing", false, 0, func(context.Context) (lifecycle.ComponentState, string) {
+		queued, capacity := pool.Backlog()
+		detail := fmt.Sprintf("%d/%d queued", queued, capacity)
+		if queued >= capacity {
+			return lifecycle.COMPONENT_DOWN, detail
+		}
+		return lifecycle.COMPONENT_UP, detail
+	})
+}
+
+func (m *AuthModule) purgeExpiredTokens(ctx context.Context, raw json.RawMessage) error {
+	olderThan, err := new(purgeParams).parse(raw)
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	res, err := m.purge.Execute(ctx, olderThan)
+	if err != nil {
+		return err
+	}
+	logger.L().Infow("purged expired tokens",
+		"sessions", res.Sessions,
+		"password_resets", res.PasswordResets,
+		"authorization_codes", res.AuthorizationCodes,
+		"deleted_accounts", res.DeletedAccounts,
+		"older_than", olderThan.String(),
+	)
+	return nil
+}
+
+// warmHasher hashes and checks a throwaway password, which also starts the
+// hashing pool's workers.
+func (m *AuthModule) warmHasher(ctx context.Context) error {
+	for range m.warmHashes {
+		hashed, err := m.hasher.Hash(ctx, "warm-up password")
+		if err != nil {
+			return err
+		}
+		if _, err := m.hasher.Compare(ctx, hashed, "warm-up password"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+)
+
+// BillingModule probes the billing provider, when it can be probed, so an
+// outage or a revoked key shows before checkouts fail. It is not critical:
+// the rest of the API works without billing.
+type BillingModule struct {
+	provider contract.BillingProvider
+	interval time.Duration
+}
+
+var _ Module = (*BillingModule)(nil)
+
+// NewBillingModule returns the billing module; provider is nil when billing
+// is disabled.
+func NewBillingModule(provider contract.BillingProvider, interval time.Duration) *BillingModule {
+	return &BillingModule{provider: provider, interval: interval}
+}
+
+func (m *BillingModule) Name() string { return "billing" }
+
+func (m *BillingModule) Register(r *ModuleRegistrar) {
+	pinger, ok := m.provider.(interface{ Ping(context.Context) error })
+	if !ok || m.interval <= 0 {
+		return
+	}
+	r.Check("provider", false, m.interval, func(ctx context.Context) (lifecycle.ComponentState, string) {
+		if err := pinger.Ping(ctx); err != nil {
+			return lifecycle.COMPONENT_DOWN, err.Error()
+		}
+		return lifecycle.COMPONENT_UP, ""
+	})
+}
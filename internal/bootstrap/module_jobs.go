@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	scheduledJobs = metrics.NewGauge("scheduled_jobs",
+		"Scheduled jobs by kind and state (pending, due, retrying, dead), as last counted by the leader.", "kind", "state")
+	scheduledJobsOldestDue = metrics.NewGauge("scheduled_jobs_oldest_due_seconds",
+		"How long the earliest due job of each kind has been waiting, as last counted by the leader.", "kind")
+)
+
+// JobsModule runs the scheduled jobs on the leader, by the handlers the
+// modules register, and counts the backlog for scheduled_jobs.
+type JobsModule struct {
+	scheduler *jobs.Scheduler
+	repo      contract.ScheduledJobRepository
+	// kinds are the kinds counted so far, reset to 0 once they have no jobs
+	// left rather than reporting their last count.
+	kinds map[string]struct{}
+}
+
+var _ Module = (*JobsModule)(nil)
+
+func NewJobsModule(scheduler *jobs.Scheduler, repo contract.ScheduledJobRepository) *JobsModule {
+	return &JobsModule{scheduler: scheduler, repo: repo, kinds: make(map[string]struct{})}
+}
+
+func (m *JobsModule) Name() string { return "jobs" }
+
+func (m *JobsModule) Register(r *ModuleRegistrar) {
+	r.Task("worker", m.scheduler.Run)
+	r.Every("metrics", time.Minute, m.countJobs)
+}
+
+func (m *JobsModule) countJobs(ctx context.Context) error {
+	now := time.Now().UTC()
+	stats, err := m.repo.Stats(ctx, now, now)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(stats))
+	for _, s := range stats {
+		seen[s.Kind] = struct{}{}
+		m.kinds[s.Kind] = struct{}{}
+		scheduledJobs.Set(float64(s.Pending), s.Kind, "pending")
+		scheduledJobs.Set(float64(s.Due), s.Kind, "due")
+		scheduledJobs.Set(float64(s.Retrying), s.Kind, "retrying")
+		scheduledJobs.Set(float64(s.Dead), s.Kind, "dead")
+		scheduledJobsOldestDue.Set(s.OldestDueSeconds, s.Kind)
+	}
+	for kind := range m.kinds {
+		if _, ok := seen[kind]; ok {
+			continue
+		}
+		for _, state := range []string{"pending", "due", "retrying", "dead"} {
+			scheduledJobs.Set(0, kind, state)
+		}
+		scheduledJobsOldestDue.Set(0, kind)
+	}
+	return nil
+}
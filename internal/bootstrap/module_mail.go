@@ -0,0 +1,76 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/infrastructure/mailer"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var mailQueueMessages = metrics.NewGauge("mail_queue_messages",
+	"Messages in the outgoing mail queue by status, as last counted by the leader.", "status")
+
+// mailQueueStatuses are the statuses counted into mail_queue_messages.
+var mailQueueStatuses = []string{
+	entity.OUTBOUND_EMAIL_QUEUED,
+	entity.OUTBOUND_EMAIL_SENT,
+	entity.OUTBOUND_EMAIL_FAILED,
+	entity.OUTBOUND_EMAIL_BOUNCED,
+	entity.OUTBOUND_EMAIL_COMPLAINED,
+	entity.OUTBOUND_EMAIL_SUPPRESSED,
+}
+
+// MailModule runs the queue worker on the leader, counts the queue for
+// mail_queue_messages, and reports the queue down while a message has been
+// due for longer than the allowed lag, which means delivery is stuck. It
+// renders every template once at startup.
+type MailModule struct {
+	worker    *mailer.QueueWorker
+	repo      contract.EmailQueueRepository
+	templates *mailer.TemplateRegistry
+	maxLag    time.Duration
+}
+
+var _ Module = (*MailModule)(nil)
+
+func NewMailModule(worker *mailer.QueueWorker, repo contract.EmailQueueRepository, templates *mailer.TemplateRegistry, maxLag time.Duration) *MailModule {
+	return &MailModule{worker: worker, repo: repo, templates: templates, maxLag: maxLag}
+}
+
+func (m *MailModule) Name() string { return "mail" }
+
+func (m *MailModule) Register(r *ModuleRegistrar) {
+	r.Task("queue", m.worker.Run)
+	r.Every("queue_metrics", time.Minute, m.countQueue)
+	r.WarmUp("templates", func(context.Context) error { return m.templates.WarmUp() })
+	if m.maxLag > 0 {
+		r.Check("queue", false, 0, m.checkLag)
+	}
+}
+
+func (m *MailModule) countQueue(ctx context.Context) error {
+	for _, status := range mailQueueStatuses {
+		_, total, err := m.repo.List(ctx, status, 1, 0)
+		if err != nil {
+			return err
+		}
+		mailQueueMessages.Set(float64(total), status)
+	}
+	return nil
+}
+
+func (m *MailModule) checkLag(ctx context.Context) (lifecycle.ComponentState, string) {
+	late, err := m.repo.Due(ctx, time.Now().Add(-m.maxLag), 1)
+	if err != nil {
+		return lifecycle.COMPONENT_DOWN, err.Error()
+	}
+	if len(late) > 0 {
+		lag := time.Since(late[0].NextAttemptAt).Round(time.Second)
+		return lifecycle.COMPONENT_DOWN, "oldest due message waiting " + lag.String()
+	}
+	return lifecycle.COMPONENT_UP, ""
+}
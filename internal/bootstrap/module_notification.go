@@ -0,0 +1,45 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	notificationUseCase "github.com/haidang666/go-app/internal/domain/use_case/notification"
+)
+
+// notificationDeliverInterval is how often held notifications are checked.
+// Quiet hours and digests are minute-granular, so this bounds how late they
+// go out.
+const notificationDeliverInterval = time.Minute
+
+// NotificationModule sends the notifications held back by users' quiet
+// hours and digests once they are due.
+type NotificationModule struct {
+	deliver *notificationUseCase.DeliverPendingNotificationsUseCase
+}
+
+var _ Module = (*NotificationModule)(nil)
+
+func NewNotificationModule(deliver *notificationUseCase.DeliverPendingNotificationsUseCase) *NotificationModule {
+	return &NotificationModule{deliver: deliver}
+}
+
+func (m *NotificationModule) Name() string { return "notification" }
+
+func (m *NotificationModule) Register(r *ModuleRegistrar) {
+	r.Every("deliver", notificationDeliverInterval, m.deliverDue)
+}
+
+// deliverDue drains the due notifications batch by batch.
+func (m *NotificationModule) deliverDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := m.deliver.Execute(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
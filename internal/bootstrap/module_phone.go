@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	phoneUseCase "github.com/haidang666/go-app/internal/domain/use_case/phone"
+)
+
+// PhoneModule clears the phone numbers left unverified past
+// PHONE_OTP_UNVERIFIED_TTL.
+type PhoneModule struct {
+	clearUnverified *phoneUseCase.ClearUnverifiedPhoneUseCase
+}
+
+var _ Module = (*PhoneModule)(nil)
+
+func NewPhoneModule(clearUnverified *phoneUseCase.ClearUnverifiedPhoneUseCase) *PhoneModule {
+	return &PhoneModule{clearUnverified: clearUnverified}
+}
+
+func (m *PhoneModule) Name() string { return "phone" }
+
+func (m *PhoneModule) Register(r *ModuleRegistrar) {
+	r.Job(phoneUseCase.JOB_CLEAR_UNVERIFIED_PHONE, m.clearUnverified.Execute)
+}
@@ -0,0 +1,59 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/users"
+	"github.com/haidang666/go-app/internal/infrastructure/http/router"
+	"github.com/haidang666/go-app/internal/infrastructure/realtime"
+)
+
+// PresenceConfig keeps who is online in "memory" (per instance) or "redis"
+// (shared by the instances). An open notification stream keeps its user
+// online for TTL past each heartbeat, sent every 20 seconds, so TTL bounds
+// how long a user whose instance died stays online.
+type PresenceConfig struct {
+	Backend       string        `split_words:"true" default:"memory"`
+	TTL           time.Duration `split_words:"true" default:"1m"`
+	RedisAddr     string        `split_words:"true" default:"localhost:6379"`
+	RedisPassword string        `split_words:"true" secret:"true"`
+	RedisDB       int           `split_words:"true" default:"0"`
+	RedisPrefix   string        `split_words:"true" default:"go-app:presence:"`
+}
+
+var presenceConfig = config.RegisterSection[PresenceConfig]("PRESENCE")
+
+func (c *PresenceConfig) Validate() error {
+	switch {
+	case c.Backend != "memory" && c.Backend != "redis":
+		return fmt.Errorf("PRESENCE_BACKEND must be memory or redis, got %q", c.Backend)
+	case c.TTL < 30*time.Second:
+		// Shorter would let users drop offline between heartbeats.
+		return fmt.Errorf("PRESENCE_TTL must be at least 30s, got %s", c.TTL)
+	}
+	return nil
+}
+
+// PresenceModule serves who is online and reports offline, on the leader,
+// the users whose connections stopped sending heartbeats without closing.
+type PresenceModule struct {
+	tracker *realtime.PresenceTracker
+	handler *users.UsersHandler
+	ttl     time.Duration
+}
+
+var _ Module = (*PresenceModule)(nil)
+
+func NewPresenceModule(tracker *realtime.PresenceTracker, handler *users.UsersHandler, ttl time.Duration) *PresenceModule {
+	return &PresenceModule{tracker: tracker, handler: handler, ttl: ttl}
+}
+
+func (m *PresenceModule) Name() string { return "presence" }
+
+func (m *PresenceModule) Register(r *ModuleRegistrar) {
+	r.Every("expire", m.ttl/4, m.tracker.Expire)
+	r.Route(http.MethodGet, "/users/{id}/presence", m.handler.Presence, router.Policy{Auth: router.AUTH_USER})
+}
@@ -0,0 +1,68 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/config"
+	statsUseCase "github.com/haidang666/go-app/internal/domain/use_case/stats"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+)
+
+// StatsConfig keeps the stats served by GET /admin/stats in "memory" (per
+// instance) or "postgres" (the stats_views table of the DB_* database,
+// shared by the instances). The leader refreshes them every
+// RefreshInterval; a request finding them older than MaxAge, or missing,
+// refreshes them itself. SignUpDays is how many days of daily sign-up
+// counts they hold.
+type StatsConfig struct {
+	Backend         string        `split_words:"true" default:"memory"`
+	SQLDriver       string        `split_words:"true" default:"pgx"`
+	RefreshInterval time.Duration `split_words:"true" default:"5m"`
+	MaxAge          time.Duration `split_words:"true" default:"30m"`
+	SignUpDays      int           `split_words:"true" default:"30"`
+}
+
+var statsConfig = config.RegisterSection[StatsConfig]("STATS")
+
+func (c *StatsConfig) Validate() error {
+	switch {
+	case c.Backend != "memory" && c.Backend != "postgres":
+		return fmt.Errorf("STATS_BACKEND must be memory or postgres, got %q", c.Backend)
+	case c.RefreshInterval <= 0:
+		return fmt.Errorf("STATS_REFRESH_INTERVAL must be positive")
+	case c.SignUpDays < 1:
+		return fmt.Errorf("STATS_SIGN_UP_DAYS must be at least 1")
+	}
+	return nil
+}
+
+// StatsModule refreshes the stats served by GET /admin/stats on the leader,
+// and on demand as the stats.refresh admin task.
+type StatsModule struct {
+	refresh  *statsUseCase.RefreshStatsUseCase
+	interval time.Duration
+}
+
+var _ Module = (*StatsModule)(nil)
+
+func NewStatsModule(refresh *statsUseCase.RefreshStatsUseCase, interval time.Duration) *StatsModule {
+	return &StatsModule{refresh: refresh, interval: interval}
+}
+
+func (m *StatsModule) Name() string { return "stats" }
+
+func (m *StatsModule) Register(r *ModuleRegistrar) {
+	r.Every("refresh", m.interval, func(ctx context.Context) error {
+		_, err := m.refresh.Execute(ctx)
+		return err
+	})
+	r.AdminTask("refresh", jobs.AdminTask{
+		Description: "Recompute the stats served by GET /admin/stats now rather than at the next interval.",
+	}, func(ctx context.Context, _ json.RawMessage) error {
+		_, err := m.refresh.Execute(ctx)
+		return err
+	})
+}
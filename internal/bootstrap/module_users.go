@@ -0,0 +1,26 @@
+package bootstrap
+
+import (
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+)
+
+// UsersModule keeps the user cache of every instance in step with the user
+// writes of the others, when USER_CACHE_INVALIDATION is "redis".
+type UsersModule struct {
+	// relay is nil unless the cache is shared through Redis.
+	relay *infrastructure.UserCacheRelay
+}
+
+var _ Module = (*UsersModule)(nil)
+
+func NewUsersModule(relay *infrastructure.UserCacheRelay) *UsersModule {
+	return &UsersModule{relay: relay}
+}
+
+func (m *UsersModule) Name() string { return "users" }
+
+func (m *UsersModule) Register(r *ModuleRegistrar) {
+	if m.relay != nil {
+		r.Background("cache_invalidation", m.relay.Run)
+	}
+}
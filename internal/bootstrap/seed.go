@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/fixtures"
+	"github.com/haidang666/go-app/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newFixtureLoader registers the tables fixture files can fill: "users",
+// whose records take a plaintext "password", and "subscriptions", which
+// take a "provider_id".
+func newFixtureLoader(userRepo contract.UserRepository, subscriptionRepo contract.SubscriptionRepository) *fixtures.Loader {
+	l := fixtures.NewLoader()
+	l.Register("users", fixtures.Table{
+		Insert: func(ctx context.Context, r fixtures.Record) (any, error) {
+			var f struct {
+				entity.User
+				Password string `json:"password"`
+			}
+			if err := r.Decode(&f); err != nil {
+				return nil, err
+			}
+			u := f.User
+			if f.Password != "" {
+				hashed, err := bcrypt.GenerateFromPassword([]byte(f.Password), bcrypt.DefaultCost)
+				if err != nil {
+					return nil, err
+				}
+				u.HashedPassword = string(hashed)
+			}
+			return userRepo.Create(ctx, &u)
+		},
+	})
+	l.Register("subscriptions", fixtures.Table{
+		Insert: func(ctx context.Context, r fixtures.Record) (any, error) {
+			var f struct {
+				entity.Subscription
+				ProviderID string `json:"provider_id"`
+			}
+			if err := r.Decode(&f); err != nil {
+				return nil, err
+			}
+			s := f.Subscription
+			s.ProviderID = f.ProviderID
+			if s.ProviderID == "" {
+				return nil, fmt.Errorf("provider_id is required")
+			}
+			now := time.Now()
+			s.SyncedAt, s.CreatedAt = now, now
+			return subscriptionRepo.Upsert(ctx, &s)
+		},
+	})
+	return l
+}
+
+// seedFixtures loads SEED_FIXTURES into the repositories at startup.
+func seedFixtures(ctx context.Context, l *fixtures.Loader, paths []string) error {
+	set, err := l.LoadFiles(ctx, paths...)
+	if err != nil {
+		return fmt.Errorf("seed fixtures: %w", err)
+	}
+	logger.L().Infow("seeded fixtures", "files", paths, "unique_suffix", set.Suffix)
+	return nil
+}
@@ -4,40 +4,310 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/haidang666/go-app/internal/config"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/listener"
 	"github.com/haidang666/go-app/pkg/logger"
 )
 
-func StartRestAPI(ctx context.Context, cfg *config.Config, router *chi.Mux) error {
+// shutdownFlushGrace is how long the queued events may still be flushed for
+// after the drain ran out of APP_SHUTDOWN_TIMEOUT.
+const shutdownFlushGrace = 250 * time.Millisecond
+
+// Server is what a named listener serves. *http.Server implements it; other
+// protocols, such as gRPC, plug in through an adapter whose Shutdown stops
+// gracefully.
+type Server interface {
+	Serve(ln net.Listener) error
+	// Shutdown stops accepting connections and waits for the in-flight
+	// requests until ctx is done.
+	Shutdown(ctx context.Context) error
+	Close() error
+}
+
+// Listener is one server of the instance, reported to the lifecycle
+// registry as the component Name.
+type Listener struct {
+	Name string
+	// Addr is in the format of APP_LISTEN; EnvVar names the setting it
+	// comes from, for errors.
+	Addr   string
+	EnvVar string
+	Server Server
+	// Ops listeners stay up until the others have drained, so probes and
+	// metrics cover the drain.
+	Ops bool
+}
+
+// ServerManager runs the listeners of the instance from one container and
+// stops them together: on ctx being done, on a SIGUSR2 handover to a new
+// process, or when one of them fails.
+type ServerManager struct {
+	cfg       *config.Config
+	c         *Container
+	listeners []Listener
+}
+
+func NewServerManager(cfg *config.Config, c *Container) *ServerManager {
+	return &ServerManager{cfg: cfg, c: c}
+}
+
+func (m *ServerManager) Add(l Listener) {
+	m.listeners = append(m.listeners, l)
+}
+
+// Serve runs the REST API, and the ops API when APP_OPS_ADDR is set, until
+// ctx is done.
+func Serve(ctx context.Context, cfg *config.Config, c *Container) error {
+	addr := cfg.App.Listen
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", cfg.App.Port)
+	}
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.App.Port),
-		Handler: router,
+		Handler:           c.Router,
+		ReadHeaderTimeout: cfg.App.ReadHeaderTimeout,
+		ReadTimeout:       cfg.App.ReadTimeout,
+		WriteTimeout:      cfg.App.WriteTimeout,
+		IdleTimeout:       cfg.App.IdleTimeout,
+		MaxHeaderBytes:    cfg.App.MaxHeaderBytes,
 	}
+	server.SetKeepAlivesEnabled(cfg.App.KeepAlives)
+	server.RegisterOnShutdown(c.Drainer.CloseStreams)
 
-	errCh := make(chan error, 1)
+	m := NewServerManager(cfg, c)
+	m.Add(Listener{Name: COMPONENT_HTTP, Addr: addr, EnvVar: "APP_LISTEN", Server: server})
+	if c.OpsRouter != nil {
+		m.Add(Listener{
+			Name:   COMPONENT_OPS_HTTP,
+			Addr:   cfg.App.OpsAddr,
+			EnvVar: "APP_OPS_ADDR",
+			Server: &http.Server{
+				Handler:           c.OpsRouter,
+				ReadHeaderTimeout: cfg.App.ReadHeaderTimeout,
+				IdleTimeout:       cfg.App.IdleTimeout,
+			},
+			Ops: true,
+		})
+	}
+	return m.Run(ctx)
+}
+
+// Run opens every listener, or none if one fails to open, and serves them
+// until they are stopped.
+func (m *ServerManager) Run(ctx context.Context) error {
+	lns := make([]net.Listener, 0, len(m.listeners))
+	for _, l := range m.listeners {
+		ln, err := listener.Listen(l.Addr, m.cfg.App.SocketMode)
+		if err != nil {
+			for _, opened := range lns {
+				opened.Close()
+			}
+			return fmt.Errorf("listen %s: %w", l.EnvVar, err)
+		}
+		lns = append(lns, ln)
+	}
+
+	errCh := make(chan error, len(m.listeners))
+	for i, l := range m.listeners {
+		ln := lns[i]
+		logger.L().Infow("listening", "listener", l.Name, "addr", ln.Addr().String())
+		// The listener already accepts connections, so it is up from the
+		// start.
+		m.c.Lifecycle.Set(l.Name, lifecycle.COMPONENT_UP, ln.Addr().String())
+		go func() {
+			if err := l.Server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				m.c.Lifecycle.Set(l.Name, lifecycle.COMPONENT_DOWN, err.Error())
+				errCh <- fmt.Errorf("%s: %w", l.Name, err)
+				return
+			}
+			m.c.Lifecycle.Set(l.Name, lifecycle.COMPONENT_STOPPED, "")
+		}()
+	}
+
+	// The listeners accept connections during the warm-up, so probes see the
+	// instance starting rather than refusing them.
 	go func() {
-		logger.L().Infof("listening on :%d", cfg.App.Port)
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			errCh <- err
+		m.c.warmer.Run(ctx, m.c.Lifecycle)
+		m.c.Lifecycle.Ready()
+		// Started by a graceful restart, the old process drains from here.
+		if err := listener.Ready(); err != nil {
+			logger.L().Errorw("report readiness to the restarting process", "error", err)
 		}
 	}()
+	go m.c.background.Run(ctx)
 
-	select {
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	// SIGUSR2 hands the listeners to a new instance of the binary, then
+	// drains this one once the new one is ready: in-place deploys without
+	// refused connections. Until then this one serves on, and keeps serving
+	// if the new one fails to get ready.
+	restart := make(chan os.Signal, 1)
+	signal.Notify(restart, syscall.SIGUSR2)
+	defer signal.Stop(restart)
+	restarted := make(chan *os.Process, 1)
+	restarting := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return m.shutdown("signal")
+		case <-restart:
+			if restarting {
+				logger.L().Warnw("graceful restart already in progress")
+				continue
+			}
+			restarting = true
+			go func() {
+				restartCtx, cancel := context.WithTimeout(ctx, m.cfg.App.RestartTimeout)
+				defer cancel()
+				p, err := listener.Restart(restartCtx)
+				if err != nil {
+					logger.L().Errorw("graceful restart failed, serving on", "error", err)
+				}
+				restarted <- p
+			}()
+		case p := <-restarted:
+			restarting = false
+			if p == nil {
+				continue
+			}
+			logger.L().Infow("new process is ready, draining", "pid", p.Pid)
+			p.Release()
+			return m.shutdown("restart")
+		case err := <-errCh:
+			m.closeAll()
+			return err
+		}
+	}
+}
 
-		logger.L().Info("shutting down server...")
-		if err := server.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("server shutdown: %w", err)
+// shutdown marks the instance stopping, which fails readiness, and waits
+// ShutdownDelay so load balancers stop sending traffic, unless a preStop
+// hook already has, then stops the listeners other than the ops ones,
+// waiting up to ShutdownTimeout for in-flight requests before closing
+// forcefully, and flushes the queued events. The ops listeners are closed
+// last. Every step is run even after one fails, and the outcome is logged
+// as a ShutdownReport and summarized in APP_TERMINATION_LOG; a
+// *DegradedShutdownError is returned when work was cut short.
+func (m *ServerManager) shutdown(reason string) error {
+	cfg, c := m.cfg, m.c
+	defer m.closeOps()
+
+	report := newShutdownReport(reason, c.Drainer.InFlight())
+	report.PreStop = c.PreStop.Started()
+	if cfg.App.ShutdownDelay > 0 {
+		report.step("delay", func() error { return c.PreStop.Run(context.Background()) })
+	} else {
+		c.PreStop.Run(context.Background())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+
+	logger.L().Infow("shutting down servers...", "in_flight", c.Drainer.InFlight())
+	drainStart := time.Now()
+	type stopped struct {
+		name string
+		d    time.Duration
+		err  error
+	}
+	stoppedCh := make(chan stopped, len(m.listeners))
+	stopping := 0
+	for _, l := range m.listeners {
+		if l.Ops {
+			continue
+		}
+		stopping++
+		go func() {
+			start := time.Now()
+			err := l.Server.Shutdown(shutdownCtx)
+			if err != nil {
+				l.Server.Close()
+			}
+			stoppedCh <- stopped{name: l.Name, d: time.Since(start), err: err}
+		}()
+	}
+	for range stopping {
+		s := <-stoppedCh
+		report.record(s.name, s.d, s.err)
+		if s.err != nil {
+			logger.L().Warnw("drain timeout exceeded, closing remaining connections",
+				"listener", s.name, "in_flight", c.Drainer.InFlight(), "timeout", cfg.App.ShutdownTimeout)
+		}
+	}
+	report.DrainDuration = time.Since(drainStart)
+
+	// Shutdown does not track hijacked connections; give their handlers the
+	// rest of the budget to return after CloseStreams.
+	report.step("streams", func() error { return c.Drainer.Wait(shutdownCtx) })
+	report.InFlightAtEnd = c.Drainer.InFlight()
+
+	// Deliver the events published by the requests that just finished. When
+	// the drain used the whole budget, the flushes still get a moment, so
+	// the events already queued are not all given up on.
+	flushCtx := shutdownCtx
+	if shutdownCtx.Err() != nil {
+		var cancel context.CancelFunc
+		flushCtx, cancel = context.WithTimeout(context.Background(), shutdownFlushGrace)
+		defer cancel()
+	}
+	report.EventsPending = c.EventBus.Pending()
+	report.step("event_bus", func() error {
+		if err := c.EventBus.Close(flushCtx); err != nil {
+			return err
 		}
+		c.Lifecycle.Set(COMPONENT_EVENT_BUS, lifecycle.COMPONENT_STOPPED, "")
 		return nil
-	case err := <-errCh:
-		return err
+	})
+	report.EventsUnflushed = c.EventBus.Pending()
+	if tracker, ok := c.Analytics.(interface {
+		Pending() int
+		Close(context.Context) error
+	}); ok {
+		report.AnalyticsEventsPending = tracker.Pending()
+		report.step("analytics", func() error { return tracker.Close(flushCtx) })
+		report.AnalyticsEventsUnflushed = tracker.Pending()
 	}
+	if c.AuditStream != nil {
+		report.AuditEventsPending = c.AuditStream.Pending()
+		report.step("audit_stream", func() error { return c.AuditStream.Close(flushCtx) })
+		report.AuditEventsUnflushed = c.AuditStream.Pending()
+	}
+	if c.Metrics != nil {
+		report.step("metrics", c.Metrics.Close)
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	report.log()
+	WriteTerminationLog(cfg.App.TerminationLog, report.Summary())
+	// Exported last, so the shutdown report is part of it.
+	if c.LogExporter != nil {
+		if err := c.LogExporter.Close(flushCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "flush log export: %v\n", err)
+		}
+	}
+	if report.Degraded() {
+		return &DegradedShutdownError{Report: report}
+	}
+	return nil
 }
 
+func (m *ServerManager) closeOps() {
+	for _, l := range m.listeners {
+		if l.Ops {
+			l.Server.Close()
+		}
+	}
+}
+
+func (m *ServerManager) closeAll() {
+	for _, l := range m.listeners {
+		l.Server.Close()
+	}
+}
@@ -0,0 +1,181 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// Exit codes of the server process, so deploy tooling can tell a clean
+// shutdown from one that cut work short. Failing to start or serve exits
+// with 1, as every fatal error does.
+const (
+	EXIT_CLEAN             = 0
+	EXIT_DEGRADED_SHUTDOWN = 3
+)
+
+// ShutdownStep is one stage of a shutdown, such as draining a listener or
+// flushing the event bus.
+type ShutdownStep struct {
+	Name     string
+	Duration time.Duration
+	// TimedOut is set when the step ran out of APP_SHUTDOWN_TIMEOUT.
+	TimedOut bool
+	Err      error
+}
+
+// ShutdownReport summarizes a shutdown. It is logged as one entry when the
+// shutdown is over.
+type ShutdownReport struct {
+	// Reason is what stopped the instance: "signal" or "restart".
+	Reason string
+	// PreStop is set when a preStop hook began the shutdown ahead of the
+	// signal, waiting its delay.
+	PreStop   bool
+	StartedAt time.Time
+	Duration  time.Duration
+	// DrainDuration is how long the listeners took to finish the in-flight
+	// requests.
+	DrainDuration time.Duration
+	// InFlightAtSignal and InFlightAtEnd count the requests being served
+	// when the shutdown started and those still running when it ended,
+	// which were cut off.
+	InFlightAtSignal int64
+	InFlightAtEnd    int64
+	// The events queued on the event bus, in the analytics tracker and in the
+	// audit stream when they were flushed, and those left undelivered.
+	EventsPending            int
+	EventsUnflushed          int
+	AnalyticsEventsPending   int
+	AnalyticsEventsUnflushed int
+	AuditEventsPending       int
+	AuditEventsUnflushed     int
+	Steps                    []ShutdownStep
+}
+
+func newShutdownReport(reason string, inFlight int64) *ShutdownReport {
+	return &ShutdownReport{Reason: reason, StartedAt: time.Now(), InFlightAtSignal: inFlight}
+}
+
+// step runs fn as the step name and records it.
+func (r *ShutdownReport) step(name string, fn func() error) {
+	start := time.Now()
+	err := fn()
+	r.record(name, time.Since(start), err)
+}
+
+func (r *ShutdownReport) record(name string, d time.Duration, err error) {
+	r.Steps = append(r.Steps, ShutdownStep{
+		Name:     name,
+		Duration: d,
+		TimedOut: errors.Is(err, context.DeadlineExceeded),
+		Err:      err,
+	})
+}
+
+// Degraded reports whether a step failed or timed out, or requests or
+// events were cut off.
+func (r *ShutdownReport) Degraded() bool {
+	return r.Err() != nil || r.InFlightAtEnd > 0 || r.EventsUnflushed > 0 || r.AnalyticsEventsUnflushed > 0
+}
+
+// Err joins the errors of the failed steps.
+func (r *ShutdownReport) Err() error {
+	var errs []error
+	for _, s := range r.Steps {
+		if s.Err != nil {
+			errs = append(errs, errors.New(s.Name+": "+s.Err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *ShutdownReport) log() {
+	steps := make([]string, len(r.Steps))
+	var timedOut, failed []string
+	for i, s := range r.Steps {
+		steps[i] = s.Name + "=" + s.Duration.Round(time.Millisecond).String()
+		switch {
+		case s.TimedOut:
+			timedOut = append(timedOut, s.Name)
+		case s.Err != nil:
+			failed = append(failed, s.Name)
+		}
+	}
+	outcome, log := "clean", logger.L().Infow
+	if r.Degraded() {
+		outcome, log = "degraded", logger.L().Warnw
+	}
+	log("shutdown report",
+		"outcome", outcome,
+		"reason", r.Reason,
+		"pre_stop", r.PreStop,
+		"duration", r.Duration.Round(time.Millisecond).String(),
+		"drain_duration", r.DrainDuration.Round(time.Millisecond).String(),
+		"in_flight_at_signal", r.InFlightAtSignal,
+		"in_flight_at_end", r.InFlightAtEnd,
+		"events_pending", r.EventsPending,
+		"events_unflushed", r.EventsUnflushed,
+		"analytics_events_pending", r.AnalyticsEventsPending,
+		"analytics_events_unflushed", r.AnalyticsEventsUnflushed,
+		"audit_events_pending", r.AuditEventsPending,
+		"audit_events_unflushed", r.AuditEventsUnflushed,
+		"steps", strings.Join(steps, " "),
+		"timed_out", timedOut,
+		"failed", failed,
+	)
+}
+
+// Summary is a few lines for people, as written to the termination log.
+func (r *ShutdownReport) Summary() string {
+	outcome := "clean"
+	if r.Degraded() {
+		outcome = "degraded"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s shutdown on %s", outcome, r.Reason)
+	if r.PreStop {
+		b.WriteString(" after a preStop hook")
+	}
+	fmt.Fprintf(&b, " in %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "requests: %d in flight at the signal, drained in %s, %d cut off\n",
+		r.InFlightAtSignal, r.DrainDuration.Round(time.Millisecond), r.InFlightAtEnd)
+	fmt.Fprintf(&b, "events unflushed: %d of %d bus, %d of %d analytics, %d of %d audit\n",
+		r.EventsUnflushed, r.EventsPending, r.AnalyticsEventsUnflushed, r.AnalyticsEventsPending,
+		r.AuditEventsUnflushed, r.AuditEventsPending)
+	b.WriteString("steps:")
+	for _, s := range r.Steps {
+		fmt.Fprintf(&b, " %s=%s", s.Name, s.Duration.Round(time.Millisecond))
+		switch {
+		case s.TimedOut:
+			b.WriteString("(timed out)")
+		case s.Err != nil:
+			b.WriteString("(failed)")
+		}
+	}
+	if err := r.Err(); err != nil {
+		b.WriteString("\nerrors: " + strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	return b.String()
+}
+
+// DegradedShutdownError is returned by Serve when the shutdown cut work
+// short; the process should exit with EXIT_DEGRADED_SHUTDOWN.
+type DegradedShutdownError struct {
+	Report *ShutdownReport
+}
+
+func (e *DegradedShutdownError) Error() string {
+	if err := e.Report.Err(); err != nil {
+		return "degraded shutdown: " + strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+	return "degraded shutdown: work was cut off"
+}
+
+func (e *DegradedShutdownError) Unwrap() error {
+	return e.Report.Err()
+}
@@ -0,0 +1,26 @@
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// terminationLogLimit is the most of a termination log Kubernetes reads.
+const terminationLogLimit = 4096
+
+// WriteTerminationLog replaces the file at path, e.g. /dev/termination-log,
+// with msg, which Kubernetes then shows as the container's last state in
+// kubectl describe pod. An empty path writes nothing. Failures go to
+// stderr, as the process is exiting.
+func WriteTerminationLog(path, msg string) {
+	if path == "" {
+		return
+	}
+	if len(msg) > terminationLogLimit {
+		msg = strings.ToValidUTF8(msg[:terminationLogLimit], "")
+	}
+	if err := os.WriteFile(path, []byte(msg), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "write termination log: %v\n", err)
+	}
+}
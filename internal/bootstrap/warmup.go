@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// COMPONENT_WARMUP reports the warm-up; it is not critical, as a failed hook
+// only makes the first requests slower.
+const COMPONENT_WARMUP = "warmup"
+
+var warmUpDuration = metrics.NewGauge("warmup_duration_seconds",
+	"How long each warm-up hook took at startup.", "hook")
+
+type warmUpHook struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// warmer runs the modules' warm-up hooks once after boot, so the first
+// requests after a deploy do not pay for cold pools and templates.
+type warmer struct {
+	hooks   []warmUpHook
+	timeout time.Duration
+	// readyAt is the earliest the instance reports ready.
+	readyAt time.Time
+}
+
+func newWarmer(timeout, readinessDelay time.Duration) *warmer {
+	return &warmer{timeout: timeout, readyAt: time.Now().Add(readinessDelay)}
+}
+
+func (w *warmer) add(name string, run func(ctx context.Context) error) {
+	w.hooks = append(w.hooks, warmUpHook{name: name, run: run})
+}
+
+// Run runs the hooks concurrently, within the timeout, then waits out the
+// readiness delay. Failed hooks are logged; they do not stop the instance
+// from becoming ready. It returns early when ctx is done.
+func (w *warmer) Run(ctx context.Context, l *lifecycle.Registry) {
+	start := time.Now()
+	hookCtx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed []string
+	for _, h := range w.hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hookStart := time.Now()
+			err := h.run(hookCtx)
+			warmUpDuration.Set(time.Since(hookStart).Seconds(), h.name)
+			if err != nil {
+				logger.L().Warnw("warm-up hook failed", "hook", h.name, "error", err)
+				mu.Lock()
+				failed = append(failed, h.name)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	detail := fmt.Sprintf("%d hooks in %s", len(w.hooks), time.Since(start).Round(time.Millisecond))
+	if len(failed) > 0 {
+		detail += ", failed: " + strings.Join(failed, ", ")
+	}
+	logger.L().Infow("warm-up done", "hooks", len(w.hooks), "failed", failed, "duration", time.Since(start))
+	l.Set(COMPONENT_WARMUP, lifecycle.COMPONENT_UP, detail)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Until(w.readyAt)):
+	}
+}
@@ -4,59 +4,3283 @@
 package bootstrap
 
 import (
-	"github.com/go-chi/chi/v5"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/google/wire"
+	"github.com/haidang666/go-app/internal/config"
 	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/policy"
+	accountUseCase "github.com/haidang666/go-app/internal/domain/use_case/account"
+	activityUseCase "github.com/haidang666/go-app/internal/domain/use_case/activity"
+	adminUseCase "github.com/haidang666/go-app/internal/domain/use_case/admin"
 	authUseCase "github.com/haidang666/go-app/internal/domain/use_case/auth"
+	billingUseCase "github.com/haidang666/go-app/internal/domain/use_case/billing"
+	invitationUseCase "github.com/haidang666/go-app/internal/domain/use_case/invitation"
+	jobUseCase "github.com/haidang666/go-app/internal/domain/use_case/job"
+	mailUseCase "github.com/haidang666/go-app/internal/domain/use_case/mail"
+	notificationUseCase "github.com/haidang666/go-app/internal/domain/use_case/notification"
+	oauthUseCase "github.com/haidang666/go-app/internal/domain/use_case/oauth"
+	phoneUseCase "github.com/haidang666/go-app/internal/domain/use_case/phone"
+	presenceUseCase "github.com/haidang666/go-app/internal/domain/use_case/presence"
+	samlUseCase "github.com/haidang666/go-app/internal/domain/use_case/saml"
+	serviceAccountUseCase "github.com/haidang666/go-app/internal/domain/use_case/serviceaccount"
+	sessionUseCase "github.com/haidang666/go-app/internal/domain/use_case/session"
+	statsUseCase "github.com/haidang666/go-app/internal/domain/use_case/stats"
+	termsUseCase "github.com/haidang666/go-app/internal/domain/use_case/terms"
+	tokenUseCase "github.com/haidang666/go-app/internal/domain/use_case/token"
+	usageUseCase "github.com/haidang666/go-app/internal/domain/use_case/usage"
+	userUseCase "github.com/haidang666/go-app/internal/domain/use_case/user"
+	"github.com/haidang666/go-app/internal/infrastructure/analytics"
+	"github.com/haidang666/go-app/internal/infrastructure/billing"
+	"github.com/haidang666/go-app/internal/infrastructure/captcha"
+	"github.com/haidang666/go-app/internal/infrastructure/fake"
+	"github.com/haidang666/go-app/internal/infrastructure/geo"
+	"github.com/haidang666/go-app/internal/infrastructure/hasher"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/admin"
 	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/auth"
+	billingHandler "github.com/haidang666/go-app/internal/infrastructure/http/handlers/billing"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/dashboard"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/debug"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/health"
+	mailHandler "github.com/haidang666/go-app/internal/infrastructure/http/handlers/mail"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/me"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/oauth"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/username"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/users"
+	"github.com/haidang666/go-app/internal/infrastructure/http/middleware"
 	"github.com/haidang666/go-app/internal/infrastructure/http/router"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/internal/infrastructure/mailer"
+	"github.com/haidang666/go-app/internal/infrastructure/metering"
+	"github.com/haidang666/go-app/internal/infrastructure/realtime"
 	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/internal/infrastructure/reputation"
+	samlsp "github.com/haidang666/go-app/internal/infrastructure/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/sms"
+	"github.com/haidang666/go-app/internal/infrastructure/token"
+	"github.com/haidang666/go-app/pkg/anomaly"
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/dedupe"
+	"github.com/haidang666/go-app/pkg/drain"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/fixtures"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jwks"
+	"github.com/haidang666/go-app/pkg/jwt"
+	"github.com/haidang666/go-app/pkg/leader"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/lock"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/otlplog"
+	"github.com/haidang666/go-app/pkg/podinfo"
+	"github.com/haidang666/go-app/pkg/quota"
+	"github.com/haidang666/go-app/pkg/redact"
+	"github.com/haidang666/go-app/pkg/reqsign"
+	"github.com/haidang666/go-app/pkg/resilience"
+	"github.com/haidang666/go-app/pkg/resp"
+	"github.com/haidang666/go-app/pkg/saga"
+	"github.com/haidang666/go-app/pkg/securecookie"
+	"github.com/haidang666/go-app/pkg/securetoken"
+	"github.com/haidang666/go-app/pkg/siem"
+	"go.uber.org/zap/zapcore"
 )
 
 // Providers for the application container
 var ProviderSet = wire.NewSet(
+	ProvideClock,
+	ProvideResilienceRegistry,
+	ProvideFailModes,
+	ProvideDrainer,
+	ProvidePreStop,
+	ProvideLifecycle,
+	ProvideLoadShedder,
+	ProvideCredentialGate,
+	ProvideAuthProtection,
+	ProvideAdmissionController,
+	ProvideLockBackend,
+	ProvideQuotaStore,
+	ProvideDeduper,
+	ProvideQuotaLimiter,
+	ProvideElector,
 	ProvideUserRepository,
+	ProvideUserCache,
+	ProvideUserCacheRelay,
+	ProvideUserQuery,
+	ProvidePasswordHasher,
+	ProvideSessionRepository,
+	ProvideScheduledJobRepository,
+	ProvideJobScheduler,
+	ProvideJobSchedulerContract,
+	ProvidePersonalAccessTokenRepository,
+	ProvideNotificationPreferencesRepository,
+	ProvidePendingNotificationRepository,
+	ProvideInAppNotificationRepository,
+	ProvideBadgeHub,
+	ProvideBadgePublisher,
+	ProvidePresenceStore,
+	ProvidePresenceTracker,
+	ProvideKnownDeviceRepository,
+	ProvideDeviceApprovalRepository,
+	ProvideOutbox,
+	ProvideMailTransport,
+	ProvideEmailQueueRepository,
+	ProvideDeliverQueuedEmailsUseCase,
+	ProvideMailQueueWorker,
+	ProvideEmailTemplates,
+	ProvideMailer,
+	ProvideHandleFeedbackWebhookUseCase,
+	ProvideListEmailsUseCase,
+	ProvideGetEmailUseCase,
+	ProvideResendEmailUseCase,
+	ProvideEmailSuppressionRepository,
+	ProvideListEmailSuppressionsUseCase,
+	ProvideAddEmailSuppressionUseCase,
+	ProvideRemoveEmailSuppressionUseCase,
+	ProvideListDeadJobsUseCase,
+	ProvideGetJobUseCase,
+	ProvideRequeueJobUseCase,
+	ProvideDiscardJobUseCase,
+	ProvideGetQueueStatsUseCase,
+	ProvideAdminTaskRegistry,
+	ProvideAdminTaskRegistryContract,
+	ProvideListAdminTasksUseCase,
+	ProvideTriggerAdminTaskUseCase,
+	ProvidePurgeExpiredTokensUseCase,
+	ProvideResendEmailChangeUseCase,
+	ProvideAccountModule,
+	ProvideStatsRepository,
+	ProvideRefreshStatsUseCase,
+	ProvideGetStatsUseCase,
+	ProvideMailHandler,
+	ProvideLoginAttemptRepository,
+	ProvideAuditLogRepository,
+	ProvideAuditStream,
+	ProvideGeoLocator,
+	ProvideInvitationRepository,
+	ProvidePasswordResetRepository,
+	ProvideAccountRestoreRepository,
+	ProvideTermsAcceptanceRepository,
+	ProvideEmailChangeRepository,
+	ProvidePhoneOTPRepository,
+	ProvideExternalIdentityRepository,
+	ProvideOAuthClientRepository,
+	ProvideAuthorizationCodeRepository,
+	ProvideSAMLConnectionRepository,
+	ProvideSAMLServiceProvider,
+	ProvideLoginStateRepository,
+	ProvideSubscriptionRepository,
+	ProvideEventBus,
+	ProvideMetricsBackend,
+	ProvideRouteTable,
+	ProvideLogExporter,
+	ProvideUsageRepository,
+	ProvideAggregateUsageUseCase,
+	ProvideUsageMeter,
+	ProvideAnalyticsTracker,
+	ProvideGetCurrentUsageUseCase,
+	ProvideExportUsageUseCase,
+	ProvideBillingProvider,
+	ProvideEntitlementChecker,
+	ProvidePlanGate,
+	ProvideSMSSender,
+	ProvideJWTClient,
+	ProvideExternalTokenVerifier,
+	ProvideExternalUsers,
+	ProvideServiceAccounts,
+	ProvideServiceAccountRepository,
+	ProvideServiceAccountKeyRepository,
+	ProvideCreateServiceAccountUseCase,
+	ProvideListServiceAccountsUseCase,
+	ProvideDeleteServiceAccountUseCase,
+	ProvideCreateServiceAccountKeyUseCase,
+	ProvideListServiceAccountKeysUseCase,
+	ProvideRevokeServiceAccountKeyUseCase,
+	ProvideTokenIssuer,
+	ProvideClientTokenIssuer,
+	ProvideAccessTokenVerifier,
+	ProvideOIDCTokenIssuer,
+	ProvideDisposableEmailPolicy,
+	ProvidePasswordPolicy,
+	ProvideCaptchaVerifier,
+	ProvideCookieCodec,
+	ProvideIPReputation,
+	ProvideBotPolicy,
+	ProvideIssueSignUpFormUseCase,
+	ProvideSagaStore,
 	ProvideSignUpUseCase,
+	ProvideUpgradeGuestUseCase,
+	ProvideListIdentitiesUseCase,
+	ProvideLinkIdentityUseCase,
+	ProvideUnlinkIdentityUseCase,
+	ProvideStartGuestSessionUseCase,
+	ProvideDeviceGuard,
+	ProvideLoginRecorder,
+	ProvideSignInUseCase,
+	ProvideRotateExpiredPasswordUseCase,
+	ProvideForcePasswordRotationUseCase,
+	ProvideImpersonateUserUseCase,
+	ProvideListAuditLogUseCase,
+	ProvideListUsersUseCase,
+	ProvideExportUsersUseCase,
+	ProvideExportAuditLogUseCase,
+	ProvideSetUserStatusUseCase,
+	ProvideRevokeUserAccessUseCase,
+	ProvideSetUserPlanUseCase,
+	ProvideListUserTokensUseCase,
+	ProvideSetTenantQuotaUseCase,
+	ProvideOrganizationSettingsRepository,
+	ProvideGetOrganizationSettingsUseCase,
+	ProvideUpdateOrganizationSettingsUseCase,
+	ProvideMaintenanceRepository,
+	ProvideGetMaintenanceUseCase,
+	ProvideSetMaintenanceUseCase,
+	ProvideAuthProtectionRepository,
+	ProvideGetAuthProtectionUseCase,
+	ProvideSetAuthProtectionUseCase,
+	ProvideDeprecationUsageRepository,
+	ProvideListDeprecationUsageUseCase,
+	ProvideReviewDeviceUseCase,
+	ProvideRefreshTokensUseCase,
+	ProvideForgotPasswordUseCase,
+	ProvideResetPasswordUseCase,
+	ProvideListSessionsUseCase,
+	ProvideRevokeSessionUseCase,
+	ProvideRevokeAllSessionsUseCase,
+	ProvideCreateTokenUseCase,
+	ProvideListTokensUseCase,
+	ProvideRevokeTokenUseCase,
+	ProvideNotifier,
+	ProvideGetNotificationPreferencesUseCase,
+	ProvideUpdateNotificationPreferencesUseCase,
+	ProvideDeliverPendingNotificationsUseCase,
+	ProvideListNotificationsUseCase,
+	ProvideCountUnreadUseCase,
+	ProvideMarkReadUseCase,
+	ProvideMarkAllReadUseCase,
+	ProvideListLoginHistoryUseCase,
+	ProvideGetTermsStatusUseCase,
+	ProvideAcceptTermsUseCase,
+	ProvideRequestEmailChangeUseCase,
+	ProvideConfirmEmailChangeUseCase,
+	ProvideRevertEmailChangeUseCase,
+	ProvideDeleteAccountUseCase,
+	ProvideRestoreAccountUseCase,
+	ProvidePurgeDeletedAccountsUseCase,
+	ProvideOTPService,
+	ProvideRequestPhoneVerificationUseCase,
+	ProvideVerifyPhoneUseCase,
+	ProvideClearUnverifiedPhoneUseCase,
+	ProvideRequestSignInCodeUseCase,
+	ProvideSignInWithCodeUseCase,
+	ProvideSignInWithSAMLUseCase,
+	ProvideResolveExternalUserUseCase,
+	ProvideUpdateUserUseCase,
+	ProvideBulkUsersUseCase,
+	ProvideDisposableDomainsUseCase,
+	ProvideMintInvitationUseCase,
+	ProvideListInvitationsUseCase,
+	ProvideRevokeInvitationUseCase,
 	ProvideAuthHandler,
+	ProvideAdminHandler,
+	ProvideMeHandler,
+	ProvideHealthHandler,
+	ProvideRegisterOAuthClientUseCase,
+	ProvideListOAuthClientsUseCase,
+	ProvideRevokeOAuthClientUseCase,
+	ProvideIssueClientTokenUseCase,
+	ProvideIntrospectTokenUseCase,
+	ProvideAuthorizeUseCase,
+	ProvideExchangeCodeUseCase,
+	ProvideUserInfoUseCase,
+	ProvideDiscoveryUseCase,
+	ProvideRegisterSAMLConnectionUseCase,
+	ProvideListSAMLConnectionsUseCase,
+	ProvideDeleteSAMLConnectionUseCase,
+	ProvideSAMLMetadataUseCase,
+	ProvideStartSAMLLoginUseCase,
+	ProvideSAMLHandler,
+	ProvideOAuthHandler,
+	ProvideCreateCheckoutSessionUseCase,
+	ProvideHandleWebhookUseCase,
+	ProvideGetSubscriptionUseCase,
+	ProvideBillingHandler,
+	ProvideDebugHandler,
+	ProvideDashboardHandler,
+	ProvideFixtureLoader,
+	ProvideCheckUsernameUseCase,
+	ProvideUsernameHandler,
+	ProvideGetPresenceUseCase,
+	ProvideUsersHandler,
 	ProvideRouter,
+	ProvideAuthModule,
+	ProvideBillingModule,
+	ProvideMailModule,
+	ProvideNotificationModule,
+	ProvideJobsModule,
+	ProvidePhoneModule,
+	ProvideUsersModule,
+	ProvideStatsModule,
+	ProvidePresenceModule,
+	ProvideModules,
+	ProvideDoctor,
 	ProvideContainer,
 )
 
+// readinessPolicy is the caching policy of the readiness checks.
+func readinessPolicy(cfg *config.Config) lifecycle.CheckPolicy {
+	return lifecycle.CheckPolicy{
+		Interval:         cfg.Readiness.CheckInterval,
+		FailureThreshold: cfg.Readiness.FailureThreshold,
+		SuccessThreshold: cfg.Readiness.SuccessThreshold,
+		MaxAge:           cfg.Readiness.CheckMaxAge,
+	}
+}
+
+// ProvideClock provides the wall clock the time-based components read
+func ProvideClock() clock.Clock {
+	return clock.Real()
+}
+
+// ProvideResilienceRegistry provides the registry of dependency resilience policies
+func ProvideResilienceRegistry() *resilience.Registry {
+	return resilience.NewRegistry()
+}
+
+// ProvideLifecycle provides the instance status and component health
+// registry. The dependencies component is probed from the resilience
+// policies; the others are set by the hooks that start and stop them.
+func ProvideLifecycle(cfg *config.Config, registry *resilience.Registry) *lifecycle.Registry {
+	l := lifecycle.NewRegistry()
+	l.AddCachedCheck(COMPONENT_DEPENDENCIES, true, func() (lifecycle.ComponentState, string) {
+		statuses, healthy := registry.Statuses()
+		var open []string
+		for _, s := range statuses {
+			if s.State == resilience.STATE_OPEN.String() {
+				open = append(open, s.Name)
+			}
+		}
+		detail := ""
+		if len(open) > 0 {
+			detail = "open circuits: " + strings.Join(open, ", ")
+		}
+		if !healthy {
+			return lifecycle.COMPONENT_DOWN, detail
+		}
+		return lifecycle.COMPONENT_UP, detail
+	}, readinessPolicy(cfg))
+	l.Register(COMPONENT_HTTP, true)
+	if cfg.App.OpsAddr != "" {
+		l.Register(COMPONENT_OPS_HTTP, true)
+	}
+	l.Register(COMPONENT_EVENT_BUS, false)
+	l.Register(COMPONENT_LEADER_ELECTION, false)
+	l.Register(COMPONENT_WARMUP, false)
+	return l
+}
+
+// ProvideDrainer provides the in-flight request tracker used for draining
+func ProvideDrainer() *drain.Drainer {
+	return drain.NewDrainer()
+}
+
+// ProvidePreStop provides the start of the shutdown, run on SIGTERM or
+// earlier by a preStop hook: readiness failing for APP_SHUTDOWN_DELAY.
+func ProvidePreStop(cfg *config.Config, lifecycleRegistry *lifecycle.Registry, drainer *drain.Drainer) *drain.PreStop {
+	return drain.NewPreStop(cfg.App.ShutdownDelay, func() {
+		lifecycleRegistry.Stopping()
+		drainer.StartDraining()
+		if cfg.App.ShutdownDelay > 0 {
+			logger.L().Infof("draining: readiness failing, waiting %s before shutdown", cfg.App.ShutdownDelay)
+		}
+	})
+}
+
+// ProvideLoadShedder provides the concurrency-limiting load shedder
+func ProvideLoadShedder(cfg *config.Config) *middleware.LoadShedder {
+	return middleware.NewLoadShedder(middleware.LoadShedderArgs{
+		MaxInFlight: cfg.LoadShed.MaxInFlight,
+		GroupLimits: cfg.LoadShed.GroupLimits,
+		RetryAfter:  cfg.LoadShed.RetryAfter,
+	})
+}
+
+// ProvideCredentialGate provides the concurrency gate of the credential
+// routes, limiting those CREDENTIAL_GATE_LIMITS leaves out to GOMAXPROCS.
+func ProvideCredentialGate(cfg *config.Config) (*middleware.CredentialGate, error) {
+	credentials := credentialGateConfig.From(cfg)
+	limits := maps.Clone(credentials.Limits)
+	if limits == nil {
+		limits = make(map[string]int, len(middleware.CredentialRoutes))
+	}
+	for _, route := range middleware.CredentialRoutes {
+		if _, ok := limits[route]; !ok {
+			limits[route] = runtime.GOMAXPROCS(0)
+		}
+	}
+	gate, err := middleware.NewCredentialGate(middleware.CredentialGateArgs{
+		Limits:       limits,
+		MaxQueue:     credentials.MaxQueue,
+		QueueTimeout: credentials.QueueTimeout,
+		RetryAfter:   credentials.RetryAfter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse CREDENTIAL_GATE_LIMITS: %w", err)
+	}
+	logger.L().Infow("credential routes gated",
+		"sign_up", gate.Limit(middleware.CREDENTIAL_ROUTE_SIGN_UP),
+		"sign_in", gate.Limit(middleware.CREDENTIAL_ROUTE_SIGN_IN),
+		"max_queue", credentials.MaxQueue,
+		"queue_timeout", credentials.QueueTimeout.String())
+	return gate, nil
+}
+
+// ProvideAuthProtection provides the adaptive protection of the credential
+// routes, or nil when AUTH_PROTECTION_ENABLED is off. ASNs come from
+// GEOIP_ASN_DB_PATH, or else from GEOIP_DB_PATH when it is an ISP database.
+func ProvideAuthProtection(
+	cfg *config.Config,
+	geoLocator contract.GeoLocator,
+	authProtectionRepo contract.AuthProtectionRepository,
+	limiter *quota.Limiter,
+) (*middleware.AuthProtection, error) {
+	c := authProtectionConfig.From(cfg)
+	if !c.Enabled {
+		return nil, nil
+	}
+	var rateLimit []quota.Limit
+	if c.RateLimit != "" {
+		var err error
+		if rateLimit, err = quota.ParseLimits(c.RateLimit); err != nil {
+			return nil, fmt.Errorf("parse AUTH_PROTECTION_RATE_LIMIT: %w", err)
+		}
+	}
+	rules := make(map[string]anomaly.Rule, 3)
+	for scope, minFailures := range map[string]int{
+		middleware.AUTH_SCOPE_GLOBAL: c.GlobalMinFailures,
+		middleware.AUTH_SCOPE_ASN:    c.ASNMinFailures,
+		middleware.AUTH_SCOPE_SUBNET: c.SubnetMinFailures,
+	} {
+		if minFailures > 0 {
+			rules[scope] = anomaly.Rule{MinFailures: minFailures, Ratio: c.FailureRatio}
+		}
+	}
+	locator := geoLocator
+	if cfg.GeoIP.ASNDBPath != "" {
+		asnLocator, err := geo.NewMaxMindLocator(geo.MaxMindLocatorArgs{
+			Path:           cfg.GeoIP.ASNDBPath,
+			ReloadInterval: cfg.GeoIP.ReloadInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+		locator = asnLocator
+	}
+
+	protection, err := middleware.NewAuthProtection(middleware.AuthProtectionArgs{
+		Rules:     rules,
+		Window:    c.Window,
+		Cooldown:  c.Cooldown,
+		MaxKeys:   c.MaxTrackedNetworks,
+		Locator:   locator,
+		Override:  authProtectionRepo,
+		Limiter:   limiter,
+		RateLimit: rateLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load AUTH_PROTECTION config: %w", err)
+	}
+	logger.L().Infow("credential routes adaptively protected",
+		"scopes", slices.Sorted(maps.Keys(rules)),
+		"window", c.Window.String(),
+		"failure_ratio", c.FailureRatio,
+		"rate_limit", c.RateLimit)
+	return protection, nil
+}
+
+// ProvideAdmissionController provides the priority admission controller
+func ProvideAdmissionController(cfg *config.Config) *middleware.AdmissionController {
+	return middleware.NewAdmissionController(middleware.AdmissionControllerArgs{
+		Capacity: cfg.Admission.Capacity,
+		MaxQueue: cfg.Admission.MaxQueue,
+		QueueTimeout: map[middleware.Priority]time.Duration{
+			middleware.PRIORITY_HIGH: cfg.Admission.HighQueueTimeout,
+			middleware.PRIORITY_LOW:  cfg.Admission.LowQueueTimeout,
+		},
+	})
+}
+
+// ProvideLockBackend provides the distributed lock backend
+func ProvideLockBackend() lock.Backend {
+	return lock.NewMemoryBackend()
+}
+
+// ProvideQuotaStore provides the request quota counter store selected by
+// QUOTA_BACKEND
+func ProvideQuotaStore(cfg *config.Config, registry *resilience.Registry) (quota.Store, error) {
+	switch cfg.Quota.Backend {
+	case "memory":
+		return quota.NewMemoryStore(), nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_QUOTA, resp.ClientArgs{
+			Addr:     cfg.Quota.RedisAddr,
+			Password: cfg.Quota.RedisPassword,
+			DB:       cfg.Quota.RedisDB,
+		})
+		return quota.NewRedisStore(client, cfg.Quota.RedisPrefix), nil
+	default:
+		return nil, fmt.Errorf("QUOTA_BACKEND must be memory or redis, got %q", cfg.Quota.Backend)
+	}
+}
+
+// ProvideDeduper provides the deduplication of webhook deliveries and bus
+// events, backed by the store selected by DEDUPE_BACKEND
+func ProvideDeduper(cfg *config.Config, registry *resilience.Registry, modes FailModes) (*dedupe.Deduper, error) {
+	var store dedupe.Store
+	switch cfg.Dedupe.Backend {
+	case "memory":
+		store = dedupe.NewMemoryStore()
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_DEDUPE, resp.ClientArgs{
+			Addr:     cfg.Dedupe.RedisAddr,
+			Password: cfg.Dedupe.RedisPassword,
+			DB:       cfg.Dedupe.RedisDB,
+		})
+		store = dedupe.NewRedisStore(client, cfg.Dedupe.RedisPrefix)
+	default:
+		return nil, fmt.Errorf("DEDUPE_BACKEND must be memory or redis, got %q", cfg.Dedupe.Backend)
+	}
+	if cfg.Dedupe.Retention <= 0 {
+		return nil, fmt.Errorf("DEDUPE_RETENTION must be positive, got %s", cfg.Dedupe.Retention)
+	}
+	for scope, retention := range cfg.Dedupe.ScopeRetention {
+		if retention <= 0 {
+			return nil, fmt.Errorf("DEDUPE_SCOPE_RETENTION of %q must be positive, got %s", scope, retention)
+		}
+	}
+	return dedupe.NewDeduper(dedupe.DeduperArgs{
+		Store:          store,
+		Lease:          cfg.Dedupe.Lease,
+		Retention:      cfg.Dedupe.Retention,
+		ScopeRetention: cfg.Dedupe.ScopeRetention,
+		FailOpen:       modes.Open(FEATURE_DEDUPE),
+	}), nil
+}
+
+// ProvideQuotaLimiter provides the per-plan request quota limiter
+func ProvideQuotaLimiter(cfg *config.Config, store quota.Store, clk clock.Clock) (*quota.Limiter, error) {
+	plans, err := quota.ParsePlans(cfg.Quota.Plans)
+	if err != nil {
+		return nil, fmt.Errorf("parse QUOTA_PLANS: %w", err)
+	}
+	return quota.NewLimiter(quota.LimiterArgs{
+		Store:       store,
+		Plans:       plans,
+		DefaultPlan: cfg.Quota.DefaultPlan,
+		SoftRatio:   cfg.Quota.SoftRatio,
+		Clock:       clk,
+	})
+}
+
+// ProvideElector provides the leader elector for singleton background tasks
+func ProvideElector(cfg *config.Config, backend lock.Backend) *leader.Elector {
+	return leader.NewElector(leader.ElectorArgs{
+		Locker: lock.NewLocker(lock.LockerArgs{
+			Backend: backend,
+			TTL:     cfg.Leader.LeaseTTL,
+		}),
+		Key:           cfg.Leader.Key,
+		RetryInterval: cfg.Leader.RetryInterval,
+	})
+}
+
+// ProvideMetricsBackend selects the metrics backend of the process-wide
+// registry. It returns nil for Prometheus, which is scraped from /metrics
+// instead of pushed to.
+func ProvideMetricsBackend(cfg *config.Config) (metrics.Backend, error) {
+	switch cfg.Metrics.Backend {
+	case "prometheus":
+		return nil, nil
+	case "statsd":
+		backend, err := metrics.NewStatsD(metrics.StatsDArgs{
+			Addr:          cfg.Metrics.StatsDAddr,
+			Prefix:        cfg.Metrics.StatsDPrefix,
+			FlushInterval: cfg.Metrics.StatsDFlushInterval,
+			Tags:          podTags(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("configure METRICS: %w", err)
+		}
+		metrics.SetBackend(backend)
+		return backend, nil
+	case "noop":
+		metrics.Disable()
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("METRICS_BACKEND must be prometheus, statsd or noop, got %q", cfg.Metrics.Backend)
+	}
+}
+
+// podTags tags the StatsD updates with the pod the instance runs in, when
+// the downward API names it.
+func podTags() []metrics.Label {
+	var tags []metrics.Label
+	for _, a := range podinfo.Get().Attrs() {
+		tags = append(tags, metrics.Label{Name: a.Key, Value: a.Value})
+	}
+	return tags
+}
+
+// ProvideLogExporter exports the log entries over OTLP when
+// LOG_OTLP_ENDPOINT is set, and returns nil otherwise. The queued entries
+// are flushed on shutdown, and on a Fatal entry before the process exits.
+func ProvideLogExporter(cfg *config.Config) (*otlplog.Exporter, error) {
+	if cfg.Log.OTLPEndpoint == "" {
+		return nil, nil
+	}
+	level, err := zapcore.ParseLevel(cfg.Log.OTLPLevel)
+	if err != nil {
+		return nil, fmt.Errorf("LOG_OTLP_LEVEL: %w", err)
+	}
+	exporter := otlplog.NewExporter(otlplog.ExporterArgs{
+		Endpoint:       cfg.Log.OTLPEndpoint,
+		Headers:        cfg.Log.OTLPHeaders,
+		ServiceName:    cfg.Log.OTLPServiceName,
+		ServiceVersion: buildinfo.Get().Version,
+		Resource:       podinfo.Get().ResourceAttributes(),
+		Level:          level,
+		Buffer:         cfg.Log.OTLPBuffer,
+		BatchSize:      cfg.Log.OTLPBatchSize,
+		FlushInterval:  cfg.Log.OTLPFlushInterval,
+		SendTimeout:    cfg.Log.OTLPTimeout,
+	})
+	logger.Export(exporter.Core())
+	logger.OnFatal(func(string) {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Log.OTLPTimeout)
+		defer cancel()
+		exporter.Close(ctx)
+	})
+	return exporter, nil
+}
+
+// ProvideEventBus provides the in-process event bus
+func ProvideEventBus(cfg *config.Config) *eventbus.Bus {
+	return eventbus.NewBus(eventbus.BusArgs{
+		Buffer:  cfg.EventBus.Buffer,
+		Workers: cfg.EventBus.Workers,
+	})
+}
+
 // ProvideUserRepository provides the user repository implementation
-func ProvideUserRepository() contract.UserRepository {
-	return infrastructure.NewUserRepository()
+func ProvideUserRepository(cfg *config.Config, registry *resilience.Registry, bus *eventbus.Bus, cache *infrastructure.UserCache) (contract.UserRepository, error) {
+	policy := registry.Policy("db", resilience.PolicyArgs{
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenTimeout:      cfg.Resilience.OpenTimeout,
+		MaxConcurrent:    cfg.Resilience.MaxConcurrent,
+		MaxWait:          cfg.Resilience.MaxWait,
+		IsFailure:        infrastructure.IsDatabaseFailure,
+		Critical:         true,
+	})
+	var users contract.UserRepository
+	switch cfg.UserStore.Mode {
+	case "state":
+		users = infrastructure.NewUserRepository()
+	case "events":
+		users = infrastructure.NewEventSourcedUserRepository(infrastructure.EventSourcedUserRepositoryArgs{
+			Store:         infrastructure.NewUserEventStore(),
+			Projection:    infrastructure.NewUserRepository(),
+			SnapshotEvery: cfg.UserStore.SnapshotEvery,
+		})
+	default:
+		return nil, fmt.Errorf("unknown USER_STORE_MODE %q", cfg.UserStore.Mode)
+	}
+	users = infrastructure.NewPublishingUserRepository(users, bus)
+	users = infrastructure.NewResilientUserRepository(users, policy)
+	if cache != nil {
+		users = infrastructure.NewCachingUserRepository(users, cache)
+	}
+	return users, nil
 }
 
-// ProvideSignUpUseCase provides the sign up use case
-func ProvideSignUpUseCase(userRepo contract.UserRepository) *authUseCase.SignUpUseCase {
-	return authUseCase.NewSignUpUseCase(userRepo)
+// ProvideUserCache provides the cache of users by ID, or nil unless
+// USER_CACHE_ENABLED
+func ProvideUserCache(cfg *config.Config) *infrastructure.UserCache {
+	if !cfg.UserCache.Enabled {
+		return nil
+	}
+	return infrastructure.NewUserCache(cfg.UserCache.TTL, cfg.UserCache.Size)
 }
 
-// ProvideAuthHandler provides the auth handler
-func ProvideAuthHandler(signUpUseCase *authUseCase.SignUpUseCase) *auth.AuthHandler {
-	return auth.NewAuthHandler(auth.NewAuthHandlerArgs{
-		SignUpUseCase: signUpUseCase,
+// ProvideUserCacheRelay provides the relay of user cache invalidations
+// between instances, or nil unless USER_CACHE_INVALIDATION is "redis"
+func ProvideUserCacheRelay(cfg *config.Config, registry *resilience.Registry, modes FailModes, bus *eventbus.Bus, cache *infrastructure.UserCache) (*infrastructure.UserCacheRelay, error) {
+	if cache == nil {
+		return nil, nil
+	}
+	switch cfg.UserCache.Invalidation {
+	case "local":
+		return nil, nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_USER_CACHE, resp.ClientArgs{
+			Addr:     cfg.UserCache.RedisAddr,
+			Password: cfg.UserCache.RedisPassword,
+			DB:       cfg.UserCache.RedisDB,
+		})
+		relay := infrastructure.NewUserCacheRelay(client, cfg.UserCache.RedisChannel, cache, modes.Open(FEATURE_USER_CACHE))
+		relay.Subscribe(bus)
+		return relay, nil
+	default:
+		return nil, fmt.Errorf("USER_CACHE_INVALIDATION must be local or redis, got %q", cfg.UserCache.Invalidation)
+	}
+}
+
+// ProvideUserQuery provides the user read model, kept up to date from the
+// user writes published on the event bus
+func ProvideUserQuery(bus *eventbus.Bus) contract.UserQuery {
+	readModel := infrastructure.NewUserReadModel()
+	readModel.Subscribe(bus)
+	return readModel
+}
+
+// ProvideStatsRepository provides the stats view store selected by
+// STATS_BACKEND
+func ProvideStatsRepository(cfg *config.Config) (contract.StatsRepository, error) {
+	stats := statsConfig.From(cfg)
+	if stats.Backend != "postgres" {
+		return infrastructure.NewStatsRepository(), nil
+	}
+	db, err := openPostgres(cfg, stats.SQLDriver)
+	if err != nil {
+		return nil, fmt.Errorf("open stats database: %w", err)
+	}
+	return infrastructure.NewPostgresStatsRepository(db), nil
+}
+
+// ProvideSessionRepository provides the session repository selected by
+// SESSION_STORE_BACKEND
+func ProvideSessionRepository(cfg *config.Config, registry *resilience.Registry) (contract.SessionRepository, error) {
+	switch cfg.Sessions.Backend {
+	case "memory":
+		return infrastructure.NewSessionRepository(), nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_SESSIONS, resp.ClientArgs{
+			Addr:     cfg.Sessions.RedisAddr,
+			Password: cfg.Sessions.RedisPassword,
+			DB:       cfg.Sessions.RedisDB,
+		})
+		return infrastructure.NewRedisSessionRepository(client, cfg.Sessions.RedisPrefix), nil
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Sessions.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open session database: %w", err)
+		}
+		return infrastructure.NewPostgresSessionRepository(db), nil
+	default:
+		return nil, fmt.Errorf("SESSION_STORE_BACKEND must be memory, redis or postgres, got %q", cfg.Sessions.Backend)
+	}
+}
+
+// ProvideScheduledJobRepository provides the scheduled job store selected by JOBS_BACKEND
+func ProvideScheduledJobRepository(cfg *config.Config) (contract.ScheduledJobRepository, error) {
+	switch cfg.Jobs.Backend {
+	case "memory":
+		return infrastructure.NewScheduledJobRepository(), nil
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Jobs.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open jobs database: %w", err)
+		}
+		return infrastructure.NewPostgresScheduledJobRepository(db), nil
+	default:
+		return nil, fmt.Errorf("JOBS_BACKEND must be memory or postgres, got %q", cfg.Jobs.Backend)
+	}
+}
+
+// ProvideJobScheduler provides the scheduler that stores and runs deferred jobs
+func ProvideJobScheduler(cfg *config.Config, repo contract.ScheduledJobRepository, clk clock.Clock) *jobs.Scheduler {
+	return jobs.NewScheduler(jobs.SchedulerArgs{
+		Repo:        repo,
+		Interval:    cfg.Jobs.PollInterval,
+		BatchSize:   cfg.Jobs.BatchSize,
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+		RetryBase:   cfg.Jobs.RetryBase,
+		RetryMax:    cfg.Jobs.RetryMax,
+		Clock:       clk,
 	})
 }
 
-// ProvideRouter provides the chi router with all routes registered
-func ProvideRouter(authHandler *auth.AuthHandler) *chi.Mux {
-	return router.NewRouter(router.NewRouterArgs{
-		AuthHandler: authHandler,
+// ProvideJobSchedulerContract provides the job scheduler implementation
+func ProvideJobSchedulerContract(scheduler *jobs.Scheduler) contract.JobScheduler {
+	return scheduler
+}
+
+// openPostgres opens the DB_* database with the database/sql driver
+// registered as driver. sql.Open does not connect, so a driver missing from
+// the binary is reported here rather than at the first query.
+func openPostgres(cfg *config.Config, driver string) (*sql.DB, error) {
+	if err := sqlDriverLinked(driver); err != nil {
+		return nil, err
+	}
+	return sql.Open(driver, postgresDSN(cfg))
+}
+
+// sqlDriverLinked reports a database/sql driver missing from the binary by
+// name.
+func sqlDriverLinked(driver string) error {
+	if !slices.Contains(sql.Drivers(), driver) {
+		return fmt.Errorf("database/sql driver %q is not linked into the binary, which has %v", driver, sql.Drivers())
+	}
+	return nil
+}
+
+// postgresDSN is the URL of the DB_* database.
+func postgresDSN(cfg *config.Config) string {
+	return (&url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.DB.Username, cfg.DB.Password),
+		Host:   net.JoinHostPort(cfg.DB.Host, strconv.Itoa(cfg.DB.Port)),
+		Path:   cfg.DB.DatabaseName,
+	}).String()
+}
+
+// ProvidePersonalAccessTokenRepository provides the personal access token repository implementation
+func ProvidePersonalAccessTokenRepository() contract.PersonalAccessTokenRepository {
+	return infrastructure.NewPersonalAccessTokenRepository()
+}
+
+// ProvideNotificationPreferencesRepository provides the notification preferences repository implementation
+func ProvideNotificationPreferencesRepository() contract.NotificationPreferencesRepository {
+	return infrastructure.NewNotificationPreferencesRepository()
+}
+
+// ProvidePendingNotificationRepository provides the held notification repository implementation
+func ProvidePendingNotificationRepository() contract.PendingNotificationRepository {
+	return infrastructure.NewPendingNotificationRepository()
+}
+
+// ProvideInAppNotificationRepository provides the in-app notification inbox implementation
+func ProvideInAppNotificationRepository() contract.InAppNotificationRepository {
+	return infrastructure.NewInAppNotificationRepository()
+}
+
+// ProvideBadgeHub provides the hub pushing unread notification counts to
+// open notification streams
+func ProvideBadgeHub(bus *eventbus.Bus) *realtime.BadgeHub {
+	return realtime.NewBadgeHub(bus)
+}
+
+// ProvideBadgePublisher provides the unread count publisher implementation
+func ProvideBadgePublisher(hub *realtime.BadgeHub) contract.BadgePublisher {
+	return hub
+}
+
+// ProvidePresenceStore provides the presence store selected by
+// PRESENCE_BACKEND
+func ProvidePresenceStore(cfg *config.Config, registry *resilience.Registry) contract.PresenceStore {
+	presence := presenceConfig.From(cfg)
+	if presence.Backend != "redis" {
+		return infrastructure.NewPresenceStore()
+	}
+	client := redisClient(cfg, registry, FEATURE_PRESENCE, resp.ClientArgs{
+		Addr:     presence.RedisAddr,
+		Password: presence.RedisPassword,
+		DB:       presence.RedisDB,
+	})
+	return infrastructure.NewRedisPresenceStore(client, presence.RedisPrefix)
+}
+
+// ProvidePresenceTracker provides the tracker of who is online
+func ProvidePresenceTracker(cfg *config.Config, store contract.PresenceStore, bus *eventbus.Bus) *realtime.PresenceTracker {
+	return realtime.NewPresenceTracker(store, bus, presenceConfig.From(cfg).TTL)
+}
+
+// ProvideKnownDeviceRepository provides the known device repository implementation
+func ProvideKnownDeviceRepository() contract.KnownDeviceRepository {
+	return infrastructure.NewKnownDeviceRepository()
+}
+
+// ProvideDeviceApprovalRepository provides the device approval repository implementation
+func ProvideDeviceApprovalRepository() contract.DeviceApprovalRepository {
+	return infrastructure.NewDeviceApprovalRepository()
+}
+
+// ProvideOutbox provides the recorder of the fake external dependencies, or
+// nil unless MOCK_DEPS is on
+func ProvideOutbox(cfg *config.Config) (*fake.Outbox, error) {
+	if !cfg.Mock.Deps {
+		return nil, nil
+	}
+	if cfg.App.Env == "production" {
+		return nil, errors.New("MOCK_DEPS must not be used in production")
+	}
+	return fake.NewOutbox(cfg.Mock.OutboxSize), nil
+}
+
+// ProvideDebugHandler provides the fake dependency outbox and email preview
+// handler, or nil outside development unless MOCK_DEPS is on
+func ProvideDebugHandler(cfg *config.Config, outbox *fake.Outbox, templates *mailer.TemplateRegistry) *debug.DebugHandler {
+	args := debug.NewDebugHandlerArgs{Outbox: outbox}
+	if cfg.App.Env == "development" {
+		args.Templates = templates
+	}
+	if args.Outbox == nil && args.Templates == nil {
+		return nil
+	}
+	return debug.NewDebugHandler(args)
+}
+
+// ProvideDashboardHandler provides the admin dashboard handler, or nil when
+// the dashboard is disabled
+func ProvideDashboardHandler(cfg *config.Config) *dashboard.DashboardHandler {
+	if !cfg.Admin.Dashboard {
+		return nil
+	}
+	return dashboard.NewDashboardHandler()
+}
+
+// ProvideMailTransport provides the implementation that hands email to the
+// provider
+func ProvideMailTransport(cfg *config.Config, outbox *fake.Outbox) contract.MailTransport {
+	if outbox != nil {
+		return fake.NewMailer(cfg.Mail.From, outbox)
+	}
+	return mailer.NewLogMailer(cfg.Mail.From)
+}
+
+// ProvideEmailQueueRepository provides the outgoing mail queue repository implementation
+func ProvideEmailQueueRepository() contract.EmailQueueRepository {
+	return infrastructure.NewEmailQueueRepository()
+}
+
+// ProvideDeliverQueuedEmailsUseCase provides the mail queue delivery use case
+func ProvideDeliverQueuedEmailsUseCase(
+	cfg *config.Config,
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	transport contract.MailTransport,
+) *mailUseCase.DeliverQueuedEmailsUseCase {
+	return mailUseCase.NewDeliverQueuedEmailsUseCase(mailUseCase.DeliverQueuedEmailsUseCaseArgs{
+		EmailQueueRepo:  emailQueueRepo,
+		SuppressionRepo: suppressionRepo,
+		Transport:       transport,
+		BatchSize:       cfg.Mail.BatchSize,
+		MaxAttempts:     cfg.Mail.MaxAttempts,
+		RetryBase:       cfg.Mail.RetryBase,
+		RetryMax:        cfg.Mail.RetryMax,
+	})
+}
+
+// ProvideMailQueueWorker provides the mail queue worker; the mail module
+// runs it on the leader
+func ProvideMailQueueWorker(
+	cfg *config.Config,
+	deliver *mailUseCase.DeliverQueuedEmailsUseCase,
+) *mailer.QueueWorker {
+	return mailer.NewQueueWorker(deliver, cfg.Mail.PollInterval)
+}
+
+// ProvideEmailTemplates provides the email template registry, with the
+// templates in MAIL_TEMPLATE_DIR overriding the built-in ones
+func ProvideEmailTemplates(cfg *config.Config) (*mailer.TemplateRegistry, error) {
+	return mailer.NewTemplateRegistry(cfg.Mail.TemplateDir)
+}
+
+// ProvideMailer provides the outgoing email implementation, which renders
+// and queues every message
+func ProvideMailer(
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	templates *mailer.TemplateRegistry,
+	worker *mailer.QueueWorker,
+) contract.Mailer {
+	return mailer.NewQueueMailer(emailQueueRepo, suppressionRepo, templates, worker)
+}
+
+// ProvideHandleFeedbackWebhookUseCase provides the bounce and complaint
+// webhook use case, or nil when no webhook secret is configured
+func ProvideHandleFeedbackWebhookUseCase(
+	cfg *config.Config,
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	deduper *dedupe.Deduper,
+) *mailUseCase.HandleFeedbackWebhookUseCase {
+	if cfg.Mail.WebhookSecret == "" {
+		return nil
+	}
+	return mailUseCase.NewHandleFeedbackWebhookUseCase(emailQueueRepo, suppressionRepo, deduper, cfg.Mail.WebhookSecret)
+}
+
+// ProvideListEmailsUseCase provides the mail queue listing use case
+func ProvideListEmailsUseCase(emailQueueRepo contract.EmailQueueRepository) *mailUseCase.ListEmailsUseCase {
+	return mailUseCase.NewListEmailsUseCase(emailQueueRepo)
+}
+
+// ProvideGetEmailUseCase provides the queued email lookup use case
+func ProvideGetEmailUseCase(emailQueueRepo contract.EmailQueueRepository) *mailUseCase.GetEmailUseCase {
+	return mailUseCase.NewGetEmailUseCase(emailQueueRepo)
+}
+
+// ProvideResendEmailUseCase provides the queued email resend use case
+func ProvideResendEmailUseCase(
+	emailQueueRepo contract.EmailQueueRepository,
+	worker *mailer.QueueWorker,
+) *mailUseCase.ResendEmailUseCase {
+	return mailUseCase.NewResendEmailUseCase(emailQueueRepo, worker.Wake)
+}
+
+// ProvideEmailSuppressionRepository provides the list of addresses no mail
+// is sent to
+func ProvideEmailSuppressionRepository() contract.EmailSuppressionRepository {
+	return infrastructure.NewEmailSuppressionRepository()
+}
+
+// ProvideListEmailSuppressionsUseCase provides the suppression list listing use case
+func ProvideListEmailSuppressionsUseCase(suppressionRepo contract.EmailSuppressionRepository) *mailUseCase.ListEmailSuppressionsUseCase {
+	return mailUseCase.NewListEmailSuppressionsUseCase(suppressionRepo)
+}
+
+// ProvideAddEmailSuppressionUseCase provides the admin address suppression use case
+func ProvideAddEmailSuppressionUseCase(
+	suppressionRepo contract.EmailSuppressionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *mailUseCase.AddEmailSuppressionUseCase {
+	return mailUseCase.NewAddEmailSuppressionUseCase(suppressionRepo, auditLogRepo)
+}
+
+// ProvideRemoveEmailSuppressionUseCase provides the admin address unsuppression use case
+func ProvideRemoveEmailSuppressionUseCase(
+	suppressionRepo contract.EmailSuppressionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *mailUseCase.RemoveEmailSuppressionUseCase {
+	return mailUseCase.NewRemoveEmailSuppressionUseCase(suppressionRepo, auditLogRepo)
+}
+
+// ProvideListDeadJobsUseCase provides the failed scheduled job listing use case
+func ProvideListDeadJobsUseCase(jobRepo contract.ScheduledJobRepository) *jobUseCase.ListDeadJobsUseCase {
+	return jobUseCase.NewListDeadJobsUseCase(jobRepo)
+}
+
+// ProvideGetJobUseCase provides the scheduled job lookup use case
+func ProvideGetJobUseCase(jobRepo contract.ScheduledJobRepository) *jobUseCase.GetJobUseCase {
+	return jobUseCase.NewGetJobUseCase(jobRepo)
+}
+
+// ProvideRequeueJobUseCase provides the failed scheduled job requeue use case
+func ProvideRequeueJobUseCase(jobRepo contract.ScheduledJobRepository, scheduler *jobs.Scheduler) *jobUseCase.RequeueJobUseCase {
+	return jobUseCase.NewRequeueJobUseCase(jobRepo, scheduler.Wake)
+}
+
+// ProvideAdminTaskRegistry provides the registry of the tasks admins may
+// trigger, filled in by the modules
+func ProvideAdminTaskRegistry() *jobs.AdminTaskRegistry {
+	return jobs.NewAdminTaskRegistry()
+}
+
+// ProvideAdminTaskRegistryContract exposes the admin task registry to the use cases
+func ProvideAdminTaskRegistryContract(registry *jobs.AdminTaskRegistry) contract.AdminTaskRegistry {
+	return registry
+}
+
+// ProvideListAdminTasksUseCase provides the admin task listing use case
+func ProvideListAdminTasksUseCase(registry contract.AdminTaskRegistry) *jobUseCase.ListAdminTasksUseCase {
+	return jobUseCase.NewListAdminTasksUseCase(registry)
+}
+
+// ProvideTriggerAdminTaskUseCase provides the use case queuing admin task runs
+func ProvideTriggerAdminTaskUseCase(
+	registry contract.AdminTaskRegistry,
+	jobRepo contract.ScheduledJobRepository,
+	auditLogRepo contract.AuditLogRepository,
+	scheduler *jobs.Scheduler,
+) *jobUseCase.TriggerAdminTaskUseCase {
+	return jobUseCase.NewTriggerAdminTaskUseCase(registry, jobRepo, auditLogRepo, scheduler.Wake)
+}
+
+// ProvidePurgeExpiredTokensUseCase provides the expired session, reset and authorization code purge use case
+func ProvidePurgeExpiredTokensUseCase(
+	sessionRepo contract.SessionRepository,
+	resetRepo contract.PasswordResetRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	purgeDeleted *accountUseCase.PurgeDeletedAccountsUseCase,
+) *sessionUseCase.PurgeExpiredTokensUseCase {
+	return sessionUseCase.NewPurgeExpiredTokensUseCase(sessionRepo, resetRepo, codeRepo, purgeDeleted)
+}
+
+// ProvideDiscardJobUseCase provides the failed scheduled job discard use case
+func ProvideDiscardJobUseCase(jobRepo contract.ScheduledJobRepository) *jobUseCase.DiscardJobUseCase {
+	return jobUseCase.NewDiscardJobUseCase(jobRepo)
+}
+
+// ProvideRefreshStatsUseCase provides the use case recomputing the admin stats
+func ProvideRefreshStatsUseCase(
+	cfg *config.Config,
+	userQuery contract.UserQuery,
+	loginAttemptRepo contract.LoginAttemptRepository,
+	statsRepo contract.StatsRepository,
+) *statsUseCase.RefreshStatsUseCase {
+	return statsUseCase.NewRefreshStatsUseCase(userQuery, loginAttemptRepo, statsRepo, statsConfig.From(cfg).SignUpDays)
+}
+
+// ProvideGetStatsUseCase provides the admin stats use case
+func ProvideGetStatsUseCase(cfg *config.Config, statsRepo contract.StatsRepository, refresh *statsUseCase.RefreshStatsUseCase) *statsUseCase.GetStatsUseCase {
+	return statsUseCase.NewGetStatsUseCase(statsRepo, refresh, statsConfig.From(cfg).MaxAge)
+}
+
+// ProvideGetQueueStatsUseCase provides the scheduled job queue stats use case
+func ProvideGetQueueStatsUseCase(jobRepo contract.ScheduledJobRepository) *jobUseCase.GetQueueStatsUseCase {
+	return jobUseCase.NewGetQueueStatsUseCase(jobRepo)
+}
+
+// ProvideMailHandler provides the mail webhook handler
+func ProvideMailHandler(handleFeedbackWebhookUseCase *mailUseCase.HandleFeedbackWebhookUseCase) *mailHandler.MailHandler {
+	return mailHandler.NewMailHandler(mailHandler.NewMailHandlerArgs{
+		HandleFeedbackWebhookUseCase: handleFeedbackWebhookUseCase,
+	})
+}
+
+// ProvideOAuthClientRepository provides the OAuth client repository implementation
+func ProvideOAuthClientRepository() contract.OAuthClientRepository {
+	return infrastructure.NewOAuthClientRepository()
+}
+
+// ProvideAuthorizationCodeRepository provides the OIDC authorization code repository implementation
+func ProvideAuthorizationCodeRepository(clk clock.Clock) contract.AuthorizationCodeRepository {
+	return infrastructure.NewAuthorizationCodeRepository(clk)
+}
+
+// ProvideSAMLConnectionRepository provides the SAML connection repository implementation
+func ProvideSAMLConnectionRepository() contract.SAMLConnectionRepository {
+	return infrastructure.NewSAMLConnectionRepository()
+}
+
+// ProvideLoginStateRepository provides the federated sign-in state repository implementation
+func ProvideLoginStateRepository() contract.LoginStateRepository {
+	return infrastructure.NewLoginStateRepository()
+}
+
+// ProvideSAMLServiceProvider provides the SAML service provider implementation
+func ProvideSAMLServiceProvider(cfg *config.Config, clk clock.Clock) contract.SAMLServiceProvider {
+	return samlsp.NewServiceProvider(samlsp.ServiceProviderArgs{
+		BaseURL:   cfg.SAML.BaseURL,
+		ClockSkew: cfg.SAML.ClockSkew,
+		Clock:     clk,
+	})
+}
+
+// ProvideSubscriptionRepository provides the billing subscription repository implementation
+func ProvideSubscriptionRepository() contract.SubscriptionRepository {
+	return infrastructure.NewSubscriptionRepository()
+}
+
+// ProvideUsageRepository provides the aggregated usage repository implementation
+func ProvideUsageRepository() contract.UsageRepository {
+	return infrastructure.NewUsageRepository()
+}
+
+// ProvideAggregateUsageUseCase provides the usage aggregation use case
+func ProvideAggregateUsageUseCase(usageRepo contract.UsageRepository) *usageUseCase.AggregateUsageUseCase {
+	return usageUseCase.NewAggregateUsageUseCase(usageRepo)
+}
+
+// ProvideUsageMeter provides the usage meter and subscribes the aggregation
+// to the events it publishes
+func ProvideUsageMeter(bus *eventbus.Bus, aggregate *usageUseCase.AggregateUsageUseCase, deduper *dedupe.Deduper) contract.UsageMeter {
+	metering.SubscribeAggregation(bus, aggregate, deduper)
+	return metering.NewBusMeter(bus)
+}
+
+// ProvideAnalyticsTracker provides the product analytics tracker sending to
+// ANALYTICS_SINK, or one dropping every event when it is none
+func ProvideAnalyticsTracker(cfg *config.Config) (contract.AnalyticsTracker, error) {
+	var sink analytics.Sink
+	switch cfg.Analytics.Sink {
+	case "none":
+		return analytics.NopTracker{}, nil
+	case "segment":
+		s, err := analytics.NewSegmentSink(analytics.SegmentSinkArgs{
+			WriteKey: cfg.Analytics.SegmentWriteKey,
+			BaseURL:  cfg.Analytics.SegmentURL,
+			Timeout:  cfg.Analytics.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case "kafka":
+		s, err := analytics.NewKafkaSink(analytics.KafkaSinkArgs{
+			RESTURL: cfg.Analytics.KafkaRESTURL,
+			Topic:   cfg.Analytics.KafkaTopic,
+			Timeout: cfg.Analytics.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Analytics.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open analytics database: %w", err)
+		}
+		sink = analytics.NewPostgresSink(db)
+	default:
+		return nil, fmt.Errorf("ANALYTICS_SINK must be none, segment, kafka or postgres, got %q", cfg.Analytics.Sink)
+	}
+	return analytics.NewBatchingTracker(analytics.BatchingTrackerArgs{
+		Sink:          sink,
+		Buffer:        cfg.Analytics.Buffer,
+		BatchSize:     cfg.Analytics.BatchSize,
+		FlushInterval: cfg.Analytics.FlushInterval,
+		SendTimeout:   cfg.Analytics.Timeout,
+	}), nil
+}
+
+// ProvideGetCurrentUsageUseCase provides the current period usage use case
+func ProvideGetCurrentUsageUseCase(usageRepo contract.UsageRepository) *usageUseCase.GetCurrentUsageUseCase {
+	return usageUseCase.NewGetCurrentUsageUseCase(usageRepo)
+}
+
+// ProvideExportUsageUseCase provides the usage export use case
+func ProvideExportUsageUseCase(usageRepo contract.UsageRepository) *usageUseCase.ExportUsageUseCase {
+	return usageUseCase.NewExportUsageUseCase(usageRepo)
+}
+
+// ProvideBillingProvider provides the Stripe billing provider, the fake one
+// under MOCK_DEPS, or nil when billing is disabled
+func ProvideBillingProvider(cfg *config.Config, outbox *fake.Outbox) (contract.BillingProvider, error) {
+	if outbox != nil {
+		return fake.NewBillingProvider(outbox), nil
+	}
+	if !cfg.Billing.Enabled {
+		return nil, nil
+	}
+	provider, err := billing.NewStripeProvider(billing.StripeProviderArgs{
+		SecretKey:        cfg.Billing.StripeSecretKey,
+		WebhookSecret:    cfg.Billing.StripeWebhookSecret,
+		WebhookTolerance: cfg.Billing.WebhookTolerance,
+		Timeout:          cfg.Billing.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configure BILLING: %w", err)
+	}
+	return provider, nil
+}
+
+// ProvideEntitlementChecker provides the subscription-backed plan entitlement checker
+func ProvideEntitlementChecker(subscriptionRepo contract.SubscriptionRepository) contract.EntitlementChecker {
+	return billingUseCase.NewEntitlementService(subscriptionRepo)
+}
+
+// ProvidePlanGate provides the plan tier gate for endpoints and use cases
+func ProvidePlanGate(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	entitlements contract.EntitlementChecker,
+) (contract.PlanGate, error) {
+	gate, err := policy.NewPlanPolicy(policy.PlanPolicyArgs{
+		UserRepo:     userRepo,
+		Entitlements: entitlements,
+		Tiers:        cfg.Plan.Tiers,
+		Features:     cfg.Plan.Features,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configure PLAN: %w", err)
+	}
+	return gate, nil
+}
+
+// ProvideSMSSender provides the outgoing text message implementation
+func ProvideSMSSender(outbox *fake.Outbox) contract.SMSSender {
+	if outbox != nil {
+		return fake.NewSMSSender(outbox)
+	}
+	return sms.NewLogSender()
+}
+
+// ProvideLoginAttemptRepository provides the login history repository implementation
+func ProvideLoginAttemptRepository() contract.LoginAttemptRepository {
+	return infrastructure.NewLoginAttemptRepository()
+}
+
+// ProvideAuditLogRepository provides the audit log repository implementation,
+// streaming the events to the SIEM when AUDIT_STREAM_SINK is set
+func ProvideAuditLogRepository(geoLocator contract.GeoLocator, stream *siem.Streamer) contract.AuditLogRepository {
+	var repo contract.AuditLogRepository = infrastructure.NewAuditLogRepository()
+	if stream != nil {
+		repo = infrastructure.NewStreamingAuditLogRepository(repo, stream)
+	}
+	return infrastructure.NewLocatingAuditLogRepository(repo, geoLocator)
+}
+
+// ProvideGeoLocator provides the IP geolocation implementation
+func ProvideGeoLocator(cfg *config.Config) (contract.GeoLocator, error) {
+	if cfg.GeoIP.DBPath == "" {
+		return geo.NewNoopLocator(), nil
+	}
+	return geo.NewMaxMindLocator(geo.MaxMindLocatorArgs{
+		Path:           cfg.GeoIP.DBPath,
+		ReloadInterval: cfg.GeoIP.ReloadInterval,
 	})
 }
 
+// ProvideInvitationRepository provides the invitation repository implementation
+func ProvideInvitationRepository() contract.InvitationRepository {
+	return infrastructure.NewInvitationRepository()
+}
+
+// ProvideSagaStore provides the saga state repository implementation
+func ProvideSagaStore() saga.Store {
+	return infrastructure.NewSagaRepository()
+}
+
+// ProvidePasswordResetRepository provides the password reset repository implementation
+func ProvidePasswordResetRepository() contract.PasswordResetRepository {
+	return infrastructure.NewPasswordResetRepository()
+}
+
+// ProvideAccountRestoreRepository provides the account restore link repository implementation
+func ProvideAccountRestoreRepository() contract.AccountRestoreRepository {
+	return infrastructure.NewAccountRestoreRepository()
+}
+
+// ProvideTermsAcceptanceRepository provides the terms acceptance repository implementation
+func ProvideTermsAcceptanceRepository() contract.TermsAcceptanceRepository {
+	return infrastructure.NewTermsAcceptanceRepository()
+}
+
+// ProvideEmailChangeRepository provides the email change repository implementation
+func ProvideEmailChangeRepository() contract.EmailChangeRepository {
+	return infrastructure.NewEmailChangeRepository()
+}
+
+// ProvidePhoneOTPRepository provides the texted one-time code repository implementation
+func ProvidePhoneOTPRepository() contract.PhoneOTPRepository {
+	return infrastructure.NewPhoneOTPRepository()
+}
+
+// ProvideExternalIdentityRepository provides the external identity link repository implementation
+func ProvideExternalIdentityRepository() contract.ExternalIdentityRepository {
+	return infrastructure.NewExternalIdentityRepository()
+}
+
+// ProvideServiceAccountRepository provides the service account repository implementation
+func ProvideServiceAccountRepository() contract.ServiceAccountRepository {
+	return infrastructure.NewServiceAccountRepository()
+}
+
+// ProvideServiceAccountKeyRepository provides the service account key repository implementation
+func ProvideServiceAccountKeyRepository() contract.ServiceAccountKeyRepository {
+	return infrastructure.NewServiceAccountKeyRepository()
+}
+
+// ProvideJWTClient provides the JWT client configured from JWT settings
+func ProvideJWTClient(cfg *config.Config, clk clock.Clock) *jwt.Client {
+	return jwt.NewClient(jwt.ClientArgs{
+		SecretKey:       cfg.JWT.Secret,
+		AccessDuration:  cfg.JWT.AccessTTL,
+		RefreshDuration: cfg.JWT.RefreshTTL,
+		Issuer:          cfg.JWT.Issuer,
+		Audience:        cfg.JWT.Audience,
+		Leeway:          cfg.JWT.Leeway,
+		Clock:           clk,
+	})
+}
+
+// ProvideExternalTokenVerifier provides the verifier of the tokens of the
+// issuers in EXTERNAL_AUTH_ISSUERS, or nil when there are none
+func ProvideExternalTokenVerifier(cfg *config.Config, clk clock.Clock) *jwks.Verifier {
+	external := externalAuthConfig.From(cfg)
+	if len(external.Issuers) == 0 {
+		return nil
+	}
+	return jwks.NewVerifier(jwks.VerifierArgs{
+		Issuers:    external.Issuers,
+		Audience:   external.Audience,
+		Leeway:     cfg.JWT.Leeway,
+		MinRefresh: external.MinRefreshInterval,
+		MaxRefresh: external.RefreshInterval,
+		Timeout:    external.Timeout,
+		Clock:      clk,
+	})
+}
+
+// ProvideServiceAccounts lets organizations' service accounts authenticate
+// with their keys
+func ProvideServiceAccounts(accountRepo contract.ServiceAccountRepository, keyRepo contract.ServiceAccountKeyRepository) *middleware.ServiceAccounts {
+	return &middleware.ServiceAccounts{Accounts: accountRepo, Keys: keyRepo}
+}
+
+// ProvideExternalUsers lets users authenticate with the tokens of the
+// external issuers, or returns nil when there are none
+func ProvideExternalUsers(verifier *jwks.Verifier, resolve *authUseCase.ResolveExternalUserUseCase) *middleware.ExternalUsers {
+	if verifier == nil {
+		return nil
+	}
+	return &middleware.ExternalUsers{Verifier: verifier, Resolve: resolve.Execute}
+}
+
+// ProvideTokenIssuer provides the token issuer implementation
+func ProvideTokenIssuer(cfg *config.Config, client *jwt.Client, settingsRepo contract.OrganizationSettingsRepository) contract.TokenIssuer {
+	return token.NewJWTIssuer(client, cfg.Admin.Emails, settingsRepo)
+}
+
+// ProvideClientTokenIssuer provides the machine client token issuer implementation
+func ProvideClientTokenIssuer(client *jwt.Client) contract.ClientTokenIssuer {
+	return token.NewJWTIssuer(client, nil, nil)
+}
+
+// ProvideAccessTokenVerifier provides the access token verifier of token
+// introspection
+func ProvideAccessTokenVerifier(client *jwt.Client) contract.AccessTokenVerifier {
+	return token.NewJWTIssuer(client, nil, nil)
+}
+
+// ProvideOIDCTokenIssuer provides the OpenID Connect token issuer, or nil
+// when provider mode is disabled
+func ProvideOIDCTokenIssuer(cfg *config.Config, client *jwt.Client) (contract.OIDCTokenIssuer, error) {
+	if !cfg.OIDC.Enabled {
+		return nil, nil
+	}
+	key, ephemeral, err := token.LoadSigningKey(cfg.OIDC.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load OIDC_SIGNING_KEY_FILE: %w", err)
+	}
+	if ephemeral {
+		logger.L().Warn("OIDC_SIGNING_KEY_FILE not set, signing ID tokens with a generated key")
+	}
+	return token.NewOIDCIssuer(token.OIDCIssuerArgs{
+		JWTClient:  client,
+		SigningKey: key,
+		Issuer:     cfg.OIDC.Issuer,
+		IDTokenTTL: cfg.OIDC.IDTokenTTL,
+	}), nil
+}
+
+// ProvideDisposableEmailPolicy provides the disposable email block list,
+// read from SIGNUP_DISPOSABLE_DOMAINS_FILE when set
+func ProvideDisposableEmailPolicy(cfg *config.Config) (*policy.DisposableEmailPolicy, error) {
+	domains := policy.DefaultDisposableDomains
+	if path := cfg.SignUp.DisposableDomainsFile; path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read disposable domains: %w", err)
+		}
+		domains = strings.Split(string(b), "\n")
+	}
+	return policy.NewDisposableEmailPolicy(policy.NewDomainList(domains)), nil
+}
+
+// ProvidePasswordPolicy provides the check new passwords pass outside sign up
+func ProvidePasswordPolicy() contract.PasswordPolicy {
+	return policy.NewPasswordPolicy()
+}
+
+// ProvidePasswordHasher provides the bcrypt worker pool every password hash
+// and compare runs on
+func ProvidePasswordHasher(cfg *config.Config) contract.PasswordHasher {
+	return hasher.NewBcryptHasher(hasher.BcryptHasherArgs{
+		Workers: cfg.Password.HashWorkers,
+		Queue:   cfg.Password.HashQueue,
+	})
+}
+
+// ProvideCaptchaVerifier provides the CAPTCHA verifier, or nil when no
+// provider is configured or APP_ENV is not one of CAPTCHA_ENVIRONMENTS.
+// Under MOCK_DEPS it is the fake verifier
+func ProvideCaptchaVerifier(cfg *config.Config, outbox *fake.Outbox) (contract.CaptchaVerifier, error) {
+	if outbox != nil {
+		return fake.NewCaptchaVerifier(outbox), nil
+	}
+
+	c := cfg.Captcha
+	if c.Provider == captcha.PROVIDER_NONE {
+		return nil, nil
+	}
+	if len(c.Environments) > 0 && !slices.Contains(c.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+
+	verifier, err := captcha.NewSiteVerifier(captcha.SiteVerifierArgs{
+		Provider: c.Provider,
+		Secret:   c.Secret,
+		MinScore: c.MinScore,
+		Timeout:  c.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load CAPTCHA config: %w", err)
+	}
+	return verifier, nil
+}
+
+// ProvideCookieCodec provides the codec sealing cookies and the other values
+// handed to clients, keyed by COOKIE_SECRETS
+func ProvideCookieCodec(cfg *config.Config) (*securecookie.Codec, error) {
+	secrets := cfg.Cookie.Secrets
+	if len(secrets) == 0 && cfg.SignUpBot.FormSecret != "" {
+		secrets = []string{cfg.SignUpBot.FormSecret}
+	}
+	if len(secrets) == 0 {
+		generated, err := securetoken.New(32)
+		if err != nil {
+			return nil, err
+		}
+		secrets = []string{generated}
+		if cfg.App.Env == "production" {
+			logger.L().Warn("COOKIE_SECRETS not set, sealing cookies with a generated secret lost on restart")
+		}
+	}
+	keys := make([][]byte, len(secrets))
+	for i, s := range secrets {
+		keys[i] = []byte(s)
+	}
+	codec, err := securecookie.New(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("COOKIE_SECRETS: %w", err)
+	}
+	return codec, nil
+}
+
+// ProvideIPReputation provides the address reputation used by sign-up bot
+// detection
+func ProvideIPReputation(cfg *config.Config) (contract.IPReputation, error) {
+	networks, err := middleware.ParsePrefixes(cfg.SignUpBot.SuspiciousNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("parse SIGNUP_BOT_SUSPICIOUS_NETWORKS: %w", err)
+	}
+	return reputation.NewNetworkReputation(networks, cfg.SignUpBot.NetworkRisk), nil
+}
+
+// ProvideBotPolicy provides the sign-up bot detection policy. It is built
+// even when disabled, so forms can fetch their tokens ahead of enabling it.
+func ProvideBotPolicy(
+	cfg *config.Config,
+	cookies *securecookie.Codec,
+	ipReputation contract.IPReputation,
+	verifier contract.CaptchaVerifier,
+) (*policy.BotPolicy, error) {
+	c := cfg.SignUpBot
+	if c.CaptchaScore <= 0 || c.BlockScore < c.CaptchaScore {
+		return nil, errors.New("SIGNUP_BOT_CAPTCHA_SCORE must be positive and SIGNUP_BOT_BLOCK_SCORE at least as high")
+	}
+	return policy.NewBotPolicy(policy.BotPolicyArgs{
+		Cookies:       cookies,
+		MinSubmitTime: c.MinSubmitTime,
+		FormTTL:       c.FormTTL,
+		Reputation:    ipReputation,
+		Captcha:       verifier,
+		CaptchaScore:  c.CaptchaScore,
+		BlockScore:    c.BlockScore,
+	}), nil
+}
+
+// ProvideIssueSignUpFormUseCase provides the sign-up form token use case
+func ProvideIssueSignUpFormUseCase(bot *policy.BotPolicy) *authUseCase.IssueSignUpFormUseCase {
+	return authUseCase.NewIssueSignUpFormUseCase(bot)
+}
+
+// ProvideSignUpUseCase provides the sign up use case with the configured
+// email policies
+func ProvideSignUpUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+	bot *policy.BotPolicy,
+	hasher contract.PasswordHasher,
+	sagaStore saga.Store,
+	notifier contract.Notifier,
+	analyticsTracker contract.AnalyticsTracker,
+) *authUseCase.SignUpUseCase {
+	policies := signUpPolicies(cfg, userRepo, disposable, invitationRepo, termsRepo)
+	if cfg.SignUpBot.Enabled {
+		// Bots are turned away before the other policies reveal anything
+		// about the email.
+		policies = append([]contract.SignUpPolicy{bot}, policies...)
+	}
+	return authUseCase.NewSignUpUseCase(userRepo, hasher, sagaStore, notifier, analyticsTracker, policies...)
+}
+
+// ProvideUpgradeGuestUseCase provides the guest upgrade use case, bound by the
+// same policies as sign up
+func ProvideUpgradeGuestUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+	hasher contract.PasswordHasher,
+) *accountUseCase.UpgradeGuestUseCase {
+	return accountUseCase.NewUpgradeGuestUseCase(userRepo, hasher, signUpPolicies(cfg, userRepo, disposable, invitationRepo, termsRepo)...)
+}
+
+// ProvideListIdentitiesUseCase provides the use case listing a user's sign-in methods
+func ProvideListIdentitiesUseCase(userRepo contract.UserRepository) *accountUseCase.ListIdentitiesUseCase {
+	return accountUseCase.NewListIdentitiesUseCase(userRepo)
+}
+
+// ProvideLinkIdentityUseCase provides the use case linking a sign-in method to an account
+func ProvideLinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+	phoneVerification *phoneUseCase.RequestPhoneVerificationUseCase,
+) *accountUseCase.LinkIdentityUseCase {
+	return accountUseCase.NewLinkIdentityUseCase(userRepo, sessionRepo, hasher, passwordPolicy, phoneVerification)
+}
+
+// ProvideUnlinkIdentityUseCase provides the use case unlinking a sign-in method from an account
+func ProvideUnlinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+) *accountUseCase.UnlinkIdentityUseCase {
+	return accountUseCase.NewUnlinkIdentityUseCase(userRepo, sessionRepo, hasher)
+}
+
+// ProvideStartGuestSessionUseCase provides the anonymous session use case
+func ProvideStartGuestSessionUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+) *authUseCase.StartGuestSessionUseCase {
+	return authUseCase.NewStartGuestSessionUseCase(userRepo, sessionRepo, tokenIssuer, cfg.Guest.Enabled)
+}
+
+// signUpPolicies builds the configured policies every new account must pass.
+func signUpPolicies(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+) []contract.SignUpPolicy {
+	policies := []contract.SignUpPolicy{
+		policy.NewAllowedDomainsPolicy(policy.NewDomainList(cfg.SignUp.AllowedDomains)),
+		policy.NewPasswordPolicy(),
+	}
+	if cfg.SignUp.BlockDisposable {
+		policies = append(policies, disposable)
+	}
+	if cfg.SignUp.RejectPlusAliases {
+		policies = append(policies, policy.NewPlusAliasPolicy(userRepo))
+	}
+	if cfg.SignUp.InviteOnly {
+		policies = append(policies, policy.NewInvitePolicy(invitationRepo))
+	}
+	if cfg.Terms.RequireAtSignUp {
+		policies = append(policies, policy.NewTermsPolicy(termsRepo, currentTerms(cfg)))
+	}
+	return policies
+}
+
+// ProvideDeviceGuard provides the new-device sign-in detector
+func ProvideDeviceGuard(
+	cfg *config.Config,
+	knownDeviceRepo contract.KnownDeviceRepository,
+	deviceApprovalRepo contract.DeviceApprovalRepository,
+	mailer contract.Mailer,
+	geoLocator contract.GeoLocator,
+	settingsRepo contract.OrganizationSettingsRepository,
+) *authUseCase.DeviceGuard {
+	return authUseCase.NewDeviceGuard(authUseCase.DeviceGuardArgs{
+		KnownDeviceRepo:    knownDeviceRepo,
+		DeviceApprovalRepo: deviceApprovalRepo,
+		Mailer:             mailer,
+		GeoLocator:         geoLocator,
+		Organizations:      settingsRepo,
+		Enabled:            cfg.Device.Enabled,
+		RequireApproval:    cfg.Device.RequireApproval,
+		ApprovalTTL:        cfg.Device.ApprovalTTL,
+		LinkBaseURL:        cfg.Device.LinkBaseURL,
+	})
+}
+
+// ProvideLoginRecorder provides the sign-in attempt recorder
+func ProvideLoginRecorder(
+	loginAttemptRepo contract.LoginAttemptRepository,
+	geoLocator contract.GeoLocator,
+	analyticsTracker contract.AnalyticsTracker,
+) *authUseCase.LoginRecorder {
+	return authUseCase.NewLoginRecorder(loginAttemptRepo, geoLocator, analyticsTracker)
+}
+
+// ProvideSignInUseCase provides the sign in use case
+func ProvideSignInUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	hasher contract.PasswordHasher,
+	deviceGuard *authUseCase.DeviceGuard,
+	loginRecorder *authUseCase.LoginRecorder,
+) *authUseCase.SignInUseCase {
+	return authUseCase.NewSignInUseCase(authUseCase.SignInUseCaseArgs{
+		UserRepo:       userRepo,
+		SessionRepo:    sessionRepo,
+		Hasher:         hasher,
+		TokenIssuer:    tokenIssuer,
+		DeviceGuard:    deviceGuard,
+		LoginRecorder:  loginRecorder,
+		PasswordMaxAge: cfg.Password.MaxAge,
+	})
+}
+
+// ProvideRotateExpiredPasswordUseCase provides the expired password rotation use case
+func ProvideRotateExpiredPasswordUseCase(
+	signInUseCase *authUseCase.SignInUseCase,
+	userRepo contract.UserRepository,
+	passwordPolicy contract.PasswordPolicy,
+) *authUseCase.RotateExpiredPasswordUseCase {
+	return authUseCase.NewRotateExpiredPasswordUseCase(signInUseCase, userRepo, passwordPolicy)
+}
+
+// ProvideForcePasswordRotationUseCase provides the admin forced password rotation use case
+func ProvideForcePasswordRotationUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+) *adminUseCase.ForcePasswordRotationUseCase {
+	return adminUseCase.NewForcePasswordRotationUseCase(userRepo, sessionRepo)
+}
+
+// ProvideImpersonateUserUseCase provides the admin impersonation use case
+func ProvideImpersonateUserUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	auditLogRepo contract.AuditLogRepository,
+	tokenIssuer contract.TokenIssuer,
+	notifier contract.Notifier,
+) *adminUseCase.ImpersonateUserUseCase {
+	return adminUseCase.NewImpersonateUserUseCase(adminUseCase.ImpersonateUserUseCaseArgs{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		AuditLogRepo: auditLogRepo,
+		TokenIssuer:  tokenIssuer,
+		Notifier:     notifier,
+		TTL:          cfg.Impersonate.TTL,
+	})
+}
+
+// ProvideSetUserStatusUseCase provides the admin user status use case
+func ProvideSetUserStatusUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.SetUserStatusUseCase {
+	return adminUseCase.NewSetUserStatusUseCase(userRepo, sessionRepo, auditLogRepo)
+}
+
+// ProvideRevokeUserAccessUseCase provides the admin user access revocation use case
+func ProvideRevokeUserAccessUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.RevokeUserAccessUseCase {
+	return adminUseCase.NewRevokeUserAccessUseCase(userRepo, sessionRepo, tokenRepo, auditLogRepo)
+}
+
+// ProvideSetUserPlanUseCase provides the admin user quota plan use case
+func ProvideSetUserPlanUseCase(
+	userRepo contract.UserRepository,
+	auditLogRepo contract.AuditLogRepository,
+	limiter *quota.Limiter,
+) *adminUseCase.SetUserPlanUseCase {
+	return adminUseCase.NewSetUserPlanUseCase(userRepo, auditLogRepo, limiter.PlanNames())
+}
+
+// ProvideListUserTokensUseCase provides the admin user token inspection use case
+func ProvideListUserTokensUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	personalTokenRepo contract.PersonalAccessTokenRepository,
+) *adminUseCase.ListUserTokensUseCase {
+	return adminUseCase.NewListUserTokensUseCase(adminUseCase.ListUserTokensUseCaseArgs{
+		UserRepo:          userRepo,
+		PasswordResetRepo: passwordResetRepo,
+		EmailChangeRepo:   emailChangeRepo,
+		PersonalTokenRepo: personalTokenRepo,
+	})
+}
+
+// ProvideCreateServiceAccountUseCase provides the admin service account creation use case
+func ProvideCreateServiceAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	connectionRepo contract.SAMLConnectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceAccountUseCase.CreateAccountUseCase {
+	return serviceAccountUseCase.NewCreateAccountUseCase(accountRepo, connectionRepo, auditLogRepo)
+}
+
+// ProvideListServiceAccountsUseCase provides the admin service account listing use case
+func ProvideListServiceAccountsUseCase(accountRepo contract.ServiceAccountRepository) *serviceAccountUseCase.ListAccountsUseCase {
+	return serviceAccountUseCase.NewListAccountsUseCase(accountRepo)
+}
+
+// ProvideDeleteServiceAccountUseCase provides the admin service account deletion use case
+func ProvideDeleteServiceAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceAccountUseCase.DeleteAccountUseCase {
+	return serviceAccountUseCase.NewDeleteAccountUseCase(accountRepo, keyRepo, auditLogRepo)
+}
+
+// ProvideCreateServiceAccountKeyUseCase provides the admin service account key creation use case
+func ProvideCreateServiceAccountKeyUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceAccountUseCase.CreateKeyUseCase {
+	return serviceAccountUseCase.NewCreateKeyUseCase(accountRepo, keyRepo, auditLogRepo)
+}
+
+// ProvideListServiceAccountKeysUseCase provides the admin service account key listing use case
+func ProvideListServiceAccountKeysUseCase(accountRepo contract.ServiceAccountRepository, keyRepo contract.ServiceAccountKeyRepository) *serviceAccountUseCase.ListKeysUseCase {
+	return serviceAccountUseCase.NewListKeysUseCase(accountRepo, keyRepo)
+}
+
+// ProvideRevokeServiceAccountKeyUseCase provides the admin service account key revocation use case
+func ProvideRevokeServiceAccountKeyUseCase(keyRepo contract.ServiceAccountKeyRepository, auditLogRepo contract.AuditLogRepository) *serviceAccountUseCase.RevokeKeyUseCase {
+	return serviceAccountUseCase.NewRevokeKeyUseCase(keyRepo, auditLogRepo)
+}
+
+// ProvideSetTenantQuotaUseCase provides the admin tenant quota use case
+func ProvideSetTenantQuotaUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.SetTenantQuotaUseCase {
+	return adminUseCase.NewSetTenantQuotaUseCase(connectionRepo, auditLogRepo)
+}
+
+// ProvideOrganizationSettingsRepository provides the tenants' settings
+func ProvideOrganizationSettingsRepository() contract.OrganizationSettingsRepository {
+	return infrastructure.NewOrganizationSettingsRepository()
+}
+
+// ProvideGetOrganizationSettingsUseCase provides the admin tenant settings use case
+func ProvideGetOrganizationSettingsUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+) *adminUseCase.GetOrganizationSettingsUseCase {
+	return adminUseCase.NewGetOrganizationSettingsUseCase(connectionRepo, settingsRepo)
+}
+
+// ProvideUpdateOrganizationSettingsUseCase provides the admin tenant settings update use case
+func ProvideUpdateOrganizationSettingsUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.UpdateOrganizationSettingsUseCase {
+	return adminUseCase.NewUpdateOrganizationSettingsUseCase(connectionRepo, settingsRepo, auditLogRepo)
+}
+
+// ProvideGetMaintenanceUseCase provides the admin maintenance mode read use case
+func ProvideGetMaintenanceUseCase(maintenanceRepo contract.MaintenanceRepository) *adminUseCase.GetMaintenanceUseCase {
+	return adminUseCase.NewGetMaintenanceUseCase(maintenanceRepo)
+}
+
+// ProvideSetMaintenanceUseCase provides the admin maintenance mode toggle use case
+func ProvideSetMaintenanceUseCase(
+	maintenanceRepo contract.MaintenanceRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.SetMaintenanceUseCase {
+	return adminUseCase.NewSetMaintenanceUseCase(maintenanceRepo, auditLogRepo)
+}
+
+// ProvideAuthProtectionRepository provides the override of the credential
+// routes' adaptive protection, starting as AUTH_PROTECTION_MODE
+func ProvideAuthProtectionRepository(cfg *config.Config) (contract.AuthProtectionRepository, error) {
+	mode := authProtectionConfig.From(cfg).Mode
+	if !entity.IsAuthProtectionMode(mode) {
+		return nil, fmt.Errorf("AUTH_PROTECTION_MODE must be auto, on or off, got %q", mode)
+	}
+	return infrastructure.NewAuthProtectionRepository(entity.AuthProtection{Mode: mode}), nil
+}
+
+// ProvideGetAuthProtectionUseCase provides the admin auth protection override read use case
+func ProvideGetAuthProtectionUseCase(authProtectionRepo contract.AuthProtectionRepository) *adminUseCase.GetAuthProtectionUseCase {
+	return adminUseCase.NewGetAuthProtectionUseCase(authProtectionRepo)
+}
+
+// ProvideSetAuthProtectionUseCase provides the admin auth protection override use case
+func ProvideSetAuthProtectionUseCase(
+	authProtectionRepo contract.AuthProtectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *adminUseCase.SetAuthProtectionUseCase {
+	return adminUseCase.NewSetAuthProtectionUseCase(authProtectionRepo, auditLogRepo)
+}
+
+// ProvideDeprecationUsageRepository provides the usage of the deprecated
+// routes and fields, kept per instance
+func ProvideDeprecationUsageRepository() contract.DeprecationUsageRepository {
+	return infrastructure.NewDeprecationUsageRepository()
+}
+
+// ProvideListDeprecationUsageUseCase provides the admin deprecation usage report use case
+func ProvideListDeprecationUsageUseCase(deprecationUsageRepo contract.DeprecationUsageRepository) *adminUseCase.ListDeprecationUsageUseCase {
+	return adminUseCase.NewListDeprecationUsageUseCase(deprecationUsageRepo)
+}
+
+// ProvideListUsersUseCase provides the user listing use case
+func ProvideListUsersUseCase(
+	userQuery contract.UserQuery,
+	sessionRepo contract.SessionRepository,
+	samlConnRepo contract.SAMLConnectionRepository,
+) *adminUseCase.ListUsersUseCase {
+	return adminUseCase.NewListUsersUseCase(userQuery, sessionRepo, samlConnRepo)
+}
+
+// ProvideExportUsersUseCase provides the admin user export use case
+func ProvideExportUsersUseCase(userQuery contract.UserQuery) *adminUseCase.ExportUsersUseCase {
+	return adminUseCase.NewExportUsersUseCase(userQuery)
+}
+
+// ProvideExportAuditLogUseCase provides the admin audit log export use case
+func ProvideExportAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *adminUseCase.ExportAuditLogUseCase {
+	return adminUseCase.NewExportAuditLogUseCase(auditLogRepo)
+}
+
+// ProvideListAuditLogUseCase provides the audit log listing use case
+func ProvideListAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *adminUseCase.ListAuditLogUseCase {
+	return adminUseCase.NewListAuditLogUseCase(auditLogRepo)
+}
+
+// ProvideOTPService provides the texted one-time code issuer
+func ProvideOTPService(
+	cfg *config.Config,
+	otpRepo contract.PhoneOTPRepository,
+	smsSender contract.SMSSender,
+	clk clock.Clock,
+) *phoneUseCase.OTPService {
+	return phoneUseCase.NewOTPService(phoneUseCase.OTPServiceArgs{
+		OTPRepo:        otpRepo,
+		SMSSender:      smsSender,
+		TTL:            cfg.PhoneOTP.TTL,
+		CodeLength:     cfg.PhoneOTP.Length,
+		MaxAttempts:    cfg.PhoneOTP.MaxAttempts,
+		ResendInterval: cfg.PhoneOTP.ResendInterval,
+		MaxPerHour:     cfg.PhoneOTP.MaxPerHour,
+		Clock:          clk,
+	})
+}
+
+// ProvideRequestPhoneVerificationUseCase provides the phone number change use case
+func ProvideRequestPhoneVerificationUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	otpService *phoneUseCase.OTPService,
+	scheduler contract.JobScheduler,
+) *phoneUseCase.RequestPhoneVerificationUseCase {
+	return phoneUseCase.NewRequestPhoneVerificationUseCase(userRepo, otpService, scheduler, cfg.PhoneOTP.UnverifiedTTL)
+}
+
+// ProvideVerifyPhoneUseCase provides the phone number verification use case
+func ProvideVerifyPhoneUseCase(
+	userRepo contract.UserRepository,
+	otpService *phoneUseCase.OTPService,
+	analyticsTracker contract.AnalyticsTracker,
+	scheduler contract.JobScheduler,
+) *phoneUseCase.VerifyPhoneUseCase {
+	return phoneUseCase.NewVerifyPhoneUseCase(userRepo, otpService, analyticsTracker, scheduler)
+}
+
+// ProvideClearUnverifiedPhoneUseCase provides the unverified phone number clearing job
+func ProvideClearUnverifiedPhoneUseCase(userRepo contract.UserRepository) *phoneUseCase.ClearUnverifiedPhoneUseCase {
+	return phoneUseCase.NewClearUnverifiedPhoneUseCase(userRepo)
+}
+
+// ProvideRequestSignInCodeUseCase provides the texted sign-in code use case
+func ProvideRequestSignInCodeUseCase(
+	userRepo contract.UserRepository,
+	otpService *phoneUseCase.OTPService,
+) *authUseCase.RequestSignInCodeUseCase {
+	return authUseCase.NewRequestSignInCodeUseCase(userRepo, otpService)
+}
+
+// ProvideSignInWithCodeUseCase provides the phone code sign in use case
+func ProvideSignInWithCodeUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	otpService *phoneUseCase.OTPService,
+	deviceGuard *authUseCase.DeviceGuard,
+	loginRecorder *authUseCase.LoginRecorder,
+) *authUseCase.SignInWithCodeUseCase {
+	return authUseCase.NewSignInWithCodeUseCase(authUseCase.SignInWithCodeUseCaseArgs{
+		UserRepo:      userRepo,
+		SessionRepo:   sessionRepo,
+		TokenIssuer:   tokenIssuer,
+		OTPService:    otpService,
+		DeviceGuard:   deviceGuard,
+		LoginRecorder: loginRecorder,
+	})
+}
+
+// ProvideResolveExternalUserUseCase provides the use case mapping external subjects to local users
+func ProvideResolveExternalUserUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	identityRepo contract.ExternalIdentityRepository,
+	hasher contract.PasswordHasher,
+) *authUseCase.ResolveExternalUserUseCase {
+	external := externalAuthConfig.From(cfg)
+	return authUseCase.NewResolveExternalUserUseCase(authUseCase.ResolveExternalUserUseCaseArgs{
+		UserRepo:     userRepo,
+		IdentityRepo: identityRepo,
+		Hasher:       hasher,
+		Provision:    external.ProvisionUsers,
+		LinkByEmail:  external.LinkByEmail,
+	})
+}
+
+// ProvideSignInWithSAMLUseCase provides the SAML assertion consumer use case
+func ProvideSignInWithSAMLUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	connectionRepo contract.SAMLConnectionRepository,
+	serviceProvider contract.SAMLServiceProvider,
+	loginStateRepo contract.LoginStateRepository,
+	loginRecorder *authUseCase.LoginRecorder,
+	hasher contract.PasswordHasher,
+	cfg *config.Config,
+) *authUseCase.SignInWithSAMLUseCase {
+	return authUseCase.NewSignInWithSAMLUseCase(authUseCase.SignInWithSAMLUseCaseArgs{
+		UserRepo:          userRepo,
+		SessionRepo:       sessionRepo,
+		Hasher:            hasher,
+		TokenIssuer:       tokenIssuer,
+		ConnectionRepo:    connectionRepo,
+		ServiceProvider:   serviceProvider,
+		LoginStateRepo:    loginStateRepo,
+		LoginRecorder:     loginRecorder,
+		AllowIdPInitiated: cfg.SAML.AllowIdPInitiated,
+	})
+}
+
+// ProvideReviewDeviceUseCase provides the new device approve/deny use case
+func ProvideReviewDeviceUseCase(
+	knownDeviceRepo contract.KnownDeviceRepository,
+	deviceApprovalRepo contract.DeviceApprovalRepository,
+	sessionRepo contract.SessionRepository,
+) *authUseCase.ReviewDeviceUseCase {
+	return authUseCase.NewReviewDeviceUseCase(knownDeviceRepo, deviceApprovalRepo, sessionRepo)
+}
+
+// ProvideRefreshTokensUseCase provides the refresh tokens use case
+func ProvideRefreshTokensUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	tokenIssuer contract.TokenIssuer,
+) *authUseCase.RefreshTokensUseCase {
+	return authUseCase.NewRefreshTokensUseCase(userRepo, sessionRepo, settingsRepo, tokenIssuer)
+}
+
+// ProvideForgotPasswordUseCase provides the password reset request use case
+func ProvideForgotPasswordUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	mailer contract.Mailer,
+) *authUseCase.ForgotPasswordUseCase {
+	return authUseCase.NewForgotPasswordUseCase(userRepo, passwordResetRepo, mailer, cfg.Reset.LinkBaseURL, cfg.Reset.TTL)
+}
+
+// ProvideResetPasswordUseCase provides the password reset use case
+func ProvideResetPasswordUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+) *authUseCase.ResetPasswordUseCase {
+	return authUseCase.NewResetPasswordUseCase(userRepo, passwordResetRepo, sessionRepo, hasher, passwordPolicy)
+}
+
+// ProvideListSessionsUseCase provides the list sessions use case
+func ProvideListSessionsUseCase(sessionRepo contract.SessionRepository) *sessionUseCase.ListSessionsUseCase {
+	return sessionUseCase.NewListSessionsUseCase(sessionRepo)
+}
+
+// ProvideRevokeSessionUseCase provides the revoke session use case
+func ProvideRevokeSessionUseCase(sessionRepo contract.SessionRepository) *sessionUseCase.RevokeSessionUseCase {
+	return sessionUseCase.NewRevokeSessionUseCase(sessionRepo)
+}
+
+// ProvideListLoginHistoryUseCase provides the login history use case
+func ProvideListLoginHistoryUseCase(loginAttemptRepo contract.LoginAttemptRepository) *activityUseCase.ListLoginHistoryUseCase {
+	return activityUseCase.NewListLoginHistoryUseCase(loginAttemptRepo)
+}
+
+// ProvideGetTermsStatusUseCase provides the terms acceptance status use case
+func ProvideGetTermsStatusUseCase(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) *termsUseCase.GetTermsStatusUseCase {
+	return termsUseCase.NewGetTermsStatusUseCase(termsRepo, currentTerms(cfg))
+}
+
+// ProvideAcceptTermsUseCase provides the terms acceptance use case
+func ProvideAcceptTermsUseCase(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) *termsUseCase.AcceptTermsUseCase {
+	return termsUseCase.NewAcceptTermsUseCase(termsRepo, currentTerms(cfg))
+}
+
+// ProvideRequestEmailChangeUseCase provides the email change request use case
+func ProvideRequestEmailChangeUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	hasher contract.PasswordHasher,
+	mailer contract.Mailer,
+) *accountUseCase.RequestEmailChangeUseCase {
+	return accountUseCase.NewRequestEmailChangeUseCase(userRepo, emailChangeRepo, hasher, mailer, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.TTL)
+}
+
+// ProvideResendEmailChangeUseCase provides the use case resending email change confirmations
+func ProvideResendEmailChangeUseCase(
+	cfg *config.Config,
+	emailChangeRepo contract.EmailChangeRepository,
+	mailer contract.Mailer,
+) *accountUseCase.ResendEmailChangeUseCase {
+	return accountUseCase.NewResendEmailChangeUseCase(emailChangeRepo, mailer, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.TTL)
+}
+
+// ProvideConfirmEmailChangeUseCase provides the email change confirmation use case
+func ProvideConfirmEmailChangeUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	notifier contract.Notifier,
+	analyticsTracker contract.AnalyticsTracker,
+) *accountUseCase.ConfirmEmailChangeUseCase {
+	return accountUseCase.NewConfirmEmailChangeUseCase(userRepo, emailChangeRepo, notifier, analyticsTracker, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.RevertWindow)
+}
+
+// ProvideRevertEmailChangeUseCase provides the email change rollback use case
+func ProvideRevertEmailChangeUseCase(
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	sessionRepo contract.SessionRepository,
+) *accountUseCase.RevertEmailChangeUseCase {
+	return accountUseCase.NewRevertEmailChangeUseCase(userRepo, emailChangeRepo, sessionRepo)
+}
+
+// ProvideDeleteAccountUseCase provides the account deletion use case
+func ProvideDeleteAccountUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	restoreRepo contract.AccountRestoreRepository,
+	hasher contract.PasswordHasher,
+	mailer contract.Mailer,
+) *accountUseCase.DeleteAccountUseCase {
+	deletion := accountDeletionConfig.From(cfg)
+	return accountUseCase.NewDeleteAccountUseCase(accountUseCase.DeleteAccountUseCaseArgs{
+		UserRepo:       userRepo,
+		SessionRepo:    sessionRepo,
+		RestoreRepo:    restoreRepo,
+		Hasher:         hasher,
+		Mailer:         mailer,
+		LinkBaseURL:    deletion.LinkBaseURL,
+		RecoveryWindow: deletion.RecoveryWindow,
+	})
+}
+
+// ProvideRestoreAccountUseCase provides the deleted account restore use case
+func ProvideRestoreAccountUseCase(
+	userRepo contract.UserRepository,
+	restoreRepo contract.AccountRestoreRepository,
+) *accountUseCase.RestoreAccountUseCase {
+	return accountUseCase.NewRestoreAccountUseCase(userRepo, restoreRepo)
+}
+
+// ProvidePurgeDeletedAccountsUseCase provides the purge of deleted accounts past their recovery window
+func ProvidePurgeDeletedAccountsUseCase(
+	userRepo contract.UserRepository,
+	restoreRepo contract.AccountRestoreRepository,
+) *accountUseCase.PurgeDeletedAccountsUseCase {
+	return accountUseCase.NewPurgeDeletedAccountsUseCase(userRepo, restoreRepo)
+}
+
+func currentTerms(cfg *config.Config) entity.TermsVersions {
+	return entity.TermsVersions{Terms: cfg.Terms.Version, Privacy: cfg.Terms.PrivacyVersion}
+}
+
+// ProvideRevokeAllSessionsUseCase provides the sign-out-everywhere use case
+func ProvideRevokeAllSessionsUseCase(sessionRepo contract.SessionRepository) *sessionUseCase.RevokeAllSessionsUseCase {
+	return sessionUseCase.NewRevokeAllSessionsUseCase(sessionRepo)
+}
+
+// ProvideCreateTokenUseCase provides the personal access token minting use case
+func ProvideCreateTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *tokenUseCase.CreateTokenUseCase {
+	return tokenUseCase.NewCreateTokenUseCase(tokenRepo)
+}
+
+// ProvideListTokensUseCase provides the personal access token listing use case
+func ProvideListTokensUseCase(tokenRepo contract.PersonalAccessTokenRepository) *tokenUseCase.ListTokensUseCase {
+	return tokenUseCase.NewListTokensUseCase(tokenRepo)
+}
+
+// ProvideRevokeTokenUseCase provides the personal access token revocation use case
+func ProvideRevokeTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *tokenUseCase.RevokeTokenUseCase {
+	return tokenUseCase.NewRevokeTokenUseCase(tokenRepo)
+}
+
+// ProvideNotifier provides the notification fan-out, which applies the
+// users' notification preferences
+func ProvideNotifier(
+	prefsRepo contract.NotificationPreferencesRepository,
+	pendingRepo contract.PendingNotificationRepository,
+	inboxRepo contract.InAppNotificationRepository,
+	mailer contract.Mailer,
+	smsSender contract.SMSSender,
+	badges contract.BadgePublisher,
+) contract.Notifier {
+	return notificationUseCase.NewNotifier(notificationUseCase.NotifierArgs{
+		PrefsRepo:   prefsRepo,
+		PendingRepo: pendingRepo,
+		InboxRepo:   inboxRepo,
+		Mailer:      mailer,
+		SMSSender:   smsSender,
+		Badges:      badges,
+	})
+}
+
+// ProvideGetNotificationPreferencesUseCase provides the notification preferences lookup use case
+func ProvideGetNotificationPreferencesUseCase(prefsRepo contract.NotificationPreferencesRepository) *notificationUseCase.GetPreferencesUseCase {
+	return notificationUseCase.NewGetPreferencesUseCase(prefsRepo)
+}
+
+// ProvideUpdateNotificationPreferencesUseCase provides the notification preferences update use case
+func ProvideUpdateNotificationPreferencesUseCase(
+	prefsRepo contract.NotificationPreferencesRepository,
+	userRepo contract.UserRepository,
+) *notificationUseCase.UpdatePreferencesUseCase {
+	return notificationUseCase.NewUpdatePreferencesUseCase(prefsRepo, userRepo)
+}
+
+// ProvideListNotificationsUseCase provides the in-app inbox listing use case
+func ProvideListNotificationsUseCase(inboxRepo contract.InAppNotificationRepository) *notificationUseCase.ListNotificationsUseCase {
+	return notificationUseCase.NewListNotificationsUseCase(inboxRepo)
+}
+
+// ProvideCountUnreadUseCase provides the unread notification count use case
+func ProvideCountUnreadUseCase(inboxRepo contract.InAppNotificationRepository) *notificationUseCase.CountUnreadUseCase {
+	return notificationUseCase.NewCountUnreadUseCase(inboxRepo)
+}
+
+// ProvideMarkReadUseCase provides the use case marking a notification read
+func ProvideMarkReadUseCase(
+	inboxRepo contract.InAppNotificationRepository,
+	badges contract.BadgePublisher,
+) *notificationUseCase.MarkReadUseCase {
+	return notificationUseCase.NewMarkReadUseCase(inboxRepo, badges)
+}
+
+// ProvideMarkAllReadUseCase provides the use case marking the whole inbox read
+func ProvideMarkAllReadUseCase(
+	inboxRepo contract.InAppNotificationRepository,
+	badges contract.BadgePublisher,
+) *notificationUseCase.MarkAllReadUseCase {
+	return notificationUseCase.NewMarkAllReadUseCase(inboxRepo, badges)
+}
+
+// ProvideDeliverPendingNotificationsUseCase provides the held notification delivery use case
+func ProvideDeliverPendingNotificationsUseCase(
+	pendingRepo contract.PendingNotificationRepository,
+	mailer contract.Mailer,
+) *notificationUseCase.DeliverPendingNotificationsUseCase {
+	return notificationUseCase.NewDeliverPendingNotificationsUseCase(pendingRepo, mailer)
+}
+
+// ProvideUpdateUserUseCase provides the update user use case
+func ProvideUpdateUserUseCase(
+	userRepo contract.UserRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+) *userUseCase.UpdateUserUseCase {
+	return userUseCase.NewUpdateUserUseCase(userRepo, hasher, passwordPolicy)
+}
+
+// ProvideBulkUsersUseCase provides the admin bulk users use case
+func ProvideBulkUsersUseCase(
+	signUpUseCase *authUseCase.SignUpUseCase,
+	updateUserUseCase *userUseCase.UpdateUserUseCase,
+) *adminUseCase.BulkUsersUseCase {
+	return adminUseCase.NewBulkUsersUseCase(signUpUseCase, updateUserUseCase)
+}
+
+// ProvideAuthHandler provides the auth handler
+func ProvideAuthHandler(
+	signUpUseCase *authUseCase.SignUpUseCase,
+	issueSignUpFormUseCase *authUseCase.IssueSignUpFormUseCase,
+	signInUseCase *authUseCase.SignInUseCase,
+	refreshTokensUseCase *authUseCase.RefreshTokensUseCase,
+	reviewDeviceUseCase *authUseCase.ReviewDeviceUseCase,
+	forgotPasswordUseCase *authUseCase.ForgotPasswordUseCase,
+	resetPasswordUseCase *authUseCase.ResetPasswordUseCase,
+	confirmEmailChangeUseCase *accountUseCase.ConfirmEmailChangeUseCase,
+	revertEmailChangeUseCase *accountUseCase.RevertEmailChangeUseCase,
+	restoreAccountUseCase *accountUseCase.RestoreAccountUseCase,
+	requestSignInCodeUseCase *authUseCase.RequestSignInCodeUseCase,
+	signInWithCodeUseCase *authUseCase.SignInWithCodeUseCase,
+	startGuestSessionUseCase *authUseCase.StartGuestSessionUseCase,
+	rotateExpiredPasswordUseCase *authUseCase.RotateExpiredPasswordUseCase,
+) *auth.AuthHandler {
+	return auth.NewAuthHandler(auth.NewAuthHandlerArgs{
+		SignUpUseCase:                signUpUseCase,
+		IssueSignUpFormUseCase:       issueSignUpFormUseCase,
+		SignInUseCase:                signInUseCase,
+		RefreshTokensUseCase:         refreshTokensUseCase,
+		ReviewDeviceUseCase:          reviewDeviceUseCase,
+		ForgotPasswordUseCase:        forgotPasswordUseCase,
+		ResetPasswordUseCase:         resetPasswordUseCase,
+		ConfirmEmailChangeUseCase:    confirmEmailChangeUseCase,
+		RevertEmailChangeUseCase:     revertEmailChangeUseCase,
+		RestoreAccountUseCase:        restoreAccountUseCase,
+		RequestSignInCodeUseCase:     requestSignInCodeUseCase,
+		SignInWithCodeUseCase:        signInWithCodeUseCase,
+		StartGuestSessionUseCase:     startGuestSessionUseCase,
+		RotateExpiredPasswordUseCase: rotateExpiredPasswordUseCase,
+	})
+}
+
+// ProvideMeHandler provides the handler for the calling user's resources
+func ProvideMeHandler(
+	listSessionsUseCase *sessionUseCase.ListSessionsUseCase,
+	revokeSessionUseCase *sessionUseCase.RevokeSessionUseCase,
+	revokeAllSessionsUseCase *sessionUseCase.RevokeAllSessionsUseCase,
+	listLoginHistoryUseCase *activityUseCase.ListLoginHistoryUseCase,
+	getTermsStatusUseCase *termsUseCase.GetTermsStatusUseCase,
+	acceptTermsUseCase *termsUseCase.AcceptTermsUseCase,
+	requestEmailChangeUseCase *accountUseCase.RequestEmailChangeUseCase,
+	deleteAccountUseCase *accountUseCase.DeleteAccountUseCase,
+	requestPhoneVerificationUseCase *phoneUseCase.RequestPhoneVerificationUseCase,
+	verifyPhoneUseCase *phoneUseCase.VerifyPhoneUseCase,
+	upgradeGuestUseCase *accountUseCase.UpgradeGuestUseCase,
+	listIdentitiesUseCase *accountUseCase.ListIdentitiesUseCase,
+	linkIdentityUseCase *accountUseCase.LinkIdentityUseCase,
+	unlinkIdentityUseCase *accountUseCase.UnlinkIdentityUseCase,
+	getCurrentUsageUseCase *usageUseCase.GetCurrentUsageUseCase,
+	createTokenUseCase *tokenUseCase.CreateTokenUseCase,
+	listTokensUseCase *tokenUseCase.ListTokensUseCase,
+	revokeTokenUseCase *tokenUseCase.RevokeTokenUseCase,
+	getNotificationPreferencesUseCase *notificationUseCase.GetPreferencesUseCase,
+	updateNotificationPreferencesUseCase *notificationUseCase.UpdatePreferencesUseCase,
+	listNotificationsUseCase *notificationUseCase.ListNotificationsUseCase,
+	countUnreadUseCase *notificationUseCase.CountUnreadUseCase,
+	markReadUseCase *notificationUseCase.MarkReadUseCase,
+	markAllReadUseCase *notificationUseCase.MarkAllReadUseCase,
+	badges *realtime.BadgeHub,
+	presence *realtime.PresenceTracker,
+	drainer *drain.Drainer,
+) *me.MeHandler {
+	return me.NewMeHandler(me.NewMeHandlerArgs{
+		ListSessionsUseCase:                  listSessionsUseCase,
+		RevokeSessionUseCase:                 revokeSessionUseCase,
+		RevokeAllSessionsUseCase:             revokeAllSessionsUseCase,
+		ListLoginHistoryUseCase:              listLoginHistoryUseCase,
+		GetTermsStatusUseCase:                getTermsStatusUseCase,
+		AcceptTermsUseCase:                   acceptTermsUseCase,
+		RequestEmailChangeUseCase:            requestEmailChangeUseCase,
+		DeleteAccountUseCase:                 deleteAccountUseCase,
+		RequestPhoneVerificationUseCase:      requestPhoneVerificationUseCase,
+		VerifyPhoneUseCase:                   verifyPhoneUseCase,
+		UpgradeGuestUseCase:                  upgradeGuestUseCase,
+		ListIdentitiesUseCase:                listIdentitiesUseCase,
+		LinkIdentityUseCase:                  linkIdentityUseCase,
+		UnlinkIdentityUseCase:                unlinkIdentityUseCase,
+		GetCurrentUsageUseCase:               getCurrentUsageUseCase,
+		CreateTokenUseCase:                   createTokenUseCase,
+		ListTokensUseCase:                    listTokensUseCase,
+		RevokeTokenUseCase:                   revokeTokenUseCase,
+		GetNotificationPreferencesUseCase:    getNotificationPreferencesUseCase,
+		UpdateNotificationPreferencesUseCase: updateNotificationPreferencesUseCase,
+		ListNotificationsUseCase:             listNotificationsUseCase,
+		CountUnreadUseCase:                   countUnreadUseCase,
+		MarkReadUseCase:                      markReadUseCase,
+		MarkAllReadUseCase:                   markAllReadUseCase,
+		Badges:                               badges,
+		Presence:                             presence,
+		StreamsClosing:                       drainer.StreamsClosing(),
+	})
+}
+
+// ProvideAdminHandler provides the admin handler
+func ProvideAdminHandler(
+	bulkUsersUseCase *adminUseCase.BulkUsersUseCase,
+	forcePasswordRotationUseCase *adminUseCase.ForcePasswordRotationUseCase,
+	disposableDomainsUseCase *adminUseCase.DisposableDomainsUseCase,
+	mintInvitationUseCase *invitationUseCase.MintInvitationUseCase,
+	listInvitationsUseCase *invitationUseCase.ListInvitationsUseCase,
+	revokeInvitationUseCase *invitationUseCase.RevokeInvitationUseCase,
+	registerOAuthClientUseCase *oauthUseCase.RegisterClientUseCase,
+	listOAuthClientsUseCase *oauthUseCase.ListClientsUseCase,
+	revokeOAuthClientUseCase *oauthUseCase.RevokeClientUseCase,
+	registerSAMLConnectionUseCase *samlUseCase.RegisterConnectionUseCase,
+	listSAMLConnectionsUseCase *samlUseCase.ListConnectionsUseCase,
+	deleteSAMLConnectionUseCase *samlUseCase.DeleteConnectionUseCase,
+	setTenantQuotaUseCase *adminUseCase.SetTenantQuotaUseCase,
+	getOrganizationSettingsUseCase *adminUseCase.GetOrganizationSettingsUseCase,
+	updateOrganizationSettingsUseCase *adminUseCase.UpdateOrganizationSettingsUseCase,
+	getMaintenanceUseCase *adminUseCase.GetMaintenanceUseCase,
+	setMaintenanceUseCase *adminUseCase.SetMaintenanceUseCase,
+	getAuthProtectionUseCase *adminUseCase.GetAuthProtectionUseCase,
+	setAuthProtectionUseCase *adminUseCase.SetAuthProtectionUseCase,
+	listDeprecationUsageUseCase *adminUseCase.ListDeprecationUsageUseCase,
+	impersonateUserUseCase *adminUseCase.ImpersonateUserUseCase,
+	listAuditLogUseCase *adminUseCase.ListAuditLogUseCase,
+	listUsersUseCase *adminUseCase.ListUsersUseCase,
+	exportUsersUseCase *adminUseCase.ExportUsersUseCase,
+	exportAuditLogUseCase *adminUseCase.ExportAuditLogUseCase,
+	setUserStatusUseCase *adminUseCase.SetUserStatusUseCase,
+	revokeUserAccessUseCase *adminUseCase.RevokeUserAccessUseCase,
+	setUserPlanUseCase *adminUseCase.SetUserPlanUseCase,
+	listUserTokensUseCase *adminUseCase.ListUserTokensUseCase,
+	createServiceAccountUseCase *serviceAccountUseCase.CreateAccountUseCase,
+	listServiceAccountsUseCase *serviceAccountUseCase.ListAccountsUseCase,
+	deleteServiceAccountUseCase *serviceAccountUseCase.DeleteAccountUseCase,
+	createServiceAccountKeyUseCase *serviceAccountUseCase.CreateKeyUseCase,
+	listServiceAccountKeysUseCase *serviceAccountUseCase.ListKeysUseCase,
+	revokeServiceAccountKeyUseCase *serviceAccountUseCase.RevokeKeyUseCase,
+	exportUsageUseCase *usageUseCase.ExportUsageUseCase,
+	listEmailsUseCase *mailUseCase.ListEmailsUseCase,
+	getEmailUseCase *mailUseCase.GetEmailUseCase,
+	resendEmailUseCase *mailUseCase.ResendEmailUseCase,
+	listEmailSuppressionsUseCase *mailUseCase.ListEmailSuppressionsUseCase,
+	addEmailSuppressionUseCase *mailUseCase.AddEmailSuppressionUseCase,
+	removeEmailSuppressionUseCase *mailUseCase.RemoveEmailSuppressionUseCase,
+	listDeadJobsUseCase *jobUseCase.ListDeadJobsUseCase,
+	getJobUseCase *jobUseCase.GetJobUseCase,
+	requeueJobUseCase *jobUseCase.RequeueJobUseCase,
+	discardJobUseCase *jobUseCase.DiscardJobUseCase,
+	getQueueStatsUseCase *jobUseCase.GetQueueStatsUseCase,
+	listAdminTasksUseCase *jobUseCase.ListAdminTasksUseCase,
+	triggerAdminTaskUseCase *jobUseCase.TriggerAdminTaskUseCase,
+	getStatsUseCase *statsUseCase.GetStatsUseCase,
+	cfg *config.Config,
+	lifecycleRegistry *lifecycle.Registry,
+) *admin.AdminHandler {
+	return admin.NewAdminHandler(admin.NewAdminHandlerArgs{
+		BulkUsersUseCase:                  bulkUsersUseCase,
+		ForcePasswordRotationUseCase:      forcePasswordRotationUseCase,
+		DisposableDomainsUseCase:          disposableDomainsUseCase,
+		MintInvitationUseCase:             mintInvitationUseCase,
+		ListInvitationsUseCase:            listInvitationsUseCase,
+		RevokeInvitationUseCase:           revokeInvitationUseCase,
+		RegisterOAuthClientUseCase:        registerOAuthClientUseCase,
+		ListOAuthClientsUseCase:           listOAuthClientsUseCase,
+		RevokeOAuthClientUseCase:          revokeOAuthClientUseCase,
+		RegisterSAMLConnectionUseCase:     registerSAMLConnectionUseCase,
+		ListSAMLConnectionsUseCase:        listSAMLConnectionsUseCase,
+		DeleteSAMLConnectionUseCase:       deleteSAMLConnectionUseCase,
+		SetTenantQuotaUseCase:             setTenantQuotaUseCase,
+		GetOrganizationSettingsUseCase:    getOrganizationSettingsUseCase,
+		UpdateOrganizationSettingsUseCase: updateOrganizationSettingsUseCase,
+		GetMaintenanceUseCase:             getMaintenanceUseCase,
+		SetMaintenanceUseCase:             setMaintenanceUseCase,
+		GetAuthProtectionUseCase:          getAuthProtectionUseCase,
+		SetAuthProtectionUseCase:          setAuthProtectionUseCase,
+		ListDeprecationUsageUseCase:       listDeprecationUsageUseCase,
+		ExportUsageUseCase:                exportUsageUseCase,
+		ImpersonateUserUseCase:            impersonateUserUseCase,
+		ListAuditLogUseCase:               listAuditLogUseCase,
+		ListUsersUseCase:                  listUsersUseCase,
+		ExportUsersUseCase:                exportUsersUseCase,
+		ExportAuditLogUseCase:             exportAuditLogUseCase,
+		SetUserStatusUseCase:              setUserStatusUseCase,
+		RevokeUserAccessUseCase:           revokeUserAccessUseCase,
+		SetUserPlanUseCase:                setUserPlanUseCase,
+		ListUserTokensUseCase:             listUserTokensUseCase,
+		CreateServiceAccountUseCase:       createServiceAccountUseCase,
+		ListServiceAccountsUseCase:        listServiceAccountsUseCase,
+		DeleteServiceAccountUseCase:       deleteServiceAccountUseCase,
+		CreateServiceAccountKeyUseCase:    createServiceAccountKeyUseCase,
+		ListServiceAccountKeysUseCase:     listServiceAccountKeysUseCase,
+		RevokeServiceAccountKeyUseCase:    revokeServiceAccountKeyUseCase,
+		ListEmailsUseCase:                 listEmailsUseCase,
+		GetEmailUseCase:                   getEmailUseCase,
+		ResendEmailUseCase:                resendEmailUseCase,
+		ListEmailSuppressionsUseCase:      listEmailSuppressionsUseCase,
+		AddEmailSuppressionUseCase:        addEmailSuppressionUseCase,
+		RemoveEmailSuppressionUseCase:     removeEmailSuppressionUseCase,
+		ListDeadJobsUseCase:               listDeadJobsUseCase,
+		GetJobUseCase:                     getJobUseCase,
+		RequeueJobUseCase:                 requeueJobUseCase,
+		DiscardJobUseCase:                 discardJobUseCase,
+		GetQueueStatsUseCase:              getQueueStatsUseCase,
+		ListAdminTasksUseCase:             listAdminTasksUseCase,
+		TriggerAdminTaskUseCase:           triggerAdminTaskUseCase,
+		GetStatsUseCase:                   getStatsUseCase,
+		Config:                            cfg.Snapshot(),
+		Lifecycle:                         lifecycleRegistry,
+	})
+}
+
+// ProvideDisposableDomainsUseCase provides the disposable domain list admin use case
+func ProvideDisposableDomainsUseCase(disposable *policy.DisposableEmailPolicy) *adminUseCase.DisposableDomainsUseCase {
+	return adminUseCase.NewDisposableDomainsUseCase(disposable)
+}
+
+// ProvideMintInvitationUseCase provides the invitation minting use case
+func ProvideMintInvitationUseCase(cfg *config.Config, invitationRepo contract.InvitationRepository) *invitationUseCase.MintInvitationUseCase {
+	return invitationUseCase.NewMintInvitationUseCase(invitationRepo, cfg.SignUp.InviteTTL)
+}
+
+// ProvideListInvitationsUseCase provides the invitation listing use case
+func ProvideListInvitationsUseCase(invitationRepo contract.InvitationRepository) *invitationUseCase.ListInvitationsUseCase {
+	return invitationUseCase.NewListInvitationsUseCase(invitationRepo)
+}
+
+// ProvideRevokeInvitationUseCase provides the invitation revocation use case
+func ProvideRevokeInvitationUseCase(invitationRepo contract.InvitationRepository) *invitationUseCase.RevokeInvitationUseCase {
+	return invitationUseCase.NewRevokeInvitationUseCase(invitationRepo)
+}
+
+// ProvideRegisterOAuthClientUseCase provides the OAuth client registration use case
+func ProvideRegisterOAuthClientUseCase(clientRepo contract.OAuthClientRepository, limiter *quota.Limiter) *oauthUseCase.RegisterClientUseCase {
+	return oauthUseCase.NewRegisterClientUseCase(clientRepo, limiter.PlanNames())
+}
+
+// ProvideListOAuthClientsUseCase provides the OAuth client listing use case
+func ProvideListOAuthClientsUseCase(clientRepo contract.OAuthClientRepository) *oauthUseCase.ListClientsUseCase {
+	return oauthUseCase.NewListClientsUseCase(clientRepo)
+}
+
+// ProvideRevokeOAuthClientUseCase provides the OAuth client revocation use case
+func ProvideRevokeOAuthClientUseCase(clientRepo contract.OAuthClientRepository) *oauthUseCase.RevokeClientUseCase {
+	return oauthUseCase.NewRevokeClientUseCase(clientRepo)
+}
+
+// ProvideIssueClientTokenUseCase provides the client_credentials grant use case
+func ProvideIssueClientTokenUseCase(
+	clientRepo contract.OAuthClientRepository,
+	tokenIssuer contract.ClientTokenIssuer,
+) *oauthUseCase.IssueClientTokenUseCase {
+	return oauthUseCase.NewIssueClientTokenUseCase(clientRepo, tokenIssuer)
+}
+
+// ProvideIntrospectTokenUseCase provides the token introspection use case
+func ProvideIntrospectTokenUseCase(
+	clientRepo contract.OAuthClientRepository,
+	verifier contract.AccessTokenVerifier,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	sessionRepo contract.SessionRepository,
+	userRepo contract.UserRepository,
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+) *oauthUseCase.IntrospectTokenUseCase {
+	return oauthUseCase.NewIntrospectTokenUseCase(oauthUseCase.IntrospectTokenUseCaseArgs{
+		ClientRepo:  clientRepo,
+		Verifier:    verifier,
+		TokenRepo:   tokenRepo,
+		SessionRepo: sessionRepo,
+		UserRepo:    userRepo,
+		AccountRepo: accountRepo,
+		KeyRepo:     keyRepo,
+	})
+}
+
+// ProvideAuthorizeUseCase provides the OIDC authorization use case, or nil
+// when provider mode is disabled
+func ProvideAuthorizeUseCase(
+	cfg *config.Config,
+	clientRepo contract.OAuthClientRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	sessionRepo contract.SessionRepository,
+) *oauthUseCase.AuthorizeUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauthUseCase.NewAuthorizeUseCase(clientRepo, codeRepo, sessionRepo, cfg.OIDC.CodeTTL)
+}
+
+// ProvideExchangeCodeUseCase provides the authorization_code grant use case,
+// or nil when provider mode is disabled
+func ProvideExchangeCodeUseCase(
+	cfg *config.Config,
+	clientRepo contract.OAuthClientRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.OIDCTokenIssuer,
+) *oauthUseCase.ExchangeCodeUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauthUseCase.NewExchangeCodeUseCase(oauthUseCase.ExchangeCodeUseCaseArgs{
+		ClientRepo:  clientRepo,
+		CodeRepo:    codeRepo,
+		UserRepo:    userRepo,
+		SessionRepo: sessionRepo,
+		TokenIssuer: tokenIssuer,
+	})
+}
+
+// ProvideUserInfoUseCase provides the OIDC userinfo use case, or nil when
+// provider mode is disabled
+func ProvideUserInfoUseCase(cfg *config.Config, userRepo contract.UserRepository) *oauthUseCase.UserInfoUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauthUseCase.NewUserInfoUseCase(userRepo)
+}
+
+// ProvideDiscoveryUseCase provides the OIDC discovery use case, or nil when
+// provider mode is disabled
+func ProvideDiscoveryUseCase(cfg *config.Config, tokenIssuer contract.OIDCTokenIssuer) *oauthUseCase.DiscoveryUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauthUseCase.NewDiscoveryUseCase(tokenIssuer)
+}
+
+// ProvideRegisterSAMLConnectionUseCase provides the SAML connection registration use case
+func ProvideRegisterSAMLConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *samlUseCase.RegisterConnectionUseCase {
+	return samlUseCase.NewRegisterConnectionUseCase(connectionRepo)
+}
+
+// ProvideListSAMLConnectionsUseCase provides the SAML connection listing use case
+func ProvideListSAMLConnectionsUseCase(connectionRepo contract.SAMLConnectionRepository) *samlUseCase.ListConnectionsUseCase {
+	return samlUseCase.NewListConnectionsUseCase(connectionRepo)
+}
+
+// ProvideDeleteSAMLConnectionUseCase provides the SAML connection removal use case
+func ProvideDeleteSAMLConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *samlUseCase.DeleteConnectionUseCase {
+	return samlUseCase.NewDeleteConnectionUseCase(connectionRepo)
+}
+
+// ProvideSAMLMetadataUseCase provides the SAML service provider metadata use case
+func ProvideSAMLMetadataUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	serviceProvider contract.SAMLServiceProvider,
+) *samlUseCase.MetadataUseCase {
+	return samlUseCase.NewMetadataUseCase(connectionRepo, serviceProvider)
+}
+
+// ProvideStartSAMLLoginUseCase provides the SAML sign-in redirect use case
+func ProvideStartSAMLLoginUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	serviceProvider contract.SAMLServiceProvider,
+	loginStateRepo contract.LoginStateRepository,
+	cfg *config.Config,
+) *samlUseCase.StartLoginUseCase {
+	return samlUseCase.NewStartLoginUseCase(connectionRepo, settingsRepo, serviceProvider, loginStateRepo, cfg.SAML.LoginStateTTL)
+}
+
+// ProvideSAMLHandler provides the SAML endpoint handler
+func ProvideSAMLHandler(
+	cfg *config.Config,
+	metadataUseCase *samlUseCase.MetadataUseCase,
+	startLoginUseCase *samlUseCase.StartLoginUseCase,
+	signInWithSAMLUseCase *authUseCase.SignInWithSAMLUseCase,
+	cookies *securecookie.Codec,
+) *saml.SAMLHandler {
+	return saml.NewSAMLHandler(saml.NewSAMLHandlerArgs{
+		MetadataUseCase:       metadataUseCase,
+		StartLoginUseCase:     startLoginUseCase,
+		SignInWithSAMLUseCase: signInWithSAMLUseCase,
+		Cookies:               cookies,
+		StateTTL:              cfg.SAML.LoginStateTTL,
+		SuccessURL:            cfg.SAML.SuccessURL,
+	})
+}
+
+// ProvideCreateCheckoutSessionUseCase provides the checkout session use case,
+// or nil when billing is disabled
+func ProvideCreateCheckoutSessionUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	subscriptionRepo contract.SubscriptionRepository,
+	entitlements contract.EntitlementChecker,
+	provider contract.BillingProvider,
+) *billingUseCase.CreateCheckoutSessionUseCase {
+	if provider == nil {
+		return nil
+	}
+	return billingUseCase.NewCreateCheckoutSessionUseCase(billingUseCase.CreateCheckoutSessionUseCaseArgs{
+		UserRepo:         userRepo,
+		SubscriptionRepo: subscriptionRepo,
+		Entitlements:     entitlements,
+		Provider:         provider,
+		Prices:           cfg.Billing.Prices,
+		SuccessURL:       cfg.Billing.SuccessURL,
+		CancelURL:        cfg.Billing.CancelURL,
+	})
+}
+
+// ProvideHandleWebhookUseCase provides the billing webhook use case, or nil
+// when billing is disabled
+func ProvideHandleWebhookUseCase(
+	cfg *config.Config,
+	subscriptionRepo contract.SubscriptionRepository,
+	provider contract.BillingProvider,
+	deduper *dedupe.Deduper,
+) *billingUseCase.HandleWebhookUseCase {
+	if provider == nil {
+		return nil
+	}
+	return billingUseCase.NewHandleWebhookUseCase(subscriptionRepo, provider, deduper, cfg.Billing.Prices)
+}
+
+// ProvideGetSubscriptionUseCase provides the get subscription use case
+func ProvideGetSubscriptionUseCase(subscriptionRepo contract.SubscriptionRepository) *billingUseCase.GetSubscriptionUseCase {
+	return billingUseCase.NewGetSubscriptionUseCase(subscriptionRepo)
+}
+
+// ProvideBillingHandler provides the billing endpoint handler
+func ProvideBillingHandler(
+	createCheckoutSessionUseCase *billingUseCase.CreateCheckoutSessionUseCase,
+	handleWebhookUseCase *billingUseCase.HandleWebhookUseCase,
+	getSubscriptionUseCase *billingUseCase.GetSubscriptionUseCase,
+) *billingHandler.BillingHandler {
+	return billingHandler.NewBillingHandler(billingHandler.NewBillingHandlerArgs{
+		CreateCheckoutSessionUseCase: createCheckoutSessionUseCase,
+		HandleWebhookUseCase:         handleWebhookUseCase,
+		GetSubscriptionUseCase:       getSubscriptionUseCase,
+	})
+}
+
+// ProvideOAuthHandler provides the OAuth and OpenID Connect endpoint handler
+func ProvideOAuthHandler(
+	cfg *config.Config,
+	issueClientTokenUseCase *oauthUseCase.IssueClientTokenUseCase,
+	introspectTokenUseCase *oauthUseCase.IntrospectTokenUseCase,
+	authorizeUseCase *oauthUseCase.AuthorizeUseCase,
+	exchangeCodeUseCase *oauthUseCase.ExchangeCodeUseCase,
+	userInfoUseCase *oauthUseCase.UserInfoUseCase,
+	discoveryUseCase *oauthUseCase.DiscoveryUseCase,
+) *oauth.OAuthHandler {
+	return oauth.NewOAuthHandler(oauth.NewOAuthHandlerArgs{
+		IssueClientTokenUseCase: issueClientTokenUseCase,
+		IntrospectTokenUseCase:  introspectTokenUseCase,
+		AuthorizeUseCase:        authorizeUseCase,
+		ExchangeCodeUseCase:     exchangeCodeUseCase,
+		UserInfoUseCase:         userInfoUseCase,
+		DiscoveryUseCase:        discoveryUseCase,
+		LoginURL:                cfg.OIDC.LoginURL,
+	})
+}
+
+// ProvideCheckUsernameUseCase provides the username availability use case
+func ProvideCheckUsernameUseCase(userRepo contract.UserRepository) *userUseCase.CheckUsernameUseCase {
+	return userUseCase.NewCheckUsernameUseCase(userRepo)
+}
+
+// ProvideUsernameHandler provides the username handler
+func ProvideUsernameHandler(checkUsernameUseCase *userUseCase.CheckUsernameUseCase) *username.UsernameHandler {
+	return username.NewUsernameHandler(username.NewUsernameHandlerArgs{
+		CheckUsernameUseCase: checkUsernameUseCase,
+	})
+}
+
+// ProvideGetPresenceUseCase provides the user presence lookup use case
+func ProvideGetPresenceUseCase(userRepo contract.UserRepository, presenceStore contract.PresenceStore) *presenceUseCase.GetPresenceUseCase {
+	return presenceUseCase.NewGetPresenceUseCase(userRepo, presenceStore)
+}
+
+// ProvideUsersHandler provides the handler of the endpoints about other users
+func ProvideUsersHandler(getPresenceUseCase *presenceUseCase.GetPresenceUseCase) *users.UsersHandler {
+	return users.NewUsersHandler(users.NewUsersHandlerArgs{
+		GetPresenceUseCase: getPresenceUseCase,
+	})
+}
+
+// ProvideHealthHandler provides the health handler
+func ProvideHealthHandler(
+	registry *resilience.Registry,
+	modes FailModes,
+	relay *infrastructure.UserCacheRelay,
+	elector *leader.Elector,
+	lifecycleRegistry *lifecycle.Registry,
+	preStop *drain.PreStop,
+) *health.HealthHandler {
+	return health.NewHealthHandler(health.NewHealthHandlerArgs{
+		Resilience: registry,
+		Elector:    elector,
+		Lifecycle:  lifecycleRegistry,
+		PreStop:    preStop,
+		Degraded: func() []health.Degradation {
+			return degradations(registry, modes, relay)
+		},
+	})
+}
+
+// ProvideRouter provides the chi router with all routes registered
+func ProvideRouter(
+	cfg *config.Config,
+	authHandler *auth.AuthHandler,
+	adminHandler *admin.AdminHandler,
+	healthHandler *health.HealthHandler,
+	meHandler *me.MeHandler,
+	oauthHandler *oauth.OAuthHandler,
+	samlHandler *saml.SAMLHandler,
+	billingHandler *billingHandler.BillingHandler,
+	mailHandler *mailHandler.MailHandler,
+	debugHandler *debug.DebugHandler,
+	dashboardHandler *dashboard.DashboardHandler,
+	usernameHandler *username.UsernameHandler,
+	drainer *drain.Drainer,
+	loadShedder *middleware.LoadShedder,
+	credentialGate *middleware.CredentialGate,
+	authProtection *middleware.AuthProtection,
+	admission *middleware.AdmissionController,
+	maintenanceRepo contract.MaintenanceRepository,
+	deprecationUsageRepo contract.DeprecationUsageRepository,
+	jwtClient *jwt.Client,
+	externalVerifier *jwks.Verifier,
+	externalUsers *middleware.ExternalUsers,
+	serviceAccounts *middleware.ServiceAccounts,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+	clientRepo contract.OAuthClientRepository,
+	auditLogRepo contract.AuditLogRepository,
+	samlConnRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	geoLocator contract.GeoLocator,
+	limiter *quota.Limiter,
+	meter contract.UsageMeter,
+	planGate contract.PlanGate,
+	captchaVerifier contract.CaptchaVerifier,
+	cookies *securecookie.Codec,
+	deduper *dedupe.Deduper,
+	routes *router.RouteTable,
+	modes FailModes,
+) (*router.Routers, error) {
+	trustedProxies, err := middleware.ParsePrefixes(cfg.App.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("parse APP_TRUSTED_PROXIES: %w", err)
+	}
+	captcha := provideCaptcha(captchaVerifier)
+	signUpCaptcha := captcha
+	if cfg.SignUpBot.Enabled {
+		// Bot detection asks risky sign-ups only for a CAPTCHA.
+		signUpCaptcha = provideCaptcha(nil)
+	}
+	shadow, err := provideShadow(cfg.Shadow)
+	if err != nil {
+		return nil, err
+	}
+	faultInjector, err := provideFaultInjector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := provideContractValidator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	countryPolicy, err := provideCountryPolicy(cfg, geoLocator)
+	if err != nil {
+		return nil, err
+	}
+	rollouts, err := middleware.ParseRolloutRules(cfg.Rollout.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("parse ROLLOUT_RULES: %w", err)
+	}
+	encodings, err := response.ParseEncodings(cfg.App.ResponseEncodings)
+	if err != nil {
+		return nil, fmt.Errorf("parse APP_RESPONSE_ENCODINGS: %w", err)
+	}
+	authenticate, err := provideAuthenticate(cfg, jwtClient, userRepo, tokenRepo, externalUsers, serviceAccounts)
+	if err != nil {
+		return nil, err
+	}
+	requestBudget := cfg.App.RequestBudget
+	if requestBudget == 0 && cfg.App.WriteTimeout > 0 {
+		requestBudget = max(cfg.App.WriteTimeout-cfg.App.RequestBudgetReserve, cfg.App.WriteTimeout/2)
+	}
+
+	args := router.NewRouterArgs{
+		AuthHandler:      authHandler,
+		AdminHandler:     adminHandler,
+		HealthHandler:    healthHandler,
+		MeHandler:        meHandler,
+		UsernameHandler:  usernameHandler,
+		OAuthHandler:     oauthHandler,
+		SAMLHandler:      samlHandler,
+		BillingHandler:   billingHandler,
+		MailHandler:      mailHandler,
+		DebugHandler:     debugHandler,
+		DashboardHandler: dashboardHandler,
+		Drainer:          drainer,
+		LoadShedder:      loadShedder,
+		CredentialGate:   credentialGate,
+		AuthProtection:   authProtection,
+		Admission:        admission,
+		ReadOnly: middleware.ReadOnly(middleware.ReadOnlyArgs{
+			Maintenance: maintenanceRepo,
+			RetryAfter:  maintenanceConfig.From(cfg).RetryAfter,
+			AllowPaths:  maintenanceConfig.From(cfg).AllowPaths,
+		}),
+		RequestBudget:         requestBudget,
+		TrustedProxies:        trustedProxies,
+		Authenticate:          authenticate,
+		RequireSession:        middleware.RequireActiveSession(sessionRepo, settingsRepo, modes.Open(FEATURE_SESSIONS)),
+		AuthenticateClient:    middleware.AuthenticateClient(jwtClient, clientRepo, externalVerifier),
+		AuthenticateDelegated: middleware.AuthenticateDelegated(jwtClient),
+		AuditImpersonation:    middleware.AuditImpersonation(auditLogRepo),
+		AuditServiceAccounts:  middleware.AuditServiceAccounts(auditLogRepo),
+		Captcha:               captcha,
+		SignUpCaptcha:         signUpCaptcha,
+		CountryPolicy:         countryPolicy,
+		Quota:                 provideQuota(cfg, limiter, samlConnRepo, modes),
+		MeterUsage:            middleware.MeterUsage(meter),
+		PlanGuard:             middleware.NewPlanGuard(planGate),
+		RequireTerms:          provideRequireTerms(cfg, termsRepo),
+		Idempotency:           middleware.Idempotency(deduper),
+		Routes:                routes,
+		DeprecationUsage:      deprecationUsageRepo,
+		BatchMaxRequests:      cfg.App.BatchMaxRequests,
+		BatchConcurrency:      cfg.App.BatchConcurrency,
+		EnvelopeVersions:      cfg.App.EnvelopeVersions,
+		ResponseEncodings:     encodings,
+		AccessLog:             provideAccessLog(cfg.AccessLog),
+		BodyLogger:            provideBodyLogger(cfg.BodyLog),
+		Shadow:                shadow,
+		FaultInjector:         faultInjector,
+		Rollouts:              middleware.NewRollouts(rollouts, cookies, cfg.App.Env == "production"),
+		ContractValidator:     contract,
+		MetricsHandler:        provideMetricsHandler(cfg),
+		SeparateOps:           cfg.App.OpsAddr != "",
+	}
+	routers := &router.Routers{Public: router.NewRouter(args)}
+	if args.SeparateOps {
+		routers.Ops = router.NewOpsRouter(args)
+	}
+	return routers, nil
+}
+
+func provideMetricsHandler(cfg *config.Config) http.Handler {
+	if cfg.Metrics.Backend != "prometheus" {
+		return nil
+	}
+	return metrics.Handler()
+}
+
+func provideAccessLog(cfg config.AccessLogConfig) func(http.Handler) http.Handler {
+	return middleware.AccessLog(middleware.AccessLogArgs{
+		ExcludePaths:   cfg.ExcludePaths,
+		MaxQueryLength: cfg.MaxQueryLength,
+		Headers:        cfg.Headers,
+		DropHeaders:    cfg.DropHeaders,
+		Redactor:       redact.New(nil),
+	})
+}
+
+func provideBodyLogger(cfg config.BodyLogConfig) func(http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return nil
+	}
+	return middleware.BodyLogger(middleware.BodyLoggerArgs{
+		SampleRate: cfg.SampleRate,
+		OnError:    cfg.OnError,
+		MaxBytes:   cfg.MaxBytes,
+		Redactor:   redact.New(cfg.RedactFields),
+	})
+}
+
+// provideShadow returns the request mirroring middleware, or nil when no
+// shadow target is configured.
+func provideShadow(cfg config.ShadowConfig) (func(http.Handler) http.Handler, error) {
+	if cfg.TargetURL == "" || cfg.Percent <= 0 {
+		return nil, nil
+	}
+	target, err := url.Parse(cfg.TargetURL)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("parse SHADOW_TARGET_URL: must be an absolute URL, got %q", cfg.TargetURL)
+	}
+	return middleware.Shadow(middleware.ShadowArgs{
+		Target:       target,
+		Percent:      cfg.Percent,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		Timeout:      cfg.Timeout,
+		Concurrency:  cfg.Concurrency,
+	}), nil
+}
+
+// provideFaultInjector returns the fault injection middleware, or nil when
+// it is disabled or APP_ENV is not one of FAULT_ENVIRONMENTS.
+func provideFaultInjector(cfg *config.Config) (func(http.Handler) http.Handler, error) {
+	if !cfg.Fault.Enabled || !slices.Contains(cfg.Fault.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+	if cfg.App.Env == "production" {
+		return nil, errors.New("FAULT_ENABLED must not be used in production")
+	}
+	rules, err := middleware.ParseFaultRules(cfg.Fault.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("parse FAULT_RULES: %w", err)
+	}
+	return middleware.InjectFaults(middleware.FaultInjectorArgs{
+		Rules:        rules,
+		AllowHeaders: cfg.Fault.AllowHeaders,
+	}), nil
+}
+
+// provideContractValidator returns the request contract middleware, or nil
+// when CONTRACT_MODE is off or APP_ENV is not one of CONTRACT_ENVIRONMENTS.
+func provideContractValidator(cfg *config.Config) (func(http.Handler) http.Handler, error) {
+	mode := cfg.Contract.Mode
+	if mode == middleware.CONTRACT_MODE_OFF || !slices.Contains(cfg.Contract.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+	if mode != middleware.CONTRACT_MODE_REPORT && mode != middleware.CONTRACT_MODE_ENFORCE {
+		return nil, fmt.Errorf("CONTRACT_MODE must be off, report or enforce, got %q", mode)
+	}
+	if cfg.Contract.OpenAPIFile == "" {
+		return nil, errors.New("CONTRACT_OPENAPI_FILE is required when CONTRACT_MODE is set")
+	}
+	schemas := middleware.NewSchemaRegistry()
+	if err := schemas.RegisterOpenAPI(cfg.Contract.OpenAPIFile); err != nil {
+		return nil, fmt.Errorf("load CONTRACT_OPENAPI_FILE: %w", err)
+	}
+	logger.L().Infow("request contract validation enabled", "mode", mode, "endpoints", schemas.Len())
+	return schemas.Middleware(mode), nil
+}
+
+// provideCaptcha returns the CAPTCHA check of bot-prone endpoints, a
+// pass-through when no CAPTCHA is required.
+func provideCaptcha(verifier contract.CaptchaVerifier) func(http.Handler) http.Handler {
+	if verifier == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return middleware.RequireCaptcha(verifier)
+}
+
+// provideQuota returns the quota middleware, or nil when QUOTA_ENABLED is
+// off.
+func provideQuota(cfg *config.Config, limiter *quota.Limiter, samlConnRepo contract.SAMLConnectionRepository, modes FailModes) func(http.Handler) http.Handler {
+	if !cfg.Quota.Enabled {
+		return nil
+	}
+	return middleware.Quota(limiter, samlConnRepo, modes.Open(FEATURE_QUOTA))
+}
+
+// provideCountryPolicy returns the country access policy, or nil when
+// access is not restricted by country.
+func provideCountryPolicy(cfg *config.Config, geoLocator contract.GeoLocator) (func(http.Handler) http.Handler, error) {
+	if len(cfg.GeoIP.AllowCountries) == 0 && len(cfg.GeoIP.DenyCountries) == 0 {
+		return nil, nil
+	}
+	if cfg.GeoIP.DBPath == "" {
+		return nil, errors.New("GEOIP_ALLOW_COUNTRIES and GEOIP_DENY_COUNTRIES need GEOIP_DB_PATH")
+	}
+	return middleware.CountryPolicy(geoLocator, cfg.GeoIP.AllowCountries, cfg.GeoIP.DenyCountries), nil
+}
+
+// provideAuthenticate returns the authentication of each route group, trying
+// the strategies AUTH_STRATEGIES orders for it. The hmac strategy is only
+// available with AUTH_INTERNAL_SECRETS.
+func provideAuthenticate(
+	cfg *config.Config,
+	jwtClient *jwt.Client,
+	userRepo contract.UserRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	externalUsers *middleware.ExternalUsers,
+	serviceAccounts *middleware.ServiceAccounts,
+) (map[string]func(http.Handler) http.Handler, error) {
+	auth := authConfig.From(cfg)
+	available := []middleware.AuthStrategy{
+		&middleware.BearerJWT{JWT: jwtClient, External: externalUsers},
+		&middleware.APIKeys{Tokens: tokenRepo, ServiceAccounts: serviceAccounts},
+		&middleware.SessionCookie{JWT: jwtClient, Cookie: auth.CookieName},
+	}
+	if len(auth.InternalSecrets) > 0 {
+		secrets := make(map[string][]byte, len(auth.InternalSecrets))
+		for service, secret := range auth.InternalSecrets {
+			secrets[service] = []byte(secret)
+		}
+		available = append(available, &middleware.InternalHMAC{
+			Verifier: reqsign.NewVerifier(secrets, auth.InternalMaxSkew),
+			Roles:    auth.InternalRoles,
+		})
+	}
+	groups, err := middleware.ParseAuthStrategies(auth.Strategies, available)
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_STRATEGIES: %w", err)
+	}
+	authenticate := make(map[string]func(http.Handler) http.Handler, len(groups))
+	for group, strategies := range groups {
+		authenticate[group] = middleware.Authenticate(strategies, userRepo)
+	}
+	return authenticate, nil
+}
+
+// provideRequireTerms returns the terms gate, or nil when API access is not
+// blocked on acceptance. Accepting must stay reachable while blocked.
+func provideRequireTerms(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) func(http.Handler) http.Handler {
+	if !cfg.Terms.BlockUntilAccepted {
+		return nil
+	}
+	return middleware.RequireCurrentTerms(termsRepo, currentTerms(cfg), "/api/v1/me/terms")
+}
+
+// ProvideFixtureLoader provides the fixture loader used to seed the repositories
+func ProvideFixtureLoader(
+	userRepo contract.UserRepository,
+	subscriptionRepo contract.SubscriptionRepository,
+) *fixtures.Loader {
+	return newFixtureLoader(userRepo, subscriptionRepo)
+}
+
+// ProvideAuthModule provides the auth module
+func ProvideAuthModule(
+	cfg *config.Config,
+	hasher contract.PasswordHasher,
+	sessionRepo contract.SessionRepository,
+	purge *sessionUseCase.PurgeExpiredTokensUseCase,
+) *AuthModule {
+	return NewAuthModule(hasher, sessionRepo, purge, cfg.WarmUp.Hashes, cfg.WarmUp.Connections)
+}
+
+// ProvideAccountModule provides the account module
+func ProvideAccountModule(
+	cfg *config.Config,
+	resend *accountUseCase.ResendEmailChangeUseCase,
+	purgeDeleted *accountUseCase.PurgeDeletedAccountsUseCase,
+) (*AccountModule, error) {
+	deletion := accountDeletionConfig.From(cfg)
+	if deletion.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("ACCOUNT_DELETION_PURGE_INTERVAL must be positive, got %s", deletion.PurgeInterval)
+	}
+	return NewAccountModule(resend, purgeDeleted, deletion.PurgeInterval), nil
+}
+
+// ProvideBillingModule provides the billing module
+func ProvideBillingModule(cfg *config.Config, provider contract.BillingProvider) *BillingModule {
+	return NewBillingModule(provider, cfg.Billing.HealthInterval)
+}
+
+// ProvideMailModule provides the mail module
+func ProvideMailModule(
+	cfg *config.Config,
+	worker *mailer.QueueWorker,
+	emailQueueRepo contract.EmailQueueRepository,
+	templates *mailer.TemplateRegistry,
+) *MailModule {
+	return NewMailModule(worker, emailQueueRepo, templates, cfg.Mail.MaxLag)
+}
+
+// ProvideNotificationModule provides the notification module
+func ProvideNotificationModule(deliver *notificationUseCase.DeliverPendingNotificationsUseCase) *NotificationModule {
+	return NewNotificationModule(deliver)
+}
+
+// ProvideJobsModule provides the scheduled jobs module
+func ProvideJobsModule(scheduler *jobs.Scheduler, repo contract.ScheduledJobRepository) *JobsModule {
+	return NewJobsModule(scheduler, repo)
+}
+
+// ProvidePhoneModule provides the phone module
+func ProvidePhoneModule(clearUnverified *phoneUseCase.ClearUnverifiedPhoneUseCase) *PhoneModule {
+	return NewPhoneModule(clearUnverified)
+}
+
+// ProvideUsersModule provides the users module
+func ProvideUsersModule(relay *infrastructure.UserCacheRelay) *UsersModule {
+	return NewUsersModule(relay)
+}
+
+// ProvidePresenceModule provides the presence module
+func ProvidePresenceModule(cfg *config.Config, tracker *realtime.PresenceTracker, handler *users.UsersHandler) *PresenceModule {
+	return NewPresenceModule(tracker, handler, presenceConfig.From(cfg).TTL)
+}
+
+// ProvideStatsModule provides the stats module
+func ProvideStatsModule(cfg *config.Config, refresh *statsUseCase.RefreshStatsUseCase) *StatsModule {
+	return NewStatsModule(refresh, statsConfig.From(cfg).RefreshInterval)
+}
+
+// ProvideRouteTable provides the table of the routes modules declare with a
+// policy
+func ProvideRouteTable() *router.RouteTable {
+	return router.NewRouteTable()
+}
+
+// ProvideModules provides the modules whose checks, tasks and routes the
+// container registers
+func ProvideModules(
+	auth *AuthModule,
+	billing *BillingModule,
+	mail *MailModule,
+	notification *NotificationModule,
+	jobs *JobsModule,
+	phone *PhoneModule,
+	users *UsersModule,
+	stats *StatsModule,
+	account *AccountModule,
+	presence *PresenceModule,
+) []Module {
+	return []Module{auth, billing, mail, notification, jobs, phone, users, stats, account, presence}
+}
+
 // ProvideContainer provides the application container
-func ProvideContainer(r *chi.Mux) *Container {
+func ProvideContainer(
+	cfg *config.Config,
+	routers *router.Routers,
+	fixtureLoader *fixtures.Loader,
+	elector *leader.Elector,
+	drainer *drain.Drainer,
+	preStop *drain.PreStop,
+	bus *eventbus.Bus,
+	metricsBackend metrics.Backend,
+	logExporter *otlplog.Exporter,
+	lifecycleRegistry *lifecycle.Registry,
+	modules []Module,
+	analyticsTracker contract.AnalyticsTracker,
+	auditStream *siem.Streamer,
+	scheduler *jobs.Scheduler,
+	tasks *jobs.AdminTaskRegistry,
+	routes *router.RouteTable,
+	doctor *Doctor,
+) *Container {
+	lifecycleRegistry.Set(COMPONENT_EVENT_BUS, lifecycle.COMPONENT_UP, "")
+	warmer := newWarmer(cfg.WarmUp.Timeout, cfg.WarmUp.ReadinessDelay)
+	bg := &background{}
+	registerModules(lifecycleRegistry, elector, readinessPolicy(cfg), warmer, bg, scheduler, tasks, routes, modules)
 	return &Container{
-		Status: 1,
-		Router: r,
+		Lifecycle:   lifecycleRegistry,
+		Router:      routers.Public,
+		OpsRouter:   routers.Ops,
+		Fixtures:    fixtureLoader,
+		Elector:     elector,
+		Drainer:     drainer,
+		PreStop:     preStop,
+		EventBus:    bus,
+		Metrics:     metricsBackend,
+		LogExporter: logExporter,
+		Analytics:   analyticsTracker,
+		AuditStream: auditStream,
+		warmer:      warmer,
+		background:  bg,
+		doctor:      doctor,
 	}
 }
 
 // InitializeContainer initializes and returns the application container
 // This function is implemented by the wire code generator
-func InitializeContainer() (*Container, error) {
+func InitializeContainer(cfg *config.Config) (*Container, error) {
 	wire.Build(ProviderSet)
 	return nil, nil
 }
@@ -7,25 +7,435 @@
 package bootstrap
 
 import (
-	"github.com/go-chi/chi/v5"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/google/wire"
+	"github.com/haidang666/go-app/internal/config"
 	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/policy"
+	"github.com/haidang666/go-app/internal/domain/use_case/account"
+	"github.com/haidang666/go-app/internal/domain/use_case/activity"
+	"github.com/haidang666/go-app/internal/domain/use_case/admin"
 	"github.com/haidang666/go-app/internal/domain/use_case/auth"
+	billing2 "github.com/haidang666/go-app/internal/domain/use_case/billing"
+	"github.com/haidang666/go-app/internal/domain/use_case/invitation"
+	"github.com/haidang666/go-app/internal/domain/use_case/job"
+	"github.com/haidang666/go-app/internal/domain/use_case/mail"
+	"github.com/haidang666/go-app/internal/domain/use_case/notification"
+	"github.com/haidang666/go-app/internal/domain/use_case/oauth"
+	"github.com/haidang666/go-app/internal/domain/use_case/phone"
+	"github.com/haidang666/go-app/internal/domain/use_case/presence"
+	saml2 "github.com/haidang666/go-app/internal/domain/use_case/saml"
+	"github.com/haidang666/go-app/internal/domain/use_case/serviceaccount"
+	"github.com/haidang666/go-app/internal/domain/use_case/session"
+	"github.com/haidang666/go-app/internal/domain/use_case/stats"
+	"github.com/haidang666/go-app/internal/domain/use_case/terms"
+	token2 "github.com/haidang666/go-app/internal/domain/use_case/token"
+	"github.com/haidang666/go-app/internal/domain/use_case/usage"
+	"github.com/haidang666/go-app/internal/domain/use_case/user"
+	"github.com/haidang666/go-app/internal/infrastructure/analytics"
+	"github.com/haidang666/go-app/internal/infrastructure/billing"
+	"github.com/haidang666/go-app/internal/infrastructure/captcha"
+	"github.com/haidang666/go-app/internal/infrastructure/fake"
+	"github.com/haidang666/go-app/internal/infrastructure/geo"
+	"github.com/haidang666/go-app/internal/infrastructure/hasher"
+	admin2 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/admin"
 	auth2 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/auth"
+	billing3 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/billing"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/dashboard"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/debug"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/health"
+	mail2 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/mail"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/me"
+	oauth2 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/oauth"
+	saml3 "github.com/haidang666/go-app/internal/infrastructure/http/handlers/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/username"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/users"
+	"github.com/haidang666/go-app/internal/infrastructure/http/middleware"
 	"github.com/haidang666/go-app/internal/infrastructure/http/router"
+	"github.com/haidang666/go-app/internal/infrastructure/jobs"
+	"github.com/haidang666/go-app/internal/infrastructure/mailer"
+	"github.com/haidang666/go-app/internal/infrastructure/metering"
+	"github.com/haidang666/go-app/internal/infrastructure/realtime"
 	"github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/internal/infrastructure/reputation"
+	"github.com/haidang666/go-app/internal/infrastructure/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/sms"
+	"github.com/haidang666/go-app/internal/infrastructure/token"
+	"github.com/haidang666/go-app/pkg/anomaly"
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/dedupe"
+	"github.com/haidang666/go-app/pkg/drain"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/fixtures"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jwks"
+	"github.com/haidang666/go-app/pkg/jwt"
+	"github.com/haidang666/go-app/pkg/leader"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/lock"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/otlplog"
+	"github.com/haidang666/go-app/pkg/podinfo"
+	"github.com/haidang666/go-app/pkg/quota"
+	"github.com/haidang666/go-app/pkg/redact"
+	"github.com/haidang666/go-app/pkg/reqsign"
+	"github.com/haidang666/go-app/pkg/resilience"
+	"github.com/haidang666/go-app/pkg/resp"
+	"github.com/haidang666/go-app/pkg/saga"
+	"github.com/haidang666/go-app/pkg/securecookie"
+	"github.com/haidang666/go-app/pkg/securetoken"
+	"github.com/haidang666/go-app/pkg/siem"
+	"go.uber.org/zap/zapcore"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Injectors from wire.go:
 
 // InitializeContainer initializes and returns the application container
 // This function is implemented by the wire code generator
-func InitializeContainer() (*Container, error) {
-	userRepository := ProvideUserRepository()
-	signUpUseCase := ProvideSignUpUseCase(userRepository)
-	authHandler := ProvideAuthHandler(signUpUseCase)
-	mux := ProvideRouter(authHandler)
-	container := ProvideContainer(mux)
+func InitializeContainer(cfg *config.Config) (*Container, error) {
+	registry := ProvideResilienceRegistry()
+	bus := ProvideEventBus(cfg)
+	userCache := ProvideUserCache(cfg)
+	userRepository, err := ProvideUserRepository(cfg, registry, bus, userCache)
+	if err != nil {
+		return nil, err
+	}
+	disposableEmailPolicy, err := ProvideDisposableEmailPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	invitationRepository := ProvideInvitationRepository()
+	termsAcceptanceRepository := ProvideTermsAcceptanceRepository()
+	codec, err := ProvideCookieCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ipReputation, err := ProvideIPReputation(cfg)
+	if err != nil {
+		return nil, err
+	}
+	outbox, err := ProvideOutbox(cfg)
+	if err != nil {
+		return nil, err
+	}
+	captchaVerifier, err := ProvideCaptchaVerifier(cfg, outbox)
+	if err != nil {
+		return nil, err
+	}
+	botPolicy, err := ProvideBotPolicy(cfg, codec, ipReputation, captchaVerifier)
+	if err != nil {
+		return nil, err
+	}
+	passwordHasher := ProvidePasswordHasher(cfg)
+	store := ProvideSagaStore()
+	notificationPreferencesRepository := ProvideNotificationPreferencesRepository()
+	pendingNotificationRepository := ProvidePendingNotificationRepository()
+	inAppNotificationRepository := ProvideInAppNotificationRepository()
+	emailQueueRepository := ProvideEmailQueueRepository()
+	emailSuppressionRepository := ProvideEmailSuppressionRepository()
+	templateRegistry, err := ProvideEmailTemplates(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mailTransport := ProvideMailTransport(cfg, outbox)
+	deliverQueuedEmailsUseCase := ProvideDeliverQueuedEmailsUseCase(cfg, emailQueueRepository, emailSuppressionRepository, mailTransport)
+	queueWorker := ProvideMailQueueWorker(cfg, deliverQueuedEmailsUseCase)
+	mailer := ProvideMailer(emailQueueRepository, emailSuppressionRepository, templateRegistry, queueWorker)
+	smsSender := ProvideSMSSender(outbox)
+	badgeHub := ProvideBadgeHub(bus)
+	badgePublisher := ProvideBadgePublisher(badgeHub)
+	notifier := ProvideNotifier(notificationPreferencesRepository, pendingNotificationRepository, inAppNotificationRepository, mailer, smsSender, badgePublisher)
+	analyticsTracker, err := ProvideAnalyticsTracker(cfg)
+	if err != nil {
+		return nil, err
+	}
+	signUpUseCase := ProvideSignUpUseCase(cfg, userRepository, disposableEmailPolicy, invitationRepository, termsAcceptanceRepository, botPolicy, passwordHasher, store, notifier, analyticsTracker)
+	issueSignUpFormUseCase := ProvideIssueSignUpFormUseCase(botPolicy)
+	sessionRepository, err := ProvideSessionRepository(cfg, registry)
+	if err != nil {
+		return nil, err
+	}
+	clock := ProvideClock()
+	client := ProvideJWTClient(cfg, clock)
+	organizationSettingsRepository := ProvideOrganizationSettingsRepository()
+	tokenIssuer := ProvideTokenIssuer(cfg, client, organizationSettingsRepository)
+	knownDeviceRepository := ProvideKnownDeviceRepository()
+	deviceApprovalRepository := ProvideDeviceApprovalRepository()
+	geoLocator, err := ProvideGeoLocator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	deviceGuard := ProvideDeviceGuard(cfg, knownDeviceRepository, deviceApprovalRepository, mailer, geoLocator, organizationSettingsRepository)
+	loginAttemptRepository := ProvideLoginAttemptRepository()
+	loginRecorder := ProvideLoginRecorder(loginAttemptRepository, geoLocator, analyticsTracker)
+	signInUseCase := ProvideSignInUseCase(cfg, userRepository, sessionRepository, tokenIssuer, passwordHasher, deviceGuard, loginRecorder)
+	refreshTokensUseCase := ProvideRefreshTokensUseCase(userRepository, sessionRepository, organizationSettingsRepository, tokenIssuer)
+	reviewDeviceUseCase := ProvideReviewDeviceUseCase(knownDeviceRepository, deviceApprovalRepository, sessionRepository)
+	passwordResetRepository := ProvidePasswordResetRepository()
+	forgotPasswordUseCase := ProvideForgotPasswordUseCase(cfg, userRepository, passwordResetRepository, mailer)
+	passwordPolicy := ProvidePasswordPolicy()
+	resetPasswordUseCase := ProvideResetPasswordUseCase(userRepository, passwordResetRepository, sessionRepository, passwordHasher, passwordPolicy)
+	emailChangeRepository := ProvideEmailChangeRepository()
+	confirmEmailChangeUseCase := ProvideConfirmEmailChangeUseCase(cfg, userRepository, emailChangeRepository, notifier, analyticsTracker)
+	revertEmailChangeUseCase := ProvideRevertEmailChangeUseCase(userRepository, emailChangeRepository, sessionRepository)
+	accountRestoreRepository := ProvideAccountRestoreRepository()
+	restoreAccountUseCase := ProvideRestoreAccountUseCase(userRepository, accountRestoreRepository)
+	phoneOTPRepository := ProvidePhoneOTPRepository()
+	otpService := ProvideOTPService(cfg, phoneOTPRepository, smsSender, clock)
+	requestSignInCodeUseCase := ProvideRequestSignInCodeUseCase(userRepository, otpService)
+	signInWithCodeUseCase := ProvideSignInWithCodeUseCase(userRepository, sessionRepository, tokenIssuer, otpService, deviceGuard, loginRecorder)
+	startGuestSessionUseCase := ProvideStartGuestSessionUseCase(cfg, userRepository, sessionRepository, tokenIssuer)
+	rotateExpiredPasswordUseCase := ProvideRotateExpiredPasswordUseCase(signInUseCase, userRepository, passwordPolicy)
+	authHandler := ProvideAuthHandler(signUpUseCase, issueSignUpFormUseCase, signInUseCase, refreshTokensUseCase, reviewDeviceUseCase, forgotPasswordUseCase, resetPasswordUseCase, confirmEmailChangeUseCase, revertEmailChangeUseCase, restoreAccountUseCase, requestSignInCodeUseCase, signInWithCodeUseCase, startGuestSessionUseCase, rotateExpiredPasswordUseCase)
+	updateUserUseCase := ProvideUpdateUserUseCase(userRepository, passwordHasher, passwordPolicy)
+	bulkUsersUseCase := ProvideBulkUsersUseCase(signUpUseCase, updateUserUseCase)
+	forcePasswordRotationUseCase := ProvideForcePasswordRotationUseCase(userRepository, sessionRepository)
+	disposableDomainsUseCase := ProvideDisposableDomainsUseCase(disposableEmailPolicy)
+	mintInvitationUseCase := ProvideMintInvitationUseCase(cfg, invitationRepository)
+	listInvitationsUseCase := ProvideListInvitationsUseCase(invitationRepository)
+	revokeInvitationUseCase := ProvideRevokeInvitationUseCase(invitationRepository)
+	oAuthClientRepository := ProvideOAuthClientRepository()
+	quotaStore, err := ProvideQuotaStore(cfg, registry)
+	if err != nil {
+		return nil, err
+	}
+	limiter, err := ProvideQuotaLimiter(cfg, quotaStore, clock)
+	if err != nil {
+		return nil, err
+	}
+	registerClientUseCase := ProvideRegisterOAuthClientUseCase(oAuthClientRepository, limiter)
+	listClientsUseCase := ProvideListOAuthClientsUseCase(oAuthClientRepository)
+	revokeClientUseCase := ProvideRevokeOAuthClientUseCase(oAuthClientRepository)
+	samlConnectionRepository := ProvideSAMLConnectionRepository()
+	registerConnectionUseCase := ProvideRegisterSAMLConnectionUseCase(samlConnectionRepository)
+	listConnectionsUseCase := ProvideListSAMLConnectionsUseCase(samlConnectionRepository)
+	deleteConnectionUseCase := ProvideDeleteSAMLConnectionUseCase(samlConnectionRepository)
+	streamer, err := ProvideAuditStream(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auditLogRepository := ProvideAuditLogRepository(geoLocator, streamer)
+	setTenantQuotaUseCase := ProvideSetTenantQuotaUseCase(samlConnectionRepository, auditLogRepository)
+	getOrganizationSettingsUseCase := ProvideGetOrganizationSettingsUseCase(samlConnectionRepository, organizationSettingsRepository)
+	updateOrganizationSettingsUseCase := ProvideUpdateOrganizationSettingsUseCase(samlConnectionRepository, organizationSettingsRepository, auditLogRepository)
+	maintenanceRepository, err := ProvideMaintenanceRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+	getMaintenanceUseCase := ProvideGetMaintenanceUseCase(maintenanceRepository)
+	setMaintenanceUseCase := ProvideSetMaintenanceUseCase(maintenanceRepository, auditLogRepository)
+	authProtectionRepository, err := ProvideAuthProtectionRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+	getAuthProtectionUseCase := ProvideGetAuthProtectionUseCase(authProtectionRepository)
+	setAuthProtectionUseCase := ProvideSetAuthProtectionUseCase(authProtectionRepository, auditLogRepository)
+	deprecationUsageRepository := ProvideDeprecationUsageRepository()
+	listDeprecationUsageUseCase := ProvideListDeprecationUsageUseCase(deprecationUsageRepository)
+	impersonateUserUseCase := ProvideImpersonateUserUseCase(cfg, userRepository, sessionRepository, auditLogRepository, tokenIssuer, notifier)
+	listAuditLogUseCase := ProvideListAuditLogUseCase(auditLogRepository)
+	userQuery := ProvideUserQuery(bus)
+	listUsersUseCase := ProvideListUsersUseCase(userQuery, sessionRepository, samlConnectionRepository)
+	exportUsersUseCase := ProvideExportUsersUseCase(userQuery)
+	exportAuditLogUseCase := ProvideExportAuditLogUseCase(auditLogRepository)
+	setUserStatusUseCase := ProvideSetUserStatusUseCase(userRepository, sessionRepository, auditLogRepository)
+	personalAccessTokenRepository := ProvidePersonalAccessTokenRepository()
+	revokeUserAccessUseCase := ProvideRevokeUserAccessUseCase(userRepository, sessionRepository, personalAccessTokenRepository, auditLogRepository)
+	setUserPlanUseCase := ProvideSetUserPlanUseCase(userRepository, auditLogRepository, limiter)
+	listUserTokensUseCase := ProvideListUserTokensUseCase(userRepository, passwordResetRepository, emailChangeRepository, personalAccessTokenRepository)
+	serviceAccountRepository := ProvideServiceAccountRepository()
+	createAccountUseCase := ProvideCreateServiceAccountUseCase(serviceAccountRepository, samlConnectionRepository, auditLogRepository)
+	listAccountsUseCase := ProvideListServiceAccountsUseCase(serviceAccountRepository)
+	serviceAccountKeyRepository := ProvideServiceAccountKeyRepository()
+	deleteAccountUseCase := ProvideDeleteServiceAccountUseCase(serviceAccountRepository, serviceAccountKeyRepository, auditLogRepository)
+	createKeyUseCase := ProvideCreateServiceAccountKeyUseCase(serviceAccountRepository, serviceAccountKeyRepository, auditLogRepository)
+	listKeysUseCase := ProvideListServiceAccountKeysUseCase(serviceAccountRepository, serviceAccountKeyRepository)
+	revokeKeyUseCase := ProvideRevokeServiceAccountKeyUseCase(serviceAccountKeyRepository, auditLogRepository)
+	usageRepository := ProvideUsageRepository()
+	exportUsageUseCase := ProvideExportUsageUseCase(usageRepository)
+	listEmailsUseCase := ProvideListEmailsUseCase(emailQueueRepository)
+	getEmailUseCase := ProvideGetEmailUseCase(emailQueueRepository)
+	resendEmailUseCase := ProvideResendEmailUseCase(emailQueueRepository, queueWorker)
+	listEmailSuppressionsUseCase := ProvideListEmailSuppressionsUseCase(emailSuppressionRepository)
+	addEmailSuppressionUseCase := ProvideAddEmailSuppressionUseCase(emailSuppressionRepository, auditLogRepository)
+	removeEmailSuppressionUseCase := ProvideRemoveEmailSuppressionUseCase(emailSuppressionRepository, auditLogRepository)
+	scheduledJobRepository, err := ProvideScheduledJobRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+	listDeadJobsUseCase := ProvideListDeadJobsUseCase(scheduledJobRepository)
+	getJobUseCase := ProvideGetJobUseCase(scheduledJobRepository)
+	scheduler := ProvideJobScheduler(cfg, scheduledJobRepository, clock)
+	requeueJobUseCase := ProvideRequeueJobUseCase(scheduledJobRepository, scheduler)
+	discardJobUseCase := ProvideDiscardJobUseCase(scheduledJobRepository)
+	getQueueStatsUseCase := ProvideGetQueueStatsUseCase(scheduledJobRepository)
+	adminTaskRegistry := ProvideAdminTaskRegistry()
+	contractAdminTaskRegistry := ProvideAdminTaskRegistryContract(adminTaskRegistry)
+	listAdminTasksUseCase := ProvideListAdminTasksUseCase(contractAdminTaskRegistry)
+	triggerAdminTaskUseCase := ProvideTriggerAdminTaskUseCase(contractAdminTaskRegistry, scheduledJobRepository, auditLogRepository, scheduler)
+	statsRepository, err := ProvideStatsRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+	refreshStatsUseCase := ProvideRefreshStatsUseCase(cfg, userQuery, loginAttemptRepository, statsRepository)
+	getStatsUseCase := ProvideGetStatsUseCase(cfg, statsRepository, refreshStatsUseCase)
+	lifecycleRegistry := ProvideLifecycle(cfg, registry)
+	adminHandler := ProvideAdminHandler(bulkUsersUseCase, forcePasswordRotationUseCase, disposableDomainsUseCase, mintInvitationUseCase, listInvitationsUseCase, revokeInvitationUseCase, registerClientUseCase, listClientsUseCase, revokeClientUseCase, registerConnectionUseCase, listConnectionsUseCase, deleteConnectionUseCase, setTenantQuotaUseCase, getOrganizationSettingsUseCase, updateOrganizationSettingsUseCase, getMaintenanceUseCase, setMaintenanceUseCase, getAuthProtectionUseCase, setAuthProtectionUseCase, listDeprecationUsageUseCase, impersonateUserUseCase, listAuditLogUseCase, listUsersUseCase, exportUsersUseCase, exportAuditLogUseCase, setUserStatusUseCase, revokeUserAccessUseCase, setUserPlanUseCase, listUserTokensUseCase, createAccountUseCase, listAccountsUseCase, deleteAccountUseCase, createKeyUseCase, listKeysUseCase, revokeKeyUseCase, exportUsageUseCase, listEmailsUseCase, getEmailUseCase, resendEmailUseCase, listEmailSuppressionsUseCase, addEmailSuppressionUseCase, removeEmailSuppressionUseCase, listDeadJobsUseCase, getJobUseCase, requeueJobUseCase, discardJobUseCase, getQueueStatsUseCase, listAdminTasksUseCase, triggerAdminTaskUseCase, getStatsUseCase, cfg, lifecycleRegistry)
+	failModes, err := ProvideFailModes(cfg)
+	if err != nil {
+		return nil, err
+	}
+	userCacheRelay, err := ProvideUserCacheRelay(cfg, registry, failModes, bus, userCache)
+	if err != nil {
+		return nil, err
+	}
+	backend := ProvideLockBackend()
+	elector := ProvideElector(cfg, backend)
+	drainer := ProvideDrainer()
+	preStop := ProvidePreStop(cfg, lifecycleRegistry, drainer)
+	healthHandler := ProvideHealthHandler(registry, failModes, userCacheRelay, elector, lifecycleRegistry, preStop)
+	listSessionsUseCase := ProvideListSessionsUseCase(sessionRepository)
+	revokeSessionUseCase := ProvideRevokeSessionUseCase(sessionRepository)
+	revokeAllSessionsUseCase := ProvideRevokeAllSessionsUseCase(sessionRepository)
+	listLoginHistoryUseCase := ProvideListLoginHistoryUseCase(loginAttemptRepository)
+	getTermsStatusUseCase := ProvideGetTermsStatusUseCase(cfg, termsAcceptanceRepository)
+	acceptTermsUseCase := ProvideAcceptTermsUseCase(cfg, termsAcceptanceRepository)
+	requestEmailChangeUseCase := ProvideRequestEmailChangeUseCase(cfg, userRepository, emailChangeRepository, passwordHasher, mailer)
+	accountDeleteAccountUseCase := ProvideDeleteAccountUseCase(cfg, userRepository, sessionRepository, accountRestoreRepository, passwordHasher, mailer)
+	jobScheduler := ProvideJobSchedulerContract(scheduler)
+	requestPhoneVerificationUseCase := ProvideRequestPhoneVerificationUseCase(cfg, userRepository, otpService, jobScheduler)
+	verifyPhoneUseCase := ProvideVerifyPhoneUseCase(userRepository, otpService, analyticsTracker, jobScheduler)
+	upgradeGuestUseCase := ProvideUpgradeGuestUseCase(cfg, userRepository, disposableEmailPolicy, invitationRepository, termsAcceptanceRepository, passwordHasher)
+	listIdentitiesUseCase := ProvideListIdentitiesUseCase(userRepository)
+	linkIdentityUseCase := ProvideLinkIdentityUseCase(userRepository, sessionRepository, passwordHasher, passwordPolicy, requestPhoneVerificationUseCase)
+	unlinkIdentityUseCase := ProvideUnlinkIdentityUseCase(userRepository, sessionRepository, passwordHasher)
+	getCurrentUsageUseCase := ProvideGetCurrentUsageUseCase(usageRepository)
+	createTokenUseCase := ProvideCreateTokenUseCase(personalAccessTokenRepository)
+	listTokensUseCase := ProvideListTokensUseCase(personalAccessTokenRepository)
+	revokeTokenUseCase := ProvideRevokeTokenUseCase(personalAccessTokenRepository)
+	getPreferencesUseCase := ProvideGetNotificationPreferencesUseCase(notificationPreferencesRepository)
+	updatePreferencesUseCase := ProvideUpdateNotificationPreferencesUseCase(notificationPreferencesRepository, userRepository)
+	listNotificationsUseCase := ProvideListNotificationsUseCase(inAppNotificationRepository)
+	countUnreadUseCase := ProvideCountUnreadUseCase(inAppNotificationRepository)
+	markReadUseCase := ProvideMarkReadUseCase(inAppNotificationRepository, badgePublisher)
+	markAllReadUseCase := ProvideMarkAllReadUseCase(inAppNotificationRepository, badgePublisher)
+	presenceStore := ProvidePresenceStore(cfg, registry)
+	presenceTracker := ProvidePresenceTracker(cfg, presenceStore, bus)
+	meHandler := ProvideMeHandler(listSessionsUseCase, revokeSessionUseCase, revokeAllSessionsUseCase, listLoginHistoryUseCase, getTermsStatusUseCase, acceptTermsUseCase, requestEmailChangeUseCase, accountDeleteAccountUseCase, requestPhoneVerificationUseCase, verifyPhoneUseCase, upgradeGuestUseCase, listIdentitiesUseCase, linkIdentityUseCase, unlinkIdentityUseCase, getCurrentUsageUseCase, createTokenUseCase, listTokensUseCase, revokeTokenUseCase, getPreferencesUseCase, updatePreferencesUseCase, listNotificationsUseCase, countUnreadUseCase, markReadUseCase, markAllReadUseCase, badgeHub, presenceTracker, drainer)
+	clientTokenIssuer := ProvideClientTokenIssuer(client)
+	issueClientTokenUseCase := ProvideIssueClientTokenUseCase(oAuthClientRepository, clientTokenIssuer)
+	accessTokenVerifier := ProvideAccessTokenVerifier(client)
+	introspectTokenUseCase := ProvideIntrospectTokenUseCase(oAuthClientRepository, accessTokenVerifier, personalAccessTokenRepository, sessionRepository, userRepository, serviceAccountRepository, serviceAccountKeyRepository)
+	authorizationCodeRepository := ProvideAuthorizationCodeRepository(clock)
+	authorizeUseCase := ProvideAuthorizeUseCase(cfg, oAuthClientRepository, authorizationCodeRepository, sessionRepository)
+	oidcTokenIssuer, err := ProvideOIDCTokenIssuer(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	exchangeCodeUseCase := ProvideExchangeCodeUseCase(cfg, oAuthClientRepository, authorizationCodeRepository, userRepository, sessionRepository, oidcTokenIssuer)
+	userInfoUseCase := ProvideUserInfoUseCase(cfg, userRepository)
+	discoveryUseCase := ProvideDiscoveryUseCase(cfg, oidcTokenIssuer)
+	oAuthHandler := ProvideOAuthHandler(cfg, issueClientTokenUseCase, introspectTokenUseCase, authorizeUseCase, exchangeCodeUseCase, userInfoUseCase, discoveryUseCase)
+	samlServiceProvider := ProvideSAMLServiceProvider(cfg, clock)
+	metadataUseCase := ProvideSAMLMetadataUseCase(samlConnectionRepository, samlServiceProvider)
+	loginStateRepository := ProvideLoginStateRepository()
+	startLoginUseCase := ProvideStartSAMLLoginUseCase(samlConnectionRepository, organizationSettingsRepository, samlServiceProvider, loginStateRepository, cfg)
+	signInWithSAMLUseCase := ProvideSignInWithSAMLUseCase(userRepository, sessionRepository, tokenIssuer, samlConnectionRepository, samlServiceProvider, loginStateRepository, loginRecorder, passwordHasher, cfg)
+	samlHandler := ProvideSAMLHandler(cfg, metadataUseCase, startLoginUseCase, signInWithSAMLUseCase, codec)
+	subscriptionRepository := ProvideSubscriptionRepository()
+	entitlementChecker := ProvideEntitlementChecker(subscriptionRepository)
+	billingProvider, err := ProvideBillingProvider(cfg, outbox)
+	if err != nil {
+		return nil, err
+	}
+	createCheckoutSessionUseCase := ProvideCreateCheckoutSessionUseCase(cfg, userRepository, subscriptionRepository, entitlementChecker, billingProvider)
+	deduper, err := ProvideDeduper(cfg, registry, failModes)
+	if err != nil {
+		return nil, err
+	}
+	handleWebhookUseCase := ProvideHandleWebhookUseCase(cfg, subscriptionRepository, billingProvider, deduper)
+	getSubscriptionUseCase := ProvideGetSubscriptionUseCase(subscriptionRepository)
+	billingHandler := ProvideBillingHandler(createCheckoutSessionUseCase, handleWebhookUseCase, getSubscriptionUseCase)
+	handleFeedbackWebhookUseCase := ProvideHandleFeedbackWebhookUseCase(cfg, emailQueueRepository, emailSuppressionRepository, deduper)
+	mailHandler := ProvideMailHandler(handleFeedbackWebhookUseCase)
+	debugHandler := ProvideDebugHandler(cfg, outbox, templateRegistry)
+	dashboardHandler := ProvideDashboardHandler(cfg)
+	checkUsernameUseCase := ProvideCheckUsernameUseCase(userRepository)
+	usernameHandler := ProvideUsernameHandler(checkUsernameUseCase)
+	loadShedder := ProvideLoadShedder(cfg)
+	credentialGate, err := ProvideCredentialGate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	authProtection, err := ProvideAuthProtection(cfg, geoLocator, authProtectionRepository, limiter)
+	if err != nil {
+		return nil, err
+	}
+	admissionController := ProvideAdmissionController(cfg)
+	verifier := ProvideExternalTokenVerifier(cfg, clock)
+	externalIdentityRepository := ProvideExternalIdentityRepository()
+	resolveExternalUserUseCase := ProvideResolveExternalUserUseCase(cfg, userRepository, externalIdentityRepository, passwordHasher)
+	externalUsers := ProvideExternalUsers(verifier, resolveExternalUserUseCase)
+	serviceAccounts := ProvideServiceAccounts(serviceAccountRepository, serviceAccountKeyRepository)
+	aggregateUsageUseCase := ProvideAggregateUsageUseCase(usageRepository)
+	usageMeter := ProvideUsageMeter(bus, aggregateUsageUseCase, deduper)
+	planGate, err := ProvidePlanGate(cfg, userRepository, entitlementChecker)
+	if err != nil {
+		return nil, err
+	}
+	routeTable := ProvideRouteTable()
+	routers, err := ProvideRouter(cfg, authHandler, adminHandler, healthHandler, meHandler, oAuthHandler, samlHandler, billingHandler, mailHandler, debugHandler, dashboardHandler, usernameHandler, drainer, loadShedder, credentialGate, authProtection, admissionController, maintenanceRepository, deprecationUsageRepository, client, verifier, externalUsers, serviceAccounts, userRepository, sessionRepository, personalAccessTokenRepository, termsAcceptanceRepository, oAuthClientRepository, auditLogRepository, samlConnectionRepository, organizationSettingsRepository, geoLocator, limiter, usageMeter, planGate, captchaVerifier, codec, deduper, routeTable, failModes)
+	if err != nil {
+		return nil, err
+	}
+	loader := ProvideFixtureLoader(userRepository, subscriptionRepository)
+	metricsBackend, err := ProvideMetricsBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := ProvideLogExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	purgeDeletedAccountsUseCase := ProvidePurgeDeletedAccountsUseCase(userRepository, accountRestoreRepository)
+	purgeExpiredTokensUseCase := ProvidePurgeExpiredTokensUseCase(sessionRepository, passwordResetRepository, authorizationCodeRepository, purgeDeletedAccountsUseCase)
+	authModule := ProvideAuthModule(cfg, passwordHasher, sessionRepository, purgeExpiredTokensUseCase)
+	billingModule := ProvideBillingModule(cfg, billingProvider)
+	mailModule := ProvideMailModule(cfg, queueWorker, emailQueueRepository, templateRegistry)
+	deliverPendingNotificationsUseCase := ProvideDeliverPendingNotificationsUseCase(pendingNotificationRepository, mailer)
+	notificationModule := ProvideNotificationModule(deliverPendingNotificationsUseCase)
+	jobsModule := ProvideJobsModule(scheduler, scheduledJobRepository)
+	clearUnverifiedPhoneUseCase := ProvideClearUnverifiedPhoneUseCase(userRepository)
+	phoneModule := ProvidePhoneModule(clearUnverifiedPhoneUseCase)
+	usersModule := ProvideUsersModule(userCacheRelay)
+	statsModule := ProvideStatsModule(cfg, refreshStatsUseCase)
+	resendEmailChangeUseCase := ProvideResendEmailChangeUseCase(cfg, emailChangeRepository, mailer)
+	accountModule, err := ProvideAccountModule(cfg, resendEmailChangeUseCase, purgeDeletedAccountsUseCase)
+	if err != nil {
+		return nil, err
+	}
+	getPresenceUseCase := ProvideGetPresenceUseCase(userRepository, presenceStore)
+	usersHandler := ProvideUsersHandler(getPresenceUseCase)
+	presenceModule := ProvidePresenceModule(cfg, presenceTracker, usersHandler)
+	v := ProvideModules(authModule, billingModule, mailModule, notificationModule, jobsModule, phoneModule, usersModule, statsModule, accountModule, presenceModule)
+	doctor := ProvideDoctor(cfg, client, mailTransport)
+	container := ProvideContainer(cfg, routers, loader, elector, drainer, preStop, bus, metricsBackend, exporter, lifecycleRegistry, v, analyticsTracker, streamer, scheduler, adminTaskRegistry, routeTable, doctor)
 	return container, nil
 }
 
@@ -33,41 +443,3152 @@ func InitializeContainer() (*Container, error) {
 
 // Providers for the application container
 var ProviderSet = wire.NewSet(
+	ProvideClock,
+	ProvideResilienceRegistry,
+	ProvideFailModes,
+	ProvideDrainer,
+	ProvidePreStop,
+	ProvideLifecycle,
+	ProvideLoadShedder,
+	ProvideCredentialGate,
+	ProvideAuthProtection,
+	ProvideAdmissionController,
+	ProvideLockBackend,
+	ProvideQuotaStore,
+	ProvideDeduper,
+	ProvideQuotaLimiter,
+	ProvideElector,
 	ProvideUserRepository,
+	ProvideUserCache,
+	ProvideUserCacheRelay,
+	ProvideUserQuery,
+	ProvidePasswordHasher,
+	ProvideSessionRepository,
+	ProvideScheduledJobRepository,
+	ProvideJobScheduler,
+	ProvideJobSchedulerContract,
+	ProvidePersonalAccessTokenRepository,
+	ProvideNotificationPreferencesRepository,
+	ProvidePendingNotificationRepository,
+	ProvideInAppNotificationRepository,
+	ProvideBadgeHub,
+	ProvideBadgePublisher,
+	ProvidePresenceStore,
+	ProvidePresenceTracker,
+	ProvideKnownDeviceRepository,
+	ProvideDeviceApprovalRepository,
+	ProvideOutbox,
+	ProvideMailTransport,
+	ProvideEmailQueueRepository,
+	ProvideDeliverQueuedEmailsUseCase,
+	ProvideMailQueueWorker,
+	ProvideEmailTemplates,
+	ProvideMailer,
+	ProvideHandleFeedbackWebhookUseCase,
+	ProvideListEmailsUseCase,
+	ProvideGetEmailUseCase,
+	ProvideResendEmailUseCase,
+	ProvideEmailSuppressionRepository,
+	ProvideListEmailSuppressionsUseCase,
+	ProvideAddEmailSuppressionUseCase,
+	ProvideRemoveEmailSuppressionUseCase,
+	ProvideListDeadJobsUseCase,
+	ProvideGetJobUseCase,
+	ProvideRequeueJobUseCase,
+	ProvideDiscardJobUseCase,
+	ProvideGetQueueStatsUseCase,
+	ProvideAdminTaskRegistry,
+	ProvideAdminTaskRegistryContract,
+	ProvideListAdminTasksUseCase,
+	ProvideTriggerAdminTaskUseCase,
+	ProvidePurgeExpiredTokensUseCase,
+	ProvideResendEmailChangeUseCase,
+	ProvideAccountModule,
+	ProvideStatsRepository,
+	ProvideRefreshStatsUseCase,
+	ProvideGetStatsUseCase,
+	ProvideMailHandler,
+	ProvideLoginAttemptRepository,
+	ProvideAuditLogRepository,
+	ProvideAuditStream,
+	ProvideGeoLocator,
+	ProvideInvitationRepository,
+	ProvidePasswordResetRepository,
+	ProvideAccountRestoreRepository,
+	ProvideTermsAcceptanceRepository,
+	ProvideEmailChangeRepository,
+	ProvidePhoneOTPRepository,
+	ProvideExternalIdentityRepository,
+	ProvideOAuthClientRepository,
+	ProvideAuthorizationCodeRepository,
+	ProvideSAMLConnectionRepository,
+	ProvideSAMLServiceProvider,
+	ProvideLoginStateRepository,
+	ProvideSubscriptionRepository,
+	ProvideEventBus,
+	ProvideMetricsBackend,
+	ProvideRouteTable,
+	ProvideLogExporter,
+	ProvideUsageRepository,
+	ProvideAggregateUsageUseCase,
+	ProvideUsageMeter,
+	ProvideAnalyticsTracker,
+	ProvideGetCurrentUsageUseCase,
+	ProvideExportUsageUseCase,
+	ProvideBillingProvider,
+	ProvideEntitlementChecker,
+	ProvidePlanGate,
+	ProvideSMSSender,
+	ProvideJWTClient,
+	ProvideExternalTokenVerifier,
+	ProvideExternalUsers,
+	ProvideServiceAccounts,
+	ProvideServiceAccountRepository,
+	ProvideServiceAccountKeyRepository,
+	ProvideCreateServiceAccountUseCase,
+	ProvideListServiceAccountsUseCase,
+	ProvideDeleteServiceAccountUseCase,
+	ProvideCreateServiceAccountKeyUseCase,
+	ProvideListServiceAccountKeysUseCase,
+	ProvideRevokeServiceAccountKeyUseCase,
+	ProvideTokenIssuer,
+	ProvideClientTokenIssuer,
+	ProvideAccessTokenVerifier,
+	ProvideOIDCTokenIssuer,
+	ProvideDisposableEmailPolicy,
+	ProvidePasswordPolicy,
+	ProvideCaptchaVerifier,
+	ProvideCookieCodec,
+	ProvideIPReputation,
+	ProvideBotPolicy,
+	ProvideIssueSignUpFormUseCase,
+	ProvideSagaStore,
 	ProvideSignUpUseCase,
+	ProvideUpgradeGuestUseCase,
+	ProvideListIdentitiesUseCase,
+	ProvideLinkIdentityUseCase,
+	ProvideUnlinkIdentityUseCase,
+	ProvideStartGuestSessionUseCase,
+	ProvideDeviceGuard,
+	ProvideLoginRecorder,
+	ProvideSignInUseCase,
+	ProvideRotateExpiredPasswordUseCase,
+	ProvideForcePasswordRotationUseCase,
+	ProvideImpersonateUserUseCase,
+	ProvideListAuditLogUseCase,
+	ProvideListUsersUseCase,
+	ProvideExportUsersUseCase,
+	ProvideExportAuditLogUseCase,
+	ProvideSetUserStatusUseCase,
+	ProvideRevokeUserAccessUseCase,
+	ProvideSetUserPlanUseCase,
+	ProvideListUserTokensUseCase,
+	ProvideSetTenantQuotaUseCase,
+	ProvideOrganizationSettingsRepository,
+	ProvideGetOrganizationSettingsUseCase,
+	ProvideUpdateOrganizationSettingsUseCase,
+	ProvideMaintenanceRepository,
+	ProvideGetMaintenanceUseCase,
+	ProvideSetMaintenanceUseCase,
+	ProvideAuthProtectionRepository,
+	ProvideGetAuthProtectionUseCase,
+	ProvideSetAuthProtectionUseCase,
+	ProvideDeprecationUsageRepository,
+	ProvideListDeprecationUsageUseCase,
+	ProvideReviewDeviceUseCase,
+	ProvideRefreshTokensUseCase,
+	ProvideForgotPasswordUseCase,
+	ProvideResetPasswordUseCase,
+	ProvideListSessionsUseCase,
+	ProvideRevokeSessionUseCase,
+	ProvideRevokeAllSessionsUseCase,
+	ProvideCreateTokenUseCase,
+	ProvideListTokensUseCase,
+	ProvideRevokeTokenUseCase,
+	ProvideNotifier,
+	ProvideGetNotificationPreferencesUseCase,
+	ProvideUpdateNotificationPreferencesUseCase,
+	ProvideDeliverPendingNotificationsUseCase,
+	ProvideListNotificationsUseCase,
+	ProvideCountUnreadUseCase,
+	ProvideMarkReadUseCase,
+	ProvideMarkAllReadUseCase,
+	ProvideListLoginHistoryUseCase,
+	ProvideGetTermsStatusUseCase,
+	ProvideAcceptTermsUseCase,
+	ProvideRequestEmailChangeUseCase,
+	ProvideConfirmEmailChangeUseCase,
+	ProvideRevertEmailChangeUseCase,
+	ProvideDeleteAccountUseCase,
+	ProvideRestoreAccountUseCase,
+	ProvidePurgeDeletedAccountsUseCase,
+	ProvideOTPService,
+	ProvideRequestPhoneVerificationUseCase,
+	ProvideVerifyPhoneUseCase,
+	ProvideClearUnverifiedPhoneUseCase,
+	ProvideRequestSignInCodeUseCase,
+	ProvideSignInWithCodeUseCase,
+	ProvideSignInWithSAMLUseCase,
+	ProvideResolveExternalUserUseCase,
+	ProvideUpdateUserUseCase,
+	ProvideBulkUsersUseCase,
+	ProvideDisposableDomainsUseCase,
+	ProvideMintInvitationUseCase,
+	ProvideListInvitationsUseCase,
+	ProvideRevokeInvitationUseCase,
 	ProvideAuthHandler,
+	ProvideAdminHandler,
+	ProvideMeHandler,
+	ProvideHealthHandler,
+	ProvideRegisterOAuthClientUseCase,
+	ProvideListOAuthClientsUseCase,
+	ProvideRevokeOAuthClientUseCase,
+	ProvideIssueClientTokenUseCase,
+	ProvideIntrospectTokenUseCase,
+	ProvideAuthorizeUseCase,
+	ProvideExchangeCodeUseCase,
+	ProvideUserInfoUseCase,
+	ProvideDiscoveryUseCase,
+	ProvideRegisterSAMLConnectionUseCase,
+	ProvideListSAMLConnectionsUseCase,
+	ProvideDeleteSAMLConnectionUseCase,
+	ProvideSAMLMetadataUseCase,
+	ProvideStartSAMLLoginUseCase,
+	ProvideSAMLHandler,
+	ProvideOAuthHandler,
+	ProvideCreateCheckoutSessionUseCase,
+	ProvideHandleWebhookUseCase,
+	ProvideGetSubscriptionUseCase,
+	ProvideBillingHandler,
+	ProvideDebugHandler,
+	ProvideDashboardHandler,
+	ProvideFixtureLoader,
+	ProvideCheckUsernameUseCase,
+	ProvideUsernameHandler,
+	ProvideGetPresenceUseCase,
+	ProvideUsersHandler,
 	ProvideRouter,
+	ProvideAuthModule,
+	ProvideBillingModule,
+	ProvideMailModule,
+	ProvideNotificationModule,
+	ProvideJobsModule,
+	ProvidePhoneModule,
+	ProvideUsersModule,
+	ProvideStatsModule,
+	ProvidePresenceModule,
+	ProvideModules,
+	ProvideDoctor,
 	ProvideContainer,
 )
 
+// readinessPolicy is the caching policy of the readiness checks.
+func readinessPolicy(cfg *config.Config) lifecycle.CheckPolicy {
+	return lifecycle.CheckPolicy{
+		Interval:         cfg.Readiness.CheckInterval,
+		FailureThreshold: cfg.Readiness.FailureThreshold,
+		SuccessThreshold: cfg.Readiness.SuccessThreshold,
+		MaxAge:           cfg.Readiness.CheckMaxAge,
+	}
+}
+
+// ProvideClock provides the wall clock the time-based components read
+func ProvideClock() clock.Clock {
+	return clock.Real()
+}
+
+// ProvideResilienceRegistry provides the registry of dependency resilience policies
+func ProvideResilienceRegistry() *resilience.Registry {
+	return resilience.NewRegistry()
+}
+
+// ProvideLifecycle provides the instance status and component health
+// registry. The dependencies component is probed from the resilience
+// policies; the others are set by the hooks that start and stop them.
+func ProvideLifecycle(cfg *config.Config, registry *resilience.Registry) *lifecycle.Registry {
+	l := lifecycle.NewRegistry()
+	l.AddCachedCheck(COMPONENT_DEPENDENCIES, true, func() (lifecycle.ComponentState, string) {
+		statuses, healthy := registry.Statuses()
+		var open []string
+		for _, s := range statuses {
+			if s.State == resilience.STATE_OPEN.String() {
+				open = append(open, s.Name)
+			}
+		}
+		detail := ""
+		if len(open) > 0 {
+			detail = "open circuits: " + strings.Join(open, ", ")
+		}
+		if !healthy {
+			return lifecycle.COMPONENT_DOWN, detail
+		}
+		return lifecycle.COMPONENT_UP, detail
+	}, readinessPolicy(cfg))
+	l.Register(COMPONENT_HTTP, true)
+	if cfg.App.OpsAddr != "" {
+		l.Register(COMPONENT_OPS_HTTP, true)
+	}
+	l.Register(COMPONENT_EVENT_BUS, false)
+	l.Register(COMPONENT_LEADER_ELECTION, false)
+	l.Register(COMPONENT_WARMUP, false)
+	return l
+}
+
+// ProvideDrainer provides the in-flight request tracker used for draining
+func ProvideDrainer() *drain.Drainer {
+	return drain.NewDrainer()
+}
+
+// ProvidePreStop provides the start of the shutdown, run on SIGTERM or
+// earlier by a preStop hook: readiness failing for APP_SHUTDOWN_DELAY.
+func ProvidePreStop(cfg *config.Config, lifecycleRegistry *lifecycle.Registry, drainer *drain.Drainer) *drain.PreStop {
+	return drain.NewPreStop(cfg.App.ShutdownDelay, func() {
+		lifecycleRegistry.Stopping()
+		drainer.StartDraining()
+		if cfg.App.ShutdownDelay > 0 {
+			logger.L().Infof("draining: readiness failing, waiting %s before shutdown", cfg.App.ShutdownDelay)
+		}
+	})
+}
+
+// ProvideLoadShedder provides the concurrency-limiting load shedder
+func ProvideLoadShedder(cfg *config.Config) *middleware.LoadShedder {
+	return middleware.NewLoadShedder(middleware.LoadShedderArgs{
+		MaxInFlight: cfg.LoadShed.MaxInFlight,
+		GroupLimits: cfg.LoadShed.GroupLimits,
+		RetryAfter:  cfg.LoadShed.RetryAfter,
+	})
+}
+
+// ProvideCredentialGate provides the concurrency gate of the credential
+// routes, limiting those CREDENTIAL_GATE_LIMITS leaves out to GOMAXPROCS.
+func ProvideCredentialGate(cfg *config.Config) (*middleware.CredentialGate, error) {
+	credentials := credentialGateConfig.From(cfg)
+	limits := maps.Clone(credentials.Limits)
+	if limits == nil {
+		limits = make(map[string]int, len(middleware.CredentialRoutes))
+	}
+	for _, route := range middleware.CredentialRoutes {
+		if _, ok := limits[route]; !ok {
+			limits[route] = runtime.GOMAXPROCS(0)
+		}
+	}
+	gate, err := middleware.NewCredentialGate(middleware.CredentialGateArgs{
+		Limits:       limits,
+		MaxQueue:     credentials.MaxQueue,
+		QueueTimeout: credentials.QueueTimeout,
+		RetryAfter:   credentials.RetryAfter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse CREDENTIAL_GATE_LIMITS: %w", err)
+	}
+	logger.L().Infow("credential routes gated",
+		"sign_up", gate.Limit(middleware.CREDENTIAL_ROUTE_SIGN_UP),
+		"sign_in", gate.Limit(middleware.CREDENTIAL_ROUTE_SIGN_IN),
+		"max_queue", credentials.MaxQueue,
+		"queue_timeout", credentials.QueueTimeout.String())
+	return gate, nil
+}
+
+// ProvideAuthProtection provides the adaptive protection of the credential
+// routes, or nil when AUTH_PROTECTION_ENABLED is off. ASNs come from
+// GEOIP_ASN_DB_PATH, or else from GEOIP_DB_PATH when it is an ISP database.
+func ProvideAuthProtection(
+	cfg *config.Config,
+	geoLocator contract.GeoLocator,
+	authProtectionRepo contract.AuthProtectionRepository,
+	limiter *quota.Limiter,
+) (*middleware.AuthProtection, error) {
+	c := authProtectionConfig.From(cfg)
+	if !c.Enabled {
+		return nil, nil
+	}
+	var rateLimit []quota.Limit
+	if c.RateLimit != "" {
+		var err error
+		if rateLimit, err = quota.ParseLimits(c.RateLimit); err != nil {
+			return nil, fmt.Errorf("parse AUTH_PROTECTION_RATE_LIMIT: %w", err)
+		}
+	}
+	rules := make(map[string]anomaly.Rule, 3)
+	for scope, minFailures := range map[string]int{middleware.AUTH_SCOPE_GLOBAL: c.GlobalMinFailures, middleware.AUTH_SCOPE_ASN: c.ASNMinFailures, middleware.AUTH_SCOPE_SUBNET: c.SubnetMinFailures} {
+		if minFailures > 0 {
+			rules[scope] = anomaly.Rule{MinFailures: minFailures, Ratio: c.FailureRatio}
+		}
+	}
+	locator := geoLocator
+	if cfg.GeoIP.ASNDBPath != "" {
+		asnLocator, err := geo.NewMaxMindLocator(geo.MaxMindLocatorArgs{
+			Path:           cfg.GeoIP.ASNDBPath,
+			ReloadInterval: cfg.GeoIP.ReloadInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+		locator = asnLocator
+	}
+
+	protection, err := middleware.NewAuthProtection(middleware.AuthProtectionArgs{
+		Rules:     rules,
+		Window:    c.Window,
+		Cooldown:  c.Cooldown,
+		MaxKeys:   c.MaxTrackedNetworks,
+		Locator:   locator,
+		Override:  authProtectionRepo,
+		Limiter:   limiter,
+		RateLimit: rateLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load AUTH_PROTECTION config: %w", err)
+	}
+	logger.L().Infow("credential routes adaptively protected",
+		"scopes", slices.Sorted(maps.Keys(rules)), "window", c.Window.String(),
+		"failure_ratio", c.FailureRatio,
+		"rate_limit", c.RateLimit)
+	return protection, nil
+}
+
+// ProvideAdmissionController provides the priority admission controller
+func ProvideAdmissionController(cfg *config.Config) *middleware.AdmissionController {
+	return middleware.NewAdmissionController(middleware.AdmissionControllerArgs{
+		Capacity:     cfg.Admission.Capacity,
+		MaxQueue:     cfg.Admission.MaxQueue,
+		QueueTimeout: map[middleware.Priority]time.Duration{middleware.PRIORITY_HIGH: cfg.Admission.HighQueueTimeout, middleware.PRIORITY_LOW: cfg.Admission.LowQueueTimeout},
+	})
+}
+
+// ProvideLockBackend provides the distributed lock backend
+func ProvideLockBackend() lock.Backend {
+	return lock.NewMemoryBackend()
+}
+
+// ProvideQuotaStore provides the request quota counter store selected by
+// QUOTA_BACKEND
+func ProvideQuotaStore(cfg *config.Config, registry *resilience.Registry) (quota.Store, error) {
+	switch cfg.Quota.Backend {
+	case "memory":
+		return quota.NewMemoryStore(), nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_QUOTA, resp.ClientArgs{
+			Addr:     cfg.Quota.RedisAddr,
+			Password: cfg.Quota.RedisPassword,
+			DB:       cfg.Quota.RedisDB,
+		})
+		return quota.NewRedisStore(client, cfg.Quota.RedisPrefix), nil
+	default:
+		return nil, fmt.Errorf("QUOTA_BACKEND must be memory or redis, got %q", cfg.Quota.Backend)
+	}
+}
+
+// ProvideDeduper provides the deduplication of webhook deliveries and bus
+// events, backed by the store selected by DEDUPE_BACKEND
+func ProvideDeduper(cfg *config.Config, registry *resilience.Registry, modes FailModes) (*dedupe.Deduper, error) {
+	var store dedupe.Store
+	switch cfg.Dedupe.Backend {
+	case "memory":
+		store = dedupe.NewMemoryStore()
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_DEDUPE, resp.ClientArgs{
+			Addr:     cfg.Dedupe.RedisAddr,
+			Password: cfg.Dedupe.RedisPassword,
+			DB:       cfg.Dedupe.RedisDB,
+		})
+		store = dedupe.NewRedisStore(client, cfg.Dedupe.RedisPrefix)
+	default:
+		return nil, fmt.Errorf("DEDUPE_BACKEND must be memory or redis, got %q", cfg.Dedupe.Backend)
+	}
+	if cfg.Dedupe.Retention <= 0 {
+		return nil, fmt.Errorf("DEDUPE_RETENTION must be positive, got %s", cfg.Dedupe.Retention)
+	}
+	for scope, retention := range cfg.Dedupe.ScopeRetention {
+		if retention <= 0 {
+			return nil, fmt.Errorf("DEDUPE_SCOPE_RETENTION of %q must be positive, got %s", scope, retention)
+		}
+	}
+	return dedupe.NewDeduper(dedupe.DeduperArgs{
+		Store:          store,
+		Lease:          cfg.Dedupe.Lease,
+		Retention:      cfg.Dedupe.Retention,
+		ScopeRetention: cfg.Dedupe.ScopeRetention,
+		FailOpen:       modes.Open(FEATURE_DEDUPE),
+	}), nil
+}
+
+// ProvideQuotaLimiter provides the per-plan request quota limiter
+func ProvideQuotaLimiter(cfg *config.Config, store quota.Store, clk clock.Clock) (*quota.Limiter, error) {
+	plans, err := quota.ParsePlans(cfg.Quota.Plans)
+	if err != nil {
+		return nil, fmt.Errorf("parse QUOTA_PLANS: %w", err)
+	}
+	return quota.NewLimiter(quota.LimiterArgs{
+		Store:       store,
+		Plans:       plans,
+		DefaultPlan: cfg.Quota.DefaultPlan,
+		SoftRatio:   cfg.Quota.SoftRatio,
+		Clock:       clk,
+	})
+}
+
+// ProvideElector provides the leader elector for singleton background tasks
+func ProvideElector(cfg *config.Config, backend lock.Backend) *leader.Elector {
+	return leader.NewElector(leader.ElectorArgs{
+		Locker: lock.NewLocker(lock.LockerArgs{
+			Backend: backend,
+			TTL:     cfg.Leader.LeaseTTL,
+		}),
+		Key:           cfg.Leader.Key,
+		RetryInterval: cfg.Leader.RetryInterval,
+	})
+}
+
+// ProvideMetricsBackend selects the metrics backend of the process-wide
+// registry. It returns nil for Prometheus, which is scraped from /metrics
+// instead of pushed to.
+func ProvideMetricsBackend(cfg *config.Config) (metrics.Backend, error) {
+	switch cfg.Metrics.Backend {
+	case "prometheus":
+		return nil, nil
+	case "statsd":
+		backend, err := metrics.NewStatsD(metrics.StatsDArgs{
+			Addr:          cfg.Metrics.StatsDAddr,
+			Prefix:        cfg.Metrics.StatsDPrefix,
+			FlushInterval: cfg.Metrics.StatsDFlushInterval,
+			Tags:          podTags(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("configure METRICS: %w", err)
+		}
+		metrics.SetBackend(backend)
+		return backend, nil
+	case "noop":
+		metrics.Disable()
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("METRICS_BACKEND must be prometheus, statsd or noop, got %q", cfg.Metrics.Backend)
+	}
+}
+
+// podTags tags the StatsD updates with the pod the instance runs in, when
+// the downward API names it.
+func podTags() []metrics.Label {
+	var tags []metrics.Label
+	for _, a := range podinfo.Get().Attrs() {
+		tags = append(tags, metrics.Label{Name: a.Key, Value: a.Value})
+	}
+	return tags
+}
+
+// ProvideLogExporter exports the log entries over OTLP when
+// LOG_OTLP_ENDPOINT is set, and returns nil otherwise. The queued entries
+// are flushed on shutdown, and on a Fatal entry before the process exits.
+func ProvideLogExporter(cfg *config.Config) (*otlplog.Exporter, error) {
+	if cfg.Log.OTLPEndpoint == "" {
+		return nil, nil
+	}
+	level, err := zapcore.ParseLevel(cfg.Log.OTLPLevel)
+	if err != nil {
+		return nil, fmt.Errorf("LOG_OTLP_LEVEL: %w", err)
+	}
+	exporter := otlplog.NewExporter(otlplog.ExporterArgs{
+		Endpoint:       cfg.Log.OTLPEndpoint,
+		Headers:        cfg.Log.OTLPHeaders,
+		ServiceName:    cfg.Log.OTLPServiceName,
+		ServiceVersion: buildinfo.Get().Version,
+		Resource:       podinfo.Get().ResourceAttributes(),
+		Level:          level,
+		Buffer:         cfg.Log.OTLPBuffer,
+		BatchSize:      cfg.Log.OTLPBatchSize,
+		FlushInterval:  cfg.Log.OTLPFlushInterval,
+		SendTimeout:    cfg.Log.OTLPTimeout,
+	})
+	logger.Export(exporter.Core())
+	logger.OnFatal(func(string) {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Log.OTLPTimeout)
+		defer cancel()
+		exporter.Close(ctx)
+	})
+	return exporter, nil
+}
+
+// ProvideEventBus provides the in-process event bus
+func ProvideEventBus(cfg *config.Config) *eventbus.Bus {
+	return eventbus.NewBus(eventbus.BusArgs{
+		Buffer:  cfg.EventBus.Buffer,
+		Workers: cfg.EventBus.Workers,
+	})
+}
+
 // ProvideUserRepository provides the user repository implementation
-func ProvideUserRepository() contract.UserRepository {
-	return infrastructure.NewUserRepository()
+func ProvideUserRepository(cfg *config.Config, registry *resilience.Registry, bus *eventbus.Bus, cache *infrastructure.UserCache) (contract.UserRepository, error) {
+	policy := registry.Policy("db", resilience.PolicyArgs{
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenTimeout:      cfg.Resilience.OpenTimeout,
+		MaxConcurrent:    cfg.Resilience.MaxConcurrent,
+		MaxWait:          cfg.Resilience.MaxWait,
+		IsFailure:        infrastructure.IsDatabaseFailure,
+		Critical:         true,
+	})
+	var users contract.UserRepository
+	switch cfg.UserStore.Mode {
+	case "state":
+		users = infrastructure.NewUserRepository()
+	case "events":
+		users = infrastructure.NewEventSourcedUserRepository(infrastructure.EventSourcedUserRepositoryArgs{
+			Store:         infrastructure.NewUserEventStore(),
+			Projection:    infrastructure.NewUserRepository(),
+			SnapshotEvery: cfg.UserStore.SnapshotEvery,
+		})
+	default:
+		return nil, fmt.Errorf("unknown USER_STORE_MODE %q", cfg.UserStore.Mode)
+	}
+	users = infrastructure.NewPublishingUserRepository(users, bus)
+	users = infrastructure.NewResilientUserRepository(users, policy)
+	if cache != nil {
+		users = infrastructure.NewCachingUserRepository(users, cache)
+	}
+	return users, nil
 }
 
-// ProvideSignUpUseCase provides the sign up use case
-func ProvideSignUpUseCase(userRepo contract.UserRepository) *auth.SignUpUseCase {
-	return auth.NewSignUpUseCase(userRepo)
+// ProvideUserCache provides the cache of users by ID, or nil unless
+// USER_CACHE_ENABLED
+func ProvideUserCache(cfg *config.Config) *infrastructure.UserCache {
+	if !cfg.UserCache.Enabled {
+		return nil
+	}
+	return infrastructure.NewUserCache(cfg.UserCache.TTL, cfg.UserCache.Size)
 }
 
-// ProvideAuthHandler provides the auth handler
-func ProvideAuthHandler(signUpUseCase *auth.SignUpUseCase) *auth2.AuthHandler {
-	return auth2.NewAuthHandler(auth2.NewAuthHandlerArgs{
-		SignUpUseCase: signUpUseCase,
+// ProvideUserCacheRelay provides the relay of user cache invalidations
+// between instances, or nil unless USER_CACHE_INVALIDATION is "redis"
+func ProvideUserCacheRelay(cfg *config.Config, registry *resilience.Registry, modes FailModes, bus *eventbus.Bus, cache *infrastructure.UserCache) (*infrastructure.UserCacheRelay, error) {
+	if cache == nil {
+		return nil, nil
+	}
+	switch cfg.UserCache.Invalidation {
+	case "local":
+		return nil, nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_USER_CACHE, resp.ClientArgs{
+			Addr:     cfg.UserCache.RedisAddr,
+			Password: cfg.UserCache.RedisPassword,
+			DB:       cfg.UserCache.RedisDB,
+		})
+		relay := infrastructure.NewUserCacheRelay(client, cfg.UserCache.RedisChannel, cache, modes.Open(FEATURE_USER_CACHE))
+		relay.Subscribe(bus)
+		return relay, nil
+	default:
+		return nil, fmt.Errorf("USER_CACHE_INVALIDATION must be local or redis, got %q", cfg.UserCache.Invalidation)
+	}
+}
+
+// ProvideUserQuery provides the user read model, kept up to date from the
+// user writes published on the event bus
+func ProvideUserQuery(bus *eventbus.Bus) contract.UserQuery {
+	readModel := infrastructure.NewUserReadModel()
+	readModel.Subscribe(bus)
+	return readModel
+}
+
+// ProvideStatsRepository provides the stats view store selected by
+// STATS_BACKEND
+func ProvideStatsRepository(cfg *config.Config) (contract.StatsRepository, error) {
+	stats := statsConfig.From(cfg)
+	if stats.Backend != "postgres" {
+		return infrastructure.NewStatsRepository(), nil
+	}
+	db, err := openPostgres(cfg, stats.SQLDriver)
+	if err != nil {
+		return nil, fmt.Errorf("open stats database: %w", err)
+	}
+	return infrastructure.NewPostgresStatsRepository(db), nil
+}
+
+// ProvideSessionRepository provides the session repository selected by
+// SESSION_STORE_BACKEND
+func ProvideSessionRepository(cfg *config.Config, registry *resilience.Registry) (contract.SessionRepository, error) {
+	switch cfg.Sessions.Backend {
+	case "memory":
+		return infrastructure.NewSessionRepository(), nil
+	case "redis":
+		client := redisClient(cfg, registry, FEATURE_SESSIONS, resp.ClientArgs{
+			Addr:     cfg.Sessions.RedisAddr,
+			Password: cfg.Sessions.RedisPassword,
+			DB:       cfg.Sessions.RedisDB,
+		})
+		return infrastructure.NewRedisSessionRepository(client, cfg.Sessions.RedisPrefix), nil
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Sessions.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open session database: %w", err)
+		}
+		return infrastructure.NewPostgresSessionRepository(db), nil
+	default:
+		return nil, fmt.Errorf("SESSION_STORE_BACKEND must be memory, redis or postgres, got %q", cfg.Sessions.Backend)
+	}
+}
+
+// ProvideScheduledJobRepository provides the scheduled job store selected by JOBS_BACKEND
+func ProvideScheduledJobRepository(cfg *config.Config) (contract.ScheduledJobRepository, error) {
+	switch cfg.Jobs.Backend {
+	case "memory":
+		return infrastructure.NewScheduledJobRepository(), nil
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Jobs.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open jobs database: %w", err)
+		}
+		return infrastructure.NewPostgresScheduledJobRepository(db), nil
+	default:
+		return nil, fmt.Errorf("JOBS_BACKEND must be memory or postgres, got %q", cfg.Jobs.Backend)
+	}
+}
+
+// ProvideJobScheduler provides the scheduler that stores and runs deferred jobs
+func ProvideJobScheduler(cfg *config.Config, repo contract.ScheduledJobRepository, clk clock.Clock) *jobs.Scheduler {
+	return jobs.NewScheduler(jobs.SchedulerArgs{
+		Repo:        repo,
+		Interval:    cfg.Jobs.PollInterval,
+		BatchSize:   cfg.Jobs.BatchSize,
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+		RetryBase:   cfg.Jobs.RetryBase,
+		RetryMax:    cfg.Jobs.RetryMax,
+		Clock:       clk,
 	})
 }
 
-// ProvideRouter provides the chi router with all routes registered
-func ProvideRouter(authHandler *auth2.AuthHandler) *chi.Mux {
-	return router.NewRouter(router.NewRouterArgs{
-		AuthHandler: authHandler,
+// ProvideJobSchedulerContract provides the job scheduler implementation
+func ProvideJobSchedulerContract(scheduler *jobs.Scheduler) contract.JobScheduler {
+	return scheduler
+}
+
+// openPostgres opens the DB_* database with the database/sql driver
+// registered as driver. sql.Open does not connect, so a driver missing from
+// the binary is reported here rather than at the first query.
+func openPostgres(cfg *config.Config, driver string) (*sql.DB, error) {
+	if err := sqlDriverLinked(driver); err != nil {
+		return nil, err
+	}
+	return sql.Open(driver, postgresDSN(cfg))
+}
+
+// sqlDriverLinked reports a database/sql driver missing from the binary by
+// name.
+func sqlDriverLinked(driver string) error {
+	if !slices.Contains(sql.Drivers(), driver) {
+		return fmt.Errorf("database/sql driver %q is not linked into the binary, which has %v", driver, sql.Drivers())
+	}
+	return nil
+}
+
+// postgresDSN is the URL of the DB_* database.
+func postgresDSN(cfg *config.Config) string {
+	return (&url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.DB.Username, cfg.DB.Password),
+		Host:   net.JoinHostPort(cfg.DB.Host, strconv.Itoa(cfg.DB.Port)),
+		Path:   cfg.DB.DatabaseName,
+	}).String()
+}
+
+// ProvidePersonalAccessTokenRepository provides the personal access token repository implementation
+func ProvidePersonalAccessTokenRepository() contract.PersonalAccessTokenRepository {
+	return infrastructure.NewPersonalAccessTokenRepository()
+}
+
+// ProvideNotificationPreferencesRepository provides the notification preferences repository implementation
+func ProvideNotificationPreferencesRepository() contract.NotificationPreferencesRepository {
+	return infrastructure.NewNotificationPreferencesRepository()
+}
+
+// ProvidePendingNotificationRepository provides the held notification repository implementation
+func ProvidePendingNotificationRepository() contract.PendingNotificationRepository {
+	return infrastructure.NewPendingNotificationRepository()
+}
+
+// ProvideInAppNotificationRepository provides the in-app notification inbox implementation
+func ProvideInAppNotificationRepository() contract.InAppNotificationRepository {
+	return infrastructure.NewInAppNotificationRepository()
+}
+
+// ProvideBadgeHub provides the hub pushing unread notification counts to
+// open notification streams
+func ProvideBadgeHub(bus *eventbus.Bus) *realtime.BadgeHub {
+	return realtime.NewBadgeHub(bus)
+}
+
+// ProvideBadgePublisher provides the unread count publisher implementation
+func ProvideBadgePublisher(hub *realtime.BadgeHub) contract.BadgePublisher {
+	return hub
+}
+
+// ProvidePresenceStore provides the presence store selected by
+// PRESENCE_BACKEND
+func ProvidePresenceStore(cfg *config.Config, registry *resilience.Registry) contract.PresenceStore {
+	presence := presenceConfig.From(cfg)
+	if presence.Backend != "redis" {
+		return infrastructure.NewPresenceStore()
+	}
+	client := redisClient(cfg, registry, FEATURE_PRESENCE, resp.ClientArgs{
+		Addr:     presence.RedisAddr,
+		Password: presence.RedisPassword,
+		DB:       presence.RedisDB,
+	})
+	return infrastructure.NewRedisPresenceStore(client, presence.RedisPrefix)
+}
+
+// ProvidePresenceTracker provides the tracker of who is online
+func ProvidePresenceTracker(cfg *config.Config, store contract.PresenceStore, bus *eventbus.Bus) *realtime.PresenceTracker {
+	return realtime.NewPresenceTracker(store, bus, presenceConfig.From(cfg).TTL)
+}
+
+// ProvideKnownDeviceRepository provides the known device repository implementation
+func ProvideKnownDeviceRepository() contract.KnownDeviceRepository {
+	return infrastructure.NewKnownDeviceRepository()
+}
+
+// ProvideDeviceApprovalRepository provides the device approval repository implementation
+func ProvideDeviceApprovalRepository() contract.DeviceApprovalRepository {
+	return infrastructure.NewDeviceApprovalRepository()
+}
+
+// ProvideOutbox provides the recorder of the fake external dependencies, or
+// nil unless MOCK_DEPS is on
+func ProvideOutbox(cfg *config.Config) (*fake.Outbox, error) {
+	if !cfg.Mock.Deps {
+		return nil, nil
+	}
+	if cfg.App.Env == "production" {
+		return nil, errors.New("MOCK_DEPS must not be used in production")
+	}
+	return fake.NewOutbox(cfg.Mock.OutboxSize), nil
+}
+
+// ProvideDebugHandler provides the fake dependency outbox and email preview
+// handler, or nil outside development unless MOCK_DEPS is on
+func ProvideDebugHandler(cfg *config.Config, outbox *fake.Outbox, templates *mailer.TemplateRegistry) *debug.DebugHandler {
+	args := debug.NewDebugHandlerArgs{Outbox: outbox}
+	if cfg.App.Env == "development" {
+		args.Templates = templates
+	}
+	if args.Outbox == nil && args.Templates == nil {
+		return nil
+	}
+	return debug.NewDebugHandler(args)
+}
+
+// ProvideDashboardHandler provides the admin dashboard handler, or nil when
+// the dashboard is disabled
+func ProvideDashboardHandler(cfg *config.Config) *dashboard.DashboardHandler {
+	if !cfg.Admin.Dashboard {
+		return nil
+	}
+	return dashboard.NewDashboardHandler()
+}
+
+// ProvideMailTransport provides the implementation that hands email to the
+// provider
+func ProvideMailTransport(cfg *config.Config, outbox *fake.Outbox) contract.MailTransport {
+	if outbox != nil {
+		return fake.NewMailer(cfg.Mail.From, outbox)
+	}
+	return mailer.NewLogMailer(cfg.Mail.From)
+}
+
+// ProvideEmailQueueRepository provides the outgoing mail queue repository implementation
+func ProvideEmailQueueRepository() contract.EmailQueueRepository {
+	return infrastructure.NewEmailQueueRepository()
+}
+
+// ProvideDeliverQueuedEmailsUseCase provides the mail queue delivery use case
+func ProvideDeliverQueuedEmailsUseCase(
+	cfg *config.Config,
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	transport contract.MailTransport,
+) *mail.DeliverQueuedEmailsUseCase {
+	return mail.NewDeliverQueuedEmailsUseCase(mail.DeliverQueuedEmailsUseCaseArgs{
+		EmailQueueRepo:  emailQueueRepo,
+		SuppressionRepo: suppressionRepo,
+		Transport:       transport,
+		BatchSize:       cfg.Mail.BatchSize,
+		MaxAttempts:     cfg.Mail.MaxAttempts,
+		RetryBase:       cfg.Mail.RetryBase,
+		RetryMax:        cfg.Mail.RetryMax,
+	})
+}
+
+// ProvideMailQueueWorker provides the mail queue worker; the mail module
+// runs it on the leader
+func ProvideMailQueueWorker(
+	cfg *config.Config,
+	deliver *mail.DeliverQueuedEmailsUseCase,
+) *mailer.QueueWorker {
+	return mailer.NewQueueWorker(deliver, cfg.Mail.PollInterval)
+}
+
+// ProvideEmailTemplates provides the email template registry, with the
+// templates in MAIL_TEMPLATE_DIR overriding the built-in ones
+func ProvideEmailTemplates(cfg *config.Config) (*mailer.TemplateRegistry, error) {
+	return mailer.NewTemplateRegistry(cfg.Mail.TemplateDir)
+}
+
+// ProvideMailer provides the outgoing email implementation, which renders
+// and queues every message
+func ProvideMailer(
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	templates *mailer.TemplateRegistry,
+	worker *mailer.QueueWorker,
+) contract.Mailer {
+	return mailer.NewQueueMailer(emailQueueRepo, suppressionRepo, templates, worker)
+}
+
+// ProvideHandleFeedbackWebhookUseCase provides the bounce and complaint
+// webhook use case, or nil when no webhook secret is configured
+func ProvideHandleFeedbackWebhookUseCase(
+	cfg *config.Config,
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	deduper *dedupe.Deduper,
+) *mail.HandleFeedbackWebhookUseCase {
+	if cfg.Mail.WebhookSecret == "" {
+		return nil
+	}
+	return mail.NewHandleFeedbackWebhookUseCase(emailQueueRepo, suppressionRepo, deduper, cfg.Mail.WebhookSecret)
+}
+
+// ProvideListEmailsUseCase provides the mail queue listing use case
+func ProvideListEmailsUseCase(emailQueueRepo contract.EmailQueueRepository) *mail.ListEmailsUseCase {
+	return mail.NewListEmailsUseCase(emailQueueRepo)
+}
+
+// ProvideGetEmailUseCase provides the queued email lookup use case
+func ProvideGetEmailUseCase(emailQueueRepo contract.EmailQueueRepository) *mail.GetEmailUseCase {
+	return mail.NewGetEmailUseCase(emailQueueRepo)
+}
+
+// ProvideResendEmailUseCase provides the queued email resend use case
+func ProvideResendEmailUseCase(
+	emailQueueRepo contract.EmailQueueRepository,
+	worker *mailer.QueueWorker,
+) *mail.ResendEmailUseCase {
+	return mail.NewResendEmailUseCase(emailQueueRepo, worker.Wake)
+}
+
+// ProvideEmailSuppressionRepository provides the list of addresses no mail
+// is sent to
+func ProvideEmailSuppressionRepository() contract.EmailSuppressionRepository {
+	return infrastructure.NewEmailSuppressionRepository()
+}
+
+// ProvideListEmailSuppressionsUseCase provides the suppression list listing use case
+func ProvideListEmailSuppressionsUseCase(suppressionRepo contract.EmailSuppressionRepository) *mail.ListEmailSuppressionsUseCase {
+	return mail.NewListEmailSuppressionsUseCase(suppressionRepo)
+}
+
+// ProvideAddEmailSuppressionUseCase provides the admin address suppression use case
+func ProvideAddEmailSuppressionUseCase(
+	suppressionRepo contract.EmailSuppressionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *mail.AddEmailSuppressionUseCase {
+	return mail.NewAddEmailSuppressionUseCase(suppressionRepo, auditLogRepo)
+}
+
+// ProvideRemoveEmailSuppressionUseCase provides the admin address unsuppression use case
+func ProvideRemoveEmailSuppressionUseCase(
+	suppressionRepo contract.EmailSuppressionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *mail.RemoveEmailSuppressionUseCase {
+	return mail.NewRemoveEmailSuppressionUseCase(suppressionRepo, auditLogRepo)
+}
+
+// ProvideListDeadJobsUseCase provides the failed scheduled job listing use case
+func ProvideListDeadJobsUseCase(jobRepo contract.ScheduledJobRepository) *job.ListDeadJobsUseCase {
+	return job.NewListDeadJobsUseCase(jobRepo)
+}
+
+// ProvideGetJobUseCase provides the scheduled job lookup use case
+func ProvideGetJobUseCase(jobRepo contract.ScheduledJobRepository) *job.GetJobUseCase {
+	return job.NewGetJobUseCase(jobRepo)
+}
+
+// ProvideRequeueJobUseCase provides the failed scheduled job requeue use case
+func ProvideRequeueJobUseCase(jobRepo contract.ScheduledJobRepository, scheduler *jobs.Scheduler) *job.RequeueJobUseCase {
+	return job.NewRequeueJobUseCase(jobRepo, scheduler.Wake)
+}
+
+// ProvideAdminTaskRegistry provides the registry of the tasks admins may
+// trigger, filled in by the modules
+func ProvideAdminTaskRegistry() *jobs.AdminTaskRegistry {
+	return jobs.NewAdminTaskRegistry()
+}
+
+// ProvideAdminTaskRegistryContract exposes the admin task registry to the use cases
+func ProvideAdminTaskRegistryContract(registry *jobs.AdminTaskRegistry) contract.AdminTaskRegistry {
+	return registry
+}
+
+// ProvideListAdminTasksUseCase provides the admin task listing use case
+func ProvideListAdminTasksUseCase(registry contract.AdminTaskRegistry) *job.ListAdminTasksUseCase {
+	return job.NewListAdminTasksUseCase(registry)
+}
+
+// ProvideTriggerAdminTaskUseCase provides the use case queuing admin task runs
+func ProvideTriggerAdminTaskUseCase(
+	registry contract.AdminTaskRegistry,
+	jobRepo contract.ScheduledJobRepository,
+	auditLogRepo contract.AuditLogRepository,
+	scheduler *jobs.Scheduler,
+) *job.TriggerAdminTaskUseCase {
+	return job.NewTriggerAdminTaskUseCase(registry, jobRepo, auditLogRepo, scheduler.Wake)
+}
+
+// ProvidePurgeExpiredTokensUseCase provides the expired session, reset and authorization code purge use case
+func ProvidePurgeExpiredTokensUseCase(
+	sessionRepo contract.SessionRepository,
+	resetRepo contract.PasswordResetRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	purgeDeleted *account.PurgeDeletedAccountsUseCase,
+) *session.PurgeExpiredTokensUseCase {
+	return session.NewPurgeExpiredTokensUseCase(sessionRepo, resetRepo, codeRepo, purgeDeleted)
+}
+
+// ProvideDiscardJobUseCase provides the failed scheduled job discard use case
+func ProvideDiscardJobUseCase(jobRepo contract.ScheduledJobRepository) *job.DiscardJobUseCase {
+	return job.NewDiscardJobUseCase(jobRepo)
+}
+
+// ProvideRefreshStatsUseCase provides the use case recomputing the admin stats
+func ProvideRefreshStatsUseCase(
+	cfg *config.Config,
+	userQuery contract.UserQuery,
+	loginAttemptRepo contract.LoginAttemptRepository,
+	statsRepo contract.StatsRepository,
+) *stats.RefreshStatsUseCase {
+	return stats.NewRefreshStatsUseCase(userQuery, loginAttemptRepo, statsRepo, statsConfig.From(cfg).SignUpDays)
+}
+
+// ProvideGetStatsUseCase provides the admin stats use case
+func ProvideGetStatsUseCase(cfg *config.Config, statsRepo contract.StatsRepository, refresh *stats.RefreshStatsUseCase) *stats.GetStatsUseCase {
+	return stats.NewGetStatsUseCase(statsRepo, refresh, statsConfig.From(cfg).MaxAge)
+}
+
+// ProvideGetQueueStatsUseCase provides the scheduled job queue stats use case
+func ProvideGetQueueStatsUseCase(jobRepo contract.ScheduledJobRepository) *job.GetQueueStatsUseCase {
+	return job.NewGetQueueStatsUseCase(jobRepo)
+}
+
+// ProvideMailHandler provides the mail webhook handler
+func ProvideMailHandler(handleFeedbackWebhookUseCase *mail.HandleFeedbackWebhookUseCase) *mail2.MailHandler {
+	return mail2.NewMailHandler(mail2.NewMailHandlerArgs{
+		HandleFeedbackWebhookUseCase: handleFeedbackWebhookUseCase,
+	})
+}
+
+// ProvideOAuthClientRepository provides the OAuth client repository implementation
+func ProvideOAuthClientRepository() contract.OAuthClientRepository {
+	return infrastructure.NewOAuthClientRepository()
+}
+
+// ProvideAuthorizationCodeRepository provides the OIDC authorization code repository implementation
+func ProvideAuthorizationCodeRepository(clk clock.Clock) contract.AuthorizationCodeRepository {
+	return infrastructure.NewAuthorizationCodeRepository(clk)
+}
+
+// ProvideSAMLConnectionRepository provides the SAML connection repository implementation
+func ProvideSAMLConnectionRepository() contract.SAMLConnectionRepository {
+	return infrastructure.NewSAMLConnectionRepository()
+}
+
+// ProvideLoginStateRepository provides the federated sign-in state repository implementation
+func ProvideLoginStateRepository() contract.LoginStateRepository {
+	return infrastructure.NewLoginStateRepository()
+}
+
+// ProvideSAMLServiceProvider provides the SAML service provider implementation
+func ProvideSAMLServiceProvider(cfg *config.Config, clk clock.Clock) contract.SAMLServiceProvider {
+	return saml.NewServiceProvider(saml.ServiceProviderArgs{
+		BaseURL:   cfg.SAML.BaseURL,
+		ClockSkew: cfg.SAML.ClockSkew,
+		Clock:     clk,
+	})
+}
+
+// ProvideSubscriptionRepository provides the billing subscription repository implementation
+func ProvideSubscriptionRepository() contract.SubscriptionRepository {
+	return infrastructure.NewSubscriptionRepository()
+}
+
+// ProvideUsageRepository provides the aggregated usage repository implementation
+func ProvideUsageRepository() contract.UsageRepository {
+	return infrastructure.NewUsageRepository()
+}
+
+// ProvideAggregateUsageUseCase provides the usage aggregation use case
+func ProvideAggregateUsageUseCase(usageRepo contract.UsageRepository) *usage.AggregateUsageUseCase {
+	return usage.NewAggregateUsageUseCase(usageRepo)
+}
+
+// ProvideUsageMeter provides the usage meter and subscribes the aggregation
+// to the events it publishes
+func ProvideUsageMeter(bus *eventbus.Bus, aggregate *usage.AggregateUsageUseCase, deduper *dedupe.Deduper) contract.UsageMeter {
+	metering.SubscribeAggregation(bus, aggregate, deduper)
+	return metering.NewBusMeter(bus)
+}
+
+// ProvideAnalyticsTracker provides the product analytics tracker sending to
+// ANALYTICS_SINK, or one dropping every event when it is none
+func ProvideAnalyticsTracker(cfg *config.Config) (contract.AnalyticsTracker, error) {
+	var sink analytics.Sink
+	switch cfg.Analytics.Sink {
+	case "none":
+		return analytics.NopTracker{}, nil
+	case "segment":
+		s, err := analytics.NewSegmentSink(analytics.SegmentSinkArgs{
+			WriteKey: cfg.Analytics.SegmentWriteKey,
+			BaseURL:  cfg.Analytics.SegmentURL,
+			Timeout:  cfg.Analytics.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case "kafka":
+		s, err := analytics.NewKafkaSink(analytics.KafkaSinkArgs{
+			RESTURL: cfg.Analytics.KafkaRESTURL,
+			Topic:   cfg.Analytics.KafkaTopic,
+			Timeout: cfg.Analytics.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case "postgres":
+		db, err := openPostgres(cfg, cfg.Analytics.SQLDriver)
+		if err != nil {
+			return nil, fmt.Errorf("open analytics database: %w", err)
+		}
+		sink = analytics.NewPostgresSink(db)
+	default:
+		return nil, fmt.Errorf("ANALYTICS_SINK must be none, segment, kafka or postgres, got %q", cfg.Analytics.Sink)
+	}
+	return analytics.NewBatchingTracker(analytics.BatchingTrackerArgs{
+		Sink:          sink,
+		Buffer:        cfg.Analytics.Buffer,
+		BatchSize:     cfg.Analytics.BatchSize,
+		FlushInterval: cfg.Analytics.FlushInterval,
+		SendTimeout:   cfg.Analytics.Timeout,
+	}), nil
+}
+
+// ProvideGetCurrentUsageUseCase provides the current period usage use case
+func ProvideGetCurrentUsageUseCase(usageRepo contract.UsageRepository) *usage.GetCurrentUsageUseCase {
+	return usage.NewGetCurrentUsageUseCase(usageRepo)
+}
+
+// ProvideExportUsageUseCase provides the usage export use case
+func ProvideExportUsageUseCase(usageRepo contract.UsageRepository) *usage.ExportUsageUseCase {
+	return usage.NewExportUsageUseCase(usageRepo)
+}
+
+// ProvideBillingProvider provides the Stripe billing provider, the fake one
+// under MOCK_DEPS, or nil when billing is disabled
+func ProvideBillingProvider(cfg *config.Config, outbox *fake.Outbox) (contract.BillingProvider, error) {
+	if outbox != nil {
+		return fake.NewBillingProvider(outbox), nil
+	}
+	if !cfg.Billing.Enabled {
+		return nil, nil
+	}
+	provider, err := billing.NewStripeProvider(billing.StripeProviderArgs{
+		SecretKey:        cfg.Billing.StripeSecretKey,
+		WebhookSecret:    cfg.Billing.StripeWebhookSecret,
+		WebhookTolerance: cfg.Billing.WebhookTolerance,
+		Timeout:          cfg.Billing.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configure BILLING: %w", err)
+	}
+	return provider, nil
+}
+
+// ProvideEntitlementChecker provides the subscription-backed plan entitlement checker
+func ProvideEntitlementChecker(subscriptionRepo contract.SubscriptionRepository) contract.EntitlementChecker {
+	return billing2.NewEntitlementService(subscriptionRepo)
+}
+
+// ProvidePlanGate provides the plan tier gate for endpoints and use cases
+func ProvidePlanGate(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	entitlements contract.EntitlementChecker,
+) (contract.PlanGate, error) {
+	gate, err := policy.NewPlanPolicy(policy.PlanPolicyArgs{
+		UserRepo:     userRepo,
+		Entitlements: entitlements,
+		Tiers:        cfg.Plan.Tiers,
+		Features:     cfg.Plan.Features,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("configure PLAN: %w", err)
+	}
+	return gate, nil
+}
+
+// ProvideSMSSender provides the outgoing text message implementation
+func ProvideSMSSender(outbox *fake.Outbox) contract.SMSSender {
+	if outbox != nil {
+		return fake.NewSMSSender(outbox)
+	}
+	return sms.NewLogSender()
+}
+
+// ProvideLoginAttemptRepository provides the login history repository implementation
+func ProvideLoginAttemptRepository() contract.LoginAttemptRepository {
+	return infrastructure.NewLoginAttemptRepository()
+}
+
+// ProvideAuditLogRepository provides the audit log repository implementation,
+// streaming the events to the SIEM when AUDIT_STREAM_SINK is set
+func ProvideAuditLogRepository(geoLocator contract.GeoLocator, stream *siem.Streamer) contract.AuditLogRepository {
+	var repo contract.AuditLogRepository = infrastructure.NewAuditLogRepository()
+	if stream != nil {
+		repo = infrastructure.NewStreamingAuditLogRepository(repo, stream)
+	}
+	return infrastructure.NewLocatingAuditLogRepository(repo, geoLocator)
+}
+
+// ProvideGeoLocator provides the IP geolocation implementation
+func ProvideGeoLocator(cfg *config.Config) (contract.GeoLocator, error) {
+	if cfg.GeoIP.DBPath == "" {
+		return geo.NewNoopLocator(), nil
+	}
+	return geo.NewMaxMindLocator(geo.MaxMindLocatorArgs{
+		Path:           cfg.GeoIP.DBPath,
+		ReloadInterval: cfg.GeoIP.ReloadInterval,
+	})
+}
+
+// ProvideInvitationRepository provides the invitation repository implementation
+func ProvideInvitationRepository() contract.InvitationRepository {
+	return infrastructure.NewInvitationRepository()
+}
+
+// ProvideSagaStore provides the saga state repository implementation
+func ProvideSagaStore() saga.Store {
+	return infrastructure.NewSagaRepository()
+}
+
+// ProvidePasswordResetRepository provides the password reset repository implementation
+func ProvidePasswordResetRepository() contract.PasswordResetRepository {
+	return infrastructure.NewPasswordResetRepository()
+}
+
+// ProvideAccountRestoreRepository provides the account restore link repository implementation
+func ProvideAccountRestoreRepository() contract.AccountRestoreRepository {
+	return infrastructure.NewAccountRestoreRepository()
+}
+
+// ProvideTermsAcceptanceRepository provides the terms acceptance repository implementation
+func ProvideTermsAcceptanceRepository() contract.TermsAcceptanceRepository {
+	return infrastructure.NewTermsAcceptanceRepository()
+}
+
+// ProvideEmailChangeRepository provides the email change repository implementation
+func ProvideEmailChangeRepository() contract.EmailChangeRepository {
+	return infrastructure.NewEmailChangeRepository()
+}
+
+// ProvidePhoneOTPRepository provides the texted one-time code repository implementation
+func ProvidePhoneOTPRepository() contract.PhoneOTPRepository {
+	return infrastructure.NewPhoneOTPRepository()
+}
+
+// ProvideExternalIdentityRepository provides the external identity link repository implementation
+func ProvideExternalIdentityRepository() contract.ExternalIdentityRepository {
+	return infrastructure.NewExternalIdentityRepository()
+}
+
+// ProvideServiceAccountRepository provides the service account repository implementation
+func ProvideServiceAccountRepository() contract.ServiceAccountRepository {
+	return infrastructure.NewServiceAccountRepository()
+}
+
+// ProvideServiceAccountKeyRepository provides the service account key repository implementation
+func ProvideServiceAccountKeyRepository() contract.ServiceAccountKeyRepository {
+	return infrastructure.NewServiceAccountKeyRepository()
+}
+
+// ProvideJWTClient provides the JWT client configured from JWT settings
+func ProvideJWTClient(cfg *config.Config, clk clock.Clock) *jwt.Client {
+	return jwt.NewClient(jwt.ClientArgs{
+		SecretKey:       cfg.JWT.Secret,
+		AccessDuration:  cfg.JWT.AccessTTL,
+		RefreshDuration: cfg.JWT.RefreshTTL,
+		Issuer:          cfg.JWT.Issuer,
+		Audience:        cfg.JWT.Audience,
+		Leeway:          cfg.JWT.Leeway,
+		Clock:           clk,
+	})
+}
+
+// ProvideExternalTokenVerifier provides the verifier of the tokens of the
+// issuers in EXTERNAL_AUTH_ISSUERS, or nil when there are none
+func ProvideExternalTokenVerifier(cfg *config.Config, clk clock.Clock) *jwks.Verifier {
+	external := externalAuthConfig.From(cfg)
+	if len(external.Issuers) == 0 {
+		return nil
+	}
+	return jwks.NewVerifier(jwks.VerifierArgs{
+		Issuers:    external.Issuers,
+		Audience:   external.Audience,
+		Leeway:     cfg.JWT.Leeway,
+		MinRefresh: external.MinRefreshInterval,
+		MaxRefresh: external.RefreshInterval,
+		Timeout:    external.Timeout,
+		Clock:      clk,
+	})
+}
+
+// ProvideServiceAccounts lets organizations' service accounts authenticate
+// with their keys
+func ProvideServiceAccounts(accountRepo contract.ServiceAccountRepository, keyRepo contract.ServiceAccountKeyRepository) *middleware.ServiceAccounts {
+	return &middleware.ServiceAccounts{Accounts: accountRepo, Keys: keyRepo}
+}
+
+// ProvideExternalUsers lets users authenticate with the tokens of the
+// external issuers, or returns nil when there are none
+func ProvideExternalUsers(verifier *jwks.Verifier, resolve *auth.ResolveExternalUserUseCase) *middleware.ExternalUsers {
+	if verifier == nil {
+		return nil
+	}
+	return &middleware.ExternalUsers{Verifier: verifier, Resolve: resolve.Execute}
+}
+
+// ProvideTokenIssuer provides the token issuer implementation
+func ProvideTokenIssuer(cfg *config.Config, client *jwt.Client, settingsRepo contract.OrganizationSettingsRepository) contract.TokenIssuer {
+	return token.NewJWTIssuer(client, cfg.Admin.Emails, settingsRepo)
+}
+
+// ProvideClientTokenIssuer provides the machine client token issuer implementation
+func ProvideClientTokenIssuer(client *jwt.Client) contract.ClientTokenIssuer {
+	return token.NewJWTIssuer(client, nil, nil)
+}
+
+// ProvideAccessTokenVerifier provides the access token verifier of token
+// introspection
+func ProvideAccessTokenVerifier(client *jwt.Client) contract.AccessTokenVerifier {
+	return token.NewJWTIssuer(client, nil, nil)
+}
+
+// ProvideOIDCTokenIssuer provides the OpenID Connect token issuer, or nil
+// when provider mode is disabled
+func ProvideOIDCTokenIssuer(cfg *config.Config, client *jwt.Client) (contract.OIDCTokenIssuer, error) {
+	if !cfg.OIDC.Enabled {
+		return nil, nil
+	}
+	key, ephemeral, err := token.LoadSigningKey(cfg.OIDC.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load OIDC_SIGNING_KEY_FILE: %w", err)
+	}
+	if ephemeral {
+		logger.L().Warn("OIDC_SIGNING_KEY_FILE not set, signing ID tokens with a generated key")
+	}
+	return token.NewOIDCIssuer(token.OIDCIssuerArgs{
+		JWTClient:  client,
+		SigningKey: key,
+		Issuer:     cfg.OIDC.Issuer,
+		IDTokenTTL: cfg.OIDC.IDTokenTTL,
+	}), nil
+}
+
+// ProvideDisposableEmailPolicy provides the disposable email block list,
+// read from SIGNUP_DISPOSABLE_DOMAINS_FILE when set
+func ProvideDisposableEmailPolicy(cfg *config.Config) (*policy.DisposableEmailPolicy, error) {
+	domains := policy.DefaultDisposableDomains
+	if path := cfg.SignUp.DisposableDomainsFile; path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read disposable domains: %w", err)
+		}
+		domains = strings.Split(string(b), "\n")
+	}
+	return policy.NewDisposableEmailPolicy(policy.NewDomainList(domains)), nil
+}
+
+// ProvidePasswordPolicy provides the check new passwords pass outside sign up
+func ProvidePasswordPolicy() contract.PasswordPolicy {
+	return policy.NewPasswordPolicy()
+}
+
+// ProvidePasswordHasher provides the bcrypt worker pool every password hash
+// and compare runs on
+func ProvidePasswordHasher(cfg *config.Config) contract.PasswordHasher {
+	return hasher.NewBcryptHasher(hasher.BcryptHasherArgs{
+		Workers: cfg.Password.HashWorkers,
+		Queue:   cfg.Password.HashQueue,
+	})
+}
+
+// ProvideCaptchaVerifier provides the CAPTCHA verifier, or nil when no
+// provider is configured or APP_ENV is not one of CAPTCHA_ENVIRONMENTS.
+// Under MOCK_DEPS it is the fake verifier
+func ProvideCaptchaVerifier(cfg *config.Config, outbox *fake.Outbox) (contract.CaptchaVerifier, error) {
+	if outbox != nil {
+		return fake.NewCaptchaVerifier(outbox), nil
+	}
+
+	c := cfg.Captcha
+	if c.Provider == captcha.PROVIDER_NONE {
+		return nil, nil
+	}
+	if len(c.Environments) > 0 && !slices.Contains(c.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+
+	verifier, err := captcha.NewSiteVerifier(captcha.SiteVerifierArgs{
+		Provider: c.Provider,
+		Secret:   c.Secret,
+		MinScore: c.MinScore,
+		Timeout:  c.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load CAPTCHA config: %w", err)
+	}
+	return verifier, nil
+}
+
+// ProvideCookieCodec provides the codec sealing cookies and the other values
+// handed to clients, keyed by COOKIE_SECRETS
+func ProvideCookieCodec(cfg *config.Config) (*securecookie.Codec, error) {
+	secrets := cfg.Cookie.Secrets
+	if len(secrets) == 0 && cfg.SignUpBot.FormSecret != "" {
+		secrets = []string{cfg.SignUpBot.FormSecret}
+	}
+	if len(secrets) == 0 {
+		generated, err := securetoken.New(32)
+		if err != nil {
+			return nil, err
+		}
+		secrets = []string{generated}
+		if cfg.App.Env == "production" {
+			logger.L().Warn("COOKIE_SECRETS not set, sealing cookies with a generated secret lost on restart")
+		}
+	}
+	keys := make([][]byte, len(secrets))
+	for i, s := range secrets {
+		keys[i] = []byte(s)
+	}
+	codec, err := securecookie.New(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("COOKIE_SECRETS: %w", err)
+	}
+	return codec, nil
+}
+
+// ProvideIPReputation provides the address reputation used by sign-up bot
+// detection
+func ProvideIPReputation(cfg *config.Config) (contract.IPReputation, error) {
+	networks, err := middleware.ParsePrefixes(cfg.SignUpBot.SuspiciousNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("parse SIGNUP_BOT_SUSPICIOUS_NETWORKS: %w", err)
+	}
+	return reputation.NewNetworkReputation(networks, cfg.SignUpBot.NetworkRisk), nil
+}
+
+// ProvideBotPolicy provides the sign-up bot detection policy. It is built
+// even when disabled, so forms can fetch their tokens ahead of enabling it.
+func ProvideBotPolicy(
+	cfg *config.Config,
+	cookies *securecookie.Codec,
+	ipReputation contract.IPReputation,
+	verifier contract.CaptchaVerifier,
+) (*policy.BotPolicy, error) {
+	c := cfg.SignUpBot
+	if c.CaptchaScore <= 0 || c.BlockScore < c.CaptchaScore {
+		return nil, errors.New("SIGNUP_BOT_CAPTCHA_SCORE must be positive and SIGNUP_BOT_BLOCK_SCORE at least as high")
+	}
+	return policy.NewBotPolicy(policy.BotPolicyArgs{
+		Cookies:       cookies,
+		MinSubmitTime: c.MinSubmitTime,
+		FormTTL:       c.FormTTL,
+		Reputation:    ipReputation,
+		Captcha:       verifier,
+		CaptchaScore:  c.CaptchaScore,
+		BlockScore:    c.BlockScore,
+	}), nil
+}
+
+// ProvideIssueSignUpFormUseCase provides the sign-up form token use case
+func ProvideIssueSignUpFormUseCase(bot *policy.BotPolicy) *auth.IssueSignUpFormUseCase {
+	return auth.NewIssueSignUpFormUseCase(bot)
+}
+
+// ProvideSignUpUseCase provides the sign up use case with the configured
+// email policies
+func ProvideSignUpUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+	bot *policy.BotPolicy, hasher2 contract.PasswordHasher,
+
+	sagaStore saga.Store,
+	notifier contract.Notifier,
+	analyticsTracker contract.AnalyticsTracker,
+) *auth.SignUpUseCase {
+	policies := signUpPolicies(cfg, userRepo, disposable, invitationRepo, termsRepo)
+	if cfg.SignUpBot.Enabled {
+
+		policies = append([]contract.SignUpPolicy{bot}, policies...)
+	}
+	return auth.NewSignUpUseCase(userRepo, hasher2, sagaStore, notifier, analyticsTracker, policies...)
+}
+
+// ProvideUpgradeGuestUseCase provides the guest upgrade use case, bound by the
+// same policies as sign up
+func ProvideUpgradeGuestUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository, hasher2 contract.PasswordHasher,
+
+) *account.UpgradeGuestUseCase {
+	return account.NewUpgradeGuestUseCase(userRepo, hasher2, signUpPolicies(cfg, userRepo, disposable, invitationRepo, termsRepo)...)
+}
+
+// ProvideListIdentitiesUseCase provides the use case listing a user's sign-in methods
+func ProvideListIdentitiesUseCase(userRepo contract.UserRepository) *account.ListIdentitiesUseCase {
+	return account.NewListIdentitiesUseCase(userRepo)
+}
+
+// ProvideLinkIdentityUseCase provides the use case linking a sign-in method to an account
+func ProvideLinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository, hasher2 contract.PasswordHasher,
+
+	passwordPolicy contract.PasswordPolicy,
+	phoneVerification *phone.RequestPhoneVerificationUseCase,
+) *account.LinkIdentityUseCase {
+	return account.NewLinkIdentityUseCase(userRepo, sessionRepo, hasher2, passwordPolicy, phoneVerification)
+}
+
+// ProvideUnlinkIdentityUseCase provides the use case unlinking a sign-in method from an account
+func ProvideUnlinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository, hasher2 contract.PasswordHasher,
+
+) *account.UnlinkIdentityUseCase {
+	return account.NewUnlinkIdentityUseCase(userRepo, sessionRepo, hasher2)
+}
+
+// ProvideStartGuestSessionUseCase provides the anonymous session use case
+func ProvideStartGuestSessionUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+) *auth.StartGuestSessionUseCase {
+	return auth.NewStartGuestSessionUseCase(userRepo, sessionRepo, tokenIssuer, cfg.Guest.Enabled)
+}
+
+// signUpPolicies builds the configured policies every new account must pass.
+func signUpPolicies(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	disposable *policy.DisposableEmailPolicy,
+	invitationRepo contract.InvitationRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+) []contract.SignUpPolicy {
+	policies := []contract.SignUpPolicy{policy.NewAllowedDomainsPolicy(policy.NewDomainList(cfg.SignUp.AllowedDomains)), policy.NewPasswordPolicy()}
+	if cfg.SignUp.BlockDisposable {
+		policies = append(policies, disposable)
+	}
+	if cfg.SignUp.RejectPlusAliases {
+		policies = append(policies, policy.NewPlusAliasPolicy(userRepo))
+	}
+	if cfg.SignUp.InviteOnly {
+		policies = append(policies, policy.NewInvitePolicy(invitationRepo))
+	}
+	if cfg.Terms.RequireAtSignUp {
+		policies = append(policies, policy.NewTermsPolicy(termsRepo, currentTerms(cfg)))
+	}
+	return policies
+}
+
+// ProvideDeviceGuard provides the new-device sign-in detector
+func ProvideDeviceGuard(
+	cfg *config.Config,
+	knownDeviceRepo contract.KnownDeviceRepository,
+	deviceApprovalRepo contract.DeviceApprovalRepository, mailer2 contract.Mailer,
+
+	geoLocator contract.GeoLocator,
+	settingsRepo contract.OrganizationSettingsRepository,
+) *auth.DeviceGuard {
+	return auth.NewDeviceGuard(auth.DeviceGuardArgs{
+		KnownDeviceRepo:    knownDeviceRepo,
+		DeviceApprovalRepo: deviceApprovalRepo,
+		Mailer:             mailer2,
+		GeoLocator:         geoLocator,
+		Organizations:      settingsRepo,
+		Enabled:            cfg.Device.Enabled,
+		RequireApproval:    cfg.Device.RequireApproval,
+		ApprovalTTL:        cfg.Device.ApprovalTTL,
+		LinkBaseURL:        cfg.Device.LinkBaseURL,
+	})
+}
+
+// ProvideLoginRecorder provides the sign-in attempt recorder
+func ProvideLoginRecorder(
+	loginAttemptRepo contract.LoginAttemptRepository,
+	geoLocator contract.GeoLocator,
+	analyticsTracker contract.AnalyticsTracker,
+) *auth.LoginRecorder {
+	return auth.NewLoginRecorder(loginAttemptRepo, geoLocator, analyticsTracker)
+}
+
+// ProvideSignInUseCase provides the sign in use case
+func ProvideSignInUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer, hasher2 contract.PasswordHasher,
+
+	deviceGuard *auth.DeviceGuard,
+	loginRecorder *auth.LoginRecorder,
+) *auth.SignInUseCase {
+	return auth.NewSignInUseCase(auth.SignInUseCaseArgs{
+		UserRepo:       userRepo,
+		SessionRepo:    sessionRepo,
+		Hasher:         hasher2,
+		TokenIssuer:    tokenIssuer,
+		DeviceGuard:    deviceGuard,
+		LoginRecorder:  loginRecorder,
+		PasswordMaxAge: cfg.Password.MaxAge,
+	})
+}
+
+// ProvideRotateExpiredPasswordUseCase provides the expired password rotation use case
+func ProvideRotateExpiredPasswordUseCase(
+	signInUseCase *auth.SignInUseCase,
+	userRepo contract.UserRepository,
+	passwordPolicy contract.PasswordPolicy,
+) *auth.RotateExpiredPasswordUseCase {
+	return auth.NewRotateExpiredPasswordUseCase(signInUseCase, userRepo, passwordPolicy)
+}
+
+// ProvideForcePasswordRotationUseCase provides the admin forced password rotation use case
+func ProvideForcePasswordRotationUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+) *admin.ForcePasswordRotationUseCase {
+	return admin.NewForcePasswordRotationUseCase(userRepo, sessionRepo)
+}
+
+// ProvideImpersonateUserUseCase provides the admin impersonation use case
+func ProvideImpersonateUserUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	auditLogRepo contract.AuditLogRepository,
+	tokenIssuer contract.TokenIssuer,
+	notifier contract.Notifier,
+) *admin.ImpersonateUserUseCase {
+	return admin.NewImpersonateUserUseCase(admin.ImpersonateUserUseCaseArgs{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		AuditLogRepo: auditLogRepo,
+		TokenIssuer:  tokenIssuer,
+		Notifier:     notifier,
+		TTL:          cfg.Impersonate.TTL,
+	})
+}
+
+// ProvideSetUserStatusUseCase provides the admin user status use case
+func ProvideSetUserStatusUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.SetUserStatusUseCase {
+	return admin.NewSetUserStatusUseCase(userRepo, sessionRepo, auditLogRepo)
+}
+
+// ProvideRevokeUserAccessUseCase provides the admin user access revocation use case
+func ProvideRevokeUserAccessUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.RevokeUserAccessUseCase {
+	return admin.NewRevokeUserAccessUseCase(userRepo, sessionRepo, tokenRepo, auditLogRepo)
+}
+
+// ProvideSetUserPlanUseCase provides the admin user quota plan use case
+func ProvideSetUserPlanUseCase(
+	userRepo contract.UserRepository,
+	auditLogRepo contract.AuditLogRepository,
+	limiter *quota.Limiter,
+) *admin.SetUserPlanUseCase {
+	return admin.NewSetUserPlanUseCase(userRepo, auditLogRepo, limiter.PlanNames())
+}
+
+// ProvideListUserTokensUseCase provides the admin user token inspection use case
+func ProvideListUserTokensUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	personalTokenRepo contract.PersonalAccessTokenRepository,
+) *admin.ListUserTokensUseCase {
+	return admin.NewListUserTokensUseCase(admin.ListUserTokensUseCaseArgs{
+		UserRepo:          userRepo,
+		PasswordResetRepo: passwordResetRepo,
+		EmailChangeRepo:   emailChangeRepo,
+		PersonalTokenRepo: personalTokenRepo,
+	})
+}
+
+// ProvideCreateServiceAccountUseCase provides the admin service account creation use case
+func ProvideCreateServiceAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	connectionRepo contract.SAMLConnectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceaccount.CreateAccountUseCase {
+	return serviceaccount.NewCreateAccountUseCase(accountRepo, connectionRepo, auditLogRepo)
+}
+
+// ProvideListServiceAccountsUseCase provides the admin service account listing use case
+func ProvideListServiceAccountsUseCase(accountRepo contract.ServiceAccountRepository) *serviceaccount.ListAccountsUseCase {
+	return serviceaccount.NewListAccountsUseCase(accountRepo)
+}
+
+// ProvideDeleteServiceAccountUseCase provides the admin service account deletion use case
+func ProvideDeleteServiceAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceaccount.DeleteAccountUseCase {
+	return serviceaccount.NewDeleteAccountUseCase(accountRepo, keyRepo, auditLogRepo)
+}
+
+// ProvideCreateServiceAccountKeyUseCase provides the admin service account key creation use case
+func ProvideCreateServiceAccountKeyUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *serviceaccount.CreateKeyUseCase {
+	return serviceaccount.NewCreateKeyUseCase(accountRepo, keyRepo, auditLogRepo)
+}
+
+// ProvideListServiceAccountKeysUseCase provides the admin service account key listing use case
+func ProvideListServiceAccountKeysUseCase(accountRepo contract.ServiceAccountRepository, keyRepo contract.ServiceAccountKeyRepository) *serviceaccount.ListKeysUseCase {
+	return serviceaccount.NewListKeysUseCase(accountRepo, keyRepo)
+}
+
+// ProvideRevokeServiceAccountKeyUseCase provides the admin service account key revocation use case
+func ProvideRevokeServiceAccountKeyUseCase(keyRepo contract.ServiceAccountKeyRepository, auditLogRepo contract.AuditLogRepository) *serviceaccount.RevokeKeyUseCase {
+	return serviceaccount.NewRevokeKeyUseCase(keyRepo, auditLogRepo)
+}
+
+// ProvideSetTenantQuotaUseCase provides the admin tenant quota use case
+func ProvideSetTenantQuotaUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.SetTenantQuotaUseCase {
+	return admin.NewSetTenantQuotaUseCase(connectionRepo, auditLogRepo)
+}
+
+// ProvideOrganizationSettingsRepository provides the tenants' settings
+func ProvideOrganizationSettingsRepository() contract.OrganizationSettingsRepository {
+	return infrastructure.NewOrganizationSettingsRepository()
+}
+
+// ProvideGetOrganizationSettingsUseCase provides the admin tenant settings use case
+func ProvideGetOrganizationSettingsUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+) *admin.GetOrganizationSettingsUseCase {
+	return admin.NewGetOrganizationSettingsUseCase(connectionRepo, settingsRepo)
+}
+
+// ProvideUpdateOrganizationSettingsUseCase provides the admin tenant settings update use case
+func ProvideUpdateOrganizationSettingsUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.UpdateOrganizationSettingsUseCase {
+	return admin.NewUpdateOrganizationSettingsUseCase(connectionRepo, settingsRepo, auditLogRepo)
+}
+
+// ProvideGetMaintenanceUseCase provides the admin maintenance mode read use case
+func ProvideGetMaintenanceUseCase(maintenanceRepo contract.MaintenanceRepository) *admin.GetMaintenanceUseCase {
+	return admin.NewGetMaintenanceUseCase(maintenanceRepo)
+}
+
+// ProvideSetMaintenanceUseCase provides the admin maintenance mode toggle use case
+func ProvideSetMaintenanceUseCase(
+	maintenanceRepo contract.MaintenanceRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.SetMaintenanceUseCase {
+	return admin.NewSetMaintenanceUseCase(maintenanceRepo, auditLogRepo)
+}
+
+// ProvideAuthProtectionRepository provides the override of the credential
+// routes' adaptive protection, starting as AUTH_PROTECTION_MODE
+func ProvideAuthProtectionRepository(cfg *config.Config) (contract.AuthProtectionRepository, error) {
+	mode := authProtectionConfig.From(cfg).Mode
+	if !entity.IsAuthProtectionMode(mode) {
+		return nil, fmt.Errorf("AUTH_PROTECTION_MODE must be auto, on or off, got %q", mode)
+	}
+	return infrastructure.NewAuthProtectionRepository(entity.AuthProtection{Mode: mode}), nil
+}
+
+// ProvideGetAuthProtectionUseCase provides the admin auth protection override read use case
+func ProvideGetAuthProtectionUseCase(authProtectionRepo contract.AuthProtectionRepository) *admin.GetAuthProtectionUseCase {
+	return admin.NewGetAuthProtectionUseCase(authProtectionRepo)
+}
+
+// ProvideSetAuthProtectionUseCase provides the admin auth protection override use case
+func ProvideSetAuthProtectionUseCase(
+	authProtectionRepo contract.AuthProtectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *admin.SetAuthProtectionUseCase {
+	return admin.NewSetAuthProtectionUseCase(authProtectionRepo, auditLogRepo)
+}
+
+// ProvideDeprecationUsageRepository provides the usage of the deprecated
+// routes and fields, kept per instance
+func ProvideDeprecationUsageRepository() contract.DeprecationUsageRepository {
+	return infrastructure.NewDeprecationUsageRepository()
+}
+
+// ProvideListDeprecationUsageUseCase provides the admin deprecation usage report use case
+func ProvideListDeprecationUsageUseCase(deprecationUsageRepo contract.DeprecationUsageRepository) *admin.ListDeprecationUsageUseCase {
+	return admin.NewListDeprecationUsageUseCase(deprecationUsageRepo)
+}
+
+// ProvideListUsersUseCase provides the user listing use case
+func ProvideListUsersUseCase(
+	userQuery contract.UserQuery,
+	sessionRepo contract.SessionRepository,
+	samlConnRepo contract.SAMLConnectionRepository,
+) *admin.ListUsersUseCase {
+	return admin.NewListUsersUseCase(userQuery, sessionRepo, samlConnRepo)
+}
+
+// ProvideExportUsersUseCase provides the admin user export use case
+func ProvideExportUsersUseCase(userQuery contract.UserQuery) *admin.ExportUsersUseCase {
+	return admin.NewExportUsersUseCase(userQuery)
+}
+
+// ProvideExportAuditLogUseCase provides the admin audit log export use case
+func ProvideExportAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *admin.ExportAuditLogUseCase {
+	return admin.NewExportAuditLogUseCase(auditLogRepo)
+}
+
+// ProvideListAuditLogUseCase provides the audit log listing use case
+func ProvideListAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *admin.ListAuditLogUseCase {
+	return admin.NewListAuditLogUseCase(auditLogRepo)
+}
+
+// ProvideOTPService provides the texted one-time code issuer
+func ProvideOTPService(
+	cfg *config.Config,
+	otpRepo contract.PhoneOTPRepository,
+	smsSender contract.SMSSender,
+	clk clock.Clock,
+) *phone.OTPService {
+	return phone.NewOTPService(phone.OTPServiceArgs{
+		OTPRepo:        otpRepo,
+		SMSSender:      smsSender,
+		TTL:            cfg.PhoneOTP.TTL,
+		CodeLength:     cfg.PhoneOTP.Length,
+		MaxAttempts:    cfg.PhoneOTP.MaxAttempts,
+		ResendInterval: cfg.PhoneOTP.ResendInterval,
+		MaxPerHour:     cfg.PhoneOTP.MaxPerHour,
+		Clock:          clk,
+	})
+}
+
+// ProvideRequestPhoneVerificationUseCase provides the phone number change use case
+func ProvideRequestPhoneVerificationUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	otpService *phone.OTPService,
+	scheduler contract.JobScheduler,
+) *phone.RequestPhoneVerificationUseCase {
+	return phone.NewRequestPhoneVerificationUseCase(userRepo, otpService, scheduler, cfg.PhoneOTP.UnverifiedTTL)
+}
+
+// ProvideVerifyPhoneUseCase provides the phone number verification use case
+func ProvideVerifyPhoneUseCase(
+	userRepo contract.UserRepository,
+	otpService *phone.OTPService,
+	analyticsTracker contract.AnalyticsTracker,
+	scheduler contract.JobScheduler,
+) *phone.VerifyPhoneUseCase {
+	return phone.NewVerifyPhoneUseCase(userRepo, otpService, analyticsTracker, scheduler)
+}
+
+// ProvideClearUnverifiedPhoneUseCase provides the unverified phone number clearing job
+func ProvideClearUnverifiedPhoneUseCase(userRepo contract.UserRepository) *phone.ClearUnverifiedPhoneUseCase {
+	return phone.NewClearUnverifiedPhoneUseCase(userRepo)
+}
+
+// ProvideRequestSignInCodeUseCase provides the texted sign-in code use case
+func ProvideRequestSignInCodeUseCase(
+	userRepo contract.UserRepository,
+	otpService *phone.OTPService,
+) *auth.RequestSignInCodeUseCase {
+	return auth.NewRequestSignInCodeUseCase(userRepo, otpService)
+}
+
+// ProvideSignInWithCodeUseCase provides the phone code sign in use case
+func ProvideSignInWithCodeUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	otpService *phone.OTPService,
+	deviceGuard *auth.DeviceGuard,
+	loginRecorder *auth.LoginRecorder,
+) *auth.SignInWithCodeUseCase {
+	return auth.NewSignInWithCodeUseCase(auth.SignInWithCodeUseCaseArgs{
+		UserRepo:      userRepo,
+		SessionRepo:   sessionRepo,
+		TokenIssuer:   tokenIssuer,
+		OTPService:    otpService,
+		DeviceGuard:   deviceGuard,
+		LoginRecorder: loginRecorder,
+	})
+}
+
+// ProvideResolveExternalUserUseCase provides the use case mapping external subjects to local users
+func ProvideResolveExternalUserUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	identityRepo contract.ExternalIdentityRepository, hasher2 contract.PasswordHasher,
+
+) *auth.ResolveExternalUserUseCase {
+	external := externalAuthConfig.From(cfg)
+	return auth.NewResolveExternalUserUseCase(auth.ResolveExternalUserUseCaseArgs{
+		UserRepo:     userRepo,
+		IdentityRepo: identityRepo,
+		Hasher:       hasher2,
+		Provision:    external.ProvisionUsers,
+		LinkByEmail:  external.LinkByEmail,
+	})
+}
+
+// ProvideSignInWithSAMLUseCase provides the SAML assertion consumer use case
+func ProvideSignInWithSAMLUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	connectionRepo contract.SAMLConnectionRepository,
+	serviceProvider contract.SAMLServiceProvider,
+	loginStateRepo contract.LoginStateRepository,
+	loginRecorder *auth.LoginRecorder, hasher2 contract.PasswordHasher,
+
+	cfg *config.Config,
+) *auth.SignInWithSAMLUseCase {
+	return auth.NewSignInWithSAMLUseCase(auth.SignInWithSAMLUseCaseArgs{
+		UserRepo:          userRepo,
+		SessionRepo:       sessionRepo,
+		Hasher:            hasher2,
+		TokenIssuer:       tokenIssuer,
+		ConnectionRepo:    connectionRepo,
+		ServiceProvider:   serviceProvider,
+		LoginStateRepo:    loginStateRepo,
+		LoginRecorder:     loginRecorder,
+		AllowIdPInitiated: cfg.SAML.AllowIdPInitiated,
+	})
+}
+
+// ProvideReviewDeviceUseCase provides the new device approve/deny use case
+func ProvideReviewDeviceUseCase(
+	knownDeviceRepo contract.KnownDeviceRepository,
+	deviceApprovalRepo contract.DeviceApprovalRepository,
+	sessionRepo contract.SessionRepository,
+) *auth.ReviewDeviceUseCase {
+	return auth.NewReviewDeviceUseCase(knownDeviceRepo, deviceApprovalRepo, sessionRepo)
+}
+
+// ProvideRefreshTokensUseCase provides the refresh tokens use case
+func ProvideRefreshTokensUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	tokenIssuer contract.TokenIssuer,
+) *auth.RefreshTokensUseCase {
+	return auth.NewRefreshTokensUseCase(userRepo, sessionRepo, settingsRepo, tokenIssuer)
+}
+
+// ProvideForgotPasswordUseCase provides the password reset request use case
+func ProvideForgotPasswordUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository, mailer2 contract.Mailer,
+
+) *auth.ForgotPasswordUseCase {
+	return auth.NewForgotPasswordUseCase(userRepo, passwordResetRepo, mailer2, cfg.Reset.LinkBaseURL, cfg.Reset.TTL)
+}
+
+// ProvideResetPasswordUseCase provides the password reset use case
+func ProvideResetPasswordUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	sessionRepo contract.SessionRepository, hasher2 contract.PasswordHasher,
+
+	passwordPolicy contract.PasswordPolicy,
+) *auth.ResetPasswordUseCase {
+	return auth.NewResetPasswordUseCase(userRepo, passwordResetRepo, sessionRepo, hasher2, passwordPolicy)
+}
+
+// ProvideListSessionsUseCase provides the list sessions use case
+func ProvideListSessionsUseCase(sessionRepo contract.SessionRepository) *session.ListSessionsUseCase {
+	return session.NewListSessionsUseCase(sessionRepo)
+}
+
+// ProvideRevokeSessionUseCase provides the revoke session use case
+func ProvideRevokeSessionUseCase(sessionRepo contract.SessionRepository) *session.RevokeSessionUseCase {
+	return session.NewRevokeSessionUseCase(sessionRepo)
+}
+
+// ProvideListLoginHistoryUseCase provides the login history use case
+func ProvideListLoginHistoryUseCase(loginAttemptRepo contract.LoginAttemptRepository) *activity.ListLoginHistoryUseCase {
+	return activity.NewListLoginHistoryUseCase(loginAttemptRepo)
+}
+
+// ProvideGetTermsStatusUseCase provides the terms acceptance status use case
+func ProvideGetTermsStatusUseCase(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) *terms.GetTermsStatusUseCase {
+	return terms.NewGetTermsStatusUseCase(termsRepo, currentTerms(cfg))
+}
+
+// ProvideAcceptTermsUseCase provides the terms acceptance use case
+func ProvideAcceptTermsUseCase(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) *terms.AcceptTermsUseCase {
+	return terms.NewAcceptTermsUseCase(termsRepo, currentTerms(cfg))
+}
+
+// ProvideRequestEmailChangeUseCase provides the email change request use case
+func ProvideRequestEmailChangeUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository, hasher2 contract.PasswordHasher, mailer2 contract.Mailer,
+
+) *account.RequestEmailChangeUseCase {
+	return account.NewRequestEmailChangeUseCase(userRepo, emailChangeRepo, hasher2, mailer2, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.TTL)
+}
+
+// ProvideResendEmailChangeUseCase provides the use case resending email change confirmations
+func ProvideResendEmailChangeUseCase(
+	cfg *config.Config,
+	emailChangeRepo contract.EmailChangeRepository, mailer2 contract.Mailer,
+
+) *account.ResendEmailChangeUseCase {
+	return account.NewResendEmailChangeUseCase(emailChangeRepo, mailer2, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.TTL)
+}
+
+// ProvideConfirmEmailChangeUseCase provides the email change confirmation use case
+func ProvideConfirmEmailChangeUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	notifier contract.Notifier,
+	analyticsTracker contract.AnalyticsTracker,
+) *account.ConfirmEmailChangeUseCase {
+	return account.NewConfirmEmailChangeUseCase(userRepo, emailChangeRepo, notifier, analyticsTracker, cfg.EmailChange.LinkBaseURL, cfg.EmailChange.RevertWindow)
+}
+
+// ProvideRevertEmailChangeUseCase provides the email change rollback use case
+func ProvideRevertEmailChangeUseCase(
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	sessionRepo contract.SessionRepository,
+) *account.RevertEmailChangeUseCase {
+	return account.NewRevertEmailChangeUseCase(userRepo, emailChangeRepo, sessionRepo)
+}
+
+// ProvideDeleteAccountUseCase provides the account deletion use case
+func ProvideDeleteAccountUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	restoreRepo contract.AccountRestoreRepository, hasher2 contract.PasswordHasher, mailer2 contract.Mailer,
+
+) *account.DeleteAccountUseCase {
+	deletion := accountDeletionConfig.From(cfg)
+	return account.NewDeleteAccountUseCase(account.DeleteAccountUseCaseArgs{
+		UserRepo:       userRepo,
+		SessionRepo:    sessionRepo,
+		RestoreRepo:    restoreRepo,
+		Hasher:         hasher2,
+		Mailer:         mailer2,
+		LinkBaseURL:    deletion.LinkBaseURL,
+		RecoveryWindow: deletion.RecoveryWindow,
+	})
+}
+
+// ProvideRestoreAccountUseCase provides the deleted account restore use case
+func ProvideRestoreAccountUseCase(
+	userRepo contract.UserRepository,
+	restoreRepo contract.AccountRestoreRepository,
+) *account.RestoreAccountUseCase {
+	return account.NewRestoreAccountUseCase(userRepo, restoreRepo)
+}
+
+// ProvidePurgeDeletedAccountsUseCase provides the purge of deleted accounts past their recovery window
+func ProvidePurgeDeletedAccountsUseCase(
+	userRepo contract.UserRepository,
+	restoreRepo contract.AccountRestoreRepository,
+) *account.PurgeDeletedAccountsUseCase {
+	return account.NewPurgeDeletedAccountsUseCase(userRepo, restoreRepo)
+}
+
+func currentTerms(cfg *config.Config) entity.TermsVersions {
+	return entity.TermsVersions{Terms: cfg.Terms.Version, Privacy: cfg.Terms.PrivacyVersion}
+}
+
+// ProvideRevokeAllSessionsUseCase provides the sign-out-everywhere use case
+func ProvideRevokeAllSessionsUseCase(sessionRepo contract.SessionRepository) *session.RevokeAllSessionsUseCase {
+	return session.NewRevokeAllSessionsUseCase(sessionRepo)
+}
+
+// ProvideCreateTokenUseCase provides the personal access token minting use case
+func ProvideCreateTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *token2.CreateTokenUseCase {
+	return token2.NewCreateTokenUseCase(tokenRepo)
+}
+
+// ProvideListTokensUseCase provides the personal access token listing use case
+func ProvideListTokensUseCase(tokenRepo contract.PersonalAccessTokenRepository) *token2.ListTokensUseCase {
+	return token2.NewListTokensUseCase(tokenRepo)
+}
+
+// ProvideRevokeTokenUseCase provides the personal access token revocation use case
+func ProvideRevokeTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *token2.RevokeTokenUseCase {
+	return token2.NewRevokeTokenUseCase(tokenRepo)
+}
+
+// ProvideNotifier provides the notification fan-out, which applies the
+// users' notification preferences
+func ProvideNotifier(
+	prefsRepo contract.NotificationPreferencesRepository,
+	pendingRepo contract.PendingNotificationRepository,
+	inboxRepo contract.InAppNotificationRepository, mailer2 contract.Mailer,
+
+	smsSender contract.SMSSender,
+	badges contract.BadgePublisher,
+) contract.Notifier {
+	return notification.NewNotifier(notification.NotifierArgs{
+		PrefsRepo:   prefsRepo,
+		PendingRepo: pendingRepo,
+		InboxRepo:   inboxRepo,
+		Mailer:      mailer2,
+		SMSSender:   smsSender,
+		Badges:      badges,
+	})
+}
+
+// ProvideGetNotificationPreferencesUseCase provides the notification preferences lookup use case
+func ProvideGetNotificationPreferencesUseCase(prefsRepo contract.NotificationPreferencesRepository) *notification.GetPreferencesUseCase {
+	return notification.NewGetPreferencesUseCase(prefsRepo)
+}
+
+// ProvideUpdateNotificationPreferencesUseCase provides the notification preferences update use case
+func ProvideUpdateNotificationPreferencesUseCase(
+	prefsRepo contract.NotificationPreferencesRepository,
+	userRepo contract.UserRepository,
+) *notification.UpdatePreferencesUseCase {
+	return notification.NewUpdatePreferencesUseCase(prefsRepo, userRepo)
+}
+
+// ProvideListNotificationsUseCase provides the in-app inbox listing use case
+func ProvideListNotificationsUseCase(inboxRepo contract.InAppNotificationRepository) *notification.ListNotificationsUseCase {
+	return notification.NewListNotificationsUseCase(inboxRepo)
+}
+
+// ProvideCountUnreadUseCase provides the unread notification count use case
+func ProvideCountUnreadUseCase(inboxRepo contract.InAppNotificationRepository) *notification.CountUnreadUseCase {
+	return notification.NewCountUnreadUseCase(inboxRepo)
+}
+
+// ProvideMarkReadUseCase provides the use case marking a notification read
+func ProvideMarkReadUseCase(
+	inboxRepo contract.InAppNotificationRepository,
+	badges contract.BadgePublisher,
+) *notification.MarkReadUseCase {
+	return notification.NewMarkReadUseCase(inboxRepo, badges)
+}
+
+// ProvideMarkAllReadUseCase provides the use case marking the whole inbox read
+func ProvideMarkAllReadUseCase(
+	inboxRepo contract.InAppNotificationRepository,
+	badges contract.BadgePublisher,
+) *notification.MarkAllReadUseCase {
+	return notification.NewMarkAllReadUseCase(inboxRepo, badges)
+}
+
+// ProvideDeliverPendingNotificationsUseCase provides the held notification delivery use case
+func ProvideDeliverPendingNotificationsUseCase(
+	pendingRepo contract.PendingNotificationRepository, mailer2 contract.Mailer,
+
+) *notification.DeliverPendingNotificationsUseCase {
+	return notification.NewDeliverPendingNotificationsUseCase(pendingRepo, mailer2)
+}
+
+// ProvideUpdateUserUseCase provides the update user use case
+func ProvideUpdateUserUseCase(
+	userRepo contract.UserRepository, hasher2 contract.PasswordHasher,
+
+	passwordPolicy contract.PasswordPolicy,
+) *user.UpdateUserUseCase {
+	return user.NewUpdateUserUseCase(userRepo, hasher2, passwordPolicy)
+}
+
+// ProvideBulkUsersUseCase provides the admin bulk users use case
+func ProvideBulkUsersUseCase(
+	signUpUseCase *auth.SignUpUseCase,
+	updateUserUseCase *user.UpdateUserUseCase,
+) *admin.BulkUsersUseCase {
+	return admin.NewBulkUsersUseCase(signUpUseCase, updateUserUseCase)
+}
+
+// ProvideAuthHandler provides the auth handler
+func ProvideAuthHandler(
+	signUpUseCase *auth.SignUpUseCase,
+	issueSignUpFormUseCase *auth.IssueSignUpFormUseCase,
+	signInUseCase *auth.SignInUseCase,
+	refreshTokensUseCase *auth.RefreshTokensUseCase,
+	reviewDeviceUseCase *auth.ReviewDeviceUseCase,
+	forgotPasswordUseCase *auth.ForgotPasswordUseCase,
+	resetPasswordUseCase *auth.ResetPasswordUseCase,
+	confirmEmailChangeUseCase *account.ConfirmEmailChangeUseCase,
+	revertEmailChangeUseCase *account.RevertEmailChangeUseCase,
+	restoreAccountUseCase *account.RestoreAccountUseCase,
+	requestSignInCodeUseCase *auth.RequestSignInCodeUseCase,
+	signInWithCodeUseCase *auth.SignInWithCodeUseCase,
+	startGuestSessionUseCase *auth.StartGuestSessionUseCase,
+	rotateExpiredPasswordUseCase *auth.RotateExpiredPasswordUseCase,
+) *auth2.AuthHandler {
+	return auth2.NewAuthHandler(auth2.NewAuthHandlerArgs{
+		SignUpUseCase:                signUpUseCase,
+		IssueSignUpFormUseCase:       issueSignUpFormUseCase,
+		SignInUseCase:                signInUseCase,
+		RefreshTokensUseCase:         refreshTokensUseCase,
+		ReviewDeviceUseCase:          reviewDeviceUseCase,
+		ForgotPasswordUseCase:        forgotPasswordUseCase,
+		ResetPasswordUseCase:         resetPasswordUseCase,
+		ConfirmEmailChangeUseCase:    confirmEmailChangeUseCase,
+		RevertEmailChangeUseCase:     revertEmailChangeUseCase,
+		RestoreAccountUseCase:        restoreAccountUseCase,
+		RequestSignInCodeUseCase:     requestSignInCodeUseCase,
+		SignInWithCodeUseCase:        signInWithCodeUseCase,
+		StartGuestSessionUseCase:     startGuestSessionUseCase,
+		RotateExpiredPasswordUseCase: rotateExpiredPasswordUseCase,
+	})
+}
+
+// ProvideMeHandler provides the handler for the calling user's resources
+func ProvideMeHandler(
+	listSessionsUseCase *session.ListSessionsUseCase,
+	revokeSessionUseCase *session.RevokeSessionUseCase,
+	revokeAllSessionsUseCase *session.RevokeAllSessionsUseCase,
+	listLoginHistoryUseCase *activity.ListLoginHistoryUseCase,
+	getTermsStatusUseCase *terms.GetTermsStatusUseCase,
+	acceptTermsUseCase *terms.AcceptTermsUseCase,
+	requestEmailChangeUseCase *account.RequestEmailChangeUseCase,
+	deleteAccountUseCase *account.DeleteAccountUseCase,
+	requestPhoneVerificationUseCase *phone.RequestPhoneVerificationUseCase,
+	verifyPhoneUseCase *phone.VerifyPhoneUseCase,
+	upgradeGuestUseCase *account.UpgradeGuestUseCase,
+	listIdentitiesUseCase *account.ListIdentitiesUseCase,
+	linkIdentityUseCase *account.LinkIdentityUseCase,
+	unlinkIdentityUseCase *account.UnlinkIdentityUseCase,
+	getCurrentUsageUseCase *usage.GetCurrentUsageUseCase,
+	createTokenUseCase *token2.CreateTokenUseCase,
+	listTokensUseCase *token2.ListTokensUseCase,
+	revokeTokenUseCase *token2.RevokeTokenUseCase,
+	getNotificationPreferencesUseCase *notification.GetPreferencesUseCase,
+	updateNotificationPreferencesUseCase *notification.UpdatePreferencesUseCase,
+	listNotificationsUseCase *notification.ListNotificationsUseCase,
+	countUnreadUseCase *notification.CountUnreadUseCase,
+	markReadUseCase *notification.MarkReadUseCase,
+	markAllReadUseCase *notification.MarkAllReadUseCase,
+	badges *realtime.BadgeHub,
+	presence *realtime.PresenceTracker,
+	drainer *drain.Drainer,
+) *me.MeHandler {
+	return me.NewMeHandler(me.NewMeHandlerArgs{
+		ListSessionsUseCase:                  listSessionsUseCase,
+		RevokeSessionUseCase:                 revokeSessionUseCase,
+		RevokeAllSessionsUseCase:             revokeAllSessionsUseCase,
+		ListLoginHistoryUseCase:              listLoginHistoryUseCase,
+		GetTermsStatusUseCase:                getTermsStatusUseCase,
+		AcceptTermsUseCase:                   acceptTermsUseCase,
+		RequestEmailChangeUseCase:            requestEmailChangeUseCase,
+		DeleteAccountUseCase:                 deleteAccountUseCase,
+		RequestPhoneVerificationUseCase:      requestPhoneVerificationUseCase,
+		VerifyPhoneUseCase:                   verifyPhoneUseCase,
+		UpgradeGuestUseCase:                  upgradeGuestUseCase,
+		ListIdentitiesUseCase:                listIdentitiesUseCase,
+		LinkIdentityUseCase:                  linkIdentityUseCase,
+		UnlinkIdentityUseCase:                unlinkIdentityUseCase,
+		GetCurrentUsageUseCase:               getCurrentUsageUseCase,
+		CreateTokenUseCase:                   createTokenUseCase,
+		ListTokensUseCase:                    listTokensUseCase,
+		RevokeTokenUseCase:                   revokeTokenUseCase,
+		GetNotificationPreferencesUseCase:    getNotificationPreferencesUseCase,
+		UpdateNotificationPreferencesUseCase: updateNotificationPreferencesUseCase,
+		ListNotificationsUseCase:             listNotificationsUseCase,
+		CountUnreadUseCase:                   countUnreadUseCase,
+		MarkReadUseCase:                      markReadUseCase,
+		MarkAllReadUseCase:                   markAllReadUseCase,
+		Badges:                               badges,
+		Presence:                             presence,
+		StreamsClosing:                       drainer.StreamsClosing(),
+	})
+}
+
+// ProvideAdminHandler provides the admin handler
+func ProvideAdminHandler(
+	bulkUsersUseCase *admin.BulkUsersUseCase,
+	forcePasswordRotationUseCase *admin.ForcePasswordRotationUseCase,
+	disposableDomainsUseCase *admin.DisposableDomainsUseCase,
+	mintInvitationUseCase *invitation.MintInvitationUseCase,
+	listInvitationsUseCase *invitation.ListInvitationsUseCase,
+	revokeInvitationUseCase *invitation.RevokeInvitationUseCase,
+	registerOAuthClientUseCase *oauth.RegisterClientUseCase,
+	listOAuthClientsUseCase *oauth.ListClientsUseCase,
+	revokeOAuthClientUseCase *oauth.RevokeClientUseCase,
+	registerSAMLConnectionUseCase *saml2.RegisterConnectionUseCase,
+	listSAMLConnectionsUseCase *saml2.ListConnectionsUseCase,
+	deleteSAMLConnectionUseCase *saml2.DeleteConnectionUseCase,
+	setTenantQuotaUseCase *admin.SetTenantQuotaUseCase,
+	getOrganizationSettingsUseCase *admin.GetOrganizationSettingsUseCase,
+	updateOrganizationSettingsUseCase *admin.UpdateOrganizationSettingsUseCase,
+	getMaintenanceUseCase *admin.GetMaintenanceUseCase,
+	setMaintenanceUseCase *admin.SetMaintenanceUseCase,
+	getAuthProtectionUseCase *admin.GetAuthProtectionUseCase,
+	setAuthProtectionUseCase *admin.SetAuthProtectionUseCase,
+	listDeprecationUsageUseCase *admin.ListDeprecationUsageUseCase,
+	impersonateUserUseCase *admin.ImpersonateUserUseCase,
+	listAuditLogUseCase *admin.ListAuditLogUseCase,
+	listUsersUseCase *admin.ListUsersUseCase,
+	exportUsersUseCase *admin.ExportUsersUseCase,
+	exportAuditLogUseCase *admin.ExportAuditLogUseCase,
+	setUserStatusUseCase *admin.SetUserStatusUseCase,
+	revokeUserAccessUseCase *admin.RevokeUserAccessUseCase,
+	setUserPlanUseCase *admin.SetUserPlanUseCase,
+	listUserTokensUseCase *admin.ListUserTokensUseCase,
+	createServiceAccountUseCase *serviceaccount.CreateAccountUseCase,
+	listServiceAccountsUseCase *serviceaccount.ListAccountsUseCase,
+	deleteServiceAccountUseCase *serviceaccount.DeleteAccountUseCase,
+	createServiceAccountKeyUseCase *serviceaccount.CreateKeyUseCase,
+	listServiceAccountKeysUseCase *serviceaccount.ListKeysUseCase,
+	revokeServiceAccountKeyUseCase *serviceaccount.RevokeKeyUseCase,
+	exportUsageUseCase *usage.ExportUsageUseCase,
+	listEmailsUseCase *mail.ListEmailsUseCase,
+	getEmailUseCase *mail.GetEmailUseCase,
+	resendEmailUseCase *mail.ResendEmailUseCase,
+	listEmailSuppressionsUseCase *mail.ListEmailSuppressionsUseCase,
+	addEmailSuppressionUseCase *mail.AddEmailSuppressionUseCase,
+	removeEmailSuppressionUseCase *mail.RemoveEmailSuppressionUseCase,
+	listDeadJobsUseCase *job.ListDeadJobsUseCase,
+	getJobUseCase *job.GetJobUseCase,
+	requeueJobUseCase *job.RequeueJobUseCase,
+	discardJobUseCase *job.DiscardJobUseCase,
+	getQueueStatsUseCase *job.GetQueueStatsUseCase,
+	listAdminTasksUseCase *job.ListAdminTasksUseCase,
+	triggerAdminTaskUseCase *job.TriggerAdminTaskUseCase,
+	getStatsUseCase *stats.GetStatsUseCase,
+	cfg *config.Config,
+	lifecycleRegistry *lifecycle.Registry,
+) *admin2.AdminHandler {
+	return admin2.NewAdminHandler(admin2.NewAdminHandlerArgs{
+		BulkUsersUseCase:                  bulkUsersUseCase,
+		ForcePasswordRotationUseCase:      forcePasswordRotationUseCase,
+		DisposableDomainsUseCase:          disposableDomainsUseCase,
+		MintInvitationUseCase:             mintInvitationUseCase,
+		ListInvitationsUseCase:            listInvitationsUseCase,
+		RevokeInvitationUseCase:           revokeInvitationUseCase,
+		RegisterOAuthClientUseCase:        registerOAuthClientUseCase,
+		ListOAuthClientsUseCase:           listOAuthClientsUseCase,
+		RevokeOAuthClientUseCase:          revokeOAuthClientUseCase,
+		RegisterSAMLConnectionUseCase:     registerSAMLConnectionUseCase,
+		ListSAMLConnectionsUseCase:        listSAMLConnectionsUseCase,
+		DeleteSAMLConnectionUseCase:       deleteSAMLConnectionUseCase,
+		SetTenantQuotaUseCase:             setTenantQuotaUseCase,
+		GetOrganizationSettingsUseCase:    getOrganizationSettingsUseCase,
+		UpdateOrganizationSettingsUseCase: updateOrganizationSettingsUseCase,
+		GetMaintenanceUseCase:             getMaintenanceUseCase,
+		SetMaintenanceUseCase:             setMaintenanceUseCase,
+		GetAuthProtectionUseCase:          getAuthProtectionUseCase,
+		SetAuthProtectionUseCase:          setAuthProtectionUseCase,
+		ListDeprecationUsageUseCase:       listDeprecationUsageUseCase,
+		ExportUsageUseCase:                exportUsageUseCase,
+		ImpersonateUserUseCase:            impersonateUserUseCase,
+		ListAuditLogUseCase:               listAuditLogUseCase,
+		ListUsersUseCase:                  listUsersUseCase,
+		ExportUsersUseCase:                exportUsersUseCase,
+		ExportAuditLogUseCase:             exportAuditLogUseCase,
+		SetUserStatusUseCase:              setUserStatusUseCase,
+		RevokeUserAccessUseCase:           revokeUserAccessUseCase,
+		SetUserPlanUseCase:                setUserPlanUseCase,
+		ListUserTokensUseCase:             listUserTokensUseCase,
+		CreateServiceAccountUseCase:       createServiceAccountUseCase,
+		ListServiceAccountsUseCase:        listServiceAccountsUseCase,
+		DeleteServiceAccountUseCase:       deleteServiceAccountUseCase,
+		CreateServiceAccountKeyUseCase:    createServiceAccountKeyUseCase,
+		ListServiceAccountKeysUseCase:     listServiceAccountKeysUseCase,
+		RevokeServiceAccountKeyUseCase:    revokeServiceAccountKeyUseCase,
+		ListEmailsUseCase:                 listEmailsUseCase,
+		GetEmailUseCase:                   getEmailUseCase,
+		ResendEmailUseCase:                resendEmailUseCase,
+		ListEmailSuppressionsUseCase:      listEmailSuppressionsUseCase,
+		AddEmailSuppressionUseCase:        addEmailSuppressionUseCase,
+		RemoveEmailSuppressionUseCase:     removeEmailSuppressionUseCase,
+		ListDeadJobsUseCase:               listDeadJobsUseCase,
+		GetJobUseCase:                     getJobUseCase,
+		RequeueJobUseCase:                 requeueJobUseCase,
+		DiscardJobUseCase:                 discardJobUseCase,
+		GetQueueStatsUseCase:              getQueueStatsUseCase,
+		ListAdminTasksUseCase:             listAdminTasksUseCase,
+		TriggerAdminTaskUseCase:           triggerAdminTaskUseCase,
+		GetStatsUseCase:                   getStatsUseCase,
+		Config:                            cfg.Snapshot(),
+		Lifecycle:                         lifecycleRegistry,
+	})
+}
+
+// ProvideDisposableDomainsUseCase provides the disposable domain list admin use case
+func ProvideDisposableDomainsUseCase(disposable *policy.DisposableEmailPolicy) *admin.DisposableDomainsUseCase {
+	return admin.NewDisposableDomainsUseCase(disposable)
+}
+
+// ProvideMintInvitationUseCase provides the invitation minting use case
+func ProvideMintInvitationUseCase(cfg *config.Config, invitationRepo contract.InvitationRepository) *invitation.MintInvitationUseCase {
+	return invitation.NewMintInvitationUseCase(invitationRepo, cfg.SignUp.InviteTTL)
+}
+
+// ProvideListInvitationsUseCase provides the invitation listing use case
+func ProvideListInvitationsUseCase(invitationRepo contract.InvitationRepository) *invitation.ListInvitationsUseCase {
+	return invitation.NewListInvitationsUseCase(invitationRepo)
+}
+
+// ProvideRevokeInvitationUseCase provides the invitation revocation use case
+func ProvideRevokeInvitationUseCase(invitationRepo contract.InvitationRepository) *invitation.RevokeInvitationUseCase {
+	return invitation.NewRevokeInvitationUseCase(invitationRepo)
+}
+
+// ProvideRegisterOAuthClientUseCase provides the OAuth client registration use case
+func ProvideRegisterOAuthClientUseCase(clientRepo contract.OAuthClientRepository, limiter *quota.Limiter) *oauth.RegisterClientUseCase {
+	return oauth.NewRegisterClientUseCase(clientRepo, limiter.PlanNames())
+}
+
+// ProvideListOAuthClientsUseCase provides the OAuth client listing use case
+func ProvideListOAuthClientsUseCase(clientRepo contract.OAuthClientRepository) *oauth.ListClientsUseCase {
+	return oauth.NewListClientsUseCase(clientRepo)
+}
+
+// ProvideRevokeOAuthClientUseCase provides the OAuth client revocation use case
+func ProvideRevokeOAuthClientUseCase(clientRepo contract.OAuthClientRepository) *oauth.RevokeClientUseCase {
+	return oauth.NewRevokeClientUseCase(clientRepo)
+}
+
+// ProvideIssueClientTokenUseCase provides the client_credentials grant use case
+func ProvideIssueClientTokenUseCase(
+	clientRepo contract.OAuthClientRepository,
+	tokenIssuer contract.ClientTokenIssuer,
+) *oauth.IssueClientTokenUseCase {
+	return oauth.NewIssueClientTokenUseCase(clientRepo, tokenIssuer)
+}
+
+// ProvideIntrospectTokenUseCase provides the token introspection use case
+func ProvideIntrospectTokenUseCase(
+	clientRepo contract.OAuthClientRepository,
+	verifier contract.AccessTokenVerifier,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	sessionRepo contract.SessionRepository,
+	userRepo contract.UserRepository,
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+) *oauth.IntrospectTokenUseCase {
+	return oauth.NewIntrospectTokenUseCase(oauth.IntrospectTokenUseCaseArgs{
+		ClientRepo:  clientRepo,
+		Verifier:    verifier,
+		TokenRepo:   tokenRepo,
+		SessionRepo: sessionRepo,
+		UserRepo:    userRepo,
+		AccountRepo: accountRepo,
+		KeyRepo:     keyRepo,
+	})
+}
+
+// ProvideAuthorizeUseCase provides the OIDC authorization use case, or nil
+// when provider mode is disabled
+func ProvideAuthorizeUseCase(
+	cfg *config.Config,
+	clientRepo contract.OAuthClientRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	sessionRepo contract.SessionRepository,
+) *oauth.AuthorizeUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauth.NewAuthorizeUseCase(clientRepo, codeRepo, sessionRepo, cfg.OIDC.CodeTTL)
+}
+
+// ProvideExchangeCodeUseCase provides the authorization_code grant use case,
+// or nil when provider mode is disabled
+func ProvideExchangeCodeUseCase(
+	cfg *config.Config,
+	clientRepo contract.OAuthClientRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.OIDCTokenIssuer,
+) *oauth.ExchangeCodeUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauth.NewExchangeCodeUseCase(oauth.ExchangeCodeUseCaseArgs{
+		ClientRepo:  clientRepo,
+		CodeRepo:    codeRepo,
+		UserRepo:    userRepo,
+		SessionRepo: sessionRepo,
+		TokenIssuer: tokenIssuer,
+	})
+}
+
+// ProvideUserInfoUseCase provides the OIDC userinfo use case, or nil when
+// provider mode is disabled
+func ProvideUserInfoUseCase(cfg *config.Config, userRepo contract.UserRepository) *oauth.UserInfoUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauth.NewUserInfoUseCase(userRepo)
+}
+
+// ProvideDiscoveryUseCase provides the OIDC discovery use case, or nil when
+// provider mode is disabled
+func ProvideDiscoveryUseCase(cfg *config.Config, tokenIssuer contract.OIDCTokenIssuer) *oauth.DiscoveryUseCase {
+	if !cfg.OIDC.Enabled {
+		return nil
+	}
+	return oauth.NewDiscoveryUseCase(tokenIssuer)
+}
+
+// ProvideRegisterSAMLConnectionUseCase provides the SAML connection registration use case
+func ProvideRegisterSAMLConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *saml2.RegisterConnectionUseCase {
+	return saml2.NewRegisterConnectionUseCase(connectionRepo)
+}
+
+// ProvideListSAMLConnectionsUseCase provides the SAML connection listing use case
+func ProvideListSAMLConnectionsUseCase(connectionRepo contract.SAMLConnectionRepository) *saml2.ListConnectionsUseCase {
+	return saml2.NewListConnectionsUseCase(connectionRepo)
+}
+
+// ProvideDeleteSAMLConnectionUseCase provides the SAML connection removal use case
+func ProvideDeleteSAMLConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *saml2.DeleteConnectionUseCase {
+	return saml2.NewDeleteConnectionUseCase(connectionRepo)
+}
+
+// ProvideSAMLMetadataUseCase provides the SAML service provider metadata use case
+func ProvideSAMLMetadataUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	serviceProvider contract.SAMLServiceProvider,
+) *saml2.MetadataUseCase {
+	return saml2.NewMetadataUseCase(connectionRepo, serviceProvider)
+}
+
+// ProvideStartSAMLLoginUseCase provides the SAML sign-in redirect use case
+func ProvideStartSAMLLoginUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	serviceProvider contract.SAMLServiceProvider,
+	loginStateRepo contract.LoginStateRepository,
+	cfg *config.Config,
+) *saml2.StartLoginUseCase {
+	return saml2.NewStartLoginUseCase(connectionRepo, settingsRepo, serviceProvider, loginStateRepo, cfg.SAML.LoginStateTTL)
+}
+
+// ProvideSAMLHandler provides the SAML endpoint handler
+func ProvideSAMLHandler(
+	cfg *config.Config,
+	metadataUseCase *saml2.MetadataUseCase,
+	startLoginUseCase *saml2.StartLoginUseCase,
+	signInWithSAMLUseCase *auth.SignInWithSAMLUseCase,
+	cookies *securecookie.Codec,
+) *saml3.SAMLHandler {
+	return saml3.NewSAMLHandler(saml3.NewSAMLHandlerArgs{
+		MetadataUseCase:       metadataUseCase,
+		StartLoginUseCase:     startLoginUseCase,
+		SignInWithSAMLUseCase: signInWithSAMLUseCase,
+		Cookies:               cookies,
+		StateTTL:              cfg.SAML.LoginStateTTL,
+		SuccessURL:            cfg.SAML.SuccessURL,
+	})
+}
+
+// ProvideCreateCheckoutSessionUseCase provides the checkout session use case,
+// or nil when billing is disabled
+func ProvideCreateCheckoutSessionUseCase(
+	cfg *config.Config,
+	userRepo contract.UserRepository,
+	subscriptionRepo contract.SubscriptionRepository,
+	entitlements contract.EntitlementChecker,
+	provider contract.BillingProvider,
+) *billing2.CreateCheckoutSessionUseCase {
+	if provider == nil {
+		return nil
+	}
+	return billing2.NewCreateCheckoutSessionUseCase(billing2.CreateCheckoutSessionUseCaseArgs{
+		UserRepo:         userRepo,
+		SubscriptionRepo: subscriptionRepo,
+		Entitlements:     entitlements,
+		Provider:         provider,
+		Prices:           cfg.Billing.Prices,
+		SuccessURL:       cfg.Billing.SuccessURL,
+		CancelURL:        cfg.Billing.CancelURL,
+	})
+}
+
+// ProvideHandleWebhookUseCase provides the billing webhook use case, or nil
+// when billing is disabled
+func ProvideHandleWebhookUseCase(
+	cfg *config.Config,
+	subscriptionRepo contract.SubscriptionRepository,
+	provider contract.BillingProvider,
+	deduper *dedupe.Deduper,
+) *billing2.HandleWebhookUseCase {
+	if provider == nil {
+		return nil
+	}
+	return billing2.NewHandleWebhookUseCase(subscriptionRepo, provider, deduper, cfg.Billing.Prices)
+}
+
+// ProvideGetSubscriptionUseCase provides the get subscription use case
+func ProvideGetSubscriptionUseCase(subscriptionRepo contract.SubscriptionRepository) *billing2.GetSubscriptionUseCase {
+	return billing2.NewGetSubscriptionUseCase(subscriptionRepo)
+}
+
+// ProvideBillingHandler provides the billing endpoint handler
+func ProvideBillingHandler(
+	createCheckoutSessionUseCase *billing2.CreateCheckoutSessionUseCase,
+	handleWebhookUseCase *billing2.HandleWebhookUseCase,
+	getSubscriptionUseCase *billing2.GetSubscriptionUseCase,
+) *billing3.BillingHandler {
+	return billing3.NewBillingHandler(billing3.NewBillingHandlerArgs{
+		CreateCheckoutSessionUseCase: createCheckoutSessionUseCase,
+		HandleWebhookUseCase:         handleWebhookUseCase,
+		GetSubscriptionUseCase:       getSubscriptionUseCase,
+	})
+}
+
+// ProvideOAuthHandler provides the OAuth and OpenID Connect endpoint handler
+func ProvideOAuthHandler(
+	cfg *config.Config,
+	issueClientTokenUseCase *oauth.IssueClientTokenUseCase,
+	introspectTokenUseCase *oauth.IntrospectTokenUseCase,
+	authorizeUseCase *oauth.AuthorizeUseCase,
+	exchangeCodeUseCase *oauth.ExchangeCodeUseCase,
+	userInfoUseCase *oauth.UserInfoUseCase,
+	discoveryUseCase *oauth.DiscoveryUseCase,
+) *oauth2.OAuthHandler {
+	return oauth2.NewOAuthHandler(oauth2.NewOAuthHandlerArgs{
+		IssueClientTokenUseCase: issueClientTokenUseCase,
+		IntrospectTokenUseCase:  introspectTokenUseCase,
+		AuthorizeUseCase:        authorizeUseCase,
+		ExchangeCodeUseCase:     exchangeCodeUseCase,
+		UserInfoUseCase:         userInfoUseCase,
+		DiscoveryUseCase:        discoveryUseCase,
+		LoginURL:                cfg.OIDC.LoginURL,
+	})
+}
+
+// ProvideCheckUsernameUseCase provides the username availability use case
+func ProvideCheckUsernameUseCase(userRepo contract.UserRepository) *user.CheckUsernameUseCase {
+	return user.NewCheckUsernameUseCase(userRepo)
+}
+
+// ProvideUsernameHandler provides the username handler
+func ProvideUsernameHandler(checkUsernameUseCase *user.CheckUsernameUseCase) *username.UsernameHandler {
+	return username.NewUsernameHandler(username.NewUsernameHandlerArgs{
+		CheckUsernameUseCase: checkUsernameUseCase,
+	})
+}
+
+// ProvideGetPresenceUseCase provides the user presence lookup use case
+func ProvideGetPresenceUseCase(userRepo contract.UserRepository, presenceStore contract.PresenceStore) *presence.GetPresenceUseCase {
+	return presence.NewGetPresenceUseCase(userRepo, presenceStore)
+}
+
+// ProvideUsersHandler provides the handler of the endpoints about other users
+func ProvideUsersHandler(getPresenceUseCase *presence.GetPresenceUseCase) *users.UsersHandler {
+	return users.NewUsersHandler(users.NewUsersHandlerArgs{
+		GetPresenceUseCase: getPresenceUseCase,
+	})
+}
+
+// ProvideHealthHandler provides the health handler
+func ProvideHealthHandler(
+	registry *resilience.Registry,
+	modes FailModes,
+	relay *infrastructure.UserCacheRelay,
+	elector *leader.Elector,
+	lifecycleRegistry *lifecycle.Registry,
+	preStop *drain.PreStop,
+) *health.HealthHandler {
+	return health.NewHealthHandler(health.NewHealthHandlerArgs{
+		Resilience: registry,
+		Elector:    elector,
+		Lifecycle:  lifecycleRegistry,
+		PreStop:    preStop,
+		Degraded: func() []health.Degradation {
+			return degradations(registry, modes, relay)
+		},
+	})
+}
+
+// ProvideRouter provides the chi router with all routes registered
+func ProvideRouter(
+	cfg *config.Config,
+	authHandler *auth2.AuthHandler,
+	adminHandler *admin2.AdminHandler,
+	healthHandler *health.HealthHandler,
+	meHandler *me.MeHandler,
+	oauthHandler *oauth2.OAuthHandler,
+	samlHandler *saml3.SAMLHandler,
+	billingHandler *billing3.BillingHandler,
+	mailHandler *mail2.MailHandler,
+	debugHandler *debug.DebugHandler,
+	dashboardHandler *dashboard.DashboardHandler,
+	usernameHandler *username.UsernameHandler,
+	drainer *drain.Drainer,
+	loadShedder *middleware.LoadShedder,
+	credentialGate *middleware.CredentialGate,
+	authProtection *middleware.AuthProtection,
+	admission *middleware.AdmissionController,
+	maintenanceRepo contract.MaintenanceRepository,
+	deprecationUsageRepo contract.DeprecationUsageRepository,
+	jwtClient *jwt.Client,
+	externalVerifier *jwks.Verifier,
+	externalUsers *middleware.ExternalUsers,
+	serviceAccounts *middleware.ServiceAccounts,
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	termsRepo contract.TermsAcceptanceRepository,
+	clientRepo contract.OAuthClientRepository,
+	auditLogRepo contract.AuditLogRepository,
+	samlConnRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	geoLocator contract.GeoLocator,
+	limiter *quota.Limiter,
+	meter contract.UsageMeter,
+	planGate contract.PlanGate,
+	captchaVerifier contract.CaptchaVerifier,
+	cookies *securecookie.Codec,
+	deduper *dedupe.Deduper,
+	routes *router.RouteTable,
+	modes FailModes,
+) (*router.Routers, error) {
+	trustedProxies, err := middleware.ParsePrefixes(cfg.App.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("parse APP_TRUSTED_PROXIES: %w", err)
+	}
+	captcha2 := provideCaptcha(captchaVerifier)
+	signUpCaptcha := captcha2
+	if cfg.SignUpBot.Enabled {
+
+		signUpCaptcha = provideCaptcha(nil)
+	}
+	shadow, err := provideShadow(cfg.Shadow)
+	if err != nil {
+		return nil, err
+	}
+	faultInjector, err := provideFaultInjector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	contract2, err := provideContractValidator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	countryPolicy, err := provideCountryPolicy(cfg, geoLocator)
+	if err != nil {
+		return nil, err
+	}
+	rollouts, err := middleware.ParseRolloutRules(cfg.Rollout.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("parse ROLLOUT_RULES: %w", err)
+	}
+	encodings, err := response.ParseEncodings(cfg.App.ResponseEncodings)
+	if err != nil {
+		return nil, fmt.Errorf("parse APP_RESPONSE_ENCODINGS: %w", err)
+	}
+	authenticate, err := provideAuthenticate(cfg, jwtClient, userRepo, tokenRepo, externalUsers, serviceAccounts)
+	if err != nil {
+		return nil, err
+	}
+	requestBudget := cfg.App.RequestBudget
+	if requestBudget == 0 && cfg.App.WriteTimeout > 0 {
+		requestBudget = max(cfg.App.WriteTimeout-cfg.App.RequestBudgetReserve, cfg.App.WriteTimeout/2)
+	}
+
+	args := router.NewRouterArgs{
+		AuthHandler:      authHandler,
+		AdminHandler:     adminHandler,
+		HealthHandler:    healthHandler,
+		MeHandler:        meHandler,
+		UsernameHandler:  usernameHandler,
+		OAuthHandler:     oauthHandler,
+		SAMLHandler:      samlHandler,
+		BillingHandler:   billingHandler,
+		MailHandler:      mailHandler,
+		DebugHandler:     debugHandler,
+		DashboardHandler: dashboardHandler,
+		Drainer:          drainer,
+		LoadShedder:      loadShedder,
+		CredentialGate:   credentialGate,
+		AuthProtection:   authProtection,
+		Admission:        admission,
+		ReadOnly: middleware.ReadOnly(middleware.ReadOnlyArgs{
+			Maintenance: maintenanceRepo,
+			RetryAfter:  maintenanceConfig.From(cfg).RetryAfter,
+			AllowPaths:  maintenanceConfig.From(cfg).AllowPaths,
+		}),
+		RequestBudget:         requestBudget,
+		TrustedProxies:        trustedProxies,
+		Authenticate:          authenticate,
+		RequireSession:        middleware.RequireActiveSession(sessionRepo, settingsRepo, modes.Open(FEATURE_SESSIONS)),
+		AuthenticateClient:    middleware.AuthenticateClient(jwtClient, clientRepo, externalVerifier),
+		AuthenticateDelegated: middleware.AuthenticateDelegated(jwtClient),
+		AuditImpersonation:    middleware.AuditImpersonation(auditLogRepo),
+		AuditServiceAccounts:  middleware.AuditServiceAccounts(auditLogRepo),
+		Captcha:               captcha2,
+		SignUpCaptcha:         signUpCaptcha,
+		CountryPolicy:         countryPolicy,
+		Quota:                 provideQuota(cfg, limiter, samlConnRepo, modes),
+		MeterUsage:            middleware.MeterUsage(meter),
+		PlanGuard:             middleware.NewPlanGuard(planGate),
+		RequireTerms:          provideRequireTerms(cfg, termsRepo),
+		Idempotency:           middleware.Idempotency(deduper),
+		Routes:                routes,
+		DeprecationUsage:      deprecationUsageRepo,
+		BatchMaxRequests:      cfg.App.BatchMaxRequests,
+		BatchConcurrency:      cfg.App.BatchConcurrency,
+		EnvelopeVersions:      cfg.App.EnvelopeVersions,
+		ResponseEncodings:     encodings,
+		AccessLog:             provideAccessLog(cfg.AccessLog),
+		BodyLogger:            provideBodyLogger(cfg.BodyLog),
+		Shadow:                shadow,
+		FaultInjector:         faultInjector,
+		Rollouts:              middleware.NewRollouts(rollouts, cookies, cfg.App.Env == "production"),
+		ContractValidator:     contract2,
+		MetricsHandler:        provideMetricsHandler(cfg),
+		SeparateOps:           cfg.App.OpsAddr != "",
+	}
+	routers := &router.Routers{Public: router.NewRouter(args)}
+	if args.SeparateOps {
+		routers.Ops = router.NewOpsRouter(args)
+	}
+	return routers, nil
+}
+
+func provideMetricsHandler(cfg *config.Config) http.Handler {
+	if cfg.Metrics.Backend != "prometheus" {
+		return nil
+	}
+	return metrics.Handler()
+}
+
+func provideAccessLog(cfg config.AccessLogConfig) func(http.Handler) http.Handler {
+	return middleware.AccessLog(middleware.AccessLogArgs{
+		ExcludePaths:   cfg.ExcludePaths,
+		MaxQueryLength: cfg.MaxQueryLength,
+		Headers:        cfg.Headers,
+		DropHeaders:    cfg.DropHeaders,
+		Redactor:       redact.New(nil),
+	})
+}
+
+func provideBodyLogger(cfg config.BodyLogConfig) func(http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return nil
+	}
+	return middleware.BodyLogger(middleware.BodyLoggerArgs{
+		SampleRate: cfg.SampleRate,
+		OnError:    cfg.OnError,
+		MaxBytes:   cfg.MaxBytes,
+		Redactor:   redact.New(cfg.RedactFields),
+	})
+}
+
+// provideShadow returns the request mirroring middleware, or nil when no
+// shadow target is configured.
+func provideShadow(cfg config.ShadowConfig) (func(http.Handler) http.Handler, error) {
+	if cfg.TargetURL == "" || cfg.Percent <= 0 {
+		return nil, nil
+	}
+	target, err := url.Parse(cfg.TargetURL)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("parse SHADOW_TARGET_URL: must be an absolute URL, got %q", cfg.TargetURL)
+	}
+	return middleware.Shadow(middleware.ShadowArgs{
+		Target:       target,
+		Percent:      cfg.Percent,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		Timeout:      cfg.Timeout,
+		Concurrency:  cfg.Concurrency,
+	}), nil
+}
+
+// provideFaultInjector returns the fault injection middleware, or nil when
+// it is disabled or APP_ENV is not one of FAULT_ENVIRONMENTS.
+func provideFaultInjector(cfg *config.Config) (func(http.Handler) http.Handler, error) {
+	if !cfg.Fault.Enabled || !slices.Contains(cfg.Fault.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+	if cfg.App.Env == "production" {
+		return nil, errors.New("FAULT_ENABLED must not be used in production")
+	}
+	rules, err := middleware.ParseFaultRules(cfg.Fault.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("parse FAULT_RULES: %w", err)
+	}
+	return middleware.InjectFaults(middleware.FaultInjectorArgs{
+		Rules:        rules,
+		AllowHeaders: cfg.Fault.AllowHeaders,
+	}), nil
+}
+
+// provideContractValidator returns the request contract middleware, or nil
+// when CONTRACT_MODE is off or APP_ENV is not one of CONTRACT_ENVIRONMENTS.
+func provideContractValidator(cfg *config.Config) (func(http.Handler) http.Handler, error) {
+	mode := cfg.Contract.Mode
+	if mode == middleware.CONTRACT_MODE_OFF || !slices.Contains(cfg.Contract.Environments, cfg.App.Env) {
+		return nil, nil
+	}
+	if mode != middleware.CONTRACT_MODE_REPORT && mode != middleware.CONTRACT_MODE_ENFORCE {
+		return nil, fmt.Errorf("CONTRACT_MODE must be off, report or enforce, got %q", mode)
+	}
+	if cfg.Contract.OpenAPIFile == "" {
+		return nil, errors.New("CONTRACT_OPENAPI_FILE is required when CONTRACT_MODE is set")
+	}
+	schemas := middleware.NewSchemaRegistry()
+	if err := schemas.RegisterOpenAPI(cfg.Contract.OpenAPIFile); err != nil {
+		return nil, fmt.Errorf("load CONTRACT_OPENAPI_FILE: %w", err)
+	}
+	logger.L().Infow("request contract validation enabled", "mode", mode, "endpoints", schemas.Len())
+	return schemas.Middleware(mode), nil
+}
+
+// provideCaptcha returns the CAPTCHA check of bot-prone endpoints, a
+// pass-through when no CAPTCHA is required.
+func provideCaptcha(verifier contract.CaptchaVerifier) func(http.Handler) http.Handler {
+	if verifier == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return middleware.RequireCaptcha(verifier)
+}
+
+// provideQuota returns the quota middleware, or nil when QUOTA_ENABLED is
+// off.
+func provideQuota(cfg *config.Config, limiter *quota.Limiter, samlConnRepo contract.SAMLConnectionRepository, modes FailModes) func(http.Handler) http.Handler {
+	if !cfg.Quota.Enabled {
+		return nil
+	}
+	return middleware.Quota(limiter, samlConnRepo, modes.Open(FEATURE_QUOTA))
+}
+
+// provideCountryPolicy returns the country access policy, or nil when
+// access is not restricted by country.
+func provideCountryPolicy(cfg *config.Config, geoLocator contract.GeoLocator) (func(http.Handler) http.Handler, error) {
+	if len(cfg.GeoIP.AllowCountries) == 0 && len(cfg.GeoIP.DenyCountries) == 0 {
+		return nil, nil
+	}
+	if cfg.GeoIP.DBPath == "" {
+		return nil, errors.New("GEOIP_ALLOW_COUNTRIES and GEOIP_DENY_COUNTRIES need GEOIP_DB_PATH")
+	}
+	return middleware.CountryPolicy(geoLocator, cfg.GeoIP.AllowCountries, cfg.GeoIP.DenyCountries), nil
+}
+
+// provideAuthenticate returns the authentication of each route group, trying
+// the strategies AUTH_STRATEGIES orders for it. The hmac strategy is only
+// available with AUTH_INTERNAL_SECRETS.
+func provideAuthenticate(
+	cfg *config.Config,
+	jwtClient *jwt.Client,
+	userRepo contract.UserRepository,
+	tokenRepo contract.PersonalAccessTokenRepository,
+	externalUsers *middleware.ExternalUsers,
+	serviceAccounts *middleware.ServiceAccounts,
+) (map[string]func(http.Handler) http.Handler, error) {
+	auth3 := authConfig.From(cfg)
+	available := []middleware.AuthStrategy{
+		&middleware.BearerJWT{JWT: jwtClient, External: externalUsers},
+		&middleware.APIKeys{Tokens: tokenRepo, ServiceAccounts: serviceAccounts},
+		&middleware.SessionCookie{JWT: jwtClient, Cookie: auth3.CookieName},
+	}
+	if len(auth3.InternalSecrets) > 0 {
+		secrets := make(map[string][]byte, len(auth3.InternalSecrets))
+		for service, secret := range auth3.InternalSecrets {
+			secrets[service] = []byte(secret)
+		}
+		available = append(available, &middleware.InternalHMAC{
+			Verifier: reqsign.NewVerifier(secrets, auth3.InternalMaxSkew),
+			Roles:    auth3.InternalRoles,
+		})
+	}
+	groups, err := middleware.ParseAuthStrategies(auth3.Strategies, available)
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_STRATEGIES: %w", err)
+	}
+	authenticate := make(map[string]func(http.Handler) http.Handler, len(groups))
+	for group, strategies := range groups {
+		authenticate[group] = middleware.Authenticate(strategies, userRepo)
+	}
+	return authenticate, nil
+}
+
+// provideRequireTerms returns the terms gate, or nil when API access is not
+// blocked on acceptance. Accepting must stay reachable while blocked.
+func provideRequireTerms(cfg *config.Config, termsRepo contract.TermsAcceptanceRepository) func(http.Handler) http.Handler {
+	if !cfg.Terms.BlockUntilAccepted {
+		return nil
+	}
+	return middleware.RequireCurrentTerms(termsRepo, currentTerms(cfg), "/api/v1/me/terms")
+}
+
+// ProvideFixtureLoader provides the fixture loader used to seed the repositories
+func ProvideFixtureLoader(
+	userRepo contract.UserRepository,
+	subscriptionRepo contract.SubscriptionRepository,
+) *fixtures.Loader {
+	return newFixtureLoader(userRepo, subscriptionRepo)
+}
+
+// ProvideAuthModule provides the auth module
+func ProvideAuthModule(
+	cfg *config.Config, hasher2 contract.PasswordHasher,
+
+	sessionRepo contract.SessionRepository,
+	purge *session.PurgeExpiredTokensUseCase,
+) *AuthModule {
+	return NewAuthModule(hasher2, sessionRepo, purge, cfg.WarmUp.Hashes, cfg.WarmUp.Connections)
+}
+
+// ProvideAccountModule provides the account module
+func ProvideAccountModule(
+	cfg *config.Config,
+	resend *account.ResendEmailChangeUseCase,
+	purgeDeleted *account.PurgeDeletedAccountsUseCase,
+) (*AccountModule, error) {
+	deletion := accountDeletionConfig.From(cfg)
+	if deletion.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("ACCOUNT_DELETION_PURGE_INTERVAL must be positive, got %s", deletion.PurgeInterval)
+	}
+	return NewAccountModule(resend, purgeDeleted, deletion.PurgeInterval), nil
+}
+
+// ProvideBillingModule provides the billing module
+func ProvideBillingModule(cfg *config.Config, provider contract.BillingProvider) *BillingModule {
+	return NewBillingModule(provider, cfg.Billing.HealthInterval)
+}
+
+// ProvideMailModule provides the mail module
+func ProvideMailModule(
+	cfg *config.Config,
+	worker *mailer.QueueWorker,
+	emailQueueRepo contract.EmailQueueRepository,
+	templates *mailer.TemplateRegistry,
+) *MailModule {
+	return NewMailModule(worker, emailQueueRepo, templates, cfg.Mail.MaxLag)
+}
+
+// ProvideNotificationModule provides the notification module
+func ProvideNotificationModule(deliver *notification.DeliverPendingNotificationsUseCase) *NotificationModule {
+	return NewNotificationModule(deliver)
+}
+
+// ProvideJobsModule provides the scheduled jobs module
+func ProvideJobsModule(scheduler *jobs.Scheduler, repo contract.ScheduledJobRepository) *JobsModule {
+	return NewJobsModule(scheduler, repo)
+}
+
+// ProvidePhoneModule provides the phone module
+func ProvidePhoneModule(clearUnverified *phone.ClearUnverifiedPhoneUseCase) *PhoneModule {
+	return NewPhoneModule(clearUnverified)
+}
+
+// ProvideUsersModule provides the users module
+func ProvideUsersModule(relay *infrastructure.UserCacheRelay) *UsersModule {
+	return NewUsersModule(relay)
+}
+
+// ProvidePresenceModule provides the presence module
+func ProvidePresenceModule(cfg *config.Config, tracker *realtime.PresenceTracker, handler *users.UsersHandler) *PresenceModule {
+	return NewPresenceModule(tracker, handler, presenceConfig.From(cfg).TTL)
+}
+
+// ProvideStatsModule provides the stats module
+func ProvideStatsModule(cfg *config.Config, refresh *stats.RefreshStatsUseCase) *StatsModule {
+	return NewStatsModule(refresh, statsConfig.From(cfg).RefreshInterval)
+}
+
+// ProvideRouteTable provides the table of the routes modules declare with a
+// policy
+func ProvideRouteTable() *router.RouteTable {
+	return router.NewRouteTable()
+}
+
+// ProvideModules provides the modules whose checks, tasks and routes the
+// container registers
+func ProvideModules(auth3 *AuthModule, billing4 *BillingModule, mail3 *MailModule, notification2 *NotificationModule, jobs2 *JobsModule, phone2 *PhoneModule, users2 *UsersModule, stats2 *StatsModule, account2 *AccountModule, presence2 *PresenceModule,
+) []Module {
+	return []Module{auth3, billing4, mail3, notification2, jobs2, phone2, users2, stats2, account2, presence2}
 }
 
 // ProvideContainer provides the application container
-func ProvideContainer(r *chi.Mux) *Container {
+func ProvideContainer(
+	cfg *config.Config,
+	routers *router.Routers,
+	fixtureLoader *fixtures.Loader,
+	elector *leader.Elector,
+	drainer *drain.Drainer,
+	preStop *drain.PreStop,
+	bus *eventbus.Bus,
+	metricsBackend metrics.Backend,
+	logExporter *otlplog.Exporter,
+	lifecycleRegistry *lifecycle.Registry,
+	modules []Module,
+	analyticsTracker contract.AnalyticsTracker,
+	auditStream *siem.Streamer,
+	scheduler *jobs.Scheduler,
+	tasks *jobs.AdminTaskRegistry,
+	routes *router.RouteTable,
+	doctor *Doctor,
+) *Container {
+	lifecycleRegistry.Set(COMPONENT_EVENT_BUS, lifecycle.COMPONENT_UP, "")
+	warmer2 := newWarmer(cfg.WarmUp.Timeout, cfg.WarmUp.ReadinessDelay)
+	bg := &background{}
+	registerModules(lifecycleRegistry, elector, readinessPolicy(cfg), warmer2, bg, scheduler, tasks, routes, modules)
 	return &Container{
-		Status: 1,
-		Router: r,
+		Lifecycle:   lifecycleRegistry,
+		Router:      routers.Public,
+		OpsRouter:   routers.Ops,
+		Fixtures:    fixtureLoader,
+		Elector:     elector,
+		Drainer:     drainer,
+		PreStop:     preStop,
+		EventBus:    bus,
+		Metrics:     metricsBackend,
+		LogExporter: logExporter,
+		Analytics:   analyticsTracker,
+		AuditStream: auditStream,
+		warmer:      warmer2,
+		background:  bg,
+		doctor:      doctor,
 	}
 }
@@ -2,18 +2,120 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	App AppConfig `require:"true"`
-	DB  DBConfig  `require:"true"`
+	App         AppConfig `require:"true"`
+	DB          DBConfig  `require:"true"`
+	UserStore   UserStoreConfig
+	UserCache   UserCacheConfig
+	Sessions    SessionStoreConfig
+	AccessLog   AccessLogConfig
+	BodyLog     BodyLogConfig
+	Shadow      ShadowConfig
+	Fault       FaultConfig
+	Rollout     RolloutConfig
+	Contract    ContractConfig
+	Mock        MockConfig
+	Seed        SeedConfig
+	Log         LogConfig
+	Crash       CrashConfig
+	Metrics     MetricsConfig
+	Trace       TraceConfig
+	Resilience  ResilienceConfig
+	Readiness   ReadinessConfig
+	WarmUp      WarmUpConfig
+	Leader      LeaderConfig
+	EventBus    EventBusConfig
+	Dedupe      DedupeConfig
+	LoadShed    LoadShedConfig
+	Admission   AdmissionConfig
+	JWT         JWTConfig
+	Admin       AdminConfig
+	Mail        MailConfig
+	Device      DeviceAlertConfig
+	SignUp      SignUpConfig
+	SignUpBot   SignUpBotConfig
+	Cookie      CookieConfig
+	Captcha     CaptchaConfig
+	Reset       PasswordResetConfig
+	Password    PasswordPolicyConfig
+	Terms       TermsConfig
+	EmailChange EmailChangeConfig
+	PhoneOTP    PhoneOTPConfig
+	Guest       GuestConfig
+	OIDC        OIDCConfig
+	SAML        SAMLConfig
+	Impersonate ImpersonationConfig
+	Quota       QuotaConfig
+	GeoIP       GeoIPConfig
+	Billing     BillingConfig
+	Analytics   AnalyticsConfig
+	Plan        PlanConfig
+	Jobs        JobsConfig
+
+	// sections holds the modules' own sections by prefix; see
+	// RegisterSection.
+	sections map[string]any
 }
 
 type AppConfig struct {
-	Port int `envconfig:"APP_PORT" default:"8080"`
+	Port int    `envconfig:"APP_PORT" default:"8080"`
+	Env  string `envconfig:"APP_ENV" default:"development"`
+	// Listen overrides Port with a listener address: "unix:<path>" for a
+	// Unix socket, "systemd" or "systemd:<name>" for a socket passed by
+	// systemd socket activation, or a TCP "host:port".
+	Listen string `envconfig:"APP_LISTEN"`
+	// SocketMode is the permission of the Unix sockets created.
+	SocketMode os.FileMode `envconfig:"APP_SOCKET_MODE" default:"0660"`
+	// OpsAddr, when set, moves health, metrics, pprof and the admin API to a
+	// separate internal listener, in the format of Listen, e.g.
+	// "127.0.0.1:9090" or "unix:/run/go-app/ops.sock".
+	OpsAddr string `envconfig:"APP_OPS_ADDR"`
+	// ShutdownDelay keeps serving with failing readiness before the listener
+	// closes, giving load balancers time to deregister the instance.
+	ShutdownDelay   time.Duration `envconfig:"APP_SHUTDOWN_DELAY" default:"0s"`
+	ShutdownTimeout time.Duration `envconfig:"APP_SHUTDOWN_TIMEOUT" default:"10s"`
+	// RestartTimeout bounds how long a graceful restart (SIGUSR2) waits for
+	// the new process to be ready before giving up on it and serving on.
+	RestartTimeout time.Duration `envconfig:"APP_RESTART_TIMEOUT" default:"30s"`
+	// TerminationLog, e.g. /dev/termination-log, receives a summary of the
+	// shutdown, or the fatal error that ended the process, for kubectl
+	// describe pod to show. Empty writes none.
+	TerminationLog string `envconfig:"APP_TERMINATION_LOG"`
+	// Server timeouts guard against slow clients (e.g. slowloris); a zero
+	// value disables the corresponding limit.
+	ReadHeaderTimeout time.Duration `envconfig:"APP_READ_HEADER_TIMEOUT" default:"5s"`
+	ReadTimeout       time.Duration `envconfig:"APP_READ_TIMEOUT" default:"15s"`
+	WriteTimeout      time.Duration `envconfig:"APP_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `envconfig:"APP_IDLE_TIMEOUT" default:"120s"`
+	// RequestBudget is the deadline of the context of each request, which
+	// the calls it makes to the database and other services inherit. It
+	// defaults to WriteTimeout less RequestBudgetReserve, which is left to
+	// write the error response after the budget runs out.
+	RequestBudget        time.Duration `envconfig:"APP_REQUEST_BUDGET"`
+	RequestBudgetReserve time.Duration `envconfig:"APP_REQUEST_BUDGET_RESERVE" default:"1s"`
+	MaxHeaderBytes       int           `envconfig:"APP_MAX_HEADER_BYTES" default:"1048576"`
+	KeepAlives           bool          `envconfig:"APP_KEEP_ALIVES" default:"true"`
+	// TrustedProxies are CIDRs of upstream proxies whose X-Request-ID and
+	// forwarded client address are kept; other peers are taken as the
+	// client.
+	TrustedProxies   []string `envconfig:"APP_TRUSTED_PROXIES"`
+	BatchMaxRequests int      `envconfig:"APP_BATCH_MAX_REQUESTS" default:"20"`
+	BatchConcurrency int      `envconfig:"APP_BATCH_CONCURRENCY" default:"4"`
+	// EnvelopeVersions lists the API versions (e.g. "v1") whose responses are
+	// wrapped in the data/meta/links envelope.
+	EnvelopeVersions []string `envconfig:"APP_ENVELOPE_VERSIONS"`
+	// ResponseEncodings sets how the responses of API versions render
+	// times, 64-bit integers and nulls for clients with strict parsers,
+	// e.g. APP_RESPONSE_ENCODINGS=v1:time=unix;int64=string;nulls=omit. See
+	// response.ParseEncodings.
+	ResponseEncodings map[string]string `envconfig:"APP_RESPONSE_ENCODINGS" secret:"false"`
 }
 
 type DBConfig struct {
@@ -21,11 +123,532 @@ type DBConfig struct {
 	Port         int    `envconfig:"DB_PORT" default:"5432"`
 	DatabaseName string `envconfig:"DB_NAME" required:"true"`
 	Username     string `envconfig:"DB_USERNAME" required:"true"`
-	Password     string `envconfig:"DB_PASSWORD" required:"true"`
+	Password     string `envconfig:"DB_PASSWORD" required:"true" secret:"true"`
+}
+
+// SessionStoreConfig selects where sessions and their refresh token IDs are
+// kept: "memory" (one instance only), "redis" (fast, expiring with the
+// sessions) or "postgres" (durable and queryable, in the DB_* database).
+// Postgres uses the database/sql driver registered under SQLDriver; the
+// server links pgx.
+type SessionStoreConfig struct {
+	Backend       string `envconfig:"SESSION_STORE_BACKEND" default:"memory"`
+	RedisAddr     string `envconfig:"SESSION_STORE_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword string `envconfig:"SESSION_STORE_REDIS_PASSWORD" secret:"true"`
+	RedisDB       int    `envconfig:"SESSION_STORE_REDIS_DB" default:"0"`
+	RedisPrefix   string `envconfig:"SESSION_STORE_REDIS_PREFIX" default:"go-app:"`
+	SQLDriver     string `envconfig:"SESSION_STORE_SQL_DRIVER" default:"pgx"`
+}
+
+// UserStoreConfig selects how users are persisted: "state" keeps each
+// user's current row only, "events" appends every change to an event store
+// and projects the users table from it for queries.
+type UserStoreConfig struct {
+	Mode string `envconfig:"USER_STORE_MODE" default:"state"`
+	// SnapshotEvery is the number of events between user snapshots in
+	// "events" mode; 0 disables snapshots.
+	SnapshotEvery int `envconfig:"USER_STORE_SNAPSHOT_EVERY" default:"50"`
+}
+
+// UserCacheConfig caches users by ID in front of the user store. Writes
+// evict the user on the instance that makes them; with Invalidation "redis"
+// they are also published on RedisChannel to evict it on the other
+// instances, which "local" leaves to the TTL, so it suits a single one.
+type UserCacheConfig struct {
+	Enabled       bool          `envconfig:"USER_CACHE_ENABLED" default:"false"`
+	TTL           time.Duration `envconfig:"USER_CACHE_TTL" default:"5m"`
+	Size          int           `envconfig:"USER_CACHE_SIZE" default:"10000"`
+	Invalidation  string        `envconfig:"USER_CACHE_INVALIDATION" default:"local"`
+	RedisAddr     string        `envconfig:"USER_CACHE_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword string        `envconfig:"USER_CACHE_REDIS_PASSWORD" secret:"true"`
+	RedisDB       int           `envconfig:"USER_CACHE_REDIS_DB" default:"0"`
+	RedisChannel  string        `envconfig:"USER_CACHE_REDIS_CHANNEL" default:"go-app:user-cache"`
+}
+
+// AccessLogConfig trims the access log. ExcludePaths are not logged; an
+// entry ending in "*" covers the paths it prefixes. Query strings longer
+// than MaxQueryLength are truncated, and 0 leaves them out. Headers adds the
+// request headers, with sensitive values masked, but for DropHeaders.
+type AccessLogConfig struct {
+	ExcludePaths   []string `envconfig:"ACCESS_LOG_EXCLUDE_PATHS" default:"/health,/readyz,/metrics"`
+	MaxQueryLength int      `envconfig:"ACCESS_LOG_MAX_QUERY_LENGTH" default:"256"`
+	Headers        bool     `envconfig:"ACCESS_LOG_HEADERS" default:"false"`
+	DropHeaders    []string `envconfig:"ACCESS_LOG_DROP_HEADERS" default:"Cookie,Accept,Accept-Encoding,Connection"`
+}
+
+// BodyLogConfig controls the debug request/response body capture middleware.
+// RedactFields, when set, replaces redact.DefaultFields as the keys whose
+// values are masked; each masks the keys containing it.
+type BodyLogConfig struct {
+	Enabled      bool     `envconfig:"BODY_LOG_ENABLED" default:"false"`
+	SampleRate   float64  `envconfig:"BODY_LOG_SAMPLE_RATE" default:"0.01"`
+	OnError      bool     `envconfig:"BODY_LOG_ON_ERROR" default:"true"`
+	MaxBytes     int      `envconfig:"BODY_LOG_MAX_BYTES" default:"4096"`
+	RedactFields []string `envconfig:"BODY_LOG_REDACT_FIELDS"`
+}
+
+// ResilienceConfig sets the circuit breaker and bulkhead guarding the database.
+type ResilienceConfig struct {
+	FailureThreshold int           `envconfig:"RESILIENCE_FAILURE_THRESHOLD" default:"5"`
+	OpenTimeout      time.Duration `envconfig:"RESILIENCE_OPEN_TIMEOUT" default:"30s"`
+	MaxConcurrent    int           `envconfig:"RESILIENCE_MAX_CONCURRENT" default:"100"`
+	MaxWait          time.Duration `envconfig:"RESILIENCE_MAX_WAIT" default:"100ms"`
+}
+
+// LogConfig controls sampled loggers: per SampleInterval the first
+// SampleFirst entries of a call site are kept before sampling starts.
+// ReadinessConfig caches the dependency checks behind /readyz: they are
+// probed every CheckInterval (0 probes on every request), and a component
+// only changes state after FailureThreshold or SuccessThreshold probes in a
+// row agree. One whose last probe is older than CheckMaxAge (0 means three
+// intervals) is reported down.
+type ReadinessConfig struct {
+	CheckInterval    time.Duration `envconfig:"READINESS_CHECK_INTERVAL" default:"5s"`
+	FailureThreshold int           `envconfig:"READINESS_FAILURE_THRESHOLD" default:"3"`
+	SuccessThreshold int           `envconfig:"READINESS_SUCCESS_THRESHOLD" default:"2"`
+	CheckMaxAge      time.Duration `envconfig:"READINESS_CHECK_MAX_AGE" default:"0"`
+}
+
+// WarmUpConfig sets the warm-up run after boot, before /readyz reports
+// ready: the modules' hooks get Timeout in all, and the instance does not
+// report ready before ReadinessDelay has passed since boot either. Hashes is
+// the number of password hashes computed and Connections the number of
+// store connections opened ahead of the first requests.
+type WarmUpConfig struct {
+	Timeout        time.Duration `envconfig:"WARMUP_TIMEOUT" default:"30s"`
+	ReadinessDelay time.Duration `envconfig:"WARMUP_READINESS_DELAY" default:"0"`
+	Hashes         int           `envconfig:"WARMUP_HASHES" default:"2"`
+	Connections    int           `envconfig:"WARMUP_CONNECTIONS" default:"4"`
+}
+
+// LogConfig tunes logging. Setting OTLPEndpoint also exports every entry
+// at OTLPLevel or above to an OTLP/HTTP receiver, with its trace and span
+// IDs, whatever the console output.
+type LogConfig struct {
+	SampleFirst       int               `envconfig:"LOG_SAMPLE_FIRST" default:"10"`
+	SampleInterval    time.Duration     `envconfig:"LOG_SAMPLE_INTERVAL" default:"1m"`
+	OTLPEndpoint      string            `envconfig:"LOG_OTLP_ENDPOINT"`
+	OTLPHeaders       map[string]string `envconfig:"LOG_OTLP_HEADERS" secret:"true"`
+	OTLPLevel         string            `envconfig:"LOG_OTLP_LEVEL" default:"info"`
+	OTLPServiceName   string            `envconfig:"LOG_OTLP_SERVICE_NAME" default:"go-app"`
+	OTLPBuffer        int               `envconfig:"LOG_OTLP_BUFFER" default:"4096"`
+	OTLPBatchSize     int               `envconfig:"LOG_OTLP_BATCH_SIZE" default:"512"`
+	OTLPFlushInterval time.Duration     `envconfig:"LOG_OTLP_FLUSH_INTERVAL" default:"2s"`
+	OTLPTimeout       time.Duration     `envconfig:"LOG_OTLP_TIMEOUT" default:"5s"`
+}
+
+// CrashConfig sets where diagnostics bundles are written on unrecoverable
+// failures. An empty ReportDir disables them.
+type CrashConfig struct {
+	ReportDir string `envconfig:"CRASH_REPORT_DIR" default:"crash-reports"`
+}
+
+// ShadowConfig controls request mirroring. It is off unless TargetURL is
+// set and Percent is positive.
+type ShadowConfig struct {
+	TargetURL    string        `envconfig:"SHADOW_TARGET_URL"`
+	Percent      float64       `envconfig:"SHADOW_PERCENT" default:"0"`
+	MaxBodyBytes int64         `envconfig:"SHADOW_MAX_BODY_BYTES" default:"1048576"`
+	Timeout      time.Duration `envconfig:"SHADOW_TIMEOUT" default:"5s"`
+	Concurrency  int           `envconfig:"SHADOW_CONCURRENCY" default:"16"`
+}
+
+// FaultConfig controls fault injection for chaos testing. It is active only
+// when Enabled and APP_ENV is one of Environments, and never in production.
+// Rules maps path prefixes to faults, e.g.
+// FAULT_RULES=/api/v1/me:delay=200ms;error=0.1;drop=0.05.
+type FaultConfig struct {
+	Enabled      bool              `envconfig:"FAULT_ENABLED" default:"false"`
+	Environments []string          `envconfig:"FAULT_ENVIRONMENTS" default:"development,staging"`
+	Rules        map[string]string `envconfig:"FAULT_RULES" secret:"false"`
+	AllowHeaders bool              `envconfig:"FAULT_ALLOW_HEADERS" default:"true"`
+}
+
+// RolloutConfig soft-launches new implementations of routes. Rules maps
+// each flag to the callers its candidate serves, e.g.
+// ROLLOUT_RULES=sign_up_v2:percent=10;roles=admin; flags without a rule
+// serve everyone the stable implementation.
+type RolloutConfig struct {
+	Rules map[string]string `envconfig:"ROLLOUT_RULES" secret:"false"`
+}
+
+// ContractConfig checks request bodies against the JSON Schemas of the
+// OpenAPIFile document. Mode report logs violations and enforce also rejects
+// them with 400; it is active only when APP_ENV is one of Environments.
+type ContractConfig struct {
+	Mode         string   `envconfig:"CONTRACT_MODE" default:"off"`
+	OpenAPIFile  string   `envconfig:"CONTRACT_OPENAPI_FILE"`
+	Environments []string `envconfig:"CONTRACT_ENVIRONMENTS" default:"development,staging"`
+}
+
+// MockConfig swaps the external adapters (mailer, SMS, payments, CAPTCHA)
+// for local fakes that record into an outbox served at /debug/outbox, so the
+// stack runs offline. It is refused in production.
+type MockConfig struct {
+	Deps       bool `envconfig:"MOCK_DEPS" default:"false"`
+	OutboxSize int  `envconfig:"MOCK_OUTBOX_SIZE" default:"500"`
+}
+
+// SeedConfig lists fixture files (YAML or JSON) loaded into the
+// repositories at startup, outside production.
+type SeedConfig struct {
+	Fixtures []string `envconfig:"SEED_FIXTURES"`
+}
+
+// MetricsConfig selects where metrics go: "prometheus" serves them on
+// /metrics, "statsd" pushes them to a StatsD agent and "noop" drops them.
+type MetricsConfig struct {
+	Backend             string        `envconfig:"METRICS_BACKEND" default:"prometheus"`
+	StatsDAddr          string        `envconfig:"METRICS_STATSD_ADDR" default:"127.0.0.1:8125"`
+	StatsDPrefix        string        `envconfig:"METRICS_STATSD_PREFIX" default:"go_app."`
+	StatsDFlushInterval time.Duration `envconfig:"METRICS_STATSD_FLUSH_INTERVAL" default:"1s"`
+}
+
+// TraceConfig controls tracing. Exporter is "none" or "log"; SampleRatio is
+// the fraction of new traces recorded, while traces continued from a
+// traceparent header keep the caller's decision.
+type TraceConfig struct {
+	Exporter    string  `envconfig:"TRACE_EXPORTER" default:"none"`
+	SampleRatio float64 `envconfig:"TRACE_SAMPLE_RATIO" default:"0.1"`
+}
+
+// LeaderConfig controls leader election for singleton background tasks.
+type LeaderConfig struct {
+	Key           string        `envconfig:"LEADER_KEY" default:"go-app:leader"`
+	LeaseTTL      time.Duration `envconfig:"LEADER_LEASE_TTL" default:"15s"`
+	RetryInterval time.Duration `envconfig:"LEADER_RETRY_INTERVAL" default:"5s"`
+}
+
+// EventBusConfig sizes the in-process event bus. Events published while
+// Buffer events are already waiting are dropped.
+type EventBusConfig struct {
+	Buffer  int `envconfig:"EVENT_BUS_BUFFER" default:"4096"`
+	Workers int `envconfig:"EVENT_BUS_WORKERS" default:"2"`
+}
+
+// DedupeConfig controls the store that drops repeated webhook deliveries and
+// bus events: "memory" (one instance only) or "redis". Retention is how long
+// a processed delivery is remembered, by default longer than Stripe's three
+// days of retries; ScopeRetention overrides it per consumer, e.g.
+// DEDUPE_SCOPE_RETENTION=billing:96h,usage:10m.
+type DedupeConfig struct {
+	Backend        string                   `envconfig:"DEDUPE_BACKEND" default:"memory"`
+	Lease          time.Duration            `envconfig:"DEDUPE_LEASE" default:"1m"`
+	Retention      time.Duration            `envconfig:"DEDUPE_RETENTION" default:"96h"`
+	ScopeRetention map[string]time.Duration `envconfig:"DEDUPE_SCOPE_RETENTION" default:"usage:10m" secret:"false"`
+	RedisAddr      string                   `envconfig:"DEDUPE_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword  string                   `envconfig:"DEDUPE_REDIS_PASSWORD" secret:"true"`
+	RedisDB        int                      `envconfig:"DEDUPE_REDIS_DB" default:"0"`
+	RedisPrefix    string                   `envconfig:"DEDUPE_REDIS_PREFIX" default:"go-app:dedupe:"`
+}
+
+// LoadShedConfig caps concurrent in-flight requests. GroupLimits is keyed by
+// route group name ("api", "admin"), e.g. LOAD_SHED_GROUP_LIMITS=api:200,admin:20.
+type LoadShedConfig struct {
+	MaxInFlight int            `envconfig:"LOAD_SHED_MAX_IN_FLIGHT" default:"0"`
+	GroupLimits map[string]int `envconfig:"LOAD_SHED_GROUP_LIMITS" secret:"false"`
+	RetryAfter  time.Duration  `envconfig:"LOAD_SHED_RETRY_AFTER" default:"1s"`
+}
+
+// AdmissionConfig controls priority-based admission control. A zero Capacity
+// disables it.
+type AdmissionConfig struct {
+	Capacity         int           `envconfig:"ADMISSION_CAPACITY" default:"0"`
+	MaxQueue         int           `envconfig:"ADMISSION_MAX_QUEUE" default:"100"`
+	HighQueueTimeout time.Duration `envconfig:"ADMISSION_HIGH_QUEUE_TIMEOUT" default:"2s"`
+	LowQueueTimeout  time.Duration `envconfig:"ADMISSION_LOW_QUEUE_TIMEOUT" default:"250ms"`
+}
+
+type JWTConfig struct {
+	Secret     string        `envconfig:"JWT_SECRET" required:"true" secret:"true"`
+	AccessTTL  time.Duration `envconfig:"JWT_ACCESS_TTL" default:"15m"`
+	RefreshTTL time.Duration `envconfig:"JWT_REFRESH_TTL" default:"720h"`
+	Issuer     string        `envconfig:"JWT_ISSUER" default:"go-app"`
+	Audience   string        `envconfig:"JWT_AUDIENCE" default:"go-app"`
+	Leeway     time.Duration `envconfig:"JWT_LEEWAY" default:"30s"`
+}
+
+// AdminConfig names the administrators: tokens issued to these emails carry
+// the admin role, which the admin API and dashboard require. Emails are not
+// verified at sign-up, so list only addresses whose accounts already exist.
+// Dashboard serves the dashboard at /admin.
+type AdminConfig struct {
+	Emails    []string `envconfig:"ADMIN_EMAILS"`
+	Dashboard bool     `envconfig:"ADMIN_DASHBOARD_ENABLED" default:"true"`
+}
+
+// MailConfig controls outgoing email. Mail is queued and delivered by the
+// leader, retrying with exponential backoff from RetryBase up to RetryMax
+// for MaxAttempts attempts. WebhookSecret signs the provider's bounce,
+// complaint and unsubscribe webhook; the webhook is off when it is empty.
+// TemplateDir overrides the built-in email templates file by file. The
+// queue is reported down while a message has been due for longer than
+// MaxLag.
+type MailConfig struct {
+	From          string        `envconfig:"MAIL_FROM" default:"no-reply@go-app.local"`
+	TemplateDir   string        `envconfig:"MAIL_TEMPLATE_DIR"`
+	PollInterval  time.Duration `envconfig:"MAIL_QUEUE_POLL_INTERVAL" default:"5s"`
+	BatchSize     int           `envconfig:"MAIL_QUEUE_BATCH_SIZE" default:"50"`
+	MaxAttempts   int           `envconfig:"MAIL_QUEUE_MAX_ATTEMPTS" default:"8"`
+	RetryBase     time.Duration `envconfig:"MAIL_QUEUE_RETRY_BASE" default:"30s"`
+	RetryMax      time.Duration `envconfig:"MAIL_QUEUE_RETRY_MAX" default:"1h"`
+	MaxLag        time.Duration `envconfig:"MAIL_QUEUE_MAX_LAG" default:"15m"`
+	WebhookSecret string        `envconfig:"MAIL_WEBHOOK_SECRET" secret:"true"`
+}
+
+// DeviceAlertConfig controls new-device sign-in alerts. LinkBaseURL is the
+// public URL of the auth devices endpoints used in the emailed links.
+type DeviceAlertConfig struct {
+	Enabled         bool          `envconfig:"DEVICE_ALERT_ENABLED" default:"true"`
+	RequireApproval bool          `envconfig:"DEVICE_ALERT_REQUIRE_APPROVAL" default:"false"`
+	ApprovalTTL     time.Duration `envconfig:"DEVICE_ALERT_APPROVAL_TTL" default:"24h"`
+	LinkBaseURL     string        `envconfig:"DEVICE_ALERT_LINK_BASE_URL" default:"http://localhost:8080/api/v1/auth/devices"`
+}
+
+// SignUpConfig sets the email policies enforced on registration.
+// DisposableDomainsFile, when set, replaces the built-in disposable provider
+// list with one domain per line.
+type SignUpConfig struct {
+	AllowedDomains        []string `envconfig:"SIGNUP_ALLOWED_DOMAINS"`
+	BlockDisposable       bool     `envconfig:"SIGNUP_BLOCK_DISPOSABLE" default:"true"`
+	DisposableDomainsFile string   `envconfig:"SIGNUP_DISPOSABLE_DOMAINS_FILE"`
+	RejectPlusAliases     bool     `envconfig:"SIGNUP_REJECT_PLUS_ALIASES" default:"true"`
+	// InviteOnly closes open registration; sign-up then needs an invite code.
+	InviteOnly bool          `envconfig:"SIGNUP_INVITE_ONLY" default:"false"`
+	InviteTTL  time.Duration `envconfig:"SIGNUP_INVITE_TTL" default:"168h"`
+}
+
+// SignUpBotConfig turns on bot detection at sign-up. Each sign-up is scored
+// on its signals: a filled-in honeypot field, a form submitted without the
+// token of GET /auth/sign-up/form or sooner than MinSubmitTime after it, an
+// automated user agent, and an address in SuspiciousNetworks, which adds
+// NetworkRisk. From CaptchaScore on the sign-up needs a CAPTCHA, in place of
+// the one every sign-up needs otherwise, or is refused when there is no
+// CAPTCHA provider; from BlockScore on it is refused. FormSecret seals the
+// form tokens when COOKIE_SECRETS is unset; prefer COOKIE_SECRETS.
+type SignUpBotConfig struct {
+	Enabled            bool          `envconfig:"SIGNUP_BOT_ENABLED" default:"false"`
+	FormSecret         string        `envconfig:"SIGNUP_BOT_FORM_SECRET" secret:"true"`
+	MinSubmitTime      time.Duration `envconfig:"SIGNUP_BOT_MIN_SUBMIT_TIME" default:"3s"`
+	FormTTL            time.Duration `envconfig:"SIGNUP_BOT_FORM_TTL" default:"1h"`
+	SuspiciousNetworks []string      `envconfig:"SIGNUP_BOT_SUSPICIOUS_NETWORKS"`
+	NetworkRisk        int           `envconfig:"SIGNUP_BOT_NETWORK_RISK" default:"50"`
+	CaptchaScore       int           `envconfig:"SIGNUP_BOT_CAPTCHA_SCORE" default:"50"`
+	BlockScore         int           `envconfig:"SIGNUP_BOT_BLOCK_SCORE" default:"100"`
+}
+
+// CookieConfig holds the secrets sealing what is handed to clients to send
+// back, see pkg/securecookie: the rollout_id cookie and the sign-up form
+// tokens. The first secret seals new values and all of them open them, so
+// a secret is rotated by putting the new one first and dropping the old one
+// a day later. They must be the same on every replica; when unset,
+// SIGNUP_BOT_FORM_SECRET is used, or else a random secret that is lost on
+// restart.
+type CookieConfig struct {
+	Secrets []string `envconfig:"COOKIE_SECRETS" secret:"true"`
+}
+
+// CaptchaConfig selects the CAPTCHA provider (none, recaptcha, hcaptcha or
+// turnstile). Environments lists the APP_ENV values it is enforced in, so
+// local and test setups can skip it; empty means every environment.
+type CaptchaConfig struct {
+	Provider     string        `envconfig:"CAPTCHA_PROVIDER" default:"none"`
+	Secret       string        `envconfig:"CAPTCHA_SECRET" secret:"true"`
+	MinScore     float64       `envconfig:"CAPTCHA_MIN_SCORE" default:"0.5"`
+	Timeout      time.Duration `envconfig:"CAPTCHA_TIMEOUT" default:"5s"`
+	Environments []string      `envconfig:"CAPTCHA_ENVIRONMENTS"`
+}
+
+// PasswordPolicyConfig sets how long a password stays valid; once MaxAge has
+// passed since it was set, sign-in requires choosing a new one. Zero
+// disables expiry.
+//
+// HashWorkers bounds the password hashes and compares run at once (0 means
+// GOMAXPROCS); beyond HashQueue waiting ones, requests fail fast with 503.
+type PasswordPolicyConfig struct {
+	MaxAge      time.Duration `envconfig:"PASSWORD_MAX_AGE" default:"0"`
+	HashWorkers int           `envconfig:"PASSWORD_HASH_WORKERS" default:"0"`
+	HashQueue   int           `envconfig:"PASSWORD_HASH_QUEUE" default:"64"`
+}
+
+// PasswordResetConfig sets the page the emailed reset links open and how
+// long they work.
+type PasswordResetConfig struct {
+	LinkBaseURL string        `envconfig:"PASSWORD_RESET_LINK_BASE_URL" default:"http://localhost:3000/reset-password"`
+	TTL         time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"1h"`
+}
+
+// EmailChangeConfig sets the public URL of the email change endpoints used
+// in the confirm and revert links. TTL is how long the new address has to
+// confirm; RevertWindow is how long the old one can undo a confirmed change.
+type EmailChangeConfig struct {
+	LinkBaseURL  string        `envconfig:"EMAIL_CHANGE_LINK_BASE_URL" default:"http://localhost:8080/api/v1/auth/email-change"`
+	TTL          time.Duration `envconfig:"EMAIL_CHANGE_TTL" default:"24h"`
+	RevertWindow time.Duration `envconfig:"EMAIL_CHANGE_REVERT_WINDOW" default:"168h"`
+}
+
+// PhoneOTPConfig tunes the codes texted for phone verification and sign-in.
+// ResendInterval and MaxPerHour apply per phone number.
+type PhoneOTPConfig struct {
+	TTL            time.Duration `envconfig:"PHONE_OTP_TTL" default:"5m"`
+	Length         int           `envconfig:"PHONE_OTP_LENGTH" default:"6"`
+	MaxAttempts    int           `envconfig:"PHONE_OTP_MAX_ATTEMPTS" default:"5"`
+	ResendInterval time.Duration `envconfig:"PHONE_OTP_RESEND_INTERVAL" default:"1m"`
+	MaxPerHour     int           `envconfig:"PHONE_OTP_MAX_PER_HOUR" default:"5"`
+	// UnverifiedTTL is how long a number waiting for verification stays on
+	// the account before it is cleared; 0 keeps it.
+	UnverifiedTTL time.Duration `envconfig:"PHONE_OTP_UNVERIFIED_TTL" default:"168h"`
+}
+
+// GuestConfig enables anonymous accounts that can be upgraded later.
+type GuestConfig struct {
+	Enabled bool `envconfig:"GUEST_ENABLED" default:"false"`
+}
+
+// OIDCConfig turns on OpenID Connect provider mode. SigningKeyFile holds
+// the PEM RSA key that signs ID tokens; without it a key is generated at
+// start-up, which invalidates issued ID tokens on every restart. LoginURL is
+// the front-end page the authorization endpoint forwards the browser to.
+type OIDCConfig struct {
+	Enabled        bool          `envconfig:"OIDC_ENABLED" default:"false"`
+	Issuer         string        `envconfig:"OIDC_ISSUER" default:"http://localhost:8080"`
+	SigningKeyFile string        `envconfig:"OIDC_SIGNING_KEY_FILE"`
+	LoginURL       string        `envconfig:"OIDC_LOGIN_URL" default:"http://localhost:3000/authorize"`
+	CodeTTL        time.Duration `envconfig:"OIDC_CODE_TTL" default:"1m"`
+	IDTokenTTL     time.Duration `envconfig:"OIDC_ID_TOKEN_TTL" default:"1h"`
+}
+
+// SAMLConfig applies to every tenant's SAML connection. BaseURL is the
+// public origin the /saml endpoints are reached under; SuccessURL is the
+// front-end page that receives the outcome of a sign-in. LoginStateTTL is how
+// long a user has to sign in at the identity provider. Sign-ins the identity
+// provider starts on its own carry no login state and are refused unless
+// AllowIdPInitiated is set.
+type SAMLConfig struct {
+	BaseURL           string        `envconfig:"SAML_BASE_URL" default:"http://localhost:8080"`
+	SuccessURL        string        `envconfig:"SAML_SUCCESS_URL" default:"http://localhost:3000/sso/callback"`
+	ClockSkew         time.Duration `envconfig:"SAML_CLOCK_SKEW" default:"2m"`
+	LoginStateTTL     time.Duration `envconfig:"SAML_LOGIN_STATE_TTL" default:"10m"`
+	AllowIdPInitiated bool          `envconfig:"SAML_ALLOW_IDP_INITIATED" default:"false"`
+}
+
+// QuotaConfig caps the requests of each user and OAuth client over rolling
+// windows. Plans maps plan names to semicolon-separated requests/window
+// limits, e.g. QUOTA_PLANS=free:60/1m;5000/24h,pro:600/1m;100000/24h;
+// callers without a plan are on DefaultPlan. Once a caller has used
+// SoftRatio of a limit, responses carry a warning header; 0 turns warnings
+// off. Counters are kept in "memory" (per instance) or "redis" (shared by
+// the instances).
+type QuotaConfig struct {
+	Enabled       bool              `envconfig:"QUOTA_ENABLED" default:"false"`
+	Plans         map[string]string `envconfig:"QUOTA_PLANS" default:"free:60/1m;5000/24h" secret:"false"`
+	DefaultPlan   string            `envconfig:"QUOTA_DEFAULT_PLAN" default:"free"`
+	SoftRatio     float64           `envconfig:"QUOTA_SOFT_RATIO" default:"0.8"`
+	Backend       string            `envconfig:"QUOTA_BACKEND" default:"memory"`
+	RedisAddr     string            `envconfig:"QUOTA_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword string            `envconfig:"QUOTA_REDIS_PASSWORD" secret:"true"`
+	RedisDB       int               `envconfig:"QUOTA_REDIS_DB" default:"0"`
+	RedisPrefix   string            `envconfig:"QUOTA_REDIS_PREFIX" default:"go-app:quota:"`
+}
+
+// GeoIPConfig points at a MaxMind DB file, a GeoIP2 or GeoLite2 City or
+// Country database, used to locate sign-ins, audit events and new-device
+// alerts; without DBPath only private addresses are recognized. The file is
+// checked for updates every ReloadInterval; 0 disables reloading.
+// ASNDBPath is a GeoLite2 ASN database, read the same way, that tells
+// sign-up and sign-in protection which network a client is on.
+// AllowCountries and DenyCountries, ISO country codes, restrict where the
+// API can be used from: requests from outside AllowCountries, when set, or
+// from DenyCountries are refused. They need DBPath.
+type GeoIPConfig struct {
+	DBPath         string        `envconfig:"GEOIP_DB_PATH"`
+	ASNDBPath      string        `envconfig:"GEOIP_ASN_DB_PATH"`
+	ReloadInterval time.Duration `envconfig:"GEOIP_RELOAD_INTERVAL" default:"1m"`
+	AllowCountries []string      `envconfig:"GEOIP_ALLOW_COUNTRIES"`
+	DenyCountries  []string      `envconfig:"GEOIP_DENY_COUNTRIES"`
+}
+
+// BillingConfig connects Stripe. Prices maps the plans for sale to Stripe
+// price IDs, e.g. BILLING_PRICES=pro:price_123,team:price_456. The webhook
+// endpoint /billing/webhook must be registered in Stripe with its signing
+// secret. Stripe is probed every HealthInterval for readiness; 0 disables
+// the probe.
+type BillingConfig struct {
+	Enabled             bool              `envconfig:"BILLING_ENABLED" default:"false"`
+	StripeSecretKey     string            `envconfig:"BILLING_STRIPE_SECRET_KEY" secret:"true"`
+	StripeWebhookSecret string            `envconfig:"BILLING_STRIPE_WEBHOOK_SECRET" secret:"true"`
+	WebhookTolerance    time.Duration     `envconfig:"BILLING_WEBHOOK_TOLERANCE" default:"5m"`
+	Timeout             time.Duration     `envconfig:"BILLING_TIMEOUT" default:"10s"`
+	HealthInterval      time.Duration     `envconfig:"BILLING_HEALTH_INTERVAL" default:"1m"`
+	Prices              map[string]string `envconfig:"BILLING_PRICES" secret:"false"`
+	SuccessURL          string            `envconfig:"BILLING_SUCCESS_URL" default:"http://localhost:3000/billing/success"`
+	CancelURL           string            `envconfig:"BILLING_CANCEL_URL" default:"http://localhost:3000/billing"`
+}
+
+// AnalyticsConfig selects where product events go: "none", "segment",
+// "kafka" (through a Kafka REST Proxy) or "postgres" (the analytics_events
+// table of the DB_* database, through the database/sql driver registered
+// under SQLDriver, pgx by default). Events are sent in batches of
+// BatchSize, at least every FlushInterval; once Buffer events wait, new
+// ones are dropped.
+type AnalyticsConfig struct {
+	Sink            string        `envconfig:"ANALYTICS_SINK" default:"none"`
+	Buffer          int           `envconfig:"ANALYTICS_BUFFER" default:"1000"`
+	BatchSize       int           `envconfig:"ANALYTICS_BATCH_SIZE" default:"100"`
+	FlushInterval   time.Duration `envconfig:"ANALYTICS_FLUSH_INTERVAL" default:"5s"`
+	Timeout         time.Duration `envconfig:"ANALYTICS_TIMEOUT" default:"10s"`
+	SegmentWriteKey string        `envconfig:"ANALYTICS_SEGMENT_WRITE_KEY" secret:"true"`
+	SegmentURL      string        `envconfig:"ANALYTICS_SEGMENT_URL" default:"https://api.segment.io"`
+	KafkaRESTURL    string        `envconfig:"ANALYTICS_KAFKA_REST_URL"`
+	KafkaTopic      string        `envconfig:"ANALYTICS_KAFKA_TOPIC" default:"analytics-events"`
+	SQLDriver       string        `envconfig:"ANALYTICS_SQL_DRIVER" default:"pgx"`
+}
+
+// JobsConfig selects where scheduled jobs are kept: "memory" (lost on
+// restart) or "postgres" (durable, in the scheduled_jobs table of the DB_*
+// database, through the database/sql driver registered under SQLDriver,
+// pgx by default). The leader polls for due jobs every PollInterval; a
+// failed run is retried after RetryBase, doubling up to RetryMax, until
+// MaxAttempts.
+type JobsConfig struct {
+	Backend      string        `envconfig:"JOBS_BACKEND" default:"memory"`
+	SQLDriver    string        `envconfig:"JOBS_SQL_DRIVER" default:"pgx"`
+	PollInterval time.Duration `envconfig:"JOBS_POLL_INTERVAL" default:"5s"`
+	BatchSize    int           `envconfig:"JOBS_BATCH_SIZE" default:"100"`
+	MaxAttempts  int           `envconfig:"JOBS_MAX_ATTEMPTS" default:"5"`
+	RetryBase    time.Duration `envconfig:"JOBS_RETRY_BASE" default:"30s"`
+	RetryMax     time.Duration `envconfig:"JOBS_RETRY_MAX" default:"1h"`
+}
+
+// PlanConfig ranks the plans for feature gating, lowest first, and maps
+// gated features to the lowest plan that includes them, e.g.
+// PLAN_FEATURES=usage_report:pro.
+type PlanConfig struct {
+	Tiers    []string          `envconfig:"PLAN_TIERS" default:"free,pro,team,enterprise"`
+	Features map[string]string `envconfig:"PLAN_FEATURES" secret:"false"`
+}
+
+// ImpersonationConfig sets how long an admin may act as another user before
+// having to start over.
+type ImpersonationConfig struct {
+	TTL time.Duration `envconfig:"IMPERSONATION_TTL" default:"15m"`
+}
+
+// TermsConfig names the current legal document versions. RequireAtSignUp
+// makes sign-up record acceptance of them; BlockUntilAccepted also locks the
+// API for users who have not accepted the latest versions.
+type TermsConfig struct {
+	Version            string `envconfig:"TERMS_VERSION" default:"1"`
+	PrivacyVersion     string `envconfig:"TERMS_PRIVACY_VERSION" default:"1"`
+	RequireAtSignUp    bool   `envconfig:"TERMS_REQUIRE_AT_SIGNUP" default:"false"`
+	BlockUntilAccepted bool   `envconfig:"TERMS_BLOCK_UNTIL_ACCEPTED" default:"false"`
 }
 
 func Load() (*Config, error) {
 	godotenv.Load()
+	if err := decryptEnv(); err != nil {
+		return nil, fmt.Errorf("decrypt config: %w", err)
+	}
 
 	var cfg Config
 
@@ -35,6 +658,150 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("DB", &cfg.DB); err != nil {
 		return nil, fmt.Errorf("load DB config: %w", err)
 	}
+	if err := envconfig.Process("USER_STORE", &cfg.UserStore); err != nil {
+		return nil, fmt.Errorf("load USER_STORE config: %w", err)
+	}
+	if err := envconfig.Process("USER_CACHE", &cfg.UserCache); err != nil {
+		return nil, fmt.Errorf("load USER_CACHE config: %w", err)
+	}
+	if err := envconfig.Process("SESSION_STORE", &cfg.Sessions); err != nil {
+		return nil, fmt.Errorf("load SESSION_STORE config: %w", err)
+	}
+	if err := envconfig.Process("ACCESS_LOG", &cfg.AccessLog); err != nil {
+		return nil, fmt.Errorf("load ACCESS_LOG config: %w", err)
+	}
+	if err := envconfig.Process("BODY_LOG", &cfg.BodyLog); err != nil {
+		return nil, fmt.Errorf("load BODY_LOG config: %w", err)
+	}
+	if err := envconfig.Process("LOG", &cfg.Log); err != nil {
+		return nil, fmt.Errorf("load LOG config: %w", err)
+	}
+	if err := envconfig.Process("SHADOW", &cfg.Shadow); err != nil {
+		return nil, fmt.Errorf("load SHADOW config: %w", err)
+	}
+	if err := envconfig.Process("FAULT", &cfg.Fault); err != nil {
+		return nil, fmt.Errorf("load FAULT config: %w", err)
+	}
+	if err := envconfig.Process("ROLLOUT", &cfg.Rollout); err != nil {
+		return nil, fmt.Errorf("load ROLLOUT config: %w", err)
+	}
+	if err := envconfig.Process("CONTRACT", &cfg.Contract); err != nil {
+		return nil, fmt.Errorf("load CONTRACT config: %w", err)
+	}
+	if err := envconfig.Process("MOCK", &cfg.Mock); err != nil {
+		return nil, fmt.Errorf("load MOCK config: %w", err)
+	}
+	if err := envconfig.Process("SEED", &cfg.Seed); err != nil {
+		return nil, fmt.Errorf("load SEED config: %w", err)
+	}
+	if err := envconfig.Process("CRASH", &cfg.Crash); err != nil {
+		return nil, fmt.Errorf("load CRASH config: %w", err)
+	}
+	if err := envconfig.Process("METRICS", &cfg.Metrics); err != nil {
+		return nil, fmt.Errorf("load METRICS config: %w", err)
+	}
+	if err := envconfig.Process("TRACE", &cfg.Trace); err != nil {
+		return nil, fmt.Errorf("load TRACE config: %w", err)
+	}
+	if err := envconfig.Process("RESILIENCE", &cfg.Resilience); err != nil {
+		return nil, fmt.Errorf("load RESILIENCE config: %w", err)
+	}
+	if err := envconfig.Process("READINESS", &cfg.Readiness); err != nil {
+		return nil, fmt.Errorf("load READINESS config: %w", err)
+	}
+	if err := envconfig.Process("WARMUP", &cfg.WarmUp); err != nil {
+		return nil, fmt.Errorf("load WARMUP config: %w", err)
+	}
+	if err := envconfig.Process("LEADER", &cfg.Leader); err != nil {
+		return nil, fmt.Errorf("load LEADER config: %w", err)
+	}
+	if err := envconfig.Process("EVENT_BUS", &cfg.EventBus); err != nil {
+		return nil, fmt.Errorf("load EVENT_BUS config: %w", err)
+	}
+	if err := envconfig.Process("DEDUPE", &cfg.Dedupe); err != nil {
+		return nil, fmt.Errorf("load DEDUPE config: %w", err)
+	}
+	if err := envconfig.Process("LOAD_SHED", &cfg.LoadShed); err != nil {
+		return nil, fmt.Errorf("load LOAD_SHED config: %w", err)
+	}
+	if err := envconfig.Process("ADMISSION", &cfg.Admission); err != nil {
+		return nil, fmt.Errorf("load ADMISSION config: %w", err)
+	}
+	if err := envconfig.Process("JWT", &cfg.JWT); err != nil {
+		return nil, fmt.Errorf("load JWT config: %w", err)
+	}
+	if err := envconfig.Process("ADMIN", &cfg.Admin); err != nil {
+		return nil, fmt.Errorf("load ADMIN config: %w", err)
+	}
+	if err := envconfig.Process("MAIL", &cfg.Mail); err != nil {
+		return nil, fmt.Errorf("load MAIL config: %w", err)
+	}
+	if err := envconfig.Process("DEVICE_ALERT", &cfg.Device); err != nil {
+		return nil, fmt.Errorf("load DEVICE_ALERT config: %w", err)
+	}
+	if err := envconfig.Process("SIGNUP", &cfg.SignUp); err != nil {
+		return nil, fmt.Errorf("load SIGNUP config: %w", err)
+	}
+	if err := envconfig.Process("SIGNUP_BOT", &cfg.SignUpBot); err != nil {
+		return nil, fmt.Errorf("load SIGNUP_BOT config: %w", err)
+	}
+	if err := envconfig.Process("COOKIE", &cfg.Cookie); err != nil {
+		return nil, fmt.Errorf("load COOKIE config: %w", err)
+	}
+	if err := envconfig.Process("CAPTCHA", &cfg.Captcha); err != nil {
+		return nil, fmt.Errorf("load CAPTCHA config: %w", err)
+	}
+	if err := envconfig.Process("PASSWORD_RESET", &cfg.Reset); err != nil {
+		return nil, fmt.Errorf("load PASSWORD_RESET config: %w", err)
+	}
+	if err := envconfig.Process("PASSWORD", &cfg.Password); err != nil {
+		return nil, fmt.Errorf("load PASSWORD config: %w", err)
+	}
+	if err := envconfig.Process("TERMS", &cfg.Terms); err != nil {
+		return nil, fmt.Errorf("load TERMS config: %w", err)
+	}
+	if err := envconfig.Process("EMAIL_CHANGE", &cfg.EmailChange); err != nil {
+		return nil, fmt.Errorf("load EMAIL_CHANGE config: %w", err)
+	}
+	if err := envconfig.Process("PHONE_OTP", &cfg.PhoneOTP); err != nil {
+		return nil, fmt.Errorf("load PHONE_OTP config: %w", err)
+	}
+	if err := envconfig.Process("GUEST", &cfg.Guest); err != nil {
+		return nil, fmt.Errorf("load GUEST config: %w", err)
+	}
+	if err := envconfig.Process("OIDC", &cfg.OIDC); err != nil {
+		return nil, fmt.Errorf("load OIDC config: %w", err)
+	}
+	if err := envconfig.Process("SAML", &cfg.SAML); err != nil {
+		return nil, fmt.Errorf("load SAML config: %w", err)
+	}
+	if err := envconfig.Process("IMPERSONATION", &cfg.Impersonate); err != nil {
+		return nil, fmt.Errorf("load IMPERSONATION config: %w", err)
+	}
+	if err := envconfig.Process("QUOTA", &cfg.Quota); err != nil {
+		return nil, fmt.Errorf("load QUOTA config: %w", err)
+	}
+	if err := envconfig.Process("GEOIP", &cfg.GeoIP); err != nil {
+		return nil, fmt.Errorf("load GEOIP config: %w", err)
+	}
+	if err := envconfig.Process("BILLING", &cfg.Billing); err != nil {
+		return nil, fmt.Errorf("load BILLING config: %w", err)
+	}
+	if err := envconfig.Process("ANALYTICS", &cfg.Analytics); err != nil {
+		return nil, fmt.Errorf("load ANALYTICS config: %w", err)
+	}
+	if err := envconfig.Process("PLAN", &cfg.Plan); err != nil {
+		return nil, fmt.Errorf("load PLAN config: %w", err)
+	}
+	if err := envconfig.Process("JOBS", &cfg.Jobs); err != nil {
+		return nil, fmt.Errorf("load JOBS config: %w", err)
+	}
+	if err := validateTokenTTLs(&cfg); err != nil {
+		return nil, err
+	}
+	if err := loadSections(&cfg); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
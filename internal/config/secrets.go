@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/envcrypt"
+)
+
+// The variables decryptEnv reads the decryption key from, first set first:
+// the key itself, a file holding it, e.g. mounted by a secret manager, or a
+// command printing it, e.g. a KMS decrypt of a wrapped key.
+const (
+	DECRYPTION_KEY_ENV         = "CONFIG_DECRYPTION_KEY"
+	DECRYPTION_KEY_FILE_ENV    = "CONFIG_DECRYPTION_KEY_FILE"
+	DECRYPTION_KEY_COMMAND_ENV = "CONFIG_DECRYPTION_KEY_COMMAND"
+)
+
+// decryptEnv replaces the sealed values of the environment, see
+// pkg/envcrypt, with their plaintext, so committed .env files never hold
+// secrets in the clear. The key is only needed, and only looked up, when a
+// value is sealed; it is removed from the environment afterwards.
+func decryptEnv() error {
+	var sealed []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if envcrypt.IsSealed(value) {
+			sealed = append(sealed, name)
+		}
+	}
+	if len(sealed) == 0 {
+		return nil
+	}
+
+	id, err := decryptionKey()
+	if err != nil {
+		return err
+	}
+	for _, name := range sealed {
+		plaintext, err := id.Open(os.Getenv(name))
+		if err != nil {
+			return fmt.Errorf("decrypt %s: %w", name, err)
+		}
+		os.Setenv(name, plaintext)
+	}
+	os.Unsetenv(DECRYPTION_KEY_ENV)
+	return nil
+}
+
+func decryptionKey() (*envcrypt.Identity, error) {
+	var key string
+	switch {
+	case os.Getenv(DECRYPTION_KEY_ENV) != "":
+		key = os.Getenv(DECRYPTION_KEY_ENV)
+	case os.Getenv(DECRYPTION_KEY_FILE_ENV) != "":
+		b, err := os.ReadFile(os.Getenv(DECRYPTION_KEY_FILE_ENV))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", DECRYPTION_KEY_FILE_ENV, err)
+		}
+		key = string(b)
+	case os.Getenv(DECRYPTION_KEY_COMMAND_ENV) != "":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "sh", "-c", os.Getenv(DECRYPTION_KEY_COMMAND_ENV))
+		cmd.Stderr = os.Stderr
+		b, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("run %s: %w", DECRYPTION_KEY_COMMAND_ENV, err)
+		}
+		key = string(b)
+	default:
+		return nil, fmt.Errorf("config has encrypted values but none of %s, %s and %s is set",
+			DECRYPTION_KEY_ENV, DECRYPTION_KEY_FILE_ENV, DECRYPTION_KEY_COMMAND_ENV)
+	}
+	return envcrypt.ParseIdentity(key)
+}
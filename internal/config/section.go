@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"text/template"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// A module declares its configuration as a section of its own rather than a
+// field of Config. A section is a struct loaded from the environment
+// variables under the module's prefix: fields are named after their
+// variable, with split_words, and take envconfig's default and required
+// tags and the secret tag Snapshot masks by, e.g.
+//
+//	type StatsConfig struct {
+//		RefreshInterval time.Duration `split_words:"true" default:"5m"`
+//	}
+//
+//	var statsConfig = config.RegisterSection[StatsConfig]("STATS")
+//
+// loads STATS_REFRESH_INTERVAL, and statsConfig.From(cfg) returns it once
+// Load has run. Unlike Config's sections, fields carry no envconfig name, so
+// nothing is read from outside the prefix.
+
+// Validator is implemented by sections with checks beyond envconfig's tags;
+// Load fails with the error Validate returns.
+type Validator interface {
+	Validate() error
+}
+
+// Section is a handle on a registered section.
+type Section[T any] struct {
+	prefix string
+}
+
+type registeredSection struct {
+	prefix string
+	load   func() (any, error)
+}
+
+var (
+	sectionsMu sync.Mutex
+	sections   []registeredSection
+)
+
+// RegisterSection registers the section T under prefix with Load. Call it
+// from a package-level variable so it runs before Load; registering a
+// prefix twice panics.
+func RegisterSection[T any](prefix string) Section[T] {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	for _, s := range sections {
+		if s.prefix == prefix {
+			panic(fmt.Sprintf("config: section %s registered twice", prefix))
+		}
+	}
+	sections = append(sections, registeredSection{
+		prefix: prefix,
+		load: func() (any, error) {
+			v := new(T)
+			if err := envconfig.Process(prefix, v); err != nil {
+				return nil, err
+			}
+			if val, ok := any(v).(Validator); ok {
+				if err := val.Validate(); err != nil {
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	})
+	return Section[T]{prefix: prefix}
+}
+
+// From returns the section as loaded into cfg. It panics if cfg was not
+// returned by Load after the section was registered.
+func (s Section[T]) From(cfg *Config) *T {
+	v, ok := cfg.sections[s.prefix].(*T)
+	if !ok {
+		panic(fmt.Sprintf("config: section %s not loaded", s.prefix))
+	}
+	return v
+}
+
+// loadSections loads every registered section into cfg.
+func loadSections(cfg *Config) error {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	cfg.sections = make(map[string]any, len(sections))
+	for _, s := range sections {
+		v, err := s.load()
+		if err != nil {
+			return fmt.Errorf("load %s config: %w", s.prefix, err)
+		}
+		cfg.sections[s.prefix] = v
+	}
+	return nil
+}
+
+// snapshotSections adds the variables of the loaded sections to out, keyed
+// as envconfig reads them.
+func (c *Config) snapshotSections(out map[string]any) {
+	for prefix, v := range c.sections {
+		tmpl := template.Must(template.New(prefix).Funcs(template.FuncMap{
+			"put": func(key string, tags reflect.StructTag, field reflect.Value) string {
+				out[key] = snapshotValue(tags, field)
+				return ""
+			},
+		}).Parse(`{{range .}}{{put .Key .Tags .Field}}{{end}}`))
+		envconfig.Usaget(prefix, v, io.Discard, tmpl)
+	}
+}
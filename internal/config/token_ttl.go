@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// tokenTTL bounds the lifetime of a kind of security token: long enough to
+// be usable, short enough that a leaked link or code is not good for long.
+type tokenTTL struct {
+	name     string
+	value    time.Duration
+	min, max time.Duration
+}
+
+// validateTokenTTLs checks the lifetimes of the tokens sent to users or
+// handed out on their behalf.
+func validateTokenTTLs(cfg *Config) error {
+	ttls := []tokenTTL{
+		{"JWT_ACCESS_TTL", cfg.JWT.AccessTTL, time.Minute, day},
+		{"JWT_REFRESH_TTL", cfg.JWT.RefreshTTL, cfg.JWT.AccessTTL, 365 * day},
+		{"PASSWORD_RESET_TTL", cfg.Reset.TTL, 5 * time.Minute, day},
+		{"EMAIL_CHANGE_TTL", cfg.EmailChange.TTL, 10 * time.Minute, 7 * day},
+		{"EMAIL_CHANGE_REVERT_WINDOW", cfg.EmailChange.RevertWindow, time.Hour, 30 * day},
+		{"PHONE_OTP_TTL", cfg.PhoneOTP.TTL, 30 * time.Second, 30 * time.Minute},
+		{"SIGNUP_INVITE_TTL", cfg.SignUp.InviteTTL, time.Hour, 90 * day},
+		{"DEVICE_ALERT_APPROVAL_TTL", cfg.Device.ApprovalTTL, 5 * time.Minute, 7 * day},
+		{"OIDC_CODE_TTL", cfg.OIDC.CodeTTL, 10 * time.Second, 10 * time.Minute},
+		{"OIDC_ID_TOKEN_TTL", cfg.OIDC.IDTokenTTL, time.Minute, day},
+		{"IMPERSONATION_TTL", cfg.Impersonate.TTL, time.Minute, day},
+	}
+	for _, t := range ttls {
+		if t.value < t.min || t.value > t.max {
+			return fmt.Errorf("%s must be between %s and %s, got %s", t.name, t.min, t.max, t.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// AccessTokenVerifier checks the signed access tokens the application
+// issues: user, delegated and client tokens, not refresh tokens.
+type AccessTokenVerifier interface {
+	// VerifyAccessToken returns errs.ErrInvalidToken for any token that is
+	// malformed, forged, expired or not an access token. It does not check
+	// that the session, user or client behind the token is still active.
+	VerifyAccessToken(ctx context.Context, token string) (*dto.AccessTokenClaims, error)
+}
@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type AccountRestoreRepository interface {
+	Create(ctx context.Context, a *entity.AccountRestore) (*entity.AccountRestore, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.AccountRestore, error)
+	// MarkUsed consumes the link, failing with ErrInvalidRestoreToken if it
+	// was already used.
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+	// ListExpired returns the links that expired before cutoff, used or not,
+	// oldest first.
+	ListExpired(ctx context.Context, cutoff time.Time) ([]*entity.AccountRestore, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
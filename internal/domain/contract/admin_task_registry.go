@@ -0,0 +1,17 @@
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// AdminTaskRegistry holds the operational tasks the modules let admins run
+// on demand. A task runs as a scheduled job of its name, by the handler its
+// module registered, so its progress and errors are those of the job.
+type AdminTaskRegistry interface {
+	AdminTasks() []dto.AdminTask
+	// ValidateAdminTask fails with ErrAdminTaskNotFound for an unknown task
+	// and with ErrInvalidAdminTaskParams for params it does not take.
+	ValidateAdminTask(name string, params json.RawMessage) error
+}
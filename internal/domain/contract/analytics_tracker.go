@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// AnalyticsTracker records product events for business analytics. Track
+// must not block or fail the caller; events that cannot be recorded are
+// dropped and counted.
+type AnalyticsTracker interface {
+	Track(ctx context.Context, e dto.AnalyticsEvent)
+}
@@ -0,0 +1,22 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// AuditLogRepository is append-only; events are never updated or deleted.
+// Events are ordered by creation time, then ID.
+type AuditLogRepository interface {
+	Append(ctx context.Context, e *entity.AuditEvent) (*entity.AuditEvent, error)
+	// List returns a page of the events matching filter, newest first, and
+	// the total number of them. A non-nil before starts the listing at the
+	// event after it, so pages stay stable while events are appended; offset
+	// and total then count from there.
+	List(ctx context.Context, filter dto.AuditLogFilter, before *dto.ExportCursor, limit, offset int) ([]*entity.AuditEvent, int, error)
+	// ListAfter returns up to limit events matching filter after the cursor,
+	// oldest first; a nil cursor starts from the oldest.
+	ListAfter(ctx context.Context, filter dto.AuditLogFilter, after *dto.ExportCursor, limit int) ([]*entity.AuditEvent, error)
+}
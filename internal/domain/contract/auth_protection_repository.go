@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// AuthProtectionRepository holds the override of the adaptive protection of
+// the credential endpoints; Get is called on every sign-up and sign-in, so
+// it must be cheap.
+type AuthProtectionRepository interface {
+	Get(ctx context.Context) (*entity.AuthProtection, error)
+	Set(ctx context.Context, p *entity.AuthProtection) (*entity.AuthProtection, error)
+}
@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, c *entity.AuthorizationCode) (*entity.AuthorizationCode, error)
+	// GetByCodeHash returns ErrInvalidGrant for unknown codes. Consumed
+	// codes are returned, with ConsumedAt set, until they are deleted.
+	GetByCodeHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error)
+	// Consume marks the code consumed at at, failing with ErrInvalidGrant if
+	// it is unknown or already was consumed.
+	Consume(ctx context.Context, codeHash string, at time.Time) error
+	// DeleteExpired deletes the codes that expired before cutoff and returns
+	// how many there were.
+	DeleteExpired(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// BadgePublisher pushes a user's unread notification count to their open
+// clients, so badges update without polling. It is best effort.
+type BadgePublisher interface {
+	PublishUnread(ctx context.Context, userID uuid.UUID, unread int)
+}
@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type BillingProvider interface {
+	// CreateCheckoutSession starts a hosted checkout for a subscription.
+	CreateCheckoutSession(ctx context.Context, input *dto.CheckoutSessionInput) (*dto.CheckoutSession, error)
+	// ParseWebhook verifies the signature of a webhook delivery and decodes
+	// it; errs.ErrInvalidWebhookSignature rejects forged or stale ones.
+	ParseWebhook(payload []byte, signature string) (*dto.BillingEvent, error)
+}
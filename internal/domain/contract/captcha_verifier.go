@@ -0,0 +1,9 @@
+package contract
+
+import "context"
+
+// CaptchaVerifier checks a CAPTCHA response token produced by the client-side
+// widget. It returns ErrCaptchaFailed when the token is rejected.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
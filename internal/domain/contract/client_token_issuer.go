@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type ClientTokenIssuer interface {
+	// IssueClientToken issues an access token for a machine caller, limited
+	// to scopes.
+	IssueClientToken(ctx context.Context, client *entity.OAuthClient, scopes []string) (*dto.ClientToken, error)
+}
@@ -0,0 +1,20 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// DeprecationUsageRepository keeps who still calls the deprecated routes and
+// fields. Record is called on every such request, so it must be cheap.
+type DeprecationUsageRepository interface {
+	// Declare lists d in the report, even before anyone uses it.
+	Declare(ctx context.Context, d *entity.Deprecation) error
+	// Record counts a use of d by client at at; first is true for the first
+	// use by that client.
+	Record(ctx context.Context, d *entity.Deprecation, client string, at time.Time) (first bool, err error)
+	List(ctx context.Context) ([]*dto.DeprecationUsage, error)
+}
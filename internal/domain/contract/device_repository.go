@@ -0,0 +1,22 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type KnownDeviceRepository interface {
+	IsKnown(ctx context.Context, userID uuid.UUID, fingerprint string) (bool, error)
+	// Remember records the device, refreshing its last seen details if it is
+	// already known.
+	Remember(ctx context.Context, d *entity.KnownDevice) error
+	Forget(ctx context.Context, userID uuid.UUID, fingerprint string) error
+}
+
+type DeviceApprovalRepository interface {
+	Create(ctx context.Context, a *entity.DeviceApproval) (*entity.DeviceApproval, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.DeviceApproval, error)
+	Update(ctx context.Context, a *entity.DeviceApproval) (*entity.DeviceApproval, error)
+}
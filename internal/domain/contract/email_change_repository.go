@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type EmailChangeRepository interface {
+	Create(ctx context.Context, c *entity.EmailChange) (*entity.EmailChange, error)
+	GetByConfirmHash(ctx context.Context, hash string) (*entity.EmailChange, error)
+	GetByRevertHash(ctx context.Context, hash string) (*entity.EmailChange, error)
+	// GetPendingByUser returns the user's latest pending change, expired or
+	// not, or ErrNoPendingEmailChange.
+	GetPendingByUser(ctx context.Context, userID uuid.UUID) (*entity.EmailChange, error)
+	// ListByUser returns the user's changes, newest first, whatever their
+	// status.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.EmailChange, error)
+	Update(ctx context.Context, c *entity.EmailChange) (*entity.EmailChange, error)
+}
@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type EmailQueueRepository interface {
+	Create(ctx context.Context, e *entity.OutboundEmail) (*entity.OutboundEmail, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.OutboundEmail, error)
+	// Due returns up to limit queued messages whose next attempt is at or
+	// before now, oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]*entity.OutboundEmail, error)
+	// List returns a page of messages in status, or in any status when it is
+	// empty, newest first, and the total number of matches.
+	List(ctx context.Context, status string, limit, offset int) ([]*entity.OutboundEmail, int, error)
+	Update(ctx context.Context, e *entity.OutboundEmail) (*entity.OutboundEmail, error)
+}
@@ -0,0 +1,25 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// EmailSuppressionRepository holds the addresses no mail is sent to. Get is
+// called before every send, so it must be cheap. Addresses are passed
+// normalized by entity.NormalizeSuppressedEmail.
+type EmailSuppressionRepository interface {
+	// Get fails with ErrEmailSuppressionNotFound for an address that is not
+	// suppressed.
+	Get(ctx context.Context, email string) (*entity.EmailSuppression, error)
+	// Add suppresses s.Email unless it already is, and returns the entry in
+	// force and whether it is s.
+	Add(ctx context.Context, s *entity.EmailSuppression) (*entity.EmailSuppression, bool, error)
+	// Remove fails with ErrEmailSuppressionNotFound for an address that is
+	// not suppressed.
+	Remove(ctx context.Context, email string) (*entity.EmailSuppression, error)
+	// List returns a page of the entries with reason, or with any reason
+	// when it is empty, newest first, and the total number of matches.
+	List(ctx context.Context, reason string, limit, offset int) ([]*entity.EmailSuppression, int, error)
+}
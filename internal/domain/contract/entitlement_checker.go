@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// EntitlementChecker tells use cases which paid plan a user is entitled to.
+type EntitlementChecker interface {
+	// ActivePlan returns the plan of the user's entitling subscription, or
+	// "" when they have none.
+	ActivePlan(ctx context.Context, userID uuid.UUID) (string, error)
+	// HasPlan reports whether the user is entitled to one of plans.
+	HasPlan(ctx context.Context, userID uuid.UUID, plans ...string) (bool, error)
+}
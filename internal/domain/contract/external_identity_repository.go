@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type ExternalIdentityRepository interface {
+	// Create returns ErrExternalIdentityExists when the issuer's subject is
+	// already linked.
+	Create(ctx context.Context, i *entity.ExternalIdentity) (*entity.ExternalIdentity, error)
+	// Get returns ErrExternalIdentityNotFound for subjects never linked.
+	Get(ctx context.Context, issuer, subject string) (*entity.ExternalIdentity, error)
+}
@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type GeoLocator interface {
+	Locate(ctx context.Context, ip string) (dto.GeoLocation, error)
+}
@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, i *entity.Invitation) (*entity.Invitation, error)
+	GetByCodeHash(ctx context.Context, codeHash string) (*entity.Invitation, error)
+	List(ctx context.Context) ([]*entity.Invitation, error)
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Redeem atomically consumes one use, failing with ErrInvalidInvite if the
+	// invitation is no longer redeemable for email.
+	Redeem(ctx context.Context, id uuid.UUID, email string, at time.Time) error
+	// Release gives back a use consumed by Redeem.
+	Release(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,9 @@
+package contract
+
+import "context"
+
+// IPReputation rates how likely an address is to be used for abuse, from 0
+// for no known risk to 100.
+type IPReputation interface {
+	Risk(ctx context.Context, ip string) (int, error)
+}
@@ -0,0 +1,27 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// JobScheduler defers work to a later time. Jobs are persisted, so they
+// survive restarts, and run on the leader by the handler registered for
+// their kind.
+type JobScheduler interface {
+	// Schedule stores a job of kind due at runAt, with payload encoded as
+	// JSON. A pending job with the same non-empty key is replaced, so
+	// scheduling again moves it.
+	Schedule(ctx context.Context, kind, key string, runAt time.Time, payload any) error
+	// Cancel cancels the pending job with key, if there is one. A job
+	// already running completes.
+	Cancel(ctx context.Context, key string) error
+}
+
+// JobHandler runs a scheduled job of one kind. A returned error is retried
+// with backoff until the job runs out of attempts, so handlers must be safe
+// to run again, and should check that the job still applies. An error
+// marked with retry.Permanent fails the job at once, and so does a panic,
+// which is recovered and kept in the job's error history.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
@@ -0,0 +1,19 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, a *entity.LoginAttempt) (*entity.LoginAttempt, error)
+	// ListByUser returns a page of the user's attempts, newest first, and the
+	// total number of attempts.
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.LoginAttempt, int, error)
+	// CountActiveUsers counts the distinct users with a successful attempt
+	// at or after each of since.
+	CountActiveUsers(ctx context.Context, since ...time.Time) ([]int, error)
+}
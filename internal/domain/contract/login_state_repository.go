@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// LoginStateRepository keeps the state of sign-ins in progress at external
+// identity providers until they expire.
+type LoginStateRepository interface {
+	Save(ctx context.Context, s *entity.LoginState) error
+	// Consume removes and returns the state, so a callback can only use it
+	// once. It returns ErrInvalidLoginState for unknown or expired states.
+	Consume(ctx context.Context, state string) (*entity.LoginState, error)
+}
@@ -0,0 +1,19 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// Mailer accepts outgoing email. It returns once the message is rendered
+// and queued; delivery happens in the background.
+type Mailer interface {
+	Send(ctx context.Context, email dto.Email) error
+}
+
+// MailTransport hands a message to the email provider right away. Only the
+// mail queue uses it; everything else sends through Mailer.
+type MailTransport interface {
+	Send(ctx context.Context, msg dto.EmailMessage) error
+}
@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// MaintenanceRepository holds the maintenance mode; Get is called on every
+// request, so it must be cheap.
+type MaintenanceRepository interface {
+	Get(ctx context.Context) (*entity.MaintenanceMode, error)
+	Set(ctx context.Context, m *entity.MaintenanceMode) (*entity.MaintenanceMode, error)
+}
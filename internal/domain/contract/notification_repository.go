@@ -0,0 +1,37 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type NotificationPreferencesRepository interface {
+	// Get returns ErrNotificationPreferencesNotFound for users who never
+	// saved preferences.
+	Get(ctx context.Context, userID uuid.UUID) (*entity.NotificationPreferences, error)
+	Upsert(ctx context.Context, p *entity.NotificationPreferences) (*entity.NotificationPreferences, error)
+}
+
+type InAppNotificationRepository interface {
+	Create(ctx context.Context, n *entity.InAppNotification) (*entity.InAppNotification, error)
+	// ListByUser returns a page of the user's notifications, newest first,
+	// and the total; unreadOnly leaves out the read ones.
+	ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*entity.InAppNotification, int, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	// MarkRead returns ErrNotificationNotFound when the notification is not
+	// the user's. Marking a read notification again keeps its ReadAt.
+	MarkRead(ctx context.Context, userID, id uuid.UUID, at time.Time) error
+	// MarkAllRead returns how many notifications it marked.
+	MarkAllRead(ctx context.Context, userID uuid.UUID, at time.Time) (int, error)
+}
+
+// PendingNotificationRepository holds notifications until they are due.
+type PendingNotificationRepository interface {
+	Add(ctx context.Context, n *entity.PendingNotification) error
+	// TakeDue removes and returns the notifications due at now, oldest
+	// first, up to limit.
+	TakeDue(ctx context.Context, now time.Time, limit int) ([]*entity.PendingNotification, error)
+}
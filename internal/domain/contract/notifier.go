@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// Notifier sends notifications about a user's account through the channels
+// the user chose, honoring their quiet hours and digest. Transactional
+// email, such as sign-in codes and confirmation links, goes through Mailer
+// instead.
+type Notifier interface {
+	Notify(ctx context.Context, n dto.Notification) error
+}
@@ -0,0 +1,18 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, c *entity.OAuthClient) (*entity.OAuthClient, error)
+	// GetByClientID returns ErrOAuthClientNotFound for unknown client IDs,
+	// revoked or not.
+	GetByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error)
+	List(ctx context.Context) ([]*entity.OAuthClient, error)
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
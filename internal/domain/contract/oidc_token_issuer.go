@@ -0,0 +1,23 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// OIDCTokenIssuer issues the tokens of the OpenID Connect code flow.
+type OIDCTokenIssuer interface {
+	// IssueDelegatedToken issues an access token for a relying party acting
+	// on the user's behalf. It is only accepted by the userinfo endpoint,
+	// not by the rest of the API.
+	IssueDelegatedToken(ctx context.Context, u *entity.User, code *entity.AuthorizationCode) (*dto.ClientToken, error)
+	// IssueIDToken issues the signed identity assertion for the relying
+	// party named by code.
+	IssueIDToken(ctx context.Context, u *entity.User, code *entity.AuthorizationCode) (string, error)
+	// Issuer is the issuer identifier published in discovery and ID tokens.
+	Issuer() string
+	// JWKS returns the public keys relying parties verify ID tokens with.
+	JWKS() *dto.JWKS
+}
@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// OrganizationSettingsRepository holds the settings of each tenant. Get is
+// called when the tenant's members sign in and on their every request, so
+// it must be cheap; it returns the zero settings of tenants that have none.
+type OrganizationSettingsRepository interface {
+	Get(ctx context.Context, tenant string) (*entity.OrganizationSettings, error)
+	Put(ctx context.Context, s *entity.OrganizationSettings) (*entity.OrganizationSettings, error)
+}
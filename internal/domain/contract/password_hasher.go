@@ -0,0 +1,13 @@
+package contract
+
+import "context"
+
+// PasswordHasher hashes and checks passwords. Both are CPU-bound, so
+// implementations may bound how many run at once and fail fast with
+// ErrPasswordHashingBusy instead of queueing without limit.
+type PasswordHasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	// Compare reports whether password matches hashed; a mismatch is not an
+	// error.
+	Compare(ctx context.Context, hashed, password string) (bool, error)
+}
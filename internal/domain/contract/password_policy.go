@@ -0,0 +1,11 @@
+package contract
+
+import "context"
+
+// PasswordPolicy vets a new password against the account it is for, beyond
+// the length and character class rules checked on the request.
+type PasswordPolicy interface {
+	// CheckPassword returns ErrWeakPassword when password is too easy to
+	// guess; attributes are the account's email and username.
+	CheckPassword(ctx context.Context, password string, attributes ...string) error
+}
@@ -0,0 +1,23 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, p *entity.PasswordReset) (*entity.PasswordReset, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordReset, error)
+	// MarkUsed consumes the reset, failing with ErrInvalidResetToken if it was
+	// already used.
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+	// ListByUser returns the user's resets, newest first, used and expired
+	// ones included.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.PasswordReset, error)
+	// DeleteExpired deletes the resets that expired before cutoff, used or
+	// not, and returns how many there were.
+	DeleteExpired(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,22 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, t *entity.PersonalAccessToken) (*entity.PersonalAccessToken, error)
+	// GetByHash returns ErrPersonalTokenNotFound for unknown hashes, revoked
+	// or not.
+	GetByHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PersonalAccessToken, error)
+	// ListByUser returns the user's tokens, newest first, revoked ones
+	// included.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.PersonalAccessToken, error)
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
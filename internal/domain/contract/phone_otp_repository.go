@@ -0,0 +1,24 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type PhoneOTPRepository interface {
+	Create(ctx context.Context, o *entity.PhoneOTP) (*entity.PhoneOTP, error)
+	// Latest returns the most recent code for phone and purpose, or
+	// ErrInvalidOTP when none was issued.
+	Latest(ctx context.Context, phone string, purpose entity.OTPPurpose) (*entity.PhoneOTP, error)
+	// CountSince counts the codes issued to phone, for any purpose, since the
+	// given time.
+	CountSince(ctx context.Context, phone string, since time.Time) (int, error)
+	// RecordAttempt increments the attempt counter and returns its new value.
+	RecordAttempt(ctx context.Context, id uuid.UUID) (int, error)
+	// Consume marks the code used, failing with ErrInvalidOTP if it already
+	// was.
+	Consume(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,18 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PlanGate lets endpoints and use cases declare the plan tier they need.
+// Both methods return an *errs.UpgradeRequiredError when the user's plan is
+// below it.
+type PlanGate interface {
+	// RequirePlan passes users on plan or any higher tier.
+	RequirePlan(ctx context.Context, userID uuid.UUID, plan string) error
+	// RequireFeature passes users whose plan includes feature. Features
+	// without a configured plan are open to everyone.
+	RequireFeature(ctx context.Context, userID uuid.UUID, feature string) error
+}
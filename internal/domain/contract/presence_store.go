@@ -0,0 +1,25 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// PresenceStore tracks the live connections of users, each kept alive by
+// heartbeats until it expires. A user is online while any of their
+// connections is.
+type PresenceStore interface {
+	// Beat keeps conn of the user alive until expiresAt, reporting whether
+	// the user had no live connection before.
+	Beat(ctx context.Context, userID uuid.UUID, conn string, now, expiresAt time.Time) (cameOnline bool, err error)
+	// Leave drops conn, reporting whether the user has no live connection
+	// left.
+	Leave(ctx context.Context, userID uuid.UUID, conn string, now time.Time) (wentOffline bool, err error)
+	Get(ctx context.Context, userID uuid.UUID, now time.Time) (*dto.Presence, error)
+	// Expire forgets up to limit users whose connections all expired by
+	// now, without a Leave, and returns them.
+	Expire(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
@@ -0,0 +1,25 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type SAMLConnectionRepository interface {
+	// Create returns ErrSAMLConnectionExists when the tenant already has one.
+	Create(ctx context.Context, c *entity.SAMLConnection) (*entity.SAMLConnection, error)
+	GetByTenant(ctx context.Context, tenant string) (*entity.SAMLConnection, error)
+	// GetByTenants looks up the connections of many tenants at once. Tenants
+	// without one are left out of the map.
+	GetByTenants(ctx context.Context, tenants []string) (map[string]*entity.SAMLConnection, error)
+	List(ctx context.Context) ([]*entity.SAMLConnection, error)
+	Update(ctx context.Context, c *entity.SAMLConnection) (*entity.SAMLConnection, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ConsumeAssertion records an assertion ID until it expires and returns
+	// ErrInvalidSAMLResponse when it was already seen, so a captured
+	// response cannot be replayed.
+	ConsumeAssertion(ctx context.Context, assertionID string, expiresAt time.Time) error
+}
@@ -0,0 +1,24 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// SAMLServiceProvider speaks the SAML 2.0 web browser SSO profile on behalf
+// of a tenant's connection.
+type SAMLServiceProvider interface {
+	// Metadata returns the service provider metadata document to register
+	// with the identity provider.
+	Metadata(conn *entity.SAMLConnection) ([]byte, error)
+	// LoginURL returns the identity provider URL carrying a new
+	// AuthnRequest, per the HTTP-Redirect binding, and the request's ID,
+	// which the response must be in response to.
+	LoginURL(conn *entity.SAMLConnection, relayState string) (_, requestID string, err error)
+	// ParseResponse verifies a base64 SAMLResponse posted to the assertion
+	// consumer service: signature, issuer, audience, recipient and validity
+	// window. It returns ErrInvalidSAMLResponse for anything unacceptable.
+	ParseResponse(ctx context.Context, conn *entity.SAMLConnection, encoded string) (*dto.SAMLAssertion, error)
+}
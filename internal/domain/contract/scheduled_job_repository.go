@@ -0,0 +1,33 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type ScheduledJobRepository interface {
+	// Schedule stores j, canceling the pending job with the same non-empty
+	// key, if any.
+	Schedule(ctx context.Context, j *entity.ScheduledJob) (*entity.ScheduledJob, error)
+	// CancelByKey cancels the pending job with key and reports whether there
+	// was one.
+	CancelByKey(ctx context.Context, key string, now time.Time) (bool, error)
+	// Due returns up to limit pending jobs whose RunAt is at or before now,
+	// earliest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]*entity.ScheduledJob, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduledJob, error)
+	// GetPendingByKey returns ErrScheduledJobNotFound when no job with key
+	// is pending.
+	GetPendingByKey(ctx context.Context, key string) (*entity.ScheduledJob, error)
+	// List returns a page of jobs in status, of kind unless it is empty,
+	// most recently updated first, and the total number of matches.
+	List(ctx context.Context, status, kind string, limit, offset int) ([]*entity.ScheduledJob, int, error)
+	Update(ctx context.Context, j *entity.ScheduledJob) (*entity.ScheduledJob, error)
+	// Stats counts the jobs of each kind, by kind: the backlog at now and
+	// the jobs finished since. Rates are left to the caller.
+	Stats(ctx context.Context, since, now time.Time) ([]dto.JobKindStats, error)
+}
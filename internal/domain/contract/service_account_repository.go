@@ -0,0 +1,32 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, a *entity.ServiceAccount) (*entity.ServiceAccount, error)
+	// GetByID returns deleted accounts too, or ErrServiceAccountNotFound.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ServiceAccount, error)
+	// List returns the tenant's accounts, or every tenant's when tenant is
+	// empty, oldest first, deleted ones excluded.
+	List(ctx context.Context, tenant string) ([]*entity.ServiceAccount, error)
+	Update(ctx context.Context, a *entity.ServiceAccount) (*entity.ServiceAccount, error)
+}
+
+type ServiceAccountKeyRepository interface {
+	Create(ctx context.Context, k *entity.ServiceAccountKey) (*entity.ServiceAccountKey, error)
+	// GetByHash returns ErrServiceAccountKeyNotFound for unknown hashes,
+	// revoked or not.
+	GetByHash(ctx context.Context, hash string) (*entity.ServiceAccountKey, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ServiceAccountKey, error)
+	// ListByAccount returns the account's keys, newest first, revoked ones
+	// included.
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]*entity.ServiceAccountKey, error)
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
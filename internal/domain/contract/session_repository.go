@@ -0,0 +1,27 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, s *entity.Session) (*entity.Session, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+	// ListActiveByUsers lists the active sessions of many users at once, for
+	// lists that would otherwise call ListActiveByUser per row. Users
+	// without sessions are left out of the map.
+	ListActiveByUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]*entity.Session, error)
+	Update(ctx context.Context, s *entity.Session) (*entity.Session, error)
+	// Touch records activity on the session from ip.
+	Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID, at time.Time) (int, error)
+	// DeleteExpired deletes the sessions that expired before cutoff and
+	// returns how many there were.
+	DeleteExpired(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,26 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// SignUpPolicy vets a registration before the account is created. Returning
+// an error rejects the sign-up.
+type SignUpPolicy interface {
+	Check(ctx context.Context, input *dto.SignUpInput) error
+}
+
+// SignUpHook is implemented by policies that must also act once the account
+// exists, such as consuming an invitation.
+type SignUpHook interface {
+	AfterSignUp(ctx context.Context, input *dto.SignUpInput, u *entity.User) error
+}
+
+// SignUpCompensator is implemented by hooks whose AfterSignUp can be undone,
+// for when a later sign-up step fails and the account is rolled back.
+type SignUpCompensator interface {
+	UndoSignUp(ctx context.Context, input *dto.SignUpInput, u *entity.User) error
+}
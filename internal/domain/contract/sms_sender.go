@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type SMSSender interface {
+	Send(ctx context.Context, msg dto.SMSMessage) error
+}
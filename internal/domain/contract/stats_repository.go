@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// StatsRepository stores the stats views, so every instance serves the
+// aggregates the leader last computed.
+type StatsRepository interface {
+	// Get returns ErrStatsViewNotFound until the view is first stored.
+	Get(ctx context.Context, name string) (*entity.StatsView, error)
+	// Put stores the view, replacing the one of the same name.
+	Put(ctx context.Context, v *entity.StatsView) error
+}
@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type SubscriptionRepository interface {
+	// Upsert creates or replaces the subscription with s.ProviderID.
+	Upsert(ctx context.Context, s *entity.Subscription) (*entity.Subscription, error)
+	GetByProviderID(ctx context.Context, providerID string) (*entity.Subscription, error)
+	// ListByUser returns the user's subscriptions, newest first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Subscription, error)
+}
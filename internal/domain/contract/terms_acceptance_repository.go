@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type TermsAcceptanceRepository interface {
+	Create(ctx context.Context, a *entity.TermsAcceptance) (*entity.TermsAcceptance, error)
+	// Latest returns the user's most recent acceptance, or ErrTermsNotAccepted.
+	Latest(ctx context.Context, userID uuid.UUID) (*entity.TermsAcceptance, error)
+}
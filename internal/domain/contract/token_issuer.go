@@ -0,0 +1,20 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type TokenIssuer interface {
+	// IssueTokens issues an access/refresh pair bound to sessionID.
+	IssueTokens(ctx context.Context, u *entity.User, sessionID uuid.UUID) (*dto.AuthTokens, error)
+	// VerifyRefreshToken returns errs.ErrInvalidToken for any unusable token.
+	VerifyRefreshToken(ctx context.Context, token string) (*dto.RefreshTokenClaims, error)
+	// IssueImpersonationToken issues an access token for u, marked as used
+	// by actorID, that expires after ttl.
+	IssueImpersonationToken(ctx context.Context, u *entity.User, actorID, sessionID uuid.UUID, ttl time.Duration) (*dto.ImpersonationToken, error)
+}
@@ -0,0 +1,13 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// UsageMeter emits metered usage. Record must not block or fail the caller;
+// usage that cannot be emitted is dropped and logged.
+type UsageMeter interface {
+	Record(ctx context.Context, e dto.UsageEvent)
+}
@@ -0,0 +1,20 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// UsageRepository stores usage aggregated per user, metric and period.
+type UsageRepository interface {
+	// Add increases the period's quantity by delta.
+	Add(ctx context.Context, userID uuid.UUID, metric string, periodStart time.Time, delta int64) error
+	// RaiseTo sets the period's quantity to value when it is higher.
+	RaiseTo(ctx context.Context, userID uuid.UUID, metric string, periodStart time.Time, value int64) error
+	ListByUser(ctx context.Context, userID uuid.UUID, periodStart time.Time) ([]*entity.UsageRecord, error)
+	// ListByPeriod returns every user's records, ordered by user and metric.
+	ListByPeriod(ctx context.Context, periodStart time.Time) ([]*entity.UsageRecord, error)
+}
@@ -0,0 +1,19 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// UserQuery serves the user read paths, kept apart from UserRepository so
+// heavy listings do not contend with transactional writes. It may lag
+// behind recent writes.
+type UserQuery interface {
+	// List returns a page of matching users, newest first, and the total
+	// number of matches.
+	List(ctx context.Context, filter dto.UserFilter, limit, offset int) ([]*dto.UserSummary, int, error)
+	// ListAfter returns up to limit matching users after the cursor, oldest
+	// first; a nil cursor starts from the oldest.
+	ListAfter(ctx context.Context, filter dto.UserFilter, after *dto.ExportCursor, limit int) ([]*dto.UserSummary, error)
+}
@@ -3,9 +3,24 @@ package contract
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/haidang666/go-app/internal/domain/entity"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, u *entity.User) (*entity.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	// GetByIDs looks up many users at once, for lists that would otherwise
+	// call GetByID per row. Users that do not exist are left out of the
+	// map rather than failing the lookup.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error)
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetByUsername(ctx context.Context, username string) (*entity.User, error)
+	// GetByPhone matches verified phone numbers only.
+	GetByPhone(ctx context.Context, phone string) (*entity.User, error)
+	Update(ctx context.Context, u *entity.User) (*entity.User, error)
+	// Delete removes the user outright. It undoes a sign-up that failed
+	// partway and purges the accounts whose recovery window ended; users
+	// close their accounts by setting DeletedAt instead.
+	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeleteAccountInput struct {
+	UserID uuid.UUID
+	// Password re-authenticates the user; accounts without a usable password
+	// leave it empty, and the session SessionID must have signed in
+	// recently instead.
+	Password  string
+	SessionID uuid.UUID
+}
+
+// AccountDeletion tells a user who deleted their account until when it can
+// be restored.
+type AccountDeletion struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// AdminTask is an operational task admins may run on demand, such as
+// purging expired tokens. It runs as a scheduled job whose kind is Name.
+type AdminTask struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Params describes the params the task takes, if any.
+	Params string `json:"params,omitempty"`
+}
+
+type TriggerAdminTaskInput struct {
+	ActorID uuid.UUID
+	Name    string
+	// Params is the JSON object the task runs with; empty means none.
+	Params json.RawMessage
+}
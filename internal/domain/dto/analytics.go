@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The product events tracked for business analytics.
+const (
+	ANALYTICS_SIGN_UP_COMPLETED = "sign_up_completed"
+	ANALYTICS_LOGIN_FAILED      = "login_failed"
+	ANALYTICS_PROFILE_UPDATED   = "profile_updated"
+)
+
+// AnalyticsEvent is one product event with its user and tenant dimensions.
+// UserID is uuid.Nil for events without a known user, such as a failed
+// login with an unknown email. Properties must not hold personal data.
+type AnalyticsEvent struct {
+	Name       string         `json:"event"`
+	UserID     uuid.UUID      `json:"user_id"`
+	TenantID   string         `json:"tenant_id,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
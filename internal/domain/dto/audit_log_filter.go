@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogFilter narrows an audit log listing; zero fields match everything.
+type AuditLogFilter struct {
+	// UserID matches the events where the user is either the actor or the
+	// subject.
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	SubjectID uuid.UUID
+	// Actions matches any of the AUDIT_ actions listed.
+	Actions []string
+	// From is inclusive and To exclusive.
+	From *time.Time
+	To   *time.Time
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuthTokens struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+	// RefreshTokenID is the unique ID of the refresh token, stored on the
+	// session to detect reuse of rotated tokens.
+	RefreshTokenID string `json:"-"`
+}
+
+// RefreshTokenClaims is what a verified refresh token says about its holder.
+type RefreshTokenClaims struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	TokenID   string
+}
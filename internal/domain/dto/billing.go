@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Billing event types the subscription sync acts on.
+const (
+	BILLING_EVENT_SUBSCRIPTION_CREATED = "customer.subscription.created"
+	BILLING_EVENT_SUBSCRIPTION_UPDATED = "customer.subscription.updated"
+	BILLING_EVENT_SUBSCRIPTION_DELETED = "customer.subscription.deleted"
+)
+
+type CheckoutSessionInput struct {
+	UserID uuid.UUID
+	// CustomerID reuses the provider customer of an earlier subscription;
+	// otherwise Email pre-fills a new one.
+	CustomerID string
+	Email      string
+	PriceID    string
+	SuccessURL string
+	CancelURL  string
+}
+
+type CheckoutSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// BillingEvent is a verified webhook delivery. Subscription is set for the
+// subscription event types.
+type BillingEvent struct {
+	ID           string
+	Type         string
+	CreatedAt    time.Time
+	Subscription *ProviderSubscription
+}
+
+// ProviderSubscription is the provider's view of a subscription. UserID
+// comes from the metadata set at checkout and is uuid.Nil for
+// subscriptions created elsewhere.
+type ProviderSubscription struct {
+	ID                string
+	CustomerID        string
+	UserID            uuid.UUID
+	PriceID           string
+	Status            string
+	CurrentPeriodEnd  time.Time
+	CancelAtPeriodEnd bool
+}
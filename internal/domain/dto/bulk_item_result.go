@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/haidang666/go-app/internal/domain/entity"
+
+const (
+	BULK_ITEM_SUCCEEDED = "succeeded"
+	BULK_ITEM_FAILED    = "failed"
+)
+
+// BulkItemError is a typed failure for a single item of a bulk operation.
+type BulkItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type BulkItemResult struct {
+	Index  int            `json:"index"`
+	Status string         `json:"status"`
+	User   *entity.User   `json:"user,omitempty"`
+	Error  *BulkItemError `json:"error,omitempty"`
+}
+
+// BulkInput carries one item of a bulk operation. Err is set when the item was
+// already rejected by request validation and must not be executed.
+type BulkInput[T any] struct {
+	Input T
+	Err   error
+}
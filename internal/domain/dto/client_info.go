@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/haidang666/go-app/pkg/clientinfo"
+
+// ClientInfo describes the device a request comes from.
+type ClientInfo struct {
+	IP                string
+	UserAgent         string
+	DeviceFingerprint string
+	// Device is parsed from the User-Agent and Client Hints.
+	Device clientinfo.Info
+}
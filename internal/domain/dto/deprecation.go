@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/haidang666/go-app/internal/domain/entity"
+
+// DeprecationUsage is who still uses a deprecation, busiest clients first.
+// Deprecations nobody used have no clients.
+type DeprecationUsage struct {
+	entity.Deprecation
+	Requests int64                      `json:"requests"`
+	Clients  []entity.DeprecationClient `json:"clients"`
+	// OtherRequests counts the requests of the clients beyond those tracked.
+	OtherRequests int64 `json:"other_requests,omitempty"`
+}
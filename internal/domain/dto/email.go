@@ -0,0 +1,37 @@
+package dto
+
+// Email templates, with the Data keys each one uses.
+const (
+	// EMAIL_WELCOME uses Email.
+	EMAIL_WELCOME = "welcome"
+	// EMAIL_PASSWORD_RESET uses Link and TTL.
+	EMAIL_PASSWORD_RESET = "password_reset"
+	// EMAIL_CHANGE_CONFIRM uses Link and TTL.
+	EMAIL_CHANGE_CONFIRM = "email_change_confirm"
+	// EMAIL_CHANGE_REQUESTED uses NewEmail and RevertLink.
+	EMAIL_CHANGE_REQUESTED = "email_change_requested"
+	// EMAIL_CHANGED uses NewEmail, RevertLink and RevertUntil.
+	EMAIL_CHANGED = "email_changed"
+	// EMAIL_NEW_DEVICE uses Device, UserAgent, IP, Location, Time, ApproveLink
+	// and DenyLink.
+	EMAIL_NEW_DEVICE = "new_device"
+	// EMAIL_IMPERSONATION uses Time, Reason and Until.
+	EMAIL_IMPERSONATION = "impersonation"
+	// EMAIL_ACCOUNT_DELETED uses Link and PurgeAt.
+	EMAIL_ACCOUNT_DELETED = "account_deleted"
+	// EMAIL_DIGEST uses Items, each with a Subject and Content, which the
+	// mailer fills from Email.Items.
+	EMAIL_DIGEST = "digest"
+)
+
+// Email is an outgoing email before rendering: Template is one of the
+// EMAIL_* templates and Data fills it. It is rendered in the locale of the
+// request that sends it.
+type Email struct {
+	To       string
+	Template string
+	Data     map[string]any
+	// Items are the emails an EMAIL_DIGEST collects; their recipients are
+	// ignored.
+	Items []Email
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/google/uuid"
+
+type RequestEmailChangeInput struct {
+	UserID   uuid.UUID
+	NewEmail string
+	// Password re-authenticates the user before the change is started.
+	Password string
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/google/uuid"
+
+type EmailMessage struct {
+	// ID is set by the mail queue and passed to the provider, so its bounce
+	// and complaint webhooks can refer back to the message.
+	ID      uuid.UUID
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportCursor is a position in an export, which walks rows oldest first by
+// creation time, then ID. Continuation tokens carry it so an interrupted
+// export can resume after the last row received; listings that page newest
+// first use it the other way round.
+type ExportCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Precedes reports whether a row created at createdAt with id comes after
+// the cursor. A nil cursor precedes every row.
+func (c *ExportCursor) Precedes(createdAt time.Time, id uuid.UUID) bool {
+	if c == nil {
+		return true
+	}
+	if cmp := createdAt.Compare(c.CreatedAt); cmp != 0 {
+		return cmp > 0
+	}
+	return id.String() > c.ID.String()
+}
+
+// Follows reports whether a row created at createdAt with id comes before
+// the cursor. A nil cursor follows every row.
+func (c *ExportCursor) Follows(createdAt time.Time, id uuid.UUID) bool {
+	if c == nil {
+		return true
+	}
+	if cmp := createdAt.Compare(c.CreatedAt); cmp != 0 {
+		return cmp < 0
+	}
+	return id.String() < c.ID.String()
+}
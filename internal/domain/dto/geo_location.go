@@ -0,0 +1,23 @@
+package dto
+
+type GeoLocation struct {
+	// CountryCode is the ISO 3166-1 alpha-2 code; Country and City are names
+	// in the request's locale where the database has them.
+	CountryCode string `json:"country_code,omitempty"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+	// ASN is the autonomous system announcing the address, from a GeoLite2
+	// ASN or GeoIP2 ISP database.
+	ASN uint32 `json:"asn,omitempty"`
+}
+
+// String returns "City, Country", or as much of it as is known.
+func (g GeoLocation) String() string {
+	switch {
+	case g.City != "" && g.Country != "":
+		return g.City + ", " + g.Country
+	case g.Country != "":
+		return g.Country
+	}
+	return g.City
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of identity an account can sign in with.
+const (
+	// IDENTITY_PASSWORD is the account's email and password.
+	IDENTITY_PASSWORD = "password"
+	// IDENTITY_PHONE is a phone number signed in to with texted codes.
+	IDENTITY_PHONE = "phone"
+	// IDENTITY_SAML is the identity provider of the account's tenant.
+	IDENTITY_SAML = "saml"
+)
+
+// Identity is a way to sign in to an account. Usable is false while it
+// cannot be signed in with yet, such as a phone number awaiting
+// verification.
+type Identity struct {
+	Type       string     `json:"type"`
+	Identifier string     `json:"identifier"`
+	Usable     bool       `json:"usable"`
+	LinkedAt   *time.Time `json:"linked_at,omitempty"`
+	// Managed identities are linked by the organization and cannot be
+	// unlinked by the user.
+	Managed bool `json:"managed,omitempty"`
+}
+
+// LinkIdentityInput adds an identity to an account: a phone number, which
+// must then be verified, or a password for an account without one.
+// CurrentPassword re-authenticates the user when the account has one;
+// otherwise the session SessionID must have signed in recently.
+type LinkIdentityInput struct {
+	Type            string
+	Phone           string
+	Password        string
+	CurrentPassword string
+	SessionID       uuid.UUID
+}
+
+// UnlinkIdentityInput removes the identity of type Type from an account.
+// Password re-authenticates the user when the account has one; otherwise
+// the session SessionID must have signed in recently.
+type UnlinkIdentityInput struct {
+	Type      string
+	Password  string
+	SessionID uuid.UUID
+}
+
+// ExternalIdentityClaims are what a verified access token of a trusted
+// external issuer says about its subject. Email is only trusted when
+// EmailVerified is set.
+type ExternalIdentityClaims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+}
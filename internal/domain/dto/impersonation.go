@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImpersonateInput struct {
+	ActorID  uuid.UUID
+	TargetID uuid.UUID
+	Reason   string
+	Client   ClientInfo
+}
+
+// ImpersonationToken is a short-lived access token acting as another user.
+// It cannot be refreshed.
+type ImpersonationToken struct {
+	AccessToken     string    `json:"access_token"`
+	AccessExpiresAt time.Time `json:"access_expires_at"`
+	TokenType       string    `json:"token_type"`
+	SessionID       uuid.UUID `json:"session_id"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of token a resource server may be handed.
+const (
+	TOKEN_KIND_ACCESS    = "access"
+	TOKEN_KIND_DELEGATED = "delegated"
+	TOKEN_KIND_CLIENT    = "client"
+	TOKEN_KIND_PERSONAL  = "personal"
+	// TOKEN_KIND_SERVICE_ACCOUNT is an organization's service account key.
+	TOKEN_KIND_SERVICE_ACCOUNT = "service_account"
+)
+
+// AccessTokenClaims is what a verified access token, of any kind but
+// personal and service account, says about its holder.
+type AccessTokenClaims struct {
+	Kind string
+	// Subject is the user ID, or the client ID of client tokens.
+	Subject string
+	// ClientID names the relying party of delegated tokens.
+	ClientID  string
+	SessionID uuid.UUID
+	Scopes    []string
+	Roles     []string
+	TenantID  string
+	// ActorID is the impersonating user of impersonation tokens.
+	ActorID   string
+	TokenID   string
+	Issuer    string
+	Audience  []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// IntrospectionInput is a POST /oauth/introspect request.
+type IntrospectionInput struct {
+	ClientID     string
+	ClientSecret string
+	Token        string
+}
+
+// Introspection is the RFC 7662 introspection response. Only Active is set
+// for a token that is not active, whatever the reason. TokenKind, Roles,
+// TenantID and Actor are extensions.
+type Introspection struct {
+	Active    bool                `json:"active"`
+	Scope     string              `json:"scope,omitempty"`
+	ClientID  string              `json:"client_id,omitempty"`
+	Username  string              `json:"username,omitempty"`
+	TokenType string              `json:"token_type,omitempty"`
+	ExpiresAt int64               `json:"exp,omitempty"`
+	IssuedAt  int64               `json:"iat,omitempty"`
+	Subject   string              `json:"sub,omitempty"`
+	Audience  []string            `json:"aud,omitempty"`
+	Issuer    string              `json:"iss,omitempty"`
+	TokenID   string              `json:"jti,omitempty"`
+	TokenKind string              `json:"token_kind,omitempty"`
+	Roles     []string            `json:"roles,omitempty"`
+	TenantID  string              `json:"tid,omitempty"`
+	Actor     *IntrospectionActor `json:"act,omitempty"`
+}
+
+// IntrospectionActor is the RFC 8693 "act" claim of impersonation tokens.
+type IntrospectionActor struct {
+	Subject string `json:"sub"`
+}
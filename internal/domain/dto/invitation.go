@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type MintInvitationInput struct {
+	Email     string
+	MaxUses   int
+	TTL       time.Duration
+	CreatedBy uuid.UUID
+}
+
+// MintedInvitation carries the plain code, which is only available at mint
+// time.
+type MintedInvitation struct {
+	*entity.Invitation
+	Code string `json:"code"`
+}
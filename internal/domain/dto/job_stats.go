@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// JobKindStats describes the scheduled jobs of one kind: the backlog now,
+// and what was processed within the window.
+type JobKindStats struct {
+	Kind string `json:"kind"`
+	// Pending counts the jobs waiting, Due those of them already due and
+	// Retrying those that failed at least one run.
+	Pending  int `json:"pending"`
+	Due      int `json:"due"`
+	Retrying int `json:"retrying"`
+	// Dead counts the jobs that failed for good and wait in the dead
+	// letters.
+	Dead int `json:"dead"`
+	// OldestDueSeconds is how long the earliest due job has been waiting.
+	OldestDueSeconds float64 `json:"oldest_due_seconds"`
+
+	// Done and Failed count the jobs finished within the window.
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+	// DonePerMinute is the processing rate over the window, and FailureRatio
+	// the share of the finished jobs that failed.
+	DonePerMinute float64 `json:"done_per_minute"`
+	FailureRatio  float64 `json:"failure_ratio"`
+	// AvgLatencySeconds and MaxLatencySeconds measure how long after being
+	// due the jobs done within the window finished.
+	AvgLatencySeconds float64 `json:"avg_latency_seconds"`
+	MaxLatencySeconds float64 `json:"max_latency_seconds"`
+}
+
+// JobQueueStats is the state of the scheduled job queue, by kind.
+type JobQueueStats struct {
+	Window      string         `json:"window"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Kinds       []JobKindStats `json:"kinds"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// Mail feedback event types reported by the email provider's webhook.
+const (
+	MAIL_FEEDBACK_BOUNCE    = "bounce"
+	MAIL_FEEDBACK_COMPLAINT = "complaint"
+	// MAIL_FEEDBACK_UNSUBSCRIBE is a recipient who used the unsubscribe
+	// link or List-Unsubscribe header of a message.
+	MAIL_FEEDBACK_UNSUBSCRIBE = "unsubscribe"
+)
+
+// Bounce types of the bounce events.
+const (
+	// MAIL_BOUNCE_HARD is permanent, such as an unknown mailbox; the
+	// address is suppressed. Bounces without a type count as hard.
+	MAIL_BOUNCE_HARD = "hard"
+	// MAIL_BOUNCE_SOFT is transient, such as a full mailbox.
+	MAIL_BOUNCE_SOFT = "soft"
+)
+
+// MailFeedbackEvent is one bounce, complaint or unsubscribe in a mail
+// webhook delivery. MessageID is the EmailMessage.ID the message was sent
+// with; it may be left out of unsubscribes, which then need a Recipient.
+type MailFeedbackEvent struct {
+	Type       string    `json:"type"`
+	MessageID  uuid.UUID `json:"message_id"`
+	Recipient  string    `json:"recipient"`
+	Reason     string    `json:"reason"`
+	BounceType string    `json:"bounce_type,omitempty"`
+}
+
+// SuppressionReason is the reason the event suppresses its recipient for,
+// or "" when it does not, as for soft bounces.
+func (ev MailFeedbackEvent) SuppressionReason() string {
+	switch ev.Type {
+	case MAIL_FEEDBACK_BOUNCE:
+		if ev.BounceType != MAIL_BOUNCE_SOFT {
+			return entity.EMAIL_SUPPRESSION_BOUNCE
+		}
+	case MAIL_FEEDBACK_COMPLAINT:
+		return entity.EMAIL_SUPPRESSION_COMPLAINT
+	case MAIL_FEEDBACK_UNSUBSCRIBE:
+		return entity.EMAIL_SUPPRESSION_UNSUBSCRIBE
+	}
+	return ""
+}
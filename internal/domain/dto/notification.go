@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// Notification is a message about a user's account, sent by email and,
+// when Summary is set, to the in-app inbox and by text message.
+type Notification struct {
+	UserID uuid.UUID
+	// Category is one of the entity.NOTIFY_CATEGORY_* categories.
+	Category string
+	// Email is sent unless the user turned email off.
+	Email Email
+	// Phone is the user's verified number; empty means no SMS is sent.
+	Phone string
+	// Summary is the notification in one line of plain text, shown in the
+	// in-app inbox and sent by SMS.
+	Summary string
+}
+
+type UpdateNotificationPreferencesInput struct {
+	UserID     uuid.UUID
+	Channels   map[string]bool
+	Categories map[string]bool
+	// QuietHoursStart and QuietHoursEnd are both set or both empty.
+	QuietHoursStart string
+	QuietHoursEnd   string
+	TimeZone        string
+	Digest          string
+}
+
+// InboxPage is a page of the in-app inbox with the unread count, which
+// covers the whole inbox rather than the page.
+type InboxPage struct {
+	Page[*entity.InAppNotification]
+	Unread int `json:"unread"`
+}
+
+// UNREAD_COUNT_TOPIC is the event bus topic an UnreadCountEvent is
+// published on.
+const UNREAD_COUNT_TOPIC = "notification.unread"
+
+// UnreadCountEvent carries a user's unread notification count as of
+// CountedAt.
+type UnreadCountEvent struct {
+	UserID    uuid.UUID
+	Unread    int
+	CountedAt time.Time
+}
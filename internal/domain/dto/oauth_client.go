@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type RegisterOAuthClientInput struct {
+	Name         string
+	Scopes       []string
+	RedirectURIs []string
+	Public       bool
+	Plan         string
+	CreatedBy    uuid.UUID
+}
+
+// RegisteredOAuthClient carries the plain secret, which is only available at
+// registration time.
+type RegisteredOAuthClient struct {
+	*entity.OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type ClientCredentialsInput struct {
+	ClientID     string
+	ClientSecret string
+	// Scopes defaults to every scope granted to the client when empty.
+	Scopes []string
+}
+
+// ClientToken is the RFC 6749 token response of the client_credentials
+// grant.
+type ClientToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
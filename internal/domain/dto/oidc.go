@@ -0,0 +1,59 @@
+package dto
+
+import "github.com/google/uuid"
+
+// AuthorizeInput is an OpenID Connect authentication request, approved by
+// the signed-in user.
+type AuthorizeInput struct {
+	UserID              uuid.UUID
+	SessionID           uuid.UUID
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scopes              []string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizeResult is where the user agent must be sent next: the relying
+// party's redirect URI carrying either a code or an error.
+type AuthorizeResult struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+type AuthorizationCodeInput struct {
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+// OIDCTokens is the token response of the authorization_code grant.
+type OIDCTokens struct {
+	ClientToken
+	IDToken string `json:"id_token"`
+}
+
+// UserInfo holds the standard claims released for the granted scopes.
+type UserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+// JWK is an RSA public signing key (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
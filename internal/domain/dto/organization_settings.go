@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/google/uuid"
+
+type UpdateOrganizationSettingsInput struct {
+	ActorID                uuid.UUID
+	Tenant                 string
+	AllowedCallbackDomains []string
+	DefaultMemberRole      string
+	RequireMFA             bool
+	SessionMaxAgeSeconds   int64
+}
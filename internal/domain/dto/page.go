@@ -0,0 +1,9 @@
+package dto
+
+// Page is one offset-paginated slice of a larger result.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type ResetPasswordInput struct {
+	Token    string
+	Password string
+}
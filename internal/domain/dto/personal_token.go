@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type CreatePersonalTokenInput struct {
+	UserID uuid.UUID
+	Name   string
+	Scopes []string
+	// ExpiresAt is nil for a token that does not expire.
+	ExpiresAt *time.Time
+}
+
+// CreatedPersonalToken carries the plain token, which is only available at
+// creation time.
+type CreatedPersonalToken struct {
+	*entity.PersonalAccessToken
+	Token string `json:"token"`
+}
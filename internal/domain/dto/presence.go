@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PRESENCE_CHANGED_TOPIC is the event bus topic a PresenceChangedEvent is
+// published on when a user comes online or goes offline.
+const PRESENCE_CHANGED_TOPIC = "presence.changed"
+
+// PresenceChangedEvent reports that a user opened their first live
+// connection, or that their last one closed or stopped sending heartbeats.
+type PresenceChangedEvent struct {
+	UserID uuid.UUID
+	Online bool
+	At     time.Time
+}
+
+// Presence is whether a user currently has a live connection, such as an
+// open notification stream, and when one was last seen.
+type Presence struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// PresenceViewer is the caller asking for a user's presence.
+type PresenceViewer struct {
+	ID       uuid.UUID
+	TenantID string
+	Admin    bool
+	Guest    bool
+}
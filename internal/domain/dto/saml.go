@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RegisterSAMLConnectionInput struct {
+	Tenant            string
+	IdPEntityID       string
+	IdPSSOURL         string
+	IdPCertificate    string
+	EmailAttribute    string
+	UsernameAttribute string
+	JITProvisioning   bool
+	CreatedBy         uuid.UUID
+}
+
+// SAMLAssertion is what a verified assertion says about the user.
+type SAMLAssertion struct {
+	ID           string
+	Issuer       string
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+	Attributes   map[string][]string
+	// ExpiresAt is the end of the assertion's validity window.
+	ExpiresAt time.Time
+	// InResponseTo is the ID of the AuthnRequest the assertion answers;
+	// empty for identity provider-initiated sign-ins.
+	InResponseTo string
+}
+
+// Attribute returns the first value of the named attribute.
+func (a *SAMLAssertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+type SAMLSignInInput struct {
+	Tenant       string
+	SAMLResponse string
+	// RelayState is the login state the identity provider posted back, and
+	// BrowserState the one kept in the browser's cookie.
+	RelayState   string
+	BrowserState string
+	Client       ClientInfo
+}
+
+// SAMLSignInResult is the outcome of a sign-in. ReturnState and ReturnTo
+// are what the caller passed when starting it; they are set once the login
+// state checks out, even when the sign-in then fails.
+type SAMLSignInResult struct {
+	Tokens      *AuthTokens
+	ReturnState string
+	ReturnTo    string
+}
+
+// SAMLLogin is a service provider-initiated sign-in being started.
+type SAMLLogin struct {
+	// URL sends the browser to the identity provider.
+	URL string
+	// State must be kept in the browser until the identity provider posts
+	// it back.
+	State     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SecurityTokenKind string
+
+const (
+	SECURITY_TOKEN_PASSWORD_RESET        SecurityTokenKind = "password_reset"
+	SECURITY_TOKEN_EMAIL_CHANGE_CONFIRM  SecurityTokenKind = "email_change_confirm"
+	SECURITY_TOKEN_EMAIL_CHANGE_REVERT   SecurityTokenKind = "email_change_revert"
+	SECURITY_TOKEN_PERSONAL_ACCESS_TOKEN SecurityTokenKind = "personal_access_token"
+)
+
+type SecurityTokenStatus string
+
+const (
+	SECURITY_TOKEN_ACTIVE  SecurityTokenStatus = "active"
+	SECURITY_TOKEN_USED    SecurityTokenStatus = "used"
+	SECURITY_TOKEN_EXPIRED SecurityTokenStatus = "expired"
+	SECURITY_TOKEN_REVOKED SecurityTokenStatus = "revoked"
+)
+
+// SecurityToken describes a token issued to a user, for support to see why
+// a link or credential was refused. It never carries the token or its hash.
+type SecurityToken struct {
+	Kind SecurityTokenKind `json:"kind"`
+	// ID is the record the token belongs to; the two tokens of an email
+	// change share it.
+	ID       uuid.UUID `json:"id"`
+	IssuedAt time.Time `json:"issued_at"`
+	// ExpiresAt is nil for tokens that do not expire.
+	ExpiresAt *time.Time          `json:"expires_at,omitempty"`
+	UsedAt    *time.Time          `json:"used_at,omitempty"`
+	RevokedAt *time.Time          `json:"revoked_at,omitempty"`
+	Status    SecurityTokenStatus `json:"status"`
+	// TTL is how long the token was issued for, e.g. "1h0m0s".
+	TTL string `json:"ttl,omitempty"`
+}
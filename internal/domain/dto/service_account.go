@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type CreateServiceAccountInput struct {
+	Tenant      string
+	Name        string
+	Description string
+	Roles       []string
+	CreatedBy   uuid.UUID
+}
+
+type CreateServiceAccountKeyInput struct {
+	ServiceAccountID uuid.UUID
+	Name             string
+	// ExpiresAt is nil for a key that does not expire.
+	ExpiresAt *time.Time
+	CreatedBy uuid.UUID
+}
+
+// CreatedServiceAccountKey carries the plain key, which is only available at
+// creation time.
+type CreatedServiceAccountKey struct {
+	*entity.ServiceAccountKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/clientinfo"
+)
+
+type SessionView struct {
+	*entity.Session
+	Current bool `json:"current"`
+	// Device is parsed from the session's User-Agent.
+	Device clientinfo.Info `json:"device"`
+}
+
+// PurgeExpiredTokensResult counts what a purge of expired tokens deleted.
+type PurgeExpiredTokensResult struct {
+	Sessions           int `json:"sessions"`
+	PasswordResets     int `json:"password_resets"`
+	AuthorizationCodes int `json:"authorization_codes"`
+	DeletedAccounts    int `json:"deleted_accounts"`
+}
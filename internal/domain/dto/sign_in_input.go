@@ -0,0 +1,29 @@
+package dto
+
+// SignInInput identifies the account by Email, or by Username when Email is
+// empty. Phone is only set for the login history of code sign-ins.
+type SignInInput struct {
+	Email    string
+	Username string
+	Phone    string
+	Password string
+	Client   ClientInfo
+}
+
+// RotatePasswordInput signs in with the expired password and replaces it
+// with NewPassword.
+type RotatePasswordInput struct {
+	SignInInput
+	NewPassword string
+}
+
+type RefreshTokensInput struct {
+	RefreshToken string
+	Client       ClientInfo
+}
+
+type PhoneSignInInput struct {
+	Phone  string
+	Code   string
+	Client ClientInfo
+}
@@ -1,6 +1,44 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
 type SignUpInput struct {
-	Email    string
-	Password string
+	Email      string
+	Password   string
+	Username   string
+	InviteCode string
+	// Terms are the document versions the user agreed to on the sign-up form.
+	Terms  entity.TermsVersions
+	Client ClientInfo
+	// Bot carries the bot detection signals of the sign-up form. It is nil
+	// for accounts not created through the public form, such as admin
+	// imports, which skip bot detection.
+	Bot *BotSignals
+	// SkipWelcomeEmail is set for users an admin imports and tells about
+	// out of band.
+	SkipWelcomeEmail bool
+}
+
+// BotSignals are what the sign-up form sends for bot detection.
+type BotSignals struct {
+	// Honeypot is the value of a form field hidden from people; anything in
+	// it was filled in by a bot.
+	Honeypot string
+	// FormToken is issued when the form is shown and dates it.
+	FormToken    string
+	CaptchaToken string
+	// Protected is set while sign-ups from the client's network are under
+	// adaptive protection, which asks every one of them for a CAPTCHA.
+	Protected bool
+}
+
+// SignUpForm is what a sign-up form is shown with. FormToken goes back with
+// the submission.
+type SignUpForm struct {
+	FormToken string    `json:"form_token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
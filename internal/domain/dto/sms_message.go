@@ -0,0 +1,7 @@
+package dto
+
+type SMSMessage struct {
+	// To is an E.164 phone number.
+	To   string
+	Body string
+}
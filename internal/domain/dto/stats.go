@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// STATS_VIEW_USERS names the stats view holding UserStats.
+const STATS_VIEW_USERS = "users"
+
+// DailyCount is a count for one UTC day, dated YYYY-MM-DD.
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ActiveUsers counts the distinct users who signed in successfully within
+// the last day, week and 30 days.
+type ActiveUsers struct {
+	Day   int `json:"day"`
+	Week  int `json:"week"`
+	Month int `json:"month"`
+}
+
+// UserStats aggregates the users table. ByPlan counts the users on the
+// default plan under "". SignUps covers the last STATS_SIGN_UP_DAYS days,
+// oldest first, including days without sign-ups.
+type UserStats struct {
+	Total    int            `json:"total"`
+	Guests   int            `json:"guests"`
+	ByStatus map[string]int `json:"by_status"`
+	ByPlan   map[string]int `json:"by_plan"`
+	SignUps  []DailyCount   `json:"sign_ups"`
+	Active   ActiveUsers    `json:"active"`
+}
+
+// AdminStats is what GET /admin/stats serves: the stats as of RefreshedAt.
+type AdminStats struct {
+	RefreshedAt time.Time `json:"refreshed_at"`
+	Users       UserStats `json:"users"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "github.com/haidang666/go-app/internal/domain/entity"
+
+type TermsStatus struct {
+	Current  entity.TermsVersions    `json:"current"`
+	Accepted *entity.TermsAcceptance `json:"accepted"`
+	UpToDate bool                    `json:"up_to_date"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "github.com/google/uuid"
+
+type UpdateUserInput struct {
+	ID       uuid.UUID
+	Email    string
+	Password string
+}
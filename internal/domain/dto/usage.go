@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// USAGE_EVENT_TOPIC is the event bus topic metered usage is published on.
+const USAGE_EVENT_TOPIC = "usage"
+
+// UsageEvent is one metered occurrence. Quantity is a count to add, or the
+// current level for peak metrics.
+type UsageEvent struct {
+	UserID     uuid.UUID
+	Metric     string
+	Quantity   int64
+	OccurredAt time.Time
+}
+
+type UsageSummary struct {
+	PeriodStart time.Time        `json:"period_start"`
+	PeriodEnd   time.Time        `json:"period_end"`
+	Usage       map[string]int64 `json:"usage"`
+}
+
+type UsageExport struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Records     []*entity.UsageRecord
+}
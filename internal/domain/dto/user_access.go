@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type RevokeUserAccessInput struct {
+	ActorID uuid.UUID
+	UserID  uuid.UUID
+	Reason  string
+}
+
+// RevokeUserAccessResult is the user whose access was revoked, and how many
+// of their credentials were.
+type RevokeUserAccessResult struct {
+	User                  *entity.User `json:"user"`
+	SessionsRevoked       int          `json:"sessions_revoked"`
+	PersonalTokensRevoked int          `json:"personal_tokens_revoked"`
+}
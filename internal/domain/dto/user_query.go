@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// USER_CHANGED_TOPIC is the event bus topic a UserChangedEvent is published
+// on after every user write.
+const USER_CHANGED_TOPIC = "user.changed"
+
+// UserChangedEvent carries the user as written, without its password hash.
+// Deleted events carry only the user's ID.
+type UserChangedEvent struct {
+	User    entity.User
+	Deleted bool
+}
+
+// UserSummary is a row of the user read model, denormalized for listing and
+// search.
+type UserSummary struct {
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username,omitempty"`
+	Status        string    `json:"status"`
+	Plan          string    `json:"plan,omitempty"`
+	TenantID      string    `json:"tenant_id,omitempty"`
+	IsGuest       bool      `json:"is_guest,omitempty"`
+	PhoneVerified bool      `json:"phone_verified"`
+	// PurgeAt is set on accounts deleted by their owner and still
+	// restorable.
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// UserFilter narrows a user listing; empty fields match everything.
+type UserFilter struct {
+	// Query matches a substring of the email or username, case-insensitively.
+	Query    string
+	Status   string
+	Plan     string
+	TenantID string
+}
+
+// UserExpand names the relations to embed in a user listing.
+type UserExpand struct {
+	Organization bool
+	Sessions     bool
+}
+
+// UserView is a user listing row with the relations asked for embedded.
+// Organization stays nil for users outside any tenant.
+type UserView struct {
+	*UserSummary
+	Organization *Organization     `json:"organization,omitempty"`
+	Sessions     []*entity.Session `json:"sessions,omitzero"`
+}
+
+// Organization is the tenant a user belongs to. The SAML fields are empty
+// when the tenant has no connection.
+type Organization struct {
+	Tenant           string     `json:"tenant"`
+	SAMLConnectionID *uuid.UUID `json:"saml_connection_id,omitempty"`
+	JITProvisioning  bool       `json:"jit_provisioning"`
+	QuotaLimits      string     `json:"quota_limits,omitempty"`
+}
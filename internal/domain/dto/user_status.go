@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/google/uuid"
+
+type SetUserStatusInput struct {
+	ActorID uuid.UUID
+	UserID  uuid.UUID
+	Status  string
+	Reason  string
+}
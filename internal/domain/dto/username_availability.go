@@ -0,0 +1,9 @@
+package dto
+
+// UsernameAvailability answers whether a username can be claimed. Reason is
+// set when it cannot.
+type UsernameAvailability struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
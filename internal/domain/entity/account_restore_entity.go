@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountRestore is the emailed, single-use link that restores a deleted
+// account. It expires when the account is purged.
+type AccountRestore struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func (a *AccountRestore) IsUsable(now time.Time) bool {
+	return a.UsedAt == nil && now.Before(a.ExpiresAt)
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AUDIT_IMPERSONATION_STARTED = "impersonation.started"
+	// AUDIT_IMPERSONATED_REQUEST is a request made with an impersonation
+	// token.
+	AUDIT_IMPERSONATED_REQUEST = "impersonation.request"
+	AUDIT_USER_STATUS_CHANGED  = "user.status_changed"
+	AUDIT_USER_PLAN_CHANGED    = "user.plan_changed"
+	// AUDIT_USER_ACCESS_REVOKED counts the revoked credentials in Detail.
+	AUDIT_USER_ACCESS_REVOKED = "user.access_revoked"
+	// AUDIT_TENANT_QUOTA_CHANGED has the tenant's SAML connection as its
+	// subject.
+	AUDIT_TENANT_QUOTA_CHANGED = "tenant.quota_changed"
+	// AUDIT_TENANT_SETTINGS_CHANGED has the tenant's SAML connection as
+	// its subject and names the changed settings in Detail.
+	AUDIT_TENANT_SETTINGS_CHANGED = "tenant.settings_changed"
+	// AUDIT_ADMIN_TASK_TRIGGERED has the job the task runs as as its
+	// subject.
+	AUDIT_ADMIN_TASK_TRIGGERED = "admin_task.triggered"
+	// AUDIT_MAINTENANCE_CHANGED has no subject; Detail is the new mode.
+	AUDIT_MAINTENANCE_CHANGED = "maintenance.changed"
+	// AUDIT_AUTH_PROTECTION_CHANGED has no subject; Detail is the old and
+	// new override mode.
+	AUDIT_AUTH_PROTECTION_CHANGED = "auth_protection.changed"
+	// AUDIT_EMAIL_SUPPRESSION_ADDED and AUDIT_EMAIL_SUPPRESSION_REMOVED
+	// have no subject; Detail is the address, and the reason when added.
+	AUDIT_EMAIL_SUPPRESSION_ADDED   = "email_suppression.added"
+	AUDIT_EMAIL_SUPPRESSION_REMOVED = "email_suppression.removed"
+	// The service account events have the account as their subject; the
+	// key ones name the key's hint in Detail.
+	AUDIT_SERVICE_ACCOUNT_CREATED     = "service_account.created"
+	AUDIT_SERVICE_ACCOUNT_DELETED     = "service_account.deleted"
+	AUDIT_SERVICE_ACCOUNT_KEY_CREATED = "service_account.key_created"
+	AUDIT_SERVICE_ACCOUNT_KEY_REVOKED = "service_account.key_revoked"
+	// AUDIT_SERVICE_ACCOUNT_REQUEST is a write request made with a service
+	// account key, the account being both actor and subject.
+	AUDIT_SERVICE_ACCOUNT_REQUEST = "service_account.request"
+)
+
+// AuditEvent records an action ActorID took that affected SubjectID. Method,
+// Path and Status describe the HTTP request the action was made with.
+type AuditEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	Action    string     `json:"action"`
+	ActorID   uuid.UUID  `json:"actor_id"`
+	SubjectID uuid.UUID  `json:"subject_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	Method    string     `json:"method,omitempty"`
+	Path      string     `json:"path,omitempty"`
+	Status    int        `json:"status,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	// CountryCode, Country and City locate IP, with names in English.
+	CountryCode string `json:"country_code,omitempty"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+	// Device describes the client of the request, e.g. "Chrome 120 on
+	// macOS 14".
+	Device string `json:"device,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Detail describes the change, e.g. "active -> suspended".
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
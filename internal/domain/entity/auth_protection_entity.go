@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How the adaptive protection of sign-up and sign-in is overridden: auto
+// protects the networks whose failure rate spikes, on protects every
+// request and off none.
+const (
+	AUTH_PROTECTION_AUTO = "auto"
+	AUTH_PROTECTION_ON   = "on"
+	AUTH_PROTECTION_OFF  = "off"
+)
+
+// AuthProtection is the override of the adaptive protection of the
+// credential endpoints. While it protects a request, the client's address
+// is rate limited more tightly and a CAPTCHA is required.
+type AuthProtection struct {
+	Mode  string     `json:"mode"`
+	Since *time.Time `json:"since,omitempty"`
+	// SetBy is the admin who last changed the mode; nil when it comes from
+	// the configuration.
+	SetBy *uuid.UUID `json:"set_by,omitempty"`
+}
+
+func IsAuthProtectionMode(mode string) bool {
+	switch mode {
+	case AUTH_PROTECTION_AUTO, AUTH_PROTECTION_ON, AUTH_PROTECTION_OFF:
+		return true
+	}
+	return false
+}
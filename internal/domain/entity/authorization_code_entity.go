@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes a relying party may request in the OpenID Connect code flow.
+const (
+	SCOPE_OPENID  = "openid"
+	SCOPE_EMAIL   = "email"
+	SCOPE_PROFILE = "profile"
+)
+
+// PKCE_METHOD_S256 is the only code challenge method accepted; "plain" offers
+// no protection once the authorization request leaks.
+const PKCE_METHOD_S256 = "S256"
+
+// AuthorizationCode is the single-use code handed to a relying party after
+// the user authorizes it. Only the hash of the code is stored. A redeemed
+// code is kept, with ConsumedAt set, until it expires, so a replay of it
+// can be told apart from an unknown code.
+type AuthorizationCode struct {
+	ID            uuid.UUID
+	CodeHash      string
+	ClientID      string
+	UserID        uuid.UUID
+	SessionID     uuid.UUID
+	RedirectURI   string
+	Scopes        []string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      time.Time
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	ConsumedAt    *time.Time
+}
+
+func (c *AuthorizationCode) IsUsable(now time.Time) bool {
+	return c.ConsumedAt == nil && now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// Deprecation is a route, or a field of its requests, that clients are asked
+// to stop using before it is removed.
+type Deprecation struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	// Field is the deprecated request field, a top-level JSON body member or
+	// a query parameter; empty when the whole route is deprecated.
+	Field string    `json:"field,omitempty"`
+	Since time.Time `json:"since"`
+	// Sunset is when it stops working; nil until a date is announced.
+	Sunset *time.Time `json:"sunset,omitempty"`
+	// Link documents the replacement.
+	Link string `json:"link,omitempty"`
+}
+
+// Route is how the deprecation is named in logs and metrics, e.g.
+// "GET /users/{id}" or "POST /reports#legacy_format".
+func (d *Deprecation) Route() string {
+	route := d.Method + " " + d.Pattern
+	if d.Field != "" {
+		route += "#" + d.Field
+	}
+	return route
+}
+
+// DeprecationClient is one client's use of a deprecation.
+type DeprecationClient struct {
+	// Client is the caller as quotas count it, e.g. "user:<id>" or
+	// "client:<client id>", or "anonymous".
+	Client    string    `json:"client"`
+	Requests  int64     `json:"requests"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
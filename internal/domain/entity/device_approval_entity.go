@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeviceApprovalStatus string
+
+const (
+	DEVICE_APPROVAL_PENDING  DeviceApprovalStatus = "pending"
+	DEVICE_APPROVAL_APPROVED DeviceApprovalStatus = "approved"
+	DEVICE_APPROVAL_DENIED   DeviceApprovalStatus = "denied"
+)
+
+// DeviceApproval is the pending review of a sign-in from a new device. Only
+// the hash of the emailed token is stored.
+type DeviceApproval struct {
+	ID          uuid.UUID            `json:"id"`
+	UserID      uuid.UUID            `json:"user_id"`
+	SessionID   uuid.UUID            `json:"session_id"`
+	Fingerprint string               `json:"fingerprint"`
+	UserAgent   string               `json:"user_agent"`
+	IP          string               `json:"ip"`
+	TokenHash   string               `json:"-"`
+	Status      DeviceApprovalStatus `json:"status"`
+	CreatedAt   time.Time            `json:"created_at"`
+	ExpiresAt   time.Time            `json:"expires_at"`
+	DecidedAt   *time.Time           `json:"decided_at,omitempty"`
+}
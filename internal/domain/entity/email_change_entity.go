@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailChangeStatus string
+
+const (
+	EMAIL_CHANGE_PENDING   EmailChangeStatus = "pending"
+	EMAIL_CHANGE_CONFIRMED EmailChangeStatus = "confirmed"
+	EMAIL_CHANGE_REVERTED  EmailChangeStatus = "reverted"
+)
+
+// EmailChange is a requested address change. The new address confirms it;
+// the old address can cancel it, or revert it within a window after it was
+// confirmed.
+type EmailChange struct {
+	ID          uuid.UUID         `json:"id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	OldEmail    string            `json:"old_email"`
+	NewEmail    string            `json:"new_email"`
+	ConfirmHash string            `json:"-"`
+	RevertHash  string            `json:"-"`
+	Status      EmailChangeStatus `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	ConfirmedAt *time.Time        `json:"confirmed_at,omitempty"`
+	RevertUntil *time.Time        `json:"revert_until,omitempty"`
+	RevertedAt  *time.Time        `json:"reverted_at,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why mail to an address is suppressed.
+const (
+	// EMAIL_SUPPRESSION_BOUNCE is a hard bounce: the address does not
+	// exist or the mailbox refuses mail for good.
+	EMAIL_SUPPRESSION_BOUNCE = "bounce"
+	// EMAIL_SUPPRESSION_COMPLAINT is a recipient who reported a message as
+	// spam.
+	EMAIL_SUPPRESSION_COMPLAINT   = "complaint"
+	EMAIL_SUPPRESSION_UNSUBSCRIBE = "unsubscribe"
+	// EMAIL_SUPPRESSION_MANUAL is an address an admin suppressed.
+	EMAIL_SUPPRESSION_MANUAL = "manual"
+)
+
+// EmailSuppression is an address no mail is sent to anymore, as sending to
+// it again would bounce or annoy its owner and hurt the sender's
+// reputation.
+type EmailSuppression struct {
+	// Email is normalized by NormalizeSuppressedEmail.
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
+	// Detail is the provider's feedback or the admin's note.
+	Detail string `json:"detail,omitempty"`
+	// CreatedBy is the admin who suppressed the address; nil when the
+	// provider reported it.
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func IsEmailSuppressionReason(reason string) bool {
+	switch reason {
+	case EMAIL_SUPPRESSION_BOUNCE, EMAIL_SUPPRESSION_COMPLAINT, EMAIL_SUPPRESSION_UNSUBSCRIBE, EMAIL_SUPPRESSION_MANUAL:
+		return true
+	}
+	return false
+}
+
+// NormalizeSuppressedEmail is the form suppressed addresses are kept and
+// looked up in: mailbox providers treat addresses case-insensitively.
+func NormalizeSuppressedEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
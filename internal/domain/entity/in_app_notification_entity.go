@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InAppNotification is an entry of a user's notification inbox, shown in
+// the app until the user reads it.
+type InAppNotification struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"-"`
+	Category string    `json:"category"`
+	// Kind names what the notification is about, e.g. "welcome", so clients
+	// can pick an icon or link.
+	Kind      string     `json:"kind"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+func (n *InAppNotification) IsRead() bool {
+	return n.ReadAt != nil
+}
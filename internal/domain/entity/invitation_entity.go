@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation grants sign-up while registration is invite-only. Only the hash
+// of the code is stored; the code itself is shown once when minted. A
+// non-empty Email restricts the invitation to that address.
+type Invitation struct {
+	ID        uuid.UUID  `json:"id"`
+	CodeHash  string     `json:"-"`
+	Email     string     `json:"email,omitempty"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (i *Invitation) IsRedeemable(email string, now time.Time) bool {
+	return i.RevokedAt == nil &&
+		now.Before(i.ExpiresAt) &&
+		i.Uses < i.MaxUses &&
+		(i.Email == "" || strings.EqualFold(i.Email, email))
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KnownDevice is a device fingerprint a user has signed in from before.
+type KnownDevice struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Fingerprint string    `json:"fingerprint"`
+	UserAgent   string    `json:"user_agent"`
+	LastIP      string    `json:"last_ip"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
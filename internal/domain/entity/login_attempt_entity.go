@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt is one sign-in attempt, successful or not. UserID is nil when
+// the email or username did not match any account.
+type LoginAttempt struct {
+	ID            uuid.UUID `json:"id"`
+	UserID        uuid.UUID `json:"-"`
+	Email         string    `json:"email,omitempty"`
+	Username      string    `json:"username,omitempty"`
+	Phone         string    `json:"phone,omitempty"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"user_agent"`
+	CountryCode   string    `json:"country_code,omitempty"`
+	Country       string    `json:"country,omitempty"`
+	City          string    `json:"city,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// LoginState is kept server side between sending a browser to an external
+// identity provider and the provider sending it back, so the callback can be
+// tied to a sign-in this service started in that browser.
+type LoginState struct {
+	// State is the random value round-tripped through the provider, as the
+	// SAML RelayState, and kept in a sealed cookie in the browser.
+	State string
+	// Provider is the identity provider the sign-in went to, e.g.
+	// "saml:acme".
+	Provider string
+	// Nonce is what the provider's response must echo back: the ID of the
+	// SAML AuthnRequest, matched against InResponseTo.
+	Nonce string
+	// ReturnState is the caller's own state, handed back once the sign-in
+	// completes.
+	ReturnState string
+	// ReturnTo is the page on one of the tenant's allowed callback domains
+	// the browser is sent to once the sign-in completes; empty for the
+	// configured success page.
+	ReturnTo  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// SAMLLoginProvider names a tenant's SAML identity provider in login states.
+func SAMLLoginProvider(tenant string) string {
+	return "saml:" + tenant
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why the service is read-only, told to the clients it turns away.
+const (
+	MAINTENANCE_REASON_MAINTENANCE = "maintenance"
+	MAINTENANCE_REASON_FAILOVER    = "failover"
+	MAINTENANCE_REASON_MIGRATION   = "migration"
+)
+
+// MaintenanceMode is whether the service accepts writes. While ReadOnly,
+// write endpoints answer 503 and reads keep working.
+type MaintenanceMode struct {
+	ReadOnly bool       `json:"read_only"`
+	Reason   string     `json:"reason,omitempty"`
+	Since    *time.Time `json:"since,omitempty"`
+	// SetBy is the admin who last changed the mode; nil when it comes from
+	// the configuration.
+	SetBy *uuid.UUID `json:"set_by,omitempty"`
+}
+
+func IsMaintenanceReason(reason string) bool {
+	switch reason {
+	case MAINTENANCE_REASON_MAINTENANCE, MAINTENANCE_REASON_FAILOVER, MAINTENANCE_REASON_MIGRATION:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification channels.
+const (
+	NOTIFY_CHANNEL_EMAIL  = "email"
+	NOTIFY_CHANNEL_SMS    = "sms"
+	NOTIFY_CHANNEL_IN_APP = "in_app"
+)
+
+// Notification categories.
+const (
+	// NOTIFY_CATEGORY_ACCOUNT covers news about the account, such as the
+	// welcome email.
+	NOTIFY_CATEGORY_ACCOUNT = "account"
+	// NOTIFY_CATEGORY_SECURITY covers changes to the account's credentials
+	// and access to it by others. It cannot be turned off, and quiet hours
+	// and digests do not hold it back.
+	NOTIFY_CATEGORY_SECURITY = "security"
+)
+
+// Digest frequencies.
+const (
+	DIGEST_OFF    = "off"
+	DIGEST_DAILY  = "daily"
+	DIGEST_WEEKLY = "weekly"
+)
+
+// digestHour is the local hour digests are sent at; weekly digests go out
+// on Mondays.
+const digestHour = 9
+
+// QuietHours is a daily window, in the preferences' time zone, during which
+// notifications wait. Start and End are "15:04" times; a window whose End
+// is not after its Start spans midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// NotificationPreferences controls which notifications a user receives and
+// when. Channels and categories missing from the maps keep their defaults.
+type NotificationPreferences struct {
+	UserID     uuid.UUID       `json:"-"`
+	Channels   map[string]bool `json:"channels"`
+	Categories map[string]bool `json:"categories"`
+	// QuietHours is nil when notifications are sent at any time.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	// TimeZone is an IANA zone name; empty means UTC.
+	TimeZone  string     `json:"time_zone,omitempty"`
+	Digest    string     `json:"digest"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultNotificationPreferences are what users start with: everything by
+// email and in the app, right away.
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID: userID,
+		Channels: map[string]bool{
+			NOTIFY_CHANNEL_EMAIL:  true,
+			NOTIFY_CHANNEL_SMS:    false,
+			NOTIFY_CHANNEL_IN_APP: true,
+		},
+		Categories: map[string]bool{
+			NOTIFY_CATEGORY_ACCOUNT:  true,
+			NOTIFY_CATEGORY_SECURITY: true,
+		},
+		Digest: DIGEST_OFF,
+	}
+}
+
+func (p *NotificationPreferences) ChannelEnabled(channel string) bool {
+	if on, ok := p.Channels[channel]; ok {
+		return on
+	}
+	return channel != NOTIFY_CHANNEL_SMS
+}
+
+func (p *NotificationPreferences) CategoryEnabled(category string) bool {
+	if category == NOTIFY_CATEGORY_SECURITY {
+		return true
+	}
+	if on, ok := p.Categories[category]; ok {
+		return on
+	}
+	return true
+}
+
+// HoldUntil reports when a notification made at now should be delivered if
+// not right away: at the next digest, or else at the end of quiet hours.
+func (p *NotificationPreferences) HoldUntil(now time.Time) (time.Time, bool) {
+	local := now.In(p.location())
+	switch p.Digest {
+	case DIGEST_DAILY:
+		return nextDigest(local, false), true
+	case DIGEST_WEEKLY:
+		return nextDigest(local, true), true
+	}
+	return p.quietUntil(local)
+}
+
+// InQuietHours reports whether now falls within the quiet hours.
+func (p *NotificationPreferences) InQuietHours(now time.Time) bool {
+	_, ok := p.quietUntil(now.In(p.location()))
+	return ok
+}
+
+func (p *NotificationPreferences) quietUntil(local time.Time) (time.Time, bool) {
+	if p.QuietHours == nil {
+		return time.Time{}, false
+	}
+	start, err := time.Parse("15:04", p.QuietHours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse("15:04", p.QuietHours.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	var quiet bool
+	if from < to {
+		quiet = minute >= from && minute < to
+	} else {
+		quiet = minute >= from || minute < to
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+func (p *NotificationPreferences) location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// nextDigest returns the next digestHour after local, on a Monday when
+// weekly.
+func nextDigest(local time.Time, weekly bool) time.Time {
+	next := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, local.Location())
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if weekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+// PendingNotification is an email held back by quiet hours or a digest.
+type PendingNotification struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	To       string
+	Template string
+	Data     map[string]any
+	// Locale is the locale of the request that made the notification, which
+	// the email is rendered in when it is sent.
+	Locale string
+	// Digest marks notifications collected into a digest rather than held
+	// for quiet hours.
+	Digest    bool
+	DueAt     time.Time
+	CreatedAt time.Time
+}
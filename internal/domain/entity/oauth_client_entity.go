@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCOPE_INTROSPECT lets a confidential client, such as an internal resource
+// server, introspect tokens at POST /oauth/introspect.
+const SCOPE_INTROSPECT = "introspect"
+
+// OAuthClient is a registered OAuth client: a machine caller using the
+// client_credentials grant, or an OpenID Connect relying party using the
+// authorization code flow. Only the hash of the secret is stored; the secret
+// itself is shown once at registration. Public clients (SPAs, mobile apps)
+// have no secret and rely on PKCE alone.
+type OAuthClient struct {
+	ID           uuid.UUID `json:"id"`
+	ClientID     string    `json:"client_id"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name"`
+	Scopes       []string  `json:"scopes"`
+	RedirectURIs []string  `json:"redirect_uris,omitempty"`
+	Public       bool      `json:"public,omitempty"`
+	// Plan selects the client's request quota; empty means the default plan.
+	Plan      string     `json:"plan,omitempty"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (c *OAuthClient) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// AllowsScopes reports whether every requested scope was granted to the
+// client at registration.
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsRedirectURI requires an exact match, as OpenID Connect mandates.
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
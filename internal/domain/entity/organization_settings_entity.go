@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+var callbackDomainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(:[0-9]{1,5})?$`)
+
+// OrganizationSettings are what an organization (a tenant) sets for its
+// members. The zero value, which a tenant has until they are first saved,
+// restricts nothing.
+type OrganizationSettings struct {
+	Tenant string `json:"tenant"`
+	// AllowedCallbackDomains are the hosts the tenant's SAML sign-ins may
+	// return the browser to; "*.example.com" also allows its subdomains.
+	// Empty allows only the configured success page.
+	AllowedCallbackDomains []string `json:"allowed_callback_domains"`
+	// DefaultMemberRole is added to the roles of the members' tokens.
+	DefaultMemberRole string `json:"default_member_role,omitempty"`
+	// RequireMFA makes members approve every password or code sign-in
+	// from a new device through the emailed link, whatever the global
+	// device settings. SAML sign-ins are left to the identity provider.
+	RequireMFA bool `json:"require_mfa"`
+	// SessionMaxAgeSeconds ends the members' sessions that long after they
+	// were opened, however active; 0 keeps them until the refresh token
+	// expires.
+	SessionMaxAgeSeconds int64      `json:"session_max_age_seconds"`
+	UpdatedBy            uuid.UUID  `json:"updated_by,omitzero"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+}
+
+// ValidateCallbackDomain checks an entry of AllowedCallbackDomains: a
+// lower-case host, optionally with a port and a leading "*.".
+func ValidateCallbackDomain(domain string) error {
+	if !callbackDomainPattern.MatchString(domain) {
+		return errs.ErrInvalidCallbackDomain
+	}
+	return nil
+}
+
+// AllowsCallback reports whether rawURL is an https URL, or an http one on
+// localhost, whose host is one of AllowedCallbackDomains. It must have no
+// fragment, which is where the sign-in's outcome goes.
+func (s *OrganizationSettings) AllowsCallback(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	if u.Scheme != "https" && (u.Scheme != "http" || u.Hostname() != "localhost") {
+		return false
+	}
+	for _, domain := range s.AllowedCallbackDomains {
+		if suffix, ok := strings.CutPrefix(domain, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OrganizationSettings) SessionMaxAge() time.Duration {
+	return time.Duration(s.SessionMaxAgeSeconds) * time.Second
+}
+
+// SessionTooOld reports whether a session opened at createdAt has outlived
+// SessionMaxAge.
+func (s *OrganizationSettings) SessionTooOld(createdAt, now time.Time) bool {
+	return s.SessionMaxAgeSeconds > 0 && now.Sub(createdAt) >= s.SessionMaxAge()
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// OUTBOUND_EMAIL_QUEUED is waiting for its first or next delivery
+	// attempt.
+	OUTBOUND_EMAIL_QUEUED = "queued"
+	OUTBOUND_EMAIL_SENT   = "sent"
+	// OUTBOUND_EMAIL_FAILED ran out of delivery attempts, or made the
+	// transport panic.
+	OUTBOUND_EMAIL_FAILED = "failed"
+	// OUTBOUND_EMAIL_BOUNCED and OUTBOUND_EMAIL_COMPLAINED were accepted by
+	// the provider, which later reported them through its webhook.
+	OUTBOUND_EMAIL_BOUNCED    = "bounced"
+	OUTBOUND_EMAIL_COMPLAINED = "complained"
+	// OUTBOUND_EMAIL_SUPPRESSED was not sent, as its recipient is on the
+	// suppression list.
+	OUTBOUND_EMAIL_SUPPRESSED = "suppressed"
+)
+
+// OutboundEmail is a message in the outgoing mail queue. The body is never
+// serialized: it holds live sign-in, reset and approval links.
+type OutboundEmail struct {
+	ID       uuid.UUID `json:"id"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"-"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts"`
+	// NextAttemptAt is when a queued message is due.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	// Feedback is the reason the provider gave for a bounce or complaint.
+	Feedback  string     `json:"feedback,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// IsResendable reports whether an operator may queue the message again.
+// Complaints are excluded: the recipient asked not to get it. A suppressed
+// message is only sent once its recipient is taken off the list.
+func (e *OutboundEmail) IsResendable() bool {
+	switch e.Status {
+	case OUTBOUND_EMAIL_FAILED, OUTBOUND_EMAIL_BOUNCED, OUTBOUND_EMAIL_SUPPRESSED:
+		return true
+	}
+	return false
+}
+
+func ValidOutboundEmailStatus(status string) bool {
+	switch status {
+	case OUTBOUND_EMAIL_QUEUED, OUTBOUND_EMAIL_SENT, OUTBOUND_EMAIL_FAILED,
+		OUTBOUND_EMAIL_BOUNCED, OUTBOUND_EMAIL_COMPLAINED, OUTBOUND_EMAIL_SUPPRESSED:
+		return true
+	}
+	return false
+}
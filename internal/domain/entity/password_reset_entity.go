@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordReset is an emailed, single-use password reset link.
+type PasswordReset struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func (p *PasswordReset) IsUsable(now time.Time) bool {
+	return p.UsedAt == nil && now.Before(p.ExpiresAt)
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PERSONAL_TOKEN_PREFIX starts every personal access token, telling them
+// apart from JWTs in the Authorization header and making leaked ones easy
+// to spot in secret scanners.
+const PERSONAL_TOKEN_PREFIX = "pat_"
+
+const (
+	// TOKEN_SCOPE_READ allows safe requests (GET, HEAD, OPTIONS).
+	TOKEN_SCOPE_READ = "read"
+	// TOKEN_SCOPE_WRITE allows every other request.
+	TOKEN_SCOPE_WRITE = "write"
+)
+
+// PersonalAccessToken is a long-lived bearer credential a user mints for
+// scripts and integrations. Only the hash of the token is stored; the token
+// itself is shown once at creation.
+type PersonalAccessToken struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	// Hint is the start of the token, enough to recognize it in a list.
+	Hint      string    `json:"hint"`
+	TokenHash string    `json:"-"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is nil for tokens that do not expire.
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
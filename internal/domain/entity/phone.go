@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// NormalizePhone converts a phone number typed in international format
+// ("+1 (415) 555-0100", "0044 20 7946 0958") to E.164. Numbers without a
+// country code are rejected since there is no default region to assume.
+func NormalizePhone(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if strings.HasPrefix(s, "00") {
+		s = "+" + s[2:]
+	}
+	if !strings.HasPrefix(s, "+") {
+		return "", errs.ErrInvalidPhone
+	}
+
+	var b strings.Builder
+	b.WriteByte('+')
+	for _, c := range s[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", errs.ErrInvalidPhone
+		}
+	}
+
+	phone := b.String()
+	// E.164 allows at most 15 digits and country codes never start with 0.
+	if len(phone) < 8 || len(phone) > 16 || phone[1] == '0' {
+		return "", errs.ErrInvalidPhone
+	}
+	return phone, nil
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OTPPurpose string
+
+const (
+	OTP_PURPOSE_VERIFY_PHONE OTPPurpose = "verify_phone"
+	OTP_PURPOSE_SIGN_IN      OTPPurpose = "sign_in"
+)
+
+// PhoneOTP is a one-time code texted to a phone number. Only the hash of the
+// code is kept.
+type PhoneOTP struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Phone      string
+	Purpose    OTPPurpose
+	CodeHash   string
+	Attempts   int
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+}
+
+// IsUsable reports whether the code can still be tried.
+func (o *PhoneOTP) IsUsable(now time.Time, maxAttempts int) bool {
+	return o.ConsumedAt == nil && now.Before(o.ExpiresAt) && o.Attempts < maxAttempts
+}
@@ -0,0 +1,7 @@
+package entity
+
+// Plan-gated features. Which plan includes each is configured with
+// PLAN_FEATURES; unconfigured features are open to every plan.
+const (
+	FEATURE_USAGE_REPORT = "usage_report"
+)
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// SAMLConnection federates sign-in for a tenant with its SAML identity
+// provider. Accounts signing in through it belong to the tenant; with
+// JITProvisioning they are created on first sign-in.
+type SAMLConnection struct {
+	ID             uuid.UUID `json:"id"`
+	Tenant         string    `json:"tenant"`
+	IdPEntityID    string    `json:"idp_entity_id"`
+	IdPSSOURL      string    `json:"idp_sso_url"`
+	IdPCertificate string    `json:"idp_certificate"`
+	// EmailAttribute names the assertion attribute holding the email; the
+	// NameID is used when empty.
+	EmailAttribute    string `json:"email_attribute,omitempty"`
+	UsernameAttribute string `json:"username_attribute,omitempty"`
+	JITProvisioning   bool   `json:"jit_provisioning"`
+	// QuotaLimits is the request quota the tenant's accounts share, on top
+	// of their own plans, in the format of QUOTA_PLANS; empty for none.
+	QuotaLimits string    `json:"quota_limits,omitempty"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ValidateTenant checks a tenant slug, which appears in the SAML endpoint
+// URLs.
+func ValidateTenant(tenant string) error {
+	if !tenantPattern.MatchString(tenant) {
+		return errs.ErrInvalidTenant
+	}
+	return nil
+}
+
+// Certificate parses the identity provider's signing certificate.
+func (c *SAMLConnection) Certificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(c.IdPCertificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errs.ErrInvalidCertificate
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errs.ErrInvalidCertificate
+	}
+	return cert, nil
+}
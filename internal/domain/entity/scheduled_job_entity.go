@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SCHEDULED_JOB_PENDING is waiting for RunAt, or for a retry.
+	SCHEDULED_JOB_PENDING = "pending"
+	SCHEDULED_JOB_DONE    = "done"
+	// SCHEDULED_JOB_FAILED ran out of attempts, panicked, or has a kind no
+	// handler is registered for.
+	SCHEDULED_JOB_FAILED   = "failed"
+	SCHEDULED_JOB_CANCELED = "canceled"
+	// SCHEDULED_JOB_DISCARDED failed and was given up on by an operator.
+	SCHEDULED_JOB_DISCARDED = "discarded"
+)
+
+// maxScheduledJobErrors is how many failed runs a job's history keeps.
+const maxScheduledJobErrors = 20
+
+// ScheduledJob is work deferred to RunAt, such as clearing a phone number
+// still unverified a week later. Key, when set, identifies the job to
+// whoever scheduled it, to move or cancel it once the condition that
+// triggered it resolves; only one job per key is pending at a time.
+type ScheduledJob struct {
+	ID      uuid.UUID       `json:"id"`
+	Kind    string          `json:"kind"`
+	Key     string          `json:"key,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Status  string          `json:"status"`
+	// RunAt is when a pending job is due; retries move it.
+	RunAt     time.Time `json:"run_at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+	// Errors is the history of the failed runs, oldest first.
+	Errors    []ScheduledJobError `json:"errors,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt *time.Time          `json:"updated_at,omitempty"`
+}
+
+// ScheduledJobError is one failed run of a job. Panic tells a run whose
+// handler panicked from one that returned an error.
+type ScheduledJobError struct {
+	Attempt int       `json:"attempt"`
+	Error   string    `json:"error"`
+	Panic   bool      `json:"panic,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// RecordError adds a failed run to the job's history, dropping the oldest
+// beyond the last maxScheduledJobErrors.
+func (j *ScheduledJob) RecordError(err string, panicked bool, at time.Time) {
+	j.LastError = err
+	j.Errors = append(j.Errors, ScheduledJobError{Attempt: j.Attempts, Error: err, Panic: panicked, At: at})
+	if n := len(j.Errors); n > maxScheduledJobErrors {
+		j.Errors = j.Errors[n-maxScheduledJobErrors:]
+	}
+}
+
+// IsDead reports whether the job failed for good and waits for an operator
+// to requeue or discard it.
+func (j *ScheduledJob) IsDead() bool {
+	return j.Status == SCHEDULED_JOB_FAILED
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// SERVICE_ACCOUNT_KEY_PREFIX starts every service account key, telling them
+// apart from personal access tokens and JWTs.
+const SERVICE_ACCOUNT_KEY_PREFIX = "sak_"
+
+var rolePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// ServiceAccount is a non-human identity that belongs to an organization
+// (a tenant) rather than to a user, for automation and CI. It authenticates
+// with its own keys and holds its own roles, and what it does is audited
+// under its own ID.
+type ServiceAccount struct {
+	ID          uuid.UUID `json:"id"`
+	Tenant      string    `json:"tenant"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []string  `json:"roles"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	// DeletedAt is set once the account is deleted; its keys stop working
+	// but it is kept so the audit log can still name it.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (a *ServiceAccount) IsActive() bool {
+	return a.DeletedAt == nil
+}
+
+// ServiceAccountKey is a bearer credential of a service account. Only its
+// hash is stored; the key itself is shown once at creation.
+type ServiceAccountKey struct {
+	ID               uuid.UUID `json:"id"`
+	ServiceAccountID uuid.UUID `json:"service_account_id"`
+	Name             string    `json:"name"`
+	// Hint is the start of the key, enough to recognize it in a list.
+	Hint      string    `json:"hint"`
+	KeyHash   string    `json:"-"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is nil for keys that do not expire.
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *ServiceAccountKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// ValidateRoles checks the role names given to a service account.
+func ValidateRoles(roles []string) error {
+	for _, role := range roles {
+		if !rolePattern.MatchString(role) {
+			return errs.ErrInvalidRole
+		}
+	}
+	return nil
+}
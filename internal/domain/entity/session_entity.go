@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. It owns the current refresh token, which
+// is rotated on every refresh.
+type Session struct {
+	ID                uuid.UUID  `json:"id"`
+	UserID            uuid.UUID  `json:"user_id"`
+	RefreshTokenID    string     `json:"-"`
+	DeviceFingerprint string     `json:"device_fingerprint"`
+	UserAgent         string     `json:"user_agent"`
+	IP                string     `json:"ip"`
+	CreatedAt         time.Time  `json:"created_at"`
+	LastSeenAt        time.Time  `json:"last_seen_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	// ImpersonatorID is the admin an impersonation session was opened for.
+	// Such sessions have no refresh token and end when their access token
+	// expires.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StatsView is an aggregate too costly to compute per request, such as the
+// daily sign-up counts, stored as last computed and refreshed periodically.
+type StatsView struct {
+	Name        string          `json:"name"`
+	Data        json.RawMessage `json:"data"`
+	RefreshedAt time.Time       `json:"refreshed_at"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription statuses mirror the billing provider's.
+const (
+	SUBSCRIPTION_STATUS_INCOMPLETE = "incomplete"
+	SUBSCRIPTION_STATUS_TRIALING   = "trialing"
+	SUBSCRIPTION_STATUS_ACTIVE     = "active"
+	// SUBSCRIPTION_STATUS_PAST_DUE is a failed renewal the provider is still
+	// retrying.
+	SUBSCRIPTION_STATUS_PAST_DUE = "past_due"
+	SUBSCRIPTION_STATUS_UNPAID   = "unpaid"
+	SUBSCRIPTION_STATUS_CANCELED = "canceled"
+)
+
+// Subscription is a user's paid plan, synced from the billing provider's
+// webhooks. ProviderID is the provider's subscription ID and CustomerID its
+// customer, reused for the user's later checkouts.
+type Subscription struct {
+	ID                uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	ProviderID        string    `json:"-"`
+	CustomerID        string    `json:"-"`
+	Plan              string    `json:"plan"`
+	Status            string    `json:"status"`
+	CurrentPeriodEnd  time.Time `json:"current_period_end"`
+	CancelAtPeriodEnd bool      `json:"cancel_at_period_end"`
+	// SyncedAt is the creation time of the provider event last applied, so
+	// events delivered out of order cannot roll the state back.
+	SyncedAt  time.Time `json:"synced_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Entitles reports whether the subscription grants its plan. Past-due
+// subscriptions keep it while the provider retries the payment.
+func (s *Subscription) Entitles() bool {
+	switch s.Status {
+	case SUBSCRIPTION_STATUS_ACTIVE, SUBSCRIPTION_STATUS_TRIALING, SUBSCRIPTION_STATUS_PAST_DUE:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TermsVersions identifies a revision of the legal documents users accept.
+type TermsVersions struct {
+	Terms   string `json:"terms_version"`
+	Privacy string `json:"privacy_version"`
+}
+
+// TermsAcceptance records a user agreeing to specific document versions.
+type TermsAcceptance struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	TermsVersions
+	IP         string    `json:"ip"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
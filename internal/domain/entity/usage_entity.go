@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metered usage metrics.
+const (
+	USAGE_METRIC_API_CALLS = "api_calls"
+	// USAGE_METRIC_STORAGE_BYTES is a level rather than a count: events
+	// report the current total and the period keeps its peak.
+	USAGE_METRIC_STORAGE_BYTES = "storage_bytes"
+	USAGE_METRIC_JOBS_EXECUTED = "jobs_executed"
+)
+
+// UsageMetrics lists the metered metrics in report order.
+var UsageMetrics = []string{
+	USAGE_METRIC_API_CALLS,
+	USAGE_METRIC_STORAGE_BYTES,
+	USAGE_METRIC_JOBS_EXECUTED,
+}
+
+func ValidUsageMetric(metric string) bool {
+	switch metric {
+	case USAGE_METRIC_API_CALLS, USAGE_METRIC_STORAGE_BYTES, USAGE_METRIC_JOBS_EXECUTED:
+		return true
+	}
+	return false
+}
+
+// UsageIsPeak reports whether metric aggregates by peak instead of sum.
+func UsageIsPeak(metric string) bool {
+	return metric == USAGE_METRIC_STORAGE_BYTES
+}
+
+// UsageRecord is a user's aggregated usage of one metric over a billing
+// period, which is a calendar month in UTC starting at PeriodStart.
+type UsageRecord struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Metric      string    `json:"metric"`
+	PeriodStart time.Time `json:"period_start"`
+	Quantity    int64     `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UsagePeriod returns the bounds of the billing period containing t.
+func UsagePeriod(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
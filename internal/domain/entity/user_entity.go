@@ -4,28 +4,167 @@ import (
 	"errors"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/validate"
 )
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
-
 type User struct {
-	ID             uuid.UUID  `json:"id"`
-	Email          string     `json:"email"`
-	HashedPassword string     `json:"-"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      *time.Time `json:"updated_at"`
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	Username string    `json:"username,omitempty"`
+	// Phone is E.164-normalized; it can be used to sign in only once
+	// PhoneVerifiedAt is set.
+	Phone           string     `json:"phone,omitempty"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	HashedPassword  string     `json:"-"`
+	// PasswordChangedAt is nil until the first change; the password then
+	// dates from CreatedAt.
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	// PasswordChangeRequired is set by an admin to force a new password at
+	// the next sign-in.
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
+	// PasswordUnset marks a HashedPassword of a random secret nobody knows,
+	// as given to accounts provisioned through SAML or whose password was
+	// unlinked. Setting a password clears it.
+	PasswordUnset bool `json:"password_unset,omitempty"`
+	// IsGuest marks an anonymous account without credentials. Upgrading it
+	// sets Email and HashedPassword and keeps the same ID.
+	IsGuest bool `json:"is_guest,omitempty"`
+	// TenantID is set on accounts that sign in through a tenant's SAML
+	// connection; it is carried in their tokens.
+	TenantID string `json:"tenant_id,omitempty"`
+	// Status is one of the USER_STATUS_ values; only active users can sign
+	// in or use their tokens.
+	Status          string     `json:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	// AccessRevokedAt is when an admin last revoked the user's access; the
+	// access tokens issued until then are rejected.
+	AccessRevokedAt *time.Time `json:"access_revoked_at,omitempty"`
+	// Plan selects the user's request quota; empty means the default plan.
+	Plan string `json:"plan,omitempty"`
+	// DeletedAt is set when the user deletes the account. It can be restored
+	// from the emailed link until PurgeAt, when it is deleted for good.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// SCOPE_GUEST is the token scope of guest accounts.
+const SCOPE_GUEST = "guest"
+
+// ROLE_ADMIN is the token role of the accounts allowed to use the admin API
+// and dashboard.
+const ROLE_ADMIN = "admin"
+
+const (
+	USER_STATUS_ACTIVE = "active"
+	// USER_STATUS_SUSPENDED locks the account until an admin reactivates it.
+	USER_STATUS_SUSPENDED = "suspended"
+	// USER_STATUS_BANNED locks the account and signs it out everywhere.
+	USER_STATUS_BANNED = "banned"
+)
+
+func ValidUserStatus(status string) bool {
+	switch status {
+	case USER_STATUS_ACTIVE, USER_STATUS_SUSPENDED, USER_STATUS_BANNED:
+		return true
+	default:
+		return false
+	}
 }
 
 func (u *User) Validate() error {
-	errs := validate.Var(u.Email, "required,email")
-	if errs != nil {
-		return errs
+	if u.IsGuest {
+		return nil
 	}
-	errs = validate.Var(u.HashedPassword, "required")
-	if errs != nil {
+	if err := validate.Var("email", u.Email, "required,email"); err != nil {
+		return err
+	}
+	if u.Username != "" {
+		if err := ValidateUsername(u.Username); err != nil {
+			return err
+		}
+	}
+	if u.Phone != "" {
+		if _, err := NormalizePhone(u.Phone); err != nil {
+			return err
+		}
+	}
+	if u.HashedPassword == "" {
 		return errors.New("hashed password is required")
 	}
 	return nil
 }
+
+// HasUsablePassword reports whether the user can sign in with a password.
+func (u *User) HasUsablePassword() bool {
+	return !u.IsGuest && !u.PasswordUnset
+}
+
+// HasVerifiedPhone reports whether the user can sign in by phone.
+func (u *User) HasVerifiedPhone() bool {
+	return u.Phone != "" && u.PhoneVerifiedAt != nil
+}
+
+// IsDeleted reports whether the account was deleted and awaits its purge.
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// CheckStatus returns the error a locked or deleted account is rejected
+// with, or nil for an active one. Accounts created before statuses existed
+// are active.
+func (u *User) CheckStatus() error {
+	if u.IsDeleted() {
+		return errs.ErrAccountDeleted
+	}
+	switch u.Status {
+	case USER_STATUS_SUSPENDED:
+		return errs.ErrAccountSuspended
+	case USER_STATUS_BANNED:
+		return errs.ErrAccountBanned
+	default:
+		return nil
+	}
+}
+
+// AccessRevoked reports whether an access token issued at issuedAt was
+// revoked with the user's access. Tokens carry their issue time to the
+// second, so one issued in the second of the revocation counts as revoked.
+func (u *User) AccessRevoked(issuedAt time.Time) bool {
+	return u.AccessRevokedAt != nil && !issuedAt.After(u.AccessRevokedAt.Truncate(time.Second))
+}
+
+// SetPassword replaces the password hash, restarting its max age and
+// clearing any forced rotation.
+func (u *User) SetPassword(hashed string, at time.Time) {
+	u.HashedPassword = hashed
+	u.PasswordChangedAt = &at
+	u.PasswordChangeRequired = false
+	u.PasswordUnset = false
+}
+
+// UnsetPassword replaces the password with the hash of a random secret, so
+// it can no longer be signed in with until a new one is set.
+func (u *User) UnsetPassword(hashed string, at time.Time) {
+	u.SetPassword(hashed, at)
+	u.PasswordUnset = true
+}
+
+// PasswordExpired reports whether the password must be changed before the
+// next sign-in. A maxAge of zero disables expiry.
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if u.PasswordChangeRequired {
+		return true
+	}
+	if maxAge <= 0 {
+		return false
+	}
+	changedAt := u.CreatedAt
+	if u.PasswordChangedAt != nil {
+		changedAt = *u.PasswordChangedAt
+	}
+	return now.After(changedAt.Add(maxAge))
+}
@@ -0,0 +1,225 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User event types. Each carries the userEventData fields listed with it.
+const (
+	// USER_EVENT_CREATED carries every field.
+	USER_EVENT_CREATED = "user.created"
+	// USER_EVENT_EMAIL_CHANGED carries email.
+	USER_EVENT_EMAIL_CHANGED = "user.email_changed"
+	// USER_EVENT_USERNAME_CHANGED carries username.
+	USER_EVENT_USERNAME_CHANGED = "user.username_changed"
+	// USER_EVENT_PHONE_CHANGED carries phone and phone_verified_at.
+	USER_EVENT_PHONE_CHANGED = "user.phone_changed"
+	// USER_EVENT_PASSWORD_CHANGED carries hashed_password,
+	// password_changed_at, password_change_required and password_unset.
+	USER_EVENT_PASSWORD_CHANGED = "user.password_changed"
+	// USER_EVENT_GUEST_UPGRADED carries is_guest.
+	USER_EVENT_GUEST_UPGRADED = "user.guest_upgraded"
+	// USER_EVENT_STATUS_CHANGED carries status and status_changed_at.
+	USER_EVENT_STATUS_CHANGED = "user.status_changed"
+	// USER_EVENT_PLAN_CHANGED carries plan.
+	USER_EVENT_PLAN_CHANGED = "user.plan_changed"
+	// USER_EVENT_ACCESS_REVOKED carries access_revoked_at.
+	USER_EVENT_ACCESS_REVOKED = "user.access_revoked"
+	// USER_EVENT_DELETION_CHANGED carries deleted_at and purge_at, both
+	// empty when the account is restored.
+	USER_EVENT_DELETION_CHANGED = "user.deletion_changed"
+	// USER_EVENT_DELETED carries nothing; the user no longer exists after it.
+	USER_EVENT_DELETED = "user.deleted"
+)
+
+// UserEvent is one change to a user in the event-sourced user store. Version
+// numbers a user's events from 1 without gaps.
+type UserEvent struct {
+	UserID     uuid.UUID       `json:"user_id"`
+	Version    int             `json:"version"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// userEventData is the payload of every event type; each type fills only
+// its own fields. Unlike User's JSON it includes the password hash, since
+// the events are the only record of it.
+type userEventData struct {
+	Email                  string     `json:"email,omitempty"`
+	Username               string     `json:"username,omitempty"`
+	Phone                  string     `json:"phone,omitempty"`
+	PhoneVerifiedAt        *time.Time `json:"phone_verified_at,omitempty"`
+	HashedPassword         string     `json:"hashed_password,omitempty"`
+	PasswordChangedAt      *time.Time `json:"password_changed_at,omitempty"`
+	PasswordChangeRequired bool       `json:"password_change_required,omitempty"`
+	PasswordUnset          bool       `json:"password_unset,omitempty"`
+	IsGuest                bool       `json:"is_guest,omitempty"`
+	TenantID               string     `json:"tenant_id,omitempty"`
+	Status                 string     `json:"status,omitempty"`
+	StatusChangedAt        *time.Time `json:"status_changed_at,omitempty"`
+	AccessRevokedAt        *time.Time `json:"access_revoked_at,omitempty"`
+	Plan                   string     `json:"plan,omitempty"`
+	DeletedAt              *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt                *time.Time `json:"purge_at,omitempty"`
+}
+
+type userChange struct {
+	typ  string
+	data userEventData
+}
+
+// UserDeleted returns the event that deletes the user with id at at.
+func UserDeleted(id uuid.UUID, at time.Time) UserEvent {
+	return UserEvent{UserID: id, Type: USER_EVENT_DELETED, Data: json.RawMessage("{}"), OccurredAt: at}
+}
+
+// UserChanges returns the events that turn before into after, at is their
+// time. A nil before yields a single USER_EVENT_CREATED. Versions are left
+// for the store to assign.
+func UserChanges(before *User, after *User, at time.Time) ([]UserEvent, error) {
+	var changes []userChange
+	add := func(typ string, data userEventData) {
+		changes = append(changes, userChange{typ, data})
+	}
+
+	if before == nil {
+		add(USER_EVENT_CREATED, userEventData{
+			Email:                  after.Email,
+			Username:               after.Username,
+			Phone:                  after.Phone,
+			PhoneVerifiedAt:        after.PhoneVerifiedAt,
+			HashedPassword:         after.HashedPassword,
+			PasswordChangedAt:      after.PasswordChangedAt,
+			PasswordChangeRequired: after.PasswordChangeRequired,
+			PasswordUnset:          after.PasswordUnset,
+			IsGuest:                after.IsGuest,
+			TenantID:               after.TenantID,
+			Status:                 after.Status,
+			StatusChangedAt:        after.StatusChangedAt,
+			AccessRevokedAt:        after.AccessRevokedAt,
+			Plan:                   after.Plan,
+			DeletedAt:              after.DeletedAt,
+			PurgeAt:                after.PurgeAt,
+		})
+	} else {
+		if before.Email != after.Email {
+			add(USER_EVENT_EMAIL_CHANGED, userEventData{Email: after.Email})
+		}
+		if before.Username != after.Username {
+			add(USER_EVENT_USERNAME_CHANGED, userEventData{Username: after.Username})
+		}
+		if before.Phone != after.Phone || !sameTime(before.PhoneVerifiedAt, after.PhoneVerifiedAt) {
+			add(USER_EVENT_PHONE_CHANGED, userEventData{Phone: after.Phone, PhoneVerifiedAt: after.PhoneVerifiedAt})
+		}
+		if before.HashedPassword != after.HashedPassword ||
+			!sameTime(before.PasswordChangedAt, after.PasswordChangedAt) ||
+			before.PasswordChangeRequired != after.PasswordChangeRequired ||
+			before.PasswordUnset != after.PasswordUnset {
+			add(USER_EVENT_PASSWORD_CHANGED, userEventData{
+				HashedPassword:         after.HashedPassword,
+				PasswordChangedAt:      after.PasswordChangedAt,
+				PasswordChangeRequired: after.PasswordChangeRequired,
+				PasswordUnset:          after.PasswordUnset,
+			})
+		}
+		if before.IsGuest != after.IsGuest {
+			add(USER_EVENT_GUEST_UPGRADED, userEventData{IsGuest: after.IsGuest})
+		}
+		if before.Status != after.Status || !sameTime(before.StatusChangedAt, after.StatusChangedAt) {
+			add(USER_EVENT_STATUS_CHANGED, userEventData{Status: after.Status, StatusChangedAt: after.StatusChangedAt})
+		}
+		if !sameTime(before.AccessRevokedAt, after.AccessRevokedAt) {
+			add(USER_EVENT_ACCESS_REVOKED, userEventData{AccessRevokedAt: after.AccessRevokedAt})
+		}
+		if before.Plan != after.Plan {
+			add(USER_EVENT_PLAN_CHANGED, userEventData{Plan: after.Plan})
+		}
+		if !sameTime(before.DeletedAt, after.DeletedAt) || !sameTime(before.PurgeAt, after.PurgeAt) {
+			add(USER_EVENT_DELETION_CHANGED, userEventData{DeletedAt: after.DeletedAt, PurgeAt: after.PurgeAt})
+		}
+	}
+
+	events := make([]UserEvent, 0, len(changes))
+	for _, c := range changes {
+		data, err := json.Marshal(c.data)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s event: %w", c.typ, err)
+		}
+		events = append(events, UserEvent{UserID: after.ID, Type: c.typ, Data: data, OccurredAt: at})
+	}
+	return events, nil
+}
+
+// Apply replays e onto u, rehydrating the user one event at a time.
+func (u *User) Apply(e UserEvent) error {
+	var d userEventData
+	if err := json.Unmarshal(e.Data, &d); err != nil {
+		return fmt.Errorf("decode %s event %d: %w", e.Type, e.Version, err)
+	}
+
+	switch e.Type {
+	case USER_EVENT_CREATED:
+		*u = User{
+			ID:                     e.UserID,
+			Email:                  d.Email,
+			Username:               d.Username,
+			Phone:                  d.Phone,
+			PhoneVerifiedAt:        d.PhoneVerifiedAt,
+			HashedPassword:         d.HashedPassword,
+			PasswordChangedAt:      d.PasswordChangedAt,
+			PasswordChangeRequired: d.PasswordChangeRequired,
+			PasswordUnset:          d.PasswordUnset,
+			IsGuest:                d.IsGuest,
+			TenantID:               d.TenantID,
+			Status:                 d.Status,
+			StatusChangedAt:        d.StatusChangedAt,
+			AccessRevokedAt:        d.AccessRevokedAt,
+			Plan:                   d.Plan,
+			DeletedAt:              d.DeletedAt,
+			PurgeAt:                d.PurgeAt,
+			CreatedAt:              e.OccurredAt,
+		}
+		return nil
+	case USER_EVENT_EMAIL_CHANGED:
+		u.Email = d.Email
+	case USER_EVENT_USERNAME_CHANGED:
+		u.Username = d.Username
+	case USER_EVENT_PHONE_CHANGED:
+		u.Phone, u.PhoneVerifiedAt = d.Phone, d.PhoneVerifiedAt
+	case USER_EVENT_PASSWORD_CHANGED:
+		u.HashedPassword = d.HashedPassword
+		u.PasswordChangedAt = d.PasswordChangedAt
+		u.PasswordChangeRequired = d.PasswordChangeRequired
+		u.PasswordUnset = d.PasswordUnset
+	case USER_EVENT_GUEST_UPGRADED:
+		u.IsGuest = d.IsGuest
+	case USER_EVENT_STATUS_CHANGED:
+		u.Status, u.StatusChangedAt = d.Status, d.StatusChangedAt
+	case USER_EVENT_ACCESS_REVOKED:
+		u.AccessRevokedAt = d.AccessRevokedAt
+	case USER_EVENT_PLAN_CHANGED:
+		u.Plan = d.Plan
+	case USER_EVENT_DELETION_CHANGED:
+		u.DeletedAt, u.PurgeAt = d.DeletedAt, d.PurgeAt
+	case USER_EVENT_DELETED:
+		*u = User{}
+		return nil
+	default:
+		return fmt.Errorf("unknown user event type %q", e.Type)
+	}
+	at := e.OccurredAt
+	u.UpdatedAt = &at
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
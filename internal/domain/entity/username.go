@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{2,29}$`)
+
+// reservedUsernames could be confused with system accounts or collide with
+// routes.
+var reservedUsernames = map[string]struct{}{
+	"abuse": {}, "admin": {}, "administrator": {}, "api": {}, "auth": {},
+	"help": {}, "info": {}, "me": {}, "moderator": {}, "noreply": {},
+	"no-reply": {}, "postmaster": {}, "root": {}, "security": {},
+	"support": {}, "system": {}, "webmaster": {},
+}
+
+// NormalizeUsername returns the canonical (lower-case) form usernames are
+// stored and compared in.
+func NormalizeUsername(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidateUsername checks a normalized username: 3-30 characters of letters,
+// digits, '_', '.' or '-', starting with a letter, and not reserved.
+func ValidateUsername(name string) error {
+	if !usernamePattern.MatchString(name) {
+		return errs.ErrInvalidUsername
+	}
+	if _, ok := reservedUsernames[name]; ok {
+		return errs.ErrUsernameReserved
+	}
+	return nil
+}
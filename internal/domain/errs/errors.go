@@ -0,0 +1,199 @@
+package errs
+
+import (
+	"errors"
+
+	"github.com/haidang666/go-app/pkg/apperr"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email is already taken")
+	// ErrConcurrentUpdate reports that another write to the same user won;
+	// the caller may reload and retry.
+	ErrConcurrentUpdate = errors.New("user was modified concurrently")
+
+	ErrUsernameTaken    = errors.New("username is already taken")
+	ErrInvalidUsername  = errors.New("username must be 3-30 letters, digits, '_', '.' or '-' and start with a letter")
+	ErrUsernameReserved = errors.New("username is reserved")
+
+	ErrInvalidPhone    = errors.New("phone number must be in international format, e.g. +14155550100")
+	ErrPhoneTaken      = errors.New("phone number is already in use")
+	ErrInvalidOTP      = errors.New("invalid or expired code")
+	ErrOTPRateLimited  = errors.New("too many codes requested, try again later")
+	ErrPhoneNotPending = errors.New("no phone number is awaiting verification")
+
+	ErrIdentityNotLinked     = errors.New("this sign-in method is not linked to your account")
+	ErrIdentityAlreadyLinked = errors.New("this sign-in method is already linked to your account")
+	ErrIdentityManaged       = errors.New("this sign-in method is managed by your organization")
+	ErrLastIdentity          = errors.New("link another sign-in method before removing your last one")
+	// ErrRecentSignInRequired asks a user without a password to sign in
+	// again before changing how they sign in.
+	ErrRecentSignInRequired = errors.New("sign in again to confirm this change")
+
+	ErrGuestAccessDisabled = errors.New("guest access is disabled")
+	ErrGuestNotAllowed     = errors.New("create an account to use this feature")
+	ErrNotGuest            = errors.New("account is not a guest account")
+
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+	ErrInvalidClient       = errors.New("invalid client credentials")
+	ErrInvalidScope        = errors.New("requested scope exceeds the client's grant")
+	// ErrIntrospectionNotAllowed is returned to clients not granted the
+	// introspect scope.
+	ErrIntrospectionNotAllowed = errors.New("client is not allowed to introspect tokens")
+	ErrUnsupportedGrantType    = errors.New("unsupported grant type")
+	ErrInvalidRedirectURI      = errors.New("redirect_uri is not registered for the client")
+	ErrInvalidGrant            = errors.New("authorization code is invalid, expired or already used")
+
+	ErrSAMLConnectionNotFound = errors.New("no SAML connection is configured for this tenant")
+	ErrSAMLConnectionExists   = errors.New("tenant already has a SAML connection")
+	ErrInvalidTenant          = errors.New("tenant must be 2-63 lower-case letters, digits or '-'")
+	ErrInvalidCertificate     = errors.New("identity provider certificate must be a PEM-encoded X.509 certificate")
+	ErrInvalidSAMLResponse    = errors.New("invalid SAML response")
+	ErrSAMLAccountConflict    = errors.New("an account with this email exists outside the tenant")
+	ErrInvalidCallbackDomain  = errors.New("callback domains must be lower-case hosts, optionally with a port and a leading '*.'")
+	ErrCallbackNotAllowed     = errors.New("return_to is not on a callback domain the organization allows")
+	ErrAdminRoleNotAllowed    = errors.New("the admin role cannot be granted to an organization's members")
+	ErrSAMLUserNotProvisioned = errors.New("no account exists for this user and just-in-time provisioning is disabled")
+	ErrInvalidLoginState      = errors.New("sign-in was not started in this browser or has expired")
+
+	ErrExternalIdentityNotFound   = errors.New("external identity not found")
+	ErrExternalIdentityExists     = errors.New("external identity is already linked to an account")
+	ErrExternalAccountConflict    = errors.New("an account with this email exists and cannot be linked to the external identity")
+	ErrExternalUserNotProvisioned = errors.New("no account is linked to this external identity")
+
+	ErrEmailDomainNotAllowed = errors.New("sign-up is not open to this email domain")
+	ErrDisposableEmail       = errors.New("disposable email addresses are not allowed")
+	ErrEmailAliasTaken       = errors.New("an account already exists for this address without the +alias")
+
+	ErrInviteRequired     = errors.New("sign-up requires an invitation code")
+	ErrInvalidInvite      = errors.New("invitation code is invalid, expired or used up")
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	ErrCaptchaRequired = errors.New("captcha token is required")
+	ErrCaptchaFailed   = errors.New("captcha verification failed")
+	// ErrCaptchaUnavailable reports a CAPTCHA provider outage.
+	ErrCaptchaUnavailable = errors.New("captcha verification is unavailable, try again later")
+	ErrSignUpBlocked      = errors.New("sign-up was refused as likely automated")
+
+	ErrInvalidResetToken = errors.New("password reset link is invalid or expired")
+	// ErrPasswordHashingBusy reports that every password hashing worker is
+	// busy and the queue is full; the caller should retry shortly.
+	ErrPasswordHashingBusy = apperr.New("password_hashing_busy", "too many password checks in progress, retry later")
+
+	ErrTermsNotAccepted = errors.New("the current terms of service and privacy policy must be accepted")
+	ErrTermsOutdated    = errors.New("accepted versions do not match the current terms of service and privacy policy")
+
+	ErrInvalidEmailChangeToken = errors.New("email change link is invalid or expired")
+	ErrSameEmail               = errors.New("new email is the same as the current one")
+
+	ErrAccountSuspended = apperr.New("account_suspended", "account is suspended")
+	ErrAccountBanned    = apperr.New("account_banned", "account is banned")
+	ErrInvalidStatus    = errors.New("status must be one of active, suspended or banned")
+	ErrCannotLockSelf   = errors.New("cannot suspend or ban yourself")
+	ErrCannotRevokeSelf = errors.New("cannot revoke your own access")
+	// ErrAccountDeleted rejects an account deleted by its owner while it can
+	// still be restored.
+	ErrAccountDeleted      = apperr.New("account_deleted", "account is deleted, use the link emailed at deletion to restore it")
+	ErrInvalidRestoreToken = errors.New("account restore link is invalid or expired")
+
+	ErrUnknownPlan        = errors.New("plan is not one of the configured quota plans")
+	ErrInvalidQuotaLimits = errors.New("limits must be requests/window pairs separated by semicolons, e.g. 60/1m;5000/24h")
+
+	ErrUnknownBillingPlan      = errors.New("plan is not available for purchase")
+	ErrAlreadySubscribed       = errors.New("user already has an active subscription")
+	ErrSubscriptionNotFound    = errors.New("subscription not found")
+	ErrInvalidWebhookSignature = errors.New("webhook signature is invalid or expired")
+	ErrBillingProviderFailed   = errors.New("billing provider request failed")
+	ErrQuotaExceeded           = apperr.New("quota_exceeded", "request quota exceeded")
+
+	ErrInvalidWebhookPayload = errors.New("webhook payload is malformed")
+	// ErrWebhookInProgress reports a repeated delivery arriving while the
+	// first one is still processed; the sender should retry it later.
+	ErrWebhookInProgress     = errors.New("webhook delivery is already being processed")
+	ErrOutboundEmailNotFound = errors.New("email not found")
+	// ErrEmailSuppressionNotFound reports an address that is not on the
+	// suppression list.
+	ErrEmailSuppressionNotFound = errors.New("email address is not suppressed")
+	ErrScheduledJobNotFound     = errors.New("scheduled job not found")
+	ErrScheduledJobNotDead      = errors.New("only failed jobs can be requeued or discarded")
+	// ErrScheduledJobSuperseded reports a failed job whose key has been
+	// scheduled again since; requeuing it would run the work twice.
+	ErrScheduledJobSuperseded = errors.New("a newer job with the same key is pending")
+	ErrEmailNotResendable     = errors.New("only failed, bounced or suppressed email can be resent")
+	ErrStatsViewNotFound      = errors.New("stats view not found")
+	ErrAdminTaskNotFound      = errors.New("admin task not found")
+	// ErrInvalidAdminTaskParams wraps what a task found wrong with the
+	// params it was triggered with.
+	ErrInvalidAdminTaskParams = errors.New("invalid admin task params")
+	ErrNoPendingEmailChange   = errors.New("user has no pending email change")
+
+	ErrUpgradeRequired = errors.New("a higher plan is required")
+	ErrUnknownPlanTier = errors.New("plan is not one of the configured plan tiers")
+
+	ErrUnknownUsageMetric   = errors.New("unknown usage metric")
+	ErrInvalidUsageQuantity = errors.New("usage quantity must not be negative")
+
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrPasswordExpired    = errors.New("password has expired and must be changed before signing in")
+	ErrPasswordNotExpired = errors.New("password has not expired")
+	ErrPasswordReused     = errors.New("new password must differ from the current one")
+	// ErrWeakPassword reports a password too similar to the account's email
+	// or username, or made of obvious sequences; its "reason" field says
+	// which.
+	ErrWeakPassword = apperr.New("weak_password", "password is too easy to guess")
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	ErrCannotImpersonateSelf   = errors.New("cannot impersonate yourself")
+	ErrImpersonationNotAllowed = errors.New("not allowed while impersonating a user")
+
+	ErrAdminRequired = errors.New("administrator role is required")
+	ErrRoleRequired  = errors.New("a role this endpoint requires is missing")
+
+	ErrIdempotencyKeyRequired  = errors.New("an Idempotency-Key header of at most 255 characters is required")
+	ErrRequestAlreadyProcessed = errors.New("a request with this Idempotency-Key was already processed")
+	ErrRequestInProgress       = errors.New("a request with this Idempotency-Key is still being processed")
+
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked or expired")
+	ErrSessionTooOld   = errors.New("session is older than the organization allows, sign in again")
+	// ErrAccessRevoked rejects an access token issued before an admin
+	// revoked the user's access.
+	ErrAccessRevoked = errors.New("access has been revoked, sign in again")
+	// ErrDependencyUnavailable reports a store that is down while the
+	// feature needing it is set to fail closed.
+	ErrDependencyUnavailable = errors.New("a required service is unavailable, try again later")
+	// ErrReadOnlyMode rejects writes while the service is in maintenance;
+	// its details carry the reason and since when.
+	ErrReadOnlyMode           = apperr.New("read_only_mode", "the service is read-only during maintenance, retry later")
+	ErrInvalidMaintenanceMode = errors.New("reason must be maintenance, failover or migration")
+
+	// ErrTooManyAttempts rejects sign-ups and sign-ins beyond the tighter
+	// limits applied while a failure spike is detected.
+	ErrTooManyAttempts           = errors.New("too many attempts from this address, try again later")
+	ErrInvalidAuthProtectionMode = errors.New("mode must be auto, on or off")
+
+	ErrPersonalTokenNotFound   = errors.New("personal access token not found")
+	ErrPersonalTokenExpiry     = errors.New("expires_at must be in the future")
+	ErrPersonalTokenLimit      = errors.New("too many personal access tokens, revoke one first")
+	ErrPersonalTokenNotAllowed = errors.New("not allowed with a personal access token")
+	ErrPersonalTokenScope      = errors.New("personal access token lacks the required scope")
+
+	ErrServiceAccountNotFound    = errors.New("service account not found")
+	ErrServiceAccountKeyNotFound = errors.New("service account key not found")
+	ErrServiceAccountKeyLimit    = errors.New("too many service account keys, revoke one first")
+	ErrServiceAccountNotAllowed  = errors.New("not allowed for a service account")
+	ErrInvalidRole               = errors.New("roles must be 1-63 lower-case letters, digits, '_', '.' or '-'")
+
+	ErrDeviceVerificationRequired = errors.New("sign-in from a new device must be approved via the emailed link")
+	ErrDeviceApprovalNotFound     = errors.New("device approval link is invalid or expired")
+	ErrDeviceApprovalDecided      = errors.New("device sign-in has already been reviewed")
+
+	ErrNotificationPreferencesNotFound = errors.New("notification preferences not found")
+	ErrSecurityNotificationsRequired   = errors.New("security notifications cannot be turned off")
+	ErrNotificationSMSUnavailable      = errors.New("verify a phone number before turning on SMS notifications")
+	ErrQuietHoursIncomplete            = errors.New("quiet hours need both a start and an end")
+	ErrNotificationNotFound            = errors.New("notification not found")
+
+	ErrCountryNotAllowed = errors.New("access is not available in your country")
+)
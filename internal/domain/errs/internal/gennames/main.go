@@ -0,0 +1,68 @@
+// Command gennames writes names_gen.go, which lists the sentinel errors
+// declared in errors.go with stable snake_case names for metric labels.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "errors.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gennames; DO NOT EDIT.\n\npackage errs\n\n")
+	buf.WriteString("var sentinels = []sentinel{\n")
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				if strings.HasPrefix(name.Name, "Err") {
+					fmt.Fprintf(&buf, "\t{%s, %q},\n", name.Name, snake(strings.TrimPrefix(name.Name, "Err")))
+				}
+			}
+		}
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("names_gen.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// snake converts CamelCase to snake_case, keeping acronyms together:
+// SAMLConnectionNotFound becomes saml_connection_not_found.
+func snake(s string) string {
+	runes := []rune(strings.ReplaceAll(s, "OAuth", "Oauth"))
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			endsAcronym := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || endsAcronym {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
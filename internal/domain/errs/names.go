@@ -0,0 +1,38 @@
+package errs
+
+import (
+	"context"
+	"errors"
+)
+
+//go:generate go run ./internal/gennames
+
+type sentinel struct {
+	err  error
+	name string
+}
+
+// Name returns a stable, low-cardinality name for err, suitable as a metric
+// label: the code of a coded error, the snake_case name of the sentinel it
+// wraps, or "internal" for anything else.
+func Name(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.name
+		}
+	}
+	return "internal"
+}
@@ -0,0 +1,37 @@
+package errs
+
+import "fmt"
+
+// UpgradeRequiredError reports that the caller's plan is below the tier a
+// feature requires. It matches ErrUpgradeRequired with errors.Is.
+type UpgradeRequiredError struct {
+	RequiredPlan string
+	CurrentPlan  string
+	// Feature is set when the requirement comes from a gated feature.
+	Feature string
+}
+
+func (e *UpgradeRequiredError) Error() string {
+	return fmt.Sprintf("plan %q or higher is required", e.RequiredPlan)
+}
+
+func (e *UpgradeRequiredError) Is(target error) bool {
+	return target == ErrUpgradeRequired
+}
+
+func (e *UpgradeRequiredError) ErrorCode() string {
+	return "upgrade_required"
+}
+
+// ErrorDetails lets clients offer the right upgrade without parsing the
+// message.
+func (e *UpgradeRequiredError) ErrorDetails() map[string]any {
+	details := map[string]any{
+		"required_plan": e.RequiredPlan,
+		"current_plan":  e.CurrentPlan,
+	}
+	if e.Feature != "" {
+		details["feature"] = e.Feature
+	}
+	return details
+}
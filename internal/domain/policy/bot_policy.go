@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/securecookie"
+)
+
+// The risk each bot signal adds to a sign-up's score.
+const (
+	// riskHoneypot is enough on its own to reach the default block score:
+	// people never see the field.
+	riskHoneypot         = 100
+	riskSubmittedTooFast = 60
+	riskInvalidForm      = 40
+	riskMissingForm      = 20
+	riskNoUserAgent      = 40
+	riskAutomatedAgent   = 40
+)
+
+// automatedAgents are user agent fragments, lower case, of HTTP libraries
+// and headless browsers rather than people's browsers.
+var automatedAgents = []string{
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "go-http-client",
+	"java/", "libwww-perl", "httpclient", "scrapy", "phantomjs", "headlesschrome",
+	"selenium", "puppeteer", "playwright", "bot", "crawler", "spider",
+}
+
+// signUpFormToken is the name form tokens are sealed under.
+const signUpFormToken = "signup_form"
+
+var botChecksTotal = metrics.NewCounter("signup_bot_checks_total",
+	"Sign-ups checked for bots by outcome (allowed, captcha_required, captcha_passed, captcha_failed, blocked).", "outcome")
+
+type BotPolicyArgs struct {
+	// Cookies seals the form tokens.
+	Cookies *securecookie.Codec
+	// MinSubmitTime is how long a person takes at least to fill in the form.
+	MinSubmitTime time.Duration
+	// FormTTL is how long a form token stays valid.
+	FormTTL    time.Duration
+	Reputation contract.IPReputation
+	// Captcha verifies the CAPTCHA asked of risky sign-ups; when nil, sign-ups
+	// risky enough to need one are blocked.
+	Captcha contract.CaptchaVerifier
+	// CaptchaScore is the risk score from which a CAPTCHA is required, and
+	// BlockScore the one from which the sign-up is refused.
+	CaptchaScore int
+	BlockScore   int
+}
+
+// BotPolicy scores a sign-up on signals of automation, namely a filled-in
+// honeypot field, a form submitted too fast or never shown, the user agent
+// and the reputation of the address, and asks risky sign-ups for a CAPTCHA
+// or refuses them. Sign-ups without BotSignals are not checked.
+type BotPolicy struct {
+	cookies       *securecookie.Codec
+	minSubmitTime time.Duration
+	formTTL       time.Duration
+	reputation    contract.IPReputation
+	captcha       contract.CaptchaVerifier
+	captchaScore  int
+	blockScore    int
+}
+
+func NewBotPolicy(args BotPolicyArgs) *BotPolicy {
+	return &BotPolicy{
+		cookies:       args.Cookies,
+		minSubmitTime: args.MinSubmitTime,
+		formTTL:       args.FormTTL,
+		reputation:    args.Reputation,
+		captcha:       args.Captcha,
+		captchaScore:  args.CaptchaScore,
+		blockScore:    args.BlockScore,
+	}
+}
+
+// IssueFormToken returns the token the sign-up form is shown with, which
+// carries the time it was issued at, sealed, and when it expires.
+func (p *BotPolicy) IssueFormToken(now time.Time) (string, time.Time, error) {
+	token, err := p.cookies.Encode(signUpFormToken, []byte(strconv.FormatInt(now.UnixMilli(), 10)))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, now.Add(p.formTTL), nil
+}
+
+func (p *BotPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	if input.Bot == nil {
+		return nil
+	}
+	score, signals := p.score(ctx, input, time.Now())
+	ctxutil.SetMeta(ctx, ctxutil.MetaRiskScore, score)
+	log := ctxutil.Logger(ctx)
+
+	if score >= p.blockScore || (score >= p.captchaScore && p.captcha == nil) {
+		botChecksTotal.Inc("blocked")
+		log.Infow("sign-up blocked as automated", "score", score, "signals", signals)
+		return errs.ErrSignUpBlocked
+	}
+	if score < p.captchaScore {
+		botChecksTotal.Inc("allowed")
+		return nil
+	}
+
+	if input.Bot.CaptchaToken == "" {
+		botChecksTotal.Inc("captcha_required")
+		return errs.ErrCaptchaRequired
+	}
+	if err := p.captcha.Verify(ctx, input.Bot.CaptchaToken, input.Client.IP); err != nil {
+		botChecksTotal.Inc("captcha_failed")
+		if errors.Is(err, errs.ErrCaptchaFailed) {
+			log.Infow("sign-up failed its captcha", "score", score, "signals", signals)
+			return err
+		}
+		return fmt.Errorf("%w: %v", errs.ErrCaptchaUnavailable, err)
+	}
+	botChecksTotal.Inc("captcha_passed")
+	return nil
+}
+
+// score adds up the risk of the signals the sign-up shows and names them.
+func (p *BotPolicy) score(ctx context.Context, input *dto.SignUpInput, now time.Time) (int, []string) {
+	score := 0
+	var signals []string
+	add := func(risk int, signal string) {
+		score += risk
+		signals = append(signals, signal)
+	}
+
+	if input.Bot.Honeypot != "" {
+		add(riskHoneypot, "honeypot")
+	}
+	switch issued, ok := p.formIssuedAt(input.Bot.FormToken); {
+	case input.Bot.FormToken == "":
+		add(riskMissingForm, "missing_form_token")
+	case !ok || now.Sub(issued) > p.formTTL:
+		add(riskInvalidForm, "invalid_form_token")
+	case now.Sub(issued) < p.minSubmitTime:
+		add(riskSubmittedTooFast, "submitted_too_fast")
+	}
+
+	agent := strings.ToLower(input.Client.UserAgent)
+	if strings.TrimSpace(agent) == "" {
+		add(riskNoUserAgent, "no_user_agent")
+	} else {
+		for _, a := range automatedAgents {
+			if strings.Contains(agent, a) {
+				add(riskAutomatedAgent, "automated_user_agent")
+				break
+			}
+		}
+	}
+
+	if p.reputation != nil {
+		risk, err := p.reputation.Risk(ctx, input.Client.IP)
+		if err != nil {
+			ctxutil.Logger(ctx).Warnw("ip reputation", "ip", input.Client.IP, "error", err)
+		} else if risk > 0 {
+			add(risk, "ip_reputation")
+		}
+	}
+
+	// Without a CAPTCHA to ask for, protection must not block every
+	// sign-up.
+	if input.Bot.Protected && p.captcha != nil && score < p.captchaScore {
+		add(p.captchaScore-score, "auth_protection")
+	}
+	return score, signals
+}
+
+// formIssuedAt returns when token was issued, if it is one IssueFormToken
+// sealed.
+func (p *BotPolicy) formIssuedAt(token string) (time.Time, bool) {
+	issued, err := p.cookies.Decode(signUpFormToken, token, 0)
+	if err != nil {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(string(issued), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DomainList is a concurrency-safe set of email domains that can be replaced
+// at runtime. A listed domain also matches its subdomains.
+type DomainList struct {
+	mu      sync.RWMutex
+	domains map[string]struct{}
+}
+
+func NewDomainList(domains []string) *DomainList {
+	l := &DomainList{}
+	l.Replace(domains)
+	return l
+}
+
+func (l *DomainList) Replace(domains []string) {
+	set := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" {
+			set[d] = struct{}{}
+		}
+	}
+
+	l.mu.Lock()
+	l.domains = set
+	l.mu.Unlock()
+}
+
+func (l *DomainList) Contains(domain string) bool {
+	domain = strings.ToLower(domain)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for {
+		if _, ok := l.domains[domain]; ok {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+func (l *DomainList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.domains)
+}
+
+// Domains returns the list sorted.
+func (l *DomainList) Domains() []string {
+	l.mu.RLock()
+	out := make([]string, 0, len(l.domains))
+	for d := range l.domains {
+		out = append(out, d)
+	}
+	l.mu.RUnlock()
+
+	sort.Strings(out)
+	return out
+}
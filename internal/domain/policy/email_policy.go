@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// DefaultDisposableDomains seeds the disposable provider list until an
+// up-to-date list is loaded.
+var DefaultDisposableDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"maildrop.cc",
+	"mailinator.com",
+	"mintemail.com",
+	"sharklasers.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// AllowedDomainsPolicy restricts sign-up to the listed (e.g. corporate)
+// domains. An empty list allows every domain.
+type AllowedDomainsPolicy struct {
+	allowed *DomainList
+}
+
+func NewAllowedDomainsPolicy(allowed *DomainList) *AllowedDomainsPolicy {
+	return &AllowedDomainsPolicy{allowed: allowed}
+}
+
+func (p *AllowedDomainsPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	if p.allowed.Len() == 0 || p.allowed.Contains(emailDomain(input.Email)) {
+		return nil
+	}
+	return errs.ErrEmailDomainNotAllowed
+}
+
+// DisposableEmailPolicy blocks throwaway email providers.
+type DisposableEmailPolicy struct {
+	blocked *DomainList
+}
+
+func NewDisposableEmailPolicy(blocked *DomainList) *DisposableEmailPolicy {
+	return &DisposableEmailPolicy{blocked: blocked}
+}
+
+// Domains and Replace expose the block list so it can be kept up to date
+// without a restart.
+func (p *DisposableEmailPolicy) Domains() []string {
+	return p.blocked.Domains()
+}
+
+func (p *DisposableEmailPolicy) Replace(domains []string) {
+	p.blocked.Replace(domains)
+}
+
+func (p *DisposableEmailPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	if p.blocked.Contains(emailDomain(input.Email)) {
+		return errs.ErrDisposableEmail
+	}
+	return nil
+}
+
+// PlusAliasPolicy rejects "user+tag@example.com" when "user@example.com"
+// already has an account, so one mailbox cannot farm accounts.
+type PlusAliasPolicy struct {
+	userRepo contract.UserRepository
+}
+
+func NewPlusAliasPolicy(userRepo contract.UserRepository) *PlusAliasPolicy {
+	return &PlusAliasPolicy{userRepo: userRepo}
+}
+
+func (p *PlusAliasPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	base, ok := stripPlusTag(input.Email)
+	if !ok {
+		return nil
+	}
+
+	_, err := p.userRepo.GetByEmail(ctx, base)
+	switch {
+	case err == nil:
+		return errs.ErrEmailAliasTaken
+	case errors.Is(err, errs.ErrUserNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func stripPlusTag(email string) (string, bool) {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email, false
+	}
+	local, domain := email[:at], email[at:]
+	plus := strings.IndexByte(local, '+')
+	if plus < 0 {
+		return email, false
+	}
+	return local[:plus] + domain, true
+}
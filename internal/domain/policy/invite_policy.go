@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+var inviteRedemptions = metrics.NewCounter("invite_redemptions_total",
+	"Invite-only sign-up attempts by result.", "result")
+
+// InvitePolicy makes sign-up invite-only. The code is checked before the
+// account is created and consumed once it has been.
+type InvitePolicy struct {
+	invitationRepo contract.InvitationRepository
+}
+
+var (
+	_ contract.SignUpPolicy      = (*InvitePolicy)(nil)
+	_ contract.SignUpHook        = (*InvitePolicy)(nil)
+	_ contract.SignUpCompensator = (*InvitePolicy)(nil)
+)
+
+func NewInvitePolicy(invitationRepo contract.InvitationRepository) *InvitePolicy {
+	return &InvitePolicy{invitationRepo: invitationRepo}
+}
+
+func (p *InvitePolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	_, err := p.lookup(ctx, input)
+	if err != nil {
+		inviteRedemptions.Inc("rejected")
+	}
+	return err
+}
+
+func (p *InvitePolicy) AfterSignUp(ctx context.Context, input *dto.SignUpInput, u *entity.User) error {
+	inv, err := p.lookup(ctx, input)
+	if err == nil {
+		err = p.invitationRepo.Redeem(ctx, inv.ID, input.Email, time.Now().UTC())
+	}
+	if err != nil {
+		inviteRedemptions.Inc("lost_race")
+		return err
+	}
+	inviteRedemptions.Inc("redeemed")
+	return nil
+}
+
+// UndoSignUp gives back the use AfterSignUp consumed.
+func (p *InvitePolicy) UndoSignUp(ctx context.Context, input *dto.SignUpInput, u *entity.User) error {
+	inv, err := p.invitationRepo.GetByCodeHash(ctx, securetoken.Hash(input.InviteCode))
+	if err != nil {
+		return err
+	}
+	if err := p.invitationRepo.Release(ctx, inv.ID); err != nil {
+		return err
+	}
+	inviteRedemptions.Inc("released")
+	return nil
+}
+
+func (p *InvitePolicy) lookup(ctx context.Context, input *dto.SignUpInput) (*entity.Invitation, error) {
+	if input.InviteCode == "" {
+		return nil, errs.ErrInviteRequired
+	}
+
+	inv, err := p.invitationRepo.GetByCodeHash(ctx, securetoken.Hash(input.InviteCode))
+	if errors.Is(err, errs.ErrInvitationNotFound) {
+		return nil, errs.ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !inv.IsRedeemable(input.Email, time.Now().UTC()) {
+		return nil, errs.ErrInvalidInvite
+	}
+	return inv, nil
+}
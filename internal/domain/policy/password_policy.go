@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/apperr"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+// The reasons a password is rejected as weak, reported in the error's
+// "reason" field.
+const (
+	WEAK_PASSWORD_SIMILAR_TO_ACCOUNT = "similar_to_account"
+	WEAK_PASSWORD_SEQUENCE           = "sequence"
+)
+
+// weakPasswordMessages are the rejection messages by reason and locale;
+// locales without a translation get the English one.
+var weakPasswordMessages = map[string]map[string]string{
+	WEAK_PASSWORD_SIMILAR_TO_ACCOUNT: {
+		"en": "password is too similar to your email address or username",
+		"vi": "mật khẩu quá giống với địa chỉ email hoặc tên người dùng của bạn",
+	},
+	WEAK_PASSWORD_SEQUENCE: {
+		"en": "password is mostly an obvious keyboard or character sequence, such as qwerty or 12345",
+		"vi": "mật khẩu chủ yếu là một chuỗi phím hoặc ký tự dễ đoán, như qwerty hoặc 12345",
+	},
+}
+
+// sequences are the runs of characters people type in order: keyboard rows
+// and the alphabet and digits. Reversed runs count too.
+var sequences = []string{
+	"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890",
+	"abcdefghijklmnopqrstuvwxyz",
+}
+
+// minAttributeLength is the shortest email local part or username checked
+// for inside passwords; shorter ones match too many passwords by chance.
+const minAttributeLength = 4
+
+// PasswordPolicy rejects passwords built from the account's own email or
+// username, and passwords that are mostly keyboard or character sequences,
+// which the length and character class rules let through.
+type PasswordPolicy struct{}
+
+var (
+	_ contract.SignUpPolicy   = (*PasswordPolicy)(nil)
+	_ contract.PasswordPolicy = (*PasswordPolicy)(nil)
+)
+
+func NewPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{}
+}
+
+func (p *PasswordPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	return p.CheckPassword(ctx, input.Password, input.Email, input.Username)
+}
+
+// CheckPassword returns ErrWeakPassword, with a message in the request's
+// locale, when password is too similar to one of the account's attributes
+// (its email and username) or is mostly a sequence. An empty password, as
+// imports may have, passes.
+func (p *PasswordPolicy) CheckPassword(ctx context.Context, password string, attributes ...string) error {
+	if password == "" {
+		return nil
+	}
+	pw := strings.ToLower(password)
+	for _, attr := range attributes {
+		if similarToAttribute(pw, attr) {
+			return weakPassword(ctx, WEAK_PASSWORD_SIMILAR_TO_ACCOUNT)
+		}
+	}
+	if mostlySequences(pw) {
+		return weakPassword(ctx, WEAK_PASSWORD_SEQUENCE)
+	}
+	return nil
+}
+
+func weakPassword(ctx context.Context, reason string) error {
+	msg, ok := weakPasswordMessages[reason][ctxutil.Locale(ctx)]
+	if !ok {
+		msg = weakPasswordMessages[reason]["en"]
+	}
+	return apperr.New(errs.ErrWeakPassword.ErrorCode(), msg).With("reason", reason)
+}
+
+// similarToAttribute reports whether pw, lower-cased, contains attr (or the
+// local part of an email and its words), is contained in it, or is a few
+// edits away from it, e.g. "johnsmith1" for john.smith@example.com.
+func similarToAttribute(pw, attr string) bool {
+	attr = strings.ToLower(strings.TrimSpace(attr))
+	if attr == "" {
+		return false
+	}
+	candidates := []string{attr}
+	if local, _, ok := strings.Cut(attr, "@"); ok {
+		candidates = append(candidates, local)
+		// john.smith+news: "john", "smith" and "news" on their own, and
+		// "johnsmith" with the separators dropped.
+		words := strings.FieldsFunc(local, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		candidates = append(candidates, strings.Join(words, ""))
+		candidates = append(candidates, words...)
+	}
+
+	for _, c := range candidates {
+		if len(c) < minAttributeLength {
+			continue
+		}
+		if strings.Contains(pw, c) || strings.Contains(c, pw) {
+			return true
+		}
+		if editDistance(pw, c) <= max(len(pw), len(c))/4 {
+			return true
+		}
+	}
+	return false
+}
+
+// mostlySequences reports whether runs of at least three sequential or
+// repeated characters, such as "qwerty", "4321" or "aaa", make up three
+// quarters or more of pw.
+func mostlySequences(pw string) bool {
+	chars := []rune(pw)
+	covered, run := 0, 1
+	for i := 1; i <= len(chars); i++ {
+		if i < len(chars) && sequential(chars[i-1], chars[i]) {
+			run++
+			continue
+		}
+		if run >= 3 {
+			covered += run
+		}
+		run = 1
+	}
+	return covered*4 >= len(chars)*3
+}
+
+// sequential reports whether b follows a on a keyboard row or in the
+// alphabet or digits, either way, or repeats it.
+func sequential(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for _, seq := range sequences {
+		i := strings.IndexRune(seq, a)
+		if i < 0 {
+			continue
+		}
+		if i+1 < len(seq) && rune(seq[i+1]) == b || i > 0 && rune(seq[i-1]) == b {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
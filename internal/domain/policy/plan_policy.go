@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type PlanPolicyArgs struct {
+	UserRepo     contract.UserRepository
+	Entitlements contract.EntitlementChecker
+	// Tiers lists the plans from lowest to highest.
+	Tiers []string
+	// Features maps a feature to the lowest plan that includes it.
+	Features map[string]string
+}
+
+// PlanPolicy ranks a user by the higher of their subscription's plan and the
+// plan set on their account, so admins can still grant plans by hand. Plans
+// outside Tiers rank as the lowest tier.
+type PlanPolicy struct {
+	userRepo     contract.UserRepository
+	entitlements contract.EntitlementChecker
+	tiers        []string
+	features     map[string]string
+}
+
+var _ contract.PlanGate = (*PlanPolicy)(nil)
+
+func NewPlanPolicy(args PlanPolicyArgs) (*PlanPolicy, error) {
+	if len(args.Tiers) == 0 {
+		return nil, fmt.Errorf("no plan tiers configured")
+	}
+	for feature, plan := range args.Features {
+		if !slices.Contains(args.Tiers, plan) {
+			return nil, fmt.Errorf("feature %q: %w: %q", feature, errs.ErrUnknownPlanTier, plan)
+		}
+	}
+	return &PlanPolicy{
+		userRepo:     args.UserRepo,
+		entitlements: args.Entitlements,
+		tiers:        args.Tiers,
+		features:     args.Features,
+	}, nil
+}
+
+func (p *PlanPolicy) RequirePlan(ctx context.Context, userID uuid.UUID, plan string) error {
+	return p.require(ctx, userID, plan, "")
+}
+
+func (p *PlanPolicy) RequireFeature(ctx context.Context, userID uuid.UUID, feature string) error {
+	plan, ok := p.features[feature]
+	if !ok {
+		return nil
+	}
+	return p.require(ctx, userID, plan, feature)
+}
+
+func (p *PlanPolicy) require(ctx context.Context, userID uuid.UUID, plan, feature string) error {
+	required := slices.Index(p.tiers, plan)
+	if required < 0 {
+		return fmt.Errorf("%w: %q", errs.ErrUnknownPlanTier, plan)
+	}
+
+	current, err := p.currentTier(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current >= required {
+		return nil
+	}
+	return &errs.UpgradeRequiredError{
+		RequiredPlan: plan,
+		CurrentPlan:  p.tiers[current],
+		Feature:      feature,
+	}
+}
+
+func (p *PlanPolicy) currentTier(ctx context.Context, userID uuid.UUID) (int, error) {
+	u, err := p.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	subscribed, err := p.entitlements.ActivePlan(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return max(slices.Index(p.tiers, u.Plan), slices.Index(p.tiers, subscribed), 0), nil
+}
@@ -0,0 +1,48 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// TermsPolicy requires sign-ups to accept the current legal documents and
+// records the acceptance once the account exists.
+type TermsPolicy struct {
+	termsRepo contract.TermsAcceptanceRepository
+	current   entity.TermsVersions
+}
+
+var (
+	_ contract.SignUpPolicy = (*TermsPolicy)(nil)
+	_ contract.SignUpHook   = (*TermsPolicy)(nil)
+)
+
+func NewTermsPolicy(termsRepo contract.TermsAcceptanceRepository, current entity.TermsVersions) *TermsPolicy {
+	return &TermsPolicy{termsRepo: termsRepo, current: current}
+}
+
+func (p *TermsPolicy) Check(ctx context.Context, input *dto.SignUpInput) error {
+	switch input.Terms {
+	case p.current:
+		return nil
+	case entity.TermsVersions{}:
+		return errs.ErrTermsNotAccepted
+	default:
+		return errs.ErrTermsOutdated
+	}
+}
+
+func (p *TermsPolicy) AfterSignUp(ctx context.Context, input *dto.SignUpInput, u *entity.User) error {
+	_, err := p.termsRepo.Create(ctx, &entity.TermsAcceptance{
+		UserID:        u.ID,
+		TermsVersions: input.Terms,
+		IP:            input.Client.IP,
+		AcceptedAt:    time.Now().UTC(),
+	})
+	return err
+}
@@ -0,0 +1,113 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ConfirmEmailChangeUseCase struct {
+	userRepo        contract.UserRepository
+	emailChangeRepo contract.EmailChangeRepository
+	notifier        contract.Notifier
+	analytics       contract.AnalyticsTracker
+	linkBaseURL     string
+	// revertWindow is how long the old address can undo a confirmed change.
+	revertWindow time.Duration
+}
+
+func NewConfirmEmailChangeUseCase(
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	notifier contract.Notifier,
+	analytics contract.AnalyticsTracker,
+	linkBaseURL string,
+	revertWindow time.Duration,
+) *ConfirmEmailChangeUseCase {
+	return &ConfirmEmailChangeUseCase{
+		userRepo:        userRepo,
+		emailChangeRepo: emailChangeRepo,
+		notifier:        notifier,
+		analytics:       analytics,
+		linkBaseURL:     linkBaseURL,
+		revertWindow:    revertWindow,
+	}
+}
+
+// Execute commits the change once the new address is verified, then tells
+// the old address, with a fresh link to revert it.
+func (uc *ConfirmEmailChangeUseCase) Execute(ctx context.Context, token string) (_ *entity.EmailChange, err error) {
+	defer instrument.Observe("account.confirm_email_change", time.Now(), &err)
+
+	change, err := uc.emailChangeRepo.GetByConfirmHash(ctx, securetoken.Hash(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if change.Status != entity.EMAIL_CHANGE_PENDING || !now.Before(change.ExpiresAt) {
+		return nil, errs.ErrInvalidEmailChangeToken
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return nil, err
+	}
+	u.Email = change.NewEmail
+	u.UpdatedAt = &now
+	if _, err := uc.userRepo.Update(ctx, u); err != nil {
+		return nil, err
+	}
+	uc.analytics.Track(ctx, dto.AnalyticsEvent{
+		Name:       dto.ANALYTICS_PROFILE_UPDATED,
+		UserID:     u.ID,
+		TenantID:   u.TenantID,
+		Properties: map[string]any{"field": "email"},
+	})
+
+	revertToken, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	change.Status = entity.EMAIL_CHANGE_CONFIRMED
+	change.ConfirmedAt = &now
+	change.RevertHash = securetoken.Hash(revertToken)
+	revertUntil := now.Add(uc.revertWindow)
+	change.RevertUntil = &revertUntil
+	change, err = uc.emailChangeRepo.Update(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	n := dto.Notification{
+		UserID:   change.UserID,
+		Category: entity.NOTIFY_CATEGORY_SECURITY,
+		Email: dto.Email{
+			To:       change.OldEmail,
+			Template: dto.EMAIL_CHANGED,
+			Data: map[string]any{
+				"NewEmail":    change.NewEmail,
+				"RevertLink":  uc.linkBaseURL + "/revert?token=" + url.QueryEscape(revertToken),
+				"RevertUntil": revertUntil.Format(time.RFC1123),
+			},
+		},
+		Summary: fmt.Sprintf("The email of your account was changed to %s. If this was not you, check the message sent to %s.", change.NewEmail, change.OldEmail),
+	}
+	if u.HasVerifiedPhone() {
+		n.Phone = u.Phone
+	}
+	err = uc.notifier.Notify(ctx, n)
+	if err != nil {
+		ctxutil.Logger(ctx).Warnw("notify old email", "user_id", change.UserID, "error", err)
+	}
+	return change, nil
+}
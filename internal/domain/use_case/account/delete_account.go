@@ -0,0 +1,103 @@
+package account
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type DeleteAccountUseCaseArgs struct {
+	UserRepo    contract.UserRepository
+	SessionRepo contract.SessionRepository
+	RestoreRepo contract.AccountRestoreRepository
+	Hasher      contract.PasswordHasher
+	Mailer      contract.Mailer
+	// LinkBaseURL is the page that receives the restore token.
+	LinkBaseURL string
+	// RecoveryWindow is how long the account can be restored.
+	RecoveryWindow time.Duration
+}
+
+type DeleteAccountUseCase struct {
+	userRepo       contract.UserRepository
+	sessionRepo    contract.SessionRepository
+	restoreRepo    contract.AccountRestoreRepository
+	hasher         contract.PasswordHasher
+	mailer         contract.Mailer
+	linkBaseURL    string
+	recoveryWindow time.Duration
+}
+
+func NewDeleteAccountUseCase(args DeleteAccountUseCaseArgs) *DeleteAccountUseCase {
+	return &DeleteAccountUseCase{
+		userRepo:       args.UserRepo,
+		sessionRepo:    args.SessionRepo,
+		restoreRepo:    args.RestoreRepo,
+		hasher:         args.Hasher,
+		mailer:         args.Mailer,
+		linkBaseURL:    args.LinkBaseURL,
+		recoveryWindow: args.RecoveryWindow,
+	}
+}
+
+// Execute deletes the user's account: it is signed out everywhere and
+// rejected like a locked one, and the user is emailed a link to restore it
+// within the recovery window. The retention job purges it afterwards. It
+// returns ErrInvalidCredentials unless the password, if any, is given, and
+// ErrRecentSignInRequired for an account without one whose session was not
+// signed in recently.
+func (uc *DeleteAccountUseCase) Execute(ctx context.Context, input *dto.DeleteAccountInput) (_ *dto.AccountDeletion, err error) {
+	defer instrument.Observe("account.delete_account", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, uc.hasher, uc.sessionRepo, u, input.Password, input.SessionID); err != nil {
+		return nil, err
+	}
+
+	token, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	purgeAt := now.Add(uc.recoveryWindow)
+	_, err = uc.restoreRepo.Create(ctx, &entity.AccountRestore{
+		UserID:    u.ID,
+		TokenHash: securetoken.Hash(token),
+		CreatedAt: now,
+		ExpiresAt: purgeAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.DeletedAt, u.PurgeAt = &now, &purgeAt
+	if _, err := uc.userRepo.Update(ctx, u); err != nil {
+		return nil, err
+	}
+	if _, err := uc.sessionRepo.RevokeAllByUser(ctx, u.ID, now); err != nil {
+		return nil, err
+	}
+
+	err = uc.mailer.Send(ctx, dto.Email{
+		To:       u.Email,
+		Template: dto.EMAIL_ACCOUNT_DELETED,
+		Data: map[string]any{
+			"Link":    uc.linkBaseURL + "?token=" + url.QueryEscape(token),
+			"PurgeAt": purgeAt.Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AccountDeletion{DeletedAt: now, PurgeAt: purgeAt}, nil
+}
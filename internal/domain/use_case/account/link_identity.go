@@ -0,0 +1,93 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/internal/domain/use_case/phone"
+)
+
+type LinkIdentityUseCase struct {
+	userRepo          contract.UserRepository
+	sessionRepo       contract.SessionRepository
+	hasher            contract.PasswordHasher
+	passwordPolicy    contract.PasswordPolicy
+	phoneVerification *phone.RequestPhoneVerificationUseCase
+}
+
+func NewLinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+	phoneVerification *phone.RequestPhoneVerificationUseCase,
+) *LinkIdentityUseCase {
+	return &LinkIdentityUseCase{
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		hasher:            hasher,
+		passwordPolicy:    passwordPolicy,
+		phoneVerification: phoneVerification,
+	}
+}
+
+// Execute links a phone number or a password to the user's account and
+// returns its identities. A phone number is texted a code and becomes
+// usable once verified; an account has at most one, so a verified number
+// must be unlinked before another is linked. A password can only be linked
+// to an account that has none, such as one provisioned through SAML. It
+// returns ErrInvalidCredentials unless the current password, if any, is
+// given, and ErrRecentSignInRequired for an account without one whose
+// session was not signed in recently.
+func (uc *LinkIdentityUseCase) Execute(ctx context.Context, userID uuid.UUID, input *dto.LinkIdentityInput) (_ []*dto.Identity, err error) {
+	defer instrument.Observe("account.link_identity", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, uc.hasher, uc.sessionRepo, u, input.CurrentPassword, input.SessionID); err != nil {
+		return nil, err
+	}
+
+	switch input.Type {
+	case dto.IDENTITY_PHONE:
+		if u.HasVerifiedPhone() {
+			return nil, errs.ErrIdentityAlreadyLinked
+		}
+		if err := uc.phoneVerification.Execute(ctx, u.ID, input.Phone); err != nil {
+			return nil, err
+		}
+		if u, err = uc.userRepo.GetByID(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	case dto.IDENTITY_PASSWORD:
+		if u.HasUsablePassword() {
+			return nil, errs.ErrIdentityAlreadyLinked
+		}
+		if u, err = uc.linkPassword(ctx, u, input.Password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.ErrIdentityNotLinked
+	}
+	return identities(u), nil
+}
+
+func (uc *LinkIdentityUseCase) linkPassword(ctx context.Context, u *entity.User, password string) (*entity.User, error) {
+	if err := uc.passwordPolicy.CheckPassword(ctx, password, u.Email, u.Username); err != nil {
+		return nil, err
+	}
+	hashed, err := uc.hasher.Hash(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+	u.SetPassword(hashed, time.Now().UTC())
+	return uc.userRepo.Update(ctx, u)
+}
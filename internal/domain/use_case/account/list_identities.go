@@ -0,0 +1,133 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListIdentitiesUseCase struct {
+	userRepo contract.UserRepository
+}
+
+func NewListIdentitiesUseCase(userRepo contract.UserRepository) *ListIdentitiesUseCase {
+	return &ListIdentitiesUseCase{userRepo: userRepo}
+}
+
+// Execute lists the ways the user can sign in.
+func (uc *ListIdentitiesUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ []*dto.Identity, err error) {
+	defer instrument.Observe("account.list_identities", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return identities(u), nil
+}
+
+// identities derives the user's identities from the account. A password
+// nobody knows is left out, while a phone number awaiting verification is
+// listed as not yet usable.
+func identities(u *entity.User) []*dto.Identity {
+	out := []*dto.Identity{}
+	if u.HasUsablePassword() {
+		linkedAt := u.CreatedAt
+		if u.PasswordChangedAt != nil {
+			linkedAt = *u.PasswordChangedAt
+		}
+		out = append(out, &dto.Identity{
+			Type:       dto.IDENTITY_PASSWORD,
+			Identifier: u.Email,
+			Usable:     true,
+			LinkedAt:   &linkedAt,
+		})
+	}
+	if u.Phone != "" {
+		out = append(out, &dto.Identity{
+			Type:       dto.IDENTITY_PHONE,
+			Identifier: u.Phone,
+			Usable:     u.HasVerifiedPhone(),
+			LinkedAt:   u.PhoneVerifiedAt,
+		})
+	}
+	if u.TenantID != "" {
+		out = append(out, &dto.Identity{
+			Type:       dto.IDENTITY_SAML,
+			Identifier: u.TenantID,
+			Usable:     true,
+			Managed:    true,
+		})
+	}
+	return out
+}
+
+// usableOtherThan counts the identities the user could still sign in with
+// without the one of type typ.
+func usableOtherThan(u *entity.User, typ string) int {
+	n := 0
+	for _, id := range identities(u) {
+		if id.Usable && id.Type != typ {
+			n++
+		}
+	}
+	return n
+}
+
+// recentSignIn is how recently a user without a password must have signed
+// in to change how they sign in or delete their account.
+const recentSignIn = 10 * time.Minute
+
+// reauthenticate checks password against the user's own before an identity
+// is linked or unlinked or the account deleted, so a stolen session alone
+// cannot take over or delete the account. Accounts without a usable password, such as those provisioned
+// through SAML, have nothing to check; the session sessionID must instead
+// have been signed in within recentSignIn, or ErrRecentSignInRequired is
+// returned.
+func reauthenticate(
+	ctx context.Context,
+	hasher contract.PasswordHasher,
+	sessionRepo contract.SessionRepository,
+	u *entity.User,
+	password string,
+	sessionID uuid.UUID,
+) error {
+	if !u.HasUsablePassword() {
+		return checkRecentSignIn(ctx, sessionRepo, u, sessionID)
+	}
+	match, err := hasher.Compare(ctx, u.HashedPassword, password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return errs.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// checkRecentSignIn returns ErrRecentSignInRequired unless sessionID is a
+// session of u signed in within recentSignIn. Requests made without a
+// session of their own, such as with another issuer's token, never are.
+func checkRecentSignIn(ctx context.Context, sessionRepo contract.SessionRepository, u *entity.User, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return errs.ErrRecentSignInRequired
+	}
+	s, err := sessionRepo.GetByID(ctx, sessionID)
+	if errors.Is(err, errs.ErrSessionNotFound) {
+		return errs.ErrRecentSignInRequired
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if s.UserID != u.ID || !s.IsActive(now) || now.Sub(s.CreatedAt) > recentSignIn {
+		return errs.ErrRecentSignInRequired
+	}
+	return nil
+}
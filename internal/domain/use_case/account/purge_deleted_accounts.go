@@ -0,0 +1,55 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type PurgeDeletedAccountsUseCase struct {
+	userRepo    contract.UserRepository
+	restoreRepo contract.AccountRestoreRepository
+}
+
+func NewPurgeDeletedAccountsUseCase(userRepo contract.UserRepository, restoreRepo contract.AccountRestoreRepository) *PurgeDeletedAccountsUseCase {
+	return &PurgeDeletedAccountsUseCase{userRepo: userRepo, restoreRepo: restoreRepo}
+}
+
+// Execute deletes for good the accounts whose recovery window ended, found
+// through their expired restore links, and returns how many there were. The
+// expired links are deleted with them, used ones included.
+func (uc *PurgeDeletedAccountsUseCase) Execute(ctx context.Context) (_ int, err error) {
+	defer instrument.Observe("account.purge_deleted_accounts", time.Now(), &err)
+
+	now := time.Now().UTC()
+	restores, err := uc.restoreRepo.ListExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, restore := range restores {
+		if restore.UsedAt == nil {
+			u, err := uc.userRepo.GetByID(ctx, restore.UserID)
+			switch {
+			case errors.Is(err, errs.ErrUserNotFound):
+			case err != nil:
+				return n, err
+			// An account restored and deleted again waits for its newer link.
+			case u.IsDeleted() && !now.Before(*u.PurgeAt):
+				if err := uc.userRepo.Delete(ctx, u.ID); err != nil {
+					return n, err
+				}
+				n++
+			}
+		}
+		if err := uc.restoreRepo.Delete(ctx, restore.ID); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
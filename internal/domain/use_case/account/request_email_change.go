@@ -0,0 +1,122 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type RequestEmailChangeUseCase struct {
+	userRepo        contract.UserRepository
+	emailChangeRepo contract.EmailChangeRepository
+	hasher          contract.PasswordHasher
+	mailer          contract.Mailer
+	linkBaseURL     string
+	// ttl is how long the new address has to confirm.
+	ttl time.Duration
+}
+
+func NewRequestEmailChangeUseCase(
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	hasher contract.PasswordHasher,
+	mailer contract.Mailer,
+	linkBaseURL string,
+	ttl time.Duration,
+) *RequestEmailChangeUseCase {
+	return &RequestEmailChangeUseCase{
+		userRepo:        userRepo,
+		emailChangeRepo: emailChangeRepo,
+		hasher:          hasher,
+		mailer:          mailer,
+		linkBaseURL:     linkBaseURL,
+		ttl:             ttl,
+	}
+}
+
+// Execute starts an email change. The new address gets a confirmation link;
+// the old one gets a link to cancel. Nothing changes until confirmation.
+func (uc *RequestEmailChangeUseCase) Execute(ctx context.Context, input *dto.RequestEmailChangeInput) (_ *entity.EmailChange, err error) {
+	defer instrument.Observe("account.request_email_change", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	match, err := uc.hasher.Compare(ctx, u.HashedPassword, input.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, errs.ErrInvalidCredentials
+	}
+	if strings.EqualFold(u.Email, input.NewEmail) {
+		return nil, errs.ErrSameEmail
+	}
+
+	_, err = uc.userRepo.GetByEmail(ctx, input.NewEmail)
+	if err == nil {
+		return nil, errs.ErrEmailTaken
+	}
+	if !errors.Is(err, errs.ErrUserNotFound) {
+		return nil, err
+	}
+
+	confirmToken, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	revertToken, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	change, err := uc.emailChangeRepo.Create(ctx, &entity.EmailChange{
+		UserID:      u.ID,
+		OldEmail:    u.Email,
+		NewEmail:    input.NewEmail,
+		ConfirmHash: securetoken.Hash(confirmToken),
+		RevertHash:  securetoken.Hash(revertToken),
+		Status:      entity.EMAIL_CHANGE_PENDING,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(uc.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.mailer.Send(ctx, dto.Email{
+		To:       change.NewEmail,
+		Template: dto.EMAIL_CHANGE_CONFIRM,
+		Data: map[string]any{
+			"Link": uc.linkBaseURL + "/confirm?token=" + url.QueryEscape(confirmToken),
+			"TTL":  uc.ttl.String(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.mailer.Send(ctx, dto.Email{
+		To:       change.OldEmail,
+		Template: dto.EMAIL_CHANGE_REQUESTED,
+		Data: map[string]any{
+			"NewEmail":   change.NewEmail,
+			"RevertLink": uc.linkBaseURL + "/revert?token=" + url.QueryEscape(revertToken),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
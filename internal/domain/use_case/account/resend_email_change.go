@@ -0,0 +1,66 @@
+package account
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ResendEmailChangeUseCase struct {
+	emailChangeRepo contract.EmailChangeRepository
+	mailer          contract.Mailer
+	linkBaseURL     string
+	ttl             time.Duration
+}
+
+func NewResendEmailChangeUseCase(
+	emailChangeRepo contract.EmailChangeRepository,
+	mailer contract.Mailer,
+	linkBaseURL string,
+	ttl time.Duration,
+) *ResendEmailChangeUseCase {
+	return &ResendEmailChangeUseCase{emailChangeRepo: emailChangeRepo, mailer: mailer, linkBaseURL: linkBaseURL, ttl: ttl}
+}
+
+// Execute sends the new address of the user's pending email change a fresh
+// confirmation link, for when the first one was lost or expired. The old
+// link stops working and the change gets a full TTL again.
+func (uc *ResendEmailChangeUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ *entity.EmailChange, err error) {
+	defer instrument.Observe("account.resend_email_change", time.Now(), &err)
+
+	change, err := uc.emailChangeRepo.GetPendingByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmToken, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	change.ConfirmHash = securetoken.Hash(confirmToken)
+	change.ExpiresAt = time.Now().UTC().Add(uc.ttl)
+	updated, err := uc.emailChangeRepo.Update(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.mailer.Send(ctx, dto.Email{
+		To:       updated.NewEmail,
+		Template: dto.EMAIL_CHANGE_CONFIRM,
+		Data: map[string]any{
+			"Link": uc.linkBaseURL + "/confirm?token=" + url.QueryEscape(confirmToken),
+			"TTL":  uc.ttl.String(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
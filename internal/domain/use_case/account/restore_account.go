@@ -0,0 +1,51 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type RestoreAccountUseCase struct {
+	userRepo    contract.UserRepository
+	restoreRepo contract.AccountRestoreRepository
+}
+
+func NewRestoreAccountUseCase(userRepo contract.UserRepository, restoreRepo contract.AccountRestoreRepository) *RestoreAccountUseCase {
+	return &RestoreAccountUseCase{userRepo: userRepo, restoreRepo: restoreRepo}
+}
+
+// Execute restores the account deleted with the link token was sent in. The
+// user then signs in again as before; the sessions revoked at deletion stay
+// revoked.
+func (uc *RestoreAccountUseCase) Execute(ctx context.Context, token string) (_ *entity.User, err error) {
+	defer instrument.Observe("account.restore_account", time.Now(), &err)
+
+	restore, err := uc.restoreRepo.GetByTokenHash(ctx, securetoken.Hash(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !restore.IsUsable(now) {
+		return nil, errs.ErrInvalidRestoreToken
+	}
+	u, err := uc.userRepo.GetByID(ctx, restore.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsDeleted() {
+		return nil, errs.ErrInvalidRestoreToken
+	}
+
+	if err := uc.restoreRepo.MarkUsed(ctx, restore.ID, now); err != nil {
+		return nil, err
+	}
+	u.DeletedAt, u.PurgeAt = nil, nil
+	return uc.userRepo.Update(ctx, u)
+}
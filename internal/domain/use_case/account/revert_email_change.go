@@ -0,0 +1,80 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type RevertEmailChangeUseCase struct {
+	userRepo        contract.UserRepository
+	emailChangeRepo contract.EmailChangeRepository
+	sessionRepo     contract.SessionRepository
+}
+
+func NewRevertEmailChangeUseCase(
+	userRepo contract.UserRepository,
+	emailChangeRepo contract.EmailChangeRepository,
+	sessionRepo contract.SessionRepository,
+) *RevertEmailChangeUseCase {
+	return &RevertEmailChangeUseCase{
+		userRepo:        userRepo,
+		emailChangeRepo: emailChangeRepo,
+		sessionRepo:     sessionRepo,
+	}
+}
+
+// Execute handles the old address's objection. A pending change is simply
+// cancelled; a confirmed one is rolled back and every session is revoked,
+// since the account may have been taken over.
+func (uc *RevertEmailChangeUseCase) Execute(ctx context.Context, token string) (_ *entity.EmailChange, err error) {
+	defer instrument.Observe("account.revert_email_change", time.Now(), &err)
+
+	change, err := uc.emailChangeRepo.GetByRevertHash(ctx, securetoken.Hash(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	switch change.Status {
+	case entity.EMAIL_CHANGE_PENDING:
+	case entity.EMAIL_CHANGE_CONFIRMED:
+		if change.RevertUntil == nil || !now.Before(*change.RevertUntil) {
+			return nil, errs.ErrInvalidEmailChangeToken
+		}
+		if err := uc.rollback(ctx, change, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.ErrInvalidEmailChangeToken
+	}
+
+	change.Status = entity.EMAIL_CHANGE_REVERTED
+	change.RevertedAt = &now
+	return uc.emailChangeRepo.Update(ctx, change)
+}
+
+func (uc *RevertEmailChangeUseCase) rollback(ctx context.Context, change *entity.EmailChange, now time.Time) error {
+	u, err := uc.userRepo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return err
+	}
+	// A later change superseded this one; restoring would clobber it.
+	if !strings.EqualFold(u.Email, change.NewEmail) {
+		return errs.ErrInvalidEmailChangeToken
+	}
+
+	u.Email = change.OldEmail
+	u.UpdatedAt = &now
+	if _, err := uc.userRepo.Update(ctx, u); err != nil {
+		return err
+	}
+	_, err = uc.sessionRepo.RevokeAllByUser(ctx, u.ID, now)
+	return err
+}
@@ -0,0 +1,89 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type UnlinkIdentityUseCase struct {
+	userRepo    contract.UserRepository
+	sessionRepo contract.SessionRepository
+	hasher      contract.PasswordHasher
+}
+
+func NewUnlinkIdentityUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+) *UnlinkIdentityUseCase {
+	return &UnlinkIdentityUseCase{userRepo: userRepo, sessionRepo: sessionRepo, hasher: hasher}
+}
+
+// Execute removes the identity of type typ from the user's account and
+// returns the identities left. It returns ErrLastIdentity rather than leave
+// the account without a usable one, and ErrIdentityManaged for the tenant's
+// identity provider, which only an admin can detach. Unlinking the password
+// replaces it with one nobody knows; a password reset links one again. It
+// returns ErrInvalidCredentials unless the current password, if any, is
+// given, and ErrRecentSignInRequired for an account without one whose
+// session was not signed in recently.
+func (uc *UnlinkIdentityUseCase) Execute(ctx context.Context, userID uuid.UUID, input *dto.UnlinkIdentityInput) (_ []*dto.Identity, err error) {
+	defer instrument.Observe("account.unlink_identity", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, uc.hasher, uc.sessionRepo, u, input.Password, input.SessionID); err != nil {
+		return nil, err
+	}
+
+	typ := input.Type
+	switch typ {
+	case dto.IDENTITY_PHONE:
+		if u.Phone == "" {
+			return nil, errs.ErrIdentityNotLinked
+		}
+		if u.HasVerifiedPhone() && usableOtherThan(u, typ) == 0 {
+			return nil, errs.ErrLastIdentity
+		}
+		u.Phone = ""
+		u.PhoneVerifiedAt = nil
+	case dto.IDENTITY_PASSWORD:
+		if !u.HasUsablePassword() {
+			return nil, errs.ErrIdentityNotLinked
+		}
+		if usableOtherThan(u, typ) == 0 {
+			return nil, errs.ErrLastIdentity
+		}
+		secret, err := securetoken.New(32)
+		if err != nil {
+			return nil, err
+		}
+		hashed, err := uc.hasher.Hash(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		u.UnsetPassword(hashed, time.Now().UTC())
+	case dto.IDENTITY_SAML:
+		if u.TenantID == "" {
+			return nil, errs.ErrIdentityNotLinked
+		}
+		return nil, errs.ErrIdentityManaged
+	default:
+		return nil, errs.ErrIdentityNotLinked
+	}
+
+	u, err = uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return identities(u), nil
+}
@@ -0,0 +1,74 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+type UpgradeGuestUseCase struct {
+	userRepo contract.UserRepository
+	hasher   contract.PasswordHasher
+	policies []contract.SignUpPolicy
+}
+
+// NewUpgradeGuestUseCase takes the same policies as sign-up, since upgrading
+// is how a guest signs up.
+func NewUpgradeGuestUseCase(userRepo contract.UserRepository, hasher contract.PasswordHasher, policies ...contract.SignUpPolicy) *UpgradeGuestUseCase {
+	return &UpgradeGuestUseCase{userRepo: userRepo, hasher: hasher, policies: policies}
+}
+
+// Execute turns a guest into a full account in place, so the user ID and
+// everything attached to it are kept. Tokens issued before the upgrade keep
+// the guest scope until refreshed.
+func (uc *UpgradeGuestUseCase) Execute(ctx context.Context, userID uuid.UUID, input *dto.SignUpInput) (_ *entity.User, err error) {
+	defer instrument.Observe("account.upgrade_guest", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsGuest {
+		return nil, errs.ErrNotGuest
+	}
+
+	for _, p := range uc.policies {
+		if err := p.Check(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	hashed, err := uc.hasher.Hash(ctx, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	u.IsGuest = false
+	u.Email = input.Email
+	u.Username = entity.NormalizeUsername(input.Username)
+	u.SetPassword(hashed, time.Now().UTC())
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	upgraded, err := uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range uc.policies {
+		if hook, ok := p.(contract.SignUpHook); ok {
+			if err := hook.AfterSignUp(ctx, input, upgraded); err != nil {
+				ctxutil.Logger(ctx).Warnw("sign-up hook", "user_id", upgraded.ID, "error", err)
+			}
+		}
+	}
+
+	return upgraded, nil
+}
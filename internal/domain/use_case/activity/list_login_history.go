@@ -0,0 +1,35 @@
+package activity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListLoginHistoryUseCase struct {
+	loginAttemptRepo contract.LoginAttemptRepository
+}
+
+func NewListLoginHistoryUseCase(loginAttemptRepo contract.LoginAttemptRepository) *ListLoginHistoryUseCase {
+	return &ListLoginHistoryUseCase{loginAttemptRepo: loginAttemptRepo}
+}
+
+func (uc *ListLoginHistoryUseCase) Execute(ctx context.Context, userID uuid.UUID, limit, offset int) (_ *dto.Page[*entity.LoginAttempt], err error) {
+	defer instrument.Observe("activity.list_login_history", time.Now(), &err)
+
+	attempts, total, err := uc.loginAttemptRepo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Page[*entity.LoginAttempt]{
+		Items:  attempts,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetAuthProtectionUseCase struct {
+	authProtectionRepo contract.AuthProtectionRepository
+}
+
+func NewGetAuthProtectionUseCase(authProtectionRepo contract.AuthProtectionRepository) *GetAuthProtectionUseCase {
+	return &GetAuthProtectionUseCase{authProtectionRepo: authProtectionRepo}
+}
+
+func (uc *GetAuthProtectionUseCase) Execute(ctx context.Context) (_ *entity.AuthProtection, err error) {
+	defer instrument.Observe("admin.get_auth_protection", time.Now(), &err)
+
+	return uc.authProtectionRepo.Get(ctx)
+}
+
+type SetAuthProtectionUseCase struct {
+	authProtectionRepo contract.AuthProtectionRepository
+	auditLogRepo       contract.AuditLogRepository
+}
+
+func NewSetAuthProtectionUseCase(authProtectionRepo contract.AuthProtectionRepository, auditLogRepo contract.AuditLogRepository) *SetAuthProtectionUseCase {
+	return &SetAuthProtectionUseCase{authProtectionRepo: authProtectionRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute overrides the adaptive protection of the credential endpoints,
+// or hands it back to detection with auto. Setting the current mode again
+// changes nothing.
+func (uc *SetAuthProtectionUseCase) Execute(ctx context.Context, actorID uuid.UUID, mode string) (_ *entity.AuthProtection, err error) {
+	defer instrument.Observe("admin.set_auth_protection", time.Now(), &err)
+
+	if !entity.IsAuthProtectionMode(mode) {
+		return nil, errs.ErrInvalidAuthProtectionMode
+	}
+
+	current, err := uc.authProtectionRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.Mode == mode {
+		return current, nil
+	}
+
+	now := time.Now().UTC()
+	updated, err := uc.authProtectionRepo.Set(ctx, &entity.AuthProtection{Mode: mode, Since: &now, SetBy: &actorID})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_AUTH_PROTECTION_CHANGED,
+		ActorID:   actorID,
+		Detail:    current.Mode + " -> " + updated.Mode,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	authUseCase "github.com/haidang666/go-app/internal/domain/use_case/auth"
+	userUseCase "github.com/haidang666/go-app/internal/domain/use_case/user"
+)
+
+const (
+	BULK_ERR_EMAIL_TAKEN = "email_taken"
+	BULK_ERR_NOT_FOUND   = "not_found"
+	BULK_ERR_INVALID     = "invalid"
+	BULK_ERR_CANCELED    = "canceled"
+	// BULK_ERR_BUSY items failed only because password hashing was saturated
+	// and can be retried as they are.
+	BULK_ERR_BUSY = "busy"
+)
+
+// ProgressFunc receives each item result as soon as it has been processed.
+type ProgressFunc func(result dto.BulkItemResult)
+
+type BulkUsersUseCase struct {
+	signUpUseCase     *authUseCase.SignUpUseCase
+	updateUserUseCase *userUseCase.UpdateUserUseCase
+}
+
+func NewBulkUsersUseCase(
+	signUpUseCase *authUseCase.SignUpUseCase,
+	updateUserUseCase *userUseCase.UpdateUserUseCase,
+) *BulkUsersUseCase {
+	return &BulkUsersUseCase{
+		signUpUseCase:     signUpUseCase,
+		updateUserUseCase: updateUserUseCase,
+	}
+}
+
+// CreateUsers creates every input independently: a failing item never rolls
+// back or blocks the others.
+func (uc *BulkUsersUseCase) CreateUsers(ctx context.Context, inputs []dto.BulkInput[dto.SignUpInput], progress ProgressFunc) []dto.BulkItemResult {
+	return run(ctx, inputs, progress, func(input *dto.SignUpInput) (*entity.User, error) {
+		return uc.signUpUseCase.Execute(ctx, input)
+	})
+}
+
+// UpdateUsers updates every input independently, see CreateUsers.
+func (uc *BulkUsersUseCase) UpdateUsers(ctx context.Context, inputs []dto.BulkInput[dto.UpdateUserInput], progress ProgressFunc) []dto.BulkItemResult {
+	return run(ctx, inputs, progress, func(input *dto.UpdateUserInput) (*entity.User, error) {
+		return uc.updateUserUseCase.Execute(ctx, input)
+	})
+}
+
+// ImportUsers signs up rows as they are read, like CreateUsers, but sends
+// no welcome emails: imported users are told out of band. Results only go
+// to progress, so an import of any size runs in constant memory.
+func (uc *BulkUsersUseCase) ImportUsers(ctx context.Context, rows iter.Seq[dto.BulkInput[dto.SignUpInput]], progress ProgressFunc) {
+	each(ctx, rows, progress, func(input *dto.SignUpInput) (*entity.User, error) {
+		input.SkipWelcomeEmail = true
+		return uc.signUpUseCase.Execute(ctx, input)
+	})
+}
+
+func run[T any](ctx context.Context, inputs []dto.BulkInput[T], progress ProgressFunc, fn func(input *T) (*entity.User, error)) []dto.BulkItemResult {
+	results := make([]dto.BulkItemResult, 0, len(inputs))
+	each(ctx, slices.Values(inputs), func(result dto.BulkItemResult) {
+		results = append(results, result)
+		if progress != nil {
+			progress(result)
+		}
+	}, fn)
+	return results
+}
+
+// each runs fn on every input as it is produced and hands the result to
+// progress.
+func each[T any](ctx context.Context, inputs iter.Seq[dto.BulkInput[T]], progress ProgressFunc, fn func(input *T) (*entity.User, error)) {
+	i := 0
+	for input := range inputs {
+		var result dto.BulkItemResult
+		if input.Err != nil {
+			result = failed(i, input.Err)
+		} else if err := ctx.Err(); err != nil {
+			result = failed(i, err)
+		} else if u, err := fn(&input.Input); err != nil {
+			result = failed(i, err)
+		} else {
+			result = dto.BulkItemResult{Index: i, Status: dto.BULK_ITEM_SUCCEEDED, User: u}
+		}
+		progress(result)
+		i++
+	}
+}
+
+func failed(i int, err error) dto.BulkItemResult {
+	return dto.BulkItemResult{
+		Index:  i,
+		Status: dto.BULK_ITEM_FAILED,
+		Error:  &dto.BulkItemError{Code: errorCode(err), Message: err.Error()},
+	}
+}
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, errs.ErrEmailTaken), errors.Is(err, errs.ErrEmailAliasTaken):
+		return BULK_ERR_EMAIL_TAKEN
+	case errors.Is(err, errs.ErrUserNotFound):
+		return BULK_ERR_NOT_FOUND
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return BULK_ERR_CANCELED
+	case errors.Is(err, errs.ErrPasswordHashingBusy):
+		return BULK_ERR_BUSY
+	default:
+		return BULK_ERR_INVALID
+	}
+}
@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"github.com/haidang666/go-app/internal/domain/policy"
+)
+
+// DisposableDomainsUseCase manages the sign-up block list of disposable
+// email providers.
+type DisposableDomainsUseCase struct {
+	policy *policy.DisposableEmailPolicy
+}
+
+func NewDisposableDomainsUseCase(p *policy.DisposableEmailPolicy) *DisposableDomainsUseCase {
+	return &DisposableDomainsUseCase{policy: p}
+}
+
+func (uc *DisposableDomainsUseCase) List() []string {
+	return uc.policy.Domains()
+}
+
+func (uc *DisposableDomainsUseCase) Replace(domains []string) []string {
+	uc.policy.Replace(domains)
+	return uc.policy.Domains()
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ExportAuditLogUseCase struct {
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewExportAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *ExportAuditLogUseCase {
+	return &ExportAuditLogUseCase{auditLogRepo: auditLogRepo}
+}
+
+// Execute walks the audit events matching filter after the cursor, oldest
+// first, as ExportUsersUseCase does users.
+func (uc *ExportAuditLogUseCase) Execute(ctx context.Context, filter dto.AuditLogFilter, after *dto.ExportCursor, emit func([]*entity.AuditEvent) error) (err error) {
+	defer instrument.Observe("admin.export_audit_log", time.Now(), &err)
+
+	for {
+		// Stop reading once the client has gone away.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		events, err := uc.auditLogRepo.ListAfter(ctx, filter, after, EXPORT_CHUNK_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := emit(events); err != nil {
+			return err
+		}
+		if len(events) < EXPORT_CHUNK_SIZE {
+			return nil
+		}
+		last := events[len(events)-1]
+		after = &dto.ExportCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// EXPORT_CHUNK_SIZE is the number of rows an export reads at a time.
+const EXPORT_CHUNK_SIZE = 500
+
+type ExportUsersUseCase struct {
+	userQuery contract.UserQuery
+}
+
+func NewExportUsersUseCase(userQuery contract.UserQuery) *ExportUsersUseCase {
+	return &ExportUsersUseCase{userQuery: userQuery}
+}
+
+// Execute walks the users matching filter after the cursor, oldest first,
+// handing them to emit a chunk at a time. Only one chunk is held in memory,
+// so the export can be as large as the table. It stops at the first error
+// emit returns.
+func (uc *ExportUsersUseCase) Execute(ctx context.Context, filter dto.UserFilter, after *dto.ExportCursor, emit func([]*dto.UserSummary) error) (err error) {
+	defer instrument.Observe("admin.export_users", time.Now(), &err)
+
+	for {
+		// Stop reading once the client has gone away.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		users, err := uc.userQuery.ListAfter(ctx, filter, after, EXPORT_CHUNK_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := emit(users); err != nil {
+			return err
+		}
+		if len(users) < EXPORT_CHUNK_SIZE {
+			return nil
+		}
+		last := users[len(users)-1]
+		after = &dto.ExportCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
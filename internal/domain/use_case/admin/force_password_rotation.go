@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type ForcePasswordRotationUseCase struct {
+	userRepo    contract.UserRepository
+	sessionRepo contract.SessionRepository
+}
+
+func NewForcePasswordRotationUseCase(userRepo contract.UserRepository, sessionRepo contract.SessionRepository) *ForcePasswordRotationUseCase {
+	return &ForcePasswordRotationUseCase{userRepo: userRepo, sessionRepo: sessionRepo}
+}
+
+// Execute makes each user change their password at the next sign-in.
+// revokeSessions also signs them out everywhere, for suspected compromise.
+// Users are processed independently, as in BulkUsersUseCase, after being
+// looked up together.
+func (uc *ForcePasswordRotationUseCase) Execute(
+	ctx context.Context,
+	inputs []dto.BulkInput[uuid.UUID],
+	revokeSessions bool,
+	progress ProgressFunc,
+) []dto.BulkItemResult {
+	ids := make([]uuid.UUID, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Err == nil {
+			ids = append(ids, input.Input)
+		}
+	}
+	users, lookupErr := uc.userRepo.GetByIDs(ctx, ids)
+
+	return run(ctx, inputs, progress, func(id *uuid.UUID) (*entity.User, error) {
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		u, ok := users[*id]
+		if !ok {
+			return nil, errs.ErrUserNotFound
+		}
+
+		u.PasswordChangeRequired = true
+		u, err := uc.userRepo.Update(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		// A repeated ID updates the user as just written.
+		users[u.ID] = u
+
+		if revokeSessions {
+			if _, err := uc.sessionRepo.RevokeAllByUser(ctx, u.ID, time.Now().UTC()); err != nil {
+				return nil, err
+			}
+		}
+		return u, nil
+	})
+}
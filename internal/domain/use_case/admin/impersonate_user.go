@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+type ImpersonateUserUseCaseArgs struct {
+	UserRepo     contract.UserRepository
+	SessionRepo  contract.SessionRepository
+	AuditLogRepo contract.AuditLogRepository
+	TokenIssuer  contract.TokenIssuer
+	Notifier     contract.Notifier
+	// TTL is the lifetime of impersonation tokens.
+	TTL time.Duration
+}
+
+// ImpersonateUserUseCase lets an admin act as another user for a short time,
+// e.g. to reproduce a support issue.
+type ImpersonateUserUseCase struct {
+	userRepo     contract.UserRepository
+	sessionRepo  contract.SessionRepository
+	auditLogRepo contract.AuditLogRepository
+	tokenIssuer  contract.TokenIssuer
+	notifier     contract.Notifier
+	ttl          time.Duration
+}
+
+func NewImpersonateUserUseCase(args ImpersonateUserUseCaseArgs) *ImpersonateUserUseCase {
+	return &ImpersonateUserUseCase{
+		userRepo:     args.UserRepo,
+		sessionRepo:  args.SessionRepo,
+		auditLogRepo: args.AuditLogRepo,
+		tokenIssuer:  args.TokenIssuer,
+		notifier:     args.Notifier,
+		ttl:          args.TTL,
+	}
+}
+
+// Execute opens a session for the target that is marked with the actor and
+// returns its access token. The token carries the actor in its claims, so
+// every request made with it is attributable. The target is notified, and can
+// end the impersonation by revoking the session.
+func (uc *ImpersonateUserUseCase) Execute(ctx context.Context, input *dto.ImpersonateInput) (_ *dto.ImpersonationToken, err error) {
+	defer instrument.Observe("admin.impersonate_user", time.Now(), &err)
+
+	if input.ActorID == input.TargetID {
+		return nil, errs.ErrCannotImpersonateSelf
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, input.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	// The token would be rejected anyway; fail before notifying the user.
+	if err := u.CheckStatus(); err != nil {
+		return nil, err
+	}
+
+	sessionID := uuid.New()
+	token, err := uc.tokenIssuer.IssueImpersonationToken(ctx, u, input.ActorID, sessionID, uc.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	actorID := input.ActorID
+	_, err = uc.sessionRepo.Create(ctx, &entity.Session{
+		ID:                sessionID,
+		UserID:            u.ID,
+		DeviceFingerprint: input.Client.DeviceFingerprint,
+		UserAgent:         input.Client.UserAgent,
+		IP:                input.Client.IP,
+		CreatedAt:         now,
+		LastSeenAt:        now,
+		ExpiresAt:         token.AccessExpiresAt,
+		ImpersonatorID:    &actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_IMPERSONATION_STARTED,
+		ActorID:   input.ActorID,
+		SubjectID: u.ID,
+		SessionID: &sessionID,
+		IP:        input.Client.IP,
+		Device:    input.Client.Device.String(),
+		Reason:    input.Reason,
+		CreatedAt: now,
+	})
+	if err != nil {
+		// An impersonation that is not on record must not go ahead.
+		if revokeErr := uc.sessionRepo.Revoke(ctx, sessionID, now); revokeErr != nil {
+			ctxutil.Logger(ctx).Warnw("revoke unaudited impersonation session", "session_id", sessionID, "error", revokeErr)
+		}
+		return nil, err
+	}
+
+	n := dto.Notification{
+		UserID:   u.ID,
+		Category: entity.NOTIFY_CATEGORY_SECURITY,
+		Email: dto.Email{
+			To:       u.Email,
+			Template: dto.EMAIL_IMPERSONATION,
+			Data: map[string]any{
+				"Time":   now.Format(time.RFC1123),
+				"Reason": input.Reason,
+				"Until":  token.AccessExpiresAt.Format(time.RFC1123),
+			},
+		},
+		Summary: fmt.Sprintf("A support administrator is accessing your account until %s. Reason: %s", token.AccessExpiresAt.Format(time.RFC1123), input.Reason),
+	}
+	if u.HasVerifiedPhone() {
+		n.Phone = u.Phone
+	}
+	err = uc.notifier.Notify(ctx, n)
+	if err != nil {
+		ctxutil.Logger(ctx).Warnw("notify impersonated user", "user_id", u.ID, "error", err)
+	}
+	return token, nil
+}
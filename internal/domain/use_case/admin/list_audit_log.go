@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListAuditLogUseCase struct {
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewListAuditLogUseCase(auditLogRepo contract.AuditLogRepository) *ListAuditLogUseCase {
+	return &ListAuditLogUseCase{auditLogRepo: auditLogRepo}
+}
+
+// Execute lists the audit events matching filter, newest first, from the
+// event after before when it is set.
+func (uc *ListAuditLogUseCase) Execute(ctx context.Context, filter dto.AuditLogFilter, before *dto.ExportCursor, limit, offset int) (_ *dto.Page[*entity.AuditEvent], err error) {
+	defer instrument.Observe("admin.list_audit_log", time.Now(), &err)
+
+	events, total, err := uc.auditLogRepo.List(ctx, filter, before, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Page[*entity.AuditEvent]{
+		Items:  events,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListDeprecationUsageUseCase struct {
+	deprecationUsageRepo contract.DeprecationUsageRepository
+}
+
+func NewListDeprecationUsageUseCase(deprecationUsageRepo contract.DeprecationUsageRepository) *ListDeprecationUsageUseCase {
+	return &ListDeprecationUsageUseCase{deprecationUsageRepo: deprecationUsageRepo}
+}
+
+// Execute reports every deprecated route and field with the clients still
+// using it, so their owners know whom to contact before the sunset.
+func (uc *ListDeprecationUsageUseCase) Execute(ctx context.Context) (_ []*dto.DeprecationUsage, err error) {
+	defer instrument.Observe("admin.list_deprecation_usage", time.Now(), &err)
+
+	return uc.deprecationUsageRepo.List(ctx)
+}
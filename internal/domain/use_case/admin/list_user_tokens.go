@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListUserTokensUseCaseArgs struct {
+	UserRepo          contract.UserRepository
+	PasswordResetRepo contract.PasswordResetRepository
+	EmailChangeRepo   contract.EmailChangeRepository
+	PersonalTokenRepo contract.PersonalAccessTokenRepository
+}
+
+type ListUserTokensUseCase struct {
+	userRepo          contract.UserRepository
+	passwordResetRepo contract.PasswordResetRepository
+	emailChangeRepo   contract.EmailChangeRepository
+	personalTokenRepo contract.PersonalAccessTokenRepository
+}
+
+func NewListUserTokensUseCase(args ListUserTokensUseCaseArgs) *ListUserTokensUseCase {
+	return &ListUserTokensUseCase{
+		userRepo:          args.UserRepo,
+		passwordResetRepo: args.PasswordResetRepo,
+		emailChangeRepo:   args.EmailChangeRepo,
+		personalTokenRepo: args.PersonalTokenRepo,
+	}
+}
+
+// Execute lists the password reset, email change and personal access tokens
+// issued to a user, newest first, with when they were issued, expire and
+// were used or revoked. Expired resets are listed until the purge task
+// deletes them.
+func (uc *ListUserTokensUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ []dto.SecurityToken, err error) {
+	defer instrument.Observe("admin.list_user_tokens", time.Now(), &err)
+
+	if _, err := uc.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	resets, err := uc.passwordResetRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := uc.emailChangeRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	personal, err := uc.personalTokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tokens := make([]dto.SecurityToken, 0, len(resets)+2*len(changes)+len(personal))
+	for _, p := range resets {
+		tokens = append(tokens, securityToken(dto.SECURITY_TOKEN_PASSWORD_RESET, p.ID, p.CreatedAt, &p.ExpiresAt, p.UsedAt, nil, now))
+	}
+	for _, c := range changes {
+		tokens = append(tokens, emailChangeTokens(c, now)...)
+	}
+	for _, t := range personal {
+		tokens = append(tokens, securityToken(dto.SECURITY_TOKEN_PERSONAL_ACCESS_TOKEN, t.ID, t.CreatedAt, t.ExpiresAt, nil, t.RevokedAt, now))
+	}
+	slices.SortStableFunc(tokens, func(a, b dto.SecurityToken) int {
+		return b.IssuedAt.Compare(a.IssuedAt)
+	})
+	return tokens, nil
+}
+
+// emailChangeTokens describes the link sent to the new address and the one
+// sent to the old address. The latter cancels the change while it is
+// pending, and is reissued to revert it once confirmed.
+func emailChangeTokens(c *entity.EmailChange, now time.Time) []dto.SecurityToken {
+	var confirmRevoked *time.Time
+	if c.ConfirmedAt == nil {
+		confirmRevoked = c.RevertedAt
+	}
+	confirm := securityToken(dto.SECURITY_TOKEN_EMAIL_CHANGE_CONFIRM, c.ID, c.CreatedAt, &c.ExpiresAt, c.ConfirmedAt, confirmRevoked, now)
+
+	var revert dto.SecurityToken
+	if c.ConfirmedAt != nil {
+		revert = securityToken(dto.SECURITY_TOKEN_EMAIL_CHANGE_REVERT, c.ID, *c.ConfirmedAt, c.RevertUntil, c.RevertedAt, nil, now)
+	} else {
+		revert = securityToken(dto.SECURITY_TOKEN_EMAIL_CHANGE_REVERT, c.ID, c.CreatedAt, &c.ExpiresAt, c.RevertedAt, nil, now)
+	}
+	return []dto.SecurityToken{confirm, revert}
+}
+
+func securityToken(kind dto.SecurityTokenKind, id uuid.UUID, issuedAt time.Time, expiresAt, usedAt, revokedAt *time.Time, now time.Time) dto.SecurityToken {
+	t := dto.SecurityToken{
+		Kind:      kind,
+		ID:        id,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		UsedAt:    usedAt,
+		RevokedAt: revokedAt,
+	}
+	if expiresAt != nil {
+		t.TTL = expiresAt.Sub(issuedAt).Round(time.Second).String()
+	}
+	switch {
+	case usedAt != nil:
+		t.Status = dto.SECURITY_TOKEN_USED
+	case revokedAt != nil:
+		t.Status = dto.SECURITY_TOKEN_REVOKED
+	case expiresAt != nil && !now.Before(*expiresAt):
+		t.Status = dto.SECURITY_TOKEN_EXPIRED
+	default:
+		t.Status = dto.SECURITY_TOKEN_ACTIVE
+	}
+	return t
+}
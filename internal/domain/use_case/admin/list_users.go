@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListUsersUseCase struct {
+	userQuery    contract.UserQuery
+	sessionRepo  contract.SessionRepository
+	samlConnRepo contract.SAMLConnectionRepository
+}
+
+func NewListUsersUseCase(
+	userQuery contract.UserQuery,
+	sessionRepo contract.SessionRepository,
+	samlConnRepo contract.SAMLConnectionRepository,
+) *ListUsersUseCase {
+	return &ListUsersUseCase{userQuery: userQuery, sessionRepo: sessionRepo, samlConnRepo: samlConnRepo}
+}
+
+// Execute lists the users matching filter, newest first, from the read
+// model; writes made in the last moments may not show yet. The relations in
+// expand are loaded with one lookup each for the whole page.
+func (uc *ListUsersUseCase) Execute(
+	ctx context.Context,
+	filter dto.UserFilter,
+	expand dto.UserExpand,
+	limit, offset int,
+) (_ *dto.Page[*dto.UserView], err error) {
+	defer instrument.Observe("admin.list_users", time.Now(), &err)
+
+	users, total, err := uc.userQuery.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*dto.UserView, len(users))
+	for i, u := range users {
+		views[i] = &dto.UserView{UserSummary: u}
+	}
+	if expand.Organization {
+		if err := uc.expandOrganizations(ctx, views); err != nil {
+			return nil, err
+		}
+	}
+	if expand.Sessions {
+		if err := uc.expandSessions(ctx, views); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.Page[*dto.UserView]{
+		Items:  views,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func (uc *ListUsersUseCase) expandOrganizations(ctx context.Context, views []*dto.UserView) error {
+	var tenants []string
+	seen := make(map[string]bool)
+	for _, v := range views {
+		if v.TenantID != "" && !seen[v.TenantID] {
+			seen[v.TenantID] = true
+			tenants = append(tenants, v.TenantID)
+		}
+	}
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	conns, err := uc.samlConnRepo.GetByTenants(ctx, tenants)
+	if err != nil {
+		return err
+	}
+	orgs := make(map[string]*dto.Organization, len(tenants))
+	for _, tenant := range tenants {
+		org := &dto.Organization{Tenant: tenant}
+		if c, ok := conns[tenant]; ok {
+			org.SAMLConnectionID = &c.ID
+			org.JITProvisioning = c.JITProvisioning
+			org.QuotaLimits = c.QuotaLimits
+		}
+		orgs[tenant] = org
+	}
+	for _, v := range views {
+		if v.TenantID != "" {
+			v.Organization = orgs[v.TenantID]
+		}
+	}
+	return nil
+}
+
+func (uc *ListUsersUseCase) expandSessions(ctx context.Context, views []*dto.UserView) error {
+	ids := make([]uuid.UUID, len(views))
+	for i, v := range views {
+		ids[i] = v.ID
+	}
+	sessions, err := uc.sessionRepo.ListActiveByUsers(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, v := range views {
+		v.Sessions = sessions[v.ID]
+		if v.Sessions == nil {
+			v.Sessions = []*entity.Session{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetMaintenanceUseCase struct {
+	maintenanceRepo contract.MaintenanceRepository
+}
+
+func NewGetMaintenanceUseCase(maintenanceRepo contract.MaintenanceRepository) *GetMaintenanceUseCase {
+	return &GetMaintenanceUseCase{maintenanceRepo: maintenanceRepo}
+}
+
+func (uc *GetMaintenanceUseCase) Execute(ctx context.Context) (_ *entity.MaintenanceMode, err error) {
+	defer instrument.Observe("admin.get_maintenance", time.Now(), &err)
+
+	return uc.maintenanceRepo.Get(ctx)
+}
+
+type SetMaintenanceUseCase struct {
+	maintenanceRepo contract.MaintenanceRepository
+	auditLogRepo    contract.AuditLogRepository
+}
+
+func NewSetMaintenanceUseCase(maintenanceRepo contract.MaintenanceRepository, auditLogRepo contract.AuditLogRepository) *SetMaintenanceUseCase {
+	return &SetMaintenanceUseCase{maintenanceRepo: maintenanceRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute turns read-only mode on or off. Turning it on again only updates
+// the reason, keeping since when the service has been read-only.
+func (uc *SetMaintenanceUseCase) Execute(ctx context.Context, actorID uuid.UUID, readOnly bool, reason string) (_ *entity.MaintenanceMode, err error) {
+	defer instrument.Observe("admin.set_maintenance", time.Now(), &err)
+
+	if !readOnly {
+		reason = ""
+	} else if reason == "" {
+		reason = entity.MAINTENANCE_REASON_MAINTENANCE
+	} else if !entity.IsMaintenanceReason(reason) {
+		return nil, errs.ErrInvalidMaintenanceMode
+	}
+
+	current, err := uc.maintenanceRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.ReadOnly == readOnly && current.Reason == reason {
+		return current, nil
+	}
+
+	next := &entity.MaintenanceMode{ReadOnly: readOnly, Reason: reason, SetBy: &actorID}
+	if readOnly {
+		next.Since = current.Since
+		if !current.ReadOnly || next.Since == nil {
+			now := time.Now().UTC()
+			next.Since = &now
+		}
+	}
+	updated, err := uc.maintenanceRepo.Set(ctx, next)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_MAINTENANCE_CHANGED,
+		ActorID:   actorID,
+		Detail:    maintenanceName(current) + " -> " + maintenanceName(updated),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func maintenanceName(m *entity.MaintenanceMode) string {
+	if !m.ReadOnly {
+		return "read-write"
+	}
+	return "read-only (" + m.Reason + ")"
+}
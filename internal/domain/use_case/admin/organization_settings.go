@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetOrganizationSettingsUseCase struct {
+	samlConnRepo contract.SAMLConnectionRepository
+	settingsRepo contract.OrganizationSettingsRepository
+}
+
+func NewGetOrganizationSettingsUseCase(samlConnRepo contract.SAMLConnectionRepository, settingsRepo contract.OrganizationSettingsRepository) *GetOrganizationSettingsUseCase {
+	return &GetOrganizationSettingsUseCase{samlConnRepo: samlConnRepo, settingsRepo: settingsRepo}
+}
+
+// Execute returns the settings of a tenant, which only exists through its
+// SAML connection.
+func (uc *GetOrganizationSettingsUseCase) Execute(ctx context.Context, tenant string) (_ *entity.OrganizationSettings, err error) {
+	defer instrument.Observe("admin.get_organization_settings", time.Now(), &err)
+
+	if _, err := uc.samlConnRepo.GetByTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return uc.settingsRepo.Get(ctx, tenant)
+}
+
+type UpdateOrganizationSettingsUseCase struct {
+	samlConnRepo contract.SAMLConnectionRepository
+	settingsRepo contract.OrganizationSettingsRepository
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewUpdateOrganizationSettingsUseCase(
+	samlConnRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *UpdateOrganizationSettingsUseCase {
+	return &UpdateOrganizationSettingsUseCase{samlConnRepo: samlConnRepo, settingsRepo: settingsRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute replaces the settings of a tenant. They apply from the members'
+// next sign-in or request on; the default member role from their next
+// token.
+func (uc *UpdateOrganizationSettingsUseCase) Execute(ctx context.Context, input *dto.UpdateOrganizationSettingsInput) (_ *entity.OrganizationSettings, err error) {
+	defer instrument.Observe("admin.update_organization_settings", time.Now(), &err)
+
+	domains := make([]string, 0, len(input.AllowedCallbackDomains))
+	for _, d := range input.AllowedCallbackDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if err := entity.ValidateCallbackDomain(d); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+	if input.DefaultMemberRole != "" {
+		if err := entity.ValidateRoles([]string{input.DefaultMemberRole}); err != nil {
+			return nil, err
+		}
+		// Admins are named by ADMIN_EMAILS alone.
+		if input.DefaultMemberRole == entity.ROLE_ADMIN {
+			return nil, errs.ErrAdminRoleNotAllowed
+		}
+	}
+
+	c, err := uc.samlConnRepo.GetByTenant(ctx, input.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := uc.settingsRepo.Get(ctx, input.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	next := &entity.OrganizationSettings{
+		Tenant:                 input.Tenant,
+		AllowedCallbackDomains: domains,
+		DefaultMemberRole:      input.DefaultMemberRole,
+		RequireMFA:             input.RequireMFA,
+		SessionMaxAgeSeconds:   input.SessionMaxAgeSeconds,
+		UpdatedBy:              input.ActorID,
+		UpdatedAt:              &now,
+	}
+	changed := changedSettings(previous, next)
+	if len(changed) == 0 {
+		return previous, nil
+	}
+	updated, err := uc.settingsRepo.Put(ctx, next)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_TENANT_SETTINGS_CHANGED,
+		ActorID:   input.ActorID,
+		SubjectID: c.ID,
+		Detail:    c.Tenant + ": " + strings.Join(changed, ", "),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// changedSettings names the settings that differ, by their JSON name.
+func changedSettings(a, b *entity.OrganizationSettings) []string {
+	var changed []string
+	if !slices.Equal(a.AllowedCallbackDomains, b.AllowedCallbackDomains) {
+		changed = append(changed, "allowed_callback_domains")
+	}
+	if a.DefaultMemberRole != b.DefaultMemberRole {
+		changed = append(changed, "default_member_role")
+	}
+	if a.RequireMFA != b.RequireMFA {
+		changed = append(changed, "require_mfa")
+	}
+	if a.SessionMaxAgeSeconds != b.SessionMaxAgeSeconds {
+		changed = append(changed, "session_max_age_seconds")
+	}
+	return changed
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/quota"
+)
+
+type SetTenantQuotaUseCase struct {
+	samlConnRepo contract.SAMLConnectionRepository
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewSetTenantQuotaUseCase(samlConnRepo contract.SAMLConnectionRepository, auditLogRepo contract.AuditLogRepository) *SetTenantQuotaUseCase {
+	return &SetTenantQuotaUseCase{samlConnRepo: samlConnRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute sets the request quota a tenant's accounts share, which applies
+// from their next request on; empty limits remove it.
+func (uc *SetTenantQuotaUseCase) Execute(ctx context.Context, actorID uuid.UUID, tenant, limits string) (_ *entity.SAMLConnection, err error) {
+	defer instrument.Observe("admin.set_tenant_quota", time.Now(), &err)
+
+	limits = strings.TrimSpace(limits)
+	if limits != "" {
+		if _, err := quota.ParseLimits(limits); err != nil {
+			return nil, errs.ErrInvalidQuotaLimits
+		}
+	}
+
+	c, err := uc.samlConnRepo.GetByTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	previous := c.QuotaLimits
+	if previous == limits {
+		return c, nil
+	}
+
+	c.QuotaLimits = limits
+	updated, err := uc.samlConnRepo.Update(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_TENANT_QUOTA_CHANGED,
+		ActorID:   actorID,
+		SubjectID: c.ID,
+		Detail:    c.Tenant + ": " + limitsName(previous) + " -> " + limitsName(limits),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func limitsName(limits string) string {
+	if limits == "" {
+		return "none"
+	}
+	return limits
+}
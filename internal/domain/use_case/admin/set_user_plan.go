@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type SetUserPlanUseCase struct {
+	userRepo     contract.UserRepository
+	auditLogRepo contract.AuditLogRepository
+	plans        []string
+}
+
+// NewSetUserPlanUseCase takes the names of the configured quota plans.
+func NewSetUserPlanUseCase(userRepo contract.UserRepository, auditLogRepo contract.AuditLogRepository, plans []string) *SetUserPlanUseCase {
+	return &SetUserPlanUseCase{userRepo: userRepo, auditLogRepo: auditLogRepo, plans: plans}
+}
+
+// Execute moves a user to another quota plan; an empty plan returns them to
+// the default one.
+func (uc *SetUserPlanUseCase) Execute(ctx context.Context, actorID, userID uuid.UUID, plan string) (_ *entity.User, err error) {
+	defer instrument.Observe("admin.set_user_plan", time.Now(), &err)
+
+	if plan != "" && !slices.Contains(uc.plans, plan) {
+		return nil, errs.ErrUnknownPlan
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := u.Plan
+	if previous == plan {
+		return u, nil
+	}
+
+	u.Plan = plan
+	updated, err := uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_USER_PLAN_CHANGED,
+		ActorID:   actorID,
+		SubjectID: u.ID,
+		Detail:    planName(previous) + " -> " + planName(plan),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func planName(plan string) string {
+	if plan == "" {
+		return "default"
+	}
+	return plan
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type SetUserStatusUseCase struct {
+	userRepo     contract.UserRepository
+	sessionRepo  contract.SessionRepository
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewSetUserStatusUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *SetUserStatusUseCase {
+	return &SetUserStatusUseCase{userRepo: userRepo, sessionRepo: sessionRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute moves a user to another status and records the change in the
+// audit log. Suspension keeps the user's sessions so reactivation restores
+// them; a ban revokes them. Setting the current status is a no-op.
+func (uc *SetUserStatusUseCase) Execute(ctx context.Context, input *dto.SetUserStatusInput) (_ *entity.User, err error) {
+	defer instrument.Observe("admin.set_user_status", time.Now(), &err)
+
+	if !entity.ValidUserStatus(input.Status) {
+		return nil, errs.ErrInvalidStatus
+	}
+	if input.ActorID == input.UserID && input.Status != entity.USER_STATUS_ACTIVE {
+		return nil, errs.ErrCannotLockSelf
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	previous := u.Status
+	if previous == input.Status {
+		return u, nil
+	}
+
+	now := time.Now().UTC()
+	u.Status = input.Status
+	u.StatusChangedAt = &now
+	updated, err := uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status == entity.USER_STATUS_BANNED {
+		if _, err := uc.sessionRepo.RevokeAllByUser(ctx, u.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_USER_STATUS_CHANGED,
+		ActorID:   input.ActorID,
+		SubjectID: u.ID,
+		Reason:    input.Reason,
+		Detail:    previous + " -> " + input.Status,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type DeviceGuardArgs struct {
+	KnownDeviceRepo    contract.KnownDeviceRepository
+	DeviceApprovalRepo contract.DeviceApprovalRepository
+	Mailer             contract.Mailer
+	// GeoLocator adds where the sign-in came from to the alert.
+	GeoLocator contract.GeoLocator
+	// Organizations names the tenants whose members must approve new
+	// devices, see entity.OrganizationSettings.RequireMFA, even when the
+	// guard is not Enabled.
+	Organizations contract.OrganizationSettingsRepository
+	Enabled       bool
+	// RequireApproval withholds tokens from new devices until the emailed
+	// approve link is followed.
+	RequireApproval bool
+	ApprovalTTL     time.Duration
+	// LinkBaseURL is the public URL the approve and deny paths are appended to.
+	LinkBaseURL string
+}
+
+// DeviceGuard detects sign-ins from devices a user has not used before and
+// emails them approve/deny links.
+type DeviceGuard struct {
+	knownDeviceRepo    contract.KnownDeviceRepository
+	deviceApprovalRepo contract.DeviceApprovalRepository
+	mailer             contract.Mailer
+	geoLocator         contract.GeoLocator
+	organizations      contract.OrganizationSettingsRepository
+	enabled            bool
+	requireApproval    bool
+	approvalTTL        time.Duration
+	linkBaseURL        string
+}
+
+func NewDeviceGuard(args DeviceGuardArgs) *DeviceGuard {
+	return &DeviceGuard{
+		knownDeviceRepo:    args.KnownDeviceRepo,
+		deviceApprovalRepo: args.DeviceApprovalRepo,
+		mailer:             args.Mailer,
+		geoLocator:         args.GeoLocator,
+		organizations:      args.Organizations,
+		enabled:            args.Enabled,
+		requireApproval:    args.RequireApproval,
+		approvalTTL:        args.ApprovalTTL,
+		linkBaseURL:        args.LinkBaseURL,
+	}
+}
+
+// Check runs after the credentials are verified and before tokens are issued.
+// It reports whether the device is new; in approval mode, or when the user's
+// organization requires MFA, a new device gets the approval email and
+// ErrDeviceVerificationRequired instead.
+func (g *DeviceGuard) Check(ctx context.Context, u *entity.User, client dto.ClientInfo) (bool, error) {
+	if g == nil {
+		return false, nil
+	}
+	mfa, err := g.requiresMFA(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	if !g.enabled && !mfa {
+		return false, nil
+	}
+
+	known, err := g.knownDeviceRepo.IsKnown(ctx, u.ID, client.DeviceFingerprint)
+	if err != nil {
+		return false, err
+	}
+	if known {
+		return false, g.remember(ctx, u.ID, client)
+	}
+
+	if g.requireApproval || mfa {
+		if err := g.requestReview(ctx, u, client, uuid.Nil); err != nil {
+			return true, err
+		}
+		return true, errs.ErrDeviceVerificationRequired
+	}
+	return true, nil
+}
+
+// requiresMFA reports whether the organization u belongs to requires MFA.
+func (g *DeviceGuard) requiresMFA(ctx context.Context, u *entity.User) (bool, error) {
+	if g.organizations == nil || u.TenantID == "" {
+		return false, nil
+	}
+	settings, err := g.organizations.Get(ctx, u.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return settings.RequireMFA, nil
+}
+
+// Alert remembers a new device that was let in and emails the user so they
+// can deny it, which revokes sessionID. Mail failures are logged rather than
+// failing the sign-in.
+func (g *DeviceGuard) Alert(ctx context.Context, u *entity.User, client dto.ClientInfo, sessionID uuid.UUID) {
+	if err := g.remember(ctx, u.ID, client); err != nil {
+		ctxutil.Logger(ctx).Warnw("remember device", "user_id", u.ID, "error", err)
+	}
+	if err := g.requestReview(ctx, u, client, sessionID); err != nil {
+		ctxutil.Logger(ctx).Warnw("send new device alert", "user_id", u.ID, "error", err)
+	}
+}
+
+func (g *DeviceGuard) remember(ctx context.Context, userID uuid.UUID, client dto.ClientInfo) error {
+	now := time.Now().UTC()
+	return g.knownDeviceRepo.Remember(ctx, &entity.KnownDevice{
+		UserID:      userID,
+		Fingerprint: client.DeviceFingerprint,
+		UserAgent:   client.UserAgent,
+		LastIP:      client.IP,
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	})
+}
+
+func (g *DeviceGuard) requestReview(ctx context.Context, u *entity.User, client dto.ClientInfo, sessionID uuid.UUID) error {
+	token, err := securetoken.New(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = g.deviceApprovalRepo.Create(ctx, &entity.DeviceApproval{
+		UserID:      u.ID,
+		SessionID:   sessionID,
+		Fingerprint: client.DeviceFingerprint,
+		UserAgent:   client.UserAgent,
+		IP:          client.IP,
+		TokenHash:   securetoken.Hash(token),
+		Status:      entity.DEVICE_APPROVAL_PENDING,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(g.approvalTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	location, err := g.geoLocator.Locate(ctx, client.IP)
+	if err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "auth.locate_ip", 100).Warnw("locate new device ip", "ip", client.IP, "error", err)
+	}
+
+	query := "?token=" + url.QueryEscape(token)
+	return g.mailer.Send(ctx, dto.Email{
+		To:       u.Email,
+		Template: dto.EMAIL_NEW_DEVICE,
+		Data: map[string]any{
+			"Device":      client.Device.String(),
+			"UserAgent":   client.UserAgent,
+			"IP":          client.IP,
+			"Location":    location.String(),
+			"Time":        now.Format(time.RFC1123),
+			"ApproveLink": g.linkBaseURL + "/approve" + query,
+			"DenyLink":    g.linkBaseURL + "/deny" + query,
+		},
+	})
+}
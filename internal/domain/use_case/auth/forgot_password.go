@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ForgotPasswordUseCase struct {
+	userRepo          contract.UserRepository
+	passwordResetRepo contract.PasswordResetRepository
+	mailer            contract.Mailer
+	// linkBaseURL is the page that receives the token and collects the new
+	// password.
+	linkBaseURL string
+	ttl         time.Duration
+}
+
+func NewForgotPasswordUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	mailer contract.Mailer,
+	linkBaseURL string,
+	ttl time.Duration,
+) *ForgotPasswordUseCase {
+	return &ForgotPasswordUseCase{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		mailer:            mailer,
+		linkBaseURL:       linkBaseURL,
+		ttl:               ttl,
+	}
+}
+
+// Execute emails a reset link. Unknown addresses, and deleted accounts
+// awaiting their purge, succeed silently so the endpoint cannot be used to
+// discover accounts.
+func (uc *ForgotPasswordUseCase) Execute(ctx context.Context, email string) (err error) {
+	defer instrument.Observe("auth.forgot_password", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.IsDeleted() {
+		return nil
+	}
+
+	token, err := securetoken.New(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = uc.passwordResetRepo.Create(ctx, &entity.PasswordReset{
+		UserID:    u.ID,
+		TokenHash: securetoken.Hash(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, dto.Email{
+		To:       u.Email,
+		Template: dto.EMAIL_PASSWORD_RESET,
+		Data: map[string]any{
+			"Link": uc.linkBaseURL + "?token=" + url.QueryEscape(token),
+			"TTL":  uc.ttl.String(),
+		},
+	})
+}
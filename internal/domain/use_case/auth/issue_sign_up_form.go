@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/policy"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// IssueSignUpFormUseCase hands out the token a sign-up form is shown with,
+// which bot detection dates the submission by.
+type IssueSignUpFormUseCase struct {
+	bot *policy.BotPolicy
+}
+
+func NewIssueSignUpFormUseCase(bot *policy.BotPolicy) *IssueSignUpFormUseCase {
+	return &IssueSignUpFormUseCase{bot: bot}
+}
+
+func (uc *IssueSignUpFormUseCase) Execute(ctx context.Context) (_ *dto.SignUpForm, err error) {
+	defer instrument.Observe("auth.issue_sign_up_form", time.Now(), &err)
+
+	token, expiresAt, err := uc.bot.IssueFormToken(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SignUpForm{FormToken: token, ExpiresAt: expiresAt}, nil
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// LoginRecorder appends every sign-in attempt to the login history and
+// tracks the failed ones for analytics.
+type LoginRecorder struct {
+	loginAttemptRepo contract.LoginAttemptRepository
+	geoLocator       contract.GeoLocator
+	analytics        contract.AnalyticsTracker
+}
+
+func NewLoginRecorder(loginAttemptRepo contract.LoginAttemptRepository, geoLocator contract.GeoLocator, analytics contract.AnalyticsTracker) *LoginRecorder {
+	return &LoginRecorder{loginAttemptRepo: loginAttemptRepo, geoLocator: geoLocator, analytics: analytics}
+}
+
+// Record stores the outcome of a sign-in. u is nil when the email or username
+// matched no account. Failures to record are logged, never surfaced to the caller.
+func (lr *LoginRecorder) Record(ctx context.Context, input *dto.SignInInput, u *entity.User, signInErr error) {
+	if lr == nil {
+		return
+	}
+
+	attempt := &entity.LoginAttempt{
+		Email:         input.Email,
+		Username:      input.Username,
+		Phone:         input.Phone,
+		Success:       signInErr == nil,
+		FailureReason: failureReason(signInErr),
+		IP:            input.Client.IP,
+		UserAgent:     input.Client.UserAgent,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if u != nil {
+		attempt.UserID = u.ID
+	}
+
+	location, err := lr.geoLocator.Locate(ctx, input.Client.IP)
+	if err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "auth.locate_ip", 100).Warnw("locate sign-in ip", "ip", input.Client.IP, "error", err)
+	}
+	attempt.CountryCode, attempt.Country, attempt.City = location.CountryCode, location.Country, location.City
+
+	if signInErr != nil {
+		e := dto.AnalyticsEvent{
+			Name:       dto.ANALYTICS_LOGIN_FAILED,
+			Properties: map[string]any{"reason": attempt.FailureReason},
+		}
+		if attempt.CountryCode != "" {
+			e.Properties["country"] = attempt.CountryCode
+		}
+		if u != nil {
+			e.UserID, e.TenantID = u.ID, u.TenantID
+		}
+		lr.analytics.Track(ctx, e)
+	}
+
+	if _, err := lr.loginAttemptRepo.Create(ctx, attempt); err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "auth.record_login", 100).Warnw("record login attempt", "email", input.Email, "username", input.Username, "error", err)
+	}
+}
+
+func failureReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errs.ErrInvalidCredentials):
+		return "invalid_credentials"
+	case errors.Is(err, errs.ErrInvalidOTP):
+		return "invalid_code"
+	case errors.Is(err, errs.ErrDeviceVerificationRequired):
+		return "device_verification_required"
+	case errors.Is(err, errs.ErrPasswordExpired):
+		return "password_expired"
+	case errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned):
+		return "account_locked"
+	case errors.Is(err, errs.ErrAccountDeleted):
+		return "account_deleted"
+	case errors.Is(err, errs.ErrInvalidSAMLResponse):
+		return "invalid_saml_response"
+	case errors.Is(err, errs.ErrSAMLAccountConflict), errors.Is(err, errs.ErrSAMLUserNotProvisioned):
+		return "sso_account_rejected"
+	default:
+		return "internal_error"
+	}
+}
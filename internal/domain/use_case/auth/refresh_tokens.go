@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RefreshTokensUseCase struct {
+	userRepo     contract.UserRepository
+	sessionRepo  contract.SessionRepository
+	settingsRepo contract.OrganizationSettingsRepository
+	tokenIssuer  contract.TokenIssuer
+}
+
+func NewRefreshTokensUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	tokenIssuer contract.TokenIssuer,
+) *RefreshTokensUseCase {
+	return &RefreshTokensUseCase{userRepo: userRepo, sessionRepo: sessionRepo, settingsRepo: settingsRepo, tokenIssuer: tokenIssuer}
+}
+
+// Execute rotates the refresh token of a session. Presenting an already
+// rotated refresh token means it leaked, so the whole session is revoked,
+// as is a session older than the session max age of the user's
+// organization.
+func (uc *RefreshTokensUseCase) Execute(ctx context.Context, input *dto.RefreshTokensInput) (_ *dto.AuthTokens, err error) {
+	defer instrument.Observe("auth.refresh_tokens", time.Now(), &err)
+
+	claims, err := uc.tokenIssuer.VerifyRefreshToken(ctx, input.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session, err := uc.sessionRepo.GetByID(ctx, claims.SessionID)
+	if err != nil {
+		return nil, errs.ErrSessionRevoked
+	}
+	if session.UserID != claims.UserID || !session.IsActive(now) {
+		return nil, errs.ErrSessionRevoked
+	}
+	if session.RefreshTokenID != claims.TokenID {
+		if err := uc.sessionRepo.Revoke(ctx, session.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, errs.ErrSessionRevoked
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.CheckStatus(); err != nil {
+		return nil, err
+	}
+	if u.TenantID != "" {
+		settings, err := uc.settingsRepo.Get(ctx, u.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.SessionTooOld(session.CreatedAt, now) {
+			if err := uc.sessionRepo.Revoke(ctx, session.ID, now); err != nil {
+				return nil, err
+			}
+			return nil, errs.ErrSessionTooOld
+		}
+	}
+	tokens, err := uc.tokenIssuer.IssueTokens(ctx, u, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.RefreshTokenID = tokens.RefreshTokenID
+	session.ExpiresAt = tokens.RefreshExpiresAt
+	session.LastSeenAt = now
+	if input.Client.IP != "" {
+		session.IP = input.Client.IP
+	}
+	if _, err := uc.sessionRepo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/internal/domain/use_case/phone"
+)
+
+type RequestSignInCodeUseCase struct {
+	userRepo   contract.UserRepository
+	otpService *phone.OTPService
+}
+
+func NewRequestSignInCodeUseCase(userRepo contract.UserRepository, otpService *phone.OTPService) *RequestSignInCodeUseCase {
+	return &RequestSignInCodeUseCase{userRepo: userRepo, otpService: otpService}
+}
+
+// Execute texts a sign-in code to a verified phone number. Unknown numbers
+// succeed silently so the endpoint cannot be used to discover accounts, and
+// so does a rate-limited request: only registered numbers are ever limited,
+// so ErrOTPRateLimited would give them away.
+func (uc *RequestSignInCodeUseCase) Execute(ctx context.Context, rawPhone string) (err error) {
+	defer instrument.Observe("auth.request_sign_in_code", time.Now(), &err)
+
+	number, err := entity.NormalizePhone(rawPhone)
+	if err != nil {
+		return err
+	}
+
+	u, err := uc.userRepo.GetByPhone(ctx, number)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = uc.otpService.Issue(ctx, number, entity.OTP_PURPOSE_SIGN_IN, u.ID)
+	if errors.Is(err, errs.ErrOTPRateLimited) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/phone"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+)
+
+// smsCounter counts the texts it is asked to send.
+type smsCounter struct{ sent int }
+
+func (s *smsCounter) Send(context.Context, dto.SMSMessage) error {
+	s.sent++
+	return nil
+}
+
+func TestRequestSignInCodeHidesRateLimit(t *testing.T) {
+	ctx := context.Background()
+	const registered, unknown = "+15555550100", "+15555550199"
+	users := infrastructure.NewUserRepository()
+	verifiedAt := time.Now().UTC()
+	if _, err := users.Create(ctx, &entity.User{Email: "a@example.com", Phone: registered, PhoneVerifiedAt: &verifiedAt}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	sender := &smsCounter{}
+	uc := NewRequestSignInCodeUseCase(users, phone.NewOTPService(phone.OTPServiceArgs{
+		OTPRepo:        infrastructure.NewPhoneOTPRepository(),
+		SMSSender:      sender,
+		TTL:            5 * time.Minute,
+		CodeLength:     6,
+		MaxAttempts:    3,
+		ResendInterval: time.Minute,
+		MaxPerHour:     3,
+	}))
+
+	for _, number := range []string{registered, registered, unknown, unknown} {
+		if err := uc.Execute(ctx, number); err != nil {
+			t.Errorf("request for %s: err = %v, want nil", number, err)
+		}
+	}
+	if sender.sent != 1 {
+		t.Errorf("sent %d codes, want 1", sender.sent)
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ResetPasswordUseCase struct {
+	userRepo          contract.UserRepository
+	passwordResetRepo contract.PasswordResetRepository
+	sessionRepo       contract.SessionRepository
+	hasher            contract.PasswordHasher
+	passwordPolicy    contract.PasswordPolicy
+}
+
+func NewResetPasswordUseCase(
+	userRepo contract.UserRepository,
+	passwordResetRepo contract.PasswordResetRepository,
+	sessionRepo contract.SessionRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+) *ResetPasswordUseCase {
+	return &ResetPasswordUseCase{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		sessionRepo:       sessionRepo,
+		hasher:            hasher,
+		passwordPolicy:    passwordPolicy,
+	}
+}
+
+// Execute sets the new password and signs the user out everywhere, since a
+// reset usually means the old password can no longer be trusted.
+func (uc *ResetPasswordUseCase) Execute(ctx context.Context, input *dto.ResetPasswordInput) (err error) {
+	defer instrument.Observe("auth.reset_password", time.Now(), &err)
+
+	reset, err := uc.passwordResetRepo.GetByTokenHash(ctx, securetoken.Hash(input.Token))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if !reset.IsUsable(now) {
+		return errs.ErrInvalidResetToken
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, reset.UserID)
+	if err != nil {
+		return err
+	}
+	if err := uc.passwordPolicy.CheckPassword(ctx, input.Password, u.Email, u.Username); err != nil {
+		return err
+	}
+	hashed, err := uc.hasher.Hash(ctx, input.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.passwordResetRepo.MarkUsed(ctx, reset.ID, now); err != nil {
+		return err
+	}
+	u.SetPassword(hashed, now)
+	if _, err := uc.userRepo.Update(ctx, u); err != nil {
+		return err
+	}
+
+	_, err = uc.sessionRepo.RevokeAllByUser(ctx, u.ID, now)
+	return err
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+var externalIdentitiesLinked = metrics.NewCounter("external_identities_linked_total",
+	"External subjects linked to local users on first use, by issuer and how (provisioned, email).", "issuer", "how")
+
+type ResolveExternalUserUseCaseArgs struct {
+	UserRepo     contract.UserRepository
+	IdentityRepo contract.ExternalIdentityRepository
+	Hasher       contract.PasswordHasher
+	// Provision creates an account for subjects whose verified email has
+	// none.
+	Provision bool
+	// LinkByEmail links subjects to the existing account of their verified
+	// email. Only enable it for issuers trusted to verify emails.
+	LinkByEmail bool
+}
+
+// ResolveExternalUserUseCase finds the local user an external issuer's
+// subject acts as. The first time a subject is seen it is linked to a user,
+// by creating one or, when allowed, by its verified email; later tokens
+// find that user by the link alone, whatever email they carry.
+type ResolveExternalUserUseCase struct {
+	userRepo     contract.UserRepository
+	identityRepo contract.ExternalIdentityRepository
+	hasher       contract.PasswordHasher
+	provision    bool
+	linkByEmail  bool
+}
+
+func NewResolveExternalUserUseCase(args ResolveExternalUserUseCaseArgs) *ResolveExternalUserUseCase {
+	return &ResolveExternalUserUseCase{
+		userRepo:     args.UserRepo,
+		identityRepo: args.IdentityRepo,
+		hasher:       args.Hasher,
+		provision:    args.Provision,
+		linkByEmail:  args.LinkByEmail,
+	}
+}
+
+// Execute returns ErrExternalUserNotProvisioned when the subject is not
+// linked and cannot be, and ErrExternalAccountConflict when its email
+// belongs to an account it may not be linked to.
+func (uc *ResolveExternalUserUseCase) Execute(ctx context.Context, claims *dto.ExternalIdentityClaims) (_ *entity.User, err error) {
+	defer instrument.Observe("auth.resolve_external_user", time.Now(), &err)
+
+	identity, err := uc.identityRepo.Get(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		return uc.userRepo.GetByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, errs.ErrExternalIdentityNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, errs.ErrExternalUserNotProvisioned
+	}
+	u, err := uc.userRepo.GetByEmail(ctx, email)
+	switch {
+	case errors.Is(err, errs.ErrUserNotFound):
+		if !uc.provision {
+			return nil, errs.ErrExternalUserNotProvisioned
+		}
+		return uc.create(ctx, claims, email)
+	case err != nil:
+		return nil, err
+	case !uc.linkByEmail || u.TenantID != "":
+		// Accounts of a SAML tenant sign in through its identity provider
+		// only.
+		return nil, errs.ErrExternalAccountConflict
+	}
+	return uc.link(ctx, claims, u, "email")
+}
+
+// create provisions the account like a SAML sign-in does: with a random
+// password nobody knows, and the subject's username when it is free.
+func (uc *ResolveExternalUserUseCase) create(ctx context.Context, claims *dto.ExternalIdentityClaims, email string) (*entity.User, error) {
+	secret, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := uc.hasher.Hash(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+	u := &entity.User{Email: email, HashedPassword: hashed, PasswordUnset: true}
+	if err := u.Validate(); err != nil {
+		return nil, errs.ErrExternalUserNotProvisioned
+	}
+	if name := entity.NormalizeUsername(claims.Username); name != "" && entity.ValidateUsername(name) == nil {
+		if _, err := uc.userRepo.GetByUsername(ctx, name); errors.Is(err, errs.ErrUserNotFound) {
+			u.Username = name
+		}
+	}
+
+	u, err = uc.userRepo.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := uc.link(ctx, claims, u, "provisioned")
+	if errors.Is(err, errs.ErrExternalIdentityExists) {
+		// A concurrent first request linked the subject to the account it
+		// created; drop this one and use that.
+		if err := uc.userRepo.Delete(ctx, u.ID); err != nil {
+			return nil, err
+		}
+		identity, err := uc.identityRepo.Get(ctx, claims.Issuer, claims.Subject)
+		if err != nil {
+			return nil, err
+		}
+		return uc.userRepo.GetByID(ctx, identity.UserID)
+	}
+	return linked, err
+}
+
+func (uc *ResolveExternalUserUseCase) link(ctx context.Context, claims *dto.ExternalIdentityClaims, u *entity.User, how string) (*entity.User, error) {
+	_, err := uc.identityRepo.Create(ctx, &entity.ExternalIdentity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		UserID:  u.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	externalIdentitiesLinked.Inc(claims.Issuer, how)
+	return u, nil
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ReviewDeviceUseCase struct {
+	knownDeviceRepo    contract.KnownDeviceRepository
+	deviceApprovalRepo contract.DeviceApprovalRepository
+	sessionRepo        contract.SessionRepository
+}
+
+func NewReviewDeviceUseCase(
+	knownDeviceRepo contract.KnownDeviceRepository,
+	deviceApprovalRepo contract.DeviceApprovalRepository,
+	sessionRepo contract.SessionRepository,
+) *ReviewDeviceUseCase {
+	return &ReviewDeviceUseCase{
+		knownDeviceRepo:    knownDeviceRepo,
+		deviceApprovalRepo: deviceApprovalRepo,
+		sessionRepo:        sessionRepo,
+	}
+}
+
+// Execute applies the user's answer to a new device alert. Approving trusts
+// the device for future sign-ins; denying forgets it and revokes the session
+// it opened, if any.
+func (uc *ReviewDeviceUseCase) Execute(ctx context.Context, token string, approve bool) (_ *entity.DeviceApproval, err error) {
+	defer instrument.Observe("auth.review_device", time.Now(), &err)
+
+	approval, err := uc.deviceApprovalRepo.GetByTokenHash(ctx, securetoken.Hash(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(approval.ExpiresAt) {
+		return nil, errs.ErrDeviceApprovalNotFound
+	}
+	if approval.Status != entity.DEVICE_APPROVAL_PENDING {
+		return nil, errs.ErrDeviceApprovalDecided
+	}
+
+	if approve {
+		approval.Status = entity.DEVICE_APPROVAL_APPROVED
+		err = uc.knownDeviceRepo.Remember(ctx, &entity.KnownDevice{
+			UserID:      approval.UserID,
+			Fingerprint: approval.Fingerprint,
+			UserAgent:   approval.UserAgent,
+			LastIP:      approval.IP,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		})
+	} else {
+		approval.Status = entity.DEVICE_APPROVAL_DENIED
+		err = uc.knownDeviceRepo.Forget(ctx, approval.UserID, approval.Fingerprint)
+		if err == nil && approval.SessionID != uuid.Nil {
+			err = uc.sessionRepo.Revoke(ctx, approval.SessionID, now)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	approval.DecidedAt = &now
+	return uc.deviceApprovalRepo.Update(ctx, approval)
+}
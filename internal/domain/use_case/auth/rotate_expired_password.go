@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// RotateExpiredPasswordUseCase is the way out of ErrPasswordExpired: the
+// user proves the old password, sets a new one and is signed in.
+type RotateExpiredPasswordUseCase struct {
+	signIn         *SignInUseCase
+	userRepo       contract.UserRepository
+	passwordPolicy contract.PasswordPolicy
+}
+
+func NewRotateExpiredPasswordUseCase(
+	signIn *SignInUseCase,
+	userRepo contract.UserRepository,
+	passwordPolicy contract.PasswordPolicy,
+) *RotateExpiredPasswordUseCase {
+	return &RotateExpiredPasswordUseCase{signIn: signIn, userRepo: userRepo, passwordPolicy: passwordPolicy}
+}
+
+func (uc *RotateExpiredPasswordUseCase) Execute(ctx context.Context, input *dto.RotatePasswordInput) (_ *dto.AuthTokens, err error) {
+	defer instrument.Observe("auth.rotate_expired_password", time.Now(), &err)
+
+	u, tokens, err := uc.rotate(ctx, input)
+	uc.signIn.loginRecorder.Record(ctx, &input.SignInInput, u, err)
+	return tokens, err
+}
+
+func (uc *RotateExpiredPasswordUseCase) rotate(ctx context.Context, input *dto.RotatePasswordInput) (*entity.User, *dto.AuthTokens, error) {
+	u, err := uc.signIn.authenticate(ctx, &input.SignInInput)
+	if err != nil {
+		return u, nil, err
+	}
+
+	now := time.Now().UTC()
+	if !u.PasswordExpired(uc.signIn.passwordMaxAge, now) {
+		return u, nil, errs.ErrPasswordNotExpired
+	}
+	reused, err := uc.signIn.hasher.Compare(ctx, u.HashedPassword, input.NewPassword)
+	if err != nil {
+		return u, nil, err
+	}
+	if reused {
+		return u, nil, errs.ErrPasswordReused
+	}
+	if err := uc.passwordPolicy.CheckPassword(ctx, input.NewPassword, u.Email, u.Username); err != nil {
+		return u, nil, err
+	}
+
+	hashed, err := uc.signIn.hasher.Hash(ctx, input.NewPassword)
+	if err != nil {
+		return u, nil, err
+	}
+	u.SetPassword(hashed, now)
+	updated, err := uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return u, nil, err
+	}
+
+	tokens, err := uc.signIn.complete(ctx, updated, input.Client)
+	return updated, tokens, err
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// dummyHash is compared against when no user matches, so an unknown email
+// takes as long to reject as a wrong password. It is a bcrypt hash at the
+// default cost, the same as the hashes of real passwords.
+const dummyHash = "$2a$10$A3zWBPnJ1Ol3HZxeluXmdebcDSwd6PUvppBhXYL3FaoBPnJJmrtSC"
+
+type SignInUseCaseArgs struct {
+	UserRepo      contract.UserRepository
+	SessionRepo   contract.SessionRepository
+	Hasher        contract.PasswordHasher
+	TokenIssuer   contract.TokenIssuer
+	DeviceGuard   *DeviceGuard
+	LoginRecorder *LoginRecorder
+	// PasswordMaxAge makes passwords expire; zero disables expiry.
+	PasswordMaxAge time.Duration
+}
+
+type SignInUseCase struct {
+	userRepo       contract.UserRepository
+	sessionRepo    contract.SessionRepository
+	hasher         contract.PasswordHasher
+	tokenIssuer    contract.TokenIssuer
+	deviceGuard    *DeviceGuard
+	loginRecorder  *LoginRecorder
+	passwordMaxAge time.Duration
+}
+
+func NewSignInUseCase(args SignInUseCaseArgs) *SignInUseCase {
+	return &SignInUseCase{
+		userRepo:       args.UserRepo,
+		sessionRepo:    args.SessionRepo,
+		hasher:         args.Hasher,
+		tokenIssuer:    args.TokenIssuer,
+		deviceGuard:    args.DeviceGuard,
+		loginRecorder:  args.LoginRecorder,
+		passwordMaxAge: args.PasswordMaxAge,
+	}
+}
+
+func (uc *SignInUseCase) Execute(ctx context.Context, input *dto.SignInInput) (_ *dto.AuthTokens, err error) {
+	defer instrument.Observe("auth.sign_in", time.Now(), &err)
+
+	u, tokens, err := uc.signIn(ctx, input)
+	uc.loginRecorder.Record(ctx, input, u, err)
+	return tokens, err
+}
+
+// signIn also returns the matched user, even on failure, so the attempt can
+// be attributed in the login history.
+func (uc *SignInUseCase) signIn(ctx context.Context, input *dto.SignInInput) (*entity.User, *dto.AuthTokens, error) {
+	u, err := uc.authenticate(ctx, input)
+	if err != nil {
+		return u, nil, err
+	}
+
+	// No tokens until the password is rotated, see RotateExpiredPasswordUseCase.
+	if u.PasswordExpired(uc.passwordMaxAge, time.Now().UTC()) {
+		return u, nil, errs.ErrPasswordExpired
+	}
+
+	tokens, err := uc.complete(ctx, u, input.Client)
+	return u, tokens, err
+}
+
+// authenticate checks the credentials and returns the matched user, if any.
+func (uc *SignInUseCase) authenticate(ctx context.Context, input *dto.SignInInput) (*entity.User, error) {
+	u, err := uc.findUser(ctx, input)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		if _, err := uc.hasher.Compare(ctx, dummyHash, input.Password); err != nil {
+			return nil, err
+		}
+		return nil, errs.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := uc.hasher.Compare(ctx, u.HashedPassword, input.Password)
+	if err != nil {
+		return u, err
+	}
+	if !match {
+		return u, errs.ErrInvalidCredentials
+	}
+	// Only revealed to callers who know the password.
+	if err := u.CheckStatus(); err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// complete runs the new device check and opens the session of an
+// authenticated user.
+func (uc *SignInUseCase) complete(ctx context.Context, u *entity.User, client dto.ClientInfo) (*dto.AuthTokens, error) {
+	newDevice, err := uc.deviceGuard.Check(ctx, u, client)
+	if err != nil {
+		return nil, err
+	}
+
+	session, tokens, err := startSession(ctx, uc.sessionRepo, uc.tokenIssuer, u, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if newDevice {
+		uc.deviceGuard.Alert(ctx, u, client, session.ID)
+	}
+	return tokens, nil
+}
+
+// startSession opens a new session for u and issues the tokens bound to it.
+// Locked accounts get their status error instead.
+func startSession(
+	ctx context.Context,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	u *entity.User,
+	client dto.ClientInfo,
+) (*entity.Session, *dto.AuthTokens, error) {
+	if err := u.CheckStatus(); err != nil {
+		return nil, nil, err
+	}
+
+	sessionID := uuid.New()
+	tokens, err := tokenIssuer.IssueTokens(ctx, u, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	session, err := sessionRepo.Create(ctx, &entity.Session{
+		ID:                sessionID,
+		UserID:            u.ID,
+		RefreshTokenID:    tokens.RefreshTokenID,
+		DeviceFingerprint: client.DeviceFingerprint,
+		UserAgent:         client.UserAgent,
+		IP:                client.IP,
+		CreatedAt:         now,
+		LastSeenAt:        now,
+		ExpiresAt:         tokens.RefreshExpiresAt,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, tokens, nil
+}
+
+func (uc *SignInUseCase) findUser(ctx context.Context, input *dto.SignInInput) (*entity.User, error) {
+	if input.Email == "" {
+		return uc.userRepo.GetByUsername(ctx, input.Username)
+	}
+	return uc.userRepo.GetByEmail(ctx, input.Email)
+}
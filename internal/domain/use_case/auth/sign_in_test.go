@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+)
+
+// compareRecorder records the hashes it is asked to compare against.
+type compareRecorder struct{ hashes []string }
+
+func (h *compareRecorder) Hash(context.Context, string) (string, error) { return "", nil }
+
+func (h *compareRecorder) Compare(_ context.Context, hashed, _ string) (bool, error) {
+	h.hashes = append(h.hashes, hashed)
+	return false, nil
+}
+
+func TestSignInUnknownUserComparesDummyHash(t *testing.T) {
+	hasher := &compareRecorder{}
+	uc := NewSignInUseCase(SignInUseCaseArgs{
+		UserRepo: infrastructure.NewUserRepository(),
+		Hasher:   hasher,
+	})
+
+	_, err := uc.Execute(context.Background(), &dto.SignInInput{Email: "nobody@example.com", Password: "guess"})
+	if !errors.Is(err, errs.ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if len(hasher.hashes) != 1 || hasher.hashes[0] != dummyHash {
+		t.Errorf("compared against %q, want the dummy hash once", hasher.hashes)
+	}
+}
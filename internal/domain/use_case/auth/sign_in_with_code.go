@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/internal/domain/use_case/phone"
+)
+
+type SignInWithCodeUseCaseArgs struct {
+	UserRepo      contract.UserRepository
+	SessionRepo   contract.SessionRepository
+	TokenIssuer   contract.TokenIssuer
+	OTPService    *phone.OTPService
+	DeviceGuard   *DeviceGuard
+	LoginRecorder *LoginRecorder
+}
+
+// SignInWithCodeUseCase signs a user in with a code texted to their verified
+// phone number instead of a password.
+type SignInWithCodeUseCase struct {
+	userRepo      contract.UserRepository
+	sessionRepo   contract.SessionRepository
+	tokenIssuer   contract.TokenIssuer
+	otpService    *phone.OTPService
+	deviceGuard   *DeviceGuard
+	loginRecorder *LoginRecorder
+}
+
+func NewSignInWithCodeUseCase(args SignInWithCodeUseCaseArgs) *SignInWithCodeUseCase {
+	return &SignInWithCodeUseCase{
+		userRepo:      args.UserRepo,
+		sessionRepo:   args.SessionRepo,
+		tokenIssuer:   args.TokenIssuer,
+		otpService:    args.OTPService,
+		deviceGuard:   args.DeviceGuard,
+		loginRecorder: args.LoginRecorder,
+	}
+}
+
+func (uc *SignInWithCodeUseCase) Execute(ctx context.Context, input *dto.PhoneSignInInput) (_ *dto.AuthTokens, err error) {
+	defer instrument.Observe("auth.sign_in_with_code", time.Now(), &err)
+
+	u, tokens, err := uc.signIn(ctx, input)
+	uc.loginRecorder.Record(ctx, &dto.SignInInput{Phone: input.Phone, Client: input.Client}, u, err)
+	return tokens, err
+}
+
+func (uc *SignInWithCodeUseCase) signIn(ctx context.Context, input *dto.PhoneSignInInput) (*entity.User, *dto.AuthTokens, error) {
+	number, err := entity.NormalizePhone(input.Phone)
+	if err != nil {
+		return nil, nil, errs.ErrInvalidOTP
+	}
+
+	otp, err := uc.otpService.Verify(ctx, number, entity.OTP_PURPOSE_SIGN_IN, input.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The number may have moved to another account since the code was sent.
+	u, err := uc.userRepo.GetByPhone(ctx, number)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil, nil, errs.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.ID != otp.UserID {
+		return u, nil, errs.ErrInvalidOTP
+	}
+
+	newDevice, err := uc.deviceGuard.Check(ctx, u, input.Client)
+	if err != nil {
+		return u, nil, err
+	}
+
+	session, tokens, err := startSession(ctx, uc.sessionRepo, uc.tokenIssuer, u, input.Client)
+	if err != nil {
+		return u, nil, err
+	}
+
+	if newDevice {
+		uc.deviceGuard.Alert(ctx, u, input.Client, session.ID)
+	}
+	return u, tokens, nil
+}
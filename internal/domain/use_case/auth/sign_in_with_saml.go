@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+var loginStateRejections = metrics.NewCounter("federated_login_invalid_state_total",
+	"Identity provider callbacks rejected for their login state, by provider kind and reason.", "provider", "reason")
+
+// The reasons a callback's login state is rejected.
+const (
+	LOGIN_STATE_UNSOLICITED       = "unsolicited"
+	LOGIN_STATE_UNKNOWN           = "unknown"
+	LOGIN_STATE_BROWSER_MISMATCH  = "browser_mismatch"
+	LOGIN_STATE_PROVIDER_MISMATCH = "provider_mismatch"
+	LOGIN_STATE_RESPONSE_MISMATCH = "response_mismatch"
+)
+
+type SignInWithSAMLUseCaseArgs struct {
+	UserRepo        contract.UserRepository
+	SessionRepo     contract.SessionRepository
+	Hasher          contract.PasswordHasher
+	TokenIssuer     contract.TokenIssuer
+	ConnectionRepo  contract.SAMLConnectionRepository
+	ServiceProvider contract.SAMLServiceProvider
+	LoginStateRepo  contract.LoginStateRepository
+	LoginRecorder   *LoginRecorder
+	// AllowIdPInitiated accepts responses the identity provider sends
+	// unprompted, which carry no login state to check.
+	AllowIdPInitiated bool
+}
+
+// SignInWithSAMLUseCase signs a user in with an assertion from their
+// tenant's identity provider. The identity provider has authenticated the
+// user, so the new device check does not apply.
+//
+// Responses to a sign-in started here must come back with its login state,
+// in the same browser, and answer its AuthnRequest; otherwise a response
+// obtained elsewhere could be posted into a victim's browser to sign them in
+// to the attacker's account.
+type SignInWithSAMLUseCase struct {
+	userRepo          contract.UserRepository
+	sessionRepo       contract.SessionRepository
+	hasher            contract.PasswordHasher
+	tokenIssuer       contract.TokenIssuer
+	connectionRepo    contract.SAMLConnectionRepository
+	serviceProvider   contract.SAMLServiceProvider
+	loginStateRepo    contract.LoginStateRepository
+	loginRecorder     *LoginRecorder
+	allowIdPInitiated bool
+}
+
+func NewSignInWithSAMLUseCase(args SignInWithSAMLUseCaseArgs) *SignInWithSAMLUseCase {
+	return &SignInWithSAMLUseCase{
+		userRepo:          args.UserRepo,
+		sessionRepo:       args.SessionRepo,
+		hasher:            args.Hasher,
+		tokenIssuer:       args.TokenIssuer,
+		connectionRepo:    args.ConnectionRepo,
+		serviceProvider:   args.ServiceProvider,
+		loginStateRepo:    args.LoginStateRepo,
+		loginRecorder:     args.LoginRecorder,
+		allowIdPInitiated: args.AllowIdPInitiated,
+	}
+}
+
+// Execute returns ErrInvalidLoginState, with a nil result, when the response
+// does not belong to a sign-in started in this browser.
+func (uc *SignInWithSAMLUseCase) Execute(ctx context.Context, input *dto.SAMLSignInInput) (_ *dto.SAMLSignInResult, err error) {
+	defer instrument.Observe("auth.sign_in_with_saml", time.Now(), &err)
+
+	conn, err := uc.connectionRepo.GetByTenant(ctx, input.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := uc.serviceProvider.ParseResponse(ctx, conn, input.SAMLResponse)
+	if err != nil {
+		uc.loginRecorder.Record(ctx, &dto.SignInInput{Client: input.Client}, nil, err)
+		return nil, err
+	}
+
+	ls, err := uc.checkLoginState(ctx, conn, input, assertion)
+	if err != nil {
+		uc.loginRecorder.Record(ctx, &dto.SignInInput{Client: input.Client}, nil, err)
+		return nil, err
+	}
+	res := &dto.SAMLSignInResult{ReturnState: ls.ReturnState, ReturnTo: ls.ReturnTo}
+
+	email := assertion.NameID
+	if conn.EmailAttribute != "" {
+		email = assertion.Attribute(conn.EmailAttribute)
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	u, tokens, err := uc.signIn(ctx, conn, assertion, email, input.Client)
+	uc.loginRecorder.Record(ctx, &dto.SignInInput{Email: email, Client: input.Client}, u, err)
+	res.Tokens = tokens
+	return res, err
+}
+
+// checkLoginState consumes the login state the response came back with and
+// returns it. Unsolicited responses, with neither a login state nor an
+// AuthnRequest to answer, pass only when identity provider-initiated
+// sign-ins are allowed, with an empty login state.
+func (uc *SignInWithSAMLUseCase) checkLoginState(
+	ctx context.Context,
+	conn *entity.SAMLConnection,
+	input *dto.SAMLSignInInput,
+	assertion *dto.SAMLAssertion,
+) (*entity.LoginState, error) {
+	if input.RelayState == "" && assertion.InResponseTo == "" {
+		if uc.allowIdPInitiated {
+			return &entity.LoginState{}, nil
+		}
+		return nil, rejectLoginState(LOGIN_STATE_UNSOLICITED)
+	}
+
+	ls, err := uc.loginStateRepo.Consume(ctx, input.RelayState)
+	switch {
+	case errors.Is(err, errs.ErrInvalidLoginState):
+		return nil, rejectLoginState(LOGIN_STATE_UNKNOWN)
+	case err != nil:
+		return nil, err
+	case subtle.ConstantTimeCompare([]byte(input.BrowserState), []byte(ls.State)) != 1:
+		return nil, rejectLoginState(LOGIN_STATE_BROWSER_MISMATCH)
+	case ls.Provider != entity.SAMLLoginProvider(conn.Tenant):
+		return nil, rejectLoginState(LOGIN_STATE_PROVIDER_MISMATCH)
+	case assertion.InResponseTo != ls.Nonce:
+		return nil, rejectLoginState(LOGIN_STATE_RESPONSE_MISMATCH)
+	}
+	return ls, nil
+}
+
+func rejectLoginState(reason string) error {
+	loginStateRejections.Inc("saml", reason)
+	return errs.ErrInvalidLoginState
+}
+
+func (uc *SignInWithSAMLUseCase) signIn(
+	ctx context.Context,
+	conn *entity.SAMLConnection,
+	assertion *dto.SAMLAssertion,
+	email string,
+	client dto.ClientInfo,
+) (*entity.User, *dto.AuthTokens, error) {
+	if email == "" {
+		return nil, nil, errs.ErrInvalidSAMLResponse
+	}
+	if err := uc.connectionRepo.ConsumeAssertion(ctx, assertion.ID, assertion.ExpiresAt); err != nil {
+		return nil, nil, err
+	}
+
+	u, err := uc.userRepo.GetByEmail(ctx, email)
+	switch {
+	case errors.Is(err, errs.ErrUserNotFound):
+		if !conn.JITProvisioning {
+			return nil, nil, errs.ErrSAMLUserNotProvisioned
+		}
+		u, err = uc.provision(ctx, conn, assertion, email)
+		if err != nil {
+			return nil, nil, err
+		}
+	case err != nil:
+		return nil, nil, err
+	case u.TenantID != conn.Tenant:
+		// Otherwise a tenant's identity provider could sign in as any
+		// account by asserting its email.
+		return u, nil, errs.ErrSAMLAccountConflict
+	}
+
+	_, tokens, err := startSession(ctx, uc.sessionRepo, uc.tokenIssuer, u, client)
+	if err != nil {
+		return u, nil, err
+	}
+	return u, tokens, nil
+}
+
+// provision creates the account on first sign-in. It gets a random password
+// nobody knows, so it can only sign in through the identity provider until
+// a password is reset.
+func (uc *SignInWithSAMLUseCase) provision(
+	ctx context.Context,
+	conn *entity.SAMLConnection,
+	assertion *dto.SAMLAssertion,
+	email string,
+) (*entity.User, error) {
+	secret, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := uc.hasher.Hash(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &entity.User{Email: email, HashedPassword: hashed, PasswordUnset: true, TenantID: conn.Tenant}
+	if err := u.Validate(); err != nil {
+		return nil, errs.ErrInvalidSAMLResponse
+	}
+
+	// A username that is invalid or taken is dropped rather than failing
+	// the sign-in; the user can pick one later.
+	if conn.UsernameAttribute != "" {
+		name := entity.NormalizeUsername(assertion.Attribute(conn.UsernameAttribute))
+		if name != "" && entity.ValidateUsername(name) == nil {
+			if _, err := uc.userRepo.GetByUsername(ctx, name); errors.Is(err, errs.ErrUserNotFound) {
+				u.Username = name
+			}
+		}
+	}
+
+	return uc.userRepo.Create(ctx, u)
+}
@@ -2,39 +2,132 @@ package auth
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/haidang666/go-app/internal/domain/contract"
 	"github.com/haidang666/go-app/internal/domain/dto"
 	"github.com/haidang666/go-app/internal/domain/entity"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/saga"
 )
 
+// SIGN_UP_SAGA names the sign-up saga in the saga store.
+const SIGN_UP_SAGA = "sign_up"
+
 type SignUpUseCase struct {
-	userRepo contract.UserRepository
+	userRepo  contract.UserRepository
+	hasher    contract.PasswordHasher
+	sagaStore saga.Store
+	notifier  contract.Notifier
+	analytics contract.AnalyticsTracker
+	policies  []contract.SignUpPolicy
 }
 
-func NewSignUpUseCase(userRepo contract.UserRepository) *SignUpUseCase {
-	return &SignUpUseCase{userRepo: userRepo}
+// NewSignUpUseCase builds the use case; policies run in order before any
+// other validation and the first rejection wins.
+func NewSignUpUseCase(
+	userRepo contract.UserRepository,
+	hasher contract.PasswordHasher,
+	sagaStore saga.Store,
+	notifier contract.Notifier,
+	analytics contract.AnalyticsTracker,
+	policies ...contract.SignUpPolicy,
+) *SignUpUseCase {
+	return &SignUpUseCase{userRepo: userRepo, hasher: hasher, sagaStore: sagaStore, notifier: notifier, analytics: analytics, policies: policies}
 }
 
-func (uc *SignUpUseCase) Execute(ctx context.Context, input *dto.SignUpInput) (*entity.User, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+func (uc *SignUpUseCase) Execute(ctx context.Context, input *dto.SignUpInput) (_ *entity.User, err error) {
+	defer instrument.Observe("auth.sign_up", time.Now(), &err)
+
+	for _, p := range uc.policies {
+		if err := p.Check(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	hashed, err := uc.hasher.Hash(ctx, input.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	du := &entity.User{
 		Email:          input.Email,
-		HashedPassword: string(hashed),
+		Username:       entity.NormalizeUsername(input.Username),
+		HashedPassword: hashed,
 	}
 
 	if err := du.Validate(); err != nil {
 		return nil, err
 	}
-	newUser, err := uc.userRepo.Create(ctx, du)
-	if err != nil {
-		return nil, err
+
+	// Everything from creating the account on runs as a saga, so a failure
+	// in any step (e.g. an invitation used up concurrently) rolls back the
+	// account and what the earlier hooks did instead of leaving it
+	// half set up.
+	var newUser *entity.User
+	steps := []saga.Step{{
+		Name: "create_account",
+		Do: func(ctx context.Context) (err error) {
+			newUser, err = uc.userRepo.Create(ctx, du)
+			if err == nil {
+				saga.Record(ctx, "user_id", newUser.ID.String())
+			}
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			return uc.userRepo.Delete(ctx, newUser.ID)
+		},
+	}}
+	for _, p := range uc.policies {
+		hook, ok := p.(contract.SignUpHook)
+		if !ok {
+			continue
+		}
+		step := saga.Step{
+			Name: strings.TrimPrefix(fmt.Sprintf("%T", hook), "*"),
+			Do: func(ctx context.Context) error {
+				return hook.AfterSignUp(ctx, input, newUser)
+			},
+		}
+		if undo, ok := hook.(contract.SignUpCompensator); ok {
+			step.Compensate = func(ctx context.Context) error {
+				return undo.UndoSignUp(ctx, input, newUser)
+			}
+		}
+		steps = append(steps, step)
+	}
+	if !input.SkipWelcomeEmail {
+		steps = append(steps, saga.Step{
+			Name: "welcome_email",
+			Do: func(ctx context.Context) error {
+				return uc.notifier.Notify(ctx, dto.Notification{
+					UserID:   newUser.ID,
+					Category: entity.NOTIFY_CATEGORY_ACCOUNT,
+					Email: dto.Email{
+						To:       newUser.Email,
+						Template: dto.EMAIL_WELCOME,
+						Data:     map[string]any{"Email": newUser.Email},
+					},
+					Summary: "Welcome! Your account is ready.",
+				})
+			},
+		})
 	}
 
+	if err := saga.Run(ctx, uc.sagaStore, SIGN_UP_SAGA, steps...); err != nil {
+		return nil, err
+	}
+	uc.analytics.Track(ctx, dto.AnalyticsEvent{
+		Name:     dto.ANALYTICS_SIGN_UP_COMPLETED,
+		UserID:   newUser.ID,
+		TenantID: newUser.TenantID,
+		Properties: map[string]any{
+			// Accounts created without the public form are admin imports.
+			"self_service": input.Bot != nil,
+			"invited":      input.InviteCode != "",
+		},
+	})
 	return newUser, nil
 }
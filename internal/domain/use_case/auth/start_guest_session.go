@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type StartGuestSessionUseCase struct {
+	userRepo    contract.UserRepository
+	sessionRepo contract.SessionRepository
+	tokenIssuer contract.TokenIssuer
+	enabled     bool
+}
+
+func NewStartGuestSessionUseCase(
+	userRepo contract.UserRepository,
+	sessionRepo contract.SessionRepository,
+	tokenIssuer contract.TokenIssuer,
+	enabled bool,
+) *StartGuestSessionUseCase {
+	return &StartGuestSessionUseCase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokenIssuer: tokenIssuer,
+		enabled:     enabled,
+	}
+}
+
+// Execute creates an anonymous account and signs it in. Its tokens carry the
+// guest scope until the account is upgraded.
+func (uc *StartGuestSessionUseCase) Execute(ctx context.Context, client dto.ClientInfo) (_ *dto.AuthTokens, err error) {
+	defer instrument.Observe("auth.start_guest_session", time.Now(), &err)
+
+	if !uc.enabled {
+		return nil, errs.ErrGuestAccessDisabled
+	}
+
+	u, err := uc.userRepo.Create(ctx, &entity.User{IsGuest: true})
+	if err != nil {
+		return nil, err
+	}
+
+	_, tokens, err := startSession(ctx, uc.sessionRepo, uc.tokenIssuer, u, client)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
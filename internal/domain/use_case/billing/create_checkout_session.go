@@ -0,0 +1,85 @@
+package billing
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type CreateCheckoutSessionUseCaseArgs struct {
+	UserRepo         contract.UserRepository
+	SubscriptionRepo contract.SubscriptionRepository
+	Entitlements     contract.EntitlementChecker
+	Provider         contract.BillingProvider
+	// Prices maps the plans for sale to the provider's price IDs.
+	Prices     map[string]string
+	SuccessURL string
+	CancelURL  string
+}
+
+type CreateCheckoutSessionUseCase struct {
+	userRepo         contract.UserRepository
+	subscriptionRepo contract.SubscriptionRepository
+	entitlements     contract.EntitlementChecker
+	provider         contract.BillingProvider
+	prices           map[string]string
+	successURL       string
+	cancelURL        string
+}
+
+func NewCreateCheckoutSessionUseCase(args CreateCheckoutSessionUseCaseArgs) *CreateCheckoutSessionUseCase {
+	return &CreateCheckoutSessionUseCase{
+		userRepo:         args.UserRepo,
+		subscriptionRepo: args.SubscriptionRepo,
+		entitlements:     args.Entitlements,
+		provider:         args.Provider,
+		prices:           args.Prices,
+		successURL:       args.SuccessURL,
+		cancelURL:        args.CancelURL,
+	}
+}
+
+// Execute starts a checkout for plan. Users with an entitling subscription
+// are refused, as a second one would bill them twice.
+func (uc *CreateCheckoutSessionUseCase) Execute(ctx context.Context, userID uuid.UUID, plan string) (_ *dto.CheckoutSession, err error) {
+	defer instrument.Observe("billing.create_checkout_session", time.Now(), &err)
+
+	priceID, ok := uc.prices[plan]
+	if !ok {
+		return nil, errs.ErrUnknownBillingPlan
+	}
+
+	active, err := uc.entitlements.ActivePlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		return nil, errs.ErrAlreadySubscribed
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	input := &dto.CheckoutSessionInput{
+		UserID:     u.ID,
+		Email:      u.Email,
+		PriceID:    priceID,
+		SuccessURL: uc.successURL,
+		CancelURL:  uc.cancelURL,
+	}
+
+	subs, err := uc.subscriptionRepo.ListByUser(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) > 0 {
+		input.CustomerID = subs[0].CustomerID
+	}
+	return uc.provider.CreateCheckoutSession(ctx, input)
+}
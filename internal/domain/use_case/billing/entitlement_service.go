@@ -0,0 +1,42 @@
+package billing
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+)
+
+// EntitlementService answers entitlement questions from the synced
+// subscriptions.
+type EntitlementService struct {
+	subscriptionRepo contract.SubscriptionRepository
+}
+
+var _ contract.EntitlementChecker = (*EntitlementService)(nil)
+
+func NewEntitlementService(subscriptionRepo contract.SubscriptionRepository) *EntitlementService {
+	return &EntitlementService{subscriptionRepo: subscriptionRepo}
+}
+
+func (s *EntitlementService) ActivePlan(ctx context.Context, userID uuid.UUID) (string, error) {
+	subs, err := s.subscriptionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, sub := range subs {
+		if sub.Entitles() {
+			return sub.Plan, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *EntitlementService) HasPlan(ctx context.Context, userID uuid.UUID, plans ...string) (bool, error) {
+	plan, err := s.ActivePlan(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return plan != "" && slices.Contains(plans, plan), nil
+}
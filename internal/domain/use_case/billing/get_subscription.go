@@ -0,0 +1,40 @@
+package billing
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetSubscriptionUseCase struct {
+	subscriptionRepo contract.SubscriptionRepository
+}
+
+func NewGetSubscriptionUseCase(subscriptionRepo contract.SubscriptionRepository) *GetSubscriptionUseCase {
+	return &GetSubscriptionUseCase{subscriptionRepo: subscriptionRepo}
+}
+
+// Execute returns the user's entitling subscription, or else their latest
+// one.
+func (uc *GetSubscriptionUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ *entity.Subscription, err error) {
+	defer instrument.Observe("billing.get_subscription", time.Now(), &err)
+
+	subs, err := uc.subscriptionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, errs.ErrSubscriptionNotFound
+	}
+	for _, s := range subs {
+		if s.Entitles() {
+			return s, nil
+		}
+	}
+	return subs[0], nil
+}
@@ -0,0 +1,105 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/dedupe"
+)
+
+// webhookScope is the dedupe scope of billing events.
+const webhookScope = "billing"
+
+type HandleWebhookUseCase struct {
+	subscriptionRepo contract.SubscriptionRepository
+	provider         contract.BillingProvider
+	deduper          *dedupe.Deduper
+	// plans maps the provider's price IDs back to plan names.
+	plans map[string]string
+}
+
+// NewHandleWebhookUseCase takes the same plan to price ID map as the
+// checkout.
+func NewHandleWebhookUseCase(
+	subscriptionRepo contract.SubscriptionRepository,
+	provider contract.BillingProvider,
+	deduper *dedupe.Deduper,
+	prices map[string]string,
+) *HandleWebhookUseCase {
+	plans := make(map[string]string, len(prices))
+	for plan, priceID := range prices {
+		plans[priceID] = plan
+	}
+	return &HandleWebhookUseCase{subscriptionRepo: subscriptionRepo, provider: provider, deduper: deduper, plans: plans}
+}
+
+// Execute verifies a webhook delivery and syncs the subscription it
+// describes. Other event types are acknowledged and ignored. Deliveries are
+// retried by the provider and may arrive out of order, so an event already
+// applied, by its ID, or older than the last one applied is skipped. A
+// repeat arriving while the first delivery is processed fails with
+// ErrWebhookInProgress.
+func (uc *HandleWebhookUseCase) Execute(ctx context.Context, payload []byte, signature string) (err error) {
+	defer instrument.Observe("billing.handle_webhook", time.Now(), &err)
+
+	event, err := uc.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+	_, err = uc.deduper.Do(ctx, webhookScope, event.ID, func(ctx context.Context) error {
+		return uc.apply(ctx, event)
+	})
+	if errors.Is(err, dedupe.ErrInProgress) {
+		return errs.ErrWebhookInProgress
+	}
+	return err
+}
+
+func (uc *HandleWebhookUseCase) apply(ctx context.Context, event *dto.BillingEvent) error {
+	ps := event.Subscription
+	if ps == nil {
+		return nil
+	}
+
+	current, err := uc.subscriptionRepo.GetByProviderID(ctx, ps.ID)
+	switch {
+	case errors.Is(err, errs.ErrSubscriptionNotFound):
+		current = nil
+	case err != nil:
+		return err
+	}
+	if current != nil && event.CreatedAt.Before(current.SyncedAt) {
+		return nil
+	}
+
+	userID := ps.UserID
+	if current != nil {
+		userID = current.UserID
+	}
+	if userID == uuid.Nil {
+		ctxutil.Logger(ctx).Warnw("subscription without user", "event_id", event.ID, "subscription_id", ps.ID)
+		return nil
+	}
+
+	sub := &entity.Subscription{
+		UserID:            userID,
+		ProviderID:        ps.ID,
+		CustomerID:        ps.CustomerID,
+		Plan:              uc.plans[ps.PriceID],
+		Status:            ps.Status,
+		CurrentPeriodEnd:  ps.CurrentPeriodEnd,
+		CancelAtPeriodEnd: ps.CancelAtPeriodEnd,
+		SyncedAt:          event.CreatedAt,
+		CreatedAt:         event.CreatedAt,
+	}
+	_, err = uc.subscriptionRepo.Upsert(ctx, sub)
+	return err
+}
@@ -0,0 +1,28 @@
+package instrument
+
+import (
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	durationSeconds = metrics.NewHistogram("usecase_duration_seconds",
+		"Use case execution latency.", metrics.DefaultBuckets, "usecase", "outcome")
+	executionsTotal = metrics.NewCounter("usecase_executions_total",
+		"Use case executions by outcome and error.", "usecase", "outcome", "error")
+)
+
+// Observe records one execution of a use case. It is deferred at the top of
+// Execute with the method's named error result:
+//
+//	defer instrument.Observe("auth.sign_in", time.Now(), &err)
+func Observe(usecase string, start time.Time, err *error) {
+	outcome, name := "success", ""
+	if *err != nil {
+		outcome, name = "error", errs.Name(*err)
+	}
+	durationSeconds.Observe(time.Since(start).Seconds(), usecase, outcome)
+	executionsTotal.Inc(usecase, outcome, name)
+}
@@ -0,0 +1,24 @@
+package invitation
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListInvitationsUseCase struct {
+	invitationRepo contract.InvitationRepository
+}
+
+func NewListInvitationsUseCase(invitationRepo contract.InvitationRepository) *ListInvitationsUseCase {
+	return &ListInvitationsUseCase{invitationRepo: invitationRepo}
+}
+
+func (uc *ListInvitationsUseCase) Execute(ctx context.Context) (_ []*entity.Invitation, err error) {
+	defer instrument.Observe("invitation.list_invitations", time.Now(), &err)
+
+	return uc.invitationRepo.List(ctx)
+}
@@ -0,0 +1,53 @@
+package invitation
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type MintInvitationUseCase struct {
+	invitationRepo contract.InvitationRepository
+	defaultTTL     time.Duration
+}
+
+func NewMintInvitationUseCase(invitationRepo contract.InvitationRepository, defaultTTL time.Duration) *MintInvitationUseCase {
+	return &MintInvitationUseCase{invitationRepo: invitationRepo, defaultTTL: defaultTTL}
+}
+
+func (uc *MintInvitationUseCase) Execute(ctx context.Context, input *dto.MintInvitationInput) (_ *dto.MintedInvitation, err error) {
+	defer instrument.Observe("invitation.mint_invitation", time.Now(), &err)
+
+	code, err := securetoken.NewCode(10)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := input.TTL
+	if ttl <= 0 {
+		ttl = uc.defaultTTL
+	}
+	maxUses := input.MaxUses
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+
+	now := time.Now().UTC()
+	inv, err := uc.invitationRepo.Create(ctx, &entity.Invitation{
+		CodeHash:  securetoken.Hash(code),
+		Email:     input.Email,
+		MaxUses:   maxUses,
+		CreatedBy: input.CreatedBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MintedInvitation{Invitation: inv, Code: code}, nil
+}
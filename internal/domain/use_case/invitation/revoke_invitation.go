@@ -0,0 +1,24 @@
+package invitation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RevokeInvitationUseCase struct {
+	invitationRepo contract.InvitationRepository
+}
+
+func NewRevokeInvitationUseCase(invitationRepo contract.InvitationRepository) *RevokeInvitationUseCase {
+	return &RevokeInvitationUseCase{invitationRepo: invitationRepo}
+}
+
+func (uc *RevokeInvitationUseCase) Execute(ctx context.Context, id uuid.UUID) (err error) {
+	defer instrument.Observe("invitation.revoke_invitation", time.Now(), &err)
+
+	return uc.invitationRepo.Revoke(ctx, id, time.Now().UTC())
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type DiscardJobUseCase struct {
+	jobRepo contract.ScheduledJobRepository
+}
+
+func NewDiscardJobUseCase(jobRepo contract.ScheduledJobRepository) *DiscardJobUseCase {
+	return &DiscardJobUseCase{jobRepo: jobRepo}
+}
+
+// Execute gives up on a failed job. It is kept, with its payload and error
+// history, but no longer listed as dead.
+func (uc *DiscardJobUseCase) Execute(ctx context.Context, id uuid.UUID) (_ *entity.ScheduledJob, err error) {
+	defer instrument.Observe("job.discard_job", time.Now(), &err)
+
+	j, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !j.IsDead() {
+		return nil, errs.ErrScheduledJobNotDead
+	}
+
+	now := time.Now().UTC()
+	j.Status = entity.SCHEDULED_JOB_DISCARDED
+	j.UpdatedAt = &now
+	return uc.jobRepo.Update(ctx, j)
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetJobUseCase struct {
+	jobRepo contract.ScheduledJobRepository
+}
+
+func NewGetJobUseCase(jobRepo contract.ScheduledJobRepository) *GetJobUseCase {
+	return &GetJobUseCase{jobRepo: jobRepo}
+}
+
+func (uc *GetJobUseCase) Execute(ctx context.Context, id uuid.UUID) (_ *entity.ScheduledJob, err error) {
+	defer instrument.Observe("job.get_job", time.Now(), &err)
+
+	return uc.jobRepo.GetByID(ctx, id)
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetQueueStatsUseCase struct {
+	jobRepo contract.ScheduledJobRepository
+}
+
+func NewGetQueueStatsUseCase(jobRepo contract.ScheduledJobRepository) *GetQueueStatsUseCase {
+	return &GetQueueStatsUseCase{jobRepo: jobRepo}
+}
+
+// Execute reports the backlog of each kind of job and the rates at which
+// they were processed and failed over the last window. It reads the job
+// store, so every instance reports the same, whichever runs the worker.
+func (uc *GetQueueStatsUseCase) Execute(ctx context.Context, window time.Duration) (_ *dto.JobQueueStats, err error) {
+	defer instrument.Observe("job.get_queue_stats", time.Now(), &err)
+
+	now := time.Now().UTC()
+	kinds, err := uc.jobRepo.Stats(ctx, now.Add(-window), now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range kinds {
+		k := &kinds[i]
+		k.DonePerMinute = float64(k.Done) / window.Minutes()
+		if finished := k.Done + k.Failed; finished > 0 {
+			k.FailureRatio = float64(k.Failed) / float64(finished)
+		}
+	}
+	return &dto.JobQueueStats{Window: window.String(), GeneratedAt: now, Kinds: kinds}, nil
+}
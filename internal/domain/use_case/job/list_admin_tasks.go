@@ -0,0 +1,18 @@
+package job
+
+import (
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type ListAdminTasksUseCase struct {
+	registry contract.AdminTaskRegistry
+}
+
+func NewListAdminTasksUseCase(registry contract.AdminTaskRegistry) *ListAdminTasksUseCase {
+	return &ListAdminTasksUseCase{registry: registry}
+}
+
+func (uc *ListAdminTasksUseCase) Execute() []dto.AdminTask {
+	return uc.registry.AdminTasks()
+}
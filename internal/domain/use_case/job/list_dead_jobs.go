@@ -0,0 +1,31 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListDeadJobsUseCase struct {
+	jobRepo contract.ScheduledJobRepository
+}
+
+func NewListDeadJobsUseCase(jobRepo contract.ScheduledJobRepository) *ListDeadJobsUseCase {
+	return &ListDeadJobsUseCase{jobRepo: jobRepo}
+}
+
+// Execute lists the jobs that failed for good, the most recent failure
+// first; an empty kind lists every kind.
+func (uc *ListDeadJobsUseCase) Execute(ctx context.Context, kind string, limit, offset int) (_ *dto.Page[*entity.ScheduledJob], err error) {
+	defer instrument.Observe("job.list_dead_jobs", time.Now(), &err)
+
+	items, total, err := uc.jobRepo.List(ctx, entity.SCHEDULED_JOB_FAILED, kind, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Page[*entity.ScheduledJob]{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
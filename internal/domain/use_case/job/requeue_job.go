@@ -0,0 +1,60 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RequeueJobUseCase struct {
+	jobRepo contract.ScheduledJobRepository
+	// wake nudges the job worker so the job runs now rather than on its
+	// next poll.
+	wake func()
+}
+
+func NewRequeueJobUseCase(jobRepo contract.ScheduledJobRepository, wake func()) *RequeueJobUseCase {
+	return &RequeueJobUseCase{jobRepo: jobRepo, wake: wake}
+}
+
+// Execute makes a failed job due now with a fresh set of attempts, for
+// instance once the bug or outage that failed it is fixed. Its error
+// history is kept.
+func (uc *RequeueJobUseCase) Execute(ctx context.Context, id uuid.UUID) (_ *entity.ScheduledJob, err error) {
+	defer instrument.Observe("job.requeue_job", time.Now(), &err)
+
+	j, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !j.IsDead() {
+		return nil, errs.ErrScheduledJobNotDead
+	}
+	if j.Key != "" {
+		_, err := uc.jobRepo.GetPendingByKey(ctx, j.Key)
+		switch {
+		case err == nil:
+			return nil, errs.ErrScheduledJobSuperseded
+		case !errors.Is(err, errs.ErrScheduledJobNotFound):
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	j.Status = entity.SCHEDULED_JOB_PENDING
+	j.Attempts = 0
+	j.RunAt = now
+	j.UpdatedAt = &now
+	updated, err := uc.jobRepo.Update(ctx, j)
+	if err != nil {
+		return nil, err
+	}
+	uc.wake()
+	return updated, nil
+}
@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type TriggerAdminTaskUseCase struct {
+	registry     contract.AdminTaskRegistry
+	jobRepo      contract.ScheduledJobRepository
+	auditLogRepo contract.AuditLogRepository
+	// wake nudges the job worker so the task runs now rather than on its
+	// next poll.
+	wake func()
+}
+
+func NewTriggerAdminTaskUseCase(
+	registry contract.AdminTaskRegistry,
+	jobRepo contract.ScheduledJobRepository,
+	auditLogRepo contract.AuditLogRepository,
+	wake func(),
+) *TriggerAdminTaskUseCase {
+	return &TriggerAdminTaskUseCase{registry: registry, jobRepo: jobRepo, auditLogRepo: auditLogRepo, wake: wake}
+}
+
+// Execute schedules a run of the task due now and returns its job, which
+// GET /admin/jobs/{id} follows. Each trigger is a run of its own, even
+// while an earlier one is pending.
+func (uc *TriggerAdminTaskUseCase) Execute(ctx context.Context, input *dto.TriggerAdminTaskInput) (_ *entity.ScheduledJob, err error) {
+	defer instrument.Observe("job.trigger_admin_task", time.Now(), &err)
+
+	if err := uc.registry.ValidateAdminTask(input.Name, input.Params); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	j, err := uc.jobRepo.Schedule(ctx, &entity.ScheduledJob{
+		Kind:      input.Name,
+		Payload:   input.Params,
+		Status:    entity.SCHEDULED_JOB_PENDING,
+		RunAt:     now,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := input.Name
+	if len(input.Params) > 0 {
+		detail += " " + string(input.Params)
+	}
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_ADMIN_TASK_TRIGGERED,
+		ActorID:   input.ActorID,
+		SubjectID: j.ID,
+		Detail:    detail,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.wake()
+	return j, nil
+}
@@ -0,0 +1,138 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/safe"
+)
+
+var deliveries = metrics.NewCounter("mail_queue_deliveries_total",
+	"Delivery attempts of queued email by result.", "result")
+
+type DeliverQueuedEmailsUseCaseArgs struct {
+	EmailQueueRepo contract.EmailQueueRepository
+	// SuppressionRepo is checked again before each attempt, as an address
+	// may be suppressed while its mail waits.
+	SuppressionRepo contract.EmailSuppressionRepository
+	Transport       contract.MailTransport
+	BatchSize       int
+	// MaxAttempts is the number of attempts before a message is marked
+	// failed.
+	MaxAttempts int
+	// RetryBase is the delay before the second attempt; it doubles with each
+	// attempt after that, up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+}
+
+// DeliverQueuedEmailsUseCase sends the queued email that is due. It is run
+// by a single worker, the leader, so messages need no claiming.
+type DeliverQueuedEmailsUseCase struct {
+	emailQueueRepo  contract.EmailQueueRepository
+	suppressionRepo contract.EmailSuppressionRepository
+	transport       contract.MailTransport
+	batchSize       int
+	maxAttempts     int
+	retryBase       time.Duration
+	retryMax        time.Duration
+}
+
+func NewDeliverQueuedEmailsUseCase(args DeliverQueuedEmailsUseCaseArgs) *DeliverQueuedEmailsUseCase {
+	return &DeliverQueuedEmailsUseCase{
+		emailQueueRepo:  args.EmailQueueRepo,
+		suppressionRepo: args.SuppressionRepo,
+		transport:       args.Transport,
+		batchSize:       args.BatchSize,
+		maxAttempts:     args.MaxAttempts,
+		retryBase:       args.RetryBase,
+		retryMax:        args.RetryMax,
+	}
+}
+
+// Execute attempts one batch of due messages and returns how many it
+// attempted, so the caller can loop while there is a backlog. A transport
+// error reschedules the message rather than failing the batch; a message
+// that makes the transport panic fails at once, as a retry would too.
+func (uc *DeliverQueuedEmailsUseCase) Execute(ctx context.Context) (_ int, err error) {
+	defer instrument.Observe("mail.deliver_queued_emails", time.Now(), &err)
+
+	due, err := uc.emailQueueRepo.Due(ctx, time.Now().UTC(), uc.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range due {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err := uc.deliver(ctx, e); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+func (uc *DeliverQueuedEmailsUseCase) deliver(ctx context.Context, e *entity.OutboundEmail) error {
+	suppression, err := uc.suppressionRepo.Get(ctx, entity.NormalizeSuppressedEmail(e.To))
+	if err != nil && !errors.Is(err, errs.ErrEmailSuppressionNotFound) {
+		return err
+	}
+	if suppression != nil {
+		now := time.Now().UTC()
+		e.Status = entity.OUTBOUND_EMAIL_SUPPRESSED
+		e.LastError = "recipient is suppressed: " + suppression.Reason
+		e.UpdatedAt = &now
+		deliveries.Inc("suppressed")
+		_, err := uc.emailQueueRepo.Update(ctx, e)
+		return err
+	}
+
+	sendErr := safe.Call(ctx, "mail.transport", func() error {
+		return uc.transport.Send(ctx, dto.EmailMessage{ID: e.ID, To: e.To, Subject: e.Subject, Body: e.Body})
+	}, "email_id", e.ID)
+
+	now := time.Now().UTC()
+	e.Attempts++
+	e.UpdatedAt = &now
+	switch {
+	case sendErr == nil:
+		e.Status = entity.OUTBOUND_EMAIL_SENT
+		e.SentAt = &now
+		e.LastError = ""
+		e.Feedback = ""
+		deliveries.Inc("sent")
+	case safe.IsPanic(sendErr):
+		e.Status = entity.OUTBOUND_EMAIL_FAILED
+		e.LastError = sendErr.Error()
+		deliveries.Inc("quarantined")
+	case e.Attempts >= uc.maxAttempts:
+		e.Status = entity.OUTBOUND_EMAIL_FAILED
+		e.LastError = sendErr.Error()
+		deliveries.Inc("failed")
+		ctxutil.Logger(ctx).Warnw("email delivery failed for good",
+			"email_id", e.ID, "attempts", e.Attempts, "error", sendErr)
+	default:
+		e.NextAttemptAt = now.Add(uc.retryDelay(e.Attempts))
+		e.LastError = sendErr.Error()
+		deliveries.Inc("retry")
+	}
+	_, err = uc.emailQueueRepo.Update(ctx, e)
+	return err
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func (uc *DeliverQueuedEmailsUseCase) retryDelay(attempts int) time.Duration {
+	delay := uc.retryBase
+	for i := 1; i < attempts && delay < uc.retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, uc.retryMax)
+}
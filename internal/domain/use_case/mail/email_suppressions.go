@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListEmailSuppressionsUseCase struct {
+	suppressionRepo contract.EmailSuppressionRepository
+}
+
+func NewListEmailSuppressionsUseCase(suppressionRepo contract.EmailSuppressionRepository) *ListEmailSuppressionsUseCase {
+	return &ListEmailSuppressionsUseCase{suppressionRepo: suppressionRepo}
+}
+
+// Execute lists the suppressed addresses, newest first; an empty reason
+// lists them all.
+func (uc *ListEmailSuppressionsUseCase) Execute(ctx context.Context, reason string, limit, offset int) (_ *dto.Page[*entity.EmailSuppression], err error) {
+	defer instrument.Observe("mail.list_email_suppressions", time.Now(), &err)
+
+	items, total, err := uc.suppressionRepo.List(ctx, reason, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Page[*entity.EmailSuppression]{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+type AddEmailSuppressionUseCase struct {
+	suppressionRepo contract.EmailSuppressionRepository
+	auditLogRepo    contract.AuditLogRepository
+}
+
+func NewAddEmailSuppressionUseCase(suppressionRepo contract.EmailSuppressionRepository, auditLogRepo contract.AuditLogRepository) *AddEmailSuppressionUseCase {
+	return &AddEmailSuppressionUseCase{suppressionRepo: suppressionRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute suppresses email for reason, EMAIL_SUPPRESSION_MANUAL when empty,
+// and reports whether it was not suppressed yet. An address already
+// suppressed keeps its entry.
+func (uc *AddEmailSuppressionUseCase) Execute(ctx context.Context, actorID uuid.UUID, email, reason, detail string) (_ *entity.EmailSuppression, added bool, err error) {
+	defer instrument.Observe("mail.add_email_suppression", time.Now(), &err)
+
+	if reason == "" {
+		reason = entity.EMAIL_SUPPRESSION_MANUAL
+	}
+	now := time.Now().UTC()
+	s, added, err := uc.suppressionRepo.Add(ctx, &entity.EmailSuppression{
+		Email:     entity.NormalizeSuppressedEmail(email),
+		Reason:    reason,
+		Detail:    detail,
+		CreatedBy: &actorID,
+		CreatedAt: now,
+	})
+	if err != nil || !added {
+		return s, false, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_EMAIL_SUPPRESSION_ADDED,
+		ActorID:   actorID,
+		Detail:    s.Email + " (" + s.Reason + ")",
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return s, true, nil
+}
+
+type RemoveEmailSuppressionUseCase struct {
+	suppressionRepo contract.EmailSuppressionRepository
+	auditLogRepo    contract.AuditLogRepository
+}
+
+func NewRemoveEmailSuppressionUseCase(suppressionRepo contract.EmailSuppressionRepository, auditLogRepo contract.AuditLogRepository) *RemoveEmailSuppressionUseCase {
+	return &RemoveEmailSuppressionUseCase{suppressionRepo: suppressionRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute takes email off the suppression list, so mail is sent to it
+// again, e.g. after its owner fixed their mailbox. It fails with
+// ErrEmailSuppressionNotFound when the address is not suppressed.
+func (uc *RemoveEmailSuppressionUseCase) Execute(ctx context.Context, actorID uuid.UUID, email string) (err error) {
+	defer instrument.Observe("mail.remove_email_suppression", time.Now(), &err)
+
+	s, err := uc.suppressionRepo.Remove(ctx, entity.NormalizeSuppressedEmail(email))
+	if err != nil {
+		return err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_EMAIL_SUPPRESSION_REMOVED,
+		ActorID:   actorID,
+		Detail:    s.Email + " (" + s.Reason + ")",
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
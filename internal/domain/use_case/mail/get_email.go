@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetEmailUseCase struct {
+	emailQueueRepo contract.EmailQueueRepository
+}
+
+func NewGetEmailUseCase(emailQueueRepo contract.EmailQueueRepository) *GetEmailUseCase {
+	return &GetEmailUseCase{emailQueueRepo: emailQueueRepo}
+}
+
+func (uc *GetEmailUseCase) Execute(ctx context.Context, id uuid.UUID) (_ *entity.OutboundEmail, err error) {
+	defer instrument.Observe("mail.get_email", time.Now(), &err)
+
+	return uc.emailQueueRepo.GetByID(ctx, id)
+}
@@ -0,0 +1,151 @@
+package mail
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/dedupe"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var feedbackEvents = metrics.NewCounter("mail_feedback_events_total",
+	"Bounce, complaint and unsubscribe webhook events by type.", "type")
+
+// feedbackScope is the dedupe scope of mail feedback deliveries.
+const feedbackScope = "mail_feedback"
+
+type HandleFeedbackWebhookUseCase struct {
+	emailQueueRepo  contract.EmailQueueRepository
+	suppressionRepo contract.EmailSuppressionRepository
+	deduper         *dedupe.Deduper
+	secret          []byte
+}
+
+// NewHandleFeedbackWebhookUseCase verifies deliveries with secret, shared
+// with the email provider.
+func NewHandleFeedbackWebhookUseCase(
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	deduper *dedupe.Deduper,
+	secret string,
+) *HandleFeedbackWebhookUseCase {
+	return &HandleFeedbackWebhookUseCase{
+		emailQueueRepo:  emailQueueRepo,
+		suppressionRepo: suppressionRepo,
+		deduper:         deduper,
+		secret:          []byte(secret),
+	}
+}
+
+// Execute checks that signature is the hex HMAC-SHA256 of payload, a JSON
+// array of MailFeedbackEvents, records each bounce or complaint on its
+// message, and suppresses the recipients of hard bounces, complaints and
+// unsubscribes. Events for unknown messages and other event types are
+// acknowledged and skipped, so the provider does not redeliver them.
+//
+// Deliveries carry no ID, so they are deduplicated by the MAC of the
+// payload, which a retry repeats byte for byte.
+func (uc *HandleFeedbackWebhookUseCase) Execute(ctx context.Context, payload []byte, signature string) (err error) {
+	defer instrument.Observe("mail.handle_feedback_webhook", time.Now(), &err)
+
+	mac := hmac.New(sha256.New, uc.secret)
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, sum) {
+		return errs.ErrInvalidWebhookSignature
+	}
+
+	var events []dto.MailFeedbackEvent
+	if err := json.Unmarshal(payload, &events); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrInvalidWebhookPayload, err)
+	}
+	_, err = uc.deduper.Do(ctx, feedbackScope, hex.EncodeToString(sum), func(ctx context.Context) error {
+		for _, ev := range events {
+			if err := uc.apply(ctx, ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, dedupe.ErrInProgress) {
+		return errs.ErrWebhookInProgress
+	}
+	return err
+}
+
+func (uc *HandleFeedbackWebhookUseCase) apply(ctx context.Context, ev dto.MailFeedbackEvent) error {
+	var status string
+	switch ev.Type {
+	case dto.MAIL_FEEDBACK_BOUNCE:
+		status = entity.OUTBOUND_EMAIL_BOUNCED
+	case dto.MAIL_FEEDBACK_COMPLAINT:
+		status = entity.OUTBOUND_EMAIL_COMPLAINED
+	case dto.MAIL_FEEDBACK_UNSUBSCRIBE:
+	default:
+		return nil
+	}
+	feedbackEvents.Inc(ev.Type)
+
+	recipient := ev.Recipient
+	if status != "" || ev.MessageID != uuid.Nil {
+		e, err := uc.emailQueueRepo.GetByID(ctx, ev.MessageID)
+		if errors.Is(err, errs.ErrOutboundEmailNotFound) {
+			ctxutil.Logger(ctx).Warnw("mail feedback for unknown message", "email_id", ev.MessageID, "type", ev.Type)
+		} else if err != nil {
+			return err
+		} else {
+			if recipient == "" {
+				recipient = e.To
+			}
+			if err := uc.record(ctx, e, status, ev.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	return uc.suppress(ctx, ev, recipient)
+}
+
+// record sets the status of the message the feedback is about; a
+// complaint outranks an earlier bounce report, never the reverse.
+func (uc *HandleFeedbackWebhookUseCase) record(ctx context.Context, e *entity.OutboundEmail, status, reason string) error {
+	if status == "" || e.Status == entity.OUTBOUND_EMAIL_COMPLAINED {
+		return nil
+	}
+	now := time.Now().UTC()
+	e.Status = status
+	e.Feedback = reason
+	e.UpdatedAt = &now
+	_, err := uc.emailQueueRepo.Update(ctx, e)
+	return err
+}
+
+// suppress adds the recipient of the feedback to the suppression list,
+// unless it is already on it or the feedback does not call for it.
+func (uc *HandleFeedbackWebhookUseCase) suppress(ctx context.Context, ev dto.MailFeedbackEvent, recipient string) error {
+	reason := ev.SuppressionReason()
+	recipient = entity.NormalizeSuppressedEmail(recipient)
+	if reason == "" || recipient == "" {
+		return nil
+	}
+	_, _, err := uc.suppressionRepo.Add(ctx, &entity.EmailSuppression{
+		Email:     recipient,
+		Reason:    reason,
+		Detail:    ev.Reason,
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
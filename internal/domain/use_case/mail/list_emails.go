@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListEmailsUseCase struct {
+	emailQueueRepo contract.EmailQueueRepository
+}
+
+func NewListEmailsUseCase(emailQueueRepo contract.EmailQueueRepository) *ListEmailsUseCase {
+	return &ListEmailsUseCase{emailQueueRepo: emailQueueRepo}
+}
+
+// Execute lists queued and sent email, newest first; an empty status lists
+// every message.
+func (uc *ListEmailsUseCase) Execute(ctx context.Context, status string, limit, offset int) (_ *dto.Page[*entity.OutboundEmail], err error) {
+	defer instrument.Observe("mail.list_emails", time.Now(), &err)
+
+	items, total, err := uc.emailQueueRepo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Page[*entity.OutboundEmail]{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
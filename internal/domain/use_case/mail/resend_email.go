@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ResendEmailUseCase struct {
+	emailQueueRepo contract.EmailQueueRepository
+	// wake nudges the delivery worker so the message goes out now rather
+	// than on its next poll.
+	wake func()
+}
+
+func NewResendEmailUseCase(emailQueueRepo contract.EmailQueueRepository, wake func()) *ResendEmailUseCase {
+	return &ResendEmailUseCase{emailQueueRepo: emailQueueRepo, wake: wake}
+}
+
+// Execute queues a failed, bounced or suppressed message again with a fresh
+// set of attempts. Its feedback and last error are kept until the next
+// attempt, which is suppressed again while the recipient is still listed.
+func (uc *ResendEmailUseCase) Execute(ctx context.Context, id uuid.UUID) (_ *entity.OutboundEmail, err error) {
+	defer instrument.Observe("mail.resend_email", time.Now(), &err)
+
+	e, err := uc.emailQueueRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsResendable() {
+		return nil, errs.ErrEmailNotResendable
+	}
+
+	now := time.Now().UTC()
+	e.Status = entity.OUTBOUND_EMAIL_QUEUED
+	e.Attempts = 0
+	e.NextAttemptAt = now
+	e.UpdatedAt = &now
+	updated, err := uc.emailQueueRepo.Update(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	uc.wake()
+	return updated, nil
+}
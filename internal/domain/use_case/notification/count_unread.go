@@ -0,0 +1,24 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type CountUnreadUseCase struct {
+	inboxRepo contract.InAppNotificationRepository
+}
+
+func NewCountUnreadUseCase(inboxRepo contract.InAppNotificationRepository) *CountUnreadUseCase {
+	return &CountUnreadUseCase{inboxRepo: inboxRepo}
+}
+
+func (uc *CountUnreadUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ int, err error) {
+	defer instrument.Observe("notification.count_unread", time.Now(), &err)
+
+	return uc.inboxRepo.CountUnread(ctx, userID)
+}
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+const (
+	deliverBatchSize = 500
+	// deliverRetryDelay is how long notifications wait after the mailer
+	// failed to take them.
+	deliverRetryDelay = 5 * time.Minute
+)
+
+// DeliverPendingNotificationsUseCase sends the held notifications that are
+// due: digest notifications as one digest email per recipient, the rest one
+// by one. It is run by a single worker, the leader.
+type DeliverPendingNotificationsUseCase struct {
+	pendingRepo contract.PendingNotificationRepository
+	mailer      contract.Mailer
+}
+
+func NewDeliverPendingNotificationsUseCase(pendingRepo contract.PendingNotificationRepository, mailer contract.Mailer) *DeliverPendingNotificationsUseCase {
+	return &DeliverPendingNotificationsUseCase{pendingRepo: pendingRepo, mailer: mailer}
+}
+
+type digestKey struct {
+	userID uuid.UUID
+	to     string
+}
+
+// Execute sends one batch of due notifications and returns how many it
+// took. Notifications the mailer rejects are held again for a retry.
+func (uc *DeliverPendingNotificationsUseCase) Execute(ctx context.Context) (_ int, err error) {
+	defer instrument.Observe("notification.deliver_pending", time.Now(), &err)
+
+	due, err := uc.pendingRepo.TakeDue(ctx, time.Now().UTC(), deliverBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errList []error
+	var order []digestKey
+	digests := make(map[digestKey][]*entity.PendingNotification)
+	for _, n := range due {
+		if !n.Digest {
+			errList = append(errList, uc.send(ctx, []*entity.PendingNotification{n}))
+			continue
+		}
+		key := digestKey{userID: n.UserID, to: n.To}
+		if _, ok := digests[key]; !ok {
+			order = append(order, key)
+		}
+		digests[key] = append(digests[key], n)
+	}
+	for _, key := range order {
+		errList = append(errList, uc.send(ctx, digests[key]))
+	}
+	return len(due), errors.Join(errList...)
+}
+
+// send mails a single notification as is and several as a digest, in the
+// locale of the newest one.
+func (uc *DeliverPendingNotificationsUseCase) send(ctx context.Context, group []*entity.PendingNotification) error {
+	last := group[len(group)-1]
+	ctx = ctxutil.WithLocale(ctx, last.Locale)
+
+	email := dto.Email{To: last.To, Template: last.Template, Data: last.Data}
+	if len(group) > 1 {
+		email = dto.Email{To: last.To, Template: dto.EMAIL_DIGEST}
+		for _, n := range group {
+			email.Items = append(email.Items, dto.Email{Template: n.Template, Data: n.Data})
+		}
+	}
+	if err := uc.mailer.Send(ctx, email); err != nil {
+		retryAt := time.Now().UTC().Add(deliverRetryDelay)
+		for _, n := range group {
+			n.DueAt = retryAt
+			if addErr := uc.pendingRepo.Add(ctx, n); addErr != nil {
+				ctxutil.Logger(ctx).Warnw("hold notification for retry", "user_id", n.UserID, "error", addErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetPreferencesUseCase struct {
+	prefsRepo contract.NotificationPreferencesRepository
+}
+
+func NewGetPreferencesUseCase(prefsRepo contract.NotificationPreferencesRepository) *GetPreferencesUseCase {
+	return &GetPreferencesUseCase{prefsRepo: prefsRepo}
+}
+
+// Execute returns the user's preferences, or the defaults when they never
+// saved any.
+func (uc *GetPreferencesUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ *entity.NotificationPreferences, err error) {
+	defer instrument.Observe("notification.get_preferences", time.Now(), &err)
+
+	prefs, err := uc.prefsRepo.Get(ctx, userID)
+	if errors.Is(err, errs.ErrNotificationPreferencesNotFound) {
+		return entity.DefaultNotificationPreferences(userID), nil
+	}
+	return prefs, err
+}
@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListNotificationsUseCase struct {
+	inboxRepo contract.InAppNotificationRepository
+}
+
+func NewListNotificationsUseCase(inboxRepo contract.InAppNotificationRepository) *ListNotificationsUseCase {
+	return &ListNotificationsUseCase{inboxRepo: inboxRepo}
+}
+
+// Execute returns a page of the user's inbox, newest first, with the
+// unread count for the badge.
+func (uc *ListNotificationsUseCase) Execute(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (_ *dto.InboxPage, err error) {
+	defer instrument.Observe("notification.list_notifications", time.Now(), &err)
+
+	items, total, err := uc.inboxRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := uc.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	page := &dto.InboxPage{Unread: unread}
+	page.Items, page.Total, page.Limit, page.Offset = items, total, limit, offset
+	return page, nil
+}
@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type MarkAllReadUseCase struct {
+	inboxRepo contract.InAppNotificationRepository
+	badges    contract.BadgePublisher
+}
+
+func NewMarkAllReadUseCase(inboxRepo contract.InAppNotificationRepository, badges contract.BadgePublisher) *MarkAllReadUseCase {
+	return &MarkAllReadUseCase{inboxRepo: inboxRepo, badges: badges}
+}
+
+// Execute marks the user's whole inbox read and returns how many
+// notifications were unread.
+func (uc *MarkAllReadUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ int, err error) {
+	defer instrument.Observe("notification.mark_all_read", time.Now(), &err)
+
+	n, err := uc.inboxRepo.MarkAllRead(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		publishUnread(ctx, uc.inboxRepo, uc.badges, userID)
+	}
+	return n, nil
+}
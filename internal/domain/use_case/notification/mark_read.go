@@ -0,0 +1,32 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type MarkReadUseCase struct {
+	inboxRepo contract.InAppNotificationRepository
+	badges    contract.BadgePublisher
+}
+
+func NewMarkReadUseCase(inboxRepo contract.InAppNotificationRepository, badges contract.BadgePublisher) *MarkReadUseCase {
+	return &MarkReadUseCase{inboxRepo: inboxRepo, badges: badges}
+}
+
+// Execute marks one of the user's notifications read and pushes the new
+// unread count to their other clients. Notifications of other users are
+// reported as not found.
+func (uc *MarkReadUseCase) Execute(ctx context.Context, userID, id uuid.UUID) (err error) {
+	defer instrument.Observe("notification.mark_read", time.Now(), &err)
+
+	if err := uc.inboxRepo.MarkRead(ctx, userID, id, time.Now().UTC()); err != nil {
+		return err
+	}
+	publishUnread(ctx, uc.inboxRepo, uc.badges, userID)
+	return nil
+}
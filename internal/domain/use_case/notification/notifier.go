@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var notificationsTotal = metrics.NewCounter("notifications_total",
+	"Notifications by category, channel and outcome (sent, held, suppressed).", "category", "channel", "outcome")
+
+// Notifier is the notification fan-out: it looks up the user's preferences
+// and sends, holds or drops each channel of a notification accordingly.
+type NotifierArgs struct {
+	PrefsRepo   contract.NotificationPreferencesRepository
+	PendingRepo contract.PendingNotificationRepository
+	InboxRepo   contract.InAppNotificationRepository
+	Mailer      contract.Mailer
+	SMSSender   contract.SMSSender
+	Badges      contract.BadgePublisher
+}
+
+type Notifier struct {
+	prefsRepo   contract.NotificationPreferencesRepository
+	pendingRepo contract.PendingNotificationRepository
+	inboxRepo   contract.InAppNotificationRepository
+	mailer      contract.Mailer
+	smsSender   contract.SMSSender
+	badges      contract.BadgePublisher
+}
+
+var _ contract.Notifier = (*Notifier)(nil)
+
+func NewNotifier(args NotifierArgs) *Notifier {
+	return &Notifier{
+		prefsRepo:   args.PrefsRepo,
+		pendingRepo: args.PendingRepo,
+		inboxRepo:   args.InboxRepo,
+		mailer:      args.Mailer,
+		smsSender:   args.SMSSender,
+		badges:      args.Badges,
+	}
+}
+
+// Notify delivers n by email, SMS and to the in-app inbox as the user's
+// preferences allow. Email held by quiet hours or a digest is stored and
+// sent by DeliverPendingNotificationsUseCase; SMS is sent right away or not
+// at all. Security notifications always go out at once. The inbox is
+// silent, so it gets every notification right away.
+func (uc *Notifier) Notify(ctx context.Context, n dto.Notification) (err error) {
+	defer instrument.Observe("notification.notify", time.Now(), &err)
+
+	prefs, err := uc.prefsRepo.Get(ctx, n.UserID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotificationPreferencesNotFound) {
+			// Sending with the defaults beats dropping a notification the
+			// user may not have opted out of.
+			ctxutil.Logger(ctx).Warnw("load notification preferences", "user_id", n.UserID, "error", err)
+		}
+		prefs = entity.DefaultNotificationPreferences(n.UserID)
+	}
+	if !prefs.CategoryEnabled(n.Category) {
+		notificationsTotal.Inc(n.Category, "", "suppressed")
+		return nil
+	}
+
+	now := time.Now()
+	urgent := n.Category == entity.NOTIFY_CATEGORY_SECURITY
+	var errList []error
+
+	if n.Email.To != "" && prefs.ChannelEnabled(entity.NOTIFY_CHANNEL_EMAIL) {
+		if due, held := prefs.HoldUntil(now); held && !urgent {
+			err := uc.pendingRepo.Add(ctx, &entity.PendingNotification{
+				UserID:    n.UserID,
+				To:        n.Email.To,
+				Template:  n.Email.Template,
+				Data:      n.Email.Data,
+				Locale:    ctxutil.Locale(ctx),
+				Digest:    prefs.Digest != entity.DIGEST_OFF,
+				DueAt:     due.UTC(),
+				CreatedAt: now.UTC(),
+			})
+			errList = append(errList, err)
+			notificationsTotal.Inc(n.Category, entity.NOTIFY_CHANNEL_EMAIL, "held")
+		} else {
+			errList = append(errList, uc.mailer.Send(ctx, n.Email))
+			notificationsTotal.Inc(n.Category, entity.NOTIFY_CHANNEL_EMAIL, "sent")
+		}
+	}
+
+	if n.Phone != "" && n.Summary != "" && prefs.ChannelEnabled(entity.NOTIFY_CHANNEL_SMS) {
+		if urgent || !prefs.InQuietHours(now) {
+			errList = append(errList, uc.smsSender.Send(ctx, dto.SMSMessage{To: n.Phone, Body: n.Summary}))
+			notificationsTotal.Inc(n.Category, entity.NOTIFY_CHANNEL_SMS, "sent")
+		} else {
+			notificationsTotal.Inc(n.Category, entity.NOTIFY_CHANNEL_SMS, "suppressed")
+		}
+	}
+
+	if n.Summary != "" && prefs.ChannelEnabled(entity.NOTIFY_CHANNEL_IN_APP) {
+		errList = append(errList, uc.addToInbox(ctx, n, now))
+		notificationsTotal.Inc(n.Category, entity.NOTIFY_CHANNEL_IN_APP, "sent")
+	}
+	return errors.Join(errList...)
+}
+
+func (uc *Notifier) addToInbox(ctx context.Context, n dto.Notification, now time.Time) error {
+	_, err := uc.inboxRepo.Create(ctx, &entity.InAppNotification{
+		UserID:    n.UserID,
+		Category:  n.Category,
+		Kind:      n.Email.Template,
+		Text:      n.Summary,
+		CreatedAt: now.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	publishUnread(ctx, uc.inboxRepo, uc.badges, n.UserID)
+	return nil
+}
+
+// publishUnread pushes the user's current unread count. Clients refetch it
+// on reconnect, so a failed count is only logged.
+func publishUnread(ctx context.Context, inboxRepo contract.InAppNotificationRepository, badges contract.BadgePublisher, userID uuid.UUID) {
+	unread, err := inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		ctxutil.Logger(ctx).Warnw("count unread notifications", "user_id", userID, "error", err)
+		return
+	}
+	badges.PublishUnread(ctx, userID, unread)
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type UpdatePreferencesUseCase struct {
+	prefsRepo contract.NotificationPreferencesRepository
+	userRepo  contract.UserRepository
+}
+
+func NewUpdatePreferencesUseCase(prefsRepo contract.NotificationPreferencesRepository, userRepo contract.UserRepository) *UpdatePreferencesUseCase {
+	return &UpdatePreferencesUseCase{prefsRepo: prefsRepo, userRepo: userRepo}
+}
+
+// Execute replaces the user's preferences. Channels and categories left out
+// of the input keep their defaults. Notifications already held keep the
+// delivery time they were given.
+func (uc *UpdatePreferencesUseCase) Execute(ctx context.Context, input *dto.UpdateNotificationPreferencesInput) (_ *entity.NotificationPreferences, err error) {
+	defer instrument.Observe("notification.update_preferences", time.Now(), &err)
+
+	if on, ok := input.Categories[entity.NOTIFY_CATEGORY_SECURITY]; ok && !on {
+		return nil, errs.ErrSecurityNotificationsRequired
+	}
+	if (input.QuietHoursStart == "") != (input.QuietHoursEnd == "") {
+		return nil, errs.ErrQuietHoursIncomplete
+	}
+	if input.Channels[entity.NOTIFY_CHANNEL_SMS] {
+		u, err := uc.userRepo.GetByID(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !u.HasVerifiedPhone() {
+			return nil, errs.ErrNotificationSMSUnavailable
+		}
+	}
+
+	prefs := entity.DefaultNotificationPreferences(input.UserID)
+	for channel, on := range input.Channels {
+		prefs.Channels[channel] = on
+	}
+	for category, on := range input.Categories {
+		prefs.Categories[category] = on
+	}
+	if input.QuietHoursStart != "" {
+		prefs.QuietHours = &entity.QuietHours{Start: input.QuietHoursStart, End: input.QuietHoursEnd}
+	}
+	prefs.TimeZone = input.TimeZone
+	if input.Digest != "" {
+		prefs.Digest = input.Digest
+	}
+	now := time.Now().UTC()
+	prefs.UpdatedAt = &now
+	return uc.prefsRepo.Upsert(ctx, prefs)
+}
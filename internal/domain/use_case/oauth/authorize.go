@@ -0,0 +1,136 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type AuthorizeUseCase struct {
+	clientRepo  contract.OAuthClientRepository
+	codeRepo    contract.AuthorizationCodeRepository
+	sessionRepo contract.SessionRepository
+	codeTTL     time.Duration
+}
+
+func NewAuthorizeUseCase(
+	clientRepo contract.OAuthClientRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	sessionRepo contract.SessionRepository,
+	codeTTL time.Duration,
+) *AuthorizeUseCase {
+	return &AuthorizeUseCase{clientRepo: clientRepo, codeRepo: codeRepo, sessionRepo: sessionRepo, codeTTL: codeTTL}
+}
+
+// Execute issues an authorization code for the signed-in user. Unknown
+// clients and unregistered redirect URIs fail with an error since the user
+// must not be sent there; every other problem is reported to the relying
+// party through its redirect URI, as OpenID Connect Core 3.1.2.6 requires.
+func (uc *AuthorizeUseCase) Execute(ctx context.Context, input *dto.AuthorizeInput) (_ *dto.AuthorizeResult, err error) {
+	defer instrument.Observe("oauth.authorize", time.Now(), &err)
+
+	client, err := uc.clientRepo.GetByClientID(ctx, input.ClientID)
+	if errors.Is(err, errs.ErrOAuthClientNotFound) {
+		return nil, errs.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive() {
+		return nil, errs.ErrInvalidClient
+	}
+	redirect, err := url.Parse(input.RedirectURI)
+	if err != nil || !client.AllowsRedirectURI(input.RedirectURI) {
+		return nil, errs.ErrInvalidRedirectURI
+	}
+
+	fail := func(code, description string) (*dto.AuthorizeResult, error) {
+		return redirectWith(redirect, map[string]string{
+			"error":             code,
+			"error_description": description,
+			"state":             input.State,
+		}), nil
+	}
+	if input.ResponseType != "code" {
+		return fail("unsupported_response_type", "only the code response type is supported")
+	}
+	if !slices.Contains(input.Scopes, entity.SCOPE_OPENID) || !client.AllowsScopes(input.Scopes) {
+		return fail("invalid_scope", "scope must include openid and only scopes granted to the client")
+	}
+	if input.CodeChallenge == "" || input.CodeChallengeMethod != entity.PKCE_METHOD_S256 {
+		return fail("invalid_request", "PKCE with code_challenge_method=S256 is required")
+	}
+
+	now := time.Now().UTC()
+	// auth_time is when the user signed in, not when the code was issued.
+	session, err := uc.signedInSession(ctx, input, now)
+	if errors.Is(err, errs.ErrSessionNotFound) {
+		return fail("login_required", "the session has ended, sign in again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	_, err = uc.codeRepo.Create(ctx, &entity.AuthorizationCode{
+		CodeHash:      securetoken.Hash(code),
+		ClientID:      client.ClientID,
+		UserID:        input.UserID,
+		SessionID:     input.SessionID,
+		RedirectURI:   input.RedirectURI,
+		Scopes:        input.Scopes,
+		Nonce:         input.Nonce,
+		CodeChallenge: input.CodeChallenge,
+		AuthTime:      session.CreatedAt,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(uc.codeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return redirectWith(redirect, map[string]string{"code": code, "state": input.State}), nil
+}
+
+// signedInSession returns the live session of the user, failing with
+// ErrSessionNotFound when there is none.
+func (uc *AuthorizeUseCase) signedInSession(ctx context.Context, input *dto.AuthorizeInput, now time.Time) (*entity.Session, error) {
+	if input.SessionID == uuid.Nil {
+		return nil, errs.ErrSessionNotFound
+	}
+	s, err := uc.sessionRepo.GetByID(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID != input.UserID || !s.IsActive(now) {
+		return nil, errs.ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// redirectWith adds params to the query of base, keeping any query the
+// relying party registered and skipping empty values.
+func redirectWith(base *url.URL, params map[string]string) *dto.AuthorizeResult {
+	u := *base
+	q := u.Query()
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return &dto.AuthorizeResult{RedirectTo: u.String()}
+}
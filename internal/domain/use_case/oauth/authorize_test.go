@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+func TestAuthorizeCarriesSignInTime(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	signedIn := now.Add(-3 * time.Hour).Truncate(time.Second)
+	clients := infrastructure.NewOAuthClientRepository()
+	codes := infrastructure.NewAuthorizationCodeRepository(nil)
+	sessions := infrastructure.NewSessionRepository()
+	uc := NewAuthorizeUseCase(clients, codes, sessions, time.Minute)
+
+	if _, err := clients.Create(ctx, &entity.OAuthClient{
+		ClientID:     "rp",
+		Public:       true,
+		Scopes:       []string{entity.SCOPE_OPENID},
+		RedirectURIs: []string{"https://rp.example/cb"},
+		CreatedAt:    now,
+	}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	userID := uuid.New()
+	s, err := sessions.Create(ctx, &entity.Session{UserID: userID, CreatedAt: signedIn, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	authorize := func(sessionID uuid.UUID) url.Values {
+		t.Helper()
+		res, err := uc.Execute(ctx, &dto.AuthorizeInput{
+			UserID:              userID,
+			SessionID:           sessionID,
+			ResponseType:        "code",
+			ClientID:            "rp",
+			RedirectURI:         "https://rp.example/cb",
+			Scopes:              []string{entity.SCOPE_OPENID},
+			CodeChallenge:       "challenge",
+			CodeChallengeMethod: entity.PKCE_METHOD_S256,
+		})
+		if err != nil {
+			t.Fatalf("authorize: %v", err)
+		}
+		to, err := url.Parse(res.RedirectTo)
+		if err != nil {
+			t.Fatalf("parse redirect: %v", err)
+		}
+		return to.Query()
+	}
+
+	code, err := codes.GetByCodeHash(ctx, securetoken.Hash(authorize(s.ID).Get("code")))
+	if err != nil {
+		t.Fatalf("get code: %v", err)
+	}
+	if !code.AuthTime.Equal(signedIn) {
+		t.Errorf("auth_time = %v, want the sign-in time %v", code.AuthTime, signedIn)
+	}
+
+	if got := authorize(uuid.New()).Get("error"); got != "login_required" {
+		t.Errorf("error for an unknown session = %q, want login_required", got)
+	}
+}
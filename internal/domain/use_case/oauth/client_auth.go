@@ -0,0 +1,40 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// authenticateClient checks client credentials at the token endpoint. Public
+// clients have no secret and must not send one. Every failure is
+// ErrInvalidClient so callers cannot probe which client IDs exist.
+func authenticateClient(ctx context.Context, clientRepo contract.OAuthClientRepository, clientID, secret string) (*entity.OAuthClient, error) {
+	client, err := clientRepo.GetByClientID(ctx, clientID)
+	if errors.Is(err, errs.ErrOAuthClientNotFound) {
+		return nil, errs.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive() {
+		return nil, errs.ErrInvalidClient
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, errs.ErrInvalidClient
+		}
+		return client, nil
+	}
+	hash := securetoken.Hash(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+		return nil, errs.ErrInvalidClient
+	}
+	return client, nil
+}
@@ -0,0 +1,58 @@
+package oauth
+
+import (
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// DiscoveryDocument is the OpenID Provider Metadata served at
+// /.well-known/openid-configuration.
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type DiscoveryUseCase struct {
+	tokenIssuer contract.OIDCTokenIssuer
+}
+
+func NewDiscoveryUseCase(tokenIssuer contract.OIDCTokenIssuer) *DiscoveryUseCase {
+	return &DiscoveryUseCase{tokenIssuer: tokenIssuer}
+}
+
+func (uc *DiscoveryUseCase) Document() *DiscoveryDocument {
+	issuer := uc.tokenIssuer.Issuer()
+	return &DiscoveryDocument{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   []string{entity.SCOPE_OPENID, entity.SCOPE_EMAIL, entity.SCOPE_PROFILE},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{entity.PKCE_METHOD_S256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "preferred_username"},
+	}
+}
+
+func (uc *DiscoveryUseCase) JWKS() *dto.JWKS {
+	return uc.tokenIssuer.JWKS()
+}
@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type ExchangeCodeUseCaseArgs struct {
+	ClientRepo  contract.OAuthClientRepository
+	CodeRepo    contract.AuthorizationCodeRepository
+	UserRepo    contract.UserRepository
+	SessionRepo contract.SessionRepository
+	TokenIssuer contract.OIDCTokenIssuer
+}
+
+type ExchangeCodeUseCase struct {
+	clientRepo  contract.OAuthClientRepository
+	codeRepo    contract.AuthorizationCodeRepository
+	userRepo    contract.UserRepository
+	sessionRepo contract.SessionRepository
+	tokenIssuer contract.OIDCTokenIssuer
+}
+
+func NewExchangeCodeUseCase(args ExchangeCodeUseCaseArgs) *ExchangeCodeUseCase {
+	return &ExchangeCodeUseCase{
+		clientRepo:  args.ClientRepo,
+		codeRepo:    args.CodeRepo,
+		userRepo:    args.UserRepo,
+		sessionRepo: args.SessionRepo,
+		tokenIssuer: args.TokenIssuer,
+	}
+}
+
+// Execute implements the authorization_code grant: it checks the code was
+// issued to this client and redirect URI, verifies the PKCE code verifier
+// and consumes the code. A code redeemed a second time revokes the session
+// the first redemption's access token is bound to, as RFC 6749 section
+// 4.1.2 asks of a replayed code.
+func (uc *ExchangeCodeUseCase) Execute(ctx context.Context, input *dto.AuthorizationCodeInput) (_ *dto.OIDCTokens, err error) {
+	defer instrument.Observe("oauth.exchange_code", time.Now(), &err)
+
+	client, err := authenticateClient(ctx, uc.clientRepo, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHash := securetoken.Hash(input.Code)
+	code, err := uc.codeRepo.GetByCodeHash(ctx, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	if code.ClientID != client.ClientID {
+		return nil, errs.ErrInvalidGrant
+	}
+	now := time.Now().UTC()
+	if code.ConsumedAt != nil {
+		return nil, uc.revokeReplayed(ctx, code, now)
+	}
+	if code.RedirectURI != input.RedirectURI || !code.IsUsable(now) {
+		return nil, errs.ErrInvalidGrant
+	}
+	if !verifyPKCE(code, input.CodeVerifier) {
+		return nil, errs.ErrInvalidGrant
+	}
+	if err := uc.codeRepo.Consume(ctx, codeHash, now); err != nil {
+		if errors.Is(err, errs.ErrInvalidGrant) {
+			// Redeemed concurrently: the other exchange got the tokens.
+			return nil, uc.revokeReplayed(ctx, code, now)
+		}
+		return nil, err
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, code.UserID)
+	if err != nil || u.CheckStatus() != nil {
+		return nil, errs.ErrInvalidGrant
+	}
+
+	access, err := uc.tokenIssuer.IssueDelegatedToken(ctx, u, code)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := uc.tokenIssuer.IssueIDToken(ctx, u, code)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OIDCTokens{ClientToken: *access, IDToken: idToken}, nil
+}
+
+// revokeReplayed revokes the session of a code that was redeemed already,
+// which the access token issued from it is bound to, and returns
+// ErrInvalidGrant.
+func (uc *ExchangeCodeUseCase) revokeReplayed(ctx context.Context, code *entity.AuthorizationCode, now time.Time) error {
+	ctxutil.Logger(ctx).Warnw("authorization code replayed, revoking its session",
+		"client_id", code.ClientID, "user_id", code.UserID, "session_id", code.SessionID)
+	if err := uc.sessionRepo.Revoke(ctx, code.SessionID, now); err != nil && !errors.Is(err, errs.ErrSessionNotFound) {
+		return err
+	}
+	return errs.ErrInvalidGrant
+}
+
+// verifyPKCE checks the S256 transformation of RFC 7636 section 4.6.
+func verifyPKCE(code *entity.AuthorizationCode, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) == 1
+}
@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// stubIssuer issues fixed tokens; the exchange only hands them back.
+type stubIssuer struct{ contract.OIDCTokenIssuer }
+
+func (stubIssuer) IssueDelegatedToken(context.Context, *entity.User, *entity.AuthorizationCode) (*dto.ClientToken, error) {
+	return &dto.ClientToken{AccessToken: "access", TokenType: "Bearer"}, nil
+}
+
+func (stubIssuer) IssueIDToken(context.Context, *entity.User, *entity.AuthorizationCode) (string, error) {
+	return "id", nil
+}
+
+func TestReplayedCodeRevokesItsSession(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	clients := infrastructure.NewOAuthClientRepository()
+	codes := infrastructure.NewAuthorizationCodeRepository(nil)
+	users := infrastructure.NewUserRepository()
+	sessions := infrastructure.NewSessionRepository()
+	uc := NewExchangeCodeUseCase(ExchangeCodeUseCaseArgs{
+		ClientRepo:  clients,
+		CodeRepo:    codes,
+		UserRepo:    users,
+		SessionRepo: sessions,
+		TokenIssuer: stubIssuer{},
+	})
+
+	if _, err := clients.Create(ctx, &entity.OAuthClient{ClientID: "rp", Public: true, CreatedAt: now}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	u, err := users.Create(ctx, &entity.User{Email: "rp@example.com", Username: "rp"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	s, err := sessions.Create(ctx, &entity.Session{UserID: u.ID, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	if _, err := codes.Create(ctx, &entity.AuthorizationCode{
+		CodeHash:      securetoken.Hash("code"),
+		ClientID:      "rp",
+		UserID:        u.ID,
+		SessionID:     s.ID,
+		RedirectURI:   "https://rp.example/cb",
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(time.Minute),
+	}); err != nil {
+		t.Fatalf("create code: %v", err)
+	}
+
+	input := &dto.AuthorizationCodeInput{ClientID: "rp", Code: "code", RedirectURI: "https://rp.example/cb", CodeVerifier: verifier}
+	if _, err := uc.Execute(ctx, input); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+	if got, _ := sessions.GetByID(ctx, s.ID); !got.IsActive(time.Now()) {
+		t.Fatal("the first exchange revoked the session")
+	}
+
+	if _, err := uc.Execute(ctx, input); !errors.Is(err, errs.ErrInvalidGrant) {
+		t.Fatalf("replay: got %v, want ErrInvalidGrant", err)
+	}
+	if got, _ := sessions.GetByID(ctx, s.ID); got.IsActive(time.Now()) {
+		t.Fatal("the replay left the session the issued token is bound to active")
+	}
+}
@@ -0,0 +1,240 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// inactive is the answer for every token that is not active, so callers
+// learn nothing about why.
+var inactive = &dto.Introspection{Active: false}
+
+type IntrospectTokenUseCaseArgs struct {
+	ClientRepo  contract.OAuthClientRepository
+	Verifier    contract.AccessTokenVerifier
+	TokenRepo   contract.PersonalAccessTokenRepository
+	SessionRepo contract.SessionRepository
+	UserRepo    contract.UserRepository
+	AccountRepo contract.ServiceAccountRepository
+	KeyRepo     contract.ServiceAccountKeyRepository
+}
+
+// IntrospectTokenUseCase tells resource servers whether a token is active,
+// per RFC 7662, applying the same checks as the API: the signature and
+// expiry, and that the session, user, client, personal token or service
+// account key behind it has not been revoked, suspended or deleted since.
+type IntrospectTokenUseCase struct {
+	clientRepo  contract.OAuthClientRepository
+	verifier    contract.AccessTokenVerifier
+	tokenRepo   contract.PersonalAccessTokenRepository
+	sessionRepo contract.SessionRepository
+	userRepo    contract.UserRepository
+	accountRepo contract.ServiceAccountRepository
+	keyRepo     contract.ServiceAccountKeyRepository
+}
+
+func NewIntrospectTokenUseCase(args IntrospectTokenUseCaseArgs) *IntrospectTokenUseCase {
+	return &IntrospectTokenUseCase{
+		clientRepo:  args.ClientRepo,
+		verifier:    args.Verifier,
+		tokenRepo:   args.TokenRepo,
+		sessionRepo: args.SessionRepo,
+		userRepo:    args.UserRepo,
+		accountRepo: args.AccountRepo,
+		keyRepo:     args.KeyRepo,
+	}
+}
+
+// Execute introspects input.Token for the confidential client input
+// authenticates as, which must have been granted entity.SCOPE_INTROSPECT.
+func (uc *IntrospectTokenUseCase) Execute(ctx context.Context, input *dto.IntrospectionInput) (_ *dto.Introspection, err error) {
+	defer instrument.Observe("oauth.introspect_token", time.Now(), &err)
+
+	caller, err := authenticateClient(ctx, uc.clientRepo, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Public {
+		return nil, errs.ErrInvalidClient
+	}
+	if !caller.AllowsScopes([]string{entity.SCOPE_INTROSPECT}) {
+		return nil, errs.ErrIntrospectionNotAllowed
+	}
+
+	if strings.HasPrefix(input.Token, entity.PERSONAL_TOKEN_PREFIX) {
+		return uc.personalToken(ctx, input.Token)
+	}
+	if strings.HasPrefix(input.Token, entity.SERVICE_ACCOUNT_KEY_PREFIX) {
+		return uc.serviceAccountKey(ctx, input.Token)
+	}
+	claims, err := uc.verifier.VerifyAccessToken(ctx, input.Token)
+	if err != nil {
+		return inactive, nil
+	}
+	if claims.Kind == dto.TOKEN_KIND_CLIENT {
+		return uc.clientToken(ctx, claims)
+	}
+	return uc.userToken(ctx, claims)
+}
+
+func (uc *IntrospectTokenUseCase) personalToken(ctx context.Context, token string) (*dto.Introspection, error) {
+	t, err := uc.tokenRepo.GetByHash(ctx, securetoken.Hash(token))
+	if errors.Is(err, errs.ErrPersonalTokenNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsActive(time.Now()) {
+		return inactive, nil
+	}
+	u, ok, err := uc.activeUser(ctx, t.UserID)
+	if err != nil || !ok {
+		return inactive, err
+	}
+
+	out := &dto.Introspection{
+		Active:    true,
+		Scope:     strings.Join(t.Scopes, " "),
+		Username:  u.Username,
+		TokenType: "Bearer",
+		IssuedAt:  t.CreatedAt.Unix(),
+		Subject:   u.ID.String(),
+		TokenID:   t.ID.String(),
+		TokenKind: dto.TOKEN_KIND_PERSONAL,
+		TenantID:  u.TenantID,
+	}
+	if t.ExpiresAt != nil {
+		out.ExpiresAt = t.ExpiresAt.Unix()
+	}
+	return out, nil
+}
+
+// serviceAccountKey checks the key and its account, like the api_key
+// strategy. The account's organization is reported as the tenant.
+func (uc *IntrospectTokenUseCase) serviceAccountKey(ctx context.Context, key string) (*dto.Introspection, error) {
+	k, err := uc.keyRepo.GetByHash(ctx, securetoken.Hash(key))
+	if errors.Is(err, errs.ErrServiceAccountKeyNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !k.IsActive(time.Now()) {
+		return inactive, nil
+	}
+	a, err := uc.accountRepo.GetByID(ctx, k.ServiceAccountID)
+	if errors.Is(err, errs.ErrServiceAccountNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsActive() {
+		return inactive, nil
+	}
+
+	out := &dto.Introspection{
+		Active:    true,
+		Username:  a.Name,
+		TokenType: "Bearer",
+		IssuedAt:  k.CreatedAt.Unix(),
+		Subject:   a.ID.String(),
+		TokenID:   k.ID.String(),
+		TokenKind: dto.TOKEN_KIND_SERVICE_ACCOUNT,
+		Roles:     a.Roles,
+		TenantID:  a.Tenant,
+	}
+	if k.ExpiresAt != nil {
+		out.ExpiresAt = k.ExpiresAt.Unix()
+	}
+	return out, nil
+}
+
+func (uc *IntrospectTokenUseCase) clientToken(ctx context.Context, claims *dto.AccessTokenClaims) (*dto.Introspection, error) {
+	client, err := uc.clientRepo.GetByClientID(ctx, claims.Subject)
+	if errors.Is(err, errs.ErrOAuthClientNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive() {
+		return inactive, nil
+	}
+	out := introspection(claims)
+	out.ClientID = client.ClientID
+	return out, nil
+}
+
+// userToken checks the user and, like RequireActiveSession, the session of
+// user and delegated tokens.
+func (uc *IntrospectTokenUseCase) userToken(ctx context.Context, claims *dto.AccessTokenClaims) (*dto.Introspection, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return inactive, nil
+	}
+	s, err := uc.sessionRepo.GetByID(ctx, claims.SessionID)
+	if errors.Is(err, errs.ErrSessionNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID != userID || !s.IsActive(time.Now().UTC()) {
+		return inactive, nil
+	}
+	u, ok, err := uc.activeUser(ctx, userID)
+	if err != nil || !ok {
+		return inactive, err
+	}
+
+	out := introspection(claims)
+	out.Username = u.Username
+	return out, nil
+}
+
+// activeUser loads the user behind a token; ok is false when it was deleted
+// or may not sign in.
+func (uc *IntrospectTokenUseCase) activeUser(ctx context.Context, id uuid.UUID) (_ *entity.User, ok bool, err error) {
+	u, err := uc.userRepo.GetByID(ctx, id)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return u, u.CheckStatus() == nil, nil
+}
+
+func introspection(claims *dto.AccessTokenClaims) *dto.Introspection {
+	out := &dto.Introspection{
+		Active:    true,
+		Scope:     strings.Join(claims.Scopes, " "),
+		ClientID:  claims.ClientID,
+		TokenType: "Bearer",
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		Issuer:    claims.Issuer,
+		TokenID:   claims.TokenID,
+		TokenKind: claims.Kind,
+		Roles:     claims.Roles,
+		TenantID:  claims.TenantID,
+	}
+	if claims.ActorID != "" {
+		out.Actor = &dto.IntrospectionActor{Subject: claims.ActorID}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+func TestIntrospectServiceAccountKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	clients := infrastructure.NewOAuthClientRepository()
+	accounts := infrastructure.NewServiceAccountRepository()
+	keys := infrastructure.NewServiceAccountKeyRepository()
+	uc := NewIntrospectTokenUseCase(IntrospectTokenUseCaseArgs{
+		ClientRepo:  clients,
+		AccountRepo: accounts,
+		KeyRepo:     keys,
+	})
+
+	if _, err := clients.Create(ctx, &entity.OAuthClient{
+		ClientID:   "rs",
+		SecretHash: securetoken.Hash("secret"),
+		Scopes:     []string{entity.SCOPE_INTROSPECT},
+		CreatedAt:  now,
+	}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	a, err := accounts.Create(ctx, &entity.ServiceAccount{Tenant: "acme", Name: "ci", Roles: []string{"deployer"}, CreatedAt: now})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	key := entity.SERVICE_ACCOUNT_KEY_PREFIX + "live"
+	k, err := keys.Create(ctx, &entity.ServiceAccountKey{ServiceAccountID: a.ID, KeyHash: securetoken.Hash(key), CreatedAt: now})
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	introspect := func(token string) *dto.Introspection {
+		t.Helper()
+		out, err := uc.Execute(ctx, &dto.IntrospectionInput{ClientID: "rs", ClientSecret: "secret", Token: token})
+		if err != nil {
+			t.Fatalf("introspect: %v", err)
+		}
+		return out
+	}
+
+	got := introspect(key)
+	switch {
+	case !got.Active:
+		t.Fatal("a live key is reported inactive")
+	case got.TokenKind != dto.TOKEN_KIND_SERVICE_ACCOUNT:
+		t.Errorf("token_kind = %q, want %q", got.TokenKind, dto.TOKEN_KIND_SERVICE_ACCOUNT)
+	case got.Subject != a.ID.String() || got.TokenID != k.ID.String():
+		t.Errorf("sub, jti = %q, %q, want the account and key IDs", got.Subject, got.TokenID)
+	case got.TenantID != "acme":
+		t.Errorf("tid = %q, want acme", got.TenantID)
+	case !slices.Equal(got.Roles, []string{"deployer"}):
+		t.Errorf("roles = %v, want [deployer]", got.Roles)
+	}
+
+	if got := introspect(entity.SERVICE_ACCOUNT_KEY_PREFIX + "unknown"); got.Active {
+		t.Error("an unknown key is reported active")
+	}
+	if err := keys.Revoke(ctx, k.ID, now); err != nil {
+		t.Fatalf("revoke key: %v", err)
+	}
+	if got := introspect(key); got.Active {
+		t.Error("a revoked key is reported active")
+	}
+
+	live := entity.SERVICE_ACCOUNT_KEY_PREFIX + "orphan"
+	if _, err := keys.Create(ctx, &entity.ServiceAccountKey{ServiceAccountID: a.ID, KeyHash: securetoken.Hash(live), CreatedAt: now}); err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	a.DeletedAt = &now
+	if _, err := accounts.Update(ctx, a); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	if got := introspect(live); got.Active {
+		t.Error("the key of a deleted account is reported active")
+	}
+}
@@ -0,0 +1,45 @@
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type IssueClientTokenUseCase struct {
+	clientRepo  contract.OAuthClientRepository
+	tokenIssuer contract.ClientTokenIssuer
+}
+
+func NewIssueClientTokenUseCase(clientRepo contract.OAuthClientRepository, tokenIssuer contract.ClientTokenIssuer) *IssueClientTokenUseCase {
+	return &IssueClientTokenUseCase{clientRepo: clientRepo, tokenIssuer: tokenIssuer}
+}
+
+// Execute implements the client_credentials grant, which public clients
+// cannot use.
+func (uc *IssueClientTokenUseCase) Execute(ctx context.Context, input *dto.ClientCredentialsInput) (_ *dto.ClientToken, err error) {
+	defer instrument.Observe("oauth.issue_client_token", time.Now(), &err)
+
+	client, err := authenticateClient(ctx, uc.clientRepo, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	// Without a secret there is nothing to authenticate a machine caller by.
+	if client.Public {
+		return nil, errs.ErrInvalidClient
+	}
+
+	scopes := input.Scopes
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, errs.ErrInvalidScope
+	}
+
+	return uc.tokenIssuer.IssueClientToken(ctx, client, scopes)
+}
@@ -0,0 +1,24 @@
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListClientsUseCase struct {
+	clientRepo contract.OAuthClientRepository
+}
+
+func NewListClientsUseCase(clientRepo contract.OAuthClientRepository) *ListClientsUseCase {
+	return &ListClientsUseCase{clientRepo: clientRepo}
+}
+
+func (uc *ListClientsUseCase) Execute(ctx context.Context) (_ []*entity.OAuthClient, err error) {
+	defer instrument.Observe("oauth.list_clients", time.Now(), &err)
+
+	return uc.clientRepo.List(ctx)
+}
@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type RegisterClientUseCase struct {
+	clientRepo contract.OAuthClientRepository
+	plans      []string
+}
+
+// NewRegisterClientUseCase takes the names of the configured quota plans a
+// client can be put on.
+func NewRegisterClientUseCase(clientRepo contract.OAuthClientRepository, plans []string) *RegisterClientUseCase {
+	return &RegisterClientUseCase{clientRepo: clientRepo, plans: plans}
+}
+
+func (uc *RegisterClientUseCase) Execute(ctx context.Context, input *dto.RegisterOAuthClientInput) (_ *dto.RegisteredOAuthClient, err error) {
+	defer instrument.Observe("oauth.register_client", time.Now(), &err)
+
+	if input.Plan != "" && !slices.Contains(uc.plans, input.Plan) {
+		return nil, errs.ErrUnknownPlan
+	}
+
+	clientID, err := securetoken.New(16)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &entity.OAuthClient{
+		ClientID:     clientID,
+		Name:         input.Name,
+		Scopes:       input.Scopes,
+		RedirectURIs: input.RedirectURIs,
+		Public:       input.Public,
+		Plan:         input.Plan,
+		CreatedBy:    input.CreatedBy,
+		CreatedAt:    time.Now().UTC(),
+	}
+	var secret string
+	if !input.Public {
+		secret, err = securetoken.New(32)
+		if err != nil {
+			return nil, err
+		}
+		client.SecretHash = securetoken.Hash(secret)
+	}
+
+	client, err = uc.clientRepo.Create(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RegisteredOAuthClient{OAuthClient: client, ClientSecret: secret}, nil
+}
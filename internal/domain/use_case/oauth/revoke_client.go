@@ -0,0 +1,26 @@
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RevokeClientUseCase struct {
+	clientRepo contract.OAuthClientRepository
+}
+
+func NewRevokeClientUseCase(clientRepo contract.OAuthClientRepository) *RevokeClientUseCase {
+	return &RevokeClientUseCase{clientRepo: clientRepo}
+}
+
+// Execute revokes the client; tokens it already holds stop working because
+// AuthenticateClient rechecks the client on every request.
+func (uc *RevokeClientUseCase) Execute(ctx context.Context, id uuid.UUID) (err error) {
+	defer instrument.Observe("oauth.revoke_client", time.Now(), &err)
+
+	return uc.clientRepo.Revoke(ctx, id, time.Now().UTC())
+}
@@ -0,0 +1,44 @@
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type UserInfoUseCase struct {
+	userRepo contract.UserRepository
+}
+
+func NewUserInfoUseCase(userRepo contract.UserRepository) *UserInfoUseCase {
+	return &UserInfoUseCase{userRepo: userRepo}
+}
+
+// Execute returns the claims about userID that scopes release.
+func (uc *UserInfoUseCase) Execute(ctx context.Context, userID uuid.UUID, scopes []string) (_ *dto.UserInfo, err error) {
+	defer instrument.Observe("oauth.user_info", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.CheckStatus(); err != nil {
+		return nil, err
+	}
+
+	info := &dto.UserInfo{Subject: u.ID.String()}
+	for _, s := range scopes {
+		switch s {
+		case entity.SCOPE_EMAIL:
+			info.Email = u.Email
+		case entity.SCOPE_PROFILE:
+			info.PreferredUsername = u.Username
+		}
+	}
+	return info, nil
+}
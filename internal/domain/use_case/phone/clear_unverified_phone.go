@@ -0,0 +1,63 @@
+package phone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// JOB_CLEAR_UNVERIFIED_PHONE is the kind of the job that clears a number
+// still unverified once the verification window has passed.
+const JOB_CLEAR_UNVERIFIED_PHONE = "phone.clear_unverified"
+
+type clearUnverifiedPhonePayload struct {
+	UserID uuid.UUID `json:"user_id"`
+	Phone  string    `json:"phone"`
+}
+
+// clearUnverifiedPhoneKey identifies the user's pending job, which
+// verifying the number cancels.
+func clearUnverifiedPhoneKey(userID uuid.UUID) string {
+	return JOB_CLEAR_UNVERIFIED_PHONE + ":" + userID.String()
+}
+
+type ClearUnverifiedPhoneUseCase struct {
+	userRepo contract.UserRepository
+}
+
+func NewClearUnverifiedPhoneUseCase(userRepo contract.UserRepository) *ClearUnverifiedPhoneUseCase {
+	return &ClearUnverifiedPhoneUseCase{userRepo: userRepo}
+}
+
+// Execute handles a JOB_CLEAR_UNVERIFIED_PHONE job: it removes the number
+// from the account unless it has been verified or replaced since.
+func (uc *ClearUnverifiedPhoneUseCase) Execute(ctx context.Context, payload json.RawMessage) (err error) {
+	defer instrument.Observe("phone.clear_unverified_phone", time.Now(), &err)
+
+	var p clearUnverifiedPhonePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, p.UserID)
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.Phone != p.Phone || u.PhoneVerifiedAt != nil {
+		return nil
+	}
+
+	u.Phone = ""
+	_, err = uc.userRepo.Update(ctx, u)
+	return err
+}
@@ -0,0 +1,115 @@
+package phone
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+const testPhone = "+15555550100"
+
+// codeSender keeps the code of the last text it was asked to send.
+type codeSender struct{ code string }
+
+func (s *codeSender) Send(_ context.Context, msg dto.SMSMessage) error {
+	_, rest, _ := strings.Cut(msg.Body, "code is ")
+	s.code, _, _ = strings.Cut(rest, ".")
+	return nil
+}
+
+func newTestOTPService(clk clock.Clock) (*OTPService, *codeSender) {
+	sender := &codeSender{}
+	return NewOTPService(OTPServiceArgs{
+		OTPRepo:        infrastructure.NewPhoneOTPRepository(),
+		SMSSender:      sender,
+		TTL:            5 * time.Minute,
+		CodeLength:     6,
+		MaxAttempts:    3,
+		ResendInterval: time.Minute,
+		MaxPerHour:     3,
+		Clock:          clk,
+	}), sender
+}
+
+func TestOTPExpires(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    error
+	}{
+		{5*time.Minute - time.Second, nil},
+		{5 * time.Minute, errs.ErrInvalidOTP},
+	} {
+		clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+		s, sender := newTestOTPService(clk)
+		if err := s.Issue(ctx, testPhone, entity.OTP_PURPOSE_SIGN_IN, uuid.New()); err != nil {
+			t.Fatalf("issue: %v", err)
+		}
+		clk.Advance(tc.elapsed)
+		if _, err := s.Verify(ctx, testPhone, entity.OTP_PURPOSE_SIGN_IN, sender.code); !errors.Is(err, tc.want) {
+			t.Errorf("verify after %v: err = %v, want %v", tc.elapsed, err, tc.want)
+		}
+	}
+}
+
+func TestOTPAttemptsRunOut(t *testing.T) {
+	ctx := context.Background()
+	s, sender := newTestOTPService(clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)))
+	if err := s.Issue(ctx, testPhone, entity.OTP_PURPOSE_SIGN_IN, uuid.New()); err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	for range 3 {
+		if _, err := s.Verify(ctx, testPhone, entity.OTP_PURPOSE_SIGN_IN, "wrong"); !errors.Is(err, errs.ErrInvalidOTP) {
+			t.Fatalf("wrong code: err = %v, want %v", err, errs.ErrInvalidOTP)
+		}
+	}
+	if _, err := s.Verify(ctx, testPhone, entity.OTP_PURPOSE_SIGN_IN, sender.code); !errors.Is(err, errs.ErrInvalidOTP) {
+		t.Errorf("right code after the attempts ran out: err = %v, want %v", err, errs.ErrInvalidOTP)
+	}
+}
+
+func TestOTPResendLimits(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	s, _ := newTestOTPService(clk)
+	issue := func() error {
+		return s.Issue(ctx, testPhone, entity.OTP_PURPOSE_VERIFY_PHONE, uuid.New())
+	}
+
+	if err := issue(); err != nil {
+		t.Fatalf("first code: %v", err)
+	}
+	clk.Advance(time.Minute - time.Second)
+	if err := issue(); !errors.Is(err, errs.ErrOTPRateLimited) {
+		t.Errorf("resend within the interval: err = %v, want %v", err, errs.ErrOTPRateLimited)
+	}
+	clk.Advance(time.Second)
+	if err := issue(); err != nil {
+		t.Fatalf("resend after the interval: %v", err)
+	}
+	clk.Advance(time.Minute)
+	if err := issue(); err != nil {
+		t.Fatalf("third code: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+	if err := issue(); !errors.Is(err, errs.ErrOTPRateLimited) {
+		t.Errorf("fourth code in the hour: err = %v, want %v", err, errs.ErrOTPRateLimited)
+	}
+	// The first code leaves the rolling hour a moment after it turns an
+	// hour old.
+	clk.Set(start.Add(time.Hour + time.Second))
+	if err := issue(); err != nil {
+		t.Errorf("code once the first left the hour: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package phone
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RequestPhoneVerificationUseCase struct {
+	userRepo      contract.UserRepository
+	otpService    *OTPService
+	scheduler     contract.JobScheduler
+	unverifiedTTL time.Duration
+}
+
+// NewRequestPhoneVerificationUseCase takes how long a number may stay
+// unverified before it is cleared; 0 keeps it.
+func NewRequestPhoneVerificationUseCase(
+	userRepo contract.UserRepository,
+	otpService *OTPService,
+	scheduler contract.JobScheduler,
+	unverifiedTTL time.Duration,
+) *RequestPhoneVerificationUseCase {
+	return &RequestPhoneVerificationUseCase{
+		userRepo:      userRepo,
+		otpService:    otpService,
+		scheduler:     scheduler,
+		unverifiedTTL: unverifiedTTL,
+	}
+}
+
+// Execute stores phone as the user's unverified number, replacing any
+// previous one, and texts it a verification code. The number is cleared
+// if it is still unverified after the TTL.
+func (uc *RequestPhoneVerificationUseCase) Execute(ctx context.Context, userID uuid.UUID, rawPhone string) (err error) {
+	defer instrument.Observe("phone.request_phone_verification", time.Now(), &err)
+
+	phone, err := entity.NormalizePhone(rawPhone)
+	if err != nil {
+		return err
+	}
+
+	owner, err := uc.userRepo.GetByPhone(ctx, phone)
+	switch {
+	case err == nil && owner.ID != userID:
+		return errs.ErrPhoneTaken
+	case err != nil && !errors.Is(err, errs.ErrUserNotFound):
+		return err
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.Phone != phone || u.PhoneVerifiedAt == nil {
+		u.Phone = phone
+		u.PhoneVerifiedAt = nil
+		if _, err := uc.userRepo.Update(ctx, u); err != nil {
+			return err
+		}
+		if uc.unverifiedTTL > 0 {
+			err := uc.scheduler.Schedule(ctx, JOB_CLEAR_UNVERIFIED_PHONE, clearUnverifiedPhoneKey(u.ID),
+				time.Now().Add(uc.unverifiedTTL), clearUnverifiedPhonePayload{UserID: u.ID, Phone: phone})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return uc.otpService.Issue(ctx, phone, entity.OTP_PURPOSE_VERIFY_PHONE, u.ID)
+}
@@ -0,0 +1,71 @@
+package phone
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+type VerifyPhoneUseCase struct {
+	userRepo   contract.UserRepository
+	otpService *OTPService
+	analytics  contract.AnalyticsTracker
+	scheduler  contract.JobScheduler
+}
+
+func NewVerifyPhoneUseCase(
+	userRepo contract.UserRepository,
+	otpService *OTPService,
+	analytics contract.AnalyticsTracker,
+	scheduler contract.JobScheduler,
+) *VerifyPhoneUseCase {
+	return &VerifyPhoneUseCase{userRepo: userRepo, otpService: otpService, analytics: analytics, scheduler: scheduler}
+}
+
+// Execute marks the user's pending phone number verified when code matches,
+// which enables signing in with it.
+func (uc *VerifyPhoneUseCase) Execute(ctx context.Context, userID uuid.UUID, code string) (_ *entity.User, err error) {
+	defer instrument.Observe("phone.verify_phone", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Phone == "" || u.PhoneVerifiedAt != nil {
+		return nil, errs.ErrPhoneNotPending
+	}
+
+	otp, err := uc.otpService.Verify(ctx, u.Phone, entity.OTP_PURPOSE_VERIFY_PHONE, code)
+	if err != nil {
+		return nil, err
+	}
+	if otp.UserID != u.ID {
+		return nil, errs.ErrInvalidOTP
+	}
+
+	now := time.Now().UTC()
+	u.PhoneVerifiedAt = &now
+	u, err = uc.userRepo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	// The clearing job checks the number is still unverified anyway, so it
+	// does no harm if canceling fails.
+	if err := uc.scheduler.Cancel(ctx, clearUnverifiedPhoneKey(u.ID)); err != nil {
+		ctxutil.Logger(ctx).Warnw("cancel unverified phone job", "user_id", u.ID, "error", err)
+	}
+	uc.analytics.Track(ctx, dto.AnalyticsEvent{
+		Name:       dto.ANALYTICS_PROFILE_UPDATED,
+		UserID:     u.ID,
+		TenantID:   u.TenantID,
+		Properties: map[string]any{"field": "phone"},
+	})
+	return u, nil
+}
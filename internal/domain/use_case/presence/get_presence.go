@@ -0,0 +1,50 @@
+package presence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetPresenceUseCase struct {
+	userRepo      contract.UserRepository
+	presenceStore contract.PresenceStore
+}
+
+func NewGetPresenceUseCase(userRepo contract.UserRepository, presenceStore contract.PresenceStore) *GetPresenceUseCase {
+	return &GetPresenceUseCase{userRepo: userRepo, presenceStore: presenceStore}
+}
+
+// Execute reports whether the user is online. Viewers see themselves and
+// the members of their own tenant, admins everyone; any other user, and an
+// unknown one, fails with ErrUserNotFound, so their existence is not
+// revealed either.
+func (uc *GetPresenceUseCase) Execute(ctx context.Context, viewer dto.PresenceViewer, userID uuid.UUID) (_ *dto.Presence, err error) {
+	defer instrument.Observe("presence.get_presence", time.Now(), &err)
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeePresence(viewer, u) {
+		return nil, errs.ErrUserNotFound
+	}
+	return uc.presenceStore.Get(ctx, userID, time.Now().UTC())
+}
+
+func canSeePresence(viewer dto.PresenceViewer, u *entity.User) bool {
+	switch {
+	case viewer.ID == u.ID || viewer.Admin:
+		return true
+	case viewer.Guest || viewer.TenantID == "":
+		return false
+	default:
+		return viewer.TenantID == u.TenantID
+	}
+}
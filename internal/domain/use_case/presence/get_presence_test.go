@@ -0,0 +1,31 @@
+package presence
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+func TestCanSeePresence(t *testing.T) {
+	member := &entity.User{ID: uuid.New(), TenantID: "acme"}
+	loner := &entity.User{ID: uuid.New()}
+	for _, tc := range []struct {
+		name   string
+		viewer dto.PresenceViewer
+		target *entity.User
+		want   bool
+	}{
+		{"self", dto.PresenceViewer{ID: loner.ID}, loner, true},
+		{"same tenant", dto.PresenceViewer{ID: uuid.New(), TenantID: "acme"}, member, true},
+		{"other tenant", dto.PresenceViewer{ID: uuid.New(), TenantID: "globex"}, member, false},
+		{"no tenant", dto.PresenceViewer{ID: uuid.New()}, loner, false},
+		{"guest in the tenant", dto.PresenceViewer{ID: uuid.New(), TenantID: "acme", Guest: true}, member, false},
+		{"admin", dto.PresenceViewer{ID: uuid.New(), Admin: true}, member, true},
+	} {
+		if got := canSeePresence(tc.viewer, tc.target); got != tc.want {
+			t.Errorf("%s: canSeePresence = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
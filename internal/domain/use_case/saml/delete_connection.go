@@ -0,0 +1,26 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type DeleteConnectionUseCase struct {
+	connectionRepo contract.SAMLConnectionRepository
+}
+
+func NewDeleteConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *DeleteConnectionUseCase {
+	return &DeleteConnectionUseCase{connectionRepo: connectionRepo}
+}
+
+// Execute stops SSO for the tenant. Sessions already started through the
+// connection stay valid until revoked.
+func (uc *DeleteConnectionUseCase) Execute(ctx context.Context, id uuid.UUID) (err error) {
+	defer instrument.Observe("saml.delete_connection", time.Now(), &err)
+
+	return uc.connectionRepo.Delete(ctx, id)
+}
@@ -0,0 +1,24 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListConnectionsUseCase struct {
+	connectionRepo contract.SAMLConnectionRepository
+}
+
+func NewListConnectionsUseCase(connectionRepo contract.SAMLConnectionRepository) *ListConnectionsUseCase {
+	return &ListConnectionsUseCase{connectionRepo: connectionRepo}
+}
+
+func (uc *ListConnectionsUseCase) Execute(ctx context.Context) (_ []*entity.SAMLConnection, err error) {
+	defer instrument.Observe("saml.list_connections", time.Now(), &err)
+
+	return uc.connectionRepo.List(ctx)
+}
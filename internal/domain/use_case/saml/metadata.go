@@ -0,0 +1,30 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type MetadataUseCase struct {
+	connectionRepo  contract.SAMLConnectionRepository
+	serviceProvider contract.SAMLServiceProvider
+}
+
+func NewMetadataUseCase(connectionRepo contract.SAMLConnectionRepository, serviceProvider contract.SAMLServiceProvider) *MetadataUseCase {
+	return &MetadataUseCase{connectionRepo: connectionRepo, serviceProvider: serviceProvider}
+}
+
+// Execute returns the service provider metadata for the tenant's identity
+// provider administrators.
+func (uc *MetadataUseCase) Execute(ctx context.Context, tenant string) (_ []byte, err error) {
+	defer instrument.Observe("saml.metadata", time.Now(), &err)
+
+	conn, err := uc.connectionRepo.GetByTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return uc.serviceProvider.Metadata(conn)
+}
@@ -0,0 +1,45 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RegisterConnectionUseCase struct {
+	connectionRepo contract.SAMLConnectionRepository
+}
+
+func NewRegisterConnectionUseCase(connectionRepo contract.SAMLConnectionRepository) *RegisterConnectionUseCase {
+	return &RegisterConnectionUseCase{connectionRepo: connectionRepo}
+}
+
+func (uc *RegisterConnectionUseCase) Execute(ctx context.Context, input *dto.RegisterSAMLConnectionInput) (_ *entity.SAMLConnection, err error) {
+	defer instrument.Observe("saml.register_connection", time.Now(), &err)
+
+	if err := entity.ValidateTenant(input.Tenant); err != nil {
+		return nil, err
+	}
+
+	conn := &entity.SAMLConnection{
+		Tenant:            input.Tenant,
+		IdPEntityID:       input.IdPEntityID,
+		IdPSSOURL:         input.IdPSSOURL,
+		IdPCertificate:    input.IdPCertificate,
+		EmailAttribute:    input.EmailAttribute,
+		UsernameAttribute: input.UsernameAttribute,
+		JITProvisioning:   input.JITProvisioning,
+		CreatedBy:         input.CreatedBy,
+		CreatedAt:         time.Now().UTC(),
+	}
+	// Reject certificates that would make every sign-in fail.
+	if _, err := conn.Certificate(); err != nil {
+		return nil, err
+	}
+
+	return uc.connectionRepo.Create(ctx, conn)
+}
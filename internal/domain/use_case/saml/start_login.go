@@ -0,0 +1,84 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+type StartLoginUseCase struct {
+	connectionRepo  contract.SAMLConnectionRepository
+	settingsRepo    contract.OrganizationSettingsRepository
+	serviceProvider contract.SAMLServiceProvider
+	loginStateRepo  contract.LoginStateRepository
+	stateTTL        time.Duration
+}
+
+func NewStartLoginUseCase(
+	connectionRepo contract.SAMLConnectionRepository,
+	settingsRepo contract.OrganizationSettingsRepository,
+	serviceProvider contract.SAMLServiceProvider,
+	loginStateRepo contract.LoginStateRepository,
+	stateTTL time.Duration,
+) *StartLoginUseCase {
+	return &StartLoginUseCase{
+		connectionRepo:  connectionRepo,
+		settingsRepo:    settingsRepo,
+		serviceProvider: serviceProvider,
+		loginStateRepo:  loginStateRepo,
+		stateTTL:        stateTTL,
+	}
+}
+
+// Execute returns the identity provider URL that starts a service
+// provider-initiated sign-in, and the login state the browser must keep
+// until the response comes back. returnState is handed back once the
+// sign-in completes, on the page returnTo when it is set, which must be on
+// one of the tenant's allowed callback domains.
+func (uc *StartLoginUseCase) Execute(ctx context.Context, tenant, returnState, returnTo string) (_ *dto.SAMLLogin, err error) {
+	defer instrument.Observe("saml.start_login", time.Now(), &err)
+
+	conn, err := uc.connectionRepo.GetByTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if returnTo != "" {
+		settings, err := uc.settingsRepo.Get(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		if !settings.AllowsCallback(returnTo) {
+			return nil, errs.ErrCallbackNotAllowed
+		}
+	}
+
+	state, err := securetoken.New(20)
+	if err != nil {
+		return nil, err
+	}
+	target, requestID, err := uc.serviceProvider.LoginURL(conn, state)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ls := &entity.LoginState{
+		State:       state,
+		Provider:    entity.SAMLLoginProvider(tenant),
+		Nonce:       requestID,
+		ReturnState: returnState,
+		ReturnTo:    returnTo,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(uc.stateTTL),
+	}
+	if err := uc.loginStateRepo.Save(ctx, ls); err != nil {
+		return nil, err
+	}
+	return &dto.SAMLLogin{URL: target, State: state, ExpiresAt: ls.ExpiresAt}, nil
+}
@@ -0,0 +1,66 @@
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type CreateAccountUseCase struct {
+	accountRepo    contract.ServiceAccountRepository
+	connectionRepo contract.SAMLConnectionRepository
+	auditLogRepo   contract.AuditLogRepository
+}
+
+func NewCreateAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	connectionRepo contract.SAMLConnectionRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *CreateAccountUseCase {
+	return &CreateAccountUseCase{accountRepo: accountRepo, connectionRepo: connectionRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute creates a service account in an organization, which must have a
+// SAML connection, as tenants only exist through one.
+func (uc *CreateAccountUseCase) Execute(ctx context.Context, input *dto.CreateServiceAccountInput) (_ *entity.ServiceAccount, err error) {
+	defer instrument.Observe("service_account.create_account", time.Now(), &err)
+
+	if err := entity.ValidateTenant(input.Tenant); err != nil {
+		return nil, err
+	}
+	if err := entity.ValidateRoles(input.Roles); err != nil {
+		return nil, err
+	}
+	if _, err := uc.connectionRepo.GetByTenant(ctx, input.Tenant); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	a, err := uc.accountRepo.Create(ctx, &entity.ServiceAccount{
+		Tenant:      input.Tenant,
+		Name:        input.Name,
+		Description: input.Description,
+		Roles:       input.Roles,
+		CreatedBy:   input.CreatedBy,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_SERVICE_ACCOUNT_CREATED,
+		ActorID:   input.CreatedBy,
+		SubjectID: a.ID,
+		Detail:    a.Tenant + "/" + a.Name,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
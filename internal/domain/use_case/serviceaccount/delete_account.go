@@ -0,0 +1,67 @@
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type DeleteAccountUseCase struct {
+	accountRepo  contract.ServiceAccountRepository
+	keyRepo      contract.ServiceAccountKeyRepository
+	auditLogRepo contract.AuditLogRepository
+}
+
+func NewDeleteAccountUseCase(
+	accountRepo contract.ServiceAccountRepository,
+	keyRepo contract.ServiceAccountKeyRepository,
+	auditLogRepo contract.AuditLogRepository,
+) *DeleteAccountUseCase {
+	return &DeleteAccountUseCase{accountRepo: accountRepo, keyRepo: keyRepo, auditLogRepo: auditLogRepo}
+}
+
+// Execute deletes a service account and revokes its keys. The account is
+// kept, marked deleted, so audit events can still be traced to it.
+func (uc *DeleteAccountUseCase) Execute(ctx context.Context, actorID, accountID uuid.UUID) (err error) {
+	defer instrument.Observe("service_account.delete_account", time.Now(), &err)
+
+	a, err := uc.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if !a.IsActive() {
+		return errs.ErrServiceAccountNotFound
+	}
+
+	now := time.Now().UTC()
+	a.DeletedAt = &now
+	if _, err := uc.accountRepo.Update(ctx, a); err != nil {
+		return err
+	}
+
+	keys, err := uc.keyRepo.ListByAccount(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.RevokedAt == nil {
+			if err := uc.keyRepo.Revoke(ctx, k.ID, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = uc.auditLogRepo.Append(ctx, &entity.AuditEvent{
+		Action:    entity.AUDIT_SERVICE_ACCOUNT_DELETED,
+		ActorID:   actorID,
+		SubjectID: a.ID,
+		Detail:    a.Tenant + "/" + a.Name,
+		CreatedAt: now,
+	})
+	return err
+}
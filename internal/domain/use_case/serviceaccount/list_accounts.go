@@ -0,0 +1,26 @@
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListAccountsUseCase struct {
+	accountRepo contract.ServiceAccountRepository
+}
+
+func NewListAccountsUseCase(accountRepo contract.ServiceAccountRepository) *ListAccountsUseCase {
+	return &ListAccountsUseCase{accountRepo: accountRepo}
+}
+
+// Execute lists the service accounts of tenant, or of every tenant when it
+// is empty.
+func (uc *ListAccountsUseCase) Execute(ctx context.Context, tenant string) (_ []*entity.ServiceAccount, err error) {
+	defer instrument.Observe("service_account.list_accounts", time.Now(), &err)
+
+	return uc.accountRepo.List(ctx, tenant)
+}
@@ -0,0 +1,31 @@
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListKeysUseCase struct {
+	accountRepo contract.ServiceAccountRepository
+	keyRepo     contract.ServiceAccountKeyRepository
+}
+
+func NewListKeysUseCase(accountRepo contract.ServiceAccountRepository, keyRepo contract.ServiceAccountKeyRepository) *ListKeysUseCase {
+	return &ListKeysUseCase{accountRepo: accountRepo, keyRepo: keyRepo}
+}
+
+// Execute lists the keys of a service account, deleted or not, newest
+// first.
+func (uc *ListKeysUseCase) Execute(ctx context.Context, accountID uuid.UUID) (_ []*entity.ServiceAccountKey, err error) {
+	defer instrument.Observe("service_account.list_keys", time.Now(), &err)
+
+	if _, err := uc.accountRepo.GetByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return uc.keyRepo.ListByAccount(ctx, accountID)
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/clientinfo"
+)
+
+type ListSessionsUseCase struct {
+	sessionRepo contract.SessionRepository
+}
+
+func NewListSessionsUseCase(sessionRepo contract.SessionRepository) *ListSessionsUseCase {
+	return &ListSessionsUseCase{sessionRepo: sessionRepo}
+}
+
+// Execute lists the user's active sessions, flagging the one making the call.
+func (uc *ListSessionsUseCase) Execute(ctx context.Context, userID, currentSessionID uuid.UUID) (_ []dto.SessionView, err error) {
+	defer instrument.Observe("session.list_sessions", time.Now(), &err)
+
+	sessions, err := uc.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]dto.SessionView, len(sessions))
+	for i, s := range sessions {
+		views[i] = dto.SessionView{Session: s, Current: s.ID == currentSessionID, Device: clientinfo.Parse(s.UserAgent)}
+	}
+	return views, nil
+}
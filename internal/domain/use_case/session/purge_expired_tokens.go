@@ -0,0 +1,59 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	accountUseCase "github.com/haidang666/go-app/internal/domain/use_case/account"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type PurgeExpiredTokensUseCase struct {
+	sessionRepo     contract.SessionRepository
+	resetRepo       contract.PasswordResetRepository
+	codeRepo        contract.AuthorizationCodeRepository
+	deletedAccounts *accountUseCase.PurgeDeletedAccountsUseCase
+}
+
+func NewPurgeExpiredTokensUseCase(
+	sessionRepo contract.SessionRepository,
+	resetRepo contract.PasswordResetRepository,
+	codeRepo contract.AuthorizationCodeRepository,
+	deletedAccounts *accountUseCase.PurgeDeletedAccountsUseCase,
+) *PurgeExpiredTokensUseCase {
+	return &PurgeExpiredTokensUseCase{sessionRepo: sessionRepo, resetRepo: resetRepo, codeRepo: codeRepo, deletedAccounts: deletedAccounts}
+}
+
+// Execute deletes the sessions, password resets and authorization codes
+// that expired more than olderThan ago; the ones expired more recently are
+// kept for audits. The deleted accounts whose recovery window ended are
+// purged regardless.
+func (uc *PurgeExpiredTokensUseCase) Execute(ctx context.Context, olderThan time.Duration) (_ *dto.PurgeExpiredTokensResult, err error) {
+	defer instrument.Observe("session.purge_expired_tokens", time.Now(), &err)
+
+	cutoff := time.Now().UTC().Add(-olderThan)
+	sessions, err := uc.sessionRepo.DeleteExpired(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	resets, err := uc.resetRepo.DeleteExpired(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := uc.codeRepo.DeleteExpired(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := uc.deletedAccounts.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PurgeExpiredTokensResult{
+		Sessions:           sessions,
+		PasswordResets:     resets,
+		AuthorizationCodes: codes,
+		DeletedAccounts:    accounts,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RevokeAllSessionsUseCase struct {
+	sessionRepo contract.SessionRepository
+}
+
+func NewRevokeAllSessionsUseCase(sessionRepo contract.SessionRepository) *RevokeAllSessionsUseCase {
+	return &RevokeAllSessionsUseCase{sessionRepo: sessionRepo}
+}
+
+// Execute signs the user out everywhere, including the calling session, and
+// returns how many sessions were revoked.
+func (uc *RevokeAllSessionsUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ int, err error) {
+	defer instrument.Observe("session.revoke_all_sessions", time.Now(), &err)
+
+	return uc.sessionRepo.RevokeAllByUser(ctx, userID, time.Now().UTC())
+}
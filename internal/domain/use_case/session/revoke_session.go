@@ -0,0 +1,34 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RevokeSessionUseCase struct {
+	sessionRepo contract.SessionRepository
+}
+
+func NewRevokeSessionUseCase(sessionRepo contract.SessionRepository) *RevokeSessionUseCase {
+	return &RevokeSessionUseCase{sessionRepo: sessionRepo}
+}
+
+// Execute signs out one of the user's sessions. Sessions of other users are
+// reported as not found.
+func (uc *RevokeSessionUseCase) Execute(ctx context.Context, userID, sessionID uuid.UUID) (err error) {
+	defer instrument.Observe("session.revoke_session", time.Now(), &err)
+
+	s, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if s.UserID != userID {
+		return errs.ErrSessionNotFound
+	}
+	return uc.sessionRepo.Revoke(ctx, sessionID, time.Now().UTC())
+}
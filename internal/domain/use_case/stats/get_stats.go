@@ -0,0 +1,46 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetStatsUseCase struct {
+	statsRepo contract.StatsRepository
+	refresh   *RefreshStatsUseCase
+	maxAge    time.Duration
+}
+
+func NewGetStatsUseCase(statsRepo contract.StatsRepository, refresh *RefreshStatsUseCase, maxAge time.Duration) *GetStatsUseCase {
+	return &GetStatsUseCase{statsRepo: statsRepo, refresh: refresh, maxAge: maxAge}
+}
+
+// Execute serves the stats as last refreshed. It reads through to a
+// refresh when they were never computed or are older than maxAge, e.g.
+// right after a first deploy or while no leader refreshes them.
+func (uc *GetStatsUseCase) Execute(ctx context.Context) (_ *dto.AdminStats, err error) {
+	defer instrument.Observe("stats.get_stats", time.Now(), &err)
+
+	view, err := uc.statsRepo.Get(ctx, dto.STATS_VIEW_USERS)
+	switch {
+	case errors.Is(err, errs.ErrStatsViewNotFound):
+		return uc.refresh.Execute(ctx)
+	case err != nil:
+		return nil, err
+	case uc.maxAge > 0 && time.Since(view.RefreshedAt) > uc.maxAge:
+		return uc.refresh.Execute(ctx)
+	}
+
+	stats := &dto.AdminStats{RefreshedAt: view.RefreshedAt}
+	if err := json.Unmarshal(view.Data, &stats.Users); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// refreshChunkSize is the number of users a refresh reads at a time.
+const refreshChunkSize = 1000
+
+type RefreshStatsUseCase struct {
+	userQuery        contract.UserQuery
+	loginAttemptRepo contract.LoginAttemptRepository
+	statsRepo        contract.StatsRepository
+	signUpDays       int
+}
+
+func NewRefreshStatsUseCase(
+	userQuery contract.UserQuery,
+	loginAttemptRepo contract.LoginAttemptRepository,
+	statsRepo contract.StatsRepository,
+	signUpDays int,
+) *RefreshStatsUseCase {
+	return &RefreshStatsUseCase{
+		userQuery:        userQuery,
+		loginAttemptRepo: loginAttemptRepo,
+		statsRepo:        statsRepo,
+		signUpDays:       max(signUpDays, 1),
+	}
+}
+
+// Execute recomputes the user stats, walking the whole user read model a
+// chunk at a time, and stores them as the users stats view.
+func (uc *RefreshStatsUseCase) Execute(ctx context.Context) (_ *dto.AdminStats, err error) {
+	defer instrument.Observe("stats.refresh_stats", time.Now(), &err)
+
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	firstDay := today.AddDate(0, 0, 1-uc.signUpDays)
+
+	users := dto.UserStats{
+		ByStatus: make(map[string]int),
+		ByPlan:   make(map[string]int),
+		SignUps:  make([]dto.DailyCount, uc.signUpDays),
+	}
+	for i := range users.SignUps {
+		users.SignUps[i].Date = firstDay.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+
+	var after *dto.ExportCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk, err := uc.userQuery.ListAfter(ctx, dto.UserFilter{}, after, refreshChunkSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range chunk {
+			users.Total++
+			if u.IsGuest {
+				users.Guests++
+			}
+			users.ByStatus[u.Status]++
+			users.ByPlan[u.Plan]++
+			if !u.CreatedAt.Before(firstDay) {
+				if day := int(u.CreatedAt.Sub(firstDay) / (24 * time.Hour)); day < len(users.SignUps) {
+					users.SignUps[day].Count++
+				}
+			}
+		}
+		if len(chunk) < refreshChunkSize {
+			break
+		}
+		last := chunk[len(chunk)-1]
+		after = &dto.ExportCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	active, err := uc.loginAttemptRepo.CountActiveUsers(ctx, now.AddDate(0, 0, -1), now.AddDate(0, 0, -7), now.AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+	users.Active = dto.ActiveUsers{Day: active[0], Week: active[1], Month: active[2]}
+
+	data, err := json.Marshal(users)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.statsRepo.Put(ctx, &entity.StatsView{Name: dto.STATS_VIEW_USERS, Data: data, RefreshedAt: now}); err != nil {
+		return nil, err
+	}
+	return &dto.AdminStats{RefreshedAt: now, Users: users}, nil
+}
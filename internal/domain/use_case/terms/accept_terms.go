@@ -0,0 +1,37 @@
+package terms
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type AcceptTermsUseCase struct {
+	termsRepo contract.TermsAcceptanceRepository
+	current   entity.TermsVersions
+}
+
+func NewAcceptTermsUseCase(termsRepo contract.TermsAcceptanceRepository, current entity.TermsVersions) *AcceptTermsUseCase {
+	return &AcceptTermsUseCase{termsRepo: termsRepo, current: current}
+}
+
+// Execute records acceptance. The client must echo the current versions so a
+// stale form cannot accept documents the user never saw.
+func (uc *AcceptTermsUseCase) Execute(ctx context.Context, userID uuid.UUID, accepted entity.TermsVersions, ip string) (_ *entity.TermsAcceptance, err error) {
+	defer instrument.Observe("terms.accept_terms", time.Now(), &err)
+
+	if accepted != uc.current {
+		return nil, errs.ErrTermsOutdated
+	}
+	return uc.termsRepo.Create(ctx, &entity.TermsAcceptance{
+		UserID:        userID,
+		TermsVersions: accepted,
+		IP:            ip,
+		AcceptedAt:    time.Now().UTC(),
+	})
+}
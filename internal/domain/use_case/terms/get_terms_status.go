@@ -0,0 +1,41 @@
+package terms
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetTermsStatusUseCase struct {
+	termsRepo contract.TermsAcceptanceRepository
+	current   entity.TermsVersions
+}
+
+func NewGetTermsStatusUseCase(termsRepo contract.TermsAcceptanceRepository, current entity.TermsVersions) *GetTermsStatusUseCase {
+	return &GetTermsStatusUseCase{termsRepo: termsRepo, current: current}
+}
+
+func (uc *GetTermsStatusUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ *dto.TermsStatus, err error) {
+	defer instrument.Observe("terms.get_terms_status", time.Now(), &err)
+
+	status := &dto.TermsStatus{Current: uc.current}
+
+	latest, err := uc.termsRepo.Latest(ctx, userID)
+	if errors.Is(err, errs.ErrTermsNotAccepted) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.Accepted = latest
+	status.UpToDate = latest.TermsVersions == uc.current
+	return status, nil
+}
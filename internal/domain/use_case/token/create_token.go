@@ -0,0 +1,72 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// maxActiveTokens bounds the unrevoked, unexpired tokens of one user.
+const maxActiveTokens = 50
+
+type CreateTokenUseCase struct {
+	tokenRepo contract.PersonalAccessTokenRepository
+}
+
+func NewCreateTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *CreateTokenUseCase {
+	return &CreateTokenUseCase{tokenRepo: tokenRepo}
+}
+
+func (uc *CreateTokenUseCase) Execute(ctx context.Context, input *dto.CreatePersonalTokenInput) (_ *dto.CreatedPersonalToken, err error) {
+	defer instrument.Observe("token.create_token", time.Now(), &err)
+
+	now := time.Now().UTC()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, errs.ErrPersonalTokenExpiry
+	}
+
+	existing, err := uc.tokenRepo.ListByUser(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, t := range existing {
+		if t.IsActive(now) {
+			active++
+		}
+	}
+	if active >= maxActiveTokens {
+		return nil, errs.ErrPersonalTokenLimit
+	}
+
+	secret, err := securetoken.New(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := entity.PERSONAL_TOKEN_PREFIX + secret
+
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		at := input.ExpiresAt.UTC()
+		expiresAt = &at
+	}
+	t, err := uc.tokenRepo.Create(ctx, &entity.PersonalAccessToken{
+		UserID:    input.UserID,
+		Name:      input.Name,
+		Hint:      plain[:len(entity.PERSONAL_TOKEN_PREFIX)+6],
+		TokenHash: securetoken.Hash(plain),
+		Scopes:    input.Scopes,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CreatedPersonalToken{PersonalAccessToken: t, Token: plain}, nil
+}
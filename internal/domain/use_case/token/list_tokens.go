@@ -0,0 +1,37 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ListTokensUseCase struct {
+	tokenRepo contract.PersonalAccessTokenRepository
+}
+
+func NewListTokensUseCase(tokenRepo contract.PersonalAccessTokenRepository) *ListTokensUseCase {
+	return &ListTokensUseCase{tokenRepo: tokenRepo}
+}
+
+// Execute lists the user's tokens that are neither revoked nor expired.
+func (uc *ListTokensUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ []*entity.PersonalAccessToken, err error) {
+	defer instrument.Observe("token.list_tokens", time.Now(), &err)
+
+	tokens, err := uc.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := make([]*entity.PersonalAccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		if t.IsActive(now) {
+			active = append(active, t)
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type RevokeTokenUseCase struct {
+	tokenRepo contract.PersonalAccessTokenRepository
+}
+
+func NewRevokeTokenUseCase(tokenRepo contract.PersonalAccessTokenRepository) *RevokeTokenUseCase {
+	return &RevokeTokenUseCase{tokenRepo: tokenRepo}
+}
+
+// Execute revokes one of the user's tokens; it stops working on its next
+// use. Tokens of other users are reported as not found.
+func (uc *RevokeTokenUseCase) Execute(ctx context.Context, userID, tokenID uuid.UUID) (err error) {
+	defer instrument.Observe("token.revoke_token", time.Now(), &err)
+
+	t, err := uc.tokenRepo.GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if t.UserID != userID {
+		return errs.ErrPersonalTokenNotFound
+	}
+	return uc.tokenRepo.Revoke(ctx, tokenID, time.Now().UTC())
+}
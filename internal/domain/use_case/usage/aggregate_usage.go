@@ -0,0 +1,42 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+// AggregateUsageUseCase folds usage events from the event bus into the
+// per-period totals.
+type AggregateUsageUseCase struct {
+	usageRepo contract.UsageRepository
+}
+
+func NewAggregateUsageUseCase(usageRepo contract.UsageRepository) *AggregateUsageUseCase {
+	return &AggregateUsageUseCase{usageRepo: usageRepo}
+}
+
+// Execute adds the event to the period it occurred in, so events delivered
+// late still land in the right period.
+func (uc *AggregateUsageUseCase) Execute(ctx context.Context, e dto.UsageEvent) (err error) {
+	defer instrument.Observe("usage.aggregate_usage", time.Now(), &err)
+
+	if !entity.ValidUsageMetric(e.Metric) {
+		return fmt.Errorf("%w: %q", errs.ErrUnknownUsageMetric, e.Metric)
+	}
+	if e.Quantity < 0 {
+		return errs.ErrInvalidUsageQuantity
+	}
+
+	start, _ := entity.UsagePeriod(e.OccurredAt)
+	if entity.UsageIsPeak(e.Metric) {
+		return uc.usageRepo.RaiseTo(ctx, e.UserID, e.Metric, start, e.Quantity)
+	}
+	return uc.usageRepo.Add(ctx, e.UserID, e.Metric, start, e.Quantity)
+}
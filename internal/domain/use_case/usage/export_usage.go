@@ -0,0 +1,32 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type ExportUsageUseCase struct {
+	usageRepo contract.UsageRepository
+}
+
+func NewExportUsageUseCase(usageRepo contract.UsageRepository) *ExportUsageUseCase {
+	return &ExportUsageUseCase{usageRepo: usageRepo}
+}
+
+// Execute returns every user's usage for the period containing at. A period
+// is only final once it has ended.
+func (uc *ExportUsageUseCase) Execute(ctx context.Context, at time.Time) (_ *dto.UsageExport, err error) {
+	defer instrument.Observe("usage.export_usage", time.Now(), &err)
+
+	start, end := entity.UsagePeriod(at)
+	records, err := uc.usageRepo.ListByPeriod(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UsageExport{PeriodStart: start, PeriodEnd: end, Records: records}, nil
+}
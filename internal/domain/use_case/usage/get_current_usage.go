@@ -0,0 +1,45 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type GetCurrentUsageUseCase struct {
+	usageRepo contract.UsageRepository
+}
+
+func NewGetCurrentUsageUseCase(usageRepo contract.UsageRepository) *GetCurrentUsageUseCase {
+	return &GetCurrentUsageUseCase{usageRepo: usageRepo}
+}
+
+// Execute returns the user's usage so far in the current period, with every
+// metric present. Recent events may still be in flight on the event bus.
+func (uc *GetCurrentUsageUseCase) Execute(ctx context.Context, userID uuid.UUID) (_ *dto.UsageSummary, err error) {
+	defer instrument.Observe("usage.get_current_usage", time.Now(), &err)
+
+	start, end := entity.UsagePeriod(time.Now())
+	records, err := uc.usageRepo.ListByUser(ctx, userID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.UsageSummary{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Usage:       make(map[string]int64, len(entity.UsageMetrics)),
+	}
+	for _, metric := range entity.UsageMetrics {
+		summary.Usage[metric] = 0
+	}
+	for _, rec := range records {
+		summary.Usage[rec.Metric] = rec.Quantity
+	}
+	return summary, nil
+}
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type CheckUsernameUseCase struct {
+	userRepo contract.UserRepository
+}
+
+func NewCheckUsernameUseCase(userRepo contract.UserRepository) *CheckUsernameUseCase {
+	return &CheckUsernameUseCase{userRepo: userRepo}
+}
+
+// Execute reports whether name passes validation and is unclaimed. Only
+// repository failures are returned as errors.
+func (uc *CheckUsernameUseCase) Execute(ctx context.Context, name string) (_ *dto.UsernameAvailability, err error) {
+	defer instrument.Observe("user.check_username", time.Now(), &err)
+
+	name = entity.NormalizeUsername(name)
+	result := &dto.UsernameAvailability{Username: name}
+
+	if err := entity.ValidateUsername(name); err != nil {
+		result.Reason = err.Error()
+		return result, nil
+	}
+
+	_, err = uc.userRepo.GetByUsername(ctx, name)
+	switch {
+	case errors.Is(err, errs.ErrUserNotFound):
+		result.Available = true
+	case err != nil:
+		return nil, err
+	default:
+		result.Reason = errs.ErrUsernameTaken.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/use_case/instrument"
+)
+
+type UpdateUserUseCase struct {
+	userRepo       contract.UserRepository
+	hasher         contract.PasswordHasher
+	passwordPolicy contract.PasswordPolicy
+}
+
+func NewUpdateUserUseCase(
+	userRepo contract.UserRepository,
+	hasher contract.PasswordHasher,
+	passwordPolicy contract.PasswordPolicy,
+) *UpdateUserUseCase {
+	return &UpdateUserUseCase{userRepo: userRepo, hasher: hasher, passwordPolicy: passwordPolicy}
+}
+
+// Execute applies the non-empty fields of input to the stored user.
+func (uc *UpdateUserUseCase) Execute(ctx context.Context, input *dto.UpdateUserInput) (_ *entity.User, err error) {
+	defer instrument.Observe("user.update_user", time.Now(), &err)
+
+	du, err := uc.userRepo.GetByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Email != "" {
+		du.Email = input.Email
+	}
+	if input.Password != "" {
+		if err := uc.passwordPolicy.CheckPassword(ctx, input.Password, du.Email, du.Username); err != nil {
+			return nil, err
+		}
+		hashed, err := uc.hasher.Hash(ctx, input.Password)
+		if err != nil {
+			return nil, err
+		}
+		du.SetPassword(hashed, time.Now().UTC())
+	}
+
+	if err := du.Validate(); err != nil {
+		return nil, err
+	}
+	return uc.userRepo.Update(ctx, du)
+}
@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type KafkaSinkArgs struct {
+	// RESTURL is the base URL of a Kafka REST Proxy (v2 API).
+	RESTURL string
+	Topic   string
+	Timeout time.Duration
+}
+
+// KafkaSink produces events to a Kafka topic through a REST Proxy, as JSON
+// records keyed by user so one user's events stay in order on a partition.
+type KafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+var _ Sink = (*KafkaSink)(nil)
+
+func NewKafkaSink(args KafkaSinkArgs) (*KafkaSink, error) {
+	if args.RESTURL == "" || args.Topic == "" {
+		return nil, fmt.Errorf("kafka needs a REST proxy URL and a topic")
+	}
+	return &KafkaSink{
+		endpoint: strings.TrimSuffix(args.RESTURL, "/") + "/topics/" + url.PathEscape(args.Topic),
+		client:   &http.Client{Timeout: args.Timeout},
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string             `json:"key,omitempty"`
+	Value dto.AnalyticsEvent `json:"value"`
+}
+
+func (s *KafkaSink) Send(ctx context.Context, events []dto.AnalyticsEvent) error {
+	records := make([]kafkaRecord, 0, len(events))
+	for _, e := range events {
+		r := kafkaRecord{Value: e}
+		if e.UserID != uuid.Nil {
+			r.Key = e.UserID.String()
+		}
+		records = append(records, r)
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("kafka: status %d: %s", res.StatusCode, msg)
+	}
+	// The proxy answers 200 even when some records failed.
+	var reply struct {
+		Offsets []struct {
+			Error string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	for _, o := range reply.Offsets {
+		if o.Error != "" {
+			return fmt.Errorf("kafka: %s", o.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// PostgresSink appends events to the analytics_events table, for querying
+// them with SQL or loading them into a warehouse:
+//
+//	CREATE TABLE analytics_events (
+//		id          BIGSERIAL PRIMARY KEY,
+//		name        TEXT NOT NULL,
+//		user_id     UUID,
+//		tenant_id   TEXT,
+//		properties  JSONB NOT NULL DEFAULT '{}',
+//		occurred_at TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE INDEX analytics_events_name_idx ON analytics_events (name, occurred_at);
+type PostgresSink struct {
+	db *sql.DB
+}
+
+var _ Sink = (*PostgresSink)(nil)
+
+func NewPostgresSink(db *sql.DB) *PostgresSink {
+	return &PostgresSink{db: db}
+}
+
+// Send inserts the batch in one statement.
+func (s *PostgresSink) Send(ctx context.Context, events []dto.AnalyticsEvent) error {
+	var query strings.Builder
+	query.WriteString(`INSERT INTO analytics_events (name, user_id, tenant_id, properties, occurred_at) VALUES `)
+	args := make([]any, 0, 5*len(events))
+	for i, e := range events {
+		props, err := json.Marshal(e.Properties)
+		if err != nil {
+			return fmt.Errorf("analytics event %s: %w", e.Name, err)
+		}
+		if e.Properties == nil {
+			props = []byte("{}")
+		}
+		userID := uuid.NullUUID{UUID: e.UserID, Valid: e.UserID != uuid.Nil}
+		tenantID := sql.NullString{String: e.TenantID, Valid: e.TenantID != ""}
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, e.Name, userID, tenantID, string(props), e.OccurredAt)
+	}
+	if _, err := s.db.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("insert analytics events: %w", err)
+	}
+	return nil
+}
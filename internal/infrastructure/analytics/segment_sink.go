@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+const segmentAPIURL = "https://api.segment.io"
+
+type SegmentSinkArgs struct {
+	WriteKey string
+	// BaseURL overrides the Segment API, e.g. for a regional endpoint.
+	BaseURL string
+	Timeout time.Duration
+}
+
+// SegmentSink sends events to Segment's batch API as track calls. Events
+// without a user are tracked under an anonymous ID, and the tenant, when
+// set, is the call's group.
+type SegmentSink struct {
+	writeKey string
+	baseURL  string
+	client   *http.Client
+}
+
+var _ Sink = (*SegmentSink)(nil)
+
+func NewSegmentSink(args SegmentSinkArgs) (*SegmentSink, error) {
+	if args.WriteKey == "" {
+		return nil, fmt.Errorf("segment needs a write key")
+	}
+	baseURL := args.BaseURL
+	if baseURL == "" {
+		baseURL = segmentAPIURL
+	}
+	return &SegmentSink{writeKey: args.WriteKey, baseURL: baseURL, client: &http.Client{Timeout: args.Timeout}}, nil
+}
+
+type segmentTrack struct {
+	Type        string         `json:"type"`
+	Event       string         `json:"event"`
+	UserID      string         `json:"userId,omitempty"`
+	AnonymousID string         `json:"anonymousId,omitempty"`
+	Properties  map[string]any `json:"properties,omitempty"`
+	Context     map[string]any `json:"context,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+func (s *SegmentSink) Send(ctx context.Context, events []dto.AnalyticsEvent) error {
+	batch := make([]segmentTrack, 0, len(events))
+	for _, e := range events {
+		call := segmentTrack{Type: "track", Event: e.Name, Properties: e.Properties, Timestamp: e.OccurredAt}
+		if e.UserID == uuid.Nil {
+			// Segment requires one of the two IDs.
+			call.AnonymousID = uuid.NewString()
+		} else {
+			call.UserID = e.UserID.String()
+		}
+		if e.TenantID != "" {
+			call.Context = map[string]any{"groupId": e.TenantID}
+		}
+		batch = append(batch, call)
+	}
+	body, err := json.Marshal(map[string]any{"batch": batch})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v1/batch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(s.writeKey, "")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("segment: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("segment: status %d: %s", res.StatusCode, msg)
+	}
+	return nil
+}
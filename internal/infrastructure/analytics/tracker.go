@@ -0,0 +1,176 @@
+package analytics
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var eventsTotal = metrics.NewCounter("analytics_events_total",
+	"Analytics events by name and outcome (sent, dropped, failed).", "event", "outcome")
+
+// Sink delivers a batch of events to an analytics backend. It must not keep
+// the slice after returning.
+type Sink interface {
+	Send(ctx context.Context, events []dto.AnalyticsEvent) error
+}
+
+type BatchingTrackerArgs struct {
+	Sink Sink
+	// Buffer is how many events may wait to be sent before Track drops.
+	Buffer    int
+	BatchSize int
+	// FlushInterval is the longest an event waits for its batch to fill.
+	FlushInterval time.Duration
+	// SendTimeout bounds each delivery to the sink.
+	SendTimeout time.Duration
+}
+
+// BatchingTracker queues events and sends them to its sink in batches from
+// a single goroutine, so a slow backend never holds up a request. Batches
+// that fail are dropped rather than retried: analytics tolerate gaps better
+// than a growing backlog.
+type BatchingTracker struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	sendTimeout   time.Duration
+	queue         chan dto.AnalyticsEvent
+	done          chan struct{}
+	// mu guards closed, so Track never sends on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ contract.AnalyticsTracker = (*BatchingTracker)(nil)
+
+func NewBatchingTracker(args BatchingTrackerArgs) *BatchingTracker {
+	t := &BatchingTracker{
+		sink:          args.Sink,
+		batchSize:     max(args.BatchSize, 1),
+		flushInterval: args.FlushInterval,
+		sendTimeout:   args.SendTimeout,
+		queue:         make(chan dto.AnalyticsEvent, args.Buffer),
+		done:          make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Track queues e, adding the request's metadata, such as the country, to
+// the properties it does not set.
+func (t *BatchingTracker) Track(ctx context.Context, e dto.AnalyticsEvent) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	e.Properties = withMeta(ctx, e.Properties)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		eventsTotal.Inc(e.Name, "dropped")
+		return
+	}
+	select {
+	case t.queue <- e:
+	default:
+		eventsTotal.Inc(e.Name, "dropped")
+	}
+}
+
+// Pending returns how many tracked events are queued, not counting the
+// batch being filled.
+func (t *BatchingTracker) Pending() int {
+	return len(t.queue)
+}
+
+// Close stops accepting events and sends the queued ones, waiting until ctx
+// is done at most.
+func (t *BatchingTracker) Close(ctx context.Context) error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *BatchingTracker) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]dto.AnalyticsEvent, 0, t.batchSize)
+	for {
+		select {
+		case e, ok := <-t.queue:
+			if !ok {
+				t.send(batch)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) < t.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		t.send(batch)
+		batch = batch[:0]
+	}
+}
+
+func (t *BatchingTracker) send(batch []dto.AnalyticsEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.sendTimeout)
+	defer cancel()
+
+	outcome := "sent"
+	if err := t.sink.Send(ctx, batch); err != nil {
+		outcome = "failed"
+		logger.Sampled("analytics.send", 100).Warnw("send analytics events", "events", len(batch), "error", err)
+	}
+	for _, e := range batch {
+		eventsTotal.Inc(e.Name, outcome)
+	}
+}
+
+// NopTracker drops every event, for when analytics are disabled.
+type NopTracker struct{}
+
+var _ contract.AnalyticsTracker = NopTracker{}
+
+func (NopTracker) Track(context.Context, dto.AnalyticsEvent) {}
+
+// withMeta returns properties with the request's metadata added, copied so
+// the caller's map is left alone.
+func withMeta(ctx context.Context, properties map[string]any) map[string]any {
+	meta := ctxutil.MetaFrom(ctx)
+	if meta == nil {
+		return properties
+	}
+	out := maps.Clone(properties)
+	meta.Each(func(name string, value any) {
+		if out == nil {
+			out = make(map[string]any)
+		}
+		if _, ok := out[name]; !ok {
+			out[name] = value
+		}
+	})
+	return out
+}
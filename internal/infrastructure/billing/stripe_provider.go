@@ -0,0 +1,234 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+const stripeAPIURL = "https://api.stripe.com"
+
+type StripeProviderArgs struct {
+	SecretKey     string
+	WebhookSecret string
+	// WebhookTolerance is the maximum age of a webhook signature, which
+	// bounds replays of captured deliveries.
+	WebhookTolerance time.Duration
+	Timeout          time.Duration
+}
+
+// StripeProvider creates Stripe Checkout sessions and verifies Stripe
+// webhooks, talking to the REST API directly.
+type StripeProvider struct {
+	secretKey        string
+	webhookSecret    string
+	webhookTolerance time.Duration
+	baseURL          string
+	client           *http.Client
+}
+
+var _ contract.BillingProvider = (*StripeProvider)(nil)
+
+func NewStripeProvider(args StripeProviderArgs) (*StripeProvider, error) {
+	if args.SecretKey == "" || args.WebhookSecret == "" {
+		return nil, fmt.Errorf("stripe needs a secret key and a webhook secret")
+	}
+	return &StripeProvider{
+		secretKey:        args.SecretKey,
+		webhookSecret:    args.WebhookSecret,
+		webhookTolerance: args.WebhookTolerance,
+		baseURL:          stripeAPIURL,
+		client:           &http.Client{Timeout: args.Timeout},
+	}, nil
+}
+
+type stripeError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *StripeProvider) CreateCheckoutSession(ctx context.Context, input *dto.CheckoutSessionInput) (*dto.CheckoutSession, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"line_items[0][price]":    {input.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"success_url":             {input.SuccessURL},
+		"cancel_url":              {input.CancelURL},
+		"client_reference_id":     {input.UserID.String()},
+		// The subscription webhooks only carry the subscription, so it is
+		// tagged with its user.
+		"subscription_data[metadata][user_id]": {input.UserID.String()},
+	}
+	if input.CustomerID != "" {
+		form.Set("customer", input.CustomerID)
+	} else {
+		form.Set("customer_email", input.Email)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.secretKey, "")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errs.ErrBillingProviderFailed, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var body stripeError
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return nil, fmt.Errorf("%w: status %d: %s", errs.ErrBillingProviderFailed, res.StatusCode, body.Error.Message)
+	}
+
+	var session dto.CheckoutSession
+	if err := json.NewDecoder(res.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("%w: %w", errs.ErrBillingProviderFailed, err)
+	}
+	return &session, nil
+}
+
+// Ping checks that Stripe is reachable and accepts the secret key, with a
+// read of the account balance.
+func (p *StripeProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/v1/balance", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.secretKey, "")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errs.ErrBillingProviderFailed, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var body stripeError
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return fmt.Errorf("%w: status %d: %s", errs.ErrBillingProviderFailed, res.StatusCode, body.Error.Message)
+	}
+	return nil
+}
+
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeSubscription holds the fields of a Stripe subscription object that
+// are synced. Newer API versions moved current_period_end to the items.
+type stripeSubscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []struct {
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+func (p *StripeProvider) ParseWebhook(payload []byte, signature string) (*dto.BillingEvent, error) {
+	if err := p.verifySignature(payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var raw stripeEvent
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("decode stripe event: %w", err)
+	}
+	event := &dto.BillingEvent{ID: raw.ID, Type: raw.Type, CreatedAt: time.Unix(raw.Created, 0).UTC()}
+
+	switch raw.Type {
+	case dto.BILLING_EVENT_SUBSCRIPTION_CREATED, dto.BILLING_EVENT_SUBSCRIPTION_UPDATED, dto.BILLING_EVENT_SUBSCRIPTION_DELETED:
+		var s stripeSubscription
+		if err := json.Unmarshal(raw.Data.Object, &s); err != nil {
+			return nil, fmt.Errorf("decode stripe subscription: %w", err)
+		}
+		userID, _ := uuid.Parse(s.Metadata["user_id"])
+		periodEnd := s.CurrentPeriodEnd
+		var priceID string
+		if len(s.Items.Data) > 0 {
+			priceID = s.Items.Data[0].Price.ID
+			if periodEnd == 0 {
+				periodEnd = s.Items.Data[0].CurrentPeriodEnd
+			}
+		}
+		event.Subscription = &dto.ProviderSubscription{
+			ID:                s.ID,
+			CustomerID:        s.Customer,
+			UserID:            userID,
+			PriceID:           priceID,
+			Status:            s.Status,
+			CurrentPeriodEnd:  time.Unix(periodEnd, 0).UTC(),
+			CancelAtPeriodEnd: s.CancelAtPeriodEnd,
+		}
+	}
+	return event, nil
+}
+
+// verifySignature checks a Stripe-Signature header of the form
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<payload>">". Several v1
+// entries appear while the webhook secret is being rolled.
+func (p *StripeProvider) verifySignature(payload []byte, header string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	t, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errs.ErrInvalidWebhookSignature
+	}
+	if age := now.Sub(time.Unix(t, 0)); age > p.webhookTolerance || age < -p.webhookTolerance {
+		return errs.ErrInvalidWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, s := range signatures {
+		got, err := hex.DecodeString(s)
+		if err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return errs.ErrInvalidWebhookSignature
+}
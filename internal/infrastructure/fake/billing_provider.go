@@ -0,0 +1,86 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// BillingProvider completes every checkout at once and accepts unsigned
+// webhooks, so billing flows run without a payment provider.
+type BillingProvider struct {
+	outbox *Outbox
+}
+
+var _ contract.BillingProvider = (*BillingProvider)(nil)
+
+func NewBillingProvider(outbox *Outbox) *BillingProvider {
+	return &BillingProvider{outbox: outbox}
+}
+
+type checkoutRecord struct {
+	SessionID  string    `json:"session_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	CustomerID string    `json:"customer_id,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	PriceID    string    `json:"price_id"`
+}
+
+// CreateCheckoutSession returns a session whose URL is the success URL, as
+// if the user had paid.
+func (p *BillingProvider) CreateCheckoutSession(_ context.Context, input *dto.CheckoutSessionInput) (*dto.CheckoutSession, error) {
+	session := &dto.CheckoutSession{ID: "cs_mock_" + uuid.NewString(), URL: input.SuccessURL}
+	p.outbox.Record(KIND_CHECKOUT, checkoutRecord{
+		SessionID:  session.ID,
+		UserID:     input.UserID,
+		CustomerID: input.CustomerID,
+		Email:      input.Email,
+		PriceID:    input.PriceID,
+	})
+	return session, nil
+}
+
+// ParseWebhook ignores the signature and decodes the payload as the fields
+// of dto.BillingEvent, e.g.
+//
+//	{"id":"evt_1","type":"customer.subscription.created","subscription":
+//	 {"id":"sub_1","user_id":"…","price_id":"price_pro","status":"active",
+//	  "current_period_end":"2030-01-01T00:00:00Z"}}
+func (p *BillingProvider) ParseWebhook(payload []byte, _ string) (*dto.BillingEvent, error) {
+	var raw struct {
+		ID           string `json:"id"`
+		Type         string `json:"type"`
+		Subscription *struct {
+			ID                string    `json:"id"`
+			CustomerID        string    `json:"customer_id"`
+			UserID            uuid.UUID `json:"user_id"`
+			PriceID           string    `json:"price_id"`
+			Status            string    `json:"status"`
+			CurrentPeriodEnd  time.Time `json:"current_period_end"`
+			CancelAtPeriodEnd bool      `json:"cancel_at_period_end"`
+		} `json:"subscription"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("decode mock billing event: %w", err)
+	}
+	p.outbox.Record(KIND_WEBHOOK, json.RawMessage(payload))
+
+	event := &dto.BillingEvent{ID: raw.ID, Type: raw.Type, CreatedAt: time.Now().UTC()}
+	if s := raw.Subscription; s != nil {
+		event.Subscription = &dto.ProviderSubscription{
+			ID:                s.ID,
+			CustomerID:        s.CustomerID,
+			UserID:            s.UserID,
+			PriceID:           s.PriceID,
+			Status:            s.Status,
+			CurrentPeriodEnd:  s.CurrentPeriodEnd,
+			CancelAtPeriodEnd: s.CancelAtPeriodEnd,
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,39 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// FAILING_CAPTCHA_TOKEN is the one token CaptchaVerifier rejects, to
+// exercise the failure path.
+const FAILING_CAPTCHA_TOKEN = "fail"
+
+// CaptchaVerifier accepts every CAPTCHA token but FAILING_CAPTCHA_TOKEN
+// without calling a provider.
+type CaptchaVerifier struct {
+	outbox *Outbox
+}
+
+var _ contract.CaptchaVerifier = (*CaptchaVerifier)(nil)
+
+func NewCaptchaVerifier(outbox *Outbox) *CaptchaVerifier {
+	return &CaptchaVerifier{outbox: outbox}
+}
+
+type captchaRecord struct {
+	Token    string `json:"token"`
+	RemoteIP string `json:"remote_ip"`
+	Passed   bool   `json:"passed"`
+}
+
+func (v *CaptchaVerifier) Verify(_ context.Context, token, remoteIP string) error {
+	passed := token != FAILING_CAPTCHA_TOKEN
+	v.outbox.Record(KIND_CAPTCHA, captchaRecord{Token: token, RemoteIP: remoteIP, Passed: passed})
+	if !passed {
+		return errs.ErrCaptchaFailed
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// Mailer records outgoing email in the outbox instead of delivering it.
+type Mailer struct {
+	from   string
+	outbox *Outbox
+}
+
+var _ contract.MailTransport = (*Mailer)(nil)
+
+func NewMailer(from string, outbox *Outbox) *Mailer {
+	return &Mailer{from: from, outbox: outbox}
+}
+
+type emailRecord struct {
+	ID      uuid.UUID `json:"id"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+func (m *Mailer) Send(_ context.Context, msg dto.EmailMessage) error {
+	m.outbox.Record(KIND_EMAIL, emailRecord{ID: msg.ID, From: m.from, To: msg.To, Subject: msg.Subject, Body: msg.Body})
+	return nil
+}
@@ -0,0 +1,70 @@
+package fake
+
+import (
+	"sync"
+	"time"
+)
+
+// Interaction kinds recorded by the fakes.
+const (
+	KIND_EMAIL    = "email"
+	KIND_SMS      = "sms"
+	KIND_CHECKOUT = "checkout"
+	KIND_WEBHOOK  = "webhook"
+	KIND_CAPTCHA  = "captcha"
+)
+
+// Interaction is one call made to a fake external dependency.
+type Interaction struct {
+	ID      int64     `json:"id"`
+	Kind    string    `json:"kind"`
+	At      time.Time `json:"at"`
+	Payload any       `json:"payload"`
+}
+
+// Outbox records what the fakes were asked to do, keeping the latest max
+// interactions, so a developer can read the email or text that would have
+// been sent.
+type Outbox struct {
+	mu     sync.RWMutex
+	items  []Interaction
+	nextID int64
+	max    int
+}
+
+func NewOutbox(max int) *Outbox {
+	if max <= 0 {
+		max = 500
+	}
+	return &Outbox{max: max}
+}
+
+func (o *Outbox) Record(kind string, payload any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nextID++
+	o.items = append(o.items, Interaction{ID: o.nextID, Kind: kind, At: time.Now().UTC(), Payload: payload})
+	if len(o.items) > o.max {
+		o.items = o.items[len(o.items)-o.max:]
+	}
+}
+
+// List returns the recorded interactions of kind, or of every kind when
+// kind is empty, newest first.
+func (o *Outbox) List(kind string) []Interaction {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	out := make([]Interaction, 0, len(o.items))
+	for i := len(o.items) - 1; i >= 0; i-- {
+		if kind == "" || o.items[i].Kind == kind {
+			out = append(out, o.items[i])
+		}
+	}
+	return out
+}
+
+func (o *Outbox) Clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.items = nil
+}
@@ -0,0 +1,30 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// SMSSender records outgoing text messages in the outbox instead of sending
+// them.
+type SMSSender struct {
+	outbox *Outbox
+}
+
+var _ contract.SMSSender = (*SMSSender)(nil)
+
+func NewSMSSender(outbox *Outbox) *SMSSender {
+	return &SMSSender{outbox: outbox}
+}
+
+type smsRecord struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+func (s *SMSSender) Send(_ context.Context, msg dto.SMSMessage) error {
+	s.outbox.Record(KIND_SMS, smsRecord{To: msg.To, Body: msg.Body})
+	return nil
+}
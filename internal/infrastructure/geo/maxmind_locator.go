@@ -0,0 +1,140 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/mmdb"
+)
+
+// fallbackLanguage is used for names the database lacks in the request's
+// locale.
+const fallbackLanguage = "en"
+
+var geoipReloadsTotal = metrics.NewCounter("geoip_reloads_total",
+	"Reloads of the GeoIP database after its file changed, by outcome (ok, error).", "outcome")
+
+type MaxMindLocatorArgs struct {
+	// Path is a GeoIP2 or GeoLite2 City or Country database, or an ASN or
+	// ISP one, which resolves only the ASN.
+	Path string
+	// ReloadInterval is how often the file is checked for a new version; 0
+	// disables reloading.
+	ReloadInterval time.Duration
+}
+
+// MaxMindLocator resolves addresses with a MaxMind DB file. The file is
+// checked for changes at most every ReloadInterval, by the lookup that
+// finds the check due, so databases updated in place, e.g. by
+// geoipupdate, are picked up without a restart. A file that fails to load
+// leaves the previous database in use.
+type MaxMindLocator struct {
+	path     string
+	interval time.Duration
+	reader   atomic.Pointer[mmdb.Reader]
+	// nextCheck is when the file is due to be checked, in Unix nanoseconds.
+	nextCheck atomic.Int64
+	// mu is held by the lookup checking the file; modTime is guarded by it.
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+var _ contract.GeoLocator = (*MaxMindLocator)(nil)
+
+func NewMaxMindLocator(args MaxMindLocatorArgs) (*MaxMindLocator, error) {
+	l := &MaxMindLocator{path: args.Path, interval: args.ReloadInterval}
+	info, err := os.Stat(args.Path)
+	if err != nil {
+		return nil, fmt.Errorf("GeoIP database: %w", err)
+	}
+	reader, err := mmdb.Open(args.Path)
+	if err != nil {
+		return nil, fmt.Errorf("GeoIP database %s: %w", args.Path, err)
+	}
+	l.reader.Store(reader)
+	l.modTime = info.ModTime()
+	l.nextCheck.Store(time.Now().Add(args.ReloadInterval).UnixNano())
+	return l, nil
+}
+
+func (l *MaxMindLocator) Locate(ctx context.Context, ip string) (dto.GeoLocation, error) {
+	l.reloadIfDue()
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return dto.GeoLocation{}, nil
+	}
+	if addr.IsLoopback() || addr.IsPrivate() {
+		return dto.GeoLocation{Country: "private network"}, nil
+	}
+
+	record, ok, err := l.reader.Load().Lookup(addr)
+	if err != nil || !ok {
+		return dto.GeoLocation{}, err
+	}
+	fields, _ := record.(map[string]any)
+	locale := ctxutil.Locale(ctx)
+	country, _ := fields["country"].(map[string]any)
+	city, _ := fields["city"].(map[string]any)
+	code, _ := country["iso_code"].(string)
+	asn, _ := fields["autonomous_system_number"].(uint64)
+	return dto.GeoLocation{
+		CountryCode: code,
+		Country:     localName(country, locale),
+		City:        localName(city, locale),
+		ASN:         uint32(asn),
+	}, nil
+}
+
+// localName returns the name of a country or city record in locale, or in
+// fallbackLanguage.
+func localName(place map[string]any, locale string) string {
+	names, _ := place["names"].(map[string]any)
+	if name, ok := names[locale].(string); ok {
+		return name
+	}
+	name, _ := names[fallbackLanguage].(string)
+	return name
+}
+
+// reloadIfDue checks the file when due and loads it if it changed. Only one
+// lookup checks; the others keep using the current database meanwhile.
+func (l *MaxMindLocator) reloadIfDue() {
+	now := time.Now()
+	if l.interval <= 0 || now.UnixNano() < l.nextCheck.Load() || !l.mu.TryLock() {
+		return
+	}
+	defer l.mu.Unlock()
+	l.nextCheck.Store(now.Add(l.interval).UnixNano())
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		geoipReloadsTotal.Inc("error")
+		logger.Sampled("geo.reload", 100).Warnw("stat GeoIP database", "path", l.path, "error", err)
+		return
+	}
+	if info.ModTime().Equal(l.modTime) {
+		return
+	}
+	reader, err := mmdb.Open(l.path)
+	if err != nil {
+		geoipReloadsTotal.Inc("error")
+		logger.Sampled("geo.reload", 100).Warnw("reload GeoIP database", "path", l.path, "error", err)
+		return
+	}
+	l.reader.Store(reader)
+	l.modTime = info.ModTime()
+	geoipReloadsTotal.Inc("ok")
+	logger.L().Infow("reloaded GeoIP database", "path", l.path,
+		"built_at", time.Unix(int64(reader.Metadata().BuildEpoch), 0).UTC())
+}
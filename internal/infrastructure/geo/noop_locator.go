@@ -0,0 +1,27 @@
+package geo
+
+import (
+	"context"
+	"net"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// NoopLocator resolves no public addresses; it only labels loopback and
+// private ranges. It stands in until a GeoIP database is wired.
+type NoopLocator struct{}
+
+var _ contract.GeoLocator = NoopLocator{}
+
+func NewNoopLocator() NoopLocator {
+	return NoopLocator{}
+}
+
+func (NoopLocator) Locate(ctx context.Context, ip string) (dto.GeoLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed != nil && (parsed.IsLoopback() || parsed.IsPrivate()) {
+		return dto.GeoLocation{Country: "private network"}, nil
+	}
+	return dto.GeoLocation{}, nil
+}
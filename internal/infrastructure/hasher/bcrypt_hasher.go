@@ -0,0 +1,134 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	operationsTotal = metrics.NewCounter("password_hash_operations_total",
+		"Password hash and compare operations by outcome (done, rejected, canceled).", "op", "outcome")
+	queueDepth = metrics.NewGauge("password_hash_queue_depth",
+		"Password operations waiting for a hashing worker.")
+	waitSeconds = metrics.NewHistogram("password_hash_wait_seconds",
+		"Time password operations waited for a hashing worker.", metrics.DefaultBuckets)
+)
+
+type BcryptHasherArgs struct {
+	// Workers is the number of operations run at once; 0 means GOMAXPROCS.
+	Workers int
+	// Queue bounds the operations waiting for a worker; beyond it they fail
+	// with ErrPasswordHashingBusy.
+	Queue int
+	// Cost is the bcrypt cost of new hashes; 0 means bcrypt.DefaultCost.
+	Cost int
+}
+
+// BcryptHasher runs every bcrypt operation on a fixed pool of workers, so a
+// burst of sign-ups and sign-ins cannot take more CPU than the pool has and
+// leave the rest of the server starved.
+type BcryptHasher struct {
+	cost int
+	jobs chan job
+}
+
+var _ contract.PasswordHasher = (*BcryptHasher)(nil)
+
+type job struct {
+	ctx      context.Context
+	run      func()
+	done     chan struct{}
+	queuedAt time.Time
+}
+
+func NewBcryptHasher(args BcryptHasherArgs) *BcryptHasher {
+	if args.Workers <= 0 {
+		args.Workers = runtime.GOMAXPROCS(0)
+	}
+	if args.Cost == 0 {
+		args.Cost = bcrypt.DefaultCost
+	}
+	h := &BcryptHasher{cost: args.Cost, jobs: make(chan job, max(args.Queue, 0))}
+	for range args.Workers {
+		go h.work()
+	}
+	return h
+}
+
+func (h *BcryptHasher) work() {
+	for j := range h.jobs {
+		queueDepth.Dec()
+		waitSeconds.Observe(time.Since(j.queuedAt).Seconds())
+		// Skip operations whose caller has already given up.
+		if j.ctx.Err() == nil {
+			j.run()
+		}
+		close(j.done)
+	}
+}
+
+func (h *BcryptHasher) Hash(ctx context.Context, password string) (string, error) {
+	var (
+		hashed []byte
+		err    error
+	)
+	if serr := h.submit(ctx, "hash", func() {
+		hashed, err = bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	}); serr != nil {
+		return "", serr
+	}
+	return string(hashed), err
+}
+
+func (h *BcryptHasher) Compare(ctx context.Context, hashed, password string) (bool, error) {
+	var err error
+	if serr := h.submit(ctx, "compare", func() {
+		err = bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+	}); serr != nil {
+		return false, serr
+	}
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Backlog returns the number of operations waiting for a worker and how
+// many may wait before new ones are rejected.
+func (h *BcryptHasher) Backlog() (queued, capacity int) {
+	return len(h.jobs), cap(h.jobs)
+}
+
+// submit runs fn on a worker and waits for it. It fails at once when the
+// queue is full, rather than adding to the backlog.
+func (h *BcryptHasher) submit(ctx context.Context, op string, fn func()) error {
+	j := job{ctx: ctx, run: fn, done: make(chan struct{}), queuedAt: time.Now()}
+	queueDepth.Inc()
+	select {
+	case h.jobs <- j:
+	default:
+		queueDepth.Dec()
+		operationsTotal.Inc(op, "rejected")
+		return errs.ErrPasswordHashingBusy
+	}
+
+	select {
+	case <-j.done:
+		if ctx.Err() != nil {
+			operationsTotal.Inc(op, "canceled")
+			return ctx.Err()
+		}
+		operationsTotal.Inc(op, "done")
+		return nil
+	case <-ctx.Done():
+		operationsTotal.Inc(op, "canceled")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,44 @@
+package clientinfo
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	uaInfo "github.com/haidang666/go-app/pkg/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+// FromRequest extracts the device details recorded on sessions and audit
+// records. Clients may send a stable X-Device-ID; otherwise the fingerprint
+// is derived from headers that rarely change for a given browser.
+func FromRequest(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:                IP(r),
+		UserAgent:         r.UserAgent(),
+		DeviceFingerprint: uaInfo.Fingerprint(r.Header),
+		Device:            Device(r),
+	}
+}
+
+// IP returns the client address without the port. RealIP has already
+// replaced RemoteAddr with the forwarded address where applicable.
+func IP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// Device tells the browser, OS and kind of device of the request from its
+// User-Agent and Client Hints. It is kept in the request's metadata, so the
+// headers are parsed once per request.
+func Device(r *http.Request) uaInfo.Info {
+	if info, ok := ctxutil.GetMeta(r.Context(), ctxutil.MetaDevice); ok {
+		return info
+	}
+	info := uaInfo.FromHeaders(r.Header)
+	ctxutil.SetMeta(r.Context(), ctxutil.MetaDevice, info)
+	return info
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 200
+)
+
+// auditLogPage is a page of the audit log. NextCursor, passed as before,
+// gets the next page; it stays correct while events are appended, unlike
+// offsets. With before, Total counts the events from the cursor on.
+type auditLogPage struct {
+	*dto.Page[*entity.AuditEvent]
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListAuditLog lists audit events, newest first, filtered by the user_id,
+// actor_id, subject_id, action, from and to query parameters.
+func (h *AdminHandler) ListAuditLog(resWriter http.ResponseWriter, r *http.Request) {
+	limit, offset, err := request.Pagination(r, auditLogDefaultLimit, auditLogMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	filter, err := auditLogFilter(r)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	before, err := decodeCursorParam(r, "before")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listAuditLogUseCase.Execute(r.Context(), *filter, before, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := auditLogPage{Page: page}
+	if offset+len(page.Items) < page.Total {
+		last := page.Items[len(page.Items)-1]
+		res.NextCursor = encodeContinuationToken(last.CreatedAt, last.ID)
+		query := r.URL.Query()
+		query.Set("limit", fmt.Sprint(limit))
+		query.Set("before", res.NextCursor)
+		query.Del("offset")
+		response.AddLink(r, "next", r.URL.Path+"?"+query.Encode())
+	}
+	response.JSON(resWriter, r, res, http.StatusOK)
+}
+
+// auditLogFilter returns the filters of the audit log list and export.
+func auditLogFilter(r *http.Request) (*dto.AuditLogFilter, error) {
+	payload := new(admin.AuditLogFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		return nil, err
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return mapping.AuditLogFilter(payload), nil
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// GetAuthProtection reports how this instance's adaptive protection of
+// sign-up and sign-in is overridden.
+func (h *AdminHandler) GetAuthProtection(resWriter http.ResponseWriter, r *http.Request) {
+	protection, err := h.getAuthProtectionUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, protection, http.StatusOK)
+}
+
+// SetAuthProtection forces the protection on or off on this instance, or
+// hands it back to failure spike detection.
+func (h *AdminHandler) SetAuthProtection(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.SetAuthProtectionRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	protection, err := h.setAuthProtectionUseCase.Execute(r.Context(), current.ID, payload.Mode)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrInvalidAuthProtectionMode) {
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, protection, http.StatusOK)
+}
@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	deadLetterListDefaultLimit = 50
+	deadLetterListMaxLimit     = 200
+)
+
+// ListDeadLetters pages through the scheduled jobs that failed for good,
+// the most recent failure first, optionally filtered by kind.
+func (h *AdminHandler) ListDeadLetters(resWriter http.ResponseWriter, r *http.Request) {
+	limit, offset, err := request.Pagination(r, deadLetterListDefaultLimit, deadLetterListMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.DeadLetterFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listDeadJobsUseCase.Execute(r.Context(), payload.Kind, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		query := r.URL.Query()
+		query.Set("limit", fmt.Sprint(limit))
+		query.Set("offset", fmt.Sprint(next))
+		response.AddLink(r, "next", r.URL.Path+"?"+query.Encode())
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
+
+// GetDeadLetter returns a job with its payload and error history, whatever
+// its status, so a requeued job can be followed.
+func (h *AdminHandler) GetDeadLetter(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := h.getJobUseCase.Execute(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrScheduledJobNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, job, http.StatusOK)
+}
+
+// RequeueDeadLetter makes a failed job due again.
+func (h *AdminHandler) RequeueDeadLetter(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := h.requeueJobUseCase.Execute(r.Context(), id)
+	if err != nil {
+		response.Error(resWriter, r, deadLetterErrorStatus(err), err)
+		return
+	}
+
+	response.JSON(resWriter, r, job, http.StatusAccepted)
+}
+
+// DiscardDeadLetter gives up on a failed job.
+func (h *AdminHandler) DiscardDeadLetter(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := h.discardJobUseCase.Execute(r.Context(), id); err != nil {
+		response.Error(resWriter, r, deadLetterErrorStatus(err), err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+func deadLetterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrScheduledJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrScheduledJobNotDead), errors.Is(err, errs.ErrScheduledJobSuperseded):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
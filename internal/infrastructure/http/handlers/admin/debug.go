@@ -0,0 +1,13 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// GetConfig reports the configuration the process loaded from the
+// environment and .env file, keyed by variable, with secrets masked.
+func (h *AdminHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, r, h.config, http.StatusOK)
+}
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ListDeprecationUsage reports the deprecated routes and fields with the
+// clients that used them since this instance started.
+func (h *AdminHandler) ListDeprecationUsage(resWriter http.ResponseWriter, r *http.Request) {
+	usage, err := h.listDeprecationUsageUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, usage, http.StatusOK)
+}
@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ListEmailSuppressions pages through the addresses no mail is sent to,
+// newest first, optionally filtered by reason.
+func (h *AdminHandler) ListEmailSuppressions(resWriter http.ResponseWriter, r *http.Request) {
+	limit, offset, err := request.Pagination(r, emailListDefaultLimit, emailListMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.EmailSuppressionFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listEmailSuppressionsUseCase.Execute(r.Context(), payload.Reason, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		query := r.URL.Query()
+		query.Set("limit", fmt.Sprint(limit))
+		query.Set("offset", fmt.Sprint(next))
+		response.AddLink(r, "next", r.URL.Path+"?"+query.Encode())
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
+
+// AddEmailSuppression stops mail to an address. It answers 201 with the new
+// entry, or 200 with the existing one when the address was already
+// suppressed.
+func (h *AdminHandler) AddEmailSuppression(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.AddEmailSuppressionRequest)
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	suppression, added, err := h.addEmailSuppressionUseCase.Execute(r.Context(), current.ID, payload.Email, payload.Reason, payload.Detail)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	response.JSON(resWriter, r, suppression, status)
+}
+
+// RemoveEmailSuppression sends mail to an address again.
+func (h *AdminHandler) RemoveEmailSuppression(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	if err := h.removeEmailSuppressionUseCase.Execute(r.Context(), current.ID, chi.URLParam(r, "email")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrEmailSuppressionNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
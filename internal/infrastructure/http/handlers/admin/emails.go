@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	emailListDefaultLimit = 50
+	emailListMaxLimit     = 200
+)
+
+// ListEmails pages through the mail queue, newest first, optionally
+// filtered by status.
+func (h *AdminHandler) ListEmails(resWriter http.ResponseWriter, r *http.Request) {
+	limit, offset, err := request.Pagination(r, emailListDefaultLimit, emailListMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.EmailFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listEmailsUseCase.Execute(r.Context(), payload.Status, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		query := r.URL.Query()
+		query.Set("limit", fmt.Sprint(limit))
+		query.Set("offset", fmt.Sprint(next))
+		response.AddLink(r, "next", r.URL.Path+"?"+query.Encode())
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
+
+func (h *AdminHandler) GetEmail(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	email, err := h.getEmailUseCase.Execute(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrOutboundEmailNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, email, http.StatusOK)
+}
+
+// ResendEmail queues a failed, bounced or suppressed message again.
+func (h *AdminHandler) ResendEmail(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	email, err := h.resendEmailUseCase.Execute(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrOutboundEmailNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrEmailNotResendable):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, email, http.StatusAccepted)
+}
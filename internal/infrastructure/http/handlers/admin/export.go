@@ -0,0 +1,278 @@
+package admin
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// exportChunkWriteTimeout bounds writing one chunk of an export; the
+// deadline moves with every chunk, so exports may outlast APP_WRITE_TIMEOUT.
+const exportChunkWriteTimeout = 30 * time.Second
+
+var (
+	ErrInvalidExportFormat      = errors.New("format must be csv or jsonl")
+	ErrInvalidContinuationToken = errors.New("continuation token is malformed")
+)
+
+var (
+	userExportHeader = []string{
+		"id", "email", "username", "status", "plan", "tenant_id", "is_guest",
+		"phone_verified", "created_at", "updated_at", "continuation_token",
+	}
+	auditExportHeader = []string{
+		"id", "created_at", "action", "actor_id", "subject_id", "session_id", "method",
+		"path", "status", "ip", "country_code", "city", "reason", "detail", "continuation_token",
+	}
+)
+
+type userExportLine struct {
+	*dto.UserSummary
+	ContinuationToken string `json:"continuation_token"`
+}
+
+type auditExportLine struct {
+	*entity.AuditEvent
+	ContinuationToken string `json:"continuation_token"`
+}
+
+// ExportUsers streams the users matching the ListUsers filters, oldest
+// first, as CSV or JSONL (the format query parameter). Every row carries a
+// continuation token; passing the last one received as after resumes an
+// interrupted export with the same filters.
+func (h *AdminHandler) ExportUsers(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.UserFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	exp, err := newExport(resWriter, r, "users", userExportHeader)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	after, err := exportAfter(r)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.exportUsersUseCase.Execute(r.Context(), *mapping.UserFilter(payload), after, func(users []*dto.UserSummary) error {
+		for _, u := range users {
+			token := encodeContinuationToken(u.CreatedAt, u.ID)
+			exp.write(userExportLine{u, token}, []string{
+				u.ID.String(), u.Email, u.Username, u.Status, u.Plan, u.TenantID,
+				strconv.FormatBool(u.IsGuest), strconv.FormatBool(u.PhoneVerified),
+				formatTime(&u.CreatedAt), formatTime(u.UpdatedAt), token,
+			})
+		}
+		return exp.flush()
+	})
+	exp.finish(err)
+}
+
+// ExportAuditLog streams the audit events matching the ListAuditLog filters,
+// oldest first, like ExportUsers.
+func (h *AdminHandler) ExportAuditLog(resWriter http.ResponseWriter, r *http.Request) {
+	filter, err := auditLogFilter(r)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	exp, err := newExport(resWriter, r, "audit-log", auditExportHeader)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	after, err := exportAfter(r)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.exportAuditLogUseCase.Execute(r.Context(), *filter, after, func(events []*entity.AuditEvent) error {
+		for _, e := range events {
+			token := encodeContinuationToken(e.CreatedAt, e.ID)
+			sessionID, status := "", ""
+			if e.SessionID != nil {
+				sessionID = e.SessionID.String()
+			}
+			if e.Status != 0 {
+				status = strconv.Itoa(e.Status)
+			}
+			exp.write(auditExportLine{e, token}, []string{
+				e.ID.String(), formatTime(&e.CreatedAt), e.Action, e.ActorID.String(), e.SubjectID.String(),
+				sessionID, e.Method, e.Path, status, e.IP, e.CountryCode, e.City, e.Reason, e.Detail, token,
+			})
+		}
+		return exp.flush()
+	})
+	exp.finish(err)
+}
+
+// export writes one streamed export. Nothing is buffered beyond the chunk
+// being written, and the response is chunked since its length is unknown.
+// The response starts with the first chunk, so a failure before it can still
+// be answered with an error.
+type export struct {
+	name   string
+	header []string
+	jsonl  bool
+
+	r       *http.Request
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+// newExport prepares an export in the format the format query parameter
+// asks for, CSV by default.
+func newExport(w http.ResponseWriter, r *http.Request, name string, header []string) (*export, error) {
+	exp := &export{name: name, header: header, r: r, w: w, rc: http.NewResponseController(w)}
+	switch r.URL.Query().Get("format") {
+	case "", "csv":
+	case "jsonl":
+		exp.jsonl = true
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+	return exp, nil
+}
+
+// exportAfter returns the cursor of the after query parameter, or nil to
+// export from the start.
+func exportAfter(r *http.Request) (*dto.ExportCursor, error) {
+	return decodeCursorParam(r, "after")
+}
+
+// decodeCursorParam returns the cursor in the continuation token of the
+// query parameter name, or nil when it is absent.
+func decodeCursorParam(r *http.Request, name string) (*dto.ExportCursor, error) {
+	token := r.URL.Query().Get(name)
+	if token == "" {
+		return nil, nil
+	}
+	return decodeContinuationToken(token)
+}
+
+func (e *export) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+
+	ext, contentType := "csv", "text/csv; charset=utf-8"
+	if e.jsonl {
+		ext, contentType = "jsonl", ndjsonContentType
+	}
+	filename := fmt.Sprintf("%s-%s.%s", e.name, time.Now().UTC().Format("20060102T150405Z"), ext)
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	e.w.WriteHeader(http.StatusOK)
+
+	if e.jsonl {
+		e.json = json.NewEncoder(e.w)
+		return
+	}
+	e.csv = csv.NewWriter(e.w)
+	e.csv.Write(e.header)
+}
+
+// write adds a row as line in JSONL exports and as record in CSV ones.
+func (e *export) write(line any, record []string) {
+	e.start()
+	if e.jsonl {
+		e.json.Encode(line)
+		return
+	}
+	e.csv.Write(record)
+}
+
+// flush sends the chunk written so far and gives the next one a fresh write
+// deadline. It fails once the client has gone.
+func (e *export) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if err := e.rc.SetWriteDeadline(time.Now().Add(exportChunkWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return e.r.Context().Err()
+}
+
+// finish ends the export. Once the status is sent, a failure aborts the
+// response: the client sees a broken stream, not a complete export that is
+// silently short, and resumes from its last token.
+func (e *export) finish(err error) {
+	if err != nil && !e.started {
+		response.Error(e.w, e.r, http.StatusInternalServerError, err)
+		return
+	}
+	if err == nil {
+		e.start()
+		err = e.flush()
+	}
+	if err != nil {
+		ctxutil.Logger(e.r.Context()).Warnw("export aborted", "path", e.r.URL.Path, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func encodeContinuationToken(createdAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(createdAt.UnixNano(), 10) + "." + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeContinuationToken(token string) (*dto.ExportCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidContinuationToken
+	}
+	nanos, rawID, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidContinuationToken
+	}
+	ns, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidContinuationToken
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, ErrInvalidContinuationToken
+	}
+	return &dto.ExportCursor{CreatedAt: time.Unix(0, ns).UTC(), ID: id}, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,343 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	adminUseCase "github.com/haidang666/go-app/internal/domain/use_case/admin"
+	invitationUseCase "github.com/haidang666/go-app/internal/domain/use_case/invitation"
+	jobUseCase "github.com/haidang666/go-app/internal/domain/use_case/job"
+	mailUseCase "github.com/haidang666/go-app/internal/domain/use_case/mail"
+	oauthUseCase "github.com/haidang666/go-app/internal/domain/use_case/oauth"
+	samlUseCase "github.com/haidang666/go-app/internal/domain/use_case/saml"
+	serviceAccountUseCase "github.com/haidang666/go-app/internal/domain/use_case/serviceaccount"
+	statsUseCase "github.com/haidang666/go-app/internal/domain/use_case/stats"
+	usageUseCase "github.com/haidang666/go-app/internal/domain/use_case/usage"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+var ErrInvalidUserID = errors.New("user id must be a valid UUID")
+
+type NewAdminHandlerArgs struct {
+	BulkUsersUseCase                  *adminUseCase.BulkUsersUseCase
+	ForcePasswordRotationUseCase      *adminUseCase.ForcePasswordRotationUseCase
+	ImpersonateUserUseCase            *adminUseCase.ImpersonateUserUseCase
+	ListAuditLogUseCase               *adminUseCase.ListAuditLogUseCase
+	ListUsersUseCase                  *adminUseCase.ListUsersUseCase
+	ExportUsersUseCase                *adminUseCase.ExportUsersUseCase
+	ExportAuditLogUseCase             *adminUseCase.ExportAuditLogUseCase
+	SetUserStatusUseCase              *adminUseCase.SetUserStatusUseCase
+	RevokeUserAccessUseCase           *adminUseCase.RevokeUserAccessUseCase
+	SetUserPlanUseCase                *adminUseCase.SetUserPlanUseCase
+	ListUserTokensUseCase             *adminUseCase.ListUserTokensUseCase
+	CreateServiceAccountUseCase       *serviceAccountUseCase.CreateAccountUseCase
+	ListServiceAccountsUseCase        *serviceAccountUseCase.ListAccountsUseCase
+	DeleteServiceAccountUseCase       *serviceAccountUseCase.DeleteAccountUseCase
+	CreateServiceAccountKeyUseCase    *serviceAccountUseCase.CreateKeyUseCase
+	ListServiceAccountKeysUseCase     *serviceAccountUseCase.ListKeysUseCase
+	RevokeServiceAccountKeyUseCase    *serviceAccountUseCase.RevokeKeyUseCase
+	DisposableDomainsUseCase          *adminUseCase.DisposableDomainsUseCase
+	MintInvitationUseCase             *invitationUseCase.MintInvitationUseCase
+	ListInvitationsUseCase            *invitationUseCase.ListInvitationsUseCase
+	RevokeInvitationUseCase           *invitationUseCase.RevokeInvitationUseCase
+	RegisterOAuthClientUseCase        *oauthUseCase.RegisterClientUseCase
+	ListOAuthClientsUseCase           *oauthUseCase.ListClientsUseCase
+	RevokeOAuthClientUseCase          *oauthUseCase.RevokeClientUseCase
+	RegisterSAMLConnectionUseCase     *samlUseCase.RegisterConnectionUseCase
+	ListSAMLConnectionsUseCase        *samlUseCase.ListConnectionsUseCase
+	DeleteSAMLConnectionUseCase       *samlUseCase.DeleteConnectionUseCase
+	SetTenantQuotaUseCase             *adminUseCase.SetTenantQuotaUseCase
+	GetOrganizationSettingsUseCase    *adminUseCase.GetOrganizationSettingsUseCase
+	UpdateOrganizationSettingsUseCase *adminUseCase.UpdateOrganizationSettingsUseCase
+	GetMaintenanceUseCase             *adminUseCase.GetMaintenanceUseCase
+	SetMaintenanceUseCase             *adminUseCase.SetMaintenanceUseCase
+	GetAuthProtectionUseCase          *adminUseCase.GetAuthProtectionUseCase
+	SetAuthProtectionUseCase          *adminUseCase.SetAuthProtectionUseCase
+	ListDeprecationUsageUseCase       *adminUseCase.ListDeprecationUsageUseCase
+	ExportUsageUseCase                *usageUseCase.ExportUsageUseCase
+	ListEmailsUseCase                 *mailUseCase.ListEmailsUseCase
+	GetEmailUseCase                   *mailUseCase.GetEmailUseCase
+	ResendEmailUseCase                *mailUseCase.ResendEmailUseCase
+	ListEmailSuppressionsUseCase      *mailUseCase.ListEmailSuppressionsUseCase
+	AddEmailSuppressionUseCase        *mailUseCase.AddEmailSuppressionUseCase
+	RemoveEmailSuppressionUseCase     *mailUseCase.RemoveEmailSuppressionUseCase
+	ListDeadJobsUseCase               *jobUseCase.ListDeadJobsUseCase
+	GetJobUseCase                     *jobUseCase.GetJobUseCase
+	RequeueJobUseCase                 *jobUseCase.RequeueJobUseCase
+	DiscardJobUseCase                 *jobUseCase.DiscardJobUseCase
+	GetQueueStatsUseCase              *jobUseCase.GetQueueStatsUseCase
+	ListAdminTasksUseCase             *jobUseCase.ListAdminTasksUseCase
+	TriggerAdminTaskUseCase           *jobUseCase.TriggerAdminTaskUseCase
+	GetStatsUseCase                   *statsUseCase.GetStatsUseCase
+	// Config is the loaded configuration with secrets masked.
+	Config    map[string]any
+	Lifecycle *lifecycle.Registry
+}
+
+type AdminHandler struct {
+	bulkUsersUseCase                  *adminUseCase.BulkUsersUseCase
+	forcePasswordRotationUseCase      *adminUseCase.ForcePasswordRotationUseCase
+	impersonateUserUseCase            *adminUseCase.ImpersonateUserUseCase
+	listAuditLogUseCase               *adminUseCase.ListAuditLogUseCase
+	listUsersUseCase                  *adminUseCase.ListUsersUseCase
+	exportUsersUseCase                *adminUseCase.ExportUsersUseCase
+	exportAuditLogUseCase             *adminUseCase.ExportAuditLogUseCase
+	setUserStatusUseCase              *adminUseCase.SetUserStatusUseCase
+	revokeUserAccessUseCase           *adminUseCase.RevokeUserAccessUseCase
+	setUserPlanUseCase                *adminUseCase.SetUserPlanUseCase
+	listUserTokensUseCase             *adminUseCase.ListUserTokensUseCase
+	createServiceAccountUseCase       *serviceAccountUseCase.CreateAccountUseCase
+	listServiceAccountsUseCase        *serviceAccountUseCase.ListAccountsUseCase
+	deleteServiceAccountUseCase       *serviceAccountUseCase.DeleteAccountUseCase
+	createServiceAccountKeyUseCase    *serviceAccountUseCase.CreateKeyUseCase
+	listServiceAccountKeysUseCase     *serviceAccountUseCase.ListKeysUseCase
+	revokeServiceAccountKeyUseCase    *serviceAccountUseCase.RevokeKeyUseCase
+	disposableDomainsUseCase          *adminUseCase.DisposableDomainsUseCase
+	mintInvitationUseCase             *invitationUseCase.MintInvitationUseCase
+	listInvitationsUseCase            *invitationUseCase.ListInvitationsUseCase
+	revokeInvitationUseCase           *invitationUseCase.RevokeInvitationUseCase
+	registerOAuthClientUseCase        *oauthUseCase.RegisterClientUseCase
+	listOAuthClientsUseCase           *oauthUseCase.ListClientsUseCase
+	revokeOAuthClientUseCase          *oauthUseCase.RevokeClientUseCase
+	registerSAMLConnectionUseCase     *samlUseCase.RegisterConnectionUseCase
+	listSAMLConnectionsUseCase        *samlUseCase.ListConnectionsUseCase
+	deleteSAMLConnectionUseCase       *samlUseCase.DeleteConnectionUseCase
+	setTenantQuotaUseCase             *adminUseCase.SetTenantQuotaUseCase
+	getOrganizationSettingsUseCase    *adminUseCase.GetOrganizationSettingsUseCase
+	updateOrganizationSettingsUseCase *adminUseCase.UpdateOrganizationSettingsUseCase
+	getMaintenanceUseCase             *adminUseCase.GetMaintenanceUseCase
+	setMaintenanceUseCase             *adminUseCase.SetMaintenanceUseCase
+	getAuthProtectionUseCase          *adminUseCase.GetAuthProtectionUseCase
+	setAuthProtectionUseCase          *adminUseCase.SetAuthProtectionUseCase
+	listDeprecationUsageUseCase       *adminUseCase.ListDeprecationUsageUseCase
+	exportUsageUseCase                *usageUseCase.ExportUsageUseCase
+	listEmailsUseCase                 *mailUseCase.ListEmailsUseCase
+	getEmailUseCase                   *mailUseCase.GetEmailUseCase
+	resendEmailUseCase                *mailUseCase.ResendEmailUseCase
+	listEmailSuppressionsUseCase      *mailUseCase.ListEmailSuppressionsUseCase
+	addEmailSuppressionUseCase        *mailUseCase.AddEmailSuppressionUseCase
+	removeEmailSuppressionUseCase     *mailUseCase.RemoveEmailSuppressionUseCase
+	listDeadJobsUseCase               *jobUseCase.ListDeadJobsUseCase
+	getJobUseCase                     *jobUseCase.GetJobUseCase
+	requeueJobUseCase                 *jobUseCase.RequeueJobUseCase
+	discardJobUseCase                 *jobUseCase.DiscardJobUseCase
+	getQueueStatsUseCase              *jobUseCase.GetQueueStatsUseCase
+	listAdminTasksUseCase             *jobUseCase.ListAdminTasksUseCase
+	triggerAdminTaskUseCase           *jobUseCase.TriggerAdminTaskUseCase
+	getStatsUseCase                   *statsUseCase.GetStatsUseCase
+	config                            map[string]any
+	lifecycle                         *lifecycle.Registry
+}
+
+func NewAdminHandler(args NewAdminHandlerArgs) *AdminHandler {
+	return &AdminHandler{
+		bulkUsersUseCase:                  args.BulkUsersUseCase,
+		forcePasswordRotationUseCase:      args.ForcePasswordRotationUseCase,
+		impersonateUserUseCase:            args.ImpersonateUserUseCase,
+		listAuditLogUseCase:               args.ListAuditLogUseCase,
+		listUsersUseCase:                  args.ListUsersUseCase,
+		exportUsersUseCase:                args.ExportUsersUseCase,
+		exportAuditLogUseCase:             args.ExportAuditLogUseCase,
+		setUserStatusUseCase:              args.SetUserStatusUseCase,
+		revokeUserAccessUseCase:           args.RevokeUserAccessUseCase,
+		setUserPlanUseCase:                args.SetUserPlanUseCase,
+		listUserTokensUseCase:             args.ListUserTokensUseCase,
+		createServiceAccountUseCase:       args.CreateServiceAccountUseCase,
+		listServiceAccountsUseCase:        args.ListServiceAccountsUseCase,
+		deleteServiceAccountUseCase:       args.DeleteServiceAccountUseCase,
+		createServiceAccountKeyUseCase:    args.CreateServiceAccountKeyUseCase,
+		listServiceAccountKeysUseCase:     args.ListServiceAccountKeysUseCase,
+		revokeServiceAccountKeyUseCase:    args.RevokeServiceAccountKeyUseCase,
+		disposableDomainsUseCase:          args.DisposableDomainsUseCase,
+		mintInvitationUseCase:             args.MintInvitationUseCase,
+		listInvitationsUseCase:            args.ListInvitationsUseCase,
+		revokeInvitationUseCase:           args.RevokeInvitationUseCase,
+		registerOAuthClientUseCase:        args.RegisterOAuthClientUseCase,
+		listOAuthClientsUseCase:           args.ListOAuthClientsUseCase,
+		revokeOAuthClientUseCase:          args.RevokeOAuthClientUseCase,
+		registerSAMLConnectionUseCase:     args.RegisterSAMLConnectionUseCase,
+		listSAMLConnectionsUseCase:        args.ListSAMLConnectionsUseCase,
+		deleteSAMLConnectionUseCase:       args.DeleteSAMLConnectionUseCase,
+		setTenantQuotaUseCase:             args.SetTenantQuotaUseCase,
+		getOrganizationSettingsUseCase:    args.GetOrganizationSettingsUseCase,
+		updateOrganizationSettingsUseCase: args.UpdateOrganizationSettingsUseCase,
+		getMaintenanceUseCase:             args.GetMaintenanceUseCase,
+		setMaintenanceUseCase:             args.SetMaintenanceUseCase,
+		getAuthProtectionUseCase:          args.GetAuthProtectionUseCase,
+		setAuthProtectionUseCase:          args.SetAuthProtectionUseCase,
+		listDeprecationUsageUseCase:       args.ListDeprecationUsageUseCase,
+		exportUsageUseCase:                args.ExportUsageUseCase,
+		listEmailsUseCase:                 args.ListEmailsUseCase,
+		getEmailUseCase:                   args.GetEmailUseCase,
+		resendEmailUseCase:                args.ResendEmailUseCase,
+		listEmailSuppressionsUseCase:      args.ListEmailSuppressionsUseCase,
+		addEmailSuppressionUseCase:        args.AddEmailSuppressionUseCase,
+		removeEmailSuppressionUseCase:     args.RemoveEmailSuppressionUseCase,
+		listDeadJobsUseCase:               args.ListDeadJobsUseCase,
+		getJobUseCase:                     args.GetJobUseCase,
+		requeueJobUseCase:                 args.RequeueJobUseCase,
+		discardJobUseCase:                 args.DiscardJobUseCase,
+		getQueueStatsUseCase:              args.GetQueueStatsUseCase,
+		listAdminTasksUseCase:             args.ListAdminTasksUseCase,
+		triggerAdminTaskUseCase:           args.TriggerAdminTaskUseCase,
+		getStatsUseCase:                   args.GetStatsUseCase,
+		config:                            args.Config,
+		lifecycle:                         args.Lifecycle,
+	}
+}
+
+type bulkResponse struct {
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []dto.BulkItemResult `json:"results"`
+}
+
+func (h *AdminHandler) BulkCreateUsers(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.BulkCreateUsersRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	inputs := make([]dto.BulkInput[dto.SignUpInput], len(payload.Users))
+	for i, item := range payload.Users {
+		inputs[i] = dto.BulkInput[dto.SignUpInput]{
+			Input: dto.SignUpInput{Email: item.Email, Password: item.Password},
+			Err:   item.Validate(),
+		}
+	}
+
+	writeBulk(resWriter, r, func(progress adminUseCase.ProgressFunc) []dto.BulkItemResult {
+		return h.bulkUsersUseCase.CreateUsers(r.Context(), inputs, progress)
+	})
+}
+
+func (h *AdminHandler) BulkUpdateUsers(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.BulkUpdateUsersRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	inputs := make([]dto.BulkInput[dto.UpdateUserInput], len(payload.Users))
+	for i, item := range payload.Users {
+		id, _ := uuid.Parse(item.ID)
+		inputs[i] = dto.BulkInput[dto.UpdateUserInput]{
+			Input: dto.UpdateUserInput{ID: id, Email: item.Email, Password: item.Password},
+			Err:   item.Validate(),
+		}
+	}
+
+	writeBulk(resWriter, r, func(progress adminUseCase.ProgressFunc) []dto.BulkItemResult {
+		return h.bulkUsersUseCase.UpdateUsers(r.Context(), inputs, progress)
+	})
+}
+
+// ForcePasswordRotation makes the listed users change their password at
+// their next sign-in. Results are reported per user as in the bulk endpoints.
+func (h *AdminHandler) ForcePasswordRotation(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.ForcePasswordRotationRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	inputs := make([]dto.BulkInput[uuid.UUID], len(payload.UserIDs))
+	for i, raw := range payload.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			err = ErrInvalidUserID
+		}
+		inputs[i] = dto.BulkInput[uuid.UUID]{Input: id, Err: err}
+	}
+
+	writeBulk(resWriter, r, func(progress adminUseCase.ProgressFunc) []dto.BulkItemResult {
+		return h.forcePasswordRotationUseCase.Execute(r.Context(), inputs, payload.RevokeSessions, progress)
+	})
+}
+
+// writeBulk streams one JSON line per item when the client accepts NDJSON,
+// otherwise it buffers all results into a single 207 Multi-Status response.
+func writeBulk(w http.ResponseWriter, r *http.Request, exec func(adminUseCase.ProgressFunc) []dto.BulkItemResult) {
+	if r.Header.Get("Accept") == ndjsonContentType {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		exec(func(result dto.BulkItemResult) {
+			enc.Encode(result)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		})
+		return
+	}
+
+	res := bulkResponse{Results: exec(nil)}
+	for _, result := range res.Results {
+		if result.Status == dto.BULK_ITEM_SUCCEEDED {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+
+	status := http.StatusOK
+	if res.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	response.JSON(w, r, res, status)
+}
+
+type disposableDomainsResponse struct {
+	Domains []string `json:"domains"`
+}
+
+func (h *AdminHandler) ListDisposableDomains(resWriter http.ResponseWriter, r *http.Request) {
+	domains := h.disposableDomainsUseCase.List()
+	response.JSON(resWriter, r, disposableDomainsResponse{Domains: domains}, http.StatusOK)
+}
+
+func (h *AdminHandler) ReplaceDisposableDomains(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.ReplaceDisposableDomainsRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	domains := h.disposableDomainsUseCase.Replace(payload.Domains)
+	response.JSON(resWriter, r, disposableDomainsResponse{Domains: domains}, http.StatusOK)
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ImpersonateUser returns a short-lived access token acting as the user in
+// the path.
+func (h *AdminHandler) ImpersonateUser(resWriter http.ResponseWriter, r *http.Request) {
+	targetID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.ImpersonateUserRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	token, err := h.impersonateUserUseCase.Execute(r.Context(), &dto.ImpersonateInput{
+		ActorID:  current.ID,
+		TargetID: targetID,
+		Reason:   payload.Reason,
+		Client:   clientinfo.FromRequest(r),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrCannotImpersonateSelf):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+			errors.Is(err, errs.ErrAccountDeleted):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, token, http.StatusCreated)
+}
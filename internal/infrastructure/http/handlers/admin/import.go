@@ -0,0 +1,218 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	// maxImportLineBytes bounds one JSONL row of an import.
+	maxImportLineBytes = 64 << 10
+	// importRowReadTimeout bounds reading the next row of an import; like
+	// exportChunkWriteTimeout, the deadline moves with every row.
+	importRowReadTimeout = 30 * time.Second
+)
+
+var (
+	ErrUnsupportedImportType = errors.New("import must be text/csv or application/x-ndjson")
+	ErrInvalidImportHeader   = errors.New("import header must name the columns email, password, username, invite_code, terms_version and privacy_version")
+)
+
+// importColumns are the CSV columns an import may have; email and password
+// are required.
+var importColumns = []string{"email", "password", "username", "invite_code", "terms_version", "privacy_version"}
+
+var importReportHeader = []string{"row", "email", "status", "user_id", "error_code", "error"}
+
+type importReportLine struct {
+	Row    int                `json:"row"`
+	Email  string             `json:"email,omitempty"`
+	Status string             `json:"status"`
+	UserID *uuid.UUID         `json:"user_id,omitempty"`
+	Error  *dto.BulkItemError `json:"error,omitempty"`
+}
+
+// ImportUsers signs up the users of a CSV (with a header row) or JSONL
+// upload, one row at a time, without welcome emails. The response is a
+// downloadable report in the upload's format with a line per row; rows are
+// numbered from 1, not counting the CSV header. Rows already imported stay
+// imported if the upload is interrupted.
+func (h *AdminHandler) ImportUsers(resWriter http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var (
+		rows iter.Seq[dto.BulkInput[auth.SignUpRequest]]
+		err  error
+	)
+	switch mediaType {
+	case "text/csv":
+		rows, err = csvImportRows(r.Body)
+	case ndjsonContentType, "application/jsonl":
+		rows = jsonlImportRows(r.Body)
+	default:
+		response.Error(resWriter, r, http.StatusUnsupportedMediaType, ErrUnsupportedImportType)
+		return
+	}
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	exp := &export{
+		name:   "user-import",
+		header: importReportHeader,
+		jsonl:  mediaType != "text/csv",
+		r:      r,
+		w:      resWriter,
+		rc:     http.NewResponseController(resWriter),
+	}
+	// The report streams while the upload is still being read, which
+	// HTTP/1 only allows in full duplex.
+	if err := exp.rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Rows are signed up as they are read, so email is always that of the
+	// row whose result is reported.
+	var (
+		email    string
+		flushErr error
+	)
+	inputs := func(yield func(dto.BulkInput[dto.SignUpInput]) bool) {
+		for row := range rows {
+			if err := exp.rc.SetReadDeadline(time.Now().Add(importRowReadTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return
+			}
+			email = row.Input.Email
+			input := dto.BulkInput[dto.SignUpInput]{Err: row.Err}
+			if input.Err == nil {
+				input.Err = row.Input.Validate()
+			}
+			if input.Err == nil {
+				input.Input = *mapping.SignUpInput(&row.Input)
+			}
+			if !yield(input) {
+				return
+			}
+		}
+	}
+
+	h.bulkUsersUseCase.ImportUsers(r.Context(), inputs, func(result dto.BulkItemResult) {
+		line := importReportLine{Row: result.Index + 1, Email: email, Status: result.Status, Error: result.Error}
+		record := []string{strconv.Itoa(line.Row), email, result.Status, "", "", ""}
+		if result.User != nil {
+			line.UserID = &result.User.ID
+			record[3] = result.User.ID.String()
+		}
+		if result.Error != nil {
+			record[4], record[5] = result.Error.Code, result.Error.Message
+		}
+		exp.write(line, record)
+		if err := exp.flush(); err != nil && flushErr == nil {
+			flushErr = err
+		}
+	})
+	exp.finish(flushErr)
+}
+
+// csvImportRows reads the header row and returns the rows after it. A
+// malformed row fails on its own; reading stops at the first I/O error.
+func csvImportRows(body io.Reader) (iter.Seq[dto.BulkInput[auth.SignUpRequest]], error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportHeader, err)
+	}
+	for _, column := range header {
+		if !slices.Contains(importColumns, column) {
+			return nil, ErrInvalidImportHeader
+		}
+	}
+	if !slices.Contains(header, "email") || !slices.Contains(header, "password") {
+		return nil, ErrInvalidImportHeader
+	}
+
+	return func(yield func(dto.BulkInput[auth.SignUpRequest]) bool) {
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				yield(dto.BulkInput[auth.SignUpRequest]{Err: err})
+				return
+			}
+
+			var row dto.BulkInput[auth.SignUpRequest]
+			if err != nil {
+				row.Err = err
+			} else {
+				for i, value := range record {
+					switch header[i] {
+					case "email":
+						row.Input.Email = value
+					case "password":
+						row.Input.Password = value
+					case "username":
+						row.Input.Username = value
+					case "invite_code":
+						row.Input.InviteCode = value
+					case "terms_version":
+						row.Input.TermsVersion = value
+					case "privacy_version":
+						row.Input.PrivacyVersion = value
+					}
+				}
+			}
+			if !yield(row) {
+				return
+			}
+		}
+	}, nil
+}
+
+// jsonlImportRows returns a row per non-blank line, each a sign-up request
+// object. A malformed line fails on its own; reading stops at the first I/O
+// error or overlong line.
+func jsonlImportRows(body io.Reader) iter.Seq[dto.BulkInput[auth.SignUpRequest]] {
+	return func(yield func(dto.BulkInput[auth.SignUpRequest]) bool) {
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 4096), maxImportLineBytes)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var row dto.BulkInput[auth.SignUpRequest]
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.DisallowUnknownFields()
+			row.Err = dec.Decode(&row.Input)
+			if !yield(row) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(dto.BulkInput[auth.SignUpRequest]{Err: err})
+		}
+	}
+}
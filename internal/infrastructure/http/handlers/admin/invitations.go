@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+func (h *AdminHandler) MintInvitation(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.MintInvitationRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.MintInvitationInput{
+		Email:     payload.Email,
+		MaxUses:   payload.MaxUses,
+		TTL:       time.Duration(payload.ExpiresInHours) * time.Hour,
+		CreatedBy: current.ID,
+	}
+
+	minted, err := h.mintInvitationUseCase.Execute(r.Context(), input)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, minted, http.StatusCreated)
+}
+
+func (h *AdminHandler) ListInvitations(resWriter http.ResponseWriter, r *http.Request) {
+	invitations, err := h.listInvitationsUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, invitations, http.StatusOK)
+}
+
+func (h *AdminHandler) RevokeInvitation(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.revokeInvitationUseCase.Execute(r.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrInvitationNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
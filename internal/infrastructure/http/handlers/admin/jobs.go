@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const jobStatsDefaultWindow = 15 * time.Minute
+
+// GetJobStats reports the depth of the scheduled job queue and its
+// processing and failure rates, by kind.
+func (h *AdminHandler) GetJobStats(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.JobStatsRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	window := payload.Window
+	if window == 0 {
+		window = jobStatsDefaultWindow
+	}
+
+	stats, err := h.getQueueStatsUseCase.Execute(r.Context(), window)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, stats, http.StatusOK)
+}
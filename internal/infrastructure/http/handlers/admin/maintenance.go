@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// GetMaintenance reports whether this instance is read-only.
+func (h *AdminHandler) GetMaintenance(resWriter http.ResponseWriter, r *http.Request) {
+	mode, err := h.getMaintenanceUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, mode, http.StatusOK)
+}
+
+// SetMaintenance turns read-only mode on or off on this instance.
+func (h *AdminHandler) SetMaintenance(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.SetMaintenanceRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	mode, err := h.setMaintenanceUseCase.Execute(r.Context(), current.ID, *payload.ReadOnly, payload.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrInvalidMaintenanceMode) {
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, mode, http.StatusOK)
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RegisterOAuthClient returns the client secret; it cannot be retrieved
+// again.
+func (h *AdminHandler) RegisterOAuthClient(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.RegisterOAuthClientRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.RegisterOAuthClientInput{
+		Name:         payload.Name,
+		Scopes:       payload.Scopes,
+		RedirectURIs: payload.RedirectURIs,
+		Public:       payload.Public,
+		Plan:         payload.Plan,
+		CreatedBy:    current.ID,
+	}
+
+	registered, err := h.registerOAuthClientUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrUnknownPlan) {
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, registered, http.StatusCreated)
+}
+
+func (h *AdminHandler) ListOAuthClients(resWriter http.ResponseWriter, r *http.Request) {
+	clients, err := h.listOAuthClientsUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, clients, http.StatusOK)
+}
+
+func (h *AdminHandler) RevokeOAuthClient(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.revokeOAuthClientUseCase.Execute(r.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrOAuthClientNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// GetOrganizationSettings returns what a tenant sets for its members.
+func (h *AdminHandler) GetOrganizationSettings(resWriter http.ResponseWriter, r *http.Request) {
+	settings, err := h.getOrganizationSettingsUseCase.Execute(r.Context(), chi.URLParam(r, "tenant"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrSAMLConnectionNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, settings, http.StatusOK)
+}
+
+// UpdateOrganizationSettings replaces a tenant's settings.
+func (h *AdminHandler) UpdateOrganizationSettings(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.UpdateOrganizationSettingsRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	settings, err := h.updateOrganizationSettingsUseCase.Execute(r.Context(), &dto.UpdateOrganizationSettingsInput{
+		ActorID:                current.ID,
+		Tenant:                 chi.URLParam(r, "tenant"),
+		AllowedCallbackDomains: payload.AllowedCallbackDomains,
+		DefaultMemberRole:      payload.DefaultMemberRole,
+		RequireMFA:             payload.RequireMFA,
+		SessionMaxAgeSeconds:   payload.SessionMaxAgeSeconds,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrSAMLConnectionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrInvalidCallbackDomain), errors.Is(err, errs.ErrInvalidRole),
+			errors.Is(err, errs.ErrAdminRoleNotAllowed):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, settings, http.StatusOK)
+}
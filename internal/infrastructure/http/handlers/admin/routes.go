@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/infrastructure/http/middleware"
+)
+
+func RegisterRoutes(r chi.Router, h *AdminHandler, mws ...func(http.Handler) http.Handler) {
+	r.Route("/admin", func(ar chi.Router) {
+		ar.Use(mws...)
+
+		ar.Get("/users", h.ListUsers)
+		ar.With(middleware.Unbounded).Get("/users/export", h.ExportUsers)
+		ar.With(middleware.Unbounded).Post("/users/import", h.ImportUsers)
+		ar.Post("/users/bulk", h.BulkCreateUsers)
+		ar.Patch("/users/bulk", h.BulkUpdateUsers)
+		ar.Post("/users/password-rotation", h.ForcePasswordRotation)
+		ar.Post("/users/{id}/impersonate", h.ImpersonateUser)
+		ar.Put("/users/{id}/status", h.SetUserStatus)
+		ar.Post("/users/{id}/revoke-access", h.RevokeUserAccess)
+		ar.Put("/users/{id}/plan", h.SetUserPlan)
+		ar.Get("/users/{id}/tokens", h.ListUserTokens)
+
+		ar.Get("/audit-log", h.ListAuditLog)
+		ar.With(middleware.Unbounded).Get("/audit-log/export", h.ExportAuditLog)
+		ar.With(middleware.Unbounded).Get("/usage/export", h.ExportUsage)
+
+		ar.Get("/sign-up/disposable-domains", h.ListDisposableDomains)
+		ar.Put("/sign-up/disposable-domains", h.ReplaceDisposableDomains)
+
+		ar.Get("/invitations", h.ListInvitations)
+		ar.Post("/invitations", h.MintInvitation)
+		ar.Delete("/invitations/{id}", h.RevokeInvitation)
+
+		ar.Get("/oauth-clients", h.ListOAuthClients)
+		ar.Post("/oauth-clients", h.RegisterOAuthClient)
+		ar.Delete("/oauth-clients/{id}", h.RevokeOAuthClient)
+
+		ar.Get("/emails", h.ListEmails)
+		ar.Get("/emails/{id}", h.GetEmail)
+		ar.Post("/emails/{id}/resend", h.ResendEmail)
+		ar.Get("/email-suppressions", h.ListEmailSuppressions)
+		ar.Post("/email-suppressions", h.AddEmailSuppression)
+		ar.Delete("/email-suppressions/{email}", h.RemoveEmailSuppression)
+		ar.Get("/dead-letters", h.ListDeadLetters)
+		ar.Get("/dead-letters/{id}", h.GetDeadLetter)
+		ar.Post("/dead-letters/{id}/requeue", h.RequeueDeadLetter)
+		ar.Delete("/dead-letters/{id}", h.DiscardDeadLetter)
+		ar.Get("/jobs/stats", h.GetJobStats)
+		ar.Get("/jobs/{id}", h.GetDeadLetter)
+		ar.Get("/tasks", h.ListAdminTasks)
+		ar.Post("/tasks/{name}", h.TriggerAdminTask)
+
+		ar.Get("/stats", h.GetStats)
+
+		ar.Get("/debug/config", h.GetConfig)
+		ar.Get("/status", h.GetStatus)
+		ar.Get("/maintenance", h.GetMaintenance)
+		ar.Put("/maintenance", h.SetMaintenance)
+		ar.Get("/auth-protection", h.GetAuthProtection)
+		ar.Put("/auth-protection", h.SetAuthProtection)
+		ar.Get("/deprecations", h.ListDeprecationUsage)
+
+		ar.Get("/saml-connections", h.ListSAMLConnections)
+		ar.Post("/saml-connections", h.RegisterSAMLConnection)
+		ar.Delete("/saml-connections/{id}", h.DeleteSAMLConnection)
+		ar.Put("/tenants/{tenant}/quota", h.SetTenantQuota)
+		ar.Get("/tenants/{tenant}/settings", h.GetOrganizationSettings)
+		ar.Put("/tenants/{tenant}/settings", h.UpdateOrganizationSettings)
+
+		ar.Get("/service-accounts", h.ListServiceAccounts)
+		ar.Post("/service-accounts", h.CreateServiceAccount)
+		ar.Delete("/service-accounts/{id}", h.DeleteServiceAccount)
+		ar.Get("/service-accounts/{id}/keys", h.ListServiceAccountKeys)
+		ar.Post("/service-accounts/{id}/keys", h.CreateServiceAccountKey)
+		ar.Delete("/service-accounts/{id}/keys/{keyID}", h.RevokeServiceAccountKey)
+	})
+}
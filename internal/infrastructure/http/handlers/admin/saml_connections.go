@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+func (h *AdminHandler) RegisterSAMLConnection(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.RegisterSAMLConnectionRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.RegisterSAMLConnectionInput{
+		Tenant:            payload.Tenant,
+		IdPEntityID:       payload.IdPEntityID,
+		IdPSSOURL:         payload.IdPSSOURL,
+		IdPCertificate:    payload.IdPCertificate,
+		EmailAttribute:    payload.EmailAttribute,
+		UsernameAttribute: payload.UsernameAttribute,
+		JITProvisioning:   payload.JITProvisioning,
+		CreatedBy:         current.ID,
+	}
+
+	conn, err := h.registerSAMLConnectionUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidTenant), errors.Is(err, errs.ErrInvalidCertificate):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrSAMLConnectionExists):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, conn, http.StatusCreated)
+}
+
+func (h *AdminHandler) ListSAMLConnections(resWriter http.ResponseWriter, r *http.Request) {
+	conns, err := h.listSAMLConnectionsUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, conns, http.StatusOK)
+}
+
+func (h *AdminHandler) DeleteSAMLConnection(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.deleteSAMLConnectionUseCase.Execute(r.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrSAMLConnectionNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) SetTenantQuota(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.SetTenantQuotaRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	c, err := h.setTenantQuotaUseCase.Execute(r.Context(), current.ID, chi.URLParam(r, "tenant"), payload.Limits)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrSAMLConnectionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrInvalidQuotaLimits):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, c, http.StatusOK)
+}
@@ -0,0 +1,177 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ListServiceAccounts lists the service accounts of the tenant in the
+// "tenant" query parameter, or of every tenant.
+func (h *AdminHandler) ListServiceAccounts(resWriter http.ResponseWriter, r *http.Request) {
+	accounts, err := h.listServiceAccountsUseCase.Execute(r.Context(), r.URL.Query().Get("tenant"))
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, accounts, http.StatusOK)
+}
+
+func (h *AdminHandler) CreateServiceAccount(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.CreateServiceAccountRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	a, err := h.createServiceAccountUseCase.Execute(r.Context(), &dto.CreateServiceAccountInput{
+		Tenant:      payload.Tenant,
+		Name:        payload.Name,
+		Description: payload.Description,
+		Roles:       payload.Roles,
+		CreatedBy:   current.ID,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidTenant), errors.Is(err, errs.ErrInvalidRole):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrSAMLConnectionNotFound):
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, a, http.StatusCreated)
+}
+
+// DeleteServiceAccount deletes the account in the path and revokes its
+// keys.
+func (h *AdminHandler) DeleteServiceAccount(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	if err := h.deleteServiceAccountUseCase.Execute(r.Context(), current.ID, id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrServiceAccountNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) ListServiceAccountKeys(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	keys, err := h.listServiceAccountKeysUseCase.Execute(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrServiceAccountNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, keys, http.StatusOK)
+}
+
+// CreateServiceAccountKey mints a key for the account in the path; the key
+// is only returned in this response.
+func (h *AdminHandler) CreateServiceAccountKey(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.CreateServiceAccountKeyRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.CreateServiceAccountKeyInput{
+		ServiceAccountID: id,
+		Name:             payload.Name,
+		CreatedBy:        current.ID,
+	}
+	if payload.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, payload.ExpiresInDays)
+		input.ExpiresAt = &expiresAt
+	}
+
+	k, err := h.createServiceAccountKeyUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrServiceAccountNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrServiceAccountKeyLimit):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, k, http.StatusCreated)
+}
+
+func (h *AdminHandler) RevokeServiceAccountKey(resWriter http.ResponseWriter, r *http.Request) {
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	keyID, err := request.ParamUUID(r, "keyID")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	if err := h.revokeServiceAccountKeyUseCase.Execute(r.Context(), current.ID, id, keyID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrServiceAccountKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
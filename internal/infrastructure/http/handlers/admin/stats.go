@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// GetStats serves the user aggregates as last refreshed, with the time of
+// that refresh.
+func (h *AdminHandler) GetStats(resWriter http.ResponseWriter, r *http.Request) {
+	stats, err := h.getStatsUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, stats, http.StatusOK)
+}
@@ -0,0 +1,20 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+)
+
+type statusResponse struct {
+	lifecycle.Report
+	Build buildinfo.Info `json:"build"`
+}
+
+// GetStatus reports the status of this instance, the health of each of its
+// components and the build it runs.
+func (h *AdminHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, r, statusResponse{Report: h.lifecycle.Report(), Build: buildinfo.Get()}, http.StatusOK)
+}
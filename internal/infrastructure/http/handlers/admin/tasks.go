@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ListAdminTasks lists the operational tasks that can be triggered, with the
+// params they take.
+func (h *AdminHandler) ListAdminTasks(resWriter http.ResponseWriter, r *http.Request) {
+	response.JSON(resWriter, r, h.listAdminTasksUseCase.Execute(), http.StatusOK)
+}
+
+// TriggerAdminTask queues a run of a task and answers 202 with its job; the
+// body, {"params": {...}}, may be left out for tasks without params.
+func (h *AdminHandler) TriggerAdminTask(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(admin.TriggerAdminTaskRequest)
+	if err := request.FromJSON(r, payload); err != nil && !errors.Is(err, request.ErrEmptyBody) {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	job, err := h.triggerAdminTaskUseCase.Execute(r.Context(), &dto.TriggerAdminTaskInput{
+		ActorID: current.ID,
+		Name:    chi.URLParam(r, "name"),
+		Params:  payload.Params,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrAdminTaskNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrInvalidAdminTaskParams):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.Header().Set("Location", path.Join(path.Dir(path.Dir(r.URL.Path)), "jobs", job.ID.String()))
+	response.JSON(resWriter, r, job, http.StatusAccepted)
+}
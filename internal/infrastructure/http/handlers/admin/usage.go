@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const usagePeriodLayout = "2006-01"
+
+var ErrInvalidUsagePeriod = errors.New("period must be a month formatted as YYYY-MM")
+
+// ExportUsage writes every user's metered usage for a billing period as CSV
+// for invoicing. The period defaults to the current month.
+func (h *AdminHandler) ExportUsage(resWriter http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if raw := r.URL.Query().Get("period"); raw != "" {
+		parsed, err := time.Parse(usagePeriodLayout, raw)
+		if err != nil {
+			response.Error(resWriter, r, http.StatusBadRequest, ErrInvalidUsagePeriod)
+			return
+		}
+		at = parsed
+	}
+
+	export, err := h.exportUsageUseCase.Execute(r.Context(), at)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := fmt.Sprintf("usage-%s.csv", export.PeriodStart.Format(usagePeriodLayout))
+	resWriter.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	resWriter.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	resWriter.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(resWriter)
+	w.Write([]string{"period_start", "period_end", "user_id", "metric", "quantity"})
+	start := export.PeriodStart.Format(time.RFC3339)
+	end := export.PeriodEnd.Format(time.RFC3339)
+	for _, rec := range export.Records {
+		w.Write([]string{start, end, rec.UserID.String(), rec.Metric, strconv.FormatInt(rec.Quantity, 10)})
+	}
+	w.Flush()
+}
@@ -0,0 +1,211 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/admin"
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	userListDefaultLimit = 50
+	userListMaxLimit     = 200
+)
+
+// ListUsers lists users, newest first, filtered by the q (email or username
+// substring), status, plan and tenant query parameters. ?expand=organization
+// and ?expand=sessions embed each user's tenant and active sessions.
+func (h *AdminHandler) ListUsers(resWriter http.ResponseWriter, r *http.Request) {
+	limit, offset, err := request.Pagination(r, userListDefaultLimit, userListMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.UserFilterRequest)
+	if err := request.FromQuery(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	expand, err := request.Expand(r, "organization", "sessions")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listUsersUseCase.Execute(r.Context(), *mapping.UserFilter(payload), dto.UserExpand{
+		Organization: expand["organization"],
+		Sessions:     expand["sessions"],
+	}, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		query := r.URL.Query()
+		query.Set("limit", fmt.Sprint(limit))
+		query.Set("offset", fmt.Sprint(next))
+		response.AddLink(r, "next", r.URL.Path+"?"+query.Encode())
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
+
+// SetUserStatus activates, suspends or bans the user in the path.
+func (h *AdminHandler) SetUserStatus(resWriter http.ResponseWriter, r *http.Request) {
+	userID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.SetUserStatusRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	u, err := h.setUserStatusUseCase.Execute(r.Context(), &dto.SetUserStatusInput{
+		ActorID: current.ID,
+		UserID:  userID,
+		Status:  payload.Status,
+		Reason:  payload.Reason,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrInvalidStatus), errors.Is(err, errs.ErrCannotLockSelf):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, u, http.StatusOK)
+}
+
+// RevokeUserAccess signs the user in the path out everywhere and makes them
+// set a new password, for compromised accounts. The body, with the reason,
+// is optional.
+func (h *AdminHandler) RevokeUserAccess(resWriter http.ResponseWriter, r *http.Request) {
+	userID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.RevokeUserAccessRequest)
+	if err := request.FromJSON(r, payload); err != nil && !errors.Is(err, request.ErrEmptyBody) {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	result, err := h.revokeUserAccessUseCase.Execute(r.Context(), &dto.RevokeUserAccessInput{
+		ActorID: current.ID,
+		UserID:  userID,
+		Reason:  payload.Reason,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrCannotRevokeSelf):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, result, http.StatusOK)
+}
+
+// SetUserPlan moves the user in the path to another quota plan.
+func (h *AdminHandler) SetUserPlan(resWriter http.ResponseWriter, r *http.Request) {
+	userID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(admin.SetUserPlanRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	u, err := h.setUserPlanUseCase.Execute(r.Context(), current.ID, userID, payload.Plan)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrUnknownPlan):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, u, http.StatusOK)
+}
+
+type userTokensResponse struct {
+	Tokens []dto.SecurityToken `json:"tokens"`
+}
+
+// ListUserTokens lists the password reset, email change and personal access
+// tokens issued to the user in the path, with their issue and expiry times,
+// for support to see why a link was refused.
+func (h *AdminHandler) ListUserTokens(resWriter http.ResponseWriter, r *http.Request) {
+	userID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	tokens, err := h.listUserTokensUseCase.Execute(r.Context(), userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, userTokensResponse{Tokens: tokens}, http.StatusOK)
+}
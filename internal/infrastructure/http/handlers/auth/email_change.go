@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ConfirmEmailChange and RevertEmailChange are the targets of the links in
+// email change messages, hence GET and no authentication.
+func (h *AuthHandler) ConfirmEmailChange(resWriter http.ResponseWriter, r *http.Request) {
+	h.emailChangeLink(resWriter, r, h.confirmEmailChangeUseCase.Execute)
+}
+
+func (h *AuthHandler) RevertEmailChange(resWriter http.ResponseWriter, r *http.Request) {
+	h.emailChangeLink(resWriter, r, h.revertEmailChangeUseCase.Execute)
+}
+
+func (h *AuthHandler) emailChangeLink(
+	resWriter http.ResponseWriter,
+	r *http.Request,
+	execute func(ctx context.Context, token string) (*entity.EmailChange, error),
+) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.Error(resWriter, r, http.StatusBadRequest, errs.ErrInvalidEmailChangeToken)
+		return
+	}
+
+	change, err := execute(r.Context(), token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidEmailChangeToken):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrEmailTaken):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, change, http.StatusOK)
+}
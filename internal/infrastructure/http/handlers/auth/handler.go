@@ -1,52 +1,344 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/internal/api/mapping"
 	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	accountUseCase "github.com/haidang666/go-app/internal/domain/use_case/account"
 	authUseCase "github.com/haidang666/go-app/internal/domain/use_case/auth"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/internal/infrastructure/http/middleware"
 	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
 )
 
 type NewAuthHandlerArgs struct {
-	SignUpUseCase *authUseCase.SignUpUseCase
+	SignUpUseCase                *authUseCase.SignUpUseCase
+	IssueSignUpFormUseCase       *authUseCase.IssueSignUpFormUseCase
+	SignInUseCase                *authUseCase.SignInUseCase
+	RefreshTokensUseCase         *authUseCase.RefreshTokensUseCase
+	ReviewDeviceUseCase          *authUseCase.ReviewDeviceUseCase
+	ForgotPasswordUseCase        *authUseCase.ForgotPasswordUseCase
+	ResetPasswordUseCase         *authUseCase.ResetPasswordUseCase
+	ConfirmEmailChangeUseCase    *accountUseCase.ConfirmEmailChangeUseCase
+	RevertEmailChangeUseCase     *accountUseCase.RevertEmailChangeUseCase
+	RestoreAccountUseCase        *accountUseCase.RestoreAccountUseCase
+	RequestSignInCodeUseCase     *authUseCase.RequestSignInCodeUseCase
+	SignInWithCodeUseCase        *authUseCase.SignInWithCodeUseCase
+	StartGuestSessionUseCase     *authUseCase.StartGuestSessionUseCase
+	RotateExpiredPasswordUseCase *authUseCase.RotateExpiredPasswordUseCase
 }
 
 type AuthHandler struct {
-	signUpUseCase *authUseCase.SignUpUseCase
+	signUpUseCase                *authUseCase.SignUpUseCase
+	issueSignUpFormUseCase       *authUseCase.IssueSignUpFormUseCase
+	signInUseCase                *authUseCase.SignInUseCase
+	refreshTokensUseCase         *authUseCase.RefreshTokensUseCase
+	reviewDeviceUseCase          *authUseCase.ReviewDeviceUseCase
+	forgotPasswordUseCase        *authUseCase.ForgotPasswordUseCase
+	resetPasswordUseCase         *authUseCase.ResetPasswordUseCase
+	confirmEmailChangeUseCase    *accountUseCase.ConfirmEmailChangeUseCase
+	revertEmailChangeUseCase     *accountUseCase.RevertEmailChangeUseCase
+	restoreAccountUseCase        *accountUseCase.RestoreAccountUseCase
+	requestSignInCodeUseCase     *authUseCase.RequestSignInCodeUseCase
+	signInWithCodeUseCase        *authUseCase.SignInWithCodeUseCase
+	startGuestSessionUseCase     *authUseCase.StartGuestSessionUseCase
+	rotateExpiredPasswordUseCase *authUseCase.RotateExpiredPasswordUseCase
 }
 
 func NewAuthHandler(args NewAuthHandlerArgs) *AuthHandler {
 	return &AuthHandler{
-		signUpUseCase: args.SignUpUseCase,
+		signUpUseCase:                args.SignUpUseCase,
+		issueSignUpFormUseCase:       args.IssueSignUpFormUseCase,
+		signInUseCase:                args.SignInUseCase,
+		refreshTokensUseCase:         args.RefreshTokensUseCase,
+		reviewDeviceUseCase:          args.ReviewDeviceUseCase,
+		forgotPasswordUseCase:        args.ForgotPasswordUseCase,
+		resetPasswordUseCase:         args.ResetPasswordUseCase,
+		confirmEmailChangeUseCase:    args.ConfirmEmailChangeUseCase,
+		revertEmailChangeUseCase:     args.RevertEmailChangeUseCase,
+		restoreAccountUseCase:        args.RestoreAccountUseCase,
+		requestSignInCodeUseCase:     args.RequestSignInCodeUseCase,
+		signInWithCodeUseCase:        args.SignInWithCodeUseCase,
+		startGuestSessionUseCase:     args.StartGuestSessionUseCase,
+		rotateExpiredPasswordUseCase: args.RotateExpiredPasswordUseCase,
 	}
 }
 
 func (h *AuthHandler) SignUp(resWriter http.ResponseWriter, r *http.Request) {
 	payload := new(auth.SignUpRequest)
 
-	if err := request.FromJSON(r, payload); err != nil {
-		request.ToJSON(resWriter, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
 		return
 	}
 
 	if err := payload.Validate(); err != nil {
-		request.ToJSON(resWriter, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		response.Error(resWriter, r, http.StatusBadRequest, err)
 		return
 	}
 
-	// Convert API DTO to domain DTO
-	input := &dto.SignUpInput{
-		Email:    payload.Email,
-		Password: payload.Password,
+	input := mapping.SignUpInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+	input.Bot = &dto.BotSignals{
+		Honeypot:     payload.Website,
+		FormToken:    payload.FormToken,
+		CaptchaToken: r.Header.Get(middleware.CAPTCHA_HEADER),
+		Protected:    middleware.AuthProtected(r.Context()),
 	}
 
 	user, err := h.signUpUseCase.Execute(r.Context(), input)
 	if err != nil {
-		request.ToJSON(resWriter, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errs.ErrEmailTaken), errors.Is(err, errs.ErrEmailAliasTaken),
+			errors.Is(err, errs.ErrUsernameTaken):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrEmailDomainNotAllowed), errors.Is(err, errs.ErrDisposableEmail):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrInviteRequired), errors.Is(err, errs.ErrInvalidInvite):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrTermsNotAccepted), errors.Is(err, errs.ErrTermsOutdated),
+			errors.Is(err, errs.ErrWeakPassword):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrCaptchaFailed), errors.Is(err, errs.ErrSignUpBlocked):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrPasswordHashingBusy), errors.Is(err, errs.ErrCaptchaUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
 		return
 	}
 
-	request.ToJSON(resWriter, user, http.StatusCreated)
+	response.JSON(resWriter, r, user, http.StatusCreated)
+}
+
+// SignUpForm issues the form token a sign-up form should be shown with.
+func (h *AuthHandler) SignUpForm(resWriter http.ResponseWriter, r *http.Request) {
+	form, err := h.issueSignUpFormUseCase.Execute(r.Context())
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, form, http.StatusOK)
+}
+
+func (h *AuthHandler) SignIn(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.SignInRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	input := mapping.SignInInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+
+	tokens, err := h.signInUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			status = http.StatusUnauthorized
+		case errors.Is(err, errs.ErrDeviceVerificationRequired), errors.Is(err, errs.ErrPasswordExpired),
+			errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+			errors.Is(err, errs.ErrAccountDeleted):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusOK)
+}
+
+// RotatePassword replaces an expired password and signs the user in, the
+// step a sign-in answered with ErrPasswordExpired leads to.
+func (h *AuthHandler) RotatePassword(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.RotatePasswordRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	input := mapping.RotatePasswordInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+
+	tokens, err := h.rotateExpiredPasswordUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			status = http.StatusUnauthorized
+		case errors.Is(err, errs.ErrDeviceVerificationRequired),
+			errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+			errors.Is(err, errs.ErrAccountDeleted):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrPasswordNotExpired):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrPasswordReused), errors.Is(err, errs.ErrWeakPassword):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusOK)
+}
+
+func (h *AuthHandler) Refresh(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.RefreshRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	input := mapping.RefreshTokensInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+
+	tokens, err := h.refreshTokensUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidToken), errors.Is(err, errs.ErrSessionRevoked),
+			errors.Is(err, errs.ErrSessionTooOld):
+			status = http.StatusUnauthorized
+		case errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+			errors.Is(err, errs.ErrAccountDeleted):
+			status = http.StatusForbidden
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusOK)
+}
+
+// ApproveDevice and DenyDevice are the targets of the links in new device
+// alert emails, hence GET.
+func (h *AuthHandler) ApproveDevice(resWriter http.ResponseWriter, r *http.Request) {
+	h.reviewDevice(resWriter, r, true)
+}
+
+func (h *AuthHandler) DenyDevice(resWriter http.ResponseWriter, r *http.Request) {
+	h.reviewDevice(resWriter, r, false)
+}
+
+func (h *AuthHandler) reviewDevice(resWriter http.ResponseWriter, r *http.Request, approve bool) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.Error(resWriter, r, http.StatusBadRequest, errs.ErrDeviceApprovalNotFound)
+		return
+	}
+
+	approval, err := h.reviewDeviceUseCase.Execute(r.Context(), token, approve)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrDeviceApprovalNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrDeviceApprovalDecided):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, approval, http.StatusOK)
+}
+
+// ForgotPassword always answers 202 so callers cannot tell whether the email
+// belongs to an account.
+func (h *AuthHandler) ForgotPassword(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.ForgotPasswordRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.forgotPasswordUseCase.Execute(r.Context(), payload.Email); err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) ResetPassword(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.ResetPasswordRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	input := mapping.ResetPasswordInput(payload)
+
+	if err := h.resetPasswordUseCase.Execute(r.Context(), input); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidResetToken):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrWeakPassword):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+// Guest starts an anonymous session without credentials.
+func (h *AuthHandler) Guest(resWriter http.ResponseWriter, r *http.Request) {
+	tokens, err := h.startGuestSessionUseCase.Execute(r.Context(), clientinfo.FromRequest(r))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrGuestAccessDisabled) {
+			status = http.StatusForbidden
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusCreated)
 }
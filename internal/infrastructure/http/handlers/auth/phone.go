@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RequestSignInCode answers 202 whether or not the number belongs to an
+// account or was sent a code too recently, like ForgotPassword.
+func (h *AuthHandler) RequestSignInCode(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.RequestSignInCodeRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.requestSignInCodeUseCase.Execute(r.Context(), payload.Phone); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidPhone):
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) SignInWithCode(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.PhoneSignInRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	input := mapping.PhoneSignInInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+
+	tokens, err := h.signInWithCodeUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidOTP):
+			status = http.StatusUnauthorized
+		case errors.Is(err, errs.ErrDeviceVerificationRequired),
+			errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+			errors.Is(err, errs.ErrAccountDeleted):
+			status = http.StatusForbidden
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusOK)
+}
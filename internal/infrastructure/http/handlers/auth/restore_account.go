@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/auth"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RestoreAccount restores a deleted account with the token of the link
+// emailed at deletion; the user then signs in as usual.
+func (h *AuthHandler) RestoreAccount(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(auth.RestoreAccountRequest)
+
+	if err := request.Bind(r, payload); err != nil {
+		response.Error(resWriter, r, request.BindStatus(err), err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.restoreAccountUseCase.Execute(r.Context(), payload.Token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrInvalidRestoreToken) || errors.Is(err, errs.ErrUserNotFound) {
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, user, http.StatusOK)
+}
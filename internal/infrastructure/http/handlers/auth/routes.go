@@ -1,11 +1,34 @@
 package auth
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/infrastructure/http/middleware"
 )
 
-func RegisterRoutes(r chi.Router, h *AuthHandler) {
+// RegisterRoutes mounts the auth endpoints. captcha guards the endpoints
+// that are attractive to bots, and signUpCaptcha sign-up, which bot
+// detection may guard instead. gate caps the concurrent password hashing of
+// sign-up and sign-in, after the CAPTCHA so rejected bots hold no slot.
+// protection tightens both while their failures spike; sign-ups it leaves
+// to signUpCaptcha or bot detection, which must not verify a token twice.
+func RegisterRoutes(r chi.Router, h *AuthHandler, captcha, signUpCaptcha func(http.Handler) http.Handler, gate *middleware.CredentialGate, protection *middleware.AuthProtection) {
 	r.Route("/auth", func(ur chi.Router) {
-		ur.Post("/sign-up", h.SignUp)
+		ur.With(protection.Route(middleware.CREDENTIAL_ROUTE_SIGN_UP, nil), signUpCaptcha, gate.Route(middleware.CREDENTIAL_ROUTE_SIGN_UP)).Post("/sign-up", h.SignUp)
+		ur.Get("/sign-up/form", h.SignUpForm)
+		ur.With(protection.Route(middleware.CREDENTIAL_ROUTE_SIGN_IN, captcha), gate.Route(middleware.CREDENTIAL_ROUTE_SIGN_IN)).Post("/sign-in", h.SignIn)
+		ur.Post("/password/rotate", h.RotatePassword)
+		ur.Post("/refresh", h.Refresh)
+		ur.With(captcha).Post("/guest", h.Guest)
+		ur.With(captcha).Post("/phone/code", h.RequestSignInCode)
+		ur.Post("/phone/sign-in", h.SignInWithCode)
+		ur.Get("/devices/approve", h.ApproveDevice)
+		ur.Get("/devices/deny", h.DenyDevice)
+		ur.With(captcha).Post("/forgot-password", h.ForgotPassword)
+		ur.Post("/reset-password", h.ResetPassword)
+		ur.Get("/email-change/confirm", h.ConfirmEmailChange)
+		ur.Get("/email-change/revert", h.RevertEmailChange)
+		ur.Post("/restore-account", h.RestoreAccount)
 	})
 }
@@ -0,0 +1,147 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/haidang666/go-app/internal/api/batch"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const BATCH_PATH = "/api/v1/batch"
+
+// inBatchKey marks the sub-requests of a batch, which may not be batches
+// themselves.
+var inBatchKey = ctxutil.NewKey[bool]("in_batch")
+
+var errNestedBatch = errors.New("nested batch requests are not allowed")
+
+type NewBatchHandlerArgs struct {
+	// Router executes the sub-requests. It is the same router the batch
+	// endpoint is mounted on, so sub-requests go through the full middleware stack.
+	Router      http.Handler
+	MaxRequests int
+	Concurrency int
+}
+
+type BatchHandler struct {
+	router      http.Handler
+	maxRequests int
+	concurrency int
+}
+
+func NewBatchHandler(args NewBatchHandlerArgs) *BatchHandler {
+	return &BatchHandler{
+		router:      args.Router,
+		maxRequests: args.MaxRequests,
+		concurrency: max(args.Concurrency, 1),
+	}
+}
+
+func (h *BatchHandler) Execute(resWriter http.ResponseWriter, r *http.Request) {
+	if inBatch, _ := ctxutil.Get(r.Context(), inBatchKey); inBatch {
+		response.Error(resWriter, r, http.StatusBadRequest, errNestedBatch)
+		return
+	}
+
+	payload := new(batch.BatchRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(h.maxRequests); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res := batch.BatchResponse{Responses: make([]batch.SubResponse, len(payload.Requests))}
+	sem := make(chan struct{}, h.concurrency)
+	var wg sync.WaitGroup
+
+	for i, sub := range payload.Requests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res.Responses[i] = h.serve(r, sub)
+		}()
+	}
+	wg.Wait()
+
+	response.JSON(resWriter, r, res, http.StatusOK)
+}
+
+func (h *BatchHandler) serve(parent *http.Request, sub batch.SubRequest) batch.SubResponse {
+	// Drop the parent's routing context so the router resolves the sub-request
+	// path from scratch.
+	ctx := context.WithValue(parent.Context(), chi.RouteCtxKey, nil)
+	ctx = ctxutil.With(ctx, inBatchKey, true)
+	subReq, err := http.NewRequestWithContext(ctx, sub.Method, sub.Path, bytes.NewReader(sub.Body))
+	if err != nil {
+		return errorResponse(parent, sub.ID, http.StatusBadRequest, err.Error())
+	}
+	if subReq.URL.Scheme != "" || subReq.URL.Host != "" {
+		return errorResponse(parent, sub.ID, http.StatusBadRequest, "path must not name a scheme or host")
+	}
+	if p := path.Clean(subReq.URL.Path); p == BATCH_PATH || strings.HasPrefix(p, BATCH_PATH+"/") {
+		return errorResponse(parent, sub.ID, http.StatusBadRequest, errNestedBatch.Error())
+	}
+
+	// Sub-requests inherit the caller's credentials and client metadata.
+	for k, v := range parent.Header {
+		if k != "Content-Length" {
+			subReq.Header[k] = v
+		}
+	}
+	for k, v := range sub.Headers {
+		subReq.Header.Set(k, v)
+	}
+	if len(sub.Body) > 0 && subReq.Header.Get("Content-Type") == "" {
+		subReq.Header.Set("Content-Type", "application/json")
+	}
+	subReq.RemoteAddr = parent.RemoteAddr
+
+	rec := httptest.NewRecorder()
+	h.router.ServeHTTP(rec, subReq)
+
+	out := batch.SubResponse{
+		ID:      sub.ID,
+		Status:  rec.Code,
+		Headers: make(map[string]string, len(rec.Header())),
+	}
+	for k := range rec.Header() {
+		out.Headers[k] = rec.Header().Get(k)
+	}
+
+	body := bytes.TrimSpace(rec.Body.Bytes())
+	if json.Valid(body) {
+		out.Body = body
+	} else if len(body) > 0 {
+		out.Body, _ = json.Marshal(string(body))
+	}
+	return out
+}
+
+func errorResponse(parent *http.Request, id string, status int, msg string) batch.SubResponse {
+	body, _ := json.Marshal(response.NewProblem(parent, status, msg))
+	return batch.SubResponse{
+		ID:      id,
+		Status:  status,
+		Headers: map[string]string{"Content-Type": response.PROBLEM_CONTENT_TYPE},
+		Body:    body,
+	}
+}
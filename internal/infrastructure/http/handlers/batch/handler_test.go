@@ -0,0 +1,68 @@
+package batch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/haidang666/go-app/internal/api/batch"
+)
+
+// newTestRouter mounts h at BATCH_PATH, and again at an alias the path
+// guard does not know, next to a plain endpoint.
+func newTestRouter(h *BatchHandler) chi.Router {
+	r := chi.NewRouter()
+	r.Post(BATCH_PATH, h.Execute)
+	r.Post("/api/v1/alias", h.Execute)
+	r.Get("/api/v1/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	})
+	return r
+}
+
+func TestBatchRefusesNestedBatches(t *testing.T) {
+	h := NewBatchHandler(NewBatchHandlerArgs{MaxRequests: 10, Concurrency: 2})
+	r := newTestRouter(h)
+	h.router = r
+
+	parent := httptest.NewRequest(http.MethodPost, BATCH_PATH, nil)
+	for _, p := range []string{"//evil/api/v1/batch", "http://evil/api/v1/batch"} {
+		if got := h.serve(parent, batch.SubRequest{Method: http.MethodPost, Path: p}); got.Status != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", p, got.Status)
+		}
+	}
+
+	body := `{"requests":[
+		{"id":"ping","method":"GET","path":"/api/v1/ping"},
+		{"id":"direct","method":"POST","path":"/api/v1/batch?x=1","body":{"requests":[]}},
+		{"id":"dots","method":"POST","path":"/api/v1/./batch","body":{"requests":[]}},
+		{"id":"alias","method":"POST","path":"/api/v1/alias","body":{"requests":[{"id":"p","method":"GET","path":"/api/v1/ping"}]}}
+	]}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, BATCH_PATH, strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch status %d: %s", rec.Code, rec.Body)
+	}
+	var res batch.BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]int{"ping": http.StatusOK, "direct": http.StatusBadRequest, "dots": http.StatusBadRequest, "alias": http.StatusBadRequest}
+	for _, sub := range res.Responses {
+		if sub.Status != want[sub.ID] {
+			t.Errorf("%s: status %d, want %d", sub.ID, sub.Status, want[sub.ID])
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, BATCH_PATH,
+		strings.NewReader(`{"requests":[{"id":"a","method":"POST","path":"//evil/api/v1/batch"}]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("//host sub-path: batch status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,9 @@
+package batch
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterRoutes(r chi.Router, h *BatchHandler) {
+	r.Post("/batch", h.Execute)
+}
@@ -0,0 +1,119 @@
+package billing
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/billing"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	billingUseCase "github.com/haidang666/go-app/internal/domain/use_case/billing"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// maxWebhookSize bounds webhook deliveries; Stripe events are far smaller.
+const maxWebhookSize = 256 << 10
+
+type NewBillingHandlerArgs struct {
+	CreateCheckoutSessionUseCase *billingUseCase.CreateCheckoutSessionUseCase
+	HandleWebhookUseCase         *billingUseCase.HandleWebhookUseCase
+	GetSubscriptionUseCase       *billingUseCase.GetSubscriptionUseCase
+}
+
+type BillingHandler struct {
+	createCheckoutSessionUseCase *billingUseCase.CreateCheckoutSessionUseCase
+	handleWebhookUseCase         *billingUseCase.HandleWebhookUseCase
+	getSubscriptionUseCase       *billingUseCase.GetSubscriptionUseCase
+}
+
+func NewBillingHandler(args NewBillingHandlerArgs) *BillingHandler {
+	return &BillingHandler{
+		createCheckoutSessionUseCase: args.CreateCheckoutSessionUseCase,
+		handleWebhookUseCase:         args.HandleWebhookUseCase,
+		getSubscriptionUseCase:       args.GetSubscriptionUseCase,
+	}
+}
+
+// Enabled reports whether a billing provider is configured; the billing
+// routes are only mounted then.
+func (h *BillingHandler) Enabled() bool {
+	return h.createCheckoutSessionUseCase != nil
+}
+
+// Checkout returns the URL of a hosted checkout page for the requested plan.
+func (h *BillingHandler) Checkout(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(billing.CheckoutRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	session, err := h.createCheckoutSessionUseCase.Execute(r.Context(), current.ID, payload.Plan)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrUnknownBillingPlan):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrAlreadySubscribed):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrBillingProviderFailed):
+			status = http.StatusBadGateway
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, session, http.StatusCreated)
+}
+
+func (h *BillingHandler) Subscription(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	sub, err := h.getSubscriptionUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrSubscriptionNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, sub, http.StatusOK)
+}
+
+// Webhook receives the provider's events. The signature covers the exact
+// bytes sent, so the body is read raw. Failures other than a bad signature
+// answer 500 so the provider retries the delivery, as does the 409 to a
+// repeat of a delivery still being processed.
+func (h *BillingHandler) Webhook(resWriter http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(resWriter, r.Body, maxWebhookSize))
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.handleWebhookUseCase.Execute(r.Context(), payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidWebhookSignature):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrWebhookInProgress):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,24 @@
+package billing
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the provider webhook at the root, outside the
+// versioned API; it is authenticated by its signature.
+func RegisterRoutes(r chi.Router, h *BillingHandler) {
+	if h.Enabled() {
+		r.Post("/billing/webhook", h.Webhook)
+	}
+}
+
+// RegisterAPIRoutes mounts the endpoints for a signed-in user.
+func RegisterAPIRoutes(r chi.Router, h *BillingHandler) {
+	if !h.Enabled() {
+		return
+	}
+	r.Route("/billing", func(br chi.Router) {
+		br.Post("/checkout", h.Checkout)
+		br.Get("/subscription", h.Subscription)
+	})
+}
@@ -0,0 +1,43 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// DashboardHandler serves the admin dashboard, a single page app that calls
+// the admin API with the signed-in admin's token. The files themselves are
+// public; the data is guarded by the admin API's role check.
+type DashboardHandler struct {
+	files http.Handler
+}
+
+func NewDashboardHandler() *DashboardHandler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return &DashboardHandler{files: http.FileServerFS(root)}
+}
+
+// Redirect sends /admin to /admin/ so the page's relative asset URLs
+// resolve.
+func (h *DashboardHandler) Redirect(resWriter http.ResponseWriter, r *http.Request) {
+	http.Redirect(resWriter, r, DASHBOARD_PATH+"/", http.StatusMovedPermanently)
+}
+
+// Serve serves the dashboard files. The content security policy only allows
+// the dashboard's own scripts and API, so injected markup cannot run or
+// leak the token, and framing is refused.
+func (h *DashboardHandler) Serve(resWriter http.ResponseWriter, r *http.Request) {
+	header := resWriter.Header()
+	header.Set("Content-Security-Policy", "default-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Cache-Control", "no-cache")
+	http.StripPrefix(DASHBOARD_PATH, h.files).ServeHTTP(resWriter, r)
+}
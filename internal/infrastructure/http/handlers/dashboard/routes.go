@@ -0,0 +1,13 @@
+package dashboard
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// DASHBOARD_PATH is where the dashboard is mounted.
+const DASHBOARD_PATH = "/admin"
+
+func RegisterRoutes(r chi.Router, h *DashboardHandler) {
+	r.Get(DASHBOARD_PATH, h.Redirect)
+	r.Get(DASHBOARD_PATH+"/*", h.Serve)
+}
@@ -0,0 +1,238 @@
+// Admin dashboard. It talks to the admin API with the signed-in admin's
+// bearer token, which lives in sessionStorage so it is dropped with the tab.
+"use strict";
+
+const API = "/api/v1";
+const PAGE_SIZE = 50;
+const TOKEN_KEY = "admin_token";
+const VIEWS = ["users", "audit", "emails"];
+
+const $ = (sel, root = document) => root.querySelector(sel);
+
+// offsets holds the current page offset of each list view.
+const offsets = { users: 0, audit: 0, emails: 0 };
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+async function api(method, path, body) {
+  const headers = { Accept: "application/json" };
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) headers.Authorization = "Bearer " + token;
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+
+  const res = await fetch(API + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const text = await res.text();
+  const json = text ? JSON.parse(text) : null;
+  if (!res.ok) {
+    throw new APIError(res.status, (json && (json.detail || json.title)) || res.statusText);
+  }
+  // Unwrap the response envelope when the API version uses one.
+  return json && json.data !== undefined && !Array.isArray(json.items) ? json.data : json;
+}
+
+function showError(err) {
+  const el = $("#error");
+  if (!err) {
+    el.hidden = true;
+    return;
+  }
+  if (err instanceof APIError && err.status === 401) {
+    signOut();
+  }
+  el.textContent = err.message;
+  el.hidden = false;
+}
+
+function signOut() {
+  sessionStorage.removeItem(TOKEN_KEY);
+  route();
+}
+
+function cell(tr, value) {
+  const td = tr.insertCell();
+  td.textContent = value == null ? "" : String(value);
+  return td;
+}
+
+function button(td, label, onClick) {
+  const b = document.createElement("button");
+  b.type = "button";
+  b.textContent = label;
+  b.addEventListener("click", () => onClick().catch(showError));
+  td.appendChild(b);
+}
+
+function when(ts) {
+  return ts ? new Date(ts).toLocaleString() : "";
+}
+
+// list fetches one page of view from path with the filter form's values and
+// renders its rows with renderRow.
+async function list(view, path, renderRow) {
+  const section = $("#" + view);
+  const params = new URLSearchParams();
+  for (const [k, v] of new FormData($("form.filters", section))) {
+    if (v) params.set(k, v);
+  }
+  params.set("limit", PAGE_SIZE);
+  params.set("offset", offsets[view]);
+
+  const page = await api("GET", path + "?" + params);
+  const tbody = $("tbody", section);
+  tbody.replaceChildren();
+  for (const item of page.items) {
+    renderRow(tbody.insertRow(), item);
+  }
+
+  const pager = $(".pager", section);
+  pager.replaceChildren();
+  const from = page.total ? page.offset + 1 : 0;
+  const to = page.offset + page.items.length;
+  if (page.offset > 0) {
+    button(pager, "Previous", () => {
+      offsets[view] = Math.max(0, page.offset - page.limit);
+      return load(view);
+    });
+  }
+  if (to < page.total) {
+    button(pager, "Next", () => {
+      offsets[view] = to;
+      return load(view);
+    });
+  }
+  pager.append(`${from}-${to} of ${page.total}`);
+}
+
+function loadUsers() {
+  return list("users", "/admin/users", (tr, u) => {
+    cell(tr, u.email);
+    cell(tr, u.username);
+    cell(tr, u.status);
+    cell(tr, u.plan);
+    cell(tr, u.tenant_id);
+    cell(tr, when(u.created_at));
+    const td = tr.insertCell();
+    td.className = "actions";
+    for (const status of ["active", "suspended", "banned"]) {
+      if (status === u.status) continue;
+      button(td, status === "active" ? "Activate" : status === "suspended" ? "Suspend" : "Ban", async () => {
+        const reason = prompt(`Reason for setting ${u.email} to ${status}:`);
+        if (reason === null) return;
+        await api("PUT", `/admin/users/${u.id}/status`, { status, reason });
+        await loadUsers();
+      });
+    }
+    button(td, "Plan", async () => {
+      const plan = prompt(`Plan for ${u.email} (empty for the default):`, u.plan || "");
+      if (plan === null) return;
+      await api("PUT", `/admin/users/${u.id}/plan`, { plan });
+      await loadUsers();
+    });
+    button(td, "Audit", () => {
+      $("#audit form.filters").user_id.value = u.id;
+      offsets.audit = 0;
+      location.hash = "#audit";
+      return Promise.resolve();
+    });
+  });
+}
+
+function loadAudit() {
+  return list("audit", "/admin/audit-log", (tr, e) => {
+    cell(tr, when(e.created_at));
+    cell(tr, e.action);
+    cell(tr, e.actor_id);
+    cell(tr, e.subject_id);
+    cell(tr, e.method ? `${e.method} ${e.path} ${e.status || ""}` : "");
+    cell(tr, [e.detail, e.reason].filter(Boolean).join(" - "));
+  });
+}
+
+function loadEmails() {
+  return list("emails", "/admin/emails", (tr, m) => {
+    cell(tr, when(m.created_at));
+    cell(tr, m.to);
+    cell(tr, m.subject);
+    cell(tr, m.feedback ? `${m.status} (${m.feedback})` : m.status);
+    cell(tr, m.attempts);
+    cell(tr, m.status === "queued" ? when(m.next_attempt_at) : "");
+    cell(tr, m.last_error);
+    const td = tr.insertCell();
+    td.className = "actions";
+    if (m.status === "failed" || m.status === "bounced") {
+      button(td, "Resend", async () => {
+        await api("POST", `/admin/emails/${m.id}/resend`);
+        await loadEmails();
+      });
+    }
+  });
+}
+
+const loaders = { users: loadUsers, audit: loadAudit, emails: loadEmails };
+
+function load(view) {
+  showError(null);
+  return loaders[view]().catch(showError);
+}
+
+function route() {
+  const signedIn = Boolean(sessionStorage.getItem(TOKEN_KEY));
+  let view = location.hash.slice(1);
+  if (!VIEWS.includes(view)) view = "users";
+
+  $("#nav").hidden = !signedIn;
+  $("#sign-in").hidden = signedIn;
+  for (const v of VIEWS) {
+    $("#" + v).hidden = !signedIn || v !== view;
+    $(`nav a[href="#${v}"]`).classList.toggle("active", v === view);
+  }
+  if (signedIn) load(view);
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("#sign-in-form").addEventListener("submit", async (ev) => {
+    ev.preventDefault();
+    const form = ev.target;
+    try {
+      const tokens = await api("POST", "/auth/sign-in", {
+        email: form.email.value,
+        password: form.password.value,
+      });
+      form.reset();
+      sessionStorage.setItem(TOKEN_KEY, tokens.access_token);
+      showError(null);
+      route();
+    } catch (err) {
+      showError(err);
+    }
+  });
+
+  $("#token-form").addEventListener("submit", (ev) => {
+    ev.preventDefault();
+    sessionStorage.setItem(TOKEN_KEY, ev.target.token.value.trim());
+    ev.target.reset();
+    showError(null);
+    route();
+  });
+
+  for (const form of document.querySelectorAll("form.filters")) {
+    form.addEventListener("submit", (ev) => {
+      ev.preventDefault();
+      offsets[form.dataset.list] = 0;
+      load(form.dataset.list);
+    });
+  }
+
+  $("#sign-out").addEventListener("click", signOut);
+  window.addEventListener("hashchange", route);
+  route();
+});
@@ -0,0 +1,72 @@
+package debug
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/infrastructure/fake"
+	"github.com/haidang666/go-app/internal/infrastructure/mailer"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+type NewDebugHandlerArgs struct {
+	// Outbox is set when the fake dependencies are on.
+	Outbox *fake.Outbox
+	// Templates is set in development, for email previews.
+	Templates *mailer.TemplateRegistry
+}
+
+type DebugHandler struct {
+	outbox    *fake.Outbox
+	templates *mailer.TemplateRegistry
+}
+
+func NewDebugHandler(args NewDebugHandlerArgs) *DebugHandler {
+	return &DebugHandler{outbox: args.Outbox, templates: args.Templates}
+}
+
+// ListOutbox returns what the fake dependencies recorded, newest first,
+// optionally filtered with ?kind=email|sms|checkout|webhook|captcha.
+func (h *DebugHandler) ListOutbox(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, r, h.outbox.List(r.URL.Query().Get("kind")), http.StatusOK)
+}
+
+func (h *DebugHandler) ClearOutbox(w http.ResponseWriter, _ *http.Request) {
+	h.outbox.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type emailPreview struct {
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+}
+
+// ListEmailTemplates returns the names of the email templates.
+func (h *DebugHandler) ListEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, r, h.templates.Names(), http.StatusOK)
+}
+
+// PreviewEmail renders the template in the path with its sample data, in
+// ?locale= or the default locale.
+func (h *DebugHandler) PreviewEmail(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "template")
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = mailer.DEFAULT_LOCALE
+	}
+
+	msg, err := h.templates.Render(name, locale, h.templates.Sample(name))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, mailer.ErrUnknownTemplate) {
+			status = http.StatusNotFound
+		}
+		response.Error(w, r, status, err)
+		return
+	}
+
+	response.JSON(w, r, emailPreview{Template: name, Locale: locale, Subject: msg.Subject, Body: msg.Body}, http.StatusOK)
+}
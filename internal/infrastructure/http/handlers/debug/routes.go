@@ -0,0 +1,16 @@
+package debug
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterRoutes(r chi.Router, h *DebugHandler) {
+	if h.outbox != nil {
+		r.Get("/debug/outbox", h.ListOutbox)
+		r.Delete("/debug/outbox", h.ClearOutbox)
+	}
+	if h.templates != nil {
+		r.Get("/debug/emails", h.ListEmailTemplates)
+		r.Get("/debug/emails/{template}/preview", h.PreviewEmail)
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/drain"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/leader"
+	"github.com/haidang666/go-app/pkg/lifecycle"
+	"github.com/haidang666/go-app/pkg/resilience"
+)
+
+// Degradation is a feature running on its fail mode because a dependency
+// it needs is unusable.
+type Degradation struct {
+	Feature  string `json:"feature"`
+	FailMode string `json:"fail_mode"`
+	Reason   string `json:"reason"`
+}
+
+type NewHealthHandlerArgs struct {
+	Resilience *resilience.Registry
+	Elector    *leader.Elector
+	Lifecycle  *lifecycle.Registry
+	PreStop    *drain.PreStop
+	// Degraded lists the degraded features; nil reports none.
+	Degraded func() []Degradation
+}
+
+type HealthHandler struct {
+	resilience *resilience.Registry
+	elector    *leader.Elector
+	lifecycle  *lifecycle.Registry
+	preStop    *drain.PreStop
+	degraded   func() []Degradation
+}
+
+func NewHealthHandler(args NewHealthHandlerArgs) *HealthHandler {
+	return &HealthHandler{
+		resilience: args.Resilience,
+		elector:    args.Elector,
+		lifecycle:  args.Lifecycle,
+		preStop:    args.PreStop,
+		degraded:   args.Degraded,
+	}
+}
+
+type readinessResponse struct {
+	lifecycle.Report
+	Dependencies []resilience.PolicyStatus `json:"dependencies"`
+	Degraded     []Degradation             `json:"degraded,omitempty"`
+	Leader       leader.Status             `json:"leader"`
+}
+
+// Live reports that the process is up; it never checks dependencies.
+func (h *HealthHandler) Live(w http.ResponseWriter, _ *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// Version reports the build metadata of the running binary.
+func (h *HealthHandler) Version(w http.ResponseWriter, _ *http.Request) {
+	request.ToJSON(w, buildinfo.Get(), http.StatusOK)
+}
+
+// Ready reports whether the instance should receive traffic: only once it
+// has started, while every critical component is up and until it starts
+// stopping. A degraded feature does not make it unready, as every instance
+// shares the outage; it is listed so operators can tell.
+func (h *HealthHandler) Ready(w http.ResponseWriter, _ *http.Request) {
+	deps, _ := h.resilience.Statuses()
+
+	// Followers are as ready as the leader; leadership is reported only.
+	res := readinessResponse{Report: h.lifecycle.Report(), Dependencies: deps, Leader: h.elector.Status()}
+	if h.degraded != nil {
+		res.Degraded = h.degraded()
+	}
+	status := http.StatusOK
+	if res.Status != lifecycle.STATUS_READY {
+		status = http.StatusServiceUnavailable
+	}
+	request.ToJSON(w, res, status)
+}
+
+// PreStop is for a Kubernetes preStop httpGet hook: it fails readiness and
+// answers once APP_SHUTDOWN_DELAY has passed, so the pod has left its
+// Services by the time it is sent SIGTERM, whose shutdown then drains
+// without waiting again. It is only served on the ops listener.
+func (h *HealthHandler) PreStop(w http.ResponseWriter, r *http.Request) {
+	if err := h.preStop.Run(r.Context()); err != nil {
+		// The hook gave up; the delay carries on regardless.
+		return
+	}
+	w.Write([]byte("ok"))
+}
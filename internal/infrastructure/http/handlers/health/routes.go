@@ -0,0 +1,17 @@
+package health
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterRoutes(r chi.Router, h *HealthHandler) {
+	r.Get("/health", h.Live)
+	r.Get("/readyz", h.Ready)
+	r.Get("/version", h.Version)
+}
+
+// RegisterOpsRoutes mounts the endpoints only the ops listener may serve,
+// as they act on the instance.
+func RegisterOpsRoutes(r chi.Router, h *HealthHandler) {
+	r.Get("/prestop", h.PreStop)
+}
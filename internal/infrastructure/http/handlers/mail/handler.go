@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+	mailUseCase "github.com/haidang666/go-app/internal/domain/use_case/mail"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// maxWebhookSize bounds webhook deliveries, which batch many events.
+const maxWebhookSize = 1 << 20
+
+type NewMailHandlerArgs struct {
+	// HandleFeedbackWebhookUseCase is nil when the webhook is disabled.
+	HandleFeedbackWebhookUseCase *mailUseCase.HandleFeedbackWebhookUseCase
+}
+
+type MailHandler struct {
+	handleFeedbackWebhookUseCase *mailUseCase.HandleFeedbackWebhookUseCase
+}
+
+func NewMailHandler(args NewMailHandlerArgs) *MailHandler {
+	return &MailHandler{
+		handleFeedbackWebhookUseCase: args.HandleFeedbackWebhookUseCase,
+	}
+}
+
+// Enabled reports whether a webhook secret is configured; the webhook is
+// only mounted then.
+func (h *MailHandler) Enabled() bool {
+	return h.handleFeedbackWebhookUseCase != nil
+}
+
+// Webhook receives the email provider's bounce and complaint events. The
+// signature covers the exact bytes sent, so the body is read raw. Failures
+// other than a bad signature or payload answer 500 so the provider retries,
+// as does the 409 to a repeat of a delivery still being processed.
+func (h *MailHandler) Webhook(resWriter http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(resWriter, r.Body, maxWebhookSize))
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.handleFeedbackWebhookUseCase.Execute(r.Context(), payload, r.Header.Get("X-Mail-Signature"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidWebhookSignature), errors.Is(err, errs.ErrInvalidWebhookPayload):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrWebhookInProgress):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,13 @@
+package mail
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the provider webhook at the root, outside the
+// versioned API; it is authenticated by its signature.
+func RegisterRoutes(r chi.Router, h *MailHandler) {
+	if h.Enabled() {
+		r.Post("/mail/webhook", h.Webhook)
+	}
+}
@@ -0,0 +1,53 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// DeleteAccount deletes the calling user's account, which can be restored
+// from the emailed link until the returned purge time. The password is
+// required when the account has one; otherwise the caller must have signed
+// in recently.
+func (h *MeHandler) DeleteAccount(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.DeleteAccountRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.DeleteAccountInput{
+		UserID:    current.ID,
+		Password:  payload.Password,
+		SessionID: current.SessionID,
+	}
+
+	deletion, err := h.deleteAccountUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidCredentials), errors.Is(err, errs.ErrRecentSignInRequired):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, deletion, http.StatusAccepted)
+}
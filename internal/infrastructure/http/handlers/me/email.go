@@ -0,0 +1,55 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ChangeEmail starts an email change; it completes when the new address
+// follows the emailed link.
+func (h *MeHandler) ChangeEmail(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.ChangeEmailRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.RequestEmailChangeInput{
+		UserID:   current.ID,
+		NewEmail: payload.NewEmail,
+		Password: payload.Password,
+	}
+
+	change, err := h.requestEmailChangeUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrEmailTaken):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrSameEmail):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, change, http.StatusAccepted)
+}
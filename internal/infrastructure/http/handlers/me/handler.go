@@ -0,0 +1,188 @@
+package me
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+	accountUseCase "github.com/haidang666/go-app/internal/domain/use_case/account"
+	activityUseCase "github.com/haidang666/go-app/internal/domain/use_case/activity"
+	notificationUseCase "github.com/haidang666/go-app/internal/domain/use_case/notification"
+	phoneUseCase "github.com/haidang666/go-app/internal/domain/use_case/phone"
+	sessionUseCase "github.com/haidang666/go-app/internal/domain/use_case/session"
+	termsUseCase "github.com/haidang666/go-app/internal/domain/use_case/terms"
+	tokenUseCase "github.com/haidang666/go-app/internal/domain/use_case/token"
+	usageUseCase "github.com/haidang666/go-app/internal/domain/use_case/usage"
+	"github.com/haidang666/go-app/internal/infrastructure/realtime"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+const (
+	historyDefaultLimit = 20
+	historyMaxLimit     = 100
+)
+
+type NewMeHandlerArgs struct {
+	ListSessionsUseCase                  *sessionUseCase.ListSessionsUseCase
+	RevokeSessionUseCase                 *sessionUseCase.RevokeSessionUseCase
+	RevokeAllSessionsUseCase             *sessionUseCase.RevokeAllSessionsUseCase
+	ListLoginHistoryUseCase              *activityUseCase.ListLoginHistoryUseCase
+	GetTermsStatusUseCase                *termsUseCase.GetTermsStatusUseCase
+	AcceptTermsUseCase                   *termsUseCase.AcceptTermsUseCase
+	RequestEmailChangeUseCase            *accountUseCase.RequestEmailChangeUseCase
+	DeleteAccountUseCase                 *accountUseCase.DeleteAccountUseCase
+	RequestPhoneVerificationUseCase      *phoneUseCase.RequestPhoneVerificationUseCase
+	VerifyPhoneUseCase                   *phoneUseCase.VerifyPhoneUseCase
+	UpgradeGuestUseCase                  *accountUseCase.UpgradeGuestUseCase
+	ListIdentitiesUseCase                *accountUseCase.ListIdentitiesUseCase
+	LinkIdentityUseCase                  *accountUseCase.LinkIdentityUseCase
+	UnlinkIdentityUseCase                *accountUseCase.UnlinkIdentityUseCase
+	GetCurrentUsageUseCase               *usageUseCase.GetCurrentUsageUseCase
+	CreateTokenUseCase                   *tokenUseCase.CreateTokenUseCase
+	ListTokensUseCase                    *tokenUseCase.ListTokensUseCase
+	RevokeTokenUseCase                   *tokenUseCase.RevokeTokenUseCase
+	GetNotificationPreferencesUseCase    *notificationUseCase.GetPreferencesUseCase
+	UpdateNotificationPreferencesUseCase *notificationUseCase.UpdatePreferencesUseCase
+	ListNotificationsUseCase             *notificationUseCase.ListNotificationsUseCase
+	CountUnreadUseCase                   *notificationUseCase.CountUnreadUseCase
+	MarkReadUseCase                      *notificationUseCase.MarkReadUseCase
+	MarkAllReadUseCase                   *notificationUseCase.MarkAllReadUseCase
+	Badges                               *realtime.BadgeHub
+	// Presence marks users online while their notification stream is open.
+	Presence *realtime.PresenceTracker
+	// StreamsClosing ends the notification streams on shutdown.
+	StreamsClosing <-chan struct{}
+}
+
+// MeHandler serves the /me endpoints that operate on the calling user.
+type MeHandler struct {
+	listSessionsUseCase                  *sessionUseCase.ListSessionsUseCase
+	revokeSessionUseCase                 *sessionUseCase.RevokeSessionUseCase
+	revokeAllSessionsUseCase             *sessionUseCase.RevokeAllSessionsUseCase
+	listLoginHistoryUseCase              *activityUseCase.ListLoginHistoryUseCase
+	getTermsStatusUseCase                *termsUseCase.GetTermsStatusUseCase
+	acceptTermsUseCase                   *termsUseCase.AcceptTermsUseCase
+	requestEmailChangeUseCase            *accountUseCase.RequestEmailChangeUseCase
+	deleteAccountUseCase                 *accountUseCase.DeleteAccountUseCase
+	requestPhoneVerificationUseCase      *phoneUseCase.RequestPhoneVerificationUseCase
+	verifyPhoneUseCase                   *phoneUseCase.VerifyPhoneUseCase
+	upgradeGuestUseCase                  *accountUseCase.UpgradeGuestUseCase
+	listIdentitiesUseCase                *accountUseCase.ListIdentitiesUseCase
+	linkIdentityUseCase                  *accountUseCase.LinkIdentityUseCase
+	unlinkIdentityUseCase                *accountUseCase.UnlinkIdentityUseCase
+	getCurrentUsageUseCase               *usageUseCase.GetCurrentUsageUseCase
+	createTokenUseCase                   *tokenUseCase.CreateTokenUseCase
+	listTokensUseCase                    *tokenUseCase.ListTokensUseCase
+	revokeTokenUseCase                   *tokenUseCase.RevokeTokenUseCase
+	getNotificationPreferencesUseCase    *notificationUseCase.GetPreferencesUseCase
+	updateNotificationPreferencesUseCase *notificationUseCase.UpdatePreferencesUseCase
+	listNotificationsUseCase             *notificationUseCase.ListNotificationsUseCase
+	countUnreadUseCase                   *notificationUseCase.CountUnreadUseCase
+	markReadUseCase                      *notificationUseCase.MarkReadUseCase
+	markAllReadUseCase                   *notificationUseCase.MarkAllReadUseCase
+	badges                               *realtime.BadgeHub
+	presence                             *realtime.PresenceTracker
+	streamsClosing                       <-chan struct{}
+}
+
+func NewMeHandler(args NewMeHandlerArgs) *MeHandler {
+	return &MeHandler{
+		listSessionsUseCase:                  args.ListSessionsUseCase,
+		revokeSessionUseCase:                 args.RevokeSessionUseCase,
+		revokeAllSessionsUseCase:             args.RevokeAllSessionsUseCase,
+		listLoginHistoryUseCase:              args.ListLoginHistoryUseCase,
+		getTermsStatusUseCase:                args.GetTermsStatusUseCase,
+		acceptTermsUseCase:                   args.AcceptTermsUseCase,
+		requestEmailChangeUseCase:            args.RequestEmailChangeUseCase,
+		deleteAccountUseCase:                 args.DeleteAccountUseCase,
+		requestPhoneVerificationUseCase:      args.RequestPhoneVerificationUseCase,
+		verifyPhoneUseCase:                   args.VerifyPhoneUseCase,
+		upgradeGuestUseCase:                  args.UpgradeGuestUseCase,
+		listIdentitiesUseCase:                args.ListIdentitiesUseCase,
+		linkIdentityUseCase:                  args.LinkIdentityUseCase,
+		unlinkIdentityUseCase:                args.UnlinkIdentityUseCase,
+		getCurrentUsageUseCase:               args.GetCurrentUsageUseCase,
+		createTokenUseCase:                   args.CreateTokenUseCase,
+		listTokensUseCase:                    args.ListTokensUseCase,
+		revokeTokenUseCase:                   args.RevokeTokenUseCase,
+		getNotificationPreferencesUseCase:    args.GetNotificationPreferencesUseCase,
+		updateNotificationPreferencesUseCase: args.UpdateNotificationPreferencesUseCase,
+		listNotificationsUseCase:             args.ListNotificationsUseCase,
+		countUnreadUseCase:                   args.CountUnreadUseCase,
+		markReadUseCase:                      args.MarkReadUseCase,
+		markAllReadUseCase:                   args.MarkAllReadUseCase,
+		badges:                               args.Badges,
+		presence:                             args.Presence,
+		streamsClosing:                       args.StreamsClosing,
+	}
+}
+
+func (h *MeHandler) ListSessions(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	sessions, err := h.listSessionsUseCase.Execute(r.Context(), current.ID, current.SessionID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, sessions, http.StatusOK)
+}
+
+func (h *MeHandler) RevokeSession(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	sessionID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.revokeSessionUseCase.Execute(r.Context(), current.ID, sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions signs the user out everywhere.
+func (h *MeHandler) RevokeAllSessions(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	n, err := h.revokeAllSessionsUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, map[string]int{"revoked": n}, http.StatusOK)
+}
+
+func (h *MeHandler) LoginHistory(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	limit, offset, err := request.Pagination(r, historyDefaultLimit, historyMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.listLoginHistoryUseCase.Execute(r.Context(), current.ID, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		response.AddLink(r, "next", fmt.Sprintf("%s?limit=%d&offset=%d", r.URL.Path, limit, next))
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
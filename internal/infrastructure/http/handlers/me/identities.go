@@ -0,0 +1,123 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ListIdentities lists the ways the caller can sign in.
+func (h *MeHandler) ListIdentities(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	identities, err := h.listIdentitiesUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, identities, http.StatusOK)
+}
+
+// LinkIdentity adds a phone number or a password to the caller's account.
+// A linked phone number is texted a code and is usable once verified. The
+// current password is required when the account has one; otherwise the
+// caller must have signed in recently.
+func (h *MeHandler) LinkIdentity(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.LinkIdentityRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.LinkIdentityInput{
+		Type:            payload.Type,
+		Phone:           payload.Phone,
+		Password:        payload.Password,
+		CurrentPassword: payload.CurrentPassword,
+		SessionID:       current.SessionID,
+	}
+
+	identities, err := h.linkIdentityUseCase.Execute(r.Context(), current.ID, input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidPhone):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrInvalidCredentials), errors.Is(err, errs.ErrRecentSignInRequired):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrIdentityAlreadyLinked), errors.Is(err, errs.ErrPhoneTaken):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrWeakPassword):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrOTPRateLimited):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, identities, http.StatusOK)
+}
+
+// UnlinkIdentity removes one of the caller's identities, by type. The last
+// usable one cannot be removed. The current password is required when the
+// account has one; accounts without one may send no body at all, but must
+// have signed in recently.
+func (h *MeHandler) UnlinkIdentity(resWriter http.ResponseWriter, r *http.Request) {
+	typ, err := request.ParamEnum(r, "type", dto.IDENTITY_PASSWORD, dto.IDENTITY_PHONE, dto.IDENTITY_SAML)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := new(me.UnlinkIdentityRequest)
+
+	if err := request.FromJSON(r, payload); err != nil && !errors.Is(err, request.ErrEmptyBody) {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.UnlinkIdentityInput{Type: typ, Password: payload.Password, SessionID: current.SessionID}
+
+	identities, err := h.unlinkIdentityUseCase.Execute(r.Context(), current.ID, input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrIdentityNotLinked):
+			status = http.StatusNotFound
+		case errors.Is(err, errs.ErrInvalidCredentials), errors.Is(err, errs.ErrRecentSignInRequired),
+			errors.Is(err, errs.ErrIdentityManaged):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrLastIdentity):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, identities, http.StatusOK)
+}
@@ -0,0 +1,227 @@
+package me
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+var ErrInvalidUnreadFilter = errors.New("unread must be true or false")
+
+const (
+	inboxDefaultLimit = 20
+	inboxMaxLimit     = 100
+
+	// streamHeartbeat keeps idle notification streams from being cut by
+	// proxies; streamWriteTimeout replaces APP_WRITE_TIMEOUT, which would end
+	// every stream, with a deadline per write.
+	streamHeartbeat    = 20 * time.Second
+	streamWriteTimeout = 30 * time.Second
+	// streamRetry is how long browsers wait before reconnecting a stream.
+	streamRetry = 5 * time.Second
+)
+
+// ListNotifications returns a page of the in-app inbox, newest first, with
+// the unread count; unread=true leaves out read notifications.
+func (h *MeHandler) ListNotifications(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	limit, offset, err := request.Pagination(r, inboxDefaultLimit, inboxMaxLimit)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			response.Error(resWriter, r, http.StatusBadRequest, ErrInvalidUnreadFilter)
+			return
+		}
+	}
+
+	page, err := h.listNotificationsUseCase.Execute(r.Context(), current.ID, unreadOnly, limit, offset)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if next := offset + len(page.Items); next < page.Total {
+		response.AddLink(r, "next", fmt.Sprintf("%s?limit=%d&offset=%d&unread=%t", r.URL.Path, limit, next, unreadOnly))
+	}
+	response.JSON(resWriter, r, page, http.StatusOK)
+}
+
+func (h *MeHandler) UnreadNotifications(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	unread, err := h.countUnreadUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, map[string]int{"unread": unread}, http.StatusOK)
+}
+
+func (h *MeHandler) MarkNotificationRead(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	id, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.markReadUseCase.Execute(r.Context(), current.ID, id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MeHandler) MarkAllNotificationsRead(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	n, err := h.markAllReadUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, map[string]int{"marked": n}, http.StatusOK)
+}
+
+// NotificationStream sends the unread count as server-sent "unread" events:
+// once on connect and again whenever it changes. The stream ends when the
+// server shuts down; browsers reconnect on their own. The user counts as
+// online while the stream is open.
+func (h *MeHandler) NotificationStream(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	// Watch before counting, so no change falls between the two.
+	updates, stop, err := h.badges.Watch(current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusTooManyRequests, err)
+		return
+	}
+	defer stop()
+
+	unread, err := h.countUnreadUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	rc := http.NewResponseController(resWriter)
+	resWriter.Header().Set("Content-Type", "text/event-stream")
+	resWriter.Header().Set("Cache-Control", "no-cache")
+	resWriter.Header().Set("X-Accel-Buffering", "no")
+	resWriter.WriteHeader(http.StatusOK)
+
+	send := func(frame string) bool {
+		if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return false
+		}
+		if _, err := fmt.Fprint(resWriter, frame); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	unreadEvent := func(n int) string {
+		return fmt.Sprintf("event: unread\ndata: {\"unread\":%d}\n\n", n)
+	}
+
+	if !send(fmt.Sprintf("retry: %d\n", streamRetry.Milliseconds()) + unreadEvent(unread)) {
+		return
+	}
+	presence := h.presence.Connect(r.Context(), current.ID)
+	defer presence.Close(r.Context())
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var frame string
+		select {
+		case n := <-updates:
+			frame = unreadEvent(n)
+		case <-heartbeat.C:
+			presence.Beat(r.Context())
+			frame = ": ping\n\n"
+		case <-h.streamsClosing:
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if !send(frame) {
+			return
+		}
+	}
+}
+
+func (h *MeHandler) NotificationPreferences(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	prefs, err := h.getNotificationPreferencesUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, prefs, http.StatusOK)
+}
+
+func (h *MeHandler) UpdateNotificationPreferences(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.UpdateNotificationPreferencesRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.UpdateNotificationPreferencesInput{
+		UserID:     current.ID,
+		Channels:   payload.Channels,
+		Categories: payload.Categories,
+		TimeZone:   payload.TimeZone,
+		Digest:     payload.Digest,
+	}
+	if payload.QuietHours != nil {
+		input.QuietHoursStart = payload.QuietHours.Start
+		input.QuietHoursEnd = payload.QuietHours.End
+	}
+
+	prefs, err := h.updateNotificationPreferencesUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrSecurityNotificationsRequired),
+			errors.Is(err, errs.ErrQuietHoursIncomplete):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrNotificationSMSUnavailable):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, prefs, http.StatusOK)
+}
@@ -0,0 +1,75 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// ChangePhone sets the caller's phone number and texts it a verification
+// code; the number is usable for sign-in once VerifyPhone succeeds.
+func (h *MeHandler) ChangePhone(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.ChangePhoneRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	if err := h.requestPhoneVerificationUseCase.Execute(r.Context(), current.ID, payload.Phone); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidPhone):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrPhoneTaken):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrOTPRateLimited):
+			status = http.StatusTooManyRequests
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusAccepted)
+}
+
+func (h *MeHandler) VerifyPhone(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.VerifyPhoneRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	user, err := h.verifyPhoneUseCase.Execute(r.Context(), current.ID, payload.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrInvalidOTP), errors.Is(err, errs.ErrPhoneNotPending):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrPhoneTaken):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, user, http.StatusOK)
+}
@@ -0,0 +1,39 @@
+package me
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the /me endpoints. usageGate guards the usage
+// report, which may be limited to some plans.
+func RegisterRoutes(r chi.Router, h *MeHandler, usageGate func(http.Handler) http.Handler) {
+	r.Route("/me", func(mr chi.Router) {
+		mr.Delete("/", h.DeleteAccount)
+		mr.Get("/sessions", h.ListSessions)
+		mr.Delete("/sessions", h.RevokeAllSessions)
+		mr.Delete("/sessions/{id}", h.RevokeSession)
+		mr.Get("/tokens", h.ListTokens)
+		mr.Post("/tokens", h.CreateToken)
+		mr.Delete("/tokens/{id}", h.RevokeToken)
+		mr.Get("/notifications", h.ListNotifications)
+		mr.Get("/notifications/unread", h.UnreadNotifications)
+		mr.Get("/notifications/stream", h.NotificationStream)
+		mr.Post("/notifications/read", h.MarkAllNotificationsRead)
+		mr.Post("/notifications/{id}/read", h.MarkNotificationRead)
+		mr.Get("/notifications/preferences", h.NotificationPreferences)
+		mr.Put("/notifications/preferences", h.UpdateNotificationPreferences)
+		mr.Get("/login-history", h.LoginHistory)
+		mr.Get("/terms", h.TermsStatus)
+		mr.Post("/terms", h.AcceptTerms)
+		mr.Post("/email", h.ChangeEmail)
+		mr.Post("/phone", h.ChangePhone)
+		mr.Post("/phone/verify", h.VerifyPhone)
+		mr.Get("/identities", h.ListIdentities)
+		mr.Post("/identities", h.LinkIdentity)
+		mr.Delete("/identities/{type}", h.UnlinkIdentity)
+		mr.Post("/upgrade", h.Upgrade)
+		mr.With(usageGate).Get("/usage", h.Usage)
+	})
+}
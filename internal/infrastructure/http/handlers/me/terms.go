@@ -0,0 +1,56 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+func (h *MeHandler) TermsStatus(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	status, err := h.getTermsStatusUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, status, http.StatusOK)
+}
+
+// AcceptTerms records (re-)acceptance of the current documents.
+func (h *MeHandler) AcceptTerms(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.AcceptTermsRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	accepted := entity.TermsVersions{Terms: payload.TermsVersion, Privacy: payload.PrivacyVersion}
+
+	acceptance, err := h.acceptTermsUseCase.Execute(r.Context(), current.ID, accepted, clientinfo.IP(r))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrTermsOutdated) {
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, acceptance, http.StatusCreated)
+}
@@ -0,0 +1,85 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+func (h *MeHandler) ListTokens(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	tokens, err := h.listTokensUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, tokens, http.StatusOK)
+}
+
+// CreateToken mints a personal access token; the response is the only time
+// the token itself is shown.
+func (h *MeHandler) CreateToken(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.CreatePersonalTokenRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.CreatePersonalTokenInput{
+		UserID:    current.ID,
+		Name:      payload.Name,
+		Scopes:    payload.Scopes,
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	created, err := h.createTokenUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errs.ErrPersonalTokenExpiry):
+			status = http.StatusBadRequest
+		case errors.Is(err, errs.ErrPersonalTokenLimit):
+			status = http.StatusConflict
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, created, http.StatusCreated)
+}
+
+func (h *MeHandler) RevokeToken(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	tokenID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.revokeTokenUseCase.Execute(r.Context(), current.ID, tokenID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrPersonalTokenNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	resWriter.WriteHeader(http.StatusNoContent)
+}
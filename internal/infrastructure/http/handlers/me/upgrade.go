@@ -0,0 +1,59 @@
+package me
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/mapping"
+	"github.com/haidang666/go-app/internal/api/me"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// Upgrade attaches credentials to the calling guest account. The client
+// should refresh its tokens afterwards to drop the guest scope.
+func (h *MeHandler) Upgrade(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(me.UpgradeRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := mapping.UpgradeInput(payload)
+	input.Client = clientinfo.FromRequest(r)
+
+	user, err := h.upgradeGuestUseCase.Execute(r.Context(), current.ID, input)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errs.ErrNotGuest):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrEmailTaken), errors.Is(err, errs.ErrEmailAliasTaken),
+			errors.Is(err, errs.ErrUsernameTaken):
+			status = http.StatusConflict
+		case errors.Is(err, errs.ErrEmailDomainNotAllowed), errors.Is(err, errs.ErrDisposableEmail):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrInviteRequired), errors.Is(err, errs.ErrInvalidInvite):
+			status = http.StatusForbidden
+		case errors.Is(err, errs.ErrTermsNotAccepted), errors.Is(err, errs.ErrTermsOutdated),
+			errors.Is(err, errs.ErrWeakPassword):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, errs.ErrPasswordHashingBusy):
+			status = http.StatusServiceUnavailable
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, user, http.StatusOK)
+}
@@ -0,0 +1,21 @@
+package me
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// Usage reports the caller's metered usage in the current billing period.
+func (h *MeHandler) Usage(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	summary, err := h.getCurrentUsageUseCase.Execute(r.Context(), current.ID)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, summary, http.StatusOK)
+}
@@ -0,0 +1,199 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/api/oauth"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	oauthUseCase "github.com/haidang666/go-app/internal/domain/use_case/oauth"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+)
+
+const maxFormSize = 64 << 10
+
+type NewOAuthHandlerArgs struct {
+	IssueClientTokenUseCase *oauthUseCase.IssueClientTokenUseCase
+	IntrospectTokenUseCase  *oauthUseCase.IntrospectTokenUseCase
+	// The OpenID Connect use cases are nil when provider mode is disabled.
+	AuthorizeUseCase    *oauthUseCase.AuthorizeUseCase
+	ExchangeCodeUseCase *oauthUseCase.ExchangeCodeUseCase
+	UserInfoUseCase     *oauthUseCase.UserInfoUseCase
+	DiscoveryUseCase    *oauthUseCase.DiscoveryUseCase
+	// LoginURL is the front-end page that signs the user in and asks for
+	// consent before calling the authorize API.
+	LoginURL string
+}
+
+// OAuthHandler serves the OAuth 2.0 and OpenID Connect endpoints. Its
+// responses follow RFC 6749 rather than the API's problem+json format.
+type OAuthHandler struct {
+	issueClientTokenUseCase *oauthUseCase.IssueClientTokenUseCase
+	introspectTokenUseCase  *oauthUseCase.IntrospectTokenUseCase
+	authorizeUseCase        *oauthUseCase.AuthorizeUseCase
+	exchangeCodeUseCase     *oauthUseCase.ExchangeCodeUseCase
+	userInfoUseCase         *oauthUseCase.UserInfoUseCase
+	discoveryUseCase        *oauthUseCase.DiscoveryUseCase
+	loginURL                string
+}
+
+func NewOAuthHandler(args NewOAuthHandlerArgs) *OAuthHandler {
+	return &OAuthHandler{
+		issueClientTokenUseCase: args.IssueClientTokenUseCase,
+		introspectTokenUseCase:  args.IntrospectTokenUseCase,
+		authorizeUseCase:        args.AuthorizeUseCase,
+		exchangeCodeUseCase:     args.ExchangeCodeUseCase,
+		userInfoUseCase:         args.UserInfoUseCase,
+		discoveryUseCase:        args.DiscoveryUseCase,
+		loginURL:                args.LoginURL,
+	}
+}
+
+// OIDCEnabled reports whether the OpenID Connect provider endpoints are
+// served.
+func (h *OAuthHandler) OIDCEnabled() bool {
+	return h.authorizeUseCase != nil
+}
+
+// tokenError is the RFC 6749 section 5.2 error response.
+type tokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Token implements the client_credentials and, in provider mode, the
+// authorization_code grants.
+func (h *OAuthHandler) Token(resWriter http.ResponseWriter, r *http.Request) {
+	resWriter.Header().Set("Cache-Control", "no-store")
+
+	r.Body = http.MaxBytesReader(resWriter, r.Body, maxFormSize)
+	payload := new(oauth.TokenRequest)
+	if err := request.FromForm(r, payload); err != nil {
+		writeTokenError(resWriter, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		payload.ClientID, payload.ClientSecret = id, secret
+	}
+
+	if err := payload.Validate(); err != nil {
+		if errors.Is(err, oauth.ErrMissingClientCredentials) {
+			writeTokenError(resWriter, http.StatusUnauthorized, "invalid_client", err)
+			return
+		}
+		writeTokenError(resWriter, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	var (
+		token any
+		err   error
+	)
+	switch {
+	case payload.GrantType == oauth.GRANT_TYPE_CLIENT_CREDENTIALS:
+		token, err = h.issueClientTokenUseCase.Execute(r.Context(), &dto.ClientCredentialsInput{
+			ClientID:     payload.ClientID,
+			ClientSecret: payload.ClientSecret,
+			Scopes:       payload.Scopes(),
+		})
+	case payload.GrantType == oauth.GRANT_TYPE_AUTHORIZATION_CODE && h.OIDCEnabled():
+		token, err = h.exchangeCodeUseCase.Execute(r.Context(), &dto.AuthorizationCodeInput{
+			ClientID:     payload.ClientID,
+			ClientSecret: payload.ClientSecret,
+			Code:         payload.Code,
+			RedirectURI:  payload.RedirectURI,
+			CodeVerifier: payload.CodeVerifier,
+		})
+	default:
+		writeTokenError(resWriter, http.StatusBadRequest, "unsupported_grant_type", errs.ErrUnsupportedGrantType)
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidClient):
+			resWriter.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			writeTokenError(resWriter, http.StatusUnauthorized, "invalid_client", err)
+		case errors.Is(err, errs.ErrInvalidScope):
+			writeTokenError(resWriter, http.StatusBadRequest, "invalid_scope", err)
+		case errors.Is(err, errs.ErrInvalidGrant):
+			writeTokenError(resWriter, http.StatusBadRequest, "invalid_grant", err)
+		default:
+			ctxutil.Logger(r.Context()).Errorw("issue oauth token", "grant_type", payload.GrantType, "error", err)
+			writeTokenError(resWriter, http.StatusInternalServerError, "server_error", nil)
+		}
+		return
+	}
+
+	request.ToJSON(resWriter, token, http.StatusOK)
+}
+
+// Client describes the calling machine client, so integrators can check
+// which scopes their token carries.
+func (h *OAuthHandler) Client(resWriter http.ResponseWriter, r *http.Request) {
+	client, _ := ctxutil.CurrentClientFrom(r.Context())
+	out := map[string]any{
+		"client_id": client.ClientID,
+		"scopes":    client.Scopes,
+	}
+	if client.Issuer != "" {
+		out["issuer"] = client.Issuer
+	}
+	request.ToJSON(resWriter, out, http.StatusOK)
+}
+
+// Introspect tells a resource server whether a token is active (RFC 7662).
+// The caller authenticates with its client credentials, as at the token
+// endpoint.
+func (h *OAuthHandler) Introspect(resWriter http.ResponseWriter, r *http.Request) {
+	resWriter.Header().Set("Cache-Control", "no-store")
+
+	r.Body = http.MaxBytesReader(resWriter, r.Body, maxFormSize)
+	payload := new(oauth.IntrospectRequest)
+	if err := request.FromForm(r, payload); err != nil {
+		writeTokenError(resWriter, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		payload.ClientID, payload.ClientSecret = id, secret
+	}
+	if err := payload.Validate(); err != nil {
+		if errors.Is(err, oauth.ErrMissingClientCredentials) {
+			resWriter.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			writeTokenError(resWriter, http.StatusUnauthorized, "invalid_client", err)
+			return
+		}
+		writeTokenError(resWriter, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	result, err := h.introspectTokenUseCase.Execute(r.Context(), &dto.IntrospectionInput{
+		ClientID:     payload.ClientID,
+		ClientSecret: payload.ClientSecret,
+		Token:        payload.Token,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidClient):
+			resWriter.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			writeTokenError(resWriter, http.StatusUnauthorized, "invalid_client", err)
+		case errors.Is(err, errs.ErrIntrospectionNotAllowed):
+			writeTokenError(resWriter, http.StatusForbidden, "insufficient_scope", err)
+		default:
+			ctxutil.Logger(r.Context()).Errorw("introspect token", "client_id", payload.ClientID, "error", err)
+			writeTokenError(resWriter, http.StatusInternalServerError, "server_error", nil)
+		}
+		return
+	}
+
+	request.ToJSON(resWriter, result, http.StatusOK)
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code string, err error) {
+	body := tokenError{Error: code}
+	if err != nil {
+		body.ErrorDescription = err.Error()
+	}
+	request.ToJSON(w, body, status)
+}
@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/haidang666/go-app/internal/api/oauth"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// AuthorizeRedirect is the authorization endpoint relying parties send the
+// browser to. Signing in and consent happen in the front end, so it forwards
+// the request parameters to the login page unchanged.
+func (h *OAuthHandler) AuthorizeRedirect(resWriter http.ResponseWriter, r *http.Request) {
+	target, err := url.Parse(h.loginURL)
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+	target.RawQuery = r.URL.RawQuery
+	http.Redirect(resWriter, r, target.String(), http.StatusFound)
+}
+
+// Authorize is called by the front end for the signed-in user once they
+// consent. It answers with the URL to send the browser to.
+func (h *OAuthHandler) Authorize(resWriter http.ResponseWriter, r *http.Request) {
+	payload := new(oauth.AuthorizeRequest)
+
+	if err := request.FromJSON(r, payload); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	input := &dto.AuthorizeInput{
+		UserID:              current.ID,
+		SessionID:           current.SessionID,
+		ResponseType:        payload.ResponseType,
+		ClientID:            payload.ClientID,
+		RedirectURI:         payload.RedirectURI,
+		Scopes:              payload.Scopes(),
+		State:               payload.State,
+		Nonce:               payload.Nonce,
+		CodeChallenge:       payload.CodeChallenge,
+		CodeChallengeMethod: payload.CodeChallengeMethod,
+	}
+
+	result, err := h.authorizeUseCase.Execute(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrInvalidClient) || errors.Is(err, errs.ErrInvalidRedirectURI) {
+			status = http.StatusBadRequest
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, result, http.StatusOK)
+}
+
+func (h *OAuthHandler) UserInfo(resWriter http.ResponseWriter, r *http.Request) {
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+
+	info, err := h.userInfoUseCase.Execute(r.Context(), current.ID, current.Scopes)
+	if err != nil {
+		resWriter.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		response.Error(resWriter, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	request.ToJSON(resWriter, info, http.StatusOK)
+}
+
+func (h *OAuthHandler) Discovery(resWriter http.ResponseWriter, r *http.Request) {
+	resWriter.Header().Set("Cache-Control", "public, max-age=3600")
+	request.ToJSON(resWriter, h.discoveryUseCase.Document(), http.StatusOK)
+}
+
+func (h *OAuthHandler) JWKS(resWriter http.ResponseWriter, r *http.Request) {
+	resWriter.Header().Set("Cache-Control", "public, max-age=3600")
+	request.ToJSON(resWriter, h.discoveryUseCase.JWKS(), http.StatusOK)
+}
@@ -0,0 +1,36 @@
+package oauth
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the OAuth endpoints at the root, outside the
+// versioned API. authenticateClient guards the endpoints for machine
+// clients, authenticateDelegated the userinfo endpoint.
+func RegisterRoutes(r chi.Router, h *OAuthHandler, authenticateClient, authenticateDelegated func(http.Handler) http.Handler) {
+	r.Route("/oauth", func(or chi.Router) {
+		or.Post("/token", h.Token)
+		or.Post("/introspect", h.Introspect)
+		or.With(authenticateClient).Get("/client", h.Client)
+
+		if h.OIDCEnabled() {
+			or.Get("/authorize", h.AuthorizeRedirect)
+			or.With(authenticateDelegated).Get("/userinfo", h.UserInfo)
+		}
+	})
+
+	if h.OIDCEnabled() {
+		r.Get("/.well-known/openid-configuration", h.Discovery)
+		r.Get("/.well-known/jwks.json", h.JWKS)
+	}
+}
+
+// RegisterAPIRoutes mounts the endpoints the front end calls for a signed-in
+// user.
+func RegisterAPIRoutes(r chi.Router, h *OAuthHandler) {
+	if h.OIDCEnabled() {
+		r.Post("/oauth/authorize", h.Authorize)
+	}
+}
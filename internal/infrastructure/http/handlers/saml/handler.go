@@ -0,0 +1,180 @@
+package saml
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	authUseCase "github.com/haidang666/go-app/internal/domain/use_case/auth"
+	samlUseCase "github.com/haidang666/go-app/internal/domain/use_case/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/securecookie"
+)
+
+// maxResponseSize bounds the posted form; signed responses with a few
+// attributes are well under it.
+const maxResponseSize = 256 << 10
+
+// stateCookie keeps the login state in the browser that started the
+// sign-in. The identity provider posts back cross-site, so it must be
+// SameSite=None, which browsers only accept on Secure cookies; they treat
+// http://localhost as secure.
+const stateCookie = "saml_state"
+
+type NewSAMLHandlerArgs struct {
+	MetadataUseCase       *samlUseCase.MetadataUseCase
+	StartLoginUseCase     *samlUseCase.StartLoginUseCase
+	SignInWithSAMLUseCase *authUseCase.SignInWithSAMLUseCase
+	Cookies               *securecookie.Codec
+	// StateTTL is how long a sign-in may take at the identity provider.
+	StateTTL time.Duration
+	// SuccessURL is the front-end page that receives the tokens, or the
+	// error, in its URL fragment once the identity provider posts back.
+	SuccessURL string
+}
+
+// SAMLHandler serves the browser-facing SAML endpoints of each tenant.
+type SAMLHandler struct {
+	metadataUseCase       *samlUseCase.MetadataUseCase
+	startLoginUseCase     *samlUseCase.StartLoginUseCase
+	signInWithSAMLUseCase *authUseCase.SignInWithSAMLUseCase
+	cookies               *securecookie.Codec
+	stateTTL              time.Duration
+	successURL            string
+}
+
+func NewSAMLHandler(args NewSAMLHandlerArgs) *SAMLHandler {
+	return &SAMLHandler{
+		metadataUseCase:       args.MetadataUseCase,
+		startLoginUseCase:     args.StartLoginUseCase,
+		signInWithSAMLUseCase: args.SignInWithSAMLUseCase,
+		cookies:               args.Cookies,
+		stateTTL:              args.StateTTL,
+		successURL:            args.SuccessURL,
+	}
+}
+
+func (h *SAMLHandler) Metadata(resWriter http.ResponseWriter, r *http.Request) {
+	md, err := h.metadataUseCase.Execute(r.Context(), chi.URLParam(r, "tenant"))
+	if err != nil {
+		response.Error(resWriter, r, connectionStatus(err), err)
+		return
+	}
+
+	resWriter.Header().Set("Content-Type", "application/samlmetadata+xml")
+	resWriter.WriteHeader(http.StatusOK)
+	resWriter.Write(md)
+}
+
+// Login starts a sign-in at the tenant's identity provider. The optional
+// relay_state query parameter is handed back to the front end afterwards,
+// on the page of the optional return_to parameter, which must be on one of
+// the tenant's allowed callback domains, instead of SuccessURL.
+func (h *SAMLHandler) Login(resWriter http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	login, err := h.startLoginUseCase.Execute(r.Context(), chi.URLParam(r, "tenant"), query.Get("relay_state"), query.Get("return_to"))
+	if err != nil {
+		response.Error(resWriter, r, connectionStatus(err), err)
+		return
+	}
+
+	err = h.cookies.SetCookie(resWriter, &http.Cookie{
+		Name:     stateCookie,
+		Path:     "/saml/",
+		Expires:  login.ExpiresAt,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	}, []byte(login.State))
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(resWriter, r, login.URL, http.StatusFound)
+}
+
+// ACS is the assertion consumer service the identity provider posts the
+// browser back to. The outcome is passed to the front end in the fragment
+// of SuccessURL so tokens stay out of server logs.
+func (h *SAMLHandler) ACS(resWriter http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(resWriter, r.Body, maxResponseSize)
+	if err := r.ParseForm(); err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// A missing or tampered cookie leaves BrowserState empty, which fails
+	// the login state check.
+	browserState, _ := h.cookies.Cookie(r, stateCookie, h.stateTTL)
+	http.SetCookie(resWriter, &http.Cookie{
+		Name:     stateCookie,
+		Path:     "/saml/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	input := &dto.SAMLSignInInput{
+		Tenant:       chi.URLParam(r, "tenant"),
+		SAMLResponse: r.PostForm.Get("SAMLResponse"),
+		RelayState:   r.PostForm.Get("RelayState"),
+		BrowserState: string(browserState),
+		Client:       clientinfo.FromRequest(r),
+	}
+
+	res, err := h.signInWithSAMLUseCase.Execute(r.Context(), input)
+	fragment := url.Values{}
+	if res != nil && res.ReturnState != "" {
+		fragment.Set("state", res.ReturnState)
+	}
+	switch {
+	case errors.Is(err, errs.ErrSAMLConnectionNotFound):
+		response.Error(resWriter, r, http.StatusNotFound, err)
+		return
+	case errors.Is(err, errs.ErrInvalidSAMLResponse), errors.Is(err, errs.ErrInvalidLoginState),
+		errors.Is(err, errs.ErrSAMLAccountConflict),
+		errors.Is(err, errs.ErrSAMLUserNotProvisioned),
+		errors.Is(err, errs.ErrAccountSuspended), errors.Is(err, errs.ErrAccountBanned),
+		errors.Is(err, errs.ErrAccountDeleted):
+		ctxutil.Logger(r.Context()).Infow("saml sign-in rejected", "tenant", input.Tenant, "error", err)
+		fragment.Set("error", "access_denied")
+		fragment.Set("error_description", err.Error())
+	case errors.Is(err, errs.ErrPasswordHashingBusy):
+		response.Error(resWriter, r, http.StatusServiceUnavailable, err)
+		return
+	case err != nil:
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	default:
+		tokens := res.Tokens
+		fragment.Set("access_token", tokens.AccessToken)
+		fragment.Set("refresh_token", tokens.RefreshToken)
+		fragment.Set("token_type", tokens.TokenType)
+		fragment.Set("expires_in", strconv.Itoa(int(time.Until(tokens.AccessExpiresAt).Seconds())))
+	}
+
+	target := h.successURL
+	if res != nil && res.ReturnTo != "" {
+		target = res.ReturnTo
+	}
+	http.Redirect(resWriter, r, target+"#"+fragment.Encode(), http.StatusSeeOther)
+}
+
+func connectionStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrSAMLConnectionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrCallbackNotAllowed):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,15 @@
+package saml
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the SAML endpoints at the root, where the URLs
+// registered with identity providers point.
+func RegisterRoutes(r chi.Router, h *SAMLHandler) {
+	r.Route("/saml/{tenant}", func(sr chi.Router) {
+		sr.Get("/metadata", h.Metadata)
+		sr.Get("/login", h.Login)
+		sr.Post("/acs", h.ACS)
+	})
+}
@@ -0,0 +1,35 @@
+package username
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	userUseCase "github.com/haidang666/go-app/internal/domain/use_case/user"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+type NewUsernameHandlerArgs struct {
+	CheckUsernameUseCase *userUseCase.CheckUsernameUseCase
+}
+
+type UsernameHandler struct {
+	checkUsernameUseCase *userUseCase.CheckUsernameUseCase
+}
+
+func NewUsernameHandler(args NewUsernameHandlerArgs) *UsernameHandler {
+	return &UsernameHandler{
+		checkUsernameUseCase: args.CheckUsernameUseCase,
+	}
+}
+
+// Availability always answers 200; invalid or reserved names are reported as
+// unavailable with a reason so sign-up forms can show it inline.
+func (h *UsernameHandler) Availability(resWriter http.ResponseWriter, r *http.Request) {
+	result, err := h.checkUsernameUseCase.Execute(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		response.Error(resWriter, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(resWriter, r, result, http.StatusOK)
+}
@@ -0,0 +1,10 @@
+package username
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes mounts the public username endpoints.
+func RegisterRoutes(r chi.Router, h *UsernameHandler) {
+	r.Get("/usernames/{name}/availability", h.Availability)
+}
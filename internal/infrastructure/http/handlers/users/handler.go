@@ -0,0 +1,58 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	presenceUseCase "github.com/haidang666/go-app/internal/domain/use_case/presence"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/request"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+type NewUsersHandlerArgs struct {
+	GetPresenceUseCase *presenceUseCase.GetPresenceUseCase
+}
+
+type UsersHandler struct {
+	getPresenceUseCase *presenceUseCase.GetPresenceUseCase
+}
+
+func NewUsersHandler(args NewUsersHandlerArgs) *UsersHandler {
+	return &UsersHandler{
+		getPresenceUseCase: args.GetPresenceUseCase,
+	}
+}
+
+// Presence reports whether a user currently has the app open, and when they
+// were last seen. Users outside the caller's tenant are not found, unless
+// the caller is an admin.
+func (h *UsersHandler) Presence(resWriter http.ResponseWriter, r *http.Request) {
+	userID, err := request.ParamUUID(r, "id")
+	if err != nil {
+		response.Error(resWriter, r, http.StatusBadRequest, err)
+		return
+	}
+
+	current, _ := ctxutil.CurrentUserFrom(r.Context())
+	viewer := dto.PresenceViewer{
+		ID:       current.ID,
+		TenantID: current.TenantID,
+		Admin:    current.HasRole(entity.ROLE_ADMIN),
+		Guest:    current.HasScope(entity.SCOPE_GUEST),
+	}
+	presence, err := h.getPresenceUseCase.Execute(r.Context(), viewer, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errs.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		response.Error(resWriter, r, status, err)
+		return
+	}
+
+	response.JSON(resWriter, r, presence, http.StatusOK)
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	abandonedTotal = metrics.NewCounter("http_requests_abandoned_total",
+		"Requests whose client disconnected before they were answered, by route.", "route")
+	abandonedWorkSeconds = metrics.NewHistogram("http_abandoned_work_seconds",
+		"Time a request kept being worked on after its client disconnected, by route.",
+		metrics.DefaultBuckets, "route")
+)
+
+// Abandoned counts the requests whose client goes away before they are
+// answered, and how long each kept being worked on afterwards, which is what
+// checking the context in handlers, use cases and repositories saves. Only
+// disconnects are counted, not timeouts.
+func Abandoned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var canceledAt time.Time
+		done := make(chan struct{})
+		stop := context.AfterFunc(ctx, func() {
+			canceledAt = time.Now()
+			close(done)
+		})
+		next.ServeHTTP(w, r)
+		if stop() {
+			return
+		}
+		<-done
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		route := "unmatched"
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		abandonedTotal.Inc(route)
+		abandonedWorkSeconds.Observe(time.Since(canceledAt).Seconds(), route)
+	})
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/redact"
+)
+
+type AccessLogArgs struct {
+	// ExcludePaths are not logged, such as probes and scrapes. An entry
+	// ending in "*" matches every path it prefixes.
+	ExcludePaths []string
+	// MaxQueryLength is the longest query string logged in full; longer ones
+	// are truncated. 0 leaves query strings out.
+	MaxQueryLength int
+	// Headers logs the request headers, masked by Redactor, but for
+	// DropHeaders.
+	Headers     bool
+	DropHeaders []string
+	Redactor    *redact.Redactor
+}
+
+// AccessLog logs one line per request once it is answered. It must run
+// after RequestID, whose logger it writes to, and RealIP, and before
+// Recoverer so that panics are logged with their 500.
+func AccessLog(args AccessLogArgs) func(http.Handler) http.Handler {
+	exact := make(map[string]struct{})
+	var prefixes []string
+	for _, p := range args.ExcludePaths {
+		p = strings.TrimSpace(p)
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			prefixes = append(prefixes, prefix)
+		} else if p != "" {
+			exact[p] = struct{}{}
+		}
+	}
+	excluded := func(path string) bool {
+		if _, ok := exact[path]; ok {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	drop := make(map[string]struct{}, len(args.DropHeaders))
+	for _, h := range args.DropHeaders {
+		drop[http.CanonicalHeaderKey(strings.TrimSpace(h))] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if excluded(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+				"remote_ip", clientinfo.IP(r),
+				"user_agent", r.UserAgent(),
+			}
+			if args.MaxQueryLength > 0 && r.URL.RawQuery != "" {
+				fields = append(fields, "query", truncateQuery(r.URL.RawQuery, args.MaxQueryLength))
+			}
+			fields = append(fields, ctxutil.MetaFrom(r.Context()).Fields()...)
+			if args.Headers {
+				headers := args.Redactor.Headers(r.Header)
+				for h := range drop {
+					delete(headers, h)
+				}
+				fields = append(fields, "headers", headers)
+			}
+			ctxutil.Logger(r.Context()).Infow("http request", fields...)
+		})
+	}
+}
+
+// truncateQuery cuts query to at most max bytes, on a rune boundary, and
+// says how much was left out.
+func truncateQuery(query string, max int) string {
+	if len(query) <= max {
+		return query
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(query[cut]) {
+		cut--
+	}
+	return query[:cut] + "...(" + strconv.Itoa(len(query)-cut) + " more bytes)"
+}
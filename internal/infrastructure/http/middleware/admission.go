@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+type Priority int
+
+const (
+	PRIORITY_LOW Priority = iota
+	PRIORITY_HIGH
+	// PRIORITY_CRITICAL requests bypass admission control entirely.
+	PRIORITY_CRITICAL
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PRIORITY_CRITICAL:
+		return "critical"
+	case PRIORITY_HIGH:
+		return "high"
+	default:
+		return "low"
+	}
+}
+
+var (
+	admissionTotal = metrics.NewCounter("http_admission_total",
+		"Admission decisions by priority and outcome (admitted, queued, shed).", "priority", "outcome")
+	admissionQueued = metrics.NewGauge("http_admission_queue_depth",
+		"Requests waiting for an admission slot.", "priority")
+)
+
+// Classifier assigns a priority to an incoming request.
+type Classifier func(r *http.Request) Priority
+
+// DefaultClassifier puts probes and metrics first, authenticated traffic next
+// and anonymous traffic (sign-ups, public endpoints) last. Event streams
+// bypass admission too: they stay open and mostly idle, and holding a slot
+// each would starve the requests the slots are for.
+func DefaultClassifier(r *http.Request) Priority {
+	switch r.URL.Path {
+	case "/health", "/readyz", "/metrics", "/version":
+		return PRIORITY_CRITICAL
+	}
+	if IsEventStream(r) {
+		return PRIORITY_CRITICAL
+	}
+	if r.Header.Get("Authorization") != "" {
+		return PRIORITY_HIGH
+	}
+	return PRIORITY_LOW
+}
+
+type AdmissionControllerArgs struct {
+	// Capacity is the number of requests served concurrently.
+	Capacity int
+	// MaxQueue bounds the waiters per priority; beyond it requests are shed.
+	MaxQueue int
+	// QueueTimeout is how long a request of each priority may wait for a slot.
+	QueueTimeout map[Priority]time.Duration
+	Classifier   Classifier
+}
+
+// AdmissionController serves up to Capacity requests at once and hands freed
+// slots to the highest-priority waiter first, so critical and authenticated
+// traffic keeps flowing while anonymous traffic absorbs the overload.
+type AdmissionController struct {
+	capacity     int
+	maxQueue     int
+	queueTimeout map[Priority]time.Duration
+	classify     Classifier
+
+	mu      sync.Mutex
+	active  int
+	waiters [PRIORITY_CRITICAL]*list.List
+}
+
+func NewAdmissionController(args AdmissionControllerArgs) *AdmissionController {
+	if args.Classifier == nil {
+		args.Classifier = DefaultClassifier
+	}
+	c := &AdmissionController{
+		capacity:     args.Capacity,
+		maxQueue:     args.MaxQueue,
+		queueTimeout: args.QueueTimeout,
+		classify:     args.Classifier,
+	}
+	for i := range c.waiters {
+		c.waiters[i] = list.New()
+	}
+	return c
+}
+
+func (c *AdmissionController) Middleware(next http.Handler) http.Handler {
+	if c.capacity <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := c.classify(r)
+		if p == PRIORITY_CRITICAL {
+			admissionTotal.Inc(p.String(), "admitted")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !c.acquire(r, p) {
+			admissionTotal.Inc(p.String(), "shed")
+			w.Header().Set("Retry-After", "1")
+			response.Error(w, r, http.StatusServiceUnavailable, ErrOverloaded)
+			return
+		}
+		defer c.release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *AdmissionController) acquire(r *http.Request, p Priority) bool {
+	c.mu.Lock()
+	if c.active < c.capacity {
+		c.active++
+		c.mu.Unlock()
+		admissionTotal.Inc(p.String(), "admitted")
+		return true
+	}
+	if c.waiters[p].Len() >= c.maxQueue {
+		c.mu.Unlock()
+		return false
+	}
+
+	ready := make(chan struct{})
+	elem := c.waiters[p].PushBack(ready)
+	admissionQueued.Inc(p.String())
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.queueTimeout[p])
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		admissionTotal.Inc(p.String(), "queued")
+		return true
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-ready:
+		// The slot was handed over while we were timing out; keep it.
+		admissionTotal.Inc(p.String(), "queued")
+		return true
+	default:
+		c.waiters[p].Remove(elem)
+		admissionQueued.Dec(p.String())
+		return false
+	}
+}
+
+// release hands the slot directly to the highest-priority waiter, if any.
+func (c *AdmissionController) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for p := len(c.waiters) - 1; p >= 0; p-- {
+		if front := c.waiters[p].Front(); front != nil {
+			c.waiters[p].Remove(front)
+			admissionQueued.Dec(Priority(p).String())
+			close(front.Value.(chan struct{}))
+			return
+		}
+	}
+	c.active--
+}
@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/anomaly"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/quota"
+)
+
+// The scopes the failures of a credential route are told apart by: all of
+// its requests, those of each autonomous system and those of each subnet.
+const (
+	AUTH_SCOPE_GLOBAL = "global"
+	AUTH_SCOPE_ASN    = "asn"
+	AUTH_SCOPE_SUBNET = "subnet"
+)
+
+// The subnet scope groups IPv4 addresses by /24 and IPv6 ones by /48, what
+// a single network is commonly given.
+const (
+	authSubnetBitsV4 = 24
+	authSubnetBitsV6 = 48
+)
+
+var authProtectedKey = ctxutil.NewKey[bool]("auth_protected")
+
+var (
+	authAnomalies = metrics.NewGauge("auth_anomalies",
+		"Scopes of a credential route whose failure rate is anomalous, by route and scope (global, asn, subnet).", "route", "scope")
+	authAnomalyChangesTotal = metrics.NewCounter("auth_anomaly_changes_total",
+		"Failure rate anomalies of credential routes by route, scope and state (detected, subsided).", "route", "scope", "state")
+	authProtectionTotal = metrics.NewCounter("auth_protection_requests_total",
+		"Credential route requests under adaptive protection by route and outcome (protected, rate_limited).", "route", "outcome")
+)
+
+type AuthProtectionArgs struct {
+	// Rules tells a failure spike per scope; scopes without one are not
+	// tracked.
+	Rules    map[string]anomaly.Rule
+	Window   time.Duration
+	Cooldown time.Duration
+	// MaxKeys bounds the ASNs and subnets tracked at once.
+	MaxKeys int
+	// Locator resolves the ASN of clients; nil leaves the asn scope out.
+	Locator  contract.GeoLocator
+	Override contract.AuthProtectionRepository
+	// Limiter counts the requests of each address against RateLimit while
+	// they are protected; no limits leave them unlimited.
+	Limiter   *quota.Limiter
+	RateLimit []quota.Limit
+}
+
+// AuthProtection tightens sign-up and sign-in for the clients whose scope
+// sees a spike of failures, e.g. from credential stuffing: their requests
+// are rate limited per address and must pass a CAPTCHA. Every response but
+// a 5xx counts, the protection's own rejections as failures, so an attack
+// it holds off keeps it on. Admins can force it on or off through the
+// override. A nil AuthProtection protects nothing.
+type AuthProtection struct {
+	rules     map[string]anomaly.Rule
+	detector  *anomaly.Detector
+	locator   contract.GeoLocator
+	override  contract.AuthProtectionRepository
+	limiter   *quota.Limiter
+	rateLimit []quota.Limit
+}
+
+func NewAuthProtection(args AuthProtectionArgs) (*AuthProtection, error) {
+	for scope, rule := range args.Rules {
+		switch scope {
+		case AUTH_SCOPE_GLOBAL, AUTH_SCOPE_ASN, AUTH_SCOPE_SUBNET:
+		default:
+			return nil, fmt.Errorf("unknown auth protection scope %q", scope)
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("auth protection scope %s: %w", scope, err)
+		}
+	}
+	detector, err := anomaly.New(anomaly.Args{
+		Window:   args.Window,
+		Cooldown: args.Cooldown,
+		// The global scope of each route is always tracked.
+		MaxKeys:  args.MaxKeys + len(CredentialRoutes),
+		OnChange: logAuthAnomaly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AuthProtection{
+		rules:     args.Rules,
+		detector:  detector,
+		locator:   args.Locator,
+		override:  args.Override,
+		limiter:   args.Limiter,
+		rateLimit: args.RateLimit,
+	}, nil
+}
+
+// AuthProtected reports whether the request of ctx is protected, which
+// tells bot detection to ask sign-ups for a CAPTCHA.
+func AuthProtected(ctx context.Context) bool {
+	protected, _ := ctxutil.Get(ctx, authProtectedKey)
+	return protected
+}
+
+// Route protects one credential route, e.g. Route(CREDENTIAL_ROUTE_SIGN_IN,
+// captcha). captcha is the check protected requests must pass; nil leaves it
+// to the handler.
+func (p *AuthProtection) Route(route string, captcha func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if p == nil {
+			return next
+		}
+		guarded := next
+		if captcha != nil {
+			guarded = captcha(next)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes := p.scopes(r, route)
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			defer func() {
+				if status := ww.Status(); status > 0 && status < http.StatusInternalServerError {
+					p.record(scopes, status >= http.StatusBadRequest)
+				}
+			}()
+
+			if !p.protects(r, scopes) {
+				next.ServeHTTP(ww, r)
+				return
+			}
+			r = r.WithContext(ctxutil.With(r.Context(), authProtectedKey, true))
+			if !p.takeRateLimit(ww, r, route) {
+				authProtectionTotal.Inc(route, "rate_limited")
+				return
+			}
+			authProtectionTotal.Inc(route, "protected")
+			guarded.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// authScope is a scope a request falls in, keyed for the detector.
+type authScope struct {
+	key  string
+	rule anomaly.Rule
+}
+
+// scopes returns the scopes of r that have a rule.
+func (p *AuthProtection) scopes(r *http.Request, route string) []authScope {
+	scopes := make([]authScope, 0, 3)
+	add := func(scope, value string) {
+		if rule, ok := p.rules[scope]; ok {
+			scopes = append(scopes, authScope{key: route + " " + scope + " " + value, rule: rule})
+		}
+	}
+	add(AUTH_SCOPE_GLOBAL, "")
+
+	ip := clientinfo.IP(r)
+	if _, ok := p.rules[AUTH_SCOPE_ASN]; ok && p.locator != nil {
+		location, err := p.locator.Locate(r.Context(), ip)
+		if err != nil {
+			logger.Sample(ctxutil.Logger(r.Context()), "auth_protection.locate", 100).Warnw("locate client ip", "error", err)
+		} else if location.ASN != 0 {
+			add(AUTH_SCOPE_ASN, strconv.FormatUint(uint64(location.ASN), 10))
+		}
+	}
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		addr = addr.Unmap()
+		bits := authSubnetBitsV6
+		if addr.Is4() {
+			bits = authSubnetBitsV4
+		}
+		if prefix, err := addr.Prefix(bits); err == nil {
+			add(AUTH_SCOPE_SUBNET, prefix.String())
+		}
+	}
+	return scopes
+}
+
+// protects reports whether the override, or failing that a spike in one of
+// scopes, calls for protecting r. An override that cannot be read counts
+// as auto.
+func (p *AuthProtection) protects(r *http.Request, scopes []authScope) bool {
+	if override, err := p.override.Get(r.Context()); err == nil {
+		switch override.Mode {
+		case entity.AUTH_PROTECTION_ON:
+			return true
+		case entity.AUTH_PROTECTION_OFF:
+			return false
+		}
+	}
+	for _, s := range scopes {
+		if p.detector.Anomalous(s.key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *AuthProtection) record(scopes []authScope, failed bool) {
+	for _, s := range scopes {
+		p.detector.Record(s.key, s.rule, failed)
+	}
+}
+
+// takeRateLimit counts r against the rate limit of its address and answers
+// 429 when it is used up. Requests pass when the limiter fails.
+func (p *AuthProtection) takeRateLimit(w http.ResponseWriter, r *http.Request, route string) bool {
+	if len(p.rateLimit) == 0 {
+		return true
+	}
+	res, err := p.limiter.TakeLimits(r.Context(), "auth_protection:"+route+":"+clientinfo.IP(r), p.rateLimit)
+	if err != nil {
+		logger.Sample(ctxutil.Logger(r.Context()), "auth_protection.rate_limit", 100).Warnw("take auth protection rate limit", "error", err)
+		return true
+	}
+	if res.Allowed {
+		return true
+	}
+	wait := math.Ceil(time.Until(res.ResetAt).Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(max(int(wait), 1)))
+	response.Error(w, r, http.StatusTooManyRequests, errs.ErrTooManyAttempts)
+	return false
+}
+
+// logAuthAnomaly reports an anomaly of a scope starting or ending.
+func logAuthAnomaly(change anomaly.Change) {
+	route, rest, _ := strings.Cut(change.Key, " ")
+	scope, value, _ := strings.Cut(rest, " ")
+	fields := []any{"route", route, "scope", scope, "failures", change.Failures, "attempts", change.Attempts}
+	if value != "" {
+		fields = append(fields, scope, value)
+	}
+	if change.Anomalous {
+		authAnomalies.Inc(route, scope)
+		authAnomalyChangesTotal.Inc(route, scope, "detected")
+		logger.L().Warnw("credential failure spike detected, protecting the scope", fields...)
+		return
+	}
+	authAnomalies.Dec(route, scope)
+	authAnomalyChangesTotal.Inc(route, scope, "subsided")
+	logger.L().Infow("credential failure spike subsided", fields...)
+}
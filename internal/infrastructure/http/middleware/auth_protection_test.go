@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/haidang666/go-app/pkg/anomaly"
+)
+
+func TestAuthScopesIgnoreUntrustedForwarding(t *testing.T) {
+	p := &AuthProtection{rules: map[string]anomaly.Rule{
+		AUTH_SCOPE_SUBNET: {MinFailures: 1, Ratio: 1},
+	}}
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	subnetKey := func(peer string, header http.Header) string {
+		var key string
+		h := RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes := p.scopes(r, CREDENTIAL_ROUTE_SIGN_IN)
+			if len(scopes) != 1 {
+				t.Fatalf("scopes = %v, want the subnet one", scopes)
+			}
+			key = scopes[0].key
+		}))
+		r := httptest.NewRequest(http.MethodPost, "/auth/sign-in", nil)
+		r.RemoteAddr = peer
+		r.Header = header
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return key
+	}
+	spoofed := func(ip string) http.Header {
+		return http.Header{
+			"X-Forwarded-For": {ip},
+			"X-Real-Ip":       {ip},
+			"True-Client-Ip":  {ip},
+		}
+	}
+
+	want := CREDENTIAL_ROUTE_SIGN_IN + " subnet 203.0.113.0/24"
+	for _, ip := range []string{"198.51.100.1", "192.0.2.1", "2001:db8::1"} {
+		if got := subnetKey("203.0.113.7:4242", spoofed(ip)); got != want {
+			t.Errorf("untrusted peer claiming %s: scope %q, want %q", ip, got, want)
+		}
+	}
+
+	// Behind a trusted proxy the forwarded address counts, and a client
+	// prepending its own hops does not move it.
+	header := http.Header{"X-Forwarded-For": {"192.0.2.1, 198.51.100.1, 10.0.0.2"}}
+	if got, want := subnetKey("10.0.0.1:4242", header), CREDENTIAL_ROUTE_SIGN_IN+" subnet 198.51.100.0/24"; got != want {
+		t.Errorf("via trusted proxies: scope %q, want %q", got, want)
+	}
+}
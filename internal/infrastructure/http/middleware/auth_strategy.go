@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/jwt"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/reqsign"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// The authentication strategies, by the name AUTH_STRATEGIES lists them
+// under.
+const (
+	AUTH_STRATEGY_JWT     = "jwt"
+	AUTH_STRATEGY_API_KEY = "api_key"
+	AUTH_STRATEGY_COOKIE  = "cookie"
+	AUTH_STRATEGY_HMAC    = "hmac"
+)
+
+// The route groups, each authenticating with its own strategies.
+const (
+	AUTH_GROUP_API   = "api"
+	AUTH_GROUP_ADMIN = "admin"
+)
+
+// DefaultAuthStrategies are the strategies of the groups AUTH_STRATEGIES
+// leaves out.
+var DefaultAuthStrategies = []string{AUTH_STRATEGY_JWT, AUTH_STRATEGY_API_KEY}
+
+var ErrCrossSiteRequest = errors.New("cookie-authenticated request from another origin")
+
+// ParseAuthStrategies maps each route group to the strategies it tries, in
+// the order its spec names them, separated by "|", e.g. "jwt|api_key".
+// Groups without a spec get DefaultAuthStrategies. available holds the
+// strategies configured; naming another one is an error.
+func ParseAuthStrategies(specs map[string]string, available []AuthStrategy) (map[string][]AuthStrategy, error) {
+	byName := make(map[string]AuthStrategy, len(available))
+	for _, s := range available {
+		byName[s.Name()] = s
+	}
+	lookup := func(group string, names []string) ([]AuthStrategy, error) {
+		strategies := make([]AuthStrategy, 0, len(names))
+		for i, name := range names {
+			name = strings.TrimSpace(name)
+			s, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("group %q: strategy %q is unknown or not configured", group, name)
+			}
+			if slices.Contains(names[:i], name) {
+				return nil, fmt.Errorf("group %q: strategy %q is listed twice", group, name)
+			}
+			strategies = append(strategies, s)
+		}
+		return strategies, nil
+	}
+
+	groups := make(map[string][]AuthStrategy, 2)
+	for _, group := range []string{AUTH_GROUP_API, AUTH_GROUP_ADMIN} {
+		names := DefaultAuthStrategies
+		if spec, ok := specs[group]; ok {
+			names = strings.Split(spec, "|")
+		}
+		strategies, err := lookup(group, names)
+		if err != nil {
+			return nil, err
+		}
+		groups[group] = strategies
+	}
+	for group := range specs {
+		if _, ok := groups[group]; !ok {
+			return nil, fmt.Errorf("unknown group %q", group)
+		}
+	}
+	return groups, nil
+}
+
+// BearerJWT accepts the bearer access tokens issued here and, when External
+// is set, those of the issuers it trusts.
+type BearerJWT struct {
+	JWT      *jwt.Client
+	External *ExternalUsers
+}
+
+func (s *BearerJWT) Name() string { return AUTH_STRATEGY_JWT }
+
+func (s *BearerJWT) Authenticate(r *http.Request) (*ctxutil.Principal, error) {
+	tokenStr, ok := bearerToken(r)
+	if !ok || isAPIKey(tokenStr) {
+		return nil, ErrNoCredentials
+	}
+	if s.External != nil && s.External.Verifier.Accepts(tokenStr) {
+		return s.External.authenticate(r, tokenStr)
+	}
+	return accessTokenPrincipal(s.JWT, tokenStr)
+}
+
+// APIKeys accepts bearer personal access tokens when Tokens is set and
+// service account keys when ServiceAccounts is set, told apart by their
+// prefix. Personal access tokens carry their scopes and no roles.
+type APIKeys struct {
+	Tokens          contract.PersonalAccessTokenRepository
+	ServiceAccounts *ServiceAccounts
+}
+
+func (s *APIKeys) Name() string { return AUTH_STRATEGY_API_KEY }
+
+func (s *APIKeys) Authenticate(r *http.Request) (*ctxutil.Principal, error) {
+	tokenStr, ok := bearerToken(r)
+	switch {
+	case !ok:
+		return nil, ErrNoCredentials
+	case s.Tokens != nil && strings.HasPrefix(tokenStr, entity.PERSONAL_TOKEN_PREFIX):
+		return s.personalToken(r, tokenStr)
+	case s.ServiceAccounts != nil && strings.HasPrefix(tokenStr, entity.SERVICE_ACCOUNT_KEY_PREFIX):
+		return s.ServiceAccounts.authenticate(r, tokenStr)
+	}
+	return nil, ErrNoCredentials
+}
+
+func (s *APIKeys) personalToken(r *http.Request, tokenStr string) (*ctxutil.Principal, error) {
+	t, err := s.Tokens.GetByHash(r.Context(), securetoken.Hash(tokenStr))
+	if err != nil || !t.IsActive(time.Now()) {
+		return nil, jwt.ErrInvalidToken
+	}
+	if err := s.Tokens.Touch(r.Context(), t.ID, time.Now().UTC()); err != nil {
+		logger.Sample(ctxutil.Logger(r.Context()), "middleware.touch_token", 100).Warnw("touch personal token", "token_id", t.ID, "error", err)
+	}
+	return &ctxutil.Principal{
+		ID:              t.UserID,
+		TokenID:         t.ID.String(),
+		Scopes:          t.Scopes,
+		PersonalTokenID: t.ID,
+	}, nil
+}
+
+func isAPIKey(tokenStr string) bool {
+	return strings.HasPrefix(tokenStr, entity.PERSONAL_TOKEN_PREFIX) || strings.HasPrefix(tokenStr, entity.SERVICE_ACCOUNT_KEY_PREFIX)
+}
+
+// SessionCookie accepts an access token issued here in the cookie named
+// Cookie, for a web front end on the API's site that keeps the tokens out
+// of reach of its scripts. Browsers send the cookie along with cross-site
+// requests too, so the requests that change anything must come from the
+// API's own origin.
+type SessionCookie struct {
+	JWT    *jwt.Client
+	Cookie string
+}
+
+func (s *SessionCookie) Name() string { return AUTH_STRATEGY_COOKIE }
+
+func (s *SessionCookie) Authenticate(r *http.Request) (*ctxutil.Principal, error) {
+	c, err := r.Cookie(s.Cookie)
+	if err != nil || c.Value == "" {
+		return nil, ErrNoCredentials
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if !sameOrigin(r) {
+			return nil, &authError{status: http.StatusForbidden, err: ErrCrossSiteRequest}
+		}
+	}
+	return accessTokenPrincipal(s.JWT, c.Value)
+}
+
+// sameOrigin reports whether the Origin header, which browsers send with
+// every request that changes anything, names the API's host.
+func sameOrigin(r *http.Request) bool {
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	return err == nil && origin.Host != "" && strings.EqualFold(origin.Host, r.Host)
+}
+
+// InternalHMAC accepts the requests of internal services signed with their
+// shared secret, see pkg/reqsign. The caller is the service, with Roles;
+// there is no user or session.
+type InternalHMAC struct {
+	Verifier *reqsign.Verifier
+	Roles    []string
+}
+
+// internalServiceSpace derives the IDs of internal services from their
+// names, so quotas and audit entries keep them apart.
+var internalServiceSpace = uuid.MustParse("5f0c6f3e-8b1e-4d55-9a4b-2f1f3c9e7a10")
+
+func (s *InternalHMAC) Name() string { return AUTH_STRATEGY_HMAC }
+
+func (s *InternalHMAC) Authenticate(r *http.Request) (*ctxutil.Principal, error) {
+	if r.Header.Get(reqsign.HEADER_SIGNATURE) == "" {
+		return nil, ErrNoCredentials
+	}
+	service, err := s.Verifier.Verify(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ctxutil.Principal{
+		ID:      uuid.NewSHA1(internalServiceSpace, []byte(service)),
+		Roles:   slices.Clone(s.Roles),
+		Service: service,
+	}, nil
+}
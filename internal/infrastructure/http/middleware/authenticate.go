@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jwks"
+	"github.com/haidang666/go-app/pkg/jwt"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrUnknownUser  = errors.New("token subject no longer exists")
+	// ErrNoCredentials is returned by an AuthStrategy when the request
+	// carries none of its credentials, so the next one is tried.
+	ErrNoCredentials = errors.New("no credentials for this strategy")
+)
+
+var loadedUserKey = ctxutil.NewKey[*entity.User]("loaded_user")
+
+// LoadedUserFrom returns the stored user of the authenticated caller; it is
+// only set when Authenticate was built with a repository.
+func LoadedUserFrom(ctx context.Context) (*entity.User, bool) {
+	u, ok := ctxutil.Get(ctx, loadedUserKey)
+	return u, ok && u != nil
+}
+
+// AuthStrategy is one way of authenticating a request, such as a bearer
+// access token or a session cookie. Authenticate returns ErrNoCredentials
+// when the request does not carry the strategy's credentials, and any other
+// error when it carries invalid ones, which rejects the request.
+type AuthStrategy interface {
+	Name() string
+	Authenticate(r *http.Request) (*ctxutil.Principal, error)
+}
+
+// authError is an authentication failure answered with another status than
+// 401, such as the 403 of a valid token whose subject cannot be used.
+type authError struct {
+	status int
+	err    error
+}
+
+func (e *authError) Error() string { return e.err.Error() }
+
+func (e *authError) Unwrap() error { return e.err }
+
+// Authenticate requires the request to be accepted by the first of
+// strategies whose credentials it carries, and stores the caller as the
+// ctxutil.Principal. When userRepo is non-nil the user is loaded as well,
+// rejecting the credentials of deleted users and of users whose access an
+// admin revoked after the token was issued, and answering 403 to those of
+// suspended or banned ones. Personal access tokens and external tokens need
+// it for the email and tenant a JWT would carry. Service accounts and
+// internal services have no user to load.
+func Authenticate(strategies []AuthStrategy, userRepo contract.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, err := authenticate(r, strategies)
+			if err != nil {
+				var ae *authError
+				if errors.As(err, &ae) {
+					response.Error(w, r, ae.status, ae.err)
+				} else {
+					unauthorized(w, r, err)
+				}
+				return
+			}
+
+			var loaded *entity.User
+			if userRepo != nil && !current.IsServiceAccount() && !current.IsInternal() {
+				u, err := userRepo.GetByID(r.Context(), current.ID)
+				if err != nil {
+					unauthorized(w, r, ErrUnknownUser)
+					return
+				}
+				if err := u.CheckStatus(); err != nil {
+					response.Error(w, r, http.StatusForbidden, err)
+					return
+				}
+				if !current.IssuedAt.IsZero() && u.AccessRevoked(current.IssuedAt) {
+					unauthorized(w, r, errs.ErrAccessRevoked)
+					return
+				}
+				loaded = u
+				if current.IsPersonalToken() || current.IsExternal() {
+					current.Email, current.TenantID = u.Email, u.TenantID
+				}
+			}
+
+			ctx := ctxutil.WithCurrentUser(r.Context(), current)
+			if current.TenantID != "" {
+				ctx = ctxutil.WithTenant(ctx, current.TenantID)
+			}
+			log := ctxutil.Logger(ctx).With("user_id", current.ID.String())
+			if current.IsImpersonated() {
+				log = log.With("impersonator_id", current.ImpersonatorID.String())
+			}
+			if current.IsPersonalToken() {
+				log = log.With("personal_token_id", current.PersonalTokenID.String())
+			}
+			if current.IsExternal() {
+				log = log.With("issuer", current.Issuer)
+			}
+			if current.IsServiceAccount() {
+				log = log.With("service_account_id", current.ServiceAccountID.String())
+			}
+			if current.IsInternal() {
+				log = log.With("service", current.Service)
+			}
+			ctx = ctxutil.WithLogger(ctx, log)
+			if loaded != nil {
+				ctx = ctxutil.With(ctx, loadedUserKey, loaded)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticate runs strategies in order until one finds its credentials.
+// A bearer token none of them takes is invalid rather than missing.
+func authenticate(r *http.Request, strategies []AuthStrategy) (*ctxutil.Principal, error) {
+	for _, s := range strategies {
+		current, err := s.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		current.Method = s.Name()
+		return current, nil
+	}
+	if _, ok := bearerToken(r); ok {
+		return nil, jwt.ErrInvalidToken
+	}
+	return nil, ErrMissingToken
+}
+
+// accessTokenPrincipal verifies an access token issued here.
+func accessTokenPrincipal(jwtClient *jwt.Client, tokenStr string) (*ctxutil.Principal, error) {
+	claims, err := jwtClient.VerifyType(tokenStr, jwt.TOKEN_TYPE_ACCESS)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		return nil, jwt.ErrInvalidToken
+	}
+
+	sessionID, _ := uuid.Parse(claims.SessionID)
+	impersonatorID, _ := uuid.Parse(claims.ActorID())
+	current := &ctxutil.Principal{
+		ID:             id,
+		Email:          claims.Email,
+		Roles:          claims.Roles,
+		TenantID:       claims.TenantID,
+		TokenID:        claims.ID,
+		SessionID:      sessionID,
+		Scopes:         claims.Scopes(),
+		ImpersonatorID: impersonatorID,
+	}
+	if claims.IssuedAt != nil {
+		current.IssuedAt = claims.IssuedAt.Time
+	}
+	return current, nil
+}
+
+// ExternalUsers lets the jwt strategy accept the access tokens of the
+// issuers Verifier trusts. Resolve maps their subject to a local user,
+// linking it on first use.
+type ExternalUsers struct {
+	Verifier *jwks.Verifier
+	Resolve  func(ctx context.Context, claims *dto.ExternalIdentityClaims) (*entity.User, error)
+}
+
+// authenticate verifies an external token and resolves its user. Subjects
+// that cannot be linked get a 403, as their token is valid.
+func (e *ExternalUsers) authenticate(r *http.Request, tokenStr string) (*ctxutil.Principal, error) {
+	claims, err := e.Verifier.Verify(r.Context(), tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	u, err := e.Resolve(r.Context(), &dto.ExternalIdentityClaims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Username:      claims.PreferredUsername,
+	})
+	switch {
+	case errors.Is(err, errs.ErrExternalUserNotProvisioned), errors.Is(err, errs.ErrExternalAccountConflict), errors.Is(err, errs.ErrExternalIdentityExists):
+		return nil, &authError{status: http.StatusForbidden, err: err}
+	case errors.Is(err, errs.ErrUserNotFound):
+		return nil, ErrUnknownUser
+	case err != nil:
+		return nil, &authError{status: http.StatusInternalServerError, err: err}
+	}
+	return &ctxutil.Principal{
+		ID:       u.ID,
+		Email:    u.Email,
+		TenantID: u.TenantID,
+		TokenID:  claims.ID,
+		Issuer:   claims.Issuer,
+	}, nil
+}
+
+// AuthenticateDelegated is Authenticate for the access tokens held by OpenID
+// Connect relying parties. Those are rejected by Authenticate so a relying
+// party cannot reach the rest of the API with them.
+func AuthenticateDelegated(jwtClient *jwt.Client) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenStr, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+
+			claims, err := jwtClient.VerifyType(tokenStr, jwt.TOKEN_TYPE_DELEGATED)
+			if err != nil {
+				unauthorized(w, r, err)
+				return
+			}
+			id, err := uuid.Parse(claims.UserID())
+			if err != nil {
+				unauthorized(w, r, jwt.ErrInvalidToken)
+				return
+			}
+
+			sessionID, _ := uuid.Parse(claims.SessionID)
+			ctx := ctxutil.WithCurrentUser(r.Context(), &ctxutil.Principal{
+				ID:        id,
+				TokenID:   claims.ID,
+				SessionID: sessionID,
+				Scopes:    claims.Scopes(),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	response.Error(w, r, http.StatusUnauthorized, err)
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jwks"
+	"github.com/haidang666/go-app/pkg/jwt"
+)
+
+var (
+	ErrUnknownClient     = errors.New("token client is unknown or revoked")
+	ErrInsufficientScope = errors.New("token lacks the required scope")
+)
+
+// AuthenticateClient requires a bearer client token from the OAuth
+// client_credentials grant and stores the caller as ctxutil.CurrentClient.
+// The client is reloaded on every request so revocation takes effect
+// immediately.
+//
+// When external is set, tokens from its allowed issuers are accepted too;
+// those callers have no client here, so they get the default plan and the
+// scopes their issuer granted.
+func AuthenticateClient(jwtClient *jwt.Client, clientRepo contract.OAuthClientRepository, external *jwks.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenStr, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+			if external != nil && external.Accepts(tokenStr) {
+				authenticateExternal(w, r, next, external, tokenStr)
+				return
+			}
+
+			claims, err := jwtClient.VerifyType(tokenStr, jwt.TOKEN_TYPE_CLIENT)
+			if err != nil {
+				unauthorized(w, r, err)
+				return
+			}
+
+			client, err := clientRepo.GetByClientID(r.Context(), claims.UserID())
+			if err != nil || !client.IsActive() {
+				unauthorized(w, r, ErrUnknownClient)
+				return
+			}
+
+			ctx := ctxutil.WithCurrentClient(r.Context(), &ctxutil.CurrentClient{
+				ClientID: client.ClientID,
+				TokenID:  claims.ID,
+				Scopes:   claims.Scopes(),
+				Plan:     client.Plan,
+			})
+			ctx = ctxutil.WithLogger(ctx, ctxutil.Logger(ctx).With("client_id", client.ClientID))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func authenticateExternal(w http.ResponseWriter, r *http.Request, next http.Handler, external *jwks.Verifier, tokenStr string) {
+	claims, err := external.Verify(r.Context(), tokenStr)
+	if err != nil {
+		unauthorized(w, r, err)
+		return
+	}
+	ctx := ctxutil.WithCurrentClient(r.Context(), &ctxutil.CurrentClient{
+		ClientID: claims.Subject,
+		Issuer:   claims.Issuer,
+		TokenID:  claims.ID,
+		Scopes:   claims.Scopes(),
+	})
+	ctx = ctxutil.WithLogger(ctx, ctxutil.Logger(ctx).With("client_id", claims.Subject, "issuer", claims.Issuer))
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireClientScope answers 403 unless the client token carries scope. It
+// must run after AuthenticateClient.
+func RequireClientScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, ok := ctxutil.CurrentClientFrom(r.Context())
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+			if !client.HasScope(scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				response.Error(w, r, http.StatusForbidden, ErrInsufficientScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/redact"
+)
+
+type BodyLoggerArgs struct {
+	// SampleRate is the fraction of requests (0..1) logged regardless of outcome.
+	SampleRate float64
+	// OnError always logs requests answered with a 5xx status.
+	OnError  bool
+	MaxBytes int
+	Redactor *redact.Redactor
+}
+
+// BodyLogger captures request and response bodies and logs them for sampled
+// or failed requests. It is meant for incident debugging and is off unless
+// explicitly mounted.
+func BodyLogger(args BodyLoggerArgs) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sampled := args.SampleRate > 0 && rand.Float64() < args.SampleRate
+			if !sampled && !args.OnError {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqBody := &limitedBuffer{max: args.MaxBytes}
+			if r.Body != nil {
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(r.Body, reqBody), r.Body}
+			}
+
+			resBody := &limitedBuffer{max: args.MaxBytes}
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(resBody)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if !sampled && status < http.StatusInternalServerError {
+				return
+			}
+
+			ctxutil.Logger(r.Context()).Infow("http body capture",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"sampled", sampled,
+				"request_headers", args.Redactor.Headers(r.Header),
+				"request_body", reqBody.String(args.Redactor, r.Header.Get("Content-Type")),
+				"response_body", resBody.String(args.Redactor, ww.Header().Get("Content-Type")),
+			)
+		})
+	}
+}
+
+// limitedBuffer keeps the first max bytes written to it and counts the rest.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the captured body of type contentType, masked. A body that
+// cannot be parsed for redaction, truncated or not, is dropped rather than
+// risk leaking secrets.
+func (b *limitedBuffer) String(redactor *redact.Redactor, contentType string) string {
+	if b.truncated {
+		return "[TRUNCATED]"
+	}
+	return string(redactor.Body(contentType, b.buf.Bytes()))
+}
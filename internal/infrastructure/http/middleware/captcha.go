@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// CAPTCHA_HEADER carries the widget's response token. A header keeps the
+// check independent of each endpoint's body schema.
+const CAPTCHA_HEADER = "X-Captcha-Token"
+
+// RequireCaptcha rejects requests without a valid CAPTCHA token. Provider
+// outages fail closed with 503.
+func RequireCaptcha(verifier contract.CaptchaVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get(CAPTCHA_HEADER)
+			if token == "" {
+				response.Error(w, r, http.StatusBadRequest, errs.ErrCaptchaRequired)
+				return
+			}
+
+			if err := verifier.Verify(r.Context(), token, clientinfo.IP(r)); err != nil {
+				if errors.Is(err, errs.ErrCaptchaFailed) {
+					response.Error(w, r, http.StatusForbidden, err)
+					return
+				}
+				ctxutil.Logger(r.Context()).Errorw("captcha verification", "error", err)
+				response.Error(w, r, http.StatusServiceUnavailable, errs.ErrCaptchaFailed)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jsonschema"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// Contract enforcement modes.
+const (
+	CONTRACT_MODE_OFF     = "off"
+	CONTRACT_MODE_REPORT  = "report"
+	CONTRACT_MODE_ENFORCE = "enforce"
+)
+
+// maxContractBody is the largest body checked; larger ones are left to the
+// handler, which rejects them anyway.
+const maxContractBody = 1 << 20
+
+var contractChecksTotal = metrics.NewCounter("http_contract_checks_total",
+	"Request bodies checked against their endpoint's schema, by outcome (valid, violation, invalid_json, skipped).", "outcome")
+
+// ContractViolationError rejects a request body that breaks its endpoint's
+// schema, listing every violation.
+type ContractViolationError struct {
+	Violations []jsonschema.Violation
+}
+
+func (e *ContractViolationError) Error() string {
+	return fmt.Sprintf("request body violates the endpoint contract (%d violations)", len(e.Violations))
+}
+
+func (e *ContractViolationError) ErrorCode() string { return "contract_violation" }
+
+func (e *ContractViolationError) ErrorDetails() map[string]any {
+	return map[string]any{"violations": e.Violations}
+}
+
+// SchemaRegistry holds the request body schema of each endpoint.
+type SchemaRegistry struct {
+	routes []contractRoute
+}
+
+type contractRoute struct {
+	method   string
+	segments []string
+	schema   *jsonschema.Schema
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{}
+}
+
+// Register sets the schema of the bodies sent to method and pattern. In the
+// pattern, a {name} segment matches any one path segment, as in chi and
+// OpenAPI.
+func (s *SchemaRegistry) Register(method, pattern string, schema *jsonschema.Schema) {
+	s.routes = append(s.routes, contractRoute{
+		method:   strings.ToUpper(method),
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		schema:   schema,
+	})
+}
+
+// RegisterOpenAPI registers the request body schemas of the operations of
+// an OpenAPI document.
+func (s *SchemaRegistry) RegisterOpenAPI(file string) error {
+	ops, err := jsonschema.LoadOpenAPI(file)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		s.Register(op.Method, op.Path, op.Schema)
+	}
+	return nil
+}
+
+// Len returns the number of registered endpoints.
+func (s *SchemaRegistry) Len() int { return len(s.routes) }
+
+// match returns the schema of the request's endpoint. Literal segments win
+// over parameters, so /users/me is matched before /users/{id}.
+func (s *SchemaRegistry) match(method, path string) *jsonschema.Schema {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var (
+		best      *jsonschema.Schema
+		bestScore = -1
+	)
+	for _, route := range s.routes {
+		if route.method != method || len(route.segments) != len(segments) {
+			continue
+		}
+		score := 0
+		for i, seg := range route.segments {
+			switch {
+			case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			case seg == segments[i]:
+				score++
+			default:
+				score = -1
+			}
+			if score < 0 {
+				break
+			}
+		}
+		if score > bestScore {
+			best, bestScore = route.schema, score
+		}
+	}
+	return best
+}
+
+// Middleware checks JSON request bodies against the schemas of their
+// endpoints. In report mode violations are only logged and counted, so a
+// deployment can be observed before it is made strict; in enforce mode they
+// are rejected with 400 and the list of violations. Requests to endpoints
+// without a schema, and non-JSON bodies, pass unchecked.
+func (s *SchemaRegistry) Middleware(mode string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			schema := s.match(r.Method, r.URL.Path)
+			if schema == nil || !isJSONRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, ok := bufferBody(r, maxContractBody)
+			if !ok {
+				contractChecksTotal.Inc("skipped")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var v any
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err != nil {
+				// The handler reports empty and malformed bodies in its own
+				// words.
+				contractChecksTotal.Inc("invalid_json")
+				next.ServeHTTP(w, r)
+				return
+			}
+			violations := schema.Validate(v)
+			if len(violations) == 0 {
+				contractChecksTotal.Inc("valid")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			contractChecksTotal.Inc("violation")
+			ctxutil.Logger(r.Context()).Warnw("request contract violation",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"mode", mode,
+				"violations", violations,
+			)
+			if mode == CONTRACT_MODE_ENFORCE {
+				response.Error(w, r, http.StatusBadRequest, &ContractViolationError{Violations: violations})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
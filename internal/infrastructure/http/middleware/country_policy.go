@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var countryBlockedTotal = metrics.NewCounter("country_policy_blocked_total",
+	"Requests refused by the country access policy, by country code.", "country")
+
+// CountryPolicy answers 403 to requests from countries outside allow, when
+// it is not empty, or in deny. Countries are ISO 3166-1 alpha-2 codes.
+// Requests whose address the locator cannot place, such as private ranges
+// or lookup failures, are let through: the policy keeps out regions, it is
+// not an access control. It must run after RealIP.
+func CountryPolicy(locator contract.GeoLocator, allow, deny []string) func(http.Handler) http.Handler {
+	allowed, denied := countrySet(allow), countrySet(deny)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			location, err := locator.Locate(r.Context(), clientinfo.IP(r))
+			if err != nil {
+				logger.Sample(ctxutil.Logger(r.Context()), "country_policy.locate", 100).Warnw("locate client ip", "error", err)
+			}
+			code := location.CountryCode
+			if code != "" {
+				ctxutil.SetMeta(r.Context(), ctxutil.MetaCountry, code)
+				_, isAllowed := allowed[code]
+				_, isDenied := denied[code]
+				if isDenied || (len(allowed) > 0 && !isAllowed) {
+					countryBlockedTotal.Inc(code)
+					response.Error(w, r, http.StatusForbidden, errs.ErrCountryNotAllowed)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func countrySet(codes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			set[c] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// The credential routes hash a password on every request, which is what
+// credential stuffing makes expensive.
+const (
+	CREDENTIAL_ROUTE_SIGN_UP = "sign_up"
+	CREDENTIAL_ROUTE_SIGN_IN = "sign_in"
+)
+
+var CredentialRoutes = []string{CREDENTIAL_ROUTE_SIGN_UP, CREDENTIAL_ROUTE_SIGN_IN}
+
+var (
+	credentialGateTotal = metrics.NewCounter("http_credential_gate_total",
+		"Credential route requests by route and outcome (admitted, queued, shed, timeout, canceled).", "route", "outcome")
+	credentialGateInFlight = metrics.NewGauge("http_credential_gate_in_flight",
+		"Credential route requests being served.", "route")
+	credentialGateQueued = metrics.NewGauge("http_credential_gate_queue_depth",
+		"Credential route requests waiting for a slot.", "route")
+	credentialGateWait = metrics.NewHistogram("http_credential_gate_wait_seconds",
+		"How long credential route requests waited for a slot, whether or not they got one.", nil, "route")
+)
+
+type CredentialGateArgs struct {
+	// Limits caps the concurrent requests per credential route; routes
+	// without an entry, or with 0, are unlimited.
+	Limits map[string]int
+	// MaxQueue bounds the waiters per route; beyond it requests are shed.
+	MaxQueue     int
+	QueueTimeout time.Duration
+	RetryAfter   time.Duration
+}
+
+// CredentialGate caps the concurrent requests of each credential route, so
+// a flood of sign-ins cannot take all the CPU the rest of the API needs. It
+// is separate from the LoadShedder: requests beyond a cap wait briefly for
+// a slot, as a burst of genuine sign-ins would, and only then get 503.
+type CredentialGate struct {
+	routes       map[string]*credentialRoute
+	maxQueue     int64
+	queueTimeout time.Duration
+	retryAfter   string
+}
+
+type credentialRoute struct {
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+func NewCredentialGate(args CredentialGateArgs) (*CredentialGate, error) {
+	g := &CredentialGate{
+		routes:       make(map[string]*credentialRoute, len(args.Limits)),
+		maxQueue:     int64(args.MaxQueue),
+		queueTimeout: args.QueueTimeout,
+		retryAfter:   strconv.Itoa(max(int(args.RetryAfter.Seconds()), 1)),
+	}
+	for route, limit := range args.Limits {
+		switch route {
+		case CREDENTIAL_ROUTE_SIGN_UP, CREDENTIAL_ROUTE_SIGN_IN:
+		default:
+			return nil, fmt.Errorf("unknown credential route %q, expected one of %v", route, CredentialRoutes)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("credential route %s: negative limit %d", route, limit)
+		}
+		if limit > 0 {
+			g.routes[route] = &credentialRoute{slots: make(chan struct{}, limit)}
+		}
+	}
+	return g, nil
+}
+
+// Limit returns the cap of route, 0 for none.
+func (g *CredentialGate) Limit(route string) int {
+	if c, ok := g.routes[route]; ok {
+		return cap(c.slots)
+	}
+	return 0
+}
+
+// Route limits one credential route, e.g. Route(CREDENTIAL_ROUTE_SIGN_IN).
+func (g *CredentialGate) Route(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		c, ok := g.routes[route]
+		if !ok {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			outcome := g.acquire(r, route, c)
+			credentialGateTotal.Inc(route, outcome)
+			if outcome != "admitted" && outcome != "queued" {
+				w.Header().Set("Retry-After", g.retryAfter)
+				response.Error(w, r, http.StatusServiceUnavailable, ErrOverloaded)
+				return
+			}
+			credentialGateInFlight.Inc(route)
+			defer func() {
+				<-c.slots
+				credentialGateInFlight.Dec(route)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire takes a slot of c, waiting up to the queue timeout for one, and
+// returns the outcome: admitted or queued when it got one.
+func (g *CredentialGate) acquire(r *http.Request, route string, c *credentialRoute) string {
+	select {
+	case c.slots <- struct{}{}:
+		return "admitted"
+	default:
+	}
+	if c.queued.Add(1) > g.maxQueue {
+		c.queued.Add(-1)
+		return "shed"
+	}
+	credentialGateQueued.Inc(route)
+	defer func(start time.Time) {
+		c.queued.Add(-1)
+		credentialGateQueued.Dec(route)
+		credentialGateWait.Observe(time.Since(start).Seconds(), route)
+	}(time.Now())
+
+	timer := time.NewTimer(g.queueTimeout)
+	defer timer.Stop()
+	select {
+	case c.slots <- struct{}{}:
+		return "queued"
+	case <-timer.C:
+		return "timeout"
+	case <-r.Context().Done():
+		return "canceled"
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var deadlineExceededTotal = metrics.NewCounter("http_request_deadline_exceeded_total",
+	"Requests that ran out of their deadline budget, by route.", "route")
+
+// budget is the Deadline of a request, for Unbounded to lift.
+type budget struct {
+	// parent is the request's context before the budget.
+	parent context.Context
+	lifted bool
+}
+
+var budgetKey = ctxutil.NewKey[*budget]("deadline_budget")
+
+// Deadline gives every request a deadline budget elapsed from the moment it
+// reaches the middleware, so the database, Redis, HTTP and queue calls made
+// with its context give up with it rather than outlive it. Mount it first:
+// the time spent queueing for admission counts against the budget. A budget
+// of 0 disables it. Event streams are unbounded, as are the routes mounted
+// with Unbounded.
+func Deadline(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsEventStream(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			b := &budget{parent: r.Context()}
+			ctx, cancel := context.WithTimeout(ctxutil.With(r.Context(), budgetKey, b), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+			if b.lifted || !errors.Is(ctx.Err(), context.DeadlineExceeded) || r.Context().Err() != nil {
+				return
+			}
+			route := "unmatched"
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			deadlineExceededTotal.Inc(route)
+		})
+	}
+}
+
+// Unbounded lifts the Deadline budget off the routes it is mounted on, e.g.
+// the exports and imports, which manage their write deadline chunk by chunk
+// and which a budget would cut off. Their context keeps its values and is
+// still cancelled when the client goes away.
+func Unbounded(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := ctxutil.Get(r.Context(), budgetKey)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		b.lifted = true
+		ctx, cancel := context.WithCancelCause(context.WithoutCancel(r.Context()))
+		defer cancel(nil)
+		stop := context.AfterFunc(b.parent, func() { cancel(context.Cause(b.parent)) })
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+func TestUnboundedLiftsTheBudget(t *testing.T) {
+	userKey := ctxutil.NewKey[string]("user")
+	deadlines := make(map[string]bool)
+	record := func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		deadlines[r.URL.Path] = ok
+		if user, _ := ctxutil.Get(r.Context(), userKey); user != "alice" {
+			t.Errorf("%s: request values lost, user = %q", r.URL.Path, user)
+		}
+	}
+
+	r := chi.NewRouter()
+	r.Use(Deadline(time.Minute))
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(ctxutil.With(r.Context(), userKey, "alice")))
+		})
+	})
+	r.Get("/bounded", record)
+	r.With(Unbounded).Get("/export", record)
+
+	for _, path := range []string{"/bounded", "/export"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if !deadlines["/bounded"] {
+		t.Error("/bounded has no deadline")
+	}
+	if deadlines["/export"] {
+		t.Error("/export kept the budget")
+	}
+}
+
+func TestUnboundedFollowsTheClient(t *testing.T) {
+	var cause error
+	h := Deadline(time.Minute)(Unbounded(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		cause = context.Cause(r.Context())
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx))
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("unbounded request outlived its client")
+	}
+	if cause != context.Canceled {
+		t.Errorf("cause = %v, want %v", cause, context.Canceled)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// isDenied reports whether r falls under one of the rules of denied. A rule
+// is a path, covering it and the paths under it, or a method and a path,
+// e.g. "DELETE /api/v1/me", covering that method on exactly that path, for
+// paths that prefix others.
+func isDenied(r *http.Request, denied []string) bool {
+	for _, rule := range denied {
+		if method, path, ok := strings.Cut(rule, " "); ok {
+			if r.Method == method && strings.TrimSuffix(r.URL.Path, "/") == path {
+				return true
+			}
+			continue
+		}
+		if r.URL.Path == rule || strings.HasPrefix(r.URL.Path, rule+"/") {
+			return true
+		}
+	}
+	return false
+}
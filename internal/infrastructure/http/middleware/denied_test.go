@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsDenied(t *testing.T) {
+	denied := []string{"/api/v1/me/tokens", "DELETE /api/v1/me"}
+	for _, tc := range []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/api/v1/me/tokens", true},
+		{"POST", "/api/v1/me/tokens/abc", true},
+		{"GET", "/api/v1/me/tokensx", false},
+		{"DELETE", "/api/v1/me", true},
+		{"DELETE", "/api/v1/me/", true},
+		{"GET", "/api/v1/me", false},
+		{"DELETE", "/api/v1/me/sessions", false},
+	} {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		if got := isDenied(r, denied); got != tc.want {
+			t.Errorf("%s %s: denied = %v, want %v", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// maxDeprecatedFieldsBody is how much of a JSON body is read to look for
+// deprecated fields; the fields of larger bodies go unnoticed.
+const maxDeprecatedFieldsBody = 1 << 20
+
+var deprecatedRequestsTotal = metrics.NewCounter("http_deprecated_requests_total",
+	"Requests using a deprecated route or field, by route (\"METHOD pattern\" or \"METHOD pattern#field\").", "route")
+
+type DeprecatedArgs struct {
+	// Deprecations are those of one route: either the route itself, or
+	// some fields of its requests.
+	Deprecations []*entity.Deprecation
+	Usage        contract.DeprecationUsageRepository
+}
+
+// Deprecated tells the clients of a deprecated route, or of one using a
+// deprecated field of its requests, with the Deprecation (RFC 9745), Sunset
+// (RFC 8594) and Link headers, and records which clients still do so. The
+// first request of each client is logged. It must run after authentication
+// to tell the clients apart; unauthenticated ones are "anonymous".
+func Deprecated(args DeprecatedArgs) func(http.Handler) http.Handler {
+	var route, fields []*entity.Deprecation
+	for _, d := range args.Deprecations {
+		args.Usage.Declare(context.Background(), d)
+		if d.Field == "" {
+			route = append(route, d)
+		} else {
+			fields = append(fields, d)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			used := route
+			if len(fields) > 0 {
+				used = append(used[:len(used):len(used)], usedFields(r, fields)...)
+			}
+			if len(used) > 0 {
+				deprecationHeaders(w.Header(), used)
+				recordDeprecated(r, args.Usage, used)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// usedFields returns the deprecations of the fields r sets, in its query or
+// at the top level of its JSON body.
+func usedFields(r *http.Request, fields []*entity.Deprecation) []*entity.Deprecation {
+	query := r.URL.Query()
+	var body map[string]json.RawMessage
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" && r.Body != nil {
+		head, err := io.ReadAll(io.LimitReader(r.Body, maxDeprecatedFieldsBody))
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+		if err == nil {
+			json.Unmarshal(head, &body)
+		}
+	}
+
+	var used []*entity.Deprecation
+	for _, d := range fields {
+		_, inBody := body[d.Field]
+		if inBody || query.Has(d.Field) {
+			used = append(used, d)
+		}
+	}
+	return used
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// deprecationHeaders announces the earliest deprecation and sunset of used,
+// and links the documentation of each.
+func deprecationHeaders(h http.Header, used []*entity.Deprecation) {
+	var since, sunset time.Time
+	for _, d := range used {
+		if since.IsZero() || d.Since.Before(since) {
+			since = d.Since
+		}
+		if d.Sunset != nil && (sunset.IsZero() || d.Sunset.Before(sunset)) {
+			sunset = *d.Sunset
+		}
+		if d.Link != "" {
+			h.Add("Link", "<"+d.Link+`>; rel="deprecation"; type="text/html"`)
+		}
+	}
+	h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+func recordDeprecated(r *http.Request, usage contract.DeprecationUsageRepository, used []*entity.Deprecation) {
+	client, _, ok := quotaSubject(r)
+	if !ok {
+		client = "anonymous"
+	}
+	now := time.Now().UTC()
+	for _, d := range used {
+		deprecatedRequestsTotal.Inc(d.Route())
+		first, err := usage.Record(r.Context(), d, client, now)
+		if err != nil {
+			logger.Sample(ctxutil.Logger(r.Context()), "middleware.deprecated", 100).Warnw("record deprecated api usage", "route", d.Route(), "error", err)
+			continue
+		}
+		if first {
+			ctxutil.Logger(r.Context()).Infow("deprecated api used", "route", d.Route(), "client", client, "sunset", d.Sunset)
+		}
+	}
+}
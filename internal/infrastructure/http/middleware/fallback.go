@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var fallbacksTotal = metrics.NewCounter("dependency_fallbacks_total",
+	"Requests served while the store of a feature failed, by feature and fail mode (open, closed).", "feature", "mode")
+
+// fallback serves a request whose store failed: with failOpen it carries
+// on without the feature, otherwise it answers 503.
+func fallback(w http.ResponseWriter, r *http.Request, next http.Handler, feature string, failOpen bool) {
+	if failOpen {
+		fallbacksTotal.Inc(feature, "open")
+		next.ServeHTTP(w, r)
+		return
+	}
+	fallbacksTotal.Inc(feature, "closed")
+	response.Error(w, r, http.StatusServiceUnavailable, errs.ErrDependencyUnavailable)
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// Headers a client sends to request a fault for one request.
+const (
+	FAULT_DELAY_HEADER = "X-Fault-Delay"
+	FAULT_ERROR_HEADER = "X-Fault-Error"
+	FAULT_DROP_HEADER  = "X-Fault-Drop"
+)
+
+var ErrInjectedFault = errors.New("injected fault")
+
+var faultInjectionsTotal = metrics.NewCounter("http_fault_injections_total",
+	"Faults injected into requests for chaos testing, by kind.", "kind")
+
+// FaultRule describes the faults injected into the requests of a route.
+type FaultRule struct {
+	Delay time.Duration
+	// ErrorRate is the fraction of requests (0..1) answered with ErrorStatus.
+	ErrorRate   float64
+	ErrorStatus int
+	// DropRate is the fraction of requests whose connection is closed
+	// without a response.
+	DropRate float64
+}
+
+type FaultInjectorArgs struct {
+	// Rules are keyed by path prefix; the longest matching prefix applies.
+	Rules map[string]FaultRule
+	// AllowHeaders lets clients request faults with the X-Fault-* headers,
+	// which replace the route's rule.
+	AllowHeaders bool
+}
+
+// InjectFaults delays, fails or drops requests on purpose so client retries
+// and resilience can be tested end-to-end. It must never be mounted in
+// production.
+func InjectFaults(args FaultInjectorArgs) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, ok := matchFaultRule(args.Rules, r.URL.Path)
+			if args.AllowHeaders {
+				if hr, present, err := faultRuleFromHeaders(r.Header); err != nil {
+					response.Error(w, r, http.StatusBadRequest, err)
+					return
+				} else if present {
+					rule, ok = hr, true
+				}
+			}
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rule.Delay > 0 {
+				faultInjectionsTotal.Inc("delay")
+				select {
+				case <-time.After(rule.Delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if rule.DropRate > 0 && rand.Float64() < rule.DropRate {
+				faultInjectionsTotal.Inc("drop")
+				// net/http closes the connection without answering.
+				panic(http.ErrAbortHandler)
+			}
+			if rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate {
+				faultInjectionsTotal.Inc("error")
+				response.Error(w, r, rule.ErrorStatus, ErrInjectedFault)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matchFaultRule(rules map[string]FaultRule, path string) (FaultRule, bool) {
+	var (
+		best    FaultRule
+		bestLen = -1
+	)
+	for prefix, rule := range rules {
+		if strings.HasPrefix(path, prefix) && len(prefix) > bestLen {
+			best, bestLen = rule, len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// faultRuleFromHeaders reads X-Fault-Delay (a duration), X-Fault-Error (a
+// status code, always returned) and X-Fault-Drop ("true").
+func faultRuleFromHeaders(h http.Header) (rule FaultRule, present bool, err error) {
+	if v := h.Get(FAULT_DELAY_HEADER); v != "" {
+		present = true
+		if rule.Delay, err = time.ParseDuration(v); err != nil || rule.Delay < 0 {
+			return FaultRule{}, false, fmt.Errorf("%s must be a duration", FAULT_DELAY_HEADER)
+		}
+	}
+	if v := h.Get(FAULT_ERROR_HEADER); v != "" {
+		present = true
+		status, err := strconv.Atoi(v)
+		if err != nil || status < 400 || status > 599 {
+			return FaultRule{}, false, fmt.Errorf("%s must be a 4xx or 5xx status", FAULT_ERROR_HEADER)
+		}
+		rule.ErrorRate, rule.ErrorStatus = 1, status
+	}
+	if v := h.Get(FAULT_DROP_HEADER); v != "" {
+		present = true
+		drop, err := strconv.ParseBool(v)
+		if err != nil {
+			return FaultRule{}, false, fmt.Errorf("%s must be a boolean", FAULT_DROP_HEADER)
+		}
+		if drop {
+			rule.DropRate = 1
+		}
+	}
+	return rule, present, nil
+}
+
+// ParseFaultRules parses rule specs of the form
+// "delay=200ms;error=0.1;status=503;drop=0.05", keyed by path prefix. Every
+// field is optional; status defaults to 503.
+func ParseFaultRules(specs map[string]string) (map[string]FaultRule, error) {
+	rules := make(map[string]FaultRule, len(specs))
+	for prefix, spec := range specs {
+		rule := FaultRule{ErrorStatus: http.StatusServiceUnavailable}
+		for _, part := range strings.Split(spec, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("route %q: %q is not key=value", prefix, part)
+			}
+			var err error
+			switch key {
+			case "delay":
+				rule.Delay, err = time.ParseDuration(value)
+			case "error":
+				rule.ErrorRate, err = parseRate(value)
+			case "status":
+				rule.ErrorStatus, err = strconv.Atoi(value)
+				if err == nil && (rule.ErrorStatus < 400 || rule.ErrorStatus > 599) {
+					err = errors.New("not a 4xx or 5xx status")
+				}
+			case "drop":
+				rule.DropRate, err = parseRate(value)
+			default:
+				err = errors.New("unknown key")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("route %q: invalid %s %q: %w", prefix, key, value, err)
+			}
+		}
+		rules[prefix] = rule
+	}
+	return rules, nil
+}
+
+func parseRate(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f > 1 {
+		return 0, errors.New("not between 0 and 1")
+	}
+	return f, nil
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/dedupe"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// IDEMPOTENCY_KEY_HEADER names the key a client picks for a request and
+// sends again with its retries.
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+
+// MAX_IDEMPOTENCY_KEY_LENGTH bounds the keys stored per request.
+const MAX_IDEMPOTENCY_KEY_LENGTH = 255
+
+// errNotApplied releases the key of a request that failed, so a retry with
+// the same key runs again.
+var errNotApplied = errors.New("request not applied")
+
+// Idempotency makes the requests of a caller carrying the same
+// Idempotency-Key for the same method and path take effect once. A retry
+// of a request that succeeded gets a 409 rather than running again, as
+// does one sent while the first is still running; a request answered with
+// an error status does not count, so it can be retried with its key.
+// Requests without a key get a 400. It must run after Authenticate or
+// AuthenticateClient.
+func Idempotency(deduper *dedupe.Deduper) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IDEMPOTENCY_KEY_HEADER)
+			if key == "" || len(key) > MAX_IDEMPOTENCY_KEY_LENGTH {
+				response.Error(w, r, http.StatusBadRequest, errs.ErrIdempotencyKeyRequired)
+				return
+			}
+			subject, _, ok := quotaSubject(r)
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+
+			id := subject + "|" + r.Method + " " + r.URL.Path + "|" + key
+			ran := false
+			duplicate, err := deduper.Do(r.Context(), "http", id, func(ctx context.Context) error {
+				ran = true
+				ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+				next.ServeHTTP(ww, r)
+				if ww.Status() >= http.StatusBadRequest {
+					return errNotApplied
+				}
+				return nil
+			})
+			switch {
+			case duplicate:
+				response.Error(w, r, http.StatusConflict, errs.ErrRequestAlreadyProcessed)
+			case errors.Is(err, dedupe.ErrInProgress):
+				response.Error(w, r, http.StatusConflict, errs.ErrRequestInProgress)
+			case err != nil && !ran:
+				logger.Sample(ctxutil.Logger(r.Context()), "middleware.idempotency", 100).Warnw("claim idempotency key", "error", err)
+				response.Error(w, r, http.StatusServiceUnavailable, errs.ErrDependencyUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// AuditImpersonation writes every request made with an impersonation token to
+// the audit log, including the ones later middleware rejects. It must run
+// after Authenticate.
+func AuditImpersonation(auditLogRepo contract.AuditLogRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !current.IsImpersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			_, err := auditLogRepo.Append(r.Context(), &entity.AuditEvent{
+				Action:    entity.AUDIT_IMPERSONATED_REQUEST,
+				ActorID:   current.ImpersonatorID,
+				SubjectID: current.ID,
+				SessionID: &current.SessionID,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    status,
+				IP:        clientinfo.IP(r),
+				Device:    clientinfo.Device(r).String(),
+				CreatedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				ctxutil.Logger(r.Context()).Errorw("audit impersonated request", "error", err)
+			}
+		})
+	}
+}
+
+// RestrictImpersonation answers 403 to impersonation tokens on the requests
+// denied covers, such as admin endpoints and credential changes; see
+// isDenied for its rules. It must run after Authenticate.
+func RestrictImpersonation(denied ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !current.IsImpersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isDenied(r, denied) {
+				response.Error(w, r, http.StatusForbidden, errs.ErrImpersonationNotAllowed)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
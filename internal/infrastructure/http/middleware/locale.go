@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+// Locale stores the primary language of the Accept-Language header in the
+// request context, e.g. "vi" for "vi-VN,vi;q=0.9,en;q=0.8".
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Accept-Language")
+		first, _, _ := strings.Cut(header, ",")
+		first, _, _ = strings.Cut(first, ";")
+		lang, _, _ := strings.Cut(strings.TrimSpace(first), "-")
+
+		if lang == "" || lang == "*" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctxutil.WithLocale(r.Context(), strings.ToLower(lang))))
+	})
+}
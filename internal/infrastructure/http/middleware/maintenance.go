@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// MAINTENANCE_PATH is the admin endpoint that toggles read-only mode, which
+// must stay writable to turn it off.
+const MAINTENANCE_PATH = "/api/v1/admin/maintenance"
+
+var readOnlyRejectedTotal = metrics.NewCounter("http_read_only_rejected_total",
+	"Write requests rejected with 503 because the service is read-only, by reason.", "reason")
+
+type ReadOnlyArgs struct {
+	Maintenance contract.MaintenanceRepository
+	RetryAfter  time.Duration
+	// AllowPaths are the write endpoints served even when read-only.
+	AllowPaths []string
+}
+
+// ReadOnly answers the write requests with 503 and ErrReadOnlyMode while the
+// service is in read-only mode; GET, HEAD and OPTIONS requests always pass.
+// The batch endpoint passes too, as its sub-requests come back through here
+// one by one. Requests pass when the mode cannot be read.
+func ReadOnly(args ReadOnlyArgs) func(http.Handler) http.Handler {
+	allow := map[string]struct{}{
+		MAINTENANCE_PATH: {},
+		"/api/v1/batch":  {},
+	}
+	for _, p := range args.AllowPaths {
+		allow[p] = struct{}{}
+	}
+	retryAfter := strconv.Itoa(max(int(args.RetryAfter.Seconds()), 1))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := allow[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			mode, err := args.Maintenance.Get(r.Context())
+			if err != nil || !mode.ReadOnly {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			readOnlyRejectedTotal.Inc(mode.Reason)
+			w.Header().Set("Retry-After", retryAfter)
+			details := []any{"reason", mode.Reason}
+			if mode.Since != nil {
+				details = append(details, "since", mode.Since.Format(time.RFC3339))
+			}
+			response.Error(w, r, http.StatusServiceUnavailable, errs.ErrReadOnlyMode.With(details...))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RestrictPersonalTokens enforces the scopes of personal access tokens, read
+// for safe methods and write for the others, and answers 403 to them on the
+// requests denied covers, such as token and credential management, so a
+// leaked token cannot be turned into a lasting takeover; see isDenied for
+// its rules. It must run after Authenticate.
+func RestrictPersonalTokens(denied ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !current.IsPersonalToken() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isDenied(r, denied) {
+				response.Error(w, r, http.StatusForbidden, errs.ErrPersonalTokenNotAllowed)
+				return
+			}
+
+			scope := entity.TOKEN_SCOPE_WRITE
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = entity.TOKEN_SCOPE_READ
+			}
+			if !current.HasScope(scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				response.Error(w, r, http.StatusForbidden, errs.ErrPersonalTokenScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
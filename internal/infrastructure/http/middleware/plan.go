@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// PlanGuard builds route middleware from a plan gate. Its middleware must
+// run after Authenticate.
+type PlanGuard struct {
+	gate contract.PlanGate
+}
+
+func NewPlanGuard(gate contract.PlanGate) *PlanGuard {
+	return &PlanGuard{gate: gate}
+}
+
+// RequirePlan answers 403 with code upgrade_required to users below plan.
+func (g *PlanGuard) RequirePlan(plan string) func(http.Handler) http.Handler {
+	return g.guard(func(r *http.Request) error {
+		current, _ := ctxutil.CurrentUserFrom(r.Context())
+		return g.gate.RequirePlan(r.Context(), current.ID, plan)
+	})
+}
+
+// RequireFeature answers 403 with code upgrade_required to users whose plan
+// does not include feature.
+func (g *PlanGuard) RequireFeature(feature string) func(http.Handler) http.Handler {
+	return g.guard(func(r *http.Request) error {
+		current, _ := ctxutil.CurrentUserFrom(r.Context())
+		return g.gate.RequireFeature(r.Context(), current.ID, feature)
+	})
+}
+
+func (g *PlanGuard) guard(check func(r *http.Request) error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := ctxutil.CurrentUserFrom(r.Context()); !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := check(r); err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, errs.ErrUpgradeRequired) {
+					status = http.StatusForbidden
+				}
+				response.Error(w, r, status, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
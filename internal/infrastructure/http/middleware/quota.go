@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/quota"
+)
+
+var tenantQuotaTotal = metrics.NewCounter("quota_tenant_requests_total",
+	"Requests counted against a tenant quota by tenant and outcome (allowed, warned, rejected).", "tenant", "outcome")
+
+// Quota counts every request against the caller's quota plan, reports the
+// most constrained limit in X-RateLimit-* headers and answers 429 once it is
+// used up. From the soft threshold of a limit on, responses carry an
+// X-RateLimit-Warning header first, so integrators can back off before
+// they are rejected. It must run after Authenticate or AuthenticateClient.
+// When the quota store fails, failOpen lets requests through unmetered;
+// otherwise they get a 503.
+//
+// Accounts of a tenant with QuotaLimits on its SAML connection, and the
+// tenant's service accounts, are also counted against the quota the
+// tenant's accounts share, once their own allows the request; the limits
+// are read on every request, so changes apply at once.
+func Quota(limiter *quota.Limiter, samlConnRepo contract.SAMLConnectionRepository, failOpen bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, plan, ok := quotaSubject(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := limiter.Take(r.Context(), subject, plan)
+			if err != nil {
+				logger.Sample(ctxutil.Logger(r.Context()), "middleware.quota", 100).Warnw("take quota", "subject", subject, "error", err)
+				fallback(w, r, next, "quota", failOpen)
+				return
+			}
+			if res.Allowed {
+				res = takeTenantQuota(r, limiter, samlConnRepo, res)
+			}
+
+			h := w.Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("X-RateLimit-Reset", strconv.FormatInt(res.ResetAt.Unix(), 10))
+			if res.Warning != nil {
+				h.Set("X-RateLimit-Warning", fmt.Sprintf("%d of %d requests per %s used; requests beyond %[2]d will be rejected",
+					res.Warning.Used, res.Warning.Limit.Requests, res.Warning.Limit.Window))
+			}
+			if !res.Allowed {
+				wait := math.Ceil(time.Until(res.ResetAt).Seconds())
+				h.Set("Retry-After", strconv.Itoa(max(int(wait), 1)))
+				err := fmt.Errorf("%w; resets at %s", errs.ErrQuotaExceeded, res.ResetAt.UTC().Format(time.RFC3339))
+				response.Error(w, r, http.StatusTooManyRequests, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// takeTenantQuota counts the request against the quota of the caller's
+// tenant, if it has one, and returns the more constrained of it and res.
+func takeTenantQuota(r *http.Request, limiter *quota.Limiter, samlConnRepo contract.SAMLConnectionRepository, res quota.Result) quota.Result {
+	tenant := quotaTenant(r)
+	if tenant == "" {
+		return res
+	}
+	log := logger.Sample(ctxutil.Logger(r.Context()), "middleware.quota", 100)
+	c, err := samlConnRepo.GetByTenant(r.Context(), tenant)
+	if err != nil {
+		if !errors.Is(err, errs.ErrSAMLConnectionNotFound) {
+			log.Warnw("load tenant quota", "tenant", tenant, "error", err)
+		}
+		return res
+	}
+	if c.QuotaLimits == "" {
+		return res
+	}
+	limits, err := quota.ParseLimits(c.QuotaLimits)
+	if err != nil {
+		log.Warnw("parse tenant quota", "tenant", tenant, "error", err)
+		return res
+	}
+
+	tenantRes, err := limiter.TakeLimits(r.Context(), "tenant:"+tenant, limits)
+	if err != nil {
+		log.Warnw("take tenant quota", "tenant", tenant, "error", err)
+		return res
+	}
+	switch {
+	case !tenantRes.Allowed:
+		tenantQuotaTotal.Inc(tenant, "rejected")
+	case tenantRes.Warning != nil:
+		tenantQuotaTotal.Inc(tenant, "warned")
+	default:
+		tenantQuotaTotal.Inc(tenant, "allowed")
+	}
+
+	if !tenantRes.Allowed || tenantRes.Remaining < res.Remaining {
+		if tenantRes.Warning == nil {
+			tenantRes.Warning = res.Warning
+		}
+		return tenantRes
+	}
+	if res.Warning == nil {
+		res.Warning = tenantRes.Warning
+	}
+	return res
+}
+
+// quotaTenant returns the tenant of the caller: the stored user's, or the
+// service account's organization.
+func quotaTenant(r *http.Request) string {
+	if u, ok := LoadedUserFrom(r.Context()); ok {
+		return u.TenantID
+	}
+	if current, ok := ctxutil.CurrentUserFrom(r.Context()); ok && current.IsServiceAccount() {
+		return current.TenantID
+	}
+	return ""
+}
+
+// quotaSubject identifies the caller and its plan. Impersonated requests
+// count against the impersonated user.
+func quotaSubject(r *http.Request) (string, string, bool) {
+	if client, ok := ctxutil.CurrentClientFrom(r.Context()); ok {
+		if client.Issuer != "" {
+			return "client:" + client.Issuer + "|" + client.ClientID, client.Plan, true
+		}
+		return "client:" + client.ClientID, client.Plan, true
+	}
+	current, ok := ctxutil.CurrentUserFrom(r.Context())
+	if !ok {
+		return "", "", false
+	}
+	if current.IsServiceAccount() {
+		return "service_account:" + current.ServiceAccountID.String(), "", true
+	}
+	if current.IsInternal() {
+		return "service:" + current.Service, "", true
+	}
+	plan := ""
+	if u, ok := LoadedUserFrom(r.Context()); ok {
+		plan = u.Plan
+	}
+	return "user:" + current.ID.String(), plan, true
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces RemoteAddr with the client address forwarded by the
+// trusted proxies, so that rate limits, sessions and logs see the client
+// rather than the proxy. The forwarding headers of any other peer are
+// ignored: a client could rotate them on every request to pass for many.
+// X-Forwarded-For is read from the right, skipping the trusted proxies on
+// the way; X-Real-IP is used when it is absent.
+//
+// It must run after RequestID, which checks the peer before it is replaced.
+func RealIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fromTrustedPeer(r, trustedProxies) {
+				if addr, ok := forwardedAddr(r.Header, trustedProxies); ok {
+					r.RemoteAddr = addr.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedAddr returns the first address in X-Forwarded-For, from the
+// right, that is not a trusted proxy, or the leftmost if all are. A hop
+// that does not parse ends the search there, as nothing left of it can be
+// told apart from what the client sent.
+func forwardedAddr(h http.Header, trusted []netip.Prefix) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range h.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	var addr netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !trustedAddr(addr, trusted) {
+			break
+		}
+	}
+	if addr.IsValid() {
+		return addr, true
+	}
+	if real, err := netip.ParseAddr(strings.TrimSpace(h.Get("X-Real-IP"))); err == nil {
+		return real.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
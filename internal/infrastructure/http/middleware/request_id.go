@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// RequestID assigns every request an ID, stores it (and a logger tagged with
+// it and the request's ctxutil.Meta) in the context via ctxutil and echoes
+// it in the X-Request-ID response header. An incoming X-Request-ID is
+// reused only when the direct peer is one of the trusted proxies, so
+// clients cannot forge IDs into our logs.
+//
+// It must run before RealIP, which rewrites RemoteAddr.
+func RequestID(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(REQUEST_ID_HEADER)
+			if id == "" || !validRequestID.MatchString(id) || !fromTrustedPeer(r, trustedProxies) {
+				id = uuid.NewString()
+			}
+
+			w.Header().Set(REQUEST_ID_HEADER, id)
+			ctx := ctxutil.WithRequestID(r.Context(), id)
+			ctx = ctxutil.WithLogger(ctx, logger.L().With("request_id", id))
+			ctx = ctxutil.WithMeta(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func fromTrustedPeer(r *http.Request, trusted []netip.Prefix) bool {
+	if len(trusted) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	return trustedAddr(addr.Unmap(), trusted)
+}
+
+func trustedAddr(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePrefixes parses CIDRs or bare IPs into prefixes.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if p, err := netip.ParsePrefix(v); err == nil {
+			out = append(out, p)
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RequireAdmin answers 403 unless the token carries entity.ROLE_ADMIN. It
+// must run after Authenticate.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := ctxutil.CurrentUserFrom(r.Context())
+		if !ok || !user.HasRole(entity.ROLE_ADMIN) {
+			response.Error(w, r, http.StatusForbidden, errs.ErrAdminRequired)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
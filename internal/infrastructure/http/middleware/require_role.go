@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RequireRole answers 403 unless the token carries one of roles. A single
+// entity.ROLE_ADMIN is RequireAdmin. It must run after Authenticate.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	if len(roles) == 1 && roles[0] == entity.ROLE_ADMIN {
+		return RequireAdmin
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !slices.ContainsFunc(roles, user.HasRole) {
+				response.Error(w, r, http.StatusForbidden, errs.ErrRoleRequired)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
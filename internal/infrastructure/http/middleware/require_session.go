@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// RequireActiveSession rejects access tokens whose session was revoked (e.g.
+// by "sign out everywhere") and records the session's last activity. It must
+// run after Authenticate. Personal access tokens, external issuers' tokens
+// service account keys and signed internal requests have no session here and
+// pass.
+// Sessions of organization members older than their organization's session
+// max age are rejected too. When the session store fails, failOpen lets the
+// request through on the strength of its access token alone; otherwise it
+// gets a 503.
+func RequireActiveSession(sessionRepo contract.SessionRepository, settingsRepo contract.OrganizationSettingsRepository, failOpen bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+			if current.IsPersonalToken() || current.IsExternal() || current.IsServiceAccount() || current.IsInternal() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now().UTC()
+			s, err := sessionRepo.GetByID(r.Context(), current.SessionID)
+			if err != nil && !errors.Is(err, errs.ErrSessionNotFound) {
+				logger.Sample(ctxutil.Logger(r.Context()), "middleware.get_session", 100).Warnw("get session", "session_id", current.SessionID, "error", err)
+				fallback(w, r, next, "sessions", failOpen)
+				return
+			}
+			if err != nil || s.UserID != current.ID || !s.IsActive(now) {
+				unauthorized(w, r, errs.ErrSessionRevoked)
+				return
+			}
+			if current.TenantID != "" {
+				settings, err := settingsRepo.Get(r.Context(), current.TenantID)
+				if err != nil {
+					logger.Sample(ctxutil.Logger(r.Context()), "middleware.get_organization_settings", 100).Warnw("get organization settings", "tenant", current.TenantID, "error", err)
+					fallback(w, r, next, "sessions", failOpen)
+					return
+				}
+				if settings.SessionTooOld(s.CreatedAt, now) {
+					unauthorized(w, r, errs.ErrSessionTooOld)
+					return
+				}
+			}
+
+			if err := sessionRepo.Touch(r.Context(), s.ID, clientinfo.IP(r), now); err != nil {
+				logger.Sample(ctxutil.Logger(r.Context()), "middleware.touch_session", 100).Warnw("touch session", "session_id", s.ID, "error", err)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RequireCurrentTerms blocks API access with 403 until the user has accepted
+// the current document versions. exempt lists request paths that must stay
+// reachable, such as the endpoint used to accept. Service accounts and
+// internal services have no one to accept them and pass. It must run after Authenticate.
+func RequireCurrentTerms(termsRepo contract.TermsAcceptanceRepository, current entity.TermsVersions, exempt ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]struct{}, len(exempt))
+	for _, p := range exempt {
+		skip[p] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := skip[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok {
+				unauthorized(w, r, ErrMissingToken)
+				return
+			}
+			if user.IsServiceAccount() || user.IsInternal() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			latest, err := termsRepo.Latest(r.Context(), user.ID)
+			if err != nil || latest.TermsVersions != current {
+				response.Error(w, r, http.StatusForbidden, errs.ErrTermsNotAccepted)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
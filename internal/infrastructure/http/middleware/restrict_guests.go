@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+)
+
+// RestrictGuests answers 403 to guest-scoped tokens on every path except
+// allowed, such as the upgrade endpoint. It must run after Authenticate.
+func RestrictGuests(allowed ...string) func(http.Handler) http.Handler {
+	allow := make(map[string]struct{}, len(allowed))
+	for _, p := range allowed {
+		allow[p] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !user.HasScope(entity.SCOPE_GUEST) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := allow[r.URL.Path]; !ok {
+				response.Error(w, r, http.StatusForbidden, errs.ErrGuestNotAllowed)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
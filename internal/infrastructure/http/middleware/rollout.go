@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/securecookie"
+)
+
+// ROLLOUT_COOKIE keeps anonymous callers, e.g. signing up, on the same side
+// of every rollout across requests.
+const ROLLOUT_COOKIE = "rollout_id"
+
+// The variants of a rollout.
+const (
+	ROLLOUT_STABLE    = "stable"
+	ROLLOUT_CANDIDATE = "candidate"
+)
+
+var (
+	rolloutRequestsTotal = metrics.NewCounter("rollout_requests_total",
+		"Requests of rolled out routes, by flag, variant (stable, candidate) and status class.", "flag", "variant", "class")
+	rolloutRequestDuration = metrics.NewHistogram("rollout_request_duration_seconds",
+		"Duration of the requests of rolled out routes, by flag and variant.", metrics.DefaultBuckets, "flag", "variant")
+)
+
+// RolloutRule selects the callers a flag's candidate serves: Percent (0 to
+// 100) of them, always the same ones, and every caller in the cohorts.
+type RolloutRule struct {
+	Percent float64
+	Users   []string
+	Tenants []string
+	Roles   []string
+	Clients []string
+}
+
+// Rollouts soft-launches new implementations of routes, such as a rewrite
+// of sign-up, behind named flags: a flag's candidate serves the share of
+// callers its rule selects and the stable implementation the others. A
+// caller is identified by their user or client ID, or else by the
+// rollout_id cookie, and hashed with the flag, so they stay on one side of
+// each flag while its percentage holds. The routes record their requests by
+// variant to compare error rates and latency. A nil *Rollouts, or a flag
+// without a rule, serves everything with the stable implementation.
+type Rollouts struct {
+	rules map[string]RolloutRule
+	// cookies seals the rollout_id cookie, so callers cannot pick the
+	// bucket they land in.
+	cookies *securecookie.Codec
+	// secureCookie marks the rollout_id cookie Secure.
+	secureCookie bool
+}
+
+func NewRollouts(rules map[string]RolloutRule, cookies *securecookie.Codec, secureCookie bool) *Rollouts {
+	return &Rollouts{rules: rules, cookies: cookies, secureCookie: secureCookie}
+}
+
+// Split serves the requests of the flag's callers with candidate and the
+// others with stable, e.g.
+//
+//	ur.Post("/sign-up", rollouts.Split("sign_up_v2", h.SignUp, h.SignUpV2))
+func (ro *Rollouts) Split(flag string, stable, candidate http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		variant, handler := ROLLOUT_STABLE, stable
+		if ro.Enabled(w, r, flag) {
+			variant, handler = ROLLOUT_CANDIDATE, candidate
+		}
+
+		start := time.Now()
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		handler(ww, r)
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		rolloutRequestsTotal.Inc(flag, variant, strconv.Itoa(status/100)+"xx")
+		rolloutRequestDuration.Observe(time.Since(start).Seconds(), flag, variant)
+	}
+}
+
+// Enabled reports whether the caller of r is in the flag's rollout, for
+// handlers that branch within themselves rather than Split. It may set the
+// rollout_id cookie on w, so call it before writing the response.
+func (ro *Rollouts) Enabled(w http.ResponseWriter, r *http.Request, flag string) bool {
+	if ro == nil {
+		return false
+	}
+	rule, ok := ro.rules[flag]
+	if !ok {
+		return false
+	}
+
+	var subject string
+	if u, ok := ctxutil.CurrentUserFrom(r.Context()); ok {
+		if slices.Contains(rule.Users, u.ID.String()) || u.TenantID != "" && slices.Contains(rule.Tenants, u.TenantID) {
+			return true
+		}
+		for _, role := range u.Roles {
+			if slices.Contains(rule.Roles, role) {
+				return true
+			}
+		}
+		subject = "user:" + u.ID.String()
+	} else if c, ok := ctxutil.CurrentClientFrom(r.Context()); ok {
+		if slices.Contains(rule.Clients, c.ClientID) {
+			return true
+		}
+		subject = "client:" + c.ClientID
+	} else {
+		subject = "anonymous:" + ro.anonymousID(w, r)
+	}
+	return rolloutBucket(flag, subject) < rule.Percent*100
+}
+
+// anonymousID returns the ID in the caller's rollout_id cookie, setting a
+// new one when they have none or it was not sealed by us.
+func (ro *Rollouts) anonymousID(w http.ResponseWriter, r *http.Request) string {
+	if id, err := ro.cookies.Cookie(r, ROLLOUT_COOKIE, 0); err == nil && len(id) > 0 {
+		return string(id)
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	id := hex.EncodeToString(buf)
+	ro.cookies.SetCookie(w, &http.Cookie{
+		Name:     ROLLOUT_COOKIE,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		Secure:   ro.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}, []byte(id))
+	return id
+}
+
+// rolloutBucket places subject in one of 10000 buckets, independently for
+// each flag so the same callers do not get every candidate first.
+func rolloutBucket(flag, subject string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flag + "\x00" + subject))
+	return float64(h.Sum32() % 10000)
+}
+
+// ParseRolloutRules parses rule specs of the form
+// "percent=10;roles=admin;tenants=acme|globex", keyed by flag. Every field
+// is optional; users, tenants, roles and clients take "|"-separated lists.
+func ParseRolloutRules(specs map[string]string) (map[string]RolloutRule, error) {
+	rules := make(map[string]RolloutRule, len(specs))
+	for flag, spec := range specs {
+		var rule RolloutRule
+		for _, part := range strings.Split(spec, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("flag %q: %q is not key=value", flag, part)
+			}
+			var err error
+			switch key {
+			case "percent":
+				rule.Percent, err = strconv.ParseFloat(value, 64)
+				if err == nil && (rule.Percent < 0 || rule.Percent > 100) {
+					err = errors.New("not between 0 and 100")
+				}
+			case "users":
+				rule.Users = strings.Split(value, "|")
+			case "tenants":
+				rule.Tenants = strings.Split(value, "|")
+			case "roles":
+				rule.Roles = strings.Split(value, "|")
+			case "clients":
+				rule.Clients = strings.Split(value, "|")
+			default:
+				err = errors.New("unknown key")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("flag %q: invalid %s %q: %w", flag, key, value, err)
+			}
+		}
+		rules[flag] = rule
+	}
+	return rules, nil
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/internal/infrastructure/http/clientinfo"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/http/response"
+	"github.com/haidang666/go-app/pkg/jwt"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/securetoken"
+)
+
+// ServiceAccounts lets the api_key strategy accept the keys of
+// organizations' service accounts.
+type ServiceAccounts struct {
+	Accounts contract.ServiceAccountRepository
+	Keys     contract.ServiceAccountKeyRepository
+}
+
+// authenticate looks up a service account key and its account. Keys of
+// deleted accounts are refused like revoked ones.
+func (s *ServiceAccounts) authenticate(r *http.Request, tokenStr string) (*ctxutil.Principal, error) {
+	now := time.Now().UTC()
+	k, err := s.Keys.GetByHash(r.Context(), securetoken.Hash(tokenStr))
+	if err != nil || !k.IsActive(now) {
+		return nil, jwt.ErrInvalidToken
+	}
+	a, err := s.Accounts.GetByID(r.Context(), k.ServiceAccountID)
+	if err != nil || !a.IsActive() {
+		return nil, jwt.ErrInvalidToken
+	}
+	if err := s.Keys.Touch(r.Context(), k.ID, now); err != nil {
+		logger.Sample(ctxutil.Logger(r.Context()), "middleware.touch_service_account_key", 100).Warnw("touch service account key", "key_id", k.ID, "error", err)
+	}
+	return &ctxutil.Principal{
+		ID:               a.ID,
+		Roles:            a.Roles,
+		TenantID:         a.Tenant,
+		TokenID:          k.ID.String(),
+		ServiceAccountID: a.ID,
+	}, nil
+}
+
+// AuditServiceAccounts writes every write request made with a service
+// account key to the audit log, including the ones later middleware
+// rejects, so what automation changed can be told apart from what people
+// did. Reads are not recorded, as CI polls. It must run after Authenticate.
+func AuditServiceAccounts(auditLogRepo contract.AuditLogRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !current.IsServiceAccount() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			_, err := auditLogRepo.Append(r.Context(), &entity.AuditEvent{
+				Action:    entity.AUDIT_SERVICE_ACCOUNT_REQUEST,
+				ActorID:   current.ServiceAccountID,
+				SubjectID: current.ServiceAccountID,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    status,
+				IP:        clientinfo.IP(r),
+				Device:    clientinfo.Device(r).String(),
+				CreatedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				ctxutil.Logger(r.Context()).Errorw("audit service account request", "error", err)
+			}
+		})
+	}
+}
+
+// RestrictServiceAccounts answers 403 to service accounts on the requests
+// denied covers, such as the endpoints acting on the caller's own user
+// account, which a service account does not have; see isDenied for its
+// rules. It must run after Authenticate.
+func RestrictServiceAccounts(denied ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || !current.IsServiceAccount() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isDenied(r, denied) {
+				response.Error(w, r, http.StatusForbidden, errs.ErrServiceAccountNotAllowed)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
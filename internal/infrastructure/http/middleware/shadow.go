@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// SHADOW_HEADER marks mirrored requests so the shadow deployment can tell
+// them apart, e.g. to skip sending emails.
+const SHADOW_HEADER = "X-Shadow-Request"
+
+var shadowRequestsTotal = metrics.NewCounter("http_shadow_requests_total",
+	"Requests considered for mirroring to the shadow target, by outcome.", "outcome")
+
+type ShadowArgs struct {
+	// Target is the base URL the mirrored requests are sent to. It receives
+	// the original headers, credentials included, so it must be trusted.
+	Target *url.URL
+	// Percent of requests (0..100) mirrored.
+	Percent float64
+	// MaxBodyBytes skips requests with larger bodies rather than buffer them.
+	MaxBodyBytes int64
+	// Timeout bounds each mirrored request when Client is not set.
+	Timeout time.Duration
+	// Concurrency caps the mirrored requests in flight; more are dropped.
+	Concurrency int
+	Client      *http.Client
+}
+
+// Shadow mirrors a sample of requests, bodies included, to a shadow target
+// in the background and ignores its responses, so a new version can be
+// validated against real traffic without affecting clients.
+func Shadow(args ShadowArgs) func(http.Handler) http.Handler {
+	if args.Client == nil {
+		args.Client = &http.Client{Timeout: args.Timeout}
+	}
+	inFlight := make(chan struct{}, max(args.Concurrency, 1))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rand.Float64()*100 >= args.Percent {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, ok := bufferBody(r, args.MaxBodyBytes)
+			if !ok {
+				shadowRequestsTotal.Inc("skipped_body")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case inFlight <- struct{}{}:
+				mirror := shadowRequest(r, args.Target, body)
+				go func() {
+					defer func() { <-inFlight }()
+					sendShadow(args.Client, mirror)
+				}()
+			default:
+				shadowRequestsTotal.Inc("dropped")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bufferBody reads the request body so it can be sent twice. When it is
+// larger than limit, r keeps reading the whole body and ok is false.
+func bufferBody(r *http.Request, limit int64) (body []byte, ok bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil || int64(len(body)) > limit {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, false
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+func shadowRequest(r *http.Request, target *url.URL, body []byte) *http.Request {
+	u := *target
+	u.Path = target.JoinPath(r.URL.Path).Path
+	u.RawQuery = r.URL.RawQuery
+
+	// Not bound to r's context: the mirror outlives the original request.
+	mirror, _ := http.NewRequestWithContext(context.Background(), r.Method, u.String(), bytes.NewReader(body))
+	mirror.Header = r.Header.Clone()
+	mirror.Header.Set(SHADOW_HEADER, "1")
+	if id := ctxutil.RequestID(r.Context()); id != "" {
+		mirror.Header.Set(REQUEST_ID_HEADER, id)
+	}
+	return mirror
+}
+
+func sendShadow(client *http.Client, mirror *http.Request) {
+	res, err := client.Do(mirror)
+	if err != nil {
+		shadowRequestsTotal.Inc("failed")
+		return
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	shadowRequestsTotal.Inc("sent")
+}
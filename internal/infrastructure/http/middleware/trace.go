@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/trace"
+)
+
+// Trace starts a server span for every request, continuing the caller's
+// trace from an incoming traceparent header, and tags the request logger
+// with the trace and span IDs. It must run after RequestID.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if parent, ok := trace.ParseTraceparent(r.Header.Get(trace.TRACEPARENT_HEADER)); ok {
+			ctx = trace.WithRemoteParent(ctx, parent)
+		}
+		ctx, span := trace.Start(ctx, "http "+r.Method)
+		defer span.End()
+
+		sc := span.Context()
+		ctx = ctxutil.WithLogger(ctx, ctxutil.Logger(ctx).With("trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String()))
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttr("http.method", r.Method)
+		span.SetAttr("http.status", status)
+		// The route pattern is only known once the router has matched.
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetAttr("http.route", rctx.RoutePattern())
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+// MeterUsage records an API call for the signed-in user once the request is
+// served. Server errors and impersonated requests are not billed to the
+// user, and service accounts and internal services have no user to bill. It must run after
+// Authenticate.
+func MeterUsage(meter contract.UsageMeter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, ok := ctxutil.CurrentUserFrom(r.Context())
+			if !ok || current.IsImpersonated() || current.IsServiceAccount() || current.IsInternal() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			if ww.Status() >= http.StatusInternalServerError {
+				return
+			}
+			meter.Record(r.Context(), dto.UsageEvent{
+				UserID:     current.ID,
+				Metric:     entity.USAGE_METRIC_API_CALLS,
+				Quantity:   1,
+				OccurredAt: time.Now(),
+			})
+		})
+	}
+}
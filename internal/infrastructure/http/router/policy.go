@@ -0,0 +1,205 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	appMiddleware "github.com/haidang666/go-app/internal/infrastructure/http/middleware"
+)
+
+// Auth is who may call a route.
+type Auth string
+
+const (
+	// AUTH_PUBLIC routes need no credentials.
+	AUTH_PUBLIC Auth = "public"
+	// AUTH_USER routes get the middleware of every signed-in route:
+	// authentication, session, quota, usage metering and the guest,
+	// impersonation and token restrictions.
+	AUTH_USER Auth = "user"
+	// AUTH_CLIENT routes are for OAuth machine clients, and count against
+	// their quota.
+	AUTH_CLIENT Auth = "client"
+)
+
+// Policy is what a route requires, declared with the route rather than
+// spelled out as middleware; the router turns it into the chain.
+type Policy struct {
+	// Auth defaults to AUTH_PUBLIC.
+	Auth Auth
+	// Roles lets in the users holding one of them; it needs AUTH_USER.
+	Roles []string
+	// Tier is the load shedding group the route is limited with, e.g.
+	// "admin", as sized by LOAD_SHED_GROUP_LIMITS; empty leaves it to the
+	// "api" group alone.
+	Tier string
+	// Idempotent requires an Idempotency-Key, so retries take effect once;
+	// it needs AUTH_USER or AUTH_CLIENT.
+	Idempotent bool
+	// MaxBodyBytes, when positive, answers 413 to larger bodies.
+	MaxBodyBytes int64
+	// Deprecated, when set, announces the route, or some fields of its
+	// requests, as deprecated, and reports who still uses them.
+	Deprecated *Deprecation
+}
+
+// Deprecation is when and why a route, or some fields of its requests, are
+// deprecated.
+type Deprecation struct {
+	// Since is when it was deprecated.
+	Since time.Time
+	// Sunset is when it stops working; zero until a date is announced.
+	Sunset time.Time
+	// Link documents the replacement.
+	Link string
+	// Fields, when set, deprecate these request fields rather than the
+	// route: top-level JSON body members or query parameters. Only the
+	// requests setting one are then flagged.
+	Fields []string
+}
+
+// Route is a route declared with its policy, relative to /api/v1.
+type Route struct {
+	Method  string
+	Pattern string
+	Handler http.HandlerFunc
+	Policy  Policy
+}
+
+// RouteTable collects the routes modules declare and mounts them on the
+// router they are attached to; routes added before NewRouter attaches it
+// are mounted then. Routes must all be added before serving starts.
+type RouteTable struct {
+	mu     sync.Mutex
+	routes []Route
+	mount  func(Route)
+}
+
+func NewRouteTable() *RouteTable {
+	return &RouteTable{}
+}
+
+// Add declares route. A policy that cannot be met, such as roles on a
+// public route, panics: it is a programming error.
+func (t *RouteTable) Add(route Route) {
+	if err := route.Policy.validate(); err != nil {
+		panic(fmt.Sprintf("router: %s %s: %v", route.Method, route.Pattern, err))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, route)
+	if t.mount != nil {
+		t.mount(route)
+	}
+}
+
+// Routes returns the routes declared so far.
+func (t *RouteTable) Routes() []Route {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Route(nil), t.routes...)
+}
+
+func (t *RouteTable) attach(mount func(Route)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mount = mount
+	for _, route := range t.routes {
+		mount(route)
+	}
+}
+
+func (p Policy) validate() error {
+	switch p.Auth {
+	case "", AUTH_PUBLIC, AUTH_USER, AUTH_CLIENT:
+	default:
+		return fmt.Errorf("unknown auth %q", p.Auth)
+	}
+	if len(p.Roles) > 0 && p.Auth != AUTH_USER {
+		return fmt.Errorf("roles need %s auth", AUTH_USER)
+	}
+	if p.Idempotent && p.Auth != AUTH_USER && p.Auth != AUTH_CLIENT {
+		return fmt.Errorf("idempotency needs %s or %s auth", AUTH_USER, AUTH_CLIENT)
+	}
+	if d := p.Deprecated; d != nil {
+		if d.Since.IsZero() {
+			return fmt.Errorf("deprecation needs a since date")
+		}
+		if !d.Sunset.IsZero() && d.Sunset.Before(d.Since) {
+			return fmt.Errorf("deprecation sunset is before its since date")
+		}
+	}
+	return nil
+}
+
+// mountRoutes mounts the routes of args.Routes on r, each behind the chain
+// its policy calls for.
+func mountRoutes(r chi.Router, args NewRouterArgs) {
+	if args.Routes == nil {
+		return
+	}
+	args.Routes.attach(func(route Route) {
+		r.With(policyChain(route, args)...).Method(route.Method, route.Pattern, route.Handler)
+	})
+}
+
+// deprecations returns the deprecations of the route's policy: the route's,
+// or one per deprecated field.
+func (route Route) deprecations() []*entity.Deprecation {
+	d := route.Policy.Deprecated
+	base := entity.Deprecation{Method: route.Method, Pattern: route.Pattern, Since: d.Since.UTC(), Link: d.Link}
+	if !d.Sunset.IsZero() {
+		sunset := d.Sunset.UTC()
+		base.Sunset = &sunset
+	}
+	if len(d.Fields) == 0 {
+		return []*entity.Deprecation{&base}
+	}
+	out := make([]*entity.Deprecation, len(d.Fields))
+	for i, field := range d.Fields {
+		fd := base
+		fd.Field = field
+		out[i] = &fd
+	}
+	return out
+}
+
+// policyChain returns the middleware of the route's policy, cheapest checks
+// first: the load shedding tier and body limit, then authentication, roles,
+// deprecation and idempotency.
+func policyChain(route Route, args NewRouterArgs) []func(http.Handler) http.Handler {
+	p := route.Policy
+	var chain []func(http.Handler) http.Handler
+	if p.Tier != "" {
+		chain = append(chain, args.LoadShedder.Group(p.Tier))
+	}
+	if p.MaxBodyBytes > 0 {
+		chain = append(chain, appMiddleware.MaxBody(p.MaxBodyBytes))
+	}
+	switch p.Auth {
+	case AUTH_USER:
+		chain = append(chain, protectedChain(args, appMiddleware.AUTH_GROUP_API)...)
+	case AUTH_CLIENT:
+		chain = append(chain, args.AuthenticateClient)
+		if args.Quota != nil {
+			chain = append(chain, args.Quota)
+		}
+	}
+	if len(p.Roles) > 0 {
+		chain = append(chain, appMiddleware.RequireRole(p.Roles...))
+	}
+	if p.Deprecated != nil && args.DeprecationUsage != nil {
+		chain = append(chain, appMiddleware.Deprecated(appMiddleware.DeprecatedArgs{
+			Deprecations: route.deprecations(),
+			Usage:        args.DeprecationUsage,
+		}))
+	}
+	if p.Idempotent {
+		chain = append(chain, args.Idempotency)
+	}
+	return chain
+}
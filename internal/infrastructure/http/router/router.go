@@ -2,30 +2,316 @@ package router
 
 import (
 	"net/http"
+	"net/netip"
+	"slices"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/admin"
 	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/auth"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/batch"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/billing"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/dashboard"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/debug"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/health"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/mail"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/me"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/oauth"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/saml"
+	"github.com/haidang666/go-app/internal/infrastructure/http/handlers/username"
+	appMiddleware "github.com/haidang666/go-app/internal/infrastructure/http/middleware"
+	"github.com/haidang666/go-app/pkg/drain"
+	"github.com/haidang666/go-app/pkg/http/response"
 )
 
 type NewRouterArgs struct {
-	AuthHandler *auth.AuthHandler
+	AuthHandler     *auth.AuthHandler
+	AdminHandler    *admin.AdminHandler
+	HealthHandler   *health.HealthHandler
+	MeHandler       *me.MeHandler
+	UsernameHandler *username.UsernameHandler
+	OAuthHandler    *oauth.OAuthHandler
+	SAMLHandler     *saml.SAMLHandler
+	BillingHandler  *billing.BillingHandler
+	MailHandler     *mail.MailHandler
+	// DebugHandler serves the outbox of the fake dependencies and, in
+	// development, email previews; it is mounted only when non-nil.
+	DebugHandler *debug.DebugHandler
+	// DashboardHandler serves the admin dashboard next to the admin API; it
+	// is mounted only when non-nil.
+	DashboardHandler *dashboard.DashboardHandler
+	Drainer          *drain.Drainer
+	LoadShedder      *appMiddleware.LoadShedder
+	CredentialGate   *appMiddleware.CredentialGate
+	Admission        *appMiddleware.AdmissionController
+	// AuthProtection is nil when adaptive protection is disabled.
+	AuthProtection *appMiddleware.AuthProtection
+	// ReadOnly rejects the write requests while the service is in
+	// maintenance.
+	ReadOnly       func(http.Handler) http.Handler
+	TrustedProxies []netip.Prefix
+	// RequestBudget is the deadline of each request's context; 0 leaves
+	// requests unbounded.
+	RequestBudget time.Duration
+	// Authenticate and RequireSession guard every route outside /auth.
+	// Authenticate holds the authentication of each route group,
+	// appMiddleware.AUTH_GROUP_API and AUTH_GROUP_ADMIN.
+	Authenticate   map[string]func(http.Handler) http.Handler
+	RequireSession func(http.Handler) http.Handler
+	// AuthenticateClient guards the endpoints for OAuth machine clients.
+	AuthenticateClient func(http.Handler) http.Handler
+	// AuthenticateDelegated accepts the access tokens issued to OpenID
+	// Connect relying parties on behalf of a user.
+	AuthenticateDelegated func(http.Handler) http.Handler
+	// Captcha guards bot-prone auth endpoints; a pass-through when disabled.
+	Captcha func(http.Handler) http.Handler
+	// SignUpCaptcha guards sign-up in place of Captcha.
+	SignUpCaptcha func(http.Handler) http.Handler
+	// AuditImpersonation records the requests made with impersonation
+	// tokens.
+	AuditImpersonation func(http.Handler) http.Handler
+	// AuditServiceAccounts records the write requests made with service
+	// account keys.
+	AuditServiceAccounts func(http.Handler) http.Handler
+	// CountryPolicy, when set, refuses API requests from the countries
+	// access is restricted in.
+	CountryPolicy func(http.Handler) http.Handler
+	// Quota, when set, enforces the request quotas of users and OAuth
+	// clients.
+	Quota func(http.Handler) http.Handler
+	// PlanGuard builds the middleware of plan-gated routes.
+	PlanGuard *appMiddleware.PlanGuard
+	// MeterUsage records the API calls of signed-in users for billing.
+	MeterUsage func(http.Handler) http.Handler
+	// RequireTerms, when set, blocks protected routes until the current terms
+	// of service are accepted.
+	RequireTerms func(http.Handler) http.Handler
+	// Idempotency guards the routes whose policy is Idempotent.
+	Idempotency func(http.Handler) http.Handler
+	// Routes are the routes declared with a Policy, mounted under /api/v1.
+	Routes *RouteTable
+	// DeprecationUsage records the use of the deprecated routes and fields
+	// their policy declares.
+	DeprecationUsage contract.DeprecationUsageRepository
+	BatchMaxRequests int
+	BatchConcurrency int
+	EnvelopeVersions []string
+	// ResponseEncodings are the response encodings of API versions, by
+	// version.
+	ResponseEncodings map[string]response.Encoding
+	// MetricsHandler serves /metrics for scraping; it is mounted only when
+	// non-nil.
+	MetricsHandler http.Handler
+	// AccessLog logs every request not excluded from the access log.
+	AccessLog func(http.Handler) http.Handler
+	// BodyLogger is mounted only when non-nil.
+	BodyLogger func(http.Handler) http.Handler
+	// Shadow mirrors sampled requests to a shadow deployment; it is mounted
+	// only when non-nil.
+	Shadow func(http.Handler) http.Handler
+	// FaultInjector injects chaos-testing faults; it is mounted only when
+	// non-nil.
+	FaultInjector func(http.Handler) http.Handler
+	// Rollouts splits the routes being soft-launched between their stable
+	// and candidate handlers; pass it to the RegisterRoutes of the handlers
+	// that have a candidate.
+	Rollouts *appMiddleware.Rollouts
+	// ContractValidator checks request bodies against their endpoint's
+	// schema; it is mounted only when non-nil.
+	ContractValidator func(http.Handler) http.Handler
+	// SeparateOps leaves the health, metrics, admin and dashboard routes to
+	// NewOpsRouter instead of serving them publicly.
+	SeparateOps bool
+}
+
+// Routers are the handlers of the public listener and of the internal ops
+// listener. Ops is nil when there is no ops listener.
+type Routers struct {
+	Public *chi.Mux
+	Ops    *chi.Mux
 }
 
 func NewRouter(args NewRouterArgs) *chi.Mux {
 	r := chi.NewRouter()
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(appMiddleware.Deadline(args.RequestBudget))
+	r.Use(appMiddleware.RequestID(args.TrustedProxies))
+	r.Use(appMiddleware.Trace)
+	r.Use(appMiddleware.Abandoned)
+	r.Use(args.Drainer.Middleware)
+	r.Use(args.Admission.Middleware)
+	r.Use(args.LoadShedder.Global)
+	r.Use(appMiddleware.RealIP(args.TrustedProxies))
+	r.Use(appMiddleware.Locale)
+	r.Use(args.AccessLog)
 	r.Use(middleware.Recoverer)
+	r.Use(args.ReadOnly)
+	if args.BodyLogger != nil {
+		r.Use(args.BodyLogger)
+	}
+	if args.Shadow != nil {
+		r.Use(args.Shadow)
+	}
+	if args.FaultInjector != nil {
+		r.Use(args.FaultInjector)
+	}
+	if args.ContractValidator != nil {
+		r.Use(args.ContractValidator)
+	}
 
-	r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.Write([]byte("ok"))
+	if !args.SeparateOps {
+		registerOpsRoutes(r, args)
+		registerDashboard(r, args)
+	}
+	authenticateClient := args.AuthenticateClient
+	if args.Quota != nil {
+		authenticateClient = func(next http.Handler) http.Handler {
+			return args.AuthenticateClient(args.Quota(next))
+		}
+	}
+	oauth.RegisterRoutes(r, args.OAuthHandler, authenticateClient, func(next http.Handler) http.Handler {
+		return args.AuthenticateDelegated(args.RequireSession(next))
 	})
+	saml.RegisterRoutes(r, args.SAMLHandler)
+	billing.RegisterRoutes(r, args.BillingHandler)
+	mail.RegisterRoutes(r, args.MailHandler)
 
 	r.Route("/api/v1", func(ur chi.Router) {
-		auth.RegisterRoutes(ur, args.AuthHandler)
+		ur.Use(args.LoadShedder.Group("api"))
+		ur.Use(response.UseEnvelope(slices.Contains(args.EnvelopeVersions, "v1")))
+		ur.Use(response.UseEncoding(args.ResponseEncodings["v1"]))
+		if args.CountryPolicy != nil {
+			ur.Use(args.CountryPolicy)
+		}
+
+		auth.RegisterRoutes(ur, args.AuthHandler, args.Captcha, args.SignUpCaptcha, args.CredentialGate, args.AuthProtection)
+		username.RegisterRoutes(ur, args.UsernameHandler)
+		mountRoutes(ur, args)
+
+		ur.Group(func(pr chi.Router) {
+			useProtected(pr, args, appMiddleware.AUTH_GROUP_API)
+
+			me.RegisterRoutes(pr, args.MeHandler, args.PlanGuard.RequireFeature(entity.FEATURE_USAGE_REPORT))
+			oauth.RegisterAPIRoutes(pr, args.OAuthHandler)
+			billing.RegisterAPIRoutes(pr, args.BillingHandler)
+			batch.RegisterRoutes(pr, batch.NewBatchHandler(batch.NewBatchHandlerArgs{
+				Router:      r,
+				MaxRequests: args.BatchMaxRequests,
+				Concurrency: args.BatchConcurrency,
+			}))
+		})
+		if !args.SeparateOps {
+			ur.Group(func(pr chi.Router) {
+				useProtected(pr, args, appMiddleware.AUTH_GROUP_ADMIN)
+				admin.RegisterRoutes(pr, args.AdminHandler, appMiddleware.RequireAdmin, args.LoadShedder.Group("admin"))
+			})
+		}
 	})
 
 	return r
 }
+
+// NewOpsRouter serves the operational endpoints on the internal listener:
+// health, metrics, pprof, the admin API and dashboard. It skips admission control and
+// load shedding so operators can still reach an overloaded instance.
+func NewOpsRouter(args NewRouterArgs) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(appMiddleware.Deadline(args.RequestBudget))
+	r.Use(appMiddleware.RequestID(args.TrustedProxies))
+	r.Use(appMiddleware.Trace)
+	r.Use(appMiddleware.Abandoned)
+	r.Use(args.AccessLog)
+	r.Use(middleware.Recoverer)
+	r.Use(args.ReadOnly)
+
+	registerOpsRoutes(r, args)
+	health.RegisterOpsRoutes(r, args.HealthHandler)
+	registerDashboard(r, args)
+	r.Mount("/debug", middleware.Profiler())
+
+	r.Route("/api/v1", func(ur chi.Router) {
+		ur.Use(response.UseEnvelope(slices.Contains(args.EnvelopeVersions, "v1")))
+		ur.Use(response.UseEncoding(args.ResponseEncodings["v1"]))
+		ur.Group(func(pr chi.Router) {
+			useProtected(pr, args, appMiddleware.AUTH_GROUP_ADMIN)
+			admin.RegisterRoutes(pr, args.AdminHandler, appMiddleware.RequireAdmin)
+		})
+	})
+
+	return r
+}
+
+func registerOpsRoutes(r chi.Router, args NewRouterArgs) {
+	health.RegisterRoutes(r, args.HealthHandler)
+	if args.MetricsHandler != nil {
+		r.Handle("/metrics", args.MetricsHandler)
+	}
+	if args.DebugHandler != nil {
+		debug.RegisterRoutes(r, args.DebugHandler)
+	}
+}
+
+func registerDashboard(r chi.Router, args NewRouterArgs) {
+	if args.DashboardHandler != nil {
+		dashboard.RegisterRoutes(r, args.DashboardHandler)
+	}
+}
+
+// useProtected installs the middleware shared by every route that needs a
+// signed-in user, authenticating them as group does.
+func useProtected(pr chi.Router, args NewRouterArgs, group string) {
+	pr.Use(protectedChain(args, group)...)
+}
+
+// protectedChain is the middleware of every route that needs a signed-in
+// user, authenticating them as group does.
+func protectedChain(args NewRouterArgs, group string) []func(http.Handler) http.Handler {
+	chain := []func(http.Handler) http.Handler{args.Authenticate[group], args.RequireSession, args.AuditImpersonation, args.AuditServiceAccounts}
+	if args.Quota != nil {
+		chain = append(chain, args.Quota)
+	}
+	chain = append(chain, args.MeterUsage,
+		// Guests may only upgrade or sign out until they have an account.
+		appMiddleware.RestrictGuests("/api/v1/me/upgrade", "/api/v1/me/sessions"),
+	)
+	// Impersonators can use the account but not take it over, grant
+	// it to third parties or reach admin endpoints as the user.
+	chain = append(chain, appMiddleware.RestrictImpersonation(
+		"/api/v1/admin",
+		"DELETE /api/v1/me",
+		"/api/v1/me/sessions",
+		"/api/v1/me/tokens",
+		"/api/v1/me/email",
+		"/api/v1/me/phone",
+		"/api/v1/me/identities",
+		"/api/v1/me/upgrade",
+		"/api/v1/me/terms",
+		"/api/v1/oauth/authorize",
+		"/api/v1/billing",
+	))
+	// Personal access tokens cannot mint more of themselves, change how
+	// the user signs in or delete the account.
+	chain = append(chain, appMiddleware.RestrictPersonalTokens(
+		"DELETE /api/v1/me",
+		"/api/v1/me/sessions",
+		"/api/v1/me/tokens",
+		"/api/v1/me/email",
+		"/api/v1/me/phone",
+		"/api/v1/me/identities",
+		"/api/v1/me/upgrade",
+		"/api/v1/oauth/authorize",
+	))
+	// Service accounts have no user account of their own to manage, pay
+	// for or grant to third parties.
+	chain = append(chain, appMiddleware.RestrictServiceAccounts(
+		"/api/v1/me",
+		"/api/v1/oauth/authorize",
+		"/api/v1/billing",
+	))
+	if args.RequireTerms != nil {
+		chain = append(chain, args.RequireTerms)
+	}
+	return chain
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// AdminTask is a task registered with AdminTaskRegistry. Validate, when
+// set, checks the params of a trigger before the job is scheduled.
+type AdminTask struct {
+	Description string
+	Params      string
+	Validate    func(params json.RawMessage) error
+}
+
+// AdminTaskRegistry lists the tasks admins may trigger; the modules register
+// them, with their job handlers, at startup.
+type AdminTaskRegistry struct {
+	mu    sync.RWMutex
+	tasks map[string]AdminTask
+}
+
+var _ contract.AdminTaskRegistry = (*AdminTaskRegistry)(nil)
+
+func NewAdminTaskRegistry() *AdminTaskRegistry {
+	return &AdminTaskRegistry{tasks: make(map[string]AdminTask)}
+}
+
+func (r *AdminTaskRegistry) Add(name string, task AdminTask) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[name] = task
+}
+
+// AdminTasks returns the tasks by name.
+func (r *AdminTaskRegistry) AdminTasks() []dto.AdminTask {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]dto.AdminTask, 0, len(r.tasks))
+	for name, t := range r.tasks {
+		out = append(out, dto.AdminTask{Name: name, Description: t.Description, Params: t.Params})
+	}
+	slices.SortFunc(out, func(a, b dto.AdminTask) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+func (r *AdminTaskRegistry) ValidateAdminTask(name string, params json.RawMessage) error {
+	r.mu.RLock()
+	t, ok := r.tasks[name]
+	r.mu.RUnlock()
+	if !ok {
+		return errs.ErrAdminTaskNotFound
+	}
+	if t.Validate == nil {
+		return nil
+	}
+	if err := t.Validate(params); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrInvalidAdminTaskParams, err)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/retry"
+	"github.com/haidang666/go-app/pkg/safe"
+)
+
+var (
+	jobRunsTotal = metrics.NewCounter("scheduled_job_runs_total",
+		"Runs of scheduled jobs by kind and outcome (done, retry, failed, quarantined).", "kind", "outcome")
+	jobRunDuration = metrics.NewHistogram("scheduled_job_run_duration_seconds",
+		"Duration of the scheduled job runs by kind.", metrics.DefaultBuckets, "kind")
+	jobLag = metrics.NewHistogram("scheduled_job_lag_seconds",
+		"How long after being due scheduled jobs started running, by kind.",
+		[]float64{1, 5, 15, 30, 60, 300, 900, 3600}, "kind")
+)
+
+type SchedulerArgs struct {
+	Repo contract.ScheduledJobRepository
+	// Interval is how often the worker polls for due jobs; it bounds how
+	// late a job runs.
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts is the number of runs before a job is marked failed.
+	MaxAttempts int
+	// RetryBase is the delay before the second run; it doubles with each
+	// run after that, up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// Clock decides when jobs are due and paces the polling; nil means the
+	// wall clock.
+	Clock clock.Clock
+}
+
+// Scheduler stores deferred jobs and runs them once due. Any instance
+// schedules and cancels; Run is meant to run on the leader only, so jobs
+// need no claiming. Jobs that run out of attempts stay failed, with their
+// error history, until an operator requeues or discards them.
+type Scheduler struct {
+	repo        contract.ScheduledJobRepository
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	retryBase   time.Duration
+	retryMax    time.Duration
+	wake        chan struct{}
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	handlers map[string]contract.JobHandler
+}
+
+var _ contract.JobScheduler = (*Scheduler)(nil)
+
+func NewScheduler(args SchedulerArgs) *Scheduler {
+	return &Scheduler{
+		repo:        args.Repo,
+		interval:    args.Interval,
+		batchSize:   args.BatchSize,
+		maxAttempts: args.MaxAttempts,
+		retryBase:   args.RetryBase,
+		retryMax:    args.RetryMax,
+		wake:        make(chan struct{}, 1),
+		clock:       clock.OrReal(args.Clock),
+		handlers:    make(map[string]contract.JobHandler),
+	}
+}
+
+// Handle registers the handler of the jobs of kind. Kinds are persisted
+// with the jobs, so renaming one strands the jobs already scheduled.
+func (s *Scheduler) Handle(kind string, handler contract.JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+func (s *Scheduler) Schedule(ctx context.Context, kind, key string, runAt time.Time, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s job payload: %w", kind, err)
+	}
+	now := s.clock.Now().UTC()
+	_, err = s.repo.Schedule(ctx, &entity.ScheduledJob{
+		Kind:      kind,
+		Key:       key,
+		Payload:   raw,
+		Status:    entity.SCHEDULED_JOB_PENDING,
+		RunAt:     runAt.UTC(),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if !runAt.After(now) {
+		s.Wake()
+	}
+	return nil
+}
+
+func (s *Scheduler) Cancel(ctx context.Context, key string) error {
+	_, err := s.repo.CancelByKey(ctx, key, s.clock.Now().UTC())
+	return err
+}
+
+// Wake makes the worker poll now. It never blocks.
+func (s *Scheduler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs due jobs until ctx is canceled; it is meant to run as a leader
+// task.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-s.wake:
+		}
+	}
+}
+
+// drain runs batches of due jobs until none is left.
+func (s *Scheduler) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := s.repo.Due(ctx, s.clock.Now().UTC(), s.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.L().Errorw("list due jobs", "error", err)
+			}
+			return
+		}
+		for _, j := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := s.run(ctx, j); err != nil {
+				logger.L().Errorw("update scheduled job", "job_id", j.ID, "error", err)
+				return
+			}
+		}
+		if len(due) < s.batchSize {
+			return
+		}
+	}
+}
+
+// run runs j and records the outcome. A failed run is retried with backoff
+// rather than failing the batch, unless its error is marked with
+// retry.Permanent. A run that panicked is not retried: the job is
+// quarantined with the dead letters for an operator to look at.
+func (s *Scheduler) run(ctx context.Context, j *entity.ScheduledJob) error {
+	s.mu.RLock()
+	handler, ok := s.handlers[j.Kind]
+	s.mu.RUnlock()
+
+	jobLag.Observe(s.clock.Now().Sub(j.RunAt).Seconds(), j.Kind)
+
+	var runErr error
+	if ok {
+		start := time.Now()
+		runErr = s.call(ctx, handler, j)
+		jobRunDuration.Observe(time.Since(start).Seconds(), j.Kind)
+	} else {
+		runErr = fmt.Errorf("no handler for job kind %q", j.Kind)
+	}
+
+	now := s.clock.Now().UTC()
+	j.Attempts++
+	j.UpdatedAt = &now
+	panicked := safe.IsPanic(runErr)
+	switch {
+	case runErr == nil:
+		j.Status = entity.SCHEDULED_JOB_DONE
+		j.LastError = ""
+		jobRunsTotal.Inc(j.Kind, "done")
+	case panicked:
+		j.Status = entity.SCHEDULED_JOB_FAILED
+		j.RecordError(runErr.Error(), true, now)
+		jobRunsTotal.Inc(j.Kind, "quarantined")
+		logger.L().Warnw("scheduled job quarantined after a panic",
+			"job_id", j.ID, "kind", j.Kind, "key", j.Key, "attempts", j.Attempts, "error", runErr)
+	case !ok || j.Attempts >= s.maxAttempts || retry.IsPermanent(runErr):
+		j.Status = entity.SCHEDULED_JOB_FAILED
+		j.RecordError(runErr.Error(), false, now)
+		jobRunsTotal.Inc(j.Kind, "failed")
+		logger.L().Warnw("scheduled job failed for good",
+			"job_id", j.ID, "kind", j.Kind, "key", j.Key, "attempts", j.Attempts, "error", runErr)
+	default:
+		j.RunAt = now.Add(s.retryDelay(j.Attempts))
+		j.RecordError(runErr.Error(), false, now)
+		jobRunsTotal.Inc(j.Kind, "retry")
+	}
+	_, err := s.repo.Update(ctx, j)
+	return err
+}
+
+// call runs handler, turning a panic into a *safe.PanicError so that one
+// bad job does not stop the worker.
+func (s *Scheduler) call(ctx context.Context, handler contract.JobHandler, j *entity.ScheduledJob) error {
+	return safe.Call(ctx, "jobs."+j.Kind, func() error {
+		return handler(ctx, j.Payload)
+	}, "job_id", j.ID, "kind", j.Kind)
+}
+
+// retryDelay is the wait after the given number of failed runs.
+func (s *Scheduler) retryDelay(attempts int) time.Duration {
+	delay := s.retryBase
+	for i := 1; i < attempts && delay < s.retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, s.retryMax)
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	infrastructure "github.com/haidang666/go-app/internal/infrastructure/repository"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+func newTestScheduler(clk clock.Clock) (*Scheduler, *infrastructure.ScheduledJobRepository) {
+	repo := infrastructure.NewScheduledJobRepository()
+	return NewScheduler(SchedulerArgs{
+		Repo:        repo,
+		Interval:    30 * time.Second,
+		BatchSize:   10,
+		MaxAttempts: 3,
+		RetryBase:   10 * time.Second,
+		RetryMax:    time.Minute,
+		Clock:       clk,
+	}), repo
+}
+
+func TestSchedulerRunsJobsWhenDue(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s, repo := newTestScheduler(clk)
+	runs := 0
+	s.Handle("test", func(context.Context, json.RawMessage) error {
+		runs++
+		if runs == 1 {
+			return errors.New("flaky")
+		}
+		return nil
+	})
+	if err := s.Schedule(ctx, "test", "key", clk.Now().Add(time.Minute), nil); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	for _, step := range []struct {
+		advance time.Duration
+		runs    int
+	}{
+		{time.Minute - time.Second, 0},
+		{time.Second, 1},
+		// The failed run is retried RetryBase later.
+		{9 * time.Second, 1},
+		{time.Second, 2},
+		{time.Hour, 2},
+	} {
+		clk.Advance(step.advance)
+		s.drain(ctx)
+		if runs != step.runs {
+			t.Fatalf("at %v: %d runs, want %d", clk.Now().Format(time.TimeOnly), runs, step.runs)
+		}
+	}
+	jobs, _, err := repo.List(ctx, entity.SCHEDULED_JOB_DONE, "test", 10, 0)
+	if err != nil || len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Errorf("done jobs = %v, %v; want one after 2 attempts", jobs, err)
+	}
+}
+
+func TestSchedulerPollsOnTheClock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s, _ := newTestScheduler(clk)
+	ran := make(chan string, 2)
+	s.Handle("test", func(_ context.Context, payload json.RawMessage) error {
+		var key string
+		json.Unmarshal(payload, &key)
+		ran <- key
+		return nil
+	})
+	for _, job := range []struct {
+		key string
+		in  time.Duration
+	}{{"now", 0}, {"later", time.Minute}} {
+		if err := s.Schedule(ctx, "test", job.key, clk.Now().Add(job.in), job.key); err != nil {
+			t.Fatalf("schedule: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	wait := func(want string) {
+		t.Helper()
+		select {
+		case key := <-ran:
+			if key != want {
+				t.Fatalf("ran %q, want %q", key, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%q did not run", want)
+		}
+	}
+
+	// The due job runs on start, by which time the poll ticker exists.
+	wait("now")
+	clk.Advance(time.Minute)
+	wait("later")
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// LogMailer writes outgoing email to the application log instead of
+// delivering it. It stands in until an SMTP or API-based transport is wired.
+type LogMailer struct {
+	from string
+}
+
+var _ contract.MailTransport = (*LogMailer)(nil)
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg dto.EmailMessage) error {
+	logger.L().Infow("email sent",
+		"id", msg.ID,
+		"from", m.from,
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
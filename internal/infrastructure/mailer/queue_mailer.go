@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var suppressedTotal = metrics.NewCounter("mail_suppressed_total",
+	"Messages not queued for delivery because their recipient is suppressed, by suppression reason.", "reason")
+
+// QueueMailer renders outgoing email and stores it in the mail queue for the
+// QueueWorker to deliver, so a provider outage delays mail instead of
+// failing requests. Mail to a suppressed address is stored as suppressed
+// rather than queued, and Send succeeds: the caller did its part.
+type QueueMailer struct {
+	emailQueueRepo  contract.EmailQueueRepository
+	suppressionRepo contract.EmailSuppressionRepository
+	templates       *TemplateRegistry
+	worker          *QueueWorker
+}
+
+var _ contract.Mailer = (*QueueMailer)(nil)
+
+func NewQueueMailer(
+	emailQueueRepo contract.EmailQueueRepository,
+	suppressionRepo contract.EmailSuppressionRepository,
+	templates *TemplateRegistry,
+	worker *QueueWorker,
+) *QueueMailer {
+	return &QueueMailer{emailQueueRepo: emailQueueRepo, suppressionRepo: suppressionRepo, templates: templates, worker: worker}
+}
+
+func (m *QueueMailer) Send(ctx context.Context, email dto.Email) error {
+	if len(email.Items) > 0 {
+		items := make([]map[string]any, 0, len(email.Items))
+		for _, item := range email.Items {
+			subject, content, err := m.templates.RenderContent(item.Template, ctxutil.Locale(ctx), item.Data)
+			if err != nil {
+				return fmt.Errorf("render %s email: %w", item.Template, err)
+			}
+			items = append(items, map[string]any{"Subject": subject, "Content": content})
+		}
+		email.Data = map[string]any{"Items": items}
+	}
+
+	msg, err := m.templates.Render(email.Template, ctxutil.Locale(ctx), email.Data)
+	if err != nil {
+		return fmt.Errorf("render %s email: %w", email.Template, err)
+	}
+
+	suppression, err := m.suppressionRepo.Get(ctx, entity.NormalizeSuppressedEmail(email.To))
+	if err != nil && !errors.Is(err, errs.ErrEmailSuppressionNotFound) {
+		return err
+	}
+
+	now := time.Now().UTC()
+	e := &entity.OutboundEmail{
+		To:            email.To,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		Status:        entity.OUTBOUND_EMAIL_QUEUED,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if suppression != nil {
+		e.Status = entity.OUTBOUND_EMAIL_SUPPRESSED
+		e.LastError = "recipient is suppressed: " + suppression.Reason
+	}
+	if _, err := m.emailQueueRepo.Create(ctx, e); err != nil {
+		return err
+	}
+	if suppression != nil {
+		suppressedTotal.Inc(suppression.Reason)
+		ctxutil.Logger(ctx).Infow("email to suppressed address not sent", "template", email.Template, "reason", suppression.Reason)
+		return nil
+	}
+	m.worker.Wake()
+	return nil
+}
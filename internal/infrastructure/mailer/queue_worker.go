@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	mailUseCase "github.com/haidang666/go-app/internal/domain/use_case/mail"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// QueueWorker delivers the mail queue. It polls every interval and right
+// after Wake, each time sending batches until nothing is due.
+type QueueWorker struct {
+	deliver  *mailUseCase.DeliverQueuedEmailsUseCase
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewQueueWorker(deliver *mailUseCase.DeliverQueuedEmailsUseCase, interval time.Duration) *QueueWorker {
+	return &QueueWorker{deliver: deliver, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Wake makes the worker poll now. It never blocks.
+func (w *QueueWorker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers until ctx is canceled; it is meant to run as a leader task.
+func (w *QueueWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+func (w *QueueWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := w.deliver.Execute(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.L().Errorw("deliver queued email", "error", err)
+			}
+			return
+		}
+		if n == 0 {
+			return
+		}
+	}
+}
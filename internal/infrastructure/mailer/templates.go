@@ -0,0 +1,273 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// DEFAULT_LOCALE is the locale every template exists in; other locales fall
+// back to it template by template.
+const DEFAULT_LOCALE = "en"
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// The templates directory holds:
+//
+//	layout.tmpl              the "layout" template wrapping every email
+//	partials/*.tmpl          templates shared by every locale
+//	<locale>/partials/*.tmpl partials overriding the shared ones
+//	<locale>/<name>.tmpl     email <name>, defining "subject" and "content"
+//	samples/<name>.json      sample data for previews
+//
+//go:embed templates
+var embedded embed.FS
+
+// TemplateRegistry renders the email templates. They are parsed once, from
+// the embedded files overlaid with an optional directory of the same layout,
+// so a deployment can restyle or translate emails without a rebuild.
+type TemplateRegistry struct {
+	// sets maps a locale to its parsed templates by name.
+	sets    map[string]map[string]*template.Template
+	samples map[string]map[string]any
+}
+
+// NewTemplateRegistry parses the templates, overlaying overrideDir when it
+// is not empty. Every template must have a DEFAULT_LOCALE variant.
+func NewTemplateRegistry(overrideDir string) (*TemplateRegistry, error) {
+	root, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if overrideDir != "" {
+		if _, err := os.Stat(overrideDir); err != nil {
+			return nil, fmt.Errorf("email template dir: %w", err)
+		}
+		root = overlayFS{top: os.DirFS(overrideDir), base: root}
+	}
+
+	shared, err := template.New("").Option("missingkey=error").ParseFS(root, "layout.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if err := parseGlob(shared, root, "partials/*.tmpl"); err != nil {
+		return nil, err
+	}
+
+	reg := &TemplateRegistry{
+		sets:    make(map[string]map[string]*template.Template),
+		samples: make(map[string]map[string]any),
+	}
+	entries, err := fs.ReadDir(root, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "partials" || entry.Name() == "samples" {
+			continue
+		}
+		if err := reg.loadLocale(root, shared, entry.Name()); err != nil {
+			return nil, err
+		}
+	}
+	if err := reg.check(); err != nil {
+		return nil, err
+	}
+	if err := reg.loadSamples(root); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+func (r *TemplateRegistry) loadLocale(root fs.FS, shared *template.Template, locale string) error {
+	base, err := shared.Clone()
+	if err != nil {
+		return err
+	}
+	if err := parseGlob(base, root, locale+"/partials/*.tmpl"); err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(root, locale+"/*.tmpl")
+	if err != nil {
+		return err
+	}
+	set := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		t, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if t, err = t.ParseFS(root, file); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+		for _, required := range []string{"subject", "content"} {
+			if t.Lookup(required) == nil {
+				return fmt.Errorf("email template %s does not define %q", file, required)
+			}
+		}
+		set[name] = t
+	}
+	r.sets[locale] = set
+	return nil
+}
+
+// check makes sure falling back to DEFAULT_LOCALE always finds a template.
+func (r *TemplateRegistry) check() error {
+	defaults, ok := r.sets[DEFAULT_LOCALE]
+	if !ok {
+		return fmt.Errorf("no %s email templates", DEFAULT_LOCALE)
+	}
+	for locale, set := range r.sets {
+		for name := range set {
+			if _, ok := defaults[name]; !ok {
+				return fmt.Errorf("email template %s/%s has no %s variant", locale, name, DEFAULT_LOCALE)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *TemplateRegistry) loadSamples(root fs.FS) error {
+	files, err := fs.Glob(root, "samples/*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		raw, err := fs.ReadFile(root, file)
+		if err != nil {
+			return err
+		}
+		var data map[string]any
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return fmt.Errorf("email template sample %s: %w", file, err)
+		}
+		r.samples[strings.TrimSuffix(path.Base(file), ".json")] = data
+	}
+	return nil
+}
+
+// Render fills the named template in locale, or in DEFAULT_LOCALE when the
+// locale has no variant of it. The message has no recipient yet.
+func (r *TemplateRegistry) Render(name, locale string, data map[string]any) (dto.EmailMessage, error) {
+	t, ok := r.sets[locale][name]
+	if !ok {
+		if t, ok = r.sets[DEFAULT_LOCALE][name]; !ok {
+			return dto.EmailMessage{}, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+		}
+	}
+
+	var subject, body bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return dto.EmailMessage{}, err
+	}
+	if err := t.ExecuteTemplate(&body, "layout", data); err != nil {
+		return dto.EmailMessage{}, err
+	}
+	return dto.EmailMessage{Subject: strings.TrimSpace(subject.String()), Body: body.String()}, nil
+}
+
+// RenderContent fills the subject and content of the named template without
+// the layout, for emails shown inside another, such as a digest.
+func (r *TemplateRegistry) RenderContent(name, locale string, data map[string]any) (subject, content string, err error) {
+	t, ok := r.sets[locale][name]
+	if !ok {
+		if t, ok = r.sets[DEFAULT_LOCALE][name]; !ok {
+			return "", "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+		}
+	}
+
+	var s, c bytes.Buffer
+	if err := t.ExecuteTemplate(&s, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := t.ExecuteTemplate(&c, "content", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(s.String()), strings.TrimSpace(c.String()), nil
+}
+
+// Names lists the templates, sorted.
+func (r *TemplateRegistry) Names() []string {
+	names := make([]string, 0, len(r.sets[DEFAULT_LOCALE]))
+	for name := range r.sets[DEFAULT_LOCALE] {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Sample returns the preview data of the named template.
+func (r *TemplateRegistry) Sample(name string) map[string]any {
+	return r.samples[name]
+}
+
+// WarmUp renders every template that has sample data in every locale, so
+// the first email of each kind does not pay for the first execution and a
+// template that fails to render shows at startup.
+func (r *TemplateRegistry) WarmUp() error {
+	for locale, set := range r.sets {
+		for name := range set {
+			sample, ok := r.samples[name]
+			if !ok {
+				continue
+			}
+			if _, err := r.Render(name, locale, sample); err != nil {
+				return fmt.Errorf("email template %s/%s: %w", locale, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func parseGlob(t *template.Template, root fs.FS, pattern string) error {
+	files, err := fs.Glob(root, pattern)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	_, err = t.ParseFS(root, files...)
+	return err
+}
+
+// overlayFS serves files from top when they exist there and from base
+// otherwise; directory listings merge both.
+type overlayFS struct {
+	top, base fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.top.Open(name); err == nil {
+		return f, nil
+	}
+	return o.base.Open(name)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, topErr := fs.ReadDir(o.top, name)
+	base, baseErr := fs.ReadDir(o.base, name)
+	if topErr != nil && baseErr != nil {
+		return nil, baseErr
+	}
+	seen := make(map[string]bool, len(top))
+	for _, e := range top {
+		seen[e.Name()] = true
+	}
+	for _, e := range base {
+		if !seen[e.Name()] {
+			top = append(top, e)
+		}
+	}
+	slices.SortFunc(top, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return top, nil
+}
@@ -0,0 +1,52 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	usageUseCase "github.com/haidang666/go-app/internal/domain/use_case/usage"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/dedupe"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// BusMeter publishes usage events on the event bus, where the aggregation
+// consumes them asynchronously.
+type BusMeter struct {
+	bus *eventbus.Bus
+}
+
+var _ contract.UsageMeter = (*BusMeter)(nil)
+
+func NewBusMeter(bus *eventbus.Bus) *BusMeter {
+	return &BusMeter{bus: bus}
+}
+
+func (m *BusMeter) Record(ctx context.Context, e dto.UsageEvent) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	if err := m.bus.Publish(ctx, dto.USAGE_EVENT_TOPIC, e); err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "metering.drop", 100).Warnw("drop usage event",
+			"user_id", e.UserID, "metric", e.Metric, "quantity", e.Quantity, "error", err)
+	}
+}
+
+// SubscribeAggregation feeds the usage events published on bus into the
+// per-period aggregation. Counts must not be added twice, so repeats of an
+// event are dropped.
+func SubscribeAggregation(bus *eventbus.Bus, aggregate *usageUseCase.AggregateUsageUseCase, deduper *dedupe.Deduper) {
+	bus.Subscribe(dto.USAGE_EVENT_TOPIC, deduper.Handler("usage", func(ctx context.Context, e eventbus.Event) {
+		usage, ok := e.Payload.(dto.UsageEvent)
+		if !ok {
+			return
+		}
+		if err := aggregate.Execute(ctx, usage); err != nil {
+			logger.Sampled("metering.aggregate", 100).Errorw("aggregate usage event",
+				"user_id", usage.UserID, "metric", usage.Metric, "error", err)
+		}
+	}))
+}
@@ -0,0 +1,114 @@
+// Package realtime pushes updates to the clients connected to this
+// instance.
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// maxWatchersPerUser bounds the open streams of one user, which are not
+// covered by the request concurrency limits.
+const maxWatchersPerUser = 10
+
+var ErrTooManyWatchers = errors.New("too many open notification streams")
+
+var badgeWatchers = metrics.NewGauge("notification_badge_watchers",
+	"Clients watching their unread notification count.")
+
+// BadgeHub publishes unread counts on the event bus and hands them to the
+// clients watching them, such as open notification streams.
+type BadgeHub struct {
+	bus *eventbus.Bus
+
+	mu       sync.Mutex
+	watchers map[uuid.UUID]map[*badgeWatcher]struct{}
+}
+
+type badgeWatcher struct {
+	// ch holds the latest count not yet taken; older ones are replaced.
+	ch chan int
+	// countedAt is when the last count handed over was taken. Bus workers
+	// may deliver counts out of order, and older ones are dropped.
+	countedAt time.Time
+}
+
+var _ contract.BadgePublisher = (*BadgeHub)(nil)
+
+func NewBadgeHub(bus *eventbus.Bus) *BadgeHub {
+	h := &BadgeHub{bus: bus, watchers: make(map[uuid.UUID]map[*badgeWatcher]struct{})}
+	bus.Subscribe(dto.UNREAD_COUNT_TOPIC, h.deliver)
+	return h
+}
+
+func (h *BadgeHub) PublishUnread(ctx context.Context, userID uuid.UUID, unread int) {
+	e := dto.UnreadCountEvent{UserID: userID, Unread: unread, CountedAt: time.Now()}
+	if err := h.bus.Publish(ctx, dto.UNREAD_COUNT_TOPIC, e); err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "realtime.drop", 100).Warnw("drop unread count",
+			"user_id", userID, "error", err)
+	}
+}
+
+// Watch returns a channel receiving the user's unread count whenever it
+// changes from now on, and a func that stops the watch. A slow reader only
+// sees the latest count.
+func (h *BadgeHub) Watch(userID uuid.UUID) (<-chan int, func(), error) {
+	w := &badgeWatcher{ch: make(chan int, 1), countedAt: time.Now()}
+
+	h.mu.Lock()
+	if len(h.watchers[userID]) >= maxWatchersPerUser {
+		h.mu.Unlock()
+		return nil, nil, ErrTooManyWatchers
+	}
+	if h.watchers[userID] == nil {
+		h.watchers[userID] = make(map[*badgeWatcher]struct{})
+	}
+	h.watchers[userID][w] = struct{}{}
+	h.mu.Unlock()
+	badgeWatchers.Inc()
+
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.watchers[userID], w)
+			if len(h.watchers[userID]) == 0 {
+				delete(h.watchers, userID)
+			}
+			h.mu.Unlock()
+			badgeWatchers.Dec()
+		})
+	}, nil
+}
+
+func (h *BadgeHub) deliver(_ context.Context, e eventbus.Event) {
+	count, ok := e.Payload.(dto.UnreadCountEvent)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for w := range h.watchers[count.UserID] {
+		if count.CountedAt.Before(w.countedAt) {
+			continue
+		}
+		w.countedAt = count.CountedAt
+		select {
+		case <-w.ch:
+		default:
+		}
+		w.ch <- count.Unread
+	}
+}
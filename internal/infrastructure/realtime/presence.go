@@ -0,0 +1,120 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+// presenceExpireBatch is how many expired users one store call reports.
+const presenceExpireBatch = 500
+
+var presenceChangesTotal = metrics.NewCounter("presence_changes_total",
+	"Users coming online or going offline, by state (online, offline) and cause (connect, heartbeat, disconnect, expired).", "state", "cause")
+
+// PresenceTracker marks users online while they hold a live connection,
+// such as an open notification stream, and publishes a
+// PresenceChangedEvent on the event bus when that changes. Connections
+// send heartbeats more often than TTL; one that stops, e.g. because its
+// instance died, expires after TTL and Expire reports its user offline.
+// Presence is best effort: store failures are logged and the connection
+// carries on.
+type PresenceTracker struct {
+	store contract.PresenceStore
+	bus   *eventbus.Bus
+	ttl   time.Duration
+}
+
+func NewPresenceTracker(store contract.PresenceStore, bus *eventbus.Bus, ttl time.Duration) *PresenceTracker {
+	return &PresenceTracker{store: store, bus: bus, ttl: ttl}
+}
+
+// PresenceConn is one live connection of a user.
+type PresenceConn struct {
+	tracker *PresenceTracker
+	userID  uuid.UUID
+	id      string
+}
+
+// Connect marks a new connection of the user live for one TTL.
+func (t *PresenceTracker) Connect(ctx context.Context, userID uuid.UUID) *PresenceConn {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	c := &PresenceConn{tracker: t, userID: userID, id: hex.EncodeToString(buf)}
+	c.beat(ctx, "connect")
+	return c
+}
+
+// Beat keeps the connection live for another TTL.
+func (c *PresenceConn) Beat(ctx context.Context) {
+	c.beat(ctx, "heartbeat")
+}
+
+func (c *PresenceConn) beat(ctx context.Context, cause string) {
+	t := c.tracker
+	now := time.Now().UTC()
+	cameOnline, err := t.store.Beat(ctx, c.userID, c.id, now, now.Add(t.ttl))
+	if err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "realtime.presence", 100).Warnw("beat presence", "user_id", c.userID, "error", err)
+		return
+	}
+	if cameOnline {
+		t.publish(ctx, c.userID, true, now, cause)
+	}
+}
+
+// Close drops the connection. It runs after the request is done, so it is
+// not canceled with it.
+func (c *PresenceConn) Close(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	t := c.tracker
+	now := time.Now().UTC()
+	wentOffline, err := t.store.Leave(ctx, c.userID, c.id, now)
+	if err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "realtime.presence", 100).Warnw("leave presence", "user_id", c.userID, "error", err)
+		return
+	}
+	if wentOffline {
+		t.publish(ctx, c.userID, false, now, "disconnect")
+	}
+}
+
+// Expire reports offline the users whose connections all expired; it is
+// meant to run on the leader, more often than TTL.
+func (t *PresenceTracker) Expire(ctx context.Context) error {
+	for {
+		now := time.Now().UTC()
+		users, err := t.store.Expire(ctx, now, presenceExpireBatch)
+		if err != nil {
+			return err
+		}
+		for _, userID := range users {
+			t.publish(ctx, userID, false, now, "expired")
+		}
+		if len(users) < presenceExpireBatch {
+			return nil
+		}
+	}
+}
+
+func (t *PresenceTracker) publish(ctx context.Context, userID uuid.UUID, online bool, at time.Time, cause string) {
+	state := "offline"
+	if online {
+		state = "online"
+	}
+	presenceChangesTotal.Inc(state, cause)
+	e := dto.PresenceChangedEvent{UserID: userID, Online: online, At: at}
+	if err := t.bus.Publish(ctx, dto.PRESENCE_CHANGED_TOPIC, e); err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "realtime.drop", 100).Warnw("drop presence change",
+			"user_id", userID, "online", online, "error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type AccountRestoreRepository struct {
+	mu       sync.RWMutex
+	restores map[uuid.UUID]entity.AccountRestore
+}
+
+var _ contract.AccountRestoreRepository = (*AccountRestoreRepository)(nil)
+
+func NewAccountRestoreRepository() *AccountRestoreRepository {
+	return &AccountRestoreRepository{
+		restores: make(map[uuid.UUID]entity.AccountRestore),
+	}
+}
+
+func (r *AccountRestoreRepository) Create(ctx context.Context, a *entity.AccountRestore) (res *entity.AccountRestore, err error) {
+	ctx, span := startSpan(ctx, "account_restores.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newRestore := *a
+	if newRestore.ID == uuid.Nil {
+		newRestore.ID = uuid.New()
+	}
+	r.restores[newRestore.ID] = newRestore
+	return &newRestore, nil
+}
+
+func (r *AccountRestoreRepository) GetByTokenHash(ctx context.Context, tokenHash string) (res *entity.AccountRestore, err error) {
+	ctx, span := startSpan(ctx, "account_restores.get_by_token_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.restores {
+		if a.TokenHash == tokenHash {
+			return &a, nil
+		}
+	}
+	return nil, errs.ErrInvalidRestoreToken
+}
+
+func (r *AccountRestoreRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "account_restores.mark_used")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.restores[id]
+	if !ok || a.UsedAt != nil {
+		return errs.ErrInvalidRestoreToken
+	}
+	a.UsedAt = &at
+	r.restores[id] = a
+	return nil
+}
+
+func (r *AccountRestoreRepository) ListExpired(ctx context.Context, cutoff time.Time) (res []*entity.AccountRestore, err error) {
+	ctx, span := startSpan(ctx, "account_restores.list_expired")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*entity.AccountRestore
+	for _, a := range r.restores {
+		if a.ExpiresAt.Before(cutoff) {
+			out = append(out, &a)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.AccountRestore) int {
+		return a.ExpiresAt.Compare(b.ExpiresAt)
+	})
+	return out, nil
+}
+
+func (r *AccountRestoreRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "account_restores.delete")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.restores, id)
+	return nil
+}
@@ -0,0 +1,236 @@
+package infrastructure
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// AuditLogRepository keeps the events in creation time order and indexes
+// them by actor, subject and action, so filtered queries only visit the
+// events of the narrowest index that applies; time ranges are found by
+// binary search.
+type AuditLogRepository struct {
+	mu     sync.RWMutex
+	events []entity.AuditEvent
+	// The indexes hold positions in events, in ascending order.
+	byActor   map[uuid.UUID][]int
+	bySubject map[uuid.UUID][]int
+	byAction  map[string][]int
+}
+
+var _ contract.AuditLogRepository = (*AuditLogRepository)(nil)
+
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{
+		byActor:   make(map[uuid.UUID][]int),
+		bySubject: make(map[uuid.UUID][]int),
+		byAction:  make(map[string][]int),
+	}
+}
+
+func (r *AuditLogRepository) Append(ctx context.Context, e *entity.AuditEvent) (res *entity.AuditEvent, err error) {
+	ctx, span := startSpan(ctx, "audit_log.append")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newEvent := *e
+	if newEvent.ID == uuid.Nil {
+		newEvent.ID = uuid.New()
+	}
+
+	// Concurrent requests may append slightly out of order; the event then
+	// moves back into place and the positions after it shift.
+	pos := len(r.events)
+	if pos > 0 && newEvent.CreatedAt.Before(r.events[pos-1].CreatedAt) {
+		pos = sort.Search(len(r.events), func(i int) bool {
+			return r.events[i].CreatedAt.After(newEvent.CreatedAt)
+		})
+		for _, index := range []map[uuid.UUID][]int{r.byActor, r.bySubject} {
+			for _, positions := range index {
+				shiftPositions(positions, pos)
+			}
+		}
+		for _, positions := range r.byAction {
+			shiftPositions(positions, pos)
+		}
+	}
+	r.events = slices.Insert(r.events, pos, newEvent)
+	r.byActor[newEvent.ActorID] = insertPosition(r.byActor[newEvent.ActorID], pos)
+	r.bySubject[newEvent.SubjectID] = insertPosition(r.bySubject[newEvent.SubjectID], pos)
+	r.byAction[newEvent.Action] = insertPosition(r.byAction[newEvent.Action], pos)
+	return &newEvent, nil
+}
+
+func (r *AuditLogRepository) List(ctx context.Context, filter dto.AuditLogFilter, before *dto.ExportCursor, limit, offset int) (res []*entity.AuditEvent, total int, err error) {
+	ctx, span := startSpan(ctx, "audit_log.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	matches := r.matching(filter)
+	r.mu.RUnlock()
+
+	out := make([]*entity.AuditEvent, 0, limit)
+	for i := len(matches) - 1; i >= 0; i-- {
+		e := matches[i]
+		if !before.Follows(e.CreatedAt, e.ID) {
+			continue
+		}
+		if total >= offset && len(out) < limit {
+			out = append(out, e)
+		}
+		total++
+	}
+	return out, total, nil
+}
+
+func (r *AuditLogRepository) ListAfter(ctx context.Context, filter dto.AuditLogFilter, after *dto.ExportCursor, limit int) (res []*entity.AuditEvent, err error) {
+	ctx, span := startSpan(ctx, "audit_log.list_after")
+	defer func() { endSpan(span, res, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if after != nil && (filter.From == nil || filter.From.Before(after.CreatedAt)) {
+		from := after.CreatedAt
+		filter.From = &from
+	}
+	r.mu.RLock()
+	matches := r.matching(filter)
+	r.mu.RUnlock()
+
+	out := make([]*entity.AuditEvent, 0, min(limit, len(matches)))
+	for _, e := range matches {
+		if len(out) == limit {
+			break
+		}
+		if after.Precedes(e.CreatedAt, e.ID) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// matching returns the events matching filter in order. Callers hold r.mu.
+func (r *AuditLogRepository) matching(filter dto.AuditLogFilter) []*entity.AuditEvent {
+	positions, indexed := r.candidates(filter)
+
+	// Events are in time order, and so are the candidates' positions.
+	at := func(i int) int { return i }
+	n := len(r.events)
+	if indexed {
+		at = func(i int) int { return positions[i] }
+		n = len(positions)
+	}
+	lo, hi := 0, n
+	if filter.From != nil {
+		lo = sort.Search(n, func(i int) bool { return !r.events[at(i)].CreatedAt.Before(*filter.From) })
+	}
+	if filter.To != nil {
+		hi = sort.Search(n, func(i int) bool { return !r.events[at(i)].CreatedAt.Before(*filter.To) })
+	}
+
+	var out []*entity.AuditEvent
+	for i := lo; i < hi; i++ {
+		e := &r.events[at(i)]
+		if matchAuditEvent(filter, e) {
+			out = append(out, e)
+		}
+	}
+	// Ties on CreatedAt are in insertion order, not ID order.
+	slices.SortStableFunc(out, func(a, b *entity.AuditEvent) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+	for i, e := range out {
+		copied := *e
+		out[i] = &copied
+	}
+	return out
+}
+
+// candidates returns the positions of the narrowest index the filter can
+// use, or false when it can use none and every event is a candidate.
+func (r *AuditLogRepository) candidates(filter dto.AuditLogFilter) ([]int, bool) {
+	var lists [][]int
+	if filter.UserID != uuid.Nil {
+		lists = append(lists, mergePositions(r.byActor[filter.UserID], r.bySubject[filter.UserID]))
+	}
+	if filter.ActorID != uuid.Nil {
+		lists = append(lists, r.byActor[filter.ActorID])
+	}
+	if filter.SubjectID != uuid.Nil {
+		lists = append(lists, r.bySubject[filter.SubjectID])
+	}
+	if len(filter.Actions) > 0 {
+		var positions []int
+		for _, action := range filter.Actions {
+			positions = mergePositions(positions, r.byAction[action])
+		}
+		lists = append(lists, positions)
+	}
+	if len(lists) == 0 {
+		return nil, false
+	}
+	return slices.MinFunc(lists, func(a, b []int) int { return cmp.Compare(len(a), len(b)) }), true
+}
+
+func matchAuditEvent(f dto.AuditLogFilter, e *entity.AuditEvent) bool {
+	if f.UserID != uuid.Nil && e.ActorID != f.UserID && e.SubjectID != f.UserID {
+		return false
+	}
+	if f.ActorID != uuid.Nil && e.ActorID != f.ActorID {
+		return false
+	}
+	if f.SubjectID != uuid.Nil && e.SubjectID != f.SubjectID {
+		return false
+	}
+	if len(f.Actions) > 0 && !slices.Contains(f.Actions, e.Action) {
+		return false
+	}
+	if f.From != nil && e.CreatedAt.Before(*f.From) {
+		return false
+	}
+	return f.To == nil || e.CreatedAt.Before(*f.To)
+}
+
+// insertPosition adds pos to the ascending positions.
+func insertPosition(positions []int, pos int) []int {
+	i, _ := slices.BinarySearch(positions, pos)
+	return slices.Insert(positions, i, pos)
+}
+
+// shiftPositions moves the positions from pos on one place up, making room
+// for an event inserted at pos.
+func shiftPositions(positions []int, pos int) {
+	for i := len(positions) - 1; i >= 0 && positions[i] >= pos; i-- {
+		positions[i]++
+	}
+}
+
+// mergePositions returns the union of two ascending position lists.
+func mergePositions(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0] < b[0]):
+			out, a = append(out, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			out, b = append(out, b[0]), b[1:]
+		default:
+			out, a, b = append(out, a[0]), a[1:], b[1:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package infrastructure
+
+import (
+	"cmp"
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// TestAuditLogRepositoryMatchesScan checks the indexed queries against a
+// scan of every event, over events appended out of order with many ties
+// on their creation time.
+func TestAuditLogRepositoryMatchesScan(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(1264, 1))
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := make([]uuid.UUID, 5)
+	for i := range users {
+		users[i] = uuid.New()
+	}
+	actions := []string{
+		entity.AUDIT_IMPERSONATION_STARTED,
+		entity.AUDIT_USER_STATUS_CHANGED,
+		entity.AUDIT_USER_PLAN_CHANGED,
+		entity.AUDIT_MAINTENANCE_CHANGED,
+	}
+	pickUser := func() uuid.UUID {
+		if rng.IntN(len(users)+1) == 0 {
+			return uuid.Nil
+		}
+		return users[rng.IntN(len(users))]
+	}
+	pickTime := func() time.Time { return base.Add(time.Duration(rng.IntN(60)) * time.Minute) }
+
+	repo := NewAuditLogRepository()
+	var all []entity.AuditEvent
+	for range 500 {
+		e, err := repo.Append(ctx, &entity.AuditEvent{
+			ActorID:   users[rng.IntN(len(users))],
+			SubjectID: pickUser(),
+			Action:    actions[rng.IntN(len(actions))],
+			CreatedAt: pickTime(),
+		})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		all = append(all, *e)
+	}
+	slices.SortFunc(all, func(a, b entity.AuditEvent) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+
+	// scan returns the events matching filter, oldest first.
+	scan := func(f dto.AuditLogFilter) []entity.AuditEvent {
+		var out []entity.AuditEvent
+		for _, e := range all {
+			switch {
+			case f.UserID != uuid.Nil && e.ActorID != f.UserID && e.SubjectID != f.UserID,
+				f.ActorID != uuid.Nil && e.ActorID != f.ActorID,
+				f.SubjectID != uuid.Nil && e.SubjectID != f.SubjectID,
+				len(f.Actions) > 0 && !slices.Contains(f.Actions, e.Action),
+				f.From != nil && e.CreatedAt.Before(*f.From),
+				f.To != nil && !e.CreatedAt.Before(*f.To):
+				continue
+			}
+			out = append(out, e)
+		}
+		return out
+	}
+	randomFilter := func() dto.AuditLogFilter {
+		var f dto.AuditLogFilter
+		if rng.IntN(4) == 0 {
+			f.UserID = pickUser()
+		}
+		if rng.IntN(4) == 0 {
+			f.ActorID = pickUser()
+		}
+		if rng.IntN(4) == 0 {
+			f.SubjectID = pickUser()
+		}
+		for range rng.IntN(3) {
+			f.Actions = append(f.Actions, actions[rng.IntN(len(actions))])
+		}
+		if rng.IntN(3) == 0 {
+			from := pickTime()
+			f.From = &from
+		}
+		if rng.IntN(3) == 0 {
+			to := pickTime()
+			f.To = &to
+		}
+		return f
+	}
+	randomCursor := func() *dto.ExportCursor {
+		if rng.IntN(3) == 0 {
+			return nil
+		}
+		e := all[rng.IntN(len(all))]
+		return &dto.ExportCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	}
+
+	for i := range 300 {
+		filter := randomFilter()
+		matches := scan(filter)
+
+		before := randomCursor()
+		limit, offset := 1+rng.IntN(20), rng.IntN(10)
+		var newest []entity.AuditEvent
+		for _, e := range slices.Backward(matches) {
+			if before.Follows(e.CreatedAt, e.ID) {
+				newest = append(newest, e)
+			}
+		}
+		page, total, err := repo.List(ctx, filter, before, limit, offset)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if total != len(newest) {
+			t.Errorf("case %d: list total = %d, want %d (filter %+v)", i, total, len(newest), filter)
+		}
+		want := newest[min(offset, len(newest)):min(offset+limit, len(newest))]
+		assertAuditEvents(t, i, "list", page, want)
+
+		after := randomCursor()
+		var oldest []entity.AuditEvent
+		for _, e := range matches {
+			if after.Precedes(e.CreatedAt, e.ID) {
+				oldest = append(oldest, e)
+			}
+		}
+		page, err = repo.ListAfter(ctx, filter, after, limit)
+		if err != nil {
+			t.Fatalf("list after: %v", err)
+		}
+		assertAuditEvents(t, i, "list after", page, oldest[:min(limit, len(oldest))])
+	}
+
+	t.Run("export walk", func(t *testing.T) {
+		var (
+			walked []*entity.AuditEvent
+			cursor *dto.ExportCursor
+		)
+		for {
+			page, err := repo.ListAfter(ctx, dto.AuditLogFilter{}, cursor, 7)
+			if err != nil {
+				t.Fatalf("list after: %v", err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			walked = append(walked, page...)
+			last := page[len(page)-1]
+			cursor = &dto.ExportCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+		assertAuditEvents(t, 0, "walk", walked, all)
+	})
+}
+
+func assertAuditEvents(t *testing.T, i int, query string, got []*entity.AuditEvent, want []entity.AuditEvent) {
+	t.Helper()
+	ok := len(got) == len(want)
+	for j := 0; ok && j < len(got); j++ {
+		ok = got[j].ID == want[j].ID
+	}
+	if ok {
+		return
+	}
+	gotIDs := make([]uuid.UUID, len(got))
+	for j, e := range got {
+		gotIDs[j] = e.ID
+	}
+	wantIDs := make([]uuid.UUID, len(want))
+	for j, e := range want {
+		wantIDs[j] = e.ID
+	}
+	t.Errorf("case %d: %s = %v, want %v", i, query, gotIDs, wantIDs)
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// AuthProtectionRepository keeps the override in memory, so a change made
+// through one instance applies to that instance only until it restarts, as
+// do the anomalies it detects.
+type AuthProtectionRepository struct {
+	mu         sync.RWMutex
+	protection entity.AuthProtection
+}
+
+var _ contract.AuthProtectionRepository = (*AuthProtectionRepository)(nil)
+
+// NewAuthProtectionRepository starts with the override initial, e.g. the
+// configured one.
+func NewAuthProtectionRepository(initial entity.AuthProtection) *AuthProtectionRepository {
+	return &AuthProtectionRepository{protection: initial}
+}
+
+// Get is not traced, as every credential request calls it.
+func (r *AuthProtectionRepository) Get(ctx context.Context) (*entity.AuthProtection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p := r.protection
+	return &p, nil
+}
+
+func (r *AuthProtectionRepository) Set(ctx context.Context, p *entity.AuthProtection) (res *entity.AuthProtection, err error) {
+	ctx, span := startSpan(ctx, "auth_protection.set")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.protection = *p
+	updated := r.protection
+	return &updated, nil
+}
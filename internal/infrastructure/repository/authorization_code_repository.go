@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+// authorizationCodeSweepEvery is how many codes are created between
+// deletions of the expired ones, which are left behind by relying parties
+// that never redeem their code.
+const authorizationCodeSweepEvery = 256
+
+type AuthorizationCodeRepository struct {
+	mu sync.RWMutex
+	// codes are keyed by CodeHash. Consumed ones stay until they expire.
+	codes   map[string]entity.AuthorizationCode
+	created int
+	clock   clock.Clock
+}
+
+var _ contract.AuthorizationCodeRepository = (*AuthorizationCodeRepository)(nil)
+
+// NewAuthorizationCodeRepository returns an in-memory store whose periodic
+// sweep reads the time from clk, or the wall clock when clk is nil.
+func NewAuthorizationCodeRepository(clk clock.Clock) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{
+		codes: make(map[string]entity.AuthorizationCode),
+		clock: clock.OrReal(clk),
+	}
+}
+
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, c *entity.AuthorizationCode) (res *entity.AuthorizationCode, err error) {
+	ctx, span := startSpan(ctx, "authorization_codes.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.created++
+	if r.created%authorizationCodeSweepEvery == 0 {
+		r.deleteExpired(r.clock.Now())
+	}
+
+	newCode := *c
+	if newCode.ID == uuid.Nil {
+		newCode.ID = uuid.New()
+	}
+	newCode.Scopes = slices.Clone(c.Scopes)
+	newCode.ConsumedAt = nil
+	r.codes[newCode.CodeHash] = newCode
+	return &newCode, nil
+}
+
+func (r *AuthorizationCodeRepository) GetByCodeHash(ctx context.Context, codeHash string) (res *entity.AuthorizationCode, err error) {
+	ctx, span := startSpan(ctx, "authorization_codes.get_by_code_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.codes[codeHash]
+	if !ok {
+		return nil, errs.ErrInvalidGrant
+	}
+	return cloneAuthorizationCode(c), nil
+}
+
+func (r *AuthorizationCodeRepository) Consume(ctx context.Context, codeHash string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "authorization_codes.consume")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.codes[codeHash]
+	if !ok || c.ConsumedAt != nil {
+		return errs.ErrInvalidGrant
+	}
+	c.ConsumedAt = &at
+	r.codes[codeHash] = c
+	return nil
+}
+
+func (r *AuthorizationCodeRepository) DeleteExpired(ctx context.Context, cutoff time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "authorization_codes.delete_expired")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteExpired(cutoff), nil
+}
+
+// deleteExpired expects the caller to hold the lock.
+func (r *AuthorizationCodeRepository) deleteExpired(cutoff time.Time) int {
+	n := 0
+	for hash, c := range r.codes {
+		if c.ExpiresAt.Before(cutoff) {
+			delete(r.codes, hash)
+			n++
+		}
+	}
+	return n
+}
+
+func cloneAuthorizationCode(c entity.AuthorizationCode) *entity.AuthorizationCode {
+	c.Scopes = slices.Clone(c.Scopes)
+	if c.ConsumedAt != nil {
+		at := *c.ConsumedAt
+		c.ConsumedAt = &at
+	}
+	return &c
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+func TestAuthorizationCodeConsumeKeepsTombstone(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	repo := NewAuthorizationCodeRepository(clock.NewFake(now))
+	if _, err := repo.Create(ctx, &entity.AuthorizationCode{CodeHash: "h", ExpiresAt: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := repo.Consume(ctx, "h", now); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if err := repo.Consume(ctx, "h", now); !errors.Is(err, errs.ErrInvalidGrant) {
+		t.Fatalf("second consume: got %v, want ErrInvalidGrant", err)
+	}
+	c, err := repo.GetByCodeHash(ctx, "h")
+	if err != nil {
+		t.Fatalf("get consumed code: %v", err)
+	}
+	if c.ConsumedAt == nil || !c.ConsumedAt.Equal(now) || c.IsUsable(now) {
+		t.Fatalf("consumed code: ConsumedAt %v, usable %v", c.ConsumedAt, c.IsUsable(now))
+	}
+}
+
+func TestAuthorizationCodeSweepReadsClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().UTC()
+	clk := clock.NewFake(start)
+	repo := NewAuthorizationCodeRepository(clk)
+	if _, err := repo.Create(ctx, &entity.AuthorizationCode{CodeHash: "old", ExpiresAt: start.Add(time.Minute)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Only the fake clock says the code expired; the wall clock does not.
+	clk.Advance(time.Hour)
+	for i := 1; i < authorizationCodeSweepEvery; i++ {
+		if _, err := repo.Create(ctx, &entity.AuthorizationCode{CodeHash: fmt.Sprint(i), ExpiresAt: clk.Now().Add(time.Minute)}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	if _, err := repo.GetByCodeHash(ctx, "old"); !errors.Is(err, errs.ErrInvalidGrant) {
+		t.Fatalf("expired code after the sweep: got %v, want ErrInvalidGrant", err)
+	}
+	if _, err := repo.GetByCodeHash(ctx, "1"); err != nil {
+		t.Fatalf("live code after the sweep: %v", err)
+	}
+}
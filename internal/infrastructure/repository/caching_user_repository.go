@@ -0,0 +1,204 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	userCacheLookupsTotal = metrics.NewCounter("user_cache_lookups_total",
+		"User lookups by ID served by the user cache, by outcome (hit, miss).", "outcome")
+	userCacheInvalidationsTotal = metrics.NewCounter("user_cache_invalidations_total",
+		"Users evicted from the user cache, by source (write, remote).", "source")
+)
+
+type userCacheEntry struct {
+	user      entity.User
+	expiresAt time.Time
+}
+
+// UserCache holds users by ID for a TTL. Entries are evicted when the user
+// is written, on this replica by the CachingUserRepository and on the
+// others by the UserCacheRelay, so the TTL only bounds how long a missed
+// invalidation can serve a stale user.
+type UserCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]userCacheEntry
+	// gen counts the invalidations, so a lookup that read the store before
+	// one does not cache what it read.
+	gen uint64
+	// bypass turns the cache off while invalidations may be missed.
+	bypass atomic.Bool
+}
+
+func NewUserCache(ttl time.Duration, size int) *UserCache {
+	if size <= 0 {
+		size = 10000
+	}
+	return &UserCache{ttl: ttl, size: size, entries: make(map[uuid.UUID]userCacheEntry)}
+}
+
+// get returns a copy of the cached user, or the generation to fill the
+// entry with on a miss.
+func (c *UserCache) get(id uuid.UUID) (*entity.User, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok || c.bypass.Load() || time.Now().After(e.expiresAt) {
+		return nil, c.gen, false
+	}
+	u := e.user
+	return &u, c.gen, true
+}
+
+func (c *UserCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// fill caches u unless an invalidation happened since gen. When the cache
+// is full it drops the expired entries, or else an arbitrary one.
+func (c *UserCache) fill(u *entity.User, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen || c.bypass.Load() {
+		return
+	}
+	if _, ok := c.entries[u.ID]; !ok && len(c.entries) >= c.size {
+		now := time.Now()
+		for id, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		for id := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, id)
+		}
+	}
+	c.entries[u.ID] = userCacheEntry{user: *u, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// Invalidate evicts the user with id; source labels the eviction in
+// user_cache_invalidations_total.
+func (c *UserCache) Invalidate(id uuid.UUID, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	delete(c.entries, id)
+	userCacheInvalidationsTotal.Inc(source)
+}
+
+// Clear evicts every user.
+func (c *UserCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.entries)
+}
+
+// SetBypass turns the cache off or back on; while off, lookups go to the
+// store and nothing is cached.
+func (c *UserCache) SetBypass(on bool) {
+	c.bypass.Store(on)
+}
+
+// CachingUserRepository serves GetByID from a UserCache in front of another
+// UserRepository. Its writes evict the user before returning, so a replica
+// reads its own writes; the other lookups are not cached, as a write does
+// not tell which email or username the user had before.
+type CachingUserRepository struct {
+	next  contract.UserRepository
+	cache *UserCache
+}
+
+var _ contract.UserRepository = (*CachingUserRepository)(nil)
+
+func NewCachingUserRepository(next contract.UserRepository, cache *UserCache) *CachingUserRepository {
+	return &CachingUserRepository{next: next, cache: cache}
+}
+
+func (r *CachingUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	u, gen, ok := r.cache.get(id)
+	if ok {
+		userCacheLookupsTotal.Inc("hit")
+		return u, nil
+	}
+	userCacheLookupsTotal.Inc("miss")
+	u, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.fill(u, gen)
+	return u, nil
+}
+
+// GetByIDs serves the cached users and looks up the others in one call.
+func (r *CachingUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error) {
+	gen := r.cache.generation()
+	res := make(map[uuid.UUID]*entity.User, len(ids))
+	var missing []uuid.UUID
+	for _, id := range ids {
+		if u, _, ok := r.cache.get(id); ok {
+			userCacheLookupsTotal.Inc("hit")
+			res[id] = u
+			continue
+		}
+		userCacheLookupsTotal.Inc("miss")
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	found, err := r.next.GetByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for id, u := range found {
+		r.cache.fill(u, gen)
+		res[id] = u
+	}
+	return res, nil
+}
+
+func (r *CachingUserRepository) Create(ctx context.Context, u *entity.User) (*entity.User, error) {
+	return r.next.Create(ctx, u)
+}
+
+// Update evicts the user even when it fails: a concurrent update means the
+// cached copy is outdated.
+func (r *CachingUserRepository) Update(ctx context.Context, u *entity.User) (*entity.User, error) {
+	defer r.cache.Invalidate(u.ID, "write")
+	return r.next.Update(ctx, u)
+}
+
+func (r *CachingUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.cache.Invalidate(id, "write")
+	return r.next.Delete(ctx, id)
+}
+
+func (r *CachingUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.next.GetByEmail(ctx, email)
+}
+
+func (r *CachingUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.next.GetByUsername(ctx, username)
+}
+
+func (r *CachingUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	return r.next.GetByPhone(ctx, phone)
+}
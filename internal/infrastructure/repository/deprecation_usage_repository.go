@@ -0,0 +1,106 @@
+package infrastructure
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// MAX_DEPRECATION_CLIENTS bounds the clients tracked per deprecation; the
+// requests of the others are only counted.
+const MAX_DEPRECATION_CLIENTS = 1000
+
+// DeprecationUsageRepository keeps the usage in memory, so each instance
+// reports the requests it served since it started.
+type DeprecationUsageRepository struct {
+	mu     sync.RWMutex
+	usages map[string]*deprecationUsage
+}
+
+type deprecationUsage struct {
+	deprecation entity.Deprecation
+	clients     map[string]*entity.DeprecationClient
+	requests    int64
+	other       int64
+}
+
+var _ contract.DeprecationUsageRepository = (*DeprecationUsageRepository)(nil)
+
+func NewDeprecationUsageRepository() *DeprecationUsageRepository {
+	return &DeprecationUsageRepository{usages: map[string]*deprecationUsage{}}
+}
+
+func (r *DeprecationUsageRepository) Declare(ctx context.Context, d *entity.Deprecation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usage(d)
+	return nil
+}
+
+// Record is not traced, as every deprecated request calls it.
+func (r *DeprecationUsageRepository) Record(ctx context.Context, d *entity.Deprecation, client string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.usage(d)
+	u.requests++
+	c, ok := u.clients[client]
+	if !ok {
+		if len(u.clients) >= MAX_DEPRECATION_CLIENTS {
+			u.other++
+			return false, nil
+		}
+		c = &entity.DeprecationClient{Client: client, FirstSeen: at}
+		u.clients[client] = c
+	}
+	c.Requests++
+	c.LastSeen = at
+	return !ok, nil
+}
+
+func (r *DeprecationUsageRepository) List(ctx context.Context) (res []*dto.DeprecationUsage, err error) {
+	ctx, span := startSpan(ctx, "deprecation_usage.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res = make([]*dto.DeprecationUsage, 0, len(r.usages))
+	for _, u := range r.usages {
+		out := &dto.DeprecationUsage{
+			Deprecation:   u.deprecation,
+			Requests:      u.requests,
+			Clients:       make([]entity.DeprecationClient, 0, len(u.clients)),
+			OtherRequests: u.other,
+		}
+		for _, c := range u.clients {
+			out.Clients = append(out.Clients, *c)
+		}
+		slices.SortFunc(out.Clients, func(a, b entity.DeprecationClient) int {
+			return cmp.Or(cmp.Compare(b.Requests, a.Requests), cmp.Compare(a.Client, b.Client))
+		})
+		res = append(res, out)
+	}
+	slices.SortFunc(res, func(a, b *dto.DeprecationUsage) int {
+		return cmp.Compare(a.Route(), b.Route())
+	})
+	return res, nil
+}
+
+// usage returns the usage of d, adding it when new; r.mu must be held.
+func (r *DeprecationUsageRepository) usage(d *entity.Deprecation) *deprecationUsage {
+	key := d.Route()
+	u, ok := r.usages[key]
+	if !ok {
+		u = &deprecationUsage{deprecation: *d, clients: map[string]*entity.DeprecationClient{}}
+		r.usages[key] = u
+	}
+	return u
+}
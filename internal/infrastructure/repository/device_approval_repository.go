@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type DeviceApprovalRepository struct {
+	mu        sync.RWMutex
+	approvals map[uuid.UUID]entity.DeviceApproval
+}
+
+var _ contract.DeviceApprovalRepository = (*DeviceApprovalRepository)(nil)
+
+func NewDeviceApprovalRepository() *DeviceApprovalRepository {
+	return &DeviceApprovalRepository{
+		approvals: make(map[uuid.UUID]entity.DeviceApproval),
+	}
+}
+
+func (r *DeviceApprovalRepository) Create(ctx context.Context, a *entity.DeviceApproval) (res *entity.DeviceApproval, err error) {
+	ctx, span := startSpan(ctx, "device_approvals.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newApproval := *a
+	if newApproval.ID == uuid.Nil {
+		newApproval.ID = uuid.New()
+	}
+	r.approvals[newApproval.ID] = newApproval
+	return &newApproval, nil
+}
+
+func (r *DeviceApprovalRepository) GetByTokenHash(ctx context.Context, tokenHash string) (res *entity.DeviceApproval, err error) {
+	ctx, span := startSpan(ctx, "device_approvals.get_by_token_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.approvals {
+		if a.TokenHash == tokenHash {
+			return &a, nil
+		}
+	}
+	return nil, errs.ErrDeviceApprovalNotFound
+}
+
+func (r *DeviceApprovalRepository) Update(ctx context.Context, a *entity.DeviceApproval) (res *entity.DeviceApproval, err error) {
+	ctx, span := startSpan(ctx, "device_approvals.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.approvals[a.ID]; !ok {
+		return nil, errs.ErrDeviceApprovalNotFound
+	}
+	updated := *a
+	r.approvals[a.ID] = updated
+	return &updated, nil
+}
@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type EmailChangeRepository struct {
+	mu      sync.RWMutex
+	changes map[uuid.UUID]entity.EmailChange
+}
+
+var _ contract.EmailChangeRepository = (*EmailChangeRepository)(nil)
+
+func NewEmailChangeRepository() *EmailChangeRepository {
+	return &EmailChangeRepository{
+		changes: make(map[uuid.UUID]entity.EmailChange),
+	}
+}
+
+func (r *EmailChangeRepository) Create(ctx context.Context, c *entity.EmailChange) (res *entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newChange := *c
+	if newChange.ID == uuid.Nil {
+		newChange.ID = uuid.New()
+	}
+	r.changes[newChange.ID] = newChange
+	return &newChange, nil
+}
+
+func (r *EmailChangeRepository) GetByConfirmHash(ctx context.Context, hash string) (res *entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.get_by_confirm_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	return r.find(func(c *entity.EmailChange) bool { return c.ConfirmHash == hash })
+}
+
+func (r *EmailChangeRepository) GetByRevertHash(ctx context.Context, hash string) (res *entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.get_by_revert_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	return r.find(func(c *entity.EmailChange) bool { return c.RevertHash == hash })
+}
+
+func (r *EmailChangeRepository) GetPendingByUser(ctx context.Context, userID uuid.UUID) (res *entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.get_pending_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.changes {
+		if c.UserID == userID && c.Status == entity.EMAIL_CHANGE_PENDING && (res == nil || c.CreatedAt.After(res.CreatedAt)) {
+			res = &c
+		}
+	}
+	if res == nil {
+		return nil, errs.ErrNoPendingEmailChange
+	}
+	return res, nil
+}
+
+func (r *EmailChangeRepository) ListByUser(ctx context.Context, userID uuid.UUID) (res []*entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*entity.EmailChange
+	for _, c := range r.changes {
+		if c.UserID == userID {
+			out = append(out, &c)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.EmailChange) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return out, nil
+}
+
+func (r *EmailChangeRepository) Update(ctx context.Context, c *entity.EmailChange) (res *entity.EmailChange, err error) {
+	ctx, span := startSpan(ctx, "email_changes.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.changes[c.ID]; !ok {
+		return nil, errs.ErrInvalidEmailChangeToken
+	}
+	updated := *c
+	r.changes[c.ID] = updated
+	return &updated, nil
+}
+
+func (r *EmailChangeRepository) find(match func(*entity.EmailChange) bool) (*entity.EmailChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.changes {
+		if match(&c) {
+			return &c, nil
+		}
+	}
+	return nil, errs.ErrInvalidEmailChangeToken
+}
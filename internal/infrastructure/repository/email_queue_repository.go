@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type EmailQueueRepository struct {
+	mu     sync.RWMutex
+	emails map[uuid.UUID]entity.OutboundEmail
+	// order holds the IDs oldest first.
+	order []uuid.UUID
+}
+
+var _ contract.EmailQueueRepository = (*EmailQueueRepository)(nil)
+
+func NewEmailQueueRepository() *EmailQueueRepository {
+	return &EmailQueueRepository{
+		emails: make(map[uuid.UUID]entity.OutboundEmail),
+	}
+}
+
+func (r *EmailQueueRepository) Create(ctx context.Context, e *entity.OutboundEmail) (res *entity.OutboundEmail, err error) {
+	ctx, span := startSpan(ctx, "outbound_emails.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newEmail := *e
+	if newEmail.ID == uuid.Nil {
+		newEmail.ID = uuid.New()
+	}
+	r.emails[newEmail.ID] = newEmail
+	r.order = append(r.order, newEmail.ID)
+	return &newEmail, nil
+}
+
+func (r *EmailQueueRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.OutboundEmail, err error) {
+	ctx, span := startSpan(ctx, "outbound_emails.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.emails[id]
+	if !ok {
+		return nil, errs.ErrOutboundEmailNotFound
+	}
+	return &e, nil
+}
+
+func (r *EmailQueueRepository) Due(ctx context.Context, now time.Time, limit int) (res []*entity.OutboundEmail, err error) {
+	ctx, span := startSpan(ctx, "outbound_emails.due")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range r.order {
+		if len(res) == limit {
+			break
+		}
+		e := r.emails[id]
+		if e.Status == entity.OUTBOUND_EMAIL_QUEUED && !e.NextAttemptAt.After(now) {
+			res = append(res, &e)
+		}
+	}
+	return res, nil
+}
+
+func (r *EmailQueueRepository) List(ctx context.Context, status string, limit, offset int) (res []*entity.OutboundEmail, total int, err error) {
+	ctx, span := startSpan(ctx, "outbound_emails.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.OutboundEmail, 0, limit)
+	for i := len(r.order) - 1; i >= 0; i-- {
+		e := r.emails[r.order[i]]
+		if status != "" && e.Status != status {
+			continue
+		}
+		if total >= offset && len(out) < limit {
+			out = append(out, &e)
+		}
+		total++
+	}
+	return out, total, nil
+}
+
+func (r *EmailQueueRepository) Update(ctx context.Context, e *entity.OutboundEmail) (res *entity.OutboundEmail, err error) {
+	ctx, span := startSpan(ctx, "outbound_emails.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.emails[e.ID]; !ok {
+		return nil, errs.ErrOutboundEmailNotFound
+	}
+	r.emails[e.ID] = *e
+	updated := *e
+	return &updated, nil
+}
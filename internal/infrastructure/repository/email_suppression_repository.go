@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type EmailSuppressionRepository struct {
+	mu      sync.RWMutex
+	entries map[string]entity.EmailSuppression
+	// order holds the addresses oldest first.
+	order []string
+}
+
+var _ contract.EmailSuppressionRepository = (*EmailSuppressionRepository)(nil)
+
+func NewEmailSuppressionRepository() *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{entries: make(map[string]entity.EmailSuppression)}
+}
+
+// Get is not traced, as every send calls it.
+func (r *EmailSuppressionRepository) Get(ctx context.Context, email string) (*entity.EmailSuppression, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.entries[email]
+	if !ok {
+		return nil, errs.ErrEmailSuppressionNotFound
+	}
+	return &s, nil
+}
+
+func (r *EmailSuppressionRepository) Add(ctx context.Context, s *entity.EmailSuppression) (res *entity.EmailSuppression, added bool, err error) {
+	ctx, span := startSpan(ctx, "email_suppressions.add")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.entries[s.Email]; ok {
+		return &existing, false, nil
+	}
+	r.entries[s.Email] = *s
+	r.order = append(r.order, s.Email)
+	newEntry := *s
+	return &newEntry, true, nil
+}
+
+func (r *EmailSuppressionRepository) Remove(ctx context.Context, email string) (res *entity.EmailSuppression, err error) {
+	ctx, span := startSpan(ctx, "email_suppressions.remove")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.entries[email]
+	if !ok {
+		return nil, errs.ErrEmailSuppressionNotFound
+	}
+	delete(r.entries, email)
+	r.order = slices.DeleteFunc(r.order, func(e string) bool { return e == email })
+	return &s, nil
+}
+
+func (r *EmailSuppressionRepository) List(ctx context.Context, reason string, limit, offset int) (res []*entity.EmailSuppression, total int, err error) {
+	ctx, span := startSpan(ctx, "email_suppressions.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.EmailSuppression, 0, limit)
+	for i := len(r.order) - 1; i >= 0; i-- {
+		s := r.entries[r.order[i]]
+		if reason != "" && s.Reason != reason {
+			continue
+		}
+		if total >= offset && len(out) < limit {
+			out = append(out, &s)
+		}
+		total++
+	}
+	return out, total, nil
+}
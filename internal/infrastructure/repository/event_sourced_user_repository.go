@@ -0,0 +1,217 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// EventSourcedUserRepository persists users as the events in a
+// UserEventStore. Writes rehydrate the user from its latest snapshot and the
+// events after it, append the changes, and project the result into a
+// current-state UserRepository, which serves every read.
+type EventSourcedUserRepository struct {
+	// mu serializes writes so uniqueness checks against the projection
+	// cannot race.
+	mu            sync.Mutex
+	store         *UserEventStore
+	projection    *UserRepository
+	snapshotEvery int
+}
+
+var _ contract.UserRepository = (*EventSourcedUserRepository)(nil)
+
+type EventSourcedUserRepositoryArgs struct {
+	Store      *UserEventStore
+	Projection *UserRepository
+	// SnapshotEvery is the number of events between snapshots; 0 disables
+	// them.
+	SnapshotEvery int
+}
+
+func NewEventSourcedUserRepository(args EventSourcedUserRepositoryArgs) *EventSourcedUserRepository {
+	return &EventSourcedUserRepository{
+		store:         args.Store,
+		projection:    args.Projection,
+		snapshotEvery: args.SnapshotEvery,
+	}
+}
+
+func (r *EventSourcedUserRepository) Create(ctx context.Context, du *entity.User) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := *du
+	u.ID = uuid.New()
+	u.Email = strings.ToLower(du.Email)
+	u.Username = entity.NormalizeUsername(du.Username)
+	if u.Status == "" {
+		u.Status = entity.USER_STATUS_ACTIVE
+	}
+	if err := r.checkUnique(ctx, &u); err != nil {
+		return nil, err
+	}
+
+	events, err := entity.UserChanges(nil, &u, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return r.commit(ctx, entity.User{}, 0, events)
+}
+
+func (r *EventSourcedUserRepository) Update(ctx context.Context, du *entity.User) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, version, err := r.rehydrate(ctx, du.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := current
+	next.Email = strings.ToLower(du.Email)
+	next.Username = entity.NormalizeUsername(du.Username)
+	next.Phone = du.Phone
+	next.PhoneVerifiedAt = du.PhoneVerifiedAt
+	next.HashedPassword = du.HashedPassword
+	next.PasswordChangedAt = du.PasswordChangedAt
+	next.PasswordChangeRequired = du.PasswordChangeRequired
+	next.PasswordUnset = du.PasswordUnset
+	next.IsGuest = du.IsGuest
+	next.Status = du.Status
+	next.StatusChangedAt = du.StatusChangedAt
+	next.AccessRevokedAt = du.AccessRevokedAt
+	next.Plan = du.Plan
+	next.DeletedAt = du.DeletedAt
+	next.PurgeAt = du.PurgeAt
+	if err := r.checkUnique(ctx, &next); err != nil {
+		return nil, err
+	}
+
+	events, err := entity.UserChanges(&current, &next, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return &current, nil
+	}
+	return r.commit(ctx, current, version, events)
+}
+
+func (r *EventSourcedUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, version, err := r.rehydrate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := r.store.Append(ctx, id, version, []entity.UserEvent{entity.UserDeleted(id, time.Now().UTC())}); err != nil {
+		return err
+	}
+	return r.projection.Delete(ctx, id)
+}
+
+// commit appends events to the user at version, applies them and projects
+// the result.
+func (r *EventSourcedUserRepository) commit(ctx context.Context, u entity.User, version int, events []entity.UserEvent) (*entity.User, error) {
+	appended, err := r.store.Append(ctx, events[0].UserID, version, events)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range appended {
+		if err := u.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+	newVersion := appended[len(appended)-1].Version
+	if r.snapshotEvery > 0 && newVersion/r.snapshotEvery > version/r.snapshotEvery {
+		r.store.SaveSnapshot(ctx, u, newVersion)
+	}
+	r.projection.Project(u)
+	return &u, nil
+}
+
+// rehydrate rebuilds the user from its latest snapshot and the events
+// recorded after it, returning the version it is at. Deleted users are not
+// found.
+func (r *EventSourcedUserRepository) rehydrate(ctx context.Context, id uuid.UUID) (entity.User, int, error) {
+	u, version, _ := r.store.Snapshot(ctx, id)
+	events, err := r.store.Load(ctx, id, version)
+	if err != nil {
+		return entity.User{}, 0, err
+	}
+	for _, e := range events {
+		if err := u.Apply(e); err != nil {
+			return entity.User{}, 0, err
+		}
+		version = e.Version
+	}
+	if version == 0 || u.ID == uuid.Nil {
+		return entity.User{}, 0, errs.ErrUserNotFound
+	}
+	return u, version, nil
+}
+
+// checkUnique rejects an email, username or verified phone another user
+// already holds.
+func (r *EventSourcedUserRepository) checkUnique(ctx context.Context, u *entity.User) error {
+	if u.Email != "" {
+		other, err := r.projection.GetByEmail(ctx, u.Email)
+		if err := heldByOther(u.ID, other, err, errs.ErrEmailTaken); err != nil {
+			return err
+		}
+	}
+	if u.Username != "" {
+		other, err := r.projection.GetByUsername(ctx, u.Username)
+		if err := heldByOther(u.ID, other, err, errs.ErrUsernameTaken); err != nil {
+			return err
+		}
+	}
+	if u.Phone != "" && u.PhoneVerifiedAt != nil {
+		other, err := r.projection.GetByPhone(ctx, u.Phone)
+		if err := heldByOther(u.ID, other, err, errs.ErrPhoneTaken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func heldByOther(id uuid.UUID, other *entity.User, err, taken error) error {
+	switch {
+	case errors.Is(err, errs.ErrUserNotFound):
+		return nil
+	case err != nil:
+		return err
+	case other.ID != id:
+		return taken
+	}
+	return nil
+}
+
+func (r *EventSourcedUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return r.projection.GetByID(ctx, id)
+}
+
+func (r *EventSourcedUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error) {
+	return r.projection.GetByIDs(ctx, ids)
+}
+
+func (r *EventSourcedUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.projection.GetByEmail(ctx, email)
+}
+
+func (r *EventSourcedUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.projection.GetByUsername(ctx, username)
+}
+
+func (r *EventSourcedUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	return r.projection.GetByPhone(ctx, phone)
+}
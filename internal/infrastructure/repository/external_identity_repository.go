@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type externalIdentityKey struct {
+	issuer, subject string
+}
+
+type ExternalIdentityRepository struct {
+	mu         sync.RWMutex
+	identities map[externalIdentityKey]entity.ExternalIdentity
+}
+
+var _ contract.ExternalIdentityRepository = (*ExternalIdentityRepository)(nil)
+
+func NewExternalIdentityRepository() *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{
+		identities: make(map[externalIdentityKey]entity.ExternalIdentity),
+	}
+}
+
+func (r *ExternalIdentityRepository) Create(ctx context.Context, i *entity.ExternalIdentity) (res *entity.ExternalIdentity, err error) {
+	ctx, span := startSpan(ctx, "external_identities.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := externalIdentityKey{i.Issuer, i.Subject}
+	if _, ok := r.identities[key]; ok {
+		return nil, errs.ErrExternalIdentityExists
+	}
+	newIdentity := *i
+	if newIdentity.ID == uuid.Nil {
+		newIdentity.ID = uuid.New()
+	}
+	if newIdentity.CreatedAt.IsZero() {
+		newIdentity.CreatedAt = time.Now().UTC()
+	}
+	r.identities[key] = newIdentity
+	return &newIdentity, nil
+}
+
+func (r *ExternalIdentityRepository) Get(ctx context.Context, issuer, subject string) (res *entity.ExternalIdentity, err error) {
+	ctx, span := startSpan(ctx, "external_identities.get")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.identities[externalIdentityKey{issuer, subject}]
+	if !ok {
+		return nil, errs.ErrExternalIdentityNotFound
+	}
+	return &i, nil
+}
@@ -0,0 +1,123 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type InvitationRepository struct {
+	mu          sync.RWMutex
+	invitations map[uuid.UUID]entity.Invitation
+}
+
+var _ contract.InvitationRepository = (*InvitationRepository)(nil)
+
+func NewInvitationRepository() *InvitationRepository {
+	return &InvitationRepository{
+		invitations: make(map[uuid.UUID]entity.Invitation),
+	}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, i *entity.Invitation) (res *entity.Invitation, err error) {
+	ctx, span := startSpan(ctx, "invitations.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newInvitation := *i
+	if newInvitation.ID == uuid.Nil {
+		newInvitation.ID = uuid.New()
+	}
+	r.invitations[newInvitation.ID] = newInvitation
+	return &newInvitation, nil
+}
+
+func (r *InvitationRepository) GetByCodeHash(ctx context.Context, codeHash string) (res *entity.Invitation, err error) {
+	ctx, span := startSpan(ctx, "invitations.get_by_code_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, i := range r.invitations {
+		if i.CodeHash == codeHash {
+			return &i, nil
+		}
+	}
+	return nil, errs.ErrInvitationNotFound
+}
+
+func (r *InvitationRepository) List(ctx context.Context) (res []*entity.Invitation, err error) {
+	ctx, span := startSpan(ctx, "invitations.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.Invitation, 0, len(r.invitations))
+	for _, i := range r.invitations {
+		out = append(out, &i)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.After(out[b].CreatedAt) })
+	return out, nil
+}
+
+func (r *InvitationRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "invitations.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.invitations[id]
+	if !ok {
+		return errs.ErrInvitationNotFound
+	}
+	if i.RevokedAt == nil {
+		i.RevokedAt = &at
+		r.invitations[id] = i
+	}
+	return nil
+}
+
+func (r *InvitationRepository) Redeem(ctx context.Context, id uuid.UUID, email string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "invitations.redeem")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.invitations[id]
+	if !ok || !i.IsRedeemable(email, at) {
+		return errs.ErrInvalidInvite
+	}
+	i.Uses++
+	r.invitations[id] = i
+	return nil
+}
+
+func (r *InvitationRepository) Release(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "invitations.release")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.invitations[id]
+	if !ok {
+		return errs.ErrInvitationNotFound
+	}
+	if i.Uses > 0 {
+		i.Uses--
+		r.invitations[id] = i
+	}
+	return nil
+}
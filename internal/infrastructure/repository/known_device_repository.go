@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type knownDeviceKey struct {
+	userID      uuid.UUID
+	fingerprint string
+}
+
+type KnownDeviceRepository struct {
+	mu      sync.RWMutex
+	devices map[knownDeviceKey]entity.KnownDevice
+}
+
+var _ contract.KnownDeviceRepository = (*KnownDeviceRepository)(nil)
+
+func NewKnownDeviceRepository() *KnownDeviceRepository {
+	return &KnownDeviceRepository{
+		devices: make(map[knownDeviceKey]entity.KnownDevice),
+	}
+}
+
+func (r *KnownDeviceRepository) IsKnown(ctx context.Context, userID uuid.UUID, fingerprint string) (res bool, err error) {
+	ctx, span := startSpan(ctx, "known_devices.is_known")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.devices[knownDeviceKey{userID, fingerprint}]
+	return ok, nil
+}
+
+func (r *KnownDeviceRepository) Remember(ctx context.Context, d *entity.KnownDevice) (err error) {
+	ctx, span := startSpan(ctx, "known_devices.remember")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := knownDeviceKey{d.UserID, d.Fingerprint}
+	device := *d
+	if existing, ok := r.devices[key]; ok {
+		device.FirstSeenAt = existing.FirstSeenAt
+	}
+	r.devices[key] = device
+	return nil
+}
+
+func (r *KnownDeviceRepository) Forget(ctx context.Context, userID uuid.UUID, fingerprint string) (err error) {
+	ctx, span := startSpan(ctx, "known_devices.forget")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.devices, knownDeviceKey{userID, fingerprint})
+	return nil
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// LocatingAuditLogRepository locates the IP of every event before appending
+// it to another AuditLogRepository. Names are in English whatever the
+// request's locale, so the log reads the same for every admin. An event is
+// appended unlocated when the lookup fails.
+type LocatingAuditLogRepository struct {
+	next    contract.AuditLogRepository
+	locator contract.GeoLocator
+}
+
+var _ contract.AuditLogRepository = (*LocatingAuditLogRepository)(nil)
+
+func NewLocatingAuditLogRepository(next contract.AuditLogRepository, locator contract.GeoLocator) *LocatingAuditLogRepository {
+	return &LocatingAuditLogRepository{next: next, locator: locator}
+}
+
+func (r *LocatingAuditLogRepository) Append(ctx context.Context, e *entity.AuditEvent) (*entity.AuditEvent, error) {
+	if e.IP != "" && e.CountryCode == "" && e.Country == "" {
+		location, err := r.locator.Locate(ctxutil.WithLocale(ctx, "en"), e.IP)
+		if err != nil {
+			logger.Sample(ctxutil.Logger(ctx), "audit.locate_ip", 100).Warnw("locate audit event ip", "ip", e.IP, "error", err)
+		}
+		e.CountryCode, e.Country, e.City = location.CountryCode, location.Country, location.City
+	}
+	return r.next.Append(ctx, e)
+}
+
+func (r *LocatingAuditLogRepository) List(ctx context.Context, filter dto.AuditLogFilter, before *dto.ExportCursor, limit, offset int) ([]*entity.AuditEvent, int, error) {
+	return r.next.List(ctx, filter, before, limit, offset)
+}
+
+func (r *LocatingAuditLogRepository) ListAfter(ctx context.Context, filter dto.AuditLogFilter, after *dto.ExportCursor, limit int) ([]*entity.AuditEvent, error) {
+	return r.next.ListAfter(ctx, filter, after, limit)
+}
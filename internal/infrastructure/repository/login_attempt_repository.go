@@ -0,0 +1,94 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type LoginAttemptRepository struct {
+	mu sync.RWMutex
+	// attempts is kept in insertion order, which is chronological.
+	attempts []entity.LoginAttempt
+}
+
+var _ contract.LoginAttemptRepository = (*LoginAttemptRepository)(nil)
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{}
+}
+
+func (r *LoginAttemptRepository) Create(ctx context.Context, a *entity.LoginAttempt) (res *entity.LoginAttempt, err error) {
+	ctx, span := startSpan(ctx, "login_attempts.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newAttempt := *a
+	if newAttempt.ID == uuid.Nil {
+		newAttempt.ID = uuid.New()
+	}
+	r.attempts = append(r.attempts, newAttempt)
+	return &newAttempt, nil
+}
+
+func (r *LoginAttemptRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) (res []*entity.LoginAttempt, total int, err error) {
+	ctx, span := startSpan(ctx, "login_attempts.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.LoginAttempt, 0, limit)
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		if r.attempts[i].UserID != userID {
+			continue
+		}
+		if total >= offset && len(out) < limit {
+			a := r.attempts[i]
+			out = append(out, &a)
+		}
+		total++
+	}
+	return out, total, nil
+}
+
+func (r *LoginAttemptRepository) CountActiveUsers(ctx context.Context, since ...time.Time) (res []int, err error) {
+	ctx, span := startSpan(ctx, "login_attempts.count_active_users")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	earliest := slices.MinFunc(since, time.Time.Compare)
+	seen := make([]map[uuid.UUID]struct{}, len(since))
+	for i := range seen {
+		seen[i] = make(map[uuid.UUID]struct{})
+	}
+	// Newest first, stopping at the earliest bound.
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		a := r.attempts[i]
+		if a.CreatedAt.Before(earliest) {
+			break
+		}
+		if !a.Success || a.UserID == uuid.Nil {
+			continue
+		}
+		for j, t := range since {
+			if !a.CreatedAt.Before(t) {
+				seen[j][a.UserID] = struct{}{}
+			}
+		}
+	}
+	res = make([]int, len(since))
+	for i, users := range seen {
+		res[i] = len(users)
+	}
+	return res, nil
+}
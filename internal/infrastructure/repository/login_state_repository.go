@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// loginStateSweepEvery is how many states are saved between deletions of
+// the expired ones, which abandoned sign-ins leave behind.
+const loginStateSweepEvery = 256
+
+type LoginStateRepository struct {
+	mu     sync.Mutex
+	states map[string]entity.LoginState
+	saved  int
+}
+
+var _ contract.LoginStateRepository = (*LoginStateRepository)(nil)
+
+func NewLoginStateRepository() *LoginStateRepository {
+	return &LoginStateRepository{
+		states: make(map[string]entity.LoginState),
+	}
+}
+
+func (r *LoginStateRepository) Save(ctx context.Context, s *entity.LoginState) (err error) {
+	ctx, span := startSpan(ctx, "login_states.save")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saved++
+	if r.saved%loginStateSweepEvery == 0 {
+		r.sweep(time.Now())
+	}
+
+	r.states[s.State] = *s
+	return nil
+}
+
+func (r *LoginStateRepository) Consume(ctx context.Context, state string) (res *entity.LoginState, err error) {
+	ctx, span := startSpan(ctx, "login_states.consume")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.states[state]
+	if !ok {
+		return nil, errs.ErrInvalidLoginState
+	}
+	delete(r.states, state)
+	if !time.Now().Before(s.ExpiresAt) {
+		return nil, errs.ErrInvalidLoginState
+	}
+	return &s, nil
+}
+
+// sweep expects the caller to hold the lock.
+func (r *LoginStateRepository) sweep(now time.Time) {
+	for k, v := range r.states {
+		if !now.Before(v.ExpiresAt) {
+			delete(r.states, k)
+		}
+	}
+}
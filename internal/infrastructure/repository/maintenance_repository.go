@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+// MaintenanceRepository keeps the mode in memory, so a change made through
+// one instance applies to that instance only until it restarts.
+type MaintenanceRepository struct {
+	mu   sync.RWMutex
+	mode entity.MaintenanceMode
+}
+
+var _ contract.MaintenanceRepository = (*MaintenanceRepository)(nil)
+
+// NewMaintenanceRepository starts in the mode initial, e.g. the configured
+// one.
+func NewMaintenanceRepository(initial entity.MaintenanceMode) *MaintenanceRepository {
+	return &MaintenanceRepository{mode: initial}
+}
+
+// Get is not traced, as every write request calls it.
+func (r *MaintenanceRepository) Get(ctx context.Context) (*entity.MaintenanceMode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m := r.mode
+	return &m, nil
+}
+
+func (r *MaintenanceRepository) Set(ctx context.Context, m *entity.MaintenanceMode) (res *entity.MaintenanceMode, err error) {
+	ctx, span := startSpan(ctx, "maintenance.set")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mode = *m
+	updated := r.mode
+	return &updated, nil
+}
@@ -0,0 +1,210 @@
+package infrastructure
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type NotificationPreferencesRepository struct {
+	mu    sync.RWMutex
+	prefs map[uuid.UUID]entity.NotificationPreferences
+}
+
+var _ contract.NotificationPreferencesRepository = (*NotificationPreferencesRepository)(nil)
+
+func NewNotificationPreferencesRepository() *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{prefs: make(map[uuid.UUID]entity.NotificationPreferences)}
+}
+
+func (r *NotificationPreferencesRepository) Get(ctx context.Context, userID uuid.UUID) (res *entity.NotificationPreferences, err error) {
+	ctx, span := startSpan(ctx, "notification_preferences.get")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.prefs[userID]
+	if !ok {
+		return nil, errs.ErrNotificationPreferencesNotFound
+	}
+	return clonePreferences(p), nil
+}
+
+func (r *NotificationPreferencesRepository) Upsert(ctx context.Context, p *entity.NotificationPreferences) (res *entity.NotificationPreferences, err error) {
+	ctx, span := startSpan(ctx, "notification_preferences.upsert")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := clonePreferences(*p)
+	r.prefs[p.UserID] = *stored
+	return clonePreferences(*stored), nil
+}
+
+func clonePreferences(p entity.NotificationPreferences) *entity.NotificationPreferences {
+	p.Channels = maps.Clone(p.Channels)
+	p.Categories = maps.Clone(p.Categories)
+	if p.QuietHours != nil {
+		q := *p.QuietHours
+		p.QuietHours = &q
+	}
+	return &p
+}
+
+type PendingNotificationRepository struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID]entity.PendingNotification
+}
+
+var _ contract.PendingNotificationRepository = (*PendingNotificationRepository)(nil)
+
+func NewPendingNotificationRepository() *PendingNotificationRepository {
+	return &PendingNotificationRepository{pending: make(map[uuid.UUID]entity.PendingNotification)}
+}
+
+func (r *PendingNotificationRepository) Add(ctx context.Context, n *entity.PendingNotification) (err error) {
+	ctx, span := startSpan(ctx, "pending_notifications.add")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *n
+	if stored.ID == uuid.Nil {
+		stored.ID = uuid.New()
+	}
+	r.pending[stored.ID] = stored
+	return nil
+}
+
+func (r *PendingNotificationRepository) TakeDue(ctx context.Context, now time.Time, limit int) (res []*entity.PendingNotification, err error) {
+	ctx, span := startSpan(ctx, "pending_notifications.take_due")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]*entity.PendingNotification, 0)
+	for _, n := range r.pending {
+		if !n.DueAt.After(now) {
+			due = append(due, &n)
+		}
+	}
+	sort.Slice(due, func(a, b int) bool { return due[a].CreatedAt.Before(due[b].CreatedAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	for _, n := range due {
+		delete(r.pending, n.ID)
+	}
+	return due, nil
+}
+
+type InAppNotificationRepository struct {
+	mu sync.RWMutex
+	// inboxes holds each user's notifications, oldest first.
+	inboxes map[uuid.UUID][]entity.InAppNotification
+}
+
+var _ contract.InAppNotificationRepository = (*InAppNotificationRepository)(nil)
+
+func NewInAppNotificationRepository() *InAppNotificationRepository {
+	return &InAppNotificationRepository{inboxes: make(map[uuid.UUID][]entity.InAppNotification)}
+}
+
+func (r *InAppNotificationRepository) Create(ctx context.Context, n *entity.InAppNotification) (res *entity.InAppNotification, err error) {
+	ctx, span := startSpan(ctx, "in_app_notifications.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *n
+	if stored.ID == uuid.Nil {
+		stored.ID = uuid.New()
+	}
+	r.inboxes[stored.UserID] = append(r.inboxes[stored.UserID], stored)
+	return &stored, nil
+}
+
+func (r *InAppNotificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (res []*entity.InAppNotification, total int, err error) {
+	ctx, span := startSpan(ctx, "in_app_notifications.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	inbox := r.inboxes[userID]
+	out := make([]*entity.InAppNotification, 0, limit)
+	for i := len(inbox) - 1; i >= 0; i-- {
+		if unreadOnly && inbox[i].IsRead() {
+			continue
+		}
+		if total >= offset && len(out) < limit {
+			n := inbox[i]
+			out = append(out, &n)
+		}
+		total++
+	}
+	return out, total, nil
+}
+
+func (r *InAppNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (res int, err error) {
+	ctx, span := startSpan(ctx, "in_app_notifications.count_unread")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, n := range r.inboxes[userID] {
+		if !n.IsRead() {
+			res++
+		}
+	}
+	return res, nil
+}
+
+func (r *InAppNotificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "in_app_notifications.mark_read")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inbox := r.inboxes[userID]
+	for i := range inbox {
+		if inbox[i].ID == id {
+			if inbox[i].ReadAt == nil {
+				inbox[i].ReadAt = &at
+			}
+			return nil
+		}
+	}
+	return errs.ErrNotificationNotFound
+}
+
+func (r *InAppNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, at time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "in_app_notifications.mark_all_read")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inbox := r.inboxes[userID]
+	for i := range inbox {
+		if inbox[i].ReadAt == nil {
+			inbox[i].ReadAt = &at
+			res++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type OAuthClientRepository struct {
+	mu      sync.RWMutex
+	clients map[uuid.UUID]entity.OAuthClient
+}
+
+var _ contract.OAuthClientRepository = (*OAuthClientRepository)(nil)
+
+func NewOAuthClientRepository() *OAuthClientRepository {
+	return &OAuthClientRepository{
+		clients: make(map[uuid.UUID]entity.OAuthClient),
+	}
+}
+
+func (r *OAuthClientRepository) Create(ctx context.Context, c *entity.OAuthClient) (res *entity.OAuthClient, err error) {
+	ctx, span := startSpan(ctx, "oauth_clients.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newClient := *c
+	if newClient.ID == uuid.Nil {
+		newClient.ID = uuid.New()
+	}
+	newClient.Scopes = slices.Clone(c.Scopes)
+	newClient.RedirectURIs = slices.Clone(c.RedirectURIs)
+	r.clients[newClient.ID] = newClient
+	return &newClient, nil
+}
+
+func (r *OAuthClientRepository) GetByClientID(ctx context.Context, clientID string) (res *entity.OAuthClient, err error) {
+	ctx, span := startSpan(ctx, "oauth_clients.get_by_client_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.clients {
+		if c.ClientID == clientID {
+			return &c, nil
+		}
+	}
+	return nil, errs.ErrOAuthClientNotFound
+}
+
+func (r *OAuthClientRepository) List(ctx context.Context) (res []*entity.OAuthClient, err error) {
+	ctx, span := startSpan(ctx, "oauth_clients.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.OAuthClient, 0, len(r.clients))
+	for _, c := range r.clients {
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.After(out[b].CreatedAt) })
+	return out, nil
+}
+
+func (r *OAuthClientRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "oauth_clients.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.clients[id]
+	if !ok {
+		return errs.ErrOAuthClientNotFound
+	}
+	if c.RevokedAt == nil {
+		c.RevokedAt = &at
+		r.clients[id] = c
+	}
+	return nil
+}
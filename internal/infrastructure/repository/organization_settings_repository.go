@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type OrganizationSettingsRepository struct {
+	mu       sync.RWMutex
+	settings map[string]entity.OrganizationSettings
+}
+
+var _ contract.OrganizationSettingsRepository = (*OrganizationSettingsRepository)(nil)
+
+func NewOrganizationSettingsRepository() *OrganizationSettingsRepository {
+	return &OrganizationSettingsRepository{settings: make(map[string]entity.OrganizationSettings)}
+}
+
+// Get is not traced, as the requests of every tenant member call it.
+func (r *OrganizationSettingsRepository) Get(ctx context.Context, tenant string) (*entity.OrganizationSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.settings[tenant]
+	if !ok {
+		return &entity.OrganizationSettings{Tenant: tenant, AllowedCallbackDomains: []string{}}, nil
+	}
+	return copySettings(s), nil
+}
+
+func (r *OrganizationSettingsRepository) Put(ctx context.Context, s *entity.OrganizationSettings) (res *entity.OrganizationSettings, err error) {
+	ctx, span := startSpan(ctx, "organization_settings.put")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *copySettings(*s)
+	r.settings[s.Tenant] = stored
+	return copySettings(stored), nil
+}
+
+func copySettings(s entity.OrganizationSettings) *entity.OrganizationSettings {
+	s.AllowedCallbackDomains = slices.Clone(s.AllowedCallbackDomains)
+	if s.AllowedCallbackDomains == nil {
+		s.AllowedCallbackDomains = []string{}
+	}
+	return &s
+}
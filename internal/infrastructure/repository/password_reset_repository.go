@@ -0,0 +1,108 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type PasswordResetRepository struct {
+	mu     sync.RWMutex
+	resets map[uuid.UUID]entity.PasswordReset
+}
+
+var _ contract.PasswordResetRepository = (*PasswordResetRepository)(nil)
+
+func NewPasswordResetRepository() *PasswordResetRepository {
+	return &PasswordResetRepository{
+		resets: make(map[uuid.UUID]entity.PasswordReset),
+	}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, p *entity.PasswordReset) (res *entity.PasswordReset, err error) {
+	ctx, span := startSpan(ctx, "password_resets.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newReset := *p
+	if newReset.ID == uuid.Nil {
+		newReset.ID = uuid.New()
+	}
+	r.resets[newReset.ID] = newReset
+	return &newReset, nil
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (res *entity.PasswordReset, err error) {
+	ctx, span := startSpan(ctx, "password_resets.get_by_token_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.resets {
+		if p.TokenHash == tokenHash {
+			return &p, nil
+		}
+	}
+	return nil, errs.ErrInvalidResetToken
+}
+
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "password_resets.mark_used")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.resets[id]
+	if !ok || p.UsedAt != nil {
+		return errs.ErrInvalidResetToken
+	}
+	p.UsedAt = &at
+	r.resets[id] = p
+	return nil
+}
+
+func (r *PasswordResetRepository) ListByUser(ctx context.Context, userID uuid.UUID) (res []*entity.PasswordReset, err error) {
+	ctx, span := startSpan(ctx, "password_resets.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*entity.PasswordReset
+	for _, p := range r.resets {
+		if p.UserID == userID {
+			out = append(out, &p)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.PasswordReset) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return out, nil
+}
+
+func (r *PasswordResetRepository) DeleteExpired(ctx context.Context, cutoff time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "password_resets.delete_expired")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for id, p := range r.resets {
+		if p.ExpiresAt.Before(cutoff) {
+			delete(r.resets, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,125 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type PersonalAccessTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]entity.PersonalAccessToken
+	byHash map[string]uuid.UUID
+}
+
+var _ contract.PersonalAccessTokenRepository = (*PersonalAccessTokenRepository)(nil)
+
+func NewPersonalAccessTokenRepository() *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		tokens: make(map[uuid.UUID]entity.PersonalAccessToken),
+		byHash: make(map[string]uuid.UUID),
+	}
+}
+
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, t *entity.PersonalAccessToken) (res *entity.PersonalAccessToken, err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newToken := *t
+	if newToken.ID == uuid.Nil {
+		newToken.ID = uuid.New()
+	}
+	newToken.Scopes = slices.Clone(t.Scopes)
+	r.tokens[newToken.ID] = newToken
+	r.byHash[newToken.TokenHash] = newToken.ID
+	return &newToken, nil
+}
+
+func (r *PersonalAccessTokenRepository) GetByHash(ctx context.Context, hash string) (res *entity.PersonalAccessToken, err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.get_by_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tokens[r.byHash[hash]]
+	if !ok {
+		return nil, errs.ErrPersonalTokenNotFound
+	}
+	return &t, nil
+}
+
+func (r *PersonalAccessTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.PersonalAccessToken, err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return nil, errs.ErrPersonalTokenNotFound
+	}
+	return &t, nil
+}
+
+func (r *PersonalAccessTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) (res []*entity.PersonalAccessToken, err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.PersonalAccessToken, 0)
+	for _, t := range r.tokens {
+		if t.UserID == userID {
+			out = append(out, &t)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.After(out[b].CreatedAt) })
+	return out, nil
+}
+
+func (r *PersonalAccessTokenRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.touch")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return errs.ErrPersonalTokenNotFound
+	}
+	t.LastUsedAt = &at
+	r.tokens[id] = t
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "personal_access_tokens.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return errs.ErrPersonalTokenNotFound
+	}
+	if t.RevokedAt == nil {
+		t.RevokedAt = &at
+		r.tokens[id] = t
+	}
+	return nil
+}
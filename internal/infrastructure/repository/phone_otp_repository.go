@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type PhoneOTPRepository struct {
+	mu    sync.RWMutex
+	codes map[uuid.UUID]entity.PhoneOTP
+}
+
+var _ contract.PhoneOTPRepository = (*PhoneOTPRepository)(nil)
+
+func NewPhoneOTPRepository() *PhoneOTPRepository {
+	return &PhoneOTPRepository{
+		codes: make(map[uuid.UUID]entity.PhoneOTP),
+	}
+}
+
+func (r *PhoneOTPRepository) Create(ctx context.Context, o *entity.PhoneOTP) (res *entity.PhoneOTP, err error) {
+	ctx, span := startSpan(ctx, "phone_otps.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newCode := *o
+	if newCode.ID == uuid.Nil {
+		newCode.ID = uuid.New()
+	}
+	r.codes[newCode.ID] = newCode
+	return &newCode, nil
+}
+
+func (r *PhoneOTPRepository) Latest(ctx context.Context, phone string, purpose entity.OTPPurpose) (res *entity.PhoneOTP, err error) {
+	ctx, span := startSpan(ctx, "phone_otps.latest")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *entity.PhoneOTP
+	for _, o := range r.codes {
+		if o.Phone != phone || o.Purpose != purpose {
+			continue
+		}
+		if latest == nil || o.CreatedAt.After(latest.CreatedAt) {
+			latest = &o
+		}
+	}
+	if latest == nil {
+		return nil, errs.ErrInvalidOTP
+	}
+	return latest, nil
+}
+
+func (r *PhoneOTPRepository) CountSince(ctx context.Context, phone string, since time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "phone_otps.count_since")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, o := range r.codes {
+		if o.Phone == phone && !o.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *PhoneOTPRepository) RecordAttempt(ctx context.Context, id uuid.UUID) (res int, err error) {
+	ctx, span := startSpan(ctx, "phone_otps.record_attempt")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.codes[id]
+	if !ok {
+		return 0, errs.ErrInvalidOTP
+	}
+	o.Attempts++
+	r.codes[id] = o
+	return o.Attempts, nil
+}
+
+func (r *PhoneOTPRepository) Consume(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "phone_otps.consume")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.codes[id]
+	if !ok || o.ConsumedAt != nil {
+		return errs.ErrInvalidOTP
+	}
+	o.ConsumedAt = &at
+	r.codes[id] = o
+	return nil
+}
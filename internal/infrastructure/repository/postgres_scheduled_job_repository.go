@@ -0,0 +1,293 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/sqlq"
+)
+
+// PostgresScheduledJobRepository keeps jobs in the scheduled_jobs table,
+// where they outlive restarts and deploys:
+//
+//	CREATE TABLE scheduled_jobs (
+//		id         UUID PRIMARY KEY,
+//		kind       TEXT NOT NULL,
+//		key        TEXT,
+//		payload    JSONB,
+//		status     TEXT NOT NULL,
+//		run_at     TIMESTAMPTZ NOT NULL,
+//		attempts   INT NOT NULL,
+//		last_error TEXT NOT NULL,
+//		errors     JSONB,
+//		created_at TIMESTAMPTZ NOT NULL,
+//		updated_at TIMESTAMPTZ
+//	);
+//	CREATE INDEX scheduled_jobs_due_idx ON scheduled_jobs (run_at) WHERE status = 'pending';
+//	CREATE UNIQUE INDEX scheduled_jobs_pending_key_idx ON scheduled_jobs (key) WHERE status = 'pending';
+//	CREATE INDEX scheduled_jobs_status_idx ON scheduled_jobs (status, kind, updated_at DESC);
+//
+// Finished jobs are kept; delete them with a scheduled job if needed.
+type PostgresScheduledJobRepository struct {
+	db *sql.DB
+}
+
+var _ contract.ScheduledJobRepository = (*PostgresScheduledJobRepository)(nil)
+
+func NewPostgresScheduledJobRepository(db *sql.DB) *PostgresScheduledJobRepository {
+	return &PostgresScheduledJobRepository{db: db}
+}
+
+const scheduledJobColumns = `id, kind, key, payload, status, run_at, attempts, last_error, errors, created_at, updated_at`
+
+func (r *PostgresScheduledJobRepository) Schedule(ctx context.Context, j *entity.ScheduledJob) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.schedule")
+	defer func() { endSpan(span, res, err) }()
+
+	newJob := *j
+	if newJob.ID == uuid.Nil {
+		newJob.ID = uuid.New()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("schedule job: %w", err)
+	}
+	defer tx.Rollback()
+
+	if newJob.Key != "" {
+		if _, err := tx.ExecContext(ctx, `UPDATE scheduled_jobs SET status = $3, updated_at = $2
+			WHERE key = $1 AND status = 'pending'`, newJob.Key, newJob.CreatedAt, entity.SCHEDULED_JOB_CANCELED); err != nil {
+			return nil, fmt.Errorf("schedule job: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO scheduled_jobs (`+scheduledJobColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, scheduledJobArgs(&newJob)...); err != nil {
+		return nil, fmt.Errorf("schedule job: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("schedule job: %w", err)
+	}
+	return &newJob, nil
+}
+
+func (r *PostgresScheduledJobRepository) CancelByKey(ctx context.Context, key string, now time.Time) (res bool, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.cancel_by_key")
+	defer func() { endSpan(span, res, err) }()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE scheduled_jobs SET status = $3, updated_at = $2
+		WHERE key = $1 AND status = 'pending'`, key, now, entity.SCHEDULED_JOB_CANCELED)
+	if err != nil {
+		return false, fmt.Errorf("cancel job: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresScheduledJobRepository) Due(ctx context.Context, now time.Time, limit int) (res []*entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.due")
+	defer func() { endSpan(span, res, err) }()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+scheduledJobColumns+` FROM scheduled_jobs
+		WHERE status = 'pending' AND run_at <= $1
+		ORDER BY run_at LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		j, err := scanScheduledJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list due jobs: %w", err)
+		}
+		res = append(res, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list due jobs: %w", err)
+	}
+	return res, nil
+}
+
+func (r *PostgresScheduledJobRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	j, err := scanScheduledJob(r.db.QueryRowContext(ctx, `SELECT `+scheduledJobColumns+` FROM scheduled_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	return j, nil
+}
+
+func (r *PostgresScheduledJobRepository) GetPendingByKey(ctx context.Context, key string) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.get_pending_by_key")
+	defer func() { endSpan(span, res, err) }()
+
+	j, err := scanScheduledJob(r.db.QueryRowContext(ctx, `SELECT `+scheduledJobColumns+` FROM scheduled_jobs
+		WHERE key = $1 AND status = 'pending'`, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	return j, nil
+}
+
+// scheduledJobFilter selects the jobs of List; an empty Kind matches every
+// kind.
+type scheduledJobFilter struct {
+	Status string
+	Kind   string
+}
+
+func (f scheduledJobFilter) Apply(q *sqlq.Select) {
+	q.Where("status = ?", f.Status).
+		WhereIf(f.Kind != "", "kind = ?", f.Kind)
+}
+
+func (r *PostgresScheduledJobRepository) List(ctx context.Context, status, kind string, limit, offset int) (res []*entity.ScheduledJob, total int, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.list")
+	defer func() { endSpan(span, res, err) }()
+
+	q := sqlq.From("scheduled_jobs", scheduledJobColumns).
+		Filter(scheduledJobFilter{Status: status, Kind: kind}).
+		OrderBy("COALESCE(updated_at, created_at) DESC", "id DESC").
+		Page(limit, offset)
+
+	query, args := q.CountSQL()
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count jobs: %w", err)
+	}
+	query, args = q.SQL()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]*entity.ScheduledJob, 0)
+	for rows.Next() {
+		j, err := scanScheduledJob(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("list jobs: %w", err)
+		}
+		res = append(res, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list jobs: %w", err)
+	}
+	return res, total, nil
+}
+
+func (r *PostgresScheduledJobRepository) Update(ctx context.Context, j *entity.ScheduledJob) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.update")
+	defer func() { endSpan(span, res, err) }()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE scheduled_jobs SET
+		kind = $2, key = $3, payload = $4, status = $5, run_at = $6, attempts = $7,
+		last_error = $8, errors = $9, created_at = $10, updated_at = $11
+		WHERE id = $1`, scheduledJobArgs(j)...)
+	if err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	if n == 0 {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	updated := *j
+	return &updated, nil
+}
+
+func (r *PostgresScheduledJobRepository) Stats(ctx context.Context, since, now time.Time) (res []dto.JobKindStats, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.stats")
+	defer func() { endSpan(span, res, err) }()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT kind,
+			count(*) FILTER (WHERE status = 'pending'),
+			count(*) FILTER (WHERE status = 'pending' AND run_at <= $2),
+			count(*) FILTER (WHERE status = 'pending' AND attempts > 0),
+			count(*) FILTER (WHERE status = 'failed'),
+			COALESCE(EXTRACT(EPOCH FROM $2 - min(run_at) FILTER (WHERE status = 'pending' AND run_at <= $2)), 0),
+			count(*) FILTER (WHERE status = 'done' AND updated_at >= $1),
+			count(*) FILTER (WHERE status = 'failed' AND updated_at >= $1),
+			COALESCE(avg(EXTRACT(EPOCH FROM updated_at - run_at)) FILTER (WHERE status = 'done' AND updated_at >= $1), 0),
+			COALESCE(max(EXTRACT(EPOCH FROM updated_at - run_at)) FILTER (WHERE status = 'done' AND updated_at >= $1), 0)
+		FROM scheduled_jobs
+		WHERE status IN ('pending', 'failed') OR updated_at >= $1
+		GROUP BY kind ORDER BY kind`, since, now)
+	if err != nil {
+		return nil, fmt.Errorf("job stats: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]dto.JobKindStats, 0)
+	for rows.Next() {
+		var s dto.JobKindStats
+		err := rows.Scan(&s.Kind, &s.Pending, &s.Due, &s.Retrying, &s.Dead, &s.OldestDueSeconds,
+			&s.Done, &s.Failed, &s.AvgLatencySeconds, &s.MaxLatencySeconds)
+		if err != nil {
+			return nil, fmt.Errorf("job stats: %w", err)
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("job stats: %w", err)
+	}
+	return res, nil
+}
+
+func scheduledJobArgs(j *entity.ScheduledJob) []any {
+	key := sql.NullString{String: j.Key, Valid: j.Key != ""}
+	var payload, history []byte
+	if len(j.Payload) > 0 {
+		payload = j.Payload
+	}
+	if len(j.Errors) > 0 {
+		// A slice of plain structs always encodes.
+		history, _ = json.Marshal(j.Errors)
+	}
+	return []any{j.ID, j.Kind, key, payload, j.Status, j.RunAt, j.Attempts, j.LastError, history, j.CreatedAt, j.UpdatedAt}
+}
+
+func scanScheduledJob(row interface{ Scan(dest ...any) error }) (*entity.ScheduledJob, error) {
+	var (
+		j         entity.ScheduledJob
+		key       sql.NullString
+		payload   []byte
+		history   []byte
+		updatedAt sql.NullTime
+	)
+	err := row.Scan(&j.ID, &j.Kind, &key, &payload, &j.Status, &j.RunAt, &j.Attempts, &j.LastError,
+		&history, &j.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > 0 {
+		if err := json.Unmarshal(history, &j.Errors); err != nil {
+			return nil, fmt.Errorf("decode job errors: %w", err)
+		}
+	}
+	j.Key = key.String
+	j.Payload = payload
+	if updatedAt.Valid {
+		j.UpdatedAt = &updatedAt.Time
+	}
+	return &j, nil
+}
@@ -0,0 +1,266 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// PostgresSessionRepository keeps sessions in the sessions table, where they
+// outlive restarts and can be queried for audits:
+//
+//	CREATE TABLE sessions (
+//		id                 UUID PRIMARY KEY,
+//		user_id            UUID NOT NULL,
+//		refresh_token_id   TEXT NOT NULL,
+//		device_fingerprint TEXT NOT NULL,
+//		user_agent         TEXT NOT NULL,
+//		ip                 TEXT NOT NULL,
+//		created_at         TIMESTAMPTZ NOT NULL,
+//		last_seen_at       TIMESTAMPTZ NOT NULL,
+//		expires_at         TIMESTAMPTZ NOT NULL,
+//		revoked_at         TIMESTAMPTZ,
+//		impersonator_id    UUID
+//	);
+//	CREATE INDEX sessions_user_id_idx ON sessions (user_id, last_seen_at DESC);
+//
+// Expired sessions are kept until the auth.purge_expired_tokens admin task
+// deletes them.
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+var _ contract.SessionRepository = (*PostgresSessionRepository)(nil)
+
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, refresh_token_id, device_fingerprint, user_agent, ip,
+	created_at, last_seen_at, expires_at, revoked_at, impersonator_id`
+
+func (r *PostgresSessionRepository) Create(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.create")
+	defer func() { endSpan(span, res, err) }()
+
+	newSession := *s
+	if newSession.ID == uuid.Nil {
+		newSession.ID = uuid.New()
+	}
+	_, err = r.db.ExecContext(ctx, `INSERT INTO sessions (`+sessionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, sessionArgs(&newSession)...)
+	if err != nil {
+		return nil, fmt.Errorf("insert session: %w", err)
+	}
+	return &newSession, nil
+}
+
+func (r *PostgresSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	s, err := scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	return s, nil
+}
+
+func (r *PostgresSessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) (res []*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC`, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.Session, 0)
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	return out, nil
+}
+
+func (r *PostgresSessionRepository) ListActiveByUsers(ctx context.Context, userIDs []uuid.UUID) (res map[uuid.UUID][]*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_users")
+	defer func() { endSpan(span, res, err) }()
+
+	res = make(map[uuid.UUID][]*entity.Session)
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = ANY($1::uuid[]) AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC`, "{"+strings.Join(ids, ",")+"}", time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions: %w", err)
+		}
+		res[s.UserID] = append(res[s.UserID], s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	return res, nil
+}
+
+func (r *PostgresSessionRepository) Update(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.update")
+	defer func() { endSpan(span, res, err) }()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions SET
+		user_id = $2, refresh_token_id = $3, device_fingerprint = $4, user_agent = $5, ip = $6,
+		created_at = $7, last_seen_at = $8, expires_at = $9, revoked_at = $10, impersonator_id = $11
+		WHERE id = $1`, sessionArgs(s)...)
+	if err := affectedOne(result, err); err != nil {
+		return nil, err
+	}
+	updated := *s
+	return &updated, nil
+}
+
+func (r *PostgresSessionRepository) Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.touch")
+	defer func() { endSpan(span, nil, err) }()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions
+		SET last_seen_at = $2, ip = COALESCE(NULLIF($3, ''), ip)
+		WHERE id = $1`, id, at, ip)
+	return affectedOne(result, err)
+}
+
+func (r *PostgresSessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	// The row is matched even when already revoked, so only a missing
+	// session is an error.
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions
+		SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id = $1`, id, at)
+	return affectedOne(result, err)
+}
+
+func (r *PostgresSessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID, at time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke_all_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (r *PostgresSessionRepository) DeleteExpired(ctx context.Context, cutoff time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "sessions.delete_expired")
+	defer func() { endSpan(span, res, err) }()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func sessionArgs(s *entity.Session) []any {
+	var impersonator uuid.NullUUID
+	if s.ImpersonatorID != nil {
+		impersonator = uuid.NullUUID{UUID: *s.ImpersonatorID, Valid: true}
+	}
+	return []any{s.ID, s.UserID, s.RefreshTokenID, s.DeviceFingerprint, s.UserAgent, s.IP,
+		s.CreatedAt, s.LastSeenAt, s.ExpiresAt, s.RevokedAt, impersonator}
+}
+
+func scanSession(row interface{ Scan(dest ...any) error }) (*entity.Session, error) {
+	var (
+		s            entity.Session
+		revokedAt    sql.NullTime
+		impersonator uuid.NullUUID
+	)
+	err := row.Scan(&s.ID, &s.UserID, &s.RefreshTokenID, &s.DeviceFingerprint, &s.UserAgent, &s.IP,
+		&s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &revokedAt, &impersonator)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		s.RevokedAt = &revokedAt.Time
+	}
+	if impersonator.Valid {
+		s.ImpersonatorID = &impersonator.UUID
+	}
+	return &s, nil
+}
+
+// affectedOne maps an UPDATE of a single session by ID to ErrSessionNotFound
+// when no row matched.
+func affectedOne(result sql.Result, err error) error {
+	if err != nil {
+		return fmt.Errorf("update session: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update session: %w", err)
+	}
+	if n == 0 {
+		return errs.ErrSessionNotFound
+	}
+	return nil
+}
+
+// WarmUp opens n connections at once, so that as many as the pool keeps idle
+// are ready for the first requests.
+func (r *PostgresSessionRepository) WarmUp(ctx context.Context, n int) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+	for range n {
+		c, err := r.db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("open session database connection: %w", err)
+		}
+		conns = append(conns, c)
+		if err := c.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping session database: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/pkg/sqltest"
+)
+
+// sessionsTable is the schema of PostgresSessionRepository. It is created
+// as a temporary table, which shadows a sessions table the test database
+// may already have, inside the transaction sqltest rolls back.
+const sessionsTable = `CREATE TEMPORARY TABLE sessions (
+	id                 UUID PRIMARY KEY,
+	user_id            UUID NOT NULL,
+	refresh_token_id   TEXT NOT NULL,
+	device_fingerprint TEXT NOT NULL,
+	user_agent         TEXT NOT NULL,
+	ip                 TEXT NOT NULL,
+	created_at         TIMESTAMPTZ NOT NULL,
+	last_seen_at       TIMESTAMPTZ NOT NULL,
+	expires_at         TIMESTAMPTZ NOT NULL,
+	revoked_at         TIMESTAMPTZ,
+	impersonator_id    UUID
+)`
+
+// TestPostgresSessionRepository runs against the database at
+// TEST_DATABASE_URL; see sqltest.Open. The subtests share the table, which
+// is fine as each works on users of its own.
+func TestPostgresSessionRepository(t *testing.T) {
+	db := sqltest.Open(t)
+	if _, err := db.ExecContext(context.Background(), sessionsTable); err != nil {
+		t.Fatalf("create sessions table: %v", err)
+	}
+	repo := NewPostgresSessionRepository(db)
+	testSessionRepository(t, func(t *testing.T) contract.SessionRepository { return repo })
+}
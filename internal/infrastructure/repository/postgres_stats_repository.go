@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// PostgresStatsRepository keeps the stats views in the stats_views table,
+// so the instances share the leader's last refresh:
+//
+//	CREATE TABLE stats_views (
+//		name         TEXT PRIMARY KEY,
+//		data         JSONB NOT NULL,
+//		refreshed_at TIMESTAMPTZ NOT NULL
+//	);
+type PostgresStatsRepository struct {
+	db *sql.DB
+}
+
+var _ contract.StatsRepository = (*PostgresStatsRepository)(nil)
+
+func NewPostgresStatsRepository(db *sql.DB) *PostgresStatsRepository {
+	return &PostgresStatsRepository{db: db}
+}
+
+func (r *PostgresStatsRepository) Get(ctx context.Context, name string) (res *entity.StatsView, err error) {
+	ctx, span := startSpan(ctx, "stats_views.get")
+	defer func() { endSpan(span, res, err) }()
+
+	v := entity.StatsView{Name: name}
+	err = r.db.QueryRowContext(ctx, `SELECT data, refreshed_at FROM stats_views WHERE name = $1`, name).
+		Scan(&v.Data, &v.RefreshedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.ErrStatsViewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get stats view: %w", err)
+	}
+	return &v, nil
+}
+
+func (r *PostgresStatsRepository) Put(ctx context.Context, v *entity.StatsView) (err error) {
+	ctx, span := startSpan(ctx, "stats_views.put")
+	defer func() { endSpan(span, nil, err) }()
+
+	_, err = r.db.ExecContext(ctx, `INSERT INTO stats_views (name, data, refreshed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data, refreshed_at = EXCLUDED.refreshed_at`,
+		v.Name, []byte(v.Data), v.RefreshedAt)
+	if err != nil {
+		return fmt.Errorf("put stats view: %w", err)
+	}
+	return nil
+}
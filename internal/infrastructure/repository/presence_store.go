@@ -0,0 +1,125 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+type userPresence struct {
+	// conns maps the live connections to when they expire.
+	conns    map[string]time.Time
+	lastSeen time.Time
+	// announced is set from the Beat that reported the user online until
+	// the Leave or Expire that reports them offline.
+	announced bool
+}
+
+// online reports whether any connection is live at now, dropping the
+// expired ones.
+func (p *userPresence) online(now time.Time) bool {
+	for conn, expiresAt := range p.conns {
+		if !now.Before(expiresAt) {
+			delete(p.conns, conn)
+		}
+	}
+	return len(p.conns) > 0
+}
+
+// PresenceStore keeps presence per instance, for single-instance setups.
+type PresenceStore struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*userPresence
+}
+
+var _ contract.PresenceStore = (*PresenceStore)(nil)
+
+func NewPresenceStore() *PresenceStore {
+	return &PresenceStore{users: make(map[uuid.UUID]*userPresence)}
+}
+
+func (s *PresenceStore) Beat(ctx context.Context, userID uuid.UUID, conn string, now, expiresAt time.Time) (cameOnline bool, err error) {
+	ctx, span := startSpan(ctx, "presence.beat")
+	defer func() { endSpan(span, nil, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.users[userID]
+	if !ok {
+		p = &userPresence{conns: make(map[string]time.Time)}
+		s.users[userID] = p
+	}
+	cameOnline = !p.announced
+	p.conns[conn] = expiresAt
+	p.lastSeen = now
+	p.announced = true
+	return cameOnline, nil
+}
+
+func (s *PresenceStore) Leave(ctx context.Context, userID uuid.UUID, conn string, now time.Time) (wentOffline bool, err error) {
+	ctx, span := startSpan(ctx, "presence.leave")
+	defer func() { endSpan(span, nil, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.users[userID]
+	if !ok {
+		return false, nil
+	}
+	delete(p.conns, conn)
+	p.lastSeen = now
+	if !p.announced || p.online(now) {
+		return false, nil
+	}
+	p.announced = false
+	return true, nil
+}
+
+func (s *PresenceStore) Get(ctx context.Context, userID uuid.UUID, now time.Time) (res *dto.Presence, err error) {
+	ctx, span := startSpan(ctx, "presence.get")
+	defer func() { endSpan(span, res, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res = &dto.Presence{UserID: userID}
+	p, ok := s.users[userID]
+	if !ok {
+		return res, nil
+	}
+	// Expired connections are left for Expire, which reports them.
+	for _, expiresAt := range p.conns {
+		if now.Before(expiresAt) {
+			res.Online = true
+			break
+		}
+	}
+	lastSeen := p.lastSeen
+	res.LastSeenAt = &lastSeen
+	return res, nil
+}
+
+func (s *PresenceStore) Expire(ctx context.Context, now time.Time, limit int) (res []uuid.UUID, err error) {
+	ctx, span := startSpan(ctx, "presence.expire")
+	defer func() { endSpan(span, res, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, p := range s.users {
+		if len(res) >= limit {
+			break
+		}
+		if p.announced && !p.online(now) {
+			p.announced = false
+			res = append(res, userID)
+		}
+	}
+	return res, nil
+}
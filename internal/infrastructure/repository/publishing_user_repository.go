@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// PublishingUserRepository publishes a UserChangedEvent on the event bus
+// after every successful write to another UserRepository, feeding the read
+// models.
+type PublishingUserRepository struct {
+	next contract.UserRepository
+	bus  *eventbus.Bus
+}
+
+var _ contract.UserRepository = (*PublishingUserRepository)(nil)
+
+func NewPublishingUserRepository(next contract.UserRepository, bus *eventbus.Bus) *PublishingUserRepository {
+	return &PublishingUserRepository{next: next, bus: bus}
+}
+
+func (r *PublishingUserRepository) Create(ctx context.Context, u *entity.User) (*entity.User, error) {
+	created, err := r.next.Create(ctx, u)
+	if err == nil {
+		r.publish(ctx, created)
+	}
+	return created, err
+}
+
+func (r *PublishingUserRepository) Update(ctx context.Context, u *entity.User) (*entity.User, error) {
+	updated, err := r.next.Update(ctx, u)
+	if err == nil {
+		r.publish(ctx, updated)
+	}
+	return updated, err
+}
+
+func (r *PublishingUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.next.Delete(ctx, id)
+	if err == nil {
+		r.publishEvent(ctx, dto.UserChangedEvent{User: entity.User{ID: id}, Deleted: true})
+	}
+	return err
+}
+
+func (r *PublishingUserRepository) publish(ctx context.Context, u *entity.User) {
+	e := dto.UserChangedEvent{User: *u}
+	e.User.HashedPassword = ""
+	r.publishEvent(ctx, e)
+}
+
+func (r *PublishingUserRepository) publishEvent(ctx context.Context, e dto.UserChangedEvent) {
+	if err := r.bus.Publish(ctx, dto.USER_CHANGED_TOPIC, e); err != nil {
+		logger.Sample(ctxutil.Logger(ctx), "users.publish", 100).Warnw("drop user changed event",
+			"user_id", e.User.ID, "error", err)
+	}
+}
+
+func (r *PublishingUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *PublishingUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error) {
+	return r.next.GetByIDs(ctx, ids)
+}
+
+func (r *PublishingUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.next.GetByEmail(ctx, email)
+}
+
+func (r *PublishingUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.next.GetByUsername(ctx, username)
+}
+
+func (r *PublishingUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	return r.next.GetByPhone(ctx, phone)
+}
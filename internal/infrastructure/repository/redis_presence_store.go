@@ -0,0 +1,156 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+)
+
+// presenceLastSeenRetention is how long the last sighting of a user who no
+// longer connects is remembered.
+const presenceLastSeenRetention = 30 * 24 * time.Hour
+
+// A user's connections are a sorted set scored by expiry, and the users
+// announced online a sorted set scored by when their last connection
+// expires, which Expire scans. Times are Unix milliseconds.
+const (
+	// beatPresenceScript gets the connections, online and last seen keys.
+	beatPresenceScript = `
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[3])
+local announced = redis.call("ZSCORE", KEYS[2], ARGV[4])
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+local last = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")[2]
+redis.call("PEXPIREAT", KEYS[1], last)
+redis.call("ZADD", KEYS[2], last, ARGV[4])
+redis.call("SET", KEYS[3], ARGV[3], "PX", ARGV[5])
+if announced then
+	return 0
+end
+return 1`
+	leavePresenceScript = `
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[2])
+redis.call("SET", KEYS[3], ARGV[2], "PX", ARGV[4])
+local last = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")[2]
+if last then
+	redis.call("PEXPIREAT", KEYS[1], last)
+	redis.call("ZADD", KEYS[2], "XX", last, ARGV[3])
+	return 0
+end
+return redis.call("ZREM", KEYS[2], ARGV[3])`
+	getPresenceScript = `
+return {redis.call("ZCOUNT", KEYS[1], "(" .. ARGV[1], "+inf"), redis.call("GET", KEYS[2])}`
+	expirePresenceScript = `
+local users = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+if #users > 0 then
+	redis.call("ZREM", KEYS[1], unpack(users))
+end
+return users`
+)
+
+// RedisPresenceStore shares presence between the instances. Its scripts
+// reach several users' keys, so it needs a single Redis instance rather
+// than a cluster.
+type RedisPresenceStore struct {
+	client RedisScripter
+	prefix string
+}
+
+var _ contract.PresenceStore = (*RedisPresenceStore)(nil)
+
+func NewRedisPresenceStore(client RedisScripter, prefix string) *RedisPresenceStore {
+	return &RedisPresenceStore{client: client, prefix: prefix}
+}
+
+func (s *RedisPresenceStore) connsKey(userID uuid.UUID) string {
+	return s.prefix + "conns:" + userID.String()
+}
+
+func (s *RedisPresenceStore) seenKey(userID uuid.UUID) string {
+	return s.prefix + "seen:" + userID.String()
+}
+
+func (s *RedisPresenceStore) onlineKey() string {
+	return s.prefix + "online"
+}
+
+func (s *RedisPresenceStore) Beat(ctx context.Context, userID uuid.UUID, conn string, now, expiresAt time.Time) (cameOnline bool, err error) {
+	ctx, span := startSpan(ctx, "presence.beat")
+	defer func() { endSpan(span, nil, err) }()
+
+	reply, err := s.client.Eval(ctx, beatPresenceScript,
+		[]string{s.connsKey(userID), s.onlineKey(), s.seenKey(userID)},
+		conn, expiresAt.UnixMilli(), now.UnixMilli(), userID.String(), presenceLastSeenRetention.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("beat presence: %w", err)
+	}
+	return reply == int64(1), nil
+}
+
+func (s *RedisPresenceStore) Leave(ctx context.Context, userID uuid.UUID, conn string, now time.Time) (wentOffline bool, err error) {
+	ctx, span := startSpan(ctx, "presence.leave")
+	defer func() { endSpan(span, nil, err) }()
+
+	reply, err := s.client.Eval(ctx, leavePresenceScript,
+		[]string{s.connsKey(userID), s.onlineKey(), s.seenKey(userID)},
+		conn, now.UnixMilli(), userID.String(), presenceLastSeenRetention.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("leave presence: %w", err)
+	}
+	return reply == int64(1), nil
+}
+
+func (s *RedisPresenceStore) Get(ctx context.Context, userID uuid.UUID, now time.Time) (res *dto.Presence, err error) {
+	ctx, span := startSpan(ctx, "presence.get")
+	defer func() { endSpan(span, res, err) }()
+
+	reply, err := s.client.Eval(ctx, getPresenceScript,
+		[]string{s.connsKey(userID), s.seenKey(userID)}, now.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("get presence: %w", err)
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) != 2 {
+		return nil, fmt.Errorf("get presence: unexpected redis reply %T", reply)
+	}
+	live, _ := items[0].(int64)
+	res = &dto.Presence{UserID: userID, Online: live > 0}
+	if raw, ok := items[1].(string); ok {
+		ms, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("get presence: last seen %q: %w", raw, err)
+		}
+		lastSeen := time.UnixMilli(ms).UTC()
+		res.LastSeenAt = &lastSeen
+	}
+	return res, nil
+}
+
+func (s *RedisPresenceStore) Expire(ctx context.Context, now time.Time, limit int) (res []uuid.UUID, err error) {
+	ctx, span := startSpan(ctx, "presence.expire")
+	defer func() { endSpan(span, res, err) }()
+
+	reply, err := s.client.Eval(ctx, expirePresenceScript, []string{s.onlineKey()}, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("expire presence: %w", err)
+	}
+	items, ok := reply.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expire presence: unexpected redis reply %T", reply)
+	}
+	res = make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		raw, _ := item.(string)
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
@@ -0,0 +1,339 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// RedisScripter is the subset of a Redis client the Redis-backed stores
+// need, as in lock.RedisScripter; resp.Client implements it.
+type RedisScripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// The session scripts read and rewrite the JSON of a session in place, so
+// concurrent updates of different fields do not overwrite each other. They
+// reach session keys through the user index, so the store needs a single
+// Redis instance rather than a cluster.
+const (
+	getSessionScript = `return redis.call("GET", KEYS[1])`
+	// putSessionScript stores a session until it expires and indexes it
+	// under its user; with ARGV[4] set it only replaces an existing one.
+	putSessionScript = `
+if ARGV[4] == "1" and redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PXAT", ARGV[2])
+redis.call("ZADD", KEYS[2], ARGV[2], ARGV[3])
+local ttl = redis.call("PTTL", KEYS[2])
+if ttl < 0 or tonumber(ARGV[5]) + ttl < tonumber(ARGV[2]) then
+	redis.call("PEXPIREAT", KEYS[2], ARGV[2])
+end
+return 1`
+	touchSessionScript = `
+local v = redis.call("GET", KEYS[1])
+if not v then
+	return 0
+end
+local s = cjson.decode(v)
+s.last_seen_at = ARGV[1]
+if ARGV[2] ~= "" then
+	s.ip = ARGV[2]
+end
+redis.call("SET", KEYS[1], cjson.encode(s), "KEEPTTL")
+return 1`
+	revokeSessionScript = `
+local v = redis.call("GET", KEYS[1])
+if not v then
+	return 0
+end
+local s = cjson.decode(v)
+if s.revoked_at == nil or s.revoked_at == cjson.null then
+	s.revoked_at = ARGV[1]
+	redis.call("SET", KEYS[1], cjson.encode(s), "KEEPTTL")
+end
+return 1`
+	revokeUserSessionsScript = `
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[3])
+local n = 0
+for _, id in ipairs(redis.call("ZRANGE", KEYS[1], 0, -1)) do
+	local key = ARGV[2] .. id
+	local v = redis.call("GET", key)
+	if v then
+		local s = cjson.decode(v)
+		if s.revoked_at == nil or s.revoked_at == cjson.null then
+			s.revoked_at = ARGV[1]
+			redis.call("SET", key, cjson.encode(s), "KEEPTTL")
+			n = n + 1
+		end
+	end
+end
+return n`
+	listUserSessionsScript = `
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+local out = {}
+for _, id in ipairs(redis.call("ZRANGE", KEYS[1], 0, -1)) do
+	local v = redis.call("GET", ARGV[2] .. id)
+	if v then
+		table.insert(out, v)
+	end
+end
+return out`
+	listUsersSessionsScript = `
+local out = {}
+for _, key in ipairs(KEYS) do
+	redis.call("ZREMRANGEBYSCORE", key, "-inf", ARGV[1])
+	for _, id in ipairs(redis.call("ZRANGE", key, 0, -1)) do
+		local v = redis.call("GET", ARGV[2] .. id)
+		if v then
+			table.insert(out, v)
+		end
+	end
+end
+return out`
+)
+
+// RedisSessionRepository keeps each session as JSON under its own key,
+// expiring with the session, and indexes a user's sessions in a sorted set
+// scored by expiry. It needs Redis 6.2 or later.
+type RedisSessionRepository struct {
+	client RedisScripter
+	prefix string
+}
+
+var _ contract.SessionRepository = (*RedisSessionRepository)(nil)
+
+func NewRedisSessionRepository(client RedisScripter, prefix string) *RedisSessionRepository {
+	return &RedisSessionRepository{client: client, prefix: prefix}
+}
+
+// redisSession is the stored form of a session, which unlike its API form
+// includes the refresh token ID.
+type redisSession struct {
+	entity.Session
+	RefreshTokenID string `json:"refresh_token_id"`
+}
+
+func (r *RedisSessionRepository) sessionKey(id uuid.UUID) string {
+	return r.prefix + "session:" + id.String()
+}
+
+func (r *RedisSessionRepository) userKey(userID uuid.UUID) string {
+	return r.prefix + "user_sessions:" + userID.String()
+}
+
+func (r *RedisSessionRepository) Create(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.create")
+	defer func() { endSpan(span, res, err) }()
+
+	newSession := *s
+	if newSession.ID == uuid.Nil {
+		newSession.ID = uuid.New()
+	}
+	if _, err := r.put(ctx, &newSession, false); err != nil {
+		return nil, err
+	}
+	return &newSession, nil
+}
+
+func (r *RedisSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	reply, err := r.client.Eval(ctx, getSessionScript, []string{r.sessionKey(id)})
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if reply == nil {
+		return nil, errs.ErrSessionNotFound
+	}
+	return decodeRedisSession(reply)
+}
+
+func (r *RedisSessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) (res []*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	now := time.Now()
+	reply, err := r.client.Eval(ctx, listUserSessionsScript, []string{r.userKey(userID)},
+		now.UnixMilli(), r.prefix+"session:")
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	items, _ := reply.([]any)
+	out := make([]*entity.Session, 0, len(items))
+	for _, item := range items {
+		s, err := decodeRedisSession(item)
+		if err != nil {
+			return nil, err
+		}
+		if s.IsActive(now) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastSeenAt.After(out[j].LastSeenAt) })
+	return out, nil
+}
+
+// ListActiveByUsers reads the sessions of all the users in one script.
+func (r *RedisSessionRepository) ListActiveByUsers(ctx context.Context, userIDs []uuid.UUID) (res map[uuid.UUID][]*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_users")
+	defer func() { endSpan(span, res, err) }()
+
+	res = make(map[uuid.UUID][]*entity.Session)
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = r.userKey(id)
+	}
+	now := time.Now()
+	reply, err := r.client.Eval(ctx, listUsersSessionsScript, keys, now.UnixMilli(), r.prefix+"session:")
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	items, _ := reply.([]any)
+	for _, item := range items {
+		s, err := decodeRedisSession(item)
+		if err != nil {
+			return nil, err
+		}
+		if s.IsActive(now) {
+			res[s.UserID] = append(res[s.UserID], s)
+		}
+	}
+	for _, sessions := range res {
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	}
+	return res, nil
+}
+
+func (r *RedisSessionRepository) Update(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.update")
+	defer func() { endSpan(span, res, err) }()
+
+	updated := *s
+	ok, err := r.put(ctx, &updated, true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrSessionNotFound
+	}
+	return &updated, nil
+}
+
+func (r *RedisSessionRepository) Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.touch")
+	defer func() { endSpan(span, nil, err) }()
+
+	reply, err := r.client.Eval(ctx, touchSessionScript, []string{r.sessionKey(id)}, formatRedisTime(at), ip)
+	if err != nil {
+		return fmt.Errorf("touch session: %w", err)
+	}
+	if reply != int64(1) {
+		return errs.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *RedisSessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	reply, err := r.client.Eval(ctx, revokeSessionScript, []string{r.sessionKey(id)}, formatRedisTime(at))
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	if reply != int64(1) {
+		return errs.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *RedisSessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID, at time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke_all_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	reply, err := r.client.Eval(ctx, revokeUserSessionsScript, []string{r.userKey(userID)},
+		formatRedisTime(at), r.prefix+"session:", time.Now().UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
+
+// DeleteExpired deletes nothing: Redis expires the sessions, and the user
+// indexes prune them on their next read.
+func (r *RedisSessionRepository) DeleteExpired(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+// put stores s; with replace it reports false, storing nothing, when s does
+// not exist.
+func (r *RedisSessionRepository) put(ctx context.Context, s *entity.Session, replace bool) (bool, error) {
+	data, err := json.Marshal(redisSession{Session: *s, RefreshTokenID: s.RefreshTokenID})
+	if err != nil {
+		return false, err
+	}
+	flag := "0"
+	if replace {
+		flag = "1"
+	}
+	reply, err := r.client.Eval(ctx, putSessionScript, []string{r.sessionKey(s.ID), r.userKey(s.UserID)},
+		string(data), s.ExpiresAt.UnixMilli(), s.ID.String(), flag, time.Now().UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("store session: %w", err)
+	}
+	return reply == int64(1), nil
+}
+
+func decodeRedisSession(reply any) (*entity.Session, error) {
+	data, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	var stored redisSession
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	s := stored.Session
+	s.RefreshTokenID = stored.RefreshTokenID
+	return &s, nil
+}
+
+// formatRedisTime formats t as encoding/json does, so the scripts can write
+// it into the stored JSON.
+func formatRedisTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// WarmUp runs n lookups at once, which opens as many pooled connections and
+// has the server cache the lookup script.
+func (r *RedisSessionRepository) WarmUp(ctx context.Context, n int) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.client.Eval(ctx, getSessionScript, []string{r.sessionKey(uuid.Nil)}); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	return <-errCh
+}
@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/pkg/resp"
+)
+
+// TestRedisSessionRepository runs against the Redis 6.2+ at
+// TEST_REDIS_ADDR, e.g. localhost:6379, under a prefix of its own.
+func TestRedisSessionRepository(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+	client := resp.NewClient(resp.ClientArgs{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+
+	testSessionRepository(t, func(t *testing.T) contract.SessionRepository {
+		prefix := "test:" + uuid.NewString() + ":"
+		t.Cleanup(func() {
+			ctx := context.Background()
+			keys, err := client.Do(ctx, "KEYS", prefix+"*")
+			if err != nil {
+				t.Errorf("list test keys: %v", err)
+				return
+			}
+			for _, key := range keys.([]any) {
+				if _, err := client.Do(ctx, "DEL", key); err != nil {
+					t.Errorf("delete test key: %v", err)
+				}
+			}
+		})
+		return NewRedisSessionRepository(client, prefix)
+	})
+}
@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/haidang666/go-app/pkg/resilience"
+	"github.com/haidang666/go-app/pkg/resp"
+)
+
+// ResilientRedisClient runs the commands of a resp.Client through a
+// resilience policy, so callers of an unreachable Redis fail fast on the
+// open breaker instead of each waiting out a dial timeout. Subscribe is
+// long-lived and is passed through.
+type ResilientRedisClient struct {
+	client *resp.Client
+	policy *resilience.Policy
+}
+
+var (
+	_ RedisScripter = (*ResilientRedisClient)(nil)
+	_ RedisPubSub   = (*ResilientRedisClient)(nil)
+)
+
+func NewResilientRedisClient(client *resp.Client, policy *resilience.Policy) *ResilientRedisClient {
+	return &ResilientRedisClient{client: client, policy: policy}
+}
+
+// IsRedisFailure reports whether err means Redis itself is unusable; error
+// replies such as WRONGTYPE come from a reachable server.
+func IsRedisFailure(err error) bool {
+	var reply resp.Error
+	return err != nil &&
+		!errors.As(err, &reply) &&
+		!errors.Is(err, context.Canceled)
+}
+
+func (c *ResilientRedisClient) Do(ctx context.Context, args ...any) (any, error) {
+	return guard(ctx, c.policy, func(ctx context.Context) (any, error) {
+		return c.client.Do(ctx, args...)
+	})
+}
+
+func (c *ResilientRedisClient) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return guard(ctx, c.policy, func(ctx context.Context) (any, error) {
+		return c.client.Eval(ctx, script, keys, args...)
+	})
+}
+
+func (c *ResilientRedisClient) Subscribe(ctx context.Context, channel string, fn func(msg string)) error {
+	return c.client.Subscribe(ctx, channel, fn)
+}
@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/resilience"
+)
+
+// ResilientUserRepository guards another UserRepository with the database
+// resilience policy.
+type ResilientUserRepository struct {
+	next   contract.UserRepository
+	policy *resilience.Policy
+}
+
+var _ contract.UserRepository = (*ResilientUserRepository)(nil)
+
+func NewResilientUserRepository(next contract.UserRepository, policy *resilience.Policy) *ResilientUserRepository {
+	return &ResilientUserRepository{next: next, policy: policy}
+}
+
+// IsDatabaseFailure reports whether err indicates an unhealthy database rather
+// than an expected domain outcome.
+func IsDatabaseFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, errs.ErrUserNotFound) &&
+		!errors.Is(err, errs.ErrEmailTaken) &&
+		!errors.Is(err, errs.ErrUsernameTaken) &&
+		!errors.Is(err, errs.ErrPhoneTaken) &&
+		!errors.Is(err, errs.ErrConcurrentUpdate) &&
+		!errors.Is(err, context.Canceled)
+}
+
+func (r *ResilientUserRepository) Create(ctx context.Context, u *entity.User) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.Create(ctx, u)
+	})
+}
+
+func (r *ResilientUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+func (r *ResilientUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (map[uuid.UUID]*entity.User, error) {
+		return r.next.GetByIDs(ctx, ids)
+	})
+}
+
+func (r *ResilientUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByEmail(ctx, email)
+	})
+}
+
+func (r *ResilientUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByUsername(ctx, username)
+	})
+}
+
+func (r *ResilientUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByPhone(ctx, phone)
+	})
+}
+
+func (r *ResilientUserRepository) Update(ctx context.Context, u *entity.User) (*entity.User, error) {
+	return guard(ctx, r.policy, func(ctx context.Context) (*entity.User, error) {
+		return r.next.Update(ctx, u)
+	})
+}
+
+func (r *ResilientUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := guard(ctx, r.policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, r.next.Delete(ctx, id)
+	})
+	return err
+}
+
+func guard[T any](ctx context.Context, policy *resilience.Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var out T
+	err := policy.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = fn(ctx)
+		return err
+	})
+	return out, err
+}
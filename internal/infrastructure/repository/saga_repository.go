@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/saga"
+)
+
+// SagaRepository keeps the latest state of every saga run.
+type SagaRepository struct {
+	mu     sync.RWMutex
+	states map[uuid.UUID]saga.State
+}
+
+var _ saga.Store = (*SagaRepository)(nil)
+
+func NewSagaRepository() *SagaRepository {
+	return &SagaRepository{
+		states: make(map[uuid.UUID]saga.State),
+	}
+}
+
+func (r *SagaRepository) Save(ctx context.Context, s saga.State) (err error) {
+	ctx, span := startSpan(ctx, "sagas.save")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.states[s.ID] = s
+	return nil
+}
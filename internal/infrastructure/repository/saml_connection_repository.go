@@ -0,0 +1,153 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type SAMLConnectionRepository struct {
+	mu          sync.RWMutex
+	connections map[uuid.UUID]entity.SAMLConnection
+	// assertions maps consumed assertion IDs to when they expire.
+	assertions map[string]time.Time
+}
+
+var _ contract.SAMLConnectionRepository = (*SAMLConnectionRepository)(nil)
+
+func NewSAMLConnectionRepository() *SAMLConnectionRepository {
+	return &SAMLConnectionRepository{
+		connections: make(map[uuid.UUID]entity.SAMLConnection),
+		assertions:  make(map[string]time.Time),
+	}
+}
+
+func (r *SAMLConnectionRepository) Create(ctx context.Context, c *entity.SAMLConnection) (res *entity.SAMLConnection, err error) {
+	ctx, span := startSpan(ctx, "saml_connections.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findByTenant(c.Tenant) != nil {
+		return nil, errs.ErrSAMLConnectionExists
+	}
+
+	newConn := *c
+	if newConn.ID == uuid.Nil {
+		newConn.ID = uuid.New()
+	}
+	r.connections[newConn.ID] = newConn
+	return &newConn, nil
+}
+
+func (r *SAMLConnectionRepository) GetByTenant(ctx context.Context, tenant string) (res *entity.SAMLConnection, err error) {
+	ctx, span := startSpan(ctx, "saml_connections.get_by_tenant")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := r.findByTenant(tenant)
+	if c == nil {
+		return nil, errs.ErrSAMLConnectionNotFound
+	}
+	return c, nil
+}
+
+func (r *SAMLConnectionRepository) GetByTenants(ctx context.Context, tenants []string) (res map[string]*entity.SAMLConnection, err error) {
+	ctx, span := startSpan(ctx, "saml_connections.get_by_tenants")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res = make(map[string]*entity.SAMLConnection, len(tenants))
+	for _, tenant := range tenants {
+		if c := r.findByTenant(tenant); c != nil {
+			res[tenant] = c
+		}
+	}
+	return res, nil
+}
+
+func (r *SAMLConnectionRepository) List(ctx context.Context) (res []*entity.SAMLConnection, err error) {
+	ctx, span := startSpan(ctx, "saml_connections.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.SAMLConnection, 0, len(r.connections))
+	for _, c := range r.connections {
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Tenant < out[b].Tenant })
+	return out, nil
+}
+
+func (r *SAMLConnectionRepository) Update(ctx context.Context, c *entity.SAMLConnection) (res *entity.SAMLConnection, err error) {
+	ctx, span := startSpan(ctx, "saml_connections.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.connections[c.ID]; !ok {
+		return nil, errs.ErrSAMLConnectionNotFound
+	}
+	updated := *c
+	r.connections[c.ID] = updated
+	return &updated, nil
+}
+
+func (r *SAMLConnectionRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "saml_connections.delete")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.connections[id]; !ok {
+		return errs.ErrSAMLConnectionNotFound
+	}
+	delete(r.connections, id)
+	return nil
+}
+
+func (r *SAMLConnectionRepository) ConsumeAssertion(ctx context.Context, assertionID string, expiresAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, "saml_connections.consume_assertion")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range r.assertions {
+		if now.After(exp) {
+			delete(r.assertions, id)
+		}
+	}
+
+	if _, seen := r.assertions[assertionID]; seen {
+		return errs.ErrInvalidSAMLResponse
+	}
+	r.assertions[assertionID] = expiresAt
+	return nil
+}
+
+// findByTenant expects the caller to hold the lock.
+func (r *SAMLConnectionRepository) findByTenant(tenant string) *entity.SAMLConnection {
+	for _, c := range r.connections {
+		if c.Tenant == tenant {
+			return &c
+		}
+	}
+	return nil
+}
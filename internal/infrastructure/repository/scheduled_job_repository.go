@@ -0,0 +1,229 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// ScheduledJobRepository keeps jobs in memory, so they are lost on restart;
+// use PostgresScheduledJobRepository where they must be durable.
+type ScheduledJobRepository struct {
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]entity.ScheduledJob
+	// pending maps keys to their pending job.
+	pending map[string]uuid.UUID
+}
+
+var _ contract.ScheduledJobRepository = (*ScheduledJobRepository)(nil)
+
+func NewScheduledJobRepository() *ScheduledJobRepository {
+	return &ScheduledJobRepository{
+		jobs:    make(map[uuid.UUID]entity.ScheduledJob),
+		pending: make(map[string]uuid.UUID),
+	}
+}
+
+func (r *ScheduledJobRepository) Schedule(ctx context.Context, j *entity.ScheduledJob) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.schedule")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newJob := *j
+	if newJob.ID == uuid.Nil {
+		newJob.ID = uuid.New()
+	}
+	if newJob.Key != "" {
+		r.cancel(newJob.Key, newJob.CreatedAt)
+		r.pending[newJob.Key] = newJob.ID
+	}
+	r.jobs[newJob.ID] = newJob
+	return &newJob, nil
+}
+
+func (r *ScheduledJobRepository) CancelByKey(ctx context.Context, key string, now time.Time) (res bool, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.cancel_by_key")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cancel(key, now), nil
+}
+
+func (r *ScheduledJobRepository) Due(ctx context.Context, now time.Time, limit int) (res []*entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.due")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, j := range r.jobs {
+		if j.Status == entity.SCHEDULED_JOB_PENDING && !j.RunAt.After(now) {
+			res = append(res, &j)
+		}
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].RunAt.Before(res[b].RunAt) })
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (r *ScheduledJobRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	return &j, nil
+}
+
+func (r *ScheduledJobRepository) GetPendingByKey(ctx context.Context, key string) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.get_pending_by_key")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.pending[key]
+	if !ok {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	j := r.jobs[id]
+	return &j, nil
+}
+
+func (r *ScheduledJobRepository) List(ctx context.Context, status, kind string, limit, offset int) (res []*entity.ScheduledJob, total int, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []*entity.ScheduledJob
+	for _, j := range r.jobs {
+		if j.Status == status && (kind == "" || j.Kind == kind) {
+			matches = append(matches, &j)
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool { return jobUpdatedAt(matches[a]).After(jobUpdatedAt(matches[b])) })
+
+	total = len(matches)
+	if offset >= total {
+		return []*entity.ScheduledJob{}, total, nil
+	}
+	return matches[offset:min(offset+limit, total)], total, nil
+}
+
+func (r *ScheduledJobRepository) Update(ctx context.Context, j *entity.ScheduledJob) (res *entity.ScheduledJob, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[j.ID]; !ok {
+		return nil, errs.ErrScheduledJobNotFound
+	}
+	r.jobs[j.ID] = *j
+	switch {
+	case j.Key == "":
+	case j.Status == entity.SCHEDULED_JOB_PENDING:
+		r.pending[j.Key] = j.ID
+	case r.pending[j.Key] == j.ID:
+		delete(r.pending, j.Key)
+	}
+	updated := *j
+	return &updated, nil
+}
+
+func (r *ScheduledJobRepository) Stats(ctx context.Context, since, now time.Time) (res []dto.JobKindStats, err error) {
+	ctx, span := startSpan(ctx, "scheduled_jobs.stats")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byKind := make(map[string]*dto.JobKindStats)
+	latencySum := make(map[string]float64)
+	for _, j := range r.jobs {
+		finishedInWindow := j.UpdatedAt != nil && !j.UpdatedAt.Before(since)
+		if !finishedInWindow && j.Status != entity.SCHEDULED_JOB_PENDING && j.Status != entity.SCHEDULED_JOB_FAILED {
+			continue
+		}
+		s, ok := byKind[j.Kind]
+		if !ok {
+			s = &dto.JobKindStats{Kind: j.Kind}
+			byKind[j.Kind] = s
+		}
+		switch j.Status {
+		case entity.SCHEDULED_JOB_PENDING:
+			s.Pending++
+			if j.Attempts > 0 {
+				s.Retrying++
+			}
+			if !j.RunAt.After(now) {
+				s.Due++
+				s.OldestDueSeconds = max(s.OldestDueSeconds, now.Sub(j.RunAt).Seconds())
+			}
+		case entity.SCHEDULED_JOB_FAILED:
+			s.Dead++
+			if finishedInWindow {
+				s.Failed++
+			}
+		case entity.SCHEDULED_JOB_DONE:
+			if finishedInWindow {
+				s.Done++
+				latency := j.UpdatedAt.Sub(j.RunAt).Seconds()
+				latencySum[j.Kind] += latency
+				s.MaxLatencySeconds = max(s.MaxLatencySeconds, latency)
+			}
+		}
+	}
+
+	res = make([]dto.JobKindStats, 0, len(byKind))
+	for kind, s := range byKind {
+		if s.Done > 0 {
+			s.AvgLatencySeconds = latencySum[kind] / float64(s.Done)
+		}
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].Kind < res[b].Kind })
+	return res, nil
+}
+
+func jobUpdatedAt(j *entity.ScheduledJob) time.Time {
+	if j.UpdatedAt != nil {
+		return *j.UpdatedAt
+	}
+	return j.CreatedAt
+}
+
+// cancel expects the caller to hold the lock.
+func (r *ScheduledJobRepository) cancel(key string, now time.Time) bool {
+	id, ok := r.pending[key]
+	if !ok {
+		return false
+	}
+	delete(r.pending, key)
+	j := r.jobs[id]
+	j.Status = entity.SCHEDULED_JOB_CANCELED
+	j.UpdatedAt = &now
+	r.jobs[id] = j
+	return true
+}
@@ -0,0 +1,205 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type ServiceAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[uuid.UUID]entity.ServiceAccount
+}
+
+var _ contract.ServiceAccountRepository = (*ServiceAccountRepository)(nil)
+
+func NewServiceAccountRepository() *ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		accounts: make(map[uuid.UUID]entity.ServiceAccount),
+	}
+}
+
+func (r *ServiceAccountRepository) Create(ctx context.Context, a *entity.ServiceAccount) (res *entity.ServiceAccount, err error) {
+	ctx, span := startSpan(ctx, "service_accounts.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newAccount := *a
+	if newAccount.ID == uuid.Nil {
+		newAccount.ID = uuid.New()
+	}
+	newAccount.Roles = slices.Clone(a.Roles)
+	r.accounts[newAccount.ID] = newAccount
+	return &newAccount, nil
+}
+
+func (r *ServiceAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.ServiceAccount, err error) {
+	ctx, span := startSpan(ctx, "service_accounts.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.accounts[id]
+	if !ok {
+		return nil, errs.ErrServiceAccountNotFound
+	}
+	a.Roles = slices.Clone(a.Roles)
+	return &a, nil
+}
+
+func (r *ServiceAccountRepository) List(ctx context.Context, tenant string) (res []*entity.ServiceAccount, err error) {
+	ctx, span := startSpan(ctx, "service_accounts.list")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.ServiceAccount, 0)
+	for _, a := range r.accounts {
+		if a.IsActive() && (tenant == "" || a.Tenant == tenant) {
+			a.Roles = slices.Clone(a.Roles)
+			out = append(out, &a)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.ServiceAccount) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return out, nil
+}
+
+func (r *ServiceAccountRepository) Update(ctx context.Context, a *entity.ServiceAccount) (res *entity.ServiceAccount, err error) {
+	ctx, span := startSpan(ctx, "service_accounts.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.accounts[a.ID]; !ok {
+		return nil, errs.ErrServiceAccountNotFound
+	}
+	updated := *a
+	updated.Roles = slices.Clone(a.Roles)
+	r.accounts[a.ID] = updated
+	return &updated, nil
+}
+
+type ServiceAccountKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[uuid.UUID]entity.ServiceAccountKey
+	byHash map[string]uuid.UUID
+}
+
+var _ contract.ServiceAccountKeyRepository = (*ServiceAccountKeyRepository)(nil)
+
+func NewServiceAccountKeyRepository() *ServiceAccountKeyRepository {
+	return &ServiceAccountKeyRepository{
+		keys:   make(map[uuid.UUID]entity.ServiceAccountKey),
+		byHash: make(map[string]uuid.UUID),
+	}
+}
+
+func (r *ServiceAccountKeyRepository) Create(ctx context.Context, k *entity.ServiceAccountKey) (res *entity.ServiceAccountKey, err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newKey := *k
+	if newKey.ID == uuid.Nil {
+		newKey.ID = uuid.New()
+	}
+	r.keys[newKey.ID] = newKey
+	r.byHash[newKey.KeyHash] = newKey.ID
+	return &newKey, nil
+}
+
+func (r *ServiceAccountKeyRepository) GetByHash(ctx context.Context, hash string) (res *entity.ServiceAccountKey, err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.get_by_hash")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[r.byHash[hash]]
+	if !ok {
+		return nil, errs.ErrServiceAccountKeyNotFound
+	}
+	return &k, nil
+}
+
+func (r *ServiceAccountKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.ServiceAccountKey, err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return nil, errs.ErrServiceAccountKeyNotFound
+	}
+	return &k, nil
+}
+
+func (r *ServiceAccountKeyRepository) ListByAccount(ctx context.Context, accountID uuid.UUID) (res []*entity.ServiceAccountKey, err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.list_by_account")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*entity.ServiceAccountKey, 0)
+	for _, k := range r.keys {
+		if k.ServiceAccountID == accountID {
+			out = append(out, &k)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.ServiceAccountKey) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return out, nil
+}
+
+func (r *ServiceAccountKeyRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.touch")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return errs.ErrServiceAccountKeyNotFound
+	}
+	k.LastUsedAt = &at
+	r.keys[id] = k
+	return nil
+}
+
+func (r *ServiceAccountKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "service_account_keys.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return errs.ErrServiceAccountKeyNotFound
+	}
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+		r.keys[id] = k
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type SessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]entity.Session
+}
+
+var _ contract.SessionRepository = (*SessionRepository)(nil)
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		sessions: make(map[uuid.UUID]entity.Session),
+	}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newSession := *s
+	if newSession.ID == uuid.Nil {
+		newSession.ID = uuid.New()
+	}
+	r.sessions[newSession.ID] = newSession
+	return &newSession, nil
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, errs.ErrSessionNotFound
+	}
+	return &s, nil
+}
+
+func (r *SessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) (res []*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	out := make([]*entity.Session, 0)
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.IsActive(now) {
+			out = append(out, &s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastSeenAt.After(out[j].LastSeenAt) })
+	return out, nil
+}
+
+func (r *SessionRepository) ListActiveByUsers(ctx context.Context, userIDs []uuid.UUID) (res map[uuid.UUID][]*entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.list_active_by_users")
+	defer func() { endSpan(span, res, err) }()
+
+	wanted := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	res = make(map[uuid.UUID][]*entity.Session)
+	for _, s := range r.sessions {
+		if wanted[s.UserID] && s.IsActive(now) {
+			res[s.UserID] = append(res[s.UserID], &s)
+		}
+	}
+	for _, sessions := range res {
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	}
+	return res, nil
+}
+
+func (r *SessionRepository) Update(ctx context.Context, s *entity.Session) (res *entity.Session, err error) {
+	ctx, span := startSpan(ctx, "sessions.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sessions[s.ID]; !ok {
+		return nil, errs.ErrSessionNotFound
+	}
+	updated := *s
+	r.sessions[s.ID] = updated
+	return &updated, nil
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.touch")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return errs.ErrSessionNotFound
+	}
+	s.LastSeenAt = at
+	if ip != "" {
+		s.IP = ip
+	}
+	r.sessions[id] = s
+	return nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return errs.ErrSessionNotFound
+	}
+	if s.RevokedAt == nil {
+		s.RevokedAt = &at
+		r.sessions[id] = s
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID, at time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "sessions.revoke_all_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for id, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+			r.sessions[id] = s
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, cutoff time.Time) (res int, err error) {
+	ctx, span := startSpan(ctx, "sessions.delete_expired")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for id, s := range r.sessions {
+		if s.ExpiresAt.Before(cutoff) {
+			delete(r.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
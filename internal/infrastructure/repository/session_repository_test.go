@@ -0,0 +1,267 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+func TestSessionRepository(t *testing.T) {
+	testSessionRepository(t, func(t *testing.T) contract.SessionRepository {
+		return NewSessionRepository()
+	})
+}
+
+// testSessionRepository checks that a SessionRepository behaves as the
+// in-memory one does; newRepo returns the store for each subtest, which
+// may be shared, as every subtest works on users of its own.
+// DeleteExpired is only checked for what it leaves behind, since Redis
+// expires sessions on its own and reports none deleted.
+func testSessionRepository(t *testing.T, newRepo func(t *testing.T) contract.SessionRepository) {
+	ctx := context.Background()
+	// Postgres keeps microseconds and Redis what encoding/json writes, so
+	// the times are rounded for them to come back equal.
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	session := func(userID uuid.UUID, lastSeen time.Duration) *entity.Session {
+		return &entity.Session{
+			UserID:            userID,
+			RefreshTokenID:    uuid.NewString(),
+			DeviceFingerprint: "fp",
+			UserAgent:         "test",
+			IP:                "192.0.2.1",
+			CreatedAt:         now.Add(-time.Hour),
+			LastSeenAt:        now.Add(lastSeen),
+			ExpiresAt:         now.Add(time.Hour),
+		}
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repo := newRepo(t)
+		admin := uuid.New()
+		s := session(uuid.New(), 0)
+		s.ImpersonatorID = &admin
+		created, err := repo.Create(ctx, s)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if created.ID == uuid.Nil {
+			t.Fatal("create left the id unset")
+		}
+		got, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		assertSession(t, got, created)
+
+		if _, err := repo.GetByID(ctx, uuid.New()); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("get unknown: err = %v, want %v", err, errs.ErrSessionNotFound)
+		}
+	})
+
+	t.Run("list active", func(t *testing.T) {
+		repo := newRepo(t)
+		userID, otherID := uuid.New(), uuid.New()
+		older := mustCreateSession(t, repo, session(userID, -time.Minute))
+		newer := mustCreateSession(t, repo, session(userID, 0))
+		revoked := mustCreateSession(t, repo, session(userID, 0))
+		if err := repo.Revoke(ctx, revoked.ID, now); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		expired := session(userID, 0)
+		expired.ExpiresAt = now.Add(-time.Second)
+		mustCreateSession(t, repo, expired)
+		other := mustCreateSession(t, repo, session(otherID, 0))
+
+		got, err := repo.ListActiveByUser(ctx, userID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSessionIDs(t, got, newer, older)
+
+		byUser, err := repo.ListActiveByUsers(ctx, []uuid.UUID{userID, otherID, uuid.New()})
+		if err != nil {
+			t.Fatalf("list by users: %v", err)
+		}
+		if len(byUser) != 2 {
+			t.Errorf("list by users returned %d users, want 2", len(byUser))
+		}
+		assertSessionIDs(t, byUser[userID], newer, older)
+		assertSessionIDs(t, byUser[otherID], other)
+
+		if byUser, err := repo.ListActiveByUsers(ctx, nil); err != nil || len(byUser) != 0 {
+			t.Errorf("list by no users = %v, %v; want none", byUser, err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		s := mustCreateSession(t, repo, session(uuid.New(), 0))
+		s.RefreshTokenID = "rotated"
+		s.ExpiresAt = now.Add(2 * time.Hour)
+		if _, err := repo.Update(ctx, s); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, err := repo.GetByID(ctx, s.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		assertSession(t, got, s)
+
+		unknown := session(uuid.New(), 0)
+		unknown.ID = uuid.New()
+		if _, err := repo.Update(ctx, unknown); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("update unknown: err = %v, want %v", err, errs.ErrSessionNotFound)
+		}
+		if _, err := repo.GetByID(ctx, unknown.ID); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("update unknown stored it: err = %v", err)
+		}
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		repo := newRepo(t)
+		s := mustCreateSession(t, repo, session(uuid.New(), -time.Hour))
+		seen := now.Add(time.Minute)
+		if err := repo.Touch(ctx, s.ID, "198.51.100.7", seen); err != nil {
+			t.Fatalf("touch: %v", err)
+		}
+		if err := repo.Touch(ctx, s.ID, "", seen.Add(time.Second)); err != nil {
+			t.Fatalf("touch without ip: %v", err)
+		}
+		got, err := repo.GetByID(ctx, s.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if !got.LastSeenAt.Equal(seen.Add(time.Second)) || got.IP != "198.51.100.7" {
+			t.Errorf("touched session seen %v from %q, want %v from %q",
+				got.LastSeenAt, got.IP, seen.Add(time.Second), "198.51.100.7")
+		}
+		if err := repo.Touch(ctx, uuid.New(), "", now); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("touch unknown: err = %v, want %v", err, errs.ErrSessionNotFound)
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		repo := newRepo(t)
+		s := mustCreateSession(t, repo, session(uuid.New(), 0))
+		if err := repo.Revoke(ctx, s.ID, now); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if err := repo.Revoke(ctx, s.ID, now.Add(time.Minute)); err != nil {
+			t.Fatalf("revoke again: %v", err)
+		}
+		got, err := repo.GetByID(ctx, s.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(now) {
+			t.Errorf("revoked at %v, want the first revocation %v", got.RevokedAt, now)
+		}
+		if err := repo.Revoke(ctx, uuid.New(), now); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("revoke unknown: err = %v, want %v", err, errs.ErrSessionNotFound)
+		}
+	})
+
+	t.Run("revoke all by user", func(t *testing.T) {
+		repo := newRepo(t)
+		userID := uuid.New()
+		mustCreateSession(t, repo, session(userID, 0))
+		mustCreateSession(t, repo, session(userID, 0))
+		revoked := mustCreateSession(t, repo, session(userID, 0))
+		if err := repo.Revoke(ctx, revoked.ID, now); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		other := mustCreateSession(t, repo, session(uuid.New(), 0))
+
+		if n, err := repo.RevokeAllByUser(ctx, userID, now); err != nil || n != 2 {
+			t.Errorf("revoke all = %d, %v; want 2", n, err)
+		}
+		if n, err := repo.RevokeAllByUser(ctx, userID, now); err != nil || n != 0 {
+			t.Errorf("revoke all again = %d, %v; want 0", n, err)
+		}
+		if got, err := repo.ListActiveByUser(ctx, userID); err != nil || len(got) != 0 {
+			t.Errorf("user still has %d active sessions, err %v", len(got), err)
+		}
+		got, err := repo.GetByID(ctx, other.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.RevokedAt != nil {
+			t.Error("revoke all revoked another user's session")
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		repo := newRepo(t)
+		userID := uuid.New()
+		live := mustCreateSession(t, repo, session(userID, 0))
+		expired := session(userID, 0)
+		expired.ExpiresAt = now.Add(-time.Second)
+		expired = mustCreateSession(t, repo, expired)
+
+		if _, err := repo.DeleteExpired(ctx, now); err != nil {
+			t.Fatalf("delete expired: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, expired.ID); !errors.Is(err, errs.ErrSessionNotFound) {
+			t.Errorf("expired session kept: err = %v", err)
+		}
+		if _, err := repo.GetByID(ctx, live.ID); err != nil {
+			t.Errorf("live session deleted: %v", err)
+		}
+	})
+}
+
+func mustCreateSession(t *testing.T, repo contract.SessionRepository, s *entity.Session) *entity.Session {
+	t.Helper()
+	created, err := repo.Create(context.Background(), s)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return created
+}
+
+// assertSession compares times with Equal, as the stores may return them
+// in another location.
+func assertSession(t *testing.T, got, want *entity.Session) {
+	t.Helper()
+	same := got.ID == want.ID && got.UserID == want.UserID &&
+		got.RefreshTokenID == want.RefreshTokenID && got.DeviceFingerprint == want.DeviceFingerprint &&
+		got.UserAgent == want.UserAgent && got.IP == want.IP &&
+		got.CreatedAt.Equal(want.CreatedAt) && got.LastSeenAt.Equal(want.LastSeenAt) &&
+		got.ExpiresAt.Equal(want.ExpiresAt) &&
+		(got.RevokedAt == nil) == (want.RevokedAt == nil) &&
+		(got.RevokedAt == nil || got.RevokedAt.Equal(*want.RevokedAt)) &&
+		(got.ImpersonatorID == nil) == (want.ImpersonatorID == nil) &&
+		(got.ImpersonatorID == nil || *got.ImpersonatorID == *want.ImpersonatorID)
+	if !same {
+		t.Errorf("session = %+v, want %+v", got, want)
+	}
+}
+
+// assertSessionIDs checks that got holds the sessions of want, in order.
+func assertSessionIDs(t *testing.T, got []*entity.Session, want ...*entity.Session) {
+	t.Helper()
+	ids := func(sessions []*entity.Session) []uuid.UUID {
+		out := make([]uuid.UUID, len(sessions))
+		for i, s := range sessions {
+			out[i] = s.ID
+		}
+		return out
+	}
+	g, w := ids(got), ids(want)
+	if len(g) != len(w) {
+		t.Errorf("sessions = %v, want %v", g, w)
+		return
+	}
+	for i := range g {
+		if g[i] != w[i] {
+			t.Errorf("sessions = %v, want %v", g, w)
+			return
+		}
+	}
+}
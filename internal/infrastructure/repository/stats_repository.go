@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type StatsRepository struct {
+	mu    sync.RWMutex
+	views map[string]entity.StatsView
+}
+
+var _ contract.StatsRepository = (*StatsRepository)(nil)
+
+func NewStatsRepository() *StatsRepository {
+	return &StatsRepository{views: make(map[string]entity.StatsView)}
+}
+
+func (r *StatsRepository) Get(ctx context.Context, name string) (res *entity.StatsView, err error) {
+	ctx, span := startSpan(ctx, "stats_views.get")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.views[name]
+	if !ok {
+		return nil, errs.ErrStatsViewNotFound
+	}
+	return &v, nil
+}
+
+func (r *StatsRepository) Put(ctx context.Context, v *entity.StatsView) (err error) {
+	ctx, span := startSpan(ctx, "stats_views.put")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.views[v.Name] = *v
+	return nil
+}
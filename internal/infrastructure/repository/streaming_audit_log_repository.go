@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/siem"
+)
+
+// StreamingAuditLogRepository streams every event appended to another
+// AuditLogRepository to a SIEM, as the JSON it is listed with. Only events
+// stored are streamed, and the stream never fails or delays an append.
+type StreamingAuditLogRepository struct {
+	next     contract.AuditLogRepository
+	streamer *siem.Streamer
+}
+
+var _ contract.AuditLogRepository = (*StreamingAuditLogRepository)(nil)
+
+func NewStreamingAuditLogRepository(next contract.AuditLogRepository, streamer *siem.Streamer) *StreamingAuditLogRepository {
+	return &StreamingAuditLogRepository{next: next, streamer: streamer}
+}
+
+func (r *StreamingAuditLogRepository) Append(ctx context.Context, e *entity.AuditEvent) (*entity.AuditEvent, error) {
+	stored, err := r.next.Append(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	if record, err := json.Marshal(stored); err == nil {
+		r.streamer.Publish(record)
+	}
+	return stored, nil
+}
+
+func (r *StreamingAuditLogRepository) List(ctx context.Context, filter dto.AuditLogFilter, before *dto.ExportCursor, limit, offset int) ([]*entity.AuditEvent, int, error) {
+	return r.next.List(ctx, filter, before, limit, offset)
+}
+
+func (r *StreamingAuditLogRepository) ListAfter(ctx context.Context, filter dto.AuditLogFilter, after *dto.ExportCursor, limit int) ([]*entity.AuditEvent, error) {
+	return r.next.ListAfter(ctx, filter, after, limit)
+}
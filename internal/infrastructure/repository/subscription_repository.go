@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type SubscriptionRepository struct {
+	mu sync.RWMutex
+	// subscriptions is keyed by provider subscription ID.
+	subscriptions map[string]entity.Subscription
+}
+
+var _ contract.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{subscriptions: make(map[string]entity.Subscription)}
+}
+
+func (r *SubscriptionRepository) Upsert(ctx context.Context, s *entity.Subscription) (res *entity.Subscription, err error) {
+	ctx, span := startSpan(ctx, "subscriptions.upsert")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newSub := *s
+	if current, ok := r.subscriptions[s.ProviderID]; ok {
+		newSub.ID = current.ID
+		newSub.CreatedAt = current.CreatedAt
+	}
+	if newSub.ID == uuid.Nil {
+		newSub.ID = uuid.New()
+	}
+	r.subscriptions[newSub.ProviderID] = newSub
+	return &newSub, nil
+}
+
+func (r *SubscriptionRepository) GetByProviderID(ctx context.Context, providerID string) (res *entity.Subscription, err error) {
+	ctx, span := startSpan(ctx, "subscriptions.get_by_provider_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.subscriptions[providerID]
+	if !ok {
+		return nil, errs.ErrSubscriptionNotFound
+	}
+	return &s, nil
+}
+
+func (r *SubscriptionRepository) ListByUser(ctx context.Context, userID uuid.UUID) (res []*entity.Subscription, err error) {
+	ctx, span := startSpan(ctx, "subscriptions.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*entity.Subscription
+	for _, s := range r.subscriptions {
+		if s.UserID == userID {
+			out = append(out, &s)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.Subscription) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return out, nil
+}
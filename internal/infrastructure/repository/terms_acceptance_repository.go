@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+type TermsAcceptanceRepository struct {
+	mu sync.RWMutex
+	// latest keeps the newest acceptance per user; history holds them all.
+	latest  map[uuid.UUID]entity.TermsAcceptance
+	history []entity.TermsAcceptance
+}
+
+var _ contract.TermsAcceptanceRepository = (*TermsAcceptanceRepository)(nil)
+
+func NewTermsAcceptanceRepository() *TermsAcceptanceRepository {
+	return &TermsAcceptanceRepository{
+		latest: make(map[uuid.UUID]entity.TermsAcceptance),
+	}
+}
+
+func (r *TermsAcceptanceRepository) Create(ctx context.Context, a *entity.TermsAcceptance) (res *entity.TermsAcceptance, err error) {
+	ctx, span := startSpan(ctx, "terms_acceptances.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newAcceptance := *a
+	if newAcceptance.ID == uuid.Nil {
+		newAcceptance.ID = uuid.New()
+	}
+	r.latest[newAcceptance.UserID] = newAcceptance
+	r.history = append(r.history, newAcceptance)
+	return &newAcceptance, nil
+}
+
+func (r *TermsAcceptanceRepository) Latest(ctx context.Context, userID uuid.UUID) (res *entity.TermsAcceptance, err error) {
+	ctx, span := startSpan(ctx, "terms_acceptances.latest")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.latest[userID]
+	if !ok {
+		return nil, errs.ErrTermsNotAccepted
+	}
+	return &a, nil
+}
@@ -0,0 +1,50 @@
+package infrastructure
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/haidang666/go-app/pkg/trace"
+)
+
+// startSpan starts the span of one repository statement. stmt is a fixed
+// "<table>.<operation>" name such as "users.get_by_id", never the statement
+// text, so no user data ends up in traces.
+func startSpan(ctx context.Context, stmt string) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, "db "+stmt)
+	span.SetAttr("db.statement", stmt)
+	return ctx, span
+}
+
+// endSpan finishes a statement span with the number of rows in result: the
+// length of a slice, an affected-row count, or 1 for a found record.
+// Statements that return no rows report none.
+func endSpan(span *trace.Span, result any, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else if result != nil {
+		span.SetAttr("db.rows", rowCount(result))
+	}
+	span.End()
+}
+
+func rowCount(result any) int {
+	v := reflect.ValueOf(result)
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len()
+	case reflect.Int:
+		return int(v.Int())
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Pointer:
+		if v.IsNil() {
+			return 0
+		}
+		return 1
+	}
+	return 1
+}
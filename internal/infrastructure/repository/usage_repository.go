@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/entity"
+)
+
+type usageKey struct {
+	userID      uuid.UUID
+	metric      string
+	periodStart int64
+}
+
+type UsageRepository struct {
+	mu      sync.RWMutex
+	records map[usageKey]entity.UsageRecord
+}
+
+var _ contract.UsageRepository = (*UsageRepository)(nil)
+
+func NewUsageRepository() *UsageRepository {
+	return &UsageRepository{records: make(map[usageKey]entity.UsageRecord)}
+}
+
+func (r *UsageRepository) Add(ctx context.Context, userID uuid.UUID, metric string, periodStart time.Time, delta int64) (err error) {
+	ctx, span := startSpan(ctx, "usage.add")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.update(userID, metric, periodStart, func(q int64) int64 { return q + delta })
+	return nil
+}
+
+func (r *UsageRepository) RaiseTo(ctx context.Context, userID uuid.UUID, metric string, periodStart time.Time, value int64) (err error) {
+	ctx, span := startSpan(ctx, "usage.raise_to")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.update(userID, metric, periodStart, func(q int64) int64 { return max(q, value) })
+	return nil
+}
+
+func (r *UsageRepository) update(userID uuid.UUID, metric string, periodStart time.Time, apply func(int64) int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := usageKey{userID: userID, metric: metric, periodStart: periodStart.Unix()}
+	rec, ok := r.records[key]
+	if !ok {
+		rec = entity.UsageRecord{UserID: userID, Metric: metric, PeriodStart: periodStart.UTC()}
+	}
+	rec.Quantity = apply(rec.Quantity)
+	rec.UpdatedAt = time.Now()
+	r.records[key] = rec
+}
+
+func (r *UsageRepository) ListByUser(ctx context.Context, userID uuid.UUID, periodStart time.Time) (res []*entity.UsageRecord, err error) {
+	ctx, span := startSpan(ctx, "usage.list_by_user")
+	defer func() { endSpan(span, res, err) }()
+
+	return r.list(func(k usageKey) bool {
+		return k.userID == userID && k.periodStart == periodStart.Unix()
+	}), nil
+}
+
+func (r *UsageRepository) ListByPeriod(ctx context.Context, periodStart time.Time) (res []*entity.UsageRecord, err error) {
+	ctx, span := startSpan(ctx, "usage.list_by_period")
+	defer func() { endSpan(span, res, err) }()
+
+	return r.list(func(k usageKey) bool {
+		return k.periodStart == periodStart.Unix()
+	}), nil
+}
+
+func (r *UsageRepository) list(match func(usageKey) bool) []*entity.UsageRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*entity.UsageRecord
+	for k, rec := range r.records {
+		if match(k) {
+			out = append(out, &rec)
+		}
+	}
+	slices.SortFunc(out, func(a, b *entity.UsageRecord) int {
+		if c := bytes.Compare(a.UserID[:], b.UserID[:]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Metric, b.Metric)
+	})
+	return out
+}
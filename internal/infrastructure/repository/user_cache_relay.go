@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/safe"
+)
+
+var userCacheRelayErrorsTotal = metrics.NewCounter("user_cache_relay_errors_total",
+	"Failures relaying user cache invalidations over Redis, by operation (publish, subscribe).", "op")
+
+// RedisPubSub is the subset of a Redis client the UserCacheRelay needs;
+// resp.Client implements it.
+type RedisPubSub interface {
+	Do(ctx context.Context, args ...any) (any, error)
+	Subscribe(ctx context.Context, channel string, fn func(msg string)) error
+}
+
+// userCacheInvalidation is the message the relay publishes for each user
+// write. Origin tells a replica its own messages apart.
+type userCacheInvalidation struct {
+	Origin string    `json:"origin"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// UserCacheRelay carries user cache invalidations between replicas: it
+// publishes the UserChangedEvents of the local event bus on a Redis channel
+// and evicts the users the other replicas publish. Pub/sub does not queue,
+// so a replica misses the writes made while it is not subscribed; the cache
+// TTL bounds how long those stay stale, unless failOpen is off: then the
+// cache is bypassed until the replica is subscribed again.
+type UserCacheRelay struct {
+	client   RedisPubSub
+	channel  string
+	cache    *UserCache
+	origin   string
+	failOpen bool
+
+	connected atomic.Bool
+}
+
+func NewUserCacheRelay(client RedisPubSub, channel string, cache *UserCache, failOpen bool) *UserCacheRelay {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return &UserCacheRelay{client: client, channel: channel, cache: cache, origin: hex.EncodeToString(buf), failOpen: failOpen}
+}
+
+// Connected reports whether the relay is currently subscribed.
+func (r *UserCacheRelay) Connected() bool {
+	return r.connected.Load()
+}
+
+// Subscribe publishes the user writes of bus to the other replicas.
+func (r *UserCacheRelay) Subscribe(bus *eventbus.Bus) {
+	bus.Subscribe(dto.USER_CHANGED_TOPIC, func(ctx context.Context, e eventbus.Event) {
+		changed, ok := e.Payload.(dto.UserChangedEvent)
+		if !ok {
+			return
+		}
+		msg, err := json.Marshal(userCacheInvalidation{Origin: r.origin, UserID: changed.User.ID})
+		if err != nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		if _, err := r.client.Do(ctx, "PUBLISH", r.channel, msg); err != nil {
+			userCacheRelayErrorsTotal.Inc("publish")
+			logger.Sample(logger.L(), "user_cache.publish", 100).Warnw("publish user cache invalidation",
+				"user_id", changed.User.ID, "error", err)
+		}
+	})
+}
+
+// Run evicts the users written on the other replicas until ctx is done,
+// resubscribing after a connection failure. A lost subscription clears the
+// cache, as the writes made until the next one are missed. A subscription
+// counts as up once it has held for a second.
+func (r *UserCacheRelay) Run(ctx context.Context) {
+	r.cache.SetBypass(!r.failOpen)
+	for {
+		up := time.AfterFunc(time.Second, func() {
+			r.cache.Clear()
+			r.cache.SetBypass(false)
+			r.connected.Store(true)
+		})
+		err := r.client.Subscribe(ctx, r.channel, func(msg string) {
+			// A message that panics is dropped rather than ending the
+			// subscription.
+			safe.Call(ctx, "user_cache.relay", func() error {
+				r.receive(msg)
+				return nil
+			})
+		})
+		up.Stop()
+		r.connected.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		r.cache.SetBypass(!r.failOpen)
+		r.cache.Clear()
+		userCacheRelayErrorsTotal.Inc("subscribe")
+		logger.L().Warnw("user cache invalidation subscription lost", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (r *UserCacheRelay) receive(msg string) {
+	var inv userCacheInvalidation
+	if err := json.Unmarshal([]byte(msg), &inv); err != nil || inv.Origin == r.origin {
+		return
+	}
+	r.cache.Invalidate(inv.UserID, "remote")
+}
@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+)
+
+// UserEventStore is the append-only table of user events, with snapshots of
+// users at a version so rehydration does not replay every event.
+type UserEventStore struct {
+	mu        sync.RWMutex
+	events    map[uuid.UUID][]entity.UserEvent
+	snapshots map[uuid.UUID]entity.User
+	// snapshotVersions is the version each snapshot was taken at.
+	snapshotVersions map[uuid.UUID]int
+}
+
+func NewUserEventStore() *UserEventStore {
+	return &UserEventStore{
+		events:           make(map[uuid.UUID][]entity.UserEvent),
+		snapshots:        make(map[uuid.UUID]entity.User),
+		snapshotVersions: make(map[uuid.UUID]int),
+	}
+}
+
+// Append numbers events after expectedVersion, the version the caller
+// loaded, and stores them. It fails with ErrConcurrentUpdate when another
+// append got there first.
+func (s *UserEventStore) Append(ctx context.Context, userID uuid.UUID, expectedVersion int, events []entity.UserEvent) (res []entity.UserEvent, err error) {
+	ctx, span := startSpan(ctx, "user_events.append")
+	defer func() { endSpan(span, res, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream := s.events[userID]
+	if len(stream) != expectedVersion {
+		return nil, errs.ErrConcurrentUpdate
+	}
+	out := make([]entity.UserEvent, len(events))
+	for i, e := range events {
+		e.UserID = userID
+		e.Version = expectedVersion + i + 1
+		out[i] = e
+	}
+	s.events[userID] = append(stream, out...)
+	return out, nil
+}
+
+// Load returns the user's events after version afterVersion, oldest first.
+func (s *UserEventStore) Load(ctx context.Context, userID uuid.UUID, afterVersion int) (res []entity.UserEvent, err error) {
+	ctx, span := startSpan(ctx, "user_events.load")
+	defer func() { endSpan(span, res, err) }()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stream := s.events[userID]
+	if afterVersion >= len(stream) {
+		return nil, nil
+	}
+	return slices.Clone(stream[afterVersion:]), nil
+}
+
+// SaveSnapshot records u as of version, replacing an older snapshot.
+func (s *UserEventStore) SaveSnapshot(ctx context.Context, u entity.User, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version > s.snapshotVersions[u.ID] {
+		s.snapshots[u.ID] = u
+		s.snapshotVersions[u.ID] = version
+	}
+}
+
+// Snapshot returns the latest snapshot of the user and its version; ok is
+// false without one.
+func (s *UserEventStore) Snapshot(ctx context.Context, userID uuid.UUID) (u entity.User, version int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok = s.snapshots[userID]
+	return u, s.snapshotVersions[userID], ok
+}
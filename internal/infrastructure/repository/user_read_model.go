@@ -0,0 +1,154 @@
+package infrastructure
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/eventbus"
+)
+
+// UserReadModel is a denormalized users table maintained from the
+// UserChangedEvents on the event bus. It has its own lock, so listings never
+// wait on the write model. Events dropped by a full bus leave rows stale
+// until the user's next write.
+type UserReadModel struct {
+	mu   sync.RWMutex
+	rows map[uuid.UUID]*dto.UserSummary
+	// deleted remembers removed users so a late change event cannot bring
+	// them back; user IDs are never reused.
+	deleted map[uuid.UUID]bool
+}
+
+var _ contract.UserQuery = (*UserReadModel)(nil)
+
+func NewUserReadModel() *UserReadModel {
+	return &UserReadModel{
+		rows:    make(map[uuid.UUID]*dto.UserSummary),
+		deleted: make(map[uuid.UUID]bool),
+	}
+}
+
+// Subscribe keeps the read model up to date with the user writes published
+// on bus.
+func (m *UserReadModel) Subscribe(bus *eventbus.Bus) {
+	bus.Subscribe(dto.USER_CHANGED_TOPIC, func(ctx context.Context, e eventbus.Event) {
+		changed, ok := e.Payload.(dto.UserChangedEvent)
+		switch {
+		case !ok:
+		case changed.Deleted:
+			m.remove(changed.User.ID)
+		default:
+			m.apply(changed.User)
+		}
+	})
+}
+
+func (m *UserReadModel) apply(u entity.User) {
+	row := &dto.UserSummary{
+		ID:            u.ID,
+		Email:         u.Email,
+		Username:      u.Username,
+		Status:        u.Status,
+		Plan:          u.Plan,
+		TenantID:      u.TenantID,
+		IsGuest:       u.IsGuest,
+		PhoneVerified: u.HasVerifiedPhone(),
+		PurgeAt:       u.PurgeAt,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deleted[u.ID] {
+		return
+	}
+	// Workers may deliver a user's events out of order; keep the newest.
+	if prev, ok := m.rows[u.ID]; ok && updatedAt(prev).After(updatedAt(row)) {
+		return
+	}
+	m.rows[u.ID] = row
+}
+
+func (m *UserReadModel) remove(id uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rows, id)
+	m.deleted[id] = true
+}
+
+func updatedAt(s *dto.UserSummary) time.Time {
+	if s.UpdatedAt != nil {
+		return *s.UpdatedAt
+	}
+	return s.CreatedAt
+}
+
+func (m *UserReadModel) List(ctx context.Context, filter dto.UserFilter, limit, offset int) (res []*dto.UserSummary, total int, err error) {
+	ctx, span := startSpan(ctx, "user_summaries.list")
+	defer func() { endSpan(span, res, err) }()
+
+	matches := m.match(filter, nil)
+	slices.SortFunc(matches, func(a, b *dto.UserSummary) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+	total = len(matches)
+	if offset >= total {
+		return []*dto.UserSummary{}, total, nil
+	}
+	return matches[offset:min(offset+limit, total)], total, nil
+}
+
+func (m *UserReadModel) ListAfter(ctx context.Context, filter dto.UserFilter, after *dto.ExportCursor, limit int) (res []*dto.UserSummary, err error) {
+	ctx, span := startSpan(ctx, "user_summaries.list_after")
+	defer func() { endSpan(span, res, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matches := m.match(filter, after)
+	slices.SortFunc(matches, func(a, b *dto.UserSummary) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+	return matches[:min(limit, len(matches))], nil
+}
+
+// match returns copies of the rows matching filter that come after the
+// cursor, unordered.
+func (m *UserReadModel) match(filter dto.UserFilter, after *dto.ExportCursor) []*dto.UserSummary {
+	query := strings.ToLower(filter.Query)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	matches := make([]*dto.UserSummary, 0, len(m.rows))
+	for _, row := range m.rows {
+		if filter.Status != "" && row.Status != filter.Status ||
+			filter.Plan != "" && row.Plan != filter.Plan ||
+			filter.TenantID != "" && row.TenantID != filter.TenantID {
+			continue
+		}
+		if query != "" && !strings.Contains(row.Email, query) && !strings.Contains(row.Username, query) {
+			continue
+		}
+		if !after.Precedes(row.CreatedAt, row.ID) {
+			continue
+		}
+		c := *row
+		matches = append(matches, &c)
+	}
+	return matches
+}
@@ -3,26 +3,238 @@ package infrastructure
 import (
 	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/haidang666/go-app/internal/domain/contract"
 	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
 )
 
 type UserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]entity.User
 }
 
 var _ contract.UserRepository = (*UserRepository)(nil)
 
 func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+	return &UserRepository{
+		users: make(map[uuid.UUID]entity.User),
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, du *entity.User) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.create")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email := strings.ToLower(du.Email)
+	if email != "" && r.findByEmail(email) != nil {
+		return nil, errs.ErrEmailTaken
+	}
+	username := entity.NormalizeUsername(du.Username)
+	if username != "" && r.findByUsername(username) != nil {
+		return nil, errs.ErrUsernameTaken
+	}
+
+	newUser := entity.User{
+		ID:                     uuid.New(),
+		Email:                  email,
+		Username:               username,
+		Phone:                  du.Phone,
+		PhoneVerifiedAt:        du.PhoneVerifiedAt,
+		HashedPassword:         du.HashedPassword,
+		PasswordChangedAt:      du.PasswordChangedAt,
+		PasswordChangeRequired: du.PasswordChangeRequired,
+		PasswordUnset:          du.PasswordUnset,
+		IsGuest:                du.IsGuest,
+		TenantID:               du.TenantID,
+		Status:                 du.Status,
+		StatusChangedAt:        du.StatusChangedAt,
+		Plan:                   du.Plan,
+		CreatedAt:              time.Now().UTC(),
+	}
+	if newUser.Status == "" {
+		newUser.Status = entity.USER_STATUS_ACTIVE
+	}
+	r.users[newUser.ID] = newUser
+	return &newUser, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.get_by_id")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, errs.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]*entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.get_by_ids")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res = make(map[uuid.UUID]*entity.User, len(ids))
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok {
+			res[id] = &u
+		}
+	}
+	return res, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.get_by_email")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u := r.findByEmail(strings.ToLower(email))
+	if email == "" || u == nil {
+		return nil, errs.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.get_by_username")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	username = entity.NormalizeUsername(username)
+	u := r.findByUsername(username)
+	if username == "" || u == nil {
+		return nil, errs.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.get_by_phone")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u := r.findByVerifiedPhone(phone)
+	if u == nil {
+		return nil, errs.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, du *entity.User) (res *entity.User, err error) {
+	ctx, span := startSpan(ctx, "users.update")
+	defer func() { endSpan(span, res, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[du.ID]
+	if !ok {
+		return nil, errs.ErrUserNotFound
+	}
+
+	email := strings.ToLower(du.Email)
+	if other := r.findByEmail(email); email != "" && other != nil && other.ID != du.ID {
+		return nil, errs.ErrEmailTaken
+	}
+	username := entity.NormalizeUsername(du.Username)
+	if other := r.findByUsername(username); username != "" && other != nil && other.ID != du.ID {
+		return nil, errs.ErrUsernameTaken
+	}
+	if du.PhoneVerifiedAt != nil {
+		if other := r.findByVerifiedPhone(du.Phone); other != nil && other.ID != du.ID {
+			return nil, errs.ErrPhoneTaken
+		}
+	}
+
+	now := time.Now().UTC()
+	current.Email = email
+	current.Username = username
+	current.Phone = du.Phone
+	current.PhoneVerifiedAt = du.PhoneVerifiedAt
+	current.HashedPassword = du.HashedPassword
+	current.PasswordChangedAt = du.PasswordChangedAt
+	current.PasswordChangeRequired = du.PasswordChangeRequired
+	current.PasswordUnset = du.PasswordUnset
+	current.IsGuest = du.IsGuest
+	current.Status = du.Status
+	current.StatusChangedAt = du.StatusChangedAt
+	current.AccessRevokedAt = du.AccessRevokedAt
+	current.Plan = du.Plan
+	current.DeletedAt = du.DeletedAt
+	current.PurgeAt = du.PurgeAt
+	current.UpdatedAt = &now
+	r.users[current.ID] = current
+	return &current, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "users.delete")
+	defer func() { endSpan(span, nil, err) }()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return errs.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// Project stores u as is, for the event-sourced repository to keep this
+// table as the current-state projection of the user events.
+func (r *UserRepository) Project(u entity.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[u.ID] = u
+}
+
+// findByEmail expects the caller to hold the lock and pass a lowercase email.
+func (r *UserRepository) findByEmail(email string) *entity.User {
+	for _, u := range r.users {
+		if u.Email == email {
+			return &u
+		}
+	}
+	return nil
+}
+
+// findByUsername expects the caller to hold the lock and pass a normalized
+// username.
+func (r *UserRepository) findByUsername(username string) *entity.User {
+	for _, u := range r.users {
+		if u.Username == username {
+			return &u
+		}
+	}
+	return nil
 }
 
-func (r *UserRepository) Create(ctx context.Context, du *entity.User) (*entity.User, error) {
-	newUser := &entity.User{
-		ID:             uuid.New(),
-		Email:          strings.ToLower(du.Email),
-		HashedPassword: du.HashedPassword,
+// findByVerifiedPhone expects the caller to hold the lock. Unverified numbers
+// are ignored so nobody can squat on a phone they do not own.
+func (r *UserRepository) findByVerifiedPhone(phone string) *entity.User {
+	for _, u := range r.users {
+		if u.Phone == phone && u.PhoneVerifiedAt != nil {
+			return &u
+		}
 	}
-	return newUser, nil
+	return nil
 }
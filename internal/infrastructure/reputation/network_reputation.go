@@ -0,0 +1,36 @@
+package reputation
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+)
+
+// NetworkReputation rates the addresses in a list of networks, such as
+// hosting providers' ranges or known abuse sources, as risky, and every
+// other address as not. It stands in for a reputation service.
+type NetworkReputation struct {
+	networks []netip.Prefix
+	risk     int
+}
+
+var _ contract.IPReputation = (*NetworkReputation)(nil)
+
+func NewNetworkReputation(networks []netip.Prefix, risk int) *NetworkReputation {
+	return &NetworkReputation{networks: networks, risk: risk}
+}
+
+func (r *NetworkReputation) Risk(_ context.Context, ip string) (int, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return 0, nil
+	}
+	addr = addr.Unmap()
+	for _, n := range r.networks {
+		if n.Contains(addr) {
+			return r.risk, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,314 @@
+// Package saml implements the service provider side of the SAML 2.0 web
+// browser SSO profile. Responses must be signed, at the response or the
+// assertion level; encrypted assertions are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/securetoken"
+	"github.com/haidang666/go-app/pkg/xmldsig"
+)
+
+const (
+	NS_METADATA  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	NS_PROTOCOL  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	NS_ASSERTION = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	BINDING_HTTP_POST    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	STATUS_SUCCESS       = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	CONFIRMATION_BEARER  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	NAMEID_EMAIL_ADDRESS = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+type ServiceProviderArgs struct {
+	// BaseURL is the public origin the SAML endpoints are served under.
+	BaseURL string
+	// ClockSkew is tolerated between this service and identity providers.
+	ClockSkew time.Duration
+	// Clock stamps requests and checks assertion validity; nil means the
+	// wall clock.
+	Clock clock.Clock
+}
+
+type ServiceProvider struct {
+	baseURL   string
+	clockSkew time.Duration
+	clock     clock.Clock
+}
+
+var _ contract.SAMLServiceProvider = (*ServiceProvider)(nil)
+
+func NewServiceProvider(args ServiceProviderArgs) *ServiceProvider {
+	return &ServiceProvider{
+		baseURL:   strings.TrimSuffix(args.BaseURL, "/"),
+		clockSkew: args.ClockSkew,
+		clock:     clock.OrReal(args.Clock),
+	}
+}
+
+// EntityID is the service provider's identity towards the tenant's identity
+// provider; it doubles as the metadata URL.
+func (sp *ServiceProvider) EntityID(tenant string) string {
+	return sp.baseURL + "/saml/" + url.PathEscape(tenant) + "/metadata"
+}
+
+func (sp *ServiceProvider) ACSURL(tenant string) string {
+	return sp.baseURL + "/saml/" + url.PathEscape(tenant) + "/acs"
+}
+
+type entityDescriptor struct {
+	XMLName  xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID string   `xml:"entityID,attr"`
+	SP       struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		ACS                        struct {
+			Binding   string `xml:"Binding,attr"`
+			Location  string `xml:"Location,attr"`
+			Index     int    `xml:"index,attr"`
+			IsDefault bool   `xml:"isDefault,attr"`
+		} `xml:"AssertionConsumerService"`
+	} `xml:"SPSSODescriptor"`
+}
+
+func (sp *ServiceProvider) Metadata(conn *entity.SAMLConnection) ([]byte, error) {
+	var md entityDescriptor
+	md.EntityID = sp.EntityID(conn.Tenant)
+	md.SP.WantAssertionsSigned = true
+	md.SP.ProtocolSupportEnumeration = NS_PROTOCOL
+	md.SP.NameIDFormat = NAMEID_EMAIL_ADDRESS
+	md.SP.ACS.Binding = BINDING_HTTP_POST
+	md.SP.ACS.Location = sp.ACSURL(conn.Tenant)
+	md.SP.ACS.IsDefault = true
+
+	out, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      struct {
+		XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+		Value   string   `xml:",chardata"`
+	}
+	NameIDPolicy struct {
+		Format      string `xml:"Format,attr"`
+		AllowCreate bool   `xml:"AllowCreate,attr"`
+	} `xml:"NameIDPolicy"`
+}
+
+func (sp *ServiceProvider) LoginURL(conn *entity.SAMLConnection, relayState string) (_, requestID string, err error) {
+	id, err := securetoken.New(20)
+	if err != nil {
+		return "", "", err
+	}
+
+	req := authnRequest{
+		// IDs are xs:ID values, which cannot start with a digit.
+		ID:                          "_" + id,
+		Version:                     "2.0",
+		IssueInstant:                sp.clock.Now().UTC().Format(time.RFC3339),
+		Destination:                 conn.IdPSSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL(conn.Tenant),
+		ProtocolBinding:             BINDING_HTTP_POST,
+	}
+	req.Issuer.Value = sp.EntityID(conn.Tenant)
+	req.NameIDPolicy.Format = NAMEID_EMAIL_ADDRESS
+	req.NameIDPolicy.AllowCreate = true
+
+	raw, err := xml.Marshal(req)
+	if err != nil {
+		return "", "", err
+	}
+	var deflated bytes.Buffer
+	w, _ := flate.NewWriter(&deflated, flate.BestCompression)
+	w.Write(raw)
+	w.Close()
+
+	target, err := url.Parse(conn.IdPSSOURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := target.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		q.Set("RelayState", relayState)
+	}
+	target.RawQuery = q.Encode()
+	return target.String(), req.ID, nil
+}
+
+func (sp *ServiceProvider) ParseResponse(ctx context.Context, conn *entity.SAMLConnection, encoded string) (*dto.SAMLAssertion, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, invalid("response is not base64")
+	}
+	root, err := xmldsig.Parse(raw)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+	if !root.Is(NS_PROTOCOL, "Response") {
+		return nil, invalid("document is not a SAML response")
+	}
+
+	acs := sp.ACSURL(conn.Tenant)
+	if dest := root.Attr("Destination"); dest != "" && dest != acs {
+		return nil, invalid("response is addressed to another destination")
+	}
+	status := root.Element(NS_PROTOCOL, "Status")
+	if status == nil || status.Element(NS_PROTOCOL, "StatusCode") == nil ||
+		status.Element(NS_PROTOCOL, "StatusCode").Attr("Value") != STATUS_SUCCESS {
+		return nil, invalid("identity provider reported a failure")
+	}
+
+	if root.Element(NS_ASSERTION, "EncryptedAssertion") != nil {
+		return nil, invalid("encrypted assertions are not supported")
+	}
+	assertions := root.Elements(NS_ASSERTION, "Assertion")
+	if len(assertions) != 1 {
+		return nil, invalid("response must carry exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	cert, err := conn.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	// Everything below is read from assertion, which the verified signature
+	// covers either directly or through the enclosing response.
+	if err := xmldsig.Verify(assertion, cert); err != nil {
+		if !errors.Is(err, xmldsig.ErrMissingSignature) {
+			return nil, invalid(err.Error())
+		}
+		if err := xmldsig.Verify(root, cert); err != nil {
+			return nil, invalid(err.Error())
+		}
+	}
+
+	return sp.readAssertion(conn, assertion)
+}
+
+func (sp *ServiceProvider) readAssertion(conn *entity.SAMLConnection, assertion *xmldsig.Element) (*dto.SAMLAssertion, error) {
+	now := sp.clock.Now()
+	out := &dto.SAMLAssertion{
+		ID:         assertion.Attr("ID"),
+		Attributes: make(map[string][]string),
+	}
+	if out.ID == "" {
+		return nil, invalid("assertion has no ID")
+	}
+
+	issuer := assertion.Element(NS_ASSERTION, "Issuer")
+	if issuer == nil || issuer.Text() != conn.IdPEntityID {
+		return nil, invalid("assertion was issued by another identity provider")
+	}
+	out.Issuer = issuer.Text()
+
+	subject := assertion.Element(NS_ASSERTION, "Subject")
+	if subject == nil {
+		return nil, invalid("assertion has no subject")
+	}
+	if nameID := subject.Element(NS_ASSERTION, "NameID"); nameID != nil {
+		out.NameID, out.NameIDFormat = nameID.Text(), nameID.Attr("Format")
+	}
+	confirmed := false
+	for _, sc := range subject.Elements(NS_ASSERTION, "SubjectConfirmation") {
+		data := sc.Element(NS_ASSERTION, "SubjectConfirmationData")
+		if sc.Attr("Method") != CONFIRMATION_BEARER || data == nil || data.Attr("Recipient") != sp.ACSURL(conn.Tenant) {
+			continue
+		}
+		notOnOrAfter, err := parseTime(data.Attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(sp.clockSkew)) {
+			continue
+		}
+		confirmed = true
+		out.ExpiresAt = notOnOrAfter
+		out.InResponseTo = data.Attr("InResponseTo")
+		break
+	}
+	if !confirmed {
+		return nil, invalid("assertion has no valid bearer confirmation for this service")
+	}
+
+	conditions := assertion.Element(NS_ASSERTION, "Conditions")
+	if conditions == nil {
+		return nil, invalid("assertion has no conditions")
+	}
+	if v := conditions.Attr("NotBefore"); v != "" {
+		notBefore, err := parseTime(v)
+		if err != nil || now.Add(sp.clockSkew).Before(notBefore) {
+			return nil, invalid("assertion is not yet valid")
+		}
+	}
+	if v := conditions.Attr("NotOnOrAfter"); v != "" {
+		notOnOrAfter, err := parseTime(v)
+		if err != nil || !now.Before(notOnOrAfter.Add(sp.clockSkew)) {
+			return nil, invalid("assertion has expired")
+		}
+		if notOnOrAfter.Before(out.ExpiresAt) {
+			out.ExpiresAt = notOnOrAfter
+		}
+	}
+	// Every audience restriction must name this service.
+	restrictions := conditions.Elements(NS_ASSERTION, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, invalid("assertion is not restricted to an audience")
+	}
+	for _, ar := range restrictions {
+		ok := false
+		for _, aud := range ar.Elements(NS_ASSERTION, "Audience") {
+			ok = ok || aud.Text() == sp.EntityID(conn.Tenant)
+		}
+		if !ok {
+			return nil, invalid("assertion is meant for another audience")
+		}
+	}
+
+	if authn := assertion.Element(NS_ASSERTION, "AuthnStatement"); authn != nil {
+		out.SessionIndex = authn.Attr("SessionIndex")
+	}
+	for _, statement := range assertion.Elements(NS_ASSERTION, "AttributeStatement") {
+		for _, attr := range statement.Elements(NS_ASSERTION, "Attribute") {
+			name := attr.Attr("Name")
+			for _, v := range attr.Elements(NS_ASSERTION, "AttributeValue") {
+				out.Attributes[name] = append(out.Attributes[name], v.Text())
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	return time.Parse(time.RFC3339, v)
+}
+
+func invalid(reason string) error {
+	return fmt.Errorf("%w: %s", errs.ErrInvalidSAMLResponse, reason)
+}
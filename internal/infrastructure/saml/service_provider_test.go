@@ -0,0 +1,194 @@
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/xmldsig/xmldsigtest"
+)
+
+const (
+	testIdP       = "https://idp.example"
+	testSkew      = time.Minute
+	testValidity  = 5 * time.Minute
+	testNameID    = "alice@example.com"
+	assertionTmpl = `<saml:Assertion xmlns:saml="` + NS_ASSERTION + `" ID="%s" Version="2.0" IssueInstant="%s">` +
+		`<saml:Issuer>` + testIdP + `</saml:Issuer>` +
+		`<saml:Subject><saml:NameID Format="` + NAMEID_EMAIL_ADDRESS + `">%s</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="` + CONFIRMATION_BEARER + `">` +
+		`<saml:SubjectConfirmationData Recipient="%s" NotOnOrAfter="%s"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="%s" NotOnOrAfter="%s">` +
+		`<saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AuthnStatement SessionIndex="_session"></saml:AuthnStatement>` +
+		`</saml:Assertion>`
+)
+
+var issued = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	sp   *ServiceProvider
+	clk  *clock.Fake
+	conn *entity.SAMLConnection
+	key  *rsa.PrivateKey
+}
+
+func newFixture(t *testing.T) *fixture {
+	key, cert := xmldsigtest.NewCertificate(t)
+	clk := clock.NewFake(issued)
+	return &fixture{
+		sp:  NewServiceProvider(ServiceProviderArgs{BaseURL: "https://sp.example", ClockSkew: testSkew, Clock: clk}),
+		clk: clk,
+		conn: &entity.SAMLConnection{
+			Tenant:         "acme",
+			IdPEntityID:    testIdP,
+			IdPCertificate: xmldsigtest.PEM(cert),
+		},
+		key: key,
+	}
+}
+
+// assertion returns an assertion for nameID, valid from issued for
+// testValidity.
+func (f *fixture) assertion(id, nameID string) string {
+	stamp := func(t time.Time) string { return t.Format(time.RFC3339) }
+	expires := stamp(issued.Add(testValidity))
+	return fmt.Sprintf(assertionTmpl, id, stamp(issued), nameID,
+		f.sp.ACSURL(f.conn.Tenant), expires, stamp(issued), expires, f.sp.EntityID(f.conn.Tenant))
+}
+
+// response wraps the given children in a successful response.
+func (f *fixture) response(id string, children ...string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<samlp:Response xmlns:samlp="%s" ID="%s" Version="2.0" Destination="%s">`,
+		NS_PROTOCOL, id, f.sp.ACSURL(f.conn.Tenant))
+	fmt.Fprintf(&b, `<samlp:Status><samlp:StatusCode Value="%s"></samlp:StatusCode></samlp:Status>`, STATUS_SUCCESS)
+	for _, c := range children {
+		b.WriteString(c)
+	}
+	b.WriteString(`</samlp:Response>`)
+	return b.Bytes()
+}
+
+func (f *fixture) sign(t *testing.T, doc []byte, id string) []byte {
+	return xmldsigtest.Sign(t, doc, id, f.key)
+}
+
+func (f *fixture) parse(conn *entity.SAMLConnection, doc []byte) (string, error) {
+	out, err := f.sp.ParseResponse(context.Background(), conn, base64.StdEncoding.EncodeToString(doc))
+	if err != nil {
+		return "", err
+	}
+	return out.NameID, nil
+}
+
+func TestParseResponseSignatures(t *testing.T) {
+	f := newFixture(t)
+	_, otherCert := xmldsigtest.NewCertificate(t)
+	other := *f.conn
+	other.IdPCertificate = xmldsigtest.PEM(otherCert)
+
+	signedAssertion := string(f.sign(t, []byte(f.assertion("_a", testNameID)), "_a"))
+	evil := f.assertion("_evil", "mallory@example.com")
+
+	for _, tc := range []struct {
+		name string
+		doc  []byte
+		conn *entity.SAMLConnection
+		ok   bool
+	}{
+		{name: "signed assertion", doc: f.response("_r", signedAssertion), ok: true},
+		{
+			name: "unsigned assertion in a signed response",
+			doc:  f.sign(t, f.response("_r", f.assertion("_a", testNameID)), "_r"),
+			ok:   true,
+		},
+		{name: "nothing signed", doc: f.response("_r", f.assertion("_a", testNameID))},
+		{name: "wrong certificate", doc: f.response("_r", signedAssertion), conn: &other},
+		{
+			name: "assertion changed after signing",
+			doc:  bytes.Replace(f.response("_r", signedAssertion), []byte(testNameID), []byte("mallory@example.com"), 1),
+		},
+		{
+			name: "assertion swapped in a signed response",
+			doc: bytes.Replace(f.sign(t, f.response("_r", f.assertion("_a", testNameID)), "_r"),
+				[]byte(testNameID), []byte("mallory@example.com"), 1),
+		},
+		{
+			// The signed assertion is moved where it is not read; the
+			// one read is not signed.
+			name: "signed assertion moved aside",
+			doc:  f.response("_r", `<samlp:Extensions>`+signedAssertion+`</samlp:Extensions>`, evil),
+		},
+		{
+			// A signed response is moved inside an unsigned one carrying
+			// another assertion.
+			name: "signed response moved aside",
+			doc: f.response("_outer", `<samlp:Extensions>`+
+				string(f.sign(t, f.response("_r", f.assertion("_a", testNameID)), "_r"))+
+				`</samlp:Extensions>`, evil),
+		},
+		{
+			name: "signature copied to another assertion",
+			doc:  f.response("_r", copySignature(t, signedAssertion, evil)),
+		},
+		{name: "two assertions", doc: f.response("_r", signedAssertion, signedAssertion)},
+	} {
+		conn := f.conn
+		if tc.conn != nil {
+			conn = tc.conn
+		}
+		nameID, err := f.parse(conn, tc.doc)
+		switch {
+		case tc.ok && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.ok && nameID != testNameID:
+			t.Errorf("%s: name ID = %q, want %q", tc.name, nameID, testNameID)
+		case !tc.ok && !errors.Is(err, errs.ErrInvalidSAMLResponse):
+			t.Errorf("%s: err = %v, want ErrInvalidSAMLResponse", tc.name, err)
+		}
+	}
+}
+
+// copySignature moves the signature of signed into the start of target.
+func copySignature(t *testing.T, signed, target string) string {
+	t.Helper()
+	start := strings.Index(signed, "<ds:Signature")
+	end := strings.Index(signed, "</ds:Signature>") + len("</ds:Signature>")
+	at := strings.IndexByte(target, '>') + 1
+	if start < 0 || at <= 0 {
+		t.Fatal("fixture has no signature")
+	}
+	return target[:at] + signed[start:end] + target[at:]
+}
+
+func TestParseResponseValidity(t *testing.T) {
+	f := newFixture(t)
+	doc := f.response("_r", string(f.sign(t, []byte(f.assertion("_a", testNameID)), "_a")))
+
+	for _, tc := range []struct {
+		at time.Time
+		ok bool
+	}{
+		{at: issued.Add(-testSkew - time.Second)},
+		{at: issued.Add(-testSkew), ok: true},
+		{at: issued.Add(testValidity + testSkew - time.Second), ok: true},
+		{at: issued.Add(testValidity + testSkew)},
+	} {
+		f.clk.Set(tc.at)
+		if _, err := f.parse(f.conn, doc); (err == nil) != tc.ok {
+			t.Errorf("at %v: err = %v, want ok = %v", tc.at.Sub(issued), err, tc.ok)
+		}
+	}
+}
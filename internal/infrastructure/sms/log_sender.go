@@ -0,0 +1,27 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// LogSender writes outgoing text messages to the application log instead of
+// delivering them. It stands in until an SMS gateway is wired.
+type LogSender struct{}
+
+var _ contract.SMSSender = (*LogSender)(nil)
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg dto.SMSMessage) error {
+	logger.L().Infow("sms sent",
+		"to", msg.To,
+		"body", msg.Body,
+	)
+	return nil
+}
@@ -0,0 +1,173 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/internal/domain/errs"
+	"github.com/haidang666/go-app/pkg/jwt"
+)
+
+// JWTIssuer issues access/refresh token pairs as signed JWTs.
+type JWTIssuer struct {
+	client *jwt.Client
+	// admins holds the lower-cased emails granted ROLE_ADMIN.
+	admins map[string]struct{}
+	// organizations holds the default role of each tenant's members; nil
+	// grants none.
+	organizations contract.OrganizationSettingsRepository
+}
+
+var (
+	_ contract.TokenIssuer         = (*JWTIssuer)(nil)
+	_ contract.ClientTokenIssuer   = (*JWTIssuer)(nil)
+	_ contract.AccessTokenVerifier = (*JWTIssuer)(nil)
+)
+
+// NewJWTIssuer returns an issuer whose user tokens carry ROLE_ADMIN for the
+// accounts with one of adminEmails, and the default member role of their
+// organization in organizations, if any.
+func NewJWTIssuer(client *jwt.Client, adminEmails []string, organizations contract.OrganizationSettingsRepository) *JWTIssuer {
+	admins := make(map[string]struct{}, len(adminEmails))
+	for _, email := range adminEmails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			admins[email] = struct{}{}
+		}
+	}
+	return &JWTIssuer{client: client, admins: admins, organizations: organizations}
+}
+
+func (i *JWTIssuer) IssueTokens(ctx context.Context, u *entity.User, sessionID uuid.UUID) (*dto.AuthTokens, error) {
+	subject := jwt.Subject{UserID: u.ID.String(), Email: u.Email, TenantID: u.TenantID, SessionID: sessionID.String()}
+	if u.IsGuest {
+		subject.Scope = entity.SCOPE_GUEST
+	} else if _, ok := i.admins[strings.ToLower(u.Email)]; ok {
+		subject.Roles = []string{entity.ROLE_ADMIN}
+	}
+	if i.organizations != nil && u.TenantID != "" && !u.IsGuest {
+		settings, err := i.organizations.Get(ctx, u.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.DefaultMemberRole != "" {
+			subject.Roles = append(subject.Roles, settings.DefaultMemberRole)
+		}
+	}
+
+	access, accessClaims, err := i.client.Issue(jwt.TOKEN_TYPE_ACCESS, subject)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshClaims, err := i.client.Issue(jwt.TOKEN_TYPE_REFRESH, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthTokens{
+		AccessToken:      access,
+		AccessExpiresAt:  accessClaims.ExpiresAt.Time,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshClaims.ExpiresAt.Time,
+		TokenType:        "Bearer",
+		RefreshTokenID:   refreshClaims.ID,
+	}, nil
+}
+
+func (i *JWTIssuer) IssueImpersonationToken(ctx context.Context, u *entity.User, actorID, sessionID uuid.UUID, ttl time.Duration) (*dto.ImpersonationToken, error) {
+	access, claims, err := i.client.Issue(jwt.TOKEN_TYPE_ACCESS, jwt.Subject{
+		UserID:    u.ID.String(),
+		Email:     u.Email,
+		TenantID:  u.TenantID,
+		SessionID: sessionID.String(),
+		ActorID:   actorID.String(),
+		TTL:       ttl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ImpersonationToken{
+		AccessToken:     access,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		TokenType:       "Bearer",
+		SessionID:       sessionID,
+	}, nil
+}
+
+func (i *JWTIssuer) VerifyRefreshToken(ctx context.Context, token string) (*dto.RefreshTokenClaims, error) {
+	claims, err := i.client.VerifyType(token, jwt.TOKEN_TYPE_REFRESH)
+	if err != nil {
+		return nil, errs.ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		return nil, errs.ErrInvalidToken
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, errs.ErrInvalidToken
+	}
+
+	return &dto.RefreshTokenClaims{UserID: userID, SessionID: sessionID, TokenID: claims.ID}, nil
+}
+
+func (i *JWTIssuer) VerifyAccessToken(ctx context.Context, token string) (*dto.AccessTokenClaims, error) {
+	claims, err := i.client.Verify(token)
+	if err != nil {
+		return nil, errs.ErrInvalidToken
+	}
+	var kind string
+	switch claims.TokenType {
+	case jwt.TOKEN_TYPE_ACCESS:
+		kind = dto.TOKEN_KIND_ACCESS
+	case jwt.TOKEN_TYPE_DELEGATED:
+		kind = dto.TOKEN_KIND_DELEGATED
+	case jwt.TOKEN_TYPE_CLIENT:
+		kind = dto.TOKEN_KIND_CLIENT
+	default:
+		return nil, errs.ErrInvalidToken
+	}
+
+	sessionID, _ := uuid.Parse(claims.SessionID)
+	out := &dto.AccessTokenClaims{
+		Kind:      kind,
+		Subject:   claims.UserID(),
+		ClientID:  claims.ClientID,
+		SessionID: sessionID,
+		Scopes:    claims.Scopes(),
+		Roles:     claims.Roles,
+		TenantID:  claims.TenantID,
+		ActorID:   claims.ActorID(),
+		TokenID:   claims.ID,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+	}
+	if claims.IssuedAt != nil {
+		out.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		out.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return out, nil
+}
+
+func (i *JWTIssuer) IssueClientToken(ctx context.Context, client *entity.OAuthClient, scopes []string) (*dto.ClientToken, error) {
+	scope := strings.Join(scopes, " ")
+	token, claims, err := i.client.Issue(jwt.TOKEN_TYPE_CLIENT, jwt.Subject{UserID: client.ClientID, Scope: scope})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ClientToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(claims.ExpiresAt.Sub(claims.IssuedAt.Time).Seconds()),
+		Scope:       scope,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	jwtV5 "github.com/golang-jwt/jwt/v5"
+	"github.com/haidang666/go-app/internal/domain/contract"
+	"github.com/haidang666/go-app/internal/domain/dto"
+	"github.com/haidang666/go-app/internal/domain/entity"
+	"github.com/haidang666/go-app/pkg/jwt"
+)
+
+var ErrUnsupportedKey = errors.New("signing key must be an RSA private key")
+
+type OIDCIssuerArgs struct {
+	// JWTClient signs the delegated access tokens, which only this service
+	// verifies.
+	JWTClient *jwt.Client
+	// SigningKey signs ID tokens, which relying parties verify against the
+	// published JWKS.
+	SigningKey *rsa.PrivateKey
+	Issuer     string
+	IDTokenTTL time.Duration
+}
+
+// OIDCIssuer issues the tokens of the OpenID Connect code flow.
+type OIDCIssuer struct {
+	jwtClient  *jwt.Client
+	signingKey *rsa.PrivateKey
+	keyID      string
+	issuer     string
+	idTokenTTL time.Duration
+}
+
+var _ contract.OIDCTokenIssuer = (*OIDCIssuer)(nil)
+
+func NewOIDCIssuer(args OIDCIssuerArgs) *OIDCIssuer {
+	return &OIDCIssuer{
+		jwtClient:  args.JWTClient,
+		signingKey: args.SigningKey,
+		keyID:      keyID(&args.SigningKey.PublicKey),
+		issuer:     args.Issuer,
+		idTokenTTL: args.IDTokenTTL,
+	}
+}
+
+// idTokenClaims are the OpenID Connect Core ID token claims.
+type idTokenClaims struct {
+	jwtV5.RegisteredClaims
+	AuthorizedParty   string `json:"azp"`
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time"`
+	Email             string `json:"email,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+func (i *OIDCIssuer) IssueDelegatedToken(ctx context.Context, u *entity.User, code *entity.AuthorizationCode) (*dto.ClientToken, error) {
+	scope := strings.Join(code.Scopes, " ")
+	token, claims, err := i.jwtClient.Issue(jwt.TOKEN_TYPE_DELEGATED, jwt.Subject{
+		UserID:    u.ID.String(),
+		SessionID: code.SessionID.String(),
+		Scope:     scope,
+		ClientID:  code.ClientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ClientToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(claims.ExpiresAt.Sub(claims.IssuedAt.Time).Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func (i *OIDCIssuer) IssueIDToken(ctx context.Context, u *entity.User, code *entity.AuthorizationCode) (string, error) {
+	now := time.Now()
+	claims := idTokenClaims{
+		RegisteredClaims: jwtV5.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   u.ID.String(),
+			Audience:  jwtV5.ClaimStrings{code.ClientID},
+			IssuedAt:  jwtV5.NewNumericDate(now),
+			ExpiresAt: jwtV5.NewNumericDate(now.Add(i.idTokenTTL)),
+		},
+		AuthorizedParty: code.ClientID,
+		Nonce:           code.Nonce,
+		AuthTime:        code.AuthTime.Unix(),
+	}
+	for _, s := range code.Scopes {
+		switch s {
+		case entity.SCOPE_EMAIL:
+			claims.Email = u.Email
+		case entity.SCOPE_PROFILE:
+			claims.PreferredUsername = u.Username
+		}
+	}
+
+	token := jwtV5.NewWithClaims(jwtV5.SigningMethodRS256, claims)
+	token.Header["kid"] = i.keyID
+	return token.SignedString(i.signingKey)
+}
+
+func (i *OIDCIssuer) Issuer() string {
+	return i.issuer
+}
+
+func (i *OIDCIssuer) JWKS() *dto.JWKS {
+	pub := &i.signingKey.PublicKey
+	return &dto.JWKS{Keys: []dto.JWK{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwtV5.SigningMethodRS256.Alg(),
+		KeyID:     i.keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}}
+}
+
+// LoadSigningKey reads a PEM-encoded RSA private key (PKCS#1 or PKCS#8).
+// An empty path generates an ephemeral key, which invalidates issued ID
+// tokens on every restart and differs between replicas.
+func LoadSigningKey(path string) (key *rsa.PrivateKey, ephemeral bool, err error) {
+	if path == "" {
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		return key, true, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, false, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, false, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, false, err
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, false, ErrUnsupportedKey
+	}
+	return rsaKey, false, nil
+}
+
+// keyID derives a stable key ID from the public key so rotating the key
+// changes the kid.
+func keyID(pub *rsa.PublicKey) string {
+	der := x509.MarshalPKCS1PublicKey(pub)
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,235 @@
+// Package anomaly tells when the failing share of the attempts of a key,
+// e.g. the sign-ins from a network, spikes, and when it has subsided again.
+// Attempts are counted in memory over a sliding window, so each instance
+// judges the traffic it serves.
+package anomaly
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+// relaxFactor scales the Rule of an anomalous key for it to end: its
+// failures have to fall below half of either threshold, so traffic hovering
+// around them does not flap.
+const relaxFactor = 0.5
+
+// sweepEvery is how many records pass between sweeps of the idle keys.
+const sweepEvery = 1024
+
+// Rule is when the traffic of a key is anomalous: at least MinFailures
+// failed attempts in the window, making up at least Ratio of its attempts.
+type Rule struct {
+	MinFailures int
+	Ratio       float64
+}
+
+// Change is a key becoming anomalous or normal again, with the estimated
+// counts of the window that decided it.
+type Change struct {
+	Key       string
+	Anomalous bool
+	Failures  int
+	Attempts  int
+}
+
+type Args struct {
+	// Window is the sliding span attempts are counted over.
+	Window time.Duration
+	// Cooldown is how long an anomaly lasts at least once detected.
+	Cooldown time.Duration
+	// MaxKeys bounds the keys tracked; the attempts of new keys beyond it
+	// are not counted until idle ones are dropped.
+	MaxKeys int
+	// OnChange, when set, is called outside the lock for every change.
+	OnChange func(Change)
+	// Clock places attempts in their windows; nil means the wall clock.
+	Clock clock.Clock
+}
+
+// Detector tracks the attempts of keys. An anomaly ends at the first record
+// or check of its key after it has subsided, or at a sweep.
+type Detector struct {
+	window   time.Duration
+	cooldown time.Duration
+	maxKeys  int
+	onChange func(Change)
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	keys    map[string]*counter
+	records int
+}
+
+// counter is a key's attempts in the current window bucket and the one
+// before.
+type counter struct {
+	rule  Rule
+	start time.Time
+	cur   tally
+	prev  tally
+	// since is when the anomaly started; zero while the key is normal.
+	since time.Time
+}
+
+type tally struct {
+	attempts int
+	failures int
+}
+
+func New(args Args) (*Detector, error) {
+	if args.Window <= 0 {
+		return nil, errors.New("anomaly: window must be positive")
+	}
+	if args.MaxKeys <= 0 {
+		return nil, errors.New("anomaly: max keys must be positive")
+	}
+	return &Detector{
+		window:   args.Window,
+		cooldown: args.Cooldown,
+		maxKeys:  args.MaxKeys,
+		onChange: args.OnChange,
+		clock:    clock.OrReal(args.Clock),
+		keys:     make(map[string]*counter),
+	}, nil
+}
+
+// Validate checks that r can be met: MinFailures of at least 1 and Ratio in
+// (0, 1].
+func (r Rule) Validate() error {
+	if r.MinFailures < 1 || r.Ratio <= 0 || r.Ratio > 1 {
+		return errors.New("anomaly: a rule needs at least 1 failure and a ratio in (0, 1]")
+	}
+	return nil
+}
+
+// Record counts an attempt of key, judged by rule.
+func (d *Detector) Record(key string, rule Rule, failed bool) {
+	now := d.clock.Now()
+	var changes []Change
+
+	d.mu.Lock()
+	d.records++
+	if d.records%sweepEvery == 0 {
+		changes = d.sweep(now)
+	}
+	c, ok := d.keys[key]
+	if !ok {
+		if len(d.keys) >= d.maxKeys {
+			changes = append(changes, d.sweep(now)...)
+		}
+		if len(d.keys) >= d.maxKeys {
+			d.mu.Unlock()
+			d.notify(changes)
+			return
+		}
+		c = &counter{start: now}
+		d.keys[key] = c
+	}
+	c.rule = rule
+	c.roll(now, d.window)
+	c.cur.attempts++
+	if failed {
+		c.cur.failures++
+	}
+	if change, ok := d.evaluate(key, c, now); ok {
+		changes = append(changes, change)
+	}
+	d.mu.Unlock()
+
+	d.notify(changes)
+}
+
+// Anomalous reports whether key is anomalous.
+func (d *Detector) Anomalous(key string) bool {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	c, ok := d.keys[key]
+	if !ok || c.since.IsZero() {
+		d.mu.Unlock()
+		return false
+	}
+	c.roll(now, d.window)
+	change, changed := d.evaluate(key, c, now)
+	d.mu.Unlock()
+
+	if changed {
+		d.notify([]Change{change})
+	}
+	return !changed
+}
+
+// evaluate starts or ends the anomaly of c as its estimated counts warrant.
+func (d *Detector) evaluate(key string, c *counter, now time.Time) (Change, bool) {
+	failures, attempts := c.estimate(now, d.window)
+	rule := c.rule
+	if c.since.IsZero() {
+		if failures < float64(rule.MinFailures) || failures < rule.Ratio*attempts {
+			return Change{}, false
+		}
+		c.since = now
+	} else {
+		if now.Sub(c.since) < d.cooldown {
+			return Change{}, false
+		}
+		if failures >= relaxFactor*float64(rule.MinFailures) && failures >= relaxFactor*rule.Ratio*attempts {
+			return Change{}, false
+		}
+		c.since = time.Time{}
+	}
+	return Change{Key: key, Anomalous: !c.since.IsZero(), Failures: int(failures), Attempts: int(attempts)}, true
+}
+
+// sweep ends the anomalies that have subsided and drops the keys without
+// attempts in the window.
+func (d *Detector) sweep(now time.Time) []Change {
+	var changes []Change
+	for key, c := range d.keys {
+		c.roll(now, d.window)
+		if !c.since.IsZero() {
+			if change, ok := d.evaluate(key, c, now); ok {
+				changes = append(changes, change)
+			}
+		}
+		if c.since.IsZero() && c.cur.attempts == 0 && c.prev.attempts == 0 {
+			delete(d.keys, key)
+		}
+	}
+	return changes
+}
+
+func (d *Detector) notify(changes []Change) {
+	if d.onChange == nil {
+		return
+	}
+	for _, change := range changes {
+		d.onChange(change)
+	}
+}
+
+// roll moves c to the bucket now falls in.
+func (c *counter) roll(now time.Time, window time.Duration) {
+	elapsed := now.Sub(c.start)
+	if elapsed < window {
+		return
+	}
+	if elapsed < 2*window {
+		c.prev, c.cur = c.cur, tally{}
+	} else {
+		c.prev, c.cur = tally{}, tally{}
+	}
+	c.start = c.start.Add(elapsed.Truncate(window))
+}
+
+// estimate returns the failures and attempts of the window ending now,
+// counting the previous bucket for the share of it the window still covers.
+func (c *counter) estimate(now time.Time, window time.Duration) (failures, attempts float64) {
+	weight := 1 - float64(now.Sub(c.start))/float64(window)
+	failures = float64(c.cur.failures) + weight*float64(c.prev.failures)
+	attempts = float64(c.cur.attempts) + weight*float64(c.prev.attempts)
+	return failures, attempts
+}
@@ -0,0 +1,138 @@
+// Package apperr builds errors carrying a stable machine-readable code,
+// metadata fields and, outside production, the stack they were created at.
+// The HTTP problem renderer reads the code and fields; Report logs all of it.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync/atomic"
+)
+
+var captureStacks atomic.Bool
+
+// CaptureStacks turns stack capture on or off for errors created afterwards.
+// It costs an allocation per error, so it is meant for non-production
+// environments.
+func CaptureStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// Error is an error with a code. Build it with New, Errorf or Wrap and add
+// metadata with With; it is immutable once returned.
+type Error struct {
+	code   string
+	msg    string
+	cause  error
+	fields map[string]any
+	stack  []uintptr
+}
+
+// New returns an error with code and message. Package-level sentinels built
+// with New match their copies made by With in errors.Is.
+func New(code, message string) *Error {
+	return &Error{code: code, msg: message, stack: callers()}
+}
+
+// Errorf formats the message like fmt.Errorf, wrapping the operands of %w.
+func Errorf(code, format string, args ...any) *Error {
+	wrapped := fmt.Errorf(format, args...)
+	e := &Error{code: code, msg: wrapped.Error(), stack: callers()}
+	switch u := wrapped.(type) {
+	case interface{ Unwrap() error }:
+		e.cause = u.Unwrap()
+	case interface{ Unwrap() []error }:
+		// Keep fmt's error as the cause so every %w operand still matches.
+		e.cause = wrapped
+	}
+	return e
+}
+
+// Wrap gives err a code and a message prefix; err must not be nil.
+func Wrap(err error, code, message string) *Error {
+	return &Error{code: code, msg: message + ": " + err.Error(), cause: err, stack: callers()}
+}
+
+// With returns a copy with the key-value pairs added to its fields and a
+// stack captured at the call, so returning a sentinel With details points
+// at the right place.
+func (e *Error) With(keyvals ...any) *Error {
+	c := *e
+	c.fields = maps.Clone(e.fields)
+	if c.fields == nil {
+		c.fields = make(map[string]any, len(keyvals)/2)
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		c.fields[fmt.Sprint(keyvals[i])] = keyvals[i+1]
+	}
+	c.stack = callers()
+	return &c
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches another *Error with the same code, so copies made by With
+// still match their sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.code == e.code
+}
+
+func (e *Error) ErrorCode() string {
+	return e.code
+}
+
+// ErrorDetails exposes the fields to clients in the problem's details.
+func (e *Error) ErrorDetails() map[string]any {
+	if len(e.fields) == 0 {
+		return nil
+	}
+	return maps.Clone(e.fields)
+}
+
+// Code returns the code of the outermost *Error in err's chain, or "".
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ""
+}
+
+// Fields merges the fields of every *Error in err's chain; outer errors win.
+func Fields(err error) map[string]any {
+	out := make(map[string]any)
+	walk(err, func(e *Error) {
+		for k, v := range e.fields {
+			if _, ok := out[k]; !ok {
+				out[k] = v
+			}
+		}
+	})
+	return out
+}
+
+// walk calls fn for every *Error in err's chain, outermost first.
+func walk(err error, fn func(*Error)) {
+	if err == nil {
+		return
+	}
+	if e, ok := err.(*Error); ok {
+		fn(e)
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		walk(u.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			walk(inner, fn)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package apperr
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"go.uber.org/zap"
+)
+
+const maxStackDepth = 32
+
+// callers captures the stack above New, Errorf, Wrap or With when capture
+// is enabled.
+func callers() []uintptr {
+	if !captureStacks.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	// Skip runtime.Callers, callers and the constructor.
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// Stack formats the stack of the innermost *Error in err's chain that has
+// one, the closest to where the failure happened, or "" without one.
+func Stack(err error) string {
+	var stack []uintptr
+	walk(err, func(e *Error) {
+		if len(e.stack) > 0 {
+			stack = e.stack
+		}
+	})
+	if len(stack) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte('\n')
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// LogFields returns err's code, fields and stack as key-value pairs for a
+// sugared logger.
+func LogFields(err error) []any {
+	kv := []any{"error", err.Error()}
+	if code := Code(err); code != "" {
+		kv = append(kv, "error_code", code)
+	}
+	if fields := Fields(err); len(fields) > 0 {
+		kv = append(kv, "error_fields", fields)
+	}
+	if stack := Stack(err); stack != "" {
+		kv = append(kv, "error_stack", stack)
+	}
+	return kv
+}
+
+// Report logs err with everything it carries on the request-scoped logger.
+func Report(ctx context.Context, msg string, err error) {
+	ctxutil.Logger(ctx).WithOptions(zap.AddCallerSkip(1)).Errorw(msg, LogFields(err)...)
+}
@@ -0,0 +1,49 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Populated at build time, e.g.
+//
+//	go build -ldflags "-X github.com/haidang666/go-app/pkg/buildinfo.Version=v1.2.3"
+var (
+	Version   = "dev"
+	GitSHA    = ""
+	BuildTime = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	GitSHA    string `json:"git_sha"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata, falling back to the VCS stamp embedded by
+// the Go toolchain when the ldflags were not set.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitSHA:    GitSHA,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.GitSHA == "" {
+					info.GitSHA = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			}
+		}
+	}
+	return info
+}
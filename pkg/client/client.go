@@ -0,0 +1,340 @@
+// Package client is the Go SDK of the public API. The types and endpoint
+// methods in client_gen.go are generated from api/openapi.yaml, as is the
+// TypeScript client in ts/client.ts; this file holds the transport they
+// share: authentication, token refresh, retries and problem errors. Every
+// method takes a context, which bounds the request and its retries.
+package client
+
+//go:generate go run ./internal/genclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the bearer token of each request; "" sends none.
+type TokenSource func(ctx context.Context) (string, error)
+
+// RetryPolicy retries requests failing with a network error or a 429, 502,
+// 503 or 504. Requests that may have changed something, i.e. other than
+// GET, HEAD, PUT and DELETE, are retried only on a 429 or 503, which the
+// server answers before handling them.
+type RetryPolicy struct {
+	// MaxAttempts includes the first; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay doubles with each attempt, with jitter, up to MaxDelay. A
+	// Retry-After header takes precedence.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   TokenSource
+	// invalidate drops an access token the server answered 401 to.
+	invalidate func(token string)
+	retry      RetryPolicy
+	envelope   bool
+	header     http.Header
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken authenticates every request with a fixed access token.
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) { return token, nil })
+}
+
+// WithTokenSource authenticates every request with the token ts returns.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.token = ts }
+}
+
+// WithRefreshingTokens authenticates every request with t, and on a 401
+// has t refresh the token and sends the request once more, as happens when
+// a session is revoked before the token expires.
+func WithRefreshingTokens(t *RefreshingTokens) Option {
+	return func(c *Client) {
+		c.token = t.Token
+		c.invalidate = t.Invalidate
+	}
+}
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithEnvelope unwraps the {"data": ...} envelope of servers that have it
+// enabled for the API version (APP_ENVELOPE_VERSIONS).
+func WithEnvelope() Option {
+	return func(c *Client) { c.envelope = true }
+}
+
+// WithHeader adds a header to every request, e.g. X-Request-ID.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// New returns a client of the API at baseURL, including the version prefix,
+// e.g. https://api.example.com/api/v1.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+		retry:   DefaultRetryPolicy,
+		header:  make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a failed request, carrying the server's problem details.
+type Error struct {
+	StatusCode int
+	Problem    Problem
+}
+
+func (e *Error) Error() string {
+	msg := e.Problem.Detail
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Problem.Code != "" {
+		return fmt.Sprintf("api: %d %s (%s)", e.StatusCode, msg, e.Problem.Code)
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, msg)
+}
+
+// IsCode reports whether err is an API error with the given problem code,
+// e.g. "contract_violation".
+func IsCode(err error, code string) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Problem.Code == code
+}
+
+// do sends in, when not nil, as the JSON body of a request and decodes the
+// response into out, when not nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("api: encode request: %w", err)
+		}
+	}
+
+	attempts := max(c.retry.MaxAttempts, 1)
+	reauthenticated := false
+	for attempt := 1; ; attempt++ {
+		token, err := c.bearer(ctx)
+		if err != nil {
+			return err
+		}
+		res, err := c.send(ctx, method, path, body, token)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= attempts || !idempotent(method) {
+				return err
+			}
+			if werr := c.wait(ctx, attempt, nil); werr != nil {
+				return err
+			}
+			continue
+		}
+		if res.StatusCode == http.StatusUnauthorized && c.invalidate != nil && token != "" && !reauthenticated {
+			// The server rejects a bad token before handling the request,
+			// so it is safe to send again whatever the method, and does not
+			// count as a retry.
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			c.invalidate(token)
+			reauthenticated = true
+			attempt--
+			continue
+		}
+		if attempt < attempts && retryable(method, res.StatusCode) {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			if werr := c.wait(ctx, attempt, res); werr != nil {
+				return werr
+			}
+			continue
+		}
+		return c.decode(res, out)
+	}
+}
+
+// bearer returns the token of the next request, "" for none.
+func (c *Client) bearer(ctx context.Context) (string, error) {
+	if c.token == nil {
+		return "", nil
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("api: token: %w", err)
+	}
+	return token, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte, token string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("api: %w", err)
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.http.Do(req)
+}
+
+func (c *Client) decode(res *http.Response, out any) error {
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		apiErr := &Error{StatusCode: res.StatusCode}
+		// A body that is not a problem still leaves the status to report.
+		json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&apiErr.Problem)
+		return apiErr
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if c.envelope {
+		out = &struct {
+			Data any `json:"data"`
+		}{Data: out}
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("api: decode response: %w", err)
+	}
+	return nil
+}
+
+// wait sleeps before the next attempt, for as long as res asks with
+// Retry-After or else for the policy's backoff.
+func (c *Client) wait(ctx context.Context, attempt int, res *http.Response) error {
+	delay := c.retry.BaseDelay << (attempt - 1)
+	if c.retry.MaxDelay > 0 {
+		delay = min(delay, c.retry.MaxDelay)
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+	if res != nil {
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			delay = time.Duration(secs) * time.Second
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+// RefreshingTokens signs in once and then keeps the access token fresh with
+// the refresh endpoint, for services calling the API as a user of their own.
+// Pass it to WithRefreshingTokens.
+type RefreshingTokens struct {
+	client *Client
+	signIn SignInRequest
+
+	mu     sync.Mutex
+	tokens *AuthTokens
+}
+
+// NewRefreshingTokens returns a token source signing in through c, which
+// must not itself use the source.
+func NewRefreshingTokens(c *Client, signIn SignInRequest) *RefreshingTokens {
+	return &RefreshingTokens{client: c, signIn: signIn}
+}
+
+// Token returns a valid access token, refreshing it a minute before it
+// expires and signing in again when the refresh token is no longer good.
+func (t *RefreshingTokens) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tokens != nil && time.Until(t.tokens.AccessExpiresAt) > time.Minute {
+		return t.tokens.AccessToken, nil
+	}
+	if t.tokens != nil && time.Until(t.tokens.RefreshExpiresAt) > time.Minute {
+		tokens, err := t.client.Refresh(ctx, &RefreshRequest{RefreshToken: t.tokens.RefreshToken})
+		if err == nil {
+			t.tokens = tokens
+			return tokens.AccessToken, nil
+		}
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+			return "", err
+		}
+	}
+	tokens, err := t.client.SignIn(ctx, &t.signIn)
+	if err != nil {
+		return "", err
+	}
+	t.tokens = tokens
+	return tokens.AccessToken, nil
+}
+
+// Invalidate makes the next Token call refresh the access token if it is
+// still token, which the server rejected; another request may have had it
+// refreshed already.
+func (t *RefreshingTokens) Invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tokens != nil && t.tokens.AccessToken == token {
+		t.tokens.AccessExpiresAt = time.Time{}
+	}
+}
@@ -0,0 +1,388 @@
+// Command genclient writes the Go client in client_gen.go and the TypeScript
+// client in ts/client.ts from the OpenAPI document, failing on the parts of
+// it the generators do not support rather than leaving them out.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIFile is relative to the client package, where go generate runs.
+const openAPIFile = "../../api/openapi.yaml"
+
+type document struct {
+	Paths      map[string]map[string]*operation `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type operation struct {
+	OperationID string       `yaml:"operationId"`
+	Summary     string       `yaml:"summary"`
+	Parameters  []*parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]*struct {
+		Content map[string]struct {
+			Schema *schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"responses"`
+
+	method string
+	path   string
+}
+
+type parameter struct {
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string    `yaml:"$ref"`
+	Type                 string    `yaml:"type"`
+	Format               string    `yaml:"format"`
+	Nullable             bool      `yaml:"nullable"`
+	Enum                 []string  `yaml:"enum"`
+	Required             []string  `yaml:"required"`
+	Properties           yaml.Node `yaml:"properties"`
+	AdditionalProperties any       `yaml:"additionalProperties"`
+	Items                *schema   `yaml:"items"`
+}
+
+// property is a schema property, kept in document order.
+type property struct {
+	name     string
+	schema   *schema
+	required bool
+}
+
+func (s *schema) properties() ([]property, error) {
+	var out []property
+	node := &s.Properties
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var p schema
+		if err := node.Content[i+1].Decode(&p); err != nil {
+			return nil, err
+		}
+		name := node.Content[i].Value
+		out = append(out, property{name: name, schema: &p, required: slices.Contains(s.Required, name)})
+	}
+	return out, nil
+}
+
+func main() {
+	data, err := os.ReadFile(openAPIFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		log.Fatalf("parse %s: %v", openAPIFile, err)
+	}
+	ops, err := operations(&doc)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	goSrc, err := generateGo(&doc, ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("client_gen.go", goSrc, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	tsSrc, err := generateTS(&doc, ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll("ts", 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("ts", "client.ts"), tsSrc, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// operations lists the operations of doc sorted by path and method.
+func operations(doc *document) ([]*operation, error) {
+	var ops []*operation
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			op.method, op.path = strings.ToUpper(method), path
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: operationId is required", op.method, path)
+			}
+			for _, p := range op.Parameters {
+				if p.In != "path" {
+					return nil, fmt.Errorf("%s %s: %s parameters are not supported", op.method, path, p.In)
+				}
+			}
+			ops = append(ops, op)
+		}
+	}
+	slices.SortFunc(ops, func(a, b *operation) int {
+		return strings.Compare(a.path+" "+a.method, b.path+" "+b.method)
+	})
+	return ops, nil
+}
+
+// requestSchema returns the JSON request body schema of op, if any.
+func (op *operation) requestSchema() *schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content["application/json"].Schema
+}
+
+// responseSchema returns the JSON body schema of the first 2xx response
+// of op, if any.
+func (op *operation) responseSchema() *schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return op.Responses[code].Content["application/json"].Schema
+		}
+	}
+	return nil
+}
+
+// pathSegments splits a templated path into its literal parts and the
+// names of its parameters, alternating, starting with a literal.
+func pathSegments(path string) []string {
+	var parts []string
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			return append(parts, path)
+		}
+		end := strings.Index(path[start:], "}") + start
+		parts = append(parts, path[:start], path[start+1:end])
+		path = path[end+1:]
+	}
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+func sortedSchemas(doc *document) []string {
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// initialisms are upper-cased whole in Go names.
+var initialisms = map[string]bool{"id": true, "ip": true, "url": true, "uuid": true, "api": true, "http": true}
+
+// goName turns snake_case, kebab-case or camelCase into an exported name.
+func goName(s string) string {
+	var b strings.Builder
+	for word := range strings.FieldsFuncSeq(s, func(r rune) bool { return r == '_' || r == '-' }) {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func goType(s *schema, optional bool) (string, error) {
+	if s.Ref != "" {
+		if optional {
+			return "*" + refName(s.Ref), nil
+		}
+		return refName(s.Ref), nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			if optional || s.Nullable {
+				return "*time.Time", nil
+			}
+			return "time.Time", nil
+		}
+		return pointerIf(s.Nullable, "string"), nil
+	case "integer":
+		return pointerIf(s.Nullable, "int64"), nil
+	case "number":
+		return pointerIf(s.Nullable, "float64"), nil
+	case "boolean":
+		return pointerIf(s.Nullable, "bool"), nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		elem, err := goType(s.Items, false)
+		return "[]" + elem, err
+	case "object":
+		if s.Properties.Kind == 0 && s.AdditionalProperties != nil {
+			if sub, ok := s.AdditionalProperties.(map[string]any); ok && len(sub) > 0 {
+				return "", fmt.Errorf("typed additionalProperties are not supported")
+			}
+			return "map[string]any", nil
+		}
+		return "", fmt.Errorf("inline objects are not supported; move them to components/schemas")
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+func pointerIf(nullable bool, typ string) string {
+	if nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+func generateGo(doc *document, ops []*operation) ([]byte, error) {
+	var b bytes.Buffer
+
+	for _, name := range sortedSchemas(doc) {
+		s := doc.Components.Schemas[name]
+		if s.Type != "object" || s.Properties.Kind == 0 {
+			typ, err := goType(s, false)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", name, err)
+			}
+			fmt.Fprintf(&b, "type %s = %s\n\n", name, typ)
+			continue
+		}
+		props, err := s.properties()
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		var enums bytes.Buffer
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+		for _, p := range props {
+			typ, err := goType(p.schema, !p.required)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s.%s: %w", name, p.name, err)
+			}
+			tag := p.name
+			if !p.required {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "%s %s `json:%q`\n", goName(p.name), typ, tag)
+			for _, v := range p.schema.Enum {
+				fmt.Fprintf(&enums, "%s%s%s = %q\n", name, goName(p.name), goName(v), v)
+			}
+		}
+		b.WriteString("}\n\n")
+		if enums.Len() > 0 {
+			fmt.Fprintf(&b, "const (\n%s)\n\n", enums.String())
+		}
+	}
+
+	for _, op := range ops {
+		name := goName(op.OperationID)
+		params := []string{"ctx context.Context"}
+		segments := pathSegments(op.path)
+		for i := 1; i < len(segments); i += 2 {
+			params = append(params, lowerFirst(goName(segments[i]))+" string")
+		}
+		body := "nil"
+		if req := op.requestSchema(); req != nil {
+			typ, err := goType(req, true)
+			if err != nil {
+				return nil, fmt.Errorf("%s request: %w", op.OperationID, err)
+			}
+			params = append(params, "body "+typ)
+			body = "body"
+		}
+
+		var path strings.Builder
+		for i, seg := range segments {
+			switch {
+			case i%2 == 1:
+				fmt.Fprintf(&path, " + url.PathEscape(%s)", lowerFirst(goName(seg)))
+			case seg != "":
+				if path.Len() > 0 {
+					path.WriteString(" + ")
+				}
+				fmt.Fprintf(&path, "%q", seg)
+			}
+		}
+		pathExpr := strings.TrimPrefix(path.String(), " + ")
+
+		fmt.Fprintf(&b, "// %s %s\n//\n// %s %s\n", name, lowerFirst(op.Summary), op.method, op.path)
+		res := op.responseSchema()
+		if res == nil {
+			fmt.Fprintf(&b, "func (c *Client) %s(%s) error {\n", name, strings.Join(params, ", "))
+			fmt.Fprintf(&b, "return c.do(ctx, %s, %s, %s, nil)\n}\n\n", methodConst(op.method), pathExpr, body)
+			continue
+		}
+		typ, err := goType(res, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s response: %w", op.OperationID, err)
+		}
+		if res.Ref != "" {
+			fmt.Fprintf(&b, "func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(params, ", "), typ)
+			fmt.Fprintf(&b, "out := new(%s)\n", typ)
+			fmt.Fprintf(&b, "if err := c.do(ctx, %s, %s, %s, out); err != nil {\nreturn nil, err\n}\nreturn out, nil\n}\n\n", methodConst(op.method), pathExpr, body)
+			continue
+		}
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(params, ", "), typ)
+		fmt.Fprintf(&b, "var out %s\n", typ)
+		fmt.Fprintf(&b, "if err := c.do(ctx, %s, %s, %s, &out); err != nil {\nreturn out, err\n}\nreturn out, nil\n}\n\n", methodConst(op.method), pathExpr, body)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by genclient from %s. DO NOT EDIT.\n\n", filepath.Base(openAPIFile))
+	out.WriteString("package client\n\nimport (\n")
+	for _, imp := range []string{"context", "net/http", "net/url", "time"} {
+		if bytes.Contains(b.Bytes(), []byte(filepath.Base(imp)+".")) {
+			fmt.Fprintf(&out, "%q\n", imp)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(b.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+func methodConst(method string) string {
+	name := strings.ToUpper(method[:1]) + strings.ToLower(method[1:])
+	if http.MethodGet == method || http.MethodPost == method || http.MethodPut == method ||
+		http.MethodPatch == method || http.MethodDelete == method {
+		return "http.Method" + name
+	}
+	return fmt.Sprintf("%q", method)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	if strings.ToUpper(s) == s {
+		return strings.ToLower(s)
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// tsRuntime is the transport of the TypeScript client, the counterpart of
+// client.go. It relies only on fetch.
+const tsRuntime = `export interface ClientOptions {
+  /** The API root including the version prefix, e.g. https://api.example.com/api/v1. */
+  baseUrl: string;
+  /** Returns the bearer token of each request; an empty token sends none. */
+  token?: () => string | Promise<string>;
+  /** Unwraps the {"data": ...} envelope of servers that have it enabled. */
+  envelope?: boolean;
+  fetch?: typeof fetch;
+}
+
+/** A failed request, carrying the server's problem details. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly problem: Partial<Problem>,
+  ) {
+    super(problem.detail ?? "request failed with status " + status);
+    this.name = "ApiError";
+  }
+
+  get code(): string | undefined {
+    return this.problem.code;
+  }
+}
+
+export class Client {
+  private readonly fetch: typeof fetch;
+
+  constructor(private readonly options: ClientOptions) {
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    const token = this.options.token ? await this.options.token() : "";
+    if (token) {
+      headers["Authorization"] = "Bearer " + token;
+    }
+    const res = await this.fetch(this.options.baseUrl.replace(/\/$/, "") + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!res.ok) {
+      const problem = await res.json().catch(() => ({}));
+      throw new ApiError(res.status, problem);
+    }
+    if (res.status === 204) {
+      return undefined as T;
+    }
+    const data = await res.json();
+    return (this.options.envelope ? data.data : data) as T;
+  }
+`
+
+func tsType(s *schema) (string, error) {
+	if s.Ref != "" {
+		return refName(s.Ref), nil
+	}
+	var typ string
+	switch s.Type {
+	case "string":
+		typ = "string"
+		if len(s.Enum) > 0 {
+			quoted := make([]string, len(s.Enum))
+			for i, v := range s.Enum {
+				quoted[i] = fmt.Sprintf("%q", v)
+			}
+			typ = strings.Join(quoted, " | ")
+		}
+	case "integer", "number":
+		typ = "number"
+	case "boolean":
+		typ = "boolean"
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		elem, err := tsType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		typ = elem + "[]"
+	case "object":
+		if s.Properties.Kind != 0 {
+			return "", fmt.Errorf("inline objects are not supported; move them to components/schemas")
+		}
+		typ = "Record<string, unknown>"
+	default:
+		return "", fmt.Errorf("unsupported type %q", s.Type)
+	}
+	if s.Nullable {
+		typ += " | null"
+	}
+	return typ, nil
+}
+
+func generateTS(doc *document, ops []*operation) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by genclient from %s. DO NOT EDIT.\n\n", filepath.Base(openAPIFile))
+
+	for _, name := range sortedSchemas(doc) {
+		s := doc.Components.Schemas[name]
+		if s.Type != "object" || s.Properties.Kind == 0 {
+			typ, err := tsType(s)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", name, err)
+			}
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", name, typ)
+			continue
+		}
+		props, err := s.properties()
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		for _, p := range props {
+			typ, err := tsType(p.schema)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s.%s: %w", name, p.name, err)
+			}
+			optional := "?"
+			if p.required {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", p.name, optional, typ)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString(tsRuntime)
+	for _, op := range ops {
+		var params []string
+		var path strings.Builder
+		segments := pathSegments(op.path)
+		for i, seg := range segments {
+			if i%2 == 0 {
+				path.WriteString(seg)
+				continue
+			}
+			arg := lowerFirst(goName(seg))
+			params = append(params, arg+": string")
+			fmt.Fprintf(&path, "${encodeURIComponent(%s)}", arg)
+		}
+		body := ""
+		if req := op.requestSchema(); req != nil {
+			typ, err := tsType(req)
+			if err != nil {
+				return nil, fmt.Errorf("%s request: %w", op.OperationID, err)
+			}
+			params = append(params, "body: "+typ)
+			body = ", body"
+		}
+		result := "void"
+		if res := op.responseSchema(); res != nil {
+			typ, err := tsType(res)
+			if err != nil {
+				return nil, fmt.Errorf("%s response: %w", op.OperationID, err)
+			}
+			result = typ
+		}
+
+		fmt.Fprintf(&b, "\n  /** %s %s %s */\n", op.Summary, op.method, op.path)
+		fmt.Fprintf(&b, "  %s(%s): Promise<%s> {\n", op.OperationID, strings.Join(params, ", "), result)
+		fmt.Fprintf(&b, "    return this.request(%q, `%s`%s);\n  }\n", op.method, path.String(), body)
+	}
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+// Package clientinfo tells the browser, operating system and kind of device
+// a request comes from, from its User-Agent and, when the browser sends
+// them, its User-Agent Client Hints, which are more precise: Chromium
+// browsers freeze the OS versions of their User-Agent, e.g. at macOS 10.15
+// and Windows 10.
+//
+// Parsing is a handful of substring searches, and parsed User-Agents are
+// cached, as a service sees the same few hundred of them over and over.
+package clientinfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Kinds of devices.
+const (
+	DEVICE_DESKTOP = "desktop"
+	DEVICE_MOBILE  = "mobile"
+	DEVICE_TABLET  = "tablet"
+	// DEVICE_BOT is a crawler, or a command-line or library HTTP client
+	// such as curl.
+	DEVICE_BOT     = "bot"
+	DEVICE_UNKNOWN = "unknown"
+)
+
+// Info is what a request tells of the client that sent it. Versions are
+// major versions, e.g. "120", or "14.1" for Apple's operating systems;
+// the fields are empty when they cannot be told.
+type Info struct {
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	OSVersion      string `json:"os_version,omitempty"`
+	Device         string `json:"device"`
+}
+
+// String describes the client for people, e.g. "Chrome 120 on macOS 14"
+// or "curl 8".
+func (i Info) String() string {
+	browser := join(i.Browser, i.BrowserVersion)
+	os := join(i.OS, i.OSVersion)
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	return "Unknown device"
+}
+
+func join(name, version string) string {
+	if name == "" || version == "" {
+		return name
+	}
+	return name + " " + version
+}
+
+var defaultParser = NewParser(DEFAULT_CACHE_SIZE)
+
+// Parse parses userAgent with a shared parser.
+func Parse(userAgent string) Info {
+	return defaultParser.Parse(userAgent)
+}
+
+// FromHeaders reads the client of a request from h with a shared parser.
+func FromHeaders(h http.Header) Info {
+	return defaultParser.FromHeaders(h)
+}
+
+// Fingerprint identifies the device of a request across sessions. Clients
+// may send a stable X-Device-ID; otherwise it is derived from headers that
+// rarely change for a given browser.
+func Fingerprint(h http.Header) string {
+	source := h.Get("X-Device-ID")
+	if source == "" {
+		source = h.Get("User-Agent") + "|" + h.Get("Accept-Language")
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FromHeaders parses the User-Agent of h, then lets its Client Hints
+// correct the result.
+func (p *Parser) FromHeaders(h http.Header) Info {
+	info := p.Parse(h.Get("User-Agent"))
+	if info.Device == DEVICE_BOT {
+		return info
+	}
+
+	if brand, version := hintBrand(h.Get("Sec-CH-UA")); brand != "" {
+		info.Browser, info.BrowserVersion = brand, version
+	}
+	if platform := unquote(h.Get("Sec-CH-UA-Platform")); platform != "" && platform != "Unknown" {
+		if platform == "Chrome OS" {
+			platform = "ChromeOS"
+		}
+		if platform != info.OS {
+			info.OSVersion = ""
+		}
+		info.OS = platform
+		if version := unquote(h.Get("Sec-CH-UA-Platform-Version")); version != "" {
+			info.OSVersion = hintOSVersion(platform, version)
+		}
+	}
+	switch h.Get("Sec-CH-UA-Mobile") {
+	case "?1":
+		info.Device = DEVICE_MOBILE
+	case "?0":
+		if info.Device == DEVICE_MOBILE || info.Device == DEVICE_UNKNOWN {
+			info.Device = DEVICE_DESKTOP
+		}
+	}
+	return info
+}
+
+// hintBrand picks the browser of a Sec-CH-UA list, e.g.
+// `"Chromium";v="120", "Google Chrome";v="120", "Not?A_Brand";v="99"`,
+// skipping the Chromium engine and the GREASE brands.
+func hintBrand(list string) (string, string) {
+	var chromium string
+	for _, item := range strings.Split(list, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		name = unquote(name)
+		_, version, _ := strings.Cut(params, "v=")
+		version = unquote(version)
+		switch {
+		case name == "" || strings.Contains(name, "Brand"):
+		case name == "Chromium":
+			chromium = version
+		default:
+			if mapped, ok := hintBrands[name]; ok {
+				name = mapped
+			}
+			return name, version
+		}
+	}
+	if chromium != "" {
+		return "Chromium", chromium
+	}
+	return "", ""
+}
+
+var hintBrands = map[string]string{
+	"Google Chrome":  "Chrome",
+	"Microsoft Edge": "Edge",
+	"Opera":          "Opera",
+}
+
+// hintOSVersion turns a Sec-CH-UA-Platform-Version into the version people
+// know: Windows 13 and above is Windows 11.
+func hintOSVersion(platform, version string) string {
+	major, minor, _ := strings.Cut(version, ".")
+	minor, _, _ = strings.Cut(minor, ".")
+	switch platform {
+	case "Windows":
+		if n := atoi(major); n >= 13 {
+			return "11"
+		} else if n > 0 {
+			return "10"
+		}
+		return ""
+	case "macOS", "iOS":
+		if minor != "" && minor != "0" {
+			return major + "." + minor
+		}
+	}
+	return major
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"`)
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
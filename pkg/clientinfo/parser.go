@@ -0,0 +1,235 @@
+package clientinfo
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// DEFAULT_CACHE_SIZE is how many User-Agents the shared parser remembers.
+const DEFAULT_CACHE_SIZE = 4096
+
+// maxUserAgent bounds the User-Agents parsed and cached; longer ones are
+// cut, as no browser sends them.
+const maxUserAgent = 512
+
+// Parser parses User-Agents, remembering the results of the most recently
+// used ones. It is safe for concurrent use.
+type Parser struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	recent  *list.List
+}
+
+type cacheEntry struct {
+	userAgent string
+	info      Info
+}
+
+// NewParser remembers size User-Agents; 0 disables the cache.
+func NewParser(size int) *Parser {
+	return &Parser{size: size, entries: map[string]*list.Element{}, recent: list.New()}
+}
+
+// Parse tells the client from userAgent alone.
+func (p *Parser) Parse(userAgent string) Info {
+	if len(userAgent) > maxUserAgent {
+		userAgent = userAgent[:maxUserAgent]
+	}
+	if p.size <= 0 {
+		return parse(userAgent)
+	}
+
+	p.mu.Lock()
+	if e, ok := p.entries[userAgent]; ok {
+		p.recent.MoveToFront(e)
+		info := e.Value.(*cacheEntry).info
+		p.mu.Unlock()
+		return info
+	}
+	p.mu.Unlock()
+
+	info := parse(userAgent)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.entries[userAgent]; !ok {
+		p.entries[userAgent] = p.recent.PushFront(&cacheEntry{userAgent: userAgent, info: info})
+		if p.recent.Len() > p.size {
+			oldest := p.recent.Back()
+			p.recent.Remove(oldest)
+			delete(p.entries, oldest.Value.(*cacheEntry).userAgent)
+		}
+	}
+	return info
+}
+
+// tools are the HTTP clients told by their product token, and
+// crawlerMarkers the words that tell crawlers in their User-Agents; both are
+// reported as bots.
+var (
+	tools = []struct{ token, name string }{
+		{"curl/", "curl"},
+		{"Wget/", "Wget"},
+		{"python-requests/", "Python Requests"},
+		{"python-urllib", "Python urllib"},
+		{"Go-http-client/", "Go"},
+		{"okhttp/", "OkHttp"},
+		{"PostmanRuntime/", "Postman"},
+		{"axios/", "axios"},
+		{"node-fetch", "node-fetch"},
+		{"Java/", "Java"},
+	}
+	crawlerMarkers = []string{"bot", "crawler", "spider", "slurp", "headless"}
+)
+
+// browsers are matched in order, as browsers also carry the tokens of those
+// they are derived from: Edge says it is Chrome and Safari, and Chrome says
+// it is Safari.
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Chromium/", "Chromium"},
+}
+
+func parse(ua string) Info {
+	if ua == "" {
+		return Info{Device: DEVICE_UNKNOWN}
+	}
+	for _, t := range tools {
+		if strings.HasPrefix(ua, t.token) || strings.Contains(ua, " "+t.token) {
+			return Info{Browser: t.name, BrowserVersion: version(ua, t.token), Device: DEVICE_BOT}
+		}
+	}
+	lower := strings.ToLower(ua)
+	for _, marker := range crawlerMarkers {
+		if strings.Contains(lower, marker) {
+			return Info{Browser: crawlerName(ua), Device: DEVICE_BOT}
+		}
+	}
+
+	var info Info
+	info.Browser, info.BrowserVersion = browser(ua)
+	info.OS, info.OSVersion = operatingSystem(ua)
+	info.Device = device(ua, info.OS)
+	return info
+}
+
+func browser(ua string) (string, string) {
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			return b.name, version(ua, b.token)
+		}
+	}
+	if strings.Contains(ua, "Safari/") && strings.Contains(ua, "Version/") {
+		return "Safari", version(ua, "Version/")
+	}
+	if strings.Contains(ua, "MSIE ") {
+		return "Internet Explorer", version(ua, "MSIE ")
+	}
+	if strings.Contains(ua, "Trident/") {
+		return "Internet Explorer", version(ua, "rv:")
+	}
+	return "", ""
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+}
+
+func operatingSystem(ua string) (string, string) {
+	switch {
+	case strings.Contains(ua, "Windows NT "):
+		return "Windows", windowsVersions[dotted(ua, "Windows NT ")]
+	case strings.Contains(ua, "iPhone OS "):
+		return "iOS", appleVersion(dotted(ua, "iPhone OS "))
+	case strings.Contains(ua, "iPad") && strings.Contains(ua, "CPU OS "):
+		return "iPadOS", appleVersion(dotted(ua, "CPU OS "))
+	case strings.Contains(ua, "Android"):
+		v := dotted(ua, "Android ")
+		major, _, _ := strings.Cut(v, ".")
+		return "Android", major
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS", ""
+	case strings.Contains(ua, "Mac OS X"):
+		return "macOS", appleVersion(dotted(ua, "Mac OS X "))
+	case strings.Contains(ua, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// appleVersion keeps the major and minor version of "14_1_2" or "14.1".
+func appleVersion(v string) string {
+	parts := strings.SplitN(strings.ReplaceAll(v, "_", "."), ".", 3)
+	if len(parts) >= 2 && parts[1] != "0" {
+		return parts[0] + "." + parts[1]
+	}
+	return parts[0]
+}
+
+func device(ua, os string) string {
+	switch {
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet"):
+		return DEVICE_TABLET
+	case os == "Android" && !strings.Contains(ua, "Mobile"):
+		return DEVICE_TABLET
+	case strings.Contains(ua, "Mobile") || strings.Contains(ua, "iPhone"):
+		return DEVICE_MOBILE
+	case os != "":
+		return DEVICE_DESKTOP
+	}
+	return DEVICE_UNKNOWN
+}
+
+// version returns the major version following token, e.g. "120" after
+// "Chrome/" in "Chrome/120.0.6099.109".
+func version(ua, token string) string {
+	v := dotted(ua, token)
+	major, _, _ := strings.Cut(v, ".")
+	return major
+}
+
+// dotted returns the version following token: the digits, dots and
+// underscores up to the next other character.
+func dotted(ua, token string) string {
+	_, rest, ok := strings.Cut(ua, token)
+	if !ok {
+		return ""
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '_'
+	})
+	if end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.Trim(rest, "._")
+}
+
+// crawlerName returns the product of a crawler's User-Agent, e.g.
+// "Googlebot" in "Mozilla/5.0 (compatible; Googlebot/2.1; ...)".
+func crawlerName(ua string) string {
+	for _, field := range strings.FieldsFunc(ua, func(r rune) bool { return r == ' ' || r == ';' || r == '(' || r == ')' }) {
+		name, _, _ := strings.Cut(field, "/")
+		lower := strings.ToLower(name)
+		for _, marker := range crawlerMarkers {
+			if strings.Contains(lower, marker) {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	ticker := f.NewTicker(time.Minute)
+
+	f.Advance(59 * time.Second)
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("ticked at %v before the interval", tick)
+	default:
+	}
+
+	// Like time.Ticker, ticks the receiver misses are dropped.
+	f.Advance(3 * time.Minute)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Minute)) {
+		t.Errorf("tick = %v, want %v", tick, start.Add(time.Minute))
+	}
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("missed tick %v was kept", tick)
+	default:
+	}
+
+	ticker.Stop()
+	f.Advance(time.Hour)
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("stopped ticker ticked at %v", tick)
+	default:
+	}
+	if want := start.Add(time.Hour + 3*time.Minute + 59*time.Second); !f.Now().Equal(want) {
+		t.Errorf("now = %v, want %v", f.Now(), want)
+	}
+}
+
+func TestFakeSetBackwards(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	ticker := f.NewTicker(time.Minute)
+	f.Set(start.Add(-time.Hour))
+	select {
+	case tick := <-ticker.C():
+		t.Fatalf("ticked at %v going backwards", tick)
+	default:
+	}
+	f.Set(start.Add(time.Minute))
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Minute)) {
+		t.Errorf("tick = %v, want %v", tick, start.Add(time.Minute))
+	}
+}
@@ -0,0 +1,132 @@
+package crash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/logger"
+)
+
+// RUNTIME_CRASH_FILE receives the Go runtime's own report of panics that no
+// Recover caught, e.g. in goroutines started by libraries.
+const RUNTIME_CRASH_FILE = "runtime-crash.log"
+
+type ReporterArgs struct {
+	Dir string
+	// Config is the configuration snapshot to include. Secrets must already
+	// be redacted.
+	Config any
+}
+
+// Reporter writes diagnostics bundles for post-mortem analysis. A nil
+// *Reporter is valid and does nothing.
+type Reporter struct {
+	dir    string
+	config any
+}
+
+// NewReporter returns nil when args.Dir is empty, which disables reports.
+func NewReporter(args ReporterArgs) *Reporter {
+	if args.Dir == "" {
+		return nil
+	}
+	return &Reporter{dir: args.Dir, config: args.Config}
+}
+
+// Install creates the report directory and points the runtime's crash
+// output at it, so panics that escape every Recover still leave a trace.
+func (r *Reporter) Install() error {
+	if r == nil {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0o750); err != nil {
+		return fmt.Errorf("create crash report dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, RUNTIME_CRASH_FILE), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("open runtime crash file: %w", err)
+	}
+	defer f.Close()
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		return fmt.Errorf("set crash output: %w", err)
+	}
+	return nil
+}
+
+// Recover writes a bundle for a panic and re-panics. It must be deferred
+// directly: defer reporter.Recover().
+func (r *Reporter) Recover() {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	if r != nil {
+		r.Write(fmt.Sprintf("panic: %v\n\n%s", rec, debug.Stack()))
+	}
+	panic(rec)
+}
+
+// Write stores a bundle in a new directory under Dir: the reason, a dump of
+// every goroutine, the recent log entries, the configuration snapshot and
+// the build info. It returns the bundle's path.
+func (r *Reporter) Write(reason string) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	path := filepath.Join(r.dir, fmt.Sprintf("crash-%s-%d", time.Now().UTC().Format("20060102T150405Z"), os.Getpid()))
+	if err := os.MkdirAll(path, 0o750); err != nil {
+		return "", fmt.Errorf("create crash bundle: %w", err)
+	}
+
+	var errs []error
+	write := func(name string, fill func(f *os.File) error) {
+		f, err := os.OpenFile(filepath.Join(path, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		defer f.Close()
+		if err := fill(f); err != nil {
+			errs = append(errs, fmt.Errorf("write %s: %w", name, err))
+		}
+	}
+	write("reason.txt", func(f *os.File) error {
+		_, err := fmt.Fprintln(f, reason)
+		return err
+	})
+	write("goroutines.txt", func(f *os.File) error {
+		return pprof.Lookup("goroutine").WriteTo(f, 2)
+	})
+	write("logs.jsonl", func(f *os.File) error {
+		for _, line := range logger.Recent() {
+			if _, err := f.Write(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	write("config.json", func(f *os.File) error {
+		return writeJSON(f, r.config)
+	})
+	write("build.json", func(f *os.File) error {
+		return writeJSON(f, buildinfo.Get())
+	})
+
+	if len(errs) > 0 {
+		return path, fmt.Errorf("write crash bundle: %w", errors.Join(errs...))
+	}
+	return path, nil
+}
+
+func writeJSON(f *os.File, v any) error {
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,186 @@
+package ctxutil
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Key is a typed context key. Declare one per value with NewKey; the pointer
+// identity of the key keeps it distinct from every other key.
+type Key[T any] struct {
+	name string
+}
+
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+func (k *Key[T]) String() string {
+	return "ctxutil." + k.name
+}
+
+func With[T any](ctx context.Context, key *Key[T], v T) context.Context {
+	return context.WithValue(ctx, key, v)
+}
+
+func Get[T any](ctx context.Context, key *Key[T]) (T, bool) {
+	v, ok := ctx.Value(key).(T)
+	return v, ok
+}
+
+// Principal is the authenticated caller of a request, whichever
+// authentication strategy accepted it: a user, an organization's service
+// account or an internal service. Authorization looks at it alone.
+type Principal struct {
+	ID        uuid.UUID
+	Email     string
+	Roles     []string
+	TenantID  string
+	TokenID   string
+	SessionID uuid.UUID
+	// Scopes restrict what the token may do; empty means unrestricted.
+	Scopes []string
+	// ImpersonatorID is the admin acting as this user; uuid.Nil when the
+	// user is acting for themselves.
+	ImpersonatorID uuid.UUID
+	// PersonalTokenID is the personal access token the request was made
+	// with; uuid.Nil for session tokens. Such requests have no SessionID.
+	PersonalTokenID uuid.UUID
+	// Issuer is set when the request was made with another issuer's token;
+	// its session is kept by that issuer, so there is no SessionID either.
+	Issuer string
+	// ServiceAccountID is set when the caller is an organization's service
+	// account rather than a user. ID is then the account's ID too, TenantID
+	// its organization, and there is no SessionID.
+	ServiceAccountID uuid.UUID
+	// Service is set when the caller is an internal service that signed
+	// the request with its shared secret. ID is derived from its name and
+	// there is no user or session.
+	Service string
+	// Method is the name of the authentication strategy that accepted the
+	// caller, e.g. "jwt".
+	Method string
+	// IssuedAt is when the caller's access token was issued; zero for the
+	// credentials other than the access tokens issued here.
+	IssuedAt time.Time
+}
+
+func (u *Principal) IsImpersonated() bool {
+	return u.ImpersonatorID != uuid.Nil
+}
+
+func (u *Principal) IsPersonalToken() bool {
+	return u.PersonalTokenID != uuid.Nil
+}
+
+func (u *Principal) IsExternal() bool {
+	return u.Issuer != ""
+}
+
+func (u *Principal) IsServiceAccount() bool {
+	return u.ServiceAccountID != uuid.Nil
+}
+
+func (u *Principal) IsInternal() bool {
+	return u.Service != ""
+}
+
+func (u *Principal) HasScope(scope string) bool {
+	return slices.Contains(u.Scopes, scope)
+}
+
+func (u *Principal) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// CurrentClient is the authenticated machine caller of a request, set
+// instead of a Principal for OAuth client tokens.
+type CurrentClient struct {
+	ClientID string
+	// Issuer is set for clients authenticated with another issuer's token;
+	// ClientID is then that issuer's subject.
+	Issuer  string
+	TokenID string
+	Scopes  []string
+	// Plan is the client's quota plan; empty means the default plan.
+	Plan string
+}
+
+func (c *CurrentClient) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+var (
+	currentUserKey   = NewKey[*Principal]("current_user")
+	currentClientKey = NewKey[*CurrentClient]("current_client")
+	tenantKey        = NewKey[string]("tenant")
+	localeKey        = NewKey[string]("locale")
+	loggerKey        = NewKey[*zap.SugaredLogger]("logger")
+)
+
+// WithRequestID stores id under chi's key so chi middleware (e.g. Logger)
+// and GetReqID see the same value.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, middleware.RequestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	return middleware.GetReqID(ctx)
+}
+
+func WithCurrentUser(ctx context.Context, u *Principal) context.Context {
+	return With(ctx, currentUserKey, u)
+}
+
+func CurrentUserFrom(ctx context.Context) (*Principal, bool) {
+	u, ok := Get(ctx, currentUserKey)
+	return u, ok && u != nil
+}
+
+func WithCurrentClient(ctx context.Context, c *CurrentClient) context.Context {
+	return With(ctx, currentClientKey, c)
+}
+
+func CurrentClientFrom(ctx context.Context) (*CurrentClient, bool) {
+	c, ok := Get(ctx, currentClientKey)
+	return c, ok && c != nil
+}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return With(ctx, tenantKey, tenantID)
+}
+
+func Tenant(ctx context.Context) string {
+	t, _ := Get(ctx, tenantKey)
+	return t
+}
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return With(ctx, localeKey, locale)
+}
+
+// Locale returns the request locale, defaulting to "en".
+func Locale(ctx context.Context) string {
+	if l, ok := Get(ctx, localeKey); ok && l != "" {
+		return l
+	}
+	return "en"
+}
+
+func WithLogger(ctx context.Context, l *zap.SugaredLogger) context.Context {
+	return With(ctx, loggerKey, l)
+}
+
+// Logger returns the request-scoped logger, falling back to the global one.
+func Logger(ctx context.Context) *zap.SugaredLogger {
+	if l, ok := Get(ctx, loggerKey); ok && l != nil {
+		return l
+	}
+	return logger.L()
+}
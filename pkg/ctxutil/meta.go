@@ -0,0 +1,125 @@
+package ctxutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haidang666/go-app/pkg/clientinfo"
+)
+
+// MetaKey is a typed key of the request metadata. Declare one per value with
+// NewMetaKey; its name labels the value in logs and analytics.
+type MetaKey[T any] struct {
+	name string
+}
+
+func NewMetaKey[T any](name string) *MetaKey[T] {
+	return &MetaKey[T]{name: name}
+}
+
+func (k *MetaKey[T]) Name() string {
+	return k.name
+}
+
+// The request metadata set by the HTTP middleware and policies.
+var (
+	// MetaCountry is the ISO 3166-1 alpha-2 code of the client's country.
+	MetaCountry = NewMetaKey[string]("country")
+	// MetaDevice is the client's browser, OS and kind of device.
+	MetaDevice = NewMetaKey[clientinfo.Info]("device")
+	// MetaRiskScore is the bot risk score of a sign-up.
+	MetaRiskScore = NewMetaKey[int]("risk_score")
+)
+
+// Meta carries the metadata of one request that middleware determine for
+// handlers, use cases, logs and analytics, such as the client's country,
+// without each concern adding a context key. It is installed once per
+// request, so setting values does not copy the context, and values set deep
+// in the handler chain are seen by the middleware that run after it, such
+// as the access log.
+type Meta struct {
+	mu      sync.RWMutex
+	entries []metaEntry
+	// inline holds the first entries, sparing an allocation for most
+	// requests.
+	inline [6]metaEntry
+}
+
+type metaEntry struct {
+	key   any
+	name  string
+	value any
+}
+
+var metaKey = NewKey[*Meta]("meta")
+
+// WithMeta returns ctx with a new, empty Meta.
+func WithMeta(ctx context.Context) context.Context {
+	m := new(Meta)
+	m.entries = m.inline[:0]
+	return With(ctx, metaKey, m)
+}
+
+// MetaFrom returns the request's Meta, or nil outside a request.
+func MetaFrom(ctx context.Context) *Meta {
+	m, _ := Get(ctx, metaKey)
+	return m
+}
+
+// SetMeta sets the value of key for the request, replacing any previous
+// one. It is a no-op outside a request.
+func SetMeta[T any](ctx context.Context, key *MetaKey[T], v T) {
+	m := MetaFrom(ctx)
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.entries {
+		if m.entries[i].key == key {
+			m.entries[i].value = v
+			return
+		}
+	}
+	m.entries = append(m.entries, metaEntry{key: key, name: key.name, value: v})
+}
+
+// GetMeta returns the value of key set for the request.
+func GetMeta[T any](ctx context.Context, key *MetaKey[T]) (T, bool) {
+	var zero T
+	m := MetaFrom(ctx)
+	if m == nil {
+		return zero, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, e := range m.entries {
+		if e.key == key {
+			v, ok := e.value.(T)
+			return v, ok
+		}
+	}
+	return zero, false
+}
+
+// Each calls fn with the name and value of each entry, in the order they
+// were first set.
+func (m *Meta) Each(fn func(name string, value any)) {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, e := range m.entries {
+		fn(e.name, e.value)
+	}
+}
+
+// Fields returns the entries as name/value pairs, for structured logging.
+func (m *Meta) Fields() []any {
+	var fields []any
+	m.Each(func(name string, value any) {
+		fields = append(fields, name, value)
+	})
+	return fields
+}
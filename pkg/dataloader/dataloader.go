@@ -0,0 +1,169 @@
+// Package dataloader batches lookups by key made concurrently, e.g. by the
+// rows of a list or the resolvers of a GraphQL query, into one call of a
+// batch function, and memoizes the results. A loader caches for its whole
+// lifetime and is never invalidated, so create one per request.
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for keys the batch function left out of its
+// result.
+var ErrNotFound = errors.New("dataloader: not found")
+
+// BatchFunc looks up keys in one call. Keys missing from the map are
+// reported as ErrNotFound; an error fails every key of the batch.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type LoaderArgs[K comparable, V any] struct {
+	Fetch BatchFunc[K, V]
+	// Wait is how long a batch collects keys after the first one before it
+	// is fetched; 0 means one millisecond.
+	Wait time.Duration
+	// MaxBatch fetches a batch as soon as it has this many keys; 0 means
+	// 100.
+	MaxBatch int
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	// ctx is that of the first Load of the batch, which the fetch runs
+	// with.
+	ctx        context.Context
+	keys       []K
+	results    []*result[V]
+	dispatched bool
+}
+
+// Loader is safe for concurrent use.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	// pending collects the keys of the next fetch.
+	pending *batch[K, V]
+}
+
+func NewLoader[K comparable, V any](args LoaderArgs[K, V]) *Loader[K, V] {
+	if args.Wait <= 0 {
+		args.Wait = time.Millisecond
+	}
+	if args.MaxBatch <= 0 {
+		args.MaxBatch = 100
+	}
+	return &Loader[K, V]{
+		fetch:    args.Fetch,
+		wait:     args.Wait,
+		maxBatch: args.MaxBatch,
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value of key, fetched with the other keys loaded within
+// the wait, or from an earlier fetch.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.await(ctx, l.enqueue(ctx, key))
+}
+
+// LoadMany returns the values of keys that exist, fetched in as few batches
+// as MaxBatch allows. It fails with the first error other than ErrNotFound.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.enqueue(ctx, key)
+	}
+	values := make(map[K]V, len(keys))
+	for i, r := range results {
+		v, err := l.await(ctx, r)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			values[keys[i]] = v
+		}
+	}
+	return values, nil
+}
+
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.results[key]; ok {
+		return r
+	}
+
+	r := &result[V]{done: make(chan struct{})}
+	l.results[key] = r
+	if l.pending == nil {
+		b := &batch[K, V]{ctx: ctx}
+		l.pending = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if len(b.keys) >= l.maxBatch {
+		l.pending = nil
+		go l.dispatch(b)
+	}
+	return r
+}
+
+func (l *Loader[K, V]) await(ctx context.Context, r *result[V]) (V, error) {
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch fetches b once, whether its wait ran out or it filled up. Keys
+// that failed with an error other than ErrNotFound are forgotten, so a
+// later Load tries them again.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(b.ctx, b.keys)
+	if err != nil {
+		l.mu.Lock()
+		for _, key := range b.keys {
+			delete(l.results, key)
+		}
+		l.mu.Unlock()
+	}
+	for i, key := range b.keys {
+		r := b.results[i]
+		if err != nil {
+			r.err = err
+		} else if v, ok := values[key]; ok {
+			r.value = v
+		} else {
+			r.err = ErrNotFound
+		}
+		close(r.done)
+	}
+}
@@ -0,0 +1,163 @@
+// Package dedupe makes at-least-once deliveries, such as webhook retries or
+// redelivered messages, take effect once. A delivery is keyed by the ID its
+// sender gives it; the key is claimed while the delivery is processed and
+// remembered for a retention window after it succeeds.
+package dedupe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/eventbus"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var ErrInProgress = errors.New("dedupe: delivery is being processed")
+
+var deliveriesTotal = metrics.NewCounter("dedupe_deliveries_total",
+	"Deliveries by scope and outcome (processed, duplicate, in_progress, failed, unguarded).", "scope", "outcome")
+
+// State is what a Store found for a key when claiming it.
+type State int
+
+const (
+	// StateNew means the key was free and is now claimed by the caller.
+	StateNew State = iota
+	// StateProcessing means another delivery holds the claim.
+	StateProcessing
+	// StateDone means a delivery with the key succeeded within the
+	// retention window.
+	StateDone
+)
+
+// Store records the state of delivery keys. Claim must be atomic, and
+// Complete and Release must only act on a claim still held by owner, so a
+// delivery that outlived its lease cannot undo a newer claim.
+type Store interface {
+	Claim(ctx context.Context, key, owner string, lease time.Duration) (State, error)
+	Complete(ctx context.Context, key, owner string, retention time.Duration) error
+	Release(ctx context.Context, key, owner string) error
+}
+
+type DeduperArgs struct {
+	Store Store
+	// Lease bounds how long a claim survives a crashed consumer; 0 means
+	// one minute.
+	Lease time.Duration
+	// Retention is how long a processed key is remembered; it should cover
+	// the sender's retry schedule.
+	Retention time.Duration
+	// ScopeRetention overrides Retention per scope.
+	ScopeRetention map[string]time.Duration
+	// FailOpen processes deliveries without duplicate detection while the
+	// store fails, instead of failing them for the sender to retry.
+	FailOpen bool
+}
+
+// Deduper is safe for concurrent use.
+type Deduper struct {
+	store          Store
+	lease          time.Duration
+	retention      time.Duration
+	scopeRetention map[string]time.Duration
+	failOpen       bool
+}
+
+func NewDeduper(args DeduperArgs) *Deduper {
+	if args.Lease <= 0 {
+		args.Lease = time.Minute
+	}
+	return &Deduper{
+		store:          args.Store,
+		lease:          args.Lease,
+		retention:      args.Retention,
+		scopeRetention: args.ScopeRetention,
+		failOpen:       args.FailOpen,
+	}
+}
+
+// Do runs fn for the delivery id of scope unless a delivery with the same
+// id already succeeded, in which case it reports a duplicate. While another
+// delivery of id is being processed it returns ErrInProgress; the sender
+// should retry later. When fn fails the claim is released, so a retry
+// processes the delivery again. When the store fails Do returns its error,
+// or with FailOpen runs fn regardless.
+func (d *Deduper) Do(ctx context.Context, scope, id string, fn func(ctx context.Context) error) (duplicate bool, err error) {
+	key := scope + ":" + id
+	owner, err := newOwner()
+	if err != nil {
+		return false, err
+	}
+
+	state, err := d.store.Claim(ctx, key, owner, d.lease)
+	if err != nil {
+		if !d.failOpen {
+			deliveriesTotal.Inc(scope, "failed")
+			return false, err
+		}
+		logger.Sampled("dedupe.claim", 100).Warnw("claim delivery, processing it unguarded", "scope", scope, "error", err)
+		deliveriesTotal.Inc(scope, "unguarded")
+		return false, fn(ctx)
+	}
+	switch state {
+	case StateDone:
+		deliveriesTotal.Inc(scope, "duplicate")
+		return true, nil
+	case StateProcessing:
+		deliveriesTotal.Inc(scope, "in_progress")
+		return false, ErrInProgress
+	}
+
+	if err := fn(ctx); err != nil {
+		deliveriesTotal.Inc(scope, "failed")
+		// The sender retries the delivery either way; a claim left behind
+		// only delays that until the lease ends.
+		d.store.Release(context.WithoutCancel(ctx), key, owner)
+		return false, err
+	}
+	deliveriesTotal.Inc(scope, "processed")
+	return false, d.store.Complete(context.WithoutCancel(ctx), key, owner, d.retentionOf(scope))
+}
+
+func (d *Deduper) retentionOf(scope string) time.Duration {
+	if r, ok := d.scopeRetention[scope]; ok {
+		return r
+	}
+	return d.retention
+}
+
+func newOwner() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Handler wraps an event bus handler so it sees each event ID of scope
+// once. Events without an ID are passed through. When the store fails the
+// event is still handled, preferring a repeat to a loss.
+func (d *Deduper) Handler(scope string, h eventbus.Handler) eventbus.Handler {
+	return func(ctx context.Context, e eventbus.Event) {
+		if e.ID == "" {
+			h(ctx, e)
+			return
+		}
+		handled := false
+		_, err := d.Do(ctx, scope, e.ID, func(ctx context.Context) error {
+			handled = true
+			h(ctx, e)
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrInProgress) {
+			logger.Sampled("dedupe.handler", 100).Warnw("dedupe event", "scope", scope, "event_id", e.ID, "error", err)
+			if !handled {
+				h(ctx, e)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package dedupe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many claims pass between removals of expired keys.
+const sweepEvery = 1024
+
+// MemoryStore keeps keys inside a single process. It is meant for local
+// development and single-replica deployments.
+type MemoryStore struct {
+	mu     sync.Mutex
+	keys   map[string]memoryKey
+	claims int
+}
+
+type memoryKey struct {
+	// owner is empty once the key is done.
+	owner     string
+	expiresAt time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]memoryKey)}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, key, owner string, lease time.Duration) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.claims++
+	if s.claims%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	if k, ok := s.keys[key]; ok && now.Before(k.expiresAt) {
+		if k.owner == "" {
+			return StateDone, nil
+		}
+		return StateProcessing, nil
+	}
+	s.keys[key] = memoryKey{owner: owner, expiresAt: now.Add(lease)}
+	return StateNew, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key, owner string, retention time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.keys[key]; ok && k.owner == owner {
+		s.keys[key] = memoryKey{expiresAt: time.Now().Add(retention)}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.keys[key]; ok && k.owner == owner {
+		delete(s.keys, key)
+	}
+	return nil
+}
+
+// sweep expects the caller to hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, k := range s.keys {
+		if !now.Before(k.expiresAt) {
+			delete(s.keys, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisScripter is the subset of a Redis client the store needs; adapt e.g.
+// go-redis with a one-line wrapper around Eval(...).Result().
+type RedisScripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// A key holds "p:<owner>" while claimed and "d" once done.
+const (
+	claimScript = `
+if redis.call("SET", KEYS[1], "p:" .. ARGV[1], "NX", "PX", ARGV[2]) then
+	return 0
+end
+if redis.call("GET", KEYS[1]) == "d" then
+	return 2
+end
+return 1`
+	completeScript = `
+if redis.call("GET", KEYS[1]) == "p:" .. ARGV[1] then
+	redis.call("SET", KEYS[1], "d", "PX", ARGV[2])
+end
+return 0`
+	releaseScript = `
+if redis.call("GET", KEYS[1]) == "p:" .. ARGV[1] then
+	redis.call("DEL", KEYS[1])
+end
+return 0`
+)
+
+// RedisStore shares delivery keys between replicas.
+type RedisStore struct {
+	client RedisScripter
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client RedisScripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Claim(ctx context.Context, key, owner string, lease time.Duration) (State, error) {
+	res, err := s.client.Eval(ctx, claimScript, []string{s.prefix + key}, owner, lease.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	n, ok := res.(int64)
+	if !ok || n < 0 || n > int64(StateDone) {
+		return 0, fmt.Errorf("unexpected redis reply %v", res)
+	}
+	return State(n), nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key, owner string, retention time.Duration) error {
+	_, err := s.client.Eval(ctx, completeScript, []string{s.prefix + key}, owner, retention.Milliseconds())
+	return err
+}
+
+func (s *RedisStore) Release(ctx context.Context, key, owner string) error {
+	_, err := s.client.Eval(ctx, releaseScript, []string{s.prefix + key}, owner)
+	return err
+}
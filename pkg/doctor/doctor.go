@@ -0,0 +1,75 @@
+// Package doctor runs self-tests and prints a pass/fail report,
+// e.g. as a deploy preflight.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Check is one self-test. Run returns a detail shown next to the outcome;
+// an error fails the check, unless it is a Skip.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type skipError struct{ reason string }
+
+func (e *skipError) Error() string { return e.reason }
+
+// Skip is returned by a check that does not apply, e.g. to a backend that
+// is not configured.
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Report runs checks and prints a line per check to its writer.
+type Report struct {
+	w       io.Writer
+	timeout time.Duration
+
+	passed, failed, skipped int
+}
+
+// NewReport bounds each check by timeout.
+func NewReport(w io.Writer, timeout time.Duration) *Report {
+	return &Report{w: w, timeout: timeout}
+}
+
+// Run runs the checks in order and reports whether none failed.
+func (r *Report) Run(ctx context.Context, checks ...Check) bool {
+	ok := true
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		start := time.Now()
+		detail, err := c.Run(checkCtx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		cancel()
+
+		var skip *skipError
+		switch {
+		case errors.As(err, &skip):
+			r.skipped++
+			fmt.Fprintf(r.w, "SKIP  %-24s %s\n", c.Name, skip.reason)
+		case err != nil:
+			r.failed++
+			ok = false
+			fmt.Fprintf(r.w, "FAIL  %-24s %v (%s)\n", c.Name, err, elapsed)
+		default:
+			r.passed++
+			fmt.Fprintf(r.w, "PASS  %-24s %s (%s)\n", c.Name, detail, elapsed)
+		}
+	}
+	return ok
+}
+
+// Summary prints the counts of the checks run and reports whether none
+// failed.
+func (r *Report) Summary() bool {
+	fmt.Fprintf(r.w, "\n%d passed, %d failed, %d skipped\n", r.passed, r.failed, r.skipped)
+	return r.failed == 0
+}
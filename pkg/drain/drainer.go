@@ -0,0 +1,83 @@
+package drain
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var inFlightGauge = metrics.NewGauge("http_in_flight_requests",
+	"HTTP requests currently being served.")
+
+// Drainer tracks in-flight requests and coordinates connection draining
+// during shutdown.
+type Drainer struct {
+	inFlight atomic.Int64
+	draining atomic.Bool
+
+	closeOnce sync.Once
+	streams   chan struct{}
+}
+
+func NewDrainer() *Drainer {
+	return &Drainer{streams: make(chan struct{})}
+}
+
+// Middleware counts the requests passing through it.
+func (d *Drainer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.inFlight.Add(1)
+		inFlightGauge.Inc()
+		defer func() {
+			d.inFlight.Add(-1)
+			inFlightGauge.Dec()
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (d *Drainer) InFlight() int64 {
+	return d.inFlight.Load()
+}
+
+// StartDraining marks the instance as going away; readiness probes should
+// fail from this point so load balancers stop routing new traffic here.
+func (d *Drainer) StartDraining() {
+	d.draining.Store(true)
+}
+
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// CloseStreams signals long-lived handlers (SSE, WebSocket) to finish. The
+// HTTP server does not wait for hijacked or streaming connections on its own.
+func (d *Drainer) CloseStreams() {
+	d.closeOnce.Do(func() { close(d.streams) })
+}
+
+// StreamsClosing is closed once long-lived handlers must wrap up, e.g. by
+// sending a final event and returning.
+func (d *Drainer) StreamsClosing() <-chan struct{} {
+	return d.streams
+}
+
+// Wait blocks until no request is in flight or ctx is done.
+func (d *Drainer) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for d.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package drain
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PreStop is the start of a shutdown: readiness failing for a delay so load
+// balancers stop routing to the instance. A Kubernetes preStop hook can run
+// it before the pod is sent SIGTERM, and the shutdown that follows then
+// does not wait again.
+type PreStop struct {
+	delay   time.Duration
+	onStart func()
+
+	once    sync.Once
+	started atomic.Bool
+	done    chan struct{}
+}
+
+// NewPreStop returns a PreStop waiting delay, after onStart has made
+// readiness fail.
+func NewPreStop(delay time.Duration, onStart func()) *PreStop {
+	return &PreStop{delay: delay, onStart: onStart, done: make(chan struct{})}
+}
+
+// Run starts the delay on its first call and waits until it has passed or
+// ctx is done. Later calls wait for the same delay rather than a new one.
+func (p *PreStop) Run(ctx context.Context) error {
+	p.once.Do(func() {
+		p.started.Store(true)
+		p.onStart()
+		time.AfterFunc(p.delay, func() { close(p.done) })
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Started reports whether Run has been called.
+func (p *PreStop) Started() bool {
+	return p.started.Load()
+}
@@ -0,0 +1,159 @@
+// Package envcrypt seals configuration values to a public key, so they can be
+// committed in .env files and decrypted only where the private key is. A
+// sealed value reads "ENC[x25519,<base64>]": an ephemeral X25519 public key
+// followed by the value encrypted with ChaCha20-Poly1305 under a key derived
+// from the shared secret.
+package envcrypt
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	prefix = "ENC[x25519,"
+	suffix = "]"
+	// hkdfInfo separates the keys derived here from other uses of the same
+	// X25519 keys.
+	hkdfInfo = "envcrypt v1"
+)
+
+// ErrMalformed is returned for sealed values that cannot be decoded.
+var ErrMalformed = errors.New("envcrypt: malformed sealed value")
+
+// ErrDecrypt is returned when a sealed value was not sealed to the
+// identity, or has been tampered with.
+var ErrDecrypt = errors.New("envcrypt: cannot decrypt value")
+
+// Identity is a private key, which decrypts the values sealed to its
+// Recipient.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// Recipient is the public key values are sealed to.
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// GenerateIdentity returns a new random identity.
+func GenerateIdentity() (*Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseIdentity parses an identity in the form String returns.
+func ParseIdentity(s string) (*Identity, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("envcrypt: parse identity: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("envcrypt: parse identity: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseRecipient parses a recipient in the form String returns.
+func ParseRecipient(s string) (*Recipient, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("envcrypt: parse recipient: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("envcrypt: parse recipient: %w", err)
+	}
+	return &Recipient{key: key}, nil
+}
+
+func (id *Identity) String() string {
+	return base64.StdEncoding.EncodeToString(id.key.Bytes())
+}
+
+func (id *Identity) Recipient() *Recipient {
+	return &Recipient{key: id.key.PublicKey()}
+}
+
+func (r *Recipient) String() string {
+	return base64.StdEncoding.EncodeToString(r.key.Bytes())
+}
+
+// IsSealed reports whether v is a sealed value.
+func IsSealed(v string) bool {
+	return strings.HasPrefix(v, prefix) && strings.HasSuffix(v, suffix)
+}
+
+// Seal encrypts plaintext to r.
+func Seal(r *Recipient, plaintext string) (string, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(shared, ephemeral.PublicKey().Bytes(), r.key.Bytes())
+	if err != nil {
+		return "", err
+	}
+	// Every value has its own key, so the nonce can be fixed.
+	nonce := make([]byte, aead.NonceSize())
+	out := aead.Seal(ephemeral.PublicKey().Bytes(), nonce, []byte(plaintext), nil)
+	return prefix + base64.StdEncoding.EncodeToString(out) + suffix, nil
+}
+
+// Open decrypts a value sealed to id.
+func (id *Identity) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", ErrMalformed
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed[len(prefix) : len(sealed)-len(suffix)])
+	if err != nil || len(raw) < 32+chacha20poly1305.Overhead {
+		return "", ErrMalformed
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(raw[:32])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	shared, err := id.key.ECDH(ephemeral)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	aead, err := newAEAD(shared, raw[:32], id.key.PublicKey().Bytes())
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, raw[32:], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// newAEAD derives the value key from the shared secret, bound to both public
+// keys.
+func newAEAD(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte(hkdfInfo)), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
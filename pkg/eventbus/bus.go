@@ -0,0 +1,174 @@
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/safe"
+	"github.com/haidang666/go-app/pkg/trace"
+)
+
+var (
+	publishedTotal = metrics.NewCounter("eventbus_published_total",
+		"Events accepted by the event bus.", "topic")
+	droppedTotal = metrics.NewCounter("eventbus_dropped_total",
+		"Events dropped because the event bus buffer was full or closed.", "topic")
+	handlerPanicsTotal = metrics.NewCounter("eventbus_handler_panics_total",
+		"Event handlers that panicked.", "topic")
+)
+
+var (
+	ErrBufferFull = errors.New("eventbus: buffer full")
+	ErrClosed     = errors.New("eventbus: closed")
+)
+
+// Event is a message published on a topic. Trace is the span context of the
+// publisher, so the handlers' spans join its trace.
+type Event struct {
+	// ID is unique to each Publish, so handlers fed by a transport that
+	// redelivers can drop repeats of an event.
+	ID          string
+	Topic       string
+	Payload     any
+	PublishedAt time.Time
+	Trace       trace.SpanContext
+}
+
+// Handler consumes events of a topic. Handlers run on the bus workers, so a
+// slow handler delays every topic. ctx is not canceled with the publisher's
+// request but carries its trace.
+type Handler func(ctx context.Context, e Event)
+
+type BusArgs struct {
+	// Buffer is how many events may wait for a worker before Publish drops.
+	Buffer  int
+	Workers int
+}
+
+// Bus is an in-process, asynchronous publish/subscribe bus. Publishing never
+// blocks the caller: events are queued and delivered by a fixed pool of
+// workers, and dropped when the queue is full.
+type Bus struct {
+	queue chan Event
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	closed   bool
+
+	wg sync.WaitGroup
+}
+
+func NewBus(args BusArgs) *Bus {
+	if args.Buffer <= 0 {
+		args.Buffer = 1024
+	}
+	if args.Workers <= 0 {
+		args.Workers = 1
+	}
+	b := &Bus{
+		queue:    make(chan Event, args.Buffer),
+		handlers: make(map[string][]Handler),
+	}
+	b.wg.Add(args.Workers)
+	for range args.Workers {
+		go b.work()
+	}
+	return b
+}
+
+// Subscribe registers h for every later event on topic.
+func (b *Bus) Subscribe(topic string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], h)
+}
+
+// Publish queues payload on topic. It returns ErrBufferFull or ErrClosed
+// when the event is dropped.
+func (b *Bus) Publish(ctx context.Context, topic string, payload any) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		droppedTotal.Inc(topic)
+		return ErrClosed
+	}
+	select {
+	case b.queue <- Event{ID: newEventID(), Topic: topic, Payload: payload, PublishedAt: time.Now(), Trace: trace.SpanContextFrom(ctx)}:
+		publishedTotal.Inc(topic)
+		return nil
+	default:
+		droppedTotal.Inc(topic)
+		return ErrBufferFull
+	}
+}
+
+// Pending returns how many published events wait for a worker.
+func (b *Bus) Pending() int {
+	return len(b.queue)
+}
+
+// Close stops accepting events and waits until the queued ones are
+// delivered or ctx is done.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) work() {
+	defer b.wg.Done()
+	for e := range b.queue {
+		b.mu.RLock()
+		handlers := b.handlers[e.Topic]
+		b.mu.RUnlock()
+
+		for _, h := range handlers {
+			b.deliver(h, e)
+		}
+	}
+}
+
+// newEventID returns 16 random bytes in hex, or "" in the unlikely case the
+// system's random source fails.
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+func (b *Bus) deliver(h Handler, e Event) {
+	ctx, span := trace.Start(trace.WithRemoteParent(context.Background(), e.Trace), "eventbus deliver "+e.Topic)
+	span.SetAttr("eventbus.queued", time.Since(e.PublishedAt))
+	defer span.End()
+	err := safe.Call(ctx, "eventbus."+e.Topic, func() error {
+		h(ctx, e)
+		return nil
+	}, "topic", e.Topic, "event_id", e.ID)
+	if err != nil {
+		handlerPanicsTotal.Inc(e.Topic)
+		span.RecordError(err)
+	}
+}
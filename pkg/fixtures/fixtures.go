@@ -0,0 +1,283 @@
+package fixtures
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// REF_KEY names a record so others can reference it.
+	REF_KEY = "_ref"
+	// REF_PREFIX starts a value replaced by a field of a named record once
+	// it is inserted, e.g. "$ref:alice.id".
+	REF_PREFIX = "$ref:"
+	// UNIQUE_PLACEHOLDER is replaced in every string by a suffix unique to
+	// the load, e.g. "alice+${unique}@example.com", so loads into a shared
+	// store do not collide.
+	UNIQUE_PLACEHOLDER = "${unique}"
+)
+
+var ErrUnresolvedRef = errors.New("fixtures: unresolved reference")
+
+// Record is one fixture entry with its references resolved.
+type Record map[string]any
+
+// Decode fills dst, a pointer to a struct with json tags, from r.
+func (r Record) Decode(dst any) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// InsertFunc stores a record and returns what was stored; its JSON fields
+// are what references to the record resolve to.
+type InsertFunc func(ctx context.Context, r Record) (any, error)
+
+type Table struct {
+	Insert InsertFunc
+	// Delete, when set, lets Set.Cleanup remove what Insert stored.
+	Delete func(ctx context.Context, stored any) error
+}
+
+// Loader loads fixture files into the registered tables. A file maps table
+// names to lists of records, in YAML or JSON:
+//
+//	users:
+//	  - _ref: alice
+//	    email: alice+${unique}@example.com
+//	subscriptions:
+//	  - user_id: $ref:alice.id
+//	    plan: pro
+//
+// Records are inserted once the records they reference are, whatever their
+// order in the files.
+type Loader struct {
+	tables map[string]Table
+}
+
+func NewLoader() *Loader {
+	return &Loader{tables: make(map[string]Table)}
+}
+
+func (l *Loader) Register(table string, t Table) {
+	l.tables[table] = t
+}
+
+// Set is the result of one load.
+type Set struct {
+	// Suffix is what UNIQUE_PLACEHOLDER was replaced with.
+	Suffix string
+
+	loader   *Loader
+	refs     map[string]any
+	inserted []insertedRecord
+}
+
+type insertedRecord struct {
+	table  string
+	stored any
+}
+
+// Get returns what was stored for the record named ref.
+func (s *Set) Get(ref string) (any, bool) {
+	v, ok := s.refs[ref]
+	return v, ok
+}
+
+// Cleanup deletes the inserted records, newest first, from the tables that
+// support it, isolating tests that share a store.
+func (s *Set) Cleanup(ctx context.Context) error {
+	var errs []error
+	for i := len(s.inserted) - 1; i >= 0; i-- {
+		rec := s.inserted[i]
+		if del := s.loader.tables[rec.table].Delete; del != nil {
+			if err := del(ctx, rec.stored); err != nil {
+				errs = append(errs, fmt.Errorf("delete %s fixture: %w", rec.table, err))
+			}
+		}
+	}
+	s.inserted = nil
+	return errors.Join(errs...)
+}
+
+type pendingRecord struct {
+	table  string
+	source string
+	fields map[string]any
+}
+
+// LoadFiles loads .yaml, .yml and .json fixture files in one Set, so their
+// records can reference each other.
+func (l *Loader) LoadFiles(ctx context.Context, paths ...string) (*Set, error) {
+	var pending []pendingRecord
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read fixtures: %w", err)
+		}
+		records, err := l.parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, records...)
+	}
+	return l.load(ctx, pending)
+}
+
+// LoadBytes loads one fixture document; name selects the format by its
+// extension and appears in errors.
+func (l *Loader) LoadBytes(ctx context.Context, name string, data []byte) (*Set, error) {
+	pending, err := l.parse(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return l.load(ctx, pending)
+}
+
+func (l *Loader) parse(name string, data []byte) ([]pendingRecord, error) {
+	var doc map[string][]map[string]any
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".json":
+		err = json.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("fixtures %s: unsupported format", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode fixtures %s: %w", name, err)
+	}
+
+	// Map order is random; sort the tables so loads are reproducible.
+	tables := make([]string, 0, len(doc))
+	for table := range doc {
+		if _, ok := l.tables[table]; !ok {
+			return nil, fmt.Errorf("fixtures %s: unknown table %q", name, table)
+		}
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+
+	var out []pendingRecord
+	for _, table := range tables {
+		for _, fields := range doc[table] {
+			out = append(out, pendingRecord{table: table, source: name, fields: fields})
+		}
+	}
+	return out, nil
+}
+
+func (l *Loader) load(ctx context.Context, pending []pendingRecord) (*Set, error) {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	s := &Set{Suffix: hex.EncodeToString(suffix), loader: l, refs: make(map[string]any)}
+
+	for len(pending) > 0 {
+		var (
+			deferred []pendingRecord
+			missing  error
+		)
+		for _, p := range pending {
+			resolved, err := s.resolve(p.fields)
+			if errors.Is(err, ErrUnresolvedRef) {
+				deferred, missing = append(deferred, p), err
+				continue
+			}
+			if err != nil {
+				return s, fmt.Errorf("fixtures %s: %s: %w", p.source, p.table, err)
+			}
+
+			ref, _ := resolved[REF_KEY].(string)
+			delete(resolved, REF_KEY)
+			stored, err := l.tables[p.table].Insert(ctx, Record(resolved))
+			if err != nil {
+				return s, fmt.Errorf("fixtures %s: insert %s %q: %w", p.source, p.table, ref, err)
+			}
+			s.inserted = append(s.inserted, insertedRecord{table: p.table, stored: stored})
+			if ref != "" {
+				s.refs[ref] = stored
+			}
+		}
+		if len(deferred) == len(pending) {
+			// Nothing was inserted: the references are unknown or circular.
+			return s, missing
+		}
+		pending = deferred
+	}
+	return s, nil
+}
+
+// resolve returns a copy of v with placeholders and references replaced.
+func (s *Set) resolve(v map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(v))
+	for k, fv := range v {
+		r, err := s.resolveValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = r
+	}
+	return out, nil
+}
+
+func (s *Set) resolveValue(v any) (any, error) {
+	switch t := v.(type) {
+	case string:
+		if target, ok := strings.CutPrefix(t, REF_PREFIX); ok {
+			return s.lookup(target)
+		}
+		return strings.ReplaceAll(t, UNIQUE_PLACEHOLDER, s.Suffix), nil
+	case map[string]any:
+		return s.resolve(t)
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			r, err := s.resolveValue(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// lookup resolves "name.field" to the JSON field of the stored record.
+func (s *Set) lookup(target string) (any, error) {
+	ref, field, ok := strings.Cut(target, ".")
+	if !ok {
+		return nil, fmt.Errorf("reference %q must be name.field", target)
+	}
+	stored, ok := s.refs[ref]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnresolvedRef, target)
+	}
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("encode %q: %w", ref, err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("reference %q: record is not an object", target)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("reference %q: no field %q", target, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,258 @@
+package request
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+const maxMultipartMemory = 1 << 20
+
+var (
+	ErrInvalidForm          = errors.New("invalid form body")
+	ErrUnsupportedMediaType = errors.New("request body must be JSON or a form")
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// FromQuery fills the fields of dest, a pointer to a struct, from the URL
+// query. See bind for the supported fields and tags.
+func FromQuery(r *http.Request, dest any) error {
+	return bind(r.URL.Query(), dest, "query")
+}
+
+// FromForm fills the fields of dest from a url-encoded or multipart form
+// body, like FromQuery but with `form` tags. Query parameters are ignored.
+func FromForm(r *http.Request, dest any) error {
+	values, err := parseForm(r)
+	if err != nil {
+		return err
+	}
+	return bind(values, dest, "form")
+}
+
+// Bind fills dest from the request body by its Content-Type, so one DTO
+// serves JSON clients and HTML or OAuth-style form posts alike. JSON, and
+// bodies without a type or sent as text/plain, are read as by FromJSON.
+// Url-encoded and multipart forms are read as by FromForm, naming the
+// fields without a `form` tag by their `json` one; unlike with JSON, form
+// fields dest lacks are ignored, as browsers also post their buttons. A
+// url-encoded body holding a JSON object, as curl -d sends, is read as JSON.
+func Bind(r *http.Request, dest any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "", mediaType == "application/json", mediaType == "text/plain",
+		strings.HasSuffix(mediaType, "+json"):
+		return FromJSON(r, dest)
+	case mediaType == "application/x-www-form-urlencoded" && holdsJSONObject(r):
+		return FromJSON(r, dest)
+	case mediaType == "application/x-www-form-urlencoded", mediaType == "multipart/form-data":
+		values, err := parseForm(r)
+		if err != nil {
+			return err
+		}
+		return bind(values, dest, "form", "json")
+	default:
+		return ErrUnsupportedMediaType
+	}
+}
+
+// BindStatus is the status to answer a failed Bind with.
+func BindStatus(err error) int {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
+
+// holdsJSONObject reports whether the body starts with "{", which no form
+// field name does, leaving the body to be read in full.
+func holdsJSONObject(r *http.Request) bool {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	body := bufio.NewReader(r.Body)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return false
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			body.UnreadByte()
+			return b == '{'
+		}
+	}
+}
+
+// parseForm reads a url-encoded or multipart form body of up to
+// maxBodySize.
+func parseForm(r *http.Request) (url.Values, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxMultipartMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, ErrInvalidForm
+	}
+	return r.PostForm, nil
+}
+
+// bind sets every field of dest tagged with the first of tags it has, e.g.
+// `query:"user_id"`, from the values of that name; embedded structs are
+// bound too. A missing value leaves the field as is, unless a
+// `default:"..."` tag supplies one.
+//
+// Fields may be strings (trimmed), bools, integers, floats, time.Duration,
+// any encoding.TextUnmarshaler such as time.Time or uuid.UUID, pointers to
+// these, or slices of them, filled from repeated or comma-separated values.
+// Values that do not convert are reported together as validate.Errors; the
+// caller still runs the struct's Validate, as with FromJSON.
+func bind(values url.Values, dest any, tags ...string) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return errors.New("dest must be a pointer to a struct")
+	}
+
+	var errs validate.Errors
+	bindStruct(values, tags, val.Elem(), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func bindStruct(values url.Values, tags []string, val reflect.Value, errs *validate.Errors) {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		var name string
+		for _, tag := range tags {
+			if value, ok := field.Tag.Lookup(tag); ok {
+				name, _, _ = strings.Cut(value, ",")
+				break
+			}
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindStruct(values, tags, val.Field(i), errs)
+			continue
+		}
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 {
+			def, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			raw = []string{def}
+		}
+		if err := setField(val.Field(i), raw); err != nil {
+			*errs = append(*errs, validate.FieldError{Field: name, Rule: "type", Message: name + " " + err.Error()})
+		}
+	}
+}
+
+func setField(f reflect.Value, raw []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+		var items []string
+		for _, r := range raw {
+			for item := range strings.SplitSeq(r, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(f.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		f.Set(slice)
+		return nil
+	}
+	return setValue(f, raw[len(raw)-1])
+}
+
+func setValue(f reflect.Value, raw string) error {
+	if f.Kind() == reflect.Pointer {
+		ptr := reflect.New(f.Type().Elem())
+		if err := setValue(ptr.Elem(), raw); err != nil {
+			return err
+		}
+		f.Set(ptr)
+		return nil
+	}
+	if reflect.PointerTo(f.Type()).Implements(textUnmarshalerType) {
+		if err := f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strings.TrimSpace(raw))); err != nil {
+			return fmt.Errorf("is invalid: %v", err)
+		}
+		return nil
+	}
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 1h")
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	raw = strings.TrimSpace(raw)
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, f.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("has unsupported type %s", f.Type())
+	}
+	return nil
+}
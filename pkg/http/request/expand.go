@@ -0,0 +1,28 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Expand reads the expand query parameter, the comma-separated relations to
+// embed in the response rather than leave to further requests, e.g.
+// ?expand=organization,sessions. A relation not in allowed is an error.
+func Expand(r *http.Request, allowed ...string) (map[string]bool, error) {
+	expand := make(map[string]bool)
+	for _, v := range r.URL.Query()["expand"] {
+		for _, rel := range strings.Split(v, ",") {
+			rel = strings.TrimSpace(rel)
+			if rel == "" {
+				continue
+			}
+			if !slices.Contains(allowed, rel) {
+				return nil, fmt.Errorf("cannot expand %q, expected one of %s", rel, strings.Join(allowed, ", "))
+			}
+			expand[rel] = true
+		}
+	}
+	return expand, nil
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var ErrInvalidPagination = errors.New("limit and offset must be non-negative integers")
+
+// Pagination reads the limit and offset query parameters. A missing limit
+// falls back to defaultLimit and larger values are capped at maxLimit.
+func Pagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	limit, offset = defaultLimit, 0
+
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, 0, ErrInvalidPagination
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, ErrInvalidPagination
+		}
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit, offset, nil
+}
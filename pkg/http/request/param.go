@@ -0,0 +1,48 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/validate"
+)
+
+// The Param functions read a chi path parameter. A value that does not
+// parse is reported as validate.Errors naming the parameter, so handlers
+// answer it with a 400 like any other invalid input.
+
+// ParamUUID returns the path parameter name as a UUID.
+func ParamUUID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		return uuid.Nil, paramError(name, "uuid", name+" must be a valid UUID")
+	}
+	return id, nil
+}
+
+// ParamInt returns the path parameter name as an int.
+func ParamInt(r *http.Request, name string) (int, error) {
+	n, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil {
+		return 0, paramError(name, "int", name+" must be an integer")
+	}
+	return n, nil
+}
+
+// ParamEnum returns the path parameter name if it is one of allowed.
+func ParamEnum(r *http.Request, name string, allowed ...string) (string, error) {
+	v := chi.URLParam(r, name)
+	if !slices.Contains(allowed, v) {
+		return "", paramError(name, "oneof", fmt.Sprintf("%s must be one of: %s", name, strings.Join(allowed, " ")))
+	}
+	return v, nil
+}
+
+func paramError(name, rule, msg string) error {
+	return validate.Errors{{Field: name, Rule: rule, Message: msg}}
+}
@@ -1,17 +1,26 @@
 package request
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 const maxBodySize = 1 << 20
 
+// bufferLimit is the largest response ToJSON copies into its pooled buffer
+// to send with a Content-Length. json.Encoder marshals the whole value before
+// writing any of it, so larger responses are not streamed: they are
+// written from the encoder's own buffer, without the copy or the length.
+const bufferLimit = 64 << 10
+
 var (
 	ErrEmptyBody     = errors.New("request body is empty")
 	ErrTooLarge      = errors.New("request body is too large")
@@ -19,27 +28,76 @@ var (
 	ErrUnknownFields = errors.New("request contains unknown fields")
 )
 
-func ToJSON(w http.ResponseWriter, data any, statusCode int) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(statusCode)
+var debugJSON = sync.OnceValue(func() bool { return os.Getenv("DEBUG") == "true" })
+
+// jsonWriter collects an encoded response unless it outgrows bufferLimit,
+// in which case it sends the status and the response directly. Writers and
+// their encoders are pooled, so small responses cost no allocations of their
+// own.
+type jsonWriter struct {
+	w      http.ResponseWriter
+	status int
+	direct bool
+	buf    bytes.Buffer
+	enc    *json.Encoder
+}
 
-	var b []byte
-	var err error
+var jsonWriters = sync.Pool{New: func() any {
+	jw := new(jsonWriter)
+	jw.enc = json.NewEncoder(jw)
+	return jw
+}}
 
-	if os.Getenv("DEBUG") == "true" {
-		b, err = json.MarshalIndent(data, "", "  ")
+func (jw *jsonWriter) Write(p []byte) (int, error) {
+	if !jw.direct && jw.buf.Len()+len(p) <= bufferLimit {
+		return jw.buf.Write(p)
+	}
+	if !jw.direct {
+		jw.direct = true
+		jw.w.WriteHeader(jw.status)
+		if _, err := jw.w.Write(jw.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		jw.buf.Reset()
+	}
+	return jw.w.Write(p)
+}
+
+// ToJSON writes data as the response body. Nothing is sent if data cannot be
+// encoded, so the failure can still be reported with a 500.
+func ToJSON(w http.ResponseWriter, data any, statusCode int) {
+	jw := jsonWriters.Get().(*jsonWriter)
+	jw.w, jw.status = w, statusCode
+	if debugJSON() {
+		jw.enc.SetIndent("", "  ")
 	} else {
-		b, err = json.Marshal(data)
+		jw.enc.SetIndent("", "")
 	}
 
-	if err != nil {
-		http.Error(w, `{"error":"failed to encode json"}`, http.StatusInternalServerError)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := jw.enc.Encode(data)
+	switch {
+	case err != nil && jw.direct:
+		// The client has gone; the encoder keeps the write error, so it is
+		// not reused.
 		return
+	case err != nil:
+		http.Error(w, `{"error":"failed to encode json"}`, http.StatusInternalServerError)
+	default:
+		if !jw.direct {
+			w.Header().Set("Content-Length", strconv.Itoa(jw.buf.Len()))
+			w.WriteHeader(statusCode)
+			w.Write(jw.buf.Bytes())
+		}
 	}
 
-	w.Write(b)
+	jw.w, jw.direct = nil, false
+	jw.buf.Reset()
+	jsonWriters.Put(jw)
 }
 
+// FromJSON decodes the request body into dest as it is read, rejecting
+// bodies over maxBodySize and unknown fields, and trims its string fields.
 func FromJSON(r *http.Request, dest any) error {
 	if dest == nil {
 		return errors.New("dest is nil")
@@ -47,23 +105,17 @@ func FromJSON(r *http.Request, dest any) error {
 
 	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		if err.Error() == "http: request body too large" {
-			return ErrTooLarge
-		}
-		return err
-	}
-
-	if len(body) == 0 {
-		return ErrEmptyBody
-	}
-
-	dec := json.NewDecoder(strings.NewReader(string(body)))
+	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
 	if err := dec.Decode(dest); err != nil {
-		if strings.Contains(err.Error(), "unknown field") {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, io.EOF):
+			return ErrEmptyBody
+		case errors.As(err, &tooLarge):
+			return ErrTooLarge
+		case strings.Contains(err.Error(), "unknown field"):
 			return ErrUnknownFields
 		}
 		return ErrInvalidJSON
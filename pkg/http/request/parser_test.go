@@ -0,0 +1,94 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// discardWriter is a ResponseWriter that keeps nothing, so the benchmarks
+// count the allocations of ToJSON alone.
+type discardWriter struct{ header http.Header }
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+type benchUser struct {
+	ID        string   `json:"id"`
+	Email     string   `json:"email"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Roles     []string `json:"roles"`
+}
+
+func benchUsers(n int) []benchUser {
+	users := make([]benchUser, n)
+	for i := range users {
+		users[i] = benchUser{
+			ID:        "5f0c1c52-7c8e-4d1c-9a57-2f1c3f6f7a10",
+			Email:     "someone@example.com",
+			FirstName: "Some",
+			LastName:  "One",
+			Roles:     []string{"user", "billing"},
+		}
+	}
+	return users
+}
+
+// BenchmarkToJSON covers a response small enough to be buffered and one over
+// bufferLimit, written from the encoder's buffer.
+func BenchmarkToJSON(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		data any
+	}{
+		{"small", benchUsers(1)[0]},
+		{"large", benchUsers(2000)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			w := &discardWriter{header: make(http.Header)}
+			b.ReportAllocs()
+			for b.Loop() {
+				ToJSON(w, bc.data, http.StatusOK)
+			}
+		})
+	}
+}
+
+func BenchmarkFromJSON(b *testing.B) {
+	type signUp struct {
+		Email     string `json:"email"`
+		Password  string `json:"password"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	body, err := json.Marshal(signUp{
+		Email:     "  someone@example.com ",
+		Password:  strings.Repeat("p", 32),
+		FirstName: "Some",
+		LastName:  "One",
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	reader := bytes.NewReader(body)
+	r, err := http.NewRequest(http.MethodPost, "/", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	for b.Loop() {
+		reader.Reset(body)
+		r.Body = io.NopCloser(reader)
+		var dest signUp
+		if err := FromJSON(r, &dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
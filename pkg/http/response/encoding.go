@@ -0,0 +1,449 @@
+package response
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Time formats of an Encoding.
+const (
+	TIME_RFC3339 = "rfc3339"
+	TIME_UNIX    = "unix"
+	TIME_UNIX_MS = "unix_ms"
+)
+
+// Null policies of an Encoding.
+const (
+	NULLS_OMIT     = "omit"
+	NULLS_EXPLICIT = "explicit"
+)
+
+// Encoding adjusts how JSON renders values, for clients whose parsers are
+// strict about them. The zero Encoding renders as encoding/json does.
+type Encoding struct {
+	// Time is TIME_RFC3339 (the default), or TIME_UNIX or TIME_UNIX_MS to
+	// render times as seconds or milliseconds since the epoch.
+	Time string
+	// Int64AsString renders 64-bit integers as strings, which JavaScript
+	// parses without losing precision beyond 2^53.
+	Int64AsString bool
+	// Nulls is NULLS_OMIT to drop the null members of objects, or
+	// NULLS_EXPLICIT to render nil pointers, slices, maps and interfaces as
+	// null even when their field is tagged omitempty. By default, fields
+	// follow their tags.
+	Nulls string
+}
+
+type encodingCtxKey struct{}
+
+// ParseEncodings parses the encodings of API versions, keyed by version.
+// Each spec lists key=value options separated by ";":
+// time=rfc3339|unix|unix_ms, int64=number|string and nulls=omit|explicit,
+// e.g. "time=unix;int64=string".
+func ParseEncodings(specs map[string]string) (map[string]Encoding, error) {
+	encodings := make(map[string]Encoding, len(specs))
+	for version, spec := range specs {
+		var enc Encoding
+		for _, part := range strings.Split(spec, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("version %q: %q is not key=value", version, part)
+			}
+			var err error
+			switch key {
+			case "time":
+				enc.Time = value
+				if !slices.Contains([]string{TIME_RFC3339, TIME_UNIX, TIME_UNIX_MS}, value) {
+					err = errors.New("not rfc3339, unix or unix_ms")
+				}
+			case "int64":
+				enc.Int64AsString = value == "string"
+				if value != "string" && value != "number" {
+					err = errors.New("not number or string")
+				}
+			case "nulls":
+				enc.Nulls = value
+				if value != NULLS_OMIT && value != NULLS_EXPLICIT {
+					err = errors.New("not omit or explicit")
+				}
+			default:
+				err = errors.New("unknown key")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("version %q: invalid %s %q: %w", version, key, value, err)
+			}
+		}
+		encodings[version] = enc
+	}
+	return encodings, nil
+}
+
+// UseEncoding returns a middleware that renders the JSON responses of the
+// routes it wraps, typically one API version group, with enc.
+func UseEncoding(enc Encoding) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if enc.isDefault() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), encodingCtxKey{}, enc)))
+		})
+	}
+}
+
+func encodingFrom(ctx context.Context) Encoding {
+	enc, _ := ctx.Value(encodingCtxKey{}).(Encoding)
+	return enc
+}
+
+func (e Encoding) isDefault() bool {
+	return (e.Time == "" || e.Time == TIME_RFC3339) && !e.Int64AsString && e.Nulls == ""
+}
+
+// Render returns data as a value encoding/json renders with e. It follows
+// the json tags and marshalers data's types have, except that fields
+// promoted from unexported embedded structs are left out.
+func (e Encoding) Render(data any) any {
+	if e.isDefault() {
+		return data
+	}
+	return e.value(reflect.ValueOf(data))
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	numberType        = reflect.TypeFor[json.Number]()
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func (e Encoding) value(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	t := v.Type()
+	if t == timeType {
+		return e.time(v.Interface().(time.Time))
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return e.value(v.Elem())
+	}
+	if t == numberType || t.Implements(marshalerType) || t.Implements(textMarshalerType) {
+		return v.Interface()
+	}
+	if v.CanAddr() && (reflect.PointerTo(t).Implements(marshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)) {
+		return v.Addr().Interface()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return e.object(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		return e.mapObject(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = e.value(v.Index(i))
+		}
+		return out
+	case reflect.Int, reflect.Int64:
+		if e.Int64AsString {
+			return strconv.FormatInt(v.Int(), 10)
+		}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if e.Int64AsString {
+			return strconv.FormatUint(v.Uint(), 10)
+		}
+	}
+	return v.Interface()
+}
+
+func (e Encoding) time(t time.Time) any {
+	switch e.Time {
+	case TIME_UNIX:
+		return t.Unix()
+	case TIME_UNIX_MS:
+		return t.UnixMilli()
+	}
+	return t
+}
+
+func (e Encoding) object(v reflect.Value) object {
+	fields := typeFields(v.Type())
+	obj := make(object, 0, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		var value any
+		switch {
+		case (f.omitEmpty && isEmpty(fv)) || (f.omitZero && isZero(fv)):
+			if e.Nulls != NULLS_EXPLICIT || !isNil(fv) {
+				continue
+			}
+		case f.quoted:
+			value = e.quoted(fv)
+		default:
+			value = e.value(fv)
+		}
+		if value == nil && e.Nulls == NULLS_OMIT {
+			continue
+		}
+		obj = append(obj, member{name: f.name, value: value})
+	}
+	return obj
+}
+
+// quoted renders a field tagged ",string".
+func (e Encoding) quoted(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		b, _ := json.Marshal(v.Interface())
+		return string(b)
+	case reflect.String:
+		b, _ := json.Marshal(v.String())
+		return string(b)
+	}
+	return e.value(v)
+}
+
+func (e Encoding) mapObject(v reflect.Value) object {
+	obj := make(object, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		name, ok := mapKey(iter.Key())
+		if !ok {
+			continue
+		}
+		value := e.value(iter.Value())
+		if value == nil && e.Nulls == NULLS_OMIT {
+			continue
+		}
+		obj = append(obj, member{name: name, value: value})
+	}
+	slices.SortFunc(obj, func(a, b member) int { return strings.Compare(a.name, b.name) })
+	return obj
+}
+
+func mapKey(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", true
+		}
+		b, err := tm.MarshalText()
+		return string(b), err == nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// object is a JSON object whose members keep their order.
+type object []member
+
+type member struct {
+	name  string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, m := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(append(b, name...), ':'), value...)
+	}
+	return append(b, '}'), nil
+}
+
+type field struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// typeFields returns the fields encoding/json renders for t, in order,
+// resolving the names promoted from embedded structs as it does: the
+// shallowest field wins, then the tagged one, and names still ambiguous
+// are dropped.
+func typeFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	var all []field
+	collectFields(t, nil, &all)
+
+	byName := make(map[string][]int)
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	fields := make([]field, 0, len(all))
+	for i, f := range all {
+		if dominant(all, byName[f.name]) == i {
+			fields = append(fields, f)
+		}
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// dominant returns which of the fields at positions, all of one name, wins,
+// or -1 when none does.
+func dominant(fields []field, positions []int) int {
+	depth := len(fields[positions[0]].index)
+	for _, i := range positions {
+		depth = min(depth, len(fields[i].index))
+	}
+	var shallowest, tagged []int
+	for _, i := range positions {
+		if len(fields[i].index) == depth {
+			shallowest = append(shallowest, i)
+			if fields[i].tagged {
+				tagged = append(tagged, i)
+			}
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0]
+	case len(tagged) == 1:
+		return tagged[0]
+	}
+	return -1
+}
+
+func collectFields(t reflect.Type, index []int, out *[]field) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		idx := append(slices.Clone(index), i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if sf.IsExported() {
+					collectFields(ft, idx, out)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		f := field{name: name, index: idx, tagged: name != ""}
+		if name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "omitzero":
+				f.omitZero = true
+			case "string":
+				f.quoted = true
+			}
+		}
+		*out = append(*out, f)
+	}
+}
+
+// fieldByIndex is v.FieldByIndex, reporting false when the field is in a
+// nil embedded struct.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func isZero(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
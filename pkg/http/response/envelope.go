@@ -0,0 +1,97 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/haidang666/go-app/pkg/http/request"
+)
+
+type envelopeCtxKey struct{}
+
+// Envelope is the standard success body when enveloping is enabled for an
+// API version.
+type Envelope struct {
+	Data  any               `json:"data"`
+	Meta  map[string]any    `json:"meta,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// envelopeState collects meta and links contributed by middleware and
+// handlers during a request.
+type envelopeState struct {
+	enabled bool
+	mu      sync.Mutex
+	meta    map[string]any
+	links   map[string]string
+}
+
+// UseEnvelope returns a middleware that turns enveloping on or off for the
+// routes it wraps, typically one API version group.
+func UseEnvelope(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := &envelopeState{
+				enabled: enabled,
+				meta:    make(map[string]any),
+				links:   make(map[string]string),
+			}
+			ctx := context.WithValue(r.Context(), envelopeCtxKey{}, state)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AddMeta records a meta entry for the current response. It is a no-op
+// outside an envelope-aware route group.
+func AddMeta(r *http.Request, key string, value any) {
+	if state := stateFrom(r.Context()); state != nil {
+		state.mu.Lock()
+		state.meta[key] = value
+		state.mu.Unlock()
+	}
+}
+
+// AddLink records a link relation for the current response.
+func AddLink(r *http.Request, rel, href string) {
+	if state := stateFrom(r.Context()); state != nil {
+		state.mu.Lock()
+		state.links[rel] = href
+		state.mu.Unlock()
+	}
+}
+
+// JSON writes data as JSON, wrapped in an Envelope when the route group has
+// enveloping enabled, and rendered with the route group's Encoding.
+func JSON(w http.ResponseWriter, r *http.Request, data any, statusCode int) {
+	enc := encodingFrom(r.Context())
+	state := stateFrom(r.Context())
+	if state == nil || !state.enabled {
+		request.ToJSON(w, enc.Render(data), statusCode)
+		return
+	}
+
+	state.mu.Lock()
+	env := Envelope{Data: enc.Render(data)}
+	if len(state.meta) > 0 {
+		env.Meta = state.meta
+		if !enc.isDefault() {
+			env.Meta = make(map[string]any, len(state.meta))
+			for k, v := range state.meta {
+				env.Meta[k] = enc.Render(v)
+			}
+		}
+	}
+	if len(state.links) > 0 {
+		env.Links = state.links
+	}
+	state.mu.Unlock()
+
+	request.ToJSON(w, env, statusCode)
+}
+
+func stateFrom(ctx context.Context) *envelopeState {
+	state, _ := ctx.Value(envelopeCtxKey{}).(*envelopeState)
+	return state
+}
@@ -0,0 +1,79 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/haidang666/go-app/pkg/apperr"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+const PROBLEM_CONTENT_TYPE = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. Every error response carries
+// the request ID so clients can quote it when reporting issues.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code identifies the failure for errors that carry one.
+	Code string `json:"code,omitempty"`
+	// Details carries structured data for errors clients act on.
+	Details   map[string]any `json:"details,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// NewProblem builds a Problem for status with the request's ID and path.
+func NewProblem(r *http.Request, status int, detail string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: ctxutil.RequestID(r.Context()),
+	}
+}
+
+// Error writes err as a problem+json response. An err with an ErrorCode
+// method sets the problem's code, and one with an ErrorDetails method its
+// details. Server errors are also reported with apperr.Report.
+func Error(w http.ResponseWriter, r *http.Request, status int, err error) {
+	// A request its client abandoned failing with the cancellation is not a
+	// fault of ours.
+	abandoned := errors.Is(err, context.Canceled) && r.Context().Err() != nil
+	// One that ran out of its deadline budget is answered as unavailable,
+	// as a retry may find the server less busy.
+	if errors.Is(err, context.DeadlineExceeded) && errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		status = http.StatusServiceUnavailable
+	}
+	if status >= http.StatusInternalServerError && !abandoned {
+		apperr.Report(r.Context(), "request failed", err)
+	}
+	p := NewProblem(r, status, err.Error())
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		p.Code = coded.ErrorCode()
+	}
+	var detailed interface{ ErrorDetails() map[string]any }
+	if errors.As(err, &detailed) {
+		p.Details = detailed.ErrorDetails()
+	}
+	WriteProblem(w, p)
+}
+
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", PROBLEM_CONTENT_TYPE)
+	w.WriteHeader(p.Status)
+	w.Write(b)
+}
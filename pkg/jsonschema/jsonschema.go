@@ -0,0 +1,489 @@
+// Package jsonschema validates decoded JSON against JSON Schema. It covers
+// the keywords request contracts use: type (with OpenAPI's nullable), enum,
+// const, the object, array, string and number constraints, format (email,
+// uuid, date, date-time, uri), allOf, anyOf, oneOf, not, and local $refs
+// into the document the schema came from.
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxRefDepth bounds $ref chains, so a cyclic schema fails instead of
+// recursing forever.
+const maxRefDepth = 32
+
+// Violation is one way a value breaks its schema.
+type Violation struct {
+	// Path is the JSON pointer of the offending value; "" is the root.
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// Schema is a compiled schema. It is safe for concurrent use.
+type Schema struct {
+	doc     any
+	node    any
+	regexps map[string]*regexp.Regexp
+}
+
+// Parse compiles a standalone JSON schema document.
+func Parse(data []byte) (*Schema, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return New(doc, doc)
+}
+
+// New compiles node, a schema decoded from JSON or YAML, whose $refs point
+// into doc, e.g. "#/components/schemas/User" of an OpenAPI document.
+func New(doc, node any) (*Schema, error) {
+	s := &Schema{doc: normalize(doc), regexps: make(map[string]*regexp.Regexp)}
+	s.node = normalize(node)
+	if err := s.compile(s.node, 0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compile checks every $ref resolves and compiles every pattern.
+func (s *Schema) compile(node any, depth int) error {
+	if depth > maxRefDepth {
+		return errors.New("jsonschema: $ref chain too deep or cyclic")
+	}
+	m, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		return s.compile(target, depth+1)
+	}
+	if p, ok := m["pattern"].(string); ok {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("jsonschema: pattern %q: %w", p, err)
+		}
+		s.regexps[p] = re
+	}
+	for key, v := range m {
+		switch key {
+		case "properties", "$defs", "definitions", "patternProperties":
+			props, _ := v.(map[string]any)
+			for _, sub := range props {
+				if err := s.compile(sub, depth); err != nil {
+					return err
+				}
+			}
+		case "items", "additionalProperties", "not":
+			if err := s.compile(v, depth); err != nil {
+				return err
+			}
+		case "allOf", "anyOf", "oneOf":
+			subs, _ := v.([]any)
+			for _, sub := range subs {
+				if err := s.compile(sub, depth); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows a local reference such as "#/$defs/Name".
+func (s *Schema) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: only local $refs are supported: %q", ref)
+	}
+	node := s.doc
+	for part := range strings.SplitSeq(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("jsonschema: unresolvable $ref %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("jsonschema: unresolvable $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// Validate returns every violation of the schema by v, a value decoded from
+// JSON, preferably with json.Decoder.UseNumber so integers are exact.
+func (s *Schema) Validate(v any) []Violation {
+	var out []Violation
+	s.validate(s.node, normalize(v), "", &out, 0)
+	return out
+}
+
+func (s *Schema) validate(node, v any, path string, out *[]Violation, depth int) {
+	fail := func(keyword, format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch n := node.(type) {
+	case bool:
+		if !n {
+			fail("false", "no value is allowed here")
+		}
+		return
+	case map[string]any:
+		node = n
+	default:
+		return
+	}
+	m := node.(map[string]any)
+
+	if ref, ok := m["$ref"].(string); ok {
+		// compile guarantees the reference resolves.
+		if target, err := s.resolve(ref); err == nil && depth < maxRefDepth {
+			s.validate(target, v, path, out, depth+1)
+		}
+		return
+	}
+
+	if v == nil && m["nullable"] == true {
+		return
+	}
+	if t, ok := m["type"]; ok && !matchesType(t, v) {
+		fail("type", "must be of type %s", typeNames(t))
+		return
+	}
+	if enum, ok := m["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equal(e, v) }) {
+		fail("enum", "must be one of %s", formatValues(enum))
+	}
+	if c, ok := m["const"]; ok && !equal(c, v) {
+		fail("const", "must be %s", formatValue(c))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		s.validateObject(m, val, path, out, depth)
+	case []any:
+		s.validateArray(m, val, path, out, depth)
+	case string:
+		s.validateString(m, val, fail)
+	case float64:
+		validateNumber(m, val, fail)
+	}
+
+	if all, ok := m["allOf"].([]any); ok {
+		for _, sub := range all {
+			s.validate(sub, v, path, out, depth)
+		}
+	}
+	if anyOf, ok := m["anyOf"].([]any); ok {
+		if s.matching(anyOf, v, depth) == 0 {
+			fail("anyOf", "must match at least one of the allowed schemas")
+		}
+	}
+	if oneOf, ok := m["oneOf"].([]any); ok {
+		if n := s.matching(oneOf, v, depth); n != 1 {
+			fail("oneOf", "must match exactly one of the allowed schemas, matches %d", n)
+		}
+	}
+	if not, ok := m["not"]; ok {
+		var sub []Violation
+		if s.validate(not, v, path, &sub, depth); len(sub) == 0 {
+			fail("not", "must not match the excluded schema")
+		}
+	}
+}
+
+func (s *Schema) matching(schemas []any, v any, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		var violations []Violation
+		if s.validate(sub, v, "", &violations, depth); len(violations) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) validateObject(m map[string]any, val map[string]any, path string, out *[]Violation, depth int) {
+	if required, ok := m["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := val[name]; !present {
+					*out = append(*out, Violation{Path: join(path, name), Keyword: "required", Message: "is required"})
+				}
+			}
+		}
+	}
+	if n, ok := number(m["minProperties"]); ok && float64(len(val)) < n {
+		*out = append(*out, Violation{Path: path, Keyword: "minProperties", Message: fmt.Sprintf("must have at least %v properties", n)})
+	}
+	if n, ok := number(m["maxProperties"]); ok && float64(len(val)) > n {
+		*out = append(*out, Violation{Path: path, Keyword: "maxProperties", Message: fmt.Sprintf("must have at most %v properties", n)})
+	}
+
+	props, _ := m["properties"].(map[string]any)
+	additional, hasAdditional := m["additionalProperties"]
+	names := make([]string, 0, len(val))
+	for name := range val {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if sub, ok := props[name]; ok {
+			s.validate(sub, val[name], join(path, name), out, depth)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if additional == false {
+			*out = append(*out, Violation{Path: join(path, name), Keyword: "additionalProperties", Message: "is not allowed"})
+			continue
+		}
+		s.validate(additional, val[name], join(path, name), out, depth)
+	}
+}
+
+func (s *Schema) validateArray(m map[string]any, val []any, path string, out *[]Violation, depth int) {
+	if n, ok := number(m["minItems"]); ok && float64(len(val)) < n {
+		*out = append(*out, Violation{Path: path, Keyword: "minItems", Message: fmt.Sprintf("must have at least %v items", n)})
+	}
+	if n, ok := number(m["maxItems"]); ok && float64(len(val)) > n {
+		*out = append(*out, Violation{Path: path, Keyword: "maxItems", Message: fmt.Sprintf("must have at most %v items", n)})
+	}
+	if m["uniqueItems"] == true {
+	unique:
+		for i := range val {
+			for j := range i {
+				if equal(val[i], val[j]) {
+					*out = append(*out, Violation{Path: path, Keyword: "uniqueItems", Message: "must not contain duplicates"})
+					break unique
+				}
+			}
+		}
+	}
+	if items, ok := m["items"]; ok {
+		for i, item := range val {
+			s.validate(items, item, join(path, strconv.Itoa(i)), out, depth)
+		}
+	}
+}
+
+func (s *Schema) validateString(m map[string]any, val string, fail func(keyword, format string, args ...any)) {
+	length := float64(utf8.RuneCountInString(val))
+	if n, ok := number(m["minLength"]); ok && length < n {
+		fail("minLength", "must be at least %v characters", n)
+	}
+	if n, ok := number(m["maxLength"]); ok && length > n {
+		fail("maxLength", "must be at most %v characters", n)
+	}
+	if p, ok := m["pattern"].(string); ok && !s.regexps[p].MatchString(val) {
+		fail("pattern", "must match %s", p)
+	}
+	if f, ok := m["format"].(string); ok && !validFormat(f, val) {
+		fail("format", "must be a valid %s", f)
+	}
+}
+
+func validateNumber(m map[string]any, val float64, fail func(keyword, format string, args ...any)) {
+	if n, ok := number(m["minimum"]); ok {
+		// OpenAPI 3.0 spells an exclusive bound as a boolean next to it.
+		if m["exclusiveMinimum"] == true && val <= n {
+			fail("exclusiveMinimum", "must be greater than %v", n)
+		} else if val < n {
+			fail("minimum", "must be at least %v", n)
+		}
+	}
+	if n, ok := number(m["maximum"]); ok {
+		if m["exclusiveMaximum"] == true && val >= n {
+			fail("exclusiveMaximum", "must be less than %v", n)
+		} else if val > n {
+			fail("maximum", "must be at most %v", n)
+		}
+	}
+	if n, ok := number(m["exclusiveMinimum"]); ok && val <= n {
+		fail("exclusiveMinimum", "must be greater than %v", n)
+	}
+	if n, ok := number(m["exclusiveMaximum"]); ok && val >= n {
+		fail("exclusiveMaximum", "must be less than %v", n)
+	}
+	if n, ok := number(m["multipleOf"]); ok && n > 0 {
+		if q := val / n; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("multipleOf", "must be a multiple of %v", n)
+		}
+	}
+}
+
+func validFormat(format, val string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(val)
+		return err == nil && addr.Address == val
+	case "uuid":
+		_, err := uuid.Parse(val)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, val)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(val)
+		return err == nil && u.Scheme != ""
+	}
+	// Unknown formats are annotations only.
+	return true
+}
+
+func matchesType(t, v any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, v)
+	case []any:
+		return slices.ContainsFunc(t, func(name any) bool {
+			s, _ := name.(string)
+			return isType(s, v)
+		})
+	}
+	return true
+}
+
+func isType(name string, v any) bool {
+	switch name {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+// normalize turns every number into a float64 and every map into a
+// map[string]any, whether v was decoded from JSON (possibly with UseNumber)
+// or from YAML.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = normalize(e)
+		}
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[fmt.Sprint(k)] = normalize(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = normalize(e)
+		}
+		return out
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		bm, ok := b.(map[string]any)
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := bm[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bs, ok := b.([]any)
+		return ok && slices.EqualFunc(a, bs, equal)
+	}
+	return a == b
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+func typeNames(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func formatValues(values []any) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = formatValue(v)
+	}
+	return strings.Join(out, ", ")
+}
+
+func formatValue(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// join appends a segment to a JSON pointer.
+func join(path, segment string) string {
+	return path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(segment)
+}
@@ -0,0 +1,99 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Operation is the request body schema of one OpenAPI operation.
+type Operation struct {
+	Method string
+	// Path is the templated path, e.g. /api/v1/users/{id}, prefixed with the
+	// path of the document's first server URL.
+	Path   string
+	Schema *Schema
+}
+
+// LoadOpenAPI reads an OpenAPI 3 document, in JSON or YAML, and compiles the
+// application/json request body schema of each of its operations.
+// Operations without one are left out.
+func LoadOpenAPI(file string) ([]Operation, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	// YAML is a superset of JSON, so one decoder reads both.
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("jsonschema: parse %s: %w", file, err)
+	}
+	doc, ok := normalize(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: %s is not an OpenAPI document", file)
+	}
+
+	prefix := ""
+	if servers, ok := doc["servers"].([]any); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]any); ok {
+			if u, err := url.Parse(fmt.Sprint(server["url"])); err == nil && u.Path != "/" {
+				prefix = u.Path
+			}
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	var ops []Operation
+	for p, item := range paths {
+		methods, _ := item.(map[string]any)
+		for method, op := range methods {
+			method = strings.ToUpper(method)
+			if !isMethod(method) {
+				continue
+			}
+			node, ok := jsonBodySchema(op)
+			if !ok {
+				continue
+			}
+			schema, err := New(doc, node)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, p, err)
+			}
+			ops = append(ops, Operation{Method: method, Path: path.Join("/", prefix, p), Schema: schema})
+		}
+	}
+	slices.SortFunc(ops, func(a, b Operation) int {
+		return strings.Compare(a.Path+" "+a.Method, b.Path+" "+b.Method)
+	})
+	return ops, nil
+}
+
+// jsonBodySchema digs requestBody.content["application/json"].schema out of
+// an operation.
+func jsonBodySchema(op any) (any, bool) {
+	node := op
+	for _, key := range []string{"requestBody", "content", "application/json", "schema"} {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func isMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
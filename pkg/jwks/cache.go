@@ -0,0 +1,93 @@
+package jwks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDocumentSize bounds the discovery documents and key sets read.
+const maxDocumentSize = 1 << 20
+
+// document is a fetched HTTP resource with what it takes to revalidate it.
+type document struct {
+	body         []byte
+	etag         string
+	lastModified string
+	// expires is when the document must be revalidated, from its
+	// Cache-Control max-age clamped to the fetcher's bounds.
+	expires time.Time
+}
+
+// get fetches url, revalidating prev with If-None-Match and
+// If-Modified-Since when it is set. A 304 keeps prev's body; notModified
+// reports it.
+func (f *Fetcher) get(ctx context.Context, url string, prev *document) (_ *document, notModified bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if prev != nil {
+		if prev.etag != "" {
+			req.Header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.lastModified)
+		}
+	}
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	now := f.clock.Now()
+	switch {
+	case res.StatusCode == http.StatusNotModified && prev != nil:
+		doc := *prev
+		doc.expires = now.Add(f.maxAge(res.Header))
+		if etag := res.Header.Get("ETag"); etag != "" {
+			doc.etag = etag
+		}
+		return &doc, true, nil
+	case res.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("get %s: status %d", url, res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxDocumentSize))
+	if err != nil {
+		return nil, false, fmt.Errorf("get %s: %w", url, err)
+	}
+	return &document{
+		body:         body,
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+		expires:      now.Add(f.maxAge(res.Header)),
+	}, false, nil
+}
+
+// maxAge is how long a response may be used: its max-age, within
+// [minRefresh, maxRefresh], or maxRefresh when it has none. no-cache and
+// no-store only bring it down to minRefresh, so keys are not fetched for
+// every token.
+func (f *Fetcher) maxAge(h http.Header) time.Duration {
+	age := f.maxRefresh
+	for directive := range strings.SplitSeq(h.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return f.minRefresh
+		case "max-age":
+			if s, err := strconv.Atoi(value); err == nil {
+				age = time.Duration(s) * time.Second
+			}
+		}
+	}
+	return min(max(age, f.minRefresh), f.maxRefresh)
+}
@@ -0,0 +1,181 @@
+// Package jwks verifies tokens signed by external OpenID Connect issuers.
+// The signing keys of each allowed issuer are read from the jwks_uri of its
+// discovery document and cached as HTTP allows, revalidated with ETags, and
+// refetched early when a token names a key not seen yet, such as after a
+// rotation.
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var ErrUnknownKey = errors.New("jwks: token signed with an unknown key")
+
+var fetchesTotal = metrics.NewCounter("jwks_fetches_total",
+	"Fetches of external issuers' documents by issuer, document (discovery, jwks) and outcome (fetched, not_modified, failed).",
+	"issuer", "document", "outcome")
+
+type FetcherArgs struct {
+	// Issuer is the issuer identifier; its discovery document is read from
+	// Issuer + "/.well-known/openid-configuration".
+	Issuer string
+	// MinRefresh is the shortest time documents are cached for, and how
+	// often an unknown key may trigger a refetch.
+	MinRefresh time.Duration
+	// MaxRefresh is the longest time documents are cached for.
+	MaxRefresh time.Duration
+	// Timeout bounds each fetch.
+	Timeout time.Duration
+	Client  *http.Client
+	Clock   clock.Clock
+}
+
+// Fetcher keeps the signing keys of one issuer. When a refresh fails, the
+// keys already known keep being used until the next attempt, at most
+// MinRefresh later.
+type Fetcher struct {
+	issuer     string
+	minRefresh time.Duration
+	maxRefresh time.Duration
+	timeout    time.Duration
+	client     *http.Client
+	clock      clock.Clock
+
+	// mu is held while fetching, so concurrent lookups wait for one fetch
+	// rather than each making their own.
+	mu          sync.Mutex
+	discovery   *document
+	jwksURI     string
+	keySet      *document
+	keys        map[string]Key
+	lastAttempt time.Time
+	lastErr     error
+}
+
+func NewFetcher(args FetcherArgs) *Fetcher {
+	client := args.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Fetcher{
+		issuer:     strings.TrimSuffix(args.Issuer, "/"),
+		minRefresh: args.MinRefresh,
+		maxRefresh: max(args.MaxRefresh, args.MinRefresh),
+		timeout:    args.Timeout,
+		client:     client,
+		clock:      clock.OrReal(args.Clock),
+	}
+}
+
+// Key returns the key kid of the issuer, fetching the keys when the cached
+// ones expired or do not include kid.
+func (f *Fetcher) Key(ctx context.Context, kid string) (Key, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.clock.Now()
+	if f.keys == nil || !now.Before(f.keySet.expires) {
+		f.tryRefresh(ctx, now)
+	}
+	if k, ok := f.keys[kid]; ok {
+		return k, nil
+	}
+	if f.tryRefresh(ctx, now) {
+		if k, ok := f.keys[kid]; ok {
+			return k, nil
+		}
+	}
+	if f.keys == nil && f.lastErr != nil {
+		return Key{}, f.lastErr
+	}
+	return Key{}, ErrUnknownKey
+}
+
+// Refresh fetches the keys now, e.g. to warm the cache up at boot.
+func (f *Fetcher) Refresh(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastAttempt = f.clock.Now()
+	f.lastErr = f.refresh(ctx)
+	return f.lastErr
+}
+
+// tryRefresh refreshes the keys unless the last attempt was less than
+// MinRefresh ago, and reports whether it did.
+func (f *Fetcher) tryRefresh(ctx context.Context, now time.Time) bool {
+	if !f.lastAttempt.IsZero() && now.Sub(f.lastAttempt) < f.minRefresh {
+		return false
+	}
+	f.lastAttempt = now
+	f.lastErr = f.refresh(ctx)
+	if f.lastErr != nil {
+		logger.Sampled("jwks.refresh", 100).Warnw("refresh issuer keys", "issuer", f.issuer, "keys_cached", len(f.keys), "error", f.lastErr)
+	}
+	return f.lastErr == nil
+}
+
+func (f *Fetcher) refresh(ctx context.Context) error {
+	now := f.clock.Now()
+	if f.discovery == nil || !now.Before(f.discovery.expires) {
+		doc, notModified, err := f.get(ctx, f.issuer+"/.well-known/openid-configuration", f.discovery)
+		f.count("discovery", notModified, err)
+		if err != nil {
+			return err
+		}
+		var meta struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := json.Unmarshal(doc.body, &meta); err != nil {
+			return fmt.Errorf("parse discovery document: %w", err)
+		}
+		// OpenID Connect Discovery 1.0, section 4.3.
+		if strings.TrimSuffix(meta.Issuer, "/") != f.issuer {
+			return fmt.Errorf("discovery document names issuer %q", meta.Issuer)
+		}
+		if meta.JWKSURI == "" || (strings.HasPrefix(f.issuer, "https://") && !strings.HasPrefix(meta.JWKSURI, "https://")) {
+			return fmt.Errorf("discovery document has no usable jwks_uri: %q", meta.JWKSURI)
+		}
+		f.discovery = doc
+		if meta.JWKSURI != f.jwksURI {
+			f.jwksURI, f.keySet = meta.JWKSURI, nil
+		}
+	}
+
+	doc, notModified, err := f.get(ctx, f.jwksURI, f.keySet)
+	f.count("jwks", notModified, err)
+	if err != nil {
+		return err
+	}
+	if !notModified || f.keys == nil {
+		keys, err := parseKeySet(doc.body)
+		if err != nil {
+			return err
+		}
+		f.keys = keys
+	}
+	f.keySet = doc
+	return nil
+}
+
+func (f *Fetcher) count(document string, notModified bool, err error) {
+	outcome := "fetched"
+	switch {
+	case err != nil:
+		outcome = "failed"
+	case notModified:
+		outcome = "not_modified"
+	}
+	fetchesTotal.Inc(f.issuer, document, outcome)
+}
@@ -0,0 +1,194 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	jwtV5 "github.com/golang-jwt/jwt/v5"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+var (
+	ErrUnknownIssuer = errors.New("jwks: token issuer is not allowed")
+	ErrInvalidToken  = errors.New("jwks: invalid or expired token")
+)
+
+// ALGORITHMS are the signature algorithms accepted; HMAC and "none" never
+// are, as the keys are public.
+var ALGORITHMS = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type VerifierArgs struct {
+	// Issuers is the allowlist of issuer identifiers, e.g.
+	// "https://accounts.example.com".
+	Issuers []string
+	// Audience, when set, must be one of a token's audiences.
+	Audience string
+	// Leeway allows for clock skew with the issuers.
+	Leeway     time.Duration
+	MinRefresh time.Duration
+	MaxRefresh time.Duration
+	Timeout    time.Duration
+	Client     *http.Client
+	Clock      clock.Clock
+}
+
+// Verifier verifies the tokens of the allowed issuers.
+type Verifier struct {
+	fetchers map[string]*Fetcher
+	audience string
+	leeway   time.Duration
+	clock    clock.Clock
+}
+
+func NewVerifier(args VerifierArgs) *Verifier {
+	v := &Verifier{
+		fetchers: make(map[string]*Fetcher, len(args.Issuers)),
+		audience: args.Audience,
+		leeway:   args.Leeway,
+		clock:    clock.OrReal(args.Clock),
+	}
+	for _, issuer := range args.Issuers {
+		issuer = strings.TrimSuffix(issuer, "/")
+		v.fetchers[issuer] = NewFetcher(FetcherArgs{
+			Issuer:     issuer,
+			MinRefresh: args.MinRefresh,
+			MaxRefresh: args.MaxRefresh,
+			Timeout:    args.Timeout,
+			Client:     args.Client,
+			Clock:      args.Clock,
+		})
+	}
+	return v
+}
+
+// Claims are the claims read from an external token.
+type Claims struct {
+	jwtV5.RegisteredClaims
+	Scope    string    `json:"scope,omitempty"`
+	Scp      scopeList `json:"scp,omitempty"`
+	ClientID string    `json:"client_id,omitempty"`
+	// AuthorizedParty is the client the token was issued to, per OpenID
+	// Connect.
+	AuthorizedParty string `json:"azp,omitempty"`
+	// Email, EmailVerified and PreferredUsername are the OpenID Connect
+	// standard claims, which some issuers put in access tokens too.
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+// Scopes returns the "scope" claim, or the "scp" claim some issuers use
+// instead.
+func (c *Claims) Scopes() []string {
+	if c.Scope != "" {
+		return strings.Fields(c.Scope)
+	}
+	return c.Scp
+}
+
+// scopeList reads "scp" as either an array or a space-separated string.
+type scopeList []string
+
+func (s *scopeList) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*s = list
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = strings.Fields(str)
+	return nil
+}
+
+// Accepts reports whether token claims to come from an allowed issuer. It
+// does not verify anything.
+func (v *Verifier) Accepts(token string) bool {
+	_, ok := v.fetchers[unverifiedIssuer(token)]
+	return ok
+}
+
+// Verify checks token's signature against its issuer's keys, and its
+// expiry, issuer and audience.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	fetcher, ok := v.fetchers[unverifiedIssuer(token)]
+	if !ok {
+		return nil, ErrUnknownIssuer
+	}
+
+	opts := []jwtV5.ParserOption{
+		jwtV5.WithValidMethods(ALGORITHMS),
+		jwtV5.WithExpirationRequired(),
+		jwtV5.WithIssuer(fetcher.issuer),
+		jwtV5.WithLeeway(v.leeway),
+		jwtV5.WithTimeFunc(v.clock.Now),
+	}
+	if v.audience != "" {
+		opts = append(opts, jwtV5.WithAudience(v.audience))
+	}
+	claims := new(Claims)
+	parsed, err := jwtV5.ParseWithClaims(token, claims, func(t *jwtV5.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := fetcher.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.Alg != "" && key.Alg != t.Method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		switch key.Public.(type) {
+		case *rsa.PublicKey:
+			if !strings.HasPrefix(t.Method.Alg(), "RS") && !strings.HasPrefix(t.Method.Alg(), "PS") {
+				return nil, ErrInvalidToken
+			}
+		case *ecdsa.PublicKey:
+			if !strings.HasPrefix(t.Method.Alg(), "ES") {
+				return nil, ErrInvalidToken
+			}
+		}
+		return key.Public, nil
+	}, opts...)
+	if err != nil || !parsed.Valid {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Refresh fetches the keys of every issuer, e.g. to warm the caches up.
+func (v *Verifier) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, f := range v.fetchers {
+		errs = append(errs, f.Refresh(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Issuers returns the allowed issuers.
+func (v *Verifier) Issuers() []string {
+	issuers := make([]string, 0, len(v.fetchers))
+	for issuer := range v.fetchers {
+		issuers = append(issuers, issuer)
+	}
+	slices.Sort(issuers)
+	return issuers
+}
+
+func unverifiedIssuer(token string) string {
+	claims := new(jwtV5.RegisteredClaims)
+	if _, _, err := jwtV5.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(claims.Issuer, "/")
+}
@@ -0,0 +1,262 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwtV5 "github.com/golang-jwt/jwt/v5"
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+const testAudience = "api://go-app"
+
+// testIssuer serves a discovery document and the key set it is given,
+// counting the key set fetches.
+type testIssuer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches int
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	iss := &testIssuer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": iss.URL, "jwks_uri": iss.URL + "/jwks"})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		iss.fetches++
+		json.NewEncoder(w).Encode(map[string]any{"keys": iss.keys})
+	})
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+func (iss *testIssuer) publish(keys ...map[string]string) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.keys = keys
+}
+
+func (iss *testIssuer) keySetFetches() int {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	return iss.fetches
+}
+
+func rsaJWK(t *testing.T, kid, alg string) (*rsa.PrivateKey, map[string]string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key, map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"alg": alg,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string) (*ecdsa.PrivateKey, map[string]string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("encode key: %v", err)
+	}
+	return key, map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+	}
+}
+
+func sign(t *testing.T, method jwtV5.SigningMethod, kid string, key any, claims jwtV5.MapClaims) string {
+	t.Helper()
+	token := jwtV5.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signed
+}
+
+func newTestVerifier(iss *testIssuer, clk clock.Clock) *Verifier {
+	return NewVerifier(VerifierArgs{
+		Issuers:    []string{iss.URL},
+		Audience:   testAudience,
+		Leeway:     30 * time.Second,
+		MinRefresh: time.Minute,
+		MaxRefresh: time.Hour,
+		Timeout:    time.Second,
+		Clock:      clk,
+	})
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	iss := newTestIssuer(t)
+	old, oldJWK := rsaJWK(t, "old", "RS256")
+	next, nextJWK := rsaJWK(t, "next", "RS256")
+	iss.publish(oldJWK)
+	v := newTestVerifier(iss, clk)
+	claims := jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "exp": clk.Now().Add(time.Hour).Unix()}
+	oldToken := sign(t, jwtV5.SigningMethodRS256, "old", old, claims)
+	nextToken := sign(t, jwtV5.SigningMethodRS256, "next", next, claims)
+
+	if _, err := v.Verify(ctx, oldToken); err != nil {
+		t.Fatalf("verify with the published key: %v", err)
+	}
+	iss.publish(nextJWK)
+
+	// An unknown kid refetches the keys, but at most once per MinRefresh.
+	if _, err := v.Verify(ctx, nextToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("right after a fetch: err = %v, want ErrUnknownKey", err)
+	}
+	if got := iss.keySetFetches(); got != 1 {
+		t.Errorf("key set fetched %d times within MinRefresh, want 1", got)
+	}
+	clk.Advance(time.Minute)
+	if _, err := v.Verify(ctx, nextToken); err != nil {
+		t.Errorf("verify with the rotated key: %v", err)
+	}
+	if got := iss.keySetFetches(); got != 2 {
+		t.Errorf("key set fetched %d times, want 2", got)
+	}
+	if _, err := v.Verify(ctx, oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("verify with the retired key: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestVerifyAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	iss := newTestIssuer(t)
+	rsaKey, rsaPub := rsaJWK(t, "rsa", "RS256")
+	ecKey, ecPub := ecJWK(t, "ec")
+	iss.publish(rsaPub, ecPub)
+	v := newTestVerifier(iss, clk)
+	claims := jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "exp": clk.Now().Add(time.Hour).Unix()}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "RS256 with its key", token: sign(t, jwtV5.SigningMethodRS256, "rsa", rsaKey, claims)},
+		{name: "ES256 with its key", token: sign(t, jwtV5.SigningMethodES256, "ec", ecKey, claims)},
+		{
+			name:  "algorithm other than the key's",
+			token: sign(t, jwtV5.SigningMethodPS256, "rsa", rsaKey, claims),
+			want:  ErrInvalidToken,
+		},
+		{
+			name:  "RSA signature naming an EC key",
+			token: sign(t, jwtV5.SigningMethodRS256, "ec", rsaKey, claims),
+			want:  ErrInvalidToken,
+		},
+		{
+			// The public key is public, so it must never be used as an
+			// HMAC secret.
+			name:  "HS256",
+			token: sign(t, jwtV5.SigningMethodHS256, "rsa", []byte(rsaPub["n"]), claims),
+			want:  ErrInvalidToken,
+		},
+		{
+			name:  "none",
+			token: sign(t, jwtV5.SigningMethodNone, "rsa", jwtV5.UnsafeAllowNoneSignatureType, claims),
+			want:  ErrInvalidToken,
+		},
+	} {
+		if _, err := v.Verify(ctx, tc.token); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	iss := newTestIssuer(t)
+	other := newTestIssuer(t)
+	key, pub := rsaJWK(t, "k", "RS256")
+	iss.publish(pub)
+	other.publish(pub)
+	v := newTestVerifier(iss, clk)
+	now := clk.Now()
+
+	for _, tc := range []struct {
+		name   string
+		claims jwtV5.MapClaims
+		want   error
+	}{
+		{name: "valid", claims: jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "exp": now.Add(time.Minute).Unix()}},
+		{
+			name:   "one of several audiences",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": []string{"other", testAudience}, "exp": now.Add(time.Minute).Unix()},
+		},
+		{
+			name:   "issuer not allowed",
+			claims: jwtV5.MapClaims{"iss": other.URL, "aud": testAudience, "exp": now.Add(time.Minute).Unix()},
+			want:   ErrUnknownIssuer,
+		},
+		{
+			name:   "other audience",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": "api://other", "exp": now.Add(time.Minute).Unix()},
+			want:   ErrInvalidToken,
+		},
+		{
+			name:   "no audience",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "exp": now.Add(time.Minute).Unix()},
+			want:   ErrInvalidToken,
+		},
+		{
+			name:   "expired within the leeway",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "exp": now.Add(-20 * time.Second).Unix()},
+		},
+		{
+			name:   "expired",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "exp": now.Add(-time.Minute).Unix()},
+			want:   ErrInvalidToken,
+		},
+		{
+			name:   "no expiry",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience},
+			want:   ErrInvalidToken,
+		},
+		{
+			name:   "not yet valid",
+			claims: jwtV5.MapClaims{"iss": iss.URL, "aud": testAudience, "nbf": now.Add(time.Minute).Unix(), "exp": now.Add(time.Hour).Unix()},
+			want:   ErrInvalidToken,
+		},
+	} {
+		if _, err := v.Verify(ctx, sign(t, jwtV5.SigningMethodRS256, "k", key, tc.claims)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
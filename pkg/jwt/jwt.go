@@ -5,21 +5,53 @@ import (
 	"time"
 
 	jwtV5 "github.com/golang-jwt/jwt/v5"
+	"github.com/haidang666/go-app/pkg/clock"
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrExpiredToken   = errors.New("token has expired")
+	ErrWrongTokenType = errors.New("unexpected token type")
 )
 
+type ClientArgs struct {
+	SecretKey       string
+	AccessDuration  time.Duration
+	RefreshDuration time.Duration
+	Issuer          string
+	Audience        string
+	// Leeway tolerates clock skew between issuer and verifier.
+	Leeway time.Duration
+	// Clock stamps and checks token times; nil means the wall clock.
+	Clock clock.Clock
+}
+
 type Client struct {
-	secretKey     string
-	tokenDuration time.Duration
+	secretKey       string
+	tokenDuration   time.Duration
+	refreshDuration time.Duration
+	issuer          string
+	audience        string
+	leeway          time.Duration
+	clock           clock.Clock
 }
 
 func NewJWTClient(secretKey string, tokenDuration time.Duration) *Client {
+	return NewClient(ClientArgs{
+		SecretKey:      secretKey,
+		AccessDuration: tokenDuration,
+	})
+}
+
+func NewClient(args ClientArgs) *Client {
 	return &Client{
-		secretKey,
-		tokenDuration,
+		secretKey:       args.SecretKey,
+		tokenDuration:   args.AccessDuration,
+		refreshDuration: args.RefreshDuration,
+		issuer:          args.Issuer,
+		audience:        args.Audience,
+		leeway:          args.Leeway,
+		clock:           clock.OrReal(args.Clock),
 	}
 }
 
@@ -32,15 +64,69 @@ func (c *Client) Generate(claims jwtV5.Claims) (string, error) {
 	return signedToken, nil
 }
 
-func (c *Client) Verify(tokenStr string, claims jwtV5.Claims) error {
+// Issue builds claims of the given type for subject and signs them.
+func (c *Client) Issue(tokenType TokenType, subject Subject) (string, *AppClaims, error) {
+	claims := c.NewClaims(tokenType, subject)
+	token, err := c.Generate(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Verify parses tokenStr as AppClaims, checking signature, expiry, issuer
+// and audience.
+func (c *Client) Verify(tokenStr string) (*AppClaims, error) {
+	claims := new(AppClaims)
+	if err := c.VerifyInto(tokenStr, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyType is Verify that also requires a specific token type, so refresh
+// tokens cannot be used as access tokens and vice versa.
+func (c *Client) VerifyType(tokenStr string, tokenType TokenType) (*AppClaims, error) {
+	claims, err := c.Verify(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// VerifyInto parses tokenStr into caller-provided claims.
+func (c *Client) VerifyInto(tokenStr string, claims jwtV5.Claims) error {
 	token, err := jwtV5.ParseWithClaims(tokenStr, claims, func(t *jwtV5.Token) (any, error) {
 		if _, ok := t.Method.(*jwtV5.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
 		return []byte(c.secretKey), nil
-	})
+	}, c.parserOptions()...)
+	if errors.Is(err, jwtV5.ErrTokenExpired) {
+		return ErrExpiredToken
+	}
 	if err != nil || !token.Valid {
 		return ErrInvalidToken
 	}
 	return nil
 }
+
+func (c *Client) parserOptions() []jwtV5.ParserOption {
+	opts := []jwtV5.ParserOption{
+		jwtV5.WithValidMethods([]string{jwtV5.SigningMethodHS256.Alg()}),
+		jwtV5.WithExpirationRequired(),
+		jwtV5.WithIssuedAt(),
+		jwtV5.WithLeeway(c.leeway),
+		jwtV5.WithTimeFunc(c.clock.Now),
+	}
+	if c.issuer != "" {
+		opts = append(opts, jwtV5.WithIssuer(c.issuer))
+	}
+	if c.audience != "" {
+		opts = append(opts, jwtV5.WithAudience(c.audience))
+	}
+	return opts
+}
@@ -0,0 +1,80 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/clock"
+)
+
+func TestVerifyFollowsTheClock(t *testing.T) {
+	// Token times are whole seconds.
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		leeway  time.Duration
+		elapsed time.Duration
+		want    error
+	}{
+		{"fresh", 0, 0, nil},
+		{"last second", 0, 15*time.Minute - time.Second, nil},
+		{"expired", 0, 15 * time.Minute, ErrExpiredToken},
+		{"within leeway", time.Minute, 15*time.Minute + 30*time.Second, nil},
+		{"past leeway", time.Minute, 16 * time.Minute, ErrExpiredToken},
+		{"issued in the future", 0, -time.Minute, ErrInvalidToken},
+		{"future within leeway", time.Minute, -30 * time.Second, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clk := clock.NewFake(start)
+			c := NewClient(ClientArgs{
+				SecretKey:      "secret",
+				AccessDuration: 15 * time.Minute,
+				Leeway:         tc.leeway,
+				Clock:          clk,
+			})
+			token, claims, err := c.Issue(TOKEN_TYPE_ACCESS, Subject{UserID: "user"})
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			if !claims.ExpiresAt.Equal(start.Add(15 * time.Minute)) {
+				t.Errorf("expires at %v, want %v", claims.ExpiresAt, start.Add(15*time.Minute))
+			}
+
+			clk.Advance(tc.elapsed)
+			if _, err := c.VerifyType(token, TOKEN_TYPE_ACCESS); !errors.Is(err, tc.want) {
+				t.Errorf("verify after %v: err = %v, want %v", tc.elapsed, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRefreshTokensOutliveAccessTokens(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	c := NewClient(ClientArgs{
+		SecretKey:       "secret",
+		AccessDuration:  15 * time.Minute,
+		RefreshDuration: 24 * time.Hour,
+		Clock:           clk,
+	})
+	access, _, err := c.Issue(TOKEN_TYPE_ACCESS, Subject{UserID: "user"})
+	if err != nil {
+		t.Fatalf("issue access: %v", err)
+	}
+	refresh, _, err := c.Issue(TOKEN_TYPE_REFRESH, Subject{UserID: "user"})
+	if err != nil {
+		t.Fatalf("issue refresh: %v", err)
+	}
+
+	clk.Advance(time.Hour)
+	if _, err := c.VerifyType(access, TOKEN_TYPE_ACCESS); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("access token after an hour: err = %v, want %v", err, ErrExpiredToken)
+	}
+	if _, err := c.VerifyType(refresh, TOKEN_TYPE_REFRESH); err != nil {
+		t.Errorf("refresh token after an hour: %v", err)
+	}
+	clk.Advance(23 * time.Hour)
+	if _, err := c.VerifyType(refresh, TOKEN_TYPE_REFRESH); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("refresh token after a day: err = %v, want %v", err, ErrExpiredToken)
+	}
+}
@@ -0,0 +1,142 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/lock"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var isLeaderGauge = metrics.NewGauge("leader_is_leader",
+	"1 when this replica holds the leadership for the election key.", "key")
+
+// Task is a singleton background job. It must return when ctx is canceled,
+// which happens as soon as leadership is lost.
+type Task func(ctx context.Context)
+
+type ElectorArgs struct {
+	Locker *lock.Locker
+	Key    string
+	// RetryInterval is how often followers try to take over.
+	RetryInterval time.Duration
+}
+
+// Elector campaigns for leadership and runs the registered tasks only while
+// this replica is leader. When the lease is lost the tasks are canceled and
+// the elector goes back to campaigning, so another replica can fail over.
+type Elector struct {
+	locker        *lock.Locker
+	key           string
+	retryInterval time.Duration
+
+	mu      sync.Mutex
+	tasks   map[string]Task
+	leading atomic.Bool
+	since   atomic.Pointer[time.Time]
+}
+
+func NewElector(args ElectorArgs) *Elector {
+	if args.RetryInterval <= 0 {
+		args.RetryInterval = 5 * time.Second
+	}
+	return &Elector{
+		locker:        args.Locker,
+		key:           args.Key,
+		retryInterval: args.RetryInterval,
+		tasks:         make(map[string]Task),
+	}
+}
+
+// Register adds a task started whenever this replica becomes leader. Tasks
+// registered after Run starts are picked up on the next election.
+func (e *Elector) Register(name string, task Task) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks[name] = task
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+type Status struct {
+	Key      string     `json:"key"`
+	Owner    string     `json:"owner"`
+	IsLeader bool       `json:"is_leader"`
+	Since    *time.Time `json:"since,omitempty"`
+	Tasks    []string   `json:"tasks"`
+}
+
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	names := make([]string, 0, len(e.tasks))
+	for name := range e.tasks {
+		names = append(names, name)
+	}
+	e.mu.Unlock()
+
+	s := Status{Key: e.key, Owner: e.locker.Owner(), IsLeader: e.IsLeader(), Tasks: names}
+	if s.IsLeader {
+		s.Since = e.since.Load()
+	}
+	return s
+}
+
+// Run campaigns until ctx is canceled.
+func (e *Elector) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		lease, err := e.locker.TryAcquire(ctx, e.key)
+		if err != nil {
+			if !errors.Is(err, lock.ErrNotAcquired) {
+				logger.L().Warnw("leader election failed", "key", e.key, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.retryInterval):
+			}
+			continue
+		}
+
+		e.lead(ctx, lease)
+	}
+}
+
+func (e *Elector) lead(ctx context.Context, lease *lock.Lease) {
+	now := time.Now().UTC()
+	e.since.Store(&now)
+	e.leading.Store(true)
+	isLeaderGauge.Set(1, e.key)
+	logger.L().Infow("acquired leadership", "key", e.key, "token", lease.Token())
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	e.mu.Lock()
+	for name, task := range e.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.L().Infow("starting leader task", "task", name)
+			task(taskCtx)
+		}()
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-lease.Lost():
+		logger.L().Warnw("lost leadership", "key", e.key)
+	}
+
+	cancel()
+	wg.Wait()
+	e.leading.Store(false)
+	isLeaderGauge.Set(0, e.key)
+	lease.Release(context.WithoutCancel(ctx))
+}
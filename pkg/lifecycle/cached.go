@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"time"
+)
+
+// CheckPolicy makes a check cached: it is probed in the background every
+// Interval rather than on every Report, so frequent readiness scrapes do not
+// load the dependency. The reported state only flips after
+// FailureThreshold failing probes in a row, or SuccessThreshold passing
+// ones, so a brief blip does not flap readiness.
+type CheckPolicy struct {
+	Interval         time.Duration
+	FailureThreshold int
+	SuccessThreshold int
+	// MaxAge is how old the last probe may be before the component is
+	// reported down, for probes that hang; 0 means three intervals.
+	MaxAge time.Duration
+}
+
+// cachedCheck is the hysteresis state of a check added with
+// AddCachedCheck. It is guarded by the registry's mutex.
+type cachedCheck struct {
+	policy    CheckPolicy
+	lastProbe time.Time
+	// streak counts the probes in a row disagreeing with the reported
+	// state.
+	streak int
+}
+
+// AddCachedCheck adds a component probed by check now and then every
+// policy.Interval until the instance is stopping. A zero Interval adds it
+// uncached, as AddCheck does.
+func (r *Registry) AddCachedCheck(name string, critical bool, check Check, policy CheckPolicy) {
+	if policy.Interval <= 0 {
+		r.AddCheck(name, critical, check)
+		return
+	}
+	policy.FailureThreshold = max(policy.FailureThreshold, 1)
+	policy.SuccessThreshold = max(policy.SuccessThreshold, 1)
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = 3 * policy.Interval
+	}
+
+	r.Register(name, critical)
+	state, detail := check()
+
+	r.mu.Lock()
+	r.cached[name] = &cachedCheck{policy: policy, lastProbe: time.Now()}
+	r.set(name, state, detail)
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-r.stopping:
+				return
+			}
+			state, detail := check()
+			r.mu.Lock()
+			r.probed(name, state, detail)
+			r.mu.Unlock()
+		}
+	}()
+}
+
+// probed records a probe of a cached check, changing the reported state
+// once enough probes in a row disagree with it.
+func (r *Registry) probed(name string, state ComponentState, detail string) {
+	c, cc := r.components[name], r.cached[name]
+	cc.lastProbe = time.Now()
+
+	if state == c.State {
+		cc.streak = 0
+		c.Detail = detail
+		return
+	}
+	cc.streak++
+	threshold := cc.policy.FailureThreshold
+	if state == COMPONENT_UP {
+		threshold = cc.policy.SuccessThreshold
+	}
+	if cc.streak >= threshold {
+		cc.streak = 0
+		r.set(name, state, detail)
+	}
+}
+
+// stale reports a cached component down while its probes are overdue.
+func (r *Registry) stale(c *Component) Component {
+	cc, ok := r.cached[c.Name]
+	if !ok {
+		return *c
+	}
+	if age := time.Since(cc.lastProbe); age > cc.policy.MaxAge {
+		stale := *c
+		stale.State = COMPONENT_DOWN
+		stale.Detail = "no probe for " + age.Round(time.Millisecond).String()
+		return stale
+	}
+	return *c
+}
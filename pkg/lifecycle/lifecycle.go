@@ -0,0 +1,171 @@
+// Package lifecycle tracks the status of the running instance and the
+// health of its components. Components report their state from their own
+// start and stop hooks, or are probed through a Check whenever the status
+// is read or, when cached, in the background.
+package lifecycle
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is the status of the whole instance.
+type Status string
+
+const (
+	STATUS_STARTING Status = "starting"
+	STATUS_READY    Status = "ready"
+	// STATUS_DEGRADED means the instance is running but a critical component
+	// is not up.
+	STATUS_DEGRADED Status = "degraded"
+	STATUS_STOPPING Status = "stopping"
+)
+
+type ComponentState string
+
+const (
+	COMPONENT_STARTING ComponentState = "starting"
+	COMPONENT_UP       ComponentState = "up"
+	COMPONENT_DOWN     ComponentState = "down"
+	COMPONENT_STOPPED  ComponentState = "stopped"
+)
+
+// Component is the health of one part of the instance.
+type Component struct {
+	Name  string         `json:"name"`
+	State ComponentState `json:"state"`
+	// Critical components degrade the instance while they are not up.
+	Critical bool   `json:"critical"`
+	Detail   string `json:"detail,omitempty"`
+	// Since is when the component entered State.
+	Since time.Time `json:"since"`
+}
+
+// Check probes a component, returning its state and an optional detail.
+type Check func() (ComponentState, string)
+
+// Report is a snapshot of the instance status and its components, sorted by
+// name.
+type Report struct {
+	Status     Status      `json:"status"`
+	StartedAt  time.Time   `json:"started_at"`
+	Components []Component `json:"components"`
+}
+
+// Registry holds the instance status and component health. The zero value
+// is not usable; use NewRegistry.
+type Registry struct {
+	mu sync.Mutex
+	// phase is STATUS_STARTING, STATUS_READY or STATUS_STOPPING;
+	// STATUS_DEGRADED is derived from the components.
+	phase      Status
+	startedAt  time.Time
+	components map[string]*Component
+	checks     map[string]Check
+	cached     map[string]*cachedCheck
+	// stopping is closed by Stopping to end the cached checks' probing.
+	stopping chan struct{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		phase:      STATUS_STARTING,
+		startedAt:  time.Now().UTC(),
+		components: make(map[string]*Component),
+		checks:     make(map[string]Check),
+		cached:     make(map[string]*cachedCheck),
+		stopping:   make(chan struct{}),
+	}
+}
+
+// Register adds a component in the starting state for its hooks to update
+// with Set.
+func (r *Registry) Register(name string, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.components[name] = &Component{Name: name, State: COMPONENT_STARTING, Critical: critical, Since: time.Now().UTC()}
+}
+
+// AddCheck adds a component whose state is probed by check now and every
+// time the status is read.
+func (r *Registry) AddCheck(name string, critical bool, check Check) {
+	r.Register(name, critical)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+	state, detail := check()
+	r.set(name, state, detail)
+}
+
+// Set records the state of a registered component. Unknown names are
+// registered as non-critical.
+func (r *Registry) Set(name string, state ComponentState, detail string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(name, state, detail)
+}
+
+func (r *Registry) set(name string, state ComponentState, detail string) {
+	c, ok := r.components[name]
+	if !ok {
+		c = &Component{Name: name}
+		r.components[name] = c
+	}
+	if c.State != state {
+		c.State = state
+		c.Since = time.Now().UTC()
+	}
+	c.Detail = detail
+}
+
+// Ready ends startup. It does nothing once the instance is stopping.
+func (r *Registry) Ready() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.phase == STATUS_STARTING {
+		r.phase = STATUS_READY
+	}
+}
+
+// Stopping marks the instance as shutting down; it stays stopping.
+func (r *Registry) Stopping() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.phase != STATUS_STOPPING {
+		close(r.stopping)
+	}
+	r.phase = STATUS_STOPPING
+}
+
+func (r *Registry) Status() Status {
+	return r.Report().Status
+}
+
+// Report runs the uncached checks and returns the current status.
+func (r *Registry) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, check := range r.checks {
+		state, detail := check()
+		r.set(name, state, detail)
+	}
+
+	rep := Report{Status: r.phase, StartedAt: r.startedAt, Components: make([]Component, 0, len(r.components))}
+	for _, c := range r.components {
+		c := r.stale(c)
+		rep.Components = append(rep.Components, c)
+		if rep.Status == STATUS_READY && c.Critical && c.State != COMPONENT_UP {
+			rep.Status = STATUS_DEGRADED
+		}
+	}
+	slices.SortFunc(rep.Components, func(a, b Component) int { return strings.Compare(a.Name, b.Name) })
+	return rep
+}
@@ -0,0 +1,138 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SD_LISTEN_FDS_START is the first file descriptor passed by systemd socket
+// activation.
+const SD_LISTEN_FDS_START = 3
+
+var ErrNoActivatedSocket = errors.New("listener: no matching socket passed by systemd")
+
+// Listen opens addr, which is one of:
+//
+//	unix:<path>      a Unix socket created with mode, replacing a stale one
+//	systemd          the next socket passed by systemd socket activation
+//	systemd:<name>   the activated socket with FileDescriptorName=<name>
+//	<host:port>      a TCP address
+//
+// A socket for addr inherited from the process that started this one with
+// Restart is used instead of opening a new one.
+func Listen(addr string, mode os.FileMode) (net.Listener, error) {
+	ln, err := inheritedOrOpen(addr, mode)
+	if err != nil {
+		return nil, err
+	}
+	track(addr, ln)
+	return ln, nil
+}
+
+func inheritedOrOpen(addr string, mode os.FileMode) (net.Listener, error) {
+	if ln, err := inherited(addr); ln != nil || err != nil {
+		return ln, err
+	}
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return listenUnix(path, mode)
+	}
+	if addr == "systemd" {
+		return activated("")
+	}
+	if name, ok := strings.CutPrefix(addr, "systemd:"); ok {
+		return activated(name)
+	}
+	return net.Listen("tcp", addr)
+}
+
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+	return ln, nil
+}
+
+type activatedSocket struct {
+	name    string
+	ln      net.Listener
+	claimed bool
+}
+
+var (
+	activateOnce sync.Once
+	activateErr  error
+
+	activatedMu sync.Mutex
+	sockets     []*activatedSocket
+)
+
+// activated hands out the sockets systemd passed to this process, each at
+// most once.
+func activated(name string) (net.Listener, error) {
+	activateOnce.Do(func() { sockets, activateErr = loadActivated() })
+	if activateErr != nil {
+		return nil, activateErr
+	}
+
+	activatedMu.Lock()
+	defer activatedMu.Unlock()
+	for _, s := range sockets {
+		if !s.claimed && (name == "" || s.name == name) {
+			s.claimed = true
+			return s.ln, nil
+		}
+	}
+	if name == "" {
+		return nil, ErrNoActivatedSocket
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNoActivatedSocket, name)
+}
+
+// loadActivated reads the sd_listen_fds(3) protocol from the environment
+// and unsets it so child processes do not inherit it.
+func loadActivated() ([]*activatedSocket, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	out := make([]*activatedSocket, 0, n)
+	for i := range n {
+		fd := SD_LISTEN_FDS_START + i
+		f := os.NewFile(uintptr(fd), fmt.Sprintf("LISTEN_FD_%d", fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("activated socket %d: %w", fd, err)
+		}
+		s := &activatedSocket{ln: ln}
+		if i < len(names) {
+			s.name = names[i]
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,201 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// INHERITED_ENV lists, comma separated, the addresses of the sockets passed
+// by Restart, in file descriptor order from SD_LISTEN_FDS_START.
+const INHERITED_ENV = "INHERITED_LISTENERS"
+
+// READY_FD_ENV is the file descriptor, right after the sockets, of the pipe
+// a process started by Restart reports its readiness on; see Ready.
+const READY_FD_ENV = "RESTART_READY_FD"
+
+type trackedListener struct {
+	addr string
+	ln   net.Listener
+}
+
+var (
+	trackedMu sync.Mutex
+	tracked   []trackedListener
+
+	inheritOnce sync.Once
+	inheritErr  error
+
+	inheritedMu sync.Mutex
+	inheritedLn map[string]net.Listener
+)
+
+func track(addr string, ln net.Listener) {
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	tracked = append(tracked, trackedListener{addr: addr, ln: ln})
+}
+
+// inherited returns the socket for addr passed by Restart, or nil.
+func inherited(addr string) (net.Listener, error) {
+	inheritOnce.Do(func() { inheritedLn, inheritErr = loadInherited() })
+	if inheritErr != nil {
+		return nil, inheritErr
+	}
+
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+	ln := inheritedLn[addr]
+	delete(inheritedLn, addr)
+	return ln, nil
+}
+
+func loadInherited() (map[string]net.Listener, error) {
+	raw := os.Getenv(INHERITED_ENV)
+	os.Unsetenv(INHERITED_ENV)
+	if raw == "" {
+		return nil, nil
+	}
+
+	out := make(map[string]net.Listener)
+	for i, addr := range strings.Split(raw, ",") {
+		fd := SD_LISTEN_FDS_START + i
+		f := os.NewFile(uintptr(fd), addr)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherited socket %d for %q: %w", fd, addr, err)
+		}
+		out[addr] = ln
+	}
+	return out, nil
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Restart starts a new instance of the running binary, with the same
+// arguments and environment, that inherits every socket opened by Listen,
+// and waits for it to call Ready. Both processes accept on the sockets
+// until this one stops, so no connection is refused while it drains.
+//
+// The new process gets the write end of a pipe as well, whose descriptor is
+// in READY_FD_ENV. Ready writes a byte to it; the process exiting closes it
+// unwritten. Restart returns once the byte arrives, or an error when the
+// pipe closes without it or ctx ends first, in which case the new process
+// is killed and this one keeps its sockets as they were.
+func Restart(ctx context.Context) (*os.Process, error) {
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate executable: %w", err)
+	}
+
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	addrs := make([]string, 0, len(tracked))
+	defer func() {
+		for _, f := range files[3:] {
+			f.Close()
+		}
+	}()
+	for _, t := range tracked {
+		fl, ok := t.ln.(filer)
+		if !ok {
+			return nil, fmt.Errorf("listener for %q cannot be passed on", t.addr)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return nil, fmt.Errorf("dup listener for %q: %w", t.addr, err)
+		}
+		files = append(files, f)
+		addrs = append(addrs, t.addr)
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create readiness pipe: %w", err)
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, INHERITED_ENV+"=") && !strings.HasPrefix(kv, READY_FD_ENV+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		INHERITED_ENV+"="+strings.Join(addrs, ","),
+		READY_FD_ENV+"="+strconv.Itoa(len(files)-1))
+
+	p, err := os.StartProcess(exe, os.Args, &os.ProcAttr{Env: env, Files: files})
+	if err != nil {
+		return nil, fmt.Errorf("start new process: %w", err)
+	}
+	// Only the new process may hold the write end, so that its exit closes
+	// the pipe.
+	readyW.Close()
+	if err := waitReady(ctx, readyR); err != nil {
+		p.Kill()
+		p.Wait()
+		return nil, fmt.Errorf("new process %d: %w", p.Pid, err)
+	}
+	// The socket files now belong to the new process too.
+	for _, t := range tracked {
+		if ul, ok := t.ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return p, nil
+}
+
+// waitReady waits for the byte Ready writes to r.
+func waitReady(ctx context.Context, r *os.File) error {
+	done := make(chan error, 1)
+	go func() {
+		var b [1]byte
+		_, err := r.Read(b[:])
+		if errors.Is(err, io.EOF) {
+			err = errors.New("exited before it was ready")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// Unblocks the read.
+		r.Close()
+		return fmt.Errorf("not ready: %w", context.Cause(ctx))
+	}
+}
+
+// Ready tells the process that started this one with Restart that it
+// serves, upon which that one drains and exits. It does nothing in a
+// process started otherwise, or when called again.
+func Ready() error {
+	raw := os.Getenv(READY_FD_ENV)
+	os.Unsetenv(READY_FD_ENV)
+	if raw == "" {
+		return nil
+	}
+	fd, err := strconv.Atoi(raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", READY_FD_ENV, err)
+	}
+	f := os.NewFile(uintptr(fd), "restart-ready")
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		return fmt.Errorf("report readiness: %w", err)
+	}
+	return nil
+}
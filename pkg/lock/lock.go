@@ -0,0 +1,210 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	ErrNotAcquired = errors.New("lock is held by another owner")
+	ErrLeaseLost   = errors.New("lock lease was lost")
+)
+
+var (
+	acquireTotal = metrics.NewCounter("lock_acquire_total",
+		"Lock acquisition attempts by outcome (acquired, contended, error).", "key", "result")
+	heldGauge = metrics.NewGauge("lock_held",
+		"Locks currently held by this process.", "key")
+	lostTotal = metrics.NewCounter("lock_lost_total",
+		"Leases lost because renewal failed.", "key")
+)
+
+// Backend is the storage that arbitrates a lock between replicas.
+type Backend interface {
+	// TryAcquire takes key for owner if it is free and returns a fencing token
+	// that increases with every successful acquisition of the key.
+	TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (token uint64, ok bool, err error)
+	// Renew extends the lease; ok is false when owner no longer holds key.
+	Renew(ctx context.Context, key, owner string, ttl time.Duration) (ok bool, err error)
+	Release(ctx context.Context, key, owner string) error
+}
+
+type LockerArgs struct {
+	Backend Backend
+	// TTL is the lease length; leases are renewed every TTL/3 while held.
+	TTL time.Duration
+	// RetryInterval is the wait between attempts in Acquire.
+	RetryInterval time.Duration
+}
+
+// Locker hands out leases on named keys. Every Locker has its own owner ID,
+// so two Lockers in the same process contend like two replicas would.
+type Locker struct {
+	backend       Backend
+	owner         string
+	ttl           time.Duration
+	retryInterval time.Duration
+}
+
+func NewLocker(args LockerArgs) *Locker {
+	if args.TTL <= 0 {
+		args.TTL = 30 * time.Second
+	}
+	if args.RetryInterval <= 0 {
+		args.RetryInterval = time.Second
+	}
+	return &Locker{
+		backend:       args.Backend,
+		owner:         uuid.NewString(),
+		ttl:           args.TTL,
+		retryInterval: args.RetryInterval,
+	}
+}
+
+func (l *Locker) Owner() string {
+	return l.owner
+}
+
+// TryAcquire returns ErrNotAcquired immediately when key is held elsewhere.
+func (l *Locker) TryAcquire(ctx context.Context, key string) (*Lease, error) {
+	token, ok, err := l.backend.TryAcquire(ctx, key, l.owner, l.ttl)
+	if err != nil {
+		acquireTotal.Inc(key, "error")
+		return nil, fmt.Errorf("acquire lock %q: %w", key, err)
+	}
+	if !ok {
+		acquireTotal.Inc(key, "contended")
+		return nil, ErrNotAcquired
+	}
+	acquireTotal.Inc(key, "acquired")
+	heldGauge.Inc(key)
+
+	lease := &Lease{
+		locker: l,
+		key:    key,
+		token:  token,
+		lost:   make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+	go lease.renew()
+	return lease, nil
+}
+
+// Acquire blocks until key is acquired or ctx is done.
+func (l *Locker) Acquire(ctx context.Context, key string) (*Lease, error) {
+	for {
+		lease, err := l.TryAcquire(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// WithLock runs fn while holding key. The context passed to fn is canceled if
+// the lease is lost mid-way.
+func (l *Locker) WithLock(ctx context.Context, key string, fn func(ctx context.Context, token uint64) error) error {
+	lease, err := l.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer lease.Release(context.WithoutCancel(ctx))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lease.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := fn(ctx, lease.Token()); err != nil {
+		return err
+	}
+	if lease.IsLost() {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Lease is a held lock. Writers guarded by the lock should pass Token to the
+// protected resource so stale holders can be fenced off.
+type Lease struct {
+	locker *Locker
+	key    string
+	token  uint64
+
+	lost     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	lostOnce sync.Once
+}
+
+func (le *Lease) Key() string {
+	return le.key
+}
+
+func (le *Lease) Token() uint64 {
+	return le.token
+}
+
+// Lost is closed when the lease could not be renewed.
+func (le *Lease) Lost() <-chan struct{} {
+	return le.lost
+}
+
+func (le *Lease) IsLost() bool {
+	select {
+	case <-le.lost:
+		return true
+	default:
+		return false
+	}
+}
+
+func (le *Lease) Release(ctx context.Context) error {
+	var err error
+	le.stopOnce.Do(func() {
+		close(le.stop)
+		heldGauge.Dec(le.key)
+		err = le.locker.backend.Release(ctx, le.key, le.locker.owner)
+	})
+	return err
+}
+
+func (le *Lease) renew() {
+	ticker := time.NewTicker(le.locker.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-le.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), le.locker.ttl/3)
+			ok, err := le.locker.backend.Renew(ctx, le.key, le.locker.owner, le.locker.ttl)
+			cancel()
+			if err == nil && ok {
+				continue
+			}
+
+			logger.L().Warnw("lock lease lost", "key", le.key, "token", le.token, "error", err)
+			lostTotal.Inc(le.key)
+			le.lostOnce.Do(func() { close(le.lost) })
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend arbitrates locks inside a single process. It is meant for
+// local development and single-replica deployments.
+type MemoryBackend struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+	tokens map[string]uint64
+}
+
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		leases: make(map[string]memoryLease),
+		tokens: make(map[string]uint64),
+	}
+}
+
+func (b *MemoryBackend) TryAcquire(_ context.Context, key, owner string, ttl time.Duration) (uint64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if l, ok := b.leases[key]; ok && l.owner != owner && time.Now().Before(l.expiresAt) {
+		return 0, false, nil
+	}
+	b.tokens[key]++
+	b.leases[key] = memoryLease{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return b.tokens[key], true, nil
+}
+
+func (b *MemoryBackend) Renew(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.leases[key]
+	if !ok || l.owner != owner || time.Now().After(l.expiresAt) {
+		return false, nil
+	}
+	l.expiresAt = time.Now().Add(ttl)
+	b.leases[key] = l
+	return true, nil
+}
+
+func (b *MemoryBackend) Release(_ context.Context, key, owner string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if l, ok := b.leases[key]; ok && l.owner == owner {
+		delete(b.leases, key)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PostgresBackend uses session-level advisory locks. Each held key pins one
+// pooled connection, since the lock lives as long as that session. Fencing
+// tokens come from the lock_fencing_tokens table:
+//
+//	CREATE TABLE lock_fencing_tokens (key TEXT PRIMARY KEY, token BIGINT NOT NULL);
+type PostgresBackend struct {
+	db *sql.DB
+
+	mu    sync.Mutex
+	conns map[string]*sql.Conn
+}
+
+var _ Backend = (*PostgresBackend)(nil)
+
+func NewPostgresBackend(db *sql.DB) *PostgresBackend {
+	return &PostgresBackend{db: db, conns: make(map[string]*sql.Conn)}
+}
+
+func (b *PostgresBackend) TryAcquire(ctx context.Context, key, owner string, _ time.Duration) (uint64, bool, error) {
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", key).Scan(&ok); err != nil {
+		conn.Close()
+		return 0, false, err
+	}
+	if !ok {
+		conn.Close()
+		return 0, false, nil
+	}
+
+	var token uint64
+	err = conn.QueryRowContext(ctx, `
+		INSERT INTO lock_fencing_tokens (key, token) VALUES ($1, 1)
+		ON CONFLICT (key) DO UPDATE SET token = lock_fencing_tokens.token + 1
+		RETURNING token`, key).Scan(&token)
+	if err != nil {
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", key)
+		conn.Close()
+		return 0, false, fmt.Errorf("issue fencing token: %w", err)
+	}
+
+	b.mu.Lock()
+	b.conns[key+"\x00"+owner] = conn
+	b.mu.Unlock()
+	return token, true, nil
+}
+
+// Renew verifies the session holding the advisory lock is still alive; the
+// lock itself has no expiry.
+func (b *PostgresBackend) Renew(ctx context.Context, key, owner string, _ time.Duration) (bool, error) {
+	b.mu.Lock()
+	conn, ok := b.conns[key+"\x00"+owner]
+	b.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *PostgresBackend) Release(ctx context.Context, key, owner string) error {
+	b.mu.Lock()
+	conn, ok := b.conns[key+"\x00"+owner]
+	delete(b.conns, key+"\x00"+owner)
+	b.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	defer conn.Close()
+
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", key)
+	return err
+}
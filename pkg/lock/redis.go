@@ -0,0 +1,84 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisScripter is the subset of a Redis client the backend needs; adapt
+// e.g. go-redis with a one-line wrapper around Eval(...).Result().
+type RedisScripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+const (
+	acquireScript = `
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`
+	renewScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`
+	releaseScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+)
+
+// RedisBackend implements single-instance Redlock: SET NX PX with owner
+// checked on renew/release, plus a per-key INCR counter for fencing tokens.
+type RedisBackend struct {
+	client RedisScripter
+	prefix string
+}
+
+var _ Backend = (*RedisBackend)(nil)
+
+func NewRedisBackend(client RedisScripter, prefix string) *RedisBackend {
+	return &RedisBackend{client: client, prefix: prefix}
+}
+
+func (b *RedisBackend) TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (uint64, bool, error) {
+	res, err := b.client.Eval(ctx, acquireScript,
+		[]string{b.prefix + key, b.prefix + key + ":fence"}, owner, ttl.Milliseconds())
+	if err != nil {
+		return 0, false, err
+	}
+	token, err := toUint64(res)
+	if err != nil {
+		return 0, false, err
+	}
+	return token, token > 0, nil
+}
+
+func (b *RedisBackend) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	res, err := b.client.Eval(ctx, renewScript, []string{b.prefix + key}, owner, ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	n, err := toUint64(res)
+	return n == 1, err
+}
+
+func (b *RedisBackend) Release(ctx context.Context, key, owner string) error {
+	_, err := b.client.Eval(ctx, releaseScript, []string{b.prefix + key}, owner)
+	return err
+}
+
+func toUint64(v any) (uint64, error) {
+	switch n := v.(type) {
+	case int64:
+		return uint64(max(n, 0)), nil
+	case int:
+		return uint64(max(n, 0)), nil
+	case uint64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unexpected redis reply %T", v)
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// exportTarget is the core set by Export; nil while nothing exports.
+var exportTarget atomic.Pointer[zapcore.Core]
+
+// Export sends every entry to core as well, alongside the console and
+// whatever its level, so logs can be shipped to a backend configured apart
+// from the console output. Loggers derived before the call export too. A nil
+// core stops exporting.
+func Export(core zapcore.Core) {
+	initOnce.Do(initLogger)
+	if core == nil {
+		exportTarget.Store(nil)
+		return
+	}
+	exportTarget.Store(&core)
+}
+
+// exportCore forwards to the core set by Export, carrying the fields added
+// with With, since the loggers holding it were built before Export ran.
+type exportCore struct {
+	fields []zapcore.Field
+}
+
+func (c exportCore) Enabled(level zapcore.Level) bool {
+	target := exportTarget.Load()
+	return target != nil && (*target).Enabled(level)
+}
+
+func (c exportCore) With(fields []zapcore.Field) zapcore.Core {
+	return exportCore{fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c exportCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c exportCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	target := exportTarget.Load()
+	if target == nil {
+		return nil
+	}
+	return (*target).Write(e, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+}
+
+func (c exportCore) Sync() error {
+	if target := exportTarget.Load(); target != nil {
+		return (*target).Sync()
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"sync"
 
+	"github.com/haidang666/go-app/pkg/buildinfo"
+	"github.com/haidang666/go-app/pkg/podinfo"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -31,13 +33,54 @@ func initLogger() {
 	cfg.OutputPaths = []string{"stdout"}
 	cfg.ErrorOutputPaths = []string{"stderr"}
 
-	logger, loggerError = cfg.Build()
+	// Recent entries are also kept in memory, JSON encoded whatever the
+	// console format, so crash reports can include them.
+	ringEncoder := zap.NewProductionEncoderConfig()
+	ringEncoder.TimeKey = "timestamp"
+	ringEncoder.EncodeTime = zapcore.ISO8601TimeEncoder
+	ringCore := zapcore.NewCore(zapcore.NewJSONEncoder(ringEncoder), recent, cfg.Level)
+
+	logger, loggerError = cfg.Build(
+		zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, ringCore, exportCore{}) }),
+		zap.WithFatalHook(fatalHook{}),
+	)
 	if loggerError != nil {
 		panic("failed to initialize logger: " + loggerError.Error())
 	}
+	info := buildinfo.Get()
+	logger = logger.With(zap.String("version", info.Version), zap.String("git_sha", info.GitSHA))
+	// In Kubernetes, entries also name the pod and node they come from.
+	for _, a := range podinfo.Get().Attrs() {
+		logger = logger.With(zap.String(a.Key, a.Value))
+	}
 	sugar = logger.Sugar().WithOptions(zap.AddStacktrace(zap.DPanicLevel))
 }
 
+var (
+	fatalMu    sync.Mutex
+	fatalHooks []func(msg string)
+)
+
+// OnFatal registers fn to run after a Fatal entry is written and before the
+// process exits.
+func OnFatal(fn func(msg string)) {
+	fatalMu.Lock()
+	defer fatalMu.Unlock()
+	fatalHooks = append(fatalHooks, fn)
+}
+
+type fatalHook struct{}
+
+func (fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	fatalMu.Lock()
+	hooks := append([]func(string){}, fatalHooks...)
+	fatalMu.Unlock()
+	for _, fn := range hooks {
+		fn(ce.Message)
+	}
+	os.Exit(1)
+}
+
 func L() *zap.SugaredLogger {
 	initOnce.Do(initLogger)
 	return sugar
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// RECENT_ENTRIES is how many log entries Recent keeps.
+const RECENT_ENTRIES = 500
+
+// ring keeps the last encoded log entries for crash reports.
+type ring struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+}
+
+var recent = &ring{entries: make([][]byte, RECENT_ENTRIES)}
+
+// Write stores one encoded entry; zap writes each entry in a single call.
+func (r *ring) Write(p []byte) (int, error) {
+	line := bytes.Clone(p)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *ring) Sync() error { return nil }
+
+// Recent returns the last log entries as JSON lines, oldest first.
+func Recent() [][]byte {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	if !recent.full {
+		return append([][]byte(nil), recent.entries[:recent.next]...)
+	}
+	return append(append([][]byte(nil), recent.entries[recent.next:]...), recent.entries[:recent.next]...)
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/metrics"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var suppressedTotal = metrics.NewCounter("log_entries_suppressed_total",
+	"Log entries dropped by sampled loggers.", "key")
+
+type samplingConfig struct {
+	first    uint64
+	interval time.Duration
+}
+
+var sampling atomic.Pointer[samplingConfig]
+
+func init() {
+	ConfigureSampling(10, time.Minute)
+}
+
+// ConfigureSampling sets how sampled loggers behave: in every interval the
+// first entries of a key are always logged, and only one in rate after that.
+func ConfigureSampling(first int, interval time.Duration) {
+	if first < 0 {
+		first = 0
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	sampling.Store(&samplingConfig{first: uint64(first), interval: interval})
+}
+
+var samplers sync.Map // key -> *sampler
+
+// Sampled is Sample applied to the global logger.
+func Sampled(key string, rate int) *zap.SugaredLogger {
+	return Sample(L(), key, rate)
+}
+
+// Sample wraps l for a call site that can fire in storms, e.g. every request
+// failing while the database is down. Entries logged through any logger
+// sampled with the same key share one budget: per interval the first ones
+// are kept, then one in rate, and a summary of what was dropped is logged
+// when the interval ends. The rate of the first Sample call for a key wins.
+func Sample(l *zap.SugaredLogger, key string, rate int) *zap.SugaredLogger {
+	if rate < 1 {
+		rate = 1
+	}
+	v, _ := samplers.LoadOrStore(key, &sampler{key: key, rate: uint64(rate)})
+	s := v.(*sampler)
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return &sampledCore{Core: c, s: s}
+	}))
+}
+
+type sampler struct {
+	key  string
+	rate uint64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	seen        uint64
+	dropped     uint64
+	flushArmed  bool
+}
+
+// allow counts one entry and reports whether to log it and whether it was
+// kept by sampling rather than within the burst.
+func (s *sampler) allow() (keep, sampled bool) {
+	cfg := sampling.Load()
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.windowStart) >= cfg.interval {
+		s.windowStart = now
+		s.seen = 0
+	}
+	s.seen++
+	if s.seen <= cfg.first {
+		return true, false
+	}
+	if (s.seen-cfg.first)%s.rate == 0 {
+		return true, true
+	}
+	s.dropped++
+	if !s.flushArmed {
+		s.flushArmed = true
+		time.AfterFunc(s.windowStart.Add(cfg.interval).Sub(now), s.flush)
+	}
+	return false, false
+}
+
+// flush logs how many entries were dropped since the last summary.
+func (s *sampler) flush() {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.dropped = 0
+	s.flushArmed = false
+	s.mu.Unlock()
+
+	if dropped == 0 {
+		return
+	}
+	suppressedTotal.Add(float64(dropped), s.key)
+	L().Warnw("suppressed repeated log entries",
+		"sample_key", s.key, "suppressed", dropped, "interval", sampling.Load().interval)
+}
+
+type sampledCore struct {
+	zapcore.Core
+	s *sampler
+}
+
+func (c *sampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sampledCore{Core: c.Core.With(fields), s: c.s}
+}
+
+func (c *sampledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	keep, sampled := c.s.allow()
+	if !keep {
+		return ce
+	}
+	if sampled {
+		return c.Core.With([]zapcore.Field{zap.Uint64("sampled_one_in", c.s.rate)}).Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+// Label is one label name and value of a metric update.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Backend receives every metric update as it is recorded, for exporting to
+// systems that are pushed to rather than scraped. Gauges are reported with
+// their resulting value. Implementations must be safe for concurrent use
+// and must not block.
+type Backend interface {
+	Count(name string, labels []Label, delta float64)
+	Gauge(name string, labels []Label, value float64)
+	Observe(name string, labels []Label, value float64)
+	Close() error
+}
+
+// SetBackend forwards every later update to b in addition to the
+// in-process series; nil stops forwarding.
+func (reg *Registry) SetBackend(b Backend) {
+	if b == nil {
+		reg.backend.Store(nil)
+		return
+	}
+	reg.backend.Store(&backendRef{b: b})
+}
+
+// Disable turns every instrument of the registry into a no-op.
+func (reg *Registry) Disable() {
+	reg.disabled.Store(true)
+}
+
+// SetBackend sets the backend of the Default registry.
+func SetBackend(b Backend) {
+	Default.SetBackend(b)
+}
+
+// Disable disables the Default registry.
+func Disable() {
+	Default.Disable()
+}
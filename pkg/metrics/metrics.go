@@ -0,0 +1,365 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const labelSep = "\xff"
+
+// DefaultBuckets suit request latencies measured in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metric families and renders them in the Prometheus text
+// exposition format. Updates are also forwarded to the backend set with
+// SetBackend, so the same instruments can feed a push-based system.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]family
+
+	backend  atomic.Pointer[backendRef]
+	disabled atomic.Bool
+}
+
+type backendRef struct {
+	b Backend
+}
+
+type family interface {
+	write(w io.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// Default is the process-wide registry used by the package-level constructors.
+var Default = NewRegistry()
+
+func (reg *Registry) register(name string, f family) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.families[name]; ok {
+		panic("metrics: duplicate registration of " + name)
+	}
+	reg.families[name] = f
+}
+
+// Handler serves the registry contents for scraping.
+func (reg *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		reg.Write(w)
+	})
+}
+
+func (reg *Registry) Write(w io.Writer) {
+	reg.mu.RLock()
+	names := make([]string, 0, len(reg.families))
+	for name := range reg.families {
+		names = append(names, name)
+	}
+	reg.mu.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		reg.mu.RLock()
+		f := reg.families[name]
+		reg.mu.RUnlock()
+		f.write(w)
+	}
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// vec is the labelled series store shared by all metric kinds.
+type vec[T any] struct {
+	reg        *Registry
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	newSeries  func() *T
+
+	mu     sync.RWMutex
+	series map[string]*T
+}
+
+func (v *vec[T]) with(labelValues []string) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelSep)
+
+	v.mu.RLock()
+	s, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok = v.series[key]; !ok {
+		s = v.newSeries()
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec[T]) each(fn func(labels string, s *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		v.mu.RLock()
+		s := v.series[k]
+		v.mu.RUnlock()
+		fn(formatLabels(v.labelNames, strings.Split(k, labelSep)), s)
+	}
+}
+
+// forward hands the update to the registry's backend, if any.
+func (v *vec[T]) forward(labelValues []string, fn func(b Backend, labels []Label)) {
+	ref := v.reg.backend.Load()
+	if ref == nil {
+		return
+	}
+	labels := make([]Label, len(v.labelNames))
+	for i, name := range v.labelNames {
+		labels[i] = Label{Name: name, Value: labelValues[i]}
+	}
+	fn(ref.b, labels)
+}
+
+func (v *vec[T]) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind)
+}
+
+func newVec[T any](reg *Registry, name, help, kind string, labelNames []string, newSeries func() *T) *vec[T] {
+	return &vec[T]{
+		reg:        reg,
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: slices.Clone(labelNames),
+		newSeries:  newSeries,
+		series:     make(map[string]*T),
+	}
+}
+
+type value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (s *value) add(d float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.v += d
+	return s.v
+}
+
+func (s *value) set(v float64) {
+	s.mu.Lock()
+	s.v = v
+	s.mu.Unlock()
+}
+
+func (s *value) get() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.v
+}
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct {
+	*vec[value]
+}
+
+func (reg *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{newVec(reg, name, help, "counter", labelNames, func() *value { return &value{} })}
+	reg.register(name, c)
+	return c
+}
+
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return Default.NewCounter(name, help, labelNames...)
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(d float64, labelValues ...string) {
+	if d < 0 {
+		panic("metrics: counter " + c.name + " cannot decrease")
+	}
+	if c.reg.disabled.Load() {
+		return
+	}
+	c.with(labelValues).add(d)
+	c.forward(labelValues, func(b Backend, labels []Label) { b.Count(c.name, labels, d) })
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.header(w)
+	c.each(func(labels string, s *value) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatFloat(s.get()))
+	})
+}
+
+// Gauge is an arbitrary value per label combination.
+type Gauge struct {
+	*vec[value]
+}
+
+func (reg *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	g := &Gauge{newVec(reg, name, help, "gauge", labelNames, func() *value { return &value{} })}
+	reg.register(name, g)
+	return g
+}
+
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return Default.NewGauge(name, help, labelNames...)
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	if g.reg.disabled.Load() {
+		return
+	}
+	g.with(labelValues).set(v)
+	g.forward(labelValues, func(b Backend, labels []Label) { b.Gauge(g.name, labels, v) })
+}
+
+// Add changes the gauge by d; backends receive the resulting value.
+func (g *Gauge) Add(d float64, labelValues ...string) {
+	if g.reg.disabled.Load() {
+		return
+	}
+	v := g.with(labelValues).add(d)
+	g.forward(labelValues, func(b Backend, labels []Label) { b.Gauge(g.name, labels, v) })
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.header(w)
+	g.each(func(labels string, s *value) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labels, formatFloat(s.get()))
+	})
+}
+
+type histogramSeries struct {
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Histogram counts observations into cumulative buckets per label combination.
+type Histogram struct {
+	*vec[histogramSeries]
+	buckets []float64
+}
+
+func (reg *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+
+	h := &Histogram{buckets: buckets}
+	h.vec = newVec(reg, name, help, "histogram", labelNames, func() *histogramSeries {
+		return &histogramSeries{counts: make([]uint64, len(buckets))}
+	})
+	reg.register(name, h)
+	return h
+}
+
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labelNames...)
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	if h.reg.disabled.Load() {
+		return
+	}
+	s := h.with(labelValues)
+	s.mu.Lock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+	s.mu.Unlock()
+
+	h.forward(labelValues, func(b Backend, labels []Label) { b.Observe(h.name, labels, v) })
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.header(w)
+	h.each(func(labels string, s *histogramSeries) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(labels, "le", formatFloat(upper)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, s.count)
+	})
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Quote(values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func withLabel(labels, name, value string) string {
+	pair := name + "=" + strconv.Quote(value)
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var tagReplacer = strings.NewReplacer(",", "_", "|", "_", "\n", "_")
+
+type StatsDArgs struct {
+	// Addr is the host:port of the StatsD agent.
+	Addr string
+	// Prefix is prepended to every metric name, e.g. "go_app.".
+	Prefix string
+	// FlushInterval bounds how long an update waits to fill a packet.
+	FlushInterval time.Duration
+	// MaxPacketSize keeps packets within the path MTU.
+	MaxPacketSize int
+	// Tags are added to every update, e.g. the pod the instance runs in.
+	Tags []Label
+}
+
+// StatsD is a Backend that sends updates over UDP in the DogStatsD format,
+// with non-empty labels as tags. Updates are batched into packets; a packet that
+// fails to send is dropped.
+type StatsD struct {
+	conn      net.Conn
+	prefix    string
+	tags      []Label
+	maxPacket int
+
+	mu  sync.Mutex
+	buf []byte
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ Backend = (*StatsD)(nil)
+
+func NewStatsD(args StatsDArgs) (*StatsD, error) {
+	if args.FlushInterval <= 0 {
+		args.FlushInterval = time.Second
+	}
+	if args.MaxPacketSize <= 0 {
+		args.MaxPacketSize = 1432
+	}
+	conn, err := net.Dial("udp", args.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial statsd %s: %w", args.Addr, err)
+	}
+
+	s := &StatsD{
+		conn:      conn,
+		prefix:    args.Prefix,
+		tags:      args.Tags,
+		maxPacket: args.MaxPacketSize,
+		buf:       make([]byte, 0, args.MaxPacketSize),
+		done:      make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.flushLoop(args.FlushInterval)
+	return s, nil
+}
+
+func (s *StatsD) Count(name string, labels []Label, delta float64) {
+	s.write(name, delta, "c", labels)
+}
+
+func (s *StatsD) Gauge(name string, labels []Label, value float64) {
+	s.write(name, value, "g", labels)
+}
+
+func (s *StatsD) Observe(name string, labels []Label, value float64) {
+	s.write(name, value, "h", labels)
+}
+
+// Close sends the buffered updates and closes the connection.
+func (s *StatsD) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.flushLocked()
+	s.mu.Unlock()
+	return s.conn.Close()
+}
+
+func (s *StatsD) write(name string, value float64, kind string, labels []Label) {
+	var line strings.Builder
+	line.WriteString(s.prefix)
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(formatFloat(value))
+	line.WriteByte('|')
+	line.WriteString(kind)
+	sep := "|#"
+	for _, tags := range [2][]Label{labels, s.tags} {
+		for _, l := range tags {
+			if l.Value == "" {
+				continue
+			}
+			line.WriteString(sep)
+			sep = ","
+			line.WriteString(l.Name)
+			line.WriteByte(':')
+			line.WriteString(tagReplacer.Replace(l.Value))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) > 0 && len(s.buf)+1+line.Len() > s.maxPacket {
+		s.flushLocked()
+	}
+	if len(s.buf) > 0 {
+		s.buf = append(s.buf, '\n')
+	}
+	s.buf = append(s.buf, line.String()...)
+}
+
+func (s *StatsD) flushLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			s.flushLocked()
+			s.mu.Unlock()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *StatsD) flushLocked() {
+	if len(s.buf) == 0 {
+		return
+	}
+	s.conn.Write(s.buf)
+	s.buf = s.buf[:0]
+}
@@ -0,0 +1,216 @@
+package mmdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Data field types.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// maxDepth bounds the nesting of maps, arrays and pointers, so a corrupt
+// file fails instead of recursing forever.
+const maxDepth = 32
+
+var errTruncated = errors.New("data section truncated")
+
+// decoder decodes the fields of a data section; offsets, including those of
+// pointers, are relative to the start of buf.
+type decoder struct {
+	buf []byte
+}
+
+// decode returns the field at offset and the offset after it.
+func (d decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, errors.New("data nested too deep")
+	}
+	typ, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		v, _, err := d.decode(target, depth+1)
+		return v, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		for range size {
+			var k, v any
+			k, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("map key of type %T", k)
+			}
+			v, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[key] = v
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, min(size, 1024))
+		for range size {
+			var v any
+			v, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, v)
+		}
+		return a, offset, nil
+	case typeBool:
+		if size > 1 {
+			return nil, 0, fmt.Errorf("boolean of size %d", size)
+		}
+		return size == 1, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	b := d.buf[offset : offset+size]
+	next := offset + size
+	switch typ {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return append([]byte(nil), b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("double of size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("float of size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("unsigned integer of size %d", size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("int32 of size %d", size)
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int32(n), next, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("uint128 of size %d", size)
+		}
+		return new(big.Int).SetBytes(b), next, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d", typ)
+}
+
+// control reads the control byte at offset, with the extended type and size
+// bytes that may follow it, and returns the field's type, its size and the
+// offset of its payload. For pointers size holds the control byte's low
+// five bits.
+func (d decoder) control(offset uint) (typ int, size, next uint, err error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ = int(ctrl >> 5)
+	if typ == typePointer {
+		return typ, uint(ctrl & 0x1F), offset, nil
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+		if typ <= typeMap || typ > typeFloat {
+			return 0, 0, 0, fmt.Errorf("invalid extended type %d", typ)
+		}
+	}
+
+	size = uint(ctrl & 0x1F)
+	if size < 29 {
+		return typ, size, offset, nil
+	}
+	n := size - 28
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	var ext uint
+	for _, c := range d.buf[offset : offset+n] {
+		ext = ext<<8 | uint(c)
+	}
+	switch size {
+	case 29:
+		size = 29 + ext
+	case 30:
+		size = 285 + ext
+	default:
+		size = 65821 + ext
+	}
+	return typ, size, offset + n, nil
+}
+
+// pointer decodes the pointer whose control byte carried bits and whose
+// remaining bytes start at offset.
+func (d decoder) pointer(bits, offset uint) (target, next uint, err error) {
+	n := (bits>>3)&0x3 + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	var p uint
+	if n < 4 {
+		p = bits & 0x7
+	}
+	for _, c := range d.buf[offset : offset+n] {
+		p = p<<8 | uint(c)
+	}
+	switch n {
+	case 2:
+		p += 2048
+	case 3:
+		p += 526336
+	}
+	return p, offset + n, nil
+}
@@ -0,0 +1,199 @@
+// Package mmdb reads MaxMind DB files, the format of the GeoIP2 and GeoLite2
+// databases. The whole file is held in memory; a Reader is immutable and
+// safe for concurrent use, so replacing a database means opening a new
+// Reader and swapping it in.
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+)
+
+// metadataMarker precedes the metadata map at the end of the file.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the number of zero bytes between the search tree
+// and the data section.
+const dataSectionSeparator = 16
+
+var ErrInvalidDatabase = errors.New("mmdb: invalid database")
+
+// Metadata describes a database.
+type Metadata struct {
+	NodeCount    uint
+	RecordSize   uint
+	IPVersion    uint
+	DatabaseType string
+	Languages    []string
+	// BuildEpoch is when the database was built, in seconds since the Unix
+	// epoch.
+	BuildEpoch uint64
+}
+
+type Reader struct {
+	buf      []byte
+	tree     []byte
+	data     decoder
+	meta     Metadata
+	nodeSize uint
+	// ipv4Start is the node IPv4 lookups start at: in an IPv6 tree, the one
+	// reached by the 96 zero bits of an IPv4-mapped address.
+	ipv4Start uint
+}
+
+// Open reads the database at path.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes reads a database from buf, which must not be modified
+// afterwards.
+func FromBytes(buf []byte) (*Reader, error) {
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: metadata marker not found", ErrInvalidDatabase)
+	}
+	metaStart := i + len(metadataMarker)
+	raw, _, err := decoder{buf: buf[metaStart:]}.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata: %v", ErrInvalidDatabase, err)
+	}
+	meta, err := parseMetadata(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeSize := meta.RecordSize * 2 / 8
+	treeSize := meta.NodeCount * nodeSize
+	if treeSize+dataSectionSeparator > uint(i) {
+		return nil, fmt.Errorf("%w: search tree larger than the file", ErrInvalidDatabase)
+	}
+	r := &Reader{
+		buf:      buf,
+		tree:     buf[:treeSize],
+		data:     decoder{buf: buf[treeSize+dataSectionSeparator : i]},
+		meta:     meta,
+		nodeSize: nodeSize,
+	}
+	if meta.IPVersion == 6 {
+		node := uint(0)
+		for b := 0; b < 96 && node < meta.NodeCount; b++ {
+			node = r.record(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+func parseMetadata(raw any) (Metadata, error) {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return Metadata{}, fmt.Errorf("%w: metadata is not a map", ErrInvalidDatabase)
+	}
+	var meta Metadata
+	meta.NodeCount = uint(asUint(m["node_count"]))
+	meta.RecordSize = uint(asUint(m["record_size"]))
+	meta.IPVersion = uint(asUint(m["ip_version"]))
+	meta.BuildEpoch = asUint(m["build_epoch"])
+	meta.DatabaseType, _ = m["database_type"].(string)
+	if langs, ok := m["languages"].([]any); ok {
+		for _, l := range langs {
+			if s, ok := l.(string); ok {
+				meta.Languages = append(meta.Languages, s)
+			}
+		}
+	}
+
+	switch meta.RecordSize {
+	case 24, 28, 32:
+	default:
+		return Metadata{}, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, meta.RecordSize)
+	}
+	if meta.IPVersion != 4 && meta.IPVersion != 6 {
+		return Metadata{}, fmt.Errorf("%w: unsupported IP version %d", ErrInvalidDatabase, meta.IPVersion)
+	}
+	return meta, nil
+}
+
+func asUint(v any) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int32:
+		if n >= 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
+
+func (r *Reader) Metadata() Metadata {
+	return r.meta
+}
+
+// Lookup returns the record of the network addr is in; ok is false when the
+// database has none. Records are decoded into map[string]any, []any,
+// string, float64, float32, []byte, uint64, int32, bool and, for 128-bit
+// integers, *big.Int.
+func (r *Reader) Lookup(addr netip.Addr) (record any, ok bool, err error) {
+	addr = addr.Unmap()
+	var ip []byte
+	node := uint(0)
+	switch {
+	case addr.Is4():
+		a := addr.As4()
+		ip = a[:]
+		node = r.ipv4Start
+	case r.meta.IPVersion == 4:
+		return nil, false, nil
+	case addr.Is6():
+		a := addr.As16()
+		ip = a[:]
+	default:
+		return nil, false, fmt.Errorf("mmdb: invalid address %v", addr)
+	}
+
+	count := r.meta.NodeCount
+	for i := 0; i < len(ip)*8 && node < count; i++ {
+		bit := uint(ip[i/8]>>(7-i%8)) & 1
+		node = r.record(node, bit)
+	}
+	switch {
+	case node == count:
+		return nil, false, nil
+	case node < count:
+		return nil, false, fmt.Errorf("%w: search tree deeper than the address", ErrInvalidDatabase)
+	}
+
+	offset := node - count - dataSectionSeparator
+	record, _, err = r.data.decode(offset, 0)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	return record, true, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (r *Reader) record(node, bit uint) uint {
+	b := r.tree[node*r.nodeSize : (node+1)*r.nodeSize]
+	switch r.meta.RecordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b = b[bit*4:]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+}
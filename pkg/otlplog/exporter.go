@@ -0,0 +1,408 @@
+// Package otlplog exports zap log entries as OpenTelemetry log records over
+// OTLP/HTTP with the JSON encoding, so they can be correlated with traces in
+// backends such as Loki or Tempo. The trace_id and span_id fields the
+// request loggers carry become the record's trace context.
+package otlplog
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/metrics"
+	"go.uber.org/zap/zapcore"
+)
+
+var recordsTotal = metrics.NewCounter("log_export_records_total",
+	"Log records exported over OTLP, by outcome (exported, dropped, failed).", "outcome")
+
+// SCOPE_NAME names the instrumentation scope of the exported records.
+const SCOPE_NAME = "github.com/haidang666/go-app/pkg/logger"
+
+type ExporterArgs struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, e.g.
+	// "http://otel-collector:4318"; records are posted to its /v1/logs.
+	Endpoint string
+	// Headers are sent with every request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+	// ServiceVersion is the service.version resource attribute.
+	ServiceVersion string
+	// Resource holds further resource attributes, e.g. k8s.pod.name.
+	Resource map[string]string
+	// Level is the lowest level exported, independent of the console's.
+	Level zapcore.Level
+	// Buffer is how many records may wait to be sent before new ones drop.
+	Buffer    int
+	BatchSize int
+	// FlushInterval is the longest a record waits for its batch to fill.
+	FlushInterval time.Duration
+	// SendTimeout bounds each request to the receiver.
+	SendTimeout time.Duration
+	Client      *http.Client
+}
+
+// Exporter queues log records and posts them to the receiver in batches
+// from a single goroutine, so a slow receiver never holds up the code
+// logging. Batches that fail are dropped rather than retried. Failures are
+// only counted in log_export_records_total: logging them would feed the
+// exporter its own errors.
+type Exporter struct {
+	url           string
+	headers       map[string]string
+	resource      resource
+	level         zapcore.Level
+	batchSize     int
+	flushInterval time.Duration
+	sendTimeout   time.Duration
+	client        *http.Client
+	queue         chan logRecord
+	done          chan struct{}
+	// mu guards closed, so Write never sends on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewExporter(args ExporterArgs) *Exporter {
+	client := args.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	attrs := []keyValue{{Key: "service.name", Value: stringValue(args.ServiceName)}}
+	if args.ServiceVersion != "" {
+		attrs = append(attrs, keyValue{Key: "service.version", Value: stringValue(args.ServiceVersion)})
+	}
+	for _, key := range slices.Sorted(maps.Keys(args.Resource)) {
+		attrs = append(attrs, keyValue{Key: key, Value: stringValue(args.Resource[key])})
+	}
+	e := &Exporter{
+		url:           strings.TrimSuffix(args.Endpoint, "/") + "/v1/logs",
+		headers:       args.Headers,
+		resource:      resource{Attributes: attrs},
+		level:         args.Level,
+		batchSize:     max(args.BatchSize, 1),
+		flushInterval: args.FlushInterval,
+		sendTimeout:   args.SendTimeout,
+		client:        client,
+		queue:         make(chan logRecord, args.Buffer),
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Core returns the zap core feeding the exporter, for logger.Export.
+func (e *Exporter) Core() zapcore.Core {
+	return &core{exporter: e}
+}
+
+// Pending returns how many records are queued, not counting the batch
+// being filled.
+func (e *Exporter) Pending() int {
+	return len(e.queue)
+}
+
+// Close stops accepting records and sends the queued ones, waiting until
+// ctx is done at most.
+func (e *Exporter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Exporter) enqueue(r logRecord) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		recordsTotal.Inc("dropped")
+		return
+	}
+	select {
+	case e.queue <- r:
+	default:
+		recordsTotal.Inc("dropped")
+	}
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]logRecord, 0, e.batchSize)
+	for {
+		select {
+		case r, ok := <-e.queue:
+			if !ok {
+				e.send(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) < e.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		e.send(batch)
+		batch = batch[:0]
+	}
+}
+
+func (e *Exporter) send(batch []logRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	outcome := "exported"
+	if err := e.post(batch); err != nil {
+		outcome = "failed"
+	}
+	recordsTotal.Add(float64(len(batch)), outcome)
+}
+
+func (e *Exporter) post(batch []logRecord) error {
+	body, err := json.Marshal(exportRequest{ResourceLogs: []resourceLogs{{
+		Resource:  e.resource,
+		ScopeLogs: []scopeLogs{{Scope: scope{Name: SCOPE_NAME}, LogRecords: batch}},
+	}}})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp logs: status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// core turns zap entries into log records for its exporter.
+type core struct {
+	exporter *Exporter
+	fields   []zapcore.Field
+}
+
+func (c *core) Enabled(level zapcore.Level) bool {
+	return level >= c.exporter.level
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{exporter: c.exporter, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *core) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+// Write converts the entry right away, as the fields may not outlive the
+// call.
+func (c *core) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	c.exporter.enqueue(newLogRecord(e, enc.Fields))
+	return nil
+}
+
+func (c *core) Sync() error { return nil }
+
+func newLogRecord(e zapcore.Entry, fields map[string]any) logRecord {
+	severity, text := severityOf(e.Level)
+	r := logRecord{
+		TimeUnixNano:         strconv.FormatInt(e.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       severity,
+		SeverityText:         text,
+		Body:                 stringValue(e.Message),
+	}
+	if id, ok := hexID(fields["trace_id"], 16); ok {
+		r.TraceID = id
+		delete(fields, "trace_id")
+	}
+	if id, ok := hexID(fields["span_id"], 8); ok {
+		r.SpanID = id
+		delete(fields, "span_id")
+	}
+	if e.LoggerName != "" {
+		r.Attributes = append(r.Attributes, keyValue{Key: "logger", Value: stringValue(e.LoggerName)})
+	}
+	if e.Caller.Defined {
+		r.Attributes = append(r.Attributes,
+			keyValue{Key: "code.filepath", Value: stringValue(e.Caller.File)},
+			keyValue{Key: "code.lineno", Value: anyValue{IntValue: strconv.Itoa(e.Caller.Line)}},
+		)
+	}
+	if e.Stack != "" {
+		r.Attributes = append(r.Attributes, keyValue{Key: "exception.stacktrace", Value: stringValue(e.Stack)})
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.Attributes = append(r.Attributes, keyValue{Key: k, Value: valueOf(fields[k])})
+	}
+	return r
+}
+
+// severityOf maps a zap level to the OpenTelemetry severity number and text.
+func severityOf(level zapcore.Level) (int, string) {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5, "DEBUG"
+	case zapcore.InfoLevel:
+		return 9, "INFO"
+	case zapcore.WarnLevel:
+		return 13, "WARN"
+	case zapcore.ErrorLevel:
+		return 17, "ERROR"
+	case zapcore.DPanicLevel:
+		return 18, "ERROR"
+	default:
+		return 21, "FATAL"
+	}
+}
+
+// hexID returns v when it is a hex ID of n bytes, as OTLP/JSON encodes them.
+func hexID(v any, n int) (string, bool) {
+	s, ok := v.(string)
+	if !ok || len(s) != 2*n || strings.Trim(s, "0") == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+func valueOf(v any) anyValue {
+	switch v := v.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int:
+		return anyValue{IntValue: strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return anyValue{IntValue: strconv.FormatInt(v, 10)}
+	case int32:
+		return anyValue{IntValue: strconv.FormatInt(int64(v), 10)}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return anyValue{IntValue: strconv.FormatUint(v, 10)}
+		}
+	case uint32:
+		return anyValue{IntValue: strconv.FormatUint(uint64(v), 10)}
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return anyValue{DoubleValue: &v}
+		}
+	case time.Duration:
+		return stringValue(v.String())
+	case time.Time:
+		return stringValue(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return stringValue(v.String())
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return stringValue(string(b))
+	}
+	return stringValue(fmt.Sprint(v))
+}
+
+// The OTLP/JSON export request, reduced to what the exporter sends.
+
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type logRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes,omitempty"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue holds exactly one of its fields; int64s are strings, as in
+// the protobuf JSON mapping.
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    string   `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func stringValue(s string) anyValue {
+	return anyValue{StringValue: &s}
+}
@@ -0,0 +1,65 @@
+// Package podinfo reads the Kubernetes pod an instance runs in from the
+// environment variables the downward API sets, e.g.
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: POD_NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	  - name: NODE_NAME
+//	    valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//
+// Outside Kubernetes, or when the manifest does not set them, they are
+// empty and nothing is enriched.
+package podinfo
+
+import (
+	"os"
+	"sync"
+)
+
+type Info struct {
+	Pod       string `json:"pod,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Node      string `json:"node,omitempty"`
+}
+
+// Attr is one set field of Info, by its short name: pod, namespace or node.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+var get = sync.OnceValue(func() Info {
+	return Info{
+		Pod:       os.Getenv("POD_NAME"),
+		Namespace: os.Getenv("POD_NAMESPACE"),
+		Node:      os.Getenv("NODE_NAME"),
+	}
+})
+
+// Get returns the pod metadata, read once.
+func Get() Info {
+	return get()
+}
+
+// Attrs returns the set fields in the order pod, namespace, node.
+func (i Info) Attrs() []Attr {
+	var attrs []Attr
+	for _, a := range []Attr{{"pod", i.Pod}, {"namespace", i.Namespace}, {"node", i.Node}} {
+		if a.Value != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// ResourceAttributes returns the set fields under their OpenTelemetry
+// names: k8s.pod.name, k8s.namespace.name and k8s.node.name.
+func (i Info) ResourceAttributes() map[string]string {
+	attrs := make(map[string]string)
+	for _, a := range i.Attrs() {
+		attrs["k8s."+a.Key+".name"] = a.Value
+	}
+	return attrs
+}
@@ -0,0 +1,90 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many takes pass between removals of expired buckets.
+const sweepEvery = 1024
+
+// MemoryStore counts hits inside a single process. It is meant for local
+// development and single-replica deployments.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	takes   int
+}
+
+type memoryBucket struct {
+	count     int
+	expiresAt time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limits []Limit, now time.Time) ([]Usage, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	usage := make([]Usage, len(limits))
+	currentKeys := make([]string, len(limits))
+	allowed := true
+	for i, limit := range limits {
+		prevKey, curKey := bucketKeys(key, limit, now)
+		currentKeys[i] = curKey
+		usage[i] = Usage{Previous: s.count(prevKey, now), Current: s.count(curKey, now)}
+		if estimate(usage[i], limit, now) >= limit.Requests {
+			allowed = false
+		}
+	}
+	if !allowed {
+		return usage, false, nil
+	}
+
+	for i, limit := range limits {
+		usage[i].Current++
+		s.buckets[currentKeys[i]] = memoryBucket{
+			count:     usage[i].Current,
+			expiresAt: bucketStart(limit, now).Add(2 * limit.Window),
+		}
+	}
+	return usage, true, nil
+}
+
+// count expects the caller to hold the lock.
+func (s *MemoryStore) count(key string, now time.Time) int {
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.expiresAt) {
+		return 0
+	}
+	return b.count
+}
+
+// sweep expects the caller to hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// bucketKeys names the previous and current bucket of limit for key.
+func bucketKeys(key string, limit Limit, now time.Time) (string, string) {
+	window := strconv.FormatInt(limit.Window.Milliseconds(), 10)
+	bucket := bucketIndex(limit, now)
+	prefix := key + ":" + window + ":"
+	return prefix + strconv.FormatInt(bucket-1, 10), prefix + strconv.FormatInt(bucket, 10)
+}
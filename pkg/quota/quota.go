@@ -0,0 +1,241 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/clock"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var ErrUnknownPlan = errors.New("unknown quota plan")
+
+var (
+	rejectedTotal = metrics.NewCounter("quota_rejected_total",
+		"Requests rejected because a quota was exhausted.", "plan")
+	warnedTotal = metrics.NewCounter("quota_warned_total",
+		"Requests allowed at or past the soft threshold of a quota.", "plan")
+)
+
+// Limit allows Requests per rolling Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Usage is a subject's hit count in the window bucket before the current one
+// and in the current one.
+type Usage struct {
+	Previous int
+	Current  int
+}
+
+// Store counts hits per key. Take must be atomic: it checks every limit and
+// counts the hit in all of them only if none is exhausted.
+type Store interface {
+	Take(ctx context.Context, key string, limits []Limit, now time.Time) (usage []Usage, allowed bool, err error)
+}
+
+// Result describes the most constrained limit after a hit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAt is when the current window ends; for a rejected hit it is when
+	// the next hit will be allowed.
+	ResetAt time.Time
+	// Warning is set for an allowed hit that reached the soft threshold of a
+	// limit.
+	Warning *Warning
+}
+
+// Warning describes the limit closest to exhaustion after a hit that
+// reached its soft threshold.
+type Warning struct {
+	Limit Limit
+	// Used is the estimated hit count in the rolling window.
+	Used int
+}
+
+type LimiterArgs struct {
+	Store Store
+	// Plans maps plan names to their limits.
+	Plans map[string][]Limit
+	// DefaultPlan applies to subjects without a plan or with an unknown one.
+	DefaultPlan string
+	// SoftRatio is the share of each limit from which allowed hits carry a
+	// Warning, so clients can back off before they are rejected. 0 disables
+	// warnings.
+	SoftRatio float64
+	// Clock places hits in their windows; nil means the wall clock.
+	Clock clock.Clock
+}
+
+// Limiter enforces per-plan request quotas with sliding window counters: a
+// hit is allowed while the current bucket's count plus the previous bucket's
+// count, weighted by how much of it still overlaps the rolling window, stays
+// below the limit.
+type Limiter struct {
+	store       Store
+	plans       map[string][]Limit
+	defaultPlan string
+	softRatio   float64
+	clock       clock.Clock
+}
+
+func NewLimiter(args LimiterArgs) (*Limiter, error) {
+	if _, ok := args.Plans[args.DefaultPlan]; !ok {
+		return nil, fmt.Errorf("%w: default plan %q", ErrUnknownPlan, args.DefaultPlan)
+	}
+	if args.SoftRatio < 0 || args.SoftRatio >= 1 {
+		return nil, fmt.Errorf("soft ratio must be in [0, 1), got %v", args.SoftRatio)
+	}
+	return &Limiter{store: args.Store, plans: args.Plans, defaultPlan: args.DefaultPlan, softRatio: args.SoftRatio, clock: clock.OrReal(args.Clock)}, nil
+}
+
+// PlanNames lists the configured plans.
+func (l *Limiter) PlanNames() []string {
+	names := make([]string, 0, len(l.plans))
+	for name := range l.plans {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Take counts a hit by subject against its plan's limits.
+func (l *Limiter) Take(ctx context.Context, subject, plan string) (Result, error) {
+	limits, ok := l.plans[plan]
+	if !ok {
+		plan = l.defaultPlan
+		limits = l.plans[plan]
+	}
+
+	res, err := l.take(ctx, subject, limits)
+	if err != nil {
+		return Result{}, err
+	}
+	if !res.Allowed {
+		rejectedTotal.Inc(plan)
+	} else if res.Warning != nil {
+		warnedTotal.Inc(plan)
+	}
+	return res, nil
+}
+
+// TakeLimits counts a hit by subject against limits of its own rather than
+// a plan's, such as a tenant's. It is not counted in the plan metrics.
+func (l *Limiter) TakeLimits(ctx context.Context, subject string, limits []Limit) (Result, error) {
+	return l.take(ctx, subject, limits)
+}
+
+func (l *Limiter) take(ctx context.Context, subject string, limits []Limit) (Result, error) {
+	now := l.clock.Now()
+	usage, allowed, err := l.store.Take(ctx, subject, limits, now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var res Result
+	var warning *Warning
+	for i, limit := range limits {
+		estimate := estimate(usage[i], limit, now)
+		remaining := max(limit.Requests-estimate, 0)
+		reset := bucketStart(limit, now).Add(limit.Window)
+		if !allowed && estimate >= limit.Requests {
+			reset = retryAt(usage[i], limit, now)
+		}
+
+		if i == 0 || remaining < res.Remaining || (remaining == res.Remaining && reset.After(res.ResetAt)) {
+			res = Result{Limit: limit.Requests, Remaining: remaining, ResetAt: reset}
+		}
+		if l.softRatio > 0 && float64(estimate) >= l.softRatio*float64(limit.Requests) &&
+			(warning == nil || share(estimate, limit) > share(warning.Used, warning.Limit)) {
+			warning = &Warning{Limit: limit, Used: estimate}
+		}
+	}
+	res.Allowed = allowed
+	if allowed && warning != nil {
+		res.Warning = warning
+	}
+	return res, nil
+}
+
+// share is how much of limit used hits take up.
+func share(used int, limit Limit) float64 {
+	return float64(used) / float64(limit.Requests)
+}
+
+// bucketStart returns the start of the fixed bucket of limit containing now.
+func bucketStart(limit Limit, now time.Time) time.Time {
+	return time.UnixMilli(bucketIndex(limit, now) * limit.Window.Milliseconds())
+}
+
+// bucketIndex numbers the fixed buckets of limit from the Unix epoch.
+func bucketIndex(limit Limit, now time.Time) int64 {
+	return now.UnixMilli() / limit.Window.Milliseconds()
+}
+
+// overlap is the share of the previous bucket still inside the rolling
+// window ending at now.
+func overlap(limit Limit, now time.Time) float64 {
+	elapsed := now.Sub(bucketStart(limit, now))
+	return 1 - float64(elapsed)/float64(limit.Window)
+}
+
+func estimate(u Usage, limit Limit, now time.Time) int {
+	return int(math.Floor(float64(u.Previous)*overlap(limit, now))) + u.Current
+}
+
+// retryAt is the earliest time the estimate drops below the limit again.
+func retryAt(u Usage, limit Limit, now time.Time) time.Time {
+	start := bucketStart(limit, now)
+	n := float64(limit.Requests)
+	if u.Current < limit.Requests {
+		// The previous bucket decays out of the window within this bucket.
+		share := 1 - (n-float64(u.Current))/float64(u.Previous)
+		return start.Add(time.Duration(share * float64(limit.Window)))
+	}
+	// The current bucket becomes the previous one and has to decay.
+	share := 1 - n/float64(u.Current)
+	return start.Add(limit.Window + time.Duration(share*float64(limit.Window)))
+}
+
+// ParsePlans parses plan specs of the form "60/1m;5000/24h": requests per
+// window, separated by semicolons.
+func ParsePlans(specs map[string]string) (map[string][]Limit, error) {
+	plans := make(map[string][]Limit, len(specs))
+	for name, spec := range specs {
+		limits, err := ParseLimits(spec)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: %w", name, err)
+		}
+		plans[name] = limits
+	}
+	return plans, nil
+}
+
+// ParseLimits parses one spec of the form "60/1m;5000/24h".
+func ParseLimits(spec string) ([]Limit, error) {
+	var limits []Limit
+	for _, part := range strings.Split(spec, ";") {
+		requests, window, ok := strings.Cut(strings.TrimSpace(part), "/")
+		if !ok {
+			return nil, fmt.Errorf("limit %q is not requests/window", part)
+		}
+		n, err := strconv.Atoi(requests)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid request count %q", requests)
+		}
+		d, err := time.ParseDuration(window)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid window %q", window)
+		}
+		limits = append(limits, Limit{Requests: n, Window: d})
+	}
+	return limits, nil
+}
@@ -0,0 +1,83 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisScripter is the subset of a Redis client the store needs; adapt e.g.
+// go-redis with a one-line wrapper around Eval(...).Result().
+type RedisScripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// takeScript gets KEYS as previous/current bucket pairs and ARGV as
+// limit/overlap/ttl triples, one per limit. It replies with the allowed flag
+// followed by the previous and current count of every limit.
+const takeScript = `
+local reply = {1}
+for i = 1, #KEYS / 2 do
+	local prev = tonumber(redis.call("GET", KEYS[2*i-1]) or "0")
+	local cur = tonumber(redis.call("GET", KEYS[2*i]) or "0")
+	local limit = tonumber(ARGV[3*i-2])
+	if math.floor(prev * tonumber(ARGV[3*i-1])) + cur >= limit then
+		reply[1] = 0
+	end
+	reply[2*i] = prev
+	reply[2*i+1] = cur
+end
+if reply[1] == 1 then
+	for i = 1, #KEYS / 2 do
+		reply[2*i+1] = redis.call("INCR", KEYS[2*i])
+		redis.call("PEXPIRE", KEYS[2*i], ARGV[3*i])
+	end
+end
+return reply`
+
+// RedisStore shares quota counters between replicas.
+type RedisStore struct {
+	client RedisScripter
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client RedisScripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limits []Limit, now time.Time) ([]Usage, bool, error) {
+	keys := make([]string, 0, 2*len(limits))
+	args := make([]any, 0, 3*len(limits))
+	for _, limit := range limits {
+		prevKey, curKey := bucketKeys(s.prefix+key, limit, now)
+		ttl := bucketStart(limit, now).Add(2 * limit.Window).Sub(now)
+		keys = append(keys, prevKey, curKey)
+		args = append(args, limit.Requests, strconv.FormatFloat(overlap(limit, now), 'f', -1, 64), ttl.Milliseconds())
+	}
+
+	res, err := s.client.Eval(ctx, takeScript, keys, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	reply, ok := res.([]any)
+	if !ok || len(reply) != 1+2*len(limits) {
+		return nil, false, fmt.Errorf("unexpected redis reply %T", res)
+	}
+
+	values := make([]int, len(reply))
+	for i, v := range reply {
+		n, ok := v.(int64)
+		if !ok {
+			return nil, false, fmt.Errorf("unexpected redis reply element %T", v)
+		}
+		values[i] = int(n)
+	}
+	usage := make([]Usage, len(limits))
+	for i := range limits {
+		usage[i] = Usage{Previous: values[1+2*i], Current: values[2+2*i]}
+	}
+	return usage, values[0] == 1, nil
+}
@@ -0,0 +1,183 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const MASK = "[REDACTED]"
+
+// UNPARSED replaces the bodies that cannot be parsed for redaction, as they
+// may hold secrets in a form no key can be found in.
+const UNPARSED = "[UNPARSED]"
+
+// DefaultFields are the keys masked when no explicit list is configured.
+var DefaultFields = []string{
+	"password", "secret", "token", "authorization", "cookie", "api_key",
+	"apikey", "code_verifier", "otp", "samlresponse", "assertion",
+}
+
+// exactFields are masked on an exact match only, as they are part of the
+// names of many harmless keys, e.g. an OAuth code but not an error code.
+var exactFields = map[string]struct{}{"code": {}}
+
+// Redactor masks values of sensitive keys. A key is sensitive when it
+// contains one of the fields, matched case-insensitively with "-" taken as
+// "_", so "password" covers "new_password" and "api_key" covers
+// "X-Api-Key".
+type Redactor struct {
+	fields []string
+}
+
+func New(fields []string) *Redactor {
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = normalize(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return &Redactor{fields: out}
+}
+
+func normalize(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+func (r *Redactor) IsSensitive(key string) bool {
+	key = normalize(key)
+	if _, ok := exactFields[key]; ok {
+		return true
+	}
+	for _, f := range r.fields {
+		if strings.Contains(key, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON returns body with sensitive values masked at any depth. Bodies that
+// are not valid JSON are returned unchanged.
+func (r *Redactor) JSON(body []byte) []byte {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(r.value(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// Body returns body, of type contentType, with sensitive values masked:
+// JSON at any depth, and URL-encoded and multipart forms by field, files
+// being left out. Any other body is replaced with UNPARSED.
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []byte(UNPARSED)
+		}
+		return []byte(r.values(values).Encode())
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return r.multipart(body, params["boundary"])
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []byte(UNPARSED)
+	}
+	out, err := json.Marshal(r.value(v))
+	if err != nil {
+		return []byte(UNPARSED)
+	}
+	return out
+}
+
+// multipart renders the fields of a multipart body URL-encoded, masked, with
+// each file as its name.
+func (r *Redactor) multipart(body []byte, boundary string) []byte {
+	if boundary == "" {
+		return []byte(UNPARSED)
+	}
+	values := url.Values{}
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return []byte(UNPARSED)
+		}
+		if name := part.FileName(); name != "" {
+			values.Add(part.FormName(), "[FILE "+name+"]")
+			continue
+		}
+		v, err := io.ReadAll(part)
+		if err != nil {
+			return []byte(UNPARSED)
+		}
+		values.Add(part.FormName(), string(v))
+	}
+	return []byte(r.values(values).Encode())
+}
+
+func (r *Redactor) values(values url.Values) url.Values {
+	for k, vs := range values {
+		if r.IsSensitive(k) {
+			for i := range vs {
+				vs[i] = MASK
+			}
+		}
+	}
+	return values
+}
+
+// Headers returns a flattened copy of h with sensitive headers masked.
+func (r *Redactor) Headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k := range h {
+		if r.IsSensitive(k) {
+			out[k] = MASK
+			continue
+		}
+		out[k] = h.Get(k)
+	}
+	return out
+}
+
+func (r *Redactor) value(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if r.IsSensitive(k) {
+				t[k] = MASK
+				continue
+			}
+			t[k] = r.value(val)
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = r.value(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
@@ -0,0 +1,81 @@
+package redact
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBodyMasksFormFields(t *testing.T) {
+	r := New(nil)
+	body := "grant_type=authorization_code&code=abc&code_verifier=xyz&client_id=rp&client_secret=s3cr3t"
+
+	got, err := url.ParseQuery(string(r.Body("application/x-www-form-urlencoded; charset=utf-8", []byte(body))))
+	if err != nil {
+		t.Fatalf("parse redacted body: %v", err)
+	}
+	for key, want := range map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "rp",
+		"code":          MASK,
+		"code_verifier": MASK,
+		"client_secret": MASK,
+	} {
+		if got.Get(key) != want {
+			t.Errorf("%s = %q, want %q", key, got.Get(key), want)
+		}
+	}
+}
+
+func TestBodyMasksMultipartFields(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("email", "a@example.com")
+	w.WriteField("password", "hunter2")
+	fw, _ := w.CreateFormFile("avatar", "me.png")
+	fw.Write([]byte("PNG"))
+	w.Close()
+
+	got := string(New(nil).Body(w.FormDataContentType(), buf.Bytes()))
+	if strings.Contains(got, "hunter2") || strings.Contains(got, "PNG") {
+		t.Fatalf("redacted body leaks a value: %s", got)
+	}
+	values, _ := url.ParseQuery(got)
+	if values.Get("email") != "a@example.com" || values.Get("password") != MASK {
+		t.Errorf("redacted body = %s", got)
+	}
+}
+
+func TestBodyDropsUnparsedBodies(t *testing.T) {
+	r := New(nil)
+	for _, tc := range []struct{ contentType, body string }{
+		{"text/plain", "password=hunter2"},
+		{"application/xml", "<samlp:Response>...</samlp:Response>"},
+		{"multipart/form-data", "--x\r\npassword"},
+	} {
+		if got := string(r.Body(tc.contentType, []byte(tc.body))); got != UNPARSED {
+			t.Errorf("%s: body = %q, want %q", tc.contentType, got, UNPARSED)
+		}
+	}
+}
+
+func TestSensitiveKeysMatchBySubstring(t *testing.T) {
+	r := New(nil)
+	got := string(r.Body("application/json", []byte(`{"client_secret":"s","id_token":"t","code":"c","error_code":"e","user":{"new_password":"p"}}`)))
+	for _, leak := range []string{`"s"`, `"t"`, `"c"`, `"p"`} {
+		if strings.Contains(got, leak) {
+			t.Errorf("redacted body leaks %s: %s", leak, got)
+		}
+	}
+	if !strings.Contains(got, `"error_code":"e"`) {
+		t.Errorf("error_code was masked: %s", got)
+	}
+
+	headers := r.Headers(http.Header{"X-Api-Key": {"k"}, "Accept": {"*/*"}})
+	if headers["X-Api-Key"] != MASK || headers["Accept"] != "*/*" {
+		t.Errorf("headers = %v", headers)
+	}
+}
@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	STATE_CLOSED State = iota
+	STATE_HALF_OPEN
+	STATE_OPEN
+)
+
+func (s State) String() string {
+	switch s {
+	case STATE_CLOSED:
+		return "closed"
+	case STATE_HALF_OPEN:
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+type BreakerArgs struct {
+	// FailureThreshold consecutive failures open the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting a probe through.
+	OpenTimeout time.Duration
+	// IsFailure decides which errors count against the dependency. Defaults
+	// to every non-nil error except context cancellation.
+	IsFailure func(error) bool
+	// OnStateChange is called with the new state after every transition.
+	OnStateChange func(State)
+}
+
+// Breaker is a consecutive-failure circuit breaker. While open it rejects
+// calls immediately; after OpenTimeout a single probe call is allowed and its
+// outcome closes or re-opens the breaker.
+type Breaker struct {
+	args BreakerArgs
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(args BreakerArgs) *Breaker {
+	if args.FailureThreshold <= 0 {
+		args.FailureThreshold = 5
+	}
+	if args.OpenTimeout <= 0 {
+		args.OpenTimeout = 30 * time.Second
+	}
+	if args.IsFailure == nil {
+		args.IsFailure = func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		}
+	}
+	return &Breaker{args: args}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn(ctx)
+	b.record(err)
+	return err
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case STATE_OPEN:
+		if time.Since(b.openedAt) < b.args.OpenTimeout {
+			return ErrBreakerOpen
+		}
+		b.transition(STATE_HALF_OPEN)
+		b.probing = true
+		return nil
+	case STATE_HALF_OPEN:
+		if b.probing {
+			return ErrBreakerOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !b.args.IsFailure(err) {
+		b.failures = 0
+		if b.state != STATE_CLOSED {
+			b.transition(STATE_CLOSED)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == STATE_HALF_OPEN || b.failures >= b.args.FailureThreshold {
+		b.openedAt = time.Now()
+		b.transition(STATE_OPEN)
+	}
+}
+
+// transition expects the caller to hold the lock.
+func (b *Breaker) transition(s State) {
+	b.state = s
+	if b.args.OnStateChange != nil {
+		b.args.OnStateChange(s)
+	}
+}
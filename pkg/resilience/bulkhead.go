@@ -0,0 +1,51 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// Bulkhead caps the number of concurrent calls into a dependency so a slow
+// dependency cannot tie up every request goroutine.
+type Bulkhead struct {
+	sem     chan struct{}
+	maxWait time.Duration
+}
+
+// NewBulkhead allows maxConcurrent calls at once; extra callers wait up to
+// maxWait for a slot before failing with ErrBulkheadFull.
+func NewBulkhead(maxConcurrent int, maxWait time.Duration) *Bulkhead {
+	return &Bulkhead{
+		sem:     make(chan struct{}, max(maxConcurrent, 1)),
+		maxWait: maxWait,
+	}
+}
+
+func (b *Bulkhead) InFlight() int {
+	return len(b.sem)
+}
+
+func (b *Bulkhead) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	select {
+	case b.sem <- struct{}{}:
+	default:
+		if b.maxWait <= 0 {
+			return ErrBulkheadFull
+		}
+		timer := time.NewTimer(b.maxWait)
+		defer timer.Stop()
+		select {
+		case b.sem <- struct{}{}:
+		case <-timer.C:
+			return ErrBulkheadFull
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() { <-b.sem }()
+
+	return fn(ctx)
+}
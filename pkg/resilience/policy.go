@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var (
+	breakerState = metrics.NewGauge("resilience_breaker_state",
+		"Circuit breaker state per dependency (0=closed, 1=half_open, 2=open).", "dependency")
+	rejectedTotal = metrics.NewCounter("resilience_rejected_total",
+		"Calls rejected by a resilience policy.", "dependency", "reason")
+	bulkheadInFlight = metrics.NewGauge("resilience_bulkhead_in_flight",
+		"Calls currently admitted by the bulkhead.", "dependency")
+)
+
+type PolicyArgs struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	MaxConcurrent    int
+	MaxWait          time.Duration
+	IsFailure        func(error) bool
+	// Critical policies fail the readiness check while their breaker is open.
+	Critical bool
+}
+
+// Policy guards one dependency with a bulkhead in front of a circuit breaker.
+type Policy struct {
+	name     string
+	critical bool
+	breaker  *Breaker
+	bulkhead *Bulkhead
+}
+
+func (p *Policy) Name() string {
+	return p.name
+}
+
+func (p *Policy) State() State {
+	return p.breaker.State()
+}
+
+func (p *Policy) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Calls for callers that already gave up are not started.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := p.bulkhead.Execute(ctx, func(ctx context.Context) error {
+		bulkheadInFlight.Inc(p.name)
+		defer bulkheadInFlight.Dec(p.name)
+		return p.breaker.Execute(ctx, fn)
+	})
+
+	switch {
+	case errors.Is(err, ErrBulkheadFull):
+		rejectedTotal.Inc(p.name, "bulkhead_full")
+	case errors.Is(err, ErrBreakerOpen):
+		rejectedTotal.Inc(p.name, "breaker_open")
+	}
+	return err
+}
+
+// Registry keeps every policy so their state can be reported together.
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string]*Policy
+}
+
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]*Policy)}
+}
+
+// Policy returns the policy registered under name, creating it from args on
+// first use.
+func (r *Registry) Policy(name string, args PolicyArgs) *Policy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.policies[name]; ok {
+		return p
+	}
+
+	p := &Policy{
+		name:     name,
+		critical: args.Critical,
+		bulkhead: NewBulkhead(args.MaxConcurrent, args.MaxWait),
+	}
+	p.breaker = NewBreaker(BreakerArgs{
+		FailureThreshold: args.FailureThreshold,
+		OpenTimeout:      args.OpenTimeout,
+		IsFailure:        args.IsFailure,
+		OnStateChange: func(s State) {
+			breakerState.Set(float64(s), name)
+		},
+	})
+	breakerState.Set(float64(STATE_CLOSED), name)
+	r.policies[name] = p
+	return p
+}
+
+type PolicyStatus struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Critical bool   `json:"critical"`
+	InFlight int    `json:"in_flight"`
+}
+
+// Statuses reports every policy sorted by name, and whether all critical
+// dependencies are currently usable.
+func (r *Registry) Statuses() ([]PolicyStatus, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	healthy := true
+	out := make([]PolicyStatus, 0, len(r.policies))
+	for _, p := range r.policies {
+		state := p.State()
+		if p.critical && state == STATE_OPEN {
+			healthy = false
+		}
+		out = append(out, PolicyStatus{
+			Name:     p.name,
+			State:    state.String(),
+			Critical: p.critical,
+			InFlight: p.bulkhead.InFlight(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, healthy
+}
@@ -0,0 +1,274 @@
+// Package resp is a minimal Redis client speaking RESP2 over a small pool of
+// connections. It covers what the application's Redis-backed stores need,
+// plain commands, Lua scripts and channel subscriptions, and satisfies
+// lock.RedisScripter.
+package resp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is an error reply from the server, e.g. "WRONGTYPE ...".
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+type ClientArgs struct {
+	Addr     string
+	Password string
+	DB       int
+	// PoolSize bounds the idle connections kept; 0 means 8.
+	PoolSize    int
+	DialTimeout time.Duration
+}
+
+// Client is safe for concurrent use.
+type Client struct {
+	args ClientArgs
+	idle chan *conn
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func NewClient(args ClientArgs) *Client {
+	if args.PoolSize <= 0 {
+		args.PoolSize = 8
+	}
+	if args.DialTimeout <= 0 {
+		args.DialTimeout = 5 * time.Second
+	}
+	return &Client{args: args, idle: make(chan *conn, args.PoolSize)}
+}
+
+// Do sends one command and returns its reply: a string for simple and bulk
+// strings, an int64 for integers, a []any for arrays, and nil for nil
+// replies. An error reply is returned as an Error.
+func (c *Client) Do(ctx context.Context, args ...any) (any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	} else {
+		cn.SetDeadline(time.Time{})
+	}
+
+	reply, err := cn.roundTrip(args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may be out of step with the server.
+		cn.Close()
+		return nil, fmt.Errorf("resp: %w", err)
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Eval runs a Lua script, by its SHA1 once the server has seen it.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	sum := sha1.Sum([]byte(script))
+	sha := hex.EncodeToString(sum[:])
+
+	cmd := make([]any, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVALSHA", sha, len(keys))
+	for _, k := range keys {
+		cmd = append(cmd, k)
+	}
+	cmd = append(cmd, args...)
+
+	reply, err := c.Do(ctx, cmd...)
+	var replyErr Error
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		cmd[0], cmd[1] = "EVAL", script
+		return c.Do(ctx, cmd...)
+	}
+	return reply, err
+}
+
+// Subscribe listens on channel, on a connection of its own, and calls fn
+// with each message until ctx is done or the connection fails. fn runs on
+// the reading goroutine, so a slow fn holds up the messages behind it.
+// Messages published while no subscription is open are lost.
+func (c *Client) Subscribe(ctx context.Context, channel string, fn func(msg string)) error {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return err
+	}
+	defer cn.Close()
+	cn.SetDeadline(time.Time{})
+	stop := context.AfterFunc(ctx, func() { cn.Close() })
+	defer stop()
+
+	if _, err := cn.roundTrip([]any{"SUBSCRIBE", channel}); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("resp: subscribe: %w", err)
+	}
+	for {
+		reply, err := readReply(cn.r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("resp: %w", err)
+		}
+		// A message is ["message", channel, payload].
+		items, ok := reply.([]any)
+		if !ok || len(items) != 3 || items[0] != "message" {
+			continue
+		}
+		if msg, ok := items[2].(string); ok {
+			fn(msg)
+		}
+	}
+}
+
+// Close closes the idle connections.
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	d := net.Dialer{Timeout: c.args.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.args.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("resp: %w", err)
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	}
+	if c.args.Password != "" {
+		if _, err := cn.roundTrip([]any{"AUTH", c.args.Password}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("resp: auth: %w", err)
+		}
+	}
+	if c.args.DB != 0 {
+		if _, err := cn.roundTrip([]any{"SELECT", c.args.DB}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("resp: select: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+func (cn *conn) roundTrip(args []any) (any, error) {
+	if err := writeCommand(cn.w, args); err != nil {
+		return nil, err
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+func writeCommand(w *bufio.Writer, args []any) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Errorf("unsupported argument type %T", arg)
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+	}
+	return nil
+}
+
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			// An error inside an array, e.g. from EXEC, is kept as a value.
+			item, err := readReply(r)
+			var replyErr Error
+			if errors.As(err, &replyErr) {
+				item, err = replyErr, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown reply type %q", kind)
+}
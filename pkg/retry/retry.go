@@ -0,0 +1,138 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrExhausted = errors.New("retry attempts exhausted")
+
+// Policy describes how an operation is retried. The zero value retries
+// nothing; use DefaultPolicy as a starting point.
+type Policy struct {
+	// MaxAttempts includes the first call. Zero means unlimited, bounded only
+	// by MaxElapsed and the context.
+	MaxAttempts int
+	// MaxElapsed stops retrying once this much time has passed since the first
+	// attempt. Zero means no limit.
+	MaxElapsed time.Duration
+
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter randomizes each delay by up to ±Jitter (0..1) of its value.
+	Jitter float64
+
+	// Retryable classifies errors; nil treats every error as retryable unless
+	// it is marked with Permanent.
+	Retryable func(error) bool
+	// OnRetry is called before sleeping for the next attempt.
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:     5,
+		MaxElapsed:      30 * time.Second,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the policy
+// or context gives up. The error from the last attempt is wrapped so callers
+// can still match it with errors.Is.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	var zero T
+
+	for attempt := 1; ; attempt++ {
+		v, err := fn(ctx)
+		if err == nil {
+			return v, nil
+		}
+		if !p.retryable(err) {
+			return zero, err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return zero, fmt.Errorf("%w after %d attempts: %w", ErrExhausted, attempt, err)
+		}
+
+		delay := p.Backoff(attempt)
+		if p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			return zero, fmt.Errorf("%w after %s: %w", ErrExhausted, time.Since(start).Round(time.Millisecond), err)
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the delay to wait after the given failed attempt (1-based).
+func (p Policy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialInterval) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxInterval > 0 && delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(max(delay, 0))
+}
+
+func (p Policy) retryable(err error) bool {
+	if IsPermanent(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Package safe isolates the handlers of background work, such as scheduled
+// jobs, queued email and bus events, the way the HTTP Recoverer isolates
+// requests: a handler that panics fails its own job or message, reported
+// with its stack, and the worker carries on with the next one.
+package safe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var panicsTotal = metrics.NewCounter("handler_panics_total",
+	"Panics recovered from background handlers, by component.", "component")
+
+// PanicError is a recovered panic. Unlike most errors it is not worth a
+// retry: the same job or message is likely to panic again, so callers
+// quarantine it instead.
+type PanicError struct {
+	Value any
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// IsPanic reports whether err is or wraps a recovered panic.
+func IsPanic(err error) bool {
+	var p *PanicError
+	return errors.As(err, &p)
+}
+
+// Call runs fn and turns a panic into a *PanicError, which it logs on ctx's
+// logger with its stack and keyvals, e.g. the job ID, and counts under
+// component, e.g. "jobs.phone.clear_unverified".
+func Call(ctx context.Context, component string, fn func() error, keyvals ...any) (err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		p := &PanicError{Value: rec, Stack: string(debug.Stack())}
+		panicsTotal.Inc(component)
+		fields := append([]any{"component", component, "panic", fmt.Sprint(rec), "stack", p.Stack}, keyvals...)
+		ctxutil.Logger(ctx).Errorw("handler panicked", fields...)
+		err = p
+	}()
+	return fn()
+}
@@ -0,0 +1,146 @@
+// Package saga runs multi-step operations as a sequence of steps with
+// compensations: when a step fails, the steps that already succeeded are
+// undone in reverse order. Progress is saved to a Store after every
+// transition so partially applied sagas can be found and repaired.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/haidang666/go-app/pkg/ctxutil"
+	"github.com/haidang666/go-app/pkg/metrics"
+)
+
+var outcomesTotal = metrics.NewCounter("saga_outcomes_total",
+	"Finished sagas by name and final status.", "saga", "status")
+
+type Status string
+
+const (
+	STATUS_RUNNING      Status = "running"
+	STATUS_COMPLETED    Status = "completed"
+	STATUS_COMPENSATING Status = "compensating"
+	STATUS_COMPENSATED  Status = "compensated"
+	// STATUS_STUCK means a compensation failed too, so the saga is left
+	// partially applied and needs a person to look at it.
+	STATUS_STUCK Status = "stuck"
+)
+
+// Step is one unit of a saga. Compensate undoes a successful Do and may be
+// nil when there is nothing to undo.
+type Step struct {
+	Name       string
+	Do         func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// State is the persisted progress of one saga run.
+type State struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Status Status    `json:"status"`
+	// Completed lists the steps that succeeded, in order; compensated steps
+	// are removed from it.
+	Completed []string `json:"completed"`
+	// Data holds identifiers the steps record, such as the ID of a created
+	// row, so a stuck saga can be repaired by hand.
+	Data       map[string]string `json:"data,omitempty"`
+	FailedStep string            `json:"failed_step,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// Store persists saga state. Save is called with the full state after every
+// transition.
+type Store interface {
+	Save(ctx context.Context, s State) error
+}
+
+type stateKey struct{}
+
+// Record stores key=value in the state of the saga running in ctx; steps use
+// it to note what they created. It does nothing outside a saga.
+func Record(ctx context.Context, key, value string) {
+	if st, ok := ctx.Value(stateKey{}).(*State); ok {
+		st.Data[key] = value
+	}
+}
+
+// Run executes steps in order. If one fails, the completed steps are
+// compensated in reverse and the step's error is returned. Compensation runs
+// on a context that is not canceled with ctx, so a client hanging up does
+// not stop the cleanup.
+func Run(ctx context.Context, store Store, name string, steps ...Step) error {
+	now := time.Now().UTC()
+	st := &State{
+		ID:        uuid.New(),
+		Name:      name,
+		Status:    STATUS_RUNNING,
+		Data:      make(map[string]string),
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	ctx = context.WithValue(ctx, stateKey{}, st)
+	save(ctx, store, st)
+
+	for i, step := range steps {
+		if err := step.Do(ctx); err != nil {
+			st.Status = STATUS_COMPENSATING
+			st.FailedStep = step.Name
+			st.Error = err.Error()
+			save(ctx, store, st)
+			compensate(context.WithoutCancel(ctx), store, st, steps[:i])
+			outcomesTotal.Inc(name, string(st.Status))
+			return err
+		}
+		st.Completed = append(st.Completed, step.Name)
+		save(ctx, store, st)
+	}
+
+	st.Status = STATUS_COMPLETED
+	save(ctx, store, st)
+	outcomesTotal.Inc(name, string(st.Status))
+	return nil
+}
+
+func compensate(ctx context.Context, store Store, st *State, done []Step) {
+	var failed error
+	for i := len(done) - 1; i >= 0; i-- {
+		step := done[i]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx); err != nil {
+				failed = errors.Join(failed, fmt.Errorf("%s: %w", step.Name, err))
+				continue
+			}
+		}
+		st.Completed = slices.Delete(st.Completed, i, i+1)
+		save(ctx, store, st)
+	}
+
+	st.Status = STATUS_COMPENSATED
+	if failed != nil {
+		st.Status = STATUS_STUCK
+		ctxutil.Logger(ctx).Errorw("saga compensation failed",
+			"saga", st.Name, "saga_id", st.ID, "data", st.Data, "error", failed)
+	}
+	save(ctx, store, st)
+}
+
+// save persists st. A store failure only costs visibility into the saga, so
+// it is logged rather than aborting the run.
+func save(ctx context.Context, store Store, st *State) {
+	st.UpdatedAt = time.Now().UTC()
+	snapshot := *st
+	snapshot.Completed = slices.Clone(st.Completed)
+	snapshot.Data = maps.Clone(st.Data)
+	if err := store.Save(ctx, snapshot); err != nil {
+		ctxutil.Logger(ctx).Warnw("save saga state", "saga", st.Name, "saga_id", st.ID, "error", err)
+	}
+}
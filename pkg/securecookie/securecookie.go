@@ -0,0 +1,160 @@
+// Package securecookie seals values handed to clients, such as cookies and
+// form or state tokens, so they can neither be read nor altered. A sealed
+// value is the URL-safe base64 of a key ID, the time it was sealed at, a
+// nonce and the value encrypted with XChaCha20-Poly1305. The name it was
+// sealed under is authenticated too, so a value cannot be replayed as
+// another.
+//
+// A Codec holds several keys so they can be rotated: the first seals new
+// values and every key opens the values it sealed. Add the new key first,
+// and drop the old one once the values it sealed have expired.
+package securecookie
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	keyIDSize     = 4
+	timestampSize = 8
+	headerSize    = keyIDSize + timestampSize + chacha20poly1305.NonceSizeX
+	// hkdfInfo separates the keys derived here from other uses of the same
+	// secrets.
+	hkdfInfo = "securecookie v1"
+	// MinSecretSize is the shortest secret New accepts.
+	MinSecretSize = 16
+)
+
+var (
+	// ErrInvalid is returned for values that were not sealed by any of the
+	// codec's keys under the name, or have been tampered with.
+	ErrInvalid = errors.New("securecookie: invalid value")
+	// ErrExpired is returned for values sealed longer ago than the maxAge
+	// they are opened with.
+	ErrExpired = errors.New("securecookie: value expired")
+	// ErrNoKeys is returned by New without secrets.
+	ErrNoKeys = errors.New("securecookie: no keys")
+	// ErrShortSecret is returned by New for a secret shorter than
+	// MinSecretSize.
+	ErrShortSecret = errors.New("securecookie: secret too short")
+)
+
+type key struct {
+	id   [keyIDSize]byte
+	aead cipher.AEAD
+}
+
+// Codec seals and opens values with its keys.
+type Codec struct {
+	keys []key
+	now  func() time.Time
+}
+
+// New returns a codec sealing with the key derived from the first secret
+// and opening with those of all of them.
+func New(secrets ...[]byte) (*Codec, error) {
+	if len(secrets) == 0 {
+		return nil, ErrNoKeys
+	}
+	c := &Codec{now: time.Now}
+	for _, secret := range secrets {
+		if len(secret) < MinSecretSize {
+			return nil, ErrShortSecret
+		}
+		raw := make([]byte, chacha20poly1305.KeySize)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(hkdfInfo)), raw); err != nil {
+			return nil, err
+		}
+		aead, err := chacha20poly1305.NewX(raw)
+		if err != nil {
+			return nil, err
+		}
+		k := key{aead: aead}
+		sum := sha256.Sum256(raw)
+		copy(k.id[:], sum[:])
+		c.keys = append(c.keys, k)
+	}
+	return c, nil
+}
+
+// Encode seals value under name with the current key.
+func (c *Codec) Encode(name string, value []byte) (string, error) {
+	k := c.keys[0]
+	out := make([]byte, headerSize, headerSize+len(value)+k.aead.Overhead())
+	copy(out, k.id[:])
+	binary.BigEndian.PutUint64(out[keyIDSize:], uint64(c.now().Unix()))
+	if _, err := rand.Read(out[keyIDSize+timestampSize : headerSize]); err != nil {
+		return "", err
+	}
+	out = k.aead.Seal(out, out[keyIDSize+timestampSize:headerSize], value, additionalData(name, out))
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Decode opens a value Encode sealed under name. Values sealed more than
+// maxAge ago are rejected with ErrExpired; a maxAge of 0 accepts any age.
+func (c *Codec) Decode(name, encoded string, maxAge time.Duration) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw) < headerSize {
+		return nil, ErrInvalid
+	}
+	var k *key
+	for i := range c.keys {
+		if [keyIDSize]byte(raw[:keyIDSize]) == c.keys[i].id {
+			k = &c.keys[i]
+			break
+		}
+	}
+	if k == nil {
+		return nil, ErrInvalid
+	}
+	value, err := k.aead.Open(nil, raw[keyIDSize+timestampSize:headerSize], raw[headerSize:], additionalData(name, raw))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	sealedAt := time.Unix(int64(binary.BigEndian.Uint64(raw[keyIDSize:])), 0)
+	if maxAge > 0 && c.now().Sub(sealedAt) > maxAge {
+		return nil, ErrExpired
+	}
+	return value, nil
+}
+
+// SetCookie sets cookie on w with value sealed under the cookie's name.
+func (c *Codec) SetCookie(w http.ResponseWriter, cookie *http.Cookie, value []byte) error {
+	sealed, err := c.Encode(cookie.Name, value)
+	if err != nil {
+		return err
+	}
+	cp := *cookie
+	cp.Value = sealed
+	http.SetCookie(w, &cp)
+	return nil
+}
+
+// Cookie opens the value of r's cookie name, returning http.ErrNoCookie
+// when there is none.
+func (c *Codec) Cookie(r *http.Request, name string, maxAge time.Duration) ([]byte, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decode(name, cookie.Value, maxAge)
+}
+
+// additionalData binds the name and the header, key ID and timestamp
+// included, to the ciphertext.
+func additionalData(name string, sealed []byte) []byte {
+	ad := make([]byte, 0, keyIDSize+timestampSize+len(name))
+	ad = append(ad, sealed[:keyIDSize+timestampSize]...)
+	return append(ad, name...)
+}
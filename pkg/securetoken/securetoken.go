@@ -0,0 +1,61 @@
+// Package securetoken generates random single-use secrets (email links,
+// invite codes) and the hashes they are stored under.
+package securetoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+var codeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// New returns a URL-safe token carrying n random bytes.
+func New(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// NewCode returns an upper-case code carrying n random bytes, easier to type
+// than New's output.
+func NewCode(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return codeEncoding.EncodeToString(buf), nil
+}
+
+// NewDigits returns a numeric code of n digits, for codes read out of a text
+// message. Its entropy is low, so callers must cap verification attempts.
+func NewDigits(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		// 250 is the largest multiple of 10 below 256; redraw above it to
+		// avoid modulo bias.
+		for b >= 250 {
+			var one [1]byte
+			if _, err := rand.Read(one[:]); err != nil {
+				return "", err
+			}
+			b = one[0]
+		}
+		buf[i] = '0' + b%10
+	}
+	return string(buf), nil
+}
+
+// Hash returns the hex SHA-256 of token. Tokens are high-entropy, so an
+// unsalted fast hash is sufficient for at-rest storage.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type HTTPSinkArgs struct {
+	// URL receives the batches, one JSON record per line
+	// (application/x-ndjson).
+	URL string
+	// Headers are sent with every request, e.g. for authentication.
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// HTTPSink posts batches to an HTTPS collector, such as Splunk HEC or a
+// Logstash or Vector HTTP input.
+type HTTPSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+func NewHTTPSink(args HTTPSinkArgs) *HTTPSink {
+	client := args.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &HTTPSink{url: args.URL, headers: args.Headers, client: client}
+}
+
+func (s *HTTPSink) Name() string { return "http" }
+
+func (s *HTTPSink) Send(ctx context.Context, records [][]byte) error {
+	var body bytes.Buffer
+	for _, r := range records {
+		body.Write(r)
+		body.WriteByte('\n')
+	}
+	return post(ctx, s.client, s.url, "application/x-ndjson", s.headers, body.Bytes())
+}
+
+func (s *HTTPSink) Close() error { return nil }
+
+func post(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("post %s: status %d", url, res.StatusCode)
+	}
+	return nil
+}
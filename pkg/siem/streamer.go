@@ -0,0 +1,161 @@
+// Package siem streams security records, such as audit events, to a SIEM
+// as they happen: to a syslog server, an HTTPS collector or a Kafka topic.
+// Records are JSON documents; the streamer queues them and sends them in
+// batches, retrying failed batches, so the code producing them never waits
+// on the SIEM.
+package siem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/logger"
+	"github.com/haidang666/go-app/pkg/metrics"
+	"github.com/haidang666/go-app/pkg/retry"
+)
+
+var recordsTotal = metrics.NewCounter("siem_records_total",
+	"Records streamed to the SIEM, by sink and outcome (sent, dropped, failed).", "sink", "outcome")
+
+// Sink delivers batches of records. Send must be safe to retry: a SIEM may
+// then receive a record twice, never lose one it acknowledged.
+type Sink interface {
+	// Name labels the sink's metrics, e.g. "syslog".
+	Name() string
+	Send(ctx context.Context, records [][]byte) error
+	Close() error
+}
+
+type StreamerArgs struct {
+	Sink Sink
+	// Buffer is how many records may wait to be sent before new ones drop.
+	Buffer    int
+	BatchSize int
+	// FlushInterval is the longest a record waits for its batch to fill.
+	FlushInterval time.Duration
+	// SendTimeout bounds each attempt at sending a batch.
+	SendTimeout time.Duration
+	// Retry is how a failed batch is retried; it is dropped after that.
+	Retry retry.Policy
+}
+
+// Streamer sends the records published to it from a single goroutine.
+// While a batch is being retried, new records queue up, and drop once
+// Buffer is full.
+type Streamer struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	sendTimeout   time.Duration
+	retry         retry.Policy
+	queue         chan []byte
+	done          chan struct{}
+	// stop cancels the retries when Close gives up waiting.
+	ctx  context.Context
+	stop context.CancelFunc
+	// mu guards closed, so Publish never sends on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewStreamer(args StreamerArgs) *Streamer {
+	ctx, stop := context.WithCancel(context.Background())
+	s := &Streamer{
+		sink:          args.Sink,
+		batchSize:     max(args.BatchSize, 1),
+		flushInterval: args.FlushInterval,
+		sendTimeout:   args.SendTimeout,
+		retry:         args.Retry,
+		queue:         make(chan []byte, args.Buffer),
+		done:          make(chan struct{}),
+		ctx:           ctx,
+		stop:          stop,
+	}
+	go s.run()
+	return s
+}
+
+// Publish queues record, dropping it when the queue is full.
+func (s *Streamer) Publish(record []byte) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		recordsTotal.Inc(s.sink.Name(), "dropped")
+		return
+	}
+	select {
+	case s.queue <- record:
+	default:
+		recordsTotal.Inc(s.sink.Name(), "dropped")
+	}
+}
+
+// Pending returns how many records are queued, not counting the batch
+// being filled or sent.
+func (s *Streamer) Pending() int {
+	return len(s.queue)
+}
+
+// Close stops accepting records, sends the queued ones and closes the sink,
+// waiting until ctx is done at most; the retries in progress are then
+// abandoned.
+func (s *Streamer) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return s.sink.Close()
+	case <-ctx.Done():
+		s.stop()
+		<-s.done
+		s.sink.Close()
+		return ctx.Err()
+	}
+}
+
+func (s *Streamer) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.batchSize)
+	for {
+		select {
+		case r, ok := <-s.queue:
+			if !ok {
+				s.send(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) < s.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.send(batch)
+		batch = batch[:0]
+	}
+}
+
+func (s *Streamer) send(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	err := retry.Do(s.ctx, s.retry, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+		defer cancel()
+		return s.sink.Send(ctx, batch)
+	})
+	if err != nil {
+		logger.Sampled("siem.send", 100).Warnw("stream records to siem", "sink", s.sink.Name(), "records", len(batch), "error", err)
+		recordsTotal.Add(float64(len(batch)), s.sink.Name(), "failed")
+		return
+	}
+	recordsTotal.Add(float64(len(batch)), s.sink.Name(), "sent")
+}
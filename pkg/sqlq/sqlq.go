@@ -0,0 +1,172 @@
+// Package sqlq builds the parameterized SELECTs of list and search queries.
+// Values only ever travel as query arguments, and identifiers come from the
+// code or, for sort keys read from requests, from a whitelist, so request
+// input cannot reach the SQL text. Placeholders are written as "?" and
+// numbered for PostgreSQL when the query is built, so the SQL given must
+// not contain a "?" of its own, such as the jsonb operators.
+package sqlq
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownSort = errors.New("unknown sort key")
+
+// Filter adds the conditions of a typed filter struct to a query.
+type Filter interface {
+	Apply(q *Select)
+}
+
+// Select is a SELECT under construction; the zero value is not usable.
+type Select struct {
+	table   string
+	columns string
+	where   []string
+	args    []any
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+// From starts a SELECT of columns from table.
+func From(table, columns string) *Select {
+	return &Select{table: table, columns: columns}
+}
+
+// Where adds a condition, ANDed with the others, with one arg per "?".
+func (q *Select) Where(cond string, args ...any) *Select {
+	if n := strings.Count(cond, "?"); n != len(args) {
+		panic(fmt.Sprintf("sqlq: %q takes %d args, got %d", cond, n, len(args)))
+	}
+	q.where = append(q.where, cond)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// WhereIf adds the condition only when ok, typically when the filter field
+// is set.
+func (q *Select) WhereIf(ok bool, cond string, args ...any) *Select {
+	if ok {
+		q.Where(cond, args...)
+	}
+	return q
+}
+
+// Filter applies f.
+func (q *Select) Filter(f Filter) *Select {
+	f.Apply(q)
+	return q
+}
+
+// OrderBy sets the ORDER BY terms, e.g. from Sorts.Parse.
+func (q *Select) OrderBy(terms ...string) *Select {
+	q.orderBy = terms
+	return q
+}
+
+// Page limits the rows to limit, after skipping offset; a limit of 0 or
+// less returns every row.
+func (q *Select) Page(limit, offset int) *Select {
+	q.limit, q.offset = limit, max(offset, 0)
+	return q
+}
+
+// SQL returns the query and its args.
+func (q *Select) SQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString("SELECT " + q.columns + " FROM " + q.table)
+	q.writeWhere(&b)
+	args := q.args
+	if len(q.orderBy) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+	if q.limit > 0 {
+		b.WriteString(" LIMIT ? OFFSET ?")
+		args = append(args[:len(args):len(args)], q.limit, q.offset)
+	}
+	return number(b.String()), args
+}
+
+// CountSQL returns the query counting every row the filters match,
+// regardless of the page.
+func (q *Select) CountSQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString("SELECT count(*) FROM " + q.table)
+	q.writeWhere(&b)
+	return number(b.String()), q.args
+}
+
+func (q *Select) writeWhere(b *strings.Builder) {
+	if len(q.where) == 0 {
+		return
+	}
+	b.WriteString(" WHERE ")
+	for i, cond := range q.where {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		b.WriteString("(" + cond + ")")
+	}
+}
+
+// number turns the "?" placeholders into $1, $2, ...
+func number(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Sorts maps the sort keys a list accepts to their SQL expressions, e.g.
+// {"created_at": "created_at", "email": "lower(email)"}.
+type Sorts struct {
+	Keys map[string]string
+	// Default is the sort used when none is asked for, in the same syntax.
+	Default string
+	// TieBreak is a unique column appended to every sort, so rows with equal
+	// keys keep their order from one page to the next.
+	TieBreak string
+}
+
+// Parse turns a comma-separated list of sort keys, each prefixed with "-"
+// for descending order, into ORDER BY terms. A key not in Keys fails with
+// ErrUnknownSort.
+func (s Sorts) Parse(sort string) ([]string, error) {
+	if strings.TrimSpace(sort) == "" {
+		sort = s.Default
+	}
+	var terms []string
+	tieBreakDir := " ASC"
+	for key := range strings.SplitSeq(sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		dir := " ASC"
+		if name, ok := strings.CutPrefix(key, "-"); ok {
+			key, dir = name, " DESC"
+		}
+		expr, ok := s.Keys[key]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownSort, key)
+		}
+		if len(terms) == 0 {
+			tieBreakDir = dir
+		}
+		terms = append(terms, expr+dir)
+	}
+	if s.TieBreak != "" {
+		terms = append(terms, s.TieBreak+tieBreakDir)
+	}
+	return terms, nil
+}
@@ -0,0 +1,381 @@
+// Package sqltest gives integration tests a *sql.DB whose changes are rolled
+// back when the test ends, which is much faster than truncating the tables
+// between tests:
+//
+//	db := sqltest.Open(t)
+//	repo := infrastructure.NewPostgresSessionRepository(db)
+//
+// Every statement runs on one connection, inside a transaction opened for
+// the test. The transactions the code under test begins become savepoints
+// of it, so code that commits works unchanged and sees its own writes.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	// Open's default driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Open is TxDB on the database at TEST_DATABASE_URL, with the driver named
+// by TEST_DATABASE_DRIVER, "pgx" by default. It skips t when
+// TEST_DATABASE_URL is unset.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	driverName := os.Getenv("TEST_DATABASE_DRIVER")
+	if driverName == "" {
+		driverName = "pgx"
+	}
+	return TxDB(t, driverName, dsn)
+}
+
+// TxDB opens one connection to dsn with the driver registered as
+// driverName, begins a transaction on it and returns a *sql.DB that runs
+// everything in that transaction, rolled back in t's cleanup.
+//
+// Statements are serialized on the connection and their rows read in full
+// before they are returned. A statement run outside a transaction gets a
+// savepoint of its own, so that an error, which in Postgres aborts the
+// transaction it happens in, only undoes that statement, as it would with
+// autocommit. The isolation level and read-only options of the
+// transactions begun by the code under test are ignored.
+func TxDB(t testing.TB, driverName, dsn string) *sql.DB {
+	t.Helper()
+	base, err := sql.Open(driverName, dsn)
+	if err != nil {
+		t.Fatalf("sqltest: %v", err)
+	}
+	raw, err := base.Driver().Open(dsn)
+	base.Close()
+	if err != nil {
+		t.Fatalf("sqltest: connect: %v", err)
+	}
+
+	s := &shared{conn: raw}
+	if _, err := s.exec(context.Background(), "BEGIN", nil); err != nil {
+		raw.Close()
+		t.Fatalf("sqltest: begin: %v", err)
+	}
+	db := sql.OpenDB(&connector{shared: s})
+	t.Cleanup(func() {
+		db.Close()
+		if err := s.close(); err != nil {
+			t.Errorf("sqltest: roll back: %v", err)
+		}
+	})
+	return db
+}
+
+// shared is the connection every driver.Conn handed to database/sql wraps.
+type shared struct {
+	mu   sync.Mutex
+	conn driver.Conn
+	// depth is how many transactions of the code under test are open.
+	depth int
+	// savepoints numbers them, so each has a unique name.
+	savepoints int
+}
+
+func (s *shared) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.exec(context.Background(), "ROLLBACK", nil)
+	if cerr := s.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// guarded runs fn in a savepoint unless a transaction is open; s.mu must
+// be held.
+func (s *shared) guarded(ctx context.Context, fn func() error) error {
+	if s.depth > 0 {
+		return fn()
+	}
+	if _, err := s.exec(ctx, "SAVEPOINT sqltest_statement", nil); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if rerr := s.rollbackTo(ctx, "sqltest_statement"); rerr != nil {
+			return fmt.Errorf("%w (and roll back: %v)", err, rerr)
+		}
+		return err
+	}
+	_, err := s.exec(ctx, "RELEASE SAVEPOINT sqltest_statement", nil)
+	return err
+}
+
+// rollbackTo undoes the savepoint name and removes it, which ROLLBACK TO
+// alone does not; s.mu must be held.
+func (s *shared) rollbackTo(ctx context.Context, name string) error {
+	if _, err := s.exec(ctx, "ROLLBACK TO SAVEPOINT "+name, nil); err != nil {
+		return err
+	}
+	_, err := s.exec(ctx, "RELEASE SAVEPOINT "+name, nil)
+	return err
+}
+
+// exec runs query on the connection; s.mu must be held.
+func (s *shared) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := s.conn.(driver.ExecerContext); ok {
+		res, err := e.ExecContext(ctx, query, args)
+		if err != driver.ErrSkip {
+			return res, err
+		}
+	}
+	stmt, err := s.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	if sc, ok := stmt.(driver.StmtExecContext); ok {
+		return sc.ExecContext(ctx, args)
+	}
+	return stmt.Exec(values(args))
+}
+
+// query runs query on the connection and reads its rows in full; s.mu must
+// be held.
+func (s *shared) query(ctx context.Context, query string, args []driver.NamedValue) (*rows, error) {
+	var (
+		rs  driver.Rows
+		err error
+	)
+	if q, ok := s.conn.(driver.QueryerContext); ok {
+		rs, err = q.QueryContext(ctx, query, args)
+	} else {
+		err = driver.ErrSkip
+	}
+	if err == driver.ErrSkip {
+		var stmt driver.Stmt
+		if stmt, err = s.prepare(ctx, query); err != nil {
+			return nil, err
+		}
+		defer stmt.Close()
+		if sc, ok := stmt.(driver.StmtQueryContext); ok {
+			rs, err = sc.QueryContext(ctx, args)
+		} else {
+			rs, err = stmt.Query(values(args))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+	return readRows(rs)
+}
+
+func (s *shared) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := s.conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return s.conn.Prepare(query)
+}
+
+type connector struct {
+	shared *shared
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{shared: c.shared}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return txDriver{}
+}
+
+// txDriver only exists for connector.Driver; connections come from
+// Connect.
+type txDriver struct{}
+
+func (txDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("sqltest: open connections with TxDB")
+}
+
+// conn is one of database/sql's connections, all running on the shared
+// one. Closing it leaves the shared connection open.
+type conn struct {
+	shared *shared
+}
+
+var (
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{shared: c.shared, query: query}, nil
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{shared: c.shared, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	s := c.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savepoints++
+	name := fmt.Sprintf("sqltest_tx_%d", s.savepoints)
+	if _, err := s.exec(ctx, "SAVEPOINT "+name, nil); err != nil {
+		return nil, err
+	}
+	s.depth++
+	return &tx{shared: s, name: name}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res driver.Result
+	err := s.guarded(ctx, func() (err error) {
+		res, err = s.exec(ctx, query, args)
+		return err
+	})
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rs *rows
+	err := s.guarded(ctx, func() (err error) {
+		rs, err = s.query(ctx, query, args)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// CheckNamedValue lets the driver convert the arguments it knows, such as
+// its own types.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.shared.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	shared *shared
+	query  string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return (&conn{shared: s.shared}).ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return (&conn{shared: s.shared}).QueryContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return (&conn{shared: s.shared}).ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return (&conn{shared: s.shared}).QueryContext(ctx, s.query, args)
+}
+
+type tx struct {
+	shared *shared
+	name   string
+}
+
+func (t *tx) Commit() error {
+	s := t.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depth--
+	_, err := s.exec(context.Background(), "RELEASE SAVEPOINT "+t.name, nil)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	s := t.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depth--
+	return s.rollbackTo(context.Background(), t.name)
+}
+
+// rows are a result read in full, so the shared connection is free for the
+// next statement while the caller scans them.
+type rows struct {
+	columns []string
+	data    [][]driver.Value
+}
+
+func readRows(rs driver.Rows) (*rows, error) {
+	r := &rows{columns: rs.Columns()}
+	for {
+		dest := make([]driver.Value, len(r.columns))
+		if err := rs.Next(dest); err == io.EOF {
+			return r, nil
+		} else if err != nil {
+			return nil, err
+		}
+		// Drivers may reuse the buffers of byte slices between rows.
+		for i, v := range dest {
+			if b, ok := v.([]byte); ok {
+				dest[i] = append([]byte(nil), b...)
+			}
+		}
+		r.data = append(r.data, dest)
+	}
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	out := make([]driver.Value, len(args))
+	for i, a := range args {
+		out[i] = a.Value
+	}
+	return out
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return out
+}
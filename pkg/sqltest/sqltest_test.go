@@ -0,0 +1,168 @@
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a driver that records the statements it is given. A
+// statement starting with FAIL fails, and every query returns one row.
+type recorder struct {
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &recorderConn{r: r}, nil }
+
+func (r *recorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stmts := r.stmts
+	r.stmts = nil
+	return stmts
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("recorder: prepare")
+}
+func (c *recorderConn) Close() error              { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) { return nil, errors.New("recorder: begin") }
+
+func (c *recorderConn) record(query string) error {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.stmts = append(c.r.stmts, query)
+	if strings.HasPrefix(query, "FAIL") {
+		return errors.New("recorder: failed")
+	}
+	return nil
+}
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recorderConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return &recorderRows{left: 1}, nil
+}
+
+type recorderRows struct{ left int }
+
+func (r *recorderRows) Columns() []string { return []string{"n"} }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	r.left--
+	dest[0] = []byte("1")
+	return nil
+}
+
+var (
+	registerOnce sync.Once
+	rec          = &recorder{}
+)
+
+func openRecorded(t *testing.T) *sql.DB {
+	registerOnce.Do(func() { sql.Register("sqltest_recorder", rec) })
+	rec.take()
+	return TxDB(t, "sqltest_recorder", "")
+}
+
+func assertStatements(t *testing.T, want ...string) {
+	t.Helper()
+	if got := rec.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements =\n\t%s\nwant\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestTxDBRollsBackTheTest(t *testing.T) {
+	t.Run("test", func(t *testing.T) {
+		db := openRecorded(t)
+		if _, err := db.Exec("INSERT 1"); err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		if err := db.QueryRow("SELECT 1").Scan(&n); err != nil || n != 1 {
+			t.Fatalf("scan = %d, %v; want 1", n, err)
+		}
+	})
+	assertStatements(t,
+		"BEGIN",
+		"SAVEPOINT sqltest_statement", "INSERT 1", "RELEASE SAVEPOINT sqltest_statement",
+		"SAVEPOINT sqltest_statement", "SELECT 1", "RELEASE SAVEPOINT sqltest_statement",
+		"ROLLBACK",
+	)
+}
+
+func TestTxDBUndoesAFailedStatement(t *testing.T) {
+	db := openRecorded(t)
+	if _, err := db.Exec("FAIL"); err == nil {
+		t.Fatal("the failing statement succeeded")
+	}
+	assertStatements(t,
+		"BEGIN",
+		"SAVEPOINT sqltest_statement", "FAIL",
+		"ROLLBACK TO SAVEPOINT sqltest_statement", "RELEASE SAVEPOINT sqltest_statement",
+	)
+}
+
+func TestTxDBTurnsTransactionsIntoSavepoints(t *testing.T) {
+	db := openRecorded(t)
+	rec.take()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT 2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	assertStatements(t,
+		"SAVEPOINT sqltest_tx_1", "INSERT 1", "RELEASE SAVEPOINT sqltest_tx_1",
+		"SAVEPOINT sqltest_tx_2", "INSERT 2",
+		"ROLLBACK TO SAVEPOINT sqltest_tx_2", "RELEASE SAVEPOINT sqltest_tx_2",
+	)
+}
+
+func TestOpenSkipsWithoutADatabase(t *testing.T) {
+	t.Setenv("TEST_DATABASE_URL", "")
+	skipped := true
+	t.Run("open", func(t *testing.T) {
+		Open(t)
+		skipped = false
+	})
+	if !skipped {
+		t.Error("Open ran without TEST_DATABASE_URL")
+	}
+}
@@ -0,0 +1,36 @@
+package trace
+
+import (
+	"go.uber.org/zap"
+)
+
+// LogExporter writes every span as a structured log line, for development
+// and for log pipelines that assemble traces themselves.
+type LogExporter struct {
+	logger *zap.SugaredLogger
+}
+
+var _ Exporter = (*LogExporter)(nil)
+
+func NewLogExporter(logger *zap.SugaredLogger) *LogExporter {
+	return &LogExporter{logger: logger}
+}
+
+func (e *LogExporter) Export(s SpanData) {
+	fields := make([]any, 0, 10+2*len(s.Attrs))
+	fields = append(fields,
+		"trace_id", s.Context.TraceID.String(),
+		"span_id", s.Context.SpanID.String(),
+		"duration", s.Duration,
+	)
+	if s.ParentID != (SpanID{}) {
+		fields = append(fields, "parent_id", s.ParentID.String())
+	}
+	if s.Err != nil {
+		fields = append(fields, "error", s.Err.Error())
+	}
+	for _, a := range s.Attrs {
+		fields = append(fields, a.Key, a.Value)
+	}
+	e.logger.Infow("span "+s.Name, fields...)
+}
@@ -0,0 +1,225 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/ctxutil"
+)
+
+// TRACEPARENT_HEADER carries the W3C trace context between processes.
+const TRACEPARENT_HEADER = "traceparent"
+
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext identifies a span across process and goroutine boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a W3C traceparent header value.
+func ParseTraceparent(h string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// SpanData is a finished span as handed to the exporter.
+type SpanData struct {
+	Name     string
+	Context  SpanContext
+	ParentID SpanID
+	Start    time.Time
+	Duration time.Duration
+	Attrs    []Attr
+	Err      error
+}
+
+// Exporter receives sampled spans as they end. It must not block.
+type Exporter interface {
+	Export(s SpanData)
+}
+
+type config struct {
+	exporter    Exporter
+	sampleRatio float64
+}
+
+var current atomic.Pointer[config]
+
+// Configure sets where sampled spans go and the fraction of new traces that
+// are sampled. Traces continued from a parent keep the parent's decision.
+func Configure(exporter Exporter, sampleRatio float64) {
+	current.Store(&config{exporter: exporter, sampleRatio: math.Max(0, math.Min(1, sampleRatio))})
+}
+
+// Span is one timed operation. A nil *Span is valid and does nothing.
+type Span struct {
+	name   string
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu    sync.Mutex
+	attrs []Attr
+	err   error
+	ended bool
+}
+
+var (
+	spanKey   = ctxutil.NewKey[*Span]("trace_span")
+	remoteKey = ctxutil.NewKey[SpanContext]("trace_remote")
+)
+
+// Start starts a span as a child of the one in ctx, or of the remote parent
+// set with WithRemoteParent, or else as the root of a new trace.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanContextFrom(ctx)
+	s := &Span{name: name, start: time.Now()}
+	if parent.IsValid() {
+		s.sc.TraceID = parent.TraceID
+		s.sc.Sampled = parent.Sampled
+		s.parent = parent.SpanID
+	} else {
+		rand.Read(s.sc.TraceID[:])
+		s.sc.Sampled = sample(s.sc.TraceID)
+	}
+	rand.Read(s.sc.SpanID[:])
+	return ctxutil.With(ctx, spanKey, s), s
+}
+
+// sample decides from the trace ID, so every process reaches the same
+// decision for a trace without coordinating.
+func sample(id TraceID) bool {
+	cfg := current.Load()
+	if cfg == nil || cfg.sampleRatio <= 0 {
+		return false
+	}
+	var n uint64
+	for _, b := range id[8:] {
+		n = n<<8 | uint64(b)
+	}
+	return float64(n>>11)/(1<<53) < cfg.sampleRatio
+}
+
+// SpanContextFrom returns the context of the active span in ctx, falling
+// back to its remote parent.
+func SpanContextFrom(ctx context.Context) SpanContext {
+	if s, ok := ctxutil.Get(ctx, spanKey); ok && s != nil {
+		return s.sc
+	}
+	sc, _ := ctxutil.Get(ctx, remoteKey)
+	return sc
+}
+
+// WithRemoteParent makes sc the parent of the next span started from ctx.
+// It joins traces coming from other processes or across async boundaries.
+func WithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return ctxutil.With(ctx, remoteKey, sc)
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, Attr{Key: key, Value: value})
+}
+
+// RecordError marks the span failed; a nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End finishes the span and exports it when sampled. Later calls do
+// nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:     s.name,
+		Context:  s.sc,
+		ParentID: s.parent,
+		Start:    s.start,
+		Duration: time.Since(s.start),
+		Attrs:    s.attrs,
+		Err:      s.err,
+	}
+	s.mu.Unlock()
+
+	cfg := current.Load()
+	if s.sc.Sampled && cfg != nil && cfg.exporter != nil {
+		cfg.exporter.Export(data)
+	}
+}
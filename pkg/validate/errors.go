@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is one failed rule.
+type FieldError struct {
+	// Field is the JSON path of the value, e.g. "scopes[2]".
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors lists every failed rule of a validation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) ErrorCode() string {
+	return "validation_failed"
+}
+
+// ErrorDetails lets clients highlight the offending fields.
+func (e Errors) ErrorDetails() map[string]any {
+	return map[string]any{"fields": []FieldError(e)}
+}
+
+// messages are formats taking the field name and the rule's parameter.
+var messages = map[string]string{
+	"required":         "%s is required",
+	"email":            "%s must be a valid email address",
+	"url":              "%s must be a valid URL",
+	"fqdn":             "%s must be a valid domain name",
+	"uuid":             "%s must be a valid UUID",
+	"numeric":          "%s must contain only digits",
+	"oneof":            "%s must be one of: %s",
+	"printascii":       "%s must contain only printable ASCII characters",
+	"excludesall":      "%s must not contain any of %q",
+	"exactly_one_of":   "exactly one of %s or %s is required",
+	"strong_password":  "%s must be 8 to 72 characters with at least one letter and one digit",
+	"e164_phone":       "%s must be an international phone number, e.g. +14155550100",
+	"username":         "%s must be 3 to 30 letters, digits, '_', '.' or '-', starting with a letter",
+	"no_control_chars": "%s must not contain control characters",
+}
+
+var messagesMu sync.RWMutex
+
+// RegisterMessage sets the message for rule, a format taking the field name
+// and the rule's parameter. Call it from init.
+func RegisterMessage(rule, format string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[rule] = format
+}
+
+func message(field string, fe validator.FieldError) string {
+	if field == "" {
+		field = "value"
+	}
+	switch fe.Tag() {
+	case "min", "max", "len":
+		return sizeMessage(field, fe)
+	}
+
+	messagesMu.RLock()
+	format, ok := messages[fe.Tag()]
+	messagesMu.RUnlock()
+	if !ok {
+		return field + " is invalid"
+	}
+	if strings.Count(format, "%") < 2 {
+		return fmt.Sprintf(format, field)
+	}
+	return fmt.Sprintf(format, field, fe.Param())
+}
+
+func sizeMessage(field string, fe validator.FieldError) string {
+	bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fe.Tag()]
+	switch fe.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%s must be %s %s characters", field, bound, fe.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("%s must have %s %s items", field, bound, fe.Param())
+	default:
+		return fmt.Sprintf("%s must be %s %s", field, bound, fe.Param())
+	}
+}
@@ -0,0 +1,62 @@
+package validate
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MAX_PASSWORD_BYTES is bcrypt's input limit; longer passwords would be
+// silently truncated.
+const MAX_PASSWORD_BYTES = 72
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]{2,29}$`)
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+var rules = map[string]validator.Func{
+	"strong_password":  strongPassword,
+	"e164_phone":       e164Phone,
+	"username":         username,
+	"no_control_chars": noControlChars,
+}
+
+// strongPassword requires 8 to MAX_PASSWORD_BYTES bytes with at least one
+// letter and one digit.
+func strongPassword(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if len(s) < 8 || len(s) > MAX_PASSWORD_BYTES {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0 && strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// e164Phone accepts an international number, ignoring the separators people
+// type (spaces, '-', '.', parentheses) and a leading "00" for '+'.
+func e164Phone(fl validator.FieldLevel) bool {
+	s := strings.TrimSpace(fl.Field().String())
+	if rest, ok := strings.CutPrefix(s, "00"); ok {
+		s = "+" + rest
+	}
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, s)
+	return e164Pattern.MatchString(s)
+}
+
+// username matches the shape of usernames before normalization; reserved
+// names are the domain's concern.
+func username(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+func noControlChars(fl validator.FieldLevel) bool {
+	return strings.IndexFunc(fl.Field().String(), unicode.IsControl) < 0
+}
@@ -0,0 +1,79 @@
+// Package validate wraps a shared go-playground validator with the custom
+// rules the API uses and turns its errors into messages clients can show.
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// StructLevel is passed to struct-level validations; report failures with
+// sl.ReportError(value, jsonName, fieldName, rule, param).
+type StructLevel = validator.StructLevel
+
+var instance = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report fields by the names clients sent them under: JSON fields, or
+	// the query and form parameters of structs bound by pkg/http/request.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "form"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return ""
+	})
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// Struct validates the `validate` tags of s and the struct-level validations
+// registered for its type, returning every failure as Errors.
+func Struct(s any) error {
+	return translate(instance.Struct(s), "")
+}
+
+// Var validates a single value against tag; field names it in messages.
+func Var(field string, value any, tag string) error {
+	return translate(instance.Var(value, tag), field)
+}
+
+// RegisterStructValidation runs fn whenever a value of one of types is
+// validated, for rules spanning several fields. Call it from init.
+func RegisterStructValidation(fn func(sl StructLevel), types ...any) {
+	instance.RegisterStructValidation(func(sl validator.StructLevel) { fn(sl) }, types...)
+}
+
+func translate(err error, field string) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	out := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		path := field + fe.Namespace()
+		if field == "" {
+			// Drop the root struct's name: "SignUpRequest.email" -> "email".
+			_, path, _ = strings.Cut(fe.Namespace(), ".")
+		}
+		out = append(out, FieldError{
+			Field:   path,
+			Rule:    fe.Tag(),
+			Message: message(path, fe),
+		})
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package xmldsig
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	const doc = `<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns:unused="urn:u" z="1" b:y="2" a="&amp;&quot;">` +
+		`<child/><!-- dropped --><drop>x</drop>text &lt; &gt;&#xD;</a:root>`
+	root, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	drop := root.Element("", "drop")
+
+	for _, tc := range []struct {
+		name      string
+		exclude   *Element
+		inclusive []string
+		want      string
+	}{
+		{
+			name: "visibly used namespaces only",
+			want: `<a:root xmlns:a="urn:a" xmlns:b="urn:b" a="&amp;&quot;" z="1" b:y="2">` +
+				`<child></child><drop>x</drop>text &lt; &gt;&#xD;</a:root>`,
+		},
+		{
+			name:      "inclusive prefix",
+			inclusive: []string{"unused"},
+			want: `<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns:unused="urn:u" a="&amp;&quot;" z="1" b:y="2">` +
+				`<child></child><drop>x</drop>text &lt; &gt;&#xD;</a:root>`,
+		},
+		{
+			name:    "excluded element",
+			exclude: drop,
+			want: `<a:root xmlns:a="urn:a" xmlns:b="urn:b" a="&amp;&quot;" z="1" b:y="2">` +
+				`<child></child>text &lt; &gt;&#xD;</a:root>`,
+		},
+	} {
+		if got := string(Canonicalize(root, tc.exclude, tc.inclusive)); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// A nested element renders the namespaces it inherits, so its form does not
+// depend on where in a document it sits.
+func TestCanonicalizeSubtree(t *testing.T) {
+	for _, doc := range []string{
+		`<outer xmlns="urn:outer" xmlns:s="urn:s"><s:signed ID="_1"><s:v>1</s:v></s:signed></outer>`,
+		`<s:signed xmlns:s="urn:s" xmlns:other="urn:other" ID="_1"><s:v>1</s:v></s:signed>`,
+	} {
+		root, err := Parse([]byte(doc))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		e := root
+		if found := root.Element("urn:s", "signed"); found != nil {
+			e = found
+		}
+		const want = `<s:signed xmlns:s="urn:s" ID="_1"><s:v>1</s:v></s:signed>`
+		if got := string(Canonicalize(e, nil, nil)); got != want {
+			t.Errorf("%s:\n got %s\nwant %s", doc, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package xmldsig verifies enveloped XML signatures, as used by SAML. It
+// supports exclusive canonicalization without comments and RSA with SHA-256
+// or SHA-512, which covers what mainstream identity providers emit.
+package xmldsig
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const NS_XML = "http://www.w3.org/XML/1998/namespace"
+
+var ErrMalformed = errors.New("malformed xml document")
+
+// Element is a node of a parsed document. Prefixes are kept as written
+// because canonicalization needs them.
+type Element struct {
+	Prefix string
+	Local  string
+	// NS holds the namespace declarations made on the element; the key ""
+	// is the default namespace.
+	NS       map[string]string
+	Attrs    []Attr
+	Children []any // *Element, Text or ProcInst
+	Parent   *Element
+}
+
+type Attr struct {
+	Prefix string
+	Local  string
+	Value  string
+}
+
+type Text string
+
+type ProcInst struct {
+	Target string
+	Inst   string
+}
+
+// Parse reads a document and returns its root element. Comments are
+// dropped; document type declarations are rejected.
+func Parse(data []byte) (*Element, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = true
+
+	var root, cur *Element
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if cur == nil && root != nil {
+				return nil, fmt.Errorf("%w: multiple root elements", ErrMalformed)
+			}
+			el := &Element{Prefix: t.Name.Space, Local: t.Name.Local, Parent: cur}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					el.declare(a.Name.Local, a.Value)
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.declare("", a.Value)
+				default:
+					el.Attrs = append(el.Attrs, Attr{Prefix: a.Name.Space, Local: a.Name.Local, Value: a.Value})
+				}
+			}
+			if cur == nil {
+				root = el
+			} else {
+				cur.Children = append(cur.Children, el)
+			}
+			cur = el
+		case xml.EndElement:
+			if cur == nil || t.Name.Space != cur.Prefix || t.Name.Local != cur.Local {
+				return nil, fmt.Errorf("%w: unexpected end element %s", ErrMalformed, t.Name.Local)
+			}
+			cur = cur.Parent
+		case xml.CharData:
+			if cur != nil {
+				cur.Children = append(cur.Children, Text(t))
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("%w: text outside the root element", ErrMalformed)
+			}
+		case xml.ProcInst:
+			if cur != nil {
+				cur.Children = append(cur.Children, ProcInst{Target: t.Target, Inst: string(t.Inst)})
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("%w: document type declarations are not allowed", ErrMalformed)
+		}
+	}
+
+	if root == nil || cur != nil {
+		return nil, fmt.Errorf("%w: incomplete document", ErrMalformed)
+	}
+	return root, nil
+}
+
+func (e *Element) declare(prefix, uri string) {
+	if e.NS == nil {
+		e.NS = make(map[string]string)
+	}
+	e.NS[prefix] = uri
+}
+
+// LookupNamespace resolves prefix in the element's scope.
+func (e *Element) LookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return NS_XML, true
+	}
+	for el := e; el != nil; el = el.Parent {
+		if uri, ok := el.NS[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+// Namespace returns the namespace URI of the element's name.
+func (e *Element) Namespace() string {
+	uri, _ := e.LookupNamespace(e.Prefix)
+	return uri
+}
+
+// Is reports whether the element is named local in namespace.
+func (e *Element) Is(namespace, local string) bool {
+	return e.Local == local && e.Namespace() == namespace
+}
+
+// Attr returns the value of the unprefixed attribute local.
+func (e *Element) Attr(local string) string {
+	for _, a := range e.Attrs {
+		if a.Prefix == "" && a.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Elements returns the child elements named local in namespace.
+func (e *Element) Elements(namespace, local string) []*Element {
+	var found []*Element
+	for _, c := range e.Children {
+		if el, ok := c.(*Element); ok && el.Is(namespace, local) {
+			found = append(found, el)
+		}
+	}
+	return found
+}
+
+// Element returns the first child element named local in namespace, or nil.
+func (e *Element) Element(namespace, local string) *Element {
+	if found := e.Elements(namespace, local); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// Text returns the element's own character data with surrounding space
+// trimmed.
+func (e *Element) Text() string {
+	var sb strings.Builder
+	for _, c := range e.Children {
+		if t, ok := c.(Text); ok {
+			sb.WriteString(string(t))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
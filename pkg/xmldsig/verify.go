@@ -0,0 +1,175 @@
+package xmldsig
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	// Register the hashes looked up through crypto.Hash.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	NS_DSIG        = "http://www.w3.org/2000/09/xmldsig#"
+	NS_EXC_C14N    = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	ALG_ENVELOPED  = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	ALG_RSA_SHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	ALG_RSA_SHA512 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	ALG_SHA256     = "http://www.w3.org/2001/04/xmlenc#sha256"
+	ALG_SHA512     = "http://www.w3.org/2001/04/xmlenc#sha512"
+	ALG_EXC_C14N   = NS_EXC_C14N
+)
+
+var (
+	ErrMissingSignature     = errors.New("element is not signed")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+	ErrInvalidReference     = errors.New("signature does not reference the signed element")
+	ErrDigestMismatch       = errors.New("signed content was modified")
+	ErrInvalidSignature     = errors.New("signature verification failed")
+)
+
+var signatureHashes = map[string]crypto.Hash{
+	ALG_RSA_SHA256: crypto.SHA256,
+	ALG_RSA_SHA512: crypto.SHA512,
+}
+
+var digestHashes = map[string]crypto.Hash{
+	ALG_SHA256: crypto.SHA256,
+	ALG_SHA512: crypto.SHA512,
+}
+
+// Verify checks the enveloped signature that is a direct child of e against
+// cert. Only e itself is covered on success; callers must read the signed
+// data from e rather than from elsewhere in the document.
+func Verify(e *Element, cert *x509.Certificate) error {
+	sigs := e.Elements(NS_DSIG, "Signature")
+	if len(sigs) == 0 {
+		return ErrMissingSignature
+	}
+	if len(sigs) > 1 {
+		return fmt.Errorf("%w: multiple signatures", ErrInvalidReference)
+	}
+	sig := sigs[0]
+
+	signedInfo := sig.Element(NS_DSIG, "SignedInfo")
+	if signedInfo == nil {
+		return fmt.Errorf("%w: missing SignedInfo", ErrMalformed)
+	}
+
+	c14n := signedInfo.Element(NS_DSIG, "CanonicalizationMethod")
+	if c14n == nil || c14n.Attr("Algorithm") != ALG_EXC_C14N {
+		return fmt.Errorf("%w: canonicalization", ErrUnsupportedAlgorithm)
+	}
+	method := signedInfo.Element(NS_DSIG, "SignatureMethod")
+	if method == nil {
+		return fmt.Errorf("%w: missing SignatureMethod", ErrMalformed)
+	}
+	hash, ok := signatureHashes[method.Attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, method.Attr("Algorithm"))
+	}
+
+	if err := verifyReference(e, sig, signedInfo); err != nil {
+		return err
+	}
+
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: certificate key is not RSA", ErrUnsupportedAlgorithm)
+	}
+	sigValue := sig.Element(NS_DSIG, "SignatureValue")
+	if sigValue == nil {
+		return fmt.Errorf("%w: missing SignatureValue", ErrMalformed)
+	}
+	raw, err := decodeBase64(sigValue.Text())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	h := hash.New()
+	h.Write(Canonicalize(signedInfo, nil, inclusivePrefixes(c14n)))
+	if err := rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), raw); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func verifyReference(e, sig, signedInfo *Element) error {
+	refs := signedInfo.Elements(NS_DSIG, "Reference")
+	if len(refs) != 1 {
+		return fmt.Errorf("%w: expected exactly one reference", ErrInvalidReference)
+	}
+	ref := refs[0]
+
+	id := e.Attr("ID")
+	if id == "" || ref.Attr("URI") != "#"+id {
+		return ErrInvalidReference
+	}
+
+	var (
+		enveloped bool
+		c14n      *Element
+	)
+	if transforms := ref.Element(NS_DSIG, "Transforms"); transforms != nil {
+		for _, t := range transforms.Elements(NS_DSIG, "Transform") {
+			switch t.Attr("Algorithm") {
+			case ALG_ENVELOPED:
+				enveloped = true
+			case ALG_EXC_C14N:
+				c14n = t
+			default:
+				return fmt.Errorf("%w: transform %s", ErrUnsupportedAlgorithm, t.Attr("Algorithm"))
+			}
+		}
+	}
+	if !enveloped || c14n == nil {
+		return fmt.Errorf("%w: reference must use enveloped exclusive canonicalization", ErrUnsupportedAlgorithm)
+	}
+
+	method := ref.Element(NS_DSIG, "DigestMethod")
+	value := ref.Element(NS_DSIG, "DigestValue")
+	if method == nil || value == nil {
+		return fmt.Errorf("%w: missing digest", ErrMalformed)
+	}
+	hash, ok := digestHashes[method.Attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, method.Attr("Algorithm"))
+	}
+	want, err := decodeBase64(value.Text())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	h := hash.New()
+	h.Write(Canonicalize(e, sig, inclusivePrefixes(c14n)))
+	if subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+		return ErrDigestMismatch
+	}
+	return nil
+}
+
+func inclusivePrefixes(method *Element) []string {
+	for _, c := range method.Children {
+		if el, ok := c.(*Element); ok && el.Is(NS_EXC_C14N, "InclusiveNamespaces") {
+			return strings.Fields(el.Attr("PrefixList"))
+		}
+	}
+	return nil
+}
+
+// decodeBase64 accepts the line-wrapped base64 signers commonly emit.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	return base64.StdEncoding.DecodeString(s)
+}
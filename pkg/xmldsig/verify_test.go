@@ -0,0 +1,91 @@
+package xmldsig_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/haidang666/go-app/pkg/xmldsig"
+	"github.com/haidang666/go-app/pkg/xmldsig/xmldsigtest"
+)
+
+const fixture = `<r:root xmlns:r="urn:root">` +
+	`<d:data xmlns:d="urn:data" ID="_data"><d:name>alice</d:name></d:data>` +
+	`</r:root>`
+
+// signedData parses doc and returns its data element.
+func signedData(t *testing.T, doc []byte) *xmldsig.Element {
+	t.Helper()
+	root, err := xmldsig.Parse(doc)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return root.Element("urn:data", "data")
+}
+
+func TestVerify(t *testing.T) {
+	key, cert := xmldsigtest.NewCertificate(t)
+	_, otherCert := xmldsigtest.NewCertificate(t)
+	signed := xmldsigtest.Sign(t, []byte(fixture), "_data", key)
+
+	for _, tc := range []struct {
+		name string
+		doc  []byte
+		cert bool
+		want error
+	}{
+		{name: "valid", doc: signed},
+		{name: "unsigned", doc: []byte(fixture), want: xmldsig.ErrMissingSignature},
+		{name: "wrong certificate", doc: signed, cert: true, want: xmldsig.ErrInvalidSignature},
+		{
+			name: "content changed",
+			doc:  bytes.Replace(signed, []byte("alice"), []byte("mallory"), 1),
+			want: xmldsig.ErrDigestMismatch,
+		},
+		{
+			// The signature still names _data, which is no longer the
+			// element it sits in.
+			name: "signature moved to another element",
+			doc:  bytes.Replace(signed, []byte(`ID="_data"`), []byte(`ID="_evil"`), 1),
+			want: xmldsig.ErrInvalidReference,
+		},
+		{
+			name: "two signatures",
+			doc:  xmldsigtest.Sign(t, signed, "_data", key),
+			want: xmldsig.ErrInvalidReference,
+		},
+		{
+			name: "non-enveloped reference",
+			doc:  bytes.Replace(signed, []byte(xmldsig.ALG_ENVELOPED), []byte("urn:other"), 1),
+			want: xmldsig.ErrUnsupportedAlgorithm,
+		},
+	} {
+		c := cert
+		if tc.cert {
+			c = otherCert
+		}
+		if err := xmldsig.Verify(signedData(t, tc.doc), c); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// An exclusively canonicalized element keeps verifying when moved to a
+// document with other namespace declarations around it.
+func TestVerifyMovedElement(t *testing.T) {
+	key, cert := xmldsigtest.NewCertificate(t)
+	signed := xmldsigtest.Sign(t, []byte(fixture), "_data", key)
+
+	start := bytes.Index(signed, []byte("<d:data"))
+	end := bytes.Index(signed, []byte("</d:data>")) + len("</d:data>")
+	moved := []byte(`<other xmlns="urn:other" xmlns:x="urn:x"><x:wrap>` + string(signed[start:end]) + `</x:wrap></other>`)
+
+	root, err := xmldsig.Parse(moved)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	data := root.Element("urn:x", "wrap").Element("urn:data", "data")
+	if err := xmldsig.Verify(data, cert); err != nil {
+		t.Errorf("verify moved element: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package xmldsigtest signs XML fixtures for the tests of code that
+// verifies them with xmldsig:
+//
+//	key, cert := xmldsigtest.NewCertificate(t)
+//	doc = xmldsigtest.Sign(t, doc, "_assertion", key)
+//
+// The signatures are enveloped, use exclusive canonicalization and RSA with
+// SHA-256, and so are what xmldsig.Verify accepts.
+package xmldsigtest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/haidang666/go-app/pkg/xmldsig"
+)
+
+// NewCertificate returns a fresh key and a self-signed certificate for it.
+func NewCertificate(t testing.TB) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "xmldsigtest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return key, cert
+}
+
+// PEM encodes cert the way identity provider certificates are configured.
+func PEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// Sign adds an enveloped signature over the element whose ID attribute is
+// id, as its first child, and returns the new document. Elements signed
+// earlier stay valid only if they do not contain this one.
+func Sign(t testing.TB, doc []byte, id string, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	root, err := xmldsig.Parse(doc)
+	if err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+	el := findByID(root, id)
+	if el == nil {
+		t.Fatalf("fixture has no element with ID %q", id)
+	}
+	digest := sha256.Sum256(xmldsig.Canonicalize(el, nil, nil))
+
+	signedInfo := fmt.Sprintf(`<ds:SignedInfo xmlns:ds="%s">`+
+		`<ds:CanonicalizationMethod Algorithm="%s"></ds:CanonicalizationMethod>`+
+		`<ds:SignatureMethod Algorithm="%s"></ds:SignatureMethod>`+
+		`<ds:Reference URI="#%s"><ds:Transforms>`+
+		`<ds:Transform Algorithm="%s"></ds:Transform>`+
+		`<ds:Transform Algorithm="%s"></ds:Transform>`+
+		`</ds:Transforms>`+
+		`<ds:DigestMethod Algorithm="%s"></ds:DigestMethod>`+
+		`<ds:DigestValue>%s</ds:DigestValue>`+
+		`</ds:Reference></ds:SignedInfo>`,
+		xmldsig.NS_DSIG, xmldsig.ALG_EXC_C14N, xmldsig.ALG_RSA_SHA256, id,
+		xmldsig.ALG_ENVELOPED, xmldsig.ALG_EXC_C14N, xmldsig.ALG_SHA256,
+		base64.StdEncoding.EncodeToString(digest[:]))
+	parsed, err := xmldsig.Parse([]byte(signedInfo))
+	if err != nil {
+		t.Fatalf("parse SignedInfo: %v", err)
+	}
+	sum := sha256.Sum256(xmldsig.Canonicalize(parsed, nil, nil))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	signature := fmt.Sprintf(`<ds:Signature xmlns:ds="%s">%s<ds:SignatureValue>%s</ds:SignatureValue></ds:Signature>`,
+		xmldsig.NS_DSIG, signedInfo, base64.StdEncoding.EncodeToString(sig))
+
+	attr := []byte(`ID="` + id + `"`)
+	at := bytes.Index(doc, attr)
+	if at < 0 {
+		t.Fatalf("fixture must write the ID as %s", attr)
+	}
+	end := at + bytes.IndexByte(doc[at:], '>')
+	if doc[end-1] == '/' {
+		t.Fatalf("fixture element %q must have a separate end tag", id)
+	}
+	at = end + 1
+
+	out := make([]byte, 0, len(doc)+len(signature))
+	out = append(out, doc[:at]...)
+	out = append(out, signature...)
+	return append(out, doc[at:]...)
+}
+
+func findByID(e *xmldsig.Element, id string) *xmldsig.Element {
+	if e.Attr("ID") == id {
+		return e
+	}
+	for _, c := range e.Children {
+		if el, ok := c.(*xmldsig.Element); ok {
+			if found := findByID(el, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}